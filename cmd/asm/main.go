@@ -7,18 +7,33 @@ import (
 	"path/filepath"
 
 	ncasm "nitro-core-dx/internal/asm"
+	"nitro-core-dx/internal/link"
 )
 
 func main() {
 	entryBank := flag.Uint("entry-bank", 1, "entry bank")
 	entryOffset := flag.Uint("entry-offset", 0x8000, "entry offset (hex or decimal)")
+	objMode := flag.Bool("obj", false, "write a relocatable linker object instead of a ROM (see cmd/link)")
 	flag.Parse()
 	if flag.NArg() < 2 {
-		fmt.Fprintf(os.Stderr, "Usage: %s [--entry-bank N] [--entry-offset 0x8000] <input.asm> <output.rom>\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Usage: %s [--entry-bank N] [--entry-offset 0x8000] [--obj] <input.asm> <output.rom|output%s>\n", os.Args[0], link.ObjectExt)
 		os.Exit(1)
 	}
 	in := flag.Arg(0)
 	out := flag.Arg(1)
+	if *objMode {
+		obj, err := ncasm.AssembleObjectFile(in)
+		if err == nil {
+			err = link.WriteObject(out, obj)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Assembler error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Assembled %s -> %s\n", filepath.Base(in), filepath.Base(out))
+		fmt.Printf("Code words: %d, symbols: %d, relocations: %d\n", len(obj.Code), len(obj.Symbols), len(obj.Relocs))
+		return
+	}
 	res, err := ncasm.AssembleFile(in, &ncasm.Options{EntryBank: uint8(*entryBank), EntryOffset: uint16(*entryOffset), OutputPath: out})
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Assembler error: %v\n", err)
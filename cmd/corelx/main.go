@@ -1,25 +1,56 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"nitro-core-dx/internal/corelx"
 )
 
 func main() {
-	if len(os.Args) < 3 {
-		fmt.Fprintf(os.Stderr, "Usage: %s <project: .ncdx | folder | main.corelx> <output.cart>\n", os.Args[0])
+	if len(os.Args) > 1 && os.Args[1] == "fmt" {
+		os.Exit(runFmt(os.Args[2:]))
+	}
+	fs := flag.NewFlagSet("corelx", flag.ExitOnError)
+	emitListing := fs.Bool("emit-listing", false, "Also write <output>.lst: each source line followed by the instructions generated for it")
+	emitIR := fs.Bool("emit-ir", false, "Also write <output>.ir: a dump of each function in the compiler's IR, for inspection (the ROM is not built from it)")
+	noPeephole := fs.Bool("no-peephole", false, "Skip the peephole pass over the generated code")
+	validateHW := fs.Bool("validate-hardware", false, "Run the ROM briefly under strict hardware rules and warn about late VRAM/CGRAM/OAM writes and write-only register reads")
+	target := fs.String("target", string(corelx.TargetDev), "Tune the code for `dev` (emulator) or hardware (VBlank guards before VRAM/CGRAM/OAM uploads)")
+	var defines defineFlags
+	fs.Var(&defines, "D", "Set flag `NAME` for #if NAME sections (repeatable)")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [--emit-listing] [--emit-ir] [--no-peephole] [--target dev|hardware] [--validate-hardware] [-D NAME]... <project: .ncdx | folder | main.corelx> <output.cart>\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s fmt [-w] [-l] <file.corelx>...\n", os.Args[0])
+	}
+	fs.Parse(os.Args[1:])
+	if fs.NArg() != 2 {
+		fs.Usage()
 		os.Exit(1)
 	}
-	inputPath := os.Args[1]
-	outputPath := os.Args[2]
+	inputPath := fs.Arg(0)
+	outputPath := fs.Arg(1)
+	opts := &corelx.CompileOptions{OutputPath: outputPath, ValidateHardware: *validateHW}
+	if *emitListing {
+		opts.ListingOutputPath = outputPath + ".lst"
+	}
+	if *emitIR {
+		opts.IROutputPath = outputPath + ".ir"
+	}
 
-	// CompileProject resolves .ncdx containers and project folders, loads
+	// CompileProjectWith resolves .ncdx containers and project folders, loads
 	// external image (.cxasset) assets, runs the orphan check, and writes the
 	// ROM to OutputPath.
-	_, err := corelx.CompileProject(inputPath, &corelx.CompileOptions{OutputPath: outputPath})
+	result, err := corelx.CompileProjectWith(inputPath, corelx.Options{
+		Target:    corelx.Target(*target),
+		Optimize:  !*noPeephole,
+		DebugInfo: *emitListing,
+		Defines:   defines,
+		Advanced:  opts,
+	})
 	if err != nil {
 		if de, ok := err.(*corelx.DiagnosticsError); ok {
 			for _, d := range de.Diagnostics {
@@ -32,5 +63,83 @@ func main() {
 		}
 		os.Exit(1)
 	}
+	// Warnings and notes (beginner diagnostics among them) don't stop the
+	// build, but are worth seeing.
+	for _, d := range result.Diagnostics {
+		switch d.Severity {
+		case corelx.SeverityWarning:
+			fmt.Fprintf(os.Stderr, "warning: %s\n", d.Error())
+		case corelx.SeverityInfo:
+			fmt.Fprintf(os.Stderr, "note: %s\n", d.Error())
+		}
+	}
 	fmt.Printf("Compiled %s -> %s\n", filepath.Base(inputPath), filepath.Base(outputPath))
+	if *emitListing {
+		fmt.Printf("Listing: %s\n", filepath.Base(opts.ListingOutputPath))
+	}
+	if *emitIR {
+		fmt.Printf("IR: %s\n", filepath.Base(opts.IROutputPath))
+	}
+	if result.Manifest != nil {
+		if p := result.Manifest.Peephole; p != nil && p.CodeBytesAfter < p.CodeBytesBefore {
+			fmt.Printf("Peephole: code %d -> %d bytes\n", p.CodeBytesBefore, p.CodeBytesAfter)
+		}
+	}
+}
+
+// defineFlags collects repeated -D NAME flags.
+type defineFlags []string
+
+func (d *defineFlags) String() string { return strings.Join(*d, ",") }
+
+func (d *defineFlags) Set(name string) error {
+	if !corelx.ValidDefineName(name) {
+		return fmt.Errorf("%q is not a flag name", name)
+	}
+	*d = append(*d, name)
+	return nil
+}
+
+// runFmt implements `corelx fmt`: print each file in canonical layout, or
+// with -w rewrite it in place, or with -l list the files that would change.
+func runFmt(args []string) int {
+	fs := flag.NewFlagSet("fmt", flag.ExitOnError)
+	write := fs.Bool("w", false, "write the result back to the file instead of printing it")
+	list := fs.Bool("l", false, "list files whose formatting differs")
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: corelx fmt [-w] [-l] <file.corelx>...")
+		return 2
+	}
+
+	status := 0
+	for _, path := range fs.Args() {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			status = 1
+			continue
+		}
+		out, err := corelx.Format(string(src))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			status = 1
+			continue
+		}
+		changed := out != string(src)
+		if *list && changed {
+			fmt.Println(path)
+		}
+		if *write {
+			if changed {
+				if err := os.WriteFile(path, []byte(out), 0644); err != nil {
+					fmt.Fprintf(os.Stderr, "error: %v\n", err)
+					status = 1
+				}
+			}
+		} else if !*list {
+			fmt.Print(out)
+		}
+	}
+	return status
 }
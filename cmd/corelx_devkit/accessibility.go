@@ -0,0 +1,147 @@
+package main
+
+import (
+	"image/color"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"nitro-core-dx/internal/i18n"
+)
+
+// accessibleLabeler is implemented by the Dev Kit's canvas-drawn widgets
+// (the emulator surface, the Sprite Lab canvas) to describe themselves in
+// words. Fyne does not expose an accessibility tree to screen readers yet,
+// so the Dev Kit announces the label in the status bar when the widget takes
+// keyboard focus, and view.describe_focus repeats it on demand.
+type accessibleLabeler interface {
+	AccessibleLabel() string
+}
+
+// newFocusRing returns the outline a custom focusable widget draws while it
+// has keyboard focus; it starts hidden.
+func newFocusRing() *canvas.Rectangle {
+	ring := canvas.NewRectangle(color.Transparent)
+	ring.StrokeWidth = 2
+	ring.StrokeColor = color.Transparent
+	return ring
+}
+
+func showFocusRing(ring *canvas.Rectangle, focused bool) {
+	if ring == nil {
+		return
+	}
+	ring.StrokeColor = color.Transparent
+	if focused {
+		ring.StrokeColor = theme.Color(theme.ColorNameFocus)
+	}
+	ring.Refresh()
+}
+
+// describeFocusable returns the words announced for obj: its
+// AccessibleLabel, or a role and text for the standard widgets.
+func describeFocusable(obj fyne.Focusable) string {
+	switch w := obj.(type) {
+	case nil:
+		return i18n.T("Nothing has keyboard focus")
+	case accessibleLabeler:
+		return w.AccessibleLabel()
+	case *widget.Button:
+		return i18n.T("Button: %s", w.Text)
+	case *widget.Check:
+		return i18n.T("Checkbox: %s, %s", w.Text, onOff(w.Checked, i18n.T("checked"), i18n.T("not checked")))
+	case *widget.Select:
+		return i18n.T("Drop-down: %s", w.Selected)
+	case *widget.Entry:
+		if w.PlaceHolder != "" {
+			return i18n.T("Text field: %s", w.PlaceHolder)
+		}
+		return i18n.T("Text field")
+	case *widget.List:
+		return i18n.T("List. Up and Down move, Space selects")
+	}
+	return i18n.T("Focused control")
+}
+
+// describeFocus announces the focused widget in the status bar.
+func (s *devKitState) describeFocus() {
+	s.setStatus(describeFocusable(s.window.Canvas().Focused()))
+}
+
+func (s *devKitState) emulatorAccessibleLabel() string {
+	state := ""
+	if s.emuLabel != nil {
+		state = s.emuLabel.Text
+	}
+	return i18n.T("Emulator display, %s. Keys drive the controller while Capture Input is %s.", state, onOff(s.captureGameInput, i18n.T("on"), i18n.T("off")))
+}
+
+// focusEditor shows the code editor and gives it keyboard focus.
+func (s *devKitState) focusEditor() {
+	if s.sourceEditor == nil {
+		return
+	}
+	if s.currentView == viewModeEmulatorOnly {
+		s.setViewMode(viewModeFull)
+	}
+	selectTabByText(s.workbenchTabs, "Code")
+	s.window.Canvas().Focus(s.sourceEditor)
+	s.setStatus(describeFocusable(s.sourceEditor))
+}
+
+// focusEmulator shows the emulator surface and gives it keyboard focus.
+func (s *devKitState) focusEmulator() {
+	if s.currentView == viewModeCodeOnly {
+		s.setViewMode(viewModeFull)
+	}
+	s.focusEmulatorInput()
+}
+
+// focusDiagnostics shows the Diagnostics tab and focuses its list, where
+// Up/Down and Space pick an entry.
+func (s *devKitState) focusDiagnostics() {
+	if s.diagnosticsList == nil || !s.showBottomTab("Diagnostics") {
+		return
+	}
+	s.window.Canvas().Focus(s.diagnosticsList)
+	s.setStatus(i18n.T("Diagnostics: %d shown. Up and Down move, Space opens", len(s.filteredDiagnostics)))
+}
+
+// nextDiagnosticIndex steps delta rows from current through n diagnostics,
+// wrapping at both ends. With nothing selected (current < 0) it starts at
+// the first row going forward or the last going back.
+func nextDiagnosticIndex(current, delta, n int) int {
+	if n <= 0 {
+		return -1
+	}
+	if current < 0 || current >= n {
+		if delta < 0 {
+			return n - 1
+		}
+		return 0
+	}
+	return ((current+delta)%n + n) % n
+}
+
+// stepDiagnostic selects the diagnostic delta rows away and jumps the editor
+// to it.
+func (s *devKitState) stepDiagnostic(delta int) {
+	n := len(s.filteredDiagnostics)
+	if n == 0 || s.diagnosticsList == nil {
+		s.setStatus(i18n.T("No diagnostics"))
+		return
+	}
+	next := nextDiagnosticIndex(s.diagnosticIndex, delta, n)
+	s.diagnosticsList.ScrollTo(next)
+	if next == s.diagnosticIndex {
+		// Select does not fire OnSelected for the row already selected.
+		d := s.filteredDiagnostics[next]
+		s.showDiagnosticDetail(d)
+		s.jumpToDiagnostic(d)
+	} else {
+		s.diagnosticsList.Select(next)
+	}
+	d := s.filteredDiagnostics[next]
+	s.setStatus(i18n.T("Diagnostic %d of %d: %s", next+1, n, d.Message))
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"fyne.io/fyne/v2"
+	fynetest "fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+	"nitro-core-dx/internal/devkit"
+)
+
+func TestMainMenuReachesEveryCommand(t *testing.T) {
+	a := fynetest.NewApp()
+	defer a.Quit()
+
+	s := &devKitState{backend: devkit.NewService(t.TempDir()), settings: defaultDevKitSettings()}
+	labels := map[string]bool{}
+	var walk func(items []*fyne.MenuItem)
+	walk = func(items []*fyne.MenuItem) {
+		for _, item := range items {
+			labels[item.Label] = !item.Disabled
+			if item.ChildMenu != nil {
+				walk(item.ChildMenu.Items)
+			}
+		}
+	}
+	for _, menu := range s.buildMainMenu().Items {
+		walk(menu.Items)
+	}
+	for _, cmd := range s.devKitCommands() {
+		if !labels[cmd.title] {
+			t.Errorf("command %s (%q) has no enabled main menu item", cmd.id, cmd.title)
+		}
+	}
+}
+
+func TestNextDiagnosticIndexWraps(t *testing.T) {
+	cases := []struct{ current, delta, n, want int }{
+		{-1, 1, 3, 0},
+		{-1, -1, 3, 2},
+		{0, 1, 3, 1},
+		{2, 1, 3, 0},
+		{0, -1, 3, 2},
+		{5, 1, 3, 0},
+		{0, 1, 0, -1},
+	}
+	for _, c := range cases {
+		if got := nextDiagnosticIndex(c.current, c.delta, c.n); got != c.want {
+			t.Errorf("nextDiagnosticIndex(%d, %d, %d) = %d, want %d", c.current, c.delta, c.n, got, c.want)
+		}
+	}
+}
+
+func TestSpriteCanvasKeyboardPainting(t *testing.T) {
+	a := fynetest.NewApp()
+	defer a.Quit()
+
+	var painted [][2]int
+	var hover [2]int
+	var announced string
+	o := newSpriteLabPaintOverlay(8, 8, 8, nil, nil,
+		func(x, y int) { painted = append(painted, [2]int{x, y}) },
+		func(x, y int) { hover = [2]int{x, y} },
+		nil,
+	)
+	o.announce = func(msg string) { announced = msg }
+	w := fynetest.NewWindow(o)
+	defer w.Close()
+
+	w.Canvas().Focus(o)
+	if !strings.Contains(announced, "Sprite canvas, 8 by 8") {
+		t.Fatalf("focus announced %q", announced)
+	}
+	for _, key := range []fyne.KeyName{fyne.KeyRight, fyne.KeyRight, fyne.KeyDown, fyne.KeyLeft, fyne.KeyUp, fyne.KeyUp} {
+		o.TypedKey(&fyne.KeyEvent{Name: key})
+	}
+	if hover != [2]int{1, 0} {
+		t.Fatalf("cursor = %v, want (1,0) after clamping at the top edge", hover)
+	}
+	o.TypedKey(&fyne.KeyEvent{Name: fyne.KeySpace})
+	if len(painted) != 1 || painted[0] != [2]int{1, 0} {
+		t.Fatalf("painted = %v, want one cell at (1,0)", painted)
+	}
+	if !strings.Contains(o.AccessibleLabel(), "cursor at (1,0)") {
+		t.Fatalf("label = %q", o.AccessibleLabel())
+	}
+}
+
+func TestDescribeFocusable(t *testing.T) {
+	a := fynetest.NewApp()
+	defer a.Quit()
+
+	entry := widget.NewEntry()
+	entry.SetPlaceHolder("Search diagnostics")
+	check := widget.NewCheck("Capture Input", nil)
+	check.SetChecked(true)
+	cases := map[string]string{
+		describeFocusable(nil):                          "Nothing has keyboard focus",
+		describeFocusable(entry):                        "Text field: Search diagnostics",
+		describeFocusable(check):                        "Checkbox: Capture Input, checked",
+		describeFocusable(widget.NewButton("Run", nil)): "Button: Run",
+	}
+	for got, want := range cases {
+		if got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	}
+}
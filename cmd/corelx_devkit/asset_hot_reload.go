@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"nitro-core-dx/internal/corelx"
+)
+
+// Asset hot-reload: after Build + Run, the Dev Kit watches the files behind
+// the project's file-backed assets (corelx.assets.json records with a path,
+// and .cxasset images). When one changes -- a re-exported PNG, say -- only
+// the assets built from it are rebuilt (corelx.LiveAssets) and patched into
+// the running emulator's VRAM and CGRAM through the PPU's DMA write path,
+// without restarting the ROM. An asset that changed size needs a rebuild.
+const assetHotReloadInterval = time.Second
+
+// liveAssetWatch is what the running build uploaded, for hot-reload.
+type liveAssetWatch struct {
+	source     string
+	sourcePath string
+	opts       corelx.CompileOptions
+	assets     []corelx.LiveAsset
+	modTimes   map[string]time.Time
+}
+
+// armAssetHotReload starts watching the asset files of the build just
+// loaded into the emulator. Untitled buffers have no asset files.
+func (s *devKitState) armAssetHotReload(source, sourcePath string, defines []string) {
+	s.liveAssets = nil
+	if s.currentPath == "" {
+		return
+	}
+	opts := corelx.CompileOptions{Defines: defines}
+	assets, err := corelx.LiveAssets(source, sourcePath, &opts)
+	if err != nil {
+		s.appendBuildOutput("Asset hot-reload off: " + err.Error())
+		return
+	}
+	if len(assets) == 0 {
+		return
+	}
+	w := &liveAssetWatch{source: source, sourcePath: sourcePath, opts: opts, assets: assets, modTimes: map[string]time.Time{}}
+	for _, a := range assets {
+		if st, err := os.Stat(a.File); err == nil {
+			w.modTimes[a.File] = st.ModTime()
+		}
+	}
+	s.liveAssets = w
+}
+
+// startAssetHotReload polls the watched asset files until the emulator loop
+// stops.
+func (s *devKitState) startAssetHotReload() {
+	go func() {
+		ticker := time.NewTicker(assetHotReloadInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.updateLoopStop:
+				return
+			case <-ticker.C:
+			}
+			fyne.Do(s.reloadChangedAssets)
+		}
+	}()
+}
+
+// reloadChangedAssets rebuilds and patches the assets whose files changed
+// since the last check. It does nothing while an external ROM
+// (sessionROMPath) is running instead of the build.
+func (s *devKitState) reloadChangedAssets() {
+	w := s.liveAssets
+	if w == nil || s.sessionROMPath != "" {
+		return
+	}
+	var changed []string
+	for path, seen := range w.modTimes {
+		st, err := os.Stat(path)
+		if err != nil || st.ModTime().Equal(seen) {
+			continue
+		}
+		w.modTimes[path] = st.ModTime()
+		changed = append(changed, path)
+	}
+	if len(changed) == 0 {
+		return
+	}
+	rebuilt, err := corelx.LiveAssets(w.source, w.sourcePath, &w.opts, changed...)
+	if err != nil {
+		s.appendBuildOutput("Asset hot-reload failed: " + err.Error())
+		s.setStatus("Asset hot-reload failed")
+		return
+	}
+	for _, next := range rebuilt {
+		i := liveAssetIndex(w.assets, next.Name)
+		if i < 0 || liveAssetEqual(w.assets[i], next) {
+			continue
+		}
+		n, err := s.backend.PatchLiveAsset(w.assets[i], next)
+		if err != nil {
+			s.appendBuildOutput(fmt.Sprintf("Asset %s not reloaded: %v", next.Name, err))
+			s.setStatus(fmt.Sprintf("Asset %s changed; rebuild to apply it", next.Name))
+			continue
+		}
+		if n == 0 {
+			// VRAM will get the ROM's copy, so keep looking for that.
+			s.appendBuildOutput(fmt.Sprintf("Asset %s changed, but the ROM has not loaded it yet; rebuild to apply it", next.Name))
+			continue
+		}
+		w.assets[i] = next
+		msg := fmt.Sprintf("Reloaded %s from %s", next.Name, filepath.Base(next.File))
+		s.appendBuildOutput(msg)
+		s.setStatus(msg)
+		s.backend.Notify(msg)
+	}
+}
+
+func liveAssetIndex(assets []corelx.LiveAsset, name string) int {
+	for i, a := range assets {
+		if a.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func liveAssetEqual(a, b corelx.LiveAsset) bool {
+	if len(a.Pieces) != len(b.Pieces) {
+		return false
+	}
+	for i := range a.Pieces {
+		if a.Pieces[i].Addr != b.Pieces[i].Addr || !bytes.Equal(a.Pieces[i].Data, b.Pieces[i].Data) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"time"
+
+	"github.com/veandco/go-sdl2/sdl"
+
+	"nitro-core-dx/internal/devkit"
+)
+
+// While a ROM runs at 1x with sound, frames are run by a goroutine of their
+// own that keeps the SDL queue topped up to audioQueueFrames frames, so the
+// audio device rather than the UI ticker sets the pace. A UI stall (a long
+// build, a modal dialog, a slow panel refresh) then delays the picture but
+// no longer leaves the device without samples. The ticker still routes
+// input and presents what the audio thread has run since its last tick.
+//
+// Deterministic mode and other speeds stay on the ticker: a fixed timestep
+// must not follow the sound card's clock, and a sped-up ROM has more audio
+// than the device plays. Both loops reach the emulator only through the
+// Backend, whose lock orders their frames; SDL calls happen outside it.
+
+// audioLoopPoll is how often the audio thread checks the SDL queue while
+// pacing: well under a frame, so the queue never drains by more than one
+// frame between checks. Otherwise it only looks for a running ROM every
+// audioLoopIdlePoll.
+const (
+	audioLoopPoll     = 2 * time.Millisecond
+	audioLoopIdlePoll = 50 * time.Millisecond
+)
+
+func (s *devKitState) startAudioLoop() {
+	if s.audioDev == 0 {
+		return
+	}
+	s.audioLoopDone = make(chan struct{})
+	go func() {
+		defer close(s.audioLoopDone)
+		poll := audioLoopIdlePoll
+		for {
+			select {
+			case <-s.updateLoopStop:
+				s.audioPacing.Store(false)
+				return
+			case <-time.After(poll):
+			}
+			if s.backend.Deterministic() || s.backend.Speed() != 1 {
+				s.audioPacing.Store(false)
+				poll = audioLoopIdlePoll
+				continue
+			}
+
+			queued := int(sdl.GetQueuedAudioSize(s.audioDev)) / audioFrameBytes
+			tick, err := s.backend.RunFrames(int(s.audioQueueFrames.Load()) - queued)
+			pacing := tick.Snapshot.Loaded && !tick.Snapshot.Paused
+			if pacing && !s.audioPacing.Swap(true) {
+				// The emulator loop may be asleep on a paused or empty
+				// session; it has frames to present now.
+				s.wakeEmulatorLoop()
+			}
+			s.audioPacing.Store(pacing)
+			poll = audioLoopIdlePoll
+			if pacing {
+				poll = audioLoopPoll
+			}
+			for _, samples := range tick.AudioFrames {
+				s.queueFrameAudio(samples)
+			}
+			if len(tick.AudioFrames) > 0 {
+				s.reportAudioQueue()
+			}
+			s.publishAudioTick(tick, err)
+		}
+	}()
+}
+
+// waitAudioLoop waits for the audio thread to see updateLoopStop, so the
+// device can be closed under it.
+func (s *devKitState) waitAudioLoop() {
+	if s.audioLoopDone != nil {
+		<-s.audioLoopDone
+	}
+}
+
+// publishAudioTick adds what one RunFrames call produced to the results
+// waiting for the UI ticker. Text accumulates; the newest frame wins.
+func (s *devKitState) publishAudioTick(tick devkit.TickResult, err error) {
+	s.pendingMu.Lock()
+	defer s.pendingMu.Unlock()
+	p := &s.pendingTick
+	p.TestOutput += tick.TestOutput
+	p.DebugLines = append(p.DebugLines, tick.DebugLines...)
+	if tick.Snapshot.Loaded {
+		p.Snapshot = tick.Snapshot
+	}
+	p.FramesStepped += tick.FramesStepped
+	if tick.PresentFrame {
+		p.PresentFrame = true
+		p.Framebuffer = tick.Framebuffer
+	}
+	if err != nil && s.pendingErr == nil {
+		s.pendingErr = err
+	}
+}
+
+// takeAudioTick returns and clears the audio thread's results, or false
+// when there is nothing to present.
+func (s *devKitState) takeAudioTick() (devkit.TickResult, bool, error) {
+	s.pendingMu.Lock()
+	defer s.pendingMu.Unlock()
+	tick, err := s.pendingTick, s.pendingErr
+	s.pendingTick, s.pendingErr = devkit.TickResult{}, nil
+	ok := err != nil || tick.TestOutput != "" || len(tick.DebugLines) > 0 || tick.PresentFrame
+	return tick, ok, err
+}
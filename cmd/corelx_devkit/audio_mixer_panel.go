@@ -0,0 +1,283 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+	"nitro-core-dx/internal/apu"
+	"nitro-core-dx/internal/devkit"
+)
+
+const (
+	audioMixerScopeSamples = 256
+	audioMixerMeterWidth   = 120
+	audioMixerMeterHeight  = 12
+	audioMixerScopeWidth   = 256
+	audioMixerScopeHeight  = 40
+	// Meter peak hold decays by this factor per refresh so short clicks
+	// stay visible long enough to notice.
+	audioMixerPeakDecay = 0.85
+)
+
+var (
+	audioMixerMeterBG     = color.NRGBA{R: 0x20, G: 0x22, B: 0x28, A: 0xFF}
+	audioMixerMeterRMS    = color.NRGBA{R: 0x3C, G: 0xC8, B: 0x5A, A: 0xFF}
+	audioMixerMeterPeak   = color.NRGBA{R: 0xF0, G: 0xC0, B: 0x30, A: 0xFF}
+	audioMixerMeterClip   = color.NRGBA{R: 0xE8, G: 0x40, B: 0x40, A: 0xFF}
+	audioMixerScopeBG     = color.NRGBA{R: 0x10, G: 0x12, B: 0x16, A: 0xFF}
+	audioMixerScopeMid    = color.NRGBA{R: 0x30, G: 0x34, B: 0x3C, A: 0xFF}
+	audioMixerScopeLive   = color.NRGBA{R: 0x60, G: 0xD0, B: 0xF0, A: 0xFF}
+	audioMixerScopeSilent = color.NRGBA{R: 0x58, G: 0x5C, B: 0x66, A: 0xFF}
+)
+
+type audioMixerRow struct {
+	channel   apu.MixerChannel
+	muteCheck *widget.Check
+	soloCheck *widget.Check
+	meterRMS  *canvas.Rectangle
+	meterPeak *canvas.Rectangle
+	levelText *widget.Label
+	scope     *canvas.Raster
+
+	peakHold float64
+	samples  []int16
+	audible  bool
+}
+
+type audioMixerPanel struct {
+	rows    []*audioMixerRow
+	summary *widget.Label
+	health  *widget.Label
+}
+
+// buildAudioMixerPane builds the per-channel mute/solo panel with VU meters
+// and waveform scopes. Levels are pulled from the backend when frames are
+// presented (see refreshAudioMixer).
+func (s *devKitState) buildAudioMixerPane() fyne.CanvasObject {
+	panel := &audioMixerPanel{
+		summary: widget.NewLabel("Audio: no build loaded"),
+		health:  widget.NewLabel(""),
+	}
+	grid := container.NewVBox()
+	for ch := apu.MixerChannel(0); ch < apu.MixerChannelCount; ch++ {
+		row := newAudioMixerRow(ch)
+		channel := ch
+		row.muteCheck = widget.NewCheck("Mute", func(v bool) {
+			s.backend.SetAudioChannelMuted(channel, v)
+			s.setStatus(fmt.Sprintf("Audio %s %s", channel, onOff(v, "muted", "unmuted")))
+		})
+		row.soloCheck = widget.NewCheck("Solo", func(v bool) {
+			s.backend.SetAudioChannelSolo(channel, v)
+			s.setStatus(fmt.Sprintf("Audio %s solo %s", channel, onOff(v, "on", "off")))
+		})
+		panel.rows = append(panel.rows, row)
+
+		name := widget.NewLabel(ch.String())
+		name.TextStyle = fyne.TextStyle{Monospace: true, Bold: true}
+		meter := container.NewWithoutLayout(
+			canvas.NewRectangle(audioMixerMeterBG),
+			row.meterRMS,
+			row.meterPeak,
+		)
+		meter.Objects[0].Resize(fyne.NewSize(audioMixerMeterWidth, audioMixerMeterHeight))
+		meterBox := container.NewGridWrap(fyne.NewSize(audioMixerMeterWidth, audioMixerMeterHeight), meter)
+		grid.Add(container.NewHBox(
+			name,
+			row.muteCheck,
+			row.soloCheck,
+			container.NewCenter(meterBox),
+			row.levelText,
+			layout.NewSpacer(),
+			row.scope,
+		))
+	}
+	resetBtn := widget.NewButton("Clear Mute/Solo", func() {
+		for _, row := range panel.rows {
+			row.muteCheck.SetChecked(false)
+			row.soloCheck.SetChecked(false)
+		}
+		s.setStatus("Audio mixer reset")
+	})
+	s.audioMixer = panel
+	return container.NewBorder(
+		container.NewHBox(panel.summary, panel.health, layout.NewSpacer(), resetBtn),
+		nil, nil, nil,
+		container.NewVScroll(grid),
+	)
+}
+
+func newAudioMixerRow(ch apu.MixerChannel) *audioMixerRow {
+	row := &audioMixerRow{
+		channel:   ch,
+		meterRMS:  canvas.NewRectangle(audioMixerMeterRMS),
+		meterPeak: canvas.NewRectangle(audioMixerMeterPeak),
+		levelText: widget.NewLabel("-inf"),
+	}
+	row.levelText.TextStyle = fyne.TextStyle{Monospace: true}
+	row.meterRMS.Resize(fyne.NewSize(0, audioMixerMeterHeight))
+	row.meterPeak.Resize(fyne.NewSize(2, audioMixerMeterHeight))
+	row.scope = canvas.NewRaster(func(w, h int) image.Image {
+		return renderAudioScope(row.samples, w, h, row.audible)
+	})
+	row.scope.SetMinSize(fyne.NewSize(audioMixerScopeWidth, audioMixerScopeHeight))
+	return row
+}
+
+// refreshAudioMixer pulls a fresh mixer snapshot and updates meters/scopes.
+// Must run on the Fyne UI goroutine.
+func (s *devKitState) refreshAudioMixer() {
+	panel := s.audioMixer
+	if panel == nil {
+		return
+	}
+	snap := s.backend.AudioMixerSnapshot(audioMixerScopeSamples)
+	if !snap.Loaded {
+		panel.summary.SetText("Audio: no build loaded")
+		panel.health.SetText("")
+		return
+	}
+	audible := 0
+	for i, ch := range snap.Channels {
+		if i >= len(panel.rows) {
+			break
+		}
+		if ch.Audible {
+			audible++
+		}
+		panel.rows[i].update(ch)
+	}
+	panel.summary.SetText(fmt.Sprintf("Audio: %d/%d channels audible", audible, len(snap.Channels)))
+	panel.health.SetText(formatAudioHealth(snap.Health))
+	if snap.Health.OK() {
+		panel.health.Importance = widget.SuccessImportance
+	} else {
+		panel.health.Importance = widget.DangerImportance
+	}
+	panel.health.Refresh()
+}
+
+// formatAudioHealth is the audio health indicator's text: clicks found by
+// the backend's click detector since the ROM was loaded or reset.
+func formatAudioHealth(h devkit.AudioHealth) string {
+	if h.OK() {
+		return "Health: OK"
+	}
+	return fmt.Sprintf("Health: %d click(s), last at frame %d (jump %+d)", h.Clicks, h.LastFrame, h.LastJump)
+}
+
+func (r *audioMixerRow) update(ch devkit.AudioChannelSnapshot) {
+	r.peakHold *= audioMixerPeakDecay
+	if ch.Peak > r.peakHold {
+		r.peakHold = ch.Peak
+	}
+	r.meterRMS.Resize(fyne.NewSize(float32(clampUnit(ch.RMS))*audioMixerMeterWidth, audioMixerMeterHeight))
+	peakX := float32(clampUnit(r.peakHold)) * (audioMixerMeterWidth - 2)
+	r.meterPeak.Move(fyne.NewPos(peakX, 0))
+	if r.peakHold >= 0.999 {
+		r.meterPeak.FillColor = audioMixerMeterClip
+	} else {
+		r.meterPeak.FillColor = audioMixerMeterPeak
+	}
+	r.meterRMS.Refresh()
+	r.meterPeak.Refresh()
+	r.levelText.SetText(formatAudioLevel(ch.Peak))
+
+	if r.muteCheck.Checked != ch.Muted {
+		r.muteCheck.SetChecked(ch.Muted)
+	}
+	if r.soloCheck.Checked != ch.Solo {
+		r.soloCheck.SetChecked(ch.Solo)
+	}
+	r.samples = ch.Scope
+	r.audible = ch.Audible
+	r.scope.Refresh()
+}
+
+// formatAudioLevel renders a normalized peak as dBFS with a fixed width so
+// the meter column does not jitter.
+func formatAudioLevel(peak float64) string {
+	if peak <= 0 {
+		return " -inf dB"
+	}
+	return fmt.Sprintf("%5.1f dB", 20*math.Log10(peak))
+}
+
+// renderAudioScope draws a min/max waveform envelope for samples into a
+// w×h image. Inaudible (muted/not soloed) channels are drawn dimmed.
+func renderAudioScope(samples []int16, w, h int, audible bool) image.Image {
+	if w <= 0 || h <= 0 {
+		return image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	}
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetNRGBA(x, y, audioMixerScopeBG)
+		}
+	}
+	mid := h / 2
+	for x := 0; x < w; x++ {
+		img.SetNRGBA(x, mid, audioMixerScopeMid)
+	}
+	if len(samples) == 0 {
+		return img
+	}
+	fg := audioMixerScopeLive
+	if !audible {
+		fg = audioMixerScopeSilent
+	}
+	toY := func(v int16) int {
+		y := mid - int(int32(v)*int32(h/2)/32768)
+		if y < 0 {
+			return 0
+		}
+		if y >= h {
+			return h - 1
+		}
+		return y
+	}
+	for x := 0; x < w; x++ {
+		start := x * len(samples) / w
+		end := (x + 1) * len(samples) / w
+		if end <= start {
+			end = start + 1
+		}
+		lo, hi := samples[start], samples[start]
+		for _, v := range samples[start:end] {
+			if v < lo {
+				lo = v
+			}
+			if v > hi {
+				hi = v
+			}
+		}
+		y0, y1 := toY(hi), toY(lo)
+		for y := y0; y <= y1; y++ {
+			img.SetNRGBA(x, y, fg)
+		}
+	}
+	return img
+}
+
+func clampUnit(v float64) float64 {
+	if v < 0 {
+		return 0
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
+
+func onOff(v bool, on, off string) string {
+	if v {
+		return on
+	}
+	return off
+}
@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/dialog"
 )
 
@@ -48,6 +49,26 @@ func (s *devKitState) writeAutosaveSnapshot(content string) {
 	_ = os.WriteFile(s.autosavePath, data, 0644)
 }
 
+// scheduleAutosave writes the autosave journal for an edit. With an autosave
+// interval set, edits within the interval are coalesced into one write of the
+// latest buffer.
+func (s *devKitState) scheduleAutosave(content string) {
+	interval := time.Duration(s.settings.AutosaveInterval) * time.Second
+	if interval <= 0 {
+		s.writeAutosaveSnapshot(content)
+		return
+	}
+	if s.autosaveTimer != nil {
+		return
+	}
+	s.autosaveTimer = time.AfterFunc(interval, func() {
+		fyne.Do(func() {
+			s.autosaveTimer = nil
+			s.writeAutosaveSnapshot(s.sourceEditor.Text())
+		})
+	})
+}
+
 func (s *devKitState) clearAutosaveJournal() {
 	if s.autosavePath == "" {
 		return
@@ -55,35 +76,49 @@ func (s *devKitState) clearAutosaveJournal() {
 	_ = os.Remove(s.autosavePath)
 }
 
-func (s *devKitState) tryRecoverAutosave() {
+// pendingAutosave returns the autosave journal when one exists and differs
+// from the editor buffer. Empty or unreadable journals are removed.
+func (s *devKitState) pendingAutosave() (autosaveJournal, bool) {
 	if s.autosavePath == "" {
-		return
+		return autosaveJournal{}, false
 	}
 	data, err := os.ReadFile(s.autosavePath)
 	if err != nil {
-		if errors.Is(err, os.ErrNotExist) {
-			return
+		if !errors.Is(err, os.ErrNotExist) {
+			s.appendBuildOutput("Autosave recover warning: " + err.Error())
 		}
-		s.appendBuildOutput("Autosave recover warning: " + err.Error())
-		return
+		return autosaveJournal{}, false
 	}
 	if len(data) == 0 {
 		s.clearAutosaveJournal()
-		return
+		return autosaveJournal{}, false
 	}
 
 	var journal autosaveJournal
 	if err := json.Unmarshal(data, &journal); err != nil {
 		s.appendBuildOutput("Autosave recover warning: invalid journal format")
 		s.clearAutosaveJournal()
-		return
+		return autosaveJournal{}, false
 	}
-	if strings.TrimSpace(journal.Content) == "" {
+	if strings.TrimSpace(journal.Content) == "" || journal.Content == s.sourceEditor.Text() {
 		s.clearAutosaveJournal()
-		return
+		return autosaveJournal{}, false
 	}
-	if journal.Content == s.sourceEditor.Text() {
-		s.clearAutosaveJournal()
+	return journal, true
+}
+
+func (s *devKitState) applyAutosave(journal autosaveJournal) {
+	s.setSourceContent(journal.Content, true, false)
+	if journal.SourcePath != "" {
+		s.currentPath = journal.SourcePath
+		s.pathLabel.SetText(displayPath(s.currentPath))
+	}
+	s.refreshTitle()
+}
+
+func (s *devKitState) tryRecoverAutosave() {
+	journal, ok := s.pendingAutosave()
+	if !ok {
 		return
 	}
 
@@ -101,12 +136,7 @@ func (s *devKitState) tryRecoverAutosave() {
 			s.clearAutosaveJournal()
 			return
 		}
-		s.setSourceContent(journal.Content, true, false)
-		if journal.SourcePath != "" {
-			s.currentPath = journal.SourcePath
-			s.pathLabel.SetText(displayPath(s.currentPath))
-		}
-		s.refreshTitle()
+		s.applyAutosave(journal)
 		s.setStatus("Recovered autosave journal")
 		s.appendBuildOutput("Recovered autosave journal")
 	}, s.window).Show()
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"nitro-core-dx/internal/corelx"
+	"nitro-core-dx/internal/devkit"
+)
+
+// Background build: backgroundBuildDelay after the last edit the Dev Kit
+// compiles the buffer in memory (devkit.Service.CheckSource) with the
+// selected run configuration and refreshes the diagnostics and manifest,
+// so errors show up without pressing Build. Nothing is written or loaded
+// into the emulator. Another edit, or a real build, cancels a check that
+// is waiting or running; a running compile finishes but its result is
+// dropped. The chip beside the build state shows where the check is.
+const backgroundBuildDelay = 1500 * time.Millisecond
+
+// backgroundBuildState is what the status chip shows.
+type backgroundBuildState int
+
+const (
+	backgroundBuildIdle    backgroundBuildState = iota // no check since the last build
+	backgroundBuildPending                             // edited, waiting for the delay
+	backgroundBuildRunning
+	backgroundBuildDone
+)
+
+// backgroundBuildChip is the status chip: a spinner while a check runs and
+// its result after.
+type backgroundBuildChip struct {
+	spinner *widget.Activity
+	label   *widget.Label
+	box     *fyne.Container
+}
+
+func newBackgroundBuildChip() *backgroundBuildChip {
+	c := &backgroundBuildChip{spinner: widget.NewActivity(), label: widget.NewLabel("")}
+	c.spinner.Hide()
+	c.box = container.NewHBox(c.spinner, c.label)
+	return c
+}
+
+// formatBackgroundBuildChip returns the chip's text and importance for
+// state; summary is the finished check's diagnostics and failed whether
+// it errored without any.
+func formatBackgroundBuildChip(state backgroundBuildState, summary corelx.CompileSummary, failed bool) (string, widget.Importance) {
+	switch state {
+	case backgroundBuildPending:
+		return "Edited", widget.LowImportance
+	case backgroundBuildRunning:
+		return "Checking...", widget.LowImportance
+	case backgroundBuildDone:
+		switch {
+		case summary.ErrorCount > 0:
+			return countLabel(summary.ErrorCount, "error"), widget.DangerImportance
+		case failed:
+			return "Check failed", widget.DangerImportance
+		case summary.WarningCount > 0:
+			return countLabel(summary.WarningCount, "warning"), widget.WarningImportance
+		default:
+			return "No errors", widget.SuccessImportance
+		}
+	}
+	return "", widget.MediumImportance
+}
+
+// countLabel is "1 noun" or "n nouns".
+func countLabel(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+func (s *devKitState) setBackgroundBuildChip(state backgroundBuildState, summary corelx.CompileSummary, failed bool) {
+	c := s.backgroundBuildChip
+	if c == nil {
+		return
+	}
+	text, importance := formatBackgroundBuildChip(state, summary, failed)
+	if state == backgroundBuildRunning {
+		c.spinner.Show()
+		c.spinner.Start()
+	} else {
+		c.spinner.Stop()
+		c.spinner.Hide()
+	}
+	c.label.Importance = importance
+	c.label.SetText(text)
+}
+
+// scheduleBackgroundBuild (re)starts the idle delay after an edit,
+// canceling any check of an older buffer.
+func (s *devKitState) scheduleBackgroundBuild() {
+	if !s.settings.BackgroundBuild {
+		s.stopBackgroundBuild()
+		return
+	}
+	s.cancelBackgroundBuild()
+	seq := s.backgroundBuildSeq
+	s.backgroundBuildTimer = time.AfterFunc(backgroundBuildDelay, func() {
+		fyne.Do(func() { s.startBackgroundBuild(seq) })
+	})
+	s.setBackgroundBuildChip(backgroundBuildPending, corelx.CompileSummary{}, false)
+}
+
+// cancelBackgroundBuild stops a waiting or running check; a running
+// compile's result is dropped when it finishes.
+func (s *devKitState) cancelBackgroundBuild() {
+	s.backgroundBuildSeq++
+	if s.backgroundBuildTimer != nil {
+		s.backgroundBuildTimer.Stop()
+		s.backgroundBuildTimer = nil
+	}
+	if s.backgroundBuildCancel != nil {
+		s.backgroundBuildCancel()
+		s.backgroundBuildCancel = nil
+	}
+}
+
+// stopBackgroundBuild cancels any check and clears the chip, for a real
+// build or turning the background build off.
+func (s *devKitState) stopBackgroundBuild() {
+	s.cancelBackgroundBuild()
+	s.setBackgroundBuildChip(backgroundBuildIdle, corelx.CompileSummary{}, false)
+}
+
+// startBackgroundBuild compiles the buffer off the UI thread, unless
+// another edit or a build came after the delay that called it.
+func (s *devKitState) startBackgroundBuild(seq uint64) {
+	if seq != s.backgroundBuildSeq {
+		return
+	}
+	s.backgroundBuildTimer = nil
+	source, sourcePath := s.sourceEditor.Text(), s.currentPath
+	if sourcePath == "" {
+		sourcePath = "untitled.corelx"
+	}
+	s.syncRunConfigs()
+	cfg := s.runConfigs.Selected()
+	ctx, cancel := context.WithCancel(context.Background())
+	s.backgroundBuildCancel = cancel
+	s.setBackgroundBuildChip(backgroundBuildRunning, corelx.CompileSummary{}, false)
+
+	go func() {
+		defer cancel()
+		check, err := s.backend.CheckSource(ctx, source, sourcePath, cfg)
+		if ctx.Err() != nil {
+			return
+		}
+		fyne.Do(func() {
+			if seq != s.backgroundBuildSeq {
+				return
+			}
+			s.backgroundBuildCancel = nil
+			s.applyBackgroundBuild(source, sourcePath, check, err)
+		})
+	}()
+}
+
+// applyBackgroundBuild shows a finished check's diagnostics and manifest.
+// The build state, build output and emulator are left alone.
+func (s *devKitState) applyBackgroundBuild(source, sourcePath string, check *devkit.BuildResult, err error) {
+	var bundle corelx.CompileBundle
+	var res *corelx.CompileResult
+	if check != nil {
+		bundle, res = check.Bundle, check.Result
+	}
+	s.builtSource, s.builtSourcePath = source, sourcePath
+	s.diagnostics = bundle.Diagnostics
+	s.applyDiagnosticFilter()
+	s.updateManifestPane(bundle, res)
+	s.setBackgroundBuildChip(backgroundBuildDone, bundle.Summary, err != nil)
+}
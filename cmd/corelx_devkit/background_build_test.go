@@ -0,0 +1,36 @@
+package main
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2/widget"
+	"nitro-core-dx/internal/corelx"
+)
+
+func TestFormatBackgroundBuildChip(t *testing.T) {
+	tests := []struct {
+		name           string
+		state          backgroundBuildState
+		summary        corelx.CompileSummary
+		failed         bool
+		want           string
+		wantImportance widget.Importance
+	}{
+		{name: "idle", state: backgroundBuildIdle, want: "", wantImportance: widget.MediumImportance},
+		{name: "pending", state: backgroundBuildPending, want: "Edited", wantImportance: widget.LowImportance},
+		{name: "running", state: backgroundBuildRunning, want: "Checking...", wantImportance: widget.LowImportance},
+		{name: "clean", state: backgroundBuildDone, want: "No errors", wantImportance: widget.SuccessImportance},
+		{name: "oneError", state: backgroundBuildDone, summary: corelx.CompileSummary{ErrorCount: 1, WarningCount: 2}, failed: true, want: "1 error", wantImportance: widget.DangerImportance},
+		{name: "warnings", state: backgroundBuildDone, summary: corelx.CompileSummary{WarningCount: 3}, want: "3 warnings", wantImportance: widget.WarningImportance},
+		{name: "failedWithoutDiagnostics", state: backgroundBuildDone, failed: true, want: "Check failed", wantImportance: widget.DangerImportance},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, importance := formatBackgroundBuildChip(tt.state, tt.summary, tt.failed)
+			if got != tt.want || importance != tt.wantImportance {
+				t.Fatalf("formatBackgroundBuildChip = %q, %v; want %q, %v", got, importance, tt.want, tt.wantImportance)
+			}
+		})
+	}
+}
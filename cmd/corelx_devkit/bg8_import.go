@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	_ "image/png"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+	"nitro-core-dx/internal/emulator"
+	ppucore "nitro-core-dx/internal/ppu"
+)
+
+const (
+	bg8ImportIndexed = "Indexed (256 colors)"
+	bg8ImportDirect  = "Direct color (RGB333)"
+)
+
+// showBG8ImportDialog converts a PNG into an 8bpp background image asset: it
+// writes <name>.cxasset next to the project source and adds (or updates) the
+// `asset <Name>: image` declaration, ready for bg.load_image.
+func (s *devKitState) showBG8ImportDialog() {
+	if s.currentPath == "" {
+		s.setStatus("Save the project first: the image asset is written next to its source")
+		return
+	}
+	fd := dialog.NewFileOpen(func(rc fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, s.window)
+			return
+		}
+		if rc == nil {
+			return
+		}
+		defer rc.Close()
+		pngPath := uriPath(rc.URI())
+		img, _, err := image.Decode(rc)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("decode %s: %w", filepath.Base(pngPath), err), s.window)
+			return
+		}
+		s.showBG8ImportOptions(img, pngPath)
+	}, s.window)
+	fd.SetFilter(storage.NewExtensionFileFilter([]string{".png"}))
+	if loc := dialogListableForDir(filepath.Dir(s.currentPath)); loc != nil {
+		fd.SetLocation(loc)
+	}
+	fd.Show()
+}
+
+func (s *devKitState) showBG8ImportOptions(img image.Image, pngPath string) {
+	base := strings.TrimSuffix(filepath.Base(pngPath), filepath.Ext(pngPath))
+	name := widget.NewEntry()
+	name.SetText(sanitizeSpriteLabName(base))
+	format := widget.NewRadioGroup([]string{bg8ImportIndexed, bg8ImportDirect}, nil)
+	format.SetSelected(bg8ImportIndexed)
+	b := img.Bounds()
+	help := widget.NewLabel(fmt.Sprintf("%dx%d pixels. Indexed keeps up to 256 exact colors in CGRAM; direct color needs no palette but rounds each channel to 8 levels.", b.Dx(), b.Dy()))
+	help.Wrapping = fyne.TextWrapWord
+	help.Importance = widget.LowImportance
+
+	form := widget.NewForm(
+		widget.NewFormItem("Asset name", name),
+		widget.NewFormItem("Format", format),
+	)
+	d := dialog.NewCustomConfirm("Import 8bpp Background", "Import", "Cancel", container.NewBorder(nil, help, nil, nil, form), func(ok bool) {
+		if !ok {
+			return
+		}
+		f := ppucore.BGFormatIndexed8
+		if format.Selected == bg8ImportDirect {
+			f = ppucore.BGFormatDirect8
+		}
+		if err := s.importBG8(img, pngPath, sanitizeSpriteLabName(name.Text), f); err != nil {
+			dialog.ShowError(err, s.window)
+		}
+	}, s.window)
+	d.Resize(fyne.NewSize(480, 260))
+	d.Show()
+}
+
+func (s *devKitState) importBG8(img image.Image, pngPath, name string, format uint8) error {
+	bg, err := emulator.BuildBG8ImageFromImage(img, format)
+	if err != nil {
+		return err
+	}
+	file := strings.ToLower(name) + ".cxasset"
+	text := bg.CxAsset(name, fmt.Sprintf("Generated by the Dev Kit from %s; re-import to change", filepath.Base(pngPath)))
+	if err := os.WriteFile(filepath.Join(filepath.Dir(s.currentPath), file), []byte(text), 0644); err != nil {
+		return err
+	}
+	s.setSourceContent(upsertImageAssetDecl(s.sourceEditor.Text(), name, file), true, false)
+	summary := fmt.Sprintf("Imported %s as %s: %s, tilemap at 0x%04X; load it with bg.load_image(%s, layer)",
+		filepath.Base(pngPath), file, bg.PackSummary(), bg.TilemapBase, name)
+	s.appendBuildOutput(summary)
+	s.setStatus("Imported " + file)
+	return nil
+}
+
+// upsertImageAssetDecl sets `asset <name>: image "<file>"` in source,
+// replacing an existing declaration of name, otherwise inserting it before
+// the first top-level asset (so it never lands inside a multi-line asset
+// block), otherwise at the top.
+func upsertImageAssetDecl(source, name, file string) string {
+	decl := fmt.Sprintf("asset %s: image %q", name, file)
+	lines := strings.Split(source, "\n")
+	firstAsset := -1
+	for i, line := range lines {
+		if !strings.HasPrefix(line, "asset ") {
+			continue
+		}
+		if strings.HasPrefix(line, "asset "+name+":") {
+			lines[i] = decl
+			return strings.Join(lines, "\n")
+		}
+		if firstAsset < 0 {
+			firstAsset = i
+		}
+	}
+	if firstAsset < 0 {
+		return decl + "\n\n" + source
+	}
+	lines = append(lines[:firstAsset], append([]string{decl}, lines[firstAsset:]...)...)
+	return strings.Join(lines, "\n")
+}
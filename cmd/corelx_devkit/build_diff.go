@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"nitro-core-dx/internal/devkit"
+)
+
+// buildDiffContext is how many unchanged instructions the build diff keeps
+// around each change.
+const buildDiffContext = 4
+
+// formatBuildDiff renders d as two columns, the previous build on the left
+// and the latest on the right, with the row kind between them.
+func formatBuildDiff(d *devkit.BuildDiff) string {
+	if d.Equal() {
+		return "The last two builds generated identical code.\n"
+	}
+	var sb strings.Builder
+	for _, c := range d.Header {
+		fmt.Fprintf(&sb, "header %s: 0x%X -> 0x%X\n", c.Field, c.A, c.B)
+	}
+	for _, bank := range d.OnlyPrevious {
+		fmt.Fprintf(&sb, "bank %02X: only in the previous build\n", bank)
+	}
+	for _, bank := range d.OnlyLatest {
+		fmt.Fprintf(&sb, "bank %02X: only in this build\n", bank)
+	}
+
+	width := len("Previous build")
+	for _, row := range d.Rows {
+		if row.Old != nil {
+			width = max(width, len(row.Old.String()))
+		}
+	}
+	if len(d.Rows) > 0 {
+		fmt.Fprintf(&sb, "%-*s     %s\n", width, "Previous build", "This build")
+	}
+	for _, row := range d.Rows {
+		if row.Kind == '@' {
+			fmt.Fprintf(&sb, "@@ bank %02X @@\n", row.Bank)
+			continue
+		}
+		var old, latest string
+		if row.Old != nil {
+			old = row.Old.String()
+		}
+		if row.New != nil {
+			latest = row.New.String()
+		}
+		sb.WriteString(strings.TrimRight(fmt.Sprintf("%-*s  %c  %s", width, old, row.Kind, latest), " ") + "\n")
+	}
+	return sb.String()
+}
+
+// showBuildDiff compares the disassembly of the last two builds.
+func (s *devKitState) showBuildDiff() {
+	d, err := s.backend.BuildDiff(buildDiffContext)
+	if err != nil {
+		s.setStatus("Compare with previous build: " + err.Error())
+		return
+	}
+	text := newReadOnlyTextArea()
+	text.SetText(formatBuildDiff(d))
+	text.SetMinRowsVisible(24)
+	dlg := dialog.NewCustom("Build Diff: Previous vs This Build", "Close", container.NewScroll(text), s.window)
+	dlg.Resize(fyne.NewSize(1100, 620))
+	dlg.Show()
+}
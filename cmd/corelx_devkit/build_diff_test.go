@@ -0,0 +1,37 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"nitro-core-dx/internal/devkit"
+	"nitro-core-dx/internal/emulator"
+)
+
+func TestFormatBuildDiffSideBySide(t *testing.T) {
+	same := &emulator.CodeLine{Bank: 1, Offset: 0x8000, Text: "NOP", Symbol: "Start"}
+	d := &devkit.BuildDiff{Rows: []devkit.BuildDiffRow{
+		{Kind: '@', Bank: 1},
+		{Kind: ' ', Bank: 1, Old: same, New: same},
+		{Kind: '~', Bank: 1,
+			Old: &emulator.CodeLine{Bank: 1, Offset: 0x8002, Text: "MOV R0, #0x0001"},
+			New: &emulator.CodeLine{Bank: 1, Offset: 0x8002, Text: "MOV R0, #0x0002"}},
+		{Kind: '+', Bank: 1, New: &emulator.CodeLine{Bank: 1, Offset: 0x8006, Text: "NOP"}},
+	}}
+	got := strings.Split(strings.TrimRight(formatBuildDiff(d), "\n"), "\n")
+	if len(got) != 5 {
+		t.Fatalf("got %d rows, want 5: %q", len(got), got)
+	}
+	if got[1] != "@@ bank 01 @@" {
+		t.Errorf("hunk row = %q", got[1])
+	}
+	if !strings.Contains(got[3], "#0x0001") || !strings.Contains(got[3], " ~ ") || !strings.HasSuffix(got[3], "#0x0002") {
+		t.Errorf("changed row = %q, want old ~ new", got[3])
+	}
+	if !strings.HasPrefix(strings.TrimLeft(got[4], " "), "+  01:8006") {
+		t.Errorf("added row = %q, want an empty left column", got[4])
+	}
+	if got := formatBuildDiff(&devkit.BuildDiff{}); !strings.Contains(got, "identical") {
+		t.Errorf("equal builds = %q", got)
+	}
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"nitro-core-dx/internal/i18n"
+)
+
+// paletteItem is one row of the command palette.
+type paletteItem struct {
+	category string
+	title    string
+	binding  string
+	run      func()
+}
+
+func (p paletteItem) label() string {
+	return p.category + ": " + p.title
+}
+
+// fuzzyScore matches query as a case-insensitive subsequence of text.
+// Consecutive characters and characters starting a word score higher, so
+// "tdp" ranks "Toggle Diagnostics Panel" above titles that merely contain
+// those letters. An empty query matches everything with score 0.
+func fuzzyScore(query, text string) (int, bool) {
+	query = strings.ToLower(strings.Join(strings.Fields(query), ""))
+	lower := strings.ToLower(text)
+	score, qi, prev := 0, 0, -2
+	for ti := 0; ti < len(lower) && qi < len(query); ti++ {
+		if lower[ti] != query[qi] {
+			continue
+		}
+		score++
+		if ti == prev+1 {
+			score += 2
+		}
+		if ti == 0 || strings.ContainsRune(" :._-/+", rune(lower[ti-1])) {
+			score += 3
+		}
+		prev = ti
+		qi++
+	}
+	if qi < len(query) {
+		return 0, false
+	}
+	return score, true
+}
+
+// filterPaletteItems returns the items matching query, best match first.
+// Ties keep registry order so menus and the palette list commands alike.
+func filterPaletteItems(items []paletteItem, query string) []paletteItem {
+	type scored struct {
+		item  paletteItem
+		score int
+	}
+	var matches []scored
+	for _, item := range items {
+		if score, ok := fuzzyScore(query, item.label()); ok {
+			matches = append(matches, scored{item, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+	out := make([]paletteItem, len(matches))
+	for i, m := range matches {
+		out[i] = m.item
+	}
+	return out
+}
+
+// paletteItems lists every command plus entries that only make sense in the
+// palette: recent files and the workbench and bottom panel tabs.
+func (s *devKitState) paletteItems() []paletteItem {
+	var items []paletteItem
+	for _, cmd := range s.devKitCommands() {
+		if cmd.id == "view.command_palette" {
+			continue
+		}
+		items = append(items, paletteItem{cmd.category, cmd.title, formatKeyBinding(s.keymap[cmd.id]), cmd.run})
+	}
+	for _, path := range s.settings.RecentFiles {
+		p := path
+		items = append(items, paletteItem{i18n.T("Open Recent"), baseNameOr(p, p), "", func() { s.openRecentFile(p) }})
+	}
+	addTabs := func(category string, tabs *container.AppTabs) {
+		if tabs == nil {
+			return
+		}
+		for i, tab := range tabs.Items {
+			index := i
+			items = append(items, paletteItem{category, tab.Text, "", func() { tabs.SelectIndex(index) }})
+		}
+	}
+	addTabs(i18n.T("Go to Tab"), s.workbenchTabs)
+	addTabs(i18n.T("Go to Panel"), s.bottomLeftTabs)
+	return items
+}
+
+// paletteEntry is the palette's search field. Up and Down move the list
+// selection and Escape closes the palette without running anything.
+type paletteEntry struct {
+	widget.Entry
+	onMove   func(delta int)
+	onEscape func()
+}
+
+func newPaletteEntry() *paletteEntry {
+	e := &paletteEntry{}
+	e.ExtendBaseWidget(e)
+	return e
+}
+
+func (e *paletteEntry) TypedKey(key *fyne.KeyEvent) {
+	switch key.Name {
+	case fyne.KeyUp:
+		e.onMove(-1)
+	case fyne.KeyDown:
+		e.onMove(1)
+	case fyne.KeyPageUp:
+		e.onMove(-8)
+	case fyne.KeyPageDown:
+		e.onMove(8)
+	case fyne.KeyEscape:
+		e.onEscape()
+	default:
+		e.Entry.TypedKey(key)
+	}
+}
+
+// showCommandPalette opens a searchable list of every Dev Kit action. Typing
+// filters with fuzzy matching; Enter or Run executes the selected entry.
+func (s *devKitState) showCommandPalette() {
+	all := s.paletteItems()
+	shown := all
+	selected := 0
+
+	var popup *widget.PopUp
+	list := widget.NewList(
+		func() int { return len(shown) },
+		func() fyne.CanvasObject {
+			binding := widget.NewLabel("")
+			binding.Importance = widget.LowImportance
+			return container.NewBorder(nil, nil, nil, binding, widget.NewLabel(""))
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			row := obj.(*fyne.Container)
+			row.Objects[0].(*widget.Label).SetText(shown[id].label())
+			row.Objects[1].(*widget.Label).SetText(shown[id].binding)
+		},
+	)
+	run := func(index int) {
+		if index < 0 || index >= len(shown) {
+			return
+		}
+		item := shown[index]
+		popup.Hide()
+		item.run()
+	}
+	list.OnSelected = func(id widget.ListItemID) { selected = id }
+
+	entry := newPaletteEntry()
+	entry.SetPlaceHolder(i18n.T("Type a command..."))
+	entry.onMove = func(delta int) {
+		if len(shown) == 0 {
+			return
+		}
+		selected = clampInt(selected+delta, 0, len(shown)-1)
+		list.Select(selected)
+		list.ScrollTo(selected)
+	}
+	entry.onEscape = func() { popup.Hide() }
+	entry.OnSubmitted = func(string) { run(selected) }
+	entry.OnChanged = func(query string) {
+		shown = filterPaletteItems(all, query)
+		selected = 0
+		list.UnselectAll()
+		list.Refresh()
+		if len(shown) > 0 {
+			list.Select(0)
+			list.ScrollToTop()
+		}
+	}
+	// Clicking a row selects it; a click on the already selected row does not
+	// fire OnSelected again, so the Run button covers mouse users.
+	runBtn := widget.NewButton(i18n.T("Run"), func() { run(selected) })
+	runBtn.Importance = widget.HighImportance
+	closeBtn := widget.NewButton(i18n.T("Close"), func() { popup.Hide() })
+	hint := widget.NewLabel(i18n.T("Enter runs · Up/Down selects · Esc closes"))
+	hint.Importance = widget.LowImportance
+
+	content := container.NewBorder(entry, container.NewBorder(nil, nil, nil, container.NewHBox(closeBtn, runBtn), hint), nil, nil, list)
+	popup = widget.NewModalPopUp(content, s.window.Canvas())
+	popup.Resize(fyne.NewSize(560, 420))
+	popup.Show()
+	if len(shown) > 0 {
+		list.Select(0)
+	}
+	s.window.Canvas().Focus(entry)
+}
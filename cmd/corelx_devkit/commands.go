@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"nitro-core-dx/internal/i18n"
+)
+
+// devKitCommand is one Dev Kit action. Commands back the main menu, the
+// command palette, and the keymap: id is the stable name keymap overrides
+// refer to, defaultKey the binding used when the user has not rebound it.
+type devKitCommand struct {
+	id         string
+	category   string
+	title      string
+	defaultKey string
+	run        func()
+}
+
+// devKitCommands returns every bindable command in menu order.
+func (s *devKitState) devKitCommands() []devKitCommand {
+	saveNow := func() {
+		if err := s.save(); err != nil {
+			dialog.ShowError(err, s.window)
+		}
+	}
+	return []devKitCommand{
+		{"file.new", i18n.T("File"), i18n.T("New Project"), "Ctrl+N", s.showTemplateDialog},
+		{"file.new_window", i18n.T("File"), i18n.T("New Window"), "Ctrl+Shift+N", s.openNewWindow},
+		{"file.open", i18n.T("File"), i18n.T("Open Project..."), "Ctrl+O", s.showOpenProjectDialog},
+		{"file.load_rom", i18n.T("File"), i18n.T("Load ROM..."), "Ctrl+L", s.openROMDialog},
+		{"file.save", i18n.T("File"), i18n.T("Save"), "Ctrl+S", saveNow},
+		{"file.save_as", i18n.T("File"), i18n.T("Save As..."), "Ctrl+Shift+S", s.saveAsDialog},
+		{"file.recover_autosave", i18n.T("File"), i18n.T("Recover Autosave"), "", s.tryRecoverAutosave},
+		{"file.export_vram", i18n.T("File"), i18n.T("Export VRAM Tiles (PNG)..."), "", s.showVRAMExportDialog},
+		{"file.export_cgram", i18n.T("File"), i18n.T("Export CGRAM Palette (PNG)..."), "", s.exportCGRAMPalette},
+		{"file.import_bg8", i18n.T("File"), i18n.T("Import PNG as 8bpp Background..."), "", s.showBG8ImportDialog},
+		{"file.package_share", i18n.T("File"), i18n.T("Package for Sharing..."), "", s.packageForSharing},
+
+		{"view.code_only", i18n.T("View"), i18n.T("Code Only"), "Ctrl+1", func() { s.setViewMode(viewModeCodeOnly) }},
+		{"view.split", i18n.T("View"), i18n.T("Split View"), "Ctrl+2", func() { s.setViewMode(viewModeFull) }},
+		{"view.emulator_focus", i18n.T("View"), i18n.T("Emulator Focus"), "Ctrl+3", func() { s.setViewMode(viewModeEmulatorOnly) }},
+		{"view.toggle_diagnostics", i18n.T("View"), i18n.T("Toggle Diagnostics Panel"), "Ctrl+J", s.toggleDiagnosticsPanel},
+		{"view.toggle_capture", i18n.T("View"), i18n.T("Toggle Capture Input"), "Ctrl+Shift+I", s.toggleCaptureInput},
+		{"view.popout_emulator", i18n.T("View"), i18n.T("Emulator in Separate Window"), "", s.toggleEmulatorWindow},
+		{"view.popout_debugger", i18n.T("View"), i18n.T("Debugger in Separate Window"), "", s.toggleDebuggerWindow},
+		{"view.command_palette", i18n.T("View"), i18n.T("Command Palette..."), "Ctrl+Shift+P", s.showCommandPalette},
+		{"view.focus_editor", i18n.T("View"), i18n.T("Focus Code Editor"), "Alt+1", s.focusEditor},
+		{"view.focus_emulator", i18n.T("View"), i18n.T("Focus Emulator"), "Alt+2", s.focusEmulator},
+		{"view.focus_diagnostics", i18n.T("View"), i18n.T("Focus Diagnostics"), "Alt+3", s.focusDiagnostics},
+		{"view.next_diagnostic", i18n.T("View"), i18n.T("Next Diagnostic"), "Alt+F8", func() { s.stepDiagnostic(1) }},
+		{"view.prev_diagnostic", i18n.T("View"), i18n.T("Previous Diagnostic"), "Shift+Alt+F8", func() { s.stepDiagnostic(-1) }},
+		{"view.describe_focus", i18n.T("View"), i18n.T("Describe Focused Control"), "Ctrl+Alt+D", s.describeFocus},
+
+		{"build.build", i18n.T("Build"), i18n.T("Build"), "Ctrl+B", func() { s.runBuild(false) }},
+		{"build.run", i18n.T("Build"), i18n.T("Build + Run"), "Ctrl+R", func() { s.runBuild(true) }},
+		{"build.format", i18n.T("Build"), i18n.T("Format Source"), "Ctrl+Shift+F", func() {
+			if s.formatSource() {
+				s.setStatus("Formatted")
+			}
+		}},
+		{"build.run_configs", i18n.T("Build"), i18n.T("Run Configurations..."), "", s.showRunConfigDialog},
+		{"build.diff_previous", i18n.T("Build"), i18n.T("Compare with Previous Build"), "", s.showBuildDiff},
+
+		{"debug.run", i18n.T("Debug"), i18n.T("Run"), "Ctrl+F5", s.runEmulator},
+		{"debug.pause", i18n.T("Debug"), i18n.T("Pause"), "Ctrl+F6", s.pauseEmulator},
+		{"debug.stop", i18n.T("Debug"), i18n.T("Stop"), "Ctrl+Shift+F5", s.stopEmulator},
+		{"debug.step_frame", i18n.T("Debug"), i18n.T("Step Frame"), "Ctrl+F10", s.stepFrame},
+		{"debug.step_cpu", i18n.T("Debug"), i18n.T("Step CPU"), "Ctrl+F11", s.stepCPU},
+		{"debug.step_back", i18n.T("Debug"), i18n.T("Step Back"), "Ctrl+Shift+F11", s.stepBack},
+		{"debug.mark_frame", i18n.T("Debug"), i18n.T("Mark Frame"), "", s.markCurrentFrame},
+		{"debug.console", i18n.T("Debug"), i18n.T("Immediate Console"), "Ctrl+Shift+E", s.showImmediateConsole},
+		{"debug.io_registers", i18n.T("Debug"), i18n.T("I/O Registers"), "", func() { s.showBottomTab(ioRegisterTabName) }},
+		{"debug.logging", i18n.T("Debug"), i18n.T("Logging..."), "", s.showLoggingDialog},
+		{"debug.disassembly", i18n.T("Debug"), i18n.T("Disassembly at PC"), "", s.showDisassemblyAtPC},
+		{"debug.macro_record", i18n.T("Debug"), i18n.T("Record Macro"), "Ctrl+Alt+M", s.toggleMacroRecording},
+		{"debug.macro_play", i18n.T("Debug"), i18n.T("Play Macro"), "Ctrl+Shift+M", s.playMacro},
+		{"debug.movie_record", i18n.T("Debug"), i18n.T("Record Movie..."), "", s.showRecordMovieDialog},
+		{"debug.movie_play", i18n.T("Debug"), i18n.T("Play Movie..."), "", s.playMovieDialog},
+		{"debug.movie_stop", i18n.T("Debug"), i18n.T("Stop Movie"), "", s.stopMovie},
+		{"debug.soft_reset", i18n.T("Debug"), i18n.T("Soft Reset"), "Ctrl+Alt+R", s.softReset},
+		{"debug.reset", i18n.T("Debug"), i18n.T("Hardware Reset"), "Ctrl+Shift+R", s.hardwareReset},
+		{"debug.deterministic", i18n.T("Debug"), i18n.T("Deterministic Timing"), "", func() {
+			s.toggleDeterministic()
+			s.refreshMainMenu()
+		}},
+		{"debug.priority_view", i18n.T("Debug"), i18n.T("Priority View"), "", func() {
+			s.togglePriorityView()
+			s.refreshMainMenu()
+		}},
+		{"debug.input_display", i18n.T("Debug"), i18n.T("Input Display"), "", func() {
+			s.toggleInputDisplay()
+			s.refreshMainMenu()
+		}},
+
+		{"tools.preferences", i18n.T("Tools"), i18n.T("Preferences..."), "Ctrl+,", s.showPreferences},
+		{"tools.keymap", i18n.T("Tools"), i18n.T("Keyboard Shortcuts..."), "", s.showKeymapDialog},
+		{"tools.layout_balanced", i18n.T("Tools"), i18n.T("Layout: Balanced"), "", func() { s.applyLayoutPreset(layoutPresetBalanced) }},
+		{"tools.layout_code", i18n.T("Tools"), i18n.T("Layout: Code Focus"), "", func() { s.applyLayoutPreset(layoutPresetCodeFocus) }},
+		{"tools.layout_art", i18n.T("Tools"), i18n.T("Layout: Art Mode"), "", func() { s.applyLayoutPreset(layoutPresetArtMode) }},
+		{"tools.layout_debug", i18n.T("Tools"), i18n.T("Layout: Debug Mode"), "", func() { s.applyLayoutPreset(layoutPresetDebugMode) }},
+		{"tools.layout_emulator", i18n.T("Tools"), i18n.T("Layout: Emulator Focus"), "", func() { s.applyLayoutPreset(layoutPresetEmulatorFocus) }},
+		{"tools.density_compact", i18n.T("Tools"), i18n.T("UI Density: Compact"), "", func() { s.setUIDensity("compact") }},
+		{"tools.density_standard", i18n.T("Tools"), i18n.T("UI Density: Standard"), "", func() { s.setUIDensity("standard") }},
+		{"tools.theme_system", i18n.T("Tools"), i18n.T("Theme: System"), "", func() { s.setTheme(themeSystem) }},
+		{"tools.theme_dark", i18n.T("Tools"), i18n.T("Theme: Dark"), "", func() { s.setTheme(themeDark) }},
+		{"tools.theme_light", i18n.T("Tools"), i18n.T("Theme: Light"), "", func() { s.setTheme(themeLight) }},
+		{"tools.editor_colors", i18n.T("Tools"), i18n.T("Editor Colors..."), "", s.showEditorColorsDialog},
+
+		{"help.center", i18n.T("Help"), i18n.T("Help Center"), "", s.showHelpCenter},
+		{"help.reference", i18n.T("Help"), i18n.T("Reference (F1 in Editor)"), "Ctrl+Shift+H", s.showReferenceAtCursor},
+		{"help.docs", i18n.T("Help"), i18n.T("Open Docs on GitHub"), "", func() {
+			s.openExternalURL("https://github.com/RetroCodeRamen/Nitro-Core-DX/tree/main/docs")
+		}},
+		{"help.about", i18n.T("Help"), i18n.T("About Nitro-Core-DX"), "", s.showAbout},
+	}
+}
+
+// commandMenuItem builds the menu item for command id, showing its current
+// key binding. Fyne runs main-menu shortcuts before the focused widget sees
+// the key, so bindings work while the code editor has focus.
+func (s *devKitState) commandMenuItem(id string) *fyne.MenuItem {
+	for _, cmd := range s.devKitCommands() {
+		if cmd.id != id {
+			continue
+		}
+		item := fyne.NewMenuItem(cmd.title, cmd.run)
+		if sc := s.keymap[id]; sc != nil {
+			item.Shortcut = sc
+		}
+		return item
+	}
+	return disabledMenuItem(id)
+}
+
+func (s *devKitState) toggleCaptureInput() {
+	if s.captureCheck != nil {
+		s.captureCheck.SetChecked(!s.captureGameInput)
+	}
+	s.setStatus(i18n.T("Capture input %s", onOff(s.captureGameInput, i18n.T("on"), i18n.T("off"))))
+}
+
+func (s *devKitState) showAbout() {
+	dialog.ShowInformation(
+		i18n.T("About Nitro-Core-DX"),
+		i18n.T("Nitro-Core-DX is a project-centric SDK with an integrated emulator subsystem.\n\nUse Build + Run for the primary workflow. Code Only hides the emulator for focused development. Split View shows code and hardware output side by side. Emulator Focus isolates hardware output testing.\n\nWindow maximize/restore is handled by your operating system title bar controls."),
+		s.window,
+	)
+}
@@ -54,3 +54,36 @@ func (t *compactTheme) Size(name fyne.ThemeSizeName) float32 {
 func newStandardTheme() fyne.Theme {
 	return theme.DefaultTheme()
 }
+
+// Theme settings values. themeSystem follows the OS light/dark preference.
+const (
+	themeSystem = "system"
+	themeDark   = "dark"
+	themeLight  = "light"
+)
+
+// variantTheme forces a base theme's light or dark color variant.
+type variantTheme struct {
+	fyne.Theme
+	variant fyne.ThemeVariant
+}
+
+func (t *variantTheme) Color(name fyne.ThemeColorName, _ fyne.ThemeVariant) color.Color {
+	return t.Theme.Color(name, t.variant)
+}
+
+// newDevKitTheme combines a UI density ("compact"/"standard") with a theme
+// setting (themeSystem/themeDark/themeLight).
+func newDevKitTheme(density, variant string) fyne.Theme {
+	base := newCompactTheme()
+	if density == "standard" {
+		base = newStandardTheme()
+	}
+	switch variant {
+	case themeDark:
+		return &variantTheme{Theme: base, variant: theme.VariantDark}
+	case themeLight:
+		return &variantTheme{Theme: base, variant: theme.VariantLight}
+	}
+	return base
+}
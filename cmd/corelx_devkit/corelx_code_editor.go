@@ -18,6 +18,7 @@ import (
 
 	"nitro-core-dx/internal/corelx"
 	nativeed "nitro-core-dx/internal/editor/native"
+	"nitro-core-dx/internal/i18n"
 )
 
 var (
@@ -43,7 +44,14 @@ type coreLXCodeEditor struct {
 	grid   *widget.TextGrid
 	scroll *container.Scroll
 
-	onChanged func(string)
+	fontTheme    *editorFontTheme
+	themed       *container.ThemeOverride
+	background   *canvas.Rectangle
+	insertSpaces bool
+	scheme       editorColorScheme
+
+	onChanged     func(string)
+	onContextHelp func(word string)
 
 	refreshMu      sync.Mutex
 	refreshPending bool
@@ -63,9 +71,12 @@ func newCoreLXCodeEditor() *coreLXCodeEditor {
 	grid.ShowWhitespace = false
 	grid.Scroll = fyne.ScrollNone
 
+	scheme := resolveEditorColorScheme(editorSchemeDark, nil)
 	e := &coreLXCodeEditor{
-		model: nativeed.NewModel(""),
-		grid:  grid,
+		model:     nativeed.NewModel(""),
+		grid:      grid,
+		fontTheme: &editorFontTheme{scheme: scheme},
+		scheme:    scheme,
 	}
 	e.scroll = container.NewScroll(grid)
 	e.scroll.Direction = container.ScrollBoth
@@ -75,8 +86,89 @@ func newCoreLXCodeEditor() *coreLXCodeEditor {
 }
 
 func (e *coreLXCodeEditor) CreateRenderer() fyne.WidgetRenderer {
-	bg := canvas.NewRectangle(color.NRGBA{R: 12, G: 14, B: 18, A: 255})
-	return widget.NewSimpleRenderer(container.NewMax(bg, e.scroll))
+	e.background = canvas.NewRectangle(e.scheme.color(editorColorBackground))
+	e.themed = container.NewThemeOverride(container.NewMax(e.background, e.scroll), e.fontTheme)
+	return widget.NewSimpleRenderer(e.themed)
+}
+
+// SetTextSize sets the editor font size in points; 0 follows the UI theme.
+func (e *coreLXCodeEditor) SetTextSize(size float32) {
+	e.fontTheme.size = size
+	if e.themed != nil {
+		e.themed.Refresh()
+	}
+	e.scheduleRefresh()
+}
+
+// SetTabWidth sets how wide tab characters render and, when insertSpaces is
+// set, how many spaces the Tab key inserts instead of a tab character.
+func (e *coreLXCodeEditor) SetTabWidth(width int, insertSpaces bool) {
+	e.grid.TabWidth = width
+	e.insertSpaces = insertSpaces
+	e.grid.Refresh()
+}
+
+// SetColorScheme recolors the background and syntax highlighting.
+func (e *coreLXCodeEditor) SetColorScheme(scheme editorColorScheme) {
+	e.scheme = scheme
+	e.fontTheme.scheme = scheme
+	if e.background != nil {
+		e.background.FillColor = scheme.color(editorColorBackground)
+		e.background.Refresh()
+	}
+	if e.themed != nil {
+		e.themed.Refresh()
+	}
+	// Force SetText so stale cell styles from the old scheme are dropped.
+	e.lastRenderedText = ""
+	e.scheduleRefresh()
+}
+
+func (e *coreLXCodeEditor) textSize() float32 {
+	if e.fontTheme.size > 0 {
+		return e.fontTheme.size
+	}
+	return theme.TextSize()
+}
+
+// editorFontTheme defers to the app theme but applies the editor font size
+// and takes text color and light/dark variant from the editor color scheme.
+type editorFontTheme struct {
+	size   float32
+	scheme editorColorScheme
+}
+
+func (t *editorFontTheme) app() fyne.Theme {
+	if a := fyne.CurrentApp(); a != nil && a.Settings().Theme() != nil {
+		return a.Settings().Theme()
+	}
+	return theme.DefaultTheme()
+}
+
+func (t *editorFontTheme) Color(name fyne.ThemeColorName, _ fyne.ThemeVariant) color.Color {
+	if name == theme.ColorNameForeground {
+		return t.scheme.color(editorColorForeground)
+	}
+	variant := theme.VariantLight
+	if t.scheme.dark() {
+		variant = theme.VariantDark
+	}
+	return t.app().Color(name, variant)
+}
+
+func (t *editorFontTheme) Font(style fyne.TextStyle) fyne.Resource {
+	return t.app().Font(style)
+}
+
+func (t *editorFontTheme) Icon(name fyne.ThemeIconName) fyne.Resource {
+	return t.app().Icon(name)
+}
+
+func (t *editorFontTheme) Size(name fyne.ThemeSizeName) float32 {
+	if name == theme.SizeNameText && t.size > 0 {
+		return t.size
+	}
+	return t.app().Size(name)
 }
 
 // MinSize is intentionally clamped so large buffers/long lines in TextGrid do
@@ -162,7 +254,11 @@ func (e *coreLXCodeEditor) TypedKey(ev *fyne.KeyEvent) {
 		e.model.InsertRune('\n')
 		e.notifyChanged()
 	case fyne.KeyTab:
-		e.model.InsertRune('\t')
+		if e.insertSpaces {
+			e.model.InsertText(strings.Repeat(" ", maxInt(1, e.grid.TabWidth)))
+		} else {
+			e.model.InsertRune('\t')
+		}
 		e.notifyChanged()
 	case fyne.KeyBackspace:
 		e.model.Backspace()
@@ -182,6 +278,11 @@ func (e *coreLXCodeEditor) TypedKey(ev *fyne.KeyEvent) {
 		e.model.MoveLineHome(extend)
 	case fyne.KeyEnd:
 		e.model.MoveLineEnd(extend)
+	case fyne.KeyF1:
+		if e.onContextHelp != nil {
+			e.onContextHelp(e.WordAtCursor())
+		}
+		return
 	}
 	e.scheduleRefresh()
 }
@@ -231,6 +332,10 @@ func (e *coreLXCodeEditor) KeyUp(key *fyne.KeyEvent) {
 
 func (e *coreLXCodeEditor) SetOnChanged(cb func(string)) { e.onChanged = cb }
 
+// SetOnContextHelp sets the handler for F1 and the "Look Up in Reference"
+// context menu item; it receives WordAtCursor.
+func (e *coreLXCodeEditor) SetOnContextHelp(cb func(word string)) { e.onContextHelp = cb }
+
 func (e *coreLXCodeEditor) SetText(text string) {
 	e.model.SetText(text)
 	e.invalidateTokenCache()
@@ -244,6 +349,12 @@ func (e *coreLXCodeEditor) Cursor() (row, col int) {
 	return e.model.OffsetToLineCol(e.model.Caret.Offset)
 }
 
+// AccessibleLabel describes the editor and its caret for accessibleLabeler.
+func (e *coreLXCodeEditor) AccessibleLabel() string {
+	row, col := e.Cursor()
+	return i18n.T("Code editor, line %d, column %d", row+1, col+1)
+}
+
 func (e *coreLXCodeEditor) SetCursor(row, col int) {
 	e.model.SetCaretLineCol(row, col, false)
 	e.scheduleRefresh()
@@ -253,6 +364,20 @@ func (e *coreLXCodeEditor) SelectedText() string {
 	return e.model.SelectedText()
 }
 
+// WordAtCursor returns the single-word selection, or else the dotted
+// identifier or number under the caret ("oam.write", "0x8014").
+func (e *coreLXCodeEditor) WordAtCursor() string {
+	if sel := strings.TrimSpace(e.model.SelectedText()); sel != "" && !strings.ContainsAny(sel, " \t\n") {
+		return sel
+	}
+	row, col := e.Cursor()
+	lines := strings.Split(e.model.Text(), "\n")
+	if row < 0 || row >= len(lines) {
+		return ""
+	}
+	return contextWordAt(lines[row], col)
+}
+
 func (e *coreLXCodeEditor) focusSelf() {
 	if fyne.CurrentApp() == nil || fyne.CurrentApp().Driver() == nil {
 		return
@@ -291,7 +416,7 @@ func (e *coreLXCodeEditor) setCaretFromPoint(pos fyne.Position, keepSelection bo
 }
 
 func (e *coreLXCodeEditor) metrics() (lineHeight float32, colWidth float32, gutter float32) {
-	sz := theme.TextSize()
+	sz := e.textSize()
 	lineHeight = fyne.MeasureText("Mg", sz, fyne.TextStyle{Monospace: true}).Height
 	if lineHeight < 1 {
 		lineHeight = 1
@@ -335,6 +460,14 @@ func (e *coreLXCodeEditor) showContextMenu(ev *fyne.PointEvent) {
 			e.scheduleRefresh()
 		}),
 	)
+	if e.onContextHelp != nil {
+		menu.Items = append(menu.Items,
+			fyne.NewMenuItemSeparator(),
+			fyne.NewMenuItem("Look Up in Reference (F1)", func() {
+				e.onContextHelp(e.WordAtCursor())
+			}),
+		)
+	}
 	if c := fyne.CurrentApp().Driver().CanvasForObject(e); c != nil {
 		entryPos := fyne.CurrentApp().Driver().AbsolutePositionForObject(e)
 		e.popup = widget.NewPopUpMenu(menu, c)
@@ -392,7 +525,8 @@ func (e *coreLXCodeEditor) applySyntaxHighlight(source string) {
 		activeRow = len(lines) - 1
 	}
 
-	lineStyle := &widget.CustomTextGridStyle{FGColor: theme.ForegroundColor(), BGColor: color.NRGBA{R: 23, G: 26, B: 33, A: 255}}
+	fg := e.scheme.color(editorColorForeground)
+	lineStyle := &widget.CustomTextGridStyle{FGColor: fg, BGColor: e.scheme.color(editorColorActiveLine)}
 	e.grid.SetRowStyle(activeRow, lineStyle)
 
 	tokens := e.lastTokens
@@ -414,7 +548,7 @@ func (e *coreLXCodeEditor) applySyntaxHighlight(source string) {
 			if tok.Type == corelx.TOKEN_EOF || tok.Line <= 0 || tok.Column <= 0 {
 				continue
 			}
-			style := tokenGridStyle(tok.Type, tok.Literal)
+			style := tokenGridStyle(e.scheme, tok.Type, tok.Literal)
 			length := utf8.RuneCountInString(tok.Literal)
 			if length <= 0 {
 				length = utf8.RuneCountInString(tokenFallbackLiteral(tok.Type))
@@ -453,7 +587,7 @@ func (e *coreLXCodeEditor) applySyntaxHighlight(source string) {
 		if activeCol < 0 {
 			activeCol = 0
 		}
-		cursorStyle := &widget.CustomTextGridStyle{FGColor: theme.ForegroundColor(), BGColor: color.NRGBA{R: 60, G: 72, B: 95, A: 255}}
+		cursorStyle := &widget.CustomTextGridStyle{FGColor: fg, BGColor: e.scheme.color(editorColorCursor)}
 		e.grid.SetStyle(activeRow, activeCol, cursorStyle)
 	}
 
@@ -470,7 +604,7 @@ func (e *coreLXCodeEditor) applySelectionHighlight(lines []string) {
 	}
 	startRow, startCol := e.model.OffsetToLineCol(start)
 	endRow, endCol := e.model.OffsetToLineCol(end)
-	selStyle := &widget.CustomTextGridStyle{FGColor: theme.ForegroundColor(), BGColor: color.NRGBA{R: 54, G: 72, B: 103, A: 255}}
+	selStyle := &widget.CustomTextGridStyle{FGColor: e.scheme.color(editorColorForeground), BGColor: e.scheme.color(editorColorSelection)}
 	for row := startRow; row <= endRow && row < len(lines); row++ {
 		lineLen := utf8.RuneCountInString(lines[row])
 		if lineLen <= 0 {
@@ -496,6 +630,30 @@ func (e *coreLXCodeEditor) applySelectionHighlight(lines []string) {
 	}
 }
 
+// contextWordAt returns the run of word characters and dots around rune
+// column col of line, also when the caret sits just past the word. Leading
+// and trailing dots are dropped.
+func contextWordAt(line string, col int) string {
+	runes := []rune(line)
+	isPart := func(i int) bool {
+		return i >= 0 && i < len(runes) && (isWordRune(runes[i]) || runes[i] == '.')
+	}
+	if !isPart(col) {
+		col--
+	}
+	if !isPart(col) {
+		return ""
+	}
+	start, end := col, col
+	for isPart(start - 1) {
+		start--
+	}
+	for isPart(end + 1) {
+		end++
+	}
+	return strings.Trim(string(runes[start:end+1]), ".")
+}
+
 func isWordRune(r rune) bool {
 	if r >= 'a' && r <= 'z' {
 		return true
@@ -510,12 +668,13 @@ func isWordRune(r rune) bool {
 }
 
 func (e *coreLXCodeEditor) applyLuaStyleSemanticHighlight(lines []string, tokens []corelx.Token, cursorRow, cursorCol int) {
-	functionStyle := &widget.CustomTextGridStyle{TextStyle: fyne.TextStyle{Bold: true}, FGColor: color.NRGBA{R: 0x9A, G: 0xD7, B: 0xEA, A: 0xFF}, BGColor: color.Transparent}
-	localVarStyle := &widget.CustomTextGridStyle{FGColor: color.NRGBA{R: 0x8D, G: 0xE0, B: 0xC1, A: 0xFF}, BGColor: color.Transparent}
-	globalVarStyle := &widget.CustomTextGridStyle{FGColor: color.NRGBA{R: 0x7B, G: 0xC6, B: 0xDE, A: 0xFF}, BGColor: color.Transparent}
-	constantStyle := &widget.CustomTextGridStyle{TextStyle: fyne.TextStyle{Bold: true}, FGColor: color.NRGBA{R: 0xF4, G: 0xBE, B: 0x56, A: 0xFF}, BGColor: color.Transparent}
-	namespaceStyle := &widget.CustomTextGridStyle{TextStyle: fyne.TextStyle{Bold: true}, FGColor: color.NRGBA{R: 0x6D, G: 0xB6, B: 0xFF, A: 0xFF}, BGColor: color.Transparent}
-	typeStyle := &widget.CustomTextGridStyle{FGColor: color.NRGBA{R: 0xCF, G: 0xA8, B: 0xFF, A: 0xFF}, BGColor: color.Transparent}
+	sc := e.scheme
+	functionStyle := &widget.CustomTextGridStyle{TextStyle: fyne.TextStyle{Bold: true}, FGColor: sc.color(editorColorFunction), BGColor: color.Transparent}
+	localVarStyle := &widget.CustomTextGridStyle{FGColor: sc.color(editorColorLocal), BGColor: color.Transparent}
+	globalVarStyle := &widget.CustomTextGridStyle{FGColor: sc.color(editorColorGlobal), BGColor: color.Transparent}
+	constantStyle := &widget.CustomTextGridStyle{TextStyle: fyne.TextStyle{Bold: true}, FGColor: sc.color(editorColorConstant), BGColor: color.Transparent}
+	namespaceStyle := &widget.CustomTextGridStyle{TextStyle: fyne.TextStyle{Bold: true}, FGColor: sc.color(editorColorNamespace), BGColor: color.Transparent}
+	typeStyle := &widget.CustomTextGridStyle{FGColor: sc.color(editorColorType), BGColor: color.Transparent}
 
 	for row, line := range lines {
 		code := line
@@ -543,7 +702,7 @@ func (e *coreLXCodeEditor) applyLuaStyleSemanticHighlight(lines []string, tokens
 
 	functionNames, functionLocals, tokenFunctionCtx := analyzeIdentifierSemantics(tokens)
 	cursorSymbol, cursorSymbolFunc := symbolAtCursor(tokens, tokenFunctionCtx, cursorRow, cursorCol)
-	cursorSymbolStyle := &widget.CustomTextGridStyle{TextStyle: fyne.TextStyle{Bold: true}, FGColor: color.NRGBA{R: 0xFF, G: 0xF2, B: 0xB3, A: 0xFF}, BGColor: color.NRGBA{R: 0x3A, G: 0x3F, B: 0x22, A: 0xFF}}
+	cursorSymbolStyle := &widget.CustomTextGridStyle{TextStyle: fyne.TextStyle{Bold: true}, FGColor: sc.color(editorColorForeground), BGColor: sc.color(editorColorSymbol)}
 
 	for i, tok := range tokens {
 		if tok.Type != corelx.TOKEN_IDENTIFIER || tok.Line <= 0 || tok.Column <= 0 || tok.Literal == "" {
@@ -826,9 +985,9 @@ func (e *coreLXCodeEditor) applySpritePaletteHighlight(source string, lines []st
 			if !ok {
 				continue
 			}
-			fg := theme.ForegroundColor()
+			fg := e.scheme.color(editorColorForeground)
 			if nib == 0 && !paletteSet[bank][nib] {
-				fg = theme.DisabledColor()
+				fg = e.scheme.color(editorColorComment)
 			} else if paletteSet[bank][nib] {
 				fg = paletteColors[bank][nib]
 			}
@@ -975,24 +1134,24 @@ func hexNibbleValue(r rune) (int, bool) {
 	}
 }
 
-func tokenGridStyle(tt corelx.TokenType, literal string) widget.TextGridStyle {
-	fg := theme.ForegroundColor()
+func tokenGridStyle(scheme editorColorScheme, tt corelx.TokenType, literal string) widget.TextGridStyle {
+	fg := scheme.color(editorColorForeground)
 	style := fyne.TextStyle{}
 	switch {
 	case isKeyword(tt):
-		fg = theme.PrimaryColor()
+		fg = scheme.color(editorColorKeyword)
 	case tt == corelx.TOKEN_STRING:
-		fg = color.NRGBA{R: 0xE6, G: 0xA6, B: 0x5E, A: 0xFF}
+		fg = scheme.color(editorColorString)
 	case tt == corelx.TOKEN_NUMBER:
-		fg = color.NRGBA{R: 0xE3, G: 0xD5, B: 0x8B, A: 0xFF}
+		fg = scheme.color(editorColorNumber)
 	case tt == corelx.TOKEN_COMMENT:
-		fg = theme.DisabledColor()
+		fg = scheme.color(editorColorComment)
 		style.Italic = true
 	case tt == corelx.TOKEN_IDENTIFIER && strings.HasPrefix(literal, "SYS_"):
-		fg = theme.Color(theme.ColorNameFocus)
+		fg = scheme.color(editorColorSystem)
 		style.Bold = true
 	case tt == corelx.TOKEN_IDENTIFIER:
-		fg = color.NRGBA{R: 0x8D, G: 0xE0, B: 0xC1, A: 0xFF}
+		fg = scheme.color(editorColorLocal)
 	}
 	return &widget.CustomTextGridStyle{TextStyle: style, FGColor: fg, BGColor: color.Transparent}
 }
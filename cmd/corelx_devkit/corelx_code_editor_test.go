@@ -91,3 +91,29 @@ gfx.set_palette(3, 0, 0x0000)
 		t.Fatalf("expected Slime->3 hint, got %v (ok=%v)", got, ok)
 	}
 }
+
+func TestCoreLXCodeEditorTabInsertsSpacesAndTextSize(t *testing.T) {
+	a := fynetest.NewApp()
+	defer a.Quit()
+
+	editor := newCoreLXCodeEditor()
+	w := fynetest.NewWindow(editor)
+	defer w.Close()
+	w.Resize(fyne.NewSize(640, 480))
+	w.Show()
+
+	fynetest.Tap(editor)
+	editor.TypedKey(&fyne.KeyEvent{Name: fyne.KeyTab})
+	editor.SetTabWidth(2, true)
+	editor.TypedKey(&fyne.KeyEvent{Name: fyne.KeyTab})
+	if got := editor.Text(); got != "\t  " {
+		t.Fatalf("expected tab then two spaces, got %q", got)
+	}
+
+	lineDefault, _, _ := editor.metrics()
+	editor.SetTextSize(24)
+	lineLarge, _, _ := editor.metrics()
+	if lineLarge <= lineDefault {
+		t.Fatalf("expected 24pt lines taller than default, got %v <= %v", lineLarge, lineDefault)
+	}
+}
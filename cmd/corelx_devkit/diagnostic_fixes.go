@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"nitro-core-dx/internal/corelx"
+)
+
+// diagnosticFixBar sits under the diagnostic detail text and applies the
+// selected diagnostic's quick fixes. It is hidden when there are none.
+type diagnosticFixBar struct {
+	root   *fyne.Container
+	choice *widget.Select
+	diag   corelx.Diagnostic
+}
+
+// buildDiagnosticDetailPane is the detail text over the fix bar.
+func (s *devKitState) buildDiagnosticDetailPane() fyne.CanvasObject {
+	bar := &diagnosticFixBar{choice: widget.NewSelect(nil, nil)}
+	s.diagnosticFixes = bar
+	apply := widget.NewButton("Apply Fix", s.applyDiagnosticFix)
+	apply.Importance = widget.HighImportance
+	bar.root = container.NewBorder(nil, nil, nil, apply, bar.choice)
+	bar.root.Hide()
+	return container.NewBorder(nil, bar.root, nil, nil, s.diagnosticDetail)
+}
+
+// showDiagnosticFixes offers d's fixes, best first.
+func (s *devKitState) showDiagnosticFixes(d corelx.Diagnostic) {
+	bar := s.diagnosticFixes
+	if bar == nil {
+		return
+	}
+	bar.diag = d
+	if len(d.Fixes) == 0 {
+		bar.root.Hide()
+		return
+	}
+	titles := make([]string, len(d.Fixes))
+	for i, f := range d.Fixes {
+		titles[i] = f.Title
+	}
+	bar.choice.SetOptions(titles)
+	bar.choice.SetSelectedIndex(0)
+	bar.root.Show()
+}
+
+func (s *devKitState) hideDiagnosticFixes() {
+	if s.diagnosticFixes != nil {
+		s.diagnosticFixes.root.Hide()
+	}
+}
+
+// applyDiagnosticFix applies the chosen fix to the editor and rebuilds so
+// the diagnostics (and their fixes) match the new source. Fix positions
+// refer to the built source, so nothing is applied once it has been edited.
+func (s *devKitState) applyDiagnosticFix() {
+	bar := s.diagnosticFixes
+	i := bar.choice.SelectedIndex()
+	if i < 0 || i >= len(bar.diag.Fixes) {
+		return
+	}
+	text := s.sourceEditor.Text()
+	if text != s.builtSource || bar.diag.File != s.builtSourcePath {
+		s.setStatus("Source changed since the build - rebuild to refresh fixes")
+		return
+	}
+	fix := bar.diag.Fixes[i]
+	fixed, err := corelx.ApplyFix(text, fix)
+	if err != nil {
+		s.setStatus("Fix failed: " + err.Error())
+		return
+	}
+	s.setSourceContent(fixed, true, false)
+	if len(fix.Edits) > 0 {
+		s.sourceEditor.SetCursor(fix.Edits[0].Line-1, fix.Edits[0].Column-1)
+	}
+	s.appendBuildOutput("Applied fix: " + fix.Title)
+	s.runBuild(false)
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"testing"
+
+	fynetest "fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+	"nitro-core-dx/internal/corelx"
+)
+
+func TestDiagnosticFixBarFollowsSelection(t *testing.T) {
+	a := fynetest.NewApp()
+	defer a.Quit()
+
+	s := &devKitState{diagnosticDetail: newReadOnlyTextArea()}
+	s.buildDiagnosticDetailPane()
+
+	s.showDiagnosticFixes(corelx.Diagnostic{Fixes: []corelx.Fix{{Title: "Rename to `wait_vblank`"}, {Title: "Rename to `wait_vbl`"}}})
+	bar := s.diagnosticFixes
+	if !bar.root.Visible() {
+		t.Fatal("fix bar hidden for a diagnostic with fixes")
+	}
+	if bar.choice.Selected != "Rename to `wait_vblank`" || len(bar.choice.Options) != 2 {
+		t.Errorf("choice = %q of %v, want the first fix selected", bar.choice.Selected, bar.choice.Options)
+	}
+
+	s.showDiagnosticFixes(corelx.Diagnostic{})
+	if bar.root.Visible() {
+		t.Error("fix bar shown for a diagnostic without fixes")
+	}
+}
+
+func TestApplyDiagnosticFixRefusesEditedSource(t *testing.T) {
+	a := fynetest.NewApp()
+	defer a.Quit()
+
+	s := &devKitState{
+		diagnosticDetail: newReadOnlyTextArea(),
+		sourceEditor:     newCoreLXCodeEditor(),
+		statusLabel:      widget.NewLabel(""),
+	}
+	s.buildDiagnosticDetailPane()
+	source := "function Start()\n    wait_vblnk()\n"
+	res, _ := corelx.CompileSource(source, "main.corelx", nil)
+	if len(res.Diagnostics) == 0 || len(res.Diagnostics[0].Fixes) == 0 {
+		t.Fatalf("no fix offered: %+v", res.Diagnostics)
+	}
+	s.builtSource, s.builtSourcePath = source, "main.corelx"
+	s.showDiagnosticFixes(res.Diagnostics[0])
+
+	edited := source + "-- edited after the build\n"
+	s.sourceEditor.SetText(edited)
+	s.applyDiagnosticFix()
+	if got := s.sourceEditor.Text(); got != edited {
+		t.Errorf("fix applied to edited source: %q", got)
+	}
+}
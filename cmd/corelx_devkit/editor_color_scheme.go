@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+)
+
+// editorColorRole names one code editor color. The string is the key used in
+// devKitSettings.EditorColors.
+type editorColorRole string
+
+const (
+	editorColorBackground editorColorRole = "background"
+	editorColorForeground editorColorRole = "foreground"
+	editorColorKeyword    editorColorRole = "keyword"
+	editorColorString     editorColorRole = "string"
+	editorColorNumber     editorColorRole = "number"
+	editorColorComment    editorColorRole = "comment"
+	editorColorFunction   editorColorRole = "function"
+	editorColorLocal      editorColorRole = "local"
+	editorColorGlobal     editorColorRole = "global"
+	editorColorConstant   editorColorRole = "constant"
+	editorColorNamespace  editorColorRole = "namespace"
+	editorColorType       editorColorRole = "type"
+	editorColorSystem     editorColorRole = "system"
+	editorColorActiveLine editorColorRole = "active_line"
+	editorColorSelection  editorColorRole = "selection"
+	editorColorCursor     editorColorRole = "cursor"
+	editorColorSymbol     editorColorRole = "symbol"
+)
+
+// editorColorRoles lists the roles in the order the color editor shows them.
+var editorColorRoles = []struct {
+	role  editorColorRole
+	label string
+}{
+	{editorColorBackground, "Background"},
+	{editorColorForeground, "Text"},
+	{editorColorKeyword, "Keywords"},
+	{editorColorString, "Strings"},
+	{editorColorNumber, "Numbers"},
+	{editorColorComment, "Comments"},
+	{editorColorFunction, "Functions"},
+	{editorColorLocal, "Local variables"},
+	{editorColorGlobal, "Globals"},
+	{editorColorConstant, "Constants (ASSET_/SPR_)"},
+	{editorColorNamespace, "API namespaces"},
+	{editorColorType, "Types"},
+	{editorColorSystem, "SYS_ constants"},
+	{editorColorActiveLine, "Active line"},
+	{editorColorSelection, "Selection"},
+	{editorColorCursor, "Cursor"},
+	{editorColorSymbol, "Symbol under cursor"},
+}
+
+// Editor color scheme presets (devKitSettings.EditorColorScheme).
+const (
+	editorSchemeDark  = "dark"
+	editorSchemeLight = "light"
+)
+
+// editorColorScheme maps every role to a color. Use resolveEditorColorScheme
+// to build one; lookups of a missing role fall back to the dark preset.
+type editorColorScheme map[editorColorRole]color.NRGBA
+
+func rgb(r, g, b uint8) color.NRGBA { return color.NRGBA{R: r, G: g, B: b, A: 0xFF} }
+
+var editorSchemePresets = map[string]editorColorScheme{
+	editorSchemeDark: {
+		editorColorBackground: rgb(12, 14, 18),
+		editorColorForeground: rgb(0xE8, 0xEA, 0xED),
+		editorColorKeyword:    rgb(0x56, 0x9C, 0xF6),
+		editorColorString:     rgb(0xE6, 0xA6, 0x5E),
+		editorColorNumber:     rgb(0xE3, 0xD5, 0x8B),
+		editorColorComment:    rgb(0x7A, 0x80, 0x8A),
+		editorColorFunction:   rgb(0x9A, 0xD7, 0xEA),
+		editorColorLocal:      rgb(0x8D, 0xE0, 0xC1),
+		editorColorGlobal:     rgb(0x7B, 0xC6, 0xDE),
+		editorColorConstant:   rgb(0xF4, 0xBE, 0x56),
+		editorColorNamespace:  rgb(0x6D, 0xB6, 0xFF),
+		editorColorType:       rgb(0xCF, 0xA8, 0xFF),
+		editorColorSystem:     rgb(0x8F, 0xB8, 0xFA),
+		editorColorActiveLine: rgb(23, 26, 33),
+		editorColorSelection:  rgb(54, 72, 103),
+		editorColorCursor:     rgb(60, 72, 95),
+		editorColorSymbol:     rgb(0x3A, 0x3F, 0x22),
+	},
+	editorSchemeLight: {
+		editorColorBackground: rgb(0xFA, 0xFA, 0xF7),
+		editorColorForeground: rgb(0x24, 0x29, 0x2E),
+		editorColorKeyword:    rgb(0x00, 0x4F, 0xC4),
+		editorColorString:     rgb(0xA3, 0x4A, 0x00),
+		editorColorNumber:     rgb(0x7A, 0x5C, 0x00),
+		editorColorComment:    rgb(0x6A, 0x73, 0x7D),
+		editorColorFunction:   rgb(0x6F, 0x42, 0xC1),
+		editorColorLocal:      rgb(0x0B, 0x6E, 0x4F),
+		editorColorGlobal:     rgb(0x00, 0x5C, 0x7A),
+		editorColorConstant:   rgb(0xB3, 0x5C, 0x00),
+		editorColorNamespace:  rgb(0x00, 0x5C, 0xC5),
+		editorColorType:       rgb(0x8A, 0x3F, 0xB8),
+		editorColorSystem:     rgb(0x1F, 0x5F, 0xBF),
+		editorColorActiveLine: rgb(0xEE, 0xF1, 0xF6),
+		editorColorSelection:  rgb(0xC8, 0xDA, 0xF8),
+		editorColorCursor:     rgb(0xAF, 0xC4, 0xE8),
+		editorColorSymbol:     rgb(0xF5, 0xEC, 0xB5),
+	},
+}
+
+// color returns the color for role.
+func (s editorColorScheme) color(role editorColorRole) color.NRGBA {
+	if c, ok := s[role]; ok {
+		return c
+	}
+	return editorSchemePresets[editorSchemeDark][role]
+}
+
+// dark reports whether the scheme's background is dark, which decides the
+// Fyne color variant used for the editor's scroll bars and default text.
+func (s editorColorScheme) dark() bool {
+	bg := s.color(editorColorBackground)
+	return 299*int(bg.R)+587*int(bg.G)+114*int(bg.B) < 128*1000
+}
+
+// resolveEditorColorScheme starts from the named preset (dark when unknown)
+// and applies "#RRGGBB" overrides keyed by role name. Unknown roles and
+// malformed colors are ignored.
+func resolveEditorColorScheme(preset string, overrides map[string]string) editorColorScheme {
+	base, ok := editorSchemePresets[preset]
+	if !ok {
+		base = editorSchemePresets[editorSchemeDark]
+	}
+	out := make(editorColorScheme, len(base))
+	for role, c := range base {
+		out[role] = c
+	}
+	for key, value := range overrides {
+		role := editorColorRole(key)
+		if _, known := base[role]; !known {
+			continue
+		}
+		if c, ok := parseHexColor(value); ok {
+			out[role] = c
+		}
+	}
+	return out
+}
+
+// parseHexColor parses "#RRGGBB" or "RRGGBB".
+func parseHexColor(text string) (color.NRGBA, bool) {
+	text = strings.TrimPrefix(strings.TrimSpace(text), "#")
+	if len(text) != 6 {
+		return color.NRGBA{}, false
+	}
+	v, err := strconv.ParseUint(text, 16, 32)
+	if err != nil {
+		return color.NRGBA{}, false
+	}
+	return rgb(uint8(v>>16), uint8(v>>8), uint8(v)), true
+}
+
+func formatHexColor(c color.NRGBA) string {
+	return fmt.Sprintf("#%02X%02X%02X", c.R, c.G, c.B)
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	fynetest "fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"nitro-core-dx/internal/corelx"
+)
+
+func TestResolveEditorColorSchemeOverrides(t *testing.T) {
+	scheme := resolveEditorColorScheme(editorSchemeLight, map[string]string{
+		"keyword":    "#112233",
+		"string":     "A0B0C0",
+		"number":     "#XYZ123",
+		"not_a_role": "#FFFFFF",
+	})
+	if got := scheme.color(editorColorKeyword); got != rgb(0x11, 0x22, 0x33) {
+		t.Fatalf("keyword override = %v", got)
+	}
+	if got := scheme.color(editorColorString); got != rgb(0xA0, 0xB0, 0xC0) {
+		t.Fatalf("string override without # = %v", got)
+	}
+	light := editorSchemePresets[editorSchemeLight]
+	if got := scheme.color(editorColorNumber); got != light[editorColorNumber] {
+		t.Fatalf("malformed override should keep preset, got %v", got)
+	}
+	if _, ok := scheme["not_a_role"]; ok {
+		t.Fatalf("unknown role should be ignored")
+	}
+	if scheme.dark() {
+		t.Fatalf("light preset should not report dark")
+	}
+	if !resolveEditorColorScheme("bogus", nil).dark() {
+		t.Fatalf("unknown preset should fall back to dark")
+	}
+	for _, spec := range editorColorRoles {
+		for name, preset := range editorSchemePresets {
+			if _, ok := preset[spec.role]; !ok {
+				t.Fatalf("preset %q missing role %q", name, spec.role)
+			}
+		}
+	}
+}
+
+func TestHexColorRoundTrip(t *testing.T) {
+	c := rgb(0x0A, 0xBC, 0xEF)
+	got, ok := parseHexColor(formatHexColor(c))
+	if !ok || got != c || formatHexColor(c) != "#0ABCEF" {
+		t.Fatalf("round trip failed: %q -> %v %v", formatHexColor(c), got, ok)
+	}
+	for _, bad := range []string{"", "#12345", "#1234567", "#GG0000"} {
+		if _, ok := parseHexColor(bad); ok {
+			t.Fatalf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestTokenGridStyleUsesScheme(t *testing.T) {
+	scheme := resolveEditorColorScheme(editorSchemeDark, map[string]string{"keyword": "#010203", "comment": "#040506"})
+	kw := tokenGridStyle(scheme, corelx.TOKEN_WHILE, "while").(*widget.CustomTextGridStyle)
+	if kw.FGColor != rgb(1, 2, 3) {
+		t.Fatalf("keyword color = %v", kw.FGColor)
+	}
+	cm := tokenGridStyle(scheme, corelx.TOKEN_COMMENT, "-- x").(*widget.CustomTextGridStyle)
+	if cm.FGColor != rgb(4, 5, 6) || !cm.TextStyle.Italic {
+		t.Fatalf("comment style = %+v", cm)
+	}
+}
+
+func TestCoreLXCodeEditorSetColorScheme(t *testing.T) {
+	a := fynetest.NewApp()
+	defer a.Quit()
+
+	editor := newCoreLXCodeEditor()
+	w := fynetest.NewWindow(editor)
+	defer w.Close()
+	w.Show()
+
+	scheme := resolveEditorColorScheme(editorSchemeLight, nil)
+	editor.SetColorScheme(scheme)
+	if editor.background.FillColor != scheme.color(editorColorBackground) {
+		t.Fatalf("background not recolored: %v", editor.background.FillColor)
+	}
+	if editor.fontTheme.Color(theme.ColorNameForeground, theme.VariantDark) != scheme.color(editorColorForeground) {
+		t.Fatalf("editor theme foreground not taken from scheme")
+	}
+}
+
+func TestEditorColorsPersistInSettings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	in := defaultDevKitSettings()
+	in.EditorColorScheme = editorSchemeLight
+	in.EditorColors = map[string]string{"background": "#101010"}
+	if err := saveDevKitSettings(path, in); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	out, err := loadDevKitSettings(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if out.EditorColorScheme != editorSchemeLight || out.EditorColors["background"] != "#101010" {
+		t.Fatalf("editor colors not persisted: %q %v", out.EditorColorScheme, out.EditorColors)
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"image/color"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// showEditorColorsDialog edits the code editor color scheme. Each role has a
+// swatch, a hex entry and a color picker; changes recolor the editor
+// immediately and are stored as overrides on top of the selected preset.
+func (s *devKitState) showEditorColorsDialog() {
+	type colorRow struct {
+		role   editorColorRole
+		swatch *canvas.Rectangle
+		entry  *widget.Entry
+	}
+	rows := make([]*colorRow, 0, len(editorColorRoles))
+	syncing := false
+	syncRows := func() {
+		scheme := resolveEditorColorScheme(s.settings.EditorColorScheme, s.settings.EditorColors)
+		syncing = true
+		for _, row := range rows {
+			c := scheme.color(row.role)
+			row.swatch.FillColor = c
+			row.swatch.Refresh()
+			row.entry.SetText(formatHexColor(c))
+		}
+		syncing = false
+	}
+
+	grid := container.NewGridWithColumns(4)
+	for _, spec := range editorColorRoles {
+		row := &colorRow{
+			role:   spec.role,
+			swatch: canvas.NewRectangle(color.Transparent),
+			entry:  widget.NewEntry(),
+		}
+		row.swatch.SetMinSize(fyne.NewSize(36, 20))
+		row.swatch.StrokeColor = color.NRGBA{R: 0x80, G: 0x80, B: 0x80, A: 0xFF}
+		row.swatch.StrokeWidth = 1
+		row.entry.OnChanged = func(text string) {
+			if syncing {
+				return
+			}
+			if c, ok := parseHexColor(text); ok {
+				row.swatch.FillColor = c
+				row.swatch.Refresh()
+				s.setEditorColor(row.role, c)
+			}
+		}
+		pick := widget.NewButton("Pick...", func() {
+			picker := dialog.NewColorPicker("Editor Color", spec.label, func(c color.Color) {
+				nc := color.NRGBAModel.Convert(c).(color.NRGBA)
+				nc.A = 0xFF
+				row.entry.SetText(formatHexColor(nc))
+			}, s.window)
+			picker.Advanced = true
+			picker.SetColor(row.swatch.FillColor)
+			picker.Show()
+		})
+		rows = append(rows, row)
+		grid.Add(widget.NewLabel(spec.label))
+		grid.Add(row.swatch)
+		grid.Add(row.entry)
+		grid.Add(pick)
+	}
+
+	presetSelect := newPreferenceSelect(preferenceEditorSchemes, s.settings.EditorColorScheme, formatOption, func(name string) {
+		s.setEditorColorScheme(name)
+		syncRows()
+	})
+	resetBtn := widget.NewButton("Reset to Preset", func() {
+		s.settings.EditorColors = nil
+		s.applyEditorPreferences()
+		s.persistSettings()
+		syncRows()
+		s.setStatus("Editor colors reset to " + s.settings.EditorColorScheme + " preset")
+	})
+	syncRows()
+
+	top := container.NewBorder(nil, nil, widget.NewLabel("Preset"), resetBtn, presetSelect)
+	content := container.NewBorder(top, nil, nil, nil, container.NewVScroll(grid))
+	d := dialog.NewCustom("Editor Colors", "Close", content, s.window)
+	d.Resize(fyne.NewSize(620, 600))
+	d.Show()
+}
+
+func (s *devKitState) setEditorColorScheme(name string) {
+	s.settings.EditorColorScheme = name
+	s.applyEditorPreferences()
+	s.persistSettings()
+	s.setStatus("Editor colors: " + name)
+}
+
+// setEditorColor overrides one role. A color equal to the preset's removes
+// the override so the settings file only carries real customizations.
+func (s *devKitState) setEditorColor(role editorColorRole, c color.NRGBA) {
+	preset := resolveEditorColorScheme(s.settings.EditorColorScheme, nil)
+	if preset.color(role) == c {
+		delete(s.settings.EditorColors, string(role))
+	} else {
+		if s.settings.EditorColors == nil {
+			s.settings.EditorColors = map[string]string{}
+		}
+		s.settings.EditorColors[string(role)] = formatHexColor(c)
+	}
+	s.applyEditorPreferences()
+	s.persistSettings()
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"nitro-core-dx/internal/emulator"
+)
+
+// disassemblyContext is how many instructions the disassembly dialog shows
+// on each side of the PC.
+const disassemblyContext = 12
+
+// faultCard sits above the debugger output and describes the last emulator
+// fault: a one-line summary, the full report behind an expander, and
+// actions to copy the report or open the faulting PC in the disassembly.
+type faultCard struct {
+	root   *fyne.Container
+	card   *widget.Card
+	report *widget.Entry
+	fault  *emulator.Fault
+}
+
+// formatDisassembly renders lines one per row, marking the one at
+// bank:offset with "=>".
+func formatDisassembly(lines []emulator.CodeLine, bank uint8, offset uint16) string {
+	var sb strings.Builder
+	for _, l := range lines {
+		marker := "  "
+		if l.Bank == bank && l.Offset == offset {
+			marker = "=>"
+		}
+		sb.WriteString(marker + " " + l.String() + "\n")
+	}
+	return sb.String()
+}
+
+// faultTitle is the card title, e.g. "CPU fault" or "PPU panic".
+func faultTitle(f *emulator.Fault) string {
+	if f.Panic {
+		return f.Component + " panic"
+	}
+	return f.Component + " fault"
+}
+
+// debuggerContent is what the Debugger tab (or its pop-out window) shows:
+// the fault card over the register/PC output.
+func (s *devKitState) debuggerContent() fyne.CanvasObject {
+	if s.debuggerPane == nil {
+		return s.debuggerOutput
+	}
+	return s.debuggerPane
+}
+
+func (s *devKitState) buildDebuggerPane() fyne.CanvasObject {
+	fc := &faultCard{}
+	s.faultCard = fc
+
+	fc.report = newReadOnlyTextArea()
+	fc.report.SetMinRowsVisible(10)
+	details := widget.NewAccordion(widget.NewAccordionItem("Report", fc.report))
+	copyBtn := widget.NewButton("Copy Report", func() {
+		if fc.fault != nil && s.window != nil && s.window.Clipboard() != nil {
+			s.window.Clipboard().SetContent(fc.fault.Report())
+			s.setStatus("Fault report copied")
+		}
+	})
+	openBtn := widget.NewButton("Open at PC in Disassembly", func() {
+		if fc.fault != nil {
+			s.showDisassembly(fc.fault.PCBank, fc.fault.PCOffset)
+		}
+	})
+	dismissBtn := widget.NewButton("Dismiss", func() { s.clearFault() })
+
+	fc.card = widget.NewCard("", "", container.NewVBox(
+		details,
+		container.NewHBox(copyBtn, openBtn, dismissBtn),
+	))
+	fc.root = container.NewStack(fc.card)
+	fc.root.Hide()
+	stepBackBtn := widget.NewButton("Step Back", func() { s.stepBack() })
+	toolbar := container.NewHBox(stepBackBtn, widget.NewLabel("Undo the last Step C / Step F steps while paused"))
+	s.debuggerPane = container.NewBorder(container.NewVBox(fc.root, toolbar), nil, nil, nil, s.debuggerOutput)
+	return s.debuggerPane
+}
+
+// reportEmulatorError surfaces an error from running the emulator: a fault
+// opens the fault card, anything else goes to the Output pane.
+func (s *devKitState) reportEmulatorError(context string, err error) {
+	var fault *emulator.Fault
+	if errors.As(err, &fault) {
+		s.showFault(fault)
+		return
+	}
+	s.appendBuildOutput(context + ": " + err.Error())
+	s.setStatus(context)
+}
+
+// showFault fills in the fault card and brings the Debugger tab forward.
+// The backend has already paused the emulator.
+func (s *devKitState) showFault(f *emulator.Fault) {
+	s.appendBuildOutput("Emulator " + f.Error())
+	s.setStatus(faultTitle(f) + " - emulator paused")
+	fc := s.faultCard
+	if fc == nil {
+		return
+	}
+	fc.fault = f
+	fc.card.SetTitle(faultTitle(f))
+	fc.card.SetSubTitle(f.Error())
+	fc.report.SetText(f.Report())
+	fc.root.Show()
+	if s.debugWindow != nil {
+		s.debugWindow.RequestFocus()
+	} else {
+		s.showBottomTab(debuggerTabName)
+	}
+	s.refreshDebuggerOutput()
+}
+
+func (s *devKitState) clearFault() {
+	if s.faultCard == nil {
+		return
+	}
+	s.faultCard.fault = nil
+	s.faultCard.root.Hide()
+}
+
+// showDisassemblyAtPC opens the disassembly at the CPU's current PC.
+func (s *devKitState) showDisassemblyAtPC() {
+	pc := s.backend.GetPCState()
+	if !pc.Loaded {
+		s.setStatus("No active project build")
+		return
+	}
+	s.showDisassembly(pc.PCBank, pc.PCOffset)
+}
+
+// showDisassembly shows the code around bank:offset with that line marked.
+func (s *devKitState) showDisassembly(bank uint8, offset uint16) {
+	lines, err := s.backend.Disassemble(bank, offset, disassemblyContext, disassemblyContext)
+	if err != nil {
+		s.setStatus("Disassembly: " + err.Error())
+		return
+	}
+	text := newReadOnlyTextArea()
+	text.SetText(formatDisassembly(lines, bank, offset))
+	text.SetMinRowsVisible(2*disassemblyContext + 1)
+	title := fmt.Sprintf("Disassembly at %02X:%04X", bank, offset)
+	d := dialog.NewCustom(title, "Close", container.NewScroll(text), s.window)
+	d.Resize(fyne.NewSize(640, 520))
+	d.Show()
+}
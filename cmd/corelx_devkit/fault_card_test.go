@@ -0,0 +1,34 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"nitro-core-dx/internal/emulator"
+)
+
+func TestFormatDisassemblyMarksPC(t *testing.T) {
+	lines := []emulator.CodeLine{
+		{Bank: 1, Offset: 0x8000, Text: "MOV R1, #0x0001", Symbol: "Start"},
+		{Bank: 1, Offset: 0x8004, Text: "NOP", Symbol: "Start+0x4"},
+	}
+	got := strings.Split(strings.TrimRight(formatDisassembly(lines, 1, 0x8004), "\n"), "\n")
+	if len(got) != 2 {
+		t.Fatalf("got %d rows, want 2: %q", len(got), got)
+	}
+	if !strings.HasPrefix(got[0], "   01:8000") || !strings.HasSuffix(got[0], "; Start") {
+		t.Errorf("row 0 = %q", got[0])
+	}
+	if !strings.HasPrefix(got[1], "=> 01:8004") {
+		t.Errorf("row 1 = %q, want the PC marker", got[1])
+	}
+}
+
+func TestFaultTitle(t *testing.T) {
+	if got := faultTitle(&emulator.Fault{Component: "CPU"}); got != "CPU fault" {
+		t.Errorf("faultTitle = %q, want CPU fault", got)
+	}
+	if got := faultTitle(&emulator.Fault{Component: "PPU", Panic: true}); got != "PPU panic" {
+		t.Errorf("faultTitle = %q, want PPU panic", got)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/widget"
+	"nitro-core-dx/internal/i18n"
 )
 
 type helpDocItem struct {
@@ -37,6 +38,7 @@ var helpDocCatalog = []helpDocItem{
 
 	{"Hardware & Audio", "Hardware Spec v2.1", "docs/specifications/COMPLETE_HARDWARE_SPECIFICATION_V2.1.md", "Current source-of-truth hardware specification."},
 	{"Hardware & Audio", "APU FM OPM Extension Spec", "docs/specifications/APU_FM_OPM_EXTENSION_SPEC.md", "FM extension design and current implementation status."},
+	{"Hardware & Audio", "Memory Map", "docs/MEMORY_MAP.md", "Address space and every I/O register with its fields, generated from the emulator's register table."},
 	{"Hardware & Audio", "Hardware Features Status", "docs/HARDWARE_FEATURES_STATUS.md", "Implementation status snapshot across major subsystems."},
 
 	{"Testing & Project", "Testing Guide", "docs/testing/README.md", "Current test commands, tiers, and practical test workflows."},
@@ -46,125 +48,130 @@ var helpDocCatalog = []helpDocItem{
 }
 
 func (s *devKitState) buildMainMenu() *fyne.MainMenu {
-	fileMenu := fyne.NewMenu("File",
-		fyne.NewMenuItem("New Project", func() {
-			s.showTemplateDialog()
-		}),
-		fyne.NewMenuItem("Open Project...", func() {
-			s.showOpenProjectDialog()
-		}),
-		fyne.NewMenuItem("Load ROM...", func() {
-			s.openROMDialog()
-		}),
-		fyne.NewMenuItemSeparator(),
-		fyne.NewMenuItem("Save", func() {
-			if err := s.save(); err != nil {
-				dialog.ShowError(err, s.window)
-			}
-		}),
-		fyne.NewMenuItem("Save As...", func() {
-			s.saveAsDialog()
-		}),
-		fyne.NewMenuItemSeparator(),
-		fyne.NewMenuItem("Recover Autosave", func() {
-			s.tryRecoverAutosave()
-		}),
-		fyne.NewMenuItemSeparator(),
+	item := s.commandMenuItem
+	sep := fyne.NewMenuItemSeparator
+	fileMenu := fyne.NewMenu(i18n.T("File"),
+		item("file.new"),
+		item("file.new_window"),
+		item("file.open"),
+		item("file.load_rom"),
+		sep(),
+		item("file.save"),
+		item("file.save_as"),
+		sep(),
+		item("file.export_vram"),
+		item("file.export_cgram"),
+		item("file.import_bg8"),
+		sep(),
+		item("file.package_share"),
+		sep(),
+		item("file.recover_autosave"),
+		sep(),
 		s.buildRecentFilesMenuItem(),
 	)
 
-	editMenu := fyne.NewMenu("Edit",
-		disabledMenuItem("Undo"),
-		disabledMenuItem("Redo"),
+	editMenu := fyne.NewMenu(i18n.T("Edit"),
+		disabledMenuItem(i18n.T("Undo")),
+		disabledMenuItem(i18n.T("Redo")),
 		fyne.NewMenuItemSeparator(),
-		disabledMenuItem("Find"),
-		disabledMenuItem("Find Next"),
+		disabledMenuItem(i18n.T("Find")),
+		disabledMenuItem(i18n.T("Find Next")),
 	)
 
-	viewMenu := fyne.NewMenu("View",
-		fyne.NewMenuItem("Code Only", func() {
-			s.setViewMode(viewModeCodeOnly)
-		}),
-		fyne.NewMenuItem("Split View", func() {
-			s.setViewMode(viewModeFull)
-		}),
-		fyne.NewMenuItem("Emulator Focus", func() {
-			s.setViewMode(viewModeEmulatorOnly)
-		}),
+	popOutEmulator := item("view.popout_emulator")
+	popOutEmulator.Checked = s.emuWindow != nil
+	popOutDebugger := item("view.popout_debugger")
+	popOutDebugger.Checked = s.debugWindow != nil
+	viewMenu := fyne.NewMenu(i18n.T("View"),
+		item("view.command_palette"),
+		sep(),
+		item("view.code_only"),
+		item("view.split"),
+		item("view.emulator_focus"),
+		sep(),
+		popOutEmulator,
+		popOutDebugger,
+		sep(),
+		item("view.toggle_diagnostics"),
+		item("view.toggle_capture"),
+		sep(),
+		item("view.focus_editor"),
+		item("view.focus_emulator"),
+		item("view.focus_diagnostics"),
+		item("view.next_diagnostic"),
+		item("view.prev_diagnostic"),
+		item("view.describe_focus"),
 	)
 
-	buildMenu := fyne.NewMenu("Build",
-		fyne.NewMenuItem("Build", func() {
-			s.runBuild(false)
-		}),
-		fyne.NewMenuItem("Build + Run", func() {
-			s.runBuild(true)
-		}),
+	buildMenu := fyne.NewMenu(i18n.T("Build"),
+		item("build.build"),
+		item("build.run"),
+		sep(),
+		item("build.format"),
+		item("build.run_configs"),
+		sep(),
+		item("build.diff_previous"),
 	)
 
-	debugMenu := fyne.NewMenu("Debug",
-		fyne.NewMenuItem("Run", func() {
-			s.runEmulator()
-		}),
-		fyne.NewMenuItem("Pause", func() {
-			s.pauseEmulator()
-		}),
-		fyne.NewMenuItem("Stop", func() {
-			s.stopEmulator()
-		}),
-		fyne.NewMenuItemSeparator(),
-		fyne.NewMenuItem("Step Frame", func() {
-			s.stepFrame()
-		}),
-		fyne.NewMenuItem("Step CPU", func() {
-			s.stepCPU()
-		}),
-		fyne.NewMenuItemSeparator(),
-		fyne.NewMenuItem("Hardware Reset", func() {
-			s.hardwareReset()
-		}),
+	deterministicItem := item("debug.deterministic")
+	deterministicItem.Checked = s.backend.Deterministic()
+	priorityViewItem := item("debug.priority_view")
+	priorityViewItem.Checked = s.backend.PriorityView()
+	inputDisplayItem := item("debug.input_display")
+	inputDisplayItem.Checked = s.backend.InputDisplay()
+	debugMenu := fyne.NewMenu(i18n.T("Debug"),
+		item("debug.run"),
+		item("debug.pause"),
+		item("debug.stop"),
+		sep(),
+		item("debug.step_frame"),
+		item("debug.step_cpu"),
+		item("debug.step_back"),
+		item("debug.mark_frame"),
+		item("debug.console"),
+		item("debug.io_registers"),
+		item("debug.logging"),
+		item("debug.disassembly"),
+		sep(),
+		item("debug.macro_record"),
+		item("debug.macro_play"),
+		item("debug.movie_record"),
+		item("debug.movie_play"),
+		item("debug.movie_stop"),
+		sep(),
+		item("debug.soft_reset"),
+		item("debug.reset"),
+		sep(),
+		deterministicItem,
+		priorityViewItem,
+		inputDisplayItem,
 	)
 
-	toolsMenu := fyne.NewMenu("Tools",
-		fyne.NewMenuItem("Layout: Balanced", func() {
-			s.applyLayoutPreset(layoutPresetBalanced)
-		}),
-		fyne.NewMenuItem("Layout: Code Focus", func() {
-			s.applyLayoutPreset(layoutPresetCodeFocus)
-		}),
-		fyne.NewMenuItem("Layout: Art Mode", func() {
-			s.applyLayoutPreset(layoutPresetArtMode)
-		}),
-		fyne.NewMenuItem("Layout: Debug Mode", func() {
-			s.applyLayoutPreset(layoutPresetDebugMode)
-		}),
-		fyne.NewMenuItem("Layout: Emulator Focus", func() {
-			s.applyLayoutPreset(layoutPresetEmulatorFocus)
-		}),
-		fyne.NewMenuItemSeparator(),
-		fyne.NewMenuItem("UI Density: Compact", func() {
-			s.setUIDensity("compact")
-		}),
-		fyne.NewMenuItem("UI Density: Standard", func() {
-			s.setUIDensity("standard")
-		}),
+	toolsMenu := fyne.NewMenu(i18n.T("Tools"),
+		item("tools.preferences"),
+		item("tools.keymap"),
+		sep(),
+		item("tools.layout_balanced"),
+		item("tools.layout_code"),
+		item("tools.layout_art"),
+		item("tools.layout_debug"),
+		item("tools.layout_emulator"),
+		sep(),
+		item("tools.density_compact"),
+		item("tools.density_standard"),
+		sep(),
+		item("tools.theme_system"),
+		item("tools.theme_dark"),
+		item("tools.theme_light"),
+		item("tools.editor_colors"),
 	)
 
-	helpMenu := fyne.NewMenu("Help",
-		fyne.NewMenuItem("Help Center", func() {
-			s.showHelpCenter()
-		}),
-		fyne.NewMenuItem("Open Docs on GitHub", func() {
-			s.openExternalURL("https://github.com/RetroCodeRamen/Nitro-Core-DX/tree/main/docs")
-		}),
-		fyne.NewMenuItemSeparator(),
-		fyne.NewMenuItem("About Nitro-Core-DX", func() {
-			dialog.ShowInformation(
-				"About Nitro-Core-DX",
-				"Nitro-Core-DX is a project-centric SDK with an integrated emulator subsystem.\n\nUse Build + Run for the primary workflow. Code Only hides the emulator for focused development. Split View shows code and hardware output side by side. Emulator Focus isolates hardware output testing.\n\nWindow maximize/restore is handled by your operating system title bar controls.",
-				s.window,
-			)
-		}),
+	helpMenu := fyne.NewMenu(i18n.T("Help"),
+		item("help.center"),
+		item("help.reference"),
+		item("help.docs"),
+		sep(),
+		item("help.about"),
 	)
 	return fyne.NewMainMenu(fileMenu, editMenu, viewMenu, buildMenu, debugMenu, toolsMenu, helpMenu)
 }
@@ -176,10 +183,10 @@ func disabledMenuItem(label string) *fyne.MenuItem {
 }
 
 func (s *devKitState) buildRecentFilesMenuItem() *fyne.MenuItem {
-	item := fyne.NewMenuItem("Open Recent", nil)
-	recentMenu := fyne.NewMenu("Open Recent")
+	item := fyne.NewMenuItem(i18n.T("Open Recent"), nil)
+	recentMenu := fyne.NewMenu(i18n.T("Open Recent"))
 	if len(s.settings.RecentFiles) == 0 {
-		empty := fyne.NewMenuItem("(none)", nil)
+		empty := fyne.NewMenuItem(i18n.T("(none)"), nil)
 		empty.Disabled = true
 		recentMenu.Items = []*fyne.MenuItem{empty}
 		item.ChildMenu = recentMenu
@@ -191,19 +198,23 @@ func (s *devKitState) buildRecentFilesMenuItem() *fyne.MenuItem {
 		p := path
 		label := baseNameOr(p, p)
 		recentMenu.Items = append(recentMenu.Items, fyne.NewMenuItem(label, func() {
-			if err := s.loadFile(p, true); err != nil {
-				dialog.ShowError(err, s.window)
-				s.appendBuildOutput("Open recent failed: " + err.Error())
-				s.setStatus("Open recent failed")
-				return
-			}
-			s.setStatus("Opened " + baseNameOr(p, p))
+			s.openRecentFile(p)
 		}))
 	}
 	item.ChildMenu = recentMenu
 	return item
 }
 
+func (s *devKitState) openRecentFile(path string) {
+	if err := s.loadFile(path, true); err != nil {
+		dialog.ShowError(err, s.window)
+		s.appendBuildOutput("Open recent failed: " + err.Error())
+		s.setStatus("Open recent failed")
+		return
+	}
+	s.setStatus("Opened " + baseNameOr(path, path))
+}
+
 func (s *devKitState) showHelpCenter() {
 	w := fyne.CurrentApp().NewWindow("Nitro-Core-DX Help Center")
 	w.Resize(fyne.NewSize(1200, 820))
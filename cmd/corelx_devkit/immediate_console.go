@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"nitro-core-dx/internal/corelx"
+	"nitro-core-dx/internal/devkit"
+)
+
+// immediateConsoleHistoryLimit caps the Console tab's recall list.
+const immediateConsoleHistoryLimit = 50
+
+// formatImmediateTranscript renders one console evaluation: the echoed
+// input, then the value, a statement's instruction count, or the error.
+func formatImmediateTranscript(input string, res devkit.ImmediateResult, err error) string {
+	var sb strings.Builder
+	for i, line := range strings.Split(strings.TrimRight(input, "\n"), "\n") {
+		if i == 0 {
+			sb.WriteString("> " + line + "\n")
+		} else {
+			sb.WriteString(". " + line + "\n")
+		}
+	}
+	printed := false
+	for _, d := range res.Diagnostics {
+		if err == nil || d.Severity != corelx.SeverityError {
+			continue
+		}
+		printed = true
+		if d.Line > 0 {
+			sb.WriteString(fmt.Sprintf("! line %d: %s\n", d.Line, d.Message))
+		} else {
+			sb.WriteString("! " + d.Message + "\n")
+		}
+	}
+	switch {
+	case printed:
+	case err != nil:
+		sb.WriteString("! " + err.Error() + "\n")
+	case res.HasValue:
+		sb.WriteString(fmt.Sprintf("= 0x%04X (%d, signed %d)\n", res.Value, res.Value, int16(res.Value)))
+	default:
+		sb.WriteString(fmt.Sprintf("ok (%d instructions, %d cycles)\n", res.Instructions, res.Cycles))
+	}
+	return sb.String()
+}
+
+// buildImmediateConsolePane builds the Console tab: CoreLX typed here runs
+// against the paused machine, and text the ROM writes to the test port is
+// shown as it arrives.
+func (s *devKitState) buildImmediateConsolePane() fyne.CanvasObject {
+	transcript := newReadOnlyTextArea()
+	transcript.SetText("CoreLX immediate console. Pause the emulator, then run statements or an expression\n" +
+		"(e.g. oam.write_sprite_data(0, 50, 50, 1, 0x10, 1) or mem.read16(0x2100)).\n" +
+		"Changes show on screen after the next frame; step a frame to see them.\n" +
+		"Text and failed assertions the ROM sends to the test port (0xA030) also appear here.\n\n")
+	s.immediateConsoleTranscript = transcript
+
+	input := widget.NewMultiLineEntry()
+	input.SetPlaceHolder("CoreLX statement or expression (Shift+Enter to run)")
+	input.SetMinRowsVisible(3)
+	s.immediateConsoleInput = input
+
+	var history []string
+	recall := widget.NewSelect(nil, nil)
+	recall.PlaceHolder = "History"
+	recall.OnChanged = func(text string) {
+		if text != "" {
+			input.SetText(text)
+			recall.ClearSelected()
+		}
+	}
+
+	run := func(text string) {
+		if strings.TrimSpace(text) == "" {
+			return
+		}
+		snap := s.backend.Snapshot()
+		if !snap.Loaded {
+			s.setStatus("No active project build")
+			return
+		}
+		if !snap.Paused {
+			s.setStatus("Pause before running console code")
+			return
+		}
+		res, err := s.backend.RunImmediate(text)
+		s.appendConsoleTranscript(formatImmediateTranscript(text, res, err))
+
+		history = append([]string{text}, removeString(history, text)...)
+		if len(history) > immediateConsoleHistoryLimit {
+			history = history[:immediateConsoleHistoryLimit]
+		}
+		recall.Options = history
+		recall.Refresh()
+		if err != nil {
+			s.setStatus("Console: " + err.Error())
+			return
+		}
+		input.SetText("")
+		s.refreshDebuggerOutput()
+		s.wakeEmulatorLoop()
+		s.setStatus("Console: ran on the paused machine")
+	}
+	input.OnSubmitted = run
+	runBtn := widget.NewButton("Run", func() { run(input.Text) })
+	clearBtn := widget.NewButton("Clear", func() {
+		transcript.Enable()
+		transcript.SetText("")
+		transcript.Disable()
+	})
+
+	controls := container.NewBorder(nil, nil, nil, container.NewVBox(runBtn, recall, clearBtn), input)
+	return container.NewBorder(nil, controls, nil, nil, transcript)
+}
+
+// showImmediateConsole opens the Console tab and focuses its input.
+func (s *devKitState) showImmediateConsole() {
+	if s.immediateConsoleInput == nil || !s.showBottomTab("Console") {
+		return
+	}
+	s.window.Canvas().Focus(s.immediateConsoleInput)
+}
+
+func removeString(list []string, v string) []string {
+	out := list[:0:0]
+	for _, item := range list {
+		if item != v {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// appendConsoleTranscript adds text to the end of the Console tab and
+// scrolls to it.
+func (s *devKitState) appendConsoleTranscript(text string) {
+	transcript := s.immediateConsoleTranscript
+	if transcript == nil || text == "" {
+		return
+	}
+	transcript.Enable()
+	transcript.SetText(transcript.Text + text)
+	transcript.CursorRow = len(strings.Split(transcript.Text, "\n"))
+	transcript.Refresh()
+	transcript.Disable()
+}
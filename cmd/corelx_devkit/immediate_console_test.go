@@ -0,0 +1,30 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"nitro-core-dx/internal/corelx"
+	"nitro-core-dx/internal/devkit"
+)
+
+func TestFormatImmediateTranscript(t *testing.T) {
+	got := formatImmediateTranscript("mem.read(0x7000)", devkit.ImmediateResult{HasValue: true, Value: 0xFFFE}, nil)
+	if got != "> mem.read(0x7000)\n= 0xFFFE (65534, signed -2)\n" {
+		t.Fatalf("value transcript = %q", got)
+	}
+	got = formatImmediateTranscript("x := 1\nmem.write(0x7000, x)\n", devkit.ImmediateResult{Instructions: 12, Cycles: 40}, nil)
+	if got != "> x := 1\n. mem.write(0x7000, x)\nok (12 instructions, 40 cycles)\n" {
+		t.Fatalf("statement transcript = %q", got)
+	}
+	diag := corelx.Diagnostic{Line: 2, Message: "unexpected end of input", Severity: corelx.SeverityError}
+	got = formatImmediateTranscript("a\nb", devkit.ImmediateResult{Diagnostics: []corelx.Diagnostic{diag}}, diag)
+	if !strings.HasSuffix(got, "! line 2: unexpected end of input\n") {
+		t.Fatalf("diagnostic transcript = %q", got)
+	}
+	got = formatImmediateTranscript("a", devkit.ImmediateResult{}, errors.New("pause the emulator first"))
+	if !strings.HasSuffix(got, "! pause the emulator first\n") {
+		t.Fatalf("error transcript = %q", got)
+	}
+}
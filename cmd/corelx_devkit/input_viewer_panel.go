@@ -0,0 +1,267 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+	"nitro-core-dx/internal/input"
+)
+
+var (
+	inputViewerIdleFill    = color.NRGBA{R: 0x2A, G: 0x2D, B: 0x35, A: 0xFF}
+	inputViewerPressedFill = color.NRGBA{R: 0x3C, G: 0xC8, B: 0x5A, A: 0xFF}
+	inputViewerIdleStroke  = color.NRGBA{R: 0x48, G: 0x4C, B: 0x56, A: 0xFF}
+	inputViewerHeldStroke  = color.NRGBA{R: 0xF0, G: 0xC0, B: 0x30, A: 0xFF}
+)
+
+// inputViewerButtonSpec names one controller button, its bit in the 12-bit
+// mask, and the keyboard keys mapped to it in computeButtonMask.
+type inputViewerButtonSpec struct {
+	name string
+	bit  uint
+	keys string
+}
+
+var inputViewerButtonSpecs = []inputViewerButtonSpec{
+	{"UP", input.ButtonUP, "W / Up"},
+	{"DOWN", input.ButtonDOWN, "S / Down"},
+	{"LEFT", input.ButtonLEFT, "A / Left"},
+	{"RIGHT", input.ButtonRIGHT, "D / Right"},
+	{"A", input.ButtonA, "Z"},
+	{"B", input.ButtonB, "X"},
+	{"X", input.ButtonX, "V"},
+	{"Y", input.ButtonY, "C"},
+	{"L", input.ButtonL, "Q"},
+	{"R", input.ButtonR, "E"},
+	{"START", input.ButtonSTART, "Enter"},
+	{"Z", input.ButtonZ, "Backspace"},
+}
+
+// inputViewerButton is a virtual controller button. Holding the primary
+// mouse button presses it for as long as it is held; a secondary click
+// latches it on/off so several buttons can be combined.
+type inputViewerButton struct {
+	widget.BaseWidget
+	spec  inputViewerButtonSpec
+	rect  *canvas.Rectangle
+	label *canvas.Text
+
+	onPress func(bit uint, pressed bool)
+	onLatch func(bit uint)
+}
+
+func newInputViewerButton(spec inputViewerButtonSpec, onPress func(uint, bool), onLatch func(uint)) *inputViewerButton {
+	b := &inputViewerButton{
+		spec:    spec,
+		rect:    canvas.NewRectangle(inputViewerIdleFill),
+		label:   canvas.NewText(spec.name, color.White),
+		onPress: onPress,
+		onLatch: onLatch,
+	}
+	b.rect.StrokeColor = inputViewerIdleStroke
+	b.rect.StrokeWidth = 2
+	b.rect.CornerRadius = 4
+	b.label.Alignment = fyne.TextAlignCenter
+	b.label.TextStyle = fyne.TextStyle{Monospace: true, Bold: true}
+	b.ExtendBaseWidget(b)
+	return b
+}
+
+func (b *inputViewerButton) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(container.NewStack(b.rect, container.NewCenter(b.label)))
+}
+
+func (b *inputViewerButton) MinSize() fyne.Size {
+	return fyne.NewSize(64, 32)
+}
+
+func (b *inputViewerButton) MouseDown(ev *desktop.MouseEvent) {
+	if ev.Button == desktop.MouseButtonPrimary && b.onPress != nil {
+		b.onPress(b.spec.bit, true)
+	}
+}
+
+func (b *inputViewerButton) MouseUp(ev *desktop.MouseEvent) {
+	if ev.Button == desktop.MouseButtonPrimary && b.onPress != nil {
+		b.onPress(b.spec.bit, false)
+	}
+}
+
+func (b *inputViewerButton) TappedSecondary(*fyne.PointEvent) {
+	if b.onLatch != nil {
+		b.onLatch(b.spec.bit)
+	}
+}
+
+// Tapped is required alongside TappedSecondary; presses go through
+// MouseDown/MouseUp.
+func (b *inputViewerButton) Tapped(*fyne.PointEvent) {}
+
+func (b *inputViewerButton) set(pressed, virtual bool) {
+	if pressed {
+		b.rect.FillColor = inputViewerPressedFill
+	} else {
+		b.rect.FillColor = inputViewerIdleFill
+	}
+	if virtual {
+		b.rect.StrokeColor = inputViewerHeldStroke
+	} else {
+		b.rect.StrokeColor = inputViewerIdleStroke
+	}
+	b.rect.Refresh()
+}
+
+type inputViewerPanel struct {
+	buttons  []*inputViewerButton
+	maskText *widget.Label
+	summary  *widget.Label
+
+	held    uint16
+	latched uint16
+}
+
+// buildInputViewerPane builds the Input tab: a live view of the controller 1
+// button mask the emulator sees, plus clickable virtual buttons that inject
+// input alongside the keyboard. The view is refreshed when frames are
+// presented (see refreshInputViewer).
+func (s *devKitState) buildInputViewerPane() fyne.CanvasObject {
+	panel := &inputViewerPanel{
+		maskText: widget.NewLabel(formatInputMask(0)),
+		summary:  widget.NewLabel("Input: no build loaded"),
+	}
+	panel.maskText.TextStyle = fyne.TextStyle{Monospace: true}
+
+	byName := map[string]*inputViewerButton{}
+	for _, spec := range inputViewerButtonSpecs {
+		btn := newInputViewerButton(spec,
+			func(bit uint, pressed bool) {
+				if pressed {
+					panel.held |= 1 << bit
+				} else {
+					panel.held &^= 1 << bit
+				}
+				s.setVirtualButtons(panel.held | panel.latched)
+			},
+			func(bit uint) {
+				panel.latched ^= 1 << bit
+				s.setVirtualButtons(panel.held | panel.latched)
+				s.setStatus(fmt.Sprintf("Virtual %s %s", inputButtonName(bit), onOff(panel.latched&(1<<bit) != 0, "latched", "released")))
+			},
+		)
+		panel.buttons = append(panel.buttons, btn)
+		byName[spec.name] = btn
+	}
+
+	spacer := func() fyne.CanvasObject { return layout.NewSpacer() }
+	dpad := container.NewGridWithColumns(3,
+		spacer(), byName["UP"], spacer(),
+		byName["LEFT"], spacer(), byName["RIGHT"],
+		spacer(), byName["DOWN"], spacer(),
+	)
+	face := container.NewGridWithColumns(3,
+		spacer(), byName["X"], spacer(),
+		byName["Y"], spacer(), byName["A"],
+		spacer(), byName["B"], spacer(),
+	)
+	shoulders := container.NewHBox(byName["L"], layout.NewSpacer(), byName["R"])
+	middle := container.NewHBox(layout.NewSpacer(), byName["START"], byName["Z"], layout.NewSpacer())
+	controller := container.NewVBox(
+		shoulders,
+		container.NewHBox(dpad, layout.NewSpacer(), face),
+		middle,
+	)
+
+	keyHelp := widget.NewLabel(inputViewerKeyHelp())
+	keyHelp.TextStyle = fyne.TextStyle{Monospace: true}
+	releaseBtn := widget.NewButton("Release All", func() {
+		panel.held, panel.latched = 0, 0
+		s.setVirtualButtons(0)
+		s.setStatus("Virtual buttons released")
+	})
+	hint := widget.NewLabel("Hold a button with the mouse to press it; right-click to latch it.")
+	hint.Wrapping = fyne.TextWrapWord
+
+	s.inputViewer = panel
+	return container.NewBorder(
+		container.NewVBox(
+			container.NewHBox(panel.summary, layout.NewSpacer(), releaseBtn),
+			panel.maskText,
+		),
+		hint, nil, nil,
+		container.NewVScroll(container.NewHBox(controller, widget.NewSeparator(), keyHelp)),
+	)
+}
+
+// setVirtualButtons replaces the mouse-driven button mask and re-sends the
+// combined mask to the emulator.
+func (s *devKitState) setVirtualButtons(mask uint16) {
+	s.keyMu.Lock()
+	s.virtualButtons = mask
+	s.keyMu.Unlock()
+	s.routeInputToEmulator()
+	s.refreshInputViewer()
+}
+
+// refreshInputViewer highlights the buttons set in the emulator's current
+// mask. Must run on the Fyne UI goroutine.
+func (s *devKitState) refreshInputViewer() {
+	panel := s.inputViewer
+	if panel == nil {
+		return
+	}
+	snap := s.backend.Snapshot()
+	if snap.Loaded {
+		panel.summary.SetText("Input: controller 1")
+	} else {
+		panel.summary.SetText("Input: no build loaded")
+	}
+	mask := snap.InputButtons
+	virtual := panel.held | panel.latched
+	panel.maskText.SetText(formatInputMask(mask))
+	for _, btn := range panel.buttons {
+		bit := uint16(1) << btn.spec.bit
+		btn.set(mask&bit != 0, virtual&bit != 0)
+	}
+}
+
+// formatInputMask renders a 12-bit button mask as hex, binary (bit 11 first),
+// and the names of the pressed buttons.
+func formatInputMask(mask uint16) string {
+	names := ""
+	for _, spec := range inputViewerButtonSpecs {
+		if mask&(1<<spec.bit) == 0 {
+			continue
+		}
+		if names != "" {
+			names += " "
+		}
+		names += spec.name
+	}
+	if names == "" {
+		names = "-"
+	}
+	return fmt.Sprintf("Mask 0x%03X  %012b  %s", mask&0x0FFF, mask&0x0FFF, names)
+}
+
+func inputButtonName(bit uint) string {
+	for _, spec := range inputViewerButtonSpecs {
+		if spec.bit == bit {
+			return spec.name
+		}
+	}
+	return fmt.Sprintf("bit %d", bit)
+}
+
+func inputViewerKeyHelp() string {
+	out := "Keyboard (Capture Game Input)\n"
+	for _, spec := range inputViewerButtonSpecs {
+		out += fmt.Sprintf("%-6s %s\n", spec.name, spec.keys)
+	}
+	return out
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2"
+)
+
+func TestFormatInputMask(t *testing.T) {
+	if got, want := formatInputMask(0), "Mask 0x000  000000000000  -"; got != want {
+		t.Fatalf("formatInputMask(0) = %q, want %q", got, want)
+	}
+	if got, want := formatInputMask(0x411), "Mask 0x411  010000010001  UP A START"; got != want {
+		t.Fatalf("formatInputMask(0x411) = %q, want %q", got, want)
+	}
+}
+
+func TestComputeButtonMaskIncludesVirtualButtons(t *testing.T) {
+	state := &devKitState{
+		keyStates:      map[fyne.KeyName]bool{fyne.KeyZ: true},
+		virtualButtons: 0x800,
+	}
+	if got := state.computeButtonMask(); got != 0x810 {
+		t.Fatalf("expected keyboard A + virtual Z (0x810), got 0x%03X", got)
+	}
+}
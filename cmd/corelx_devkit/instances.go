@@ -0,0 +1,208 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"nitro-core-dx/internal/i18n"
+)
+
+// Several Dev Kits may run at once (File > New Window starts another
+// process). They share the settings file: persistSettings merges this
+// instance's changes into whatever another instance wrote, under a lock
+// file. Each process builds in its own temp directory and takes a numbered
+// instance slot for its autosave and session journals, so two windows never
+// overwrite each other's crash recovery. Slot 0 keeps the original journal
+// file names, and only slot 0 reopens the last edited file at startup.
+const (
+	instanceHeartbeat  = 5 * time.Second
+	instanceStaleAfter = 30 * time.Second
+	maxInstanceSlots   = 32
+	settingsLockWait   = 2 * time.Second
+)
+
+// devKitInstance is this process's claim on an instance slot. The slot's
+// lock file is touched every instanceHeartbeat; a lock that has not been
+// touched for instanceStaleAfter belongs to a Dev Kit that crashed and may
+// be taken over.
+type devKitInstance struct {
+	slot     int
+	lockPath string
+	stop     chan struct{}
+}
+
+// claimInstanceSlot takes the lowest free instance slot in dir.
+func claimInstanceSlot(dir string) (*devKitInstance, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	for slot := 0; slot < maxInstanceSlots; slot++ {
+		path := filepath.Join(dir, fmt.Sprintf("devkit_instance-%d.lock", slot))
+		ok, err := tryCreateLock(path)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		in := &devKitInstance{slot: slot, lockPath: path, stop: make(chan struct{})}
+		go in.heartbeat()
+		return in, nil
+	}
+	return nil, fmt.Errorf("all %d Dev Kit instance slots are in use", maxInstanceSlots)
+}
+
+func (in *devKitInstance) heartbeat() {
+	ticker := time.NewTicker(instanceHeartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-in.stop:
+			return
+		case now := <-ticker.C:
+			_ = os.Chtimes(in.lockPath, now, now)
+		}
+	}
+}
+
+// release gives the slot back.
+func (in *devKitInstance) release() {
+	if in == nil {
+		return
+	}
+	close(in.stop)
+	_ = os.Remove(in.lockPath)
+}
+
+// tryCreateLock creates path exclusively, first removing it when it is
+// older than instanceStaleAfter. It reports false when another process
+// holds the lock.
+func tryCreateLock(path string) (bool, error) {
+	for attempt := 0; attempt < 2; attempt++ {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			_, _ = f.WriteString(strconv.Itoa(os.Getpid()))
+			return true, f.Close()
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return false, err
+		}
+		info, statErr := os.Stat(path)
+		if statErr != nil || time.Since(info.ModTime()) < instanceStaleAfter {
+			return false, nil
+		}
+		_ = os.Remove(path)
+	}
+	return false, nil
+}
+
+// lockSettings waits up to settingsLockWait for the settings lock and
+// returns the function that releases it.
+func lockSettings(settingsPath string) (func(), error) {
+	path := settingsPath + ".lock"
+	deadline := time.Now().Add(settingsLockWait)
+	for {
+		ok, err := tryCreateLock(path)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return func() { _ = os.Remove(path) }, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("settings are locked by another Dev Kit (%s)", path)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// instanceFilePath returns the per-slot name of a journal file:
+// devkit_autosave.json for slot 0, devkit_autosave-2.json for slot 2.
+func instanceFilePath(path string, slot int) string {
+	if path == "" || slot == 0 {
+		return path
+	}
+	ext := filepath.Ext(path)
+	return fmt.Sprintf("%s-%d%s", strings.TrimSuffix(path, ext), slot, ext)
+}
+
+// mergeDevKitSettings folds this instance's changes into disk, the settings
+// file as another instance may have rewritten it since base was loaded.
+// Fields this instance changed since base take its value; the rest keep
+// disk's. Recent files this instance opened go in front of disk's list.
+func mergeDevKitSettings(base, mine, disk devKitSettings, opened []string) devKitSettings {
+	out := disk
+	bv, mv, ov := reflect.ValueOf(base), reflect.ValueOf(mine), reflect.ValueOf(&out).Elem()
+	for i := 0; i < mv.NumField(); i++ {
+		if !reflect.DeepEqual(mv.Field(i).Interface(), bv.Field(i).Interface()) {
+			ov.Field(i).Set(mv.Field(i))
+		}
+	}
+	recent := make([]string, 0, len(opened)+len(disk.RecentFiles))
+	for i := len(opened) - 1; i >= 0; i-- {
+		recent = append(recent, opened[i])
+	}
+	out.RecentFiles = normalizeRecentFiles(append(recent, disk.RecentFiles...))
+	return out
+}
+
+// cloneDevKitSettings deep-copies settings, so later in-place edits of its
+// maps and slices do not change the copy.
+func cloneDevKitSettings(settings devKitSettings) devKitSettings {
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return settings
+	}
+	var out devKitSettings
+	if err := json.Unmarshal(data, &out); err != nil {
+		return settings
+	}
+	return out
+}
+
+// syncDevKitSettings writes this instance's settings to path, merged with
+// the file's current contents under the settings lock, and returns what it
+// wrote.
+func syncDevKitSettings(path string, base, mine devKitSettings, opened []string) (devKitSettings, error) {
+	if path == "" {
+		return mine, nil
+	}
+	unlock, err := lockSettings(path)
+	if err != nil {
+		return mine, err
+	}
+	defer unlock()
+	merged := mine
+	if _, statErr := os.Stat(path); statErr == nil {
+		if disk, loadErr := loadDevKitSettings(path); loadErr == nil {
+			merged = mergeDevKitSettings(base, mine, disk, opened)
+		}
+	}
+	if err := saveDevKitSettings(path, merged); err != nil {
+		return mine, err
+	}
+	merged, err = loadDevKitSettings(path)
+	return merged, err
+}
+
+// openNewWindow starts another Dev Kit process with an untitled buffer.
+func (s *devKitState) openNewWindow() {
+	exe, err := os.Executable()
+	if err == nil {
+		err = exec.Command(exe).Start()
+	}
+	if err != nil {
+		s.appendBuildOutput("New window failed: " + err.Error())
+		s.setStatus(i18n.T("New window failed"))
+		return
+	}
+	s.setStatus(i18n.T("Opened a new Dev Kit window"))
+}
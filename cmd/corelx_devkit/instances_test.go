@@ -0,0 +1,130 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestMergeDevKitSettingsKeepsOtherInstanceChanges(t *testing.T) {
+	base := defaultDevKitSettings()
+	base.RecentFiles = []string{"/p/old.corelx"}
+
+	// This window changed the theme and opened a.corelx; the other window
+	// changed the font size and opened b.corelx.
+	mine := cloneDevKitSettings(base)
+	mine.Theme = themeLight
+	mine.RecentFiles = []string{"/p/a.corelx", "/p/old.corelx"}
+	disk := cloneDevKitSettings(base)
+	disk.EditorFontSize = base.EditorFontSize + 4
+	disk.RecentFiles = []string{"/p/b.corelx", "/p/old.corelx"}
+
+	got := mergeDevKitSettings(base, mine, disk, []string{"/p/a.corelx"})
+	if got.Theme != themeLight {
+		t.Errorf("Theme = %q, want this instance's %q", got.Theme, themeLight)
+	}
+	if got.EditorFontSize != disk.EditorFontSize {
+		t.Errorf("EditorFontSize = %d, want the other instance's %d", got.EditorFontSize, disk.EditorFontSize)
+	}
+	want := []string{"/p/a.corelx", "/p/b.corelx", "/p/old.corelx"}
+	if !slices.Equal(got.RecentFiles, want) {
+		t.Errorf("RecentFiles = %v, want %v", got.RecentFiles, want)
+	}
+}
+
+func TestSyncDevKitSettingsMergesConcurrentWriters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	if err := saveDevKitSettings(path, defaultDevKitSettings()); err != nil {
+		t.Fatal(err)
+	}
+	first, err := loadDevKitSettings(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second := cloneDevKitSettings(first)
+	firstBase, secondBase := cloneDevKitSettings(first), cloneDevKitSettings(second)
+
+	first.Theme = themeDark
+	if _, err := syncDevKitSettings(path, firstBase, first, []string{"/p/one.corelx"}); err != nil {
+		t.Fatal(err)
+	}
+	second.Deterministic = !second.Deterministic
+	merged, err := syncDevKitSettings(path, secondBase, second, []string{"/p/two.corelx"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged.Theme != themeDark || merged.Deterministic != second.Deterministic {
+		t.Errorf("merged Theme %q Deterministic %v; want both instances' changes", merged.Theme, merged.Deterministic)
+	}
+	if want := []string{"/p/two.corelx", "/p/one.corelx"}; !slices.Equal(merged.RecentFiles, want) {
+		t.Errorf("RecentFiles = %v, want %v", merged.RecentFiles, want)
+	}
+	if _, err := os.Stat(path + ".lock"); !os.IsNotExist(err) {
+		t.Errorf("settings lock left behind: %v", err)
+	}
+}
+
+func TestClaimInstanceSlotTakesLowestFreeSlot(t *testing.T) {
+	dir := t.TempDir()
+	a, err := claimInstanceSlot(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := claimInstanceSlot(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.release()
+	if a.slot != 0 || b.slot != 1 {
+		t.Fatalf("slots = %d, %d; want 0, 1", a.slot, b.slot)
+	}
+	a.release()
+	c, err := claimInstanceSlot(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.release()
+	if c.slot != 0 {
+		t.Errorf("slot after release = %d, want 0", c.slot)
+	}
+}
+
+func TestStaleInstanceLockIsTakenOver(t *testing.T) {
+	dir := t.TempDir()
+	stale := filepath.Join(dir, "devkit_instance-0.lock")
+	if err := os.WriteFile(stale, []byte("1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * instanceStaleAfter)
+	if err := os.Chtimes(stale, old, old); err != nil {
+		t.Fatal(err)
+	}
+	in, err := claimInstanceSlot(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer in.release()
+	if in.slot != 0 {
+		t.Errorf("slot = %d, want the crashed instance's slot 0", in.slot)
+	}
+}
+
+func TestInstanceFilePath(t *testing.T) {
+	cases := []struct {
+		path string
+		slot int
+		want string
+	}{
+		{"/cfg/devkit_autosave.json", 0, "/cfg/devkit_autosave.json"},
+		{"/cfg/devkit_autosave.json", 2, "/cfg/devkit_autosave-2.json"},
+		{"/cfg/devkit_session.json", 1, "/cfg/devkit_session-1.json"},
+		{"", 3, ""},
+	}
+	for _, c := range cases {
+		if got := instanceFilePath(c.path, c.slot); got != c.want {
+			t.Errorf("instanceFilePath(%q, %d) = %q, want %q", c.path, c.slot, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"nitro-core-dx/internal/devkit"
+	"nitro-core-dx/internal/emulator"
+)
+
+const (
+	ioRegisterTabName   = "I/O"
+	ioRegisterAllGroups = "All groups"
+)
+
+// ioRegisterPanel is the I/O tab: every named PPU/APU/input register with
+// its current value, and a field that writes a new one through the bus.
+type ioRegisterPanel struct {
+	filter   *widget.Entry
+	group    *widget.Select
+	list     *widget.List
+	summary  *widget.Label
+	selected *widget.Label
+	detail   *widget.Label
+	value    *widget.Entry
+	write    *widget.Button
+
+	regs    []devkit.IORegisterSnapshot
+	shown   []int
+	current int // index into regs, -1 for none
+}
+
+// filterIORegisters returns the indexes of regs in group ("" for all) whose
+// name or address contains every word of query.
+func filterIORegisters(regs []devkit.IORegisterSnapshot, group, query string) []int {
+	words := strings.Fields(strings.ToUpper(query))
+	var out []int
+	for i, reg := range regs {
+		if group != "" && reg.Group != group {
+			continue
+		}
+		hay := fmt.Sprintf("%s 0X%04X", reg.Name, reg.Address)
+		match := true
+		for _, w := range words {
+			if !strings.Contains(hay, w) {
+				match = false
+				break
+			}
+		}
+		if match {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+// formatIORegisterValue shows a byte as hex, decimal and binary, or "--"
+// for a write-only register nothing has written yet.
+func formatIORegisterValue(value uint8, known bool) string {
+	if !known {
+		return "--"
+	}
+	return fmt.Sprintf("0x%02X  %3d  %04b_%04b", value, value, value>>4, value&0x0F)
+}
+
+// formatIORegisterDetail describes reg for the editor: its access, its
+// fields decoded from the value when it is known, and its memory map note
+// (see docs/MEMORY_MAP.md, generated from the same table).
+func formatIORegisterDetail(reg devkit.IORegisterSnapshot) string {
+	parts := []string{"Access " + reg.Access.String()}
+	if len(reg.Fields) > 0 {
+		fields := make([]string, len(reg.Fields))
+		for i, f := range reg.Fields {
+			if reg.Known {
+				fields[i] = fmt.Sprintf("%s=%d", f.Name, f.Get(reg.Value))
+			} else {
+				fields[i] = f.Name
+			}
+		}
+		parts = append(parts, strings.Join(fields, " "))
+	}
+	if reg.Note != "" {
+		parts = append(parts, reg.Note)
+	}
+	return strings.Join(parts, "  |  ")
+}
+
+// parseIORegisterValue accepts a byte as decimal, 0x/$ hex or 0b binary.
+func parseIORegisterValue(text string) (uint8, error) {
+	text = strings.TrimSpace(text)
+	if strings.HasPrefix(text, "$") {
+		text = "0x" + text[1:]
+	}
+	v, err := strconv.ParseUint(strings.ReplaceAll(text, "_", ""), 0, 8)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a byte (0-255, 0xFF or 0b11111111)", text)
+	}
+	return uint8(v), nil
+}
+
+func (s *devKitState) buildIORegisterPane() fyne.CanvasObject {
+	panel := &ioRegisterPanel{current: -1}
+	s.ioRegisters = panel
+
+	var groups []string
+	seen := map[string]bool{}
+	for _, reg := range emulator.IORegisters() {
+		if !seen[reg.Group] {
+			seen[reg.Group] = true
+			groups = append(groups, reg.Group)
+		}
+	}
+
+	panel.summary = widget.NewLabel("I/O: no build loaded")
+	panel.filter = widget.NewEntry()
+	panel.filter.SetPlaceHolder("Filter by name or address (e.g. BG0, CH0_FREQ, 0x8012)")
+	panel.group = widget.NewSelect(append([]string{ioRegisterAllGroups}, groups...), nil)
+
+	panel.list = widget.NewList(
+		func() int { return len(panel.shown) },
+		func() fyne.CanvasObject {
+			name := widget.NewLabel("")
+			name.TextStyle = fyne.TextStyle{Monospace: true}
+			value := widget.NewLabel("")
+			value.TextStyle = fyne.TextStyle{Monospace: true}
+			return container.NewBorder(nil, nil, nil, value, name)
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			if id >= len(panel.shown) {
+				return
+			}
+			reg := panel.regs[panel.shown[id]]
+			row := obj.(*fyne.Container)
+			row.Objects[0].(*widget.Label).SetText(fmt.Sprintf("0x%04X  %-4s %s", reg.Address, reg.Access, reg.Name))
+			row.Objects[1].(*widget.Label).SetText(formatIORegisterValue(reg.Value, reg.Known))
+		},
+	)
+
+	panel.selected = widget.NewLabel("Select a register")
+	panel.detail = widget.NewLabel("")
+	panel.detail.Wrapping = fyne.TextWrapWord
+	panel.value = widget.NewEntry()
+	panel.value.SetPlaceHolder("New value")
+	panel.write = widget.NewButton("Write", func() { s.writeSelectedIORegister() })
+	panel.write.Disable()
+	panel.value.OnSubmitted = func(string) { s.writeSelectedIORegister() }
+
+	panel.list.OnSelected = func(id widget.ListItemID) {
+		if id < 0 || id >= len(panel.shown) {
+			return
+		}
+		panel.current = panel.shown[id]
+		reg := panel.regs[panel.current]
+		panel.selected.SetText(fmt.Sprintf("%s (0x%04X, %s)", reg.Name, reg.Address, reg.Group))
+		panel.detail.SetText(formatIORegisterDetail(reg))
+		if reg.Known {
+			panel.value.SetText(fmt.Sprintf("0x%02X", reg.Value))
+		} else {
+			panel.value.SetText("")
+		}
+		if reg.Access == emulator.IOReadOnly {
+			panel.write.Disable()
+		} else {
+			panel.write.Enable()
+		}
+	}
+
+	refilter := func() {
+		panel.list.UnselectAll()
+		panel.current = -1
+		panel.selected.SetText("Select a register")
+		panel.detail.SetText("")
+		panel.write.Disable()
+		s.refreshIORegisters()
+	}
+	panel.filter.OnChanged = func(string) { refilter() }
+	panel.group.OnChanged = func(string) { refilter() }
+	panel.group.SetSelected(ioRegisterAllGroups)
+
+	top := container.NewBorder(nil, nil, panel.summary, panel.group, panel.filter)
+	editor := container.NewBorder(nil, nil, panel.selected, panel.write, panel.value)
+	help := widget.NewLabel("R/W registers show live values; W and port registers show the last value written. Writes go through the bus while running or paused.")
+	help.Wrapping = fyne.TextWrapWord
+	help.Importance = widget.LowImportance
+	return container.NewBorder(top, container.NewVBox(editor, panel.detail, help), nil, nil, panel.list)
+}
+
+// refreshIORegisters re-reads the registers while the I/O tab is showing.
+// Must run on the Fyne UI goroutine.
+func (s *devKitState) refreshIORegisters() {
+	panel := s.ioRegisters
+	if panel == nil || s.bottomLeftTabs == nil {
+		return
+	}
+	if tab := s.bottomLeftTabs.Selected(); tab == nil || tab.Text != ioRegisterTabName {
+		return
+	}
+	regs := s.backend.IORegisters()
+	if regs == nil {
+		panel.regs, panel.shown = nil, nil
+		panel.summary.SetText("I/O: no build loaded")
+		panel.list.Refresh()
+		return
+	}
+	group := panel.group.Selected
+	if group == ioRegisterAllGroups {
+		group = ""
+	}
+	panel.regs = regs
+	panel.shown = filterIORegisters(regs, group, panel.filter.Text)
+	panel.summary.SetText(fmt.Sprintf("I/O: %d registers", len(panel.shown)))
+	panel.list.Refresh()
+}
+
+func (s *devKitState) writeSelectedIORegister() {
+	panel := s.ioRegisters
+	if panel == nil || panel.current < 0 || panel.current >= len(panel.regs) {
+		return
+	}
+	reg := panel.regs[panel.current]
+	if reg.Access == emulator.IOReadOnly {
+		s.setStatus(reg.Name + " is read-only")
+		return
+	}
+	value, err := parseIORegisterValue(panel.value.Text)
+	if err != nil {
+		s.setStatus(err.Error())
+		return
+	}
+	if err := s.backend.WriteIORegister(reg.Address, value); err != nil {
+		s.setStatus("Write failed: " + err.Error())
+		return
+	}
+	s.refreshIORegisters()
+	s.setStatus(fmt.Sprintf("Wrote 0x%02X to %s (0x%04X)", value, reg.Name, reg.Address))
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"nitro-core-dx/internal/devkit"
+	"nitro-core-dx/internal/emulator"
+)
+
+func TestFilterIORegisters(t *testing.T) {
+	var regs []devkit.IORegisterSnapshot
+	for _, reg := range emulator.IORegisters() {
+		regs = append(regs, devkit.IORegisterSnapshot{IORegister: reg})
+	}
+	names := func(idx []int) []string {
+		var out []string
+		for _, i := range idx {
+			out = append(out, regs[i].Name)
+		}
+		return out
+	}
+
+	if got := names(filterIORegisters(regs, "", "0x8012")); len(got) != 1 || got[0] != "CGRAM_ADDR" {
+		t.Fatalf("address filter = %v", got)
+	}
+	if got := names(filterIORegisters(regs, "", "ch0 freq")); len(got) != 2 || got[0] != "CH0_FREQ_LOW" {
+		t.Fatalf("word filter = %v", got)
+	}
+	for _, i := range filterIORegisters(regs, "BG1", "") {
+		if regs[i].Group != "BG1" {
+			t.Fatalf("group filter leaked %s", regs[i].Name)
+		}
+	}
+	if got := filterIORegisters(regs, "", ""); len(got) != len(regs) {
+		t.Fatalf("empty filter should list all %d registers, got %d", len(regs), len(got))
+	}
+}
+
+func TestParseAndFormatIORegisterValue(t *testing.T) {
+	for text, want := range map[string]uint8{"17": 17, "0x1F": 0x1F, "$ff": 0xFF, "0b0001_0001": 0x11, " 0 ": 0} {
+		got, err := parseIORegisterValue(text)
+		if err != nil || got != want {
+			t.Errorf("parse %q = %d, %v; want %d", text, got, err, want)
+		}
+	}
+	for _, text := range []string{"", "256", "-1", "zz"} {
+		if _, err := parseIORegisterValue(text); err == nil {
+			t.Errorf("parse %q should fail", text)
+		}
+	}
+	if got := formatIORegisterValue(0x5A, true); got != "0x5A   90  0101_1010" {
+		t.Fatalf("format = %q", got)
+	}
+	if got := formatIORegisterValue(0, false); got != "--" {
+		t.Fatalf("unknown format = %q", got)
+	}
+}
+
+func TestFormatIORegisterDetail(t *testing.T) {
+	var bg0 devkit.IORegisterSnapshot
+	for _, reg := range emulator.IORegisters() {
+		if reg.Name == "BG0_CONTROL" {
+			bg0 = devkit.IORegisterSnapshot{IORegister: reg}
+		}
+	}
+	bg0.Value, bg0.Known = 0x09, true // ENABLE, PRIORITY 2
+	if got := formatIORegisterDetail(bg0); !strings.Contains(got, "Access R/W") || !strings.Contains(got, "ENABLE=1 TILE_16X16=0 PRIORITY=2 TILEMAP_SIZE=0") {
+		t.Errorf("BG0_CONTROL detail = %q", got)
+	}
+	bg0.Known = false
+	if got := formatIORegisterDetail(bg0); !strings.Contains(got, "ENABLE TILE_16X16") {
+		t.Errorf("unknown-value detail = %q", got)
+	}
+}
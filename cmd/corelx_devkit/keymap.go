@@ -0,0 +1,309 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/widget"
+)
+
+// keyBindingAliases maps friendly key names accepted in bindings to Fyne key
+// names. Keys not listed here are matched against Fyne's own names.
+var keyBindingAliases = map[string]fyne.KeyName{
+	"COMMA":      fyne.KeyComma,
+	"PERIOD":     fyne.KeyPeriod,
+	"SLASH":      fyne.KeySlash,
+	"BACKSLASH":  fyne.KeyBackslash,
+	"MINUS":      fyne.KeyMinus,
+	"EQUAL":      fyne.KeyEqual,
+	"PLUS":       fyne.KeyEqual,
+	"SEMICOLON":  fyne.KeySemicolon,
+	"APOSTROPHE": fyne.KeyApostrophe,
+	"BACKTICK":   fyne.KeyBackTick,
+	"GRAVE":      fyne.KeyBackTick,
+	"LBRACKET":   fyne.KeyLeftBracket,
+	"RBRACKET":   fyne.KeyRightBracket,
+	"ENTER":      fyne.KeyReturn,
+	"RETURN":     fyne.KeyReturn,
+	"ESC":        fyne.KeyEscape,
+	"ESCAPE":     fyne.KeyEscape,
+	"SPACE":      fyne.KeySpace,
+	"TAB":        fyne.KeyTab,
+	"BACKSPACE":  fyne.KeyBackspace,
+	"DELETE":     fyne.KeyDelete,
+	"DEL":        fyne.KeyDelete,
+	"INSERT":     fyne.KeyInsert,
+	"HOME":       fyne.KeyHome,
+	"END":        fyne.KeyEnd,
+	"PAGEUP":     fyne.KeyPageUp,
+	"PAGEDOWN":   fyne.KeyPageDown,
+	"UP":         fyne.KeyUp,
+	"DOWN":       fyne.KeyDown,
+	"LEFT":       fyne.KeyLeft,
+	"RIGHT":      fyne.KeyRight,
+}
+
+// keyBindingNames is the display name for Fyne keys whose KeyName is not
+// what a user would type.
+var keyBindingNames = map[fyne.KeyName]string{
+	fyne.KeyPageUp:   "PageUp",
+	fyne.KeyPageDown: "PageDown",
+}
+
+// parseKeyBinding parses "Ctrl+Shift+P" style bindings. Ctrl is the platform
+// shortcut modifier (Cmd on macOS); Alt and Super are also accepted. A
+// binding needs Ctrl, Alt or Super, since Fyne does not deliver Shift-only
+// or unmodified keys as shortcuts, and must not be one of the clipboard or
+// undo keys the driver reserves.
+func parseKeyBinding(text string) (*desktop.CustomShortcut, error) {
+	parts := strings.Split(strings.TrimSpace(text), "+")
+	// "Ctrl++" binds the plus key; Split leaves two empty parts at the end.
+	if len(parts) >= 2 && parts[len(parts)-1] == "" && parts[len(parts)-2] == "" {
+		parts = append(parts[:len(parts)-2], "Plus")
+	}
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("%q: expected modifier+key, e.g. Ctrl+Shift+P", text)
+	}
+	var mod fyne.KeyModifier
+	for _, part := range parts[:len(parts)-1] {
+		switch strings.ToUpper(strings.TrimSpace(part)) {
+		case "CTRL", "CONTROL", "CMDORCTRL":
+			mod |= fyne.KeyModifierShortcutDefault
+		case "SHIFT":
+			mod |= fyne.KeyModifierShift
+		case "ALT", "OPTION":
+			mod |= fyne.KeyModifierAlt
+		case "SUPER", "CMD", "WIN", "META":
+			mod |= fyne.KeyModifierSuper
+		default:
+			return nil, fmt.Errorf("%q: unknown modifier %q", text, part)
+		}
+	}
+	if mod&^fyne.KeyModifierShift == 0 {
+		return nil, fmt.Errorf("%q: needs Ctrl, Alt or Super", text)
+	}
+	key, err := parseKeyName(parts[len(parts)-1])
+	if err != nil {
+		return nil, fmt.Errorf("%q: %w", text, err)
+	}
+	if mod == fyne.KeyModifierShortcutDefault {
+		switch key {
+		case fyne.KeyZ, fyne.KeyY, fyne.KeyC, fyne.KeyV, fyne.KeyX, fyne.KeyA, fyne.KeyInsert:
+			return nil, fmt.Errorf("%q is reserved for undo and clipboard editing", text)
+		}
+	}
+	return &desktop.CustomShortcut{KeyName: key, Modifier: mod}, nil
+}
+
+func parseKeyName(text string) (fyne.KeyName, error) {
+	upper := strings.ToUpper(strings.TrimSpace(text))
+	if upper == "" {
+		return "", fmt.Errorf("missing key")
+	}
+	if key, ok := keyBindingAliases[upper]; ok {
+		return key, nil
+	}
+	if len(upper) == 1 {
+		c := upper[0]
+		if (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || strings.ContainsRune(",./\\-=;'`[]", rune(c)) {
+			return fyne.KeyName(upper), nil
+		}
+	}
+	if upper[0] == 'F' {
+		var n int
+		if _, err := fmt.Sscanf(upper, "F%d", &n); err == nil && n >= 1 && n <= 12 && upper == fmt.Sprintf("F%d", n) {
+			return fyne.KeyName(upper), nil
+		}
+	}
+	return "", fmt.Errorf("unknown key %q", text)
+}
+
+// formatKeyBinding renders sc in the form parseKeyBinding accepts.
+func formatKeyBinding(sc *desktop.CustomShortcut) string {
+	if sc == nil {
+		return ""
+	}
+	var b strings.Builder
+	mod := sc.Modifier
+	if mod&fyne.KeyModifierShortcutDefault != 0 {
+		b.WriteString("Ctrl+")
+		mod &^= fyne.KeyModifierShortcutDefault
+	}
+	if mod&fyne.KeyModifierShift != 0 {
+		b.WriteString("Shift+")
+	}
+	if mod&fyne.KeyModifierAlt != 0 {
+		b.WriteString("Alt+")
+	}
+	if mod&fyne.KeyModifierSuper != 0 {
+		b.WriteString("Super+")
+	}
+	if name, ok := keyBindingNames[sc.KeyName]; ok {
+		b.WriteString(name)
+	} else {
+		b.WriteString(string(sc.KeyName))
+	}
+	return b.String()
+}
+
+// resolveKeymap returns the binding for each command: the user override in
+// devKitSettings.Keymap when present ("" unbinds), otherwise the command's
+// default. Invalid overrides fall back to the default, and when two commands
+// share a key the one listed first keeps it. Both cases are reported in
+// problems so a hand-edited settings file is not silently misread.
+func resolveKeymap(commands []devKitCommand, overrides map[string]string) (bindings map[string]*desktop.CustomShortcut, problems []string) {
+	bindings = make(map[string]*desktop.CustomShortcut, len(commands))
+	owner := make(map[string]string, len(commands))
+	for _, cmd := range commands {
+		text := cmd.defaultKey
+		if override, ok := overrides[cmd.id]; ok {
+			if _, err := parseKeyBinding(override); override == "" || err == nil {
+				text = override
+			} else {
+				problems = append(problems, fmt.Sprintf("%s: %v", cmd.id, err))
+			}
+		}
+		if text == "" {
+			continue
+		}
+		sc, err := parseKeyBinding(text)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", cmd.id, err))
+			continue
+		}
+		name := sc.ShortcutName()
+		if other, taken := owner[name]; taken {
+			problems = append(problems, fmt.Sprintf("%s: %s is already bound to %s", cmd.id, formatKeyBinding(sc), other))
+			continue
+		}
+		owner[name] = cmd.id
+		bindings[cmd.id] = sc
+	}
+	return bindings, problems
+}
+
+// applyKeymap resolves the keymap from settings and rebuilds the main menu,
+// which is where bindings are dispatched from.
+func (s *devKitState) applyKeymap() {
+	bindings, problems := resolveKeymap(s.devKitCommands(), s.settings.Keymap)
+	s.keymap = bindings
+	for _, problem := range problems {
+		s.appendBuildOutput("Keymap warning: " + problem)
+	}
+	s.refreshMainMenu()
+}
+
+// setKeyBinding stores a binding for command id. A binding equal to the
+// default removes the override so the settings file only carries changes.
+func (s *devKitState) setKeyBinding(id, binding string) error {
+	var def string
+	for _, cmd := range s.devKitCommands() {
+		if cmd.id == id {
+			def = cmd.defaultKey
+			break
+		}
+	}
+	binding = strings.TrimSpace(binding)
+	if binding != "" {
+		sc, err := parseKeyBinding(binding)
+		if err != nil {
+			return err
+		}
+		for other, bound := range s.keymap {
+			if other != id && bound.ShortcutName() == sc.ShortcutName() {
+				return fmt.Errorf("%s is already bound to %s", formatKeyBinding(sc), s.commandTitle(other))
+			}
+		}
+		binding = formatKeyBinding(sc)
+	}
+	if def != "" {
+		if sc, err := parseKeyBinding(def); err == nil {
+			def = formatKeyBinding(sc)
+		}
+	}
+	if binding == def {
+		delete(s.settings.Keymap, id)
+	} else {
+		if s.settings.Keymap == nil {
+			s.settings.Keymap = map[string]string{}
+		}
+		s.settings.Keymap[id] = binding
+	}
+	s.applyKeymap()
+	s.persistSettings()
+	return nil
+}
+
+func (s *devKitState) commandTitle(id string) string {
+	for _, cmd := range s.devKitCommands() {
+		if cmd.id == id {
+			return cmd.category + ": " + cmd.title
+		}
+	}
+	return id
+}
+
+// showKeymapDialog lists every command with an editable binding. Bindings
+// are validated and applied when an entry is submitted with Enter.
+func (s *devKitState) showKeymapDialog() {
+	commands := s.devKitCommands()
+	type keymapRow struct {
+		cmd   devKitCommand
+		entry *widget.Entry
+	}
+	var rows []keymapRow
+	syncRows := func() {
+		for _, row := range rows {
+			row.entry.SetText(formatKeyBinding(s.keymap[row.cmd.id]))
+		}
+	}
+	grid := container.NewGridWithColumns(3)
+	for _, cmd := range commands {
+		entry := widget.NewEntry()
+		entry.SetPlaceHolder("(unbound)")
+		row := keymapRow{cmd, entry}
+		commit := func(text string) {
+			if text == formatKeyBinding(s.keymap[row.cmd.id]) {
+				return
+			}
+			if err := s.setKeyBinding(row.cmd.id, text); err != nil {
+				dialog.ShowError(err, s.window)
+				syncRows()
+				return
+			}
+			syncRows()
+			s.setStatus(row.cmd.title + ": " + onOff(text == "", "unbound", formatKeyBinding(s.keymap[row.cmd.id])))
+		}
+		entry.OnSubmitted = commit
+		reset := widget.NewButton("Default", func() {
+			delete(s.settings.Keymap, row.cmd.id)
+			s.applyKeymap()
+			s.persistSettings()
+			syncRows()
+		})
+		rows = append(rows, row)
+		grid.Add(widget.NewLabel(cmd.category + ": " + cmd.title))
+		grid.Add(entry)
+		grid.Add(reset)
+	}
+	syncRows()
+
+	help := widget.NewLabel("Type a binding such as Ctrl+Shift+P, Alt+F5 or Ctrl+Comma and press Enter. Clear the field and press Enter to unbind. Ctrl is Cmd on macOS.")
+	help.Wrapping = fyne.TextWrapWord
+	resetAll := widget.NewButton("Reset All to Defaults", func() {
+		s.settings.Keymap = nil
+		s.applyKeymap()
+		s.persistSettings()
+		syncRows()
+		s.setStatus("Keyboard shortcuts reset to defaults")
+	})
+	top := container.NewBorder(nil, nil, nil, resetAll, help)
+	content := container.NewBorder(top, nil, nil, nil, container.NewVScroll(grid))
+	d := dialog.NewCustom("Keyboard Shortcuts", "Close", content, s.window)
+	d.Resize(fyne.NewSize(720, 620))
+	d.Show()
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2"
+)
+
+func TestParseKeyBindingRoundTrip(t *testing.T) {
+	cases := map[string]string{
+		"Ctrl+Shift+P":    "Ctrl+Shift+P",
+		"ctrl+shift+p":    "Ctrl+Shift+P",
+		"Shift+Ctrl+F5":   "Ctrl+Shift+F5",
+		"Ctrl+Comma":      "Ctrl+,",
+		"Ctrl+,":          "Ctrl+,",
+		"Alt+Enter":       "Alt+Return",
+		"Ctrl+PageDown":   "Ctrl+PageDown",
+		"Ctrl+Alt+1":      "Ctrl+Alt+1",
+		" Super + F12 ":   "Super+F12",
+		"Ctrl+Shift+Z":    "Ctrl+Shift+Z",
+		"Ctrl+Plus":       "Ctrl+=",
+		"Ctrl++":          "Ctrl+=",
+		"Alt+Shift+Slash": "Shift+Alt+/",
+	}
+	for in, want := range cases {
+		sc, err := parseKeyBinding(in)
+		if err != nil {
+			t.Fatalf("parse %q: %v", in, err)
+		}
+		if got := formatKeyBinding(sc); got != want {
+			t.Fatalf("format(parse(%q)) = %q, want %q", in, got, want)
+		}
+		again, err := parseKeyBinding(formatKeyBinding(sc))
+		if err != nil || again.ShortcutName() != sc.ShortcutName() {
+			t.Fatalf("re-parse of %q failed: %v", formatKeyBinding(sc), err)
+		}
+	}
+}
+
+func TestParseKeyBindingRejects(t *testing.T) {
+	for _, in := range []string{"", "P", "Shift+P", "Ctrl+", "Ctrl+F13", "Hyper+P", "Ctrl+Banana", "Ctrl+Z", "Ctrl+V", "Ctrl+A"} {
+		if _, err := parseKeyBinding(in); err == nil {
+			t.Fatalf("expected %q to be rejected", in)
+		}
+	}
+}
+
+func TestDefaultKeymapHasNoConflicts(t *testing.T) {
+	commands := (&devKitState{}).devKitCommands()
+	bindings, problems := resolveKeymap(commands, nil)
+	if len(problems) != 0 {
+		t.Fatalf("default keymap problems: %v", problems)
+	}
+	seen := map[string]bool{}
+	for _, cmd := range commands {
+		if seen[cmd.id] {
+			t.Fatalf("duplicate command id %q", cmd.id)
+		}
+		seen[cmd.id] = true
+	}
+	palette := bindings["view.command_palette"]
+	if palette == nil || palette.KeyName != fyne.KeyP || palette.Modifier != fyne.KeyModifierShortcutDefault|fyne.KeyModifierShift {
+		t.Fatalf("expected Ctrl+Shift+P for the command palette, got %v", palette)
+	}
+}
+
+func TestResolveKeymapOverrides(t *testing.T) {
+	commands := []devKitCommand{
+		{id: "a", defaultKey: "Ctrl+B"},
+		{id: "b", defaultKey: "Ctrl+R"},
+		{id: "c", defaultKey: "Ctrl+J"},
+		{id: "d"},
+	}
+	bindings, problems := resolveKeymap(commands, map[string]string{
+		"a": "Ctrl+Shift+B", // rebound
+		"b": "",             // unbound
+		"c": "Shift+J",      // invalid: falls back to the default
+		"d": "Ctrl+Shift+B", // conflicts with a, which is listed first
+	})
+	if got := formatKeyBinding(bindings["a"]); got != "Ctrl+Shift+B" {
+		t.Fatalf("a = %q", got)
+	}
+	if bindings["b"] != nil {
+		t.Fatalf("expected b unbound, got %v", bindings["b"])
+	}
+	if got := formatKeyBinding(bindings["c"]); got != "Ctrl+J" {
+		t.Fatalf("c = %q, want default", got)
+	}
+	if bindings["d"] != nil {
+		t.Fatalf("expected conflicting d dropped")
+	}
+	if len(problems) != 2 {
+		t.Fatalf("expected 2 problems, got %v", problems)
+	}
+}
+
+func TestFilterPaletteItemsRanksFuzzyMatches(t *testing.T) {
+	items := []paletteItem{
+		{category: "Debug", title: "Step Frame"},
+		{category: "View", title: "Toggle Diagnostics Panel"},
+		{category: "Tools", title: "Layout: Debug Mode"},
+	}
+	if got := filterPaletteItems(items, ""); len(got) != 3 || got[0].title != "Step Frame" {
+		t.Fatalf("empty query should keep order, got %v", got)
+	}
+	got := filterPaletteItems(items, "tdp")
+	if len(got) == 0 || got[0].title != "Toggle Diagnostics Panel" {
+		t.Fatalf("expected Toggle Diagnostics Panel first, got %v", got)
+	}
+	got = filterPaletteItems(items, "debug")
+	if len(got) != 2 || got[0].title != "Step Frame" {
+		t.Fatalf("expected both debug matches in order, got %v", got)
+	}
+	if got := filterPaletteItems(items, "zzz"); len(got) != 0 {
+		t.Fatalf("expected no matches, got %v", got)
+	}
+}
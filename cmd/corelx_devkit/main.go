@@ -1,19 +1,18 @@
 package main
 
 import (
-	"encoding/binary"
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"image"
-	"image/color"
 	"io"
-	"math"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"fyne.io/fyne/v2"
@@ -22,7 +21,6 @@ import (
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/driver/desktop"
-	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/storage"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
@@ -31,6 +29,11 @@ import (
 	"nitro-core-dx/internal/apu"
 	"nitro-core-dx/internal/corelx"
 	"nitro-core-dx/internal/devkit"
+	"nitro-core-dx/internal/emulator"
+	"nitro-core-dx/internal/fileassoc"
+	"nitro-core-dx/internal/i18n"
+	"nitro-core-dx/internal/input"
+	"nitro-core-dx/internal/rom"
 )
 
 const (
@@ -68,6 +71,12 @@ type emulatorKeyOverlay struct {
 	onTyped   func(*fyne.KeyEvent)
 	onKeyDown func(*fyne.KeyEvent)
 	onKeyUp   func(*fyne.KeyEvent)
+
+	// describe supplies the accessible label and announce speaks it when the
+	// surface takes focus (see accessibleLabeler).
+	describe  func() string
+	announce  func(string)
+	focusRing *canvas.Rectangle
 }
 
 func newEmulatorKeyOverlay(onTap func(), onTyped, onKeyDown, onKeyUp func(*fyne.KeyEvent)) *emulatorKeyOverlay {
@@ -82,8 +91,8 @@ func newEmulatorKeyOverlay(onTap func(), onTyped, onKeyDown, onKeyUp func(*fyne.
 }
 
 func (w *emulatorKeyOverlay) CreateRenderer() fyne.WidgetRenderer {
-	rect := canvas.NewRectangle(color.Transparent)
-	return widget.NewSimpleRenderer(rect)
+	w.focusRing = newFocusRing()
+	return widget.NewSimpleRenderer(w.focusRing)
 }
 
 func (w *emulatorKeyOverlay) Tapped(*fyne.PointEvent) {
@@ -93,10 +102,27 @@ func (w *emulatorKeyOverlay) Tapped(*fyne.PointEvent) {
 }
 
 func (w *emulatorKeyOverlay) TappedSecondary(*fyne.PointEvent) {}
-func (w *emulatorKeyOverlay) FocusGained()                     {}
-func (w *emulatorKeyOverlay) FocusLost()                       {}
 func (w *emulatorKeyOverlay) TypedRune(r rune)                 {}
 
+func (w *emulatorKeyOverlay) FocusGained() {
+	showFocusRing(w.focusRing, true)
+	if w.announce != nil {
+		w.announce(w.AccessibleLabel())
+	}
+}
+
+func (w *emulatorKeyOverlay) FocusLost() {
+	showFocusRing(w.focusRing, false)
+}
+
+// AccessibleLabel describes the emulator surface for accessibleLabeler.
+func (w *emulatorKeyOverlay) AccessibleLabel() string {
+	if w.describe != nil {
+		return w.describe()
+	}
+	return i18n.T("Emulator display")
+}
+
 func (w *emulatorKeyOverlay) TypedKey(ev *fyne.KeyEvent) {
 	if w.onTyped != nil {
 		w.onTyped(ev)
@@ -122,14 +148,39 @@ type devKitState struct {
 	launchDir    string
 	settingsPath string
 	settings     devKitSettings
+	// settingsBase is the settings as last loaded or saved; persistSettings
+	// diffs against it to find what this instance changed. recentOpened
+	// lists the files opened since then, oldest first.
+	settingsBase devKitSettings
+	recentOpened []string
+	instance     *devKitInstance
+	keymap       map[string]*desktop.CustomShortcut
 
 	currentPath  string
 	lastROMPath  string
 	autosavePath string
 	dirty        bool
 
+	sessionPath    string
+	sessionROMPath string
+	autosaveTimer  *time.Timer
+
+	// The background build (see background_build.go): its idle timer, the
+	// running check's cancel, and a sequence number that each edit or
+	// build bumps so stale checks drop their results.
+	backgroundBuildTimer  *time.Timer
+	backgroundBuildCancel context.CancelFunc
+	backgroundBuildSeq    uint64
+	backgroundBuildChip   *backgroundBuildChip
+
 	diagnostics         []corelx.Diagnostic
 	filteredDiagnostics []corelx.Diagnostic
+	// builtSource and builtSourcePath are what the last build compiled;
+	// diagnostic fixes apply only while the editor still holds that text.
+	builtSource     string
+	builtSourcePath string
+	// liveAssets is the running build's asset hot-reload watch, or nil.
+	liveAssets *liveAssetWatch
 
 	window          fyne.Window
 	centerHost      *fyne.Container
@@ -141,12 +192,18 @@ type devKitState struct {
 	sourceEditor    *coreLXCodeEditor
 	buildOutput     *widget.Entry
 	manifestOutput  *widget.Entry
+	manifestChart   *fyne.Container // layout and budget bars above manifestOutput
+	listingOutput   *widget.Entry
 	debuggerOutput  *widget.Entry
+	debuggerPane    fyne.CanvasObject // debuggerOutput under the fault card
+	faultCard       *faultCard
 
 	diagnosticFilter  *widget.Select
 	diagnosticSearch  *widget.Entry
 	diagnosticsList   *widget.List
+	diagnosticIndex   int // selected row of filteredDiagnostics, or -1
 	diagnosticDetail  *widget.Entry
+	diagnosticFixes   *diagnosticFixBar
 	diagnosticSummary *widget.Label
 	diagnosticsToggle *widget.Button
 	stepFrameEntry    *widget.Entry
@@ -154,6 +211,7 @@ type devKitState struct {
 
 	emuSurface       fyne.CanvasObject
 	captureCheck     *widget.Check
+	popOutBtn        *widget.Button
 	bottomLeftTabs   *container.AppTabs
 	leftSplit        *container.Split
 	mainSplit        *container.Split
@@ -171,12 +229,49 @@ type devKitState struct {
 	emuKeys    *emulatorKeyOverlay
 	emuLabel   *widget.Label
 	audioDev   sdl.AudioDeviceID
+	audioMu    sync.Mutex // guards audioFrame
 	audioFrame []byte
+	audioMixer *audioMixerPanel
+
+	// audioQueueFrames caps the SDL queue depth in emulator frames; read by
+	// the emulator and audio loops, written by the Preferences dialog.
+	audioQueueFrames atomic.Int32
+
+	// The audio thread (see audio_loop.go): whether it is pacing emulation,
+	// and what it has run that the UI ticker has not yet presented.
+	audioPacing   atomic.Bool
+	audioLoopDone chan struct{}
+	pendingMu     sync.Mutex
+	pendingTick   devkit.TickResult
+	pendingErr    error
+
+	// Run configurations of the current project (see run_configs.go).
+	runConfigs       devkit.RunConfigs
+	runConfigDir     string
+	runConfigsLoaded bool
+	runConfigSelect  *widget.Select
+
+	// movieStatus is the movie last reported in Output (see movie.go).
+	movieStatus devkit.MovieStatus
+
+	inputViewer  *inputViewerPanel
+	ioRegisters  *ioRegisterPanel
+	memoryViewer *memoryViewerPanel
+
+	referenceEntries []referenceEntry
+	referenceReveal  func(int)
+	referenceSearch  func(string)
+
+	immediateConsoleInput *widget.Entry
+	// immediateConsoleTranscript is the Console tab's output, which also
+	// receives the ROM's test port text.
+	immediateConsoleTranscript *widget.Entry
 
 	frameImages [2]*image.RGBA
 	frameIdx    int
 
 	updateLoopStop chan struct{}
+	emuLoopWake    chan struct{}
 	updateLoopOnce sync.Once
 
 	keyMu            sync.Mutex
@@ -184,22 +279,39 @@ type devKitState struct {
 	typedKeyUntil    map[fyne.KeyName]time.Time
 	desktopKeyEvents bool
 	captureGameInput bool
+	virtualButtons   uint16
 	spriteLabHotkey  func(fyne.KeyName) bool
 	spriteLabUndo    func()
 	spriteLabRedo    func()
 
 	suppressSourceChange bool
 	diagnosticsCollapsed bool
+
+	// Pop-out windows (see popout.go); nil while docked.
+	emuWindow        fyne.Window
+	debugWindow      fyne.Window
+	debuggerTabIndex int
 }
 
 func main() {
 	openPath := flag.String("file", "", "CoreLX source file to open")
+	openTarget := flag.String("open", "", "File to open: CoreLX source, .rom, or .ncdx (path or file:// URL, as passed by desktop launchers)")
+	registerTypes := flag.Bool("register-file-types", false, "Register the Dev Kit as the desktop handler for .corelx/.rom files and exit")
 	audioBackend := flag.String("audio-backend", "", "Audio backend override: ymfm (default: ymfm)")
 	flag.Parse()
 	if err := applyAudioBackendSetting(*audioBackend); err != nil {
 		fmt.Fprintf(os.Stderr, "invalid audio backend: %v\n", err)
 		os.Exit(1)
 	}
+	if *registerTypes {
+		if err := registerFileTypes(); err != nil {
+			fmt.Fprintf(os.Stderr, "register file types: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	startPath, startErr := resolveOpenFlag(*openTarget, *openPath, flag.Args())
+	openSource := startPath != "" && !isExternalROMPath(startPath)
 
 	tempDir, err := os.MkdirTemp("", "nitro-core-dx-devkit-*")
 	if err != nil {
@@ -215,13 +327,19 @@ func main() {
 	settingsPath := devKitSettingsPath()
 	settings, settingsErr := loadDevKitSettings(settingsPath)
 	launchDir, _ := os.Getwd()
-
-	if settings.UIDensity == "standard" {
-		a.Settings().SetTheme(newStandardTheme())
-	} else {
-		a.Settings().SetTheme(newCompactTheme())
+	var instance *devKitInstance
+	var instanceErr error
+	instanceSlot := 0
+	if settingsPath != "" {
+		instance, instanceErr = claimInstanceSlot(filepath.Dir(settingsPath))
+	}
+	if instance != nil {
+		instanceSlot = instance.slot
 	}
 
+	a.Settings().SetTheme(newDevKitTheme(settings.UIDensity, settings.Theme))
+	applyLanguage(settings.Language)
+
 	w := a.NewWindow("Nitro-Core-DX")
 	w.SetFixedSize(false)
 	w.Resize(fyne.NewSize(defaultWindowWidth, defaultWindowHeight))
@@ -239,7 +357,10 @@ func main() {
 		launchDir:            launchDir,
 		settingsPath:         settingsPath,
 		settings:             settings,
-		autosavePath:         devKitAutosavePath(settingsPath),
+		settingsBase:         cloneDevKitSettings(settings),
+		instance:             instance,
+		autosavePath:         instanceFilePath(devKitAutosavePath(settingsPath), instanceSlot),
+		sessionPath:          instanceFilePath(devKitSessionPath(settingsPath), instanceSlot),
 		window:               w,
 		currentView:          initialView,
 		statusLabel:          widget.NewLabel("Ready"),
@@ -249,37 +370,56 @@ func main() {
 		filteredDiagnostics:  make([]corelx.Diagnostic, 0),
 		buildOutput:          newReadOnlyTextArea(),
 		manifestOutput:       newReadOnlyTextArea(),
+		listingOutput:        newReadOnlyTextArea(),
 		diagnosticDetail:     newReadOnlyTextArea(),
 		emuScale:             2,
 		keyStates:            make(map[fyne.KeyName]bool),
 		typedKeyUntil:        make(map[fyne.KeyName]time.Time),
 		captureGameInput:     settings.CaptureGameInput,
 		updateLoopStop:       make(chan struct{}),
-		audioFrame:           make([]byte, 735*2*4),
+		emuLoopWake:          make(chan struct{}, 1),
+		audioFrame:           make([]byte, audioFrameBytes),
 		diagnosticsCollapsed: !settings.DiagnosticsPanel,
+		diagnosticIndex:      -1,
 	}
 	if settingsErr != nil {
 		fmt.Fprintf(os.Stderr, "settings load warning: %v\n", settingsErr)
 	}
+	if instanceErr != nil {
+		fmt.Fprintf(os.Stderr, "instance slot warning: %v\n", instanceErr)
+	}
 	state.backend = devkit.NewService(tempDir)
+	state.backend.SetDeterministic(settings.Deterministic)
+	state.backend.SetSpeed(settings.EmulatorSpeed)
+	turboMask, _ := input.ParseButtons(settings.TurboButtons)
+	state.backend.SetTurbo(turboMask, settings.TurboHz)
+	state.audioQueueFrames.Store(int32(settings.AudioLatencyFrames))
 	if err := state.initAudio(); err != nil {
 		state.appendBuildOutput("Audio init warning: " + err.Error())
 		state.setStatus("Ready (audio unavailable)")
 	}
 	state.initUI()
-	state.window.SetMainMenu(state.buildMainMenu())
+	state.applyEditorPreferences()
+	state.applyKeymap()
 	// Apply X11 maximize hint before first show so WM sees it when mapping (Linux/X11).
 	_ = applyX11MaximizeHint(state.window)
 	state.scheduleX11MaximizeHintRefresh()
 	state.setupKeyboardInput()
+	state.restoreDetachedWindows()
 	state.startEmulatorLoop()
+	state.startAudioLoop()
+	state.startAssetHotReload()
 
-	if *openPath != "" {
-		if err := state.loadFile(*openPath, true); err != nil {
+	if startErr != nil {
+		state.appendBuildOutput(fmt.Sprintf("Open error: %v", startErr))
+		state.setStatus("Open failed")
+	}
+	if openSource {
+		if err := state.loadFile(startPath, true); err != nil {
 			state.appendBuildOutput(fmt.Sprintf("Open error: %v", err))
 			state.setStatus("Open failed")
 		}
-	} else if state.settings.LastOpenFile != "" {
+	} else if state.settings.LastOpenFile != "" && instanceSlot == 0 {
 		if err := state.loadFile(state.settings.LastOpenFile, false); err != nil {
 			state.appendBuildOutput(fmt.Sprintf("Session restore warning: %v", err))
 		}
@@ -287,20 +427,40 @@ func main() {
 		state.dirty = false
 		state.refreshTitle()
 	}
-	if *openPath == "" {
-		state.tryRecoverAutosave()
-	}
 	if state.settings.LastROMPath != "" {
 		state.lastROMPath = state.settings.LastROMPath
 	}
+	if startPath != "" && !openSource {
+		if err := state.loadExternalROM(startPath); err != nil {
+			state.appendBuildOutput(fmt.Sprintf("Open error: %v", err))
+			state.setStatus("Open failed")
+		}
+	}
+	switch {
+	case startPath == "":
+		state.recoverSessionOrAutosave()
+	case !openSource:
+		state.tryRecoverAutosave()
+		state.startSessionJournal()
+	default:
+		state.startSessionJournal()
+	}
+	w.SetOnDropped(func(_ fyne.Position, uris []fyne.URI) {
+		state.handleDroppedURIs(uris)
+	})
 
 	w.SetCloseIntercept(func() {
 		state.captureLayoutState()
+		state.captureDetachedWindows()
+		state.closeDetachedWindows()
 		state.writeAutosaveSnapshot(state.sourceEditor.Text())
 		state.stopEmulatorLoop()
+		state.clearSessionJournal()
 		state.shutdownEmbeddedEmulator()
 		state.shutdownAudio()
 		state.persistSettings()
+		state.instance.release()
+		_ = os.RemoveAll(state.tempDir)
 		w.Close()
 	})
 
@@ -353,15 +513,18 @@ func (s *devKitState) scheduleX11MaximizeHintRefresh() {
 func (s *devKitState) initUI() {
 	s.sourceEditor = newCoreLXCodeEditor()
 	s.sourceEditor.SetText(defaultTemplate)
+	s.backgroundBuildChip = newBackgroundBuildChip()
 	s.sourceEditor.SetOnChanged(func(text string) {
 		if s.suppressSourceChange {
 			return
 		}
 		s.dirty = true
 		s.refreshTitle()
-		s.writeAutosaveSnapshot(text)
+		s.scheduleAutosave(text)
 		s.setBuildState("Draft")
+		s.scheduleBackgroundBuild()
 	})
+	s.sourceEditor.SetOnContextHelp(s.showContextHelp)
 
 	s.diagnosticFilter = widget.NewSelect([]string{"All", "Errors", "Warnings", "Info"}, func(string) {
 		s.applyDiagnosticFilter()
@@ -411,6 +574,7 @@ func (s *devKitState) initUI() {
 		if id < 0 || id >= len(s.filteredDiagnostics) {
 			return
 		}
+		s.diagnosticIndex = id
 		d := s.filteredDiagnostics[id]
 		s.showDiagnosticDetail(d)
 		s.jumpToDiagnostic(d)
@@ -428,6 +592,8 @@ func (s *devKitState) initUI() {
 		func(key *fyne.KeyEvent) { s.handleKeyDown(key) },
 		func(key *fyne.KeyEvent) { s.handleKeyUp(key) },
 	)
+	s.emuKeys.describe = s.emulatorAccessibleLabel
+	s.emuKeys.announce = s.setStatus
 	s.emuLabel = widget.NewLabel("Hardware: idle")
 	s.emuSurface = container.NewStack(s.emuImage, s.emuKeys)
 
@@ -440,6 +606,7 @@ func (s *devKitState) initUI() {
 		s.persistSettings()
 	})
 	s.captureCheck.SetChecked(s.captureGameInput)
+	s.popOutBtn = widget.NewButton("Pop Out", func() { s.toggleEmulatorWindow() })
 
 	s.diagnosticSummary = widget.NewLabel("Errors: 0 | Warnings: 0 | Info: 0")
 	s.diagnosticsToggle = widget.NewButton("Collapse", func() {
@@ -451,7 +618,7 @@ func (s *devKitState) initUI() {
 		s.diagnosticSearch,
 		s.diagnosticsToggle,
 	)
-	diagSplit := container.NewVSplit(s.diagnosticsList, s.diagnosticDetail)
+	diagSplit := container.NewVSplit(s.diagnosticsList, s.buildDiagnosticDetailPane())
 	diagSplit.Offset = 0.62
 	diagPane := container.NewBorder(
 		diagToolbar,
@@ -459,27 +626,47 @@ func (s *devKitState) initUI() {
 		diagSplit,
 	)
 	outputPane := s.buildOutput
-	manifestPane := s.manifestOutput
-	debugPane := s.debuggerOutput
+	s.manifestChart = container.NewVBox()
+	manifestPane := container.NewBorder(s.manifestChart, nil, nil, nil, s.manifestOutput)
+	debugPane := s.buildDebuggerPane()
+	audioPane := s.buildAudioMixerPane()
+	inputPane := s.buildInputViewerPane()
+	consolePane := s.buildImmediateConsolePane()
+	ioPane := s.buildIORegisterPane()
+	memoryPane := s.buildMemoryViewerPane()
+	helpPane := s.buildHelpPane()
 	s.bottomLeftTabs = container.NewAppTabs(
 		container.NewTabItem("Diagnostics", diagPane),
 		container.NewTabItem("Output", outputPane),
 		container.NewTabItem("Manifest", manifestPane),
-		container.NewTabItem("Debugger", debugPane),
+		container.NewTabItem("Listing", s.listingOutput),
+		container.NewTabItem(debuggerTabName, debugPane),
+		container.NewTabItem("Console", consolePane),
+		container.NewTabItem(ioRegisterTabName, ioPane),
+		container.NewTabItem(memoryViewerTabName, memoryPane),
+		container.NewTabItem("Audio", audioPane),
+		container.NewTabItem("Input", inputPane),
+		container.NewTabItem("Help", helpPane),
 	)
+	s.bottomLeftTabs.OnSelected = func(*container.TabItem) {
+		s.refreshIORegisters()
+		s.refreshMemoryViewer()
+	}
 
 	s.editorPane = container.NewBorder(
-		container.NewVBox(s.pathLabel, s.buildStateLabel),
+		container.NewVBox(s.pathLabel, container.NewHBox(s.buildStateLabel, s.backgroundBuildChip.box)),
 		nil, nil, nil,
 		s.sourceEditor,
 	)
 	spriteLabPane := s.buildSpriteLabPane()
 	tilemapPane := s.buildTilemapPane()
+	palettePane := s.buildPaletteEditorPane()
 	soundStudioPlaceholder := widget.NewLabel("Sound Studio (coming next)\n\nPlanned: music/ambience/SFX authoring integrated with packaging.")
 	s.workbenchTabs = container.NewAppTabs(
 		container.NewTabItem("Code", s.editorPane),
 		container.NewTabItem("Sprite Lab", spriteLabPane),
 		container.NewTabItem("Tilemap", tilemapPane),
+		container.NewTabItem("Palette", palettePane),
 		container.NewTabItem("Sound", container.NewScroll(soundStudioPlaceholder)),
 	)
 
@@ -524,6 +711,9 @@ func (s *devKitState) buildToolbar() fyne.CanvasObject {
 	buildBtn := widget.NewButton("Build", func() { s.runBuild(false) })
 	buildRunBtn := widget.NewButton("Build + Run", func() { s.runBuild(true) })
 	buildRunBtn.Importance = widget.HighImportance
+	s.runConfigSelect = widget.NewSelect(nil, func(name string) { s.selectRunConfig(name) })
+	s.syncRunConfigs()
+	s.refreshRunConfigSelect()
 
 	s.runBtn = widget.NewButton("Run", func() { s.runEmulator() })
 	s.pauseBtn = widget.NewButton("Pause", func() { s.pauseEmulator() })
@@ -549,6 +739,7 @@ func (s *devKitState) buildToolbar() fyne.CanvasObject {
 		widget.NewSeparator(),
 		buildBtn,
 		buildRunBtn,
+		s.runConfigSelect,
 		widget.NewSeparator(),
 		s.runBtn,
 		s.pauseBtn,
@@ -571,11 +762,10 @@ func (s *devKitState) setViewMode(mode viewMode) {
 
 	switch mode {
 	case viewModeEmulatorOnly:
-		emuLayout := container.NewBorder(
-			container.NewHBox(s.emuLabel, layout.NewSpacer(), s.captureCheck),
-			nil, nil, nil,
-			s.emuSurface,
-		)
+		emuLayout := s.emulatorPane(s.popOutBtn)
+		if s.emuWindow != nil {
+			emuLayout = s.detachedEmulatorPlaceholder()
+		}
 		s.centerHost.Objects = []fyne.CanvasObject{emuLayout}
 		s.setStatus("View: Emulator Focus")
 		if s.captureGameInput {
@@ -586,13 +776,20 @@ func (s *devKitState) setViewMode(mode viewMode) {
 		codeLayout.Offset = 0.72
 		s.centerHost.Objects = []fyne.CanvasObject{codeLayout}
 		s.setStatus("View: Code Only")
+	case viewModeFull:
+		if s.emuWindow != nil {
+			// The emulator is popped out: the lower tabs take the left
+			// column.
+			s.leftSplit = nil
+			s.mainSplit = container.NewHSplit(s.bottomLeftTabs, s.workbenchTabs)
+			s.mainSplit.Offset = clampOffset(s.settings.MainSplitOffset, defaultMainSplitOffset)
+			s.centerHost.Objects = []fyne.CanvasObject{s.mainSplit}
+			s.setStatus("View: Split View")
+			break
+		}
+		fallthrough
 	default:
-		emuPane := container.NewBorder(
-			container.NewHBox(s.emuLabel, layout.NewSpacer(), s.captureCheck),
-			nil, nil, nil,
-			s.emuSurface,
-		)
-		s.leftSplit = container.NewVSplit(emuPane, s.bottomLeftTabs)
+		s.leftSplit = container.NewVSplit(s.emulatorPane(s.popOutBtn), s.bottomLeftTabs)
 		s.leftSplit.Offset = clampOffset(s.settings.LeftSplitOffset, defaultLeftSplitOffset)
 		if s.diagnosticsCollapsed {
 			s.leftSplit.Offset = 1.0
@@ -643,6 +840,7 @@ func (s *devKitState) applyDiagnosticFilter() {
 		}
 		s.filteredDiagnostics = append(s.filteredDiagnostics, d)
 	}
+	s.diagnosticIndex = -1
 	if s.diagnosticsList != nil {
 		s.diagnosticsList.UnselectAll()
 		s.diagnosticsList.Refresh()
@@ -690,7 +888,7 @@ func (s *devKitState) showOpenProjectDialog() {
 	}
 	options := []filterOpt{
 		{Label: "CoreLX Project Source (*.corelx, *.clx, *.txt)", Kind: "source"},
-		{Label: "Build Output (*.rom)", Kind: "rom"},
+		{Label: "Build Output (*.rom, *.zip)", Kind: "rom"},
 		{Label: "All Supported", Kind: "all"},
 	}
 	filterSel := widget.NewSelect([]string{options[0].Label, options[1].Label, options[2].Label}, nil)
@@ -733,8 +931,8 @@ func (s *devKitState) showOpenProjectDialog() {
 			s.setStatus("Select a recent project first")
 			return
 		}
-		if strings.HasSuffix(strings.ToLower(selectedRecent), ".rom") {
-			data, err := os.ReadFile(selectedRecent)
+		if isBuildArtifactPath(selectedRecent) {
+			data, err := fileassoc.ReadROM(selectedRecent)
 			if err != nil {
 				dialog.ShowError(err, s.window)
 				return
@@ -744,7 +942,9 @@ func (s *devKitState) showOpenProjectDialog() {
 				return
 			}
 			s.lastROMPath = selectedRecent
+			s.sessionROMPath = selectedRecent
 			s.rememberROMPath(selectedRecent)
+			s.appendROMInfo(data)
 			s.setStatus("Loaded project build artifact")
 			d.Hide()
 			return
@@ -804,13 +1004,19 @@ func (s *devKitState) openAnyProjectDialog() {
 			dialog.ShowError(readErr, s.window)
 			return
 		}
-		if strings.HasSuffix(strings.ToLower(path), ".rom") {
+		if isBuildArtifactPath(path) {
+			if data, readErr = fileassoc.UnpackROM(path, data); readErr != nil {
+				dialog.ShowError(readErr, s.window)
+				return
+			}
 			if loadErr := s.loadROMIntoEmbedded(data); loadErr != nil {
 				dialog.ShowError(loadErr, s.window)
 				return
 			}
 			s.lastROMPath = path
+			s.sessionROMPath = path
 			s.rememberROMPath(path)
+			s.appendROMInfo(data)
 			s.setStatus("Loaded project build artifact")
 			return
 		}
@@ -822,7 +1028,7 @@ func (s *devKitState) openAnyProjectDialog() {
 		s.setStatus("Opened project")
 		s.appendBuildOutput("Opened " + s.currentPath)
 	}, s.window)
-	fd.SetFilter(storage.NewExtensionFileFilter([]string{".corelx", ".clx", ".txt", ".rom"}))
+	fd.SetFilter(storage.NewExtensionFileFilter([]string{".corelx", ".clx", ".txt", ".rom", ".zip"}))
 	if loc := dialogListableForDir(s.defaultProjectOpenDir()); loc != nil {
 		fd.SetLocation(loc)
 	}
@@ -840,6 +1046,9 @@ func (s *devKitState) openROMDialog() {
 		}
 		defer rc.Close()
 		data, readErr := io.ReadAll(rc)
+		if readErr == nil {
+			data, readErr = fileassoc.UnpackROM(rc.URI().Name(), data)
+		}
 		if readErr != nil {
 			dialog.ShowError(readErr, s.window)
 			return
@@ -851,12 +1060,14 @@ func (s *devKitState) openROMDialog() {
 			return
 		}
 		s.lastROMPath = uriPath(rc.URI())
+		s.sessionROMPath = s.lastROMPath
 		s.rememberROMPath(s.lastROMPath)
 		s.setViewMode(viewModeFull)
 		s.appendBuildOutput("Loaded build artifact into emulator subsystem: " + s.lastROMPath)
+		s.appendROMInfo(data)
 		s.setStatus("Project build loaded")
 	}, s.window)
-	fd.SetFilter(storage.NewExtensionFileFilter([]string{".rom"}))
+	fd.SetFilter(storage.NewExtensionFileFilter([]string{".rom", ".zip"}))
 	if loc := dialogListableForDir(s.defaultROMDialogDir()); loc != nil {
 		fd.SetLocation(loc)
 	}
@@ -873,6 +1084,9 @@ func (s *devKitState) saveAsDialog() {
 			return
 		}
 		defer wc.Close()
+		if s.settings.EditorFormatOnSave {
+			s.formatSource()
+		}
 		if _, writeErr := wc.Write([]byte(s.sourceEditor.Text())); writeErr != nil {
 			dialog.ShowError(writeErr, s.window)
 			return
@@ -903,6 +1117,9 @@ func (s *devKitState) save() error {
 		s.saveAsDialog()
 		return nil
 	}
+	if s.settings.EditorFormatOnSave {
+		s.formatSource()
+	}
 	if err := os.WriteFile(s.currentPath, []byte(s.sourceEditor.Text()), 0644); err != nil {
 		return err
 	}
@@ -915,6 +1132,25 @@ func (s *devKitState) save() error {
 	return nil
 }
 
+// formatSource rewrites the editor text in canonical CoreLX layout, keeping
+// the cursor on its line. Source that does not parse is left alone and the
+// reason goes to the Output pane.
+func (s *devKitState) formatSource() bool {
+	text := s.sourceEditor.Text()
+	formatted, err := corelx.Format(text)
+	if err != nil {
+		s.appendBuildOutput("Format skipped: " + err.Error())
+		s.setStatus("Format skipped: source does not parse")
+		return false
+	}
+	if formatted != text {
+		row, _ := s.sourceEditor.Cursor()
+		s.setSourceContent(formatted, true, false)
+		s.sourceEditor.SetCursor(row, 0)
+	}
+	return true
+}
+
 func (s *devKitState) loadFile(path string, clearAutosave bool) error {
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -934,6 +1170,7 @@ func (s *devKitState) setSourceContent(text string, dirty bool, clearAutosave bo
 	s.sourceEditor.SetText(text)
 	s.suppressSourceChange = false
 	s.setBuildState("Draft")
+	s.scheduleBackgroundBuild()
 	s.dirty = dirty
 	if dirty {
 		s.writeAutosaveSnapshot(text)
@@ -961,6 +1198,7 @@ func (s *devKitState) refreshTitle() {
 		viewLabel = "Split View"
 	}
 	s.window.SetTitle("Nitro-Core-DX - " + name + " [" + viewLabel + "]")
+	s.syncRunConfigs()
 }
 
 func (s *devKitState) runBuild(runAfter bool) {
@@ -974,12 +1212,26 @@ func (s *devKitState) runBuild(runAfter bool) {
 	}
 	romOut := pathJoin(s.tempDir, artifactBase+".rom")
 
+	s.stopBackgroundBuild()
 	start := time.Now()
 	s.setStatus("Building...")
 	s.setBuildState("Validating...")
-	s.appendBuildOutput(fmt.Sprintf("Build started (%s)", sourcePath))
+	s.syncRunConfigs()
+	cfg := s.runConfigs.Selected()
+	buildLabel := cfg.Name
+	if cfg.Target != "" {
+		buildLabel += ", " + cfg.Target + " target"
+	}
+	if cfg.ValidateHardware {
+		buildLabel += ", hardware check"
+	}
+	if len(cfg.Defines) > 0 {
+		buildLabel += ", flags " + strings.Join(cfg.Defines, " ")
+	}
+	s.appendBuildOutput(fmt.Sprintf("Build started (%s, %s)", sourcePath, buildLabel))
 
-	buildResult, err := s.backend.BuildSource(s.sourceEditor.Text(), sourcePath)
+	s.builtSource, s.builtSourcePath = s.sourceEditor.Text(), sourcePath
+	buildResult, err := s.backend.BuildSourceWith(s.builtSource, sourcePath, cfg)
 	elapsed := time.Since(start)
 	var bundle corelx.CompileBundle
 	var res *corelx.CompileResult
@@ -997,6 +1249,7 @@ func (s *devKitState) runBuild(runAfter bool) {
 	s.applyDiagnosticFilter()
 
 	s.updateManifestPane(bundle, res)
+	s.updateListingPane(res)
 	s.appendBuildSummary(bundle, res, err, elapsed)
 
 	if err != nil {
@@ -1015,6 +1268,9 @@ func (s *devKitState) runBuild(runAfter bool) {
 				s.setStatus("Build succeeded; run failed")
 				return
 			}
+			s.sessionROMPath = ""
+			s.armAssetHotReload(s.builtSource, sourcePath, cfg.Defines)
+			s.startWithRunConfig(cfg)
 			s.setViewMode(viewModeFull)
 			s.appendBuildOutput("Project build loaded into emulator subsystem")
 			s.setStatus("Build + Run completed")
@@ -1043,6 +1299,23 @@ func (s *devKitState) updateManifestPane(bundle corelx.CompileBundle, res *corel
 	s.manifestOutput.Enable()
 	s.manifestOutput.SetText(text)
 	s.manifestOutput.Disable()
+	if res != nil && res.Manifest != nil {
+		s.updateManifestChart(res.Manifest)
+	} else {
+		s.updateManifestChart(bundle.Manifest)
+	}
+}
+
+// updateListingPane shows the build's source listing: each CoreLX line
+// followed by the instructions generated for it. A failed build keeps the
+// last good listing.
+func (s *devKitState) updateListingPane(res *corelx.CompileResult) {
+	if res == nil || len(res.Listing) == 0 {
+		return
+	}
+	s.listingOutput.Enable()
+	s.listingOutput.SetText(string(res.Listing))
+	s.listingOutput.Disable()
 }
 
 func (s *devKitState) appendBuildSummary(bundle corelx.CompileBundle, res *corelx.CompileResult, buildErr error, elapsed time.Duration) {
@@ -1072,11 +1345,15 @@ func (s *devKitState) loadROMIntoEmbedded(romBytes []byte) error {
 	if err := s.backend.LoadROMBytes(romBytes); err != nil {
 		return err
 	}
+	// A fresh session starts with no buttons; re-send latched virtual ones.
+	s.routeInputToEmulator()
 	if s.audioDev != 0 {
 		sdl.ClearQueuedAudio(s.audioDev)
 	}
+	s.wakeEmulatorLoop()
 
 	fyne.Do(func() {
+		s.clearFault()
 		s.emuLabel.SetText("Hardware: running")
 		if s.captureGameInput {
 			s.focusEmulatorInput()
@@ -1085,22 +1362,42 @@ func (s *devKitState) loadROMIntoEmbedded(romBytes []byte) error {
 	return nil
 }
 
+// appendROMInfo lists a loaded ROM's header, checksum, bank usage and
+// metadata (as nitrotool info does) in the build output.
+func (s *devKitState) appendROMInfo(romBytes []byte) {
+	info, err := rom.Inspect(romBytes)
+	if err != nil {
+		s.appendBuildOutput("ROM info: " + err.Error())
+		return
+	}
+	s.appendBuildOutput("ROM info:\n" + info.Summary())
+}
+
 func (s *devKitState) shutdownEmbeddedEmulator() {
 	s.backend.Shutdown()
 }
 
 func (s *devKitState) startEmulatorLoop() {
 	go func() {
-		const uiTickHz = 120
-		ticker := time.NewTicker(time.Second / uiTickHz)
-		defer ticker.Stop()
+		timer := time.NewTimer(0)
+		defer timer.Stop()
 
 		lastTick := time.Now()
+		wasRunning := false
 		for {
+			woken := false
 			select {
 			case <-s.updateLoopStop:
 				return
-			case <-ticker.C:
+			case <-timer.C:
+			case <-s.emuLoopWake:
+				woken = true
+				if !timer.Stop() {
+					select {
+					case <-timer.C:
+					default:
+					}
+				}
 			}
 
 			now := time.Now()
@@ -1109,52 +1406,119 @@ func (s *devKitState) startEmulatorLoop() {
 			if delta > 250*time.Millisecond {
 				delta = 250 * time.Millisecond
 			}
+			if !wasRunning {
+				// Time spent idle or paused is not owed to the ROM.
+				delta = 0
+			}
 
 			s.routeInputToEmulator()
-			tick, err := s.backend.Tick(delta)
-			if err != nil {
-				fyne.Do(func() {
-					s.appendBuildOutput("Hardware frame error: " + err.Error())
-					s.setStatus("Hardware error")
-				})
-				continue
-			}
-			if !tick.Snapshot.Loaded {
-				continue
-			}
-			for _, samples := range tick.AudioFrames {
-				s.queueFrameAudio(samples)
+			if tick, ok, err := s.takeAudioTick(); ok {
+				s.presentTick(tick, err)
 			}
-			if tick.PresentFrame {
-				img, err := s.renderEmbeddedFrame(tick.Framebuffer)
-				if err == nil {
-					fps := tick.Snapshot.FPS
-					cycles := tick.Snapshot.CPUCyclesPerFrame
-					frameCount := tick.Snapshot.FrameCount
-					paused := tick.Snapshot.Paused
-					fyne.Do(func() {
-						s.emuImage.Image = img
-						s.emuImage.Refresh()
-						state := "running"
-						if paused {
-							state = "paused"
-						}
-						s.emuLabel.SetText(fmt.Sprintf(
-							"Hardware: %s | FPS %.1f | CPU %d cycles/frame | Frame %d | Time %s",
-							state,
-							fps,
-							cycles,
-							frameCount,
-							formatFrameClock(frameCount),
-						))
-						s.refreshDebuggerOutput()
-					})
+			running := true // the audio thread only paces a running ROM
+			if !s.audioPacing.Load() {
+				tick, err := s.backend.Tick(delta)
+				if woken && tick.Snapshot.Paused && !tick.PresentFrame {
+					// Stepping or editing a paused ROM changes the picture.
+					tick.PresentFrame = true
+					tick.Framebuffer = s.backend.FramebufferCopy()
 				}
+				s.presentTick(tick, err)
+				running = tick.Snapshot.Loaded && !tick.Snapshot.Paused
+				if !running && tick.Snapshot.Loaded {
+					timer.Reset(emuLoopPausedPoll)
+				}
+			}
+			if running {
+				timer.Reset(time.Second / emuLoopRunningHz)
 			}
+			wasRunning = running
 		}
 	}()
 }
 
+// The emulator loop ticks at emuLoopRunningHz while a ROM runs. Paused, it
+// polls every emuLoopPausedPoll and ticks at once when woken (stepping,
+// resetting, resuming); with no ROM loaded it sleeps until woken.
+const (
+	emuLoopRunningHz  = 60
+	emuLoopPausedPoll = 250 * time.Millisecond
+)
+
+// wakeEmulatorLoop makes the emulator loop tick now, for changes to a
+// paused or unloaded session that the next slow poll would show late.
+func (s *devKitState) wakeEmulatorLoop() {
+	select {
+	case s.emuLoopWake <- struct{}{}:
+	default:
+	}
+}
+
+// presentTick shows a tick's output and frame and queues its audio.
+func (s *devKitState) presentTick(tick devkit.TickResult, err error) {
+	if tick.TestOutput != "" {
+		output := tick.TestOutput
+		fyne.Do(func() { s.appendConsoleTranscript(output) })
+	}
+	if len(tick.DebugLines) > 0 {
+		lines := tick.DebugLines
+		fyne.Do(func() { s.appendDebugOutput(lines) })
+	}
+	if err != nil {
+		fyne.Do(func() {
+			s.reportEmulatorError("Hardware frame error", err)
+		})
+		return
+	}
+	if !tick.Snapshot.Loaded {
+		return
+	}
+	for _, samples := range tick.AudioFrames {
+		s.queueFrameAudio(samples)
+	}
+	if len(tick.AudioFrames) > 0 {
+		s.reportAudioQueue()
+	}
+	if tick.PresentFrame {
+		img, err := s.renderEmbeddedFrame(tick.Framebuffer)
+		if err == nil {
+			fps := tick.Snapshot.FPS
+			cycles := tick.Snapshot.CPUCyclesPerFrame
+			frameCount := tick.Snapshot.FrameCount
+			paused := tick.Snapshot.Paused
+			deterministic := tick.Snapshot.Deterministic
+			timing := tick.Snapshot.Timing
+			fyne.Do(func() {
+				s.emuImage.Image = img
+				s.emuImage.Refresh()
+				state := "running"
+				if paused {
+					state = "paused"
+				}
+				if deterministic {
+					state += " (deterministic)"
+				}
+				s.emuLabel.SetText(fmt.Sprintf(
+					"Hardware: %s | FPS %.1f | %s | CPU %d cycles/frame | Frame %d | Time %s",
+					state,
+					fps,
+					timing,
+					cycles,
+					frameCount,
+					formatFrameClock(frameCount),
+				))
+				s.refreshDebuggerOutput()
+				s.refreshAudioMixer()
+				s.refreshInputViewer()
+				s.refreshIORegisters()
+				s.refreshMemoryViewer()
+				s.drainEmulatorLogs()
+				s.refreshMovieStatus()
+			})
+		}
+	}
+}
+
 func formatFrameClock(frameCount uint64) string {
 	totalCentiseconds := (frameCount * 100) / 60
 	minutes := totalCentiseconds / 6000
@@ -1185,6 +1549,7 @@ func (s *devKitState) stopEmulatorLoop() {
 	s.updateLoopOnce.Do(func() {
 		close(s.updateLoopStop)
 	})
+	s.waitAudioLoop()
 }
 
 func (s *devKitState) initAudio() error {
@@ -1216,28 +1581,37 @@ func (s *devKitState) shutdownAudio() {
 	sdl.QuitSubSystem(sdl.INIT_AUDIO)
 }
 
+// audioFrameBytes is one emulator frame of SDL output: 735 interleaved
+// stereo float32 samples.
+const audioFrameBytes = 735 * 2 * 4
+
 func (s *devKitState) queueFrameAudio(samples []int16) {
 	if s.audioDev == 0 {
 		return
 	}
+	s.audioMu.Lock()
+	defer s.audioMu.Unlock()
 	// Keep queue bounded to reduce audio latency growth during UI stalls.
-	if sdl.GetQueuedAudioSize(s.audioDev) > uint32(len(s.audioFrame))*4 {
+	if sdl.GetQueuedAudioSize(s.audioDev) > uint32(len(s.audioFrame))*uint32(s.audioQueueFrames.Load()) {
 		return
 	}
 	if len(samples) == 0 {
 		return
 	}
-	j := 0
-	for _, sample := range samples {
-		f := apu.ConvertFixedToFloat(sample)
-		bits := math.Float32bits(f)
-		binary.LittleEndian.PutUint32(s.audioFrame[j:j+4], bits)
-		binary.LittleEndian.PutUint32(s.audioFrame[j+4:j+8], bits)
-		j += 8
-	}
+	s.audioFrame = apu.ConvertFrameStereoBytes(s.audioFrame, samples)
 	_ = sdl.QueueAudio(s.audioDev, s.audioFrame)
 }
 
+// reportAudioQueue feeds the SDL queue depth into the emulator's frame
+// statistics so the Hardware label can show audio latency.
+func (s *devKitState) reportAudioQueue() {
+	if s.audioDev == 0 {
+		return
+	}
+	// Interleaved stereo float32: 8 bytes per output sample.
+	s.backend.ReportAudioQueue(int(sdl.GetQueuedAudioSize(s.audioDev) / 8))
+}
+
 func (s *devKitState) renderEmbeddedFrame(buf []uint32) (image.Image, error) {
 	if len(buf) != devKitScreenW*devKitScreenH {
 		return nil, fmt.Errorf("buffer size mismatch: expected %d got %d", devKitScreenW*devKitScreenH, len(buf))
@@ -1422,10 +1796,15 @@ func (s *devKitState) handleKeyUp(key *fyne.KeyEvent) {
 }
 
 func (s *devKitState) focusEmulatorInput() {
-	if s.window == nil || s.window.Canvas() == nil || s.emuKeys == nil {
+	w := s.emulatorHostWindow()
+	if w == nil || w.Canvas() == nil || s.emuKeys == nil {
 		return
 	}
-	s.window.Canvas().Focus(s.emuKeys)
+	if w != s.window {
+		// Keys typed in the pop-out must not reach the code editor.
+		s.window.Canvas().Unfocus()
+	}
+	w.Canvas().Focus(s.emuKeys)
 }
 
 func (s *devKitState) shouldCaptureGameInput() bool {
@@ -1519,7 +1898,12 @@ func (s *devKitState) dispatchKeyUpToFocused(key *fyne.KeyEvent) {
 
 func (s *devKitState) routeInputToEmulator() {
 	if !s.shouldCaptureGameInput() {
-		s.applyInputButtons(0)
+		// Virtual (mouse) buttons are explicit, so they still reach the
+		// emulator when keyboard capture is off.
+		s.keyMu.Lock()
+		virtual := s.virtualButtons
+		s.keyMu.Unlock()
+		s.applyInputButtons(virtual)
 		return
 	}
 	s.applyInputButtons(s.computeButtonMask())
@@ -1546,7 +1930,7 @@ func (s *devKitState) computeButtonMask() uint16 {
 		return false
 	}
 
-	var buttons uint16
+	buttons := s.virtualButtons
 	if isPressed(fyne.KeyW) || isPressed(fyne.KeyUp) {
 		buttons |= 0x01
 	}
@@ -1616,9 +2000,16 @@ func (s *devKitState) showDiagnosticDetail(d corelx.Diagnostic) {
 			sb.WriteString(fmt.Sprintf("- %s:%d:%d %s\n", r.File, r.Line, r.Column, r.Message))
 		}
 	}
+	if len(d.Fixes) > 0 {
+		sb.WriteString("\nFixes:\n")
+		for _, f := range d.Fixes {
+			sb.WriteString("- " + f.Title + "\n")
+		}
+	}
 	s.diagnosticDetail.Enable()
 	s.diagnosticDetail.SetText(strings.TrimSpace(sb.String()))
 	s.diagnosticDetail.Disable()
+	s.showDiagnosticFixes(d)
 }
 
 func (s *devKitState) updateDiagnosticDetailSelection() {
@@ -1630,6 +2021,7 @@ func (s *devKitState) updateDiagnosticDetailSelection() {
 			s.diagnosticDetail.SetText("No diagnostics match current filter")
 		}
 		s.diagnosticDetail.Disable()
+		s.hideDiagnosticFixes()
 		return
 	}
 	s.showDiagnosticDetail(s.filteredDiagnostics[0])
@@ -1647,6 +2039,32 @@ func (s *devKitState) appendBuildOutput(msg string) {
 	s.buildOutput.Disable()
 }
 
+// debugOutputMaxLines caps the Output tab once the ROM prints to it, so a
+// debug.print every frame cannot grow it without bound.
+const debugOutputMaxLines = 2000
+
+// appendDebugOutput adds lines the ROM printed with debug.print to the
+// Output tab, dropping the oldest output past debugOutputMaxLines.
+func (s *devKitState) appendDebugOutput(lines []string) {
+	ts := time.Now().Format("15:04:05")
+	var sb strings.Builder
+	sb.WriteString(s.buildOutput.Text)
+	if sb.Len() > 0 && !strings.HasSuffix(s.buildOutput.Text, "\n") {
+		sb.WriteByte('\n')
+	}
+	for _, line := range lines {
+		fmt.Fprintf(&sb, "[%s] debug: %s\n", ts, line)
+	}
+	text := sb.String()
+	for n := strings.Count(text, "\n"); n > debugOutputMaxLines; n-- {
+		text = text[strings.IndexByte(text, '\n')+1:]
+	}
+	s.buildOutput.Enable()
+	s.buildOutput.SetText(text)
+	s.buildOutput.CursorRow = strings.Count(text, "\n")
+	s.buildOutput.Disable()
+}
+
 func (s *devKitState) setStatus(msg string) {
 	s.statusLabel.SetText(msg)
 }
@@ -1683,6 +2101,7 @@ func (s *devKitState) runEmulator() {
 			s.setStatus("Run failed")
 			return
 		}
+		s.wakeEmulatorLoop()
 	}
 	s.setStatus("Running")
 }
@@ -1727,9 +2146,70 @@ func (s *devKitState) hardwareReset() {
 		s.setStatus("No active project build")
 		return
 	}
+	s.clearFault()
+	s.wakeEmulatorLoop()
 	s.setStatus("Hardware reset complete")
 }
 
+func (s *devKitState) softReset() {
+	if err := s.backend.SoftResetEmulator(); err != nil {
+		s.setStatus("No active project build")
+		return
+	}
+	s.wakeEmulatorLoop()
+	s.setStatus("Soft reset complete (RAM kept)")
+}
+
+// toggleMacroRecording starts recording an input macro from the game
+// controls, or stops the recording in progress.
+func (s *devKitState) toggleMacroRecording() {
+	recording, frames := s.backend.ToggleMacroRecording()
+	if recording {
+		s.setStatus("Recording input macro... (Record Macro again to stop)")
+		return
+	}
+	s.setStatus(fmt.Sprintf("Input macro recorded: %d frames", frames))
+}
+
+func (s *devKitState) playMacro() {
+	if err := s.backend.PlayMacro(); err != nil {
+		s.setStatus("No input macro recorded")
+		return
+	}
+	s.setStatus("Playing input macro")
+}
+
+// toggleDeterministic switches the backend between wall-clock pacing and
+// fixed-timestep mode (one frame per update tick) and remembers the choice.
+func (s *devKitState) toggleDeterministic() bool {
+	enabled := !s.backend.Deterministic()
+	s.backend.SetDeterministic(enabled)
+	s.settings.Deterministic = enabled
+	s.persistSettings()
+	s.setStatus("Deterministic timing " + onOff(enabled, "on", "off"))
+	return enabled
+}
+
+// togglePriorityView switches the emulator view between the normal picture
+// and the priority debug render mode, where each pixel is tinted by the
+// layer that won it. Unlike deterministic timing it is not remembered.
+func (s *devKitState) togglePriorityView() bool {
+	enabled := !s.backend.PriorityView()
+	s.backend.SetPriorityView(enabled)
+	s.setStatus("Priority view " + onOff(enabled, "on (BG0 red, BG1 green, BG2 blue, BG3 yellow, sprites magenta, backdrop gray)", "off"))
+	return enabled
+}
+
+// toggleInputDisplay shows or hides the controller overlay in the bottom
+// left corner of the emulator view, e.g. for tutorial recordings. It is not
+// remembered either.
+func (s *devKitState) toggleInputDisplay() bool {
+	enabled := !s.backend.InputDisplay()
+	s.backend.SetInputDisplay(enabled)
+	s.setStatus("Input display " + onOff(enabled, "on", "off"))
+	return enabled
+}
+
 func (s *devKitState) stepFrame() {
 	snap := s.backend.Snapshot()
 	if !snap.Loaded {
@@ -1742,11 +2222,11 @@ func (s *devKitState) stepFrame() {
 	}
 	frames := s.parseStepCount(s.stepFrameEntry.Text, 1)
 	if err := s.backend.StepFrame(frames); err != nil {
-		s.setStatus("Step frame failed")
-		s.appendBuildOutput("Step frame failed: " + err.Error())
+		s.reportEmulatorError("Step frame failed", err)
 		return
 	}
 	s.refreshDebuggerOutput()
+	s.wakeEmulatorLoop()
 	s.setStatus(fmt.Sprintf("Stepped %d frame(s)", frames))
 }
 
@@ -1767,9 +2247,34 @@ func (s *devKitState) stepCPU() {
 		return
 	}
 	s.refreshDebuggerOutput()
+	s.wakeEmulatorLoop()
 	s.setStatus(fmt.Sprintf("Stepped %d CPU instruction(s)", steps))
 }
 
+// stepBack rewinds the paused ROM by the Step C count, undoing instruction
+// and frame steps (see devkit.Service.StepBack).
+func (s *devKitState) stepBack() {
+	snap := s.backend.Snapshot()
+	if !snap.Loaded {
+		s.setStatus("No active project build")
+		return
+	}
+	if !snap.Paused {
+		s.setStatus("Pause before stepping back")
+		return
+	}
+	steps := s.parseStepCount(s.stepCPUEntry.Text, 1)
+	back, err := s.backend.StepBack(steps)
+	if err != nil {
+		s.setStatus("Step back failed")
+		s.appendBuildOutput("Step back failed: " + err.Error())
+		return
+	}
+	s.refreshDebuggerOutput()
+	s.wakeEmulatorLoop()
+	s.setStatus(fmt.Sprintf("Stepped back %d step(s), %d more available", back, s.backend.StepBackAvailable()))
+}
+
 func (s *devKitState) markCurrentFrame() {
 	snap := s.backend.Snapshot()
 	if !snap.Loaded {
@@ -1799,6 +2304,23 @@ func (s *devKitState) toggleDiagnosticsPanel() {
 	s.leftSplit.Refresh()
 }
 
+// showBottomTab brings the lower panel's tab into view, leaving
+// emulator-only view and expanding the panel if needed. It reports false
+// before the UI is built.
+func (s *devKitState) showBottomTab(name string) bool {
+	if s.bottomLeftTabs == nil {
+		return false
+	}
+	if s.currentView == viewModeEmulatorOnly {
+		s.setViewMode(viewModeFull)
+	}
+	selectTabByText(s.bottomLeftTabs, name)
+	if s.currentView == viewModeFull && s.diagnosticsCollapsed {
+		s.toggleDiagnosticsPanel()
+	}
+	return true
+}
+
 func (s *devKitState) captureLayoutState() {
 	if s.currentView == viewModeFull && s.mainSplit != nil {
 		s.settings.MainSplitOffset = clampOffset(s.mainSplit.Offset, defaultMainSplitOffset)
@@ -1812,11 +2334,7 @@ func (s *devKitState) captureLayoutState() {
 func (s *devKitState) setUIDensity(density string) {
 	s.settings.UIDensity = density
 	s.persistSettings()
-	if density == "standard" {
-		fyne.CurrentApp().Settings().SetTheme(newStandardTheme())
-	} else {
-		fyne.CurrentApp().Settings().SetTheme(newCompactTheme())
-	}
+	fyne.CurrentApp().Settings().SetTheme(newDevKitTheme(density, s.settings.Theme))
 	s.setStatus("UI density: " + density + " (applied)")
 }
 
@@ -1898,6 +2416,14 @@ func (s *devKitState) refreshDebuggerOutput() {
 		sb.WriteString("\nRegisters:\n")
 		sb.WriteString(fmt.Sprintf("R0:%04X  R1:%04X  R2:%04X  R3:%04X\n", regs.R0, regs.R1, regs.R2, regs.R3))
 		sb.WriteString(fmt.Sprintf("R4:%04X  R5:%04X  R6:%04X  R7:%04X\n", regs.R4, regs.R5, regs.R6, regs.R7))
+		sb.WriteString("\nCall stack (innermost first):\n")
+		stack := s.backend.CallStack()
+		if len(stack) == 0 {
+			sb.WriteString("  (empty)\n")
+		}
+		for i := len(stack) - 1; i >= 0; i-- {
+			sb.WriteString(fmt.Sprintf("  #%d %s\n", len(stack)-1-i, emulator.FormatCallFrame(stack[i])))
+		}
 	}
 
 	s.debuggerOutput.Enable()
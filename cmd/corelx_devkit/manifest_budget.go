@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"nitro-core-dx/internal/corelx"
+)
+
+const (
+	manifestBarWidth  = 360
+	manifestBarHeight = 14
+)
+
+var (
+	manifestBarTrack = color.NRGBA{R: 0x20, G: 0x22, B: 0x28, A: 0xFF}
+	manifestBarUsed  = color.NRGBA{R: 0x3C, G: 0xC8, B: 0x5A, A: 0xFF}
+	manifestBarOver  = color.NRGBA{R: 0xE8, G: 0x40, B: 0x40, A: 0xFF}
+	manifestBarMark  = color.NRGBA{R: 0xF0, G: 0xF0, B: 0xF0, A: 0xFF}
+
+	// manifestSectionColors colors each ROM section in the stacked bar.
+	manifestSectionColors = map[string]color.NRGBA{
+		"code":        {R: 0x60, G: 0x90, B: 0xF0, A: 0xFF},
+		"gfx_tiles":   {R: 0x3C, G: 0xC8, B: 0x5A, A: 0xFF},
+		"tilemaps":    {R: 0x30, G: 0xB0, B: 0xB0, A: 0xFF},
+		"palettes":    {R: 0xE0, G: 0x70, B: 0xC0, A: 0xFF},
+		"audio_seq":   {R: 0xF0, G: 0xC0, B: 0x30, A: 0xFF},
+		"audio_patch": {R: 0xF0, G: 0x90, B: 0x30, A: 0xFF},
+		"gamedata":    {R: 0xA0, G: 0x80, B: 0xE0, A: 0xFF},
+		"rom_data":    {R: 0x90, G: 0x98, B: 0xA8, A: 0xFF},
+	}
+)
+
+// manifestSegment is one section's share of the ROM stacked bar.
+type manifestSegment struct {
+	Name  string
+	Bytes uint32
+	Color color.NRGBA
+}
+
+// manifestSegments lists the sections that take space in the ROM, in ROM
+// order. The fixed 32-byte header is left out.
+func manifestSegments(m *corelx.BuildManifest) []manifestSegment {
+	var segs []manifestSegment
+	for _, s := range m.Sections {
+		c, ok := manifestSectionColors[s.Name]
+		if !ok || s.UsedBytes == 0 {
+			continue
+		}
+		segs = append(segs, manifestSegment{Name: s.Name, Bytes: s.UsedBytes, Color: c})
+	}
+	return segs
+}
+
+// formatBudgetRow is the text beside a budget bar.
+func formatBudgetRow(b corelx.ManifestBudget) string {
+	pct := 0
+	if b.BudgetBytes > 0 {
+		pct = int(uint64(b.UsedBytes) * 100 / uint64(b.BudgetBytes))
+	}
+	text := fmt.Sprintf("%s / %s (%d%%)", corelx.FormatBytes(b.UsedBytes), corelx.FormatBytes(b.BudgetBytes), pct)
+	if b.Over() {
+		text += fmt.Sprintf(" - over by %s", corelx.FormatBytes(b.UsedBytes-b.BudgetBytes))
+	}
+	return text
+}
+
+// renderStackedBar draws segs side by side, scaled so they fill w.
+func renderStackedBar(segs []manifestSegment, w, h int) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	fillRect(img, 0, w, manifestBarTrack)
+	var total uint64
+	for _, s := range segs {
+		total += uint64(s.Bytes)
+	}
+	if total == 0 {
+		return img
+	}
+	var done uint64
+	for _, s := range segs {
+		x0 := int(done * uint64(w) / total)
+		done += uint64(s.Bytes)
+		x1 := int(done * uint64(w) / total)
+		if x1 == x0 {
+			x1 = x0 + 1 // keep tiny sections visible
+		}
+		fillRect(img, x0, min(x1, w), s.Color)
+	}
+	return img
+}
+
+// renderBudgetBar draws usage against a budget: green up to the budget,
+// red past it, with a tick where the budget ends.
+func renderBudgetBar(b corelx.ManifestBudget, w, h int) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	fillRect(img, 0, w, manifestBarTrack)
+	scale := uint64(max(b.UsedBytes, b.BudgetBytes))
+	if scale == 0 {
+		return img
+	}
+	budgetX := int(uint64(b.BudgetBytes) * uint64(w) / scale)
+	usedX := int(uint64(b.UsedBytes) * uint64(w) / scale)
+	fillRect(img, 0, min(usedX, budgetX), manifestBarUsed)
+	if usedX > budgetX {
+		fillRect(img, budgetX, usedX, manifestBarOver)
+	}
+	fillRect(img, max(budgetX-1, 0), max(budgetX, 1), manifestBarMark)
+	return img
+}
+
+func fillRect(img *image.NRGBA, x0, x1 int, c color.NRGBA) {
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := max(x0, 0); x < min(x1, b.Max.X); x++ {
+			img.SetNRGBA(x, y, c)
+		}
+	}
+}
+
+func newManifestBar(draw func(w, h int) image.Image) *canvas.Raster {
+	r := canvas.NewRaster(draw)
+	r.SetMinSize(fyne.NewSize(manifestBarWidth, manifestBarHeight))
+	return r
+}
+
+// updateManifestChart redraws the ROM layout bar and one bar per project
+// budget (see corelx.ManifestBudget) above the manifest JSON.
+func (s *devKitState) updateManifestChart(m *corelx.BuildManifest) {
+	if s.manifestChart == nil {
+		return
+	}
+	s.manifestChart.RemoveAll()
+	if m == nil {
+		return
+	}
+	segs := manifestSegments(m)
+	legend := container.NewHBox()
+	for _, seg := range segs {
+		swatch := canvas.NewRectangle(seg.Color)
+		swatch.SetMinSize(fyne.NewSize(10, 10))
+		legend.Add(container.NewCenter(swatch))
+		legend.Add(widget.NewLabel(fmt.Sprintf("%s %s", seg.Name, corelx.FormatBytes(seg.Bytes))))
+	}
+	romBar := newManifestBar(func(w, h int) image.Image { return renderStackedBar(segs, w, h) })
+	s.manifestChart.Add(container.NewBorder(nil, nil, manifestRowLabel("ROM"), nil, romBar))
+	s.manifestChart.Add(legend)
+	for _, b := range m.Budgets {
+		bar := newManifestBar(func(w, h int) image.Image { return renderBudgetBar(b, w, h) })
+		text := widget.NewLabel(formatBudgetRow(b))
+		if b.Over() {
+			text.Importance = widget.DangerImportance
+		}
+		s.manifestChart.Add(container.NewBorder(nil, nil, manifestRowLabel(b.Name), text, bar))
+	}
+	if len(m.Budgets) == 0 {
+		s.manifestChart.Add(widget.NewLabel(`No budgets: add "budgets" to corelx.assets.json, e.g. {"code": "24 KB", "assets": "32 KB"}`))
+	}
+}
+
+func manifestRowLabel(name string) *widget.Label {
+	l := widget.NewLabel(fmt.Sprintf("%-11s", name))
+	l.TextStyle = fyne.TextStyle{Monospace: true}
+	return l
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"testing"
+
+	"nitro-core-dx/internal/corelx"
+)
+
+func TestManifestSegmentsSkipHeaderAndEmptySections(t *testing.T) {
+	m := &corelx.BuildManifest{Sections: []corelx.ManifestSection{
+		{Name: "header", UsedBytes: 32},
+		{Name: "code", UsedBytes: 4096},
+		{Name: "gfx_tiles", UsedBytes: 0},
+		{Name: "palettes", UsedBytes: 64},
+	}}
+	segs := manifestSegments(m)
+	if len(segs) != 2 || segs[0].Name != "code" || segs[1].Name != "palettes" {
+		t.Fatalf("segments = %+v, want code and palettes", segs)
+	}
+}
+
+func TestBudgetBarAndRow(t *testing.T) {
+	over := corelx.ManifestBudget{Name: "code", UsedBytes: 3072, BudgetBytes: 2048}
+	if got, want := formatBudgetRow(over), "3 KB / 2 KB (150%) - over by 1 KB"; got != want {
+		t.Errorf("row = %q, want %q", got, want)
+	}
+	img := renderBudgetBar(over, 30, 2)
+	// 30 px span 3 KB: used (green) to x=20, over (red) to the end.
+	if c := img.At(5, 0); c != manifestBarUsed {
+		t.Errorf("pixel 5 = %v, want used", c)
+	}
+	if c := img.At(25, 0); c != manifestBarOver {
+		t.Errorf("pixel 25 = %v, want over", c)
+	}
+
+	under := corelx.ManifestBudget{Name: "assets", UsedBytes: 512, BudgetBytes: 2048}
+	if got, want := formatBudgetRow(under), "512 bytes / 2 KB (25%)"; got != want {
+		t.Errorf("row = %q, want %q", got, want)
+	}
+	img = renderBudgetBar(under, 40, 2)
+	if c := img.At(20, 0); c != manifestBarTrack {
+		t.Errorf("pixel 20 = %v, want the empty track", c)
+	}
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"nitro-core-dx/internal/emulator"
+)
+
+const (
+	memoryViewerTabName = "Memory"
+	memoryViewerRows    = 16
+	memoryViewerBytes   = memoryViewerRows * 16
+)
+
+var (
+	memoryViewerText     = color.NRGBA{R: 0xE0, G: 0xE0, B: 0xE0, A: 0xFF}
+	memoryViewerDim      = color.NRGBA{R: 0x80, G: 0x88, B: 0x90, A: 0xFF}
+	memoryViewerSelected = color.NRGBA{R: 0x2E, G: 0x5C, B: 0x8A, A: 0xFF}
+)
+
+// memoryViewerPanel is the Memory tab: a 256-byte hex view of work RAM,
+// VRAM or OAM. Clicking a byte selects it; right-clicking offers "Who wrote
+// this?", which lists the last writes to it with the PC and frame of each.
+type memoryViewerPanel struct {
+	summary *widget.Label
+	address *widget.Entry
+	rows    [memoryViewerRows]*canvas.Text
+	cells   [memoryViewerBytes]*memoryByteCell
+	detail  *widget.Label
+
+	space    emulator.MemorySpace
+	start    uint32
+	selected int // index into cells, -1 for none
+}
+
+// memoryByteCell is one byte of the hex grid.
+type memoryByteCell struct {
+	widget.BaseWidget
+	bg       *canvas.Rectangle
+	text     *canvas.Text
+	onTap    func()
+	onSecond func(*fyne.PointEvent)
+}
+
+func newMemoryByteCell(onTap func(), onSecond func(*fyne.PointEvent)) *memoryByteCell {
+	c := &memoryByteCell{
+		bg:       canvas.NewRectangle(color.Transparent),
+		text:     canvas.NewText("--", memoryViewerDim),
+		onTap:    onTap,
+		onSecond: onSecond,
+	}
+	c.text.Alignment = fyne.TextAlignCenter
+	c.text.TextStyle = fyne.TextStyle{Monospace: true}
+	c.ExtendBaseWidget(c)
+	return c
+}
+
+func (c *memoryByteCell) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(container.NewStack(c.bg, c.text))
+}
+
+func (c *memoryByteCell) Tapped(*fyne.PointEvent) {
+	if c.onTap != nil {
+		c.onTap()
+	}
+}
+
+func (c *memoryByteCell) TappedSecondary(ev *fyne.PointEvent) {
+	if c.onSecond != nil {
+		c.onSecond(ev)
+	}
+}
+
+func (c *memoryByteCell) set(text string, selected bool) {
+	if selected {
+		c.bg.FillColor = memoryViewerSelected
+	} else {
+		c.bg.FillColor = color.Transparent
+	}
+	c.text.Text = text
+	c.text.Color = memoryViewerText
+	if text == "--" {
+		c.text.Color = memoryViewerDim
+	}
+	c.Refresh()
+}
+
+// memorySpaceEnd is one past the last address of the region of space that
+// holds addr: the end of bank 0 work RAM, of an extended WRAM bank, of VRAM
+// or of OAM.
+func memorySpaceEnd(space emulator.MemorySpace, addr uint32) uint32 {
+	switch space {
+	case emulator.MemoryVRAM:
+		return 0x10000
+	case emulator.MemoryOAM:
+		return 768
+	}
+	if addr>>16 == 0 {
+		return 0x8000
+	}
+	return addr&0xFF0000 + 0x10000
+}
+
+// memoryViewStart is the first address shown when jumping to addr: addr's
+// 16-byte row, moved back so the whole view stays inside the region.
+func memoryViewStart(space emulator.MemorySpace, addr uint32) uint32 {
+	start := addr &^ 0xF
+	end := memorySpaceEnd(space, addr)
+	if start+memoryViewerBytes > end {
+		start = end - memoryViewerBytes
+	}
+	return start
+}
+
+// formatLastWrites describes the tracked writes to one byte, newest first.
+func formatLastWrites(space emulator.MemorySpace, addr uint32, writes []emulator.WriteRecord) string {
+	where := space.FormatAddress(addr)
+	if len(writes) == 0 {
+		return where + ": no writes recorded since the last reset"
+	}
+	lines := make([]string, 0, len(writes)+1)
+	lines = append(lines, fmt.Sprintf("%s: last %d write(s), newest first", where, len(writes)))
+	for _, w := range writes {
+		lines = append(lines, "  "+w.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (s *devKitState) buildMemoryViewerPane() fyne.CanvasObject {
+	panel := &memoryViewerPanel{selected: -1}
+	s.memoryViewer = panel
+
+	panel.summary = widget.NewLabel("Memory: no build loaded")
+	panel.address = widget.NewEntry()
+	panel.address.SetPlaceHolder("Address (0:0x1F00, 126:0x0010, vram:0x4000, oam:0)")
+	panel.address.SetText("0:0x0000")
+	panel.address.OnSubmitted = func(string) { s.jumpMemoryViewer() }
+	goBtn := widget.NewButton("Go", func() { s.jumpMemoryViewer() })
+
+	grid := container.NewGridWithColumns(17)
+	for row := 0; row < memoryViewerRows; row++ {
+		label := canvas.NewText("", memoryViewerDim)
+		label.TextStyle = fyne.TextStyle{Monospace: true}
+		panel.rows[row] = label
+		grid.Add(label)
+		for col := 0; col < 16; col++ {
+			i := row*16 + col
+			panel.cells[i] = newMemoryByteCell(
+				func() { s.selectMemoryByte(i, false) },
+				func(ev *fyne.PointEvent) { s.showMemoryByteMenu(i, ev) },
+			)
+			grid.Add(panel.cells[i])
+		}
+	}
+
+	panel.detail = widget.NewLabel("Click a byte to select it; right-click for \"Who wrote this?\"")
+	panel.detail.Wrapping = fyne.TextWrapWord
+	panel.detail.TextStyle = fyne.TextStyle{Monospace: true}
+
+	top := container.NewBorder(nil, nil, panel.summary, goBtn, panel.address)
+	return container.NewBorder(top, panel.detail, nil, nil, container.NewVScroll(grid))
+}
+
+func (s *devKitState) jumpMemoryViewer() {
+	panel := s.memoryViewer
+	if panel == nil {
+		return
+	}
+	space, addr, err := emulator.ParseMemoryAddress(panel.address.Text)
+	if err != nil {
+		s.setStatus(err.Error())
+		return
+	}
+	panel.space = space
+	panel.start = memoryViewStart(space, addr)
+	panel.selected = int(addr - panel.start)
+	s.refreshMemoryViewer()
+	s.selectMemoryByte(panel.selected, false)
+}
+
+// selectMemoryByte highlights cell i and, when lookup is set, shows who last
+// wrote it.
+func (s *devKitState) selectMemoryByte(i int, lookup bool) {
+	panel := s.memoryViewer
+	if panel == nil || i < 0 || i >= memoryViewerBytes {
+		return
+	}
+	panel.selected = i
+	addr := panel.start + uint32(i)
+	if lookup {
+		writes, err := s.backend.LastWrites(panel.space, addr)
+		if err != nil {
+			panel.detail.SetText(panel.space.FormatAddress(addr) + ": " + err.Error())
+		} else {
+			panel.detail.SetText(formatLastWrites(panel.space, addr, writes))
+		}
+	} else {
+		panel.detail.SetText(panel.space.FormatAddress(addr) + " selected; right-click for \"Who wrote this?\"")
+	}
+	s.refreshMemoryViewer()
+}
+
+func (s *devKitState) showMemoryByteMenu(i int, ev *fyne.PointEvent) {
+	panel := s.memoryViewer
+	if panel == nil || ev == nil || fyne.CurrentApp() == nil {
+		return
+	}
+	addr := panel.start + uint32(i)
+	menu := fyne.NewMenu("",
+		fyne.NewMenuItem("Who wrote this?", func() { s.selectMemoryByte(i, true) }),
+		fyne.NewMenuItem("Copy Address", func() {
+			fyne.CurrentApp().Clipboard().SetContent(panel.space.FormatAddress(addr))
+		}),
+	)
+	cell := panel.cells[i]
+	if c := fyne.CurrentApp().Driver().CanvasForObject(cell); c != nil {
+		pos := fyne.CurrentApp().Driver().AbsolutePositionForObject(cell)
+		widget.ShowPopUpMenuAtPosition(menu, c, pos.Add(ev.Position))
+	}
+}
+
+// refreshMemoryViewer re-reads the shown bytes while the Memory tab is
+// showing. Must run on the Fyne UI goroutine.
+func (s *devKitState) refreshMemoryViewer() {
+	panel := s.memoryViewer
+	if panel == nil || s.bottomLeftTabs == nil {
+		return
+	}
+	if tab := s.bottomLeftTabs.Selected(); tab == nil || tab.Text != memoryViewerTabName {
+		return
+	}
+	data, err := s.backend.ReadMemory(panel.space, panel.start, memoryViewerBytes)
+	if err != nil {
+		panel.summary.SetText("Memory: no build loaded")
+	} else {
+		panel.summary.SetText(fmt.Sprintf("Memory: %s", panel.space))
+	}
+	for row := 0; row < memoryViewerRows; row++ {
+		panel.rows[row].Text = panel.space.FormatAddress(panel.start + uint32(row*16))
+		panel.rows[row].Refresh()
+	}
+	for i, cell := range panel.cells {
+		text := "--"
+		if i < len(data) {
+			text = fmt.Sprintf("%02X", data[i])
+		}
+		cell.set(text, i == panel.selected)
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"nitro-core-dx/internal/emulator"
+)
+
+func TestMemoryViewStartStaysInsideRegion(t *testing.T) {
+	cases := []struct {
+		space emulator.MemorySpace
+		addr  uint32
+		want  uint32
+	}{
+		{emulator.MemoryWRAM, 0x1F07, 0x1F00},
+		{emulator.MemoryWRAM, 0x7FF0, 0x7F00},
+		{emulator.MemoryWRAM, 126<<16 | 0xFFFF, 126<<16 | 0xFF00},
+		{emulator.MemoryVRAM, 0x4021, 0x4020},
+		{emulator.MemoryOAM, 700, 512},
+	}
+	for _, c := range cases {
+		if got := memoryViewStart(c.space, c.addr); got != c.want {
+			t.Errorf("memoryViewStart(%s, 0x%X) = 0x%X, want 0x%X", c.space, c.addr, got, c.want)
+		}
+	}
+}
+
+func TestFormatLastWrites(t *testing.T) {
+	if got := formatLastWrites(emulator.MemoryWRAM, 0x1F00, nil); !strings.Contains(got, "00:1F00: no writes") {
+		t.Fatalf("empty history = %q", got)
+	}
+	got := formatLastWrites(emulator.MemoryVRAM, 0x4000, []emulator.WriteRecord{
+		{Value: 0x16, PCBank: 1, PCOffset: 0x8020, Frame: 7, DMA: true},
+		{Value: 0x02, PCBank: 1, PCOffset: 0x8010, Frame: 3},
+	})
+	lines := strings.Split(got, "\n")
+	if len(lines) != 3 || !strings.Contains(lines[0], "vram:4000") || !strings.Contains(lines[1], "PC 01:8020, frame 7 (DMA)") {
+		t.Fatalf("formatLastWrites = %q", got)
+	}
+}
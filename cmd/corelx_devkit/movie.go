@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+	"nitro-core-dx/internal/devkit"
+	"nitro-core-dx/internal/harness"
+)
+
+// showRecordMovieDialog starts recording a movie (.ncm) of the running ROM.
+func (s *devKitState) showRecordMovieDialog() {
+	if !s.backend.Snapshot().Loaded {
+		s.setStatus("Record movie: no ROM loaded")
+		return
+	}
+	author := widget.NewEntry()
+	author.SetText(s.settings.MovieAuthor)
+	comment := widget.NewMultiLineEntry()
+	comment.SetPlaceHolder("What the movie shows, e.g. steps to reproduce a bug")
+	comment.SetMinRowsVisible(3)
+	powerOn := widget.NewCheck("Start from power-on (otherwise from the current state)", nil)
+	powerOn.SetChecked(true)
+	help := widget.NewLabel("Every frame's input is recorded. Stepping, the immediate console and register or palette edits are unavailable while recording. Debug → Stop Movie saves the file.")
+	help.Wrapping = fyne.TextWrapWord
+	help.Importance = widget.LowImportance
+
+	form := widget.NewForm(
+		widget.NewFormItem("Author", author),
+		widget.NewFormItem("Comment", comment),
+		widget.NewFormItem("", powerOn),
+	)
+	d := dialog.NewCustomConfirm("Record Movie", "Record", "Cancel", container.NewBorder(nil, help, nil, nil, form), func(ok bool) {
+		if !ok {
+			return
+		}
+		s.settings.MovieAuthor = author.Text
+		s.persistSettings()
+		if err := s.backend.StartMovieRecording(powerOn.Checked, author.Text, comment.Text); err != nil {
+			dialog.ShowError(err, s.window)
+			return
+		}
+		s.movieStatus = s.backend.MovieStatus()
+		s.wakeEmulatorLoop()
+		s.appendBuildOutput("Recording movie")
+		s.setStatus("Recording movie")
+	}, s.window)
+	d.Resize(fyne.NewSize(520, 360))
+	d.Show()
+}
+
+// playMovieDialog plays a movie file on the loaded ROM.
+func (s *devKitState) playMovieDialog() {
+	fd := dialog.NewFileOpen(func(rc fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, s.window)
+			return
+		}
+		if rc == nil {
+			return
+		}
+		defer rc.Close()
+		m, err := harness.ReadMovie(rc)
+		if err != nil {
+			dialog.ShowError(err, s.window)
+			return
+		}
+		if err := s.backend.PlayMovie(m); err != nil {
+			dialog.ShowError(err, s.window)
+			return
+		}
+		s.movieStatus = s.backend.MovieStatus()
+		s.wakeEmulatorLoop()
+		s.appendBuildOutput(fmt.Sprintf("Playing movie %s: %d frames%s", uriPath(rc.URI()), len(m.Input), movieByline(m.Header)))
+		if m.Header.Comment != "" {
+			s.appendBuildOutput("  " + m.Header.Comment)
+		}
+		s.setStatus("Playing movie")
+	}, s.window)
+	fd.SetFilter(storage.NewExtensionFileFilter([]string{harness.MovieExt}))
+	if loc := dialogListableForDir(s.settings.LastSourceDir); loc != nil {
+		fd.SetLocation(loc)
+	}
+	fd.Show()
+}
+
+func movieByline(h harness.MovieHeader) string {
+	if h.Author == "" {
+		return ""
+	}
+	return " by " + h.Author
+}
+
+// stopMovie ends playback, or ends recording and asks where to save it.
+func (s *devKitState) stopMovie() {
+	if !s.backend.MovieStatus().Recording {
+		s.backend.StopMovie()
+		s.movieStatus = devkit.MovieStatus{}
+		s.setStatus("Movie stopped")
+		return
+	}
+	m, err := s.backend.StopMovieRecording()
+	s.movieStatus = devkit.MovieStatus{}
+	if err != nil {
+		s.setStatus("Stop movie: " + err.Error())
+		return
+	}
+	s.appendBuildOutput(fmt.Sprintf("Movie recorded: %d frames", len(m.Input)))
+	fd := dialog.NewFileSave(func(wc fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, s.window)
+			return
+		}
+		if wc == nil {
+			s.appendBuildOutput("Movie discarded")
+			return
+		}
+		defer wc.Close()
+		if err := harness.WriteMovie(wc, m); err != nil {
+			dialog.ShowError(err, s.window)
+			return
+		}
+		path := uriPath(wc.URI())
+		if path != "" {
+			s.settings.LastSourceDir = filepath.Dir(path)
+			s.persistSettings()
+		}
+		s.appendBuildOutput("Saved movie to " + path)
+		s.setStatus("Movie saved")
+	}, s.window)
+	fd.SetFilter(storage.NewExtensionFileFilter([]string{harness.MovieExt}))
+	if loc := dialogListableForDir(s.settings.LastSourceDir); loc != nil {
+		fd.SetLocation(loc)
+	}
+	fd.SetFileName("movie" + harness.MovieExt)
+	fd.Show()
+}
+
+// refreshMovieStatus reports a playing movie's desync and its end in the
+// Output pane.
+func (s *devKitState) refreshMovieStatus() {
+	prev := s.movieStatus
+	if !prev.Recording && !prev.Playing {
+		return
+	}
+	st := s.backend.MovieStatus()
+	s.movieStatus = st
+	if st.Desync != "" && prev.Desync == "" {
+		s.appendBuildOutput("Movie: " + st.Desync)
+	}
+	if prev.Playing && !st.Playing {
+		result := "matched every checkpoint"
+		if prev.Desync != "" || st.Desync != "" {
+			result = "desynced"
+		}
+		s.appendBuildOutput(fmt.Sprintf("Movie finished after %d frames (%s)", prev.Frames, result))
+		s.setStatus("Movie finished")
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"nitro-core-dx/internal/fileassoc"
+)
+
+// handleDroppedURIs opens the first dropped local file the Dev Kit
+// understands.
+func (s *devKitState) handleDroppedURIs(uris []fyne.URI) {
+	for _, u := range uris {
+		if u == nil || u.Scheme() != "file" || fileassoc.Classify(u.Path()) == fileassoc.KindUnknown {
+			continue
+		}
+		s.openExternalFile(u.Path())
+		return
+	}
+	s.setStatus("Drop a .rom or .corelx file to open it")
+}
+
+// openExternalFile handles a file handed over by the desktop (drag-and-drop
+// or --open): a .rom or zipped game loads into the embedded emulator, CoreLX
+// source opens
+// in the editor and runs Build + Run, and a .ncdx project is built and run
+// without replacing the editor buffer.
+func (s *devKitState) openExternalFile(path string) {
+	switch {
+	case isExternalROMPath(path):
+		if err := s.loadExternalROM(path); err != nil {
+			dialog.ShowError(err, s.window)
+			s.appendBuildOutput("Open failed: " + err.Error())
+			s.setStatus("Open failed")
+		}
+	case fileassoc.Classify(path) == fileassoc.KindSource:
+		open := func() {
+			if err := s.loadFile(path, true); err != nil {
+				dialog.ShowError(err, s.window)
+				s.setStatus("Open failed")
+				return
+			}
+			s.runBuild(true)
+		}
+		if s.dirty {
+			dialog.NewConfirm("Discard current changes?", fmt.Sprintf("Current unsaved editor content will be replaced by %s.", filepath.Base(path)), func(confirm bool) {
+				if confirm {
+					open()
+				}
+			}, s.window).Show()
+			return
+		}
+		open()
+	default:
+		s.setStatus(fmt.Sprintf("Unsupported file type: %s", filepath.Base(path)))
+	}
+}
+
+// isExternalROMPath reports whether path loads straight into the emulator
+// (a build artifact, or a .ncdx project built on the fly) rather than into
+// the editor.
+func isExternalROMPath(path string) bool {
+	return isBuildArtifactPath(path) || strings.EqualFold(filepath.Ext(path), ".ncdx")
+}
+
+// isBuildArtifactPath reports whether path is a built ROM: a .rom, or a .zip
+// holding one.
+func isBuildArtifactPath(path string) bool {
+	k := fileassoc.Classify(path)
+	return k == fileassoc.KindROM || k == fileassoc.KindBundle
+}
+
+func (s *devKitState) loadExternalROM(path string) error {
+	data, err := fileassoc.ReadROM(path)
+	if err != nil {
+		return err
+	}
+	if err := s.loadROMIntoEmbedded(data); err != nil {
+		return err
+	}
+	s.sessionROMPath = path
+	if isBuildArtifactPath(path) {
+		s.lastROMPath = path
+		s.rememberROMPath(path)
+	}
+	s.setViewMode(viewModeFull)
+	s.appendBuildOutput("Loaded build artifact into emulator subsystem: " + path)
+	s.appendROMInfo(data)
+	s.setStatus("Project build loaded")
+	return nil
+}
+
+// registerFileTypes installs the freedesktop MIME package and a desktop entry
+// that opens .rom and .corelx files with this Dev Kit binary.
+func registerFileTypes() error {
+	files, err := fileassoc.Register("", fileassoc.App{
+		ID:        "nitro-core-dx-devkit",
+		Name:      "Nitro-Core-DX Dev Kit",
+		Comment:   "Edit, build, and run CoreLX projects",
+		MimeTypes: []string{fileassoc.SourceMimeType, fileassoc.ProjectMimeType, fileassoc.ROMMimeType},
+	})
+	for _, f := range files {
+		fmt.Printf("Wrote %s\n", f)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Println("Refresh the desktop caches with update-mime-database and update-desktop-database.")
+	return nil
+}
+
+// resolveOpenFlag picks the file to open at startup: --open (path or file://
+// URL from a desktop launcher), else -file, else a bare argument.
+func resolveOpenFlag(openTarget, filePath string, args []string) (string, error) {
+	target := openTarget
+	if target == "" {
+		target = filePath
+	}
+	if target == "" && len(args) > 0 {
+		target = args[0]
+	}
+	if target == "" {
+		return "", nil
+	}
+	return fileassoc.ParseOpenTarget(target)
+}
@@ -0,0 +1,37 @@
+package main
+
+import "testing"
+
+func TestResolveOpenFlagPrecedence(t *testing.T) {
+	for _, tc := range []struct {
+		open, file string
+		args       []string
+		want       string
+	}{
+		{"file:///tmp/a%20b.corelx", "/x/main.corelx", []string{"c.rom"}, "/tmp/a b.corelx"},
+		{"", "/x/main.corelx", []string{"c.rom"}, "/x/main.corelx"},
+		{"", "", []string{"c.rom"}, "c.rom"},
+		{"", "", nil, ""},
+	} {
+		got, err := resolveOpenFlag(tc.open, tc.file, tc.args)
+		if err != nil || got != tc.want {
+			t.Errorf("resolveOpenFlag(%q, %q, %v) = %q, %v; want %q", tc.open, tc.file, tc.args, got, err, tc.want)
+		}
+	}
+	if _, err := resolveOpenFlag("https://example.com/a.rom", "", nil); err == nil {
+		t.Fatalf("expected non-file URL to be rejected")
+	}
+}
+
+func TestIsExternalROMPath(t *testing.T) {
+	for path, want := range map[string]bool{
+		"game.rom":    true,
+		"pack.NCDX":   true,
+		"main.corelx": false,
+		"notes.txt":   false,
+	} {
+		if got := isExternalROMPath(path); got != want {
+			t.Errorf("isExternalROMPath(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
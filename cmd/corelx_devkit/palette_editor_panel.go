@@ -0,0 +1,332 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	paletteEditorSwatchSize = 20
+
+	paletteEditorExportCalls = "gfx.set_palette calls"
+	paletteEditorExportAsset = "Palette asset"
+	paletteEditorScopeOne    = "Selected palette"
+	paletteEditorScopeAll    = "All palettes"
+)
+
+var (
+	paletteEditorSelectBorder = color.NRGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}
+	paletteEditorSwatchBorder = color.NRGBA{R: 0x30, G: 0x34, B: 0x3C, A: 0xFF}
+)
+
+// paletteSwatch is one tappable CGRAM color cell in the palette grid.
+type paletteSwatch struct {
+	widget.BaseWidget
+	rect  *canvas.Rectangle
+	onTap func()
+}
+
+func newPaletteSwatch(onTap func()) *paletteSwatch {
+	w := &paletteSwatch{
+		rect:  canvas.NewRectangle(color.NRGBA{A: 0xFF}),
+		onTap: onTap,
+	}
+	w.rect.StrokeColor = paletteEditorSwatchBorder
+	w.rect.StrokeWidth = 1
+	w.ExtendBaseWidget(w)
+	return w
+}
+
+func (w *paletteSwatch) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(w.rect)
+}
+
+func (w *paletteSwatch) MinSize() fyne.Size {
+	return fyne.NewSize(paletteEditorSwatchSize, paletteEditorSwatchSize)
+}
+
+func (w *paletteSwatch) Tapped(*fyne.PointEvent) {
+	if w.onTap != nil {
+		w.onTap()
+	}
+}
+
+func (w *paletteSwatch) set(rgb555 uint16, selected bool) {
+	w.rect.FillColor = rgb555ToNRGBA(rgb555)
+	if selected {
+		w.rect.StrokeColor = paletteEditorSelectBorder
+		w.rect.StrokeWidth = 2
+	} else {
+		w.rect.StrokeColor = paletteEditorSwatchBorder
+		w.rect.StrokeWidth = 1
+	}
+	w.rect.Refresh()
+}
+
+// buildPaletteEditorPane builds the Palette tab: all 16 palettes × 16 colors
+// from a working copy of CGRAM, an RGB555 picker for the selected color, and
+// exports as gfx.set_palette calls or a palette asset. With "Live" on, every
+// edit is also written to the running emulator's CGRAM.
+func (s *devKitState) buildPaletteEditorPane() fyne.CanvasObject {
+	var colors [spriteLabPaletteCount]uint16
+	selectedPalette, selectedColor := 0, 0
+	suppressEditors := false
+	live := true
+
+	statusLabel := widget.NewLabel("Palette: read CGRAM from a running build, or edit and export")
+	selectedLabel := widget.NewLabel("")
+	selectedLabel.TextStyle = fyne.TextStyle{Monospace: true}
+	selectedChip := canvas.NewRectangle(color.NRGBA{A: 0xFF})
+	selectedChip.SetMinSize(fyne.NewSize(48, 48))
+
+	channelSliders := [3]*widget.Slider{}
+	channelLabels := [3]*widget.Label{}
+	for i := range channelSliders {
+		channelSliders[i] = widget.NewSlider(0, 31)
+		channelSliders[i].Step = 1
+		channelLabels[i] = widget.NewLabel("00")
+		channelLabels[i].TextStyle = fyne.TextStyle{Monospace: true}
+	}
+	hexEntry := widget.NewEntry()
+	hexEntry.SetPlaceHolder("0x0000")
+
+	swatches := make([]*paletteSwatch, spriteLabPaletteCount)
+
+	refreshSwatch := func(idx int) {
+		swatches[idx].set(colors[idx], idx == selectedPalette*spriteLabColorsPerBank+selectedColor)
+	}
+	refreshEditors := func() {
+		val := colors[selectedPalette*spriteLabColorsPerBank+selectedColor]
+		r, g, b := decodeRGB555(val)
+		suppressEditors = true
+		for i, v := range []uint8{r, g, b} {
+			channelSliders[i].SetValue(float64(v))
+			channelLabels[i].SetText(fmt.Sprintf("%02d", v))
+		}
+		hexEntry.SetText(fmt.Sprintf("0x%04X", val))
+		suppressEditors = false
+		selectedLabel.SetText(fmt.Sprintf("Palette %X color %X  RGB555 0x%04X", selectedPalette, selectedColor, val))
+		selectedChip.FillColor = rgb555ToNRGBA(val)
+		selectedChip.Refresh()
+	}
+	refreshAll := func() {
+		for i := range swatches {
+			refreshSwatch(i)
+		}
+		refreshEditors()
+	}
+
+	setSelectedColor := func(val uint16) {
+		val &= 0x7FFF
+		idx := selectedPalette*spriteLabColorsPerBank + selectedColor
+		colors[idx] = val
+		refreshSwatch(idx)
+		refreshEditors()
+		if !live {
+			return
+		}
+		if err := s.backend.SetPaletteColor(selectedPalette, selectedColor, val); err != nil {
+			statusLabel.SetText(fmt.Sprintf("Live write skipped: %v", err))
+			return
+		}
+		statusLabel.SetText(fmt.Sprintf("Wrote palette %X color %X = 0x%04X to CGRAM", selectedPalette, selectedColor, val))
+	}
+
+	grid := container.NewVBox()
+	for p := 0; p < spriteLabPaletteBanks; p++ {
+		row := container.NewHBox()
+		label := widget.NewLabel(fmt.Sprintf("%X", p))
+		label.TextStyle = fyne.TextStyle{Monospace: true, Bold: true}
+		row.Add(label)
+		for c := 0; c < spriteLabColorsPerBank; c++ {
+			palette, index := p, c
+			swatch := newPaletteSwatch(func() {
+				prev := selectedPalette*spriteLabColorsPerBank + selectedColor
+				selectedPalette, selectedColor = palette, index
+				refreshSwatch(prev)
+				refreshSwatch(palette*spriteLabColorsPerBank + index)
+				refreshEditors()
+			})
+			swatches[p*spriteLabColorsPerBank+c] = swatch
+			row.Add(swatch)
+		}
+		grid.Add(row)
+	}
+
+	for i := range channelSliders {
+		channelSliders[i].OnChanged = func(float64) {
+			if suppressEditors {
+				return
+			}
+			r := uint8(channelSliders[0].Value)
+			g := uint8(channelSliders[1].Value)
+			b := uint8(channelSliders[2].Value)
+			setSelectedColor(encodeRGB555(clamp5(r), clamp5(g), clamp5(b)))
+		}
+	}
+	hexEntry.OnSubmitted = func(text string) {
+		val, err := parseSpriteLabRGB555Hex(text)
+		if err != nil || val > 0x7FFF {
+			statusLabel.SetText("Invalid RGB555 hex (0x0000-0x7FFF)")
+			refreshEditors()
+			return
+		}
+		setSelectedColor(val)
+	}
+
+	liveCheck := widget.NewCheck("Live", func(v bool) {
+		live = v
+		s.setStatus(fmt.Sprintf("Palette live CGRAM writes %s", onOff(v, "on", "off")))
+	})
+	liveCheck.SetChecked(live)
+
+	readButton := widget.NewButton("Read CGRAM", func() {
+		snap := s.backend.PaletteSnapshot()
+		if !snap.Loaded {
+			statusLabel.SetText("Read CGRAM: no build loaded")
+			return
+		}
+		colors = snap.Colors
+		refreshAll()
+		statusLabel.SetText("Loaded 256 colors from CGRAM")
+	})
+	writeButton := widget.NewButton("Write All To CGRAM", func() {
+		for i, val := range colors {
+			if err := s.backend.SetPaletteColor(i/spriteLabColorsPerBank, i%spriteLabColorsPerBank, val); err != nil {
+				statusLabel.SetText(fmt.Sprintf("Write CGRAM: %v", err))
+				return
+			}
+		}
+		statusLabel.SetText("Wrote 256 colors to CGRAM")
+	})
+
+	formatSelect := widget.NewSelect([]string{paletteEditorExportCalls, paletteEditorExportAsset}, nil)
+	formatSelect.SetSelected(paletteEditorExportCalls)
+	scopeSelect := widget.NewSelect([]string{paletteEditorScopeOne, paletteEditorScopeAll}, nil)
+	scopeSelect.SetSelected(paletteEditorScopeOne)
+	nameEntry := widget.NewEntry()
+	nameEntry.SetText("Palette0")
+	nameEntry.SetPlaceHolder("Asset name")
+
+	exportText := func() string {
+		palettes := []int{selectedPalette}
+		if scopeSelect.Selected == paletteEditorScopeAll {
+			palettes = palettes[:0]
+			for p := 0; p < spriteLabPaletteBanks; p++ {
+				palettes = append(palettes, p)
+			}
+		}
+		if formatSelect.Selected == paletteEditorExportAsset {
+			return paletteEditorAssetSnippet(nameEntry.Text, colors[:], palettes)
+		}
+		return paletteEditorSetPaletteSnippet(colors[:], palettes)
+	}
+	copyButton := widget.NewButton("Copy", func() {
+		if s.window != nil && s.window.Clipboard() != nil {
+			s.window.Clipboard().SetContent(exportText())
+			statusLabel.SetText("Palette export copied")
+		}
+	})
+	insertButton := widget.NewButton("Insert Into Code", func() {
+		snippet := exportText()
+		if snippet == "" {
+			dialog.ShowError(fmt.Errorf("nothing to export"), s.window)
+			return
+		}
+		next := s.sourceEditor.Text()
+		if strings.TrimSpace(next) != "" {
+			next = strings.TrimRight(next, "\n") + "\n\n"
+		}
+		next += snippet + "\n"
+		s.setSourceContent(next, true, false)
+		if s.workbenchTabs != nil {
+			s.workbenchTabs.SelectIndex(0)
+		}
+		s.setStatus("Inserted palette export")
+		statusLabel.SetText("Inserted palette export into code")
+	})
+	insertButton.Importance = widget.HighImportance
+
+	channelRow := func(name string, i int) fyne.CanvasObject {
+		return container.NewBorder(nil, nil, widget.NewLabel(name), channelLabels[i], channelSliders[i])
+	}
+	picker := container.NewVBox(
+		container.NewHBox(selectedChip, selectedLabel),
+		channelRow("R", 0),
+		channelRow("G", 1),
+		channelRow("B", 2),
+		container.NewBorder(nil, nil, widget.NewLabel("Hex"), nil, hexEntry),
+		widget.NewSeparator(),
+		container.NewHBox(liveCheck, readButton, writeButton),
+		widget.NewSeparator(),
+		widget.NewLabel("Export"),
+		formatSelect,
+		scopeSelect,
+		container.NewBorder(nil, nil, widget.NewLabel("Name"), nil, nameEntry),
+		container.NewHBox(copyButton, insertButton),
+	)
+
+	refreshAll()
+	return container.NewBorder(
+		container.NewHBox(statusLabel, layout.NewSpacer()),
+		nil, nil, nil,
+		container.NewHSplit(container.NewScroll(grid), container.NewVScroll(picker)),
+	)
+}
+
+// paletteEditorSetPaletteSnippet renders every color of the given palettes as
+// gfx.set_palette calls.
+func paletteEditorSetPaletteSnippet(colors []uint16, palettes []int) string {
+	var sb strings.Builder
+	for _, p := range palettes {
+		if p < 0 || p >= spriteLabPaletteBanks || (p+1)*spriteLabColorsPerBank > len(colors) {
+			continue
+		}
+		if sb.Len() > 0 {
+			sb.WriteByte('\n')
+		}
+		sb.WriteString(fmt.Sprintf("-- Palette %d\n", p))
+		for i := 0; i < spriteLabColorsPerBank; i++ {
+			sb.WriteString(fmt.Sprintf("gfx.set_palette(%d, %d, 0x%04X)\n", p, i, colors[p*spriteLabColorsPerBank+i]))
+		}
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// paletteEditorAssetSnippet renders the given palettes as a `palette hex`
+// asset: one line of 16 little-endian RGB555 words per palette, in CGRAM
+// byte order. Rows are quoted: bare bytes such as 1F would lex as a number
+// followed by an identifier.
+func paletteEditorAssetSnippet(name string, colors []uint16, palettes []int) string {
+	var sb strings.Builder
+	sb.WriteString("asset ")
+	sb.WriteString(sanitizeSpriteLabName(name))
+	sb.WriteString(": palette hex")
+	rows := 0
+	for _, p := range palettes {
+		if p < 0 || p >= spriteLabPaletteBanks || (p+1)*spriteLabColorsPerBank > len(colors) {
+			continue
+		}
+		data := make([]byte, 0, spriteLabColorsPerBank*2)
+		for i := 0; i < spriteLabColorsPerBank; i++ {
+			v := colors[p*spriteLabColorsPerBank+i]
+			data = append(data, byte(v), byte(v>>8))
+		}
+		sb.WriteString("\n    \"")
+		sb.WriteString(bytesToHexFields(data))
+		sb.WriteString("\"")
+		rows++
+	}
+	if rows == 0 {
+		return ""
+	}
+	return sb.String()
+}
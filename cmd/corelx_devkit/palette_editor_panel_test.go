@@ -0,0 +1,48 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPaletteEditorSetPaletteSnippet(t *testing.T) {
+	colors := make([]uint16, spriteLabPaletteCount)
+	colors[2*spriteLabColorsPerBank+3] = 0x1234
+	snippet := paletteEditorSetPaletteSnippet(colors, []int{2})
+	lines := strings.Split(snippet, "\n")
+	if len(lines) != 1+spriteLabColorsPerBank {
+		t.Fatalf("expected header + 16 calls, got %d lines:\n%s", len(lines), snippet)
+	}
+	if lines[0] != "-- Palette 2" {
+		t.Fatalf("unexpected header %q", lines[0])
+	}
+	if lines[4] != "gfx.set_palette(2, 3, 0x1234)" {
+		t.Fatalf("unexpected call %q", lines[4])
+	}
+	if got := paletteEditorSetPaletteSnippet(colors, []int{16}); got != "" {
+		t.Fatalf("expected out-of-range palette to be skipped, got %q", got)
+	}
+}
+
+func TestPaletteEditorAssetSnippetLittleEndian(t *testing.T) {
+	colors := make([]uint16, spriteLabPaletteCount)
+	colors[0] = 0x7C1F
+	colors[spriteLabColorsPerBank+1] = 0x03E0
+	snippet := paletteEditorAssetSnippet("hero pal", colors, []int{0, 1})
+	lines := strings.Split(snippet, "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected header + 2 rows, got:\n%s", snippet)
+	}
+	if lines[0] != "asset hero_pal: palette hex" {
+		t.Fatalf("unexpected header %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], `    "1F 7C 00 00`) {
+		t.Fatalf("expected little-endian first color, got %q", lines[1])
+	}
+	if !strings.HasPrefix(lines[2], `    "00 00 E0 03`) {
+		t.Fatalf("expected palette 1 color 1 second, got %q", lines[2])
+	}
+	if fields := strings.Fields(strings.Trim(strings.TrimSpace(lines[1]), `"`)); len(fields) != spriteLabColorsPerBank*2 {
+		t.Fatalf("expected 32 bytes per palette row, got %d", len(fields))
+	}
+}
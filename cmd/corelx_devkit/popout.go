@@ -0,0 +1,213 @@
+package main
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Pop-out windows: the emulator display and the Debugger tab can leave the
+// main window for a window of their own (a second monitor, say). Closing a
+// pop-out docks its pane back. Whether each pane is popped out, and the
+// window's size, persist in settings; Fyne has no portable window position
+// API, so placement is left to the window manager.
+
+const (
+	debuggerTabName = "Debugger"
+
+	defaultEmulatorWindowWidth  = devKitScreenW*2 + 40
+	defaultEmulatorWindowHeight = devKitScreenH*2 + 80
+	defaultDebuggerWindowWidth  = 640
+	defaultDebuggerWindowHeight = 480
+	minDetachedWindowSize       = 160
+)
+
+// detachedWindowSettings records one pop-out pane.
+type detachedWindowSettings struct {
+	Detached bool    `json:"detached"`
+	Width    float32 `json:"width"`
+	Height   float32 `json:"height"`
+}
+
+// normalizeDetachedWindow replaces a missing or tiny remembered size with
+// the default.
+func normalizeDetachedWindow(w *detachedWindowSettings, defaultWidth, defaultHeight float32) {
+	if w.Width < minDetachedWindowSize || w.Height < minDetachedWindowSize {
+		w.Width = defaultWidth
+		w.Height = defaultHeight
+	}
+}
+
+// emulatorPane is the emulator display with its status line, as docked in
+// the main window or shown in the pop-out.
+func (s *devKitState) emulatorPane(header ...fyne.CanvasObject) fyne.CanvasObject {
+	top := container.NewHBox(append([]fyne.CanvasObject{s.emuLabel, layout.NewSpacer()}, append(header, s.captureCheck)...)...)
+	return container.NewBorder(top, nil, nil, nil, s.emuSurface)
+}
+
+// emulatorHostWindow is the window currently showing the emulator display.
+func (s *devKitState) emulatorHostWindow() fyne.Window {
+	if s.emuWindow != nil {
+		return s.emuWindow
+	}
+	return s.window
+}
+
+// toggleEmulatorWindow pops the emulator display out, or docks it back.
+func (s *devKitState) toggleEmulatorWindow() {
+	s.setEmulatorDetached(s.emuWindow == nil)
+}
+
+// toggleDebuggerWindow pops the Debugger tab out, or docks it back.
+func (s *devKitState) toggleDebuggerWindow() {
+	s.setDebuggerDetached(s.debugWindow == nil)
+}
+
+func (s *devKitState) setEmulatorDetached(detached bool) {
+	if detached == (s.emuWindow != nil) {
+		return
+	}
+	if !detached {
+		s.rememberDetachedSize(s.emuWindow, &s.settings.EmulatorWindow)
+		w := s.emuWindow
+		s.emuWindow = nil
+		w.Close()
+		s.settings.EmulatorWindow.Detached = false
+		s.setViewMode(s.currentView)
+		s.refreshMainMenu()
+		return
+	}
+
+	s.settings.EmulatorWindow.Detached = true
+	// Rebuild the main layout first: a canvas object can only live in one
+	// window.
+	s.emuWindow = fyne.CurrentApp().NewWindow("Nitro-Core-DX - Emulator")
+	s.setViewMode(s.currentView)
+	w := s.emuWindow
+	w.SetContent(s.emulatorPane())
+	w.Resize(fyne.NewSize(s.settings.EmulatorWindow.Width, s.settings.EmulatorWindow.Height))
+	s.setupDetachedKeyboardInput(w)
+	w.SetCloseIntercept(func() { s.setEmulatorDetached(false) })
+	w.Show()
+	if s.captureGameInput {
+		s.focusEmulatorInput()
+	}
+	s.refreshMainMenu()
+}
+
+func (s *devKitState) setDebuggerDetached(detached bool) {
+	if detached == (s.debugWindow != nil) || s.bottomLeftTabs == nil {
+		return
+	}
+	if !detached {
+		s.rememberDetachedSize(s.debugWindow, &s.settings.DebuggerWindow)
+		w := s.debugWindow
+		s.debugWindow = nil
+		w.Close()
+		s.settings.DebuggerWindow.Detached = false
+		// Dock the tab back where it was.
+		item := container.NewTabItem(debuggerTabName, s.debuggerContent())
+		at := s.debuggerTabIndex
+		if at < 0 || at > len(s.bottomLeftTabs.Items) {
+			at = len(s.bottomLeftTabs.Items)
+		}
+		items := append([]*container.TabItem{}, s.bottomLeftTabs.Items[:at]...)
+		items = append(items, item)
+		s.bottomLeftTabs.SetItems(append(items, s.bottomLeftTabs.Items[at:]...))
+		s.persistSettings()
+		s.refreshMainMenu()
+		return
+	}
+
+	s.settings.DebuggerWindow.Detached = true
+	s.debuggerTabIndex = -1
+	for i, item := range s.bottomLeftTabs.Items {
+		if item.Text == debuggerTabName {
+			s.debuggerTabIndex = i
+			s.bottomLeftTabs.RemoveIndex(i)
+			break
+		}
+	}
+	s.debugWindow = fyne.CurrentApp().NewWindow("Nitro-Core-DX - Debugger")
+	w := s.debugWindow
+	w.SetContent(s.debuggerContent())
+	w.Resize(fyne.NewSize(s.settings.DebuggerWindow.Width, s.settings.DebuggerWindow.Height))
+	w.SetCloseIntercept(func() { s.setDebuggerDetached(false) })
+	w.Show()
+	s.refreshDebuggerOutput()
+	s.persistSettings()
+	s.refreshMainMenu()
+}
+
+// rememberDetachedSize stores a pop-out's current size in settings.
+func (s *devKitState) rememberDetachedSize(w fyne.Window, geom *detachedWindowSettings) {
+	if w == nil || w.Canvas() == nil {
+		return
+	}
+	size := w.Canvas().Size()
+	if size.Width >= minDetachedWindowSize && size.Height >= minDetachedWindowSize {
+		geom.Width = size.Width
+		geom.Height = size.Height
+	}
+}
+
+// captureDetachedWindows records pop-out sizes without docking them, so
+// they reopen the same way next session.
+func (s *devKitState) captureDetachedWindows() {
+	s.rememberDetachedSize(s.emuWindow, &s.settings.EmulatorWindow)
+	s.rememberDetachedSize(s.debugWindow, &s.settings.DebuggerWindow)
+}
+
+// closeDetachedWindows closes the pop-outs at exit, keeping their Detached
+// setting.
+func (s *devKitState) closeDetachedWindows() {
+	for _, w := range []fyne.Window{s.emuWindow, s.debugWindow} {
+		if w != nil {
+			w.SetCloseIntercept(nil)
+			w.Close()
+		}
+	}
+	s.emuWindow = nil
+	s.debugWindow = nil
+}
+
+// restoreDetachedWindows reopens the pop-outs that were open last session.
+func (s *devKitState) restoreDetachedWindows() {
+	if s.settings.EmulatorWindow.Detached {
+		s.setEmulatorDetached(true)
+	}
+	if s.settings.DebuggerWindow.Detached {
+		s.setDebuggerDetached(true)
+	}
+}
+
+// setupDetachedKeyboardInput routes keys typed in the emulator pop-out to
+// the game. The code editor lives in the main window, so unlike there,
+// nothing else on this canvas wants the keys.
+func (s *devKitState) setupDetachedKeyboardInput(w fyne.Window) {
+	if c, ok := w.Canvas().(desktop.Canvas); ok {
+		c.SetOnKeyDown(func(key *fyne.KeyEvent) {
+			s.window.Canvas().Unfocus()
+			s.handleKeyDown(key)
+		})
+		c.SetOnKeyUp(func(key *fyne.KeyEvent) {
+			s.window.Canvas().Unfocus()
+			s.handleKeyUp(key)
+		})
+	}
+	w.Canvas().SetOnTypedKey(func(key *fyne.KeyEvent) {
+		s.window.Canvas().Unfocus()
+		s.handleTypedKey(key)
+	})
+}
+
+// detachedEmulatorPlaceholder stands in for the emulator display in the
+// main window while it is popped out.
+func (s *devKitState) detachedEmulatorPlaceholder() fyne.CanvasObject {
+	return container.NewCenter(container.NewVBox(
+		widget.NewLabel("The emulator display is in its own window."),
+		widget.NewButton("Dock Emulator", func() { s.setEmulatorDetached(false) }),
+	))
+}
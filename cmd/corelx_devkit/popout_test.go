@@ -0,0 +1,76 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"fyne.io/fyne/v2/container"
+	fynetest "fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+	"nitro-core-dx/internal/devkit"
+)
+
+func TestLoadDevKitSettingsPopOutWindows(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings_popout.json")
+	raw := []byte(`{"emulator_window":{"detached":true,"width":900,"height":600},"debugger_window":{"detached":true,"width":12,"height":0}}`)
+	if err := os.WriteFile(path, raw, 0644); err != nil {
+		t.Fatalf("write settings fixture: %v", err)
+	}
+	out, err := loadDevKitSettings(path)
+	if err != nil {
+		t.Fatalf("load settings: %v", err)
+	}
+	if got := out.EmulatorWindow; !got.Detached || got.Width != 900 || got.Height != 600 {
+		t.Fatalf("emulator window = %+v, want detached 900x600", got)
+	}
+	if got := out.DebuggerWindow; !got.Detached || got.Width != defaultDebuggerWindowWidth || got.Height != defaultDebuggerWindowHeight {
+		t.Fatalf("debugger window = %+v, want detached at the default size", got)
+	}
+
+	defaults, err := loadDevKitSettings(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil {
+		t.Fatalf("load defaults: %v", err)
+	}
+	if defaults.EmulatorWindow.Detached || defaults.EmulatorWindow.Width != defaultEmulatorWindowWidth {
+		t.Fatalf("default emulator window = %+v", defaults.EmulatorWindow)
+	}
+}
+
+func TestDebuggerPopOutDocksBackInPlace(t *testing.T) {
+	a := fynetest.NewApp()
+	defer a.Quit()
+
+	s := &devKitState{
+		backend:        devkit.NewService(t.TempDir()),
+		window:         fynetest.NewWindow(nil),
+		settings:       defaultDevKitSettings(),
+		debuggerOutput: newReadOnlyTextArea(),
+	}
+	s.bottomLeftTabs = container.NewAppTabs(
+		container.NewTabItem("Diagnostics", widget.NewLabel("d")),
+		container.NewTabItem(debuggerTabName, s.debuggerOutput),
+		container.NewTabItem("Console", widget.NewLabel("c")),
+	)
+
+	s.toggleDebuggerWindow()
+	if s.debugWindow == nil || !s.settings.DebuggerWindow.Detached {
+		t.Fatal("debugger did not pop out")
+	}
+	for _, item := range s.bottomLeftTabs.Items {
+		if item.Text == debuggerTabName {
+			t.Fatal("Debugger tab still docked while popped out")
+		}
+	}
+	if s.debugWindow.Content() != s.debuggerOutput {
+		t.Fatal("pop-out does not show the debugger output")
+	}
+
+	s.toggleDebuggerWindow()
+	if s.debugWindow != nil || s.settings.DebuggerWindow.Detached {
+		t.Fatal("debugger did not dock back")
+	}
+	if got := s.bottomLeftTabs.Items[1].Text; got != debuggerTabName || len(s.bottomLeftTabs.Items) != 3 {
+		t.Fatalf("tab 1 = %q of %d, want the Debugger tab back in place", got, len(s.bottomLeftTabs.Items))
+	}
+}
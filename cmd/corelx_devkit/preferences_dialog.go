@@ -0,0 +1,345 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"nitro-core-dx/internal/i18n"
+	"nitro-core-dx/internal/input"
+)
+
+// preferenceEntry describes one row of the Preferences dialog for search.
+type preferenceEntry struct {
+	section  string
+	label    string
+	help     string
+	keywords string
+}
+
+// matches reports whether every word of query appears in the entry's
+// section, label, help text or keywords (case-insensitive).
+func (p preferenceEntry) matches(query string) bool {
+	hay := strings.ToLower(strings.Join([]string{p.section, p.label, p.help, p.keywords}, " "))
+	for _, word := range strings.Fields(strings.ToLower(query)) {
+		if !strings.Contains(hay, word) {
+			return false
+		}
+	}
+	return true
+}
+
+var (
+	preferenceFontSizes         = []int{0, 10, 11, 12, 13, 14, 16, 18, 20, 24}
+	preferenceTabWidths         = []int{2, 4, 8}
+	preferenceAutosaveIntervals = []int{0, 2, 5, 10, 30, 60}
+	preferenceAudioLatencies    = []int{1, 2, 3, 4, 6, 8}
+	preferenceEmulatorSpeeds    = []float64{0.25, 0.5, 1, 1.5, 2, 3, 4}
+	preferenceTurboRates        = []int{5, 10, 15, 20, 30}
+	preferenceThemes            = []string{themeSystem, themeDark, themeLight}
+	preferenceDensities         = []string{"compact", "standard"}
+	preferenceEditorSchemes     = []string{editorSchemeDark, editorSchemeLight}
+)
+
+func formatEditorFontSize(size int) string {
+	if size == 0 {
+		return i18n.T("Theme default")
+	}
+	return fmt.Sprintf("%d pt", size)
+}
+
+func formatAutosaveInterval(seconds int) string {
+	if seconds == 0 {
+		return i18n.T("Every edit")
+	}
+	return i18n.T("Every %d s", seconds)
+}
+
+func formatAudioLatency(frames int) string {
+	return i18n.T("%d frames (~%d ms)", frames, (frames*1000+30)/60)
+}
+
+func formatEmulatorSpeed(speed float64) string {
+	return fmt.Sprintf("%g%%", speed*100)
+}
+
+func formatTurboRate(hz int) string {
+	return i18n.T("%d presses/s", hz)
+}
+
+// formatLanguage names a Language setting: a catalog's own language name,
+// or the environment default for "".
+func formatLanguage(tag string) string {
+	if tag == "" {
+		return i18n.T("System (%s)", i18n.Name(i18n.Detect()))
+	}
+	return i18n.Name(tag)
+}
+
+// formatOption labels a lowercase theme, color scheme or density value
+// ("dark", "compact") in the UI language.
+func formatOption(s string) string {
+	switch s {
+	case themeSystem:
+		return i18n.T("System")
+	case themeDark:
+		return i18n.T("Dark")
+	case themeLight:
+		return i18n.T("Light")
+	case "compact":
+		return i18n.T("Compact")
+	case "standard":
+		return i18n.T("Standard")
+	}
+	return capitalize(s)
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// newPreferenceSelect builds a Select over values. A current value missing
+// from values (hand-edited settings) is appended so it still displays.
+func newPreferenceSelect[T comparable](values []T, current T, format func(T) string, apply func(T)) *widget.Select {
+	found := false
+	for _, v := range values {
+		if v == current {
+			found = true
+			break
+		}
+	}
+	if !found {
+		values = append(append([]T(nil), values...), current)
+	}
+	labels := make([]string, len(values))
+	for i, v := range values {
+		labels[i] = format(v)
+	}
+	sel := widget.NewSelect(labels, nil)
+	sel.SetSelected(format(current))
+	sel.OnChanged = func(string) {
+		if i := sel.SelectedIndex(); i >= 0 {
+			apply(values[i])
+		}
+	}
+	return sel
+}
+
+func newPreferenceCheck(current bool, apply func(bool)) *widget.Check {
+	check := widget.NewCheck("", nil)
+	check.SetChecked(current)
+	check.OnChanged = apply
+	return check
+}
+
+// showPreferences opens the Preferences dialog. Changes apply immediately
+// and are written to the settings file.
+func (s *devKitState) showPreferences() {
+	type prefRow struct {
+		entry  preferenceEntry
+		object fyne.CanvasObject
+	}
+	var rows []prefRow
+	add := func(entry preferenceEntry, control fyne.CanvasObject) {
+		label := widget.NewLabelWithStyle(entry.label, fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+		help := widget.NewLabel(entry.help)
+		help.Wrapping = fyne.TextWrapWord
+		help.Importance = widget.LowImportance
+		rows = append(rows, prefRow{entry, container.NewVBox(container.NewGridWithColumns(2, label, control), help)})
+	}
+
+	add(preferenceEntry{i18n.T("Editor"), i18n.T("Font size"), i18n.T("Code editor text size."), "font text zoom"},
+		newPreferenceSelect(preferenceFontSizes, s.settings.EditorFontSize, formatEditorFontSize, s.setEditorFontSize))
+	add(preferenceEntry{i18n.T("Editor"), i18n.T("Tab width"), i18n.T("Columns per tab stop."), "indent indentation"},
+		newPreferenceSelect(preferenceTabWidths, s.settings.EditorTabWidth, func(n int) string { return fmt.Sprintf("%d", n) }, func(n int) {
+			s.settings.EditorTabWidth = n
+			s.applyEditorPreferences()
+			s.persistSettings()
+		}))
+	spacesCheck := newPreferenceCheck(s.settings.EditorInsertSpaces, func(v bool) {
+		s.settings.EditorInsertSpaces = v
+		s.applyEditorPreferences()
+		s.persistSettings()
+	})
+	add(preferenceEntry{i18n.T("Editor"), i18n.T("Tab key inserts spaces"), i18n.T("Insert tab-width spaces instead of a tab character."), "indent soft tabs"}, spacesCheck)
+	formatCheck := newPreferenceCheck(s.settings.EditorFormatOnSave, func(v bool) {
+		s.settings.EditorFormatOnSave = v
+		s.persistSettings()
+	})
+	add(preferenceEntry{i18n.T("Editor"), i18n.T("Format on save"), i18n.T("Run the CoreLX formatter (corelx fmt) before saving. Source that does not parse is saved as is."), "corelx fmt formatter indentation"}, formatCheck)
+	add(preferenceEntry{i18n.T("Editor"), i18n.T("Autosave interval"), i18n.T("How often unsaved edits are written to the recovery journal."), "backup crash recovery journal"},
+		newPreferenceSelect(preferenceAutosaveIntervals, s.settings.AutosaveInterval, formatAutosaveInterval, func(n int) {
+			s.settings.AutosaveInterval = n
+			s.persistSettings()
+		}))
+
+	backgroundCheck := newPreferenceCheck(s.settings.BackgroundBuild, func(v bool) {
+		s.settings.BackgroundBuild = v
+		s.persistSettings()
+		if !v {
+			s.stopBackgroundBuild()
+		}
+	})
+	add(preferenceEntry{i18n.T("Editor"), i18n.T("Build in the background"), i18n.T("Check the code a moment after you stop typing and update the diagnostics and manifest without pressing Build."), "compile check errors live idle"}, backgroundCheck)
+
+	add(preferenceEntry{i18n.T("Appearance"), i18n.T("Theme"), i18n.T("Light or dark UI; System follows the OS setting."), "color dark light mode"},
+		newPreferenceSelect(preferenceThemes, s.settings.Theme, formatOption, s.setTheme))
+	add(preferenceEntry{i18n.T("Appearance"), i18n.T("Editor colors"), i18n.T("Code editor color preset; Customize edits keywords, strings, background and more."), "syntax highlighting scheme palette"},
+		container.NewBorder(nil, nil, nil, widget.NewButton(i18n.T("Customize..."), s.showEditorColorsDialog),
+			newPreferenceSelect(preferenceEditorSchemes, s.settings.EditorColorScheme, formatOption, s.setEditorColorScheme)))
+	add(preferenceEntry{i18n.T("Appearance"), i18n.T("Language"), i18n.T("Language of menus, commands and dialogs. Open dialogs update when reopened."), "locale translation idioma"},
+		newPreferenceSelect(append([]string{""}, i18n.Locales()...), s.settings.Language, formatLanguage, s.setLanguage))
+	add(preferenceEntry{i18n.T("Appearance"), i18n.T("UI density"), i18n.T("Spacing and text size of panels and controls."), "compact standard padding"},
+		newPreferenceSelect(preferenceDensities, s.settings.UIDensity, formatOption, s.setUIDensity))
+
+	add(preferenceEntry{i18n.T("Emulator"), i18n.T("Emulator speed"), i18n.T("Wall-clock speed multiplier. Ignored with deterministic timing."), "fast forward slow motion turbo"},
+		newPreferenceSelect(preferenceEmulatorSpeeds, s.settings.EmulatorSpeed, formatEmulatorSpeed, s.setEmulatorSpeed))
+	deterministicCheck := newPreferenceCheck(s.backend.Deterministic(), func(v bool) {
+		if v != s.backend.Deterministic() {
+			s.toggleDeterministic()
+			s.refreshMainMenu()
+		}
+	})
+	add(preferenceEntry{i18n.T("Emulator"), i18n.T("Deterministic timing"), i18n.T("Advance exactly one frame per update tick."), "fixed timestep replay"}, deterministicCheck)
+	captureCheck := newPreferenceCheck(s.captureGameInput, func(v bool) {
+		if s.captureCheck != nil {
+			s.captureCheck.SetChecked(v)
+		}
+	})
+	add(preferenceEntry{i18n.T("Emulator"), i18n.T("Capture game input"), i18n.T("Route the keyboard to the emulator while it has focus."), "keyboard controller"}, captureCheck)
+	turboEntry := widget.NewEntry()
+	turboEntry.SetPlaceHolder(i18n.T("e.g. a,b"))
+	turboEntry.SetText(s.settings.TurboButtons)
+	turboEntry.OnSubmitted = s.setTurboButtons
+	add(preferenceEntry{i18n.T("Emulator"), i18n.T("Turbo buttons"), i18n.T("Buttons that auto-fire while held (up, down, left, right, a, b, x, y, l, r, start, z). Press Enter to apply."), "autofire rapid fire"}, turboEntry)
+	add(preferenceEntry{i18n.T("Emulator"), i18n.T("Turbo rate"), i18n.T("How fast turbo buttons auto-fire."), "autofire rapid fire speed"},
+		newPreferenceSelect(preferenceTurboRates, s.settings.TurboHz, formatTurboRate, s.setTurboRate))
+
+	add(preferenceEntry{i18n.T("Keyboard"), i18n.T("Keyboard shortcuts"), i18n.T("Rebind menu and command palette shortcuts (Ctrl+Shift+P opens the palette)."), "keymap hotkeys bindings keys"},
+		widget.NewButton(i18n.T("Edit Shortcuts..."), s.showKeymapDialog))
+
+	add(preferenceEntry{i18n.T("Audio"), i18n.T("Audio latency"), i18n.T("Maximum audio queued ahead of playback. Lower is more responsive but may crackle."), "buffer sound delay"},
+		newPreferenceSelect(preferenceAudioLatencies, s.settings.AudioLatencyFrames, formatAudioLatency, s.setAudioLatency))
+
+	list := container.NewVBox()
+	noMatch := widget.NewLabel(i18n.T("No matching preferences"))
+	filter := func(query string) {
+		list.Objects = list.Objects[:0]
+		section := ""
+		for _, row := range rows {
+			if !row.entry.matches(query) {
+				continue
+			}
+			if row.entry.section != section {
+				section = row.entry.section
+				if len(list.Objects) > 0 {
+					list.Add(widget.NewSeparator())
+				}
+				list.Add(widget.NewLabelWithStyle(section, fyne.TextAlignLeading, fyne.TextStyle{Bold: true, Italic: true}))
+			}
+			list.Add(row.object)
+		}
+		if len(list.Objects) == 0 {
+			list.Add(noMatch)
+		}
+		list.Refresh()
+	}
+	filter("")
+
+	search := widget.NewEntry()
+	search.SetPlaceHolder(i18n.T("Search preferences..."))
+	search.OnChanged = filter
+	settingsRef := s.settingsPath
+	if settingsRef == "" {
+		settingsRef = i18n.T("(not saved: no user config directory)")
+	}
+	settingsFile := widget.NewLabel(i18n.T("Settings file: %s", settingsRef))
+	settingsFile.Wrapping = fyne.TextWrapBreak
+
+	content := container.NewBorder(search, settingsFile, nil, nil, container.NewVScroll(list))
+	d := dialog.NewCustom(i18n.T("Preferences"), i18n.T("Close"), content, s.window)
+	d.Resize(fyne.NewSize(640, 560))
+	d.Show()
+	s.window.Canvas().Focus(search)
+}
+
+func (s *devKitState) applyEditorPreferences() {
+	if s.sourceEditor == nil {
+		return
+	}
+	s.sourceEditor.SetTextSize(float32(s.settings.EditorFontSize))
+	s.sourceEditor.SetTabWidth(s.settings.EditorTabWidth, s.settings.EditorInsertSpaces)
+	s.sourceEditor.SetColorScheme(resolveEditorColorScheme(s.settings.EditorColorScheme, s.settings.EditorColors))
+}
+
+func (s *devKitState) setEditorFontSize(size int) {
+	s.settings.EditorFontSize = size
+	s.applyEditorPreferences()
+	s.persistSettings()
+}
+
+func (s *devKitState) setTheme(name string) {
+	s.settings.Theme = name
+	s.persistSettings()
+	fyne.CurrentApp().Settings().SetTheme(newDevKitTheme(s.settings.UIDensity, name))
+	s.setStatus(i18n.T("Theme: %s", formatOption(name)))
+}
+
+func (s *devKitState) setLanguage(tag string) {
+	s.settings.Language = tag
+	applyLanguage(tag)
+	s.persistSettings()
+	s.refreshMainMenu()
+	s.setStatus(i18n.T("Language: %s", formatLanguage(tag)))
+}
+
+// applyLanguage selects the UI locale for a Language setting, following the
+// environment when it is empty.
+func applyLanguage(tag string) {
+	if tag == "" {
+		tag = i18n.Detect()
+	}
+	if err := i18n.SetLocale(tag); err != nil {
+		i18n.SetLocale(i18n.English)
+	}
+}
+
+func (s *devKitState) setEmulatorSpeed(speed float64) {
+	s.backend.SetSpeed(speed)
+	s.settings.EmulatorSpeed = s.backend.Speed()
+	s.persistSettings()
+	s.setStatus(i18n.T("Emulator speed: %s", formatEmulatorSpeed(s.settings.EmulatorSpeed)))
+}
+
+func (s *devKitState) setTurboButtons(list string) {
+	mask, err := input.ParseButtons(list)
+	if err != nil {
+		s.setStatus(i18n.T("Turbo buttons: %s", err.Error()))
+		return
+	}
+	s.settings.TurboButtons = list
+	s.backend.SetTurbo(mask, s.settings.TurboHz)
+	s.persistSettings()
+	s.setStatus(i18n.T("Turbo buttons: %s", onOff(mask != 0, list, i18n.T("none"))))
+}
+
+func (s *devKitState) setTurboRate(hz int) {
+	s.settings.TurboHz = hz
+	mask, _ := input.ParseButtons(s.settings.TurboButtons)
+	s.backend.SetTurbo(mask, hz)
+	s.persistSettings()
+	s.setStatus(i18n.T("Turbo rate: %s", formatTurboRate(hz)))
+}
+
+func (s *devKitState) setAudioLatency(frames int) {
+	s.settings.AudioLatencyFrames = frames
+	s.audioQueueFrames.Store(int32(frames))
+	s.persistSettings()
+	s.setStatus(i18n.T("Audio latency: %s", formatAudioLatency(frames)))
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"testing"
+
+	fynetest "fyne.io/fyne/v2/test"
+	"nitro-core-dx/internal/i18n"
+)
+
+func TestPreferenceEntryMatches(t *testing.T) {
+	entry := preferenceEntry{"Audio", "Audio latency", "Maximum audio queued ahead of playback.", "buffer sound delay"}
+	for _, q := range []string{"", "latency", "AUDIO", "sound buffer", "  delay  "} {
+		if !entry.matches(q) {
+			t.Fatalf("expected %q to match", q)
+		}
+	}
+	for _, q := range []string{"font", "audio font"} {
+		if entry.matches(q) {
+			t.Fatalf("expected %q not to match", q)
+		}
+	}
+}
+
+func TestPreferenceFormatting(t *testing.T) {
+	cases := map[string]string{
+		formatEditorFontSize(0):   "Theme default",
+		formatEditorFontSize(14):  "14 pt",
+		formatAutosaveInterval(0): "Every edit",
+		formatAutosaveInterval(5): "Every 5 s",
+		formatAudioLatency(4):     "4 frames (~67 ms)",
+		formatEmulatorSpeed(0.25): "25%",
+		formatEmulatorSpeed(1.5):  "150%",
+		capitalize(themeLight):    "Light",
+		formatOption(themeDark):   "Dark",
+		formatTurboRate(15):       "15 presses/s",
+	}
+	for got, want := range cases {
+		if got != want {
+			t.Fatalf("got %q, want %q", got, want)
+		}
+	}
+}
+
+func TestPreferenceFormattingFollowsLanguage(t *testing.T) {
+	if err := i18n.SetLocale("es"); err != nil {
+		t.Fatal(err)
+	}
+	defer i18n.SetLocale(i18n.English)
+	cases := map[string]string{
+		formatAutosaveInterval(5): "Cada 5 s",
+		formatOption(themeDark):   "Oscuro",
+		formatLanguage("es"):      "Español",
+	}
+	for got, want := range cases {
+		if got != want {
+			t.Fatalf("got %q, want %q", got, want)
+		}
+	}
+}
+
+func TestNewPreferenceSelectAppendsUnlistedValue(t *testing.T) {
+	a := fynetest.NewApp()
+	defer a.Quit()
+
+	var applied []int
+	sel := newPreferenceSelect([]int{2, 4}, 3, func(n int) string { return formatAudioLatency(n) }, func(n int) {
+		applied = append(applied, n)
+	})
+	if len(sel.Options) != 3 || sel.Selected != formatAudioLatency(3) {
+		t.Fatalf("expected unlisted value appended and selected, got %v / %q", sel.Options, sel.Selected)
+	}
+	if len(applied) != 0 {
+		t.Fatalf("expected no apply for the initial selection, got %v", applied)
+	}
+	sel.SetSelectedIndex(1)
+	if len(applied) != 1 || applied[0] != 4 {
+		t.Fatalf("expected apply(4), got %v", applied)
+	}
+}
@@ -0,0 +1,341 @@
+package main
+
+import (
+	"embed"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// referenceFS holds the Help tab's reference manuals. They ship inside the
+// binary so the Help tab works without a checkout of docs/.
+//
+//go:embed reference/*.md
+var referenceFS embed.FS
+
+// referenceDocFiles lists the embedded manuals in display order.
+var referenceDocFiles = []string{
+	"reference/corelx_builtins.md",
+	"reference/instruction_set.md",
+	"reference/hardware_registers.md",
+}
+
+// referenceEntry is one "###" entry of a reference manual, or the text
+// between a "##" heading and its first entry. keys are the backticked words
+// and register names of the heading, lower-cased; a heading that starts with
+// an address ("`0x8014`") or range ("`0x8000`-`0x8003`") also spans lo..hi.
+type referenceEntry struct {
+	doc     string
+	section string
+	title   string
+	keys    []string
+	lo, hi  int
+	body    string
+}
+
+var (
+	referenceKeyPattern      = regexp.MustCompile("`([^`]+)`")
+	referenceRangePattern    = regexp.MustCompile("^`(0[xX][0-9a-fA-F]+)`(?:-`(0[xX][0-9a-fA-F]+)`)?")
+	referenceRegisterPattern = regexp.MustCompile(`^[A-Z][A-Z0-9]*_[A-Z0-9_]+$`)
+)
+
+// parseReferenceDoc splits a manual into entries. Headings inside fenced
+// code blocks are ignored, and a section intro only becomes an entry when
+// it has text of its own.
+func parseReferenceDoc(text string) []referenceEntry {
+	var (
+		entries   []referenceEntry
+		doc       string
+		section   string
+		cur       *referenceEntry
+		body      strings.Builder
+		headerLen int
+		fenced    bool
+	)
+	flush := func() {
+		if cur != nil && len(strings.TrimSpace(body.String())) > headerLen {
+			cur.body = strings.TrimSpace(body.String())
+			entries = append(entries, *cur)
+		}
+		cur = nil
+		body.Reset()
+	}
+	start := func(e *referenceEntry, header string) {
+		cur = e
+		body.WriteString(header + "\n\n")
+		headerLen = len(header)
+	}
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.HasPrefix(line, "```") {
+			fenced = !fenced
+		} else if !fenced {
+			switch {
+			case strings.HasPrefix(line, "# "):
+				flush()
+				doc = strings.TrimSpace(line[2:])
+				continue
+			case strings.HasPrefix(line, "## "):
+				flush()
+				section = strings.TrimSpace(line[3:])
+				start(&referenceEntry{doc: doc, section: section, title: section, lo: -1, hi: -1}, "## "+section)
+				continue
+			case strings.HasPrefix(line, "### "):
+				flush()
+				e := newReferenceEntry(doc, section, strings.TrimSpace(line[4:]))
+				start(e, "### "+e.title)
+				continue
+			}
+		}
+		if cur != nil {
+			body.WriteString(line + "\n")
+		}
+	}
+	flush()
+	return entries
+}
+
+func newReferenceEntry(doc, section, heading string) *referenceEntry {
+	e := &referenceEntry{
+		doc:     doc,
+		section: section,
+		title:   strings.ReplaceAll(heading, "`", ""),
+		lo:      -1,
+		hi:      -1,
+	}
+	for _, m := range referenceKeyPattern.FindAllStringSubmatch(heading, -1) {
+		e.keys = append(e.keys, strings.ToLower(m[1]))
+	}
+	for _, word := range strings.Fields(referenceKeyPattern.ReplaceAllString(heading, " ")) {
+		if referenceRegisterPattern.MatchString(word) {
+			e.keys = append(e.keys, strings.ToLower(word))
+		}
+	}
+	if m := referenceRangePattern.FindStringSubmatch(heading); m != nil {
+		lo, _ := parseReferenceAddress(m[1])
+		e.lo, e.hi = lo, lo
+		if hi, ok := parseReferenceAddress(m[2]); ok && hi >= lo {
+			e.hi = hi
+		}
+	}
+	return e
+}
+
+func parseReferenceAddress(text string) (int, bool) {
+	text = strings.ToLower(strings.TrimSpace(text))
+	if !strings.HasPrefix(text, "0x") {
+		return 0, false
+	}
+	v, err := strconv.ParseUint(text[2:], 16, 32)
+	if err != nil {
+		return 0, false
+	}
+	return int(v), true
+}
+
+// loadReferenceEntries parses every embedded manual.
+func loadReferenceEntries() []referenceEntry {
+	var entries []referenceEntry
+	for _, name := range referenceDocFiles {
+		data, err := referenceFS.ReadFile(name)
+		if err != nil {
+			// The file list and the embed pattern live in this file; a miss
+			// is a build mistake the reference tests catch.
+			continue
+		}
+		entries = append(entries, parseReferenceDoc(string(data))...)
+	}
+	return entries
+}
+
+// lookupReference finds the entry for word: an exact heading key (builtin
+// or mnemonic names, case-insensitive), else the narrowest address range
+// containing a hex address. Call syntax around the word is ignored.
+func lookupReference(entries []referenceEntry, word string) (int, bool) {
+	word = strings.ToLower(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(word), "()")))
+	if word == "" {
+		return -1, false
+	}
+	for i, e := range entries {
+		for _, key := range e.keys {
+			if key == word {
+				return i, true
+			}
+		}
+	}
+	if addr, ok := parseReferenceAddress(word); ok {
+		best, bestSpan := -1, 0
+		for i, e := range entries {
+			if e.lo < 0 || addr < e.lo || addr > e.hi {
+				continue
+			}
+			if span := e.hi - e.lo; best < 0 || span < bestSpan {
+				best, bestSpan = i, span
+			}
+		}
+		if best >= 0 {
+			return best, true
+		}
+	}
+	return -1, false
+}
+
+// searchReference returns the indexes of entries in doc ("" for all) whose
+// text contains every word of query. Title matches sort first.
+func searchReference(entries []referenceEntry, doc, query string) []int {
+	words := strings.Fields(strings.ToLower(query))
+	var titleHits, bodyHits []int
+	for i, e := range entries {
+		if doc != "" && e.doc != doc {
+			continue
+		}
+		title := strings.ToLower(e.title)
+		hay := title + " " + strings.ToLower(e.section) + " " + strings.ToLower(e.body)
+		inTitle, all := len(words) > 0, true
+		for _, w := range words {
+			if !strings.Contains(hay, w) {
+				all = false
+				break
+			}
+			if !strings.Contains(title, w) {
+				inTitle = false
+			}
+		}
+		if !all {
+			continue
+		}
+		if inTitle {
+			titleHits = append(titleHits, i)
+		} else {
+			bodyHits = append(bodyHits, i)
+		}
+	}
+	return append(titleHits, bodyHits...)
+}
+
+func referenceDocTitles(entries []referenceEntry) []string {
+	var titles []string
+	seen := map[string]bool{}
+	for _, e := range entries {
+		if !seen[e.doc] {
+			seen[e.doc] = true
+			titles = append(titles, e.doc)
+		}
+	}
+	return titles
+}
+
+const referenceAllDocs = "All references"
+
+// buildHelpPane builds the Help tab: a searchable index of the embedded
+// reference manuals beside the selected entry.
+func (s *devKitState) buildHelpPane() fyne.CanvasObject {
+	entries := loadReferenceEntries()
+	s.referenceEntries = entries
+	var shown []int
+
+	viewer := widget.NewRichTextFromMarkdown("")
+	viewer.Wrapping = fyne.TextWrapWord
+	viewerScroll := container.NewVScroll(viewer)
+	showEntry := func(i int) {
+		e := entries[i]
+		viewer.ParseMarkdown(e.body + "\n\n---\n\n*" + e.doc + " → " + e.section + "*")
+		viewerScroll.ScrollToTop()
+	}
+
+	list := widget.NewList(
+		func() int { return len(shown) },
+		func() fyne.CanvasObject {
+			section := widget.NewLabel("")
+			section.Importance = widget.LowImportance
+			return container.NewBorder(nil, nil, nil, section, widget.NewLabel(""))
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			e := entries[shown[id]]
+			row := obj.(*fyne.Container)
+			row.Objects[0].(*widget.Label).SetText(e.title)
+			row.Objects[1].(*widget.Label).SetText(e.section)
+		},
+	)
+	list.OnSelected = func(id widget.ListItemID) {
+		if id >= 0 && id < len(shown) {
+			showEntry(shown[id])
+		}
+	}
+
+	docSelect := widget.NewSelect(append([]string{referenceAllDocs}, referenceDocTitles(entries)...), nil)
+	search := widget.NewEntry()
+	search.SetPlaceHolder("Search reference (F1 in the editor looks up the word under the cursor)")
+	filter := func() {
+		doc := docSelect.Selected
+		if doc == referenceAllDocs {
+			doc = ""
+		}
+		shown = searchReference(entries, doc, search.Text)
+		list.UnselectAll()
+		list.Refresh()
+		if len(shown) > 0 {
+			list.Select(0)
+		} else {
+			viewer.ParseMarkdown("No reference entries match **" + search.Text + "**.")
+		}
+	}
+	search.OnChanged = func(string) { filter() }
+	docSelect.OnChanged = func(string) { filter() }
+	docSelect.SetSelected(referenceAllDocs)
+
+	s.referenceReveal = func(i int) {
+		docSelect.SetSelected(referenceAllDocs)
+		search.SetText("")
+		filter()
+		for row, idx := range shown {
+			if idx == i {
+				list.Select(row)
+				list.ScrollTo(row)
+				return
+			}
+		}
+	}
+	s.referenceSearch = func(query string) {
+		docSelect.SetSelected(referenceAllDocs)
+		search.SetText(query)
+		filter()
+	}
+
+	split := container.NewHSplit(list, viewerScroll)
+	split.Offset = 0.34
+	return container.NewBorder(container.NewBorder(nil, nil, nil, docSelect, search), nil, nil, nil, split)
+}
+
+// showContextHelp opens the Help tab at word's entry. Unknown words become
+// the search query, and an empty word just shows the tab.
+func (s *devKitState) showContextHelp(word string) {
+	if s.referenceReveal == nil || !s.showBottomTab("Help") {
+		return
+	}
+	word = strings.TrimSpace(word)
+	if word == "" {
+		return
+	}
+	if i, ok := lookupReference(s.referenceEntries, word); ok {
+		s.referenceReveal(i)
+		s.setStatus("Reference: " + s.referenceEntries[i].title)
+		return
+	}
+	s.referenceSearch(word)
+	s.setStatus("No reference entry for " + word + "; showing search results")
+}
+
+// showReferenceAtCursor looks up the word under the editor cursor, the
+// menu and shortcut equivalent of F1 in the editor.
+func (s *devKitState) showReferenceAtCursor() {
+	word := ""
+	if s.sourceEditor != nil {
+		word = s.sourceEditor.WordAtCursor()
+	}
+	s.showContextHelp(word)
+}
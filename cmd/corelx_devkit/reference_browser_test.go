@@ -0,0 +1,133 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"nitro-core-dx/internal/corelx"
+)
+
+func TestReferenceDocFilesMatchEmbed(t *testing.T) {
+	dir, err := referenceFS.ReadDir("reference")
+	if err != nil {
+		t.Fatalf("read embedded reference dir: %v", err)
+	}
+	var embedded []string
+	for _, d := range dir {
+		embedded = append(embedded, "reference/"+d.Name())
+	}
+	listed := append([]string(nil), referenceDocFiles...)
+	sort.Strings(embedded)
+	sort.Strings(listed)
+	if strings.Join(embedded, ",") != strings.Join(listed, ",") {
+		t.Fatalf("referenceDocFiles %v does not match embedded files %v", listed, embedded)
+	}
+	if got := referenceDocTitles(loadReferenceEntries()); len(got) != len(referenceDocFiles) {
+		t.Fatalf("expected %d manuals, got %v", len(referenceDocFiles), got)
+	}
+}
+
+func TestReferenceCoversEveryBuiltin(t *testing.T) {
+	entries := loadReferenceEntries()
+	for _, name := range corelx.BuiltinFunctions() {
+		i, ok := lookupReference(entries, name)
+		if !ok {
+			t.Errorf("builtin %s has no reference entry", name)
+			continue
+		}
+		if entries[i].doc != "CoreLX Built-ins" {
+			t.Errorf("builtin %s resolved to %q in %q", name, entries[i].title, entries[i].doc)
+		}
+	}
+}
+
+func TestLookupReference(t *testing.T) {
+	entries := loadReferenceEntries()
+	cases := map[string]string{
+		"oam.write":   "oam.write",
+		"OAM.WRITE()": "oam.write",
+		"0x8001":      "0x8000-0x8003 BG0 scroll",
+		"0x8014":      "0x8014 OAM_ADDR",
+		"OAM_ADDR":    "0x8014 OAM_ADDR",
+		"0x803E":      "0x803E VBLANK_FLAG",
+		"0x8040":      "0x803F-0x8040 FRAME_COUNTER",
+		"0x8039":      "0x8038-0x8044 BG2 matrix",
+		"0x1234":      "0x0000-0x7FFF WRAM",
+		"mov.b":       "MOV.B",
+		"CMP":         "CMP",
+		".extern":     ".extern",
+	}
+	for word, want := range cases {
+		i, ok := lookupReference(entries, word)
+		if !ok {
+			t.Errorf("lookup %q: no entry", word)
+			continue
+		}
+		if entries[i].title != want {
+			t.Errorf("lookup %q = %q, want %q", word, entries[i].title, want)
+		}
+	}
+	for _, word := range []string{"", "banana", "0xZZ", "0xFFFF"} {
+		if i, ok := lookupReference(entries, word); ok {
+			t.Errorf("lookup %q unexpectedly found %q", word, entries[i].title)
+		}
+	}
+}
+
+func TestParseReferenceDoc(t *testing.T) {
+	entries := parseReferenceDoc("# Doc\n\n## Empty\n\n## Intro\n\nSection text.\n\n### `a.b` thing\n\n```corelx\n### not a heading\n```\n\n### `0x10`-`0x1F` Block\n\nBody.\n")
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %+v", entries)
+	}
+	if entries[0].title != "Intro" || entries[0].section != "Intro" {
+		t.Fatalf("section intro entry = %+v", entries[0])
+	}
+	if e := entries[1]; e.title != "a.b thing" || len(e.keys) != 1 || e.keys[0] != "a.b" || !strings.Contains(e.body, "### not a heading") {
+		t.Fatalf("fenced heading should stay in the body: %+v", e)
+	}
+	if e := entries[2]; e.lo != 0x10 || e.hi != 0x1F {
+		t.Fatalf("range = %#x-%#x", e.lo, e.hi)
+	}
+}
+
+func TestSearchReferenceRanksTitleMatches(t *testing.T) {
+	entries := loadReferenceEntries()
+	hits := searchReference(entries, "", "oam")
+	if len(hits) == 0 || !strings.Contains(strings.ToLower(entries[hits[0]].title), "oam") {
+		t.Fatalf("expected a title match first, got %v", hits)
+	}
+	all := searchReference(entries, "", "")
+	if len(all) != len(entries) {
+		t.Fatalf("empty query should list all %d entries, got %d", len(entries), len(all))
+	}
+	for _, i := range searchReference(entries, "Instruction Set", "") {
+		if entries[i].doc != "Instruction Set" {
+			t.Fatalf("doc filter leaked %q from %q", entries[i].title, entries[i].doc)
+		}
+	}
+	if hits := searchReference(entries, "", "sprite zzzz"); len(hits) != 0 {
+		t.Fatalf("every query word must match, got %v", hits)
+	}
+}
+
+func TestContextWordAt(t *testing.T) {
+	line := "    oam.write(0, &hero) // 0x8014"
+	cases := map[int]string{
+		4:  "oam.write",
+		8:  "oam.write",
+		13: "oam.write", // just past the word, on "("
+		19: "hero",
+		1:  "",
+		29: "0x8014",
+		33: "0x8014", // end of line
+	}
+	for col, want := range cases {
+		if got := contextWordAt(line, col); got != want {
+			t.Errorf("contextWordAt(col %d) = %q, want %q", col, got, want)
+		}
+	}
+	if got := contextWordAt("x = .5.", 5); got != "5" {
+		t.Fatalf("dots should be trimmed, got %q", got)
+	}
+}
@@ -0,0 +1,358 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"nitro-core-dx/internal/corelx"
+	"nitro-core-dx/internal/debug"
+	"nitro-core-dx/internal/devkit"
+)
+
+// runConfigLogLimit caps how many emulator log lines one UI refresh copies
+// into the Output pane.
+const runConfigLogLimit = 50
+
+// runConfigSpeeds are the speed choices offered by the run configuration
+// dialog; "Preferences" (0) keeps the Preferences speed.
+var runConfigSpeeds = []string{"Preferences", "0.25x", "0.5x", "1x", "2x", "4x"}
+
+func formatRunConfigSpeed(speed float64) string {
+	if speed == 0 {
+		return runConfigSpeeds[0]
+	}
+	return strconv.FormatFloat(speed, 'g', -1, 64) + "x"
+}
+
+func parseRunConfigSpeed(text string) float64 {
+	v, err := strconv.ParseFloat(strings.TrimSuffix(text, "x"), 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+// parseRunConfigDefines splits the dialog's flag list, separated by commas
+// or spaces.
+func parseRunConfigDefines(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' })
+}
+
+// runConfigNames lists r's config names in file order.
+func runConfigNames(r devkit.RunConfigs) []string {
+	names := make([]string, len(r.Configs))
+	for i, c := range r.Configs {
+		names[i] = c.Name
+	}
+	return names
+}
+
+// uniqueRunConfigName returns base, or base followed by the first free
+// number, so a new config never collides with an existing one.
+func uniqueRunConfigName(r devkit.RunConfigs, base string) string {
+	name := base
+	for n := 2; ; n++ {
+		if _, taken := r.Lookup(name); !taken {
+			return name
+		}
+		name = fmt.Sprintf("%s %d", base, n)
+	}
+}
+
+// runConfigDirFor is the directory whose run configuration file applies to
+// sourcePath; "" for an unsaved buffer, which uses the defaults.
+func runConfigDirFor(sourcePath string) string {
+	if sourcePath == "" {
+		return ""
+	}
+	return filepath.Dir(sourcePath)
+}
+
+// syncRunConfigs reloads the run configurations when the project directory
+// has changed, and refreshes the toolbar selector.
+func (s *devKitState) syncRunConfigs() {
+	dir := runConfigDirFor(s.currentPath)
+	if s.runConfigsLoaded && dir == s.runConfigDir {
+		return
+	}
+	s.runConfigsLoaded = true
+	s.runConfigDir = dir
+	s.runConfigs = devkit.DefaultRunConfigs()
+	if dir != "" {
+		loaded, err := devkit.LoadRunConfigs(dir)
+		if err != nil {
+			s.appendBuildOutput("Run configurations: " + err.Error() + " (using defaults)")
+		} else {
+			s.runConfigs = loaded
+		}
+	}
+	s.refreshRunConfigSelect()
+}
+
+func (s *devKitState) refreshRunConfigSelect() {
+	if s.runConfigSelect == nil {
+		return
+	}
+	s.runConfigSelect.Options = runConfigNames(s.runConfigs)
+	s.runConfigSelect.SetSelected(s.runConfigs.Selected().Name)
+}
+
+// selectRunConfig makes name the active configuration and remembers it in
+// the project file.
+func (s *devKitState) selectRunConfig(name string) {
+	if _, ok := s.runConfigs.Lookup(name); !ok || s.runConfigs.Active == name {
+		return
+	}
+	s.runConfigs.Active = name
+	s.saveRunConfigs()
+	s.setStatus("Run configuration: " + name)
+}
+
+// saveRunConfigs writes the project's run configuration file. An unsaved
+// buffer keeps its configurations for the session only.
+func (s *devKitState) saveRunConfigs() {
+	if s.runConfigDir == "" {
+		return
+	}
+	if err := devkit.SaveRunConfigs(s.runConfigDir, s.runConfigs); err != nil {
+		s.appendBuildOutput("Save run configurations failed: " + err.Error())
+	}
+}
+
+// startWithRunConfig applies cfg to the ROM Build + Run just loaded.
+func (s *devKitState) startWithRunConfig(cfg devkit.RunConfig) {
+	if cfg.Speed == 0 {
+		s.backend.SetSpeed(s.settings.EmulatorSpeed)
+	}
+	hook, err := s.backend.ApplyRunConfig(cfg)
+	if hook != nil {
+		for _, d := range hook.Diagnostics {
+			s.appendBuildOutput(fmt.Sprintf("On-load hook line %d: %s", d.Line, d.Message))
+		}
+	}
+	if err != nil {
+		s.appendBuildOutput("Run configuration " + cfg.Name + ": " + err.Error())
+		return
+	}
+	if hook != nil {
+		s.appendBuildOutput(fmt.Sprintf("On-load hook ran (%d instructions)", hook.Instructions))
+	}
+}
+
+// drainEmulatorLogs copies new emulator log entries (from a run
+// configuration's log components) into the Output pane.
+func (s *devKitState) drainEmulatorLogs() {
+	lines, dropped := s.backend.DrainLogs(runConfigLogLimit)
+	if dropped > 0 {
+		s.appendBuildOutput(fmt.Sprintf("(%d earlier log entries not shown)", dropped))
+	}
+	for _, line := range lines {
+		s.appendBuildOutput(line)
+	}
+}
+
+// logLevelNames are the level choices of the logging dialog, least verbose
+// first.
+var logLevelNames = []string{"None", "Error", "Warning", "Info", "Debug", "Trace"}
+
+// showLoggingDialog changes which components log, and how verbosely, on the
+// running ROM. Changes apply immediately; entries appear in the Output pane.
+func (s *devKitState) showLoggingDialog() {
+	settings := s.backend.LogSettings()
+	if settings == nil {
+		s.setStatus("Logging: no ROM loaded")
+		return
+	}
+
+	form := widget.NewForm()
+	for _, setting := range settings {
+		setting := setting
+		enabled := widget.NewCheck("Enabled", nil)
+		enabled.SetChecked(setting.Enabled)
+		level := widget.NewSelect(logLevelNames, nil)
+		level.SetSelected(setting.Level.String())
+		apply := func() {
+			setting.Enabled = enabled.Checked
+			if l, err := debug.ParseLogLevel(level.Selected); err == nil {
+				setting.Level = l
+			}
+			if err := s.backend.SetLogSetting(setting); err != nil {
+				s.setStatus("Logging: " + err.Error())
+				return
+			}
+			s.setStatus(fmt.Sprintf("Logging: %s %s", setting.Component, strings.ToLower(level.Selected)))
+		}
+		enabled.OnChanged = func(bool) { apply() }
+		level.OnChanged = func(string) { apply() }
+		form.Append(string(setting.Component), container.NewHBox(enabled, level))
+	}
+	help := widget.NewLabel("Each component keeps entries at its level and anything more severe. Levels survive a rebuild; the run configuration decides which components start enabled.")
+	help.Wrapping = fyne.TextWrapWord
+	help.Importance = widget.LowImportance
+
+	d := dialog.NewCustom("Logging", "Close", container.NewBorder(nil, help, nil, nil, form), s.window)
+	d.Resize(fyne.NewSize(420, 420))
+	d.Show()
+}
+
+// showRunConfigDialog edits the project's run configurations. Changes are
+// saved to the project file when the dialog closes.
+func (s *devKitState) showRunConfigDialog() {
+	s.syncRunConfigs()
+	edit := s.runConfigs
+	edit.Configs = append([]devkit.RunConfig(nil), s.runConfigs.Configs...)
+	current := 0
+	for i, c := range edit.Configs {
+		if c.Name == edit.Active {
+			current = i
+		}
+	}
+
+	list := widget.NewSelect(nil, nil)
+	name := widget.NewEntry()
+	profile := widget.NewRadioGroup([]string{devkit.ProfileDebug, devkit.ProfileRelease}, nil)
+	profile.Horizontal = true
+	targetNames := make([]string, len(corelx.Targets))
+	for i, t := range corelx.Targets {
+		targetNames[i] = string(t)
+	}
+	target := widget.NewRadioGroup(targetNames, nil)
+	target.Horizontal = true
+	speed := widget.NewSelect(runConfigSpeeds, nil)
+	paused := widget.NewCheck("Start paused", nil)
+	hwCheck := widget.NewCheck("Check for hardware compatibility after building", nil)
+	logChecks := make([]*widget.Check, len(devkit.LogComponentNames))
+	logRow := container.NewHBox()
+	for i, comp := range devkit.LogComponentNames {
+		logChecks[i] = widget.NewCheck(comp, nil)
+		logRow.Add(logChecks[i])
+	}
+	hook := widget.NewMultiLineEntry()
+	hook.SetPlaceHolder("Immediate-console CoreLX, e.g. mem.write(0x2100, 3)")
+	hook.SetMinRowsVisible(3)
+	hookFrame := widget.NewEntry()
+	hookFrame.SetPlaceHolder("0")
+	defines := widget.NewEntry()
+	defines.SetPlaceHolder("DEBUG, CHEATS")
+	errLabel := widget.NewLabel("")
+	errLabel.Importance = widget.DangerImportance
+
+	load := func(i int) {
+		c := edit.Configs[i]
+		current = i
+		name.SetText(c.Name)
+		profile.SetSelected(c.Profile)
+		if t, err := corelx.ParseTarget(c.Target); err == nil {
+			target.SetSelected(string(t))
+		}
+		speed.SetSelected(formatRunConfigSpeed(c.Speed))
+		paused.SetChecked(c.StartPaused)
+		hwCheck.SetChecked(c.ValidateHardware)
+		for j, comp := range devkit.LogComponentNames {
+			on := false
+			for _, have := range c.LogComponents {
+				on = on || strings.EqualFold(have, comp)
+			}
+			logChecks[j].SetChecked(on)
+		}
+		hook.SetText(c.OnLoad)
+		hookFrame.SetText(strconv.Itoa(c.OnLoadFrame))
+		defines.SetText(strings.Join(c.Defines, ", "))
+	}
+	// store copies the form into the current config.
+	store := func() {
+		c := &edit.Configs[current]
+		oldName := c.Name
+		c.Name = strings.TrimSpace(name.Text)
+		c.Profile = profile.Selected
+		c.Target = ""
+		if target.Selected != string(corelx.TargetDev) {
+			c.Target = target.Selected
+		}
+		c.Speed = parseRunConfigSpeed(speed.Selected)
+		c.StartPaused = paused.Checked
+		c.ValidateHardware = hwCheck.Checked
+		c.LogComponents = nil
+		for j, comp := range devkit.LogComponentNames {
+			if logChecks[j].Checked {
+				c.LogComponents = append(c.LogComponents, comp)
+			}
+		}
+		c.OnLoad = strings.TrimSpace(hook.Text)
+		c.OnLoadFrame, _ = strconv.Atoi(strings.TrimSpace(hookFrame.Text))
+		c.Defines = parseRunConfigDefines(defines.Text)
+		if edit.Active == oldName {
+			edit.Active = c.Name
+		}
+	}
+	refreshList := func() {
+		list.OnChanged = nil
+		list.Options = runConfigNames(edit)
+		list.SetSelectedIndex(current)
+		list.OnChanged = func(string) {
+			store()
+			load(list.SelectedIndex())
+		}
+	}
+	load(current)
+	refreshList()
+
+	addBtn := widget.NewButton("Add", func() {
+		store()
+		c := edit.Configs[current]
+		c.Name = uniqueRunConfigName(edit, c.Name+" copy")
+		edit.Configs = append(edit.Configs, c)
+		load(len(edit.Configs) - 1)
+		refreshList()
+	})
+	removeBtn := widget.NewButton("Remove", func() {
+		if len(edit.Configs) == 1 {
+			errLabel.SetText("A project needs at least one run configuration")
+			return
+		}
+		edit.Configs = append(edit.Configs[:current], edit.Configs[current+1:]...)
+		load(min(current, len(edit.Configs)-1))
+		refreshList()
+	})
+
+	form := widget.NewForm(
+		widget.NewFormItem("Name", name),
+		widget.NewFormItem("Build profile", profile),
+		widget.NewFormItem("Target", target),
+		widget.NewFormItem("", hwCheck),
+		widget.NewFormItem("Flags (#if)", defines),
+		widget.NewFormItem("Speed", speed),
+		widget.NewFormItem("", paused),
+		widget.NewFormItem("Log components", logRow),
+		widget.NewFormItem("On-load hook", hook),
+		widget.NewFormItem("Hook after frames", hookFrame),
+	)
+	help := widget.NewLabel("Debug builds skip the boot splash. The hardware target waits for VBlank before each VRAM, CGRAM or OAM upload, as strict hardware requires. The hardware check runs each build for five seconds under strict rules and warns about uploads during active display and reads of write-only registers. Flags turn on `#if NAME` sections of the source. Logs appear in the Output tab. Saved to " + devkit.RunConfigFileName + " next to the project source.")
+	help.Wrapping = fyne.TextWrapWord
+	help.Importance = widget.LowImportance
+	top := container.NewBorder(nil, nil, widget.NewLabel("Configuration"), container.NewHBox(addBtn, removeBtn), list)
+	content := container.NewBorder(top, container.NewVBox(errLabel, help), nil, nil, form)
+
+	d := dialog.NewCustomConfirm("Run Configurations", "Save", "Cancel", content, func(ok bool) {
+		if !ok {
+			return
+		}
+		store()
+		if err := edit.Validate(); err != nil {
+			dialog.ShowError(err, s.window)
+			return
+		}
+		s.runConfigs = edit
+		s.saveRunConfigs()
+		s.refreshRunConfigSelect()
+		s.setStatus("Run configurations saved")
+	}, s.window)
+	d.Resize(fyne.NewSize(640, 520))
+	d.Show()
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"nitro-core-dx/internal/devkit"
+)
+
+func TestRunConfigSpeedRoundTrip(t *testing.T) {
+	for _, text := range runConfigSpeeds {
+		if got := formatRunConfigSpeed(parseRunConfigSpeed(text)); got != text {
+			t.Errorf("speed %q round-trips to %q", text, got)
+		}
+	}
+}
+
+func TestParseRunConfigDefines(t *testing.T) {
+	got := parseRunConfigDefines(" DEBUG, CHEATS  LEVEL_SELECT,")
+	if strings.Join(got, "|") != "DEBUG|CHEATS|LEVEL_SELECT" {
+		t.Fatalf("parseRunConfigDefines = %q", got)
+	}
+	if got := parseRunConfigDefines("  "); len(got) != 0 {
+		t.Fatalf("empty list = %q", got)
+	}
+}
+
+func TestUniqueRunConfigName(t *testing.T) {
+	r := devkit.DefaultRunConfigs()
+	if got := uniqueRunConfigName(r, "Trace"); got != "Trace" {
+		t.Fatalf("free name = %q", got)
+	}
+	r.Configs = append(r.Configs, devkit.RunConfig{Name: "Debug 2", Profile: devkit.ProfileDebug})
+	if got := uniqueRunConfigName(r, "Debug"); got != "Debug 3" {
+		t.Fatalf("taken name = %q, want Debug 3", got)
+	}
+	if runConfigDirFor("") != "" || runConfigDirFor("/p/game/main.corelx") != "/p/game" {
+		t.Fatalf("runConfigDirFor")
+	}
+}
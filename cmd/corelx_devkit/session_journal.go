@@ -0,0 +1,311 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+)
+
+// sessionJournalInterval is how often the session journal is rewritten while
+// the Dev Kit runs.
+const sessionJournalInterval = 5 * time.Second
+
+// sessionJournal records enough of a running Dev Kit session to put it back
+// after a crash: the open file, layout, which build was running, and where
+// the emulator was. Unsaved editor text stays in the autosave journal. The
+// file only exists while the Dev Kit is running; a clean close removes it, so
+// finding one on launch means the previous session died.
+type sessionJournal struct {
+	SavedAt    string `json:"saved_at"`
+	SourcePath string `json:"source_path,omitempty"`
+
+	ViewMode             string  `json:"view_mode"`
+	MainSplitOffset      float64 `json:"main_split_offset"`
+	LeftSplitOffset      float64 `json:"left_split_offset"`
+	DiagnosticsCollapsed bool    `json:"diagnostics_collapsed"`
+	WorkbenchTab         string  `json:"workbench_tab,omitempty"`
+	BottomTab            string  `json:"bottom_tab,omitempty"`
+
+	// ROMLoaded reports whether a build was running. ROMPath is the .rom or
+	// .ncdx file it came from; empty means it was built from the editor.
+	ROMLoaded  bool   `json:"rom_loaded"`
+	ROMPath    string `json:"rom_path,omitempty"`
+	Paused     bool   `json:"paused"`
+	FrameCount uint64 `json:"frame_count"`
+	// HasState is set when an emulator savestate was written next to the
+	// journal (see sessionStatePath).
+	HasState bool `json:"has_state"`
+}
+
+func devKitSessionPath(settingsPath string) string {
+	if settingsPath == "" {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(settingsPath), "devkit_session.json")
+}
+
+// sessionStatePath is the emulator savestate stored alongside a journal.
+func sessionStatePath(journalPath string) string {
+	return strings.TrimSuffix(journalPath, filepath.Ext(journalPath)) + ".state"
+}
+
+// writeSessionJournalFile writes the journal and, when state is non-empty,
+// the emulator savestate. A stale savestate is removed when state is empty.
+func writeSessionJournalFile(path string, journal sessionJournal, state []byte) error {
+	journal.HasState = len(state) > 0
+	data, err := json.MarshalIndent(journal, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	statePath := sessionStatePath(path)
+	if journal.HasState {
+		if err := os.WriteFile(statePath, state, 0644); err != nil {
+			return err
+		}
+	} else {
+		_ = os.Remove(statePath)
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// readSessionJournalFile loads a journal and its savestate. A missing journal
+// returns an error satisfying errors.Is(err, os.ErrNotExist); a missing
+// savestate just clears HasState.
+func readSessionJournalFile(path string) (sessionJournal, []byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return sessionJournal{}, nil, err
+	}
+	var journal sessionJournal
+	if err := json.Unmarshal(data, &journal); err != nil {
+		return sessionJournal{}, nil, fmt.Errorf("invalid session journal: %w", err)
+	}
+	var state []byte
+	if journal.HasState {
+		state, err = os.ReadFile(sessionStatePath(path))
+		if err != nil || len(state) == 0 {
+			journal.HasState = false
+			state = nil
+		}
+	}
+	return journal, state, nil
+}
+
+func removeSessionJournalFile(path string) {
+	if path == "" {
+		return
+	}
+	_ = os.Remove(path)
+	_ = os.Remove(sessionStatePath(path))
+}
+
+// captureSessionJournal snapshots the current session. Must run on the Fyne
+// UI goroutine.
+func (s *devKitState) captureSessionJournal() (sessionJournal, []byte) {
+	s.captureLayoutState()
+	journal := sessionJournal{
+		SavedAt:              time.Now().UTC().Format(time.RFC3339),
+		SourcePath:           s.currentPath,
+		ViewMode:             string(s.currentView),
+		MainSplitOffset:      s.settings.MainSplitOffset,
+		LeftSplitOffset:      s.settings.LeftSplitOffset,
+		DiagnosticsCollapsed: s.diagnosticsCollapsed,
+		WorkbenchTab:         selectedTabText(s.workbenchTabs),
+		BottomTab:            selectedTabText(s.bottomLeftTabs),
+	}
+	snap := s.backend.Snapshot()
+	if !snap.Loaded {
+		return journal, nil
+	}
+	journal.ROMLoaded = true
+	journal.ROMPath = s.sessionROMPath
+	journal.Paused = snap.Paused
+	journal.FrameCount = snap.FrameCount
+	state, err := s.backend.SaveState()
+	if err != nil {
+		return journal, nil
+	}
+	return journal, state
+}
+
+func (s *devKitState) writeSessionJournal() {
+	if s.sessionPath == "" {
+		return
+	}
+	journal, state := s.captureSessionJournal()
+	_ = writeSessionJournalFile(s.sessionPath, journal, state)
+}
+
+func (s *devKitState) clearSessionJournal() {
+	removeSessionJournalFile(s.sessionPath)
+}
+
+// startSessionJournal rewrites the session journal periodically until the
+// emulator loop stops. It writes one journal immediately so a crash right
+// after launch is still recoverable.
+func (s *devKitState) startSessionJournal() {
+	if s.sessionPath == "" {
+		return
+	}
+	s.writeSessionJournal()
+	go func() {
+		ticker := time.NewTicker(sessionJournalInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.updateLoopStop:
+				return
+			case <-ticker.C:
+			}
+			fyne.Do(s.writeSessionJournal)
+		}
+	}()
+}
+
+// recoverSessionOrAutosave offers to restore a session journal left behind by
+// a crash, falling back to the plain autosave prompt when there is none. The
+// journal is only rewritten once the user has decided, so declining or
+// crashing again before then does not lose it silently.
+func (s *devKitState) recoverSessionOrAutosave() {
+	journal, state, err := readSessionJournalFile(s.sessionPath)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			s.appendBuildOutput("Session recover warning: " + err.Error())
+			s.clearSessionJournal()
+		}
+		s.tryRecoverAutosave()
+		s.startSessionJournal()
+		return
+	}
+
+	dialog.NewConfirm("Recover Session", sessionRecoveryMessage(journal), func(restore bool) {
+		if restore {
+			s.restoreSession(journal, state)
+		} else {
+			s.clearSessionJournal()
+			s.tryRecoverAutosave()
+		}
+		s.startSessionJournal()
+	}, s.window).Show()
+}
+
+func sessionRecoveryMessage(journal sessionJournal) string {
+	savedAt := journal.SavedAt
+	if savedAt == "" {
+		savedAt = "unknown time"
+	}
+	source := journal.SourcePath
+	if source == "" {
+		source = "unsaved buffer"
+	}
+	emu := "no build running"
+	if journal.ROMLoaded {
+		from := "built from source"
+		if journal.ROMPath != "" {
+			from = filepath.Base(journal.ROMPath)
+		}
+		emu = fmt.Sprintf("%s, frame %d, %s", from, journal.FrameCount, onOff(journal.Paused, "paused", "running"))
+	}
+	return fmt.Sprintf("The Dev Kit did not shut down cleanly.\nA session journal from %s was found.\n\nFile: %s\nEmulator: %s\n\nRestore the session?", savedAt, source, emu)
+}
+
+// restoreSession reopens the journaled file and unsaved edits, reloads the
+// running build, puts the emulator back where it was, and reapplies the
+// layout.
+func (s *devKitState) restoreSession(journal sessionJournal, state []byte) {
+	if journal.SourcePath != "" && journal.SourcePath != s.currentPath {
+		if err := s.loadFile(journal.SourcePath, false); err != nil {
+			s.appendBuildOutput("Session restore warning: " + err.Error())
+		}
+	}
+	if autosave, ok := s.pendingAutosave(); ok {
+		s.applyAutosave(autosave)
+		s.appendBuildOutput("Recovered autosave journal")
+	}
+
+	if journal.ROMLoaded {
+		if err := s.restoreSessionEmulator(journal, state); err != nil {
+			s.appendBuildOutput("Session restore warning: " + err.Error())
+		}
+	}
+
+	s.diagnosticsCollapsed = journal.DiagnosticsCollapsed
+	s.settings.DiagnosticsPanel = !journal.DiagnosticsCollapsed
+	mode := viewMode(journal.ViewMode)
+	switch mode {
+	case viewModeFull, viewModeEmulatorOnly, viewModeCodeOnly:
+	default:
+		mode = viewModeFull
+	}
+	s.setViewMode(mode)
+	if s.currentView == viewModeFull && s.mainSplit != nil {
+		s.mainSplit.Offset = clampOffset(journal.MainSplitOffset, defaultMainSplitOffset)
+		s.mainSplit.Refresh()
+		if s.leftSplit != nil && !s.diagnosticsCollapsed {
+			s.leftSplit.Offset = clampOffset(journal.LeftSplitOffset, defaultLeftSplitOffset)
+			s.leftSplit.Refresh()
+		}
+	}
+	selectTabByText(s.workbenchTabs, journal.WorkbenchTab)
+	selectTabByText(s.bottomLeftTabs, journal.BottomTab)
+
+	s.setStatus("Recovered previous session")
+	s.appendBuildOutput("Recovered session journal from " + journal.SavedAt)
+}
+
+func (s *devKitState) restoreSessionEmulator(journal sessionJournal, state []byte) error {
+	if journal.ROMPath != "" {
+		if err := s.loadExternalROM(journal.ROMPath); err != nil {
+			return err
+		}
+	} else {
+		s.runBuild(true)
+		if !s.backend.Snapshot().Loaded {
+			return fmt.Errorf("rebuild failed; emulator state not restored")
+		}
+	}
+	if len(state) > 0 {
+		// The savestate carries the paused flag along with the machine state.
+		if err := s.backend.LoadState(state); err != nil {
+			return fmt.Errorf("emulator state: %w", err)
+		}
+		s.routeInputToEmulator()
+		s.wakeEmulatorLoop()
+		return nil
+	}
+	if journal.Paused && !s.backend.Snapshot().Paused {
+		_, err := s.backend.TogglePause()
+		return err
+	}
+	return nil
+}
+
+func selectedTabText(tabs *container.AppTabs) string {
+	if tabs == nil || tabs.Selected() == nil {
+		return ""
+	}
+	return tabs.Selected().Text
+}
+
+func selectTabByText(tabs *container.AppTabs, text string) {
+	if tabs == nil || text == "" {
+		return
+	}
+	for i, item := range tabs.Items {
+		if item.Text == text {
+			tabs.SelectIndex(i)
+			return
+		}
+	}
+}
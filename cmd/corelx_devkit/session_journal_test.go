@@ -0,0 +1,95 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSessionJournalRoundTrip(t *testing.T) {
+	path := devKitSessionPath(filepath.Join(t.TempDir(), "devkit_settings.json"))
+	journal := sessionJournal{
+		SavedAt:         "2026-01-02T03:04:05Z",
+		SourcePath:      "/tmp/game.corelx",
+		ViewMode:        string(viewModeCodeOnly),
+		MainSplitOffset: 0.4,
+		WorkbenchTab:    "Palette",
+		BottomTab:       "Debugger",
+		ROMLoaded:       true,
+		Paused:          true,
+		FrameCount:      1234,
+	}
+	state := []byte{1, 2, 3, 4}
+	if err := writeSessionJournalFile(path, journal, state); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	got, gotState, err := readSessionJournalFile(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	journal.HasState = true
+	if got != journal {
+		t.Fatalf("journal mismatch:\n got %+v\nwant %+v", got, journal)
+	}
+	if string(gotState) != string(state) {
+		t.Fatalf("state mismatch: %v", gotState)
+	}
+
+	// Rewriting without a running build drops the stale savestate.
+	if err := writeSessionJournalFile(path, sessionJournal{ViewMode: string(viewModeFull)}, nil); err != nil {
+		t.Fatalf("rewrite: %v", err)
+	}
+	if _, err := os.Stat(sessionStatePath(path)); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected stale savestate removed, stat err=%v", err)
+	}
+
+	removeSessionJournalFile(path)
+	if _, _, err := readSessionJournalFile(path); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected not-exist after remove, got %v", err)
+	}
+}
+
+func TestReadSessionJournalMissingStateClearsFlag(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "devkit_session.json")
+	if err := writeSessionJournalFile(path, sessionJournal{ROMLoaded: true}, []byte{9}); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := os.Remove(sessionStatePath(path)); err != nil {
+		t.Fatalf("remove state: %v", err)
+	}
+	got, state, err := readSessionJournalFile(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if got.HasState || state != nil {
+		t.Fatalf("expected HasState cleared, got %+v state=%v", got, state)
+	}
+
+	if err := os.WriteFile(path, []byte("{"), 0644); err != nil {
+		t.Fatalf("write corrupt: %v", err)
+	}
+	if _, _, err := readSessionJournalFile(path); err == nil || errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected parse error for corrupt journal, got %v", err)
+	}
+}
+
+func TestSessionRecoveryMessage(t *testing.T) {
+	msg := sessionRecoveryMessage(sessionJournal{
+		SavedAt:    "2026-01-02T03:04:05Z",
+		ROMLoaded:  true,
+		ROMPath:    "/roms/demo.rom",
+		Paused:     true,
+		FrameCount: 42,
+	})
+	for _, want := range []string{"unsaved buffer", "demo.rom", "frame 42", "paused"} {
+		if !strings.Contains(msg, want) {
+			t.Fatalf("message missing %q:\n%s", want, msg)
+		}
+	}
+	if msg := sessionRecoveryMessage(sessionJournal{}); !strings.Contains(msg, "no build running") {
+		t.Fatalf("expected idle emulator description, got:\n%s", msg)
+	}
+}
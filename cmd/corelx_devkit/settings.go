@@ -4,11 +4,16 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
+	"slices"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/storage"
+	"nitro-core-dx/internal/devkit"
+	"nitro-core-dx/internal/i18n"
+	"nitro-core-dx/internal/input"
 )
 
 const maxRecentFiles = 15
@@ -26,8 +31,58 @@ type devKitSettings struct {
 	CaptureGameInput bool     `json:"capture_game_input"`
 	RecentFiles      []string `json:"recent_files"`
 	UIDensity        string   `json:"ui_density"`
+	Deterministic    bool     `json:"deterministic"`
+
+	Theme              string  `json:"theme"`
+	EditorFontSize     int     `json:"editor_font_size"`
+	EditorTabWidth     int     `json:"editor_tab_width"`
+	EditorInsertSpaces bool    `json:"editor_insert_spaces"`
+	EditorFormatOnSave bool    `json:"editor_format_on_save"`
+	AutosaveInterval   int     `json:"autosave_interval_seconds"`
+	BackgroundBuild    bool    `json:"background_build"`
+	AudioLatencyFrames int     `json:"audio_latency_frames"`
+	EmulatorSpeed      float64 `json:"emulator_speed"`
+
+	// TurboButtons lists the buttons that auto-fire while held, in
+	// input.ParseButtons form ("a,b"); TurboHz is their rate.
+	TurboButtons string `json:"turbo_buttons"`
+	TurboHz      int    `json:"turbo_hz"`
+
+	// Language is the UI locale tag ("en", "es"); empty follows the
+	// environment (see i18n.Detect).
+	Language string `json:"language,omitempty"`
+
+	// MovieAuthor is the author recorded in new movies.
+	MovieAuthor string `json:"movie_author,omitempty"`
+
+	// EditorColorScheme is the preset (editorSchemeDark/editorSchemeLight);
+	// EditorColors overrides individual roles as "#RRGGBB".
+	EditorColorScheme string            `json:"editor_color_scheme"`
+	EditorColors      map[string]string `json:"editor_colors,omitempty"`
+
+	// Keymap overrides command key bindings by command id (see
+	// devKitCommands), e.g. "build.run": "Ctrl+Shift+B". An empty string
+	// unbinds the command.
+	Keymap map[string]string `json:"keymap,omitempty"`
+
+	// EmulatorWindow and DebuggerWindow remember whether those panes are
+	// popped out into their own windows, and the windows' sizes.
+	EmulatorWindow detachedWindowSettings `json:"emulator_window"`
+	DebuggerWindow detachedWindowSettings `json:"debugger_window"`
 }
 
+// Preference defaults and limits. EditorFontSize 0 follows the UI theme and
+// AutosaveInterval 0 writes the autosave journal on every edit.
+const (
+	defaultEditorTabWidth     = 4
+	defaultAudioLatencyFrames = 4
+	maxAudioLatencyFrames     = 8
+	minEditorFontSize         = 8
+	maxEditorFontSize         = 32
+	maxAutosaveInterval       = 300
+	defaultTurboHz            = 15
+)
+
 func defaultDevKitSettings() devKitSettings {
 	return devKitSettings{
 		ViewMode:         string(viewModeFull),
@@ -38,6 +93,17 @@ func defaultDevKitSettings() devKitSettings {
 		CaptureGameInput: true,
 		RecentFiles:      []string{},
 		UIDensity:        "compact",
+
+		Theme:              themeSystem,
+		EditorTabWidth:     defaultEditorTabWidth,
+		BackgroundBuild:    true,
+		AudioLatencyFrames: defaultAudioLatencyFrames,
+		EmulatorSpeed:      1,
+		TurboHz:            defaultTurboHz,
+		EditorColorScheme:  editorSchemeDark,
+
+		EmulatorWindow: detachedWindowSettings{Width: defaultEmulatorWindowWidth, Height: defaultEmulatorWindowHeight},
+		DebuggerWindow: detachedWindowSettings{Width: defaultDebuggerWindowWidth, Height: defaultDebuggerWindowHeight},
 	}
 }
 
@@ -86,6 +152,7 @@ func loadDevKitSettings(path string) (devKitSettings, error) {
 	if settings.UIDensity == "" {
 		settings.UIDensity = "compact"
 	}
+	normalizePreferences(&settings)
 	settings.RecentFiles = normalizeRecentFiles(settings.RecentFiles)
 	return settings, nil
 }
@@ -110,6 +177,7 @@ func saveDevKitSettings(path string, settings devKitSettings) error {
 	if settings.LeftSplitOffset <= 0 || settings.LeftSplitOffset >= 1 {
 		settings.LeftSplitOffset = defaultLeftSplitOffset
 	}
+	normalizePreferences(&settings)
 
 	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
 		return err
@@ -119,7 +187,67 @@ func saveDevKitSettings(path string, settings devKitSettings) error {
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(path, data, 0644)
+	// Write to a temp file and rename it over path, so another Dev Kit
+	// reading the settings never sees a half-written file.
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// normalizePreferences clamps the Preferences dialog values, replacing
+// missing or out-of-range ones (older settings files) with defaults.
+func normalizePreferences(settings *devKitSettings) {
+	switch settings.Theme {
+	case themeSystem, themeDark, themeLight:
+	default:
+		settings.Theme = themeSystem
+	}
+	if settings.Language != "" && !slices.Contains(i18n.Locales(), settings.Language) {
+		settings.Language = ""
+	}
+	if settings.EditorFontSize != 0 {
+		settings.EditorFontSize = clampInt(settings.EditorFontSize, minEditorFontSize, maxEditorFontSize)
+	}
+	if settings.EditorTabWidth <= 0 {
+		settings.EditorTabWidth = defaultEditorTabWidth
+	}
+	settings.EditorTabWidth = clampInt(settings.EditorTabWidth, 1, 8)
+	settings.AutosaveInterval = clampInt(settings.AutosaveInterval, 0, maxAutosaveInterval)
+	if settings.AudioLatencyFrames <= 0 {
+		settings.AudioLatencyFrames = defaultAudioLatencyFrames
+	}
+	settings.AudioLatencyFrames = clampInt(settings.AudioLatencyFrames, 1, maxAudioLatencyFrames)
+	if _, ok := editorSchemePresets[settings.EditorColorScheme]; !ok {
+		settings.EditorColorScheme = editorSchemeDark
+	}
+	if settings.EmulatorSpeed <= 0 {
+		settings.EmulatorSpeed = 1
+	}
+	settings.EmulatorSpeed = math.Min(math.Max(settings.EmulatorSpeed, devkit.MinSpeed), devkit.MaxSpeed)
+	if _, err := input.ParseButtons(settings.TurboButtons); err != nil {
+		settings.TurboButtons = ""
+	}
+	if settings.TurboHz <= 0 {
+		settings.TurboHz = defaultTurboHz
+	}
+	settings.TurboHz = clampInt(settings.TurboHz, 1, 30)
+	normalizeDetachedWindow(&settings.EmulatorWindow, defaultEmulatorWindowWidth, defaultEmulatorWindowHeight)
+	normalizeDetachedWindow(&settings.DebuggerWindow, defaultDebuggerWindowWidth, defaultDebuggerWindowHeight)
 }
 
 func normalizeRecentFiles(paths []string) []string {
@@ -203,14 +331,22 @@ func (s *devKitState) defaultProjectOpenDir() string {
 	return s.defaultROMDialogDir()
 }
 
+// persistSettings merges this instance's settings into the settings file,
+// keeping changes another Dev Kit window saved in the meantime, and adopts
+// the merged result.
 func (s *devKitState) persistSettings() {
-	if err := saveDevKitSettings(s.settingsPath, s.settings); err != nil {
+	merged, err := syncDevKitSettings(s.settingsPath, s.settingsBase, s.settings, s.recentOpened)
+	if err != nil {
 		if s.buildOutput != nil {
 			s.appendBuildOutput("Settings save warning: " + err.Error())
 		} else {
 			fmt.Fprintf(os.Stderr, "settings save warning: %v\n", err)
 		}
+		return
 	}
+	s.settings = merged
+	s.settingsBase = cloneDevKitSettings(merged)
+	s.recentOpened = nil
 }
 
 func (s *devKitState) rememberSourcePath(path string) {
@@ -240,6 +376,7 @@ func (s *devKitState) pushRecentFile(path string) {
 		return
 	}
 	clean := filepath.Clean(path)
+	s.recentOpened = append(s.recentOpened, clean)
 	next := make([]string, 0, len(s.settings.RecentFiles)+1)
 	next = append(next, clean)
 	for _, existing := range s.settings.RecentFiles {
@@ -59,6 +59,7 @@ func TestSaveLoadDevKitSettingsRoundTrip(t *testing.T) {
 		LastROMPath:      "/tmp/roms/demo.rom",
 		ViewMode:         string(viewModeEmulatorOnly),
 		CaptureGameInput: false,
+		Deterministic:    true,
 		RecentFiles: []string{
 			"/tmp/src/main.corelx",
 			"/tmp/src/other.corelx",
@@ -94,6 +95,9 @@ func TestSaveLoadDevKitSettingsRoundTrip(t *testing.T) {
 	if out.CaptureGameInput != in.CaptureGameInput {
 		t.Fatalf("CaptureGameInput mismatch: got %v want %v", out.CaptureGameInput, in.CaptureGameInput)
 	}
+	if out.Deterministic != in.Deterministic {
+		t.Fatalf("Deterministic mismatch: got %v want %v", out.Deterministic, in.Deterministic)
+	}
 	if len(out.RecentFiles) != len(in.RecentFiles) {
 		t.Fatalf("RecentFiles length mismatch: got %d want %d", len(out.RecentFiles), len(in.RecentFiles))
 	}
@@ -115,6 +119,43 @@ func TestLoadDevKitSettingsInvalidViewModeFallsBackToDefault(t *testing.T) {
 	}
 }
 
+func TestLoadDevKitSettingsNormalizesPreferences(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings_prefs.json")
+	raw := []byte(`{"theme":"neon","editor_font_size":200,"editor_tab_width":0,"autosave_interval_seconds":-5,"audio_latency_frames":99,"emulator_speed":0,"turbo_buttons":"a,turbo","turbo_hz":120,"language":"klingon"}`)
+	if err := os.WriteFile(path, raw, 0644); err != nil {
+		t.Fatalf("write settings fixture: %v", err)
+	}
+
+	out, err := loadDevKitSettings(path)
+	if err != nil {
+		t.Fatalf("load settings: %v", err)
+	}
+	if out.Theme != themeSystem {
+		t.Fatalf("expected theme fallback %q, got %q", themeSystem, out.Theme)
+	}
+	if out.EditorFontSize != maxEditorFontSize {
+		t.Fatalf("expected font size clamped to %d, got %d", maxEditorFontSize, out.EditorFontSize)
+	}
+	if out.EditorTabWidth != defaultEditorTabWidth {
+		t.Fatalf("expected default tab width, got %d", out.EditorTabWidth)
+	}
+	if out.AutosaveInterval != 0 {
+		t.Fatalf("expected autosave interval clamped to 0, got %d", out.AutosaveInterval)
+	}
+	if out.AudioLatencyFrames != maxAudioLatencyFrames {
+		t.Fatalf("expected audio latency clamped to %d, got %d", maxAudioLatencyFrames, out.AudioLatencyFrames)
+	}
+	if out.EmulatorSpeed != 1 {
+		t.Fatalf("expected default emulator speed 1, got %v", out.EmulatorSpeed)
+	}
+	if out.TurboButtons != "" || out.TurboHz != 30 {
+		t.Fatalf("expected bad turbo buttons dropped and rate clamped to 30, got %q at %d", out.TurboButtons, out.TurboHz)
+	}
+	if out.Language != "" {
+		t.Fatalf("expected unknown language to fall back to the environment, got %q", out.Language)
+	}
+}
+
 func TestResolveDefaultROMDirPrefersLastROMDir(t *testing.T) {
 	root := t.TempDir()
 	lastROM := filepath.Join(root, "last-rom")
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"nitro-core-dx/internal/devkit"
+)
+
+// packageForSharing builds the editor's source for release in the
+// background, then offers to save a zip with the ROM, a screenshot, a
+// README and checksums. The README's title, author and controls come from
+// the project's corelx.share.json.
+func (s *devKitState) packageForSharing() {
+	var info devkit.ShareInfo
+	if s.currentPath != "" {
+		var err error
+		if info, err = devkit.LoadShareInfo(filepath.Dir(s.currentPath)); err != nil {
+			dialog.ShowError(err, s.window)
+			s.setStatus("Packaging failed")
+			return
+		}
+	}
+	source, sourcePath := s.sourceEditor.Text(), s.currentPath
+	s.setStatus("Packaging...")
+	s.appendBuildOutput("Package for Sharing: release build and screenshot")
+	go func() {
+		var buf bytes.Buffer
+		pkg, err := s.backend.PackageForSharing(&buf, source, sourcePath, info)
+		fyne.Do(func() {
+			if err != nil {
+				dialog.ShowError(err, s.window)
+				s.appendBuildOutput("Package for Sharing failed: " + err.Error())
+				s.setStatus("Packaging failed")
+				return
+			}
+			s.saveSharePackageDialog(pkg, buf.Bytes())
+		})
+	}()
+}
+
+func (s *devKitState) saveSharePackageDialog(pkg *devkit.SharePackage, data []byte) {
+	fd := dialog.NewFileSave(func(wc fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, s.window)
+			return
+		}
+		if wc == nil {
+			s.setStatus("Packaging canceled")
+			return
+		}
+		defer wc.Close()
+		if _, err := wc.Write(data); err != nil {
+			dialog.ShowError(err, s.window)
+			return
+		}
+		path := uriPath(wc.URI())
+		if path != "" {
+			s.settings.LastSourceDir = filepath.Dir(path)
+			s.persistSettings()
+		}
+		s.appendBuildOutput(fmt.Sprintf("Packaged %s to %s (%d files; ROM SHA-256 %s)", pkg.Title, path, len(pkg.Files), pkg.ROMSHA256))
+		s.setStatus("Packaged " + pkg.Title)
+	}, s.window)
+	fd.SetFilter(storage.NewExtensionFileFilter([]string{".zip"}))
+	if loc := dialogListableForDir(s.settings.LastSourceDir); loc != nil {
+		fd.SetLocation(loc)
+	}
+	fd.SetFileName(pkg.FileName)
+	fd.Show()
+}
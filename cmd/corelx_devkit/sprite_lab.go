@@ -10,6 +10,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
@@ -19,6 +20,7 @@ import (
 	"fyne.io/fyne/v2/storage"
 	"fyne.io/fyne/v2/widget"
 	"nitro-core-dx/internal/corelx"
+	"nitro-core-dx/internal/i18n"
 )
 
 var (
@@ -46,6 +48,11 @@ type spriteLabAsset struct {
 	Pixels      []uint8  `json:"pixels"`
 	PaletteBank int      `json:"palette_bank,omitempty"`
 	Palettes    []uint16 `json:"palettes,omitempty"`
+	// Frames holds every frame of an animation; Pixels repeats the first
+	// for readers that only know single sprites. FrameTicks is how many
+	// anim.update() calls each frame lasts.
+	Frames     [][]uint8 `json:"frames,omitempty"`
+	FrameTicks []int     `json:"frame_ticks,omitempty"`
 }
 
 type spriteLabHistoryState struct {
@@ -53,6 +60,9 @@ type spriteLabHistoryState struct {
 	Height   int
 	Pixels   []uint8
 	Palettes []uint16
+	Frames   [][]uint8
+	Ticks    []int
+	Frame    int
 }
 
 type spriteLabTool string
@@ -73,6 +83,12 @@ type spriteLabPaintOverlay struct {
 	gridW         int
 	gridH         int
 	cellPx        int
+
+	// The keyboard cursor: arrow keys move it while the canvas has focus and
+	// Space or Enter paints its cell. announce speaks AccessibleLabel on focus.
+	cursorX, cursorY int
+	announce         func(string)
+	focusRing        *canvas.Rectangle
 }
 
 var spriteLabBasePalette = [16]color.NRGBA{
@@ -100,6 +116,15 @@ func (s *devKitState) buildSpriteLabPane() fyne.CanvasObject {
 	pixels := make([]uint8, spriteW*spriteH)
 	palettes := defaultSpriteLabPaletteData()
 
+	// The animation: pixels is always frames[currentFrame], so every edit
+	// goes to the frame being shown.
+	frames := [][]uint8{pixels}
+	frameTicks := []int{spriteLabDefaultTicks}
+	currentFrame := 0
+	onionSkin := false
+	useEmulatorPalette := false
+	var stopPlayback chan struct{}
+
 	selectedColor := 1
 	selectedBank := 0
 	currentTool := spriteLabToolPencil
@@ -123,6 +148,9 @@ func (s *devKitState) buildSpriteLabPane() fyne.CanvasObject {
 			Height:   spriteH,
 			Pixels:   pixelCopy,
 			Palettes: paletteCopy,
+			Frames:   cloneSpriteLabFrames(frames),
+			Ticks:    append([]int(nil), frameTicks...),
+			Frame:    currentFrame,
 		}
 	}
 
@@ -180,6 +208,11 @@ func (s *devKitState) buildSpriteLabPane() fyne.CanvasObject {
 	statsLabel := widget.NewLabel("Active pixels: 0/0")
 	historyLabel := widget.NewLabel("History: 1/1")
 	sizeLabel := widget.NewLabel("Size: 24x24")
+	frameLabel := widget.NewLabel("Frame 1/1")
+	ticksEntry := widget.NewEntry()
+	baseTileEntry := widget.NewEntry()
+	baseTileEntry.SetText(strconv.Itoa(spriteLabDefaultBaseTile))
+	animPreviewLabel := widget.NewLabel("Preview: Sprite Lab palette")
 
 	rEntry := widget.NewEntry()
 	gEntry := widget.NewEntry()
@@ -210,6 +243,11 @@ func (s *devKitState) buildSpriteLabPane() fyne.CanvasObject {
 	previewImage.SetMinSize(spriteLabPreviewDisplaySize(spriteW, spriteH))
 	previewHolder := container.NewCenter(previewImage)
 
+	animPreviewImage := canvas.NewImageFromImage(image.NewNRGBA(image.Rect(0, 0, 1, 1)))
+	animPreviewImage.FillMode = canvas.ImageFillContain
+	animPreviewImage.ScaleMode = canvas.ImageScalePixels
+	animPreviewImage.SetMinSize(spriteLabPreviewDisplaySize(spriteW, spriteH))
+
 	paletteButtons := make([]*widget.Button, spriteLabColorsPerBank)
 	paletteChips := make([]*canvas.Image, spriteLabColorsPerBank)
 	selectedColorChip := canvas.NewImageFromImage(renderSpriteLabPaletteChipImage(color.NRGBA{A: 0xFF}, false))
@@ -233,6 +271,7 @@ func (s *devKitState) buildSpriteLabPane() fyne.CanvasObject {
 		cellPx := spriteLabCellPx(spriteW, spriteH, spriteLabEditorMaxPx)
 		editorImage.SetMinSize(spriteLabEditorDisplaySize(spriteW, spriteH))
 		previewImage.SetMinSize(spriteLabPreviewDisplaySize(spriteW, spriteH))
+		animPreviewImage.SetMinSize(spriteLabPreviewDisplaySize(spriteW, spriteH))
 		sizeLabel.SetText("Size: " + formatSpriteSizeLabel(spriteW, spriteH))
 		if canvasOverlay != nil {
 			canvasOverlay.SetGrid(spriteW, spriteH, cellPx)
@@ -246,8 +285,10 @@ func (s *devKitState) buildSpriteLabPane() fyne.CanvasObject {
 		state := history[index]
 		spriteW = state.Width
 		spriteH = state.Height
-		pixels = make([]uint8, len(state.Pixels))
-		copy(pixels, state.Pixels)
+		frames = cloneSpriteLabFrames(state.Frames)
+		frameTicks = append([]int(nil), state.Ticks...)
+		currentFrame = state.Frame
+		pixels = frames[currentFrame]
 		copy(palettes, state.Palettes)
 		historyIndex = index
 		setSizeSelection()
@@ -262,17 +303,12 @@ func (s *devKitState) buildSpriteLabPane() fyne.CanvasObject {
 		if newW == spriteW && newH == spriteH {
 			return false
 		}
-		next := make([]uint8, newW*newH)
-		copyW := minInt(spriteW, newW)
-		copyH := minInt(spriteH, newH)
-		for y := 0; y < copyH; y++ {
-			for x := 0; x < copyW; x++ {
-				next[y*newW+x] = pixels[y*spriteW+x]
-			}
+		for i := range frames {
+			frames[i] = resizeSpriteLabPixels(frames[i], spriteW, spriteH, newW, newH)
 		}
 		spriteW = newW
 		spriteH = newH
-		pixels = next
+		pixels = frames[currentFrame]
 		updateCanvasSizes()
 		setSizeSelection()
 		return true
@@ -351,12 +387,51 @@ func (s *devKitState) buildSpriteLabPane() fyne.CanvasObject {
 
 	refreshEditorOnly := func() {
 		cellPx := spriteLabCellPx(spriteW, spriteH, spriteLabEditorMaxPx)
-		editorImage.Image = renderSpriteLabImage(
+		img := renderSpriteLabImage(
 			pixels, palettes, selectedBank, spriteW, spriteH, cellPx, hoverX, hoverY, showGrid, transparentZero,
 		)
+		if nrgba, ok := img.(*image.NRGBA); ok && onionSkin && len(frames) > 1 {
+			prev := (currentFrame + len(frames) - 1) % len(frames)
+			next := (currentFrame + 1) % len(frames)
+			if next != prev {
+				overlaySpriteLabOnionSkin(nrgba, pixels, frames[next], palettes, selectedBank, spriteW, spriteH, cellPx, spriteLabOnionNextAlpha)
+			}
+			overlaySpriteLabOnionSkin(nrgba, pixels, frames[prev], palettes, selectedBank, spriteW, spriteH, cellPx, spriteLabOnionPrevAlpha)
+		}
+		editorImage.Image = img
 		editorImage.Refresh()
 	}
 
+	// showAnimPreview draws frame into the animation preview, in the
+	// running build's CGRAM when useEmulatorPalette is set and one is
+	// loaded.
+	showAnimPreview := func(frame int) {
+		if frame < 0 || frame >= len(frames) {
+			frame = currentFrame
+		}
+		pal, source := palettes, "Sprite Lab palette"
+		if useEmulatorPalette {
+			if snap := s.backend.PaletteSnapshot(); snap.Loaded {
+				pal, source = snap.Colors[:], "emulator CGRAM"
+			} else {
+				source = "Sprite Lab palette (no build loaded)"
+			}
+		}
+		animPreviewImage.Image = renderSpriteLabImage(
+			frames[frame], pal, selectedBank, spriteW, spriteH, spriteLabCellPx(spriteW, spriteH, spriteLabPreviewMaxPx), -1, -1, false, transparentZero,
+		)
+		animPreviewImage.Refresh()
+		animPreviewLabel.SetText(fmt.Sprintf("Preview: frame %d/%d, %s", frame+1, len(frames), source))
+	}
+
+	refreshFrameControls := func() {
+		frameLabel.SetText(fmt.Sprintf("Frame %d/%d (%d ticks)", currentFrame+1, len(frames), frameTicks[currentFrame]))
+		ticksEntry.SetText(strconv.Itoa(frameTicks[currentFrame]))
+		if stopPlayback == nil {
+			showAnimPreview(currentFrame)
+		}
+	}
+
 	refreshVisuals := func() {
 		refreshEditorOnly()
 		previewCellPx := spriteLabCellPx(spriteW, spriteH, spriteLabPreviewMaxPx)
@@ -376,6 +451,7 @@ func (s *devKitState) buildSpriteLabPane() fyne.CanvasObject {
 		statsLabel.SetText(fmt.Sprintf("Active pixels: %d/%d", nonZeroPixels(), spriteW*spriteH))
 		refreshPaletteButtons()
 		refreshHistoryButtons()
+		refreshFrameControls()
 		setColorEditors()
 	}
 
@@ -451,6 +527,7 @@ func (s *devKitState) buildSpriteLabPane() fyne.CanvasObject {
 			refreshEditorOnly()
 		},
 	)
+	canvasOverlay.announce = s.setStatus
 
 	sizeOptions := spriteLabSizeOptions()
 	resizeSelect.Options = make([]string, 0, len(sizeOptions))
@@ -689,7 +766,7 @@ func (s *devKitState) buildSpriteLabPane() fyne.CanvasObject {
 			}
 			defer wc.Close()
 
-			data, marshalErr := marshalSpriteLabAsset(nameEntry.Text, pixels, spriteW, spriteH, selectedBank, palettes)
+			data, marshalErr := marshalSpriteLabAnimation(nameEntry.Text, frames, frameTicks, spriteW, spriteH, selectedBank, palettes)
 			if marshalErr != nil {
 				dialog.ShowError(marshalErr, s.window)
 				return
@@ -739,8 +816,9 @@ func (s *devKitState) buildSpriteLabPane() fyne.CanvasObject {
 
 			spriteW = asset.Width
 			spriteH = asset.Height
-			pixels = make([]uint8, len(asset.Pixels))
-			copy(pixels, asset.Pixels)
+			frames, frameTicks = asset.animation()
+			currentFrame = 0
+			pixels = frames[0]
 			copy(palettes, asset.Palettes)
 			selectedBank = asset.PaletteBank
 			if selectedBank < 0 || selectedBank >= spriteLabPaletteBanks {
@@ -856,6 +934,155 @@ func (s *devKitState) buildSpriteLabPane() fyne.CanvasObject {
 	})
 	applyManifestButton.Importance = widget.MediumImportance
 
+	selectFrame := func(i int) {
+		currentFrame = (i + len(frames)) % len(frames)
+		pixels = frames[currentFrame]
+		refreshVisuals()
+		statusLabel.SetText(fmt.Sprintf("Editing frame %d/%d", currentFrame+1, len(frames)))
+	}
+	insertFrame := func(frame []uint8, note string) {
+		if len(frames) >= spriteLabMaxFrames {
+			statusLabel.SetText(fmt.Sprintf("An animation holds at most %d frames", spriteLabMaxFrames))
+			return
+		}
+		at := currentFrame + 1
+		frames = append(frames[:at], append([][]uint8{frame}, frames[at:]...)...)
+		frameTicks = append(frameTicks[:at], append([]int{frameTicks[currentFrame]}, frameTicks[at:]...)...)
+		currentFrame = at
+		pixels = frames[currentFrame]
+		commitHistory()
+		refreshVisuals()
+		statusLabel.SetText(fmt.Sprintf("%s frame %d/%d", note, currentFrame+1, len(frames)))
+	}
+	moveFrame := func(delta int) {
+		to := currentFrame + delta
+		if to < 0 || to >= len(frames) {
+			statusLabel.SetText("Frame is already at that end of the animation")
+			return
+		}
+		frames[currentFrame], frames[to] = frames[to], frames[currentFrame]
+		frameTicks[currentFrame], frameTicks[to] = frameTicks[to], frameTicks[currentFrame]
+		currentFrame = to
+		commitHistory()
+		refreshVisuals()
+		statusLabel.SetText(fmt.Sprintf("Moved frame to %d/%d", currentFrame+1, len(frames)))
+	}
+
+	prevFrameButton := widget.NewButton("Previous", func() { selectFrame(currentFrame - 1) })
+	nextFrameButton := widget.NewButton("Next", func() { selectFrame(currentFrame + 1) })
+	addFrameButton := widget.NewButton("Add Frame", func() {
+		insertFrame(make([]uint8, spriteW*spriteH), "Added blank")
+	})
+	duplicateFrameButton := widget.NewButton("Duplicate Frame", func() {
+		insertFrame(append([]uint8(nil), pixels...), "Duplicated to")
+	})
+	deleteFrameButton := widget.NewButton("Delete Frame", func() {
+		if len(frames) == 1 {
+			statusLabel.SetText("An animation needs at least one frame")
+			return
+		}
+		frames = append(frames[:currentFrame], frames[currentFrame+1:]...)
+		frameTicks = append(frameTicks[:currentFrame], frameTicks[currentFrame+1:]...)
+		currentFrame = minInt(currentFrame, len(frames)-1)
+		pixels = frames[currentFrame]
+		commitHistory()
+		refreshVisuals()
+		statusLabel.SetText(fmt.Sprintf("Deleted frame; %d left", len(frames)))
+	})
+	moveEarlierButton := widget.NewButton("Move Earlier", func() { moveFrame(-1) })
+	moveLaterButton := widget.NewButton("Move Later", func() { moveFrame(1) })
+
+	setTicksButton := widget.NewButton("Set Duration", func() {
+		ticks, err := strconv.Atoi(strings.TrimSpace(ticksEntry.Text))
+		if err != nil || ticks < 1 || ticks > spriteLabMaxTicks {
+			statusLabel.SetText(fmt.Sprintf("Invalid duration (1-%d ticks)", spriteLabMaxTicks))
+			return
+		}
+		if frameTicks[currentFrame] == ticks {
+			statusLabel.SetText("Frame duration unchanged")
+			return
+		}
+		frameTicks[currentFrame] = ticks
+		commitHistory()
+		refreshVisuals()
+		statusLabel.SetText(fmt.Sprintf("Frame %d lasts %d ticks", currentFrame+1, ticks))
+	})
+	ticksEntry.OnSubmitted = func(string) { setTicksButton.OnTapped() }
+
+	onionCheck := widget.NewCheck("Onion Skin (previous and next frames)", func(v bool) {
+		onionSkin = v
+		refreshEditorOnly()
+	})
+	emulatorPaletteCheck := widget.NewCheck("Preview in emulator palette", func(v bool) {
+		useEmulatorPalette = v
+		if stopPlayback == nil {
+			showAnimPreview(currentFrame)
+		}
+	})
+
+	// The preview loops the frames at 60 ticks per second, as anim.update()
+	// does once per frame.
+	var playButton *widget.Button
+	playButton = widget.NewButton("Play", func() {
+		if stopPlayback != nil {
+			close(stopPlayback)
+			stopPlayback = nil
+			playButton.SetText("Play")
+			showAnimPreview(currentFrame)
+			statusLabel.SetText("Animation preview stopped")
+			return
+		}
+		stop := make(chan struct{})
+		stopPlayback = stop
+		playButton.SetText("Stop")
+		statusLabel.SetText("Animation preview playing")
+		tick, shown := 0, -1
+		go func() {
+			ticker := time.NewTicker(time.Second / 60)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-stop:
+					return
+				case <-ticker.C:
+				}
+				fyne.Do(func() {
+					if stopPlayback != stop {
+						return
+					}
+					if frame := spriteLabFrameAtTick(frameTicks, tick); frame != shown || useEmulatorPalette {
+						showAnimPreview(frame)
+						shown = frame
+					}
+					tick++
+				})
+			}
+		}()
+	})
+
+	applyAnimButton := widget.NewButton("Apply Animation Asset", func() {
+		name := sanitizeSpriteLabName(nameEntry.Text)
+		baseTile, err := strconv.Atoi(strings.TrimSpace(baseTileEntry.Text))
+		if err != nil {
+			statusLabel.SetText("Invalid base tile (0-255)")
+			return
+		}
+		snippets, err := spriteLabAnimSnippets(name, frames, frameTicks, spriteW, spriteH, baseTile)
+		if err != nil {
+			dialog.ShowError(err, s.window)
+			return
+		}
+		next, summary := upsertSpriteLabAnimIntoSource(s.sourceEditor.Text(), snippets, spriteLabAnimLoadComment(name, len(frames), baseTile))
+		s.setSourceContent(next, true, false)
+		if s.workbenchTabs != nil {
+			s.workbenchTabs.SelectIndex(0)
+		}
+		s.setStatus(summary)
+		s.appendBuildOutput(summary)
+		statusLabel.SetText(summary)
+	})
+	applyAnimButton.Importance = widget.HighImportance
+
 	s.spriteLabHotkey = func(name fyne.KeyName) bool {
 		switch name {
 		case fyne.KeyB:
@@ -997,6 +1224,27 @@ func (s *devKitState) buildSpriteLabPane() fyne.CanvasObject {
 		hotkeysLabel,
 	)
 
+	animHelp := widget.NewLabel("Durations are in anim.update() ticks (60 per second). Apply Animation Asset writes one tiles8/tiles16 asset per frame (NameFrame0, NameFrame1, ...) and an anim asset stepping through them from the base tile; frames must be 8x8 or 16x16.")
+	animHelp.Wrapping = fyne.TextWrapWord
+	animationTab := container.NewVBox(
+		frameLabel,
+		container.NewGridWithColumns(2, prevFrameButton, nextFrameButton),
+		container.NewGridWithColumns(2, addFrameButton, duplicateFrameButton),
+		container.NewGridWithColumns(2, moveEarlierButton, moveLaterButton),
+		deleteFrameButton,
+		container.NewBorder(nil, nil, widget.NewLabel("Duration (ticks)"), setTicksButton, ticksEntry),
+		onionCheck,
+		widget.NewSeparator(),
+		widget.NewLabel("Looped Preview"),
+		container.NewCenter(animPreviewImage),
+		animPreviewLabel,
+		container.NewHBox(playButton, emulatorPaletteCheck),
+		widget.NewSeparator(),
+		container.NewBorder(nil, nil, widget.NewLabel("Base Tile"), nil, baseTileEntry),
+		applyAnimButton,
+		animHelp,
+	)
+
 	inspectorAccordion := widget.NewAccordion(
 		widget.NewAccordionItem("Packed 4bpp Bytes (byte = right<<4 | left)", hexPreview),
 		widget.NewAccordionItem("Per-Pixel Color Indices (00..0F)", indexPreview),
@@ -1010,6 +1258,7 @@ func (s *devKitState) buildSpriteLabPane() fyne.CanvasObject {
 	rightTabs := container.NewAppTabs(
 		container.NewTabItem("Palette", container.NewScroll(paletteTab)),
 		container.NewTabItem("Asset", container.NewScroll(assetTab)),
+		container.NewTabItem("Animation", container.NewScroll(animationTab)),
 		container.NewTabItem("Export/Code", container.NewScroll(exportTab)),
 		container.NewTabItem("Inspector", container.NewScroll(inspectorTab)),
 	)
@@ -1050,10 +1299,71 @@ func (o *spriteLabPaintOverlay) SetGrid(w, h, cellPx int) {
 	o.gridW = w
 	o.gridH = h
 	o.cellPx = cellPx
+	o.cursorX = clampInt(o.cursorX, 0, max(w-1, 0))
+	o.cursorY = clampInt(o.cursorY, 0, max(h-1, 0))
 }
 
 func (o *spriteLabPaintOverlay) CreateRenderer() fyne.WidgetRenderer {
-	return widget.NewSimpleRenderer(canvas.NewRectangle(color.Transparent))
+	o.focusRing = newFocusRing()
+	return widget.NewSimpleRenderer(o.focusRing)
+}
+
+// AccessibleLabel describes the canvas and keyboard cursor for
+// accessibleLabeler.
+func (o *spriteLabPaintOverlay) AccessibleLabel() string {
+	return i18n.T("Sprite canvas, %d by %d pixels, cursor at (%d,%d). Arrow keys move the cursor, Space paints.", o.gridW, o.gridH, o.cursorX, o.cursorY)
+}
+
+func (o *spriteLabPaintOverlay) FocusGained() {
+	showFocusRing(o.focusRing, true)
+	if o.onHover != nil {
+		o.onHover(o.cursorX, o.cursorY)
+	}
+	if o.announce != nil {
+		o.announce(o.AccessibleLabel())
+	}
+}
+
+func (o *spriteLabPaintOverlay) FocusLost() {
+	showFocusRing(o.focusRing, false)
+	if o.onHoverOut != nil {
+		o.onHoverOut()
+	}
+}
+
+func (o *spriteLabPaintOverlay) TypedRune(rune) {}
+
+func (o *spriteLabPaintOverlay) TypedKey(ev *fyne.KeyEvent) {
+	switch ev.Name {
+	case fyne.KeyLeft:
+		o.moveCursor(-1, 0)
+	case fyne.KeyRight:
+		o.moveCursor(1, 0)
+	case fyne.KeyUp:
+		o.moveCursor(0, -1)
+	case fyne.KeyDown:
+		o.moveCursor(0, 1)
+	case fyne.KeySpace, fyne.KeyReturn, fyne.KeyEnter:
+		if o.gridW <= 0 || o.gridH <= 0 {
+			return
+		}
+		o.beginStroke()
+		if o.onPaint != nil {
+			o.onPaint(o.cursorX, o.cursorY)
+		}
+		o.endStroke()
+	}
+}
+
+func (o *spriteLabPaintOverlay) moveCursor(dx, dy int) {
+	if o.gridW <= 0 || o.gridH <= 0 {
+		return
+	}
+	o.cursorX = clampInt(o.cursorX+dx, 0, o.gridW-1)
+	o.cursorY = clampInt(o.cursorY+dy, 0, o.gridH-1)
+	if o.onHover != nil {
+		o.onHover(o.cursorX, o.cursorY)
+	}
 }
 
 func (o *spriteLabPaintOverlay) beginStroke() {
@@ -1409,15 +1719,28 @@ func shiftSpritePixelsWrap(pixels []uint8, width, height, dx, dy int) ([]uint8,
 }
 
 func marshalSpriteLabAsset(name string, pixels []uint8, width, height int, paletteBank int, palettes []uint16) ([]byte, error) {
+	return marshalSpriteLabAnimation(name, [][]uint8{pixels}, []int{spriteLabDefaultTicks}, width, height, paletteBank, palettes)
+}
+
+// marshalSpriteLabAnimation is marshalSpriteLabAsset for a sprite with
+// several frames; a single frame is stored as a plain sprite.
+func marshalSpriteLabAnimation(name string, frames [][]uint8, ticks []int, width, height int, paletteBank int, palettes []uint16) ([]byte, error) {
+	if len(frames) == 0 {
+		return nil, fmt.Errorf("sprite asset has no frames")
+	}
 	a := spriteLabAsset{
 		Format:      spriteLabFormatV1,
 		Name:        sanitizeSpriteLabName(name),
 		Width:       width,
 		Height:      height,
-		Pixels:      append([]uint8(nil), pixels...),
+		Pixels:      append([]uint8(nil), frames[0]...),
 		PaletteBank: paletteBank,
 		Palettes:    append([]uint16(nil), palettes...),
 	}
+	if len(frames) > 1 {
+		a.Frames = cloneSpriteLabFrames(frames)
+		a.FrameTicks = append([]int(nil), ticks...)
+	}
 	if err := validateSpriteLabAsset(a); err != nil {
 		return nil, err
 	}
@@ -1478,9 +1801,37 @@ func validateSpriteLabAsset(a spriteLabAsset) error {
 	if len(a.Palettes) != spriteLabPaletteCount {
 		return fmt.Errorf("palette data length must be %d", spriteLabPaletteCount)
 	}
+	if len(a.Frames) > spriteLabMaxFrames {
+		return fmt.Errorf("sprite asset has %d frames, more than %d", len(a.Frames), spriteLabMaxFrames)
+	}
+	if len(a.Frames) > 0 && len(a.FrameTicks) != len(a.Frames) {
+		return fmt.Errorf("sprite asset has %d frames but %d frame durations", len(a.Frames), len(a.FrameTicks))
+	}
+	for f, frame := range a.Frames {
+		if len(frame) != expectedPixels {
+			return fmt.Errorf("frame %d pixel length must be %d", f+1, expectedPixels)
+		}
+		for i, px := range frame {
+			if px > 0x0F {
+				return fmt.Errorf("frame %d pixel %d out of range: %d", f+1, i, px)
+			}
+		}
+		if t := a.FrameTicks[f]; t < 1 || t > spriteLabMaxTicks {
+			return fmt.Errorf("frame %d duration must be 1-%d ticks", f+1, spriteLabMaxTicks)
+		}
+	}
 	return nil
 }
 
+// animation returns a's frames and their durations; a sprite without
+// Frames is one frame of spriteLabDefaultTicks.
+func (a spriteLabAsset) animation() ([][]uint8, []int) {
+	if len(a.Frames) == 0 {
+		return [][]uint8{append([]uint8(nil), a.Pixels...)}, []int{spriteLabDefaultTicks}
+	}
+	return cloneSpriteLabFrames(a.Frames), append([]int(nil), a.FrameTicks...)
+}
+
 func spriteLabCoreLXAssetSnippet(name string, pixels []uint8, width, height int) (string, error) {
 	packed, err := packSpriteLabPixels(pixels, width, height)
 	if err != nil {
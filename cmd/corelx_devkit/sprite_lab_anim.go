@@ -0,0 +1,221 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"strings"
+)
+
+const (
+	spriteLabMaxFrames       = 32
+	spriteLabDefaultTicks    = 8
+	spriteLabMaxTicks        = 255
+	spriteLabDefaultBaseTile = 16
+	spriteLabOnionPrevAlpha  = 0x70
+	spriteLabOnionNextAlpha  = 0x38
+)
+
+// spriteLabNamedSnippet is one asset declaration for the source, keyed by the
+// asset name it declares.
+type spriteLabNamedSnippet struct {
+	Name    string
+	Snippet string
+}
+
+func cloneSpriteLabFrames(frames [][]uint8) [][]uint8 {
+	out := make([][]uint8, len(frames))
+	for i, f := range frames {
+		out[i] = append([]uint8(nil), f...)
+	}
+	return out
+}
+
+// resizeSpriteLabPixels returns pixels (width x height) on a newW x newH
+// canvas, cropped or padded with index 0 at the right and bottom.
+func resizeSpriteLabPixels(pixels []uint8, width, height, newW, newH int) []uint8 {
+	next := make([]uint8, newW*newH)
+	copyW := minInt(width, newW)
+	copyH := minInt(height, newH)
+	for y := 0; y < copyH; y++ {
+		for x := 0; x < copyW; x++ {
+			next[y*newW+x] = pixels[y*width+x]
+		}
+	}
+	return next
+}
+
+// spriteLabFrameAtTick returns the frame shown tick anim.update() calls into
+// a looping animation whose frames last ticks each.
+func spriteLabFrameAtTick(ticks []int, tick int) int {
+	total := 0
+	for _, t := range ticks {
+		total += max(t, 1)
+	}
+	if total == 0 {
+		return 0
+	}
+	tick %= total
+	if tick < 0 {
+		tick += total
+	}
+	for i, t := range ticks {
+		tick -= max(t, 1)
+		if tick < 0 {
+			return i
+		}
+	}
+	return len(ticks) - 1
+}
+
+// overlaySpriteLabOnionSkin blends ghost's pixels into img (as rendered by
+// renderSpriteLabImage for current) at alpha, on the cells where current is
+// index 0 so the frame being edited stays exact.
+func overlaySpriteLabOnionSkin(img *image.NRGBA, current, ghost []uint8, palettes []uint16, paletteBank, spriteW, spriteH, cellPx int, alpha uint8) {
+	if img == nil || cellPx < 1 || len(current) != spriteW*spriteH || len(ghost) != len(current) {
+		return
+	}
+	if paletteBank < 0 || paletteBank >= spriteLabPaletteBanks {
+		paletteBank = 0
+	}
+	bankBase := paletteBank * spriteLabColorsPerBank
+	a := uint32(alpha)
+	bounds := img.Bounds()
+	for i, px := range ghost {
+		if px&0x0F == 0 || current[i]&0x0F != 0 {
+			continue
+		}
+		clr := rgb555ToNRGBA(palettes[bankBase+int(px&0x0F)])
+		sx, sy := i%spriteW, i/spriteW
+		for py := sy * cellPx; py < (sy+1)*cellPx && py < bounds.Dy(); py++ {
+			for pxX := sx * cellPx; pxX < (sx+1)*cellPx && pxX < bounds.Dx(); pxX++ {
+				off := py*img.Stride + pxX*4
+				img.Pix[off] = uint8((uint32(clr.R)*a + uint32(img.Pix[off])*(255-a)) / 255)
+				img.Pix[off+1] = uint8((uint32(clr.G)*a + uint32(img.Pix[off+1])*(255-a)) / 255)
+				img.Pix[off+2] = uint8((uint32(clr.B)*a + uint32(img.Pix[off+2])*(255-a)) / 255)
+			}
+		}
+	}
+}
+
+// spriteLabAnimFrameAssetName names the tile asset holding frame i of the
+// animation name.
+func spriteLabAnimFrameAssetName(name string, i int) string {
+	return fmt.Sprintf("%sFrame%d", name, i)
+}
+
+// spriteLabAnimText returns the anim asset text for frames loaded at
+// consecutive tile indices from baseTile: "tile:ticks" per frame.
+func spriteLabAnimText(baseTile int, ticks []int) (string, error) {
+	if len(ticks) == 0 {
+		return "", fmt.Errorf("animation has no frames")
+	}
+	if baseTile < 0 || baseTile+len(ticks)-1 > 255 {
+		return "", fmt.Errorf("tiles %d-%d are out of range (anim tiles are 0-255)", baseTile, baseTile+len(ticks)-1)
+	}
+	parts := make([]string, len(ticks))
+	for i, t := range ticks {
+		if t < 1 || t > spriteLabMaxTicks {
+			return "", fmt.Errorf("frame %d: ticks must be 1-%d", i+1, spriteLabMaxTicks)
+		}
+		parts[i] = fmt.Sprintf("%d:%d", baseTile+i, t)
+	}
+	return strings.Join(parts, " "), nil
+}
+
+// spriteLabAnimSnippets returns the source for an animation: one tiles8 or
+// tiles16 asset per frame (see spriteLabAnimFrameAssetName), meant to be
+// loaded at baseTile+i, then the anim asset name stepping through them.
+// Frames must be a single hardware sprite tile, 8x8 or 16x16.
+func spriteLabAnimSnippets(name string, frames [][]uint8, ticks []int, width, height, baseTile int) ([]spriteLabNamedSnippet, error) {
+	if width != height || (width != 8 && width != 16) {
+		return nil, fmt.Errorf("animation export needs 8x8 or 16x16 frames (one sprite tile each), not %dx%d", width, height)
+	}
+	if len(frames) != len(ticks) {
+		return nil, fmt.Errorf("%d frames but %d durations", len(frames), len(ticks))
+	}
+	name = sanitizeSpriteLabName(name)
+	animText, err := spriteLabAnimText(baseTile, ticks)
+	if err != nil {
+		return nil, err
+	}
+	assetType := spriteLabAssetTypeForDimensions(width, height)
+	out := make([]spriteLabNamedSnippet, 0, len(frames)+1)
+	for i, frame := range frames {
+		packed, err := packSpriteLabPixels(frame, width, height)
+		if err != nil {
+			return nil, fmt.Errorf("frame %d: %w", i+1, err)
+		}
+		frameName := spriteLabAnimFrameAssetName(name, i)
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "asset %s: %s hex", frameName, assetType)
+		rowBytes := width / 2
+		for row := 0; row < height; row++ {
+			sb.WriteString("\n    ")
+			for col := 0; col < rowBytes; col++ {
+				if col > 0 {
+					sb.WriteByte(' ')
+				}
+				fmt.Fprintf(&sb, "%02X", packed[row*rowBytes+col])
+			}
+		}
+		out = append(out, spriteLabNamedSnippet{Name: frameName, Snippet: sb.String()})
+	}
+	out = append(out, spriteLabNamedSnippet{
+		Name:    name,
+		Snippet: fmt.Sprintf("asset %s: anim text\n    %q", name, animText),
+	})
+	return out, nil
+}
+
+// spriteLabAnimLoadComment is the note placed above a newly added animation:
+// how to load its frames to the tiles the anim asset names.
+func spriteLabAnimLoadComment(name string, frames, baseTile int) string {
+	return fmt.Sprintf("-- Sprite Lab animation %s (%d frames): load frame i with\n-- gfx.load_tiles(ASSET_%sFrame<i>, %d + i), then anim.play(sprite, ASSET_%s)",
+		name, frames, name, baseTile, name)
+}
+
+// upsertSpriteLabAnimIntoSource replaces each snippet's asset in source, or
+// appends it when source does not declare it. comment goes above the first
+// appended snippet. Frame assets left over from a longer earlier export are
+// kept; they cost ROM space only if loaded.
+func upsertSpriteLabAnimIntoSource(source string, snippets []spriteLabNamedSnippet, comment string) (string, string) {
+	updated := strings.TrimRight(source, "\n")
+	var lines []string
+	if updated != "" {
+		lines = strings.Split(updated, "\n")
+	}
+	replaced, added := 0, 0
+	for _, sn := range snippets {
+		repl := strings.Split(sn.Snippet, "\n")
+		if start, end, ok := findSpriteLabAssetBlock(lines, sn.Name); ok {
+			// Keep the blank lines and comments the block finder runs on
+			// into: they belong to what follows.
+			for end > start {
+				trimmed := strings.TrimSpace(lines[end])
+				if trimmed != "" && !strings.HasPrefix(trimmed, "--") {
+					break
+				}
+				end--
+			}
+			next := make([]string, 0, len(lines)-(end-start+1)+len(repl))
+			next = append(next, lines[:start]...)
+			next = append(next, repl...)
+			next = append(next, lines[end+1:]...)
+			lines = next
+			replaced++
+			continue
+		}
+		if added == 0 {
+			if len(lines) > 0 {
+				lines = append(lines, "")
+			}
+			if comment != "" {
+				lines = append(lines, strings.Split(comment, "\n")...)
+			}
+		}
+		lines = append(lines, repl...)
+		added++
+	}
+	summary := fmt.Sprintf("Applied Sprite Lab animation: %d asset(s) updated, %d new", replaced, added)
+	return strings.Join(lines, "\n") + "\n", summary
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"image"
+	"strings"
+	"testing"
+)
+
+func TestSpriteLabFrameAtTickLoops(t *testing.T) {
+	ticks := []int{2, 1, 3}
+	want := []int{0, 0, 1, 2, 2, 2, 0, 0, 1}
+	for tick, frame := range want {
+		if got := spriteLabFrameAtTick(ticks, tick); got != frame {
+			t.Errorf("tick %d: frame %d, want %d", tick, got, frame)
+		}
+	}
+}
+
+func TestSpriteLabAnimSnippets(t *testing.T) {
+	frames := [][]uint8{make([]uint8, 64), make([]uint8, 64)}
+	frames[1][0] = 0x3
+	snippets, err := spriteLabAnimSnippets("Walk", frames, []int{8, 4}, 8, 8, 16)
+	if err != nil {
+		t.Fatalf("snippets: %v", err)
+	}
+	if len(snippets) != 3 {
+		t.Fatalf("got %d snippets, want 2 frames + anim", len(snippets))
+	}
+	if snippets[1].Name != "WalkFrame1" || !strings.HasPrefix(snippets[1].Snippet, "asset WalkFrame1: tiles8 hex\n    03 00 00 00") {
+		t.Errorf("frame 1 snippet = %q", snippets[1].Snippet)
+	}
+	if got, want := snippets[2].Snippet, "asset Walk: anim text\n    \"16:8 17:4\""; got != want {
+		t.Errorf("anim snippet = %q, want %q", got, want)
+	}
+
+	if _, err := spriteLabAnimSnippets("Walk", [][]uint8{make([]uint8, 24*24)}, []int{8}, 24, 24, 16); err == nil {
+		t.Error("24x24 frames exported; anim frames must be one sprite tile")
+	}
+	if _, err := spriteLabAnimSnippets("Walk", frames, []int{8, 0}, 8, 8, 16); err == nil {
+		t.Error("a 0-tick frame exported")
+	}
+	if _, err := spriteLabAnimSnippets("Walk", frames, []int{8, 8}, 8, 8, 255); err == nil {
+		t.Error("frames past tile 255 exported")
+	}
+}
+
+func TestUpsertSpriteLabAnimIntoSource(t *testing.T) {
+	frames := [][]uint8{make([]uint8, 64), make([]uint8, 64)}
+	snippets, err := spriteLabAnimSnippets("Walk", frames, []int{8, 8}, 8, 8, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	source := "function Start()\n    wait_vblank()\n"
+	next, summary := upsertSpriteLabAnimIntoSource(source, snippets, spriteLabAnimLoadComment("Walk", 2, 16))
+	if !strings.Contains(summary, "0 asset(s) updated, 3 new") {
+		t.Errorf("first apply summary = %q", summary)
+	}
+	if !strings.HasPrefix(next, source+"\n-- Sprite Lab animation Walk") {
+		t.Errorf("animation not appended after the source:\n%s", next)
+	}
+
+	frames[0][0] = 0x5
+	snippets, err = spriteLabAnimSnippets("Walk", frames, []int{8, 2}, 8, 8, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, summary := upsertSpriteLabAnimIntoSource(next, snippets, spriteLabAnimLoadComment("Walk", 2, 16))
+	if !strings.Contains(summary, "3 asset(s) updated, 0 new") {
+		t.Errorf("second apply summary = %q", summary)
+	}
+	if strings.Count(again, "asset Walk") != 3 || strings.Count(again, "-- Sprite Lab animation") != 1 {
+		t.Errorf("re-apply duplicated blocks:\n%s", again)
+	}
+	if !strings.Contains(again, "asset WalkFrame0: tiles8 hex\n    05 00") || !strings.Contains(again, `"16:8 17:2"`) {
+		t.Errorf("re-apply did not update the assets:\n%s", again)
+	}
+}
+
+func TestSpriteLabAnimationRoundTrip(t *testing.T) {
+	frames := [][]uint8{make([]uint8, 64), make([]uint8, 64), make([]uint8, 64)}
+	frames[2][63] = 0xF
+	data, err := marshalSpriteLabAnimation("Walk", frames, []int{8, 6, 4}, 8, 8, 2, defaultSpriteLabPaletteData())
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	asset, err := unmarshalSpriteLabAsset(data)
+	if err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	gotFrames, gotTicks := asset.animation()
+	if len(gotFrames) != 3 || gotFrames[2][63] != 0xF || gotTicks[1] != 6 {
+		t.Fatalf("round trip frames/ticks = %d frames, %v", len(gotFrames), gotTicks)
+	}
+
+	// A single sprite stays a plain sprite file.
+	single, err := marshalSpriteLabAnimation("Walk", frames[:1], []int{8}, 8, 8, 0, defaultSpriteLabPaletteData())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(single), "frames") {
+		t.Errorf("single-frame sprite wrote frames: %s", single)
+	}
+
+	asset.FrameTicks = asset.FrameTicks[:2]
+	if err := validateSpriteLabAsset(asset); err == nil {
+		t.Error("asset with fewer durations than frames validated")
+	}
+}
+
+func TestOverlaySpriteLabOnionSkinOnlyOnTransparentCells(t *testing.T) {
+	palettes := defaultSpriteLabPaletteData()
+	current := []uint8{1, 0, 0, 0}
+	ghost := []uint8{2, 2, 0, 0}
+	img := renderSpriteLabImage(current, palettes, 0, 2, 2, 4, -1, -1, false, true).(*image.NRGBA)
+	before := append([]uint8(nil), img.Pix...)
+	overlaySpriteLabOnionSkin(img, current, ghost, palettes, 0, 2, 2, 4, spriteLabOnionPrevAlpha)
+
+	cellChanged := func(cx, cy int) bool {
+		off := (cy*4)*img.Stride + (cx*4+1)*4
+		return img.Pix[off] != before[off] || img.Pix[off+1] != before[off+1] || img.Pix[off+2] != before[off+2]
+	}
+	if cellChanged(0, 0) {
+		t.Error("onion skin drew over a painted pixel")
+	}
+	if !cellChanged(1, 0) {
+		t.Error("onion skin not drawn on a transparent pixel")
+	}
+	if cellChanged(0, 1) {
+		t.Error("onion skin drew where the ghost frame is transparent")
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"path/filepath"
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+	"nitro-core-dx/internal/emulator"
+)
+
+// vramExportPaletteSwatch is the swatch size of exported CGRAM strips.
+const vramExportPaletteSwatch = 16
+
+// showVRAMExportDialog previews the current VRAM as a tile sheet and saves
+// it as a PNG. The same decoding backs cmd/vram_ripper.
+func (s *devKitState) showVRAMExportDialog() {
+	if !s.backend.Snapshot().Loaded {
+		s.setStatus("No active project build")
+		return
+	}
+	opts := emulator.TileSheetOptions{TileSize: 8}
+
+	preview := canvas.NewImageFromImage(image.NewNRGBA(image.Rect(0, 0, 1, 1)))
+	preview.FillMode = canvas.ImageFillContain
+	preview.ScaleMode = canvas.ImageScalePixels
+	preview.SetMinSize(fyne.NewSize(512, 384))
+	info := widget.NewLabel("")
+	var sheet *image.NRGBA
+	render := func() {
+		img, err := s.backend.ExportTileSheet(opts)
+		if err != nil {
+			info.SetText(err.Error())
+			return
+		}
+		sheet = img
+		preview.Image = img
+		preview.Refresh()
+		b := img.Bounds()
+		info.SetText(fmt.Sprintf("%dx%d px, %d tiles of %dx%d, palette %d",
+			b.Dx(), b.Dy(), (b.Dx()/opts.TileSize)*(b.Dy()/opts.TileSize), opts.TileSize, opts.TileSize, opts.Palette))
+	}
+
+	sizeSelect := widget.NewRadioGroup([]string{"8x8", "16x16"}, func(v string) {
+		opts.TileSize = 8
+		if v == "16x16" {
+			opts.TileSize = 16
+		}
+		render()
+	})
+	sizeSelect.Horizontal = true
+	var palettes []string
+	for i := 0; i < 16; i++ {
+		palettes = append(palettes, strconv.Itoa(i))
+	}
+	paletteSelect := widget.NewSelect(palettes, func(v string) {
+		opts.Palette, _ = strconv.Atoi(v)
+		render()
+	})
+	transparent := widget.NewCheck("Color 0 transparent", func(v bool) {
+		opts.TransparentZero = v
+		render()
+	})
+	keepBlank := widget.NewCheck("Keep trailing blank tiles", func(v bool) {
+		opts.KeepBlank = v
+		render()
+	})
+	sizeSelect.SetSelected("8x8")
+	paletteSelect.SetSelected("0")
+
+	saveBtn := widget.NewButton("Save PNG...", func() {
+		if sheet != nil {
+			s.savePNGDialog(sheet, fmt.Sprintf("vram_tiles_%dx%d_pal%d.png", opts.TileSize, opts.TileSize, opts.Palette), "VRAM tile sheet")
+		}
+	})
+	saveBtn.Importance = widget.HighImportance
+
+	controls := container.NewHBox(
+		widget.NewLabel("Tiles"), sizeSelect,
+		widget.NewLabel("Palette"), paletteSelect,
+		transparent, keepBlank,
+	)
+	content := container.NewBorder(controls, container.NewBorder(nil, nil, nil, saveBtn, info), nil, nil,
+		container.NewScroll(preview))
+	d := dialog.NewCustom("Export VRAM Tiles", "Close", content, s.window)
+	d.Resize(fyne.NewSize(760, 560))
+	d.Show()
+}
+
+// exportCGRAMPalette saves the current CGRAM as a 16x16 swatch PNG, one row
+// per palette.
+func (s *devKitState) exportCGRAMPalette() {
+	img, err := s.backend.ExportPaletteStrip(vramExportPaletteSwatch)
+	if err != nil {
+		s.setStatus("Export palette: " + err.Error())
+		return
+	}
+	s.savePNGDialog(img, "cgram_palette.png", "CGRAM palette")
+}
+
+func (s *devKitState) savePNGDialog(img image.Image, fileName, what string) {
+	fd := dialog.NewFileSave(func(wc fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, s.window)
+			return
+		}
+		if wc == nil {
+			return
+		}
+		defer wc.Close()
+		if err := png.Encode(wc, img); err != nil {
+			dialog.ShowError(err, s.window)
+			return
+		}
+		path := uriPath(wc.URI())
+		if path != "" {
+			s.settings.LastSourceDir = filepath.Dir(path)
+			s.persistSettings()
+		}
+		s.appendBuildOutput(fmt.Sprintf("Exported %s to %s", what, path))
+		s.setStatus("Exported " + what)
+	}, s.window)
+	fd.SetFilter(storage.NewExtensionFileFilter([]string{".png"}))
+	if loc := dialogListableForDir(s.settings.LastSourceDir); loc != nil {
+		fd.SetLocation(loc)
+	}
+	fd.SetFileName(fileName)
+	fd.Show()
+}
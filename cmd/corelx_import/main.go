@@ -4,6 +4,11 @@
 // compiler only ever parses text.
 //
 // Usage: corelx_import <image.png> <AssetName> <planeSize:32|64|128> <paletteBank> [out.corelxasset]
+//
+//	corelx_import bg8 <image.png> <AssetName> <indexed|direct> [out.cxasset]
+//
+// The bg8 form converts the PNG into an 8bpp background image instead (tiles,
+// tilemap and, for indexed, a 256-color palette) for bg.load_image.
 package main
 
 import (
@@ -19,6 +24,10 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "bg8" {
+		importBG8(os.Args[2:])
+		return
+	}
 	if len(os.Args) < 5 {
 		fmt.Fprintln(os.Stderr, "Usage: corelx_import <image.png> <AssetName> <planeSize:32|64|128> <paletteBank> [out]")
 		os.Exit(1)
@@ -40,17 +49,7 @@ func main() {
 		os.Exit(1)
 	}
 
-	f, err := os.Open(imgPath)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "open %s: %v\n", imgPath, err)
-		os.Exit(1)
-	}
-	defer f.Close()
-	img, _, err := image.Decode(f)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "decode %s: %v\n", imgPath, err)
-		os.Exit(1)
-	}
+	img := decodeImage(imgPath)
 
 	asset, err := emulator.BuildBitmapMatrixPlaneAssetFromImage(img, 0, sizeMode, uint8(palBank))
 	if err != nil {
@@ -97,3 +96,55 @@ func main() {
 		fmt.Fprintf(os.Stderr, "[%d palette entries, %d bitmap bytes]\n", len(palette), len(bitmap))
 	}
 }
+
+func decodeImage(path string) image.Image {
+	f, err := os.Open(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "open %s: %v\n", path, err)
+		os.Exit(1)
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "decode %s: %v\n", path, err)
+		os.Exit(1)
+	}
+	return img
+}
+
+// importBG8 handles `corelx_import bg8 <image.png> <AssetName> <indexed|direct> [out]`.
+func importBG8(args []string) {
+	if len(args) < 3 {
+		fmt.Fprintln(os.Stderr, "Usage: corelx_import bg8 <image.png> <AssetName> <indexed|direct> [out]")
+		os.Exit(1)
+	}
+	imgPath, name := args[0], args[1]
+	var format uint8
+	switch args[2] {
+	case "indexed":
+		format = ppucore.BGFormatIndexed8
+	case "direct":
+		format = ppucore.BGFormatDirect8
+	default:
+		fmt.Fprintln(os.Stderr, "format must be indexed or direct")
+		os.Exit(1)
+	}
+
+	bg, err := emulator.BuildBG8ImageFromImage(decodeImage(imgPath), format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "convert: %v\n", err)
+		os.Exit(1)
+	}
+	out := bg.CxAsset(name, fmt.Sprintf("Generated by corelx_import from %s (bg8 %s, do not hand-edit the data block)", imgPath, args[2]))
+	summary := fmt.Sprintf("%s, tilemap at 0x%04X", bg.PackSummary(), bg.TilemapBase)
+	if len(args) >= 4 {
+		if err := os.WriteFile(args[3], []byte(out), 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "write: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "Wrote %s: %s\n", args[3], summary)
+	} else {
+		fmt.Print(out)
+		fmt.Fprintf(os.Stderr, "[%s]\n", summary)
+	}
+}
@@ -2,24 +2,29 @@ package main
 
 import (
 	"bufio"
+	"bytes"
+	"flag"
 	"fmt"
 	"os"
 	"strconv"
 	"strings"
 
+	"nitro-core-dx/internal/corelx"
 	"nitro-core-dx/internal/debug"
 	"nitro-core-dx/internal/emulator"
 )
 
 // Interactive debugger for Nitro Core DX ROMs
 func main() {
-	if len(os.Args) < 2 {
-		fmt.Println("Usage: debugger <rom.rom>")
+	srcPath := flag.String("src", "", "CoreLX source the ROM was built from, for function names in the call stack")
+	flag.Parse()
+	if flag.NArg() < 1 {
+		fmt.Println("Usage: debugger [-src game.corelx] <rom.rom>")
 		fmt.Println("Interactive debugger for Nitro Core DX ROMs")
 		os.Exit(1)
 	}
 
-	romPath := os.Args[1]
+	romPath := flag.Arg(0)
 	romData, err := os.ReadFile(romPath)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error reading ROM: %v\n", err)
@@ -34,7 +39,17 @@ func main() {
 	logger.SetMinLevel(debug.LogLevelDebug)
 
 	emu := emulator.NewEmulatorWithLogger(logger)
+	emu.EnableWriteTracking(true)
 	dbg := debug.NewDebugger()
+	emu.Debugger = dbg
+	var syms emulator.SymbolTable
+	if *srcPath != "" {
+		syms, err = symbolsFromSource(*srcPath, romData)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: no symbols: %v\n", err)
+		}
+	}
+	emu.EnableCallStack(syms)
 
 	// Load ROM
 	if err := emu.LoadROM(romData); err != nil {
@@ -151,6 +166,14 @@ func main() {
 			}
 			handleMemory(emu, args)
 
+		case "lastwrite", "lw":
+			if len(args) < 1 {
+				fmt.Println("Usage: lastwrite <bank>:<offset> | vram:<offset> | oam:<offset>")
+				fmt.Println("Example: lastwrite 0:0x1F00")
+				continue
+			}
+			handleLastWrite(emu, args[0])
+
 		case "stack":
 			printStack(emu)
 
@@ -176,7 +199,19 @@ func main() {
 			printVariables(dbg)
 
 		case "callstack", "cs":
-			printCallStack(dbg)
+			printCallStack(emu, syms)
+
+		case "log":
+			handleLog(logger, args)
+
+		case "eval", "e":
+			code := strings.TrimSpace(line[len(parts[0]):])
+			if code == "" {
+				fmt.Println("Usage: eval <corelx>")
+				fmt.Println("Example: eval oam.write_sprite_data(0, 50, 50, 1, 0x10, 1)")
+				continue
+			}
+			handleEval(emu, code)
 
 		case "run":
 			emu.Start()
@@ -223,13 +258,16 @@ func printHelp() {
 	fmt.Println("  pause                    - Pause execution")
 	fmt.Println("  registers                - Show CPU registers")
 	fmt.Println("  memory <bank>:<offset>   - Show memory contents")
+	fmt.Println("  lastwrite <addr>         - Show who last wrote a WRAM, vram: or oam: byte")
 	fmt.Println("  stack                    - Show stack contents")
 	fmt.Println("  oam                      - Show OAM (sprite) data")
 	fmt.Println("  ppu                      - Show PPU state")
 	fmt.Println("  watch <expr>              - Add watch expression")
 	fmt.Println("  watches                  - Show watch expressions")
 	fmt.Println("  variables                - Show tracked variables")
-	fmt.Println("  callstack                - Show call stack")
+	fmt.Println("  callstack                - Show call stack (names with -src)")
+	fmt.Println("  eval <corelx>            - Run a CoreLX statement or expression on the paused machine")
+	fmt.Println("  log [<comp|all> <level|on|off>] - Show or change per-component logging (e.g., log cpu debug)")
 	fmt.Println("  frame                    - Run one frame")
 	fmt.Println("  status                   - Show emulator status")
 	fmt.Println("  clear <bp|watches>        - Clear breakpoints or watches")
@@ -300,6 +338,90 @@ func runUntilBreakpoint(emu *emulator.Emulator, dbg *debug.Debugger) {
 	}
 }
 
+// handleLog shows the logger's per-component settings, or changes one
+// component's (or every component's) level or enablement on the live
+// logger. Setting a level also enables the component.
+func handleLog(logger *debug.Logger, args []string) {
+	if len(args) == 0 {
+		printLogSettings(logger)
+		return
+	}
+	if len(args) != 2 {
+		fmt.Println("Usage: log <component|all> <none|error|warning|info|debug|trace|on|off>")
+		fmt.Println("Example: log cpu debug")
+		return
+	}
+
+	components := debug.Components
+	if !strings.EqualFold(args[0], "all") {
+		c, err := debug.ParseComponent(args[0])
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		components = []debug.Component{c}
+	}
+
+	setting := strings.ToLower(args[1])
+	var level debug.LogLevel
+	if setting != "on" && setting != "off" {
+		var err error
+		if level, err = debug.ParseLogLevel(setting); err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
+	for _, c := range components {
+		switch setting {
+		case "on":
+			logger.SetComponentEnabled(c, true)
+		case "off":
+			logger.SetComponentEnabled(c, false)
+		default:
+			logger.SetComponentLevel(c, level)
+			logger.SetComponentEnabled(c, true)
+		}
+	}
+	printLogSettings(logger)
+}
+
+func printLogSettings(logger *debug.Logger) {
+	fmt.Println("Logging:")
+	for _, c := range debug.Components {
+		state := "off"
+		if logger.IsComponentEnabled(c) {
+			state = "on"
+		}
+		fmt.Printf("  %-7s %-3s  %s\n", c, state, logger.ComponentLevel(c))
+	}
+}
+
+// evalMaxInstructions bounds one eval, so an endless loop fails instead of
+// hanging the debugger.
+const evalMaxInstructions = 2_000_000
+
+func handleEval(emu *emulator.Emulator, code string) {
+	if !emu.Paused {
+		fmt.Println("Pause execution before eval")
+		return
+	}
+	prog, err := corelx.CompileImmediate(code)
+	if err != nil {
+		fmt.Printf("Compile error: %v\n", err)
+		return
+	}
+	run, err := emu.RunImmediate(prog.ROMBytes, evalMaxInstructions)
+	if err != nil {
+		fmt.Printf("Eval failed: %v\n", err)
+		return
+	}
+	if prog.HasValue {
+		fmt.Printf("= 0x%04X (%d, signed %d)\n", run.R0, run.R0, int16(run.R0))
+		return
+	}
+	fmt.Printf("ok (%d instructions, %d cycles)\n", run.Instructions, run.Cycles)
+}
+
 func printRegisters(emu *emulator.Emulator) {
 	state := emu.CPU.State
 	fmt.Printf("CPU Registers:\n")
@@ -349,6 +471,28 @@ func handleMemory(emu *emulator.Emulator, args []string) {
 	}
 }
 
+func handleLastWrite(emu *emulator.Emulator, addrStr string) {
+	space, addr, err := emulator.ParseMemoryAddress(addrStr)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	writes, err := emu.LastWrites(space, addr)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	where := space.FormatAddress(addr)
+	if len(writes) == 0 {
+		fmt.Printf("No writes to %s since the last reset\n", where)
+		return
+	}
+	fmt.Printf("Last writes to %s (newest first):\n", where)
+	for _, w := range writes {
+		fmt.Printf("  %s\n", w)
+	}
+}
+
 func printStack(emu *emulator.Emulator) {
 	sp := emu.CPU.State.SP
 	fmt.Printf("Stack (SP: 0x%04X):\n", sp)
@@ -365,21 +509,14 @@ func printOAM(emu *emulator.Emulator) {
 	fmt.Println("OAM (Object Attribute Memory):")
 	for i := 0; i < 8; i++ {
 		offset := i * 6
-		xLo := emu.PPU.OAM[offset]
-		xHi := emu.PPU.OAM[offset+1]
 		y := emu.PPU.OAM[offset+2]
 		tile := emu.PPU.OAM[offset+3]
 		attr := emu.PPU.OAM[offset+4]
 		ctrl := emu.PPU.OAM[offset+5]
-		
-		x := uint16(xLo) | (uint16(xHi&0x01) << 8)
-		if (xHi & 0x80) != 0 {
-			x |= 0xFE00 // Sign extend
-		}
-		
+
 		enabled := (ctrl & 0x01) != 0
 		fmt.Printf("  Sprite %d: X=%d Y=%d Tile=0x%02X Attr=0x%02X Ctrl=0x%02X (enabled=%v)\n",
-			i, int16(x), y, tile, attr, ctrl, enabled)
+			i, emu.PPU.SpriteX(i), y, tile, attr, ctrl, enabled)
 	}
 }
 
@@ -420,18 +557,44 @@ func printVariables(dbg *debug.Debugger) {
 	}
 }
 
-func printCallStack(dbg *debug.Debugger) {
-	stack := dbg.GetCallStack()
+func printCallStack(emu *emulator.Emulator, syms emulator.SymbolTable) {
+	st := emu.CPU.State
+	pc := fmt.Sprintf("%02X:%04X", st.PCBank, st.PCOffset)
+	if name := syms.Lookup(st.PCBank, st.PCOffset); name != "" {
+		pc = name + " (" + pc + ")"
+	}
+	fmt.Printf("PC: %s\n", pc)
+
+	stack := emu.CallStack()
 	if len(stack) == 0 {
 		fmt.Println("Call stack is empty")
 		return
 	}
-
-	fmt.Println("Call stack:")
+	fmt.Println("Call stack (innermost first):")
 	for i := len(stack) - 1; i >= 0; i-- {
-		frame := stack[i]
-		fmt.Printf("  #%d: %s at %02X:%04X\n", len(stack)-i-1, frame.FunctionName, frame.Bank, frame.Offset)
+		fmt.Printf("  #%d: %s\n", len(stack)-i-1, emulator.FormatCallFrame(stack[i]))
+	}
+}
+
+// symbolsFromSource compiles the CoreLX program at path and returns its
+// function symbols if it builds exactly the ROM being debugged, with or
+// without the boot splash.
+func symbolsFromSource(path string, romData []byte) (emulator.SymbolTable, error) {
+	for _, skipSplash := range []bool{false, true} {
+		res, err := corelx.CompileProject(path, &corelx.CompileOptions{EmitROMBytes: true, SkipBootSplash: skipSplash})
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(res.ROMBytes, romData) {
+			continue
+		}
+		syms := make([]emulator.Symbol, len(res.Functions))
+		for i, f := range res.Functions {
+			syms[i] = emulator.Symbol{Name: f.Name, Bank: f.Bank, Offset: f.Offset}
+		}
+		return emulator.NewSymbolTable(syms), nil
 	}
+	return nil, fmt.Errorf("%s does not build this ROM", path)
 }
 
 func printStatus(emu *emulator.Emulator) {
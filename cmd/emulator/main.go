@@ -4,35 +4,146 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"nitro-core-dx/internal/cpu"
 	"nitro-core-dx/internal/debug"
 	"nitro-core-dx/internal/emulator"
+	"nitro-core-dx/internal/fileassoc"
+	"nitro-core-dx/internal/harness"
+	"nitro-core-dx/internal/i18n"
+	"nitro-core-dx/internal/input"
+	"nitro-core-dx/internal/memory"
+	"nitro-core-dx/internal/ppu"
 	"nitro-core-dx/internal/ui"
 )
 
 func main() {
 	romPath := flag.String("rom", "", "Path to ROM file")
+	openTarget := flag.String("open", "", "ROM or CoreLX source to open (path or file:// URL, as passed by desktop launchers)")
+	registerTypes := flag.Bool("register-file-types", false, "Register this binary as the desktop handler for .rom/.corelx files and exit")
 	unlimited := flag.Bool("unlimited", false, "Run at unlimited speed (no frame limit)")
 	scale := flag.Int("scale", 3, "Display scale (1-6)")
+	pacing := flag.String("pacing", "sleep", "Frame pacing: sleep (least CPU), hybrid (sleep then spin for exact frame times) or vsync (tick at the display's refresh rate)")
+	frameSkip := flag.Int("frameskip", 3, "Most frames to leave undrawn per frame drawn when the host cannot keep up (0-9, 0 draws every frame)")
+	osd := flag.Bool("osd", false, "Show the performance OSD (speed %, frame time, audio queue)")
 	audioBackend := flag.String("audio-backend", "", "Audio backend override: ymfm (default: ymfm)")
 	enableLogging := flag.Bool("log", false, "Enable logging (disabled by default)")
 	cycleLogFile := flag.String("cyclelog", "", "Enable cycle-by-cycle logging to file (e.g., -cyclelog debug.log)")
 	maxCycles := flag.Uint64("maxcycles", 100000, "Maximum cycles to log (default: 100000, 0 = unlimited)")
 	startCycle := flag.Uint64("cyclestart", 0, "Start logging after this many cycles (default: 0 = start immediately)")
+	noPersist := flag.Bool("no-persist", false, "Disable the host-side save store (PERSIST_* mailbox), as on real hardware")
+	persistDir := flag.String("persist-dir", "", "Directory for per-ROM save data (default: <user config dir>/nitro-core-dx/persist)")
+	ramInit := flag.String("ram-init", "", "Fill work RAM and VRAM with a pattern at boot and on power cycles: a5, 00, ff, random or random:SEED")
+	fuzzStartup := flag.String("fuzz-startup", "", "Randomize RAM, VRAM, CGRAM, OAM, BG scroll and CPU registers at every power-on: random or a SEED to reproduce a run")
+	uninitReads := flag.Bool("uninit-reads", false, "Report reads of work RAM the ROM never wrote, with the PC responsible")
+	inputLatch := flag.String("input-latch", "strobe", "Controller data reads: strobe (state captured at the latch write, as hardware) or immediate (live state)")
+	inputPoll := flag.Bool("input-poll", false, "Read the keyboard when the ROM latches the controller instead of once per UI tick")
+	turbo := flag.String("turbo", "", "Comma-separated buttons that auto-fire while held (e.g. a,b)")
+	turboHz := flag.Int("turbo-hz", 15, "Turbo rate in presses per second (1-30)")
+	strictBus := flag.Bool("strict-bus", false, "Log every access to an unmapped address (reads return open bus)")
+	busContention := flag.Bool("bus-contention", false, "Charge the CPU hardware wait cycles for PPU/APU/FM register accesses and halt it during DMA, so cycle budgets match the hardware")
+	vramAccess := flag.String("vram-access", "open", "VRAM/CGRAM writes during active display: open (allowed), warn (allowed and logged) or strict (dropped, as hardware)")
+	debugConsole := flag.Bool("debug-console", false, "Print the ROM's debug console output (debug.print) to stdout")
+	chromeTrace := flag.String("chrome-trace", "", "Record frames, CPU slices, DMA, audio and UI ticks, and write them as Chrome trace-event JSON (open in ui.perfetto.dev) on exit")
+	recordMovie := flag.String("record-movie", "", "Record a movie (.ncm: input, start state and sync checkpoints) from power-on and save it on exit")
+	playMovie := flag.String("play-movie", "", "Play a movie (.ncm) recorded with this ROM instead of the keyboard")
+	movieAuthor := flag.String("movie-author", "", "Author stored in the movie written by -record-movie")
+	movieComment := flag.String("movie-comment", "", "Comment stored in the movie written by -record-movie")
+	playlistDir := flag.String("playlist", "", "Jukebox mode: load every .rom/.corelx in this directory and step through them with Ctrl+PageDown / Ctrl+PageUp")
+	framePlugins := flag.String("frame-plugins", "", "Comma-separated overlays drawn over each finished frame: "+strings.Join(emulator.BuiltinFramePlugins(), ", "))
+	priorityView := flag.Bool("priority-view", false, "Tint each pixel by the layer that won priority resolution (BG0 red, BG1 green, BG2 blue, BG3 yellow, sprites magenta, backdrop gray)")
+	border := flag.Int("border", 0, "Draw an overscan border this many pixels wide around the picture, in the ROM's BORDER_COLOR (0-64)")
+	crop := flag.Int("crop", 0, "Crop this many pixels of overscan from every edge of the picture (0-32)")
+	timing := flag.String("timing", "auto", "Video timing: auto (as the ROM header says), 60hz or 50hz")
+	lang := flag.String("lang", "", "UI language: "+strings.Join(i18n.Locales(), ", ")+" (default: from NCDX_LANG, LC_ALL, LC_MESSAGES or LANG)")
+	playlistInterval := flag.Duration("playlist-interval", 0, "With -playlist, advance to the next ROM after this long (e.g. 30s; 0 = only on the hotkeys)")
 	flag.Parse()
 
+	if *registerTypes {
+		if err := registerFileTypes(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	var playlist *fileassoc.Playlist
+	if *playlistDir != "" {
+		if *romPath != "" || *openTarget != "" || *recordMovie != "" || *playMovie != "" {
+			fmt.Fprintf(os.Stderr, "Error: -playlist cannot be combined with -rom, -open or movies\n")
+			os.Exit(1)
+		}
+		var err error
+		if playlist, err = fileassoc.LoadPlaylist(*playlistDir); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		*romPath = playlist.Current()
+	}
+
+	// -rom wins; otherwise take --open (desktop launchers) or a bare argument.
+	target := *romPath
+	if target == "" {
+		target = *openTarget
+	}
+	if target == "" && flag.NArg() > 0 {
+		target = flag.Arg(0)
+	}
+	if target != "" {
+		path, err := fileassoc.ParseOpenTarget(target)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		*romPath = path
+	}
+
 	if *romPath == "" {
 		fmt.Println("Usage: nitro-core-dx -rom <path-to-rom>")
-		fmt.Println("  -rom <path>      Path to ROM file (.rom)")
+		fmt.Println("  -rom <path>      Path to ROM file (.rom, or a .zip holding one)")
+		fmt.Println("  -open <path|url> ROM or CoreLX source (.corelx builds first); file:// URLs accepted")
+		fmt.Println("  -register-file-types  Register .rom/.corelx file associations (freedesktop) and exit")
 		fmt.Println("  -unlimited       Run at unlimited speed")
 		fmt.Println("  -scale <1-6>     Display scale (default: 3)")
+		fmt.Println("  -osd             Show the performance OSD")
+		fmt.Println("  -pacing <m>      Frame pacing: sleep (default), hybrid or vsync")
+		fmt.Println("  -frameskip <N>   Most frames skipped per frame drawn when the host lags (default: 3, 0 = off)")
 		fmt.Println("  -audio-backend   Audio backend override: ymfm (default: ymfm)")
 		fmt.Println("  -log             Enable logging (disabled by default)")
 		fmt.Println("  -cyclelog <file> Enable cycle-by-cycle logging to file")
 		fmt.Println("  -maxcycles <N>   Maximum cycles to log (default: 100000, 0 = unlimited)")
 		fmt.Println("  -cyclestart <N>  Start logging after N cycles (default: 0 = start immediately)")
+		fmt.Println("  -ram-init <p>    RAM/VRAM fill at boot and power cycle: a5, 00, ff, random[:SEED]")
+		fmt.Println("  -fuzz-startup <s> Random RAM, PPU and register state at every power-on: random or SEED")
+		fmt.Println("  -uninit-reads    Report reads of never-written work RAM on exit")
+		fmt.Println("  -strict-bus      Log every unmapped memory access")
+		fmt.Println("  -bus-contention  Hardware wait cycles for I/O register access; DMA halts the CPU")
+		fmt.Println("  -vram-access <m> Writes during active display: open (default), warn or strict")
+		fmt.Println("  -input-latch <m> Controller reads: strobe (default) or immediate")
+		fmt.Println("  -input-poll      Sample the keyboard at the ROM's latch strobe (lower latency)")
+		fmt.Println("  -turbo <list>    Buttons that auto-fire while held, e.g. a,b")
+		fmt.Println("  -turbo-hz <N>    Turbo rate in presses per second (default: 15)")
+		fmt.Println("  -chrome-trace <file> Write a Perfetto/Chrome performance trace on exit")
+		fmt.Println("  -record-movie <file> Record a .ncm movie from power-on, saved on exit")
+		fmt.Println("  -play-movie <file>   Play a .ncm movie and report desyncs")
+		fmt.Println("  -playlist <dir>      Cycle through a directory of ROMs (Ctrl+PageDown/PageUp)")
+		fmt.Println("  -playlist-interval <d> Auto-advance the playlist, e.g. 30s")
+		fmt.Println("  -frame-plugins <list> Frame overlays, e.g. sprite-boxes,wram-heatmap")
+		fmt.Println("  -priority-view   Tint pixels by the layer that won priority (debug render mode)")
+		fmt.Println("  -timing <m>      Video timing: auto (ROM header, default), 60hz or 50hz")
+		fmt.Println("  -border <N>      Overscan border in the ROM's border color, N pixels wide")
+		fmt.Println("  -crop <N>        Crop N pixels of overscan from each edge")
+		fmt.Println("  -lang <tag>      UI language: " + strings.Join(i18n.Locales(), ", ") + " (default: from the environment)")
+		os.Exit(1)
+	}
+
+	if *lang == "" {
+		*lang = i18n.Detect()
+	}
+	if err := i18n.SetLocale(*lang); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: -lang: %v\n", err)
 		os.Exit(1)
 	}
 
@@ -45,9 +156,35 @@ func main() {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
+	// A movie replays only when every run starts from the same machine, so
+	// saved data, RAM fill patterns, startup fuzzing and mid-frame polling
+	// are left out.
+	movieActive := *recordMovie != "" || *playMovie != ""
+	if *recordMovie != "" && *playMovie != "" {
+		fmt.Fprintf(os.Stderr, "Error: -record-movie and -play-movie cannot be combined\n")
+		os.Exit(1)
+	}
+	if movieActive && (*ramInit != "" || *fuzzStartup != "" || *inputPoll) {
+		fmt.Fprintf(os.Stderr, "Error: -ram-init, -fuzz-startup and -input-poll cannot be used with movies\n")
+		os.Exit(1)
+	}
+	var movie *harness.Movie
+	if *playMovie != "" {
+		var err error
+		if movie, err = harness.LoadMovie(*playMovie); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
 
-	// Read ROM file
-	romData, err := os.ReadFile(*romPath)
+	// Read ROM file (CoreLX sources are built first, zipped games extracted)
+	var romData []byte
+	var err error
+	if k := fileassoc.Classify(*romPath); k == fileassoc.KindSource || k == fileassoc.KindBundle {
+		romData, err = fileassoc.ReadROM(*romPath)
+	} else {
+		romData, err = os.ReadFile(*romPath)
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error reading ROM file: %v\n", err)
 		os.Exit(1)
@@ -79,6 +216,122 @@ func main() {
 		emu = emulator.NewEmulator()
 	}
 
+	// Host-side save store, keyed by ROM hash. Enabled before loading so
+	// the ROM's saved values are available from its first frame.
+	if !*noPersist && !movieActive {
+		dir := *persistDir
+		if dir == "" {
+			dir = defaultPersistDir()
+		}
+		if dir != "" {
+			if err := emu.EnablePersistence(dir); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+		}
+	}
+
+	if *ramInit != "" {
+		pattern, err := memory.ParseRAMPattern(*ramInit)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		emu.SetRAMPattern(pattern)
+	}
+	if *fuzzStartup != "" {
+		seed, err := emulator.ParseStartupFuzzSeed(*fuzzStartup)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		emu.EnableStartupFuzz(seed)
+		fmt.Printf("Startup fuzz: seed %d (each power cycle uses the next seed; -fuzz-startup %d repeats this run)\n", seed, seed)
+	}
+	emu.EnableUninitReadCheck(*uninitReads)
+	latchMode, err := input.ParseLatchMode(*inputLatch)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	emu.Input.Mode = latchMode
+	turboMask, err := input.ParseButtons(*turbo)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: -turbo: %v\n", err)
+		os.Exit(1)
+	}
+	if *strictBus {
+		emu.Bus.StrictUnmapped = true
+		emu.Logger.SetComponentEnabled(debug.ComponentMemory, true)
+	}
+	vramMode, err := ppu.ParseVRAMAccessMode(*vramAccess)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	emu.PPU.VRAMAccess = vramMode
+	emu.Bus.Contention = *busContention
+	framePacing, err := emulator.ParseFramePacing(*pacing)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if *frameSkip < 0 || *frameSkip > 9 {
+		fmt.Fprintf(os.Stderr, "Error: -frameskip must be 0-9\n")
+		os.Exit(1)
+	}
+	emu.SetFramePacing(framePacing)
+	if vramMode != ppu.VRAMAccessOpen {
+		emu.Logger.SetComponentEnabled(debug.ComponentPPU, true)
+	}
+	if *chromeTrace != "" {
+		emu.EnableTrace(debug.NewTraceRecorder(debug.DefaultTraceEvents))
+	}
+	if movieActive {
+		emu.SetDeterministic(true)
+	}
+	if *priorityView {
+		// Like the frame plugins, the tint changes the framebuffer a movie's
+		// checkpoints hash.
+		if movieActive {
+			fmt.Fprintf(os.Stderr, "Error: -priority-view cannot be combined with movies\n")
+			os.Exit(1)
+		}
+		emu.PPU.PriorityView = true
+	}
+	// Border and crop only change what the window shows, not the
+	// framebuffer movies hash, so they combine with everything.
+	if err := emu.SetDisplayOptions(emulator.DisplayOptions{Border: *border, Crop: *crop}); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: -border/-crop: %v\n", err)
+		os.Exit(1)
+	}
+	if t := strings.ToLower(strings.TrimSpace(*timing)); t != "" && t != "auto" {
+		mode, err := ppu.ParseTiming(t)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: -timing: %v\n", err)
+			os.Exit(1)
+		}
+		// A movie replays at the timing its ROM's header asks for.
+		if movieActive {
+			fmt.Fprintf(os.Stderr, "Error: -timing cannot be combined with movies\n")
+			os.Exit(1)
+		}
+		emu.SetTimingOverride(&mode)
+	}
+	for _, name := range strings.Split(*framePlugins, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		// Overlays change the framebuffer a movie's checkpoints hash.
+		if movieActive {
+			fmt.Fprintf(os.Stderr, "Error: -frame-plugins cannot be combined with movies\n")
+			os.Exit(1)
+		}
+		if err := emu.InstallFramePlugin(name); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: -frame-plugins: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
 	// Load ROM
 	if err := emu.LoadROM(romData); err != nil {
 		fmt.Fprintf(os.Stderr, "Error loading ROM: %v\n", err)
@@ -108,16 +361,31 @@ func main() {
 	fmt.Println("====================")
 	fmt.Printf("ROM loaded: %s\n", *romPath)
 	fmt.Printf("Frame limit: %v\n", !*unlimited)
+	fmt.Printf("Frame pacing: %s, frame-skip up to %d\n", framePacing, *frameSkip)
+	fmt.Printf("Timing: %s\n", emu.Timing())
 	fmt.Printf("Display scale: %dx\n", *scale)
 	fmt.Printf("Audio backend mode: %s\n", effectiveAudioBackendMode())
+	if emu.Persist != nil {
+		fmt.Printf("Save data: %s\n", emu.Persist.Dir)
+		if err := emu.Persist.Err(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
 	fmt.Println("\nStarting emulation...")
 	fmt.Println("\nControls:")
 	fmt.Println("  Arrow Keys / WASD - Move block")
 	fmt.Println("  Z / W - A button (change block color)")
 	fmt.Println("  X - B button (change background color)")
 	fmt.Println("  Space - Pause/Resume")
-	fmt.Println("  Ctrl+R - Reset")
+	fmt.Println("  Ctrl+R - Soft reset (RAM kept)")
+	fmt.Println("  Ctrl+Shift+R - Power cycle")
+	fmt.Println("  Ctrl+M - Start/stop recording an input macro")
+	fmt.Println("  Ctrl+Shift+M - Play the input macro")
+	if playlist != nil {
+		fmt.Printf("  Ctrl+PageDown / Ctrl+PageUp - Next / previous ROM (%d in %s)\n", playlist.Len(), *playlistDir)
+	}
 	fmt.Println("  Alt+F - Toggle fullscreen")
+	fmt.Println("  Drop a .rom, .zip or .corelx file on the window to load it")
 	fmt.Println("  ESC - Quit")
 
 	// Create Fyne UI (with SDL2 for emulator rendering)
@@ -126,12 +394,117 @@ func main() {
 		fmt.Fprintf(os.Stderr, "Error creating UI: %v\n", err)
 		os.Exit(1)
 	}
+	uiInstance.SetPerfOSD(*osd)
+	uiInstance.SetFramePacing(framePacing)
+	uiInstance.SetMaxFrameSkip(*frameSkip)
+	uiInstance.SetMidFrameInputPoll(*inputPoll)
+	uiInstance.SetTurbo(turboMask, *turboHz)
+	if *debugConsole {
+		uiInstance.SetDebugConsole(os.Stdout)
+	}
+	if playlist != nil {
+		uiInstance.SetPlaylist(playlist, *playlistInterval)
+	}
+	if *recordMovie != "" {
+		if err := uiInstance.RecordMovie(*movieAuthor, *movieComment); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Recording movie to %s\n", *recordMovie)
+	}
+	if movie != nil {
+		if err := uiInstance.PlayMovie(movie); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Playing movie %s: %d frames\n", *playMovie, len(movie.Input))
+	}
 
 	// Run UI (blocks until window is closed)
 	if err := uiInstance.Run(); err != nil {
 		fmt.Fprintf(os.Stderr, "UI error: %v\n", err)
 		os.Exit(1)
 	}
+	if emu.Persist != nil {
+		if err := emu.Persist.Err(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: save data was not written: %v\n", err)
+		}
+	}
+	if *strictBus && emu.Bus.UnmappedAccesses > 0 {
+		fmt.Fprintf(os.Stderr, "%d unmapped memory accesses (see the log viewer)\n", emu.Bus.UnmappedAccesses)
+	}
+	if n := emu.PPU.LateVRAMWrites; n > 0 {
+		fmt.Fprintf(os.Stderr, "%d VRAM/CGRAM writes during active display (%s mode, see the log viewer)\n", n, emu.PPU.VRAMAccess)
+	}
+	if reads := emu.UninitReads(); len(reads) > 0 {
+		fmt.Fprintf(os.Stderr, "%d uninitialized work RAM reads:\n", len(reads))
+		for _, r := range reads {
+			fmt.Fprintf(os.Stderr, "  %s\n", r)
+		}
+	}
+	if m := uiInstance.FinishMovie(); m != nil {
+		if err := harness.SaveMovie(*recordMovie, m); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing movie: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Movie written to %s (%d frames)\n", *recordMovie, len(m.Input))
+	}
+	if d := uiInstance.MovieDesync(); d != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", d)
+	}
+	if emu.Trace != nil {
+		if err := writeChromeTrace(*chromeTrace, emu.Trace); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing trace: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Trace written to %s (%d events", *chromeTrace, emu.Trace.Len())
+		if n := emu.Trace.Dropped(); n > 0 {
+			fmt.Printf(", %d dropped once full", n)
+		}
+		fmt.Println("); open it in ui.perfetto.dev")
+	}
+}
+
+// writeChromeTrace saves rec to path as Chrome trace-event JSON.
+func writeChromeTrace(path string, rec *debug.TraceRecorder) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := rec.WriteChromeTrace(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// defaultPersistDir is where per-ROM save data lives unless -persist-dir
+// says otherwise; empty when the platform has no config directory.
+func defaultPersistDir() string {
+	cfgDir, err := os.UserConfigDir()
+	if err != nil || cfgDir == "" {
+		return ""
+	}
+	return filepath.Join(cfgDir, "nitro-core-dx", "persist")
+}
+
+// registerFileTypes installs the freedesktop MIME package and a desktop entry
+// that opens .rom and .corelx files with this binary.
+func registerFileTypes() error {
+	files, err := fileassoc.Register("", fileassoc.App{
+		ID:        "nitro-core-dx-emulator",
+		Name:      "Nitro-Core-DX Emulator",
+		Comment:   "Run Nitro-Core-DX ROMs",
+		MimeTypes: []string{fileassoc.ROMMimeType, fileassoc.SourceMimeType, fileassoc.ProjectMimeType},
+	})
+	for _, f := range files {
+		fmt.Printf("Wrote %s\n", f)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Println("Refresh the desktop caches with update-mime-database and update-desktop-database.")
+	return nil
 }
 
 func applyAudioBackendSetting(flagValue string) error {
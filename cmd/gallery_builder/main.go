@@ -0,0 +1,54 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"nitro-core-dx/internal/devkit"
+)
+
+// gallery_builder builds every Dev Kit project template and example, runs
+// each headlessly and writes a screenshot per project plus gallery.json and a
+// Markdown index. The Dev Kit's New Project dialog shows these previews.
+//
+//	go run ./cmd/gallery_builder [-out docs/gallery] [-frames 120] [-examples 'Games/*/main.corelx']
+//
+// Exits 1 when any project failed to build or run, so CI catches examples
+// that no longer compile.
+func main() {
+	outDir := flag.String("out", filepath.Join("docs", "gallery"), "output directory for screenshots and gallery.json")
+	frames := flag.Int("frames", devkit.DefaultGalleryFrames, "frames to run each project before capturing")
+	examples := flag.String("examples", filepath.Join("Games", "*", "main.corelx"), "comma-separated glob patterns for example projects")
+	flag.Parse()
+
+	var paths []string
+	for _, pattern := range strings.Split(*examples, ",") {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "bad examples pattern %q: %v\n", pattern, err)
+			os.Exit(2)
+		}
+		paths = append(paths, matches...)
+	}
+
+	manifest, err := devkit.BuildGallery(devkit.GalleryOptions{OutDir: *outDir, Frames: *frames, Examples: paths})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gallery: %v\n", err)
+		os.Exit(2)
+	}
+	failed := manifest.Failed()
+	fmt.Printf("wrote %d previews to %s\n", len(manifest.Entries)-len(failed), filepath.Clean(*outDir))
+	for _, e := range failed {
+		fmt.Fprintf(os.Stderr, "%s %q: %s\n", e.Kind, e.Name, strings.SplitN(e.Error, "\n", 2)[0])
+	}
+	if len(failed) > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,58 @@
+// hwdoc writes the memory map document and its JSON from the register table
+// in internal/hwdef, so the docs cannot drift from what the emulator decodes.
+// The Dev Kit's I/O register panel and Help Center read the same data.
+//
+// Usage: go run ./cmd/hwdoc [-doc docs/MEMORY_MAP.md] [-json docs/memory_map.json] [-check]
+//
+// With -check nothing is written; it exits 1 if either file is out of date.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+
+	"nitro-core-dx/internal/hwdef"
+)
+
+func main() {
+	docPath := flag.String("doc", "docs/MEMORY_MAP.md", "memory map document to write")
+	jsonPath := flag.String("json", "docs/memory_map.json", "memory map JSON to write")
+	check := flag.Bool("check", false, "report stale files instead of writing them")
+	flag.Parse()
+
+	m := hwdef.Map()
+	data, err := m.JSON()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "hwdoc: %v\n", err)
+		os.Exit(1)
+	}
+	outputs := []struct {
+		path string
+		data []byte
+	}{
+		{*docPath, []byte(m.Markdown())},
+		{*jsonPath, data},
+	}
+
+	stale := false
+	for _, out := range outputs {
+		if *check {
+			current, err := os.ReadFile(out.path)
+			if err != nil || !bytes.Equal(current, out.data) {
+				fmt.Fprintf(os.Stderr, "hwdoc: %s is out of date; run go run ./cmd/hwdoc\n", out.path)
+				stale = true
+			}
+			continue
+		}
+		if err := os.WriteFile(out.path, out.data, 0o644); err != nil {
+			fmt.Fprintf(os.Stderr, "hwdoc: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("wrote %s\n", out.path)
+	}
+	if stale {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	ncasm "nitro-core-dx/internal/asm"
+	"nitro-core-dx/internal/corelx"
+	"nitro-core-dx/internal/link"
+)
+
+func main() {
+	out := flag.String("o", "", "output ROM path (required)")
+	mapPath := flag.String("map", "", "write a link map to this path")
+	entry := flag.String("entry", "", "global symbol to start execution at (default: CoreLX boot code or first unit)")
+	flag.Parse()
+	if *out == "" || flag.NArg() == 0 {
+		fmt.Fprintf(os.Stderr, "Usage: %s -o <output.rom> [-map out.map] [-entry SYMBOL] <unit.corelx|unit.asm|unit%s>...\n", os.Args[0], link.ObjectExt)
+		os.Exit(1)
+	}
+
+	objects := make([]*link.Object, 0, flag.NArg())
+	for _, path := range flag.Args() {
+		obj, err := loadUnit(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			os.Exit(1)
+		}
+		objects = append(objects, obj)
+	}
+
+	res, err := link.Link(objects, &link.Options{Entry: *entry})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Link error:\n%v\n", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(*out, res.ROMBytes, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing ROM: %v\n", err)
+		os.Exit(1)
+	}
+	if *mapPath != "" {
+		if err := os.WriteFile(*mapPath, link.FormatMap(res.Map), 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing map: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	fmt.Printf("Linked %d unit(s) -> %s\n", len(objects), filepath.Base(*out))
+	fmt.Printf("Entry: bank %d offset 0x%04X\n", res.Map.EntryBank, res.Map.EntryOffset)
+	fmt.Printf("ROM size: %d bytes\n", len(res.ROMBytes))
+}
+
+// loadUnit turns one input into an object: .asm is assembled, .corelx is
+// compiled in object mode, and object files are read as-is.
+func loadUnit(path string) (*link.Object, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".asm", ".s":
+		return ncasm.AssembleObjectFile(path)
+	case link.ObjectExt:
+		return link.ReadObject(path)
+	case ".corelx":
+		res, err := corelx.CompileProject(path, &corelx.CompileOptions{EmitObject: true})
+		if err != nil {
+			if de, ok := err.(*corelx.DiagnosticsError); ok {
+				var msgs []string
+				for _, d := range de.Diagnostics {
+					if d.Severity == corelx.SeverityError {
+						msgs = append(msgs, d.Error())
+					}
+				}
+				return nil, fmt.Errorf("compile failed:\n%s", strings.Join(msgs, "\n"))
+			}
+			return nil, err
+		}
+		return res.Object, nil
+	default:
+		return nil, fmt.Errorf("unknown unit type %q (want .corelx, .asm or %s)", filepath.Ext(path), link.ObjectExt)
+	}
+}
@@ -0,0 +1,560 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"nitro-core-dx/internal/corelx"
+	"nitro-core-dx/internal/fileassoc"
+	"nitro-core-dx/internal/harness"
+	"nitro-core-dx/internal/rom"
+)
+
+// nitrotool collects ROM inspection commands.
+//
+//	nitrotool info game.rom
+//	nitrotool edit [-title T] [-version V] [-set key=value] [-checksum] [-o out.rom] game.rom
+//	nitrotool diff [-context N] a.rom b.rom
+//	nitrotool lockstep [-frames N] [-replay rec.json] game.rom
+//	nitrotool test [-frames N] [-j N] [-v] [-update-hashes] [-junit out.xml] [-json out.json] dir|rom|project...
+//	nitrotool movie game.rom run.ncm
+//	nitrotool monkey [-seed N] [-runs N] [-frames N] [-buttons MASK] [-hold N] [-hang N] [-freeze N] game.rom
+//
+// Commands that only read a ROM also take a zipped game (a .zip holding
+// one .rom).
+//
+// info and edit exit 0 on success and 2 on errors. The others exit 0 when the ROMs (or lockstep runs, tests, movies, or monkey runs) match or pass, 1
+// when they differ or fail and 2 on errors, like cmp and diff.
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	switch os.Args[1] {
+	case "info":
+		os.Exit(runInfo(os.Args[2:]))
+	case "edit":
+		os.Exit(runEdit(os.Args[2:]))
+	case "diff":
+		os.Exit(runDiff(os.Args[2:]))
+	case "lockstep":
+		os.Exit(runLockstep(os.Args[2:]))
+	case "test":
+		os.Exit(runTest(os.Args[2:]))
+	case "movie":
+		os.Exit(runMovie(os.Args[2:]))
+	case "monkey":
+		os.Exit(runMonkey(os.Args[2:]))
+	case "-h", "-help", "--help", "help":
+		usage()
+	default:
+		fmt.Fprintf(os.Stderr, "nitrotool: unknown command %q\n", os.Args[1])
+		usage()
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: go run ./cmd/nitrotool info game.rom")
+	fmt.Fprintln(os.Stderr, "       go run ./cmd/nitrotool edit [-title T] [-version V] [-set key=value] [-checksum] [-o out.rom] game.rom")
+	fmt.Fprintln(os.Stderr, "       go run ./cmd/nitrotool diff [-context N] a.rom b.rom")
+	fmt.Fprintln(os.Stderr, "       go run ./cmd/nitrotool lockstep [-frames N] [-replay rec.json] game.rom")
+	fmt.Fprintln(os.Stderr, "       go run ./cmd/nitrotool test [-frames N] [-j N] [-v] [-update-hashes] [-junit out.xml] [-json out.json] dir|rom|project...")
+	fmt.Fprintln(os.Stderr, "       go run ./cmd/nitrotool movie game.rom run.ncm")
+	fmt.Fprintln(os.Stderr, "       go run ./cmd/nitrotool monkey [-seed N] [-runs N] [-frames N] [-buttons MASK] [-hold N] [-hang N] [-freeze N] game.rom")
+	os.Exit(2)
+}
+
+// readROM reads the ROM at path, extracting it from a zipped game.
+func readROM(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return fileassoc.UnpackROM(path, data)
+}
+
+func runInfo(args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "usage: go run ./cmd/nitrotool info game.rom")
+		return 2
+	}
+	data, err := readROM(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "read ROM: %v\n", err)
+		return 2
+	}
+	info, err := rom.Inspect(data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "info: %v\n", err)
+		return 2
+	}
+	fmt.Printf("%s\n%s", args[0], info.Summary())
+	return 0
+}
+
+// metaFlags collects repeated -set key=value flags.
+type metaFlags []string
+
+func (m *metaFlags) String() string     { return strings.Join(*m, ",") }
+func (m *metaFlags) Set(v string) error { *m = append(*m, v); return nil }
+
+// runEdit rewrites a ROM's metadata trailer (and optionally its header
+// checksum) without touching the code, in place unless -o is given.
+func runEdit(args []string) int {
+	const usageLine = "usage: go run ./cmd/nitrotool edit [-title T] [-version V] [-set key=value] [-checksum] [-o out.rom] game.rom"
+	fs := flag.NewFlagSet("edit", flag.ExitOnError)
+	title := fs.String("title", "", "Set the title")
+	version := fs.String("version", "", "Set the version string")
+	checksum := fs.Bool("checksum", false, "Write the payload CRC-32 into the header checksum")
+	out := fs.String("o", "", "Write the edited ROM here instead of in place")
+	var sets metaFlags
+	fs.Var(&sets, "set", "Set any metadata key (key=value; an empty value removes the key); repeatable")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, usageLine)
+		return 2
+	}
+	path := fs.Arg(0)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "read ROM: %v\n", err)
+		return 2
+	}
+	info, err := rom.Inspect(data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "edit: %v\n", err)
+		return 2
+	}
+	md := rom.Metadata{}
+	for k, v := range info.Metadata {
+		md[k] = v
+	}
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "title":
+			md[rom.MetaTitle] = *title
+		case "version":
+			md[rom.MetaVersion] = *version
+		}
+	})
+	for _, kv := range sets {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok || k == "" {
+			fmt.Fprintf(os.Stderr, "edit: -set %q: want key=value\n", kv)
+			return 2
+		}
+		md[k] = v
+	}
+	for k, v := range md {
+		if v == "" {
+			delete(md, k)
+		}
+	}
+
+	edited, err := rom.SetMetadata(data, md)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "edit: %v\n", err)
+		return 2
+	}
+	if *checksum {
+		if err := rom.SetChecksum(edited); err != nil {
+			fmt.Fprintf(os.Stderr, "edit: %v\n", err)
+			return 2
+		}
+	}
+	if *out == "" {
+		*out = path
+	}
+	if err := os.WriteFile(*out, edited, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "write ROM: %v\n", err)
+		return 2
+	}
+	return 0
+}
+
+func runDiff(args []string) int {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	context := fs.Int("context", 3, "Unchanged instructions shown around each change")
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		fmt.Fprintln(os.Stderr, "usage: go run ./cmd/nitrotool diff [-context N] a.rom b.rom")
+		return 2
+	}
+	pathA, pathB := fs.Arg(0), fs.Arg(1)
+	a, err := readROM(pathA)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "read ROM: %v\n", err)
+		return 2
+	}
+	b, err := readROM(pathB)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "read ROM: %v\n", err)
+		return 2
+	}
+	d, err := rom.DiffROMs(a, b, *context)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "diff: %v\n", err)
+		return 2
+	}
+	if d.Equal() {
+		return 0
+	}
+	printDiff(os.Stdout, pathA, pathB, d)
+	return 1
+}
+
+func printDiff(w io.Writer, pathA, pathB string, d *rom.ROMDiff) {
+	fmt.Fprintf(w, "--- %s\n+++ %s\n", pathA, pathB)
+	for _, c := range d.Header {
+		fmt.Fprintf(w, "header %s: 0x%X -> 0x%X\n", c.Field, c.A, c.B)
+	}
+	for _, bank := range d.OnlyA {
+		fmt.Fprintf(w, "bank %02X: only in %s\n", bank, pathA)
+	}
+	for _, bank := range d.OnlyB {
+		fmt.Fprintf(w, "bank %02X: only in %s\n", bank, pathB)
+	}
+
+	removed, added := 0, 0
+	for _, h := range d.Hunks {
+		fmt.Fprintf(w, "@@ bank %02X  %s  %s @@\n", h.Bank, hunkRange(h, '-'), hunkRange(h, '+'))
+		for _, op := range h.Ops {
+			switch op.Kind {
+			case '-':
+				removed++
+			case '+':
+				added++
+			}
+			fmt.Fprintf(w, "%c %s\n", op.Kind, formatInstruction(op.Inst))
+		}
+	}
+	fmt.Fprintf(w, "%d header fields, %d hunks: -%d +%d instructions\n", len(d.Header), len(d.Hunks), removed, added)
+}
+
+// hunkRange describes the addresses one side of a hunk covers, e.g.
+// "-8010..801C" or "+(none)" for a pure insertion or deletion.
+func hunkRange(h rom.DiffHunk, kind byte) string {
+	first, last := -1, -1
+	for _, op := range h.Ops {
+		if op.Kind == kind {
+			if first < 0 {
+				first = int(op.Inst.Offset)
+			}
+			last = int(op.Inst.Offset)
+		}
+	}
+	if first < 0 {
+		return string(kind) + "(none)"
+	}
+	return fmt.Sprintf("%c%04X..%04X", kind, first, last)
+}
+
+func formatInstruction(in rom.Instruction) string {
+	words := make([]string, len(in.Words))
+	for i, w := range in.Words {
+		words[i] = fmt.Sprintf("%04X", w)
+	}
+	line := fmt.Sprintf("%02X:%04X  %-10s %s", in.Bank, in.Offset, strings.Join(words, " "), in.Text)
+	if in.HasTarget {
+		line += fmt.Sprintf("  ; -> %04X", in.Target)
+	}
+	return line
+}
+
+// runLockstep runs the ROM on two emulators side by side and reports the
+// first frame where their machine state differs.
+func runLockstep(args []string) int {
+	fs := flag.NewFlagSet("lockstep", flag.ExitOnError)
+	frames := fs.Int("frames", 600, "Frames to run (with -replay, 0 runs the whole recording)")
+	replay := fs.String("replay", "", "Recording (harness JSON) whose per-frame input drives both machines")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: go run ./cmd/nitrotool lockstep [-frames N] [-replay rec.json] game.rom")
+		return 2
+	}
+	data, err := readROM(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "read ROM: %v\n", err)
+		return 2
+	}
+	var inputs []uint16
+	if *replay != "" {
+		rec, err := harness.Load(*replay)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "load recording: %v\n", err)
+			return 2
+		}
+		for _, f := range rec.Frames {
+			inputs = append(inputs, f.Input)
+		}
+		if *frames == 0 {
+			*frames = len(inputs)
+		}
+	}
+
+	l, err := harness.NewLockstep(data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "lockstep: %v\n", err)
+		return 2
+	}
+	defer l.Close()
+	d, err := l.Run(inputs, *frames)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "lockstep: %v\n", err)
+		return 2
+	}
+	if d == nil {
+		fmt.Printf("%d frames, no divergence\n", l.Frame)
+		return 0
+	}
+	fmt.Printf("diverged at frame %d in %s\n  %s\n", d.Frame, d.Component, d.Detail)
+	if len(d.Components) > 1 {
+		fmt.Printf("  also differing: %s\n", strings.Join(d.Components[1:], ", "))
+	}
+	return 1
+}
+
+// runMovie plays a movie headlessly and checks every sync checkpoint.
+func runMovie(args []string) int {
+	if len(args) != 2 {
+		fmt.Fprintln(os.Stderr, "usage: go run ./cmd/nitrotool movie game.rom run.ncm")
+		return 2
+	}
+	data, err := readROM(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "read ROM: %v\n", err)
+		return 2
+	}
+	m, err := harness.LoadMovie(args[1])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "movie: %v\n", err)
+		return 2
+	}
+	start := "savestate"
+	if m.Header.PowerOn {
+		start = "power-on"
+	}
+	fmt.Printf("%s: %d frames from %s, %d checkpoints\n", args[1], len(m.Input), start, len(m.Header.Checkpoints))
+	if m.Header.Author != "" {
+		fmt.Printf("  author:  %s\n", m.Header.Author)
+	}
+	if m.Header.Comment != "" {
+		fmt.Printf("  comment: %s\n", m.Header.Comment)
+	}
+	if !m.Header.Created.IsZero() {
+		fmt.Printf("  created: %s\n", m.Header.Created.Format(time.RFC3339))
+	}
+	d, err := harness.VerifyMovie(data, m)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "movie: %v\n", err)
+		return 2
+	}
+	if d != nil {
+		fmt.Println(d)
+		return 1
+	}
+	fmt.Println("in sync")
+	return 0
+}
+
+// runMonkey feeds a ROM seeded random input for a number of runs, one seed
+// after another, and reports each fault, hang or frozen screen with the
+// seed that reproduces it.
+func runMonkey(args []string) int {
+	const usageLine = "usage: go run ./cmd/nitrotool monkey [-seed N] [-runs N] [-frames N] [-buttons MASK] [-hold N] [-hang N] [-freeze N] game.rom"
+	def := harness.DefaultMonkeyOptions(0)
+	fs := flag.NewFlagSet("monkey", flag.ExitOnError)
+	seed := fs.Int64("seed", time.Now().UnixNano(), "Seed of the first run (default: from the clock); run i uses seed+i")
+	runs := fs.Int("runs", 1, "Runs, each with the next seed")
+	frames := fs.Int("frames", def.Frames, "Frames per run")
+	buttons := fs.String("buttons", fmt.Sprintf("0x%03X", def.Buttons), "Mask of the buttons random input may press (bit 10 is Start)")
+	hold := fs.Int("hold", def.MaxHold, "Most frames one random input is held")
+	hang := fs.Int("hang", def.HangFrames, "Frames the CPU may loop in place with work RAM unchanged before it counts as hung (0: off)")
+	freeze := fs.Int("freeze", def.FreezeFrames, "Frames the framebuffer may stay unchanged before it counts as frozen (0: off)")
+	fs.Parse(args)
+	mask, err := strconv.ParseUint(*buttons, 0, 16)
+	if fs.NArg() != 1 || *runs < 1 || *frames <= 0 || err != nil {
+		fmt.Fprintln(os.Stderr, usageLine)
+		return 2
+	}
+	data, err := readROM(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "read ROM: %v\n", err)
+		return 2
+	}
+
+	opts := harness.MonkeyOptions{
+		Frames:       *frames,
+		Buttons:      uint16(mask),
+		MaxHold:      *hold,
+		HangFrames:   *hang,
+		FreezeFrames: *freeze,
+	}
+	failed := 0
+	for i := 0; i < *runs; i++ {
+		opts.Seed = *seed + int64(i)
+		res, err := harness.RunMonkey(data, opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "monkey: %v\n", err)
+			return 2
+		}
+		fmt.Println(res)
+		if res.Failed() {
+			failed++
+			fmt.Printf("  reproduce: go run ./cmd/nitrotool monkey -seed %d -frames %d -buttons 0x%03X -hold %d -hang %d -freeze %d %s\n",
+				res.Seed, *frames, mask, *hold, *hang, *freeze, fs.Arg(0))
+		}
+	}
+	fmt.Printf("%d runs, %d found problems\n", *runs, failed)
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// runTest runs test ROMs headlessly in parallel and reports each one's
+// result (see the harness test ROM convention), optionally as JUnit XML
+// and JSON for CI. CoreLX projects (.corelx, .ncdx or a directory holding
+// main.corelx) are built and checked against the frame hashes in their
+// corelx.hashes.json instead.
+func runTest(args []string) int {
+	const usageLine = "usage: go run ./cmd/nitrotool test [-frames N] [-j N] [-v] [-update-hashes] [-junit out.xml] [-json out.json] dir|rom|project..."
+	fs := flag.NewFlagSet("test", flag.ExitOnError)
+	frames := fs.Int("frames", 600, "Frames a ROM may run before it times out")
+	workers := fs.Int("j", runtime.NumCPU(), "ROMs run at once")
+	junitPath := fs.String("junit", "", "Write JUnit XML results to this file")
+	jsonPath := fs.String("json", "", "Write JSON results to this file")
+	suite := fs.String("suite", "nitro-roms", "Test suite name in the JUnit output")
+	verbose := fs.Bool("v", false, "Print what each ROM wrote to the test port")
+	updateHashes := fs.Bool("update-hashes", false, "Rewrite each project's "+harness.FrameHashFileName+" with the hashes it produces now")
+	fs.Parse(args)
+	if fs.NArg() == 0 || *frames <= 0 {
+		fmt.Fprintln(os.Stderr, usageLine)
+		return 2
+	}
+
+	var paths, projects []string
+	for _, arg := range fs.Args() {
+		info, err := os.Stat(arg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "test: %v\n", err)
+			return 2
+		}
+		if !info.IsDir() {
+			if fileassoc.Classify(arg) == fileassoc.KindSource {
+				projects = append(projects, arg)
+			} else {
+				paths = append(paths, arg)
+			}
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(arg, corelx.ProjectMainFile)); err == nil {
+			projects = append(projects, arg)
+			continue
+		}
+		found, err := harness.FindTestROMs(arg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "test: %v\n", err)
+			return 2
+		}
+		paths = append(paths, found...)
+	}
+	if len(paths) == 0 && len(projects) == 0 {
+		fmt.Fprintln(os.Stderr, "test: no .rom files found")
+		return 2
+	}
+
+	results := harness.RunROMTests(paths, *frames, *workers)
+	for _, p := range projects {
+		results = append(results, runProjectHashes(p, *updateHashes))
+	}
+	for _, r := range results {
+		line := fmt.Sprintf("%-7s %s  (%d frames, %s)", strings.ToUpper(r.Outcome), r.Name, r.Frames, r.Duration.Round(time.Millisecond))
+		if !r.Passed() || r.Message != "" {
+			line += "\n        " + strings.ReplaceAll(r.Message, "\n", "\n        ")
+		}
+		if *verbose && r.Output != "" {
+			line += "\n    | " + strings.ReplaceAll(strings.TrimRight(r.Output, "\n"), "\n", "\n    | ")
+		}
+		fmt.Println(line)
+	}
+	sum := harness.Summarize(results)
+	fmt.Printf("%d ROMs: %d passed, %d failed, %d timed out, %d errors\n", sum.Total, sum.Passed, sum.Failed, sum.TimedOut, sum.Errors)
+
+	if *junitPath != "" {
+		if err := writeResultFile(*junitPath, func(w io.Writer) error { return harness.WriteROMTestJUnit(w, *suite, results) }); err != nil {
+			fmt.Fprintf(os.Stderr, "test: %v\n", err)
+			return 2
+		}
+	}
+	if *jsonPath != "" {
+		if err := writeResultFile(*jsonPath, func(w io.Writer) error { return harness.WriteROMTestJSON(w, results) }); err != nil {
+			fmt.Fprintf(os.Stderr, "test: %v\n", err)
+			return 2
+		}
+	}
+	if sum.Passed != sum.Total {
+		return 1
+	}
+	return 0
+}
+
+// runProjectHashes builds the project at path and checks it against its
+// expected frame hashes, or with update records the hashes it produces
+// now as the expected ones.
+func runProjectHashes(path string, update bool) harness.ROMTestResult {
+	res := harness.ROMTestResult{
+		Name:    strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+		Path:    path,
+		Outcome: harness.OutcomeError,
+	}
+	m, err := harness.LoadFrameHashManifest(path)
+	if err != nil {
+		res.Message = err.Error()
+		return res
+	}
+	source := path
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		source = filepath.Join(path, corelx.ProjectMainFile)
+	}
+	rom, err := fileassoc.ReadROM(source)
+	if err != nil {
+		res.Message = err.Error()
+		return res
+	}
+	if !update {
+		return harness.RunFrameHashTest(path, rom, m)
+	}
+	hashes, err := harness.FrameHashes(rom, m)
+	if err == nil {
+		for i := range m.Expect {
+			m.Expect[i].Hash = hashes[i]
+		}
+		err = harness.SaveFrameHashManifest(path, m)
+	}
+	if err != nil {
+		res.Message = err.Error()
+		return res
+	}
+	res.Outcome = harness.OutcomePass
+	res.Message = fmt.Sprintf("updated %d hashes in %s", len(hashes), harness.FrameHashManifestPath(path))
+	return res
+}
+
+func writeResultFile(path string, write func(io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+
+	"nitro-core-dx/internal/hwdef"
+	"nitro-core-dx/internal/rom"
+)
+
+// Registers the steps share. A step may clobber the scratch registers but
+// leaves the others to the steps that own them.
+const (
+	regX       = 0 // sprite X
+	regY       = 1 // sprite Y
+	regButtons = 2 // controller 1 buttons, from readPad
+	regCount   = 3 // scratch: loop counters and button tests
+	regNote    = 4 // playScale: index of the note playing
+	regTimer   = 5 // playScale: frames into the note
+	regValue   = 6 // scratch: value for poke
+	regAddr    = 7 // scratch: address for poke
+)
+
+// entryOffset is where a test ROM starts, in bank 1.
+const entryOffset = 0x8000
+
+// irqStubOffset is the RET the IRQ and NMI vectors point at. The vectors
+// only hold a page, and the hardware default aliases the entry point,
+// which would restart the ROM at the first VBlank.
+const irqStubOffset = 0x8100
+
+// asm assembles a test ROM into a rom.ROMBuilder, with labels so branches
+// can jump forward. Every instruction is encoded by the rom.Encode*
+// helpers, never by hand.
+type asm struct {
+	b      *rom.ROMBuilder
+	labels map[string]uint16
+	fixups []fixup
+	next   int
+}
+
+// fixup is a branch offset word waiting for its label's address.
+type fixup struct {
+	word  int
+	pc    uint16
+	label string
+}
+
+// newAsm starts a ROM: point the interrupt vectors at a RET stub, then
+// jump over it to the code that follows.
+func newAsm() *asm {
+	a := &asm{b: rom.NewROMBuilder(), labels: map[string]uint16{}}
+	for _, vector := range []uint16{0xFFE0, 0xFFE2} {
+		a.poke(vector, 1)                  // bank
+		a.poke(vector+1, irqStubOffset>>8) // page
+	}
+	a.jmp("start")
+	for a.pc() < irqStubOffset {
+		a.op(rom.EncodeNOP())
+	}
+	a.op(rom.EncodeRET())
+	a.label("start")
+	return a
+}
+
+// pc is the address of the next word.
+func (a *asm) pc() uint16 {
+	return entryOffset + uint16(2*a.b.GetCodeLength())
+}
+
+// newLabel returns a label name no other call returns.
+func (a *asm) newLabel() string {
+	a.next++
+	return fmt.Sprintf("L%d", a.next)
+}
+
+// label places name at the next instruction.
+func (a *asm) label(name string) {
+	a.labels[name] = a.pc()
+}
+
+func (a *asm) op(word uint16) {
+	a.b.AddInstruction(word)
+}
+
+// opImm emits an instruction with its immediate word.
+func (a *asm) opImm(word, imm uint16) {
+	a.b.AddInstruction(word)
+	a.b.AddImmediate(imm)
+}
+
+// branch emits a branch or JMP (op) to label.
+func (a *asm) branch(op uint16, label string) {
+	a.op(op)
+	a.fixups = append(a.fixups, fixup{word: a.b.GetCodeLength(), pc: a.pc(), label: label})
+	a.b.AddImmediate(0)
+}
+
+func (a *asm) jmp(label string) { a.branch(rom.EncodeJMP(), label) }
+func (a *asm) beq(label string) { a.branch(rom.EncodeBEQ(), label) }
+func (a *asm) bne(label string) { a.branch(rom.EncodeBNE(), label) }
+
+// movImm loads v into reg.
+func (a *asm) movImm(reg uint8, v uint16) { a.opImm(rom.EncodeMOV(1, reg, 0), v) }
+
+// movReg copies src into dst.
+func (a *asm) movReg(dst, src uint8) { a.op(rom.EncodeMOV(0, dst, src)) }
+
+func (a *asm) addImm(reg uint8, v uint16) { a.opImm(rom.EncodeADD(1, reg, 0), v) }
+func (a *asm) subImm(reg uint8, v uint16) { a.opImm(rom.EncodeSUB(1, reg, 0), v) }
+func (a *asm) andImm(reg uint8, v uint16) { a.opImm(rom.EncodeAND(1, reg, 0), v) }
+func (a *asm) shrImm(reg uint8, v uint16) { a.opImm(rom.EncodeSHR(1, reg, 0), v) }
+func (a *asm) cmpImm(reg uint8, v uint16) { a.opImm(rom.EncodeCMP(7, reg, 0), v) }
+
+// store8 writes the low byte of reg to the bank 0 address in addrReg.
+func (a *asm) store8(addrReg, reg uint8) { a.op(rom.EncodeMOV(7, addrReg, reg)) }
+
+// load8 reads the byte at the bank 0 address in addrReg into reg.
+func (a *asm) load8(reg, addrReg uint8) { a.op(rom.EncodeMOV(6, reg, addrReg)) }
+
+// load16 reads the word at the bank 0 address in addrReg into reg.
+func (a *asm) load16(reg, addrReg uint8) { a.op(rom.EncodeMOV(2, reg, addrReg)) }
+
+// poke writes value's low byte to the bank 0 address addr.
+func (a *asm) poke(addr, value uint16) {
+	a.movImm(regAddr, addr)
+	a.movImm(regValue, value)
+	a.store8(regAddr, regValue)
+}
+
+// waitVBlank spins until the next VBlank starts. VBLANK_FLAG reads set
+// for the whole of VBlank, so it first waits for the flag to clear; a frame
+// that finishes inside VBlank would otherwise run again at once.
+func (a *asm) waitVBlank() {
+	inVBlank, wait := a.newLabel(), a.newLabel()
+	a.movImm(regAddr, hwdef.VBLANK_FLAG)
+	a.label(inVBlank)
+	a.load8(regValue, regAddr)
+	a.cmpImm(regValue, 0)
+	a.bne(inVBlank)
+	a.label(wait)
+	a.load8(regValue, regAddr)
+	a.cmpImm(regValue, 0)
+	a.beq(wait)
+}
+
+// build resolves the branches and returns the ROM image.
+func (a *asm) build() ([]byte, error) {
+	for _, f := range a.fixups {
+		target, ok := a.labels[f.label]
+		if !ok {
+			return nil, fmt.Errorf("undefined label %q", f.label)
+		}
+		a.b.SetImmediateAt(f.word, uint16(rom.CalculateBranchOffset(f.pc, target)))
+	}
+	return a.b.BuildROMBytes(1, entryOffset)
+}
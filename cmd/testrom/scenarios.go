@@ -0,0 +1,223 @@
+package main
+
+import (
+	"nitro-core-dx/internal/hwdef"
+	"nitro-core-dx/internal/input"
+)
+
+// scenario is one test ROM: Setup runs once at power-on, then every frame
+// the ROM waits for VBlank and runs Frame.
+type scenario struct {
+	Name    string
+	Summary string
+	Expect  string
+	Setup   []step
+	Frame   []step
+}
+
+// step is a piece of a scenario, emitted into the ROM in order.
+type step func(a *asm)
+
+// RGB555 colors.
+const (
+	black = 0x0000
+	white = 0x7FFF
+	green = 0x03E0
+)
+
+// cMajor is the C major scale, C4 to C5, in Hz.
+var cMajor = []uint16{262, 294, 330, 349, 392, 440, 494, 523}
+
+// scenarios is every test ROM testrom can build.
+var scenarios = []scenario{
+	{
+		Name:    "minimal",
+		Summary: "One sprite on a black screen",
+		Expect:  "A white 8x8 block at (160, 100)",
+		Setup:   []step{enableBG0(), setColor(0x00, black), setColor(0x11, white), solidTiles(0, 1, 1), placeSprite(160, 100)},
+		Frame:   []step{drawSprite(1)},
+	},
+	{
+		Name:    "input",
+		Summary: "A sprite moved by controller 1",
+		Expect:  "A white 8x8 block that the D-pad (arrow keys or WASD) moves a pixel a frame",
+		Setup:   []step{enableBG0(), setColor(0x00, black), setColor(0x11, white), solidTiles(0, 1, 1), placeSprite(160, 100)},
+		Frame:   []step{readPad(), moveWithDPad(), drawSprite(1)},
+	},
+	{
+		Name:    "audio-scale",
+		Summary: "The C major scale on tone channel 0",
+		Expect:  "A white 8x8 block, and a sine C major scale: one second per note, half a second apart, repeating",
+		Setup:   []step{enableBG0(), setColor(0x00, black), setColor(0x11, white), solidTiles(0, 1, 1), placeSprite(160, 100)},
+		Frame:   []step{playScale(cMajor, 60, 30), drawSprite(1)},
+	},
+	{
+		Name:    "vram-loop",
+		Summary: "A long VRAM upload loop that must exit",
+		Expect:  "A green screen once 64 tiles are written to VRAM; black means the loop never ended",
+		Setup:   []step{setColor(0x00, black), solidTiles(0, 1, 64), setColor(0x00, green)},
+	},
+}
+
+// findScenario returns the scenario called name.
+func findScenario(name string) (scenario, bool) {
+	for _, s := range scenarios {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return scenario{}, false
+}
+
+// assemble builds s's ROM image.
+func (s scenario) assemble() ([]byte, error) {
+	a := newAsm()
+	for _, st := range s.Setup {
+		st(a)
+	}
+	a.label("frame")
+	a.waitVBlank()
+	for _, st := range s.Frame {
+		st(a)
+	}
+	a.jmp("frame")
+	return a.build()
+}
+
+// enableBG0 turns on background layer 0.
+func enableBG0() step {
+	return func(a *asm) {
+		a.poke(hwdef.BG0_CONTROL, 0x01)
+	}
+}
+
+// setColor writes rgb (RGB555) to CGRAM entry index (palette<<4 | color).
+func setColor(index uint8, rgb uint16) step {
+	return func(a *asm) {
+		a.poke(hwdef.CGRAM_ADDR, uint16(index))
+		a.poke(hwdef.CGRAM_DATA, rgb&0xFF)
+		a.poke(hwdef.CGRAM_DATA, rgb>>8)
+	}
+}
+
+// solidTiles fills tiles 4bpp tiles from tile first with color, in a loop.
+func solidTiles(first uint16, color uint8, tiles uint16) step {
+	return func(a *asm) {
+		addr := first * 32
+		a.poke(hwdef.VRAM_ADDR_L, addr&0xFF)
+		a.poke(hwdef.VRAM_ADDR_H, addr>>8)
+		loop := a.newLabel()
+		a.movImm(regAddr, hwdef.VRAM_DATA)
+		a.movImm(regValue, uint16(color)<<4|uint16(color))
+		a.movImm(regCount, tiles*32)
+		a.label(loop)
+		a.store8(regAddr, regValue)
+		a.subImm(regCount, 1)
+		a.bne(loop)
+	}
+}
+
+// placeSprite sets the sprite position registers.
+func placeSprite(x, y uint16) step {
+	return func(a *asm) {
+		a.movImm(regX, x)
+		a.movImm(regY, y)
+	}
+}
+
+// drawSprite writes sprite 0 to OAM: tile 0 with palette at the sprite
+// position, 8x8 and enabled. Run it in VBlank.
+func drawSprite(palette uint8) step {
+	return func(a *asm) {
+		a.poke(hwdef.OAM_ADDR, 0)
+		a.movImm(regAddr, hwdef.OAM_DATA)
+		a.store8(regAddr, regX) // X low
+		a.movReg(regValue, regX)
+		a.shrImm(regValue, 8)
+		a.andImm(regValue, 0x01)
+		a.store8(regAddr, regValue) // X high bit
+		a.store8(regAddr, regY)
+		a.movImm(regValue, 0) // tile
+		a.store8(regAddr, regValue)
+		a.movImm(regValue, uint16(palette)) // attributes
+		a.store8(regAddr, regValue)
+		a.movImm(regValue, 0x01) // control: enabled, 8x8
+		a.store8(regAddr, regValue)
+	}
+}
+
+// readPad latches controller 1 and reads its buttons into regButtons.
+func readPad() step {
+	return func(a *asm) {
+		a.poke(hwdef.CONTROLLER1_H, 1) // a write to the high byte latches
+		a.movImm(regAddr, hwdef.CONTROLLER1_L)
+		a.load16(regButtons, regAddr)
+		a.poke(hwdef.CONTROLLER1_H, 0)
+	}
+}
+
+// moveWithDPad moves the sprite a pixel for each D-pad direction held.
+func moveWithDPad() step {
+	return func(a *asm) {
+		for _, d := range []struct {
+			button uint8
+			reg    uint8
+			add    bool
+		}{
+			{input.ButtonUP, regY, false},
+			{input.ButtonDOWN, regY, true},
+			{input.ButtonLEFT, regX, false},
+			{input.ButtonRIGHT, regX, true},
+		} {
+			skip := a.newLabel()
+			a.movReg(regCount, regButtons)
+			a.andImm(regCount, 1<<d.button)
+			a.beq(skip)
+			if d.add {
+				a.addImm(d.reg, 1)
+			} else {
+				a.subImm(d.reg, 1)
+			}
+			a.label(skip)
+		}
+	}
+}
+
+// playScale plays notes on tone channel 0 in turn, each for noteFrames
+// frames followed by gapFrames of silence, and repeats.
+func playScale(notes []uint16, noteFrames, gapFrames uint16) step {
+	return func(a *asm) {
+		noStart, play, noStop, done := a.newLabel(), a.newLabel(), a.newLabel(), a.newLabel()
+
+		a.cmpImm(regTimer, 0)
+		a.bne(noStart)
+		for i, freq := range notes {
+			next := a.newLabel()
+			a.cmpImm(regNote, uint16(i))
+			a.bne(next)
+			a.poke(hwdef.CH0_FREQ_LOW, freq&0xFF)
+			a.poke(hwdef.CH0_FREQ_HIGH, freq>>8)
+			a.jmp(play)
+			a.label(next)
+		}
+		a.label(play)
+		a.poke(hwdef.CH0_VOLUME, 0x80)
+		a.poke(hwdef.CH0_CONTROL, 0x01) // enabled, sine
+		a.label(noStart)
+
+		a.cmpImm(regTimer, noteFrames)
+		a.bne(noStop)
+		a.poke(hwdef.CH0_CONTROL, 0x00)
+		a.label(noStop)
+
+		a.addImm(regTimer, 1)
+		a.cmpImm(regTimer, noteFrames+gapFrames)
+		a.bne(done)
+		a.movImm(regTimer, 0)
+		a.addImm(regNote, 1)
+		a.cmpImm(regNote, uint16(len(notes)))
+		a.bne(done)
+		a.movImm(regNote, 0)
+		a.label(done)
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"testing"
+
+	"nitro-core-dx/internal/emulator"
+	"nitro-core-dx/internal/input"
+)
+
+// runScenario boots name's ROM and runs frames frames with buttons held.
+func runScenario(t *testing.T, name string, frames int, buttons uint16) *emulator.Emulator {
+	t.Helper()
+	s, ok := findScenario(name)
+	if !ok {
+		t.Fatalf("no scenario %q", name)
+	}
+	data, err := s.assemble()
+	if err != nil {
+		t.Fatal(err)
+	}
+	emu := emulator.NewEmulator()
+	t.Cleanup(emu.Logger.Shutdown)
+	if err := emu.LoadROM(data); err != nil {
+		t.Fatal(err)
+	}
+	emu.SetFrameLimit(false)
+	emu.Start()
+	emu.SetInputButtons(buttons)
+	for i := 0; i < frames; i++ {
+		if err := emu.RunFrame(); err != nil {
+			t.Fatalf("%s frame %d: %v", name, i, err)
+		}
+	}
+	return emu
+}
+
+func TestEveryScenarioRuns(t *testing.T) {
+	seen := map[string]bool{}
+	for _, s := range scenarios {
+		if seen[s.Name] {
+			t.Errorf("two scenarios are called %q", s.Name)
+		}
+		seen[s.Name] = true
+		runScenario(t, s.Name, 10, 0)
+	}
+}
+
+func TestMinimalDrawsSprite(t *testing.T) {
+	emu := runScenario(t, "minimal", 3, 0)
+	want := []uint8{160, 0, 100, 0, 1, 1}
+	for i, w := range want {
+		if got := emu.PPU.OAM[i]; got != w {
+			t.Fatalf("OAM byte %d = %d, want %d (sprite 0 = % d)", i, got, w, emu.PPU.OAM[:6])
+		}
+	}
+	if lo, hi := emu.PPU.CGRAM[0x22], emu.PPU.CGRAM[0x23]; lo != 0xFF || hi != 0x7F {
+		t.Errorf("CGRAM color 0x11 = %02X%02X, want 7FFF (white)", hi, lo)
+	}
+}
+
+func TestInputMovesSprite(t *testing.T) {
+	emu := runScenario(t, "input", 11, 1<<input.ButtonRIGHT|1<<input.ButtonUP)
+	// The first frame only sets up; every later one moves a pixel.
+	if x, y := emu.PPU.OAM[0], emu.PPU.OAM[2]; x <= 160 || y >= 100 {
+		t.Fatalf("sprite at (%d, %d) after holding right and up, want right of 160 and above 100", x, y)
+	}
+}
+
+func TestAudioScalePlaysNotes(t *testing.T) {
+	emu := runScenario(t, "audio-scale", 5, 0)
+	if ch := emu.APU.Channels[0]; !ch.Enabled || ch.Frequency != cMajor[0] {
+		t.Fatalf("channel 0 = %d Hz, enabled %v; want %d Hz playing", ch.Frequency, ch.Enabled, cMajor[0])
+	}
+	for i := 0; i < 90; i++ {
+		if err := emu.RunFrame(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if ch := emu.APU.Channels[0]; !ch.Enabled || ch.Frequency != cMajor[1] {
+		t.Fatalf("channel 0 = %d Hz, enabled %v a note later; want %d Hz playing", ch.Frequency, ch.Enabled, cMajor[1])
+	}
+}
+
+func TestVRAMLoopExits(t *testing.T) {
+	emu := runScenario(t, "vram-loop", 3, 0)
+	for i := 0; i < 64*32; i++ {
+		if emu.PPU.VRAM[i] != 0x11 {
+			t.Fatalf("VRAM[%d] = %02X, want 11", i, emu.PPU.VRAM[i])
+		}
+	}
+	if lo, hi := emu.PPU.CGRAM[0], emu.PPU.CGRAM[1]; lo != 0xE0 || hi != 0x03 {
+		t.Errorf("backdrop = %02X%02X, want 03E0 (green): the loop did not exit", hi, lo)
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+
+	"nitro-core-dx/internal/emulator"
+)
+
+// vram_ripper runs a ROM (optionally from a save state) and writes its VRAM
+// tiles and CGRAM palettes as PNGs.
+func main() {
+	romPath := flag.String("rom", "", "Input ROM path")
+	statePath := flag.String("state", "", "Save state to load after the ROM (optional)")
+	frames := flag.Int("frames", -1, "Frames to run before capturing (default 60, or 0 with -state)")
+	tileSize := flag.Int("tile-size", 8, "Tile size: 8 or 16")
+	palette := flag.Int("palette", 0, "CGRAM palette (0-15) to draw tiles with")
+	columns := flag.Int("columns", 16, "Tiles per row in the sheet")
+	transparent := flag.Bool("transparent", false, "Draw color index 0 as transparent")
+	keepBlank := flag.Bool("keep-blank", false, "Keep blank tiles after the last used one")
+	outPath := flag.String("out", "vram_tiles.png", "Output tile sheet PNG")
+	paletteOut := flag.String("palette-out", "cgram_palette.png", "Output palette PNG (empty to skip)")
+	swatch := flag.Int("swatch", 16, "Palette swatch size in pixels")
+	flag.Parse()
+
+	if *romPath == "" {
+		fmt.Fprintln(os.Stderr, "usage: go run ./cmd/vram_ripper -rom <path.rom> [-state file] [-frames N] [-tile-size 8|16] [-palette 0-15] [-out tiles.png] [-palette-out palette.png]")
+		os.Exit(2)
+	}
+
+	romData, err := os.ReadFile(*romPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "read ROM: %v\n", err)
+		os.Exit(1)
+	}
+	emu := emulator.NewEmulator()
+	if err := emu.LoadROM(romData); err != nil {
+		fmt.Fprintf(os.Stderr, "load ROM: %v\n", err)
+		os.Exit(1)
+	}
+	emu.SetFrameLimit(false)
+	emu.Start()
+
+	nFrames := *frames
+	if *statePath != "" {
+		if err := emu.LoadStateFromFile(*statePath); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		// A paused state would not advance; capture runs it regardless.
+		emu.Running, emu.Paused = true, false
+		if nFrames < 0 {
+			nFrames = 0
+		}
+	} else if nFrames < 0 {
+		nFrames = 60
+	}
+	for i := 0; i < nFrames; i++ {
+		if err := emu.RunFrame(); err != nil {
+			fmt.Fprintf(os.Stderr, "run frame %d: %v\n", i, err)
+			os.Exit(1)
+		}
+	}
+
+	sheet, err := emu.ExportTileSheet(emulator.TileSheetOptions{
+		TileSize:        *tileSize,
+		Palette:         *palette,
+		Columns:         *columns,
+		TransparentZero: *transparent,
+		KeepBlank:       *keepBlank,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "decode VRAM: %v\n", err)
+		os.Exit(2)
+	}
+	if err := writePNG(*outPath, sheet); err != nil {
+		fmt.Fprintf(os.Stderr, "write tile sheet: %v\n", err)
+		os.Exit(1)
+	}
+	b := sheet.Bounds()
+	fmt.Printf("Wrote %dx%d tile sheet (%dx%d tiles, palette %d) to %s\n", b.Dx(), b.Dy(), *tileSize, *tileSize, *palette, *outPath)
+
+	if *paletteOut != "" {
+		if err := writePNG(*paletteOut, emu.ExportPaletteStrip(*swatch)); err != nil {
+			fmt.Fprintf(os.Stderr, "write palette: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Wrote CGRAM palette (16 palettes x 16 colors) to %s\n", *paletteOut)
+	}
+}
+
+func writePNG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
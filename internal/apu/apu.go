@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"nitro-core-dx/internal/debug"
+	"nitro-core-dx/internal/hwdef"
 )
 
 // APU represents the Audio Processing Unit.
@@ -48,6 +49,21 @@ type APU struct {
 
 	// LEGACY (scaffolding): PCM playback for the 4-channel synth.
 	PCMChannels [4]PCMChannel // One PCM channel per legacy audio channel
+
+	// Sample is the DMC-style PCM channel (see sample.go).
+	Sample SampleChannel
+	// MemoryReader fetches sample bytes from the bus.
+	MemoryReader func(bank uint8, offset uint16) uint8
+
+	// Effects is the master low-pass filter and echo (see effects.go).
+	Effects MasterEffects
+
+	// Host-side mute/solo and metering (not hardware; see mixer.go).
+	mixer mixerState
+
+	// noteEvents counts what may legitimately change the output abruptly
+	// (see NoteEvents).
+	noteEvents uint64
 }
 
 // PCMChannel represents a PCM playback channel.
@@ -116,8 +132,9 @@ func NewAPU(sampleRate uint32, logger *debug.Logger) *APU {
 }
 
 // Silence immediately stops all sound output. It disables every legacy synth
-// and PCM channel, clears their phase/duration state, and resets the YM2608 FM
-// subsystem. Used on power-off so a note that is sounding when the machine
+// and PCM channel and the sample channel, clears their phase/duration state,
+// returns the master effects to their power-on state (dropping any echo
+// tail), and resets the YM2608 FM subsystem. Used on power-off so a note that is sounding when the machine
 // stops does not keep playing.
 func (a *APU) Silence() {
 	for i := range a.Channels {
@@ -126,6 +143,8 @@ func (a *APU) Silence() {
 	for i := range a.PCMChannels {
 		a.PCMChannels[i] = PCMChannel{}
 	}
+	a.Sample = SampleChannel{}
+	a.Effects = MasterEffects{}
 	a.ChannelCompletionStatus = 0
 	if a.FM != nil {
 		a.FM.Reset()
@@ -141,13 +160,19 @@ func (a *APU) Read8(offset uint16) uint8 {
 		}
 		return 0
 	}
+	if sampleRegister(offset) {
+		return a.readSample(offset)
+	}
+	if effectsRegister(offset) {
+		return a.readEffects(offset)
+	}
 
 	// Global APU registers (not channel-specific)
 	// Check these BEFORE channel-specific registers
-	if offset == 0x20 { // MASTER_VOLUME
+	if offset == hwdef.MASTER_VOLUME-hwdef.APUBase {
 		return a.MasterVolume
 	}
-	if offset == 0x21 { // CHANNEL_COMPLETION_STATUS
+	if offset == hwdef.COMPLETION_STATUS-hwdef.APUBase {
 		// Read completion status (ONE-SHOT: cleared immediately after read)
 		// Bits 0-3 indicate which channels finished this frame
 		// Status is cleared immediately after being read to prevent multiple updates per frame
@@ -235,6 +260,7 @@ func (a *APU) Read8(offset uint16) uint8 {
 // - No need to manually count frames or loop iterations
 // - Duration in frames (60 frames = 1 second at 60 FPS)
 func (a *APU) Write8(offset uint16, value uint8) {
+	a.noteEvents++
 	// YM2608 audio subsystem host interface (0x9100-0x91FF => APU offsets 0x0100-0x01FF)
 	if offset >= FMExtensionOffsetBase && offset < FMExtensionOffsetBase+0x100 {
 		if a.FM != nil {
@@ -245,6 +271,14 @@ func (a *APU) Write8(offset uint16, value uint8) {
 		}
 		return
 	}
+	if sampleRegister(offset) {
+		a.writeSample(offset, value)
+		return
+	}
+	if effectsRegister(offset) {
+		a.writeEffects(offset, value)
+		return
+	}
 
 	channel := int((offset / 8) & 0x3) // Changed from /4 to /8
 	reg := offset & 0x7                // Changed from &0x3 to &0x7
@@ -386,11 +420,19 @@ func (a *APU) Write8(offset uint16, value uint8) {
 		// Reserved for future expansion
 	}
 
-	if offset == 0x20 { // MASTER_VOLUME (moved to 0x20 since channels are now 8 bytes)
+	if offset == hwdef.MASTER_VOLUME-hwdef.APUBase {
 		a.MasterVolume = value
 	}
 }
 
+// NoteEvents counts the register writes and duration-ended notes the APU
+// has seen: everything that may legitimately change its output abruptly.
+// Output analysis (see harness.ClickDetector) treats a jump in the output
+// right after a change in this count as intended rather than a glitch.
+func (a *APU) NoteEvents() uint64 {
+	return a.noteEvents
+}
+
 // Read16 reads a 16-bit value from APU registers
 func (a *APU) Read16(offset uint16) uint16 {
 	low := a.Read8(offset)
@@ -547,16 +589,26 @@ func (a *APU) GenerateSample() float32 {
 		// Debug logging removed - audio generation is working
 
 		// Add to mix
-		sample += channelSample
+		if a.ChannelAudible(MixerChannel(i)) {
+			sample += channelSample
+		}
 
 		// Advance the compatibility-only float phase accumulator.
 		a.advanceLegacyPhase(ch)
 	}
 
+	sampleOut := float32(a.stepSampleChannel()) / 32768.0
+	if a.ChannelAudible(MixerChannelSample) {
+		sample += sampleOut
+	}
+
 	// Apply master volume
 	masterVol := float32(a.MasterVolume) / 255.0
 	if a.FM != nil {
-		sample += a.FM.GenerateSampleFloat()
+		fmSample := a.FM.GenerateSampleFloat()
+		if a.ChannelAudible(MixerChannelFM) {
+			sample += fmSample
+		}
 	}
 	sample *= masterVol
 
@@ -615,6 +667,7 @@ func (a *APU) UpdateFrame() {
 				} else {
 					// Stop mode: disable channel when duration expires
 					ch.Enabled = false
+					a.noteEvents++
 					// Set completion status bit so ROM can detect it
 					// This flag persists for the entire frame (until next UpdateFrame clears it)
 					a.ChannelCompletionStatus |= (1 << i)
@@ -0,0 +1,70 @@
+package apu
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// Batch sample conversion for host adapters.
+//
+// Hosts receive one emulated frame of mono int16 samples (735 at 60 FPS) and
+// usually feed an interleaved stereo float32 device. These helpers convert a
+// whole frame at once into caller-owned buffers so the per-frame path does
+// not allocate.
+
+// fixedToFloatScale matches ConvertFixedToFloat (x / 32768).
+const fixedToFloatScale = 1.0 / 32768.0
+
+// ConvertFrame converts a frame of fixed-point samples to float32 in a newly
+// allocated slice. Prefer ConvertFrameInto on hot paths.
+func ConvertFrame(samples []int16) []float32 {
+	return ConvertFrameInto(nil, samples)
+}
+
+// ConvertFrameInto converts samples into dst, reusing dst's backing array
+// when it has enough capacity, and returns the filled slice.
+func ConvertFrameInto(dst []float32, samples []int16) []float32 {
+	dst = growFloat32(dst, len(samples))
+	for i, s := range samples {
+		dst[i] = float32(s) * fixedToFloatScale
+	}
+	return dst
+}
+
+// ConvertFrameStereoBytes converts mono fixed-point samples into interleaved
+// stereo little-endian float32 bytes (the layout of an SDL AUDIO_F32 stereo
+// device), duplicating each sample to both channels. dst is reused when it
+// has capacity for len(samples)*8 bytes.
+func ConvertFrameStereoBytes(dst []byte, samples []int16) []byte {
+	dst = growBytes(dst, len(samples)*8)
+	for i, s := range samples {
+		bits := uint64(math.Float32bits(float32(s) * fixedToFloatScale))
+		binary.LittleEndian.PutUint64(dst[i*8:], bits|bits<<32)
+	}
+	return dst
+}
+
+// FloatFrameStereoBytes is ConvertFrameStereoBytes for frames that are
+// already float32 (see APU.GenerateSampleFixedFloat).
+func FloatFrameStereoBytes(dst []byte, samples []float32) []byte {
+	dst = growBytes(dst, len(samples)*8)
+	for i, f := range samples {
+		bits := uint64(math.Float32bits(f))
+		binary.LittleEndian.PutUint64(dst[i*8:], bits|bits<<32)
+	}
+	return dst
+}
+
+func growFloat32(dst []float32, n int) []float32 {
+	if cap(dst) < n {
+		return make([]float32, n)
+	}
+	return dst[:n]
+}
+
+func growBytes(dst []byte, n int) []byte {
+	if cap(dst) < n {
+		return make([]byte, n)
+	}
+	return dst[:n]
+}
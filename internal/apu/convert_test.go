@@ -0,0 +1,157 @@
+package apu
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+func testFrame() []int16 {
+	frame := make([]int16, 735)
+	for i := range frame {
+		frame[i] = int16((i*977)%65536 - 32768)
+	}
+	frame[0] = -32768
+	frame[1] = 32767
+	frame[2] = 0
+	return frame
+}
+
+func TestConvertFrameMatchesPerSample(t *testing.T) {
+	frame := testFrame()
+	got := ConvertFrame(frame)
+	if len(got) != len(frame) {
+		t.Fatalf("expected %d samples, got %d", len(frame), len(got))
+	}
+	for i, s := range frame {
+		if want := ConvertFixedToFloat(s); got[i] != want {
+			t.Fatalf("sample %d: got %v want %v", i, got[i], want)
+		}
+	}
+}
+
+func TestConvertFrameIntoReusesBuffer(t *testing.T) {
+	frame := testFrame()
+	buf := make([]float32, 0, len(frame))
+	out := ConvertFrameInto(buf, frame)
+	if &out[0] != &buf[:1][0] {
+		t.Fatalf("expected ConvertFrameInto to reuse destination backing array")
+	}
+	allocs := testing.AllocsPerRun(20, func() {
+		out = ConvertFrameInto(out, frame)
+	})
+	if allocs != 0 {
+		t.Fatalf("expected zero allocations with reused buffer, got %v", allocs)
+	}
+}
+
+func TestConvertFrameStereoBytesLayout(t *testing.T) {
+	frame := testFrame()
+	out := ConvertFrameStereoBytes(nil, frame)
+	if len(out) != len(frame)*8 {
+		t.Fatalf("expected %d bytes, got %d", len(frame)*8, len(out))
+	}
+	for i, s := range frame {
+		want := math.Float32bits(ConvertFixedToFloat(s))
+		l := binary.LittleEndian.Uint32(out[i*8:])
+		r := binary.LittleEndian.Uint32(out[i*8+4:])
+		if l != want || r != want {
+			t.Fatalf("sample %d: got L=%08X R=%08X want %08X", i, l, r, want)
+		}
+	}
+}
+
+func TestGenerateSampleFixedFloatMatchesFixedPath(t *testing.T) {
+	fixedAPU := NewAPU(44100, nil)
+	dualAPU := NewAPU(44100, nil)
+	for _, a := range []*APU{fixedAPU, dualAPU} {
+		a.FM = nil
+		setupSquare(a, 0, 440)
+		setupSquare(a, 1, 330)
+		a.MasterVolume = 0xC0
+	}
+	for i := 0; i < 1024; i++ {
+		want := fixedAPU.GenerateSampleFixed()
+		gotFixed, gotFloat := dualAPU.GenerateSampleFixedFloat()
+		if gotFixed != want {
+			t.Fatalf("sample %d: fixed result %d, want %d", i, gotFixed, want)
+		}
+		if d := math.Abs(float64(gotFloat) - float64(ConvertFixedToFloat(want))); d > 1.0/32768.0 {
+			t.Fatalf("sample %d: float mix %v too far from fixed %v", i, gotFloat, ConvertFixedToFloat(want))
+		}
+	}
+	if fixedAPU.Channels[0].PhaseFixed != dualAPU.Channels[0].PhaseFixed {
+		t.Fatalf("float mixing path advanced state differently")
+	}
+}
+
+// BenchmarkConvertFramePerSampleLoop is the pre-batch host path: allocate a
+// float slice per frame and call ConvertFixedToFloat, then pack stereo bytes
+// with two PutUint32 calls per sample.
+func BenchmarkConvertFramePerSampleLoop(b *testing.B) {
+	frame := testFrame()
+	out := make([]byte, len(frame)*8)
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		floats := make([]float32, len(frame))
+		for i, s := range frame {
+			floats[i] = ConvertFixedToFloat(s)
+		}
+		j := 0
+		for _, f := range floats {
+			bits := math.Float32bits(f)
+			binary.LittleEndian.PutUint32(out[j:j+4], bits)
+			binary.LittleEndian.PutUint32(out[j+4:j+8], bits)
+			j += 8
+		}
+	}
+}
+
+func BenchmarkConvertFrameInto(b *testing.B) {
+	frame := testFrame()
+	buf := make([]float32, len(frame))
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		buf = ConvertFrameInto(buf, frame)
+	}
+}
+
+func BenchmarkConvertFrameStereoBytes(b *testing.B) {
+	frame := testFrame()
+	buf := make([]byte, len(frame)*8)
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		buf = ConvertFrameStereoBytes(buf, frame)
+	}
+}
+
+func BenchmarkGenerateFrameFixedThenConvert(b *testing.B) {
+	a := NewAPU(44100, nil)
+	a.FM = nil
+	setupSquare(a, 0, 440)
+	setupSquare(a, 1, 330)
+	fixed := make([]int16, 735)
+	floats := make([]float32, 735)
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		for i := range fixed {
+			fixed[i] = a.GenerateSampleFixed()
+		}
+		floats = ConvertFrameInto(floats, fixed)
+	}
+}
+
+func BenchmarkGenerateFrameFloatMix(b *testing.B) {
+	a := NewAPU(44100, nil)
+	a.FM = nil
+	setupSquare(a, 0, 440)
+	setupSquare(a, 1, 330)
+	fixed := make([]int16, 735)
+	floats := make([]float32, 735)
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		for i := range fixed {
+			fixed[i], floats[i] = a.GenerateSampleFixedFloat()
+		}
+	}
+}
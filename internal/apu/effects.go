@@ -0,0 +1,94 @@
+package apu
+
+import "nitro-core-dx/internal/hwdef"
+
+// MasterEffects is the master effects stage between the channel mix and
+// master volume: a one-pole low-pass filter followed by an echo.
+//
+// Register layout (0x9031-0x9034):
+//
+//	LOWPASS        - low-pass strength; 0 turns the filter off, higher
+//	                 values cut more of the highs
+//	ECHO_DELAY     - echo delay in 4 ms steps (up to 1020 ms); 0 turns the
+//	                 echo off
+//	ECHO_FEEDBACK  - share of the delayed signal fed back into the echo
+//	                 buffer (value/256), i.e. how long the echo rings
+//	ECHO_MIX       - level of the delayed signal in the output (value/256)
+//
+// All four power on as 0, so the stage starts out transparent. The filter
+// runs on the dry mix: y += (x - y) * (256 - LOWPASS) / 256 per sample. The
+// echo then adds the buffer's delayed sample to the output and writes the
+// filtered mix plus the fed-back delayed sample into the buffer.
+type MasterEffects struct {
+	Lowpass      uint8
+	EchoDelay    uint8
+	EchoFeedback uint8
+	EchoMix      uint8
+
+	// Processing state. Exported so save states capture it.
+	Filter  int32   // filter output, 24.8 fixed point
+	Echo    []int16 // ring buffer, allocated when the echo is first used
+	EchoPos int
+}
+
+// echoDelayStepMS is the unit of ECHO_DELAY.
+const echoDelayStepMS = 4
+
+// effectsRegister reports whether an APU offset is a master effects register.
+func effectsRegister(offset uint16) bool {
+	return offset >= hwdef.LOWPASS-hwdef.APUBase && offset <= hwdef.ECHO_MIX-hwdef.APUBase
+}
+
+func (a *APU) readEffects(offset uint16) uint8 {
+	fx := &a.Effects
+	switch offset + hwdef.APUBase {
+	case hwdef.LOWPASS:
+		return fx.Lowpass
+	case hwdef.ECHO_DELAY:
+		return fx.EchoDelay
+	case hwdef.ECHO_FEEDBACK:
+		return fx.EchoFeedback
+	case hwdef.ECHO_MIX:
+		return fx.EchoMix
+	}
+	return 0
+}
+
+func (a *APU) writeEffects(offset uint16, value uint8) {
+	fx := &a.Effects
+	switch offset + hwdef.APUBase {
+	case hwdef.LOWPASS:
+		fx.Lowpass = value
+	case hwdef.ECHO_DELAY:
+		fx.EchoDelay = value
+		if value == 0 {
+			// Off drops the buffer, so turning the echo back on does not
+			// replay a stale tail.
+			fx.Echo, fx.EchoPos = nil, 0
+		}
+	case hwdef.ECHO_FEEDBACK:
+		fx.EchoFeedback = value
+	case hwdef.ECHO_MIX:
+		fx.EchoMix = value
+	}
+}
+
+// applyMasterEffects runs one mixed sample through the filter and echo.
+func (a *APU) applyMasterEffects(sample int32) int32 {
+	fx := &a.Effects
+	fx.Filter += int32(int64(sample<<8-fx.Filter) * (256 - int64(fx.Lowpass)) >> 8)
+	sample = fx.Filter >> 8
+	if fx.EchoDelay == 0 || a.SampleRate == 0 {
+		return sample
+	}
+	if fx.Echo == nil {
+		fx.Echo = make([]int16, 0xFF*echoDelayStepMS*int(a.SampleRate)/1000+1)
+		fx.EchoPos = 0
+	}
+	n := len(fx.Echo)
+	delay := min(int(fx.EchoDelay)*echoDelayStepMS*int(a.SampleRate)/1000, n-1)
+	delayed := int32(fx.Echo[(fx.EchoPos-delay+n)%n])
+	fx.Echo[fx.EchoPos] = clampSample16(sample + delayed*int32(fx.EchoFeedback)>>8)
+	fx.EchoPos = (fx.EchoPos + 1) % n
+	return sample + delayed*int32(fx.EchoMix)>>8
+}
@@ -0,0 +1,73 @@
+package apu
+
+import (
+	"testing"
+
+	"nitro-core-dx/internal/hwdef"
+)
+
+func TestMasterEffectsPowerOnTransparent(t *testing.T) {
+	a := NewAPU(8000, nil)
+	for _, x := range []int32{1000, -20000, 5, 32767} {
+		if got := a.applyMasterEffects(x); got != x {
+			t.Fatalf("effects off changed %d to %d", x, got)
+		}
+	}
+}
+
+func TestMasterLowpassSmoothsSteps(t *testing.T) {
+	a := NewAPU(8000, nil)
+	a.Write8(hwdef.LOWPASS-hwdef.APUBase, 192)
+	prev := int32(0)
+	for i := 0; i < 128; i++ {
+		got := a.applyMasterEffects(16000)
+		if got < prev || got > 16000 {
+			t.Fatalf("sample %d: %d after %d, want a steady rise toward 16000", i, got, prev)
+		}
+		if i == 0 && got > 16000/2 {
+			t.Fatalf("first sample %d: filter too open", got)
+		}
+		prev = got
+	}
+	if prev < 15990 {
+		t.Errorf("filter settled at %d, want 16000", prev)
+	}
+}
+
+func TestMasterEchoRepeatsImpulse(t *testing.T) {
+	a := NewAPU(8000, nil)
+	write := func(reg uint16, v uint8) { a.Write8(reg-hwdef.APUBase, v) }
+	write(hwdef.ECHO_FEEDBACK, 128)
+	write(hwdef.ECHO_MIX, 128)
+	write(hwdef.ECHO_DELAY, 5) // 20 ms: 160 samples at 8 kHz
+
+	var out []int32
+	for i := 0; i < 400; i++ {
+		x := int32(0)
+		if i == 0 {
+			x = 16000
+		}
+		out = append(out, a.applyMasterEffects(x))
+	}
+	// Mix halves the first repeat; feedback halves each repeat again.
+	for i, want := range map[int]int32{0: 16000, 1: 0, 159: 0, 160: 8000, 161: 0, 320: 4000} {
+		if out[i] != want {
+			t.Errorf("out[%d] = %d, want %d", i, out[i], want)
+		}
+	}
+
+	write(hwdef.ECHO_DELAY, 0)
+	if a.Effects.Echo != nil {
+		t.Error("echo off kept its buffer")
+	}
+	write(hwdef.ECHO_DELAY, 5)
+	for i := 0; i < 400; i++ {
+		if got := a.applyMasterEffects(0); got != 0 {
+			t.Fatalf("re-enabled echo replayed a stale tail: out[%d] = %d", i, got)
+		}
+	}
+	a.Silence()
+	if a.Effects.EchoDelay != 0 || a.Effects.Echo != nil {
+		t.Errorf("Silence kept the echo: %+v", a.Effects.EchoDelay)
+	}
+}
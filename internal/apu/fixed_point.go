@@ -45,6 +45,31 @@ func (a *APU) updatePhaseIncrementFixed(channel int) {
 // Host adapter converts this to float32
 // This is the preferred method for clock-driven operation
 func (a *APU) GenerateSampleFixed() int16 {
+	return clampSample16((a.mixSampleFixed() * int32(a.MasterVolume)) / 255)
+}
+
+// GenerateSampleFixedFloat generates one sample and returns it both as the
+// canonical 16-bit fixed-point value and as a natively mixed float32.
+//
+// Both results come from the same channel mix, so emulated state advances
+// exactly as with GenerateSampleFixed. The float value applies master volume
+// and clamping in floating point instead of quantizing to int16 first, which
+// lets float hosts skip the per-sample ConvertFixedToFloat step.
+func (a *APU) GenerateSampleFixedFloat() (int16, float32) {
+	mix := a.mixSampleFixed()
+	fixed := clampSample16((mix * int32(a.MasterVolume)) / 255)
+	f := float32(mix) * (float32(a.MasterVolume) / (255.0 * 32768.0))
+	if f > 1.0 {
+		f = 1.0
+	} else if f < -1.0 {
+		f = -1.0
+	}
+	return fixed, f
+}
+
+// mixSampleFixed advances every channel by one sample and returns the
+// unclamped mix, through the master effects, before master volume.
+func (a *APU) mixSampleFixed() int32 {
 	var sample int32 = 0
 
 	for i := 0; i < 4; i++ {
@@ -97,28 +122,42 @@ func (a *APU) GenerateSampleFixed() int16 {
 		// Using fixed-point: (sample * volume) / 255
 		channelSample = (channelSample * int32(ch.Volume)) / 255
 
-		// Add to mix
-		sample += channelSample
+		// Add to mix (meters tap the channel before mute/solo)
+		a.mixer.observeChannel(MixerChannel(i), channelSample)
+		if a.ChannelAudible(MixerChannel(i)) {
+			sample += channelSample
+		}
 
 		// Update phase accumulator for next sample
 		ch.PhaseFixed += ch.PhaseIncrementFixed
 		// Phase wraps automatically (uint32 overflow)
 	}
 
+	pcm := a.stepSampleChannel()
+	a.mixer.observeChannel(MixerChannelSample, pcm)
+	if a.ChannelAudible(MixerChannelSample) {
+		sample += pcm
+	}
+
 	if a.FM != nil {
-		sample += int32(a.FM.GenerateSampleFixed())
+		fmSample := int32(a.FM.GenerateSampleFixed())
+		a.mixer.observeChannel(MixerChannelFM, fmSample)
+		if a.ChannelAudible(MixerChannelFM) {
+			sample += fmSample
+		}
 	}
+	a.mixer.advance()
 
-	// Apply master volume
-	sample = (sample * int32(a.MasterVolume)) / 255
+	return a.applyMasterEffects(sample)
+}
 
-	// Clamp to valid range [-32768, 32767]
+// clampSample16 clamps a mixed sample to the valid range [-32768, 32767].
+func clampSample16(sample int32) int16 {
 	if sample > 32767 {
-		sample = 32767
+		return 32767
 	} else if sample < -32768 {
-		sample = -32768
+		return -32768
 	}
-
 	return int16(sample)
 }
 
@@ -4,28 +4,32 @@ import (
 	"math"
 
 	"nitro-core-dx/internal/debug"
+	"nitro-core-dx/internal/hwdef"
 )
 
 const (
 	// FMExtensionOffsetBase is the APU-internal offset base for the YM2608 audio
 	// subsystem host interface. CPU-visible address 0x9100 maps to APU offset
 	// 0x0100. (Name kept for API stability.)
-	FMExtensionOffsetBase = 0x0100
+	FMExtensionOffsetBase = hwdef.FMBase - hwdef.APUBase
 
 	// YM2608 host interface registers (relative to FMExtensionOffsetBase).
-	FMRegAddr    = 0x00 // OPM register address select
-	FMRegData    = 0x01 // OPM register data port
-	FMRegStatus  = 0x02 // Busy/timer/IRQ flags (stubbed in phase 1)
-	FMRegControl = 0x03 // Enable/mute/reset
-	FMRegMixL    = 0x04 // Left mix gain (future stereo path)
-	FMRegMixR    = 0x05 // Right mix gain (future stereo path)
+	FMRegAddr    = hwdef.FM_ADDR - hwdef.FMBase    // OPM register address select
+	FMRegData    = hwdef.FM_DATA - hwdef.FMBase    // OPM register data port
+	FMRegStatus  = hwdef.FM_STATUS - hwdef.FMBase  // Busy/timer/IRQ flags (stubbed in phase 1)
+	FMRegControl = hwdef.FM_CONTROL - hwdef.FMBase // Enable/mute/reset
+	// FMRegMixL and FMRegMixR are left/right mix gain in the OPM backend
+	// (future stereo path); the YMFM backend uses them as the port-1
+	// register-select pair (FM_PORT1_ADDR/FM_PORT1_DATA).
+	FMRegMixL = hwdef.FM_PORT1_ADDR - hwdef.FMBase
+	FMRegMixR = hwdef.FM_PORT1_DATA - hwdef.FMBase
 	// FMRegVolume is a dedicated master output gain (0-255, default 255 =
 	// unattenuated), applied to the final generated sample regardless of
 	// which FM backend produced it. Added for music.set_volume/fade_to
 	// (internal/corelx's music.* builtins) — FMRegMixL/FMRegMixR were not
 	// usable for this: the production YMFM backend consumes them as the
 	// port-1 register-select pair (see fm_backend_ymfm_cgo.go), not gain.
-	FMRegVolume = 0x06
+	FMRegVolume = hwdef.FM_VOLUME - hwdef.FMBase
 )
 
 const (
@@ -0,0 +1,223 @@
+package apu
+
+// Host-side mixer controls and metering.
+//
+// Mute/solo and the level/scope taps below are debugging aids for the host
+// (Dev Kit audio panel, tests). They are NOT hardware: nothing here is visible
+// through MMIO, and muting a channel never changes emulated state — phase
+// accumulators, LFSRs, PCM positions and FM timers keep advancing exactly as
+// they would if the channel were audible. Only the contribution to the final
+// mix is dropped.
+
+// MixerChannel identifies one source in the APU output mix.
+type MixerChannel int
+
+const (
+	// MixerChannelLegacy0-3 are the four LEGACY synth/PCM channels.
+	MixerChannelLegacy0 MixerChannel = iota
+	MixerChannelLegacy1
+	MixerChannelLegacy2
+	MixerChannelLegacy3
+	// MixerChannelFM is the whole YM2608 / OPNA subsystem output. The YMFM
+	// backend only exposes a pre-mixed sample, so FM voices are metered as a
+	// single source.
+	MixerChannelFM
+	// MixerChannelSample is the DMC-style sample channel.
+	MixerChannelSample
+
+	// MixerChannelCount is the number of mixer sources.
+	MixerChannelCount
+)
+
+// MixerScopeSize is the number of most-recent samples kept per channel for
+// waveform scope display.
+const MixerScopeSize = 512
+
+var mixerChannelNames = [MixerChannelCount]string{
+	"CH0", "CH1", "CH2", "CH3", "FM", "SMP",
+}
+
+// String returns a short display name for the channel ("CH0".."CH3", "FM",
+// "SMP").
+func (c MixerChannel) String() string {
+	if c < 0 || c >= MixerChannelCount {
+		return "?"
+	}
+	return mixerChannelNames[c]
+}
+
+// MixerChannelLevel is a per-channel metering snapshot.
+type MixerChannelLevel struct {
+	Channel MixerChannel
+	Muted   bool
+	Solo    bool
+	// Audible reports whether the channel currently reaches the output mix
+	// (not muted, and either no channel is soloed or this one is).
+	Audible bool
+	// Peak is the largest absolute sample value since the previous
+	// TakeMixerLevels call (0-32768).
+	Peak int32
+	// RMS is the root-mean-square level over the same window (0-32768).
+	RMS int32
+}
+
+// mixerState holds host-only mute/solo flags and metering taps.
+type mixerState struct {
+	muted [MixerChannelCount]bool
+	solo  [MixerChannelCount]bool
+
+	peak    [MixerChannelCount]int32
+	sumSq   [MixerChannelCount]uint64
+	samples uint64
+
+	scope    [MixerChannelCount][MixerScopeSize]int16
+	scopePos int
+}
+
+// SetChannelMuted mutes or unmutes a mixer channel. Out-of-range channels are
+// ignored.
+func (a *APU) SetChannelMuted(ch MixerChannel, muted bool) {
+	if ch < 0 || ch >= MixerChannelCount {
+		return
+	}
+	a.mixer.muted[ch] = muted
+}
+
+// SetChannelSolo sets or clears solo on a mixer channel. While any channel is
+// soloed, only soloed (and unmuted) channels are audible.
+func (a *APU) SetChannelSolo(ch MixerChannel, solo bool) {
+	if ch < 0 || ch >= MixerChannelCount {
+		return
+	}
+	a.mixer.solo[ch] = solo
+}
+
+// ChannelMuted reports the mute flag for a mixer channel.
+func (a *APU) ChannelMuted(ch MixerChannel) bool {
+	if ch < 0 || ch >= MixerChannelCount {
+		return false
+	}
+	return a.mixer.muted[ch]
+}
+
+// ChannelSolo reports the solo flag for a mixer channel.
+func (a *APU) ChannelSolo(ch MixerChannel) bool {
+	if ch < 0 || ch >= MixerChannelCount {
+		return false
+	}
+	return a.mixer.solo[ch]
+}
+
+// ClearMuteSolo restores every channel to audible.
+func (a *APU) ClearMuteSolo() {
+	a.mixer.muted = [MixerChannelCount]bool{}
+	a.mixer.solo = [MixerChannelCount]bool{}
+}
+
+// ChannelAudible reports whether a channel currently contributes to the mix.
+func (a *APU) ChannelAudible(ch MixerChannel) bool {
+	if ch < 0 || ch >= MixerChannelCount {
+		return false
+	}
+	if a.mixer.muted[ch] {
+		return false
+	}
+	for _, s := range a.mixer.solo {
+		if s {
+			return a.mixer.solo[ch]
+		}
+	}
+	return true
+}
+
+// TakeMixerLevels returns peak/RMS levels per channel accumulated since the
+// previous call and resets the accumulators. Levels are measured before
+// mute/solo is applied, so a muted channel still shows its activity.
+func (a *APU) TakeMixerLevels() [MixerChannelCount]MixerChannelLevel {
+	var out [MixerChannelCount]MixerChannelLevel
+	m := &a.mixer
+	for i := MixerChannel(0); i < MixerChannelCount; i++ {
+		lvl := MixerChannelLevel{
+			Channel: i,
+			Muted:   m.muted[i],
+			Solo:    m.solo[i],
+			Audible: a.ChannelAudible(i),
+			Peak:    m.peak[i],
+		}
+		if m.samples > 0 {
+			lvl.RMS = int32(isqrt64(m.sumSq[i] / m.samples))
+		}
+		out[i] = lvl
+		m.peak[i] = 0
+		m.sumSq[i] = 0
+	}
+	m.samples = 0
+	return out
+}
+
+// CopyChannelScope copies the most recent samples for a channel into dst in
+// chronological order (oldest first) and returns the number copied (at most
+// MixerScopeSize). Like the level meters, the scope taps the channel before
+// mute/solo.
+func (a *APU) CopyChannelScope(ch MixerChannel, dst []int16) int {
+	if ch < 0 || ch >= MixerChannelCount {
+		return 0
+	}
+	n := len(dst)
+	if n > MixerScopeSize {
+		n = MixerScopeSize
+	}
+	ring := &a.mixer.scope[ch]
+	start := a.mixer.scopePos - n
+	if start < 0 {
+		start += MixerScopeSize
+	}
+	for i := 0; i < n; i++ {
+		dst[i] = ring[(start+i)%MixerScopeSize]
+	}
+	return n
+}
+
+// observeChannel records one channel sample into the meters and scope.
+func (m *mixerState) observeChannel(ch MixerChannel, sample int32) {
+	if sample > 32767 {
+		sample = 32767
+	} else if sample < -32768 {
+		sample = -32768
+	}
+	abs := sample
+	if abs < 0 {
+		abs = -abs
+	}
+	if abs > m.peak[ch] {
+		m.peak[ch] = abs
+	}
+	m.sumSq[ch] += uint64(abs) * uint64(abs)
+	m.scope[ch][m.scopePos] = int16(sample)
+}
+
+// advance closes out one output sample for all channels. Channels that were
+// not observed this sample (disabled) record silence in the scope.
+func (m *mixerState) advance() {
+	m.samples++
+	m.scopePos++
+	if m.scopePos >= MixerScopeSize {
+		m.scopePos = 0
+	}
+	for ch := range m.scope {
+		m.scope[ch][m.scopePos] = 0
+	}
+}
+
+func isqrt64(v uint64) uint64 {
+	if v < 2 {
+		return v
+	}
+	x := v
+	y := (x + 1) / 2
+	for y < x {
+		x = y
+		y = (x + v/x) / 2
+	}
+	return x
+}
@@ -0,0 +1,118 @@
+package apu
+
+import "testing"
+
+// setupSquare enables a legacy square-wave channel at full volume.
+func setupSquare(a *APU, ch int, freq uint16) {
+	a.Write8(uint16(ch*8+0), uint8(freq))
+	a.Write8(uint16(ch*8+1), uint8(freq>>8))
+	a.Write8(uint16(ch*8+2), 0x80)
+	a.Write8(uint16(ch*8+3), 0x03) // enable, waveform=square
+}
+
+func TestMixerMuteDropsChannelButKeepsState(t *testing.T) {
+	ref := NewAPU(44100, nil)
+	muted := NewAPU(44100, nil)
+	for _, a := range []*APU{ref, muted} {
+		a.FM = nil
+		setupSquare(a, 0, 440)
+	}
+	muted.SetChannelMuted(MixerChannelLegacy0, true)
+
+	for i := 0; i < 256; i++ {
+		ref.GenerateSampleFixed()
+		if got := muted.GenerateSampleFixed(); got != 0 {
+			t.Fatalf("sample %d: muted channel leaked into mix: %d", i, got)
+		}
+	}
+	if ref.Channels[0].PhaseFixed != muted.Channels[0].PhaseFixed {
+		t.Fatalf("mute changed emulated phase: ref=%08X muted=%08X",
+			ref.Channels[0].PhaseFixed, muted.Channels[0].PhaseFixed)
+	}
+
+	levels := muted.TakeMixerLevels()
+	if !levels[MixerChannelLegacy0].Muted || levels[MixerChannelLegacy0].Audible {
+		t.Fatalf("expected CH0 muted and inaudible, got %+v", levels[MixerChannelLegacy0])
+	}
+	if levels[MixerChannelLegacy0].Peak == 0 || levels[MixerChannelLegacy0].RMS == 0 {
+		t.Fatalf("expected muted channel to still meter activity, got %+v", levels[MixerChannelLegacy0])
+	}
+	if again := muted.TakeMixerLevels(); again[MixerChannelLegacy0].Peak != 0 {
+		t.Fatalf("expected levels to reset after take, got peak %d", again[MixerChannelLegacy0].Peak)
+	}
+}
+
+func TestMixerSoloIsolatesChannel(t *testing.T) {
+	both := NewAPU(44100, nil)
+	solo := NewAPU(44100, nil)
+	only1 := NewAPU(44100, nil)
+	for _, a := range []*APU{both, solo, only1} {
+		a.FM = nil
+	}
+	setupSquare(both, 0, 440)
+	setupSquare(both, 1, 660)
+	setupSquare(solo, 0, 440)
+	setupSquare(solo, 1, 660)
+	setupSquare(only1, 1, 660)
+	solo.SetChannelSolo(MixerChannelLegacy1, true)
+
+	if solo.ChannelAudible(MixerChannelLegacy0) {
+		t.Fatalf("expected CH0 inaudible while CH1 soloed")
+	}
+	for i := 0; i < 256; i++ {
+		both.GenerateSampleFixed()
+		got := solo.GenerateSampleFixed()
+		want := only1.GenerateSampleFixed()
+		if got != want {
+			t.Fatalf("sample %d: solo CH1 = %d, want %d", i, got, want)
+		}
+	}
+
+	solo.SetChannelMuted(MixerChannelLegacy1, true)
+	if solo.ChannelAudible(MixerChannelLegacy1) {
+		t.Fatalf("expected mute to override solo")
+	}
+	solo.ClearMuteSolo()
+	for ch := MixerChannel(0); ch < MixerChannelCount; ch++ {
+		if !solo.ChannelAudible(ch) {
+			t.Fatalf("expected %s audible after ClearMuteSolo", ch)
+		}
+	}
+}
+
+func TestMixerScopeReturnsRecentSamplesInOrder(t *testing.T) {
+	a := NewAPU(44100, nil)
+	a.FM = nil
+	a.Write8(0x00, 0xFF)
+	a.Write8(0x01, 0x00)
+	a.Write8(0x02, 0xFF)
+	a.Write8(0x03, 0x05) // enable, waveform=saw
+
+	want := make([]int16, 8)
+	for i := 0; i < MixerScopeSize+len(want); i++ {
+		ch := &a.Channels[0]
+		expected := (int32(int64(ch.PhaseFixed>>16)-32768) * int32(ch.Volume)) / 255
+		if i >= MixerScopeSize {
+			want[i-MixerScopeSize] = int16(expected)
+		}
+		a.GenerateSampleFixed()
+	}
+
+	got := make([]int16, len(want))
+	if n := a.CopyChannelScope(MixerChannelLegacy0, got); n != len(want) {
+		t.Fatalf("expected %d scope samples, got %d", len(want), n)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("scope[%d] = %d, want %d (got %v want %v)", i, got[i], want[i], got, want)
+		}
+	}
+
+	silent := make([]int16, 4)
+	a.CopyChannelScope(MixerChannelLegacy2, silent)
+	for i, v := range silent {
+		if v != 0 {
+			t.Fatalf("expected disabled channel scope to be silent, scope[%d]=%d", i, v)
+		}
+	}
+}
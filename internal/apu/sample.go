@@ -0,0 +1,158 @@
+package apu
+
+import "nitro-core-dx/internal/hwdef"
+
+// SampleChannel is the fifth APU channel: a DMC-style player for 8-bit
+// signed PCM. It reads sample bytes straight from memory through
+// APU.MemoryReader as playback reaches them, so sample data stays in ROM
+// instead of being copied into RAM first.
+//
+// Register layout (0x9028-0x9030):
+//
+//	SAMPLE_BANK        - bank of the first sample byte
+//	SAMPLE_ADDR_L/H    - offset of the first sample byte
+//	SAMPLE_LENGTH_L/H  - number of sample bytes
+//	SAMPLE_RATE_L/H    - playback rate in Hz
+//	SAMPLE_VOLUME      - volume (0-255)
+//	SAMPLE_CONTROL     - bit 0 PLAY, bit 1 LOOP
+//
+// Writing SAMPLE_CONTROL with PLAY set latches the bank, address and length
+// and restarts playback from the first byte; writing it with PLAY clear
+// stops. Reading it reports whether a sample is still playing. Like the
+// YM burst streamer, a fetch past offset 0xFFFF continues at 0x8000 of the
+// next bank, so a sample may cross ROM banks.
+type SampleChannel struct {
+	Bank   uint8
+	Addr   uint16
+	Length uint16
+	Rate   uint16
+	Volume uint8
+	Loop   bool
+
+	// Playback state. Exported so save states capture it.
+	Playing   bool
+	FetchBank uint8
+	FetchAddr uint16
+	Remaining uint16 // bytes not yet fetched
+	Phase     uint32 // 16.16 progress toward the next fetch
+	Current   int8   // sample being output
+}
+
+// sampleRegister reports whether an APU offset is a sample channel register.
+func sampleRegister(offset uint16) bool {
+	return offset >= hwdef.SAMPLE_BANK-hwdef.APUBase && offset <= hwdef.SAMPLE_CONTROL-hwdef.APUBase
+}
+
+func (a *APU) readSample(offset uint16) uint8 {
+	s := &a.Sample
+	switch offset + hwdef.APUBase {
+	case hwdef.SAMPLE_BANK:
+		return s.Bank
+	case hwdef.SAMPLE_ADDR_L:
+		return uint8(s.Addr)
+	case hwdef.SAMPLE_ADDR_H:
+		return uint8(s.Addr >> 8)
+	case hwdef.SAMPLE_LENGTH_L:
+		return uint8(s.Length)
+	case hwdef.SAMPLE_LENGTH_H:
+		return uint8(s.Length >> 8)
+	case hwdef.SAMPLE_RATE_L:
+		return uint8(s.Rate)
+	case hwdef.SAMPLE_RATE_H:
+		return uint8(s.Rate >> 8)
+	case hwdef.SAMPLE_VOLUME:
+		return s.Volume
+	case hwdef.SAMPLE_CONTROL:
+		control := uint8(0)
+		if s.Playing {
+			control |= 0x01
+		}
+		if s.Loop {
+			control |= 0x02
+		}
+		return control
+	}
+	return 0
+}
+
+func (a *APU) writeSample(offset uint16, value uint8) {
+	s := &a.Sample
+	switch offset + hwdef.APUBase {
+	case hwdef.SAMPLE_BANK:
+		s.Bank = value
+	case hwdef.SAMPLE_ADDR_L:
+		s.Addr = (s.Addr & 0xFF00) | uint16(value)
+	case hwdef.SAMPLE_ADDR_H:
+		s.Addr = (s.Addr & 0x00FF) | uint16(value)<<8
+	case hwdef.SAMPLE_LENGTH_L:
+		s.Length = (s.Length & 0xFF00) | uint16(value)
+	case hwdef.SAMPLE_LENGTH_H:
+		s.Length = (s.Length & 0x00FF) | uint16(value)<<8
+	case hwdef.SAMPLE_RATE_L:
+		s.Rate = (s.Rate & 0xFF00) | uint16(value)
+	case hwdef.SAMPLE_RATE_H:
+		s.Rate = (s.Rate & 0x00FF) | uint16(value)<<8
+	case hwdef.SAMPLE_VOLUME:
+		s.Volume = value
+	case hwdef.SAMPLE_CONTROL:
+		s.Loop = value&0x02 != 0
+		if value&0x01 == 0 {
+			s.Playing = false
+			s.Current = 0
+			return
+		}
+		a.restartSample()
+	}
+}
+
+// restartSample rewinds to the latched start and fetches the first byte.
+func (a *APU) restartSample() {
+	s := &a.Sample
+	s.FetchBank = s.Bank
+	s.FetchAddr = s.Addr
+	s.Remaining = s.Length
+	s.Phase = 0
+	s.Playing = true
+	a.fetchSampleByte()
+}
+
+// fetchSampleByte loads the next sample byte into Current. At the end of
+// the data it loops or stops.
+func (a *APU) fetchSampleByte() {
+	s := &a.Sample
+	if s.Remaining == 0 {
+		if !s.Loop || s.Length == 0 {
+			s.Playing = false
+			s.Current = 0
+			return
+		}
+		s.FetchBank = s.Bank
+		s.FetchAddr = s.Addr
+		s.Remaining = s.Length
+	}
+	if a.MemoryReader != nil {
+		s.Current = int8(a.MemoryReader(s.FetchBank, s.FetchAddr))
+	}
+	s.FetchAddr++
+	if s.FetchAddr == 0 {
+		s.FetchAddr = 0x8000
+		s.FetchBank++
+	}
+	s.Remaining--
+}
+
+// stepSampleChannel returns the sample channel's output for one sample
+// period (16-bit scale, volume applied) and advances playback.
+func (a *APU) stepSampleChannel() int32 {
+	s := &a.Sample
+	if !s.Playing || a.SampleRate == 0 {
+		return 0
+	}
+	out := int32(s.Current) * 256 * int32(s.Volume) / 255
+	s.Phase += (uint32(s.Rate) << 16) / a.SampleRate
+	for s.Phase >= 1<<16 && s.Playing {
+		s.Phase -= 1 << 16
+		a.fetchSampleByte()
+	}
+	return out
+}
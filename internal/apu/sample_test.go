@@ -0,0 +1,71 @@
+package apu
+
+import (
+	"testing"
+
+	"nitro-core-dx/internal/hwdef"
+)
+
+// newSampleTestAPU returns an APU whose sample channel reads data from
+// bank 2, offset 0xFFFE onward, so playback crosses a bank boundary.
+func newSampleTestAPU(data []int8) *APU {
+	a := NewAPU(8000, nil)
+	a.FM = nil
+	a.MemoryReader = func(bank uint8, offset uint16) uint8 {
+		i := int(bank-2)*0x8000 + int(offset) - 0xFFFE
+		if bank < 2 || i < 0 || i >= len(data) {
+			return 0x55
+		}
+		return uint8(data[i])
+	}
+	write := func(reg uint16, v uint8) { a.Write8(reg-hwdef.APUBase, v) }
+	write(hwdef.SAMPLE_BANK, 2)
+	write(hwdef.SAMPLE_ADDR_L, 0xFE)
+	write(hwdef.SAMPLE_ADDR_H, 0xFF)
+	write(hwdef.SAMPLE_LENGTH_L, uint8(len(data)))
+	write(hwdef.SAMPLE_LENGTH_H, 0)
+	write(hwdef.SAMPLE_RATE_L, 0xA0) // 4000 Hz: each byte lasts two output samples
+	write(hwdef.SAMPLE_RATE_H, 0x0F)
+	write(hwdef.SAMPLE_VOLUME, 255)
+	return a
+}
+
+func TestSampleChannelPlaysFromMemory(t *testing.T) {
+	data := []int8{10, -20, 30, -40}
+	a := newSampleTestAPU(data)
+	a.Write8(hwdef.SAMPLE_CONTROL-hwdef.APUBase, 0x01)
+
+	var got []int16
+	for i := 0; i < 10; i++ {
+		got = append(got, a.GenerateSampleFixed())
+	}
+	want := []int16{2560, 2560, -5120, -5120, 7680, 7680, -10240, -10240, 0, 0}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("output = %v, want %v", got, want)
+		}
+	}
+	if a.Read8(hwdef.SAMPLE_CONTROL-hwdef.APUBase)&0x01 != 0 {
+		t.Error("PLAY still set after the last byte")
+	}
+}
+
+func TestSampleChannelLoopsAndStops(t *testing.T) {
+	data := []int8{1, 2}
+	a := newSampleTestAPU(data)
+	a.Write8(hwdef.SAMPLE_CONTROL-hwdef.APUBase, 0x03)
+	for i := 0; i < 20; i++ {
+		a.GenerateSampleFixed()
+	}
+	if !a.Sample.Playing {
+		t.Fatal("looping sample stopped")
+	}
+	if got := a.Read8(hwdef.SAMPLE_CONTROL - hwdef.APUBase); got != 0x03 {
+		t.Errorf("SAMPLE_CONTROL = 0x%02X, want 0x03", got)
+	}
+
+	a.Write8(hwdef.SAMPLE_CONTROL-hwdef.APUBase, 0x00)
+	if out := a.GenerateSampleFixed(); out != 0 || a.Sample.Playing {
+		t.Errorf("after stop: output %d, playing %v", out, a.Sample.Playing)
+	}
+}
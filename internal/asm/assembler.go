@@ -5,9 +5,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 
+	"nitro-core-dx/internal/hwdef"
+	"nitro-core-dx/internal/link"
 	"nitro-core-dx/internal/rom"
 )
 
@@ -41,6 +44,14 @@ type Assembler struct {
 
 	stmts  []statement
 	labels map[string]int // code word index
+
+	// Object mode (AssembleObject): labels named by .global are exported,
+	// names declared with .extern may be referenced, and symbolic
+	// immediates become relocations instead of baked-in values.
+	object  bool
+	globals map[string]string // upper-case label -> exported spelling
+	externs map[string]string // upper-case name -> declared spelling
+	relocs  []link.Reloc
 }
 
 func defaultOptions() Options {
@@ -69,7 +80,7 @@ func AssembleSource(source, path string, opts *Options) (*Result, error) {
 	if path == "" {
 		path = "<buffer>"
 	}
-	a := &Assembler{source: source, path: path, opts: cfg, labels: make(map[string]int)}
+	a := newAssembler(source, path, cfg)
 	if err := a.parse(); err != nil {
 		return nil, err
 	}
@@ -88,6 +99,89 @@ func AssembleSource(source, path string, opts *Options) (*Result, error) {
 	return res, nil
 }
 
+func newAssembler(source, path string, cfg Options) *Assembler {
+	return &Assembler{
+		source:  source,
+		path:    path,
+		opts:    cfg,
+		labels:  make(map[string]int),
+		globals: make(map[string]string),
+		externs: make(map[string]string),
+	}
+}
+
+// AssembleObjectFile assembles path into a relocatable object for the linker.
+func AssembleObjectFile(path string) (*link.Object, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return AssembleObject(string(data), path)
+}
+
+// AssembleObject assembles source into a relocatable object instead of a
+// ROM. In this mode:
+//
+//	.global NAME   exports label NAME to other units
+//	.extern NAME   declares a symbol defined by another unit
+//	JMP/CALL/Bxx NAME      to an extern become PC-relative relocations
+//	#NAME, .word NAME      become absolute (0x8000+) address relocations
+//	#bank(NAME)            becomes a bank-number relocation
+//
+// Branches to local labels stay PC-relative and need no relocation. .entry
+// is not allowed; the linker chooses the entry point.
+func AssembleObject(source, path string) (*link.Object, error) {
+	if path == "" {
+		path = "<buffer>"
+	}
+	a := newAssembler(source, path, defaultOptions())
+	a.object = true
+	if err := a.parse(); err != nil {
+		return nil, err
+	}
+	if err := a.firstPass(); err != nil {
+		return nil, err
+	}
+	b := rom.NewROMBuilder()
+	if err := a.emitAll(b); err != nil {
+		return nil, err
+	}
+	obj := &link.Object{
+		Version: link.ObjectFormatVersion,
+		Name:    path,
+		Code:    b.Words(),
+		Relocs:  a.relocs,
+	}
+	names := make([]string, 0, len(a.labels))
+	for name := range a.labels {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if a.labels[names[i]] != a.labels[names[j]] {
+			return a.labels[names[i]] < a.labels[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	for _, name := range names {
+		_, global := a.globals[name]
+		obj.Symbols = append(obj.Symbols, link.Symbol{Name: a.symbolName(name), Offset: a.labels[name], Global: global})
+	}
+	return obj, nil
+}
+
+// symbolName returns the spelling a label is known by outside the
+// assembler: the .global/.extern declaration's spelling if there is one,
+// otherwise the upper-cased label.
+func (a *Assembler) symbolName(upper string) string {
+	if name, ok := a.globals[upper]; ok {
+		return name
+	}
+	if name, ok := a.externs[upper]; ok {
+		return name
+	}
+	return upper
+}
+
 func (a *Assembler) parse() error {
 	s := bufio.NewScanner(strings.NewReader(a.source))
 	lineNo := 0
@@ -149,15 +243,40 @@ func (a *Assembler) firstPass() error {
 		}
 		pcWords += w
 	}
+	for upper, name := range a.globals {
+		if _, ok := a.labels[upper]; !ok {
+			return fmt.Errorf("%s: .global %s names no label", filepath.Base(a.path), name)
+		}
+	}
+	for upper, name := range a.externs {
+		if _, ok := a.labels[upper]; ok {
+			return fmt.Errorf("%s: .extern %s is also defined locally", filepath.Base(a.path), name)
+		}
+	}
 	return nil
 }
 
 func (a *Assembler) secondPass() (*Result, error) {
 	b := rom.NewROMBuilder()
+	if err := a.emitAll(b); err != nil {
+		return nil, err
+	}
+	romBytes, err := b.BuildROMBytes(a.opts.EntryBank, a.opts.EntryOffset)
+	if err != nil {
+		return nil, err
+	}
+	labelsCopy := make(map[string]int, len(a.labels))
+	for k, v := range a.labels {
+		labelsCopy[k] = v
+	}
+	return &Result{EntryBank: a.opts.EntryBank, EntryOffset: a.opts.EntryOffset, ROMBytes: romBytes, Words: b.GetCodeLength(), Labels: labelsCopy}, nil
+}
+
+func (a *Assembler) emitAll(b *rom.ROMBuilder) error {
 	for _, st := range a.stmts {
 		if st.Dir != "" {
 			if err := a.emitDirective(b, st); err != nil {
-				return nil, err
+				return err
 			}
 			continue
 		}
@@ -165,18 +284,10 @@ func (a *Assembler) secondPass() (*Result, error) {
 			continue
 		}
 		if err := a.emitInstruction(b, st); err != nil {
-			return nil, err
+			return err
 		}
 	}
-	romBytes, err := b.BuildROMBytes(a.opts.EntryBank, a.opts.EntryOffset)
-	if err != nil {
-		return nil, err
-	}
-	labelsCopy := make(map[string]int, len(a.labels))
-	for k, v := range a.labels {
-		labelsCopy[k] = v
-	}
-	return &Result{EntryBank: a.opts.EntryBank, EntryOffset: a.opts.EntryOffset, ROMBytes: romBytes, Words: b.GetCodeLength(), Labels: labelsCopy}, nil
+	return nil
 }
 
 func (a *Assembler) statementWords(st statement) (int, error) {
@@ -186,6 +297,24 @@ func (a *Assembler) statementWords(st statement) (int, error) {
 	if st.Dir != "" {
 		switch st.Dir {
 		case ".entry":
+			if a.object {
+				return 0, a.errf(st.Line, ".entry is not allowed in an object; pass the entry symbol to the linker")
+			}
+			return 0, nil
+		case ".global", ".extern":
+			if len(st.Operands) == 0 {
+				return 0, a.errf(st.Line, "%s requires at least 1 symbol name", st.Dir)
+			}
+			for _, name := range st.Operands {
+				if !isIdent(name) {
+					return 0, a.errf(st.Line, "%s: invalid symbol name %q", st.Dir, name)
+				}
+				if st.Dir == ".global" {
+					a.globals[strings.ToUpper(name)] = name
+				} else {
+					a.externs[strings.ToUpper(name)] = name
+				}
+			}
 			return 0, nil
 		case ".word":
 			if len(st.Operands) != 1 { return 0, a.errf(st.Line, ".word requires 1 operand") }
@@ -224,11 +353,10 @@ func (a *Assembler) emitDirective(b *rom.ROMBuilder, st statement) error {
 		a.opts.EntryBank = uint8(bank)
 		a.opts.EntryOffset = uint16(off)
 		return nil
-	case ".word":
-		v, err := a.eval(st.Line, st.Operands[0])
-		if err != nil { return err }
-		b.AddImmediate(uint16(v))
+	case ".global", ".extern":
 		return nil
+	case ".word":
+		return a.emitImmediate(b, st.Line, st.Operands[0])
 	default:
 		return a.errf(st.Line, "unknown directive %s", st.Dir)
 	}
@@ -299,17 +427,14 @@ func (a *Assembler) emitInstruction(b *rom.ROMBuilder, st statement) error {
 	}
 	r1, err := parseReg(ops[0])
 	if err != nil { return a.errf(st.Line, err.Error()) }
-	if imm, ok, err := parseImmediateOperand(ops[1], a, st.Line); err != nil {
-		return err
-	} else if ok {
+	if isImmediateOperand(ops[1]) {
 		mode := uint8(1)
 		reg2 := uint8(0)
 		if m == "CMP" {
 			mode = 7
 		}
 		b.AddInstruction(encodeOpcodeModeRegs(opcode, mode, r1, reg2))
-		b.AddImmediate(uint16(imm))
-		return nil
+		return a.emitImmediate(b, st.Line, ops[1])
 	}
 	r2, err := parseReg(ops[1])
 	if err != nil { return a.errf(st.Line, err.Error()) }
@@ -338,15 +463,12 @@ func (a *Assembler) emitMOVLike(b *rom.ROMBuilder, st statement, byteMode bool)
 	// left is register target
 	r1, err := parseReg(left)
 	if err != nil { return a.errf(st.Line, err.Error()) }
-	if imm, ok, err := parseImmediateOperand(right, a, st.Line); err != nil {
-		return err
-	} else if ok {
+	if isImmediateOperand(right) {
 		if byteMode {
 			return a.errf(st.Line, "MOV.B does not support immediate form")
 		}
 		b.AddInstruction(rom.EncodeMOV(1, r1, 0))
-		b.AddImmediate(uint16(imm))
-		return nil
+		return a.emitImmediate(b, st.Line, right)
 	}
 	if isMemRef(right) {
 		r2, err := parseMemReg(right)
@@ -377,6 +499,11 @@ func (a *Assembler) emitPCRel(b *rom.ROMBuilder, st statement, op uint16) error
 		b.AddImmediate(uint16(off))
 		return nil
 	}
+	if name, ok := a.externs[strings.ToUpper(targetExpr)]; ok && a.object {
+		a.relocs = append(a.relocs, link.Reloc{Offset: offsetWordIndex, Symbol: name, Kind: link.RelocRel16, Line: st.Line})
+		b.AddImmediate(0)
+		return nil
+	}
 	// Allow explicit numeric relative offset (raw immediate)
 	v, err := a.eval(st.Line, targetExpr)
 	if err != nil {
@@ -407,21 +534,56 @@ func (a *Assembler) eval(line int, expr string) (int64, error) {
 	}
 	v, err := strconv.ParseInt(expr, 0, 64)
 	if err != nil {
+		// Hardware register names (e.g. OAM_ADDR) evaluate to their address;
+		// labels of the same name take precedence.
+		if reg, ok := hwdef.RegisterByName(expr); ok {
+			return int64(reg.Address), nil
+		}
 		return 0, a.errf(line, "invalid number or unknown label %q", expr)
 	}
 	return v, nil
 }
 
-func parseImmediateOperand(op string, a *Assembler, line int) (int64, bool, error) {
-	op = strings.TrimSpace(op)
-	if !strings.HasPrefix(op, "#") {
-		return 0, false, nil
+func isImmediateOperand(op string) bool {
+	return strings.HasPrefix(strings.TrimSpace(op), "#")
+}
+
+// emitImmediate writes the value of an immediate operand (with or without
+// the leading '#'). In object mode a symbolic operand emits a placeholder
+// word plus a relocation instead.
+func (a *Assembler) emitImmediate(b *rom.ROMBuilder, line int, op string) error {
+	expr := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(op), "#"))
+	if a.object {
+		if name, kind, ok := a.symbolRef(expr); ok {
+			a.relocs = append(a.relocs, link.Reloc{Offset: b.GetCodeLength(), Symbol: name, Kind: kind, Line: line})
+			b.AddImmediate(0)
+			return nil
+		}
 	}
-	v, err := a.eval(line, op)
+	v, err := a.eval(line, expr)
 	if err != nil {
-		return 0, true, err
+		return err
+	}
+	b.AddImmediate(uint16(v))
+	return nil
+}
+
+// symbolRef recognizes NAME and bank(NAME) operands that name a local label
+// or an extern, returning the relocation symbol and kind.
+func (a *Assembler) symbolRef(expr string) (string, link.RelocKind, bool) {
+	kind := link.RelocAbs16
+	if lower := strings.ToLower(expr); strings.HasPrefix(lower, "bank(") && strings.HasSuffix(lower, ")") {
+		kind = link.RelocBank
+		expr = strings.TrimSpace(expr[len("bank(") : len(expr)-1])
+	}
+	upper := strings.ToUpper(expr)
+	if _, ok := a.labels[upper]; ok {
+		return a.symbolName(upper), kind, true
+	}
+	if name, ok := a.externs[upper]; ok {
+		return name, kind, true
 	}
-	return v, true, nil
+	return "", kind, false
 }
 
 func parseReg(s string) (uint8, error) {
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"nitro-core-dx/internal/emulator"
+	"nitro-core-dx/internal/link"
 )
 
 func TestAssembleAllSupportedMnemonics(t *testing.T) {
@@ -112,3 +113,75 @@ func TestCMPImmediateR0UsesDedicatedMode7(t *testing.T) {
 		t.Fatalf("CMP R0,#imm did not use mode 7 encoding: inst=0x%04X mode=%d reg1=%d reg2=%d", inst, mode, reg1, reg2)
 	}
 }
+
+func TestAssembleObjectSymbolsAndRelocs(t *testing.T) {
+	src := `
+.global Entry
+.extern helper
+Entry:
+    CALL helper
+    MOV R6, #bank(helper)
+    MOV R7, #helper
+    BEQ local
+local:
+    RET
+table:
+    .word Entry
+`
+	obj, err := AssembleObject(src, "unit.asm")
+	if err != nil {
+		t.Fatalf("assemble object failed: %v", err)
+	}
+	want := map[string]struct {
+		offset int
+		global bool
+	}{"Entry": {0, true}, "LOCAL": {8, false}, "TABLE": {9, false}}
+	for _, s := range obj.Symbols {
+		w, ok := want[s.Name]
+		if !ok {
+			t.Fatalf("unexpected symbol %+v", s)
+		}
+		if s.Offset != w.offset || s.Global != w.global {
+			t.Fatalf("symbol %s = %+v, want offset %d global %v", s.Name, s, w.offset, w.global)
+		}
+	}
+	kinds := []link.RelocKind{link.RelocRel16, link.RelocBank, link.RelocAbs16, link.RelocAbs16}
+	if len(obj.Relocs) != len(kinds) {
+		t.Fatalf("relocs = %+v, want %d", obj.Relocs, len(kinds))
+	}
+	for i, r := range obj.Relocs {
+		if r.Kind != kinds[i] {
+			t.Fatalf("reloc %d kind = %s, want %s", i, r.Kind, kinds[i])
+		}
+	}
+	if obj.Relocs[0].Symbol != "helper" || obj.Relocs[3].Symbol != "Entry" {
+		t.Fatalf("reloc symbols = %+v", obj.Relocs)
+	}
+
+	for _, bad := range []string{".entry 1, 0x8000\nRET", ".global nowhere\nRET", ".extern x\nx:\nRET"} {
+		if _, err := AssembleObject(bad, "bad.asm"); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestAssembleHardwareRegisterNames(t *testing.T) {
+	src := `
+MOV R4, #OAM_ADDR
+MOV R5, #ch0_control
+.word BG0_CONTROL
+`
+	res, err := AssembleSource(src, "regs.asm", nil)
+	if err != nil {
+		t.Fatalf("assemble failed: %v", err)
+	}
+	for i, want := range []uint16{0x8014, 0x9003} {
+		off := 32 + i*4 + 2
+		if got := binary.LittleEndian.Uint16(res.ROMBytes[off : off+2]); got != want {
+			t.Errorf("immediate %d = 0x%04X, want 0x%04X", i, got, want)
+		}
+	}
+	if got := binary.LittleEndian.Uint16(res.ROMBytes[40:42]); got != 0x8008 {
+		t.Errorf(".word BG0_CONTROL = 0x%04X, want 0x8008", got)
+	}
+}
@@ -4,6 +4,19 @@ import (
 	"fmt"
 )
 
+// StepError is a component step function's error, tagged with the
+// component that returned it.
+type StepError struct {
+	Component string // "CPU", "PPU" or "APU"
+	Err       error
+}
+
+func (e *StepError) Error() string {
+	return fmt.Sprintf("%s step error: %v", e.Component, e.Err)
+}
+
+func (e *StepError) Unwrap() error { return e.Err }
+
 // MasterClock represents the master clock scheduler
 // It coordinates all subsystems (CPU, PPU, APU) based on clock cycles
 type MasterClock struct {
@@ -52,7 +65,7 @@ func (c *MasterClock) Step() (uint64, error) {
 	if c.CPUStep != nil && c.Cycle >= c.CPUNextCycle {
 		cyclesToRun := c.Cycle - c.CPUNextCycle + 1
 		if err := c.CPUStep(cyclesToRun); err != nil {
-			return 0, fmt.Errorf("CPU step error: %w", err)
+			return 0, &StepError{Component: "CPU", Err: err}
 		}
 		// CPU runs every cycle (~7.67 MHz, unified clock)
 		c.CPUNextCycle = c.Cycle + 1
@@ -62,7 +75,7 @@ func (c *MasterClock) Step() (uint64, error) {
 	if c.PPUStep != nil && c.Cycle >= c.PPUNextCycle {
 		cyclesToRun := c.Cycle - c.PPUNextCycle + 1
 		if err := c.PPUStep(cyclesToRun); err != nil {
-			return 0, fmt.Errorf("PPU step error: %w", err)
+			return 0, &StepError{Component: "PPU", Err: err}
 		}
 		// PPU runs every cycle (for dot-by-dot rendering)
 		c.PPUNextCycle = c.Cycle + 1
@@ -73,7 +86,7 @@ func (c *MasterClock) Step() (uint64, error) {
 	// while chip/timer/envelope/phase evolution remains cycle-driven.
 	if c.APUStep != nil {
 		if err := c.APUStep(1); err != nil {
-			return 0, fmt.Errorf("APU step error: %w", err)
+			return 0, &StepError{Component: "APU", Err: err}
 		}
 	}
 
@@ -93,14 +106,14 @@ func (c *MasterClock) StepCycles(cycles uint64) error {
 	// Step them for the full batch
 	if c.CPUStep != nil {
 		if err := c.CPUStep(cycles); err != nil {
-			return fmt.Errorf("CPU step error: %w", err)
+			return &StepError{Component: "CPU", Err: err}
 		}
 		c.CPUNextCycle += cycles
 	}
 
 	if c.PPUStep != nil {
 		if err := c.PPUStep(cycles); err != nil {
-			return fmt.Errorf("PPU step error: %w", err)
+			return &StepError{Component: "PPU", Err: err}
 		}
 		c.PPUNextCycle += cycles
 	}
@@ -109,7 +122,7 @@ func (c *MasterClock) StepCycles(cycles uint64) error {
 	// continuously in the same clock domain as CPU/PPU.
 	if c.APUStep != nil {
 		if err := c.APUStep(cycles); err != nil {
-			return fmt.Errorf("APU step error: %w", err)
+			return &StepError{Component: "APU", Err: err}
 		}
 	}
 
@@ -0,0 +1,300 @@
+package corelx
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"nitro-core-dx/internal/hwdef"
+	"nitro-core-dx/internal/rom"
+)
+
+// Sprite animation assets and their runtime (anim.play / anim.update /
+// anim.stop).
+//
+// An anim asset is a list of frames, each a sprite tile index plus how many
+// anim.update() calls (normally one per frame) it stays on screen:
+//
+//	asset Walk: anim text
+//	    "0:8 1:8 2:8 1:8"
+//
+// (hex encoding is also accepted: alternating tile, ticks bytes). Frames are
+// baked into the __animupdate helper as a compare chain rather than stored
+// as ROM data, the same way small assets are inlined elsewhere in codegen.
+// Playback state lives in animSlotCount slots of the runtime block, one per
+// animated sprite.
+
+// animFrame is one frame of an anim asset.
+type animFrame struct {
+	tile  uint8
+	ticks uint8
+}
+
+const (
+	animSlotCount = 8
+	animSlotSize  = 8 // anim key, sprite id, frame index, ticks left (16-bit each)
+	animSlotBase  = runtimeBlockBase + 0x80
+	animSlotEnd   = animSlotBase + animSlotCount*animSlotSize
+)
+
+// parseAnimFrames decodes an anim asset's frame list.
+func parseAnimFrames(a *AssetDecl) ([]animFrame, error) {
+	var frames []animFrame
+	switch a.Encoding {
+	case "text":
+		fields := strings.FieldsFunc(a.Data, func(r rune) bool {
+			return r == ' ' || r == ',' || r == '\t' || r == '\n' || r == '\r'
+		})
+		for _, f := range fields {
+			tileStr, ticksStr, ok := strings.Cut(f, ":")
+			if !ok {
+				return nil, fmt.Errorf("anim frame %q: expected tile:ticks", f)
+			}
+			tile, err := strconv.ParseUint(tileStr, 0, 8)
+			if err != nil {
+				return nil, fmt.Errorf("anim frame %q: tile must be 0-255", f)
+			}
+			ticks, err := strconv.ParseUint(ticksStr, 0, 8)
+			if err != nil || ticks == 0 {
+				return nil, fmt.Errorf("anim frame %q: ticks must be 1-255", f)
+			}
+			frames = append(frames, animFrame{tile: uint8(tile), ticks: uint8(ticks)})
+		}
+	case "hex":
+		data, err := decodeHexAssetData(a.Data)
+		if err != nil {
+			return nil, err
+		}
+		if len(data)%2 != 0 {
+			return nil, fmt.Errorf("anim hex data must be tile, ticks byte pairs (got %d bytes)", len(data))
+		}
+		for i := 0; i < len(data); i += 2 {
+			if data[i+1] == 0 {
+				return nil, fmt.Errorf("anim frame %d: ticks must be 1-255", i/2)
+			}
+			frames = append(frames, animFrame{tile: data[i], ticks: data[i+1]})
+		}
+	default:
+		return nil, fmt.Errorf("anim assets use text (\"tile:ticks ...\") or hex encoding, not %s", a.Encoding)
+	}
+	if len(frames) == 0 {
+		return nil, fmt.Errorf("anim asset has no frames")
+	}
+	return frames, nil
+}
+
+// animAssetKey resolves anim.play's asset argument (`ASSET_Walk` or
+// `Walk`) to the nonzero key stored in a slot: the asset's ID + 1.
+func (cg *CodeGenerator) animAssetKey(arg Expr) (uint16, error) {
+	ident, ok := arg.(*IdentExpr)
+	if !ok {
+		return 0, fmt.Errorf("anim.play: second argument must be an anim asset name")
+	}
+	name := strings.TrimPrefix(ident.Name, "ASSET_")
+	asset, ok := cg.assets[name]
+	if !ok || asset.Type != "anim" {
+		return 0, fmt.Errorf("anim.play: %q is not a declared anim asset", ident.Name)
+	}
+	return cg.assetIDs[name] + 1, nil
+}
+
+// hasAnimAssets reports whether the program declares any anim asset.
+func (cg *CodeGenerator) hasAnimAssets() bool {
+	for _, a := range cg.program.Assets {
+		if a.Type == "anim" {
+			return true
+		}
+	}
+	return false
+}
+
+// emitAnimPlayHelper emits __animplay: R0 = sprite id, R1 = anim key (0 to
+// stop). Reuses the sprite's slot if it already has one (a no-op if it is
+// already playing that anim, so calling anim.play every frame is cheap and
+// doesn't restart the animation), otherwise claims the first free slot.
+// With no free slot the call is silently dropped.
+func (cg *CodeGenerator) emitAnimPlayHelper() {
+	cg.recordFuncAddr("__animplay")
+
+	cg.hMovImm(3, animSlotBase) // R3 = slot cursor
+	cg.hMovImm(2, 0)            // R2 = chosen slot (0 = none yet)
+	loop := cg.builder.GetCodeLength()
+	cg.builder.AddInstruction(rom.EncodeMOV(2, 4, 3)) // R4 = slot key
+	cg.hCmpImm(4, 0)
+	occupiedPos := cg.hBranch(rom.EncodeBNE())
+	// Free slot: remember the first one.
+	cg.hCmpImm(2, 0)
+	haveFreePos := cg.hBranch(rom.EncodeBNE())
+	cg.builder.AddInstruction(rom.EncodeMOV(0, 2, 3))
+	haveFreeJmp := cg.hBranch(rom.EncodeJMP())
+
+	cg.hPatchToHere(occupiedPos)
+	cg.builder.AddInstruction(rom.EncodeMOV(0, 5, 3))
+	cg.builder.AddInstruction(rom.EncodeADD(1, 5, 0))
+	cg.builder.AddImmediate(2)
+	cg.builder.AddInstruction(rom.EncodeMOV(2, 5, 5)) // R5 = slot sprite id
+	cg.builder.AddInstruction(rom.EncodeCMP(0, 5, 0))
+	otherSpritePos := cg.hBranch(rom.EncodeBNE())
+	cg.builder.AddInstruction(rom.EncodeCMP(0, 4, 1))
+	samePos := cg.hBranch(rom.EncodeBEQ()) // already playing this anim
+	cg.builder.AddInstruction(rom.EncodeMOV(0, 2, 3))
+	claimPos := cg.hBranch(rom.EncodeJMP())
+
+	cg.hPatchToHere(haveFreePos)
+	cg.hPatchToHere(haveFreeJmp)
+	cg.hPatchToHere(otherSpritePos)
+	cg.builder.AddInstruction(rom.EncodeADD(1, 3, 0))
+	cg.builder.AddImmediate(animSlotSize)
+	cg.hCmpImm(3, animSlotEnd)
+	cg.builder.AddInstruction(rom.EncodeBLT())
+	cg.builder.AddImmediate(uint16(rom.CalculateBranchOffset(uint16(cg.builder.GetCodeLength()*2), uint16(loop*2))))
+
+	// Sprite not found: stopping is a no-op; playing needs a free slot.
+	cg.hCmpImm(1, 0)
+	stopNoSlotPos := cg.hBranch(rom.EncodeBEQ())
+	cg.hCmpImm(2, 0)
+	fullPos := cg.hBranch(rom.EncodeBEQ())
+
+	cg.hPatchToHere(claimPos)
+	cg.builder.AddInstruction(rom.EncodeMOV(3, 2, 1)) // [slot+0] = key
+	cg.builder.AddInstruction(rom.EncodeADD(1, 2, 0))
+	cg.builder.AddImmediate(2)
+	cg.builder.AddInstruction(rom.EncodeMOV(3, 2, 0)) // [slot+2] = sprite id
+	cg.hMovImm(4, 0)
+	cg.builder.AddInstruction(rom.EncodeADD(1, 2, 0))
+	cg.builder.AddImmediate(2)
+	cg.builder.AddInstruction(rom.EncodeMOV(3, 2, 4)) // [slot+4] = frame 0
+	cg.builder.AddInstruction(rom.EncodeADD(1, 2, 0))
+	cg.builder.AddImmediate(2)
+	cg.builder.AddInstruction(rom.EncodeMOV(3, 2, 4)) // [slot+6] = 0 ticks: show frame 0 on the next update
+
+	cg.hPatchToHere(samePos)
+	cg.hPatchToHere(stopNoSlotPos)
+	cg.hPatchToHere(fullPos)
+	cg.builder.AddInstruction(rom.EncodeRET())
+}
+
+// emitAnimUpdateHelper emits __animupdate, the per-frame step for every
+// active slot: count down the current frame's ticks and, when they run out
+// (or on the first update after anim.play), move to the next frame, looping
+// at the end, and write its tile into the sprite's OAM entry. The OAM write
+// reads past x/y (OAM_DATA reads advance the byte index like writes do) and
+// stores only the tile byte, so position and attributes are untouched; with
+// a shadow OAM the tile is stored there too.
+func (cg *CodeGenerator) emitAnimUpdateHelper() error {
+	cg.recordFuncAddr("__animupdate")
+
+	cg.hMovImm(3, animSlotBase)
+	loop := cg.builder.GetCodeLength()
+	cg.builder.AddInstruction(rom.EncodeMOV(2, 1, 3)) // R1 = anim key
+	cg.hCmpImm(1, 0)
+	var nextPatches []int
+	nextPatches = append(nextPatches, cg.hBranch(rom.EncodeBEQ()))
+
+	// R4 = &ticks, R5 = ticks
+	cg.builder.AddInstruction(rom.EncodeMOV(0, 4, 3))
+	cg.builder.AddInstruction(rom.EncodeADD(1, 4, 0))
+	cg.builder.AddImmediate(6)
+	cg.builder.AddInstruction(rom.EncodeMOV(2, 5, 4))
+	cg.hCmpImm(5, 0)
+	freshPos := cg.hBranch(rom.EncodeBEQ())
+	cg.builder.AddInstruction(rom.EncodeSUB(1, 5, 0))
+	cg.builder.AddImmediate(1)
+	cg.builder.AddInstruction(rom.EncodeMOV(3, 4, 5))
+	cg.hCmpImm(5, 0)
+	nextPatches = append(nextPatches, cg.hBranch(rom.EncodeBNE()))
+	// Ticks ran out: advance the frame.
+	cg.builder.AddInstruction(rom.EncodeMOV(0, 4, 3))
+	cg.builder.AddInstruction(rom.EncodeADD(1, 4, 0))
+	cg.builder.AddImmediate(4)
+	cg.builder.AddInstruction(rom.EncodeMOV(2, 2, 4))
+	cg.builder.AddInstruction(rom.EncodeADD(1, 2, 0))
+	cg.builder.AddImmediate(1)
+	lookupJmp := cg.hBranch(rom.EncodeJMP())
+
+	cg.hPatchToHere(freshPos)
+	cg.builder.AddInstruction(rom.EncodeMOV(0, 4, 3))
+	cg.builder.AddInstruction(rom.EncodeADD(1, 4, 0))
+	cg.builder.AddImmediate(4)
+	cg.builder.AddInstruction(rom.EncodeMOV(2, 2, 4)) // R2 = frame
+
+	// Frame lookup: R1 = key, R2 = frame -> R4 = tile, R5 = ticks, R2
+	// wrapped to 0 past the last frame.
+	cg.hPatchToHere(lookupJmp)
+	var storePatches []int
+	for i, a := range cg.program.Assets {
+		if a.Type != "anim" {
+			continue
+		}
+		frames, err := parseAnimFrames(a)
+		if err != nil {
+			return fmt.Errorf("anim asset %s: %w", a.Name, err)
+		}
+		cg.hCmpImm(1, uint16(i)+1)
+		otherAnimPos := cg.hBranch(rom.EncodeBNE())
+		cg.hCmpImm(2, uint16(len(frames)))
+		inRangePos := cg.hBranch(rom.EncodeBLT())
+		cg.hMovImm(2, 0)
+		cg.hPatchToHere(inRangePos)
+		for j, f := range frames {
+			cg.hCmpImm(2, uint16(j))
+			otherFramePos := cg.hBranch(rom.EncodeBNE())
+			cg.hMovImm(4, uint16(f.tile))
+			cg.hMovImm(5, uint16(f.ticks))
+			storePatches = append(storePatches, cg.hBranch(rom.EncodeJMP()))
+			cg.hPatchToHere(otherFramePos)
+		}
+		cg.hPatchToHere(otherAnimPos)
+	}
+	// Unknown key (cannot happen for slots written by __animplay): free it.
+	cg.hMovImm(4, 0)
+	cg.builder.AddInstruction(rom.EncodeMOV(3, 3, 4))
+	nextPatches = append(nextPatches, cg.hBranch(rom.EncodeJMP()))
+
+	for _, pos := range storePatches {
+		cg.hPatchToHere(pos)
+	}
+	cg.builder.AddInstruction(rom.EncodeMOV(0, 6, 3))
+	cg.builder.AddInstruction(rom.EncodeADD(1, 6, 0))
+	cg.builder.AddImmediate(4)
+	cg.builder.AddInstruction(rom.EncodeMOV(3, 6, 2)) // [slot+4] = frame
+	cg.builder.AddInstruction(rom.EncodeADD(1, 6, 0))
+	cg.builder.AddImmediate(2)
+	cg.builder.AddInstruction(rom.EncodeMOV(3, 6, 5)) // [slot+6] = ticks
+
+	// OAM_ADDR = sprite id, skip x_lo/x_hi/y, write tile.
+	cg.builder.AddInstruction(rom.EncodeMOV(0, 6, 3))
+	cg.builder.AddInstruction(rom.EncodeADD(1, 6, 0))
+	cg.builder.AddImmediate(2)
+	cg.builder.AddInstruction(rom.EncodeMOV(2, 6, 6)) // R6 = sprite id
+	cg.storeIOByte(hwdef.OAM_ADDR, 6)
+	cg.hMovImm(7, hwdef.OAM_DATA)
+	for i := 0; i < 3; i++ {
+		cg.builder.AddInstruction(rom.EncodeMOV(2, 6, 7)) // read (advances the OAM byte index)
+	}
+	cg.storeIOByte(hwdef.OAM_DATA, 4)
+	if cg.oamShadow {
+		// Keep the shadow in step, or the next oam.flush puts the old
+		// tile back.
+		cg.emitOAMShadowReadyCall()
+		cg.builder.AddInstruction(rom.EncodeMOV(0, 6, 3))
+		cg.builder.AddInstruction(rom.EncodeADD(1, 6, 0))
+		cg.builder.AddImmediate(2)
+		cg.builder.AddInstruction(rom.EncodeMOV(2, 6, 6)) // R6 = sprite id
+		cg.emitOAMShadowEntry(6, 7)
+		cg.builder.AddInstruction(rom.EncodeADD(1, 6, 0))
+		cg.builder.AddImmediate(3)
+		cg.builder.AddInstruction(rom.EncodeMOV(7, 6, 4)) // shadow tile = R4
+	}
+
+	for _, pos := range nextPatches {
+		cg.hPatchToHere(pos)
+	}
+	cg.builder.AddInstruction(rom.EncodeADD(1, 3, 0))
+	cg.builder.AddImmediate(animSlotSize)
+	cg.hCmpImm(3, animSlotEnd)
+	cg.builder.AddInstruction(rom.EncodeBLT())
+	cg.builder.AddImmediate(uint16(rom.CalculateBranchOffset(uint16(cg.builder.GetCodeLength()*2), uint16(loop*2))))
+	cg.builder.AddInstruction(rom.EncodeRET())
+	return nil
+}
@@ -0,0 +1,99 @@
+package corelx
+
+import (
+	"strings"
+	"testing"
+
+	"nitro-core-dx/internal/emulator"
+)
+
+// runAnimProgram compiles source, runs it for frames real frames, and
+// returns sprite 0's OAM tile byte after each frame.
+func runAnimProgram(t *testing.T, source string, frames int) []uint8 {
+	t.Helper()
+	result, err := CompileSource(source, "anim.corelx", nil)
+	if err != nil {
+		t.Fatalf("compile: %v (diagnostics: %+v)", err, result.Diagnostics)
+	}
+	emu := emulator.NewEmulator()
+	emu.SetFrameLimit(false)
+	if err := emu.LoadROM(result.ROMBytes); err != nil {
+		t.Fatalf("LoadROM: %v", err)
+	}
+	emu.Start()
+	tiles := make([]uint8, 0, frames)
+	for i := 0; i < frames; i++ {
+		if err := emu.RunFrame(); err != nil {
+			t.Fatalf("RunFrame %d: %v", i, err)
+		}
+		tiles = append(tiles, emu.PPU.OAM[3])
+	}
+	return tiles
+}
+
+// animTilesAfterStart drops the frames before the first animated tile shows.
+func animTilesAfterStart(t *testing.T, tiles []uint8, first uint8) []uint8 {
+	t.Helper()
+	for i, tile := range tiles {
+		if tile == first {
+			return tiles[i:]
+		}
+	}
+	t.Fatalf("animation never started: tiles %v", tiles)
+	return nil
+}
+
+const animWalkProgram = `asset Walk: anim text
+    "5:2 6:1 7:3"
+
+function Start()
+    hero := Sprite()
+    hero.tile = 1
+    hero.ctrl = SPR_ENABLE()
+    oam.write(0, hero)
+    anim.play(0, ASSET_Walk)
+    while true
+        wait_vblank()
+        anim.play(0, Walk)
+        anim.update()
+`
+
+func TestAnimPlayCyclesFramesWithDurations(t *testing.T) {
+	tiles := animTilesAfterStart(t, runAnimProgram(t, animWalkProgram, 16), 5)
+	// 5 for 2 ticks, 6 for 1, 7 for 3, then loop. Calling anim.play again
+	// every frame must not restart the animation.
+	want := []uint8{5, 5, 6, 7, 7, 7, 5, 5, 6, 7}
+	if len(tiles) < len(want) {
+		t.Fatalf("too few animated frames: %v", tiles)
+	}
+	for i, w := range want {
+		if tiles[i] != w {
+			t.Fatalf("tile sequence = %v, want prefix %v", tiles, want)
+		}
+	}
+}
+
+func TestAnimStopFreezesTile(t *testing.T) {
+	source := strings.Replace(animWalkProgram, "        anim.play(0, Walk)\n", "        if frame_counter() == 4\n            anim.stop(0)\n", 1)
+	tiles := runAnimProgram(t, source, 16)
+	last := tiles[len(tiles)-1]
+	for _, tile := range tiles[len(tiles)-6:] {
+		if tile != last {
+			t.Fatalf("tile kept changing after anim.stop: %v", tiles)
+		}
+	}
+}
+
+func TestAnimAssetErrors(t *testing.T) {
+	cases := map[string]string{
+		"bad frame":     "asset Walk: anim text\n    \"5\"\n\nfunction Start()\n    anim.update()\n",
+		"zero ticks":    "asset Walk: anim text\n    \"5:0\"\n\nfunction Start()\n    anim.update()\n",
+		"not anim":      "asset T: tiles8 hex\n    00\n\nasset Walk: anim text\n    \"1:1\"\n\nfunction Start()\n    anim.play(0, ASSET_T)\n",
+		"no anim asset": "function Start()\n    anim.update()\n",
+	}
+	for name, source := range cases {
+		if _, err := CompileSource(source, "anim.corelx", nil); err == nil {
+			t.Fatalf("%s: expected compile error", name)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package corelx
+
+import (
+	"fmt"
+
+	"nitro-core-dx/internal/hwdef"
+	"nitro-core-dx/internal/rom"
+)
+
+// The asset directory backs gfx.load_tiles with an asset ID only known at
+// run time (`id := ASSET_Hero` ... `gfx.load_tiles(id, 16)`). Rather than
+// inlining every tile asset at each such call, the compiler places each
+// one's bytes in the ROM data region once, after a table indexed by asset
+// ID, and the calls share the __loadtiles routine. Each entry is
+// assetDirEntryBytes long:
+//
+//	shift     1 byte, tile index to VRAM address (5 for 8x8, 7 for 16x16)
+//	bank      1 byte, ROM bank of the tile bytes
+//	offset    2 bytes, little-endian
+//	length    2 bytes, little-endian; 0 for an asset that is not tiles
+//
+// The directory is only built when some gfx.load_tiles call takes a
+// runtime ID; ASSET_* literals still load inline.
+const assetDirEntryBytes = 6
+
+// AssetDirectory is where the asset directory table sits in ROM.
+type AssetDirectory struct {
+	Bank   uint8
+	Offset uint16
+	Count  int // entries, one per declared asset
+}
+
+// usesRuntimeTileLoad reports whether any gfx.load_tiles call takes an
+// asset ID that is not an ASSET_* literal of a declared asset.
+func usesRuntimeTileLoad(program *Program) bool {
+	declared := make(map[string]bool, len(program.Assets))
+	for _, a := range program.Assets {
+		declared["ASSET_"+a.Name] = true
+	}
+	for _, fn := range program.Functions {
+		for _, call := range stmtCalls(fn.Body) {
+			if callFuncName(call) != "gfx.load_tiles" || len(call.Args) == 0 {
+				continue
+			}
+			if ident, ok := call.Args[0].(*IdentExpr); !ok || !declared[ident.Name] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// loadAssetDirectory lays out the asset directory and the tile bytes it
+// points at in the shared ROM data region starting at startBank,
+// continuing from baseCursor. The table never straddles a bank, so
+// __loadtiles can index it with one add; it is moved to the next bank
+// if it would. Returns nil and no bytes when no call needs it.
+func loadAssetDirectory(program *Program, assets []AssetIR, startBank uint8, baseCursor int) (*AssetDirectory, []byte) {
+	if !usesRuntimeTileLoad(program) {
+		return nil, nil
+	}
+	data := make(map[string][]byte, len(assets))
+	for _, a := range assets {
+		data[a.Name] = a.Data
+	}
+
+	var region []byte
+	tableLen := len(program.Assets) * assetDirEntryBytes
+	if used := baseCursor % rom.ROMBankSizeBytes; used+tableLen > rom.ROMBankSizeBytes {
+		region = make([]byte, rom.ROMBankSizeBytes-used)
+	}
+	bank, off := dataAddr(startBank, baseCursor+len(region))
+	dir := &AssetDirectory{Bank: bank, Offset: off, Count: len(program.Assets)}
+
+	table := make([]byte, tableLen)
+	cursor := baseCursor + len(region) + tableLen
+	var payloads []byte
+	for i, a := range program.Assets {
+		if !isTileAssetType(a.Type) || a.Compression != "" {
+			continue
+		}
+		payload, shift := tileLoadPayload(a.Type, data[a.Name])
+		if len(payload) == 0 {
+			continue
+		}
+		pBank, pOff := dataAddr(startBank, cursor)
+		e := table[i*assetDirEntryBytes:]
+		e[0], e[1] = byte(shift), pBank
+		e[2], e[3] = byte(pOff), byte(pOff>>8)
+		e[4], e[5] = byte(len(payload)), byte(len(payload)>>8)
+		payloads = append(payloads, payload...)
+		cursor += len(payload)
+	}
+	region = append(append(region, table...), payloads...)
+	return dir, region
+}
+
+func isTileAssetType(t string) bool {
+	return t == "tiles8" || t == "tiles16" || t == "sprite" || t == "tileset"
+}
+
+// SetAssetDirectory injects the asset directory (nil when no
+// gfx.load_tiles call takes a runtime asset ID).
+func (cg *CodeGenerator) SetAssetDirectory(d *AssetDirectory) { cg.assetDirectory = d }
+
+// generateRuntimeTileLoad handles gfx.load_tiles(id, base) with id only
+// known at run time: R0 = asset ID, R1 = base tile index. An ID that is
+// not a tile asset loads nothing; returns base either way.
+func (cg *CodeGenerator) generateRuntimeTileLoad(destReg uint8) error {
+	if cg.assetDirectory == nil {
+		return fmt.Errorf("gfx.load_tiles with a runtime asset ID needs the ROM asset directory, which the compiler lays out")
+	}
+	cg.builder.AddInstruction(rom.EncodeMOV(4, 1, 0)) // PUSH base
+	cg.needLoadTiles = true
+	cg.emitHelperCall("__loadtiles")
+	cg.builder.AddInstruction(rom.EncodeMOV(5, destReg, 0)) // POP base
+	return nil
+}
+
+// emitLoadTilesHelper emits __loadtiles: look asset ID R0 up in the
+// directory and copy its tile bytes into VRAM from tile index R1.
+// Clobbers R0-R7.
+func (cg *CodeGenerator) emitLoadTilesHelper() {
+	dir := cg.assetDirectory
+	cg.recordFuncAddr("__loadtiles")
+
+	cg.builder.AddInstruction(rom.EncodeMOV(0, 2, 1)) // R2 = base
+	cg.hCmpImm(0, 0)
+	negative := cg.hBranch(rom.EncodeBLT())
+	cg.hCmpImm(0, uint16(dir.Count))
+	tooBig := cg.hBranch(rom.EncodeBGE())
+
+	// R1:R0 = the entry, dir.Offset + id*6.
+	cg.builder.AddInstruction(rom.EncodeMOV(0, 3, 0))
+	cg.hShlImm(3, 1)
+	cg.hShlImm(0, 2)
+	cg.builder.AddInstruction(rom.EncodeADD(0, 0, 3))
+	cg.builder.AddInstruction(rom.EncodeADD(1, 0, 0))
+	cg.builder.AddImmediate(dir.Offset)
+	cg.hMovImm(1, uint16(dir.Bank))
+
+	cg.emitPackedReadByte(3)
+	cg.builder.AddInstruction(rom.EncodeSHL(0, 2, 3)) // R2 = VRAM address
+	cg.emitPackedReadByte(3)                          // R3 = bank
+	cg.emitPackedReadByte(4)
+	cg.emitPackedReadByte(5)
+	cg.hShlImm(5, 8)
+	cg.builder.AddInstruction(rom.EncodeOR(0, 4, 5)) // R4 = offset
+	cg.emitPackedReadByte(5)
+	cg.emitPackedReadByte(6)
+	cg.hShlImm(6, 8)
+	cg.builder.AddInstruction(rom.EncodeOR(0, 5, 6)) // R5 = length
+	cg.hCmpImm(5, 0)
+	empty := cg.hBranch(rom.EncodeBEQ())
+
+	cg.emitSetVRAMAddr(2, 6)
+	cg.builder.AddInstruction(rom.EncodeMOV(0, 0, 4))
+	cg.builder.AddInstruction(rom.EncodeMOV(0, 1, 3))
+	copyLoop := cg.builder.GetCodeLength()
+	cg.emitPackedReadByte(6)
+	cg.hMovImm(7, hwdef.VRAM_DATA)
+	cg.builder.AddInstruction(rom.EncodeMOV(3, 7, 6))
+	cg.emitCountdownLoop(5, copyLoop)
+
+	cg.hPatchToHere(negative)
+	cg.hPatchToHere(tooBig)
+	cg.hPatchToHere(empty)
+	cg.builder.AddInstruction(rom.EncodeRET())
+}
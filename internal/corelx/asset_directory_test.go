@@ -0,0 +1,81 @@
+package corelx
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// runtimeTileSource declares an 8x8 tile, a 16x16 tile, a tilemap and a
+// two-tile tileset, and loads them through Load, whose asset ID is only
+// known at run time.
+func runtimeTileSource(calls string) string {
+	tile16 := make([]byte, 128)
+	for i := range tile16 {
+		tile16[i] = byte(0x40 + i%16)
+	}
+	tileset := append(bytes.Repeat([]byte{0x55}, 32), bytes.Repeat([]byte{0x66}, 32)...)
+	return tileAssetSource("A", "tiles8", "", bytes.Repeat([]byte{0x11}, 32)) + "\n" +
+		tileAssetSource("B", "tiles16", "", tile16) + "\n" +
+		"asset Map: tilemap hex\n    01 00 02 00\n" +
+		tileAssetSource("Pair", "tileset", "", tileset) + `
+
+function Load(id: u16, base: u16) -> u16
+    return gfx.load_tiles(id, base)
+
+function Start()
+` + calls + `
+    while true
+        wait_vblank()
+`
+}
+
+func TestRuntimeTileLoadUsesAssetDirectory(t *testing.T) {
+	emu, _ := compileLoadForTest(t, runtimeTileSource(`    Load(ASSET_A, 1)
+    Load(ASSET_B, 2)
+    Load(ASSET_Pair, 20)
+    Load(ASSET_Map, 30)
+    if Load(77, 40) == 40
+        mem.write(0x0200, 1)`))
+	emu.SetFrameLimit(false)
+	emu.Start()
+	for i := 0; i < 3; i++ {
+		if err := emu.RunFrame(); err != nil {
+			t.Fatalf("RunFrame: %v", err)
+		}
+	}
+
+	vram := emu.PPU.VRAM
+	if got := vram[32:64]; !bytes.Equal(got, bytes.Repeat([]byte{0x11}, 32)) {
+		t.Errorf("tiles8 A at tile 1: % X", got[:8])
+	}
+	for i := 0; i < 128; i++ {
+		if vram[256+i] != byte(0x40+i%16) {
+			t.Fatalf("tiles16 B at tile 2 differs at byte %d: %02X", i, vram[256+i])
+		}
+	}
+	pair := vram[20*32 : 22*32]
+	if !bytes.Equal(pair[:32], bytes.Repeat([]byte{0x55}, 32)) || !bytes.Equal(pair[32:], bytes.Repeat([]byte{0x66}, 32)) {
+		t.Errorf("tileset Pair at tile 20: % X ... % X", pair[:4], pair[32:36])
+	}
+	if vram[30*32] != 0 || vram[40*32] != 0 {
+		t.Error("a tilemap or unknown asset ID wrote tiles")
+	}
+	if emu.Bus.Read8(0, 0x0200) != 1 {
+		t.Error("gfx.load_tiles with an unknown ID did not return base")
+	}
+	if emu.CPU.State.DBR != 0 {
+		t.Errorf("DBR = %d after the loads, want 0", emu.CPU.State.DBR)
+	}
+}
+
+func TestRuntimeTileLoadCostsLessCodeThanInlining(t *testing.T) {
+	_, one := compileLoadForTest(t, runtimeTileSource("    Load(ASSET_A, 1)"))
+	_, inline := compileLoadForTest(t, strings.Replace(runtimeTileSource("    Load(ASSET_A, 1)"),
+		"gfx.load_tiles(id, base)", "gfx.load_tiles(ASSET_B, base)", 1))
+	// The whole runtime loader (call site plus __loadtiles) is smaller than
+	// inlining the one 16x16 tile.
+	if rt, in := codeSectionBytes(t, one), codeSectionBytes(t, inline); rt >= in {
+		t.Errorf("runtime loader build uses %d code bytes, a single inline 16x16 load %d", rt, in)
+	}
+}
@@ -22,9 +22,10 @@ func NormalizeAssets(program *Program, sourcePath string) ([]AssetIR, []Diagnost
 	diags := make([]Diagnostic, 0)
 
 	for _, a := range program.Assets {
-		// Image and music assets are external files (.cxasset / .ncdxmusic)
-		// handled by loadImageAssets / loadMusicAssets, not inline-normalized here.
-		if a.Type == "image" || a.Type == "music" {
+		// Image, music and sample assets are external files (.cxasset /
+		// .ncdxmusic / .wav) handled by loadImageAssets / loadMusicAssets /
+		// loadSampleAssets, not inline-normalized here.
+		if a.Type == "image" || a.Type == "music" || a.Type == "sample" {
 			continue
 		}
 		ir, errDiag := normalizeAssetDecl(a, sourcePath)
@@ -74,7 +75,17 @@ func normalizeAssetDecl(a *AssetDecl, sourcePath string) (AssetIR, *Diagnostic)
 		d := assetDiagnostic(a, sourcePath, CategoryAssetFormatError, "E_ASSET_ENCODING_UNSUPPORTED", fmt.Sprintf("unsupported asset encoding: %s", a.Encoding))
 		return AssetIR{}, &d
 	}
-	ir.Data = normalizeLegacyTilePayload(a.Type, ir.Data)
+	// A compressed tiles asset holds any number of tiles, so 64 bytes is
+	// two packed 8x8 tiles there, not one raw one.
+	if a.Compression == "" {
+		ir.Data = normalizeLegacyTilePayload(a.Type, ir.Data)
+	}
+	if a.Type == "anim" {
+		if _, err := parseAnimFrames(a); err != nil {
+			d := assetDiagnostic(a, sourcePath, CategoryAssetParseError, "E_ANIM_PARSE", err.Error())
+			return AssetIR{}, &d
+		}
+	}
 
 	return ir, nil
 }
@@ -194,7 +205,7 @@ func sectionForAssetType(assetType string) string {
 		return "palettes"
 	case "music", "sfx", "ambience":
 		return "audio_seq"
-	case "gamedata", "blob":
+	case "gamedata", "blob", "anim":
 		return "gamedata"
 	default:
 		return "unknown"
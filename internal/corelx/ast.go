@@ -21,12 +21,18 @@ type Program struct {
 	CoreLXVersion string
 	// Modules lists the module names requested by a leading `--! modules:
 	// name, name, ...` directive (charter D1), in source order.
-	Modules   []string
+	Modules []string
+	// Timing is the ROM header timing flag from a `--! timing: 50hz` (or
+	// 60hz) directive: rom.TimingFlag60Hz unless the file asks otherwise.
+	Timing    uint8
 	Assets    []*AssetDecl
 	Types     []*TypeDecl
 	Consts    []*ConstDecl
 	Globals   []*GlobalVarDecl
 	Functions []*FunctionDecl
+	// GameLoop is set when the compiler generated Start() around the
+	// program's Update()/Draw() (see game_loop.go).
+	GameLoop bool
 }
 
 // ConstDecl represents a top-level compile-time constant: const NAME = expr
@@ -58,6 +64,11 @@ type AssetDecl struct {
 	Encoding string // "b64" or "hex"
 	Data     string
 	FilePath string // for "image": path to the external .cxasset file
+
+	// Compression is "rle" (tilemaps) or "lz" (tiles8/tiles16) for an
+	// asset packed compressed into the ROM data region (see
+	// packed_assets.go), else "".
+	Compression string
 }
 
 // TypeDecl represents a type declaration
@@ -117,6 +128,15 @@ type FunctionDecl struct {
 	Params   []*ParamDecl
 	ReturnType TypeExpr // nil if void
 	Body     []Stmt
+	// Extern marks a body-less `extern function` declaration: the function
+	// is defined by another unit (typically assembly) and calls to it are
+	// resolved by the linker. Arguments arrive in R0-R5, the result is
+	// returned in R0.
+	Extern bool
+	// Bank pins the function to a ROM bank via a `#[bank(N)]` attribute
+	// (0 = let the compiler place it). Pinning any function above bank 1
+	// forces a multi-bank build; calls into it use the far-call sequence.
+	Bank uint8
 }
 
 // ParamDecl represents a function parameter
@@ -252,6 +272,9 @@ type CallExpr struct {
 	Position Position
 	Func     Expr
 	Args     []Expr
+	// ArgNames holds the name of each `name=value` argument, "" for
+	// positional ones. It is nil when no argument is named.
+	ArgNames []string
 }
 
 func (*CallExpr) isExpr() {}
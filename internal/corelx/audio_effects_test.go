@@ -0,0 +1,37 @@
+package corelx
+
+import (
+	"testing"
+
+	"nitro-core-dx/internal/emulator"
+)
+
+func TestAudioEffectsBuiltins(t *testing.T) {
+	source := `function Start()
+    audio.set_lowpass(64)
+    audio.set_echo(50, 100, 80)
+    while true
+        wait_vblank()
+`
+	result, err := CompileSource(source, "effects.corelx", &CompileOptions{EmitROMBytes: true})
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	emu := emulator.NewEmulator()
+	if err := emu.LoadROM(result.ROMBytes); err != nil {
+		t.Fatalf("load ROM: %v", err)
+	}
+	for i := 0; i < 500; i++ {
+		if err := emu.CPU.ExecuteInstruction(); err != nil {
+			t.Fatalf("CPU step %d: %v", i, err)
+		}
+	}
+	fx := emu.APU.Effects
+	if fx.Lowpass != 64 || fx.EchoDelay != 50 || fx.EchoFeedback != 100 || fx.EchoMix != 80 {
+		t.Errorf("effects registers = %d/%d/%d/%d, want 64/50/100/80", fx.Lowpass, fx.EchoDelay, fx.EchoFeedback, fx.EchoMix)
+	}
+
+	if _, err := CompileSource("function Start()\n    audio.set_echo(50)\n", "effects.corelx", nil); err == nil {
+		t.Error("audio.set_echo with one argument compiled")
+	}
+}
@@ -0,0 +1,93 @@
+package corelx
+
+import (
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"nitro-core-dx/internal/emulator"
+	"nitro-core-dx/internal/ppu"
+)
+
+// TestBGLoadImageRenders compiles a project that loads a bg8 image asset
+// onto BG1 and checks the picture appears on screen, for both 8bpp formats.
+func TestBGLoadImageRenders(t *testing.T) {
+	pic := image.NewRGBA(image.Rect(0, 0, 320, 200))
+	for y := 0; y < 200; y++ {
+		for x := 0; x < 320; x++ {
+			c := color.RGBA{A: 255}
+			if x < 160 {
+				c.R = 255
+			} else {
+				c.G, c.B = 255, 255
+			}
+			pic.SetRGBA(x, y, c)
+		}
+	}
+	for _, format := range []uint8{ppu.BGFormatIndexed8, ppu.BGFormatDirect8} {
+		img, err := emulator.BuildBG8ImageFromImage(pic, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "title.cxasset"), []byte(img.CxAsset("Title", "")), 0644); err != nil {
+			t.Fatal(err)
+		}
+		src := `asset Title: image "title.cxasset"
+
+function Start()
+    bg.load_image(Title, 1)
+    while true
+        wait_vblank()
+`
+		srcPath := filepath.Join(dir, "main.corelx")
+		if err := os.WriteFile(srcPath, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := CompileProject(srcPath, &CompileOptions{OutputPath: filepath.Join(dir, "main.rom")}); err != nil {
+			t.Fatalf("format %d: compile: %v", format, err)
+		}
+		romData, err := os.ReadFile(filepath.Join(dir, "main.rom"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		emu := emulator.NewEmulator()
+		if err := emu.LoadROM(romData); err != nil {
+			t.Fatal(err)
+		}
+		emu.Start()
+		emu.SetFrameLimit(false)
+		for i := 0; i < 5; i++ {
+			if err := emu.RunFrame(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if got := emu.PPU.BG1.ColorFormat(); got != format {
+			t.Fatalf("BG1 format = %d, want %d", got, format)
+		}
+		buf := emu.GetOutputBuffer()
+		if left, right := buf[100*320+20], buf[100*320+300]; left != 0xFF0000 || right != 0x00FFFF {
+			t.Errorf("format %d: rendered %06X / %06X, want FF0000 / 00FFFF", format, left, right)
+		}
+	}
+}
+
+func TestBGLoadImageRejectsBitmapPlane(t *testing.T) {
+	dir := t.TempDir()
+	asset := "image Floor:\n    kind: bitmap_plane\n    plane_size: 32\n    palette_bank: 0\n    palette: hex 0000\n    data: hex\n        00\n"
+	if err := os.WriteFile(filepath.Join(dir, "floor.cxasset"), []byte(asset), 0644); err != nil {
+		t.Fatal(err)
+	}
+	srcPath := filepath.Join(dir, "main.corelx")
+	src := "asset Floor: image \"floor.cxasset\"\n\nfunction Start()\n    bg.load_image(Floor, 0)\n"
+	if err := os.WriteFile(srcPath, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := CompileProject(srcPath, &CompileOptions{OutputPath: filepath.Join(dir, "main.rom")})
+	if err == nil || !strings.Contains(err.Error(), "bg8") {
+		t.Fatalf("err = %v, want a bg8 kind error", err)
+	}
+}
@@ -94,9 +94,16 @@ function __boot_show_default()
 //     assert state a few frames later don't need touching to skip a ~3.2s
 //     hold they never asked to test. Production compiles (the real `corelx`
 //     CLI) never run under `go test`, so real games are unaffected.
+//     cfg.SkipBootSplash (the Dev Kit's debug profile) takes the same path.
 //  3. Otherwise (production, or a test with ForceBootSplash): inject
 //     `__Boot(): __boot_show_default(); Start()`.
+//
+// Immediate compiles (cfg.Immediate) skip all of this: the machine they run
+// on has already booted.
 func injectBootEntry(program *Program, cfg CompileOptions) error {
+	if cfg.Immediate {
+		return nil
+	}
 	hasBoot := false
 	hasStart := false
 	for _, fn := range program.Functions {
@@ -111,7 +118,7 @@ func injectBootEntry(program *Program, cfg CompileOptions) error {
 		return nil
 	}
 
-	fastTestBypass := !hasBoot && testing.Testing() && !cfg.ForceBootSplash
+	fastTestBypass := !hasBoot && (cfg.SkipBootSplash || testing.Testing() && !cfg.ForceBootSplash)
 	if fastTestBypass {
 		// Skip the parse+merge below entirely -- no __Boot() exists yet (so
 		// nothing in this program can reach boot.show_default()), and this
@@ -202,3 +202,42 @@ function Start()
 		t.Errorf("secret_mode with nothing held at boot: want 0, got %d", m)
 	}
 }
+
+// TestBootSkipSplashStartsImmediately verifies CompileOptions.SkipBootSplash
+// (the Dev Kit's debug profile) reaches Start() on the first frames even when
+// the real splash would otherwise be compiled in.
+func TestBootSkipSplashStartsImmediately(t *testing.T) {
+	src := `
+var marker: int = 0
+
+function Start()
+    marker = 42
+    while true
+        wait_vblank()
+`
+	res, err := CompileSource(src, "boot_skip.corelx", &CompileOptions{ForceBootSplash: true, SkipBootSplash: true})
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	addrs := map[string]uint16{}
+	for _, e := range res.MemoryMap {
+		addrs[e.Name] = e.Address
+	}
+	if _, ok := addrs["__boot_scroll"]; ok {
+		t.Errorf("skipped splash should not carry the boot sequence's globals")
+	}
+	emu := emulator.NewEmulator()
+	emu.SetFrameLimit(false)
+	if err := emu.LoadROM(res.ROMBytes); err != nil {
+		t.Fatalf("load ROM: %v", err)
+	}
+	emu.Start()
+	for i := 0; i < 3; i++ {
+		if err := emu.RunFrame(); err != nil {
+			t.Fatalf("RunFrame: %v", err)
+		}
+	}
+	if m := read16(emu, addrs["marker"]); m != 42 {
+		t.Errorf("marker after 3 frames: want 42, got %d", m)
+	}
+}
@@ -0,0 +1,17 @@
+package corelx
+
+import "testing"
+
+// TestSetBorderColorWritesRegister verifies ppu.set_border_color stores its
+// CGRAM index in BORDER_COLOR.
+func TestSetBorderColorWritesRegister(t *testing.T) {
+	source := `function Start()
+    ppu.set_border_color(0x13)
+    while true
+        wait_vblank()
+`
+	emu, _ := compileAndBoot(t, source, 200)
+	if got := emu.PPU.BorderColor; got != 0x13 {
+		t.Errorf("BORDER_COLOR = 0x%02X, want 0x13", got)
+	}
+}
@@ -0,0 +1,206 @@
+package corelx
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ByteSize is a size in a project manifest: a JSON number of bytes or a
+// string such as "24 KB", "1.5KB", "1 MB" or "0x6000" (KB = 1024 bytes).
+type ByteSize uint32
+
+func (b *ByteSize) UnmarshalJSON(data []byte) error {
+	var n uint32
+	if err := json.Unmarshal(data, &n); err == nil {
+		*b = ByteSize(n)
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("size must be a number of bytes or a string like \"24 KB\", got %s", data)
+	}
+	v, err := ParseByteSize(s)
+	if err != nil {
+		return err
+	}
+	*b = v
+	return nil
+}
+
+// MarshalJSON writes whole kilobytes as "N KB" and anything else as a
+// number of bytes.
+func (b ByteSize) MarshalJSON() ([]byte, error) {
+	if b != 0 && b%1024 == 0 {
+		return json.Marshal(fmt.Sprintf("%d KB", b/1024))
+	}
+	return json.Marshal(uint32(b))
+}
+
+// ParseByteSize parses a size written as in a project manifest budget.
+func ParseByteSize(s string) (ByteSize, error) {
+	text := strings.ToUpper(strings.TrimSpace(s))
+	if strings.HasPrefix(text, "0X") {
+		n, err := strconv.ParseUint(text[2:], 16, 32)
+		if err != nil {
+			return 0, fmt.Errorf("invalid size %q", s)
+		}
+		return ByteSize(n), nil
+	}
+	scale := 1.0
+	for _, unit := range []struct {
+		suffix string
+		scale  float64
+	}{{"MB", 1 << 20}, {"KB", 1 << 10}, {"M", 1 << 20}, {"K", 1 << 10}, {"B", 1}} {
+		if strings.HasSuffix(text, unit.suffix) {
+			text = strings.TrimSpace(strings.TrimSuffix(text, unit.suffix))
+			scale = unit.scale
+			break
+		}
+	}
+	v, err := strconv.ParseFloat(text, 64)
+	if err != nil || v < 0 || v*scale > float64(^uint32(0)) {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return ByteSize(v * scale), nil
+}
+
+// budgetUsage returns how many bytes the build uses against the budget
+// called name: "rom" is the planned ROM size, "assets" every section after
+// the code (asset sections and rom_data) together, and anything else the
+// manifest section of that name.
+func budgetUsage(m *BuildManifest, name string) (uint32, bool) {
+	switch name {
+	case "rom":
+		return m.PlannedROMSizeBytes, true
+	case "assets":
+		var total uint32
+		for _, s := range m.Sections {
+			if s.Name != "header" && s.Name != "code" {
+				total += s.UsedBytes
+			}
+		}
+		return total, true
+	}
+	for _, s := range m.Sections {
+		if s.Name == name {
+			return s.UsedBytes, true
+		}
+	}
+	return 0, false
+}
+
+// loadProjectBudgets reads the budgets of the project manifest next to
+// sourcePath. Unreadable or malformed manifests are left to
+// loadProjectAssets to report.
+func loadProjectBudgets(sourcePath string, cfg CompileOptions) (map[string]ByteSize, string, []Diagnostic) {
+	path := projectAssetManifestPath(sourcePath, cfg)
+	if path == "" {
+		return nil, "", nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", nil
+	}
+	var m ProjectAssetManifest
+	if json.Unmarshal(data, &m) != nil {
+		return nil, "", nil
+	}
+	var diags []Diagnostic
+	for name := range m.Budgets {
+		if !isBudgetName(name) {
+			diags = append(diags, Diagnostic{
+				Category: CategoryValidationError,
+				Code:     "E_ASSET_MANIFEST_BUDGET",
+				Message:  fmt.Sprintf("unknown budget %q (use rom, code, assets, rom_data or %s)", name, strings.Join(manifestAssetSections, ", ")),
+				File:     path,
+				Severity: SeverityError,
+				Stage:    StageAsset,
+			})
+		}
+	}
+	return m.Budgets, path, diags
+}
+
+func isBudgetName(name string) bool {
+	switch name {
+	case "rom", "code", "assets":
+		return true
+	}
+	for _, s := range manifestAssetSections {
+		if s == name {
+			return true
+		}
+	}
+	return name == "rom_data"
+}
+
+// checkProjectBudgets records each budget in the build manifest and warns
+// about the ones the build exceeds. Budgets guide rather than block: the
+// ROM is still produced.
+func checkProjectBudgets(m *BuildManifest, budgets map[string]ByteSize, path string) []Diagnostic {
+	if m == nil || len(budgets) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(budgets))
+	for name := range budgets {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return budgetOrder(names[i]) < budgetOrder(names[j]) })
+
+	var diags []Diagnostic
+	for _, name := range names {
+		used, ok := budgetUsage(m, name)
+		if !ok {
+			continue
+		}
+		b := ManifestBudget{Name: name, UsedBytes: used, BudgetBytes: uint32(budgets[name])}
+		m.Budgets = append(m.Budgets, b)
+		if !b.Over() {
+			continue
+		}
+		diags = append(diags, Diagnostic{
+			Category: CategoryOverflowError,
+			Code:     "W_BUDGET_EXCEEDED",
+			Message:  fmt.Sprintf("%s uses %s, over its %s budget by %s", name, FormatBytes(used), FormatBytes(b.BudgetBytes), FormatBytes(used-b.BudgetBytes)),
+			File:     path,
+			Severity: SeverityWarning,
+			Stage:    StagePack,
+			Notes: []string{
+				"Shrink or compress the " + name + " data, or raise the budget in " + defaultProjectAssetManifest + ".",
+			},
+		})
+	}
+	return diags
+}
+
+// budgetOrder sorts budgets as the sections appear in the ROM, with the
+// totals first.
+func budgetOrder(name string) int {
+	switch name {
+	case "rom":
+		return 0
+	case "code":
+		return 1
+	case "assets":
+		return 2
+	}
+	for i, s := range manifestAssetSections {
+		if s == name {
+			return 3 + i
+		}
+	}
+	return len(manifestAssetSections) + 3 // rom_data
+}
+
+// FormatBytes renders a size the way budgets are usually written: bytes
+// below 1 KB, otherwise kilobytes to one decimal place.
+func FormatBytes(n uint32) string {
+	if n < 1024 {
+		return fmt.Sprintf("%d bytes", n)
+	}
+	return strings.TrimSuffix(fmt.Sprintf("%.1f", float64(n)/1024), ".0") + " KB"
+}
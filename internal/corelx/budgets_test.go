@@ -0,0 +1,82 @@
+package corelx
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseByteSize(t *testing.T) {
+	for in, want := range map[string]ByteSize{
+		"24 KB": 24 * 1024, "24KB": 24 * 1024, "1.5k": 1536, "1 MB": 1 << 20,
+		"0x6000": 0x6000, "512": 512, "512 B": 512,
+	} {
+		got, err := ParseByteSize(in)
+		if err != nil || got != want {
+			t.Errorf("ParseByteSize(%q) = %d, %v; want %d", in, got, err, want)
+		}
+	}
+	for _, bad := range []string{"", "lots", "-1 KB", "0xZZ"} {
+		if _, err := ParseByteSize(bad); err == nil {
+			t.Errorf("ParseByteSize(%q) succeeded", bad)
+		}
+	}
+}
+
+func writeBudgetProject(t *testing.T, budgets string) string {
+	t.Helper()
+	dir := t.TempDir()
+	src := "asset Tiles: tiles8 hex\n" +
+		"    00 11 22 33 44 55 66 77 88 99 AA BB CC DD EE FF\n\n" +
+		"function Start()\n    wait_vblank()\n"
+	if err := os.WriteFile(filepath.Join(dir, ProjectMainFile), []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	manifest := `{"assets": [], "budgets": ` + budgets + `}`
+	if err := os.WriteFile(filepath.Join(dir, defaultProjectAssetManifest), []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestProjectBudgetsWarnWhenExceeded(t *testing.T) {
+	dir := writeBudgetProject(t, `{"assets": "1 KB", "code": 16, "rom": "64 KB"}`)
+	res, err := CompileProject(dir, nil)
+	if err != nil {
+		t.Fatalf("an exceeded budget must not fail the build: %v", err)
+	}
+	var warned []string
+	for _, d := range res.Diagnostics {
+		if d.Code == "W_BUDGET_EXCEEDED" {
+			if d.Severity != SeverityWarning || filepath.Base(d.File) != defaultProjectAssetManifest {
+				t.Errorf("budget diagnostic = %+v", d)
+			}
+			warned = append(warned, d.Message)
+		}
+	}
+	if len(warned) != 1 {
+		t.Fatalf("budget warnings = %q, want one for code", warned)
+	}
+
+	got := res.Manifest.Budgets
+	if len(got) != 3 || got[0].Name != "rom" || got[1].Name != "code" || got[2].Name != "assets" {
+		t.Fatalf("manifest budgets = %+v, want rom, code, assets", got)
+	}
+	if !got[1].Over() || got[1].BudgetBytes != 16 || got[0].Over() {
+		t.Errorf("manifest budgets = %+v", got)
+	}
+	if got[2].UsedBytes != 16 {
+		t.Errorf("assets use %d bytes, want the 16-byte tile", got[2].UsedBytes)
+	}
+}
+
+func TestProjectBudgetsRejectUnknownName(t *testing.T) {
+	dir := writeBudgetProject(t, `{"sprites": "4 KB"}`)
+	res, err := CompileProject(dir, nil)
+	if err == nil {
+		t.Fatal("expected an error for an unknown budget")
+	}
+	if len(res.Diagnostics) == 0 || res.Diagnostics[len(res.Diagnostics)-1].Code != "E_ASSET_MANIFEST_BUDGET" {
+		t.Fatalf("diagnostics = %+v", res.Diagnostics)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"strings"
 
+	"nitro-core-dx/internal/hwdef"
+	"nitro-core-dx/internal/link"
 	"nitro-core-dx/internal/rom"
 )
 
@@ -58,9 +60,9 @@ type funcAddr struct {
 
 // CodeGenerator generates Nitro Core DX machine code from AST
 type CodeGenerator struct {
-	program *Program
-	builder codeSink
-	symbols map[string]*Symbol
+	program          *Program
+	builder          codeSink
+	symbols          map[string]*Symbol
 	regAlloc         *RegisterAllocator
 	labelCounter     int
 	assets           map[string]*AssetDecl
@@ -74,18 +76,53 @@ type CodeGenerator struct {
 	stackOffset uint16 // Current stack offset for spilled variables
 
 	// Top-level constants and WRAM globals (charter D3).
-	consts        map[string]int64
-	constFixed    map[string]bool
-	imageAssets   map[string]*ImageAsset
-	musicAssets   map[string]*MusicAsset
-	globals       map[string]*VariableInfo
-	memoryMap     []MemoryMapEntry
-	structLayouts map[string]*structLayout // lazily built by structLayoutFor
+	consts         map[string]int64
+	constFixed     map[string]bool
+	imageAssets    map[string]*ImageAsset
+	musicAssets    map[string]*MusicAsset
+	sampleAssets   map[string]*SampleAsset
+	packedAssets   map[string]*PackedAsset
+	assetDirectory *AssetDirectory
+	globals        map[string]*VariableInfo
+	memoryMap      []MemoryMapEntry
+	structLayouts  map[string]*structLayout // lazily built by structLayoutFor
 
 	// Arithmetic helper routines emitted once after user functions when
 	// referenced (signed 8.8 multiply needs 32-bit-correct partials).
 	needFixmul  bool
+	needFixdiv  bool
+	needFxSin   bool
 	needDrawInt bool
+	// needDecDigits / needPrintDigits: dec.digits or text.print_number
+	// was used (see decimal.go).
+	needDecDigits   bool
+	needPrintDigits bool
+
+	// Where __fxsin's quarter-wave table sits in the code and the word
+	// holding its absolute address, so the peephole pass can move both.
+	fxSinTable        rom.WordRange
+	fxSinTableAddrPos int
+
+	// Sprite animation runtime helpers (see anim.go), emitted only when
+	// anim.play/anim.stop or anim.update are used.
+	needAnimPlay   bool
+	needAnimUpdate bool
+
+	// Collision helpers (see collision.go).
+	needAABB   bool
+	needTileAt bool
+
+	// Compressed asset decoders (see tilemap_rle.go, tiles_lz.go) and the
+	// runtime-ID tile loader (asset_directory.go).
+	needTilemapRLE bool
+	needTilesLZ    bool
+	needLoadTiles  bool
+
+	// oamShadow routes oam.* writes through the WRAM shadow that
+	// oam.flush DMAs into OAM (see oam_shadow.go).
+	oamShadow          bool
+	needOAMFlush       bool
+	needOAMShadowReady bool
 
 	// Function call support
 	functionAddrs map[string]funcAddr // function name -> (bank, code word index) of function start
@@ -100,6 +137,9 @@ type CodeGenerator struct {
 	// Innermost-enclosing-loop stack, for break/continue target patching.
 	loopStack []*loopContext
 
+	// Function being generated, for narrowing u8/i8 return values.
+	currentFunc *FunctionDecl
+
 	// Multi-bank support (see internal/rom/banked_builder.go and the
 	// codeSink/bankCursor types above). currentBank is which bank cg.builder
 	// (when it's a *bankCursor) is actively emitting into -- changed only
@@ -125,6 +165,28 @@ type CodeGenerator struct {
 	// exactly match what pass 3 will later emit (guaranteed, since both
 	// passes run the same codegen logic over the same AST).
 	emitOrder []string
+
+	// sourceLines records where each statement's code starts, for the
+	// source listing (see listing.go).
+	sourceLines []SourceLine
+
+	// peephole runs the peephole optimizer over compact builds (see
+	// SetPeephole); peepholeStats is what it did, nil when it did not run.
+	peephole      bool
+	peepholeStats *rom.PeepholeStats
+
+	// Object mode (see SetObjectMode): calls to extern functions are left
+	// as placeholders and recorded in externRelocs for the linker instead
+	// of failing as undefined.
+	objectMode   bool
+	externRelocs []link.Reloc
+
+	// Immediate mode (see SetImmediateMode): the entry function does not
+	// redirect the IRQ/NMI vectors.
+	immediateMode bool
+
+	// target is the machine the code is tuned for (see SetTarget).
+	target Target
 }
 
 // callPatch records a pending CALL that needs its offset patched once the
@@ -365,6 +427,29 @@ type MemoryMapEntry struct {
 // MemoryMap returns the WRAM allocation listing for this build.
 func (cg *CodeGenerator) MemoryMap() []MemoryMapEntry { return cg.memoryMap }
 
+// FunctionSymbol is the ROM entry address of one emitted function or
+// compiler helper, for symbolizing PCs in debuggers and fault reports.
+type FunctionSymbol struct {
+	Name   string
+	Bank   uint8
+	Offset uint16
+}
+
+// FunctionSymbols lists every emitted function and helper in emission
+// order.
+func (cg *CodeGenerator) FunctionSymbols() []FunctionSymbol {
+	out := make([]FunctionSymbol, 0, len(cg.emitOrder))
+	for _, name := range cg.emitOrder {
+		addr := cg.functionAddrs[name]
+		out = append(out, FunctionSymbol{
+			Name:   name,
+			Bank:   addr.bank,
+			Offset: uint16(rom.ROMBankOffsetBase + addr.index*2),
+		})
+	}
+	return out
+}
+
 // globalTypeSize returns the byte size for a global's declared type.
 func globalTypeSize(typeName string) (uint16, error) {
 	switch typeName {
@@ -394,10 +479,29 @@ func (cg *CodeGenerator) allocateGlobals() error {
 			cg.consts[name] = mask
 		}
 	}
+	// Hardware register names (OAM_ADDR, CH0_CONTROL, ...) from the shared
+	// hwdef table evaluate to their I/O address, for mem.read/mem.write.
+	// User constants and globals of the same name win.
+	declared := make(map[string]bool, len(cg.program.Globals))
+	for _, g := range cg.program.Globals {
+		declared[g.Name] = true
+	}
+	for _, reg := range hwdef.Registers() {
+		if _, used := cg.consts[reg.Name]; !used && !declared[reg.Name] {
+			cg.consts[reg.Name] = int64(reg.Address)
+		}
+	}
 
 	cg.memoryMap = append(cg.memoryMap, MemoryMapEntry{
 		Name: "__runtime", Address: runtimeBlockBase, Size: globalsBase - runtimeBlockBase, Kind: "runtime",
 	})
+	limit := globalsLimit
+	if cg.oamShadow {
+		cg.memoryMap = append(cg.memoryMap, MemoryMapEntry{
+			Name: "__oam_shadow", Address: oamShadowReady, Size: globalsLimit + 1 - oamShadowReady, Kind: "runtime",
+		})
+		limit = oamShadowReady - 1
+	}
 
 	type span struct {
 		lo, hi uint16
@@ -442,6 +546,9 @@ func (cg *CodeGenerator) allocateGlobals() error {
 			if lo >= runtimeBlockBase && lo < globalsBase {
 				return fmt.Errorf("line %d: global %s pinned at 0x%04X overlaps the reserved runtime block (0x%04X-0x%04X)", g.Position.Line, g.Name, addr, runtimeBlockBase, globalsBase-1)
 			}
+			if cg.oamShadow && lo <= globalsLimit && hi >= oamShadowReady {
+				return fmt.Errorf("line %d: global %s pinned at 0x%04X overlaps the OAM shadow (0x%04X-0x%04X) that oam.flush uses", g.Position.Line, g.Name, addr, oamShadowReady, globalsLimit)
+			}
 			for _, p := range pinned {
 				if lo <= p.hi && hi >= p.lo {
 					return fmt.Errorf("line %d: global %s pinned at 0x%04X overlaps pinned global %s", g.Position.Line, g.Name, addr, p.name)
@@ -453,8 +560,8 @@ func (cg *CodeGenerator) allocateGlobals() error {
 				cursor++
 			}
 			addr = cursor
-			if uint32(addr)+uint32(size)-1 > uint32(globalsLimit) {
-				return fmt.Errorf("global WRAM region exhausted allocating %s (cursor 0x%04X, limit 0x%04X)", g.Name, addr, globalsLimit)
+			if uint32(addr)+uint32(size)-1 > uint32(limit) {
+				return fmt.Errorf("global WRAM region exhausted allocating %s (cursor 0x%04X, limit 0x%04X)", g.Name, addr, limit)
 			}
 			cursor += size
 		}
@@ -516,11 +623,21 @@ func (cg *CodeGenerator) emitGlobalInits() error {
 		}
 		cg.builder.AddInstruction(rom.EncodeMOV(1, 7, 0)) // MOV R7, #addr
 		cg.builder.AddImmediate(info.StackAddr)
-		cg.builder.AddInstruction(rom.EncodeMOV(3, 7, 0)) // MOV [R7], R0
+		cg.emitGlobalStore(info, 0)
 	}
 	return nil
 }
 
+// emitGlobalStore stores R{src} to the scalar global whose address is in
+// R7, one byte for u8 globals.
+func (cg *CodeGenerator) emitGlobalStore(info *VariableInfo, src uint8) {
+	if info.ElemWidth == 1 {
+		cg.builder.AddInstruction(rom.EncodeMOV(7, 7, src)) // 8-bit store [R7], R{src}
+	} else {
+		cg.builder.AddInstruction(rom.EncodeMOV(3, 7, src)) // MOV [R7], R{src}
+	}
+}
+
 // SetImageAssets injects parsed external image assets (with ROM bank/offset).
 func (cg *CodeGenerator) SetImageAssets(a map[string]*ImageAsset) { cg.imageAssets = a }
 
@@ -528,6 +645,9 @@ func (cg *CodeGenerator) SetImageAssets(a map[string]*ImageAsset) { cg.imageAsse
 // Stored for the future music.* playback builtins; not consumed yet.
 func (cg *CodeGenerator) SetMusicAssets(a map[string]*MusicAsset) { cg.musicAssets = a }
 
+// SetSampleAssets injects packed `sample` assets (with ROM bank/offset).
+func (cg *CodeGenerator) SetSampleAssets(a map[string]*SampleAsset) { cg.sampleAssets = a }
+
 // SetNormalizedAssets injects compiler-normalized assets so codegen can avoid re-parsing source asset text.
 func (cg *CodeGenerator) SetNormalizedAssets(assets []AssetIR) {
 	for _, a := range assets {
@@ -572,6 +692,7 @@ func (cg *CodeGenerator) Generate() error {
 		}
 	}
 
+	cg.oamShadow = !cg.immediateMode && programFlushesOAM(cg.program)
 	if err := cg.allocateGlobals(); err != nil {
 		return err
 	}
@@ -586,10 +707,14 @@ func (cg *CodeGenerator) Generate() error {
 		}
 	}
 
-	// Generate code for each function, recording start addresses.
+	// Generate code for each function, recording start addresses. Extern
+	// declarations have no body here; another unit defines them.
 	for _, fn := range functions {
+		if fn.Extern {
+			continue
+		}
 		cg.recordFuncAddr(fn.Name)
-		if fn == entryFunction {
+		if fn == entryFunction && !cg.immediateMode {
 			// Every real frame's VBlank fires an IRQ unconditionally (see
 			// ppu/scanline.go), and the default IRQ/NMI vectors alias this
 			// entry point (InitSystemVectors: bank 1, 0x8000). CoreLX has no
@@ -612,12 +737,56 @@ func (cg *CodeGenerator) Generate() error {
 	if cg.needFixmul {
 		cg.emitFixmulHelper()
 	}
+	if cg.needFixdiv {
+		cg.emitFixdivHelper()
+	}
+	if cg.needFxSin {
+		cg.emitFxSinHelper()
+	}
 	if cg.needDrawInt {
 		cg.emitDrawIntHelper()
 	}
+	if cg.needDecDigits {
+		cg.emitDecDigitsHelper()
+	}
+	if cg.needPrintDigits {
+		cg.emitPrintDigitsHelper()
+	}
+	if cg.needAnimPlay {
+		cg.emitAnimPlayHelper()
+	}
+	if cg.needAnimUpdate {
+		if err := cg.emitAnimUpdateHelper(); err != nil {
+			return err
+		}
+	}
+	if cg.needAABB {
+		cg.emitAABBHelper()
+	}
+	if cg.needTileAt {
+		cg.emitTileAtHelper()
+	}
+	if cg.needTilemapRLE {
+		cg.emitTilemapRLEHelper()
+	}
+	if cg.needTilesLZ {
+		cg.emitTilesLZHelper()
+	}
+	if cg.needLoadTiles {
+		cg.emitLoadTilesHelper()
+	}
+	if cg.needOAMFlush {
+		cg.emitOAMFlushHelper()
+	}
+	if cg.needOAMShadowReady {
+		cg.emitOAMShadowReadyHelper()
+	}
 	if len(cg.musicAssets) > 0 {
 		cg.emitMusicAdvanceHelper()
 	}
+	if err := cg.runPeephole(); err != nil {
+		return err
+	}
 	cg.patchIRQVector()
 
 	// Compact mode's CALL patches are same-bank relative offsets, which
@@ -642,6 +811,12 @@ func (cg *CodeGenerator) Generate() error {
 	for _, patch := range cg.callPatches {
 		target, ok := cg.functionAddrs[patch.target]
 		if !ok {
+			if fn := cg.findFunction(patch.target); fn != nil && fn.Extern {
+				if err := cg.recordExternCall(patch); err != nil {
+					return err
+				}
+				continue
+			}
 			return fmt.Errorf("undefined function: %s", patch.target)
 		}
 		if patch.wide {
@@ -658,6 +833,69 @@ func (cg *CodeGenerator) Generate() error {
 	return nil
 }
 
+// SetTarget tunes the generated code for target: TargetHardware puts a
+// VBlank guard before every upload builtin (see vblankGuardedBuiltins).
+func (cg *CodeGenerator) SetTarget(target Target) {
+	cg.target = target
+}
+
+// SetObjectMode makes Generate leave calls to extern functions for the
+// linker (see Object) rather than rejecting them.
+func (cg *CodeGenerator) SetObjectMode(enabled bool) {
+	cg.objectMode = enabled
+}
+
+// SetImmediateMode compiles for the Dev Kit immediate console, which runs
+// the entry function on a machine whose vectors the loaded ROM already set.
+func (cg *CodeGenerator) SetImmediateMode(enabled bool) {
+	cg.immediateMode = enabled
+}
+
+// recordExternCall turns an unresolved call to an extern function into a
+// linker relocation. Only compact (single-bank) calls can be relocated:
+// the linker pins a CoreLX unit at bank 1 and places other units beside
+// it, so a PC-relative CALL reaches them.
+func (cg *CodeGenerator) recordExternCall(patch callPatch) error {
+	if patch.wide {
+		return fmt.Errorf("extern function %s: multi-bank programs cannot call extern functions", patch.target)
+	}
+	if !cg.objectMode {
+		return fmt.Errorf("extern function %s is not defined in this program; compile to an object and link it with the unit that defines it", patch.target)
+	}
+	cg.externRelocs = append(cg.externRelocs, link.Reloc{
+		Offset: patch.offsetPos,
+		Symbol: patch.target,
+		Kind:   link.RelocRel16,
+	})
+	return nil
+}
+
+// Object packages a compact-mode build as a linker object pinned at bank 1.
+// Every user function is exported as a global symbol (callable from
+// assembly with CALL, arguments in R0-R5); compiler helpers are local.
+func (cg *CodeGenerator) Object(name string) (*link.Object, error) {
+	b, ok := cg.builder.(*rom.ROMBuilder)
+	if !ok {
+		return nil, fmt.Errorf("object output requires a single-bank build")
+	}
+	obj := &link.Object{
+		Version:   link.ObjectFormatVersion,
+		Name:      name,
+		Code:      b.Words(),
+		Relocs:    append([]link.Reloc(nil), cg.externRelocs...),
+		FixedBank: 1,
+	}
+	for _, fnName := range cg.emitOrder {
+		addr := cg.functionAddrs[fnName]
+		obj.Symbols = append(obj.Symbols, link.Symbol{
+			Name:   fnName,
+			Offset: addr.index,
+			Global: cg.findFunction(fnName) != nil,
+		})
+	}
+	return obj, nil
+}
+
 // recordFuncAddr switches cg.currentBank per pass 2's bank schedule (if
 // one is set) and records name's start address. Called once per
 // function/helper immediately before its body is emitted -- bank
@@ -690,6 +928,8 @@ func (cg *CodeGenerator) setPatchImmediate(bank uint8, wordIndex int, value uint
 func (cg *CodeGenerator) generateFunction(fn *FunctionDecl) error {
 	// Reset variable tracking for each function.
 	cg.variables = make(map[string]*VariableInfo)
+	cg.currentFunc = fn
+	cg.markSourceLine(fn.Position.Line)
 	cg.regAlloc = &RegisterAllocator{}
 
 	// Each function gets its own non-overlapping stack region (256 bytes).
@@ -713,14 +953,12 @@ func (cg *CodeGenerator) generateFunction(fn *FunctionDecl) error {
 		if err != nil {
 			return fmt.Errorf("function %s: %w", fn.Name, err)
 		}
+		paramVarType := intTypeName(param.Type)
 		// Save R{i} to stack
+		cg.emitIntNarrow(uint8(i), paramVarType)
 		cg.builder.AddInstruction(rom.EncodeMOV(1, 7, 0))
 		cg.builder.AddImmediate(stackAddr)
 		cg.builder.AddInstruction(rom.EncodeMOV(3, 7, uint8(i)))
-		paramVarType := ""
-		if named, ok := param.Type.(*NamedType); ok {
-			paramVarType = named.Name
-		}
 		cg.variables[param.Name] = &VariableInfo{
 			Name:       param.Name,
 			Location:   VarLocationStack,
@@ -735,6 +973,9 @@ func (cg *CodeGenerator) generateFunction(fn *FunctionDecl) error {
 		if err := cg.emitGlobalInits(); err != nil {
 			return err
 		}
+		if cg.oamShadow {
+			cg.emitOAMShadowUnready()
+		}
 	}
 
 	// Generate function body
@@ -855,6 +1096,7 @@ func (cg *CodeGenerator) emitStructMemberLoad(varInfo *VariableInfo, member stru
 }
 
 func (cg *CodeGenerator) generateStmt(stmt Stmt) error {
+	cg.markSourceLine(stmt.Pos().Line)
 	switch s := stmt.(type) {
 	case *VarDeclStmt:
 		return cg.generateVarDecl(s)
@@ -960,6 +1202,14 @@ func (cg *CodeGenerator) generateVarDecl(stmt *VarDeclStmt) error {
 		return err
 	}
 	// Value is now in R0
+	varType := intTypeName(stmt.Type)
+	if varType == "" {
+		varType = localIntType(inferInt(cg, stmt.Value))
+	}
+	if varType == "" {
+		varType = cg.typeOf(stmt.Value)
+	}
+	cg.emitIntNarrow(0, varType)
 	// Pre-alpha simplification: store locals on stack. This avoids register clobbering
 	// by builtins until a real calling convention/register allocator is implemented.
 	stackAddr, err := cg.allocateStack(2, "variable "+stmt.Name) // Allocate 2 bytes (16-bit value)
@@ -974,7 +1224,7 @@ func (cg *CodeGenerator) generateVarDecl(stmt *VarDeclStmt) error {
 		Location:   VarLocationStack,
 		StackAddr:  stackAddr,
 		StructType: cg.structTypeNameFromTypeExpr(stmt.Type),
-		VarType:    cg.typeOf(stmt.Value),
+		VarType:    varType,
 	}
 	return nil
 }
@@ -1049,11 +1299,12 @@ func (cg *CodeGenerator) generateAssign(stmt *AssignStmt) error {
 			if gInfo, isGlobal := cg.globals[ident.Name]; isGlobal {
 				cg.builder.AddInstruction(rom.EncodeMOV(1, 7, 0)) // MOV R7, #addr
 				cg.builder.AddImmediate(gInfo.StackAddr)
-				cg.builder.AddInstruction(rom.EncodeMOV(3, 7, 0)) // MOV [R7], R0
+				cg.emitGlobalStore(gInfo, 0)
 				return nil
 			}
 		}
 		if varInfo, exists := cg.variables[ident.Name]; exists {
+			cg.emitIntNarrow(0, varInfo.VarType)
 			// Store value to variable location
 			if varInfo.Location == VarLocationRegister {
 				cg.builder.AddInstruction(rom.EncodeMOV(0, varInfo.RegIndex, 0)) // MOV R{reg}, R0
@@ -1301,6 +1552,9 @@ func (cg *CodeGenerator) generateReturn(stmt *ReturnStmt) error {
 			return err
 		}
 		// Value is in R0
+		if cg.currentFunc != nil {
+			cg.emitIntNarrow(0, intTypeName(cg.currentFunc.ReturnType))
+		}
 	}
 	cg.builder.AddInstruction(rom.EncodeRET())
 	return nil
@@ -1365,7 +1619,11 @@ func (cg *CodeGenerator) generateExpr(expr Expr, destReg uint8) error {
 		if gInfo, isGlobal := cg.globals[e.Name]; isGlobal {
 			cg.builder.AddInstruction(rom.EncodeMOV(1, 7, 0)) // MOV R7, #addr
 			cg.builder.AddImmediate(gInfo.StackAddr)
-			cg.builder.AddInstruction(rom.EncodeMOV(2, destReg, 7)) // MOV R{destReg}, [R7]
+			if gInfo.ElemWidth == 1 {
+				cg.builder.AddInstruction(rom.EncodeMOV(6, destReg, 7)) // 8-bit load, zero-extended
+			} else {
+				cg.builder.AddInstruction(rom.EncodeMOV(2, destReg, 7)) // MOV R{destReg}, [R7]
+			}
 			return nil
 		}
 		// Variable not found - might be a built-in or error
@@ -1464,8 +1722,16 @@ func (cg *CodeGenerator) generateExpr(expr Expr, destReg uint8) error {
 			cg.builder.AddInstruction(rom.EncodeMUL(0, destReg, 2)) // MUL R{destReg}, R2
 			return nil
 		case TOKEN_SLASH:
+			// fixed / fixed routes through the signed 8.8 software divide.
 			if cg.typeOf(e.Left) == typeFixed || cg.typeOf(e.Right) == typeFixed {
-				return fmt.Errorf("fixed division is not implemented yet; multiply by a reciprocal constant instead (e.g. x * 0.5)")
+				cg.needFixdiv = true
+				cg.builder.AddInstruction(rom.EncodeMOV(0, 0, 1)) // MOV R0, R1 (left)
+				cg.builder.AddInstruction(rom.EncodeMOV(0, 1, 2)) // MOV R1, R2 (right)
+				cg.emitHelperCall("__fixdiv")
+				if destReg != 0 {
+					cg.builder.AddInstruction(rom.EncodeMOV(0, destReg, 0))
+				}
+				return nil
 			}
 			// Hardware DIV (unsigned). Divisor in R2 already; left in R1.
 			if numExpr, ok := e.Right.(*NumberExpr); ok && numExpr.Value == 0 {
@@ -1545,7 +1811,7 @@ func (cg *CodeGenerator) generateExpr(expr Expr, destReg uint8) error {
 			cg.patchLabel(endLabel, endPos)
 			return nil
 		case TOKEN_LESS:
-			cg.builder.AddInstruction(rom.EncodeCMP(0, 1, 2))
+			cg.emitOrderedCompare(e)
 			falseLabel := cg.newLabel()
 			endLabel := cg.newLabel()
 			cg.builder.AddInstruction(rom.EncodeBGE()) // >= => false
@@ -1562,7 +1828,7 @@ func (cg *CodeGenerator) generateExpr(expr Expr, destReg uint8) error {
 			cg.patchLabel(endLabel, endPos)
 			return nil
 		case TOKEN_GREATER:
-			cg.builder.AddInstruction(rom.EncodeCMP(0, 1, 2))
+			cg.emitOrderedCompare(e)
 			falseLabel := cg.newLabel()
 			endLabel := cg.newLabel()
 			cg.builder.AddInstruction(rom.EncodeBLE()) // <= => false
@@ -1579,7 +1845,7 @@ func (cg *CodeGenerator) generateExpr(expr Expr, destReg uint8) error {
 			cg.patchLabel(endLabel, endPos)
 			return nil
 		case TOKEN_LESS_EQUAL:
-			cg.builder.AddInstruction(rom.EncodeCMP(0, 1, 2))
+			cg.emitOrderedCompare(e)
 			falseLabel := cg.newLabel()
 			endLabel := cg.newLabel()
 			cg.builder.AddInstruction(rom.EncodeBGT()) // > => false
@@ -1596,7 +1862,7 @@ func (cg *CodeGenerator) generateExpr(expr Expr, destReg uint8) error {
 			cg.patchLabel(endLabel, endPos)
 			return nil
 		case TOKEN_GREATER_EQUAL:
-			cg.builder.AddInstruction(rom.EncodeCMP(0, 1, 2))
+			cg.emitOrderedCompare(e)
 			falseLabel := cg.newLabel()
 			endLabel := cg.newLabel()
 			cg.builder.AddInstruction(rom.EncodeBLT()) // < => false
@@ -1848,24 +2114,11 @@ func (cg *CodeGenerator) generateCall(call *CallExpr, destReg uint8) error {
 		if !ok {
 			return fmt.Errorf("text.draw: the last argument must be a string literal")
 		}
-		// X is 16-bit: low byte -> 0x8070, high byte -> 0x8071.
-		if err := cg.generateExpr(call.Args[0], 0); err != nil {
+		if err := cg.setTextPort(call.Args[:5]); err != nil {
 			return err
 		}
-		cg.storeIOByte(0x8070, 0)
-		cg.builder.AddInstruction(rom.EncodeMOV(0, 6, 0)) // R6 = X
-		cg.builder.AddInstruction(rom.EncodeSHR(1, 6, 0)) // R6 >>= 8
-		cg.builder.AddImmediate(8)
-		cg.storeIOByte(0x8071, 6)
-		// Y, R, G, B are single bytes.
-		for i, addr := range []uint16{0x8072, 0x8073, 0x8074, 0x8075} {
-			if err := cg.generateExpr(call.Args[i+1], 0); err != nil {
-				return err
-			}
-			cg.storeIOByte(addr, 0)
-		}
 		// Stream each character to 0x8076 (the port auto-advances X by 8).
-		cg.hMovImm(7, 0x8076)
+		cg.hMovImm(7, hwdef.TEXT_CHAR)
 		for _, ch := range str.Value {
 			cg.builder.AddInstruction(rom.EncodeMOV(1, 0, 0)) // MOV R0, #char
 			cg.builder.AddImmediate(uint16(ch))
@@ -1880,21 +2133,9 @@ func (cg *CodeGenerator) generateCall(call *CallExpr, destReg uint8) error {
 		if len(call.Args) != 6 {
 			return fmt.Errorf("text.draw_int expects (x, y, r, g, b, value), got %d args", len(call.Args))
 		}
-		// Port setup: X 16-bit, Y, R, G, B (same registers as text.draw).
-		if err := cg.generateExpr(call.Args[0], 0); err != nil {
+		if err := cg.setTextPort(call.Args[:5]); err != nil {
 			return err
 		}
-		cg.storeIOByte(0x8070, 0)
-		cg.builder.AddInstruction(rom.EncodeMOV(0, 6, 0))
-		cg.builder.AddInstruction(rom.EncodeSHR(1, 6, 0))
-		cg.builder.AddImmediate(8)
-		cg.storeIOByte(0x8071, 6)
-		for i, addr := range []uint16{0x8072, 0x8073, 0x8074, 0x8075} {
-			if err := cg.generateExpr(call.Args[i+1], 0); err != nil {
-				return err
-			}
-			cg.storeIOByte(addr, 0)
-		}
 		// Value into R0, then the digit-rendering routine.
 		if err := cg.generateExpr(call.Args[5], 0); err != nil {
 			return err
@@ -1904,6 +2145,12 @@ func (cg *CodeGenerator) generateCall(call *CallExpr, destReg uint8) error {
 		return nil
 	}
 
+	// dec.digits(value, ptr) and text.print_number(x, y, r, g, b, value,
+	// width) share the __decdigits routine (see decimal.go).
+	if funcName == "dec.digits" || funcName == "text.print_number" {
+		return cg.generateDecimalCall(funcName, call.Args, destReg)
+	}
+
 	// music.play(asset) / music.play_loop(asset) / music.play_jingle(asset):
 	// the argument is a music asset name (resolved at compile time against
 	// cg.musicAssets), not a runtime expression — it can't go through the
@@ -1954,6 +2201,67 @@ func (cg *CodeGenerator) generateCall(call *CallExpr, destReg uint8) error {
 		return nil
 	}
 
+	// sfx.play(ASSET_Jump): a sample asset, resolved at compile time like
+	// music.play's. Any other sfx.play is the sfx module's function.
+	if funcName == "sfx.play" {
+		if name, ok := sampleAssetArg(cg.program, call); ok {
+			return cg.generateSfxPlay(name, call)
+		}
+	}
+
+	// persist.set("key", v) / persist.get("key"): the key is a string
+	// literal streamed to the mailbox, so it bypasses argument evaluation.
+	if funcName == "persist.set" || funcName == "persist.get" {
+		return cg.generatePersistCall(funcName, call, destReg)
+	}
+
+	// debug.print("x=", x): string literals are streamed like persist keys,
+	// so the arguments bypass the generic evaluation below.
+	if funcName == "debug.print" {
+		return cg.generateDebugPrint(call)
+	}
+
+	// anim.play(spriteId, ASSET_Walk) / anim.stop(spriteId): like
+	// music.play, the asset is resolved at compile time, so only the sprite
+	// id goes through expression evaluation.
+	if funcName == "anim.play" || funcName == "anim.stop" {
+		if funcName == "anim.play" && len(call.Args) != 2 {
+			return fmt.Errorf("anim.play requires 2 arguments (sprite id, anim asset)")
+		}
+		if funcName == "anim.stop" && len(call.Args) != 1 {
+			return fmt.Errorf("anim.stop requires 1 argument (sprite id)")
+		}
+		key := uint16(0) // 0 = stop
+		if funcName == "anim.play" {
+			k, err := cg.animAssetKey(call.Args[1])
+			if err != nil {
+				return err
+			}
+			key = k
+		}
+		if err := cg.generateExpr(call.Args[0], 0); err != nil {
+			return err
+		}
+		cg.hMovImm(1, key)
+		cg.needAnimPlay = true
+		cg.emitHelperCall("__animplay")
+		return nil
+	}
+
+	if strings.HasPrefix(funcName, "fx.") {
+		return cg.generateFxCall(funcName, call.Args, destReg)
+	}
+
+	if isSpriteFieldsCall(funcName) {
+		return cg.generateSpriteFieldsCall(funcName, call, destReg)
+	}
+
+	// phys.aabb / bg.tile_at stage their arguments in WRAM rather than the
+	// generic R0..R7 loop below (see collision.go).
+	if funcName == "phys.aabb" || funcName == "bg.tile_at" {
+		return cg.generateCollisionCall(funcName, call.Args, destReg)
+	}
+
 	if funcName == "" {
 		return fmt.Errorf("cannot determine function name in call")
 	}
@@ -2043,25 +2351,55 @@ func (cg *CodeGenerator) findFunction(name string) *FunctionDecl {
 	return nil
 }
 
+// vblankGuardedBuiltins are the builtins that write VRAM, CGRAM or OAM.
+// For TargetHardware each is preceded by a VBlank guard (emitVBlankGuard),
+// since strict hardware drops those writes during active display.
+var vblankGuardedBuiltins = map[string]bool{
+	"gfx.load_tiles": true, "gfx.load_tiles_compressed": true,
+	"gfx.set_palette": true, "gfx.set_palette_color": true, "gfx.init_default_palettes": true,
+	"bg.load_tilemap": true, "bg.set_tile": true, "bg.fill_span": true, "bg.clear": true, "bg.load_image": true,
+	"oam.write": true, "oam.write_sprite_data": true, "oam.clear_sprite": true, "oam.flush": true,
+}
+
+// emitVBlankWait polls the VBlank flag until it is set. The flag reads 1
+// for the whole VBlank window, so inside VBlank this falls straight
+// through. Clobbers R4, R5 and R7.
+func (cg *CodeGenerator) emitVBlankWait() {
+	// Wait for VBlank flag (0x803E, bit 0 = 1 means VBlank)
+	// Pattern from manual: Read flag, AND with 0x01, CMP with 0, BEQ if 0 (keep waiting)
+	cg.builder.AddInstruction(rom.EncodeMOV(1, 4, 0)) // MOV R4, #0x803E
+	cg.builder.AddImmediate(hwdef.VBLANK_FLAG)
+	waitPos := cg.builder.GetCodeLength()
+	cg.builder.AddInstruction(rom.EncodeMOV(2, 5, 4)) // MOV R5, [R4] (read flag)
+	cg.builder.AddInstruction(rom.EncodeMOV(1, 7, 0)) // MOV R7, #0x01
+	cg.builder.AddImmediate(0x01)
+	cg.builder.AddInstruction(rom.EncodeAND(0, 5, 7)) // AND R5, R7 (mask to bit 0)
+	cg.builder.AddInstruction(rom.EncodeMOV(1, 7, 0)) // MOV R7, #0
+	cg.builder.AddImmediate(0)
+	cg.builder.AddInstruction(rom.EncodeCMP(0, 5, 7)) // CMP R5, R7 (compare with 0)
+	cg.builder.AddInstruction(rom.EncodeBEQ())        // BEQ waitPos (if equal to 0, keep waiting)
+	currentPC := uint16(cg.builder.GetCodeLength() * 2)
+	offset := rom.CalculateBranchOffset(currentPC, uint16(waitPos*2))
+	cg.builder.AddImmediate(uint16(offset))
+}
+
+// emitVBlankGuard is emitVBlankWait preceded by one discarded read of the
+// flag, which clears a flag latched in the last VBlank but never read; it
+// would otherwise let the guard through during active display.
+func (cg *CodeGenerator) emitVBlankGuard() {
+	cg.builder.AddInstruction(rom.EncodeMOV(1, 4, 0)) // MOV R4, #0x803E
+	cg.builder.AddImmediate(hwdef.VBLANK_FLAG)
+	cg.builder.AddInstruction(rom.EncodeMOV(2, 5, 4)) // MOV R5, [R4] (clear a stale flag)
+	cg.emitVBlankWait()
+}
+
 func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg uint8) error {
+	if cg.target == TargetHardware && vblankGuardedBuiltins[name] {
+		cg.emitVBlankGuard()
+	}
 	switch name {
 	case "wait_vblank":
-		// Wait for VBlank flag (0x803E, bit 0 = 1 means VBlank)
-		// Pattern from manual: Read flag, AND with 0x01, CMP with 0, BEQ if 0 (keep waiting)
-		cg.builder.AddInstruction(rom.EncodeMOV(1, 4, 0)) // MOV R4, #0x803E
-		cg.builder.AddImmediate(0x803E)
-		waitPos := cg.builder.GetCodeLength()
-		cg.builder.AddInstruction(rom.EncodeMOV(2, 5, 4)) // MOV R5, [R4] (read flag)
-		cg.builder.AddInstruction(rom.EncodeMOV(1, 7, 0)) // MOV R7, #0x01
-		cg.builder.AddImmediate(0x01)
-		cg.builder.AddInstruction(rom.EncodeAND(0, 5, 7)) // AND R5, R7 (mask to bit 0)
-		cg.builder.AddInstruction(rom.EncodeMOV(1, 7, 0)) // MOV R7, #0
-		cg.builder.AddImmediate(0)
-		cg.builder.AddInstruction(rom.EncodeCMP(0, 5, 7)) // CMP R5, R7 (compare with 0)
-		cg.builder.AddInstruction(rom.EncodeBEQ())        // BEQ waitPos (if equal to 0, keep waiting)
-		currentPC := uint16(cg.builder.GetCodeLength() * 2)
-		offset := rom.CalculateBranchOffset(currentPC, uint16(waitPos*2))
-		cg.builder.AddImmediate(uint16(offset))
+		cg.emitVBlankWait()
 		// Advance any playing music once per VBlank (only emitted/called when
 		// the program declares a music asset — see emitMusicAdvanceHelper).
 		if len(cg.musicAssets) > 0 {
@@ -2069,6 +2407,19 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 		}
 		return nil
 
+	case "anim.update":
+		// anim.update() - advance every playing anim.play animation by one
+		// tick; call once per frame after wait_vblank() (it writes OAM).
+		if len(args) != 0 {
+			return fmt.Errorf("anim.update takes no arguments")
+		}
+		if !cg.hasAnimAssets() {
+			return fmt.Errorf("anim.update requires the program to declare an anim asset")
+		}
+		cg.needAnimUpdate = true
+		cg.emitHelperCall("__animupdate")
+		return nil
+
 	case "frame_counter":
 		// frame_counter() -> u32 (returns 16-bit frame counter)
 		// Read FRAME_COUNTER_LOW (0x803F) and FRAME_COUNTER_HIGH (0x8040)
@@ -2076,12 +2427,12 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 
 		// Read low byte from 0x803F
 		cg.builder.AddInstruction(rom.EncodeMOV(1, 4, 0)) // MOV R4, #0x803F
-		cg.builder.AddImmediate(0x803F)
+		cg.builder.AddImmediate(hwdef.FRAME_COUNTER_L)
 		cg.builder.AddInstruction(rom.EncodeMOV(2, 5, 4)) // MOV R5, [R4] (read low byte)
 
 		// Read high byte from 0x8040
 		cg.builder.AddInstruction(rom.EncodeMOV(1, 4, 0)) // MOV R4, #0x8040
-		cg.builder.AddImmediate(0x8040)
+		cg.builder.AddImmediate(hwdef.FRAME_COUNTER_H)
 		cg.builder.AddInstruction(rom.EncodeMOV(2, 6, 4)) // MOV R6, [R4] (read high byte)
 
 		// Combine: (high << 8) | low
@@ -2095,6 +2446,13 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 		cg.builder.AddInstruction(rom.EncodeMOV(0, destReg, 5)) // MOV R{destReg}, R5
 		return nil
 
+	case "reset_cause":
+		// reset_cause() -> u8: 0 power-on, 1 soft reset, 2 power cycle
+		cg.builder.AddInstruction(rom.EncodeMOV(1, 4, 0)) // MOV R4, #RESET_CAUSE
+		cg.builder.AddImmediate(hwdef.RESET_CAUSE)
+		cg.builder.AddInstruction(rom.EncodeMOV(6, destReg, 4)) // MOV R{dest}, [R4] (8-bit read)
+		return nil
+
 	case "sprite.set_pos":
 		// sprite.set_pos(s: *Sprite, x: i16, y: u8)
 		// Args: R0 = sprite pointer, R1 = x (i16), R2 = y (u8)
@@ -2123,14 +2481,14 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 		// fixed on-screen position).
 		cg.builder.AddInstruction(rom.EncodeMOV(1, 6, 0)) // MOV R6, #1
 		cg.builder.AddImmediate(1)
-		cg.builder.AddInstruction(rom.EncodeADD(0, 3, 6))  // ADD R3, R6 (offset 1: x_hi)
-		cg.builder.AddInstruction(rom.EncodeMOV(6, 6, 3))  // MOV R6, [R3] (existing x_hi, 8-bit load)
-		cg.hAndImm(6, 0xFE)                                // clear old sign bit, keep size-code bits
-		cg.builder.AddInstruction(rom.EncodeMOV(0, 7, 4))  // MOV R7, R4 (pristine x)
-		cg.hShrImm(7, 8)                                   // R7 = x >> 8
-		cg.hAndImm(7, 1)                                   // R7 = sign bit only
-		cg.builder.AddInstruction(rom.EncodeOR(0, 6, 7))   // OR R6, R7 -> merged x_hi
-		cg.builder.AddInstruction(rom.EncodeMOV(7, 3, 6))  // MOV [R3], R6 (8-bit store x_hi)
+		cg.builder.AddInstruction(rom.EncodeADD(0, 3, 6)) // ADD R3, R6 (offset 1: x_hi)
+		cg.builder.AddInstruction(rom.EncodeMOV(6, 6, 3)) // MOV R6, [R3] (existing x_hi, 8-bit load)
+		cg.hAndImm(6, 0xFE)                               // clear old sign bit, keep size-code bits
+		cg.builder.AddInstruction(rom.EncodeMOV(0, 7, 4)) // MOV R7, R4 (pristine x)
+		cg.hShrImm(7, 8)                                  // R7 = x >> 8
+		cg.hAndImm(7, 1)                                  // R7 = sign bit only
+		cg.builder.AddInstruction(rom.EncodeOR(0, 6, 7))  // OR R6, R7 -> merged x_hi
+		cg.builder.AddInstruction(rom.EncodeMOV(7, 3, 6)) // MOV [R3], R6 (8-bit store x_hi)
 
 		// Write y (offset 2)
 		cg.builder.AddInstruction(rom.EncodeMOV(1, 6, 0)) // MOV R6, #1
@@ -2163,7 +2521,7 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 		cg.builder.AddImmediate(1)
 		cg.builder.AddInstruction(rom.EncodeADD(0, 3, 4)) // ADD R3, R4 (offset 1: x_hi)
 		cg.builder.AddInstruction(rom.EncodeMOV(6, 5, 3)) // MOV R5, [R3] (existing x_hi, 8-bit load)
-		cg.hAndImm(5, 0x01)                                // keep only the sign bit
+		cg.hAndImm(5, 0x01)                               // keep only the sign bit
 		cg.emitSizeCodeBits(5, 1, 4)
 		cg.builder.AddInstruction(rom.EncodeMOV(7, 3, 5)) // MOV [R3], R5 (8-bit store x_hi)
 		return nil
@@ -2174,6 +2532,10 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 		// Set OAM_ADDR to the raw sprite id (0-127); the PPU internally
 		// multiplies by 6 to get the byte offset -- writing id*6 here would
 		// double-multiply and corrupt every id > 0.
+		if cg.oamShadow {
+			cg.generateOAMShadowWrite()
+			return nil
+		}
 
 		// Save sprite pointer (R1) to R3
 		cg.builder.AddInstruction(rom.EncodeMOV(0, 3, 1)) // MOV R3, R1 (sprite pointer)
@@ -2183,13 +2545,13 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 
 		// Set OAM_ADDR (0x8014) to raw id
 		cg.builder.AddInstruction(rom.EncodeMOV(1, 4, 0)) // MOV R4, #0x8014
-		cg.builder.AddImmediate(0x8014)
+		cg.builder.AddImmediate(hwdef.OAM_ADDR)
 		cg.builder.AddInstruction(rom.EncodeMOV(0, 5, 6)) // MOV R5, R6 (sprite id)
 		cg.builder.AddInstruction(rom.EncodeMOV(3, 4, 5)) // MOV [R4], R5 (write OAM_ADDR)
 
 		// Set OAM_DATA address (0x8015)
 		cg.builder.AddInstruction(rom.EncodeMOV(1, 4, 0)) // MOV R4, #0x8015
-		cg.builder.AddImmediate(0x8015)
+		cg.builder.AddImmediate(hwdef.OAM_DATA)
 
 		// Read sprite struct and write to OAM_DATA
 		// Sprite format: x_lo (offset 0), x_hi (offset 1), y (offset 2), tile (offset 3), attr (offset 4), ctrl (offset 5)
@@ -2244,6 +2606,10 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 		if len(args) != 6 {
 			return fmt.Errorf("oam.write_sprite_data requires 6 arguments")
 		}
+		if cg.oamShadow {
+			cg.generateOAMShadowWriteSpriteData()
+			return nil
+		}
 
 		idReg := uint8(0)
 		xReg := uint8(1)
@@ -2258,12 +2624,12 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 		// The PPU internally multiplies by 6 to get the byte offset
 		// Write sprite ID directly to OAM_ADDR (0x8014)
 		cg.builder.AddInstruction(rom.EncodeMOV(1, 2, 0)) // MOV R2, #0x8014
-		cg.builder.AddImmediate(0x8014)
+		cg.builder.AddImmediate(hwdef.OAM_ADDR)
 		cg.builder.AddInstruction(rom.EncodeMOV(3, 2, idReg)) // MOV [R2], R0 (sprite ID)
 
 		// Write sprite data to OAM_DATA (0x8015) using R0 as pointer (id no longer needed)
 		cg.builder.AddInstruction(rom.EncodeMOV(1, 0, 0)) // MOV R0, #0x8015
-		cg.builder.AddImmediate(0x8015)
+		cg.builder.AddImmediate(hwdef.OAM_DATA)
 
 		// X low byte (R1)
 		cg.builder.AddInstruction(rom.EncodeMOV(0, 2, xReg)) // MOV R2, R1 (x temp)
@@ -2304,13 +2670,17 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 		if len(args) != 1 {
 			return fmt.Errorf("oam.clear_sprite requires 1 argument")
 		}
+		if cg.oamShadow {
+			cg.generateOAMShadowClearSprite()
+			return nil
+		}
 
 		idReg := uint8(0)
 
 		// Set OAM_ADDR to sprite ID, then write 0 to control byte (byte 5)
 		// Write sprite ID to OAM_ADDR (0x8014)
 		cg.builder.AddInstruction(rom.EncodeMOV(1, 4, 0)) // MOV R4, #0x8014
-		cg.builder.AddImmediate(0x8014)
+		cg.builder.AddImmediate(hwdef.OAM_ADDR)
 		cg.builder.AddInstruction(rom.EncodeMOV(0, 6, idReg)) // MOV R6, R0 (sprite ID)
 		cg.builder.AddInstruction(rom.EncodeMOV(3, 4, 6))     // MOV [R4], R6 (write sprite ID to OAM_ADDR)
 
@@ -2318,7 +2688,7 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 		// The PPU uses OAM_ADDR * 6 + byte_index, so we need to write 5 dummy bytes first
 		// Actually, simpler: just write 0 to all 6 bytes to completely disable the sprite
 		cg.builder.AddInstruction(rom.EncodeMOV(1, 4, 0)) // MOV R4, #0x8015
-		cg.builder.AddImmediate(0x8015)
+		cg.builder.AddImmediate(hwdef.OAM_DATA)
 		// Write 0 to all 6 bytes (X_low, X_high, Y, Tile, Attr, Ctrl)
 		for i := 0; i < 6; i++ {
 			cg.builder.AddInstruction(rom.EncodeMOV(1, 6, 0)) // MOV R6, #0
@@ -2470,7 +2840,13 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 		return nil
 
 	case "oam.flush":
-		// oam.flush() - no-op for now
+		// oam.flush() - DMA the shadow OAM into OAM during VBlank. Any
+		// call makes oamShadow true, except in immediate mode, where the
+		// oam.* writes went straight to OAM and there is nothing to copy.
+		if cg.oamShadow {
+			cg.needOAMFlush = true
+			cg.emitHelperCall("__oamflush")
+		}
 		return nil
 
 	case "gfx.set_palette":
@@ -2492,7 +2868,7 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 
 		// Set CGRAM_ADDR (0x8012)
 		cg.builder.AddInstruction(rom.EncodeMOV(1, 6, 0)) // MOV R6, #0x8012
-		cg.builder.AddImmediate(0x8012)
+		cg.builder.AddImmediate(hwdef.CGRAM_ADDR)
 		cg.builder.AddInstruction(rom.EncodeMOV(0, 7, 3)) // MOV R7, R3 (CGRAM color index address)
 		cg.builder.AddInstruction(rom.EncodeMOV(1, 5, 0)) // MOV R5, #0xFF
 		cg.builder.AddImmediate(0xFF)
@@ -2502,7 +2878,7 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 		// Write color to CGRAM_DATA (0x8013)
 		// CGRAM_DATA requires two writes: low byte, then high byte (both to same address)
 		cg.builder.AddInstruction(rom.EncodeMOV(1, 6, 0)) // MOV R6, #0x8013
-		cg.builder.AddImmediate(0x8013)
+		cg.builder.AddImmediate(hwdef.CGRAM_DATA)
 
 		// Write low byte first
 		cg.builder.AddInstruction(rom.EncodeMOV(0, 7, 2)) // MOV R7, R2 (color)
@@ -2531,7 +2907,7 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 
 		// Set CGRAM_ADDR (0x8012)
 		cg.builder.AddInstruction(rom.EncodeMOV(1, 6, 0)) // MOV R6, #0x8012
-		cg.builder.AddImmediate(0x8012)
+		cg.builder.AddImmediate(hwdef.CGRAM_ADDR)
 		cg.builder.AddInstruction(rom.EncodeMOV(0, 7, 0)) // MOV R7, R0 (cgram_index)
 		cg.builder.AddInstruction(rom.EncodeMOV(1, 5, 0)) // MOV R5, #0xFF
 		cg.builder.AddImmediate(0xFF)
@@ -2540,7 +2916,7 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 
 		// Write color to CGRAM_DATA (0x8013): low byte, then high byte (triggers write).
 		cg.builder.AddInstruction(rom.EncodeMOV(1, 6, 0)) // MOV R6, #0x8013
-		cg.builder.AddImmediate(0x8013)
+		cg.builder.AddImmediate(hwdef.CGRAM_DATA)
 
 		cg.builder.AddInstruction(rom.EncodeMOV(0, 7, 1)) // MOV R7, R1 (color)
 		cg.builder.AddInstruction(rom.EncodeMOV(1, 5, 0)) // MOV R5, #0xFF
@@ -2576,14 +2952,14 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 
 			// Set palette 0, color i
 			cg.builder.AddInstruction(rom.EncodeMOV(1, 4, 0)) // MOV R4, #0x8012 (CGRAM_ADDR)
-			cg.builder.AddImmediate(0x8012)
+			cg.builder.AddImmediate(hwdef.CGRAM_ADDR)
 			cg.builder.AddInstruction(rom.EncodeMOV(1, 5, 0)) // MOV R5, #(i*2)
 			cg.builder.AddImmediate(uint16(i * 2))
 			cg.builder.AddInstruction(rom.EncodeMOV(3, 4, 5)) // MOV [R4], R5
 
 			// Write color
 			cg.builder.AddInstruction(rom.EncodeMOV(1, 4, 0)) // MOV R4, #0x8013 (CGRAM_DATA)
-			cg.builder.AddImmediate(0x8013)
+			cg.builder.AddImmediate(hwdef.CGRAM_DATA)
 			cg.builder.AddInstruction(rom.EncodeMOV(1, 5, 0)) // MOV R5, #color_low
 			cg.builder.AddImmediate(color & 0xFF)
 			cg.builder.AddInstruction(rom.EncodeMOV(3, 4, 5)) // MOV [R4], R5 (low byte)
@@ -2603,13 +2979,13 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 			color := (comp << 1) // Blue in bits 5-1
 
 			cg.builder.AddInstruction(rom.EncodeMOV(1, 4, 0)) // MOV R4, #0x8012
-			cg.builder.AddImmediate(0x8012)
+			cg.builder.AddImmediate(hwdef.CGRAM_ADDR)
 			cg.builder.AddInstruction(rom.EncodeMOV(1, 5, 0)) // MOV R5, #(16*2 + i*2)
 			cg.builder.AddImmediate(uint16(16*2 + i*2))
 			cg.builder.AddInstruction(rom.EncodeMOV(3, 4, 5)) // MOV [R4], R5
 
 			cg.builder.AddInstruction(rom.EncodeMOV(1, 4, 0)) // MOV R4, #0x8013
-			cg.builder.AddImmediate(0x8013)
+			cg.builder.AddImmediate(hwdef.CGRAM_DATA)
 			cg.builder.AddInstruction(rom.EncodeMOV(1, 5, 0)) // MOV R5, #color_low
 			cg.builder.AddImmediate(color & 0xFF)
 			cg.builder.AddInstruction(rom.EncodeMOV(3, 4, 5)) // MOV [R4], R5
@@ -2628,13 +3004,13 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 			color := (comp << 6) // Green in bits 10-6
 
 			cg.builder.AddInstruction(rom.EncodeMOV(1, 4, 0)) // MOV R4, #0x8012
-			cg.builder.AddImmediate(0x8012)
+			cg.builder.AddImmediate(hwdef.CGRAM_ADDR)
 			cg.builder.AddInstruction(rom.EncodeMOV(1, 5, 0)) // MOV R5, #(32*2 + i*2)
 			cg.builder.AddImmediate(uint16(32*2 + i*2))
 			cg.builder.AddInstruction(rom.EncodeMOV(3, 4, 5)) // MOV [R4], R5
 
 			cg.builder.AddInstruction(rom.EncodeMOV(1, 4, 0)) // MOV R4, #0x8013
-			cg.builder.AddImmediate(0x8013)
+			cg.builder.AddImmediate(hwdef.CGRAM_DATA)
 			cg.builder.AddInstruction(rom.EncodeMOV(1, 5, 0)) // MOV R5, #color_low
 			cg.builder.AddImmediate(color & 0xFF)
 			cg.builder.AddInstruction(rom.EncodeMOV(3, 4, 5)) // MOV [R4], R5
@@ -2653,13 +3029,13 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 			color := (comp << 11) // Red in bits 15-11
 
 			cg.builder.AddInstruction(rom.EncodeMOV(1, 4, 0)) // MOV R4, #0x8012
-			cg.builder.AddImmediate(0x8012)
+			cg.builder.AddImmediate(hwdef.CGRAM_ADDR)
 			cg.builder.AddInstruction(rom.EncodeMOV(1, 5, 0)) // MOV R5, #(48*2 + i*2)
 			cg.builder.AddImmediate(uint16(48*2 + i*2))
 			cg.builder.AddInstruction(rom.EncodeMOV(3, 4, 5)) // MOV [R4], R5
 
 			cg.builder.AddInstruction(rom.EncodeMOV(1, 4, 0)) // MOV R4, #0x8013
-			cg.builder.AddImmediate(0x8013)
+			cg.builder.AddImmediate(hwdef.CGRAM_DATA)
 			cg.builder.AddInstruction(rom.EncodeMOV(1, 5, 0)) // MOV R5, #color_low
 			cg.builder.AddImmediate(color & 0xFF)
 			cg.builder.AddInstruction(rom.EncodeMOV(3, 4, 5)) // MOV [R4], R5
@@ -2680,7 +3056,7 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 		// ppu.enable_display() (the normal Start() ordering) would have its
 		// BG0 priority reset back to 0 right before the first frame renders.
 		cg.builder.AddInstruction(rom.EncodeMOV(1, 4, 0)) // MOV R4, #0x8008
-		cg.builder.AddImmediate(0x8008)
+		cg.builder.AddImmediate(hwdef.BG0_CONTROL)
 		cg.builder.AddInstruction(rom.EncodeMOV(2, 5, 4)) // MOV R5, [R4] (read current)
 		cg.builder.AddInstruction(rom.EncodeMOV(1, 6, 0)) // MOV R6, #0x01
 		cg.builder.AddImmediate(0x01)
@@ -2688,6 +3064,15 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 		cg.builder.AddInstruction(rom.EncodeMOV(3, 4, 5)) // MOV [R4], R5 (write back)
 		return nil
 
+	case "ppu.set_border_color":
+		// ppu.set_border_color(index: u8)
+		// Args: R0 = CGRAM index (palette*16 + color) of the overscan border
+		if len(args) != 1 {
+			return fmt.Errorf("ppu.set_border_color requires 1 argument (index)")
+		}
+		cg.storeIOByte(hwdef.BORDER_COLOR, 0)
+		return nil
+
 	case "boot.show_default":
 		// boot.show_default(): call the merged-in default logo slide+hold
 		// sequence (see boot_splash.go's injectBootEntry, which always
@@ -2721,7 +3106,13 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 		if len(cg.program.Assets) == 0 {
 			return fmt.Errorf("gfx.load_tiles requires declared assets in this source file")
 		}
-		return cg.generateRuntimeTileLoadDispatch(destReg)
+		return cg.generateRuntimeTileLoad(destReg)
+
+	case "gfx.load_tiles_compressed":
+		// gfx.load_tiles_compressed(asset: u16, base: u16) -> u16
+		// Expands a whole lz tile asset into VRAM from tile index base;
+		// returns base (see tiles_lz.go).
+		return cg.generateLoadTilesCompressed(args, destReg)
 
 	case "input.read":
 		// input.read() -> u16
@@ -2729,17 +3120,17 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 		// Latch buttons first, then read
 		// Latch: write 1 to 0xA001
 		cg.builder.AddInstruction(rom.EncodeMOV(1, 4, 0)) // MOV R4, #0xA001
-		cg.builder.AddImmediate(0xA001)
+		cg.builder.AddImmediate(hwdef.CONTROLLER1_H)
 		cg.builder.AddInstruction(rom.EncodeMOV(1, 5, 0)) // MOV R5, #1
 		cg.builder.AddImmediate(1)
 		cg.builder.AddInstruction(rom.EncodeMOV(3, 4, 5)) // MOV [R4], R5 (latch)
 		// Read low byte from 0xA000
 		cg.builder.AddInstruction(rom.EncodeMOV(1, 4, 0)) // MOV R4, #0xA000
-		cg.builder.AddImmediate(0xA000)
+		cg.builder.AddImmediate(hwdef.CONTROLLER1_L)
 		cg.builder.AddInstruction(rom.EncodeMOV(2, 5, 4)) // MOV R5, [R4] (read low byte)
 		// Read high byte from 0xA001
 		cg.builder.AddInstruction(rom.EncodeMOV(1, 4, 0)) // MOV R4, #0xA001
-		cg.builder.AddImmediate(0xA001)
+		cg.builder.AddImmediate(hwdef.CONTROLLER1_H)
 		cg.builder.AddInstruction(rom.EncodeMOV(2, 6, 4)) // MOV R6, [R4] (read high byte)
 		// Combine: R5 (low) | (R6 << 8) (high)
 		cg.builder.AddInstruction(rom.EncodeMOV(1, 7, 0)) // MOV R7, #8
@@ -2748,7 +3139,7 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 		cg.builder.AddInstruction(rom.EncodeOR(0, 5, 6))  // OR R5, R6 -> R5 = low | (high << 8)
 		// Release latch: write 0 to 0xA001
 		cg.builder.AddInstruction(rom.EncodeMOV(1, 4, 0)) // MOV R4, #0xA001
-		cg.builder.AddImmediate(0xA001)
+		cg.builder.AddImmediate(hwdef.CONTROLLER1_H)
 		cg.builder.AddInstruction(rom.EncodeMOV(1, 6, 0)) // MOV R6, #0
 		cg.builder.AddImmediate(0)
 		cg.builder.AddInstruction(rom.EncodeMOV(3, 4, 6)) // MOV [R4], R6 (release latch)
@@ -2766,7 +3157,7 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 		// apu.enable() - Enable APU master volume
 		// Write 0xFF to MASTER_VOLUME (0x9020)
 		cg.builder.AddInstruction(rom.EncodeMOV(1, 4, 0)) // MOV R4, #0x9020
-		cg.builder.AddImmediate(0x9020)
+		cg.builder.AddImmediate(hwdef.MASTER_VOLUME)
 		cg.builder.AddInstruction(rom.EncodeMOV(1, 5, 0)) // MOV R5, #0xFF
 		cg.builder.AddImmediate(0xFF)
 		cg.builder.AddInstruction(rom.EncodeMOV(3, 4, 5)) // MOV [R4], R5 (write master volume)
@@ -2786,7 +3177,7 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 		cg.builder.AddImmediate(3)
 		cg.builder.AddInstruction(rom.EncodeSHL(0, 4, 5)) // SHL R4, R5 -> R4 = ch << 3 = ch * 8
 		cg.builder.AddInstruction(rom.EncodeMOV(1, 5, 0)) // MOV R5, #0x9000
-		cg.builder.AddImmediate(0x9000)
+		cg.builder.AddImmediate(hwdef.APUBase)
 		cg.builder.AddInstruction(rom.EncodeADD(0, 4, 5)) // ADD R4, R5 -> R4 = 0x9000 + (ch * 8)
 
 		// Add offset 3 for CONTROL register
@@ -2820,7 +3211,7 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 		cg.builder.AddImmediate(3)
 		cg.builder.AddInstruction(rom.EncodeSHL(0, 4, 5)) // SHL R4, R5 -> R4 = ch << 3 = ch * 8
 		cg.builder.AddInstruction(rom.EncodeMOV(1, 5, 0)) // MOV R5, #0x9000
-		cg.builder.AddImmediate(0x9000)
+		cg.builder.AddImmediate(hwdef.APUBase)
 		cg.builder.AddInstruction(rom.EncodeADD(0, 4, 5)) // ADD R4, R5 -> R4 = 0x9000 + (ch * 8)
 
 		// Save frequency value
@@ -2856,7 +3247,7 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 		cg.builder.AddImmediate(3)
 		cg.builder.AddInstruction(rom.EncodeSHL(0, 4, 5)) // SHL R4, R5 -> R4 = ch << 3 = ch * 8
 		cg.builder.AddInstruction(rom.EncodeMOV(1, 5, 0)) // MOV R5, #0x9000
-		cg.builder.AddImmediate(0x9000)
+		cg.builder.AddImmediate(hwdef.APUBase)
 		cg.builder.AddInstruction(rom.EncodeADD(0, 4, 5)) // ADD R4, R5 -> R4 = 0x9000 + (ch * 8)
 
 		// Add offset 2 for VOLUME register
@@ -2880,7 +3271,7 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 		cg.builder.AddImmediate(3)
 		cg.builder.AddInstruction(rom.EncodeSHL(0, 4, 5)) // SHL R4, R5 -> R4 = ch << 3 = ch * 8
 		cg.builder.AddInstruction(rom.EncodeMOV(1, 5, 0)) // MOV R5, #0x9000
-		cg.builder.AddImmediate(0x9000)
+		cg.builder.AddImmediate(hwdef.APUBase)
 		cg.builder.AddInstruction(rom.EncodeADD(0, 4, 5)) // ADD R4, R5 -> R4 = 0x9000 + (ch * 8)
 
 		// Add offset 3 for CONTROL register
@@ -2908,7 +3299,7 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 		cg.builder.AddImmediate(3)
 		cg.builder.AddInstruction(rom.EncodeSHL(0, 4, 5)) // SHL R4, R5 -> R4 = ch << 3 = ch * 8
 		cg.builder.AddInstruction(rom.EncodeMOV(1, 5, 0)) // MOV R5, #0x9000
-		cg.builder.AddImmediate(0x9000)
+		cg.builder.AddImmediate(hwdef.APUBase)
 		cg.builder.AddInstruction(rom.EncodeADD(0, 4, 5)) // ADD R4, R5 -> R4 = 0x9000 + (ch * 8)
 
 		// Add offset 3 for CONTROL register
@@ -2972,19 +3363,19 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 		cg.hLoad16(0, inputCurrSlot)
 		cg.hStore16(inputPrevSlot, 0)
 		// latch: write 1 to 0xA001
-		cg.hMovImm(4, 0xA001)
+		cg.hMovImm(4, hwdef.CONTROLLER1_H)
 		cg.hMovImm(5, 1)
 		cg.builder.AddInstruction(rom.EncodeMOV(3, 4, 5)) // [0xA001] = 1
 		// read low (0xA000) and high (0xA001) bytes, combine
-		cg.hMovImm(4, 0xA000)
+		cg.hMovImm(4, hwdef.CONTROLLER1_L)
 		cg.builder.AddInstruction(rom.EncodeMOV(2, 5, 4)) // R5 = [0xA000] low
-		cg.hMovImm(4, 0xA001)
+		cg.hMovImm(4, hwdef.CONTROLLER1_H)
 		cg.builder.AddInstruction(rom.EncodeMOV(2, 6, 4)) // R6 = [0xA001] high
 		cg.hMovImm(7, 8)
 		cg.builder.AddInstruction(rom.EncodeSHL(0, 6, 7)) // R6 = high << 8
 		cg.builder.AddInstruction(rom.EncodeOR(0, 5, 6))  // R5 = low | (high<<8)
 		// release latch: write 0 to 0xA001
-		cg.hMovImm(4, 0xA001)
+		cg.hMovImm(4, hwdef.CONTROLLER1_H)
 		cg.hMovImm(6, 0)
 		cg.builder.AddInstruction(rom.EncodeMOV(3, 4, 6)) // [0xA001] = 0
 		// curr = combined state
@@ -3067,8 +3458,8 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 		if len(args) != 2 {
 			return fmt.Errorf("ym.write requires 2 arguments (addr, value)")
 		}
-		cg.storeIOByte(0x9100, 0) // FMRegAddr: select register
-		cg.storeIOByte(0x9101, 1) // FMRegData: data
+		cg.storeIOByte(hwdef.FM_ADDR, 0) // FMRegAddr: select register
+		cg.storeIOByte(hwdef.FM_DATA, 1) // FMRegData: data
 		return nil
 
 	case "ym.write_port1":
@@ -3079,8 +3470,8 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 		if len(args) != 2 {
 			return fmt.Errorf("ym.write_port1 requires 2 arguments (addr, value)")
 		}
-		cg.storeIOByte(0x9104, 0) // port-1 address select
-		cg.storeIOByte(0x9105, 1) // port-1 data
+		cg.storeIOByte(hwdef.FM_PORT1_ADDR, 0) // port-1 address select
+		cg.storeIOByte(hwdef.FM_PORT1_DATA, 1) // port-1 data
 		return nil
 
 	case "music.stop":
@@ -3102,7 +3493,7 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 		}
 		cg.hMovImm(6, 0)
 		cg.hStore16(musicFadeActiveSlot, 6)
-		cg.storeIOByte(0x9106, 0)
+		cg.storeIOByte(hwdef.FM_VOLUME, 0)
 		return nil
 
 	case "music.fade_to":
@@ -3123,6 +3514,28 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 		cg.hStore16(musicFadeActiveSlot, 6)
 		return nil
 
+	case "audio.set_lowpass":
+		// audio.set_lowpass(amount: u8) - master low-pass filter strength
+		// (LOWPASS, 0x9031); 0 turns it off. Arg is in R0.
+		if len(args) != 1 {
+			return fmt.Errorf("audio.set_lowpass requires 1 argument (0-255)")
+		}
+		cg.storeIOByte(hwdef.LOWPASS, 0)
+		return nil
+
+	case "audio.set_echo":
+		// audio.set_echo(delay: u8, feedback: u8, mix: u8) - master echo.
+		// delay is in 4 ms steps and 0 turns the echo off; it is written
+		// last so the echo starts with its feedback and mix already set.
+		// Args: R0 = delay, R1 = feedback, R2 = mix.
+		if len(args) != 3 {
+			return fmt.Errorf("audio.set_echo requires 3 arguments (delay, feedback, mix)")
+		}
+		cg.storeIOByte(hwdef.ECHO_FEEDBACK, 1)
+		cg.storeIOByte(hwdef.ECHO_MIX, 2)
+		cg.storeIOByte(hwdef.ECHO_DELAY, 0)
+		return nil
+
 	case "bg.set_scroll":
 		// bg.set_scroll(layer: u8, scroll_x: i16, scroll_y: i16)
 		// Args: R0 = layer (0-3), R1 = scroll_x, R2 = scroll_y
@@ -3221,7 +3634,7 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 		if len(args) != 1 {
 			return fmt.Errorf("bg.enable requires 1 argument (layer)")
 		}
-		bgCtrlAddrs := []uint16{0x8008, 0x8009, 0x8021, 0x8026}
+		bgCtrlAddrs := []uint16{hwdef.BG0_CONTROL, hwdef.BG1_CONTROL, hwdef.BG2_CONTROL, hwdef.BG3_CONTROL}
 		jumpToEnd2 := make([]int, 0, 4)
 		for i, addr := range bgCtrlAddrs {
 			cg.builder.AddInstruction(rom.EncodeMOV(1, 6, 0))
@@ -3264,7 +3677,7 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 		if len(args) != 1 {
 			return fmt.Errorf("bg.disable requires 1 argument (layer)")
 		}
-		bgCtrlAddrs := []uint16{0x8008, 0x8009, 0x8021, 0x8026}
+		bgCtrlAddrs := []uint16{hwdef.BG0_CONTROL, hwdef.BG1_CONTROL, hwdef.BG2_CONTROL, hwdef.BG3_CONTROL}
 		jumpToEnd2 := make([]int, 0, 4)
 		for i, addr := range bgCtrlAddrs {
 			cg.builder.AddInstruction(rom.EncodeMOV(1, 6, 0))
@@ -3305,7 +3718,7 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 		if len(args) != 2 {
 			return fmt.Errorf("bg.set_tile_size requires 2 arguments (layer, size)")
 		}
-		bgCtrlAddrs := []uint16{0x8008, 0x8009, 0x8021, 0x8026}
+		bgCtrlAddrs := []uint16{hwdef.BG0_CONTROL, hwdef.BG1_CONTROL, hwdef.BG2_CONTROL, hwdef.BG3_CONTROL}
 		jumpToEnd := make([]int, 0, 4)
 		for i, addr := range bgCtrlAddrs {
 			cg.builder.AddInstruction(rom.EncodeMOV(1, 6, 0))
@@ -3363,7 +3776,7 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 		if len(args) != 2 {
 			return fmt.Errorf("bg.set_priority requires 2 arguments (layer, priority)")
 		}
-		bgCtrlAddrs := []uint16{0x8008, 0x8009, 0x8021, 0x8026}
+		bgCtrlAddrs := []uint16{hwdef.BG0_CONTROL, hwdef.BG1_CONTROL, hwdef.BG2_CONTROL, hwdef.BG3_CONTROL}
 		jumpToEnd := make([]int, 0, 4)
 		for i, addr := range bgCtrlAddrs {
 			cg.builder.AddInstruction(rom.EncodeMOV(1, 6, 0))
@@ -3413,7 +3826,7 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 		if len(args) != 2 {
 			return fmt.Errorf("bg.set_tilemap_base requires 2 arguments (layer, base)")
 		}
-		bgTilemapAddrs := []uint16{0x8077, 0x8079, 0x807B, 0x807D}
+		bgTilemapAddrs := []uint16{hwdef.BG0_TILEMAP_BASE_L, hwdef.BG1_TILEMAP_BASE_L, hwdef.BG2_TILEMAP_BASE_L, hwdef.BG3_TILEMAP_BASE_L}
 		jumpToEnd := make([]int, 0, 4)
 		for i, addr := range bgTilemapAddrs {
 			cg.builder.AddInstruction(rom.EncodeMOV(1, 6, 0))
@@ -3485,7 +3898,7 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 		if len(args) != 5 {
 			return fmt.Errorf("bg.set_tile requires 5 arguments (layer, x, y, tile, attr)")
 		}
-		bgTilemapAddrs := []uint16{0x8077, 0x8079, 0x807B, 0x807D}
+		bgTilemapAddrs := []uint16{hwdef.BG0_TILEMAP_BASE_L, hwdef.BG1_TILEMAP_BASE_L, hwdef.BG2_TILEMAP_BASE_L, hwdef.BG3_TILEMAP_BASE_L}
 		jumpToEnd := make([]int, 0, 4)
 		for i, addr := range bgTilemapAddrs {
 			cg.builder.AddInstruction(rom.EncodeMOV(1, 6, 0))
@@ -3530,7 +3943,7 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 
 			// Program VRAM address registers with R5.
 			cg.builder.AddInstruction(rom.EncodeMOV(1, 6, 0))
-			cg.builder.AddImmediate(0x800E)
+			cg.builder.AddImmediate(hwdef.VRAM_ADDR_L)
 			cg.builder.AddInstruction(rom.EncodeMOV(0, 7, 5))
 			cg.builder.AddInstruction(rom.EncodeMOV(1, 0, 0))
 			cg.builder.AddImmediate(0x00FF)
@@ -3538,7 +3951,7 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 			cg.builder.AddInstruction(rom.EncodeMOV(3, 6, 7))
 
 			cg.builder.AddInstruction(rom.EncodeMOV(1, 6, 0))
-			cg.builder.AddImmediate(0x800F)
+			cg.builder.AddImmediate(hwdef.VRAM_ADDR_H)
 			cg.builder.AddInstruction(rom.EncodeMOV(0, 7, 5))
 			cg.builder.AddInstruction(rom.EncodeMOV(1, 0, 0))
 			cg.builder.AddImmediate(8)
@@ -3547,7 +3960,7 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 
 			// Write tile + attr via auto-incrementing VRAM_DATA.
 			cg.builder.AddInstruction(rom.EncodeMOV(1, 6, 0))
-			cg.builder.AddImmediate(0x8010)
+			cg.builder.AddImmediate(hwdef.VRAM_DATA)
 			cg.builder.AddInstruction(rom.EncodeMOV(3, 6, 3))
 			cg.builder.AddInstruction(rom.EncodeMOV(3, 6, 4))
 
@@ -3574,7 +3987,7 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 		if len(args) != 6 {
 			return fmt.Errorf("bg.fill_span requires 6 arguments (layer, x, y, count, tile, attr)")
 		}
-		bgTilemapAddrs := []uint16{0x8077, 0x8079, 0x807B, 0x807D}
+		bgTilemapAddrs := []uint16{hwdef.BG0_TILEMAP_BASE_L, hwdef.BG1_TILEMAP_BASE_L, hwdef.BG2_TILEMAP_BASE_L, hwdef.BG3_TILEMAP_BASE_L}
 		jumpToEnd := make([]int, 0, 4)
 		for i, addr := range bgTilemapAddrs {
 			cg.builder.AddInstruction(rom.EncodeMOV(1, 6, 0))
@@ -3619,7 +4032,7 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 
 			// Program VRAM address once; VRAM_DATA auto-increments.
 			cg.builder.AddInstruction(rom.EncodeMOV(1, 7, 0))
-			cg.builder.AddImmediate(0x800E)
+			cg.builder.AddImmediate(hwdef.VRAM_ADDR_L)
 			cg.builder.AddInstruction(rom.EncodeMOV(0, 1, 6))
 			cg.builder.AddInstruction(rom.EncodeMOV(1, 0, 0))
 			cg.builder.AddImmediate(0x00FF)
@@ -3627,7 +4040,7 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 			cg.builder.AddInstruction(rom.EncodeMOV(3, 7, 1))
 
 			cg.builder.AddInstruction(rom.EncodeMOV(1, 7, 0))
-			cg.builder.AddImmediate(0x800F)
+			cg.builder.AddImmediate(hwdef.VRAM_ADDR_H)
 			cg.builder.AddInstruction(rom.EncodeMOV(0, 1, 6))
 			cg.builder.AddInstruction(rom.EncodeMOV(1, 0, 0))
 			cg.builder.AddImmediate(8)
@@ -3644,7 +4057,7 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 			cg.builder.AddImmediate(0)
 
 			cg.builder.AddInstruction(rom.EncodeMOV(1, 7, 0))
-			cg.builder.AddImmediate(0x8010)
+			cg.builder.AddImmediate(hwdef.VRAM_DATA)
 			cg.builder.AddInstruction(rom.EncodeMOV(3, 7, 4))
 			cg.builder.AddInstruction(rom.EncodeMOV(3, 7, 5))
 
@@ -3682,7 +4095,7 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 		if len(args) != 3 {
 			return fmt.Errorf("bg.clear requires 3 arguments (layer, tile, attr)")
 		}
-		bgTilemapAddrs := []uint16{0x8077, 0x8079, 0x807B, 0x807D}
+		bgTilemapAddrs := []uint16{hwdef.BG0_TILEMAP_BASE_L, hwdef.BG1_TILEMAP_BASE_L, hwdef.BG2_TILEMAP_BASE_L, hwdef.BG3_TILEMAP_BASE_L}
 		jumpToEnd := make([]int, 0, 4)
 		for i, addr := range bgTilemapAddrs {
 			cg.builder.AddInstruction(rom.EncodeMOV(1, 6, 0))
@@ -3716,7 +4129,7 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 
 			// Program VRAM address once.
 			cg.builder.AddInstruction(rom.EncodeMOV(1, 5, 0))
-			cg.builder.AddImmediate(0x800E)
+			cg.builder.AddImmediate(hwdef.VRAM_ADDR_L)
 			cg.builder.AddInstruction(rom.EncodeMOV(0, 6, 4))
 			cg.builder.AddInstruction(rom.EncodeMOV(1, 7, 0))
 			cg.builder.AddImmediate(0x00FF)
@@ -3724,7 +4137,7 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 			cg.builder.AddInstruction(rom.EncodeMOV(3, 5, 6))
 
 			cg.builder.AddInstruction(rom.EncodeMOV(1, 5, 0))
-			cg.builder.AddImmediate(0x800F)
+			cg.builder.AddImmediate(hwdef.VRAM_ADDR_H)
 			cg.builder.AddInstruction(rom.EncodeMOV(0, 6, 4))
 			cg.builder.AddInstruction(rom.EncodeMOV(1, 7, 0))
 			cg.builder.AddImmediate(8)
@@ -3743,7 +4156,7 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 			cg.builder.AddImmediate(0)
 
 			cg.builder.AddInstruction(rom.EncodeMOV(1, 4, 0))
-			cg.builder.AddImmediate(0x8010)
+			cg.builder.AddImmediate(hwdef.VRAM_DATA)
 			cg.builder.AddInstruction(rom.EncodeMOV(3, 4, 1))
 			cg.builder.AddInstruction(rom.EncodeMOV(3, 4, 2))
 
@@ -3789,6 +4202,9 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 		if img == nil {
 			return fmt.Errorf("matrix_plane.load_bitmap: %q is not an image asset", ident.Name)
 		}
+		if img.Kind != "bitmap_plane" {
+			return fmt.Errorf("matrix_plane.load_bitmap: %q is a %s image; load it with bg.load_image", ident.Name, img.Kind)
+		}
 		chVal, err := evalConstExpr(args[1], cg.consts)
 		if err != nil {
 			return fmt.Errorf("matrix_plane.load_bitmap: channel must be a constant: %w", err)
@@ -3796,6 +4212,67 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 		cg.emitLoadBitmap(img, uint8(chVal))
 		return nil
 
+	case "bg.load_image":
+		// bg.load_image(asset, layer): upload an 8bpp background image asset
+		// (tiles, tilemap and, for bg8_indexed, its palette) by DMA from the
+		// ROM data region and point the layer at it.
+		if len(args) != 2 {
+			return fmt.Errorf("bg.load_image requires (asset, layer)")
+		}
+		ident, ok := args[0].(*IdentExpr)
+		if !ok {
+			return fmt.Errorf("bg.load_image: first argument must be an image asset name")
+		}
+		img := cg.imageAssets[ident.Name]
+		if img == nil {
+			return fmt.Errorf("bg.load_image: %q is not an image asset", ident.Name)
+		}
+		if !img.isBG8() {
+			return fmt.Errorf("bg.load_image: %q is a %s image; only bg8_indexed and bg8_direct images load onto a background", ident.Name, img.Kind)
+		}
+		layer, err := evalConstExpr(args[1], cg.consts)
+		if err != nil || layer < 0 || layer > 3 {
+			return fmt.Errorf("bg.load_image: layer must be a constant 0-3")
+		}
+		cg.emitLoadBG8Image(img, int(layer))
+		return nil
+
+	case "bg.set_line_scroll":
+		// bg.set_line_scroll(table): point BG0_LINE_SCROLL at a global int
+		// array of at least 200 entries; entry y is added to BG0's X scroll
+		// on scanline y. The array is resolved at compile time to its WRAM
+		// address.
+		if len(args) != 1 {
+			return fmt.Errorf("bg.set_line_scroll requires 1 argument (a global int array)")
+		}
+		ident, ok := args[0].(*IdentExpr)
+		var info *VariableInfo
+		if ok {
+			info = cg.globals[ident.Name]
+		}
+		if info == nil || info.ArrayLen == 0 || info.ElemWidth != 2 {
+			return fmt.Errorf("bg.set_line_scroll: argument must be a global int array")
+		}
+		if info.ArrayLen < 200 {
+			return fmt.Errorf("bg.set_line_scroll: %s has %d entries; the table needs one per visible scanline (200)", info.Name, info.ArrayLen)
+		}
+		cg.hMovImm(0, info.StackAddr)
+		cg.storeIOByte(hwdef.BG0_LINE_SCROLL_L, 0)
+		cg.builder.AddInstruction(rom.EncodeSHR(1, 0, 0))
+		cg.builder.AddImmediate(8)
+		cg.storeIOByte(hwdef.BG0_LINE_SCROLL_H, 0)
+		return nil
+
+	case "bg.clear_line_scroll":
+		// bg.clear_line_scroll(): turn BG0 line scroll off.
+		if len(args) != 0 {
+			return fmt.Errorf("bg.clear_line_scroll takes no arguments")
+		}
+		cg.hMovImm(0, 0)
+		cg.storeIOByte(hwdef.BG0_LINE_SCROLL_L, 0)
+		cg.storeIOByte(hwdef.BG0_LINE_SCROLL_H, 0)
+		return nil
+
 	case "matrix_plane.set_projection":
 		// set_projection(channel, mode, horizon): selects the plane, then sets
 		// projection mode (0x8091) and horizon scanline (0x8092). mode: 0 none,
@@ -3900,7 +4377,7 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 		}
 		cg.hAndImm(0, 0x0001)
 		cg.builder.AddInstruction(rom.EncodeOR(0, 0, 6))
-		cg.storeIOByte(0x808C, 0)
+		cg.storeIOByte(hwdef.MATRIX_PLANE_FLAGS, 0)
 		return nil
 
 	case "matrix_plane.enable":
@@ -3945,7 +4422,7 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 		cg.builder.SetImmediateAt(after64Pos, uint16(rom.CalculateBranchOffset(uint16(after64Pos*2), after64PC)))
 
 		cg.builder.AddInstruction(rom.EncodeMOV(1, 6, 0))
-		cg.builder.AddImmediate(0x8081)
+		cg.builder.AddImmediate(hwdef.MATRIX_PLANE_CONTROL)
 		cg.builder.AddInstruction(rom.EncodeMOV(3, 6, 5))
 		return nil
 
@@ -3961,7 +4438,7 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 		cg.builder.AddImmediate(0x06)
 		cg.builder.AddInstruction(rom.EncodeAND(0, 5, 6))
 		cg.builder.AddInstruction(rom.EncodeMOV(1, 6, 0))
-		cg.builder.AddImmediate(0x8081)
+		cg.builder.AddImmediate(hwdef.MATRIX_PLANE_CONTROL)
 		cg.builder.AddInstruction(rom.EncodeMOV(3, 6, 5))
 		return nil
 
@@ -4014,21 +4491,21 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 		cg.builder.AddImmediate(1)
 		cg.builder.AddInstruction(rom.EncodeSHL(0, 6, 7))
 		cg.builder.AddInstruction(rom.EncodeMOV(1, 7, 0))
-		cg.builder.AddImmediate(0x8082)
+		cg.builder.AddImmediate(hwdef.MATRIX_PLANE_ADDR_L)
 		cg.builder.AddInstruction(rom.EncodeMOV(0, 0, 6))
 		cg.builder.AddInstruction(rom.EncodeMOV(1, 5, 0))
 		cg.builder.AddImmediate(0x00FF)
 		cg.builder.AddInstruction(rom.EncodeAND(0, 0, 5))
 		cg.builder.AddInstruction(rom.EncodeMOV(3, 7, 0))
 		cg.builder.AddInstruction(rom.EncodeMOV(1, 7, 0))
-		cg.builder.AddImmediate(0x8083)
+		cg.builder.AddImmediate(hwdef.MATRIX_PLANE_ADDR_H)
 		cg.builder.AddInstruction(rom.EncodeMOV(0, 0, 6))
 		cg.builder.AddInstruction(rom.EncodeMOV(1, 5, 0))
 		cg.builder.AddImmediate(8)
 		cg.builder.AddInstruction(rom.EncodeSHR(0, 0, 5))
 		cg.builder.AddInstruction(rom.EncodeMOV(3, 7, 0))
 		cg.builder.AddInstruction(rom.EncodeMOV(1, 7, 0))
-		cg.builder.AddImmediate(0x8084)
+		cg.builder.AddImmediate(hwdef.MATRIX_PLANE_DATA)
 		cg.builder.AddInstruction(rom.EncodeMOV(3, 7, 3))
 		cg.builder.AddInstruction(rom.EncodeMOV(3, 7, 4))
 		return nil
@@ -4064,14 +4541,14 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 
 		// Program table base.
 		cg.builder.AddInstruction(rom.EncodeMOV(1, 6, 0))
-		cg.builder.AddImmediate(0x805E)
+		cg.builder.AddImmediate(hwdef.HDMA_TABLE_BASE_L)
 		cg.builder.AddInstruction(rom.EncodeMOV(0, 7, 0))
 		cg.builder.AddInstruction(rom.EncodeAND(1, 7, 0))
 		cg.builder.AddImmediate(0x00FF)
 		cg.builder.AddInstruction(rom.EncodeMOV(3, 6, 7))
 
 		cg.builder.AddInstruction(rom.EncodeMOV(1, 6, 0))
-		cg.builder.AddImmediate(0x805F)
+		cg.builder.AddImmediate(hwdef.HDMA_TABLE_BASE_H)
 		cg.builder.AddInstruction(rom.EncodeMOV(0, 7, 0))
 		cg.builder.AddInstruction(rom.EncodeSHR(1, 7, 0))
 		cg.builder.AddImmediate(8)
@@ -4114,12 +4591,12 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 		cg.builder.AddInstruction(rom.EncodeOR(0, 7, 6))
 
 		cg.builder.AddInstruction(rom.EncodeMOV(1, 6, 0))
-		cg.builder.AddImmediate(0x805D)
+		cg.builder.AddImmediate(hwdef.HDMA_CONTROL)
 		cg.builder.AddInstruction(rom.EncodeMOV(3, 6, 7))
 
 		// Extension control: source-mode table bit 0.
 		cg.builder.AddInstruction(rom.EncodeMOV(1, 6, 0))
-		cg.builder.AddImmediate(0x807F)
+		cg.builder.AddImmediate(hwdef.HDMA_EXTENSION_CONTROL)
 		cg.builder.AddInstruction(rom.EncodeMOV(0, 7, 5))
 		cg.builder.AddInstruction(rom.EncodeAND(1, 7, 0))
 		cg.builder.AddImmediate(0x01)
@@ -4133,13 +4610,13 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 			return fmt.Errorf("raster.disable requires 0 arguments")
 		}
 		cg.builder.AddInstruction(rom.EncodeMOV(1, 4, 0))
-		cg.builder.AddImmediate(0x805D)
+		cg.builder.AddImmediate(hwdef.HDMA_CONTROL)
 		cg.builder.AddInstruction(rom.EncodeMOV(1, 5, 0))
 		cg.builder.AddImmediate(0)
 		cg.builder.AddInstruction(rom.EncodeMOV(3, 4, 5))
 
 		cg.builder.AddInstruction(rom.EncodeMOV(1, 4, 0))
-		cg.builder.AddImmediate(0x807F)
+		cg.builder.AddImmediate(hwdef.HDMA_EXTENSION_CONTROL)
 		cg.builder.AddInstruction(rom.EncodeMOV(1, 5, 0))
 		cg.builder.AddImmediate(0)
 		cg.builder.AddInstruction(rom.EncodeMOV(3, 4, 5))
@@ -4317,11 +4794,11 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 
 	case "bg.set_source_mode":
 		// bg.set_source_mode(layer: u8, mode: u8)
-		// Args: R0 = layer, R1 = source mode (0=tilemap, 1=bitmap)
+		// Args: R0 = layer, R1 = source mode (bit 0 = bitmap latch, bits 2:1 = color format)
 		if len(args) != 2 {
 			return fmt.Errorf("bg.set_source_mode requires 2 arguments (layer, mode)")
 		}
-		bgSourceModeAddrs := []uint16{0x8068, 0x8069, 0x806A, 0x806B}
+		bgSourceModeAddrs := []uint16{hwdef.BG0_SOURCE_MODE, hwdef.BG1_SOURCE_MODE, hwdef.BG2_SOURCE_MODE, hwdef.BG3_SOURCE_MODE}
 		jumpToEnd := make([]int, 0, 4)
 		for i, addr := range bgSourceModeAddrs {
 			cg.builder.AddInstruction(rom.EncodeMOV(1, 6, 0))
@@ -4335,7 +4812,7 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 			cg.builder.AddImmediate(addr)
 			cg.builder.AddInstruction(rom.EncodeMOV(0, 5, 1))
 			cg.builder.AddInstruction(rom.EncodeMOV(1, 6, 0))
-			cg.builder.AddImmediate(0x01)
+			cg.builder.AddImmediate(0x07)
 			cg.builder.AddInstruction(rom.EncodeAND(0, 5, 6))
 			cg.builder.AddInstruction(rom.EncodeMOV(3, 4, 5))
 
@@ -4362,7 +4839,7 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 		if len(args) != 2 {
 			return fmt.Errorf("%s requires 2 arguments (layer, channel)", name)
 		}
-		bgTransformBindAddrs := []uint16{0x806C, 0x806D, 0x806E, 0x806F}
+		bgTransformBindAddrs := []uint16{hwdef.BG0_TRANSFORM_BIND, hwdef.BG1_TRANSFORM_BIND, hwdef.BG2_TRANSFORM_BIND, hwdef.BG3_TRANSFORM_BIND}
 		jumpToEnd := make([]int, 0, 4)
 		for i, addr := range bgTransformBindAddrs {
 			cg.builder.AddInstruction(rom.EncodeMOV(1, 6, 0))
@@ -4403,7 +4880,7 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 		if len(args) != 1 {
 			return fmt.Errorf("%s requires 1 argument (layer)", name)
 		}
-		matrixControlAddrs := []uint16{0x8018, 0x802B, 0x8038, 0x8045}
+		matrixControlAddrs := []uint16{hwdef.MATRIX_CONTROL, hwdef.BG1_MATRIX_CONTROL, hwdef.BG2_MATRIX_CONTROL, hwdef.BG3_MATRIX_CONTROL}
 		jumpToEnd := make([]int, 0, 4)
 		for i, addr := range matrixControlAddrs {
 			cg.builder.AddInstruction(rom.EncodeMOV(1, 6, 0))
@@ -4449,7 +4926,7 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 		if len(args) != 5 {
 			return fmt.Errorf("matrix.set_matrix requires 5 arguments (layer, a, b, c, d)")
 		}
-		matrixAAddrs := []uint16{0x8019, 0x802C, 0x8039, 0x8046}
+		matrixAAddrs := []uint16{hwdef.MATRIX_A_L, hwdef.BG1_MATRIX_A_L, hwdef.BG2_MATRIX_A_L, hwdef.BG3_MATRIX_A_L}
 		jumpToEnd := make([]int, 0, 4)
 		for i, addr := range matrixAAddrs {
 			cg.builder.AddInstruction(rom.EncodeMOV(1, 6, 0))
@@ -4508,7 +4985,7 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 		if len(args) != 3 {
 			return fmt.Errorf("matrix.set_center requires 3 arguments (layer, x, y)")
 		}
-		centerAddrs := []uint16{0x8027, 0x8034, 0x8041, 0x804E}
+		centerAddrs := []uint16{hwdef.MATRIX_CENTER_X_L, hwdef.BG1_MATRIX_CENTER_X_L, hwdef.BG2_MATRIX_CENTER_X_L, hwdef.BG3_MATRIX_CENTER_X_L}
 		jumpToEnd := make([]int, 0, 4)
 		for i, addr := range centerAddrs {
 			cg.builder.AddInstruction(rom.EncodeMOV(1, 6, 0))
@@ -4565,7 +5042,7 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 		if len(args) != 1 {
 			return fmt.Errorf("matrix.identity requires 1 argument (layer)")
 		}
-		matrixAAddrs := []uint16{0x8019, 0x802C, 0x8039, 0x8046}
+		matrixAAddrs := []uint16{hwdef.MATRIX_A_L, hwdef.BG1_MATRIX_A_L, hwdef.BG2_MATRIX_A_L, hwdef.BG3_MATRIX_A_L}
 		jumpToEnd := make([]int, 0, 4)
 		for i, addr := range matrixAAddrs {
 			cg.builder.AddInstruction(rom.EncodeMOV(1, 6, 0))
@@ -4621,7 +5098,7 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 		if len(args) != 5 {
 			return fmt.Errorf("matrix.set_flags requires 5 arguments (layer, mirror_h, mirror_v, outside_mode, direct_color)")
 		}
-		matrixControlAddrs := []uint16{0x8018, 0x802B, 0x8038, 0x8045}
+		matrixControlAddrs := []uint16{hwdef.MATRIX_CONTROL, hwdef.BG1_MATRIX_CONTROL, hwdef.BG2_MATRIX_CONTROL, hwdef.BG3_MATRIX_CONTROL}
 		jumpToEnd := make([]int, 0, 4)
 		for i, addr := range matrixControlAddrs {
 			cg.builder.AddInstruction(rom.EncodeMOV(1, 6, 0))
@@ -4699,60 +5176,6 @@ func (cg *CodeGenerator) generateBuiltinCall(name string, args []Expr, destReg u
 	}
 }
 
-// generateRuntimeTileLoadDispatch generates runtime asset-ID dispatch for gfx.load_tiles.
-// Assumes R0=assetID, R1=base tile index.
-func (cg *CodeGenerator) generateRuntimeTileLoadDispatch(destReg uint8) error {
-	// Preserve runtime inputs across case probes.
-	cg.builder.AddInstruction(rom.EncodeMOV(0, 2, 1)) // MOV R2, R1 (base)
-	cg.builder.AddInstruction(rom.EncodeMOV(0, 3, 0)) // MOV R3, R0 (asset id)
-
-	jumpToEnd := make([]int, 0, len(cg.program.Assets))
-
-	for _, asset := range cg.program.Assets {
-		assetID, ok := cg.assetIDs[asset.Name]
-		if !ok {
-			return fmt.Errorf("missing runtime asset ID for %s", asset.Name)
-		}
-
-		// if R3 != assetID -> skip this case
-		cg.builder.AddInstruction(rom.EncodeMOV(1, 4, 0)) // MOV R4, #assetID
-		cg.builder.AddImmediate(assetID)
-		cg.builder.AddInstruction(rom.EncodeCMP(0, 3, 4)) // CMP R3, R4
-		cg.builder.AddInstruction(rom.EncodeBNE())
-		skipOffsetPos := cg.builder.GetCodeLength()
-		cg.builder.AddImmediate(0) // patch after case body
-
-		// Matched case: restore base to R1 and inline-load the chosen asset.
-		cg.builder.AddInstruction(rom.EncodeMOV(0, 1, 2)) // MOV R1, R2
-		if err := cg.generateInlineTileLoadFromBaseReg(asset, 1, destReg); err != nil {
-			return err
-		}
-
-		// Jump to common end after handling this match.
-		cg.builder.AddInstruction(rom.EncodeJMP())
-		jumpPos := cg.builder.GetCodeLength()
-		cg.builder.AddImmediate(0)
-		jumpToEnd = append(jumpToEnd, jumpPos)
-
-		nextCasePC := uint16(cg.builder.GetCodeLength() * 2)
-		currentPC := uint16(skipOffsetPos * 2)
-		offset := rom.CalculateBranchOffset(currentPC, nextCasePC)
-		cg.builder.SetImmediateAt(skipOffsetPos, uint16(offset))
-	}
-
-	// Unknown runtime ID: leave VRAM unchanged and return base.
-	cg.builder.AddInstruction(rom.EncodeMOV(0, destReg, 2)) // MOV R{destReg}, R2
-
-	endPC := uint16(cg.builder.GetCodeLength() * 2)
-	for _, jumpPos := range jumpToEnd {
-		currentPC := uint16(jumpPos * 2)
-		offset := rom.CalculateBranchOffset(currentPC, endPC)
-		cg.builder.SetImmediateAt(jumpPos, uint16(offset))
-	}
-
-	return nil
-}
-
 func (cg *CodeGenerator) generateRuntimeTilemapLoadDispatch(destReg uint8) error {
 	// Runtime asset-ID dispatch for bg.load_tilemap(asset, layer).
 	cg.builder.AddInstruction(rom.EncodeMOV(0, 2, 1)) // R2 = layer (preserve)
@@ -4919,6 +5342,9 @@ func (cg *CodeGenerator) generateInlineTileLoad(asset *AssetDecl, baseExpr Expr,
 	if asset.Type != "tiles8" && asset.Type != "tiles16" && asset.Type != "sprite" && asset.Type != "tileset" {
 		return fmt.Errorf("gfx.load_tiles requires tile asset type, got %s", asset.Type)
 	}
+	if asset.Compression == "lz" {
+		return fmt.Errorf("gfx.load_tiles: asset %s is lz compressed; load it with gfx.load_tiles_compressed", asset.Name)
+	}
 	// Generate base tile index (second argument)
 	if err := cg.generateExpr(baseExpr, 1); err != nil {
 		return err
@@ -4926,6 +5352,24 @@ func (cg *CodeGenerator) generateInlineTileLoad(asset *AssetDecl, baseExpr Expr,
 	return cg.generateInlineTileLoadFromBaseReg(asset, 1, destReg)
 }
 
+// tileLoadPayload is what gfx.load_tiles writes for a tile asset's
+// normalized bytes, and the shift from tile index to VRAM address. tiles8
+// and tiles16 are a single tile (extra bytes are ignored); sprite/tileset
+// payloads may hold several contiguous tiles and are written whole, with a
+// 16x16 stride when the payload is exactly one 128-byte tile.
+func tileLoadPayload(assetType string, data []byte) ([]byte, uint16) {
+	switch {
+	case assetType == "tiles8":
+		return data[:min(len(data), 32)], 5
+	case assetType == "tiles16":
+		return data[:min(len(data), 128)], 7
+	case assetType == "tileset" && len(data) == 128:
+		return data, 7
+	default:
+		return data, 5
+	}
+}
+
 func (cg *CodeGenerator) generateInlineTileLoadFromBaseReg(asset *AssetDecl, baseReg uint8, destReg uint8) error {
 	// Calculate VRAM address from the base tile index.
 	cg.builder.AddInstruction(rom.EncodeMOV(0, 2, baseReg)) // MOV R2, R{baseReg} (save base)
@@ -4935,24 +5379,16 @@ func (cg *CodeGenerator) generateInlineTileLoadFromBaseReg(asset *AssetDecl, bas
 	if err != nil {
 		return err
 	}
-	bytesPayload := len(dataBytes)
-
-	use16x16Stride := asset.Type == "tiles16" || (asset.Type == "tileset" && bytesPayload == 128)
-	if use16x16Stride {
-		// 16x16 tile: base * 128 = base << 7 (PPU reads sprite tile at index*128)
-		cg.builder.AddInstruction(rom.EncodeMOV(1, 4, 0)) // MOV R4, #7
-		cg.builder.AddImmediate(7)
-		cg.builder.AddInstruction(rom.EncodeSHL(0, 3, 4))
-	} else {
-		// 8x8 tile: base * 32 = base << 5
-		cg.builder.AddInstruction(rom.EncodeMOV(1, 4, 0)) // MOV R4, #5
-		cg.builder.AddImmediate(5)
-		cg.builder.AddInstruction(rom.EncodeSHL(0, 3, 4))
-	}
+	payload, shift := tileLoadPayload(asset.Type, dataBytes)
+	// base * 32 for an 8x8 tile, base * 128 for 16x16 (the PPU reads a
+	// sprite tile at index*128).
+	cg.builder.AddInstruction(rom.EncodeMOV(1, 4, 0)) // MOV R4, #shift
+	cg.builder.AddImmediate(shift)
+	cg.builder.AddInstruction(rom.EncodeSHL(0, 3, 4))
 
 	// Set VRAM address low/high registers.
 	cg.builder.AddInstruction(rom.EncodeMOV(1, 4, 0)) // MOV R4, #0x800E
-	cg.builder.AddImmediate(0x800E)
+	cg.builder.AddImmediate(hwdef.VRAM_ADDR_L)
 	cg.builder.AddInstruction(rom.EncodeMOV(0, 5, 3))
 	cg.builder.AddInstruction(rom.EncodeMOV(1, 6, 0)) // MOV R6, #0xFF
 	cg.builder.AddImmediate(0xFF)
@@ -4960,7 +5396,7 @@ func (cg *CodeGenerator) generateInlineTileLoadFromBaseReg(asset *AssetDecl, bas
 	cg.builder.AddInstruction(rom.EncodeMOV(3, 4, 5))
 
 	cg.builder.AddInstruction(rom.EncodeMOV(1, 4, 0)) // MOV R4, #0x800F
-	cg.builder.AddImmediate(0x800F)
+	cg.builder.AddImmediate(hwdef.VRAM_ADDR_H)
 	cg.builder.AddInstruction(rom.EncodeMOV(0, 5, 3))
 	cg.builder.AddInstruction(rom.EncodeMOV(1, 6, 0)) // MOV R6, #8
 	cg.builder.AddImmediate(8)
@@ -4968,28 +5404,9 @@ func (cg *CodeGenerator) generateInlineTileLoadFromBaseReg(asset *AssetDecl, bas
 	cg.builder.AddInstruction(rom.EncodeMOV(3, 4, 5))
 
 	cg.builder.AddInstruction(rom.EncodeMOV(1, 4, 0)) // MOV R4, #0x8010 (VRAM_DATA)
-	cg.builder.AddImmediate(0x8010)
+	cg.builder.AddImmediate(hwdef.VRAM_DATA)
 
-	bytesToWrite := bytesPayload
-	switch asset.Type {
-	case "tiles8":
-		// tiles8 maps to a single 8x8 tile payload.
-		if bytesToWrite > 32 {
-			bytesToWrite = 32
-		}
-	case "tiles16":
-		// tiles16 maps to a single 16x16 tile payload.
-		if bytesToWrite > 128 {
-			bytesToWrite = 128
-		}
-	default:
-		// sprite/tileset payloads may represent multiple contiguous tiles.
-		// Write the full normalized payload so tools can emit larger data blocks.
-	}
-	for i, value := range dataBytes {
-		if i >= bytesToWrite {
-			break
-		}
+	for _, value := range payload {
 		cg.builder.AddInstruction(rom.EncodeMOV(1, 5, 0)) // MOV R5, #value
 		cg.builder.AddImmediate(uint16(value))
 		cg.builder.AddInstruction(rom.EncodeMOV(3, 4, 5))
@@ -5028,7 +5445,7 @@ func (cg *CodeGenerator) generateInlineMatrixPlaneTileLoadFromRegs(asset *AssetD
 
 	// Set pattern address registers.
 	cg.builder.AddInstruction(rom.EncodeMOV(1, 4, 0))
-	cg.builder.AddImmediate(0x8085)
+	cg.builder.AddImmediate(hwdef.MATRIX_PLANE_PATTERN_ADDR_L)
 	cg.builder.AddInstruction(rom.EncodeMOV(0, 5, 3))
 	cg.builder.AddInstruction(rom.EncodeMOV(1, 6, 0))
 	cg.builder.AddImmediate(0x00FF)
@@ -5036,7 +5453,7 @@ func (cg *CodeGenerator) generateInlineMatrixPlaneTileLoadFromRegs(asset *AssetD
 	cg.builder.AddInstruction(rom.EncodeMOV(3, 4, 5))
 
 	cg.builder.AddInstruction(rom.EncodeMOV(1, 4, 0))
-	cg.builder.AddImmediate(0x8086)
+	cg.builder.AddImmediate(hwdef.MATRIX_PLANE_PATTERN_ADDR_H)
 	cg.builder.AddInstruction(rom.EncodeMOV(0, 5, 3))
 	cg.builder.AddInstruction(rom.EncodeMOV(1, 6, 0))
 	cg.builder.AddImmediate(8)
@@ -5044,7 +5461,7 @@ func (cg *CodeGenerator) generateInlineMatrixPlaneTileLoadFromRegs(asset *AssetD
 	cg.builder.AddInstruction(rom.EncodeMOV(3, 4, 5))
 
 	cg.builder.AddInstruction(rom.EncodeMOV(1, 4, 0))
-	cg.builder.AddImmediate(0x8087)
+	cg.builder.AddImmediate(hwdef.MATRIX_PLANE_PATTERN_DATA)
 
 	bytesToWrite := bytesPayload
 	switch asset.Type {
@@ -5082,19 +5499,23 @@ func (cg *CodeGenerator) generateInlineMatrixPlaneTilemapLoadFromChannelReg(asse
 	cg.emitSelectMatrixPlane(channelReg, 3, 4)
 
 	cg.builder.AddInstruction(rom.EncodeMOV(1, 3, 0))
-	cg.builder.AddImmediate(0x8082)
+	cg.builder.AddImmediate(hwdef.MATRIX_PLANE_ADDR_L)
 	cg.builder.AddInstruction(rom.EncodeMOV(1, 4, 0))
 	cg.builder.AddImmediate(0)
 	cg.builder.AddInstruction(rom.EncodeMOV(3, 3, 4))
 	cg.builder.AddInstruction(rom.EncodeMOV(1, 3, 0))
-	cg.builder.AddImmediate(0x8083)
+	cg.builder.AddImmediate(hwdef.MATRIX_PLANE_ADDR_H)
 	cg.builder.AddInstruction(rom.EncodeMOV(3, 3, 4))
-	cg.builder.AddInstruction(rom.EncodeMOV(1, 3, 0))
-	cg.builder.AddImmediate(0x8084)
-	for _, value := range dataBytes {
-		cg.builder.AddInstruction(rom.EncodeMOV(1, 4, 0))
-		cg.builder.AddImmediate(uint16(value))
-		cg.builder.AddInstruction(rom.EncodeMOV(3, 3, 4))
+	if packed, ok := cg.packedAssets[asset.Name]; ok {
+		cg.emitTilemapRLELoad(packed, hwdef.MATRIX_PLANE_DATA, channelReg)
+	} else {
+		cg.builder.AddInstruction(rom.EncodeMOV(1, 3, 0))
+		cg.builder.AddImmediate(hwdef.MATRIX_PLANE_DATA)
+		for _, value := range dataBytes {
+			cg.builder.AddInstruction(rom.EncodeMOV(1, 4, 0))
+			cg.builder.AddImmediate(uint16(value))
+			cg.builder.AddInstruction(rom.EncodeMOV(3, 3, 4))
+		}
 	}
 
 	cg.builder.AddInstruction(rom.EncodeMOV(0, destReg, channelReg))
@@ -5175,14 +5596,14 @@ func (cg *CodeGenerator) generateMatrixPlaneFillRect() error {
 
 	// Address low/high
 	cg.builder.AddInstruction(rom.EncodeMOV(1, 5, 0))
-	cg.builder.AddImmediate(0x8082)
+	cg.builder.AddImmediate(hwdef.MATRIX_PLANE_ADDR_L)
 	cg.builder.AddInstruction(rom.EncodeMOV(0, 6, 4))
 	cg.builder.AddInstruction(rom.EncodeMOV(1, 7, 0))
 	cg.builder.AddImmediate(0x00FF)
 	cg.builder.AddInstruction(rom.EncodeAND(0, 6, 7))
 	cg.builder.AddInstruction(rom.EncodeMOV(3, 5, 6))
 	cg.builder.AddInstruction(rom.EncodeMOV(1, 5, 0))
-	cg.builder.AddImmediate(0x8083)
+	cg.builder.AddImmediate(hwdef.MATRIX_PLANE_ADDR_H)
 	cg.builder.AddInstruction(rom.EncodeMOV(0, 6, 4))
 	cg.builder.AddInstruction(rom.EncodeMOV(1, 7, 0))
 	cg.builder.AddImmediate(8)
@@ -5191,7 +5612,7 @@ func (cg *CodeGenerator) generateMatrixPlaneFillRect() error {
 
 	// Data writes
 	cg.builder.AddInstruction(rom.EncodeMOV(1, 5, 0))
-	cg.builder.AddImmediate(0x8084)
+	cg.builder.AddImmediate(hwdef.MATRIX_PLANE_DATA)
 	cg.emitLoadStackWord(6, tileAddr)
 	cg.builder.AddInstruction(rom.EncodeMOV(3, 5, 6))
 	cg.emitLoadStackWord(6, attrAddr)
@@ -5266,16 +5687,16 @@ func (cg *CodeGenerator) generateMatrixPlaneClear() error {
 
 	// zero tilemap address
 	cg.builder.AddInstruction(rom.EncodeMOV(1, 5, 0))
-	cg.builder.AddImmediate(0x8082)
+	cg.builder.AddImmediate(hwdef.MATRIX_PLANE_ADDR_L)
 	cg.builder.AddInstruction(rom.EncodeMOV(1, 6, 0))
 	cg.builder.AddImmediate(0)
 	cg.builder.AddInstruction(rom.EncodeMOV(3, 5, 6))
 	cg.builder.AddInstruction(rom.EncodeMOV(1, 5, 0))
-	cg.builder.AddImmediate(0x8083)
+	cg.builder.AddImmediate(hwdef.MATRIX_PLANE_ADDR_H)
 	cg.builder.AddInstruction(rom.EncodeMOV(3, 5, 6))
 
 	cg.builder.AddInstruction(rom.EncodeMOV(1, 5, 0))
-	cg.builder.AddImmediate(0x8084)
+	cg.builder.AddImmediate(hwdef.MATRIX_PLANE_DATA)
 	loopStart := cg.builder.GetCodeLength()
 	cg.builder.AddInstruction(rom.EncodeMOV(1, 6, 0))
 	cg.builder.AddImmediate(0)
@@ -5316,7 +5737,7 @@ func (cg *CodeGenerator) generateInlineTilemapLoadFromLayerReg(asset *AssetDecl,
 	}
 
 	// Resolve the configured tilemap base for the selected layer into R5.
-	bgTilemapAddrs := []uint16{0x8077, 0x8079, 0x807B, 0x807D}
+	bgTilemapAddrs := []uint16{hwdef.BG0_TILEMAP_BASE_L, hwdef.BG1_TILEMAP_BASE_L, hwdef.BG2_TILEMAP_BASE_L, hwdef.BG3_TILEMAP_BASE_L}
 	jumpToEnd := make([]int, 0, 4)
 	for i, addr := range bgTilemapAddrs {
 		cg.builder.AddInstruction(rom.EncodeMOV(1, 6, 0))
@@ -5364,7 +5785,7 @@ func (cg *CodeGenerator) generateInlineTilemapLoadFromLayerReg(asset *AssetDecl,
 
 	// Program VRAM address from resolved base.
 	cg.builder.AddInstruction(rom.EncodeMOV(1, 6, 0))
-	cg.builder.AddImmediate(0x800E)
+	cg.builder.AddImmediate(hwdef.VRAM_ADDR_L)
 	cg.builder.AddInstruction(rom.EncodeMOV(0, 7, 5))
 	cg.builder.AddInstruction(rom.EncodeMOV(1, 4, 0))
 	cg.builder.AddImmediate(0x00FF)
@@ -5372,19 +5793,23 @@ func (cg *CodeGenerator) generateInlineTilemapLoadFromLayerReg(asset *AssetDecl,
 	cg.builder.AddInstruction(rom.EncodeMOV(3, 6, 7))
 
 	cg.builder.AddInstruction(rom.EncodeMOV(1, 6, 0))
-	cg.builder.AddImmediate(0x800F)
+	cg.builder.AddImmediate(hwdef.VRAM_ADDR_H)
 	cg.builder.AddInstruction(rom.EncodeMOV(0, 7, 5))
 	cg.builder.AddInstruction(rom.EncodeMOV(1, 4, 0))
 	cg.builder.AddImmediate(8)
 	cg.builder.AddInstruction(rom.EncodeSHR(0, 7, 4))
 	cg.builder.AddInstruction(rom.EncodeMOV(3, 6, 7))
 
-	cg.builder.AddInstruction(rom.EncodeMOV(1, 6, 0))
-	cg.builder.AddImmediate(0x8010)
-	for _, value := range dataBytes {
-		cg.builder.AddInstruction(rom.EncodeMOV(1, 7, 0))
-		cg.builder.AddImmediate(uint16(value))
-		cg.builder.AddInstruction(rom.EncodeMOV(3, 6, 7))
+	if packed, ok := cg.packedAssets[asset.Name]; ok {
+		cg.emitTilemapRLELoad(packed, hwdef.VRAM_DATA, 5)
+	} else {
+		cg.builder.AddInstruction(rom.EncodeMOV(1, 6, 0))
+		cg.builder.AddImmediate(hwdef.VRAM_DATA)
+		for _, value := range dataBytes {
+			cg.builder.AddInstruction(rom.EncodeMOV(1, 7, 0))
+			cg.builder.AddImmediate(uint16(value))
+			cg.builder.AddInstruction(rom.EncodeMOV(3, 6, 7))
+		}
 	}
 
 	// Return the tilemap base used for the transfer.
@@ -5437,7 +5862,7 @@ func (cg *CodeGenerator) emitStoreStackWord(srcReg uint8, addr uint16) {
 
 func (cg *CodeGenerator) emitSelectMatrixPlane(channelReg, addrReg, tmpReg uint8) {
 	cg.builder.AddInstruction(rom.EncodeMOV(1, addrReg, 0))
-	cg.builder.AddImmediate(0x8080)
+	cg.builder.AddImmediate(hwdef.MATRIX_PLANE_SELECT)
 	cg.builder.AddInstruction(rom.EncodeMOV(0, tmpReg, channelReg))
 	cg.builder.AddInstruction(rom.EncodeMOV(1, 0, 0))
 	cg.builder.AddImmediate(0x03)
@@ -5540,21 +5965,21 @@ func (cg *CodeGenerator) emitComputeRasterScanlineBase(scanlineReg, destReg, scr
 
 func (cg *CodeGenerator) emitWriteVRAM16AtAddrReg(addrReg, valueReg, scratchReg, ioReg uint8) {
 	cg.builder.AddInstruction(rom.EncodeMOV(1, ioReg, 0))
-	cg.builder.AddImmediate(0x800E)
+	cg.builder.AddImmediate(hwdef.VRAM_ADDR_L)
 	cg.builder.AddInstruction(rom.EncodeMOV(0, scratchReg, addrReg))
 	cg.builder.AddInstruction(rom.EncodeAND(1, scratchReg, 0))
 	cg.builder.AddImmediate(0x00FF)
 	cg.builder.AddInstruction(rom.EncodeMOV(3, ioReg, scratchReg))
 
 	cg.builder.AddInstruction(rom.EncodeMOV(1, ioReg, 0))
-	cg.builder.AddImmediate(0x800F)
+	cg.builder.AddImmediate(hwdef.VRAM_ADDR_H)
 	cg.builder.AddInstruction(rom.EncodeMOV(0, scratchReg, addrReg))
 	cg.builder.AddInstruction(rom.EncodeSHR(1, scratchReg, 0))
 	cg.builder.AddImmediate(8)
 	cg.builder.AddInstruction(rom.EncodeMOV(3, ioReg, scratchReg))
 
 	cg.builder.AddInstruction(rom.EncodeMOV(1, ioReg, 0))
-	cg.builder.AddImmediate(0x8010)
+	cg.builder.AddImmediate(hwdef.VRAM_DATA)
 	cg.builder.AddInstruction(rom.EncodeMOV(0, scratchReg, valueReg))
 	cg.builder.AddInstruction(rom.EncodeAND(1, scratchReg, 0))
 	cg.builder.AddImmediate(0x00FF)
@@ -5567,21 +5992,21 @@ func (cg *CodeGenerator) emitWriteVRAM16AtAddrReg(addrReg, valueReg, scratchReg,
 
 func (cg *CodeGenerator) emitWriteVRAM8AtAddrReg(addrReg, valueReg, scratchReg, ioReg uint8) {
 	cg.builder.AddInstruction(rom.EncodeMOV(1, ioReg, 0))
-	cg.builder.AddImmediate(0x800E)
+	cg.builder.AddImmediate(hwdef.VRAM_ADDR_L)
 	cg.builder.AddInstruction(rom.EncodeMOV(0, scratchReg, addrReg))
 	cg.builder.AddInstruction(rom.EncodeAND(1, scratchReg, 0))
 	cg.builder.AddImmediate(0x00FF)
 	cg.builder.AddInstruction(rom.EncodeMOV(3, ioReg, scratchReg))
 
 	cg.builder.AddInstruction(rom.EncodeMOV(1, ioReg, 0))
-	cg.builder.AddImmediate(0x800F)
+	cg.builder.AddImmediate(hwdef.VRAM_ADDR_H)
 	cg.builder.AddInstruction(rom.EncodeMOV(0, scratchReg, addrReg))
 	cg.builder.AddInstruction(rom.EncodeSHR(1, scratchReg, 0))
 	cg.builder.AddImmediate(8)
 	cg.builder.AddInstruction(rom.EncodeMOV(3, ioReg, scratchReg))
 
 	cg.builder.AddInstruction(rom.EncodeMOV(1, ioReg, 0))
-	cg.builder.AddImmediate(0x8010)
+	cg.builder.AddImmediate(hwdef.VRAM_DATA)
 	cg.builder.AddInstruction(rom.EncodeMOV(0, scratchReg, valueReg))
 	cg.builder.AddInstruction(rom.EncodeAND(1, scratchReg, 0))
 	cg.builder.AddImmediate(0x00FF)
@@ -5603,11 +6028,16 @@ func (cg *CodeGenerator) generateUnary(expr *UnaryExpr, destReg uint8) error {
 	}
 	switch expr.Op {
 	case TOKEN_MINUS:
-		// Negate: 0 - value
-		cg.builder.AddInstruction(rom.EncodeMOV(1, 1, 0)) // MOV R1, #0
-		cg.builder.AddImmediate(0)
-		cg.builder.AddInstruction(rom.EncodeSUB(0, 1, destReg)) // SUB R1, R{destReg}
-		cg.builder.AddInstruction(rom.EncodeMOV(0, destReg, 1)) // MOV R{destReg}, R1
+		// Negate in place (~value + 1). This used to compute 0 - value in
+		// R1, which clobbered R1 and, with destReg == 1, negated the zero
+		// it had just loaded: a negative literal passed as the second
+		// argument of a call (the x of oam.write_sprite_data or
+		// sprite.set_pos) arrived as 0, so sprites meant to sit partly
+		// off the left edge were drawn at X = 0 instead.
+		cg.builder.AddInstruction(rom.EncodeXOR(1, destReg, 0)) // XOR R{destReg}, #0xFFFF
+		cg.builder.AddImmediate(0xFFFF)
+		cg.builder.AddInstruction(rom.EncodeADD(1, destReg, 0)) // ADD R{destReg}, #1
+		cg.builder.AddImmediate(1)
 	case TOKEN_NOT:
 		// Logical NOT: compare with 0, set to 1 if zero, 0 otherwise
 		// Compare operand with 0
@@ -5626,11 +6056,9 @@ func (cg *CodeGenerator) generateUnary(expr *UnaryExpr, destReg uint8) error {
 		cg.patchLabel(skipLabel, skipPos)
 		return nil
 	case TOKEN_TILDE:
-		// Bitwise NOT: 0xFFFF - value
-		cg.builder.AddInstruction(rom.EncodeMOV(1, 1, 0)) // MOV R1, #0xFFFF
+		// Bitwise NOT, in place for the same reason as negation above.
+		cg.builder.AddInstruction(rom.EncodeXOR(1, destReg, 0)) // XOR R{destReg}, #0xFFFF
 		cg.builder.AddImmediate(0xFFFF)
-		cg.builder.AddInstruction(rom.EncodeSUB(0, 1, destReg)) // SUB R1, R{destReg}
-		cg.builder.AddInstruction(rom.EncodeMOV(0, destReg, 1)) // MOV R{destReg}, R1
 		return nil
 	case TOKEN_AMPERSAND:
 		// Address-of operator &x
@@ -5992,9 +6420,9 @@ func (cg *CodeGenerator) emitFixmulHelper() {
 func (cg *CodeGenerator) emitYM2608Silence() {
 	writeYM := func(port0Addr, port0Data uint16) {
 		cg.hMovImm(6, port0Addr)
-		cg.storeIOByte(0x9100, 6)
+		cg.storeIOByte(hwdef.FM_ADDR, 6)
 		cg.hMovImm(6, port0Data)
-		cg.storeIOByte(0x9101, 6)
+		cg.storeIOByte(hwdef.FM_DATA, 6)
 	}
 	for _, ch := range []uint16{0x00, 0x01, 0x02, 0x04, 0x05, 0x06} {
 		writeYM(0x28, ch) // key-off (no operator bits set)
@@ -6007,9 +6435,9 @@ func (cg *CodeGenerator) emitYM2608Silence() {
 
 	// ADPCM-B reset/stop, via port 1.
 	cg.hMovImm(6, 0x00)
-	cg.storeIOByte(0x9104, 6)
+	cg.storeIOByte(hwdef.FM_PORT1_ADDR, 6)
 	cg.hMovImm(6, 0x01)
-	cg.storeIOByte(0x9105, 6)
+	cg.storeIOByte(hwdef.FM_PORT1_DATA, 6)
 }
 
 // emitMusicFadeStep emits the music.fade_to per-frame interpolation step,
@@ -6032,7 +6460,7 @@ func (cg *CodeGenerator) emitMusicFadeStep() {
 	// Last step: snap exactly to the target and stop fading (avoids drifting
 	// off-target from repeated integer-division rounding).
 	cg.hLoad16(6, musicFadeTargetSlot)
-	cg.storeIOByte(0x9106, 6)
+	cg.storeIOByte(hwdef.FM_VOLUME, 6)
 	cg.hMovImm(6, 0)
 	cg.hStore16(musicFadeActiveSlot, 6)
 	fadeDonePos := cg.hBranch(rom.EncodeJMP())
@@ -6067,7 +6495,7 @@ func (cg *CodeGenerator) emitMusicFadeStep() {
 	cg.builder.AddInstruction(rom.EncodeADD(0, 4, 5)) // fading up: current += step
 	cg.hPatchToHere(appliedPos)
 
-	cg.storeIOByte(0x9106, 4)
+	cg.storeIOByte(hwdef.FM_VOLUME, 4)
 
 	cg.hLoad16(3, musicFadeFramesLeftSlot)
 	cg.builder.AddInstruction(rom.EncodeSUB(1, 3, 0))
@@ -6112,9 +6540,9 @@ func (cg *CodeGenerator) emitMusicAdvanceHelper() {
 	// Read frame_counter() (same sequence as the frame_counter() builtin:
 	// FRAME_COUNTER_LOW 0x803F, FRAME_COUNTER_HIGH 0x8040) and skip this call
 	// if it's already been processed for the current real frame.
-	cg.hMovImm(4, 0x803F)
+	cg.hMovImm(4, hwdef.FRAME_COUNTER_L)
 	cg.builder.AddInstruction(rom.EncodeMOV(2, 5, 4)) // R5 = low byte
-	cg.hMovImm(4, 0x8040)
+	cg.hMovImm(4, hwdef.FRAME_COUNTER_H)
 	cg.builder.AddInstruction(rom.EncodeMOV(2, 6, 4)) // R6 = high byte
 	cg.hShlImm(6, 8)
 	cg.builder.AddInstruction(rom.EncodeOR(0, 5, 6)) // R5 = current frame_counter()
@@ -6181,17 +6609,17 @@ func (cg *CodeGenerator) emitMusicAdvanceHelper() {
 
 	// Program the bus-side YM burst streamer:
 	// 0x9110/11 count, 0x9112 bank, 0x9113/14 offset, 0x9115 trigger.
-	cg.storeIOByte(0x9110, 4) // count lo
+	cg.storeIOByte(hwdef.YM_BURST_COUNT_L, 4) // count lo
 	cg.builder.AddInstruction(rom.EncodeMOV(0, 2, 5))
 	cg.hShrImm(2, 8)
-	cg.storeIOByte(0x9111, 2) // count hi
-	cg.storeIOByte(0x9112, 6) // source bank
-	cg.storeIOByte(0x9113, 0) // source offset lo (I/O store truncates to the low byte)
+	cg.storeIOByte(hwdef.YM_BURST_COUNT_H, 2)  // count hi
+	cg.storeIOByte(hwdef.YM_BURST_BANK, 6)     // source bank
+	cg.storeIOByte(hwdef.YM_BURST_OFFSET_L, 0) // source offset lo (I/O store truncates to the low byte)
 	cg.builder.AddInstruction(rom.EncodeMOV(0, 1, 0))
 	cg.hShrImm(1, 8)
-	cg.storeIOByte(0x9114, 1) // source offset hi
+	cg.storeIOByte(hwdef.YM_BURST_OFFSET_H, 1) // source offset hi
 	cg.hMovImm(1, 1)
-	cg.storeIOByte(0x9115, 1) // trigger burst
+	cg.storeIOByte(hwdef.YM_BURST_TRIGGER, 1) // trigger burst
 
 	cg.hPatchToHere(writeDonePos)
 
@@ -6269,7 +6697,7 @@ func (cg *CodeGenerator) selectMatrixPlane(channel Expr) error {
 	if err := cg.generateExpr(channel, 0); err != nil {
 		return err
 	}
-	cg.storeIOByte(0x8080, 0)
+	cg.storeIOByte(hwdef.MATRIX_PLANE_SELECT, 0)
 	return nil
 }
 
@@ -6311,7 +6739,7 @@ func (cg *CodeGenerator) emitDrawIntHelper() {
 	cg.hCmpImm(6, 0)
 	posBranch := cg.hBranch(rom.EncodeBEQ())
 	// emit '-'
-	cg.hMovImm(7, 0x8076)
+	cg.hMovImm(7, hwdef.TEXT_CHAR)
 	cg.hMovImm(6, 0x2D)
 	cg.builder.AddInstruction(rom.EncodeMOV(7, 7, 6)) // [0x8076] = '-'
 	// value = -value
@@ -6338,7 +6766,7 @@ func (cg *CodeGenerator) emitDrawIntHelper() {
 		skip := cg.hBranch(rom.EncodeBEQ()) // digit == 0 and not started -> skip
 		cg.hPatchToHere(emit)
 		cg.hMovImm(3, 1) // started = 1
-		cg.hMovImm(7, 0x8076)
+		cg.hMovImm(7, hwdef.TEXT_CHAR)
 		cg.hMovImm(6, 0x30)
 		cg.builder.AddInstruction(rom.EncodeADD(0, 6, 2)) // R6 = '0' + digit
 		cg.builder.AddInstruction(rom.EncodeMOV(7, 7, 6)) // emit
@@ -6346,19 +6774,107 @@ func (cg *CodeGenerator) emitDrawIntHelper() {
 	}
 
 	// Units digit: always emit (R0 is 0..9).
-	cg.hMovImm(7, 0x8076)
+	cg.hMovImm(7, hwdef.TEXT_CHAR)
 	cg.hMovImm(6, 0x30)
 	cg.builder.AddInstruction(rom.EncodeADD(0, 6, 0)) // R6 = '0' + units
 	cg.builder.AddInstruction(rom.EncodeMOV(7, 7, 6)) // emit
 	cg.builder.AddInstruction(rom.EncodeRET())
 }
 
+// setTextPort evaluates the x, y, r, g, b arguments of a text builtin into
+// the text port: X is 16-bit (0x8070/0x8071), the rest single bytes.
+func (cg *CodeGenerator) setTextPort(args []Expr) error {
+	if err := cg.generateExpr(args[0], 0); err != nil {
+		return err
+	}
+	cg.storeIOByte(hwdef.TEXT_X_L, 0)
+	cg.builder.AddInstruction(rom.EncodeMOV(0, 6, 0)) // R6 = X
+	cg.hShrImm(6, 8)                                  // R6 >>= 8
+	cg.storeIOByte(hwdef.TEXT_X_H, 6)
+	for i, addr := range []uint16{hwdef.TEXT_Y, hwdef.TEXT_COLOR_R, hwdef.TEXT_COLOR_G, hwdef.TEXT_COLOR_B} {
+		if err := cg.generateExpr(args[i+1], 0); err != nil {
+			return err
+		}
+		cg.storeIOByte(addr, 0)
+	}
+	return nil
+}
+
 // emitWriteIOByte writes a constant byte to an I/O address.
 func (cg *CodeGenerator) emitWriteIOByte(addr uint16, val uint8) {
 	cg.hMovImm(0, uint16(val))
 	cg.storeIOByte(addr, 0)
 }
 
+// emitLoadBG8Image emits the upload of an 8bpp background image: tiles to
+// VRAM 0x0000, the tilemap to its base, the palette (bg8_indexed) to CGRAM 0,
+// then the layer's tilemap base, source mode and control (enabled, 8×8
+// tiles, priority 0, the image's tilemap size).
+func (cg *CodeGenerator) emitLoadBG8Image(img *ImageAsset, layer int) {
+	tileBytes := img.Tiles * 64
+	cg.emitDMAFromROM(img.Bank, img.Offset, tileBytes, 0x01, 0x0000)
+	mapBank, mapOff := advanceROMAddr(img.Bank, img.Offset, tileBytes)
+	cg.emitDMAFromROM(mapBank, mapOff, len(img.Bitmap)-tileBytes, 0x01, img.TilemapBase)
+	format := uint8(2) // ppu.BGFormatDirect8
+	if img.Kind == "bg8_indexed" {
+		format = 1 // ppu.BGFormatIndexed8
+		palBank, palOff := advanceROMAddr(img.Bank, img.Offset, len(img.Bitmap))
+		cg.emitDMAFromROM(palBank, palOff, len(img.Palette)*2, 0x05, 0x0000)
+	}
+
+	var sizeCode uint8
+	switch img.TilemapSize {
+	case 64:
+		sizeCode = 1
+	case 128:
+		sizeCode = 2
+	}
+	control := []uint16{hwdef.BG0_CONTROL, hwdef.BG1_CONTROL, hwdef.BG2_CONTROL, hwdef.BG3_CONTROL}
+	source := []uint16{hwdef.BG0_SOURCE_MODE, hwdef.BG1_SOURCE_MODE, hwdef.BG2_SOURCE_MODE, hwdef.BG3_SOURCE_MODE}
+	baseL := []uint16{hwdef.BG0_TILEMAP_BASE_L, hwdef.BG1_TILEMAP_BASE_L, hwdef.BG2_TILEMAP_BASE_L, hwdef.BG3_TILEMAP_BASE_L}
+	cg.emitWriteIOByte(baseL[layer], uint8(img.TilemapBase))
+	cg.emitWriteIOByte(baseL[layer]+1, uint8(img.TilemapBase>>8))
+	cg.emitWriteIOByte(source[layer], format<<1)
+	cg.emitWriteIOByte(control[layer], 0x01|sizeCode<<4)
+}
+
+// advanceROMAddr returns the ROM address n bytes after (bank, offset) in the
+// data region, where each bank's data runs from 0x8000 to 0xFFFF.
+func advanceROMAddr(bank uint8, offset uint16, n int) (uint8, uint16) {
+	flat := int(offset) - rom.ROMBankOffsetBase + n
+	return bank + uint8(flat/rom.ROMBankSizeBytes), uint16(rom.ROMBankOffsetBase + flat%rom.ROMBankSizeBytes)
+}
+
+// emitDMAFromROM emits a DMA of length bytes from ROM to dest with the given
+// DMA_CONTROL value, split at ROM bank boundaries, waiting for each transfer
+// to finish.
+func (cg *CodeGenerator) emitDMAFromROM(bank uint8, srcOff uint16, length int, control uint8, dest uint16) {
+	for length > 0 {
+		count := min(length, 0x10000-int(srcOff))
+		cg.emitWriteIOByte(hwdef.DMA_SOURCE_BANK, bank)
+		cg.emitWriteIOByte(hwdef.DMA_SOURCE_OFFSET_L, uint8(srcOff&0xFF))
+		cg.emitWriteIOByte(hwdef.DMA_SOURCE_OFFSET_H, uint8(srcOff>>8))
+		cg.emitWriteIOByte(hwdef.DMA_DEST_ADDR_L, uint8(dest&0xFF))
+		cg.emitWriteIOByte(hwdef.DMA_DEST_ADDR_H, uint8(dest>>8))
+		cg.emitWriteIOByte(hwdef.DMA_LENGTH_L, uint8(count&0xFF))
+		cg.emitWriteIOByte(hwdef.DMA_LENGTH_H, uint8(count>>8))
+		cg.emitWriteIOByte(hwdef.DMA_CONTROL, control)
+		// Wait for DMA idle: loop while [0x8060] != 0.
+		loopStart := cg.builder.GetCodeLength()
+		cg.hMovImm(7, hwdef.DMA_STATUS)
+		cg.builder.AddInstruction(rom.EncodeMOV(2, 2, 7)) // R2 = [0x8060]
+		cg.hCmpImm(2, 0)
+		cg.builder.AddInstruction(rom.EncodeBNE())
+		fromPC := uint16(cg.builder.GetCodeLength() * 2)
+		cg.builder.AddImmediate(uint16(rom.CalculateBranchOffset(fromPC, uint16(loopStart*2))))
+
+		dest += uint16(count)
+		length -= count
+		bank++
+		srcOff = rom.ROMBankOffsetBase
+	}
+}
+
 // emitLoadBitmap emits the full bitmap-plane upload: palette -> CGRAM, plane
 // control for bitmap source mode, and a chunked DMA of the bitmap from ROM.
 func (cg *CodeGenerator) emitLoadBitmap(img *ImageAsset, channel uint8) {
@@ -6368,9 +6884,9 @@ func (cg *CodeGenerator) emitLoadBitmap(img *ImageAsset, channel uint8) {
 			break
 		}
 		idx := img.PaletteBank*16 + uint8(i)
-		cg.emitWriteIOByte(0x8012, idx)
-		cg.emitWriteIOByte(0x8013, uint8(color&0xFF))
-		cg.emitWriteIOByte(0x8013, uint8(color>>8))
+		cg.emitWriteIOByte(hwdef.CGRAM_ADDR, idx)
+		cg.emitWriteIOByte(hwdef.CGRAM_DATA, uint8(color&0xFF))
+		cg.emitWriteIOByte(hwdef.CGRAM_DATA, uint8(color>>8))
 	}
 
 	// Plane control: enable | size<<1 | bitmap(0x08) | paletteBank<<4.
@@ -6383,12 +6899,12 @@ func (cg *CodeGenerator) emitLoadBitmap(img *ImageAsset, channel uint8) {
 	}
 	control := uint8(0x01) | (sizeCode << 1) | 0x08 | (img.PaletteBank << 4)
 
-	cg.emitWriteIOByte(0x8080, channel) // select plane
-	cg.emitWriteIOByte(0x8081, control) // plane control (bitmap mode)
-	cg.emitWriteIOByte(0x808C, 0x00)    // flags: opaque
-	cg.emitWriteIOByte(0x8088, 0x00)    // reset bitmap dest offset
-	cg.emitWriteIOByte(0x8089, 0x00)
-	cg.emitWriteIOByte(0x808A, 0x00)
+	cg.emitWriteIOByte(hwdef.MATRIX_PLANE_SELECT, channel)     // select plane
+	cg.emitWriteIOByte(hwdef.MATRIX_PLANE_CONTROL, control)    // plane control (bitmap mode)
+	cg.emitWriteIOByte(hwdef.MATRIX_PLANE_FLAGS, 0x00)         // flags: opaque
+	cg.emitWriteIOByte(hwdef.MATRIX_PLANE_BITMAP_ADDR_L, 0x00) // reset bitmap dest offset
+	cg.emitWriteIOByte(hwdef.MATRIX_PLANE_BITMAP_ADDR_M, 0x00)
+	cg.emitWriteIOByte(hwdef.MATRIX_PLANE_BITMAP_ADDR_H, 0x00)
 
 	// Chunked DMA from ROM (split at bank boundaries).
 	remaining := len(img.Bitmap)
@@ -6401,21 +6917,21 @@ func (cg *CodeGenerator) emitLoadBitmap(img *ImageAsset, channel uint8) {
 		if count > avail {
 			count = avail
 		}
-		cg.emitWriteIOByte(0x8080, channel)
-		cg.emitWriteIOByte(0x8088, uint8(destOff&0xFF))
-		cg.emitWriteIOByte(0x8089, uint8((destOff>>8)&0xFF))
-		cg.emitWriteIOByte(0x808A, uint8((destOff>>16)&0x07))
-		cg.emitWriteIOByte(0x8061, bank)
-		cg.emitWriteIOByte(0x8062, uint8(srcOff&0xFF))
-		cg.emitWriteIOByte(0x8063, uint8(srcOff>>8))
-		cg.emitWriteIOByte(0x8064, 0x00)
-		cg.emitWriteIOByte(0x8065, 0x00)
-		cg.emitWriteIOByte(0x8066, uint8(count&0xFF))
-		cg.emitWriteIOByte(0x8067, uint8(count>>8))
-		cg.emitWriteIOByte(0x8060, 0x15) // trigger DMA
+		cg.emitWriteIOByte(hwdef.MATRIX_PLANE_SELECT, channel)
+		cg.emitWriteIOByte(hwdef.MATRIX_PLANE_BITMAP_ADDR_L, uint8(destOff&0xFF))
+		cg.emitWriteIOByte(hwdef.MATRIX_PLANE_BITMAP_ADDR_M, uint8((destOff>>8)&0xFF))
+		cg.emitWriteIOByte(hwdef.MATRIX_PLANE_BITMAP_ADDR_H, uint8((destOff>>16)&0x07))
+		cg.emitWriteIOByte(hwdef.DMA_SOURCE_BANK, bank)
+		cg.emitWriteIOByte(hwdef.DMA_SOURCE_OFFSET_L, uint8(srcOff&0xFF))
+		cg.emitWriteIOByte(hwdef.DMA_SOURCE_OFFSET_H, uint8(srcOff>>8))
+		cg.emitWriteIOByte(hwdef.DMA_DEST_ADDR_L, 0x00)
+		cg.emitWriteIOByte(hwdef.DMA_DEST_ADDR_H, 0x00)
+		cg.emitWriteIOByte(hwdef.DMA_LENGTH_L, uint8(count&0xFF))
+		cg.emitWriteIOByte(hwdef.DMA_LENGTH_H, uint8(count>>8))
+		cg.emitWriteIOByte(hwdef.DMA_CONTROL, 0x15) // trigger DMA
 		// Wait for DMA idle: loop while [0x8060] != 0.
 		loopStart := cg.builder.GetCodeLength()
-		cg.hMovImm(7, 0x8060)
+		cg.hMovImm(7, hwdef.DMA_STATUS)
 		cg.builder.AddInstruction(rom.EncodeMOV(2, 2, 7)) // R2 = [0x8060]
 		cg.hCmpImm(2, 0)
 		cg.builder.AddInstruction(rom.EncodeBNE())
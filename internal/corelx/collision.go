@@ -0,0 +1,174 @@
+package corelx
+
+import (
+	"fmt"
+
+	"nitro-core-dx/internal/hwdef"
+	"nitro-core-dx/internal/rom"
+)
+
+// Collision helper builtins: phys.aabb and bg.tile_at.
+//
+// Both compile to a shared runtime routine (__aabb / __tileat) emitted once
+// after user functions, so each call site is only argument setup plus a
+// CALL.
+
+// collisionArgSlot stages helper arguments (up to 8 words) instead of
+// R0-R7: in wide-call mode the CALL itself needs R6/R7, and evaluating one
+// argument at a time through R0 keeps expressions like px + 8 from
+// clobbering arguments already computed (binary expressions use R1/R2 as
+// scratch).
+const collisionArgSlot = runtimeBlockBase + 0x64
+
+// bgControlAddrs are the BG0-BG3 control registers (tile size in bit 1,
+// tilemap size code in bits 4-5).
+var bgControlAddrs = []uint16{hwdef.BG0_CONTROL, hwdef.BG1_CONTROL, hwdef.BG2_CONTROL, hwdef.BG3_CONTROL}
+
+// generateCollisionCall handles phys.aabb(x1, y1, w1, h1, x2, y2, w2, h2)
+// and bg.tile_at(layer, x, y): stages the arguments, then calls the helper.
+func (cg *CodeGenerator) generateCollisionCall(name string, args []Expr, destReg uint8) error {
+	helper := "__aabb"
+	switch name {
+	case "phys.aabb":
+		if len(args) != 8 {
+			return fmt.Errorf("phys.aabb requires 8 arguments (x1, y1, w1, h1, x2, y2, w2, h2)")
+		}
+		cg.needAABB = true
+	case "bg.tile_at":
+		if len(args) != 3 {
+			return fmt.Errorf("bg.tile_at requires 3 arguments (layer, x, y)")
+		}
+		cg.needTileAt = true
+		helper = "__tileat"
+	}
+	for i, arg := range args {
+		if err := cg.generateExpr(arg, 0); err != nil {
+			return err
+		}
+		cg.hStore16(collisionArgSlot+uint16(i*2), 0)
+	}
+	cg.emitHelperCall(helper)
+	if destReg != 0 {
+		cg.builder.AddInstruction(rom.EncodeMOV(0, destReg, 0))
+	}
+	return nil
+}
+
+// loadCollisionArgs loads n staged arguments into R0..R(n-1).
+func (cg *CodeGenerator) loadCollisionArgs(n uint8) {
+	for i := uint8(0); i < n && i < 7; i++ {
+		cg.hLoad16(i, collisionArgSlot+uint16(i)*2)
+	}
+	if n == 8 {
+		cg.hMovImm(7, collisionArgSlot+14)
+		cg.builder.AddInstruction(rom.EncodeMOV(2, 7, 7))
+	}
+}
+
+// emitAABBHelper emits __aabb: R0 = 1 when the staged boxes overlap, else
+// 0. Comparisons are signed so boxes partly off the left/top edge work;
+// boxes that only touch edges, or have zero width/height, do not overlap.
+func (cg *CodeGenerator) emitAABBHelper() {
+	cg.recordFuncAddr("__aabb")
+
+	cg.loadCollisionArgs(8)
+
+	// Per axis: a < b_end && b < a_end, with a_end/b_end built in place of
+	// the sizes.
+	var miss []int
+	for _, axis := range []struct{ a, aLen, b, bLen uint8 }{{0, 2, 4, 6}, {1, 3, 5, 7}} {
+		cg.builder.AddInstruction(rom.EncodeADD(0, axis.aLen, axis.a)) // a_end
+		cg.builder.AddInstruction(rom.EncodeCMP(0, axis.b, axis.aLen))
+		miss = append(miss, cg.hBranch(rom.EncodeBGE()))
+		cg.builder.AddInstruction(rom.EncodeADD(0, axis.bLen, axis.b)) // b_end
+		cg.builder.AddInstruction(rom.EncodeCMP(0, axis.a, axis.bLen))
+		miss = append(miss, cg.hBranch(rom.EncodeBGE()))
+	}
+	cg.hMovImm(0, 1)
+	cg.builder.AddInstruction(rom.EncodeRET())
+	for _, pos := range miss {
+		cg.hPatchToHere(pos)
+	}
+	cg.hMovImm(0, 0)
+	cg.builder.AddInstruction(rom.EncodeRET())
+}
+
+// emitTileAtHelper emits __tileat over the staged layer, x, y (tilemap
+// pixel coordinates); returns the tile index byte of the covering tilemap
+// entry in R0. Coordinates wrap and the entry address is computed the same
+// way the renderer does (ppu/scanline.go), honoring the layer's tile size,
+// tilemap size and tilemap base (0 = default 0x4000).
+func (cg *CodeGenerator) emitTileAtHelper() {
+	cg.recordFuncAddr("__tileat")
+
+	cg.loadCollisionArgs(3)
+	cg.hAndImm(0, 3)
+	// R3 = layer control register value.
+	cg.hMovImm(3, bgControlAddrs[0])
+	for i := 1; i < len(bgControlAddrs); i++ {
+		cg.hCmpImm(0, uint16(i))
+		skip := cg.hBranch(rom.EncodeBNE())
+		cg.hMovImm(3, bgControlAddrs[i])
+		cg.hPatchToHere(skip)
+	}
+	cg.builder.AddInstruction(rom.EncodeMOV(2, 3, 3))
+
+	// R4 = tile shift (3 or 4), R5 = tilemap width shift (5, 6 or 7; the
+	// reserved size code 3 renders as 32 wide).
+	cg.builder.AddInstruction(rom.EncodeMOV(0, 4, 3))
+	cg.hShrImm(4, 1)
+	cg.hAndImm(4, 1)
+	cg.builder.AddInstruction(rom.EncodeADD(1, 4, 0))
+	cg.builder.AddImmediate(3)
+	cg.builder.AddInstruction(rom.EncodeMOV(0, 5, 3))
+	cg.hShrImm(5, 4)
+	cg.hAndImm(5, 3)
+	cg.builder.AddInstruction(rom.EncodeADD(1, 5, 0))
+	cg.builder.AddImmediate(5)
+	cg.hCmpImm(5, 8)
+	sizeOK := cg.hBranch(rom.EncodeBNE())
+	cg.hMovImm(5, 5)
+	cg.hPatchToHere(sizeOK)
+
+	// Wrap: x,y &= (1 << (tileShift + widthShift)) - 1.
+	cg.builder.AddInstruction(rom.EncodeMOV(0, 3, 4))
+	cg.builder.AddInstruction(rom.EncodeADD(0, 3, 5))
+	cg.hMovImm(6, 1)
+	cg.builder.AddInstruction(rom.EncodeSHL(0, 6, 3))
+	cg.builder.AddInstruction(rom.EncodeSUB(1, 6, 0))
+	cg.builder.AddImmediate(1)
+	cg.builder.AddInstruction(rom.EncodeAND(0, 1, 6))
+	cg.builder.AddInstruction(rom.EncodeAND(0, 2, 6))
+
+	// R2 = ((ty << widthShift) + tx) << 1.
+	cg.builder.AddInstruction(rom.EncodeSHR(0, 1, 4))
+	cg.builder.AddInstruction(rom.EncodeSHR(0, 2, 4))
+	cg.builder.AddInstruction(rom.EncodeSHL(0, 2, 5))
+	cg.builder.AddInstruction(rom.EncodeADD(0, 2, 1))
+	cg.hShlImm(2, 1)
+
+	// R3 = tilemap base (BGn_TILEMAP_BASE_L/H at 0x8077 + 2*layer).
+	cg.builder.AddInstruction(rom.EncodeMOV(0, 6, 0))
+	cg.hShlImm(6, 1)
+	cg.builder.AddInstruction(rom.EncodeADD(1, 6, 0))
+	cg.builder.AddImmediate(hwdef.BG0_TILEMAP_BASE_L)
+	cg.builder.AddInstruction(rom.EncodeMOV(2, 3, 6))
+	cg.builder.AddInstruction(rom.EncodeADD(1, 6, 0))
+	cg.builder.AddImmediate(1)
+	cg.builder.AddInstruction(rom.EncodeMOV(2, 4, 6))
+	cg.hShlImm(4, 8)
+	cg.builder.AddInstruction(rom.EncodeOR(0, 3, 4))
+	cg.hCmpImm(3, 0)
+	baseSet := cg.hBranch(rom.EncodeBNE())
+	cg.hMovImm(3, 0x4000)
+	cg.hPatchToHere(baseSet)
+	cg.builder.AddInstruction(rom.EncodeADD(0, 3, 2))
+
+	// Point VRAM_ADDR at the entry and read its tile byte.
+	cg.storeIOByte(hwdef.VRAM_ADDR_L, 3)
+	cg.hShrImm(3, 8)
+	cg.storeIOByte(hwdef.VRAM_ADDR_H, 3)
+	cg.hMovImm(7, hwdef.VRAM_DATA)
+	cg.builder.AddInstruction(rom.EncodeMOV(2, 0, 7))
+	cg.builder.AddInstruction(rom.EncodeRET())
+}
@@ -0,0 +1,62 @@
+package corelx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPhysAABBAndTileAt(t *testing.T) {
+	source := `var out: int[10]
+
+function Start()
+    px := 10
+    w := 8
+    out[0] = phys.aabb(10, 10, 8, 8, 14, 14, 8, 8)
+    out[1] = phys.aabb(10, 10, 8, 8, 18, 10, 8, 8)
+    out[2] = phys.aabb(0 - 4, 0, 8, 8, 0, 0, 4, 4)
+    out[3] = phys.aabb(10, 10, 8, 8, 10, 30, 8, 8)
+    out[4] = phys.aabb(px + 2, 10, w, w, px + w + 1, 12, w - 2, 4)
+
+    bg.set_tile(0, 3, 2, 7, 0)
+    out[5] = bg.tile_at(0, 3 * 8 + 5, 2 * 8 + 1)
+    out[6] = bg.tile_at(0, 4 * 8, 2 * 8)
+    out[7] = bg.tile_at(0, 256 + 29, 256 + 16)
+
+    bg.set_tile_size(1, 16)
+    bg.set_tilemap_base(1, 0x5000)
+    bg.set_tile(1, 2, 1, 9, 0)
+    out[8] = bg.tile_at(1, 2 * 16 + 15, 16)
+    out[9] = bg.tile_at(1, 2 * 8, 8)
+    while true
+        wait_vblank()
+`
+	emu, result := compileAndBoot(t, source, 4000)
+	var base uint16
+	for _, e := range result.MemoryMap {
+		if e.Name == "out" {
+			base = e.Address
+		}
+	}
+	if base == 0 {
+		t.Fatalf("memory map missing 'out': %+v", result.MemoryMap)
+	}
+	want := []uint16{1, 0, 1, 0, 1, 7, 0, 7, 9, 0}
+	for i, w := range want {
+		if got := read16(emu, base+uint16(i*2)); got != w {
+			t.Errorf("out[%d] = %d, want %d", i, got, w)
+		}
+	}
+}
+
+func TestCollisionBuiltinArgCounts(t *testing.T) {
+	for _, tc := range []struct{ call, want string }{
+		{"phys.aabb(1, 2, 3, 4)", "phys.aabb requires 8 arguments"},
+		{"bg.tile_at(0, 1)", "bg.tile_at requires 3 arguments"},
+	} {
+		source := "function Start()\n    x := " + tc.call + "\n"
+		_, err := CompileSource(source, "collide.corelx", nil)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: err = %v, want %q", tc.call, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package corelx
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Target is the machine a build is tuned for (Options.Target,
+// CompileOptions.Target). It changes the generated code, not the language.
+type Target string
+
+const (
+	// TargetDev is the default, for the emulator's lenient VRAM access
+	// (ppu.VRAMAccessOpen/Warn): uploads run where the source puts them.
+	TargetDev Target = "dev"
+	// TargetHardware is for strict hardware (ppu.VRAMAccessStrict), where
+	// VRAM, CGRAM and OAM writes made during active display are dropped.
+	// Every builtin that uploads to them is preceded by a VBlank guard,
+	// which waits for VBlank unless it has already begun.
+	TargetHardware Target = "hardware"
+)
+
+// Targets lists the valid targets, default first.
+var Targets = []Target{TargetDev, TargetHardware}
+
+// ParseTarget parses a target name; "" is TargetDev.
+func ParseTarget(s string) (Target, error) {
+	switch t := Target(strings.ToLower(strings.TrimSpace(s))); t {
+	case "":
+		return TargetDev, nil
+	case TargetDev, TargetHardware:
+		return t, nil
+	}
+	return "", fmt.Errorf("unknown target %q (want %s or %s)", s, TargetDev, TargetHardware)
+}
+
+// Options are the settings of Compile, the compiler entry point the Dev
+// Kit, the corelx CLI and CI runners share. Unlike CompileOptions, the zero
+// value of every field means what it says: Optimize false turns the
+// optimizer off.
+type Options struct {
+	// SourcePath names the source in diagnostics and locates its modules,
+	// corelx.assets.json and image files. Empty compiles the source alone.
+	SourcePath string
+	Target     Target // "" is TargetDev
+	// Optimize runs the peephole pass over single-bank builds.
+	Optimize bool
+	// DebugInfo fills CompileResult.Listing, mapping each source line to
+	// its instructions, for debuggers and the Dev Kit's Listing tab.
+	DebugInfo bool
+	// SkipBootSplash starts Start() on the first frame, without the
+	// default boot splash.
+	SkipBootSplash bool
+	// Defines are the flags set for `#if NAME` sections.
+	Defines []string
+	// Advanced supplies the rest of CompileOptions (output paths, JSON
+	// outputs, ROM layout); the fields above override it. It is not
+	// modified.
+	Advanced *CompileOptions
+}
+
+// CompileOptions returns the CompileOptions o stands for.
+func (o Options) CompileOptions() (CompileOptions, error) {
+	var cfg CompileOptions
+	if o.Advanced != nil {
+		cfg = *o.Advanced
+	}
+	target, err := ParseTarget(string(o.Target))
+	if err != nil {
+		return cfg, err
+	}
+	cfg.Target = target
+	cfg.NoPeephole = !o.Optimize
+	cfg.EmitListing = o.DebugInfo || cfg.EmitListing
+	cfg.SkipBootSplash = o.SkipBootSplash
+	cfg.Defines = o.Defines
+	return cfg, nil
+}
+
+// Compile compiles CoreLX source to a ROM with opts.
+func Compile(source string, opts Options) (*CompileResult, error) {
+	cfg, err := opts.CompileOptions()
+	if err != nil {
+		return nil, err
+	}
+	return CompileSource(source, opts.SourcePath, &cfg)
+}
+
+// CompileProjectWith is Compile for a project on disk: a .ncdx container,
+// a project folder or a main .corelx file (see CompileProject).
+// opts.SourcePath is ignored.
+func CompileProjectWith(path string, opts Options) (*CompileResult, error) {
+	cfg, err := opts.CompileOptions()
+	if err != nil {
+		return nil, err
+	}
+	return CompileProject(path, &cfg)
+}
@@ -0,0 +1,98 @@
+package corelx
+
+import (
+	"bytes"
+	"testing"
+
+	"nitro-core-dx/internal/emulator"
+	"nitro-core-dx/internal/ppu"
+)
+
+// lateUploadProgram uploads a palette color well after each VBlank starts,
+// so on the dev target the write lands during active display.
+const lateUploadProgram = `var i: int = 0
+
+function Start()
+    while true
+        wait_vblank()
+        i = 0
+        while i < 400
+            i = i + 1
+        gfx.set_palette_color(1, 0x7C1F)
+`
+
+// lateWrites runs source for frames frames with strict VRAM access and
+// returns how many VRAM/CGRAM writes came during active display.
+func lateWrites(t *testing.T, source string, target Target, frames int) uint64 {
+	t.Helper()
+	result, err := Compile(source, Options{SourcePath: "late.corelx", Target: target, Optimize: true, SkipBootSplash: true})
+	if err != nil {
+		t.Fatalf("compile (%s): %v", target, err)
+	}
+	emu := emulator.NewEmulator()
+	emu.SetFrameLimit(false)
+	if err := emu.LoadROM(result.ROMBytes); err != nil {
+		t.Fatalf("LoadROM: %v", err)
+	}
+	emu.PPU.VRAMAccess = ppu.VRAMAccessStrict
+	emu.Start()
+	for i := 0; i < frames; i++ {
+		if err := emu.RunFrame(); err != nil {
+			t.Fatalf("RunFrame %d: %v", i, err)
+		}
+	}
+	return emu.PPU.LateVRAMWrites
+}
+
+func TestCompileHardwareTargetGuardsUploads(t *testing.T) {
+	if n := lateWrites(t, lateUploadProgram, TargetDev, 10); n == 0 {
+		t.Fatal("dev target: no late writes; the test program no longer uploads during active display")
+	}
+	if n := lateWrites(t, lateUploadProgram, TargetHardware, 10); n != 0 {
+		t.Errorf("hardware target: %d late writes, want 0", n)
+	}
+
+	dev, err := Compile(lateUploadProgram, Options{Target: TargetDev})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hw, err := Compile(lateUploadProgram, Options{Target: TargetHardware})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(hw.ROMBytes, dev.ROMBytes) {
+		t.Error("hardware and dev ROMs are identical; want the guard's extra code")
+	}
+	if dev.Manifest.Target != "" || hw.Manifest.Target != string(TargetHardware) {
+		t.Errorf("manifest targets = %q, %q; want \"\" and %q", dev.Manifest.Target, hw.Manifest.Target, TargetHardware)
+	}
+}
+
+func TestCompileOptions(t *testing.T) {
+	cfg, err := Options{}.CompileOptions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.NoPeephole || cfg.EmitListing || cfg.Target != TargetDev {
+		t.Errorf("zero Options = %+v; want the optimizer off, no listing, dev target", cfg)
+	}
+	adv := &CompileOptions{OutputPath: "out.rom", NoPeephole: true}
+	cfg, err = Options{Optimize: true, DebugInfo: true, Target: "Hardware", Advanced: adv}.CompileOptions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.NoPeephole || !cfg.EmitListing || cfg.Target != TargetHardware || cfg.OutputPath != "out.rom" {
+		t.Errorf("Options = %+v; want Optimize and DebugInfo over Advanced", cfg)
+	}
+	if !adv.NoPeephole {
+		t.Error("CompileOptions modified Advanced")
+	}
+
+	if _, err := Compile("function Start()\n", Options{Target: "arcade"}); err == nil {
+		t.Error("Compile with an unknown target succeeded")
+	}
+	res, err := CompileSource("function Start()\n", "t.corelx", &CompileOptions{Target: "arcade"})
+	if err == nil || len(res.Diagnostics) == 0 || res.Diagnostics[0].Code != "E_TARGET" {
+		t.Errorf("CompileSource with an unknown target = %v; want an E_TARGET diagnostic", err)
+	}
+}
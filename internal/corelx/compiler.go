@@ -9,6 +9,7 @@ import (
 	"strconv"
 	"strings"
 
+	"nitro-core-dx/internal/link"
 	"nitro-core-dx/internal/rom"
 )
 
@@ -30,11 +31,49 @@ type CompileOptions struct {
 	EmitManifestJSON    bool
 	EmitDiagnosticsJSON bool
 	EmitBundleJSON      bool
+	// EmitObject compiles to a relocatable linker object (CompileResult.Object)
+	// instead of a ROM: `extern function` calls are left for the linker, and
+	// ROMBytes/OutputPath are not produced. ObjectOutputPath, if set, also
+	// writes the object to disk. Objects are limited to single-bank programs.
+	EmitObject       bool
+	ObjectOutputPath string
 	// ForceBootSplash disables the under-`go test` __Boot() auto-bypass (see
 	// injectTestBootBypass) so a test can observe the real default boot
 	// sequence. Has no effect outside `go test` -- production compiles
 	// always see the real behavior regardless of this field.
 	ForceBootSplash bool
+	// SkipBootSplash starts Start() directly instead of showing the default
+	// boot splash first, for quick debug runs. A program's own __Boot() is
+	// unaffected.
+	SkipBootSplash bool
+	// Immediate compiles a throwaway __Boot() for the Dev Kit's immediate
+	// console, which runs it against an already-booted machine: the default
+	// boot sequence (and its globals) is not injected and the entry function
+	// leaves the IRQ/NMI vectors alone.
+	Immediate bool
+	// EmitListing fills CompileResult.Listing with the interleaved source
+	// listing (see formatListing); ListingOutputPath, if set, also writes
+	// it to disk.
+	EmitListing       bool
+	ListingOutputPath string
+	// EmitIR fills CompileResult.IR with a dump of every function in the IR
+	// (see ir.go), for inspection; the ROM is not built from it.
+	// IROutputPath, if set, also writes its text form to disk.
+	EmitIR       bool
+	IROutputPath string
+	// NoPeephole skips the peephole pass (see rom.Peephole) that otherwise
+	// runs over single-bank builds.
+	NoPeephole bool
+	// Defines are the flags set for `#if NAME` sections (see
+	// conditional.go), in the main source and its modules.
+	Defines []string
+	// Target tunes the generated code for a machine (see Target); empty is
+	// TargetDev.
+	Target Target
+	// ValidateHardware runs the built ROM for HardwareCheckFrames under
+	// strict hardware rules and adds a warning for each behavior that only
+	// works because the emulator is lenient (see checkHardwareCompat).
+	ValidateHardware bool
 }
 
 type CompileResult struct {
@@ -48,6 +87,10 @@ type CompileResult struct {
 	BundleJSON       []byte
 	MemoryMap        []MemoryMapEntry
 	MemoryMapText    []byte
+	Listing          []byte           // set when CompileOptions.EmitListing
+	IR               []*IRFunction    // set when CompileOptions.EmitIR
+	Functions        []FunctionSymbol // entry address of each emitted function
+	Object           *link.Object     // set when CompileOptions.EmitObject
 	Diagnostics      []Diagnostic
 }
 
@@ -123,6 +166,7 @@ func CompileSource(source, sourcePath string, opts *CompileOptions) (result *Com
 		if result == nil {
 			return
 		}
+		attachFixes(source, sourcePath, result.Program, result.Diagnostics)
 		normalizeDiagnosticRanges(result.Diagnostics)
 		if cfg.EmitDiagnosticsJSON || cfg.DiagnosticsOutputPath != "" {
 			if b, mErr := json.MarshalIndent(result.Diagnostics, "", "  "); mErr == nil {
@@ -182,8 +226,25 @@ func CompileSource(source, sourcePath string, opts *CompileOptions) (result *Com
 		Diagnostics: make([]Diagnostic, 0),
 	}
 
-	lexer := NewLexer(source)
+	if _, tErr := ParseTarget(string(cfg.Target)); tErr != nil {
+		result.Diagnostics = append(result.Diagnostics, Diagnostic{
+			Category: CategoryValidationError,
+			Code:     "E_TARGET",
+			Message:  tErr.Error(),
+			File:     sourcePath,
+			Severity: SeverityError,
+			Stage:    StageIO,
+		})
+		return result, &DiagnosticsError{Diagnostics: result.Diagnostics}
+	}
+
 	currentStage = StageLexer
+	active, condDiags := applyConditionals(source, sourcePath, cfg.Defines)
+	if len(condDiags) > 0 {
+		result.Diagnostics = append(result.Diagnostics, condDiags...)
+		return result, &DiagnosticsError{Diagnostics: result.Diagnostics}
+	}
+	lexer := NewLexer(active)
 	tokens, err := lexer.Tokenize()
 	if err != nil {
 		result.Diagnostics = append(result.Diagnostics, Diagnostic{
@@ -223,6 +284,23 @@ func CompileSource(source, sourcePath string, opts *CompileOptions) (result *Com
 		return result, &DiagnosticsError{Diagnostics: result.Diagnostics}
 	}
 	result.Program = program
+	// The functions declared in this source, before the boot sequence,
+	// game loop and modules add theirs; only these have lines to list.
+	ownFunctions := make(map[string]bool, len(program.Functions))
+	for _, fn := range program.Functions {
+		ownFunctions[fn.Name] = true
+	}
+	if loopErr := injectGameLoop(program, cfg); loopErr != nil {
+		result.Diagnostics = append(result.Diagnostics, Diagnostic{
+			Category: CategoryBackendCodegenError,
+			Code:     "E_GAME_LOOP",
+			Message:  loopErr.Error(),
+			File:     sourcePath,
+			Severity: SeverityError,
+			Stage:    StageParser,
+		})
+		return result, &DiagnosticsError{Diagnostics: result.Diagnostics}
+	}
 	if bootErr := injectBootEntry(program, cfg); bootErr != nil {
 		result.Diagnostics = append(result.Diagnostics, Diagnostic{
 			Category: CategoryBackendCodegenError,
@@ -252,6 +330,11 @@ func CompileSource(source, sourcePath string, opts *CompileOptions) (result *Com
 		program.Assets = append(program.Assets, externalAssets...)
 		result.AssetSourceFiles = append(result.AssetSourceFiles, externalSources...)
 	}
+	budgets, budgetsPath, budgetDiags := loadProjectBudgets(sourcePath, cfg)
+	result.Diagnostics = append(result.Diagnostics, budgetDiags...)
+	if HasErrors(result.Diagnostics) {
+		return result, &DiagnosticsError{Diagnostics: result.Diagnostics}
+	}
 
 	currentStage = StageSemantic
 	semDiags := AnalyzeWithDiagnostics(program)
@@ -301,6 +384,31 @@ func CompileSource(source, sourcePath string, opts *CompileOptions) (result *Com
 		})
 		return result, &DiagnosticsError{Diagnostics: result.Diagnostics}
 	}
+	sampleAssets, sampleRegion, smpErr := loadSampleAssets(program, sourcePath, singleBankDataStart, len(imageRegion)+len(musicRegion))
+	if smpErr != nil {
+		result.Diagnostics = append(result.Diagnostics, Diagnostic{
+			Category: CategoryAssetParseError,
+			Code:     "E_SAMPLE_ASSET",
+			Message:  smpErr.Error(),
+			File:     sourcePath,
+			Severity: SeverityError,
+			Stage:    StageAsset,
+		})
+		return result, &DiagnosticsError{Diagnostics: result.Diagnostics}
+	}
+	packedAssets, packedRegion, pkErr := loadPackedAssets(program, assets, singleBankDataStart, len(imageRegion)+len(musicRegion)+len(sampleRegion))
+	if pkErr != nil {
+		result.Diagnostics = append(result.Diagnostics, Diagnostic{
+			Category: CategoryAssetParseError,
+			Code:     "E_PACKED_ASSET",
+			Message:  pkErr.Error(),
+			File:     sourcePath,
+			Severity: SeverityError,
+			Stage:    StageAsset,
+		})
+		return result, &DiagnosticsError{Diagnostics: result.Diagnostics}
+	}
+	assetDir, dirRegion := loadAssetDirectory(program, assets, singleBankDataStart, len(imageRegion)+len(musicRegion)+len(sampleRegion)+len(packedRegion))
 
 	// Pass 1: compact, single-bank compile -- today's exact behavior byte
 	// for byte when it fits. Real codegen errors (unrelated to ROM size)
@@ -312,6 +420,13 @@ func CompileSource(source, sourcePath string, opts *CompileOptions) (result *Com
 	generator.SetNormalizedAssets(assets)
 	generator.SetImageAssets(imageAssets)
 	generator.SetMusicAssets(musicAssets)
+	generator.SetSampleAssets(sampleAssets)
+	generator.SetPackedAssets(packedAssets)
+	generator.SetAssetDirectory(assetDir)
+	generator.SetObjectMode(cfg.EmitObject)
+	generator.SetImmediateMode(cfg.Immediate)
+	generator.SetPeephole(!cfg.NoPeephole)
+	generator.SetTarget(cfg.Target)
 	currentStage = StageCodegen
 	genErr := generator.Generate()
 	needsMultiBank := errors.Is(genErr, errCodeOverflowsBank)
@@ -327,12 +442,13 @@ func CompileSource(source, sourcePath string, opts *CompileOptions) (result *Com
 		return result, &DiagnosticsError{Diagnostics: result.Diagnostics}
 	}
 
-	// Image and music bytes share one contiguous ROM data region (images first,
-	// then music — matching the bank/offset cursor used during placement).
-	dataRegion := append(append([]byte{}, imageRegion...), musicRegion...)
+	// Image, music, sample and compressed asset bytes and the asset
+	// directory share one contiguous ROM data region (in that order,
+	// matching the bank/offset cursor used during placement).
+	dataRegion := append(append(append(append(append([]byte{}, imageRegion...), musicRegion...), sampleRegion...), packedRegion...), dirRegion...)
 
 	if !needsMultiBank {
-		needsMultiBank = pass1Builder.GetCodeLength()*2 > int(rom.ROMBankSizeBytes)
+		needsMultiBank = pass1Builder.GetCodeLength()*2 > int(rom.ROMBankSizeBytes) || hasBankOverlays(program)
 	}
 	var (
 		romBytes  []byte
@@ -354,8 +470,56 @@ func CompileSource(source, sourcePath string, opts *CompileOptions) (result *Com
 		}
 	}
 
+	if needsMultiBank && cfg.EmitObject {
+		result.Diagnostics = append(result.Diagnostics, Diagnostic{
+			Category: CategoryLayoutError,
+			Code:     "E_OBJECT_MULTIBANK",
+			Message:  "program needs more than one ROM bank; object output supports single-bank CoreLX units only",
+			File:     sourcePath,
+			Severity: SeverityError,
+			Stage:    StagePack,
+		})
+		return result, &DiagnosticsError{Diagnostics: result.Diagnostics}
+	}
+	if cfg.EmitObject {
+		obj, objErr := generator.Object(sourcePath)
+		if objErr != nil {
+			result.Diagnostics = append(result.Diagnostics, Diagnostic{
+				Category: CategoryBackendCodegenError,
+				Code:     "E_OBJECT_EMIT",
+				Message:  objErr.Error(),
+				File:     sourcePath,
+				Severity: SeverityError,
+				Stage:    StagePack,
+			})
+			return result, &DiagnosticsError{Diagnostics: result.Diagnostics}
+		}
+		if len(dataRegion) > 0 {
+			obj.DataBank = singleBankDataStart
+			obj.Data = dataRegion
+		}
+		result.Object = obj
+	}
+
 	if needsMultiBank {
 		mgen, mRomBytes, mCodeBytes, mErr := compileMultiBank(program, sourcePath, assets, cfg)
+		var overflow *bankOverflowError
+		if errors.As(mErr, &overflow) {
+			result.Diagnostics = append(result.Diagnostics, Diagnostic{
+				Category: CategoryLayoutError,
+				Code:     "E_BANK_OVERFLOW",
+				Message:  overflow.Error(),
+				File:     sourcePath,
+				Line:     overflow.line,
+				Column:   overflow.column,
+				Severity: SeverityError,
+				Stage:    StagePack,
+				Notes: []string{
+					"Move some #[bank(N)] functions to another bank, or drop the attribute to let the compiler place them.",
+				},
+			})
+			return result, &DiagnosticsError{Diagnostics: result.Diagnostics}
+		}
 		if mErr != nil {
 			result.Diagnostics = append(result.Diagnostics, Diagnostic{
 				Category: CategoryLayoutError,
@@ -372,10 +536,17 @@ func CompileSource(source, sourcePath string, opts *CompileOptions) (result *Com
 		codeBytes = mCodeBytes
 	}
 
-	if cfg.EmitROMBytes {
+	if program.Timing != rom.TimingFlag60Hz {
+		if err := rom.SetTiming(romBytes, program.Timing); err != nil {
+			return result, fmt.Errorf("set ROM timing: %w", err)
+		}
+	}
+
+	if cfg.EmitROMBytes && !cfg.EmitObject {
 		result.ROMBytes = romBytes
 	}
 	result.MemoryMap = generator.MemoryMap()
+	result.Functions = generator.FunctionSymbols()
 	result.MemoryMapText = formatMemoryMap(result.MemoryMap)
 	if cfg.OutputPath != "" && len(result.MemoryMap) > 1 {
 		// Listing emitted alongside the ROM for debugger/symbol use
@@ -392,13 +563,75 @@ func CompileSource(source, sourcePath string, opts *CompileOptions) (result *Com
 			})
 		}
 	}
-	result.Manifest = buildManifestFromCompileState(sourcePath, cfg.EntryBank, cfg.EntryOffset, codeBytes, uint32(len(romBytes)), program, assets)
+	if (cfg.EmitListing || cfg.ListingOutputPath != "") && !cfg.EmitObject {
+		listing, lErr := formatListing(sourcePath, source, romBytes, generator, ownFunctions)
+		if lErr == nil && cfg.ListingOutputPath != "" {
+			currentStage = StageIO
+			lErr = os.WriteFile(cfg.ListingOutputPath, listing, 0644)
+		}
+		if lErr != nil {
+			result.Diagnostics = append(result.Diagnostics, Diagnostic{
+				Category: CategoryIOError,
+				Code:     "E_IO_WRITE_LISTING",
+				Message:  lErr.Error(),
+				File:     cfg.ListingOutputPath,
+				Severity: SeverityWarning,
+				Stage:    StageIO,
+			})
+		}
+		result.Listing = listing
+	}
+	if cfg.EmitIR || cfg.IROutputPath != "" {
+		ir, irErr := generator.LowerIR()
+		if irErr != nil {
+			result.Diagnostics = append(result.Diagnostics, Diagnostic{
+				Category: CategoryInternalCompilerError,
+				Code:     "W_IR_DUMP",
+				Message:  fmt.Sprintf("IR dump skipped (the ROM is unaffected): %v", irErr),
+				File:     sourcePath,
+				Severity: SeverityWarning,
+				Stage:    StageIO,
+			})
+		} else if cfg.IROutputPath != "" {
+			currentStage = StageIO
+			if wErr := os.WriteFile(cfg.IROutputPath, formatIR(ir), 0644); wErr != nil {
+				result.Diagnostics = append(result.Diagnostics, Diagnostic{
+					Category: CategoryIOError,
+					Code:     "E_IO_WRITE_IR",
+					Message:  wErr.Error(),
+					File:     cfg.IROutputPath,
+					Severity: SeverityWarning,
+					Stage:    StageIO,
+				})
+			}
+		}
+		result.IR = ir
+	}
+	result.Manifest = buildManifestFromCompileState(sourcePath, cfg.EntryBank, cfg.EntryOffset, codeBytes, uint32(len(romBytes)), uint32(len(dataRegion)), program, assets)
+	if result.Manifest != nil && cfg.Target != "" && cfg.Target != TargetDev {
+		result.Manifest.Target = string(cfg.Target)
+	}
 	if result.Manifest != nil && len(result.AssetSourceFiles) > 0 {
 		result.Manifest.SourceFiles = uniqueStrings(append(result.Manifest.SourceFiles, result.AssetSourceFiles...))
 	}
+	if stats := generator.PeepholeStats(); stats != nil && result.Manifest != nil {
+		result.Manifest.Peephole = &ManifestPeephole{
+			CodeBytesBefore: uint32(stats.BeforeWords * 2),
+			CodeBytesAfter:  uint32(stats.AfterWords * 2),
+			DeadMoves:       stats.DeadMoves,
+			RoundTrips:      stats.RoundTrips,
+			BranchChains:    stats.BranchChains,
+			JumpsToNext:     stats.JumpsToNext,
+		}
+	}
 	currentStage = StagePack
 	packDiags := validatePackBudgets(result.Manifest, cfg, sourcePath)
 	result.Diagnostics = append(result.Diagnostics, packDiags...)
+	result.Diagnostics = append(result.Diagnostics, checkProjectBudgets(result.Manifest, budgets, budgetsPath)...)
+	if cfg.ValidateHardware && !cfg.EmitObject && !HasErrors(result.Diagnostics) {
+		currentStage = StageValidate
+		result.Diagnostics = append(result.Diagnostics, checkHardwareCompat(romBytes, sourcePath, generator, ownFunctions)...)
+	}
 	if HasErrors(result.Diagnostics) {
 		return result, &DiagnosticsError{Diagnostics: result.Diagnostics}
 	}
@@ -432,7 +665,22 @@ func CompileSource(source, sourcePath string, opts *CompileOptions) (result *Com
 		}
 	}
 
-	if cfg.OutputPath != "" {
+	if cfg.ObjectOutputPath != "" && result.Object != nil {
+		currentStage = StageIO
+		if err := link.WriteObject(cfg.ObjectOutputPath, result.Object); err != nil {
+			result.Diagnostics = append(result.Diagnostics, Diagnostic{
+				Category: CategoryIOError,
+				Code:     "E_IO_WRITE_OBJECT",
+				Message:  err.Error(),
+				File:     cfg.ObjectOutputPath,
+				Severity: SeverityError,
+				Stage:    StageIO,
+			})
+			return result, &DiagnosticsError{Diagnostics: result.Diagnostics}
+		}
+	}
+
+	if cfg.OutputPath != "" && !cfg.EmitObject {
 		currentStage = StageIO
 		if err := os.WriteFile(cfg.OutputPath, romBytes, 0644); err != nil {
 			result.Diagnostics = append(result.Diagnostics, Diagnostic{
@@ -457,7 +705,7 @@ func CompileSource(source, sourcePath string, opts *CompileOptions) (result *Com
 // call) and greedily packs them into ROM banks in emission order -- the
 // entry function is always first, so it always lands in bank 1, matching
 // the hardware boot vector requirement. With the resulting code-bank count
-// known, image/music assets are reloaded at their real starting bank
+// known, image/music/sample/compressed assets are reloaded at their real starting bank
 // (immediately above the code banks), and pass 3 does the final emission
 // against a BankedROMBuilder using pass 2's schedule.
 func compileMultiBank(program *Program, sourcePath string, assets []AssetIR, cfg CompileOptions) (*CodeGenerator, []byte, uint32, error) {
@@ -469,24 +717,41 @@ func compileMultiBank(program *Program, sourcePath string, assets []AssetIR, cfg
 	if imgErr != nil {
 		return nil, nil, 0, imgErr
 	}
-	measureMusicAssets, _, musErr := loadMusicAssets(program, sourcePath, provisionalDataStart, len(measureImageRegion))
+	measureMusicAssets, measureMusicRegion, musErr := loadMusicAssets(program, sourcePath, provisionalDataStart, len(measureImageRegion))
 	if musErr != nil {
 		return nil, nil, 0, musErr
 	}
+	measureSampleAssets, measureSampleRegion, smpErr := loadSampleAssets(program, sourcePath, provisionalDataStart, len(measureImageRegion)+len(measureMusicRegion))
+	if smpErr != nil {
+		return nil, nil, 0, smpErr
+	}
+	measurePackedAssets, measurePackedRegion, pkErr := loadPackedAssets(program, assets, provisionalDataStart, len(measureImageRegion)+len(measureMusicRegion)+len(measureSampleRegion))
+	if pkErr != nil {
+		return nil, nil, 0, pkErr
+	}
+	measureAssetDir, _ := loadAssetDirectory(program, assets, provisionalDataStart, len(measureImageRegion)+len(measureMusicRegion)+len(measureSampleRegion)+len(measurePackedRegion))
 
 	measureBuilder := rom.NewROMBuilder()
 	measureGen := NewCodeGenerator(program, measureBuilder)
 	measureGen.SetNormalizedAssets(assets)
 	measureGen.SetImageAssets(measureImageAssets)
 	measureGen.SetMusicAssets(measureMusicAssets)
+	measureGen.SetSampleAssets(measureSampleAssets)
+	measureGen.SetPackedAssets(measurePackedAssets)
+	measureGen.SetAssetDirectory(measureAssetDir)
 	measureGen.EnableWideCallMode()
+	measureGen.SetImmediateMode(cfg.Immediate)
+	measureGen.SetTarget(cfg.Target)
 	if err := measureGen.Generate(); err != nil {
 		return nil, nil, 0, fmt.Errorf("bank measurement pass: %w", err)
 	}
 
-	schedule, codeBankCount := packFunctionBanks(measureGen)
+	schedule, codeBankCount, err := packFunctionBanks(measureGen)
+	if err != nil {
+		return nil, nil, 0, err
+	}
 
-	// Finalize: image/music data starts immediately above the code banks
+	// Finalize: image/music/sample data starts immediately above the code banks
 	// pass 2 determined are needed.
 	dataStartBank := uint8(1 + codeBankCount)
 	finalImageAssets, finalImageRegion, imgErr := loadImageAssets(program, sourcePath, dataStartBank)
@@ -497,7 +762,16 @@ func compileMultiBank(program *Program, sourcePath string, assets []AssetIR, cfg
 	if musErr != nil {
 		return nil, nil, 0, musErr
 	}
-	dataRegion := append(append([]byte{}, finalImageRegion...), finalMusicRegion...)
+	finalSampleAssets, finalSampleRegion, smpErr := loadSampleAssets(program, sourcePath, dataStartBank, len(finalImageRegion)+len(finalMusicRegion))
+	if smpErr != nil {
+		return nil, nil, 0, smpErr
+	}
+	finalPackedAssets, finalPackedRegion, pkErr := loadPackedAssets(program, assets, dataStartBank, len(finalImageRegion)+len(finalMusicRegion)+len(finalSampleRegion))
+	if pkErr != nil {
+		return nil, nil, 0, pkErr
+	}
+	finalAssetDir, finalDirRegion := loadAssetDirectory(program, assets, dataStartBank, len(finalImageRegion)+len(finalMusicRegion)+len(finalSampleRegion)+len(finalPackedRegion))
+	dataRegion := append(append(append(append(append([]byte{}, finalImageRegion...), finalMusicRegion...), finalSampleRegion...), finalPackedRegion...), finalDirRegion...)
 
 	// Pass 3: final emission -- BankedROMBuilder via a bankCursor adapter,
 	// wide-call mode, real bank schedule.
@@ -507,7 +781,12 @@ func compileMultiBank(program *Program, sourcePath string, assets []AssetIR, cfg
 	finalGen.SetNormalizedAssets(assets)
 	finalGen.SetImageAssets(finalImageAssets)
 	finalGen.SetMusicAssets(finalMusicAssets)
+	finalGen.SetSampleAssets(finalSampleAssets)
+	finalGen.SetPackedAssets(finalPackedAssets)
+	finalGen.SetAssetDirectory(finalAssetDir)
 	finalGen.EnableWideCallMode()
+	finalGen.SetImmediateMode(cfg.Immediate)
+	finalGen.SetTarget(cfg.Target)
 	finalGen.SetBankedBuilder(banked, schedule)
 	if err := finalGen.Generate(); err != nil {
 		return nil, nil, 0, fmt.Errorf("final multi-bank emission: %w", err)
@@ -528,19 +807,45 @@ func compileMultiBank(program *Program, sourcePath string, assets []AssetIR, cfg
 	return finalGen, romBytes, uint32(codeWords * 2), nil
 }
 
+// hasBankOverlays reports whether any function is pinned above bank 1 with
+// a #[bank(N)] attribute, which always needs the multi-bank build.
+func hasBankOverlays(program *Program) bool {
+	for _, fn := range program.Functions {
+		if fn.Bank > 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// bankOverflowError reports #[bank(N)]-pinned functions that together
+// exceed one ROM bank. line/column point at the first function pinned there.
+type bankOverflowError struct {
+	bank         uint8
+	words        int
+	funcs        []string
+	line, column int
+}
+
+func (e *bankOverflowError) Error() string {
+	return fmt.Sprintf("bank %d overlay overflows: %s need %d bytes, a bank holds %d",
+		e.bank, strings.Join(e.funcs, ", "), e.words*2, rom.ROMBankSizeBytes)
+}
+
 // packFunctionBanks computes a function/helper -> ROM bank assignment from
 // pass 2's flat measurement build: each entry's size is the word-index
 // delta to the next entry in emission order, so the last entry's size
 // naturally includes the trailing __irqstub bytes patchIRQVector appends
-// after every named function/helper. Functions are packed greedily in
-// emission order into ROMBankSizeWords-sized banks, starting a new bank
-// whenever the next function wouldn't fit whole -- the entry function is
-// always first in emission order, so it always lands in bank 1.
-func packFunctionBanks(measureGen *CodeGenerator) (map[string]uint8, int) {
+// after every named function/helper. Functions pinned with #[bank(N)] are
+// assigned first; the rest are packed greedily in emission order into
+// ROMBankSizeWords-sized banks (around the pinned ones), starting a new
+// bank whenever the next function wouldn't fit whole -- the entry function
+// is always first in emission order, so it always lands in bank 1.
+func packFunctionBanks(measureGen *CodeGenerator) (map[string]uint8, int, error) {
 	order := measureGen.emitOrder
 	schedule := make(map[string]uint8, len(order))
 	if len(order) == 0 {
-		return schedule, 1
+		return schedule, 1, nil
 	}
 	totalWords := measureGen.builder.GetCodeLength()
 	sizes := make([]int, len(order))
@@ -553,17 +858,51 @@ func packFunctionBanks(measureGen *CodeGenerator) (map[string]uint8, int) {
 		sizes[i] = end - start
 	}
 
+	used := make(map[uint8]int)
+	var pinnedBanks []uint8
+	pinnedFuncs := make(map[uint8][]*FunctionDecl)
+	for i, name := range order {
+		fn := measureGen.findFunction(name)
+		if fn == nil || fn.Bank == 0 {
+			continue
+		}
+		if len(pinnedFuncs[fn.Bank]) == 0 {
+			pinnedBanks = append(pinnedBanks, fn.Bank)
+		}
+		pinnedFuncs[fn.Bank] = append(pinnedFuncs[fn.Bank], fn)
+		schedule[name] = fn.Bank
+		used[fn.Bank] += sizes[i]
+	}
+	for _, b := range pinnedBanks {
+		if used[b] <= rom.ROMBankSizeWords {
+			continue
+		}
+		fns := pinnedFuncs[b]
+		names := make([]string, len(fns))
+		for i, fn := range fns {
+			names[i] = fn.Name
+		}
+		return nil, 0, &bankOverflowError{bank: b, words: used[b], funcs: names, line: fns[0].Position.Line, column: fns[0].Position.Column}
+	}
+
 	bank := uint8(1)
-	used := 0
+	highest := bank
 	for i, name := range order {
-		if used > 0 && used+sizes[i] > rom.ROMBankSizeWords {
+		if _, pinned := schedule[name]; pinned {
+			continue
+		}
+		for used[bank] > 0 && used[bank]+sizes[i] > rom.ROMBankSizeWords {
 			bank++
-			used = 0
 		}
 		schedule[name] = bank
-		used += sizes[i]
+		used[bank] += sizes[i]
 	}
-	return schedule, int(bank)
+	for b := range used {
+		if b > highest {
+			highest = b
+		}
+	}
+	return schedule, int(highest), nil
 }
 
 func mergeCompileOptions(dst *CompileOptions, src CompileOptions) {
@@ -614,6 +953,42 @@ func mergeCompileOptions(dst *CompileOptions, src CompileOptions) {
 	if src.ForceBootSplash {
 		dst.ForceBootSplash = true
 	}
+	if src.SkipBootSplash {
+		dst.SkipBootSplash = true
+	}
+	if src.EmitObject {
+		dst.EmitObject = true
+	}
+	if src.ObjectOutputPath != "" {
+		dst.ObjectOutputPath = src.ObjectOutputPath
+	}
+	if src.Immediate {
+		dst.Immediate = true
+	}
+	if src.EmitListing {
+		dst.EmitListing = true
+	}
+	if src.ListingOutputPath != "" {
+		dst.ListingOutputPath = src.ListingOutputPath
+	}
+	if src.EmitIR {
+		dst.EmitIR = true
+	}
+	if src.IROutputPath != "" {
+		dst.IROutputPath = src.IROutputPath
+	}
+	if src.NoPeephole {
+		dst.NoPeephole = true
+	}
+	if src.Defines != nil {
+		dst.Defines = src.Defines
+	}
+	if src.Target != "" {
+		dst.Target = src.Target
+	}
+	if src.ValidateHardware {
+		dst.ValidateHardware = true
+	}
 }
 
 func validatePackBudgets(manifest *BuildManifest, cfg CompileOptions, sourcePath string) []Diagnostic {
@@ -0,0 +1,156 @@
+package corelx
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Conditional sections select source lines by compile-time flag:
+//
+//	#if DEBUG
+//	    debug.print("x", x)
+//	#else
+//	    -- release-only code
+//	#end
+//
+// `#if not NAME` inverts the test, and sections nest. A flag is set with
+// CompileOptions.Defines (the CLI's -D NAME). Directive lines may be
+// indented anywhere; they never open or close a block.
+//
+// The lexer skips directive lines, so tools that lex raw source (the
+// formatter, outline, lints) see every branch. CompileSource runs
+// applyConditionals first, which blanks the directives and every line of
+// an inactive branch; line numbers in diagnostics stay those of the file.
+
+// conditionalDirective returns the directive keyword ("if", "else" or
+// "end") and its argument text when line is a conditional directive.
+func conditionalDirective(line string) (keyword, arg string, ok bool) {
+	text := strings.TrimLeft(line, " \t")
+	if !strings.HasPrefix(text, "#") {
+		return "", "", false
+	}
+	text, _ = splitComment(strings.TrimRight(text[1:], " \t\r"))
+	for _, kw := range []string{"if", "else", "end"} {
+		if !strings.HasPrefix(text, kw) {
+			continue
+		}
+		rest := text[len(kw):]
+		if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+			continue
+		}
+		return kw, strings.TrimSpace(rest), true
+	}
+	return "", "", false
+}
+
+// ValidDefineName reports whether name can be used as a conditional
+// compilation flag: an identifier that is not a keyword.
+func ValidDefineName(name string) bool {
+	if name == "" || new(Lexer).identifierType(name) != TOKEN_IDENTIFIER {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r == '_', r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z':
+		case i > 0 && r >= '0' && r <= '9':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// conditionalFrame is one open #if.
+type conditionalFrame struct {
+	line     int
+	parentOn bool // lines outside this #if are kept
+	cond     bool
+	seenElse bool
+}
+
+func (f conditionalFrame) on() bool {
+	return f.parentOn && f.cond != f.seenElse
+}
+
+// applyConditionals resolves the #if sections of source against defines.
+// Directive lines and the lines of inactive branches become empty lines,
+// so the result has the same line numbering as source.
+func applyConditionals(source, sourcePath string, defines []string) (string, []Diagnostic) {
+	if !strings.Contains(source, "#") {
+		return source, nil
+	}
+	set := make(map[string]bool, len(defines))
+	for _, d := range defines {
+		set[d] = true
+	}
+	var diags []Diagnostic
+	fail := func(line int, format string, args ...interface{}) {
+		diags = append(diags, Diagnostic{
+			Category: CategoryLexError,
+			Code:     "E_LEX_CONDITIONAL",
+			Message:  fmt.Sprintf(format, args...),
+			File:     sourcePath,
+			Line:     line,
+			Column:   1,
+			Severity: SeverityError,
+			Stage:    StageLexer,
+		})
+	}
+
+	lines := strings.Split(source, "\n")
+	var stack []conditionalFrame
+	on := true
+	for i, text := range lines {
+		line := i + 1
+		kw, arg, ok := conditionalDirective(text)
+		if !ok {
+			if !on {
+				lines[i] = ""
+			}
+			continue
+		}
+		lines[i] = ""
+		switch kw {
+		case "if":
+			negate := false
+			if rest, cut := strings.CutPrefix(arg, "not "); cut {
+				negate = true
+				arg = strings.TrimSpace(rest)
+			}
+			if !ValidDefineName(arg) {
+				fail(line, "#if needs a flag name, e.g. `#if DEBUG` or `#if not DEBUG`")
+			}
+			stack = append(stack, conditionalFrame{line: line, parentOn: on, cond: set[arg] != negate})
+		case "else":
+			if arg != "" {
+				fail(line, "unexpected %q after #else", arg)
+			}
+			if len(stack) == 0 {
+				fail(line, "#else without #if")
+				continue
+			}
+			top := &stack[len(stack)-1]
+			if top.seenElse {
+				fail(line, "second #else for the #if on line %d", top.line)
+			}
+			top.seenElse = true
+		case "end":
+			if arg != "" {
+				fail(line, "unexpected %q after #end", arg)
+			}
+			if len(stack) == 0 {
+				fail(line, "#end without #if")
+				continue
+			}
+			stack = stack[:len(stack)-1]
+		}
+		on = true
+		if len(stack) > 0 {
+			on = stack[len(stack)-1].on()
+		}
+	}
+	for _, f := range stack {
+		fail(f.line, "#if without #end")
+	}
+	return strings.Join(lines, "\n"), diags
+}
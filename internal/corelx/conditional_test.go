@@ -0,0 +1,108 @@
+package corelx
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestApplyConditionals(t *testing.T) {
+	src := strings.Join([]string{
+		"a",
+		"#if DEBUG",
+		"b",
+		"    #if not CHEATS -- nested",
+		"c",
+		"    #else",
+		"d",
+		"    #end",
+		"#else",
+		"e",
+		"#end",
+		"f",
+	}, "\n")
+	for _, tc := range []struct {
+		defines []string
+		kept    string
+	}{
+		{nil, "a e f"},
+		{[]string{"DEBUG"}, "a b c f"},
+		{[]string{"DEBUG", "CHEATS"}, "a b d f"},
+		{[]string{"CHEATS"}, "a e f"},
+	} {
+		out, diags := applyConditionals(src, "t.corelx", tc.defines)
+		if len(diags) > 0 {
+			t.Fatalf("%v: %v", tc.defines, diags)
+		}
+		lines := strings.Split(out, "\n")
+		if len(lines) != 12 {
+			t.Fatalf("%v: %d lines, want 12", tc.defines, len(lines))
+		}
+		if got := strings.Join(strings.Fields(out), " "); got != tc.kept {
+			t.Errorf("%v: kept %q, want %q", tc.defines, got, tc.kept)
+		}
+	}
+}
+
+func TestApplyConditionalsErrors(t *testing.T) {
+	for src, want := range map[string]string{
+		"#if DEBUG\nx":              "line 1: #if without #end",
+		"x\n#end":                   "line 2: #end without #if",
+		"#else":                     "line 1: #else without #if",
+		"#if\n#end":                 "line 1: #if needs a flag name",
+		"#if if\n#end":              "line 1: #if needs a flag name",
+		"#if A\n#else\n#else\n#end": "line 3: second #else for the #if on line 1",
+	} {
+		_, diags := applyConditionals(src, "t.corelx", nil)
+		if len(diags) != 1 {
+			t.Errorf("%q: %d diagnostics, want 1", src, len(diags))
+			continue
+		}
+		if got := fmt.Sprintf("line %d: %s", diags[0].Line, diags[0].Message); !strings.HasPrefix(got, want) {
+			t.Errorf("%q: got %q, want %q", src, got, want)
+		}
+	}
+}
+
+func TestCompileConditionalSections(t *testing.T) {
+	src := "function Start()\n" +
+		"    x := 1\n" +
+		"#if DEBUG\n" +
+		"    NotDefined(x)\n" +
+		"#end\n" +
+		"    wait_vblank()\n"
+	if _, err := CompileSource(src, "cond.corelx", nil); err != nil {
+		t.Fatalf("release build: %v", err)
+	}
+	res, err := CompileSource(src, "cond.corelx", &CompileOptions{Defines: []string{"DEBUG"}})
+	if err == nil {
+		t.Fatal("DEBUG build compiled a call to an undefined function")
+	}
+	found := false
+	for _, d := range res.Diagnostics {
+		found = found || d.Line == 4
+	}
+	if !found {
+		t.Errorf("no diagnostic on line 4: %v", res.Diagnostics)
+	}
+}
+
+func TestFormatConditionalDirectives(t *testing.T) {
+	src := "function Start()\n" +
+		"    x := 1\n" +
+		"    #if   not DEBUG  -- release\n" +
+		"    x = 2\n" +
+		"    #end\n"
+	want := "function Start()\n" +
+		"    x := 1\n" +
+		"#if not DEBUG  -- release\n" +
+		"    x = 2\n" +
+		"#end\n"
+	got, err := Format(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("Format:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -41,41 +41,46 @@ func evalConstExpr(expr Expr, consts map[string]int64) (int64, error) {
 		if err != nil {
 			return 0, err
 		}
-		switch e.Op {
-		case TOKEN_PLUS:
-			return l + r, nil
-		case TOKEN_MINUS:
-			return l - r, nil
-		case TOKEN_STAR:
-			return l * r, nil
-		case TOKEN_SLASH:
-			if r == 0 {
-				return 0, fmt.Errorf("division by zero in constant expression")
-			}
-			return l / r, nil
-		case TOKEN_PERCENT:
-			if r == 0 {
-				return 0, fmt.Errorf("modulo by zero in constant expression")
-			}
-			return l % r, nil
-		case TOKEN_AMPERSAND:
-			return l & r, nil
-		case TOKEN_PIPE:
-			return l | r, nil
-		case TOKEN_CARET:
-			return l ^ r, nil
-		case TOKEN_LSHIFT:
-			return l << uint(r&63), nil
-		case TOKEN_RSHIFT:
-			return l >> uint(r&63), nil
-		default:
-			return 0, fmt.Errorf("unsupported binary operator in constant expression")
-		}
+		return applyConstBinary(e.Op, l, r)
 	default:
 		return 0, fmt.Errorf("expression is not a compile-time constant")
 	}
 }
 
+// applyConstBinary folds one integer binary operation.
+func applyConstBinary(op TokenType, l, r int64) (int64, error) {
+	switch op {
+	case TOKEN_PLUS:
+		return l + r, nil
+	case TOKEN_MINUS:
+		return l - r, nil
+	case TOKEN_STAR:
+		return l * r, nil
+	case TOKEN_SLASH:
+		if r == 0 {
+			return 0, fmt.Errorf("division by zero in constant expression")
+		}
+		return l / r, nil
+	case TOKEN_PERCENT:
+		if r == 0 {
+			return 0, fmt.Errorf("modulo by zero in constant expression")
+		}
+		return l % r, nil
+	case TOKEN_AMPERSAND:
+		return l & r, nil
+	case TOKEN_PIPE:
+		return l | r, nil
+	case TOKEN_CARET:
+		return l ^ r, nil
+	case TOKEN_LSHIFT:
+		return l << uint(r&63), nil
+	case TOKEN_RSHIFT:
+		return l >> uint(r&63), nil
+	default:
+		return 0, fmt.Errorf("unsupported binary operator in constant expression")
+	}
+}
+
 // foldProgramConstsTyped evaluates all top-level const declarations in
 // order, tracking which constants are fixed-point. Fixed const arithmetic
 // supports + - between fixed values, fixed*fixed (rescaled), and
@@ -0,0 +1,104 @@
+package corelx
+
+import (
+	"fmt"
+
+	"nitro-core-dx/internal/hwdef"
+	"nitro-core-dx/internal/rom"
+)
+
+// checkDebugPrint validates debug.print(args...): each argument is a string
+// literal, printed as is, or a value, printed in decimal (fixed as 8.8,
+// bool as true/false). Strings are streamed to DEBUG_CHAR like text.draw's,
+// so they must be printable ASCII.
+func (a *SemanticAnalyzer) checkDebugPrint(call *CallExpr) {
+	if call.ArgNames != nil {
+		a.addDiagnostic(call.Position, CategoryValidationError, "E_NAMED_ARG", "debug.print does not take named arguments", "")
+		return
+	}
+	for _, arg := range call.Args {
+		str, ok := arg.(*StringExpr)
+		if !ok {
+			continue
+		}
+		for _, ch := range str.Value {
+			if ch < 0x20 || ch > 0x7E {
+				a.addDiagnostic(str.Position, CategoryValidationError, "E_DEBUG_PRINT", fmt.Sprintf("debug.print: %q must be printable ASCII", str.Value), "")
+				break
+			}
+		}
+	}
+}
+
+// generateDebugPrint emits debug.print over the DEBUG_* console: string
+// characters go to DEBUG_CHAR, each value to DEBUG_VALUE and DEBUG_PRINT
+// with a format matching its type, and a newline ends the line. On real
+// hardware the registers are unmapped, so the writes are harmless.
+// Clobbers R0, R6 and R7.
+func (cg *CodeGenerator) generateDebugPrint(call *CallExpr) error {
+	for _, arg := range call.Args {
+		if str, ok := arg.(*StringExpr); ok {
+			cg.hMovImm(7, hwdef.DEBUG_CHAR)
+			for _, ch := range str.Value {
+				cg.hMovImm(6, uint16(ch))
+				cg.builder.AddInstruction(rom.EncodeMOV(7, 7, 6)) // MOV [R7], R6 (8-bit store)
+			}
+			continue
+		}
+		if err := cg.generateExpr(arg, 0); err != nil {
+			return err
+		}
+		cg.storeIOByte(hwdef.DEBUG_VALUE_L, 0)
+		cg.builder.AddInstruction(rom.EncodeMOV(0, 6, 0)) // R6 = value
+		cg.hShrImm(6, 8)
+		cg.storeIOByte(hwdef.DEBUG_VALUE_H, 6)
+		cg.hMovImm(6, cg.debugPrintFormat(arg))
+		cg.storeIOByte(hwdef.DEBUG_PRINT, 6)
+	}
+	cg.hMovImm(6, '\n')
+	cg.storeIOByte(hwdef.DEBUG_CHAR, 6)
+	return nil
+}
+
+// debugPrintFormat picks the DEBUG_PRINT format for expr: unsigned for
+// values declared u8 or u16, signed decimal for other integers.
+func (cg *CodeGenerator) debugPrintFormat(expr Expr) uint16 {
+	switch cg.typeOf(expr) {
+	case typeFixed:
+		return hwdef.DebugPrintFixed
+	case typeBool:
+		return hwdef.DebugPrintBool
+	}
+	var storage string
+	switch e := expr.(type) {
+	case *IdentExpr:
+		if v, ok := cg.variables[e.Name]; ok {
+			storage = v.VarType
+		} else if g, ok := cg.globals[e.Name]; ok {
+			storage = g.VarType
+		}
+	case *IndexExpr:
+		if ident, ok := e.Array.(*IdentExpr); ok {
+			if g, ok := cg.globals[ident.Name]; ok {
+				storage = g.VarType
+			}
+		}
+	case *MemberExpr:
+		if ident, ok := e.Object.(*IdentExpr); ok {
+			if v, ok := cg.variables[ident.Name]; ok && v.StructType != "" {
+				storage = cg.structFieldTypeName(v.StructType, e.Member)
+			}
+		}
+	case *CallExpr:
+		name := callFuncName(e)
+		if fn := cg.findFunction(name); fn != nil {
+			storage = intTypeName(fn.ReturnType)
+		} else {
+			storage = builtinIntResults[name]
+		}
+	}
+	if storage == "u8" || storage == "u16" {
+		return hwdef.DebugPrintUnsigned
+	}
+	return hwdef.DebugPrintSigned
+}
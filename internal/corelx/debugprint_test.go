@@ -0,0 +1,53 @@
+package corelx
+
+import (
+	"strings"
+	"testing"
+
+	"nitro-core-dx/internal/emulator"
+)
+
+func TestDebugPrintWritesConsole(t *testing.T) {
+	source := `var hp: u16 = 40000
+var speed: fixed = 1.5
+
+function Start()
+    x := -3
+    debug.print("x=", x, " hp=", hp)
+    debug.print("speed ", speed, " ", x < 0)
+    debug.print()
+    while true
+        wait_vblank()
+`
+	result, err := CompileSource(source, "debug.corelx", &CompileOptions{EmitROMBytes: true})
+	if err != nil {
+		t.Fatalf("compile: %v (%+v)", err, result.Diagnostics)
+	}
+	emu := emulator.NewEmulator()
+	if err := emu.LoadROM(result.ROMBytes); err != nil {
+		t.Fatalf("load ROM: %v", err)
+	}
+	for i := 0; i < 2000; i++ {
+		if err := emu.CPU.ExecuteInstruction(); err != nil {
+			t.Fatalf("CPU step %d: %v", i, err)
+		}
+	}
+	want := []string{"x=-3 hp=40000", "speed 1.5 true", ""}
+	if got := emu.DebugConsole.TakeLines(); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("debug lines = %q, want %q", got, want)
+	}
+}
+
+func TestDebugPrintDiagnostics(t *testing.T) {
+	source := "function Start()\n    debug.print(\"caf\u00e9\")\n"
+	result, _ := CompileSource(source, "debug.corelx", nil)
+	var codes []string
+	for _, d := range result.Diagnostics {
+		if d.Severity == SeverityError {
+			codes = append(codes, d.Code)
+		}
+	}
+	if got := strings.Join(codes, ","); got != "E_DEBUG_PRINT" {
+		t.Errorf("diagnostics = %q, want E_DEBUG_PRINT (%+v)", got, result.Diagnostics)
+	}
+}
@@ -0,0 +1,138 @@
+package corelx
+
+import (
+	"fmt"
+
+	"nitro-core-dx/internal/hwdef"
+	"nitro-core-dx/internal/rom"
+)
+
+// Decimal conversion builtins: dec.digits and text.print_number.
+//
+// dec.digits(value, ptr) writes the five decimal digits of value (unsigned,
+// 0-65535), most significant first and one per byte (0-9, not ASCII), to
+// ptr..ptr+4 and returns how many are significant (1-5), so a tile-based
+// score can draw digit tiles from the buffer. text.print_number(x, y, r, g,
+// b, value, width) prints value through the text port, zero-padded to at
+// least width digits, by running __decdigits into decDigitsBuf.
+
+// Runtime block slots (after collisionArgSlot's eight words).
+const (
+	decValueSlot = runtimeBlockBase + 0x74 // __decdigits value
+	decPtrSlot   = runtimeBlockBase + 0x76 // __decdigits destination
+	decDigitsBuf = runtimeBlockBase + 0x78 // text.print_number digits (5 bytes)
+)
+
+// decPlaces are the place values __decdigits divides by, above the units.
+var decPlaces = []uint16{10000, 1000, 100, 10}
+
+func (cg *CodeGenerator) generateDecimalCall(name string, args []Expr, destReg uint8) error {
+	if name == "dec.digits" {
+		if len(args) != 2 {
+			return fmt.Errorf("dec.digits requires 2 arguments (value, ptr)")
+		}
+		for i, slot := range []uint16{decValueSlot, decPtrSlot} {
+			if err := cg.generateExpr(args[i], 0); err != nil {
+				return err
+			}
+			cg.hStore16(slot, 0)
+		}
+		cg.needDecDigits = true
+		cg.emitHelperCall("__decdigits")
+		if destReg != 0 {
+			cg.builder.AddInstruction(rom.EncodeMOV(0, destReg, 0))
+		}
+		return nil
+	}
+
+	// text.print_number
+	if len(args) != 7 {
+		return fmt.Errorf("text.print_number expects (x, y, r, g, b, value, width), got %d args", len(args))
+	}
+	width, err := evalConstExpr(args[6], cg.consts)
+	if err != nil {
+		return fmt.Errorf("text.print_number: width must be a constant: %w", err)
+	}
+	if width < 1 || width > int64(len(decPlaces)+1) {
+		return fmt.Errorf("text.print_number: width must be 1-%d, got %d", len(decPlaces)+1, width)
+	}
+	if err := cg.setTextPort(args[:5]); err != nil {
+		return err
+	}
+	if err := cg.generateExpr(args[5], 0); err != nil {
+		return err
+	}
+	cg.hStore16(decValueSlot, 0)
+	cg.hMovImm(0, decDigitsBuf)
+	cg.hStore16(decPtrSlot, 0)
+	cg.needDecDigits = true
+	cg.emitHelperCall("__decdigits")
+	// Print max(significant digits, width) of them.
+	cg.hCmpImm(0, uint16(width))
+	wide := cg.hBranch(rom.EncodeBGE())
+	cg.hMovImm(0, uint16(width))
+	cg.hPatchToHere(wide)
+	cg.needPrintDigits = true
+	cg.emitHelperCall("__printdigits")
+	return nil
+}
+
+// emitDecDigitsHelper emits __decdigits over the staged value and
+// destination: one DIV per place, storing each digit byte as it goes;
+// returns the significant digit count in R0. Clobbers R0-R7.
+func (cg *CodeGenerator) emitDecDigitsHelper() {
+	cg.recordFuncAddr("__decdigits")
+
+	cg.hLoad16(0, decValueSlot)
+	cg.hLoad16(4, decPtrSlot)
+	cg.hMovImm(3, 0) // significant digits, set at the first nonzero one
+	for i, place := range decPlaces {
+		cg.hMovImm(1, place)
+		cg.builder.AddInstruction(rom.EncodeMOV(0, 2, 0)) // R2 = R0
+		cg.builder.AddInstruction(rom.EncodeDIV(0, 2, 1)) // R2 = digit
+		cg.builder.AddInstruction(rom.EncodeMOV(0, 6, 2))
+		cg.builder.AddInstruction(rom.EncodeMUL(0, 6, 1))
+		cg.builder.AddInstruction(rom.EncodeSUB(0, 0, 6)) // R0 = remainder
+		cg.builder.AddInstruction(rom.EncodeMOV(7, 4, 2)) // [R4] = digit (8-bit)
+		cg.builder.AddInstruction(rom.EncodeADD(1, 4, 0))
+		cg.builder.AddImmediate(1)
+		cg.hCmpImm(3, 0)
+		counted := cg.hBranch(rom.EncodeBNE())
+		cg.hCmpImm(2, 0)
+		zero := cg.hBranch(rom.EncodeBEQ())
+		cg.hMovImm(3, uint16(len(decPlaces)+1-i))
+		cg.hPatchToHere(counted)
+		cg.hPatchToHere(zero)
+	}
+	cg.builder.AddInstruction(rom.EncodeMOV(7, 4, 0)) // units
+	cg.hCmpImm(3, 0)
+	counted := cg.hBranch(rom.EncodeBNE())
+	cg.hMovImm(3, 1) // 0 has one digit
+	cg.hPatchToHere(counted)
+	cg.builder.AddInstruction(rom.EncodeMOV(0, 0, 3))
+	cg.builder.AddInstruction(rom.EncodeRET())
+}
+
+// emitPrintDigitsHelper emits __printdigits: send the last R0 (1-5) digits
+// of decDigitsBuf to the text port as characters. Clobbers R4-R7.
+func (cg *CodeGenerator) emitPrintDigitsHelper() {
+	cg.recordFuncAddr("__printdigits")
+
+	end := decDigitsBuf + uint16(len(decPlaces)+1)
+	cg.hMovImm(4, end)
+	cg.builder.AddInstruction(rom.EncodeSUB(0, 4, 0)) // R4 = first digit
+	cg.hMovImm(5, end)
+	cg.hMovImm(7, hwdef.TEXT_CHAR)
+	loop := cg.builder.GetCodeLength()
+	cg.builder.AddInstruction(rom.EncodeCMP(0, 4, 5))
+	done := cg.hBranch(rom.EncodeBGE())
+	cg.builder.AddInstruction(rom.EncodeMOV(6, 6, 4)) // R6 = digit (8-bit read)
+	cg.builder.AddInstruction(rom.EncodeADD(1, 6, 0))
+	cg.builder.AddImmediate(0x30)
+	cg.builder.AddInstruction(rom.EncodeMOV(7, 7, 6)) // emit '0' + digit
+	cg.builder.AddInstruction(rom.EncodeADD(1, 4, 0))
+	cg.builder.AddImmediate(1)
+	cg.hJumpBack(loop)
+	cg.hPatchToHere(done)
+	cg.builder.AddInstruction(rom.EncodeRET())
+}
@@ -0,0 +1,76 @@
+package corelx
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestDecDigits verifies dec.digits writes the five digit bytes of a value,
+// most significant first, and returns the significant digit count.
+func TestDecDigits(t *testing.T) {
+	source := `var n: int = 0
+var m: int = 0
+function Start()
+    n = dec.digits(1205, 0x7000)
+    m = dec.digits(7, 0x7008)
+    mem.write(0x7010, n)
+    mem.write(0x7011, m)
+    n = 60000
+    n = dec.digits(n + 5535, 0x7018)
+    mem.write(0x7012, n)
+    while true
+        wait_vblank()
+`
+	emu, _ := compileAndBoot(t, source, 3000)
+	wram := emu.Bus.WRAM[:]
+	for _, c := range []struct {
+		at    int
+		want  []uint8
+		count uint8
+	}{
+		{0x7000, []uint8{0, 1, 2, 0, 5}, wram[0x7010]},
+		{0x7008, []uint8{0, 0, 0, 0, 7}, wram[0x7011]},
+		{0x7018, []uint8{6, 5, 5, 3, 5}, wram[0x7012]},
+	} {
+		if got := wram[c.at : c.at+5]; string(got) != string(c.want) {
+			t.Errorf("digits at 0x%04X = %v, want %v", c.at, got, c.want)
+		}
+	}
+	if wram[0x7010] != 4 || wram[0x7011] != 1 || wram[0x7012] != 5 {
+		t.Errorf("digit counts = %d %d %d, want 4 1 5", wram[0x7010], wram[0x7011], wram[0x7012])
+	}
+}
+
+// TestTextPrintNumber verifies text.print_number zero-pads to the width and
+// never drops digits of a wider value.
+func TestTextPrintNumber(t *testing.T) {
+	source := `var score: int = 0
+function Start()
+    score = 40
+    score = score + 2
+    text.print_number(0, 0, 255, 255, 255, score, 5)
+    text.print_number(0, 10, 255, 255, 255, 1230, 2)
+    text.print_number(0, 20, 255, 255, 255, 0, 1)
+    text.print_number(0, 30, 255, 255, 255, 65535, 3)
+    while true
+        wait_vblank()
+`
+	emu, _ := compileAndBoot(t, source, 3000)
+	if got, want := emu.PPU.GetBufferedText(), "00042"+"1230"+"0"+"65535"; got != want {
+		t.Errorf("print_number output: want %q, got %q", want, got)
+	}
+}
+
+func TestTextPrintNumberWidthMustBeConstant(t *testing.T) {
+	source := `var w: int = 3
+function Start()
+    text.print_number(0, 0, 255, 255, 255, 1, w)
+`
+	if _, err := CompileSource(source, "score.corelx", nil); err == nil || !strings.Contains(err.Error(), "width must be a constant") {
+		t.Errorf("variable width: err = %v", err)
+	}
+	source = "function Start()\n    text.print_number(0, 0, 255, 255, 255, 1, 6)\n"
+	if _, err := CompileSource(source, "score.corelx", nil); err == nil || !strings.Contains(err.Error(), "width must be 1-5") {
+		t.Errorf("width 6: err = %v", err)
+	}
+}
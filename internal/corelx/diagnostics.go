@@ -20,6 +20,7 @@ const (
 	StageAsset    DiagnosticStage = "asset"
 	StageCodegen  DiagnosticStage = "codegen"
 	StagePack     DiagnosticStage = "pack"
+	StageValidate DiagnosticStage = "validate"
 )
 
 type DiagnosticCategory string
@@ -53,6 +54,8 @@ type Diagnostic struct {
 	Stage     DiagnosticStage
 	Notes     []string
 	Related   []DiagnosticLocation
+	// Fixes are edits that resolve the diagnostic, best first (see ApplyFix).
+	Fixes []Fix `json:",omitempty"`
 }
 
 type DiagnosticLocation struct {
@@ -80,6 +83,12 @@ func (e *DiagnosticsError) Error() string {
 	if e == nil || len(e.Diagnostics) == 0 {
 		return ""
 	}
+	// Warnings travel with the errors; report the first real error.
+	for _, d := range e.Diagnostics {
+		if d.Severity == SeverityError {
+			return d.Error()
+		}
+	}
 	return e.Diagnostics[0].Error()
 }
 
@@ -5,6 +5,8 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"nitro-core-dx/internal/ppu"
 )
 
 // TestDirectivesParsed verifies `--! corelx <version>` and
@@ -127,3 +129,23 @@ function Start()
 		t.Errorf("expected 'expected at least one module name' error, got: %v", err)
 	}
 }
+
+// TestTimingDirectiveSetsHeaderFlag verifies `--! timing: 50hz` marks the
+// ROM header so the emulator boots it at 50 Hz, and that an unknown rate is
+// a compile error.
+func TestTimingDirectiveSetsHeaderFlag(t *testing.T) {
+	source := `--! timing: 50hz
+function Start()
+    while true
+        wait_vblank()
+`
+	emu, _ := compileAndBoot(t, source, 100)
+	if emu.Timing() != ppu.Timing50Hz || emu.CyclesPerFrame != 153384 {
+		t.Errorf("50hz ROM boots at %v, %d cycles per frame", emu.Timing(), emu.CyclesPerFrame)
+	}
+
+	err := compileExpectError(t, strings.Replace(source, "50hz", "30hz", 1))
+	if !strings.Contains(err.Error(), "unknown timing") {
+		t.Errorf("expected 'unknown timing' error, got: %v", err)
+	}
+}
@@ -0,0 +1,360 @@
+package corelx
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Fix is a machine-applicable correction offered with a diagnostic:
+// applying its edits (see ApplyFix) to the source the diagnostic came from
+// resolves it. Fixes are listed best first.
+type Fix struct {
+	Title string     `json:"title"`
+	Edits []TextEdit `json:"edits"`
+}
+
+// TextEdit replaces the source from Line:Column up to, but not including,
+// EndLine:EndColumn with NewText; an empty range inserts. Lines and columns
+// are 1-based, and columns count bytes.
+type TextEdit struct {
+	Line      int    `json:"line"`
+	Column    int    `json:"column"`
+	EndLine   int    `json:"end_line"`
+	EndColumn int    `json:"end_column"`
+	NewText   string `json:"new_text"`
+}
+
+// ApplyFix returns source with fix's edits applied.
+func ApplyFix(source string, fix Fix) (string, error) {
+	type span struct {
+		start, end int
+		text       string
+	}
+	spans := make([]span, 0, len(fix.Edits))
+	for _, e := range fix.Edits {
+		start, err := sourceOffset(source, e.Line, e.Column)
+		if err != nil {
+			return source, err
+		}
+		end, err := sourceOffset(source, e.EndLine, e.EndColumn)
+		if err != nil {
+			return source, err
+		}
+		if end < start {
+			return source, fmt.Errorf("edit at %d:%d ends before it starts", e.Line, e.Column)
+		}
+		spans = append(spans, span{start, end, e.NewText})
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start > spans[j].start })
+	for i := 1; i < len(spans); i++ {
+		if spans[i].end > spans[i-1].start {
+			return source, fmt.Errorf("fix %q has overlapping edits", fix.Title)
+		}
+	}
+	for _, sp := range spans {
+		source = source[:sp.start] + sp.text + source[sp.end:]
+	}
+	return source, nil
+}
+
+// sourceOffset converts a 1-based line and byte column to an offset into
+// source. The column may be one past the end of the line.
+func sourceOffset(source string, line, column int) (int, error) {
+	if line < 1 || column < 1 {
+		return 0, fmt.Errorf("invalid position %d:%d", line, column)
+	}
+	start := 0
+	for l := 1; l < line; l++ {
+		i := strings.IndexByte(source[start:], '\n')
+		if i < 0 {
+			return 0, fmt.Errorf("line %d is past the end of the source", line)
+		}
+		start += i + 1
+	}
+	lineLen := strings.IndexByte(source[start:], '\n')
+	if lineLen < 0 {
+		lineLen = len(source) - start
+	}
+	if column-1 > lineLen {
+		return 0, fmt.Errorf("column %d is past the end of line %d", column, line)
+	}
+	return start + column - 1, nil
+}
+
+// attachFixes offers fixes for the diagnostics in sourcePath that have a
+// mechanical repair. program may be nil when parsing failed. A fix is only
+// kept when the edited source no longer reports the same diagnostic.
+func attachFixes(source, sourcePath string, program *Program, diags []Diagnostic) {
+	lines := strings.Split(source, "\n")
+	for i := range diags {
+		d := &diags[i]
+		if d.File != sourcePath || d.Line < 1 || d.Line > len(lines) {
+			continue
+		}
+		var fixes []Fix
+		line := lines[d.Line-1]
+		switch d.Code {
+		case "E_PARSE":
+			fixes = closerFixes(d, line)
+		case "E_IDENT_UNDEFINED":
+			fixes = renameFixes(d, line, strings.TrimPrefix(d.Message, "undefined identifier: "), knownNames(program, false))
+		case "E_BUILTIN_UNKNOWN":
+			fixes = renameFixes(d, line, strings.TrimPrefix(d.Message, "unknown function: "), knownNames(program, true))
+		case "E_CALL_ARITY":
+			fixes = argumentFixes(d, line, program)
+		}
+		for _, fix := range fixes {
+			if fixResolves(source, fix, *d) {
+				d.Fixes = append(d.Fixes, fix)
+			}
+		}
+	}
+}
+
+// fixResolves reports whether applying fix leaves source parsing and free
+// of diagnostic d (same code, line and message).
+func fixResolves(source string, fix Fix, d Diagnostic) bool {
+	fixed, err := ApplyFix(source, fix)
+	if err != nil {
+		return false
+	}
+	tokens, err := NewLexer(fixed).Tokenize()
+	if err != nil {
+		return false
+	}
+	program, err := NewParser(tokens).Parse()
+	if err != nil {
+		pd := parseDiagnostic(err, d.File)
+		return d.Stage == StageParser && (pd.Line != d.Line || pd.Message != d.Message)
+	}
+	if d.Stage == StageParser {
+		return true
+	}
+	for _, after := range AnalyzeWithDiagnostics(program) {
+		if after.Code == d.Code && after.Line == d.Line && after.Message == d.Message {
+			return false
+		}
+	}
+	return true
+}
+
+// closerFixes repairs a missing `)` or `]` by closing the bracket at the end
+// of the line. CoreLX blocks end by dedenting, so brackets are the only
+// closers that can go missing.
+func closerFixes(d *Diagnostic, line string) []Fix {
+	var closer string
+	switch d.Message {
+	case "Expected ')'":
+		closer = ")"
+	case "Expected ']'":
+		closer = "]"
+	default:
+		return nil
+	}
+	code, _ := splitComment(line)
+	code = strings.TrimRight(code, " \t")
+	col := len(code) + 1
+	return []Fix{{
+		Title: "Insert missing `" + closer + "`",
+		Edits: []TextEdit{{Line: d.Line, Column: col, EndLine: d.Line, EndColumn: col, NewText: closer}},
+	}}
+}
+
+// knownNames lists the names a misspelling may have meant: the built-ins
+// (dotted or plain) and, for plain names, the program's functions.
+func knownNames(program *Program, dotted bool) []string {
+	var names []string
+	for _, name := range builtinFunctions {
+		if strings.Contains(name, ".") == dotted {
+			names = append(names, name)
+		}
+	}
+	if !dotted && program != nil {
+		for _, fn := range program.Functions {
+			names = append(names, fn.Name)
+		}
+	}
+	return names
+}
+
+// renameFixes offers the known names closest to name, replacing its first
+// occurrence on the diagnostic's line.
+func renameFixes(d *Diagnostic, line, name string, known []string) []Fix {
+	pattern := `\b` + regexp.QuoteMeta(name) + `\b`
+	if ns, member, ok := strings.Cut(name, "."); ok {
+		pattern = `\b` + regexp.QuoteMeta(ns) + `\s*\.\s*` + regexp.QuoteMeta(member) + `\b`
+	}
+	code, _ := splitComment(line)
+	loc := regexp.MustCompile(pattern).FindStringIndex(code)
+	if loc == nil {
+		return nil
+	}
+	var fixes []Fix
+	for _, candidate := range closestNames(name, known) {
+		fixes = append(fixes, Fix{
+			Title: "Rename to `" + candidate + "`",
+			Edits: []TextEdit{{Line: d.Line, Column: loc[0] + 1, EndLine: d.Line, EndColumn: loc[1] + 1, NewText: candidate}},
+		})
+	}
+	return fixes
+}
+
+// closestNames returns the names in known nearest to name by edit
+// distance, if any is close enough to be a plausible typo.
+func closestNames(name string, known []string) []string {
+	limit := len(name) / 3
+	if limit < 2 {
+		limit = 2
+	}
+	best := limit + 1
+	var out []string
+	for _, k := range known {
+		if k == name {
+			continue
+		}
+		d := editDistance(strings.ToLower(name), strings.ToLower(k))
+		switch {
+		case d < best:
+			best, out = d, []string{k}
+		case d == best:
+			out = append(out, k)
+		}
+	}
+	return out
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+var arityRe = regexp.MustCompile(`^(\S+) expects \d+ arguments?, got (\d+)$`)
+
+// argumentFixes completes a call with too few arguments by appending a
+// zero value for each missing parameter.
+func argumentFixes(d *Diagnostic, line string, program *Program) []Fix {
+	m := arityRe.FindStringSubmatch(d.Message)
+	if m == nil || program == nil {
+		return nil
+	}
+	var fn *FunctionDecl
+	for _, f := range program.Functions {
+		if f.Name == m[1] {
+			fn = f
+		}
+	}
+	got := 0
+	fmt.Sscan(m[2], &got)
+	if fn == nil || got >= len(fn.Params) {
+		return nil
+	}
+	var values, names []string
+	for _, p := range fn.Params[got:] {
+		v := zeroValue(p.Type)
+		if v == "" {
+			return nil
+		}
+		values = append(values, v)
+		names = append(names, "`"+p.Name+"`")
+	}
+	closing := callCloseParen(line, fn.Name, got)
+	if closing < 0 {
+		return nil
+	}
+	text := strings.Join(values, ", ")
+	if got > 0 {
+		text = ", " + text
+	}
+	title := "Add missing argument " + names[0]
+	if len(names) > 1 {
+		title = "Add missing arguments " + strings.Join(names, ", ")
+	}
+	col := closing + 1
+	return []Fix{{
+		Title: title,
+		Edits: []TextEdit{{Line: d.Line, Column: col, EndLine: d.Line, EndColumn: col, NewText: text}},
+	}}
+}
+
+// zeroValue is the default argument for a parameter of type t, or "" when
+// there is no literal for it (structs).
+func zeroValue(t TypeExpr) string {
+	name := intTypeName(t)
+	switch {
+	case t == nil || intTypes[name] != (intType{}):
+		return "0"
+	case name == typeFixed:
+		return "0.0"
+	case name == typeBool:
+		return "false"
+	}
+	return ""
+}
+
+// callCloseParen finds, in line, the first call to name with args
+// arguments and returns the byte index of its closing parenthesis, or -1.
+func callCloseParen(line, name string, args int) int {
+	code, _ := splitComment(line)
+	re := regexp.MustCompile(`\b` + regexp.QuoteMeta(name) + `\s*\(`)
+	for _, loc := range re.FindAllStringIndex(code, -1) {
+		if end, n := scanCallArgs(code, loc[1]); end >= 0 && n == args {
+			return end
+		}
+	}
+	return -1
+}
+
+// scanCallArgs scans the argument list starting just after a call's `(`
+// and returns the index of its closing `)` (or -1) and its argument count.
+func scanCallArgs(code string, start int) (end, count int) {
+	depth, inString := 0, false
+	empty := true
+	count = 1
+	for i := start; i < len(code); i++ {
+		c := code[i]
+		if inString {
+			inString = c != '"'
+			continue
+		}
+		switch c {
+		case ' ', '\t':
+			continue
+		case '"':
+			inString = true
+		case '(', '[':
+			depth++
+		case ')', ']':
+			if depth == 0 {
+				if empty {
+					count = 0
+				}
+				return i, count
+			}
+			depth--
+		case ',':
+			if depth == 0 {
+				count++
+			}
+		}
+		empty = false
+	}
+	return -1, 0
+}
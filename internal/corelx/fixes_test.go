@@ -0,0 +1,116 @@
+package corelx
+
+import "testing"
+
+// compileFix compiles source, expects exactly one error diagnostic with the
+// given code, and returns source with its first fix applied.
+func compileFix(t *testing.T, source, code string) (string, Diagnostic) {
+	t.Helper()
+	res, err := CompileSource(source, "fix.corelx", nil)
+	if err == nil {
+		t.Fatalf("compile succeeded, want %s", code)
+	}
+	var diag Diagnostic
+	for _, d := range res.Diagnostics {
+		if d.Severity == SeverityError {
+			diag = d
+			break
+		}
+	}
+	if diag.Code != code {
+		t.Fatalf("first error = %s %q, want %s", diag.Code, diag.Message, code)
+	}
+	if len(diag.Fixes) == 0 {
+		t.Fatalf("%s %q has no fixes", diag.Code, diag.Message)
+	}
+	fixed, err := ApplyFix(source, diag.Fixes[0])
+	if err != nil {
+		t.Fatalf("ApplyFix: %v", err)
+	}
+	return fixed, diag
+}
+
+func TestFixInsertsMissingParen(t *testing.T) {
+	source := "function Start()\n    x := (1 + 2  -- sum\n"
+	fixed, d := compileFix(t, source, "E_PARSE")
+	if want := "function Start()\n    x := (1 + 2)  -- sum\n"; fixed != want {
+		t.Errorf("fixed source = %q, want %q", fixed, want)
+	}
+	if d.Fixes[0].Title != "Insert missing `)`" {
+		t.Errorf("fix title = %q", d.Fixes[0].Title)
+	}
+	if _, err := CompileSource(fixed, "fix.corelx", nil); err != nil {
+		t.Errorf("fixed source does not compile: %v", err)
+	}
+}
+
+func TestFixRenamesToClosestBuiltin(t *testing.T) {
+	cases := []struct{ source, code, want string }{
+		{
+			"function Start()\n    ppu.enable_displai()\n",
+			"E_BUILTIN_UNKNOWN",
+			"function Start()\n    ppu.enable_display()\n",
+		},
+		{
+			"function Start()\n    while true\n        wait_vblnk()\n",
+			"E_IDENT_UNDEFINED",
+			"function Start()\n    while true\n        wait_vblank()\n",
+		},
+	}
+	for _, c := range cases {
+		fixed, _ := compileFix(t, c.source, c.code)
+		if fixed != c.want {
+			t.Errorf("fixed source = %q, want %q", fixed, c.want)
+		}
+	}
+}
+
+func TestFixAddsMissingArguments(t *testing.T) {
+	source := "function move(id: u8, dx: fixed, on: bool)\n    return\n\nfunction Start()\n    move(1)\n"
+	fixed, d := compileFix(t, source, "E_CALL_ARITY")
+	if want := "function move(id: u8, dx: fixed, on: bool)\n    return\n\nfunction Start()\n    move(1, 0.0, false)\n"; fixed != want {
+		t.Errorf("fixed source = %q, want %q", fixed, want)
+	}
+	if d.Fixes[0].Title != "Add missing arguments `dx`, `on`" {
+		t.Errorf("fix title = %q", d.Fixes[0].Title)
+	}
+}
+
+// TestNoFixWithoutPlausibleRepair verifies diagnostics only carry fixes
+// that resolve them: an unrelated name and a surplus argument get none.
+func TestNoFixWithoutPlausibleRepair(t *testing.T) {
+	for _, source := range []string{
+		"function Start()\n    gfx.zzz()\n",
+		"function f(a: int)\n    return\nfunction Start()\n    f(1, 2)\n",
+	} {
+		res, err := CompileSource(source, "fix.corelx", nil)
+		if err == nil {
+			t.Fatalf("%q compiled, want an error", source)
+		}
+		for _, d := range res.Diagnostics {
+			if len(d.Fixes) > 0 {
+				t.Errorf("%s %q offers %v, want no fixes", d.Code, d.Message, d.Fixes)
+			}
+		}
+	}
+}
+
+func TestApplyFixRejectsBadRanges(t *testing.T) {
+	source := "a\nbc\n"
+	for _, e := range []TextEdit{
+		{Line: 3, Column: 2, EndLine: 3, EndColumn: 2},
+		{Line: 2, Column: 4, EndLine: 2, EndColumn: 4},
+		{Line: 2, Column: 3, EndLine: 2, EndColumn: 1},
+	} {
+		if _, err := ApplyFix(source, Fix{Edits: []TextEdit{e}}); err == nil {
+			t.Errorf("ApplyFix accepted edit %+v", e)
+		}
+	}
+	got, err := ApplyFix(source, Fix{Edits: []TextEdit{
+		{Line: 1, Column: 1, EndLine: 1, EndColumn: 2, NewText: "x"},
+		{Line: 2, Column: 3, EndLine: 2, EndColumn: 3, NewText: "d"},
+	}})
+	if err != nil || got != "x\nbcd\n" {
+		t.Errorf("ApplyFix = %q, %v; want %q", got, err, "x\nbcd\n")
+	}
+}
@@ -0,0 +1,279 @@
+package corelx
+
+import (
+	"fmt"
+	"strings"
+)
+
+// formatIndent is one indentation level in formatted source.
+const formatIndent = "    "
+
+// Format returns source in canonical CoreLX layout:
+//   - each block level indented four spaces (tabs and other widths are
+//     re-indented by nesting depth, exactly as the lexer reads them)
+//   - single spaces around binary operators, after commas and colons, none
+//     inside brackets or before a call's parenthesis
+//   - inline asset blocks (`asset X: tiles8 hex`) split into the documented
+//     layout, encoding on its own line and data one level deeper, with data
+//     separated by single spaces
+//   - `#if`/`#else`/`#end` lines in column 0
+//   - no trailing whitespace, at most one blank line in a row, and a final
+//     newline
+//
+// Comments are kept as written. A trailing comment keeps its spacing when the
+// code before it is unchanged, so aligned comments stay aligned; otherwise
+// it is set two spaces after the code. Source that does not parse is returned unchanged with the
+// error, so a formatter never turns a syntax error into a different one.
+func Format(source string) (string, error) {
+	if err := parseForFormat(source); err != nil {
+		return source, err
+	}
+	f := &formatter{widths: []int{0}, assetLevel: -1}
+	for _, line := range strings.Split(strings.ReplaceAll(source, "\r\n", "\n"), "\n") {
+		if err := f.line(line); err != nil {
+			return source, err
+		}
+	}
+	out := strings.TrimRight(f.out.String(), "\n") + "\n"
+	if strings.TrimSpace(out) == "" {
+		out = ""
+	}
+
+	// The layout may change; the program may not.
+	if err := parseForFormat(out); err != nil {
+		return source, fmt.Errorf("formatter produced invalid source: %w", err)
+	}
+	if !sameTokens(source, out) {
+		return source, fmt.Errorf("formatter changed the program's tokens")
+	}
+	return out, nil
+}
+
+func parseForFormat(source string) error {
+	tokens, err := NewLexer(source).Tokenize()
+	if err != nil {
+		return err
+	}
+	for _, tok := range tokens {
+		if tok.Type == TOKEN_ERROR {
+			return fmt.Errorf("line %d: %s", tok.Line, tok.Literal)
+		}
+	}
+	_, err = NewParser(tokens).Parse()
+	return err
+}
+
+// sameTokens reports whether a and b lex to the same tokens, ignoring line
+// structure.
+func sameTokens(a, b string) bool {
+	ta, errA := codeTokens(a)
+	tb, errB := codeTokens(b)
+	if errA != nil || errB != nil || len(ta) != len(tb) {
+		return false
+	}
+	for i := range ta {
+		if ta[i].Type != tb[i].Type || ta[i].Literal != tb[i].Literal {
+			return false
+		}
+	}
+	return true
+}
+
+// codeTokens lexes source and drops the layout tokens.
+func codeTokens(source string) ([]Token, error) {
+	tokens, err := NewLexer(source).Tokenize()
+	if err != nil {
+		return nil, err
+	}
+	out := tokens[:0]
+	for _, tok := range tokens {
+		switch tok.Type {
+		case TOKEN_NEWLINE, TOKEN_INDENT, TOKEN_DEDENT, TOKEN_EOF:
+		default:
+			out = append(out, tok)
+		}
+	}
+	return out, nil
+}
+
+type formatter struct {
+	out strings.Builder
+	// widths is the lexer's indentation stack; its depth is the level.
+	widths []int
+	blank  bool // a blank line is pending
+	// assetLevel is the level of the asset header whose data block is being
+	// formatted, or -1. needEncoding is set until its encoding line is seen.
+	assetLevel   int
+	needEncoding bool
+}
+
+func (f *formatter) line(raw string) error {
+	text := strings.TrimRight(raw, " \t\r")
+	content := strings.TrimLeft(text, " \t")
+	if content == "" {
+		f.blank = f.out.Len() > 0
+		return nil
+	}
+
+	if kw, arg, ok := conditionalDirective(content); ok {
+		// Conditional directives sit in column 0, outside the block
+		// structure, whatever the level of the code they select.
+		directive := strings.Join(append([]string{"#" + kw}, strings.Fields(arg)...), " ")
+		if _, comment := splitComment(content); comment != "" {
+			directive += "  " + comment
+		}
+		f.emit(0, directive)
+		return nil
+	}
+
+	width := len(text) - len(content)
+	if width > f.widths[len(f.widths)-1] {
+		f.widths = append(f.widths, width)
+	}
+	for len(f.widths) > 1 && width < f.widths[len(f.widths)-1] {
+		f.widths = f.widths[:len(f.widths)-1]
+	}
+	level := len(f.widths) - 1
+	if f.assetLevel >= 0 && level <= f.assetLevel {
+		f.assetLevel = -1
+	}
+
+	code, comment := splitComment(content)
+	withComment := func(formatted string) string {
+		switch {
+		case comment == "":
+			return formatted
+		case formatted == code:
+			return strings.TrimSuffix(content, comment) + comment
+		}
+		return formatted + "  " + comment
+	}
+	if strings.HasPrefix(content, "--!") {
+		f.emit(level, "--! "+strings.TrimSpace(content[3:]))
+		return nil
+	}
+	if code == "" {
+		f.emit(f.dataLevel(level), comment)
+		return nil
+	}
+
+	if f.assetLevel >= 0 {
+		if f.needEncoding && isValidAssetEncoding(code) {
+			f.needEncoding = false
+			f.emit(f.assetLevel+1, withComment(code))
+			return nil
+		}
+		f.emit(f.assetLevel+2, withComment(formatAssetData(code)))
+		return nil
+	}
+
+	tokens, err := codeTokens(code)
+	if err != nil {
+		return err
+	}
+	if len(tokens) >= 4 && tokens[0].Type == TOKEN_ASSET && tokens[3].Type == TOKEN_IDENTIFIER &&
+		(len(tokens) == 4 || len(tokens) == 5 && isValidAssetEncoding(tokens[4].Literal)) {
+		// A data block follows: asset Name: type [encoding]
+		f.emit(level, withComment(formatTokens(tokens[:4])))
+		f.assetLevel = level
+		f.needEncoding = len(tokens) == 4
+		if len(tokens) == 5 {
+			f.emit(level+1, tokens[4].Literal)
+		}
+		return nil
+	}
+	f.emit(level, withComment(formatTokens(tokens)))
+	return nil
+}
+
+// dataLevel is where a comment-only line goes: with the asset data when
+// inside an asset block, otherwise at its own level.
+func (f *formatter) dataLevel(level int) int {
+	if f.assetLevel >= 0 {
+		return f.assetLevel + 2
+	}
+	return level
+}
+
+func (f *formatter) emit(level int, text string) {
+	if f.blank {
+		f.out.WriteString("\n")
+		f.blank = false
+	}
+	f.out.WriteString(strings.Repeat(formatIndent, level))
+	f.out.WriteString(text)
+	f.out.WriteString("\n")
+}
+
+// splitComment splits a line at its `--` comment (outside string literals).
+// The comment keeps its `--`.
+func splitComment(line string) (code, comment string) {
+	inString := false
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '"':
+			inString = !inString
+		case !inString && strings.HasPrefix(line[i:], "--"):
+			return strings.TrimRight(line[:i], " \t"), line[i:]
+		}
+	}
+	return line, ""
+}
+
+// formatAssetData separates asset data words with single spaces. Lines
+// holding quoted strings are kept as written.
+func formatAssetData(code string) string {
+	if strings.Contains(code, "\"") {
+		return code
+	}
+	return strings.Join(strings.Fields(code), " ")
+}
+
+// formatTokens renders one line's tokens with canonical spacing.
+func formatTokens(tokens []Token) string {
+	var sb strings.Builder
+	prevUnary := false
+	for i, tok := range tokens {
+		unary := false
+		switch tok.Type {
+		case TOKEN_MINUS, TOKEN_TILDE, TOKEN_AMPERSAND, TOKEN_ADDR_OF:
+			unary = i == 0 || !endsOperand(tokens[i-1].Type)
+		}
+		if i > 0 && spaceBetween(tokens[i-1].Type, tok.Type, prevUnary) {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(tok.Literal)
+		prevUnary = unary
+	}
+	return sb.String()
+}
+
+// endsOperand reports whether a token can end an operand, making a
+// following - or & binary rather than unary.
+func endsOperand(t TokenType) bool {
+	switch t {
+	case TOKEN_IDENTIFIER, TOKEN_NUMBER, TOKEN_STRING, TOKEN_TRUE, TOKEN_FALSE,
+		TOKEN_RPAREN, TOKEN_RBRACKET:
+		return true
+	}
+	return false
+}
+
+func spaceBetween(prev, cur TokenType, prevUnary bool) bool {
+	if prevUnary {
+		return false
+	}
+	switch cur {
+	case TOKEN_RPAREN, TOKEN_RBRACKET, TOKEN_COMMA, TOKEN_DOT, TOKEN_COLON:
+		return false
+	case TOKEN_LPAREN:
+		return !endsOperand(prev) || prev == TOKEN_NUMBER || prev == TOKEN_STRING
+	case TOKEN_LBRACKET:
+		return !endsOperand(prev) && prev != TOKEN_HASH
+	}
+	switch prev {
+	case TOKEN_LPAREN, TOKEN_LBRACKET, TOKEN_DOT, TOKEN_HASH:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,119 @@
+package corelx
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestFormatSpacingAndIndent(t *testing.T) {
+	source := "--!corelx 1.0\n" +
+		"function Start( )\n" +
+		"\tx:=-3\n" +
+		"\tif(x+1)*2>=y[ 4 ]\n" +
+		"\t\tgfx.set_palette( 0,1,-x )   -- aligned\n" +
+		"\n" +
+		"\n" +
+		"\n" +
+		"\twhile true    \n" +
+		"\t\twait_vblank()\n"
+	want := "--! corelx 1.0\n" +
+		"function Start()\n" +
+		"    x := -3\n" +
+		"    if (x + 1) * 2 >= y[4]\n" +
+		"        gfx.set_palette(0, 1, -x)  -- aligned\n" +
+		"\n" +
+		"    while true\n" +
+		"        wait_vblank()\n"
+
+	got, err := Format(source)
+	if err != nil {
+		t.Fatalf("Format: %v", err)
+	}
+	if got != want {
+		t.Errorf("Format =\n%s\nwant\n%s", got, want)
+	}
+	if again, err := Format(got); err != nil || again != got {
+		t.Errorf("Format is not idempotent:\n%s", again)
+	}
+}
+
+// TestFormatKeepsAlignedComments verifies trailing comments after code the
+// formatter leaves alone keep their column.
+func TestFormatKeepsAlignedComments(t *testing.T) {
+	source := "function Start()\n" +
+		"    tc(83)      -- S\n" +
+		"    base := 2   -- WRAM base\n"
+	got, err := Format(source)
+	if err != nil {
+		t.Fatalf("Format: %v", err)
+	}
+	if got != source {
+		t.Errorf("Format =\n%s\nwant unchanged", got)
+	}
+}
+
+func TestFormatSplitsInlineAsset(t *testing.T) {
+	source := "asset Tiles: tiles8 hex\n" +
+		"  00  11 22\n" +
+		"  -- second row\n" +
+		"  33 44\n" +
+		"\n" +
+		"function Start()\n" +
+		"  wait_vblank()\n"
+	want := "asset Tiles: tiles8\n" +
+		"    hex\n" +
+		"        00 11 22\n" +
+		"        -- second row\n" +
+		"        33 44\n" +
+		"\n" +
+		"function Start()\n" +
+		"    wait_vblank()\n"
+
+	got, err := Format(source)
+	if err != nil {
+		t.Fatalf("Format: %v", err)
+	}
+	if got != want {
+		t.Errorf("Format =\n%s\nwant\n%s", got, want)
+	}
+	if again, err := Format(got); err != nil || again != got {
+		t.Errorf("Format is not idempotent:\n%s", again)
+	}
+}
+
+func TestFormatRejectsInvalidSource(t *testing.T) {
+	source := "function Start(\n    wait_vblank()\n"
+	got, err := Format(source)
+	if err == nil {
+		t.Fatal("Format accepted source that does not parse")
+	}
+	if got != source {
+		t.Errorf("Format changed invalid source:\n%s", got)
+	}
+}
+
+// TestFormatRepoPrograms keeps the formatter honest against real programs:
+// they format, and formatting twice changes nothing.
+func TestFormatRepoPrograms(t *testing.T) {
+	for _, path := range []string{
+		"../../test/roms/pellet_game.corelx",
+		"../../test/roms/matrix_plane_showcase.corelx",
+	} {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := Format(string(src))
+		if err != nil {
+			t.Errorf("%s: %v", path, err)
+			continue
+		}
+		if again, _ := Format(got); again != got {
+			t.Errorf("%s: Format is not idempotent", path)
+		}
+		if strings.Contains(got, "\t") {
+			t.Errorf("%s: formatted source still contains tabs", path)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package corelx
+
+import (
+	"fmt"
+	"math"
+
+	"nitro-core-dx/internal/rom"
+)
+
+// 8.8 fixed-point math builtins: fx.mul, fx.div, fx.sin, fx.cos.
+//
+// fx.mul is the same __fixmul routine `fixed * fixed` uses; fx.div is
+// __fixdiv, which also backs `fixed / fixed`. fx.sin/fx.cos take a binary
+// angle (0-255 = one full turn, so 64 = 90 degrees) and look the result up
+// in a quarter-wave table stored in ROM right after the __fxsin routine.
+
+// fxSinTableLen covers angles 0..64 inclusive (one quadrant).
+const fxSinTableLen = 65
+
+// fxSinTable returns round(sin(i * 2pi/256) * 256) for i in 0..64.
+func fxSinTable() []uint16 {
+	table := make([]uint16, fxSinTableLen)
+	for i := range table {
+		table[i] = uint16(math.Round(math.Sin(float64(i)*2*math.Pi/256) * 256))
+	}
+	return table
+}
+
+// generateFxCall emits fx.mul/fx.div/fx.sin/fx.cos. The two-operand forms
+// park the first operand on the CPU stack while the second is evaluated, so
+// operands may themselves be fx calls or arithmetic.
+func (cg *CodeGenerator) generateFxCall(name string, args []Expr, destReg uint8) error {
+	switch name {
+	case "fx.mul", "fx.div":
+		if len(args) != 2 {
+			return fmt.Errorf("%s requires 2 arguments (a, b)", name)
+		}
+		if err := cg.generateExpr(args[0], 0); err != nil {
+			return err
+		}
+		cg.builder.AddInstruction(rom.EncodeMOV(4, 0, 0)) // PUSH R0
+		if err := cg.generateExpr(args[1], 0); err != nil {
+			return err
+		}
+		cg.builder.AddInstruction(rom.EncodeMOV(0, 1, 0)) // R1 = b
+		cg.builder.AddInstruction(rom.EncodeMOV(5, 0, 0)) // POP R0 (a)
+		if name == "fx.mul" {
+			cg.needFixmul = true
+			cg.emitHelperCall("__fixmul")
+		} else {
+			cg.needFixdiv = true
+			cg.emitHelperCall("__fixdiv")
+		}
+	case "fx.sin", "fx.cos":
+		if len(args) != 1 {
+			return fmt.Errorf("%s requires 1 argument (angle 0-255)", name)
+		}
+		if err := cg.generateExpr(args[0], 0); err != nil {
+			return err
+		}
+		if name == "fx.cos" {
+			cg.builder.AddInstruction(rom.EncodeADD(1, 0, 0))
+			cg.builder.AddImmediate(64)
+		}
+		cg.needFxSin = true
+		cg.emitHelperCall("__fxsin")
+	default:
+		return fmt.Errorf("unknown function: %s", name)
+	}
+	if destReg != 0 {
+		cg.builder.AddInstruction(rom.EncodeMOV(0, destReg, 0))
+	}
+	return nil
+}
+
+// emitFixdivHelper emits __fixdiv: signed 8.8 fixed divide.
+// In: R0 = a, R1 = b. Out: R0 = (a << 8) / b, truncated toward zero like
+// the constant folder. Division by zero returns +/-0x7FFF.
+// Clobbers R1-R7.
+//
+// The integer part comes from the hardware DIV on magnitudes; the 8
+// fraction bits come from restoring long division on the remainder.
+func (cg *CodeGenerator) emitFixdivHelper() {
+	cg.recordFuncAddr("__fixdiv")
+
+	// sign = (a ^ b) >> 15 -> R3
+	cg.builder.AddInstruction(rom.EncodeMOV(0, 3, 0))
+	cg.builder.AddInstruction(rom.EncodeXOR(0, 3, 1))
+	cg.hShrImm(3, 15)
+
+	// a = abs(a), b = abs(b)
+	for _, reg := range []uint8{0, 1} {
+		cg.builder.AddInstruction(rom.EncodeMOV(0, 6, reg))
+		cg.hShrImm(6, 15)
+		cg.hCmpImm(6, 0)
+		pos := cg.hBranch(rom.EncodeBEQ())
+		cg.hMovImm(6, 0)
+		cg.builder.AddInstruction(rom.EncodeSUB(0, 6, reg))
+		cg.builder.AddInstruction(rom.EncodeMOV(0, reg, 6))
+		cg.hPatchToHere(pos)
+	}
+
+	cg.hCmpImm(1, 0)
+	nonZeroPos := cg.hBranch(rom.EncodeBNE())
+	cg.hMovImm(0, 0x7FFF)
+	divZeroJmp := cg.hBranch(rom.EncodeJMP())
+	cg.hPatchToHere(nonZeroPos)
+
+	// R2 = q = a / b; R4 = r = a - q*b; R0 = q << 8
+	cg.builder.AddInstruction(rom.EncodeMOV(0, 2, 0))
+	cg.builder.AddInstruction(rom.EncodeDIV(0, 2, 1))
+	cg.builder.AddInstruction(rom.EncodeMOV(0, 6, 2))
+	cg.builder.AddInstruction(rom.EncodeMUL(0, 6, 1))
+	cg.builder.AddInstruction(rom.EncodeMOV(0, 4, 0))
+	cg.builder.AddInstruction(rom.EncodeSUB(0, 4, 6))
+	cg.builder.AddInstruction(rom.EncodeMOV(0, 0, 2))
+	cg.hShlImm(0, 8)
+
+	// Fraction bits, R5 = current bit (0x80 down to 1). r < b <= 0x8000
+	// throughout, so 2r fits in 16 bits; 2r >= b is tested as r >= b - r
+	// to stay within the signed branches.
+	cg.hMovImm(5, 0x80)
+	loop := cg.builder.GetCodeLength()
+	cg.hCmpImm(5, 0)
+	donePos := cg.hBranch(rom.EncodeBEQ())
+	cg.builder.AddInstruction(rom.EncodeMOV(0, 6, 1))
+	cg.builder.AddInstruction(rom.EncodeSUB(0, 6, 4)) // R6 = b - r
+	cg.builder.AddInstruction(rom.EncodeMOV(0, 7, 6))
+	cg.hShrImm(7, 15)
+	cg.hCmpImm(7, 0)
+	bigPos := cg.hBranch(rom.EncodeBNE()) // b - r = 0x8000: r is smaller
+	cg.builder.AddInstruction(rom.EncodeCMP(0, 4, 6))
+	smallPos := cg.hBranch(rom.EncodeBLT())
+	cg.builder.AddInstruction(rom.EncodeSUB(0, 4, 6)) // r = 2r - b
+	cg.builder.AddInstruction(rom.EncodeOR(0, 0, 5))
+	nextJmp := cg.hBranch(rom.EncodeJMP())
+	cg.hPatchToHere(bigPos)
+	cg.hPatchToHere(smallPos)
+	cg.builder.AddInstruction(rom.EncodeADD(0, 4, 4)) // r = 2r
+	cg.hPatchToHere(nextJmp)
+	cg.hShrImm(5, 1)
+	cg.hJumpBack(loop)
+
+	cg.hPatchToHere(donePos)
+	cg.hPatchToHere(divZeroJmp)
+	cg.hCmpImm(3, 0)
+	posPos := cg.hBranch(rom.EncodeBEQ())
+	cg.hMovImm(6, 0)
+	cg.builder.AddInstruction(rom.EncodeSUB(0, 6, 0))
+	cg.builder.AddInstruction(rom.EncodeMOV(0, 0, 6))
+	cg.hPatchToHere(posPos)
+	cg.builder.AddInstruction(rom.EncodeRET())
+}
+
+// emitFxSinHelper emits __fxsin: R0 = binary angle (low 8 bits used),
+// returns sin in 8.8 (-256..256) in R0. Clobbers R1-R2, R6. The quarter-wave
+// table follows the RET in the same bank and is read with DBR pointed at
+// that bank.
+func (cg *CodeGenerator) emitFxSinHelper() {
+	cg.recordFuncAddr("__fxsin")
+
+	cg.hAndImm(0, 0xFF)
+	cg.builder.AddInstruction(rom.EncodeMOV(0, 1, 0))
+	cg.hShrImm(1, 6) // R1 = quadrant
+	cg.hAndImm(0, 63)
+
+	// Odd quadrants run the table backwards: i = 64 - i.
+	cg.builder.AddInstruction(rom.EncodeMOV(0, 2, 1))
+	cg.hAndImm(2, 1)
+	cg.hCmpImm(2, 0)
+	evenPos := cg.hBranch(rom.EncodeBEQ())
+	cg.hMovImm(2, 64)
+	cg.builder.AddInstruction(rom.EncodeSUB(0, 2, 0))
+	cg.builder.AddInstruction(rom.EncodeMOV(0, 0, 2))
+	cg.hPatchToHere(evenPos)
+
+	// R0 = table[i]
+	cg.hShlImm(0, 1)
+	cg.builder.AddInstruction(rom.EncodeADD(1, 0, 0))
+	tableAddrPos := cg.builder.GetCodeLength()
+	cg.builder.AddImmediate(0) // table address, patched below
+	cg.hMovImm(6, uint16(cg.currentBank))
+	cg.builder.AddInstruction(rom.EncodeMOV(8, 6, 0)) // DBR = this bank
+	cg.builder.AddInstruction(rom.EncodeMOV(2, 0, 0))
+	cg.hMovImm(6, 0)
+	cg.builder.AddInstruction(rom.EncodeMOV(8, 6, 0)) // DBR = 0
+
+	// Quadrants 2-3 are negative.
+	cg.hCmpImm(1, 2)
+	positivePos := cg.hBranch(rom.EncodeBLT())
+	cg.hMovImm(6, 0)
+	cg.builder.AddInstruction(rom.EncodeSUB(0, 6, 0))
+	cg.builder.AddInstruction(rom.EncodeMOV(0, 0, 6))
+	cg.hPatchToHere(positivePos)
+	cg.builder.AddInstruction(rom.EncodeRET())
+
+	cg.builder.SetImmediateAt(tableAddrPos, uint16(rom.ROMBankOffsetBase+cg.builder.GetCodeLength()*2))
+	cg.fxSinTableAddrPos = tableAddrPos
+	cg.fxSinTable.Start = cg.builder.GetCodeLength()
+	for _, v := range fxSinTable() {
+		cg.builder.AddImmediate(v)
+	}
+	cg.fxSinTable.End = cg.builder.GetCodeLength()
+}
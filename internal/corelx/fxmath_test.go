@@ -0,0 +1,103 @@
+package corelx
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+// TestFxBuiltinsRuntime runs fx.mul/fx.div/fx.sin/fx.cos, fixed `/`, and
+// `fx` literals on the emulator and checks the 8.8 results.
+func TestFxBuiltinsRuntime(t *testing.T) {
+	cases := []struct {
+		expr string
+		want int16
+	}{
+		{"fx.mul(1.5fx, 2.0)", 0x0300},
+		{"fx.mul(0.0 - 1.5, 2fx)", -0x0300},
+		{"fx.div(3.0, 2.0)", 0x0180},
+		{"fx.div(0.0 - 1.0, 3.0)", -85},
+		{"fx.div(1.0, 0.0)", 0x7FFF},
+		{"fx.div(fx.mul(a, 2.0), b)", 0x0400}, // (2.5*2)/1.25
+		{"a / b", 0x0200},
+		{"fx.div(10.0, 0.5)", 20 << 8},
+		{"fx.sin(0)", 0},
+		{"fx.sin(32)", 181},
+		{"fx.sin(64)", 256},
+		{"fx.sin(96)", 181},
+		{"fx.sin(128)", 0},
+		{"fx.sin(200)", -int16(math.Round(math.Sin(72*2*math.Pi/256) * 256))},
+		{"fx.sin(256 + 64)", 256},
+		{"fx.cos(0)", 256},
+		{"fx.cos(128)", -256},
+		{"fx.cos(192)", 0},
+	}
+	var src strings.Builder
+	src.WriteString("var a: fixed = 2.5\nvar b: fixed = 1.25\n")
+	for i := range cases {
+		fmt.Fprintf(&src, "var r%d: fixed = 0.0\n", i)
+	}
+	src.WriteString("\nfunction Start()\n")
+	for i, c := range cases {
+		fmt.Fprintf(&src, "    r%d = %s\n", i, c.expr)
+	}
+	src.WriteString("    while true\n        wait_vblank()\n")
+
+	emu, result := compileAndBoot(t, src.String(), 20000)
+	addrs := map[string]uint16{}
+	for _, e := range result.MemoryMap {
+		addrs[e.Name] = e.Address
+	}
+	for i, c := range cases {
+		got := int16(read16(emu, addrs[fmt.Sprintf("r%d", i)]))
+		if got != c.want {
+			t.Errorf("%s = %d (0x%04X), want %d", c.expr, got, uint16(got), c.want)
+		}
+	}
+}
+
+// TestConstFoldMatchesRuntimeFixdiv checks constant-folded fixed division
+// agrees bit-for-bit with __fixdiv, including negative truncation.
+func TestConstFoldMatchesRuntimeFixdiv(t *testing.T) {
+	for _, c := range []struct{ a, b string }{
+		{"7.0", "3.0"}, {"-7.0", "3.0"}, {"1.0", "-0.1"}, {"-100.5", "-0.75"}, {"0.01", "3.0"},
+	} {
+		source := `const FOLDED = ` + c.a + ` / ` + c.b + `
+var rt_a: fixed = ` + c.a + `
+var rt_b: fixed = ` + c.b + `
+var folded: fixed = FOLDED
+var runtime: fixed = 0.0
+
+function Start()
+    runtime = rt_a / rt_b
+    while true
+        wait_vblank()
+`
+		emu, result := compileAndBoot(t, source, 4000)
+		addrs := map[string]uint16{}
+		for _, e := range result.MemoryMap {
+			addrs[e.Name] = e.Address
+		}
+		folded, runtime := read16(emu, addrs["folded"]), read16(emu, addrs["runtime"])
+		if folded != runtime {
+			t.Errorf("%s / %s: fold 0x%04X != runtime fixdiv 0x%04X", c.a, c.b, folded, runtime)
+		}
+	}
+}
+
+func TestFxLiteralSuffix(t *testing.T) {
+	tokens, err := NewLexer("x := 2fx + 1.5fx\ny := fxval\n").Tokenize()
+	if err != nil {
+		t.Fatalf("lex: %v", err)
+	}
+	var numbers []string
+	for _, tok := range tokens {
+		if tok.Type == TOKEN_NUMBER {
+			numbers = append(numbers, tok.Literal)
+		}
+	}
+	if strings.Join(numbers, ",") != "2fx,1.5fx" {
+		t.Fatalf("number tokens = %v, want [2fx 1.5fx]", numbers)
+	}
+}
@@ -0,0 +1,111 @@
+package corelx
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The structured game-loop form: a program with no Start() that defines
+// Update() and/or Draw() (and optionally Init()) gets a compiler-written
+// Start() that runs
+//
+//	Init()                      -- once
+//	each frame, in VBlank:
+//	    Draw()                  -- OAM/VRAM writes land while the PPU is idle
+//	    input.poll()            -- latch this frame's controller state
+//	    Update()                -- game logic, may run into active display
+//
+// so beginners never put sprite writes after their logic, where they land
+// mid-frame (the most common hand-written `while true` loop bug). Draw()
+// therefore shows the state the previous Update() left.
+//
+// The frame_counter() check makes each frame run once however short the
+// body is: wait_vblank()'s flag stays set for all of VBlank (see
+// corelx-wait-vblank-multi-iteration-pitfall). A frame whose Update() runs
+// past the next VBlank is simply skipped, never run twice.
+const gameLoopFrameVar = "__game_loop_frame"
+
+// gameLoopHooks are the user functions the generated Start() calls, in
+// the order the loop form documents them.
+var gameLoopHooks = []string{"Init", "Update", "Draw"}
+
+// injectGameLoop adds the generated Start() when the program uses the
+// loop form, and records it in program.GameLoop. A program with its own
+// Start() is left alone: Update() and Draw() are then ordinary functions.
+// A user __Boot() still works, and may call the generated Start().
+func injectGameLoop(program *Program, cfg CompileOptions) error {
+	if cfg.Immediate {
+		return nil
+	}
+	defined := make(map[string]bool)
+	for _, fn := range program.Functions {
+		defined[fn.Name] = true
+	}
+	if defined["Start"] || !defined["Update"] && !defined["Draw"] {
+		return nil
+	}
+
+	var src strings.Builder
+	fmt.Fprintf(&src, "var %s: int = 0\n\nfunction Start()\n", gameLoopFrameVar)
+	if defined["Init"] {
+		src.WriteString("    Init()\n")
+	}
+	fmt.Fprintf(&src, "    %s = frame_counter()\n", gameLoopFrameVar)
+	src.WriteString("    while true\n        wait_vblank()\n")
+	fmt.Fprintf(&src, "        if frame_counter() != %s\n", gameLoopFrameVar)
+	fmt.Fprintf(&src, "            %s = frame_counter()\n", gameLoopFrameVar)
+	if defined["Draw"] {
+		src.WriteString("            Draw()\n")
+	}
+	src.WriteString("            input.poll()\n")
+	if defined["Update"] {
+		src.WriteString("            Update()\n")
+	}
+
+	tokens, err := NewLexer(src.String()).Tokenize()
+	if err != nil {
+		return fmt.Errorf("internal: game loop lex: %w", err)
+	}
+	loop, err := NewParser(tokens).Parse()
+	if err != nil {
+		return fmt.Errorf("internal: game loop parse: %w", err)
+	}
+	program.Functions = append(program.Functions, loop.Functions...)
+	program.Globals = append(program.Globals, loop.Globals...)
+	program.GameLoop = true
+	return nil
+}
+
+// checkGameLoopHook validates a user function the generated Start() calls.
+func (a *SemanticAnalyzer) checkGameLoopHook(fn *FunctionDecl) {
+	if !a.program.GameLoop || !isGameLoopHook(fn.Name) {
+		return
+	}
+	if fn.Extern {
+		a.addDiagnostic(fn.Position, CategoryValidationError, "E_EXTERN_ENTRY", fmt.Sprintf("game loop function %s() cannot be extern", fn.Name), "")
+	}
+	if len(fn.Params) > 0 {
+		a.addDiagnostic(fn.Position, CategoryValidationError, "E_ENTRY_PARAMS", fmt.Sprintf("game loop function %s() must have no parameters", fn.Name), "")
+	}
+}
+
+// checkGameLoopWait warns about wait_vblank() in Update() or Draw(): the
+// generated loop already waits, so another wait drops every other frame
+// and moves Draw()'s writes out of VBlank.
+func (a *SemanticAnalyzer) checkGameLoopWait(call *CallExpr) {
+	if !a.program.GameLoop || a.currentFunc == nil || callFuncName(call) != "wait_vblank" {
+		return
+	}
+	if name := a.currentFunc.Name; name == "Update" || name == "Draw" {
+		a.addWarning(call.Position, CategoryValidationError, "W_GAME_LOOP_WAIT", fmt.Sprintf("wait_vblank() in %s(): the game loop already waits for VBlank once per frame; remove this call", name))
+	}
+}
+
+func isGameLoopHook(name string) bool {
+	for _, hook := range gameLoopHooks {
+		if name == hook {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+package corelx
+
+import (
+	"strings"
+	"testing"
+
+	"nitro-core-dx/internal/cpu"
+	"nitro-core-dx/internal/ppu"
+)
+
+func TestGameLoopRunsDrawInVBlankThenUpdateOncePerFrame(t *testing.T) {
+	source := `var inits: int = 0
+var updates: int = 0
+var draws: int = 0
+var drawn_updates: int = 0
+var presses: int = 0
+
+function Init()
+    inits = inits + 1
+
+function Update()
+    updates = updates + 1
+    if input.pressed(A)
+        presses = presses + 1
+
+function Draw()
+    draws = draws + 1
+    drawn_updates = updates
+`
+	emu, result := compileLoadForTest(t, source)
+	if !result.Program.GameLoop {
+		t.Fatal("Program.GameLoop not set for a program with Update()/Draw() and no Start()")
+	}
+	var drawOffset uint16
+	for _, f := range result.Functions {
+		if f.Name == "Draw" {
+			drawOffset = f.Offset
+		}
+	}
+	drawCalls, outsideVBlank := 0, 0
+	emu.CPU.CallHook = func(ev cpu.CallEvent) {
+		if ev.Kind != cpu.CallEventCall || ev.Offset != drawOffset {
+			return
+		}
+		drawCalls++
+		if emu.PPU.GetScanline() < ppu.VisibleScanlines {
+			outsideVBlank++
+		}
+	}
+	emu.Start()
+
+	const frames = 30
+	for i := 0; i < frames; i++ {
+		if i == 10 {
+			emu.SetInputButtons(0x0010) // A, held from here on
+		}
+		if err := emu.RunFrame(); err != nil {
+			t.Fatalf("frame %d: %v", i, err)
+		}
+	}
+	addrs := map[string]uint16{}
+	for _, e := range result.MemoryMap {
+		addrs[e.Name] = e.Address
+	}
+	updates := read16(emu, addrs["updates"])
+	if got := read16(emu, addrs["inits"]); got != 1 {
+		t.Errorf("Init ran %d times, want 1", got)
+	}
+	if updates < frames-3 || updates > frames {
+		t.Errorf("Update ran %d times in %d frames, want once per frame", updates, frames)
+	}
+	if got := read16(emu, addrs["draws"]); got != updates {
+		t.Errorf("Draw ran %d times, Update %d", got, updates)
+	}
+	// Draw runs first each frame, so it sees the previous Update's state.
+	if got := read16(emu, addrs["drawn_updates"]); got != updates-1 {
+		t.Errorf("Draw saw %d updates, want %d", got, updates-1)
+	}
+	if got := read16(emu, addrs["presses"]); got != 1 {
+		t.Errorf("input.pressed(A) fired %d times for one press, want 1", got)
+	}
+	if drawCalls == 0 || outsideVBlank > 0 {
+		t.Errorf("Draw was called outside VBlank %d of %d times", outsideVBlank, drawCalls)
+	}
+}
+
+func TestGameLoopLeavesStartProgramsAlone(t *testing.T) {
+	source := `function Update()
+    wait_vblank()
+
+function Start()
+    while true
+        Update()
+`
+	result, err := CompileSource(source, "start.corelx", nil)
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	if result.Program.GameLoop {
+		t.Error("Program.GameLoop set for a program with its own Start()")
+	}
+	for _, d := range result.Diagnostics {
+		if d.Code == "W_GAME_LOOP_WAIT" {
+			t.Errorf("unexpected %s outside the loop form", d.Code)
+		}
+	}
+}
+
+func TestGameLoopDiagnostics(t *testing.T) {
+	source := `function Update(x: int)
+    wait_vblank()
+
+function Draw()
+    wait_vblank()
+`
+	result, _ := CompileSource(source, "loop.corelx", nil)
+	var codes []string
+	for _, d := range result.Diagnostics {
+		codes = append(codes, d.Code)
+	}
+	got := strings.Join(codes, ",")
+	for _, want := range []string{"E_ENTRY_PARAMS", "W_GAME_LOOP_WAIT"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("diagnostics %s, want %s", got, want)
+		}
+	}
+}
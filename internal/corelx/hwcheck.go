@@ -0,0 +1,78 @@
+package corelx
+
+import (
+	"fmt"
+
+	"nitro-core-dx/internal/emulator"
+)
+
+// HardwareCheckFrames is how long CompileOptions.ValidateHardware runs the
+// built ROM: five seconds, past the default boot splash and into Start().
+const HardwareCheckFrames = 300
+
+// checkHardwareCompat runs romBytes under strict hardware rules (see
+// emulator.CheckHardwareCompat) and turns what it relies on the emulator's
+// leniency for into warnings. Each is placed on the source line whose code
+// did it first, when that code is in a function declared in source (own);
+// the rest name the ROM address only.
+func checkHardwareCompat(romBytes []byte, sourcePath string, cg *CodeGenerator, own map[string]bool) []Diagnostic {
+	issues, err := emulator.CheckHardwareCompat(romBytes, HardwareCheckFrames)
+	var diags []Diagnostic
+	for _, issue := range issues {
+		d := Diagnostic{
+			Category: CategoryValidationError,
+			Message:  issue.String(),
+			File:     sourcePath,
+			Severity: SeverityWarning,
+			Stage:    StageValidate,
+		}
+		switch issue.Kind {
+		case emulator.HardwareLateWrite:
+			d.Code = "W_HW_LATE_WRITE"
+			d.Notes = []string{"Upload during VBlank: after wait_vblank() in the frame loop, or build with the hardware target, which guards every upload builtin."}
+		case emulator.HardwareWriteOnlyRead:
+			d.Code = "W_HW_WRITE_ONLY_READ"
+			d.Notes = []string{"Keep a copy of the value in a variable instead of reading the register back."}
+		}
+		if sl, ok := sourceLineAt(cg, own, issue.PCBank, issue.PCOffset); ok {
+			d.Line, d.EndLine = sl.Line, sl.Line
+		}
+		diags = append(diags, d)
+	}
+	if err != nil {
+		diags = append(diags, Diagnostic{
+			Category: CategoryValidationError,
+			Code:     "W_HW_CHECK_FAILED",
+			Message:  fmt.Sprintf("hardware check stopped: %v", err),
+			File:     sourcePath,
+			Severity: SeverityWarning,
+			Stage:    StageValidate,
+		})
+	}
+	return diags
+}
+
+// sourceLineAt returns the source line whose code contains bank:offset, if
+// it belongs to a function in own.
+func sourceLineAt(cg *CodeGenerator, own map[string]bool, bank uint8, offset uint16) (SourceLine, bool) {
+	// The function containing the address is the one starting closest
+	// below it.
+	var fn FunctionSymbol
+	found := false
+	for _, f := range cg.FunctionSymbols() {
+		if f.Bank == bank && f.Offset <= offset && (!found || f.Offset > fn.Offset) {
+			fn, found = f, true
+		}
+	}
+	if !found || !own[fn.Name] {
+		return SourceLine{}, false
+	}
+	var best SourceLine
+	ok := false
+	for _, sl := range cg.SourceLines() {
+		if sl.Function == fn.Name && sl.Bank == bank && sl.Offset <= offset && (!ok || sl.Offset > best.Offset) {
+			best, ok = sl, true
+		}
+	}
+	return best, ok
+}
@@ -0,0 +1,58 @@
+package corelx
+
+import (
+	"strings"
+	"testing"
+)
+
+// hwCheckProgram uploads a palette color during active display (line 10)
+// and reads back BG0's write-only scroll register (line 11).
+const hwCheckProgram = `var i: int = 0
+
+function Start()
+    while true
+        wait_vblank()
+        i = 0
+        while i < 400
+            i = i + 1
+        x := mem.read(0x8000)
+        gfx.set_palette_color(1, 0x7C1F)
+        x = mem.read(0x8000)
+`
+
+func TestValidateHardwareWarnings(t *testing.T) {
+	res, err := CompileSource(hwCheckProgram, "hw.corelx", &CompileOptions{ValidateHardware: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]Diagnostic{}
+	for _, d := range res.Diagnostics {
+		if d.Stage == StageValidate {
+			if d.Severity != SeverityWarning {
+				t.Errorf("%s is %s, want a warning", d.Code, d.Severity)
+			}
+			got[d.Code] = d
+		}
+	}
+	late, ok := got["W_HW_LATE_WRITE"]
+	if !ok || late.Line != 10 || !strings.Contains(late.Message, "CGRAM") {
+		t.Errorf("late write = %+v, want CGRAM on line 10", late)
+	}
+	read, ok := got["W_HW_WRITE_ONLY_READ"]
+	if !ok || read.Line != 9 || !strings.Contains(read.Message, "BG0_SCROLLX_L") {
+		t.Errorf("write-only read = %+v, want BG0_SCROLLX_L on line 9", read)
+	}
+	if b := BuildCompileBundle(res); b.Summary.WarningCount < 2 || !b.Success {
+		t.Errorf("bundle summary = %+v; want the warnings counted and the build still successful", b.Summary)
+	}
+
+	res, err = CompileSource(hwCheckProgram, "hw.corelx", &CompileOptions{ValidateHardware: true, Target: TargetHardware})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range res.Diagnostics {
+		if d.Code == "W_HW_LATE_WRITE" {
+			t.Errorf("hardware target still warns: %s", d.Message)
+		}
+	}
+}
@@ -13,14 +13,30 @@ import (
 )
 
 // ImageAsset is a parsed external .cxasset bitmap image, placed in ROM.
+//
+// Kind is "bitmap_plane" (a 4bpp matrix-plane bitmap, the default) or one of
+// the 8bpp background kinds "bg8_indexed" and "bg8_direct", whose Bitmap is
+// Tiles×64 bytes of tile data followed by the tilemap; for bg8_indexed the
+// palette is laid out in ROM right after it as little-endian words.
 type ImageAsset struct {
 	Name        string
+	Kind        string
 	PlaneSize   int      // 32, 64, or 128 (tiles per side)
 	PaletteBank uint8    // CGRAM bank (0-15)
 	Palette     []uint16 // RGB555 colors
-	Bitmap      []byte   // 4bpp packed bitmap
+	Bitmap      []byte   // 4bpp packed bitmap, or bg8 tiles + tilemap
 	Bank        uint8    // ROM bank where Bitmap starts
 	Offset      uint16   // ROM offset (0x8000-based) where Bitmap starts
+
+	// bg8 kinds only.
+	TilemapSize int    // 32, 64, or 128 (tiles per side)
+	TilemapBase uint16 // VRAM address of the tilemap
+	Tiles       int    // number of 64-byte tiles at VRAM 0x0000
+}
+
+// isBG8 reports whether the asset is an 8bpp background image.
+func (img *ImageAsset) isBG8() bool {
+	return img.Kind == "bg8_indexed" || img.Kind == "bg8_direct"
 }
 
 // loadImageAssets reads and parses every `image` asset's external .cxasset
@@ -63,6 +79,12 @@ func loadImageAssets(program *Program, sourcePath string, startBank uint8) (map[
 		img.Offset = uint16(rom.ROMBankOffsetBase + (cursor % rom.ROMBankSizeBytes))
 		region = append(region, img.Bitmap...)
 		cursor += len(img.Bitmap)
+		if img.Kind == "bg8_indexed" {
+			for _, c := range img.Palette {
+				region = append(region, uint8(c), uint8(c>>8))
+			}
+			cursor += len(img.Palette) * 2
+		}
 
 		assets[a.Name] = img
 	}
@@ -97,8 +119,11 @@ func loadImageAssets(program *Program, sourcePath string, startBank uint8) (map[
 //	    palette: hex 0000 7fff ...
 //	    data: hex
 //	        a0 fa ...
+//
+// The bg8 kinds replace plane_size and palette_bank with tilemap_size,
+// tilemap_base and tiles (see emulator.BG8Image.CxAsset).
 func parseCxAsset(name, text string) (*ImageAsset, error) {
-	img := &ImageAsset{Name: name}
+	img := &ImageAsset{Name: name, Kind: "bitmap_plane"}
 	lines := strings.Split(text, "\n")
 	inData := false
 	for _, line := range lines {
@@ -117,6 +142,20 @@ func parseCxAsset(name, text string) (*ImageAsset, error) {
 			continue
 		}
 		switch {
+		case strings.HasPrefix(t, "kind:"):
+			img.Kind = strings.TrimSpace(strings.TrimPrefix(t, "kind:"))
+		case strings.HasPrefix(t, "tilemap_size:"):
+			n, _ := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(t, "tilemap_size:")))
+			img.TilemapSize = n
+		case strings.HasPrefix(t, "tilemap_base:"):
+			n, err := strconv.ParseUint(strings.TrimSpace(strings.TrimPrefix(t, "tilemap_base:")), 0, 16)
+			if err != nil {
+				return nil, fmt.Errorf("bad tilemap_base: %w", err)
+			}
+			img.TilemapBase = uint16(n)
+		case strings.HasPrefix(t, "tiles:"):
+			n, _ := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(t, "tiles:")))
+			img.Tiles = n
 		case strings.HasPrefix(t, "plane_size:"):
 			n, _ := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(t, "plane_size:")))
 			img.PlaneSize = n
@@ -135,12 +174,31 @@ func parseCxAsset(name, text string) (*ImageAsset, error) {
 			inData = true
 		}
 	}
-	if img.PlaneSize != 32 && img.PlaneSize != 64 && img.PlaneSize != 128 {
-		return nil, fmt.Errorf("plane_size must be 32, 64, or 128 (got %d)", img.PlaneSize)
-	}
 	if len(img.Bitmap) == 0 {
 		return nil, fmt.Errorf("no bitmap data")
 	}
+	switch img.Kind {
+	case "bitmap_plane":
+		if img.PlaneSize != 32 && img.PlaneSize != 64 && img.PlaneSize != 128 {
+			return nil, fmt.Errorf("plane_size must be 32, 64, or 128 (got %d)", img.PlaneSize)
+		}
+	case "bg8_indexed", "bg8_direct":
+		if img.TilemapSize != 32 && img.TilemapSize != 64 && img.TilemapSize != 128 {
+			return nil, fmt.Errorf("tilemap_size must be 32, 64, or 128 (got %d)", img.TilemapSize)
+		}
+		mapBytes := img.TilemapSize * img.TilemapSize * 2
+		if img.Tiles < 1 || len(img.Bitmap) != img.Tiles*64+mapBytes {
+			return nil, fmt.Errorf("data is %d bytes; %d tiles and a %dx%d tilemap need %d", len(img.Bitmap), img.Tiles, img.TilemapSize, img.TilemapSize, img.Tiles*64+mapBytes)
+		}
+		if img.Tiles*64 > int(img.TilemapBase) || int(img.TilemapBase)+mapBytes > 0x10000 {
+			return nil, fmt.Errorf("tiles (VRAM 0x0000-0x%04X) and tilemap (0x%04X-0x%04X) do not fit in VRAM without overlapping", img.Tiles*64-1, img.TilemapBase, int(img.TilemapBase)+mapBytes-1)
+		}
+		if img.Kind == "bg8_indexed" && (len(img.Palette) == 0 || len(img.Palette) > 256) {
+			return nil, fmt.Errorf("bg8_indexed needs a palette of 1-256 colors (got %d)", len(img.Palette))
+		}
+	default:
+		return nil, fmt.Errorf("unknown image kind %q", img.Kind)
+	}
 	return img, nil
 }
 
@@ -0,0 +1,135 @@
+package corelx
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ImmediateProgram is console input compiled for immediate execution on a
+// paused machine (see emulator.RunImmediate).
+type ImmediateProgram struct {
+	ROMBytes []byte
+	// HasValue is set when the input was a single value expression: anything
+	// but a call, or a call to a value-returning built-in such as mem.read.
+	// The entry function then returns it in R0.
+	HasValue bool
+	// Diagnostics count lines from the first line of the input.
+	Diagnostics []Diagnostic
+}
+
+// immediateValueBuiltins are the built-ins that return a value, so a lone
+// call to one is echoed rather than run for effect. SPR_* helpers also
+// return values and are matched by prefix.
+var immediateValueBuiltins = map[string]bool{
+	"int": true, "fixed": true, "frame_counter": true, "reset_cause": true,
+	"input.read": true, "input.held": true, "input.pressed": true, "input.released": true,
+	"mem.read": true, "mem.read16": true, "bg.tile_at": true, "phys.aabb": true,
+	"sprite.attr": true, "sprite.ctrl": true, "persist.get": true, "dec.digits": true,
+	"fx.mul": true, "fx.div": true, "fx.sin": true, "fx.cos": true,
+}
+
+// CompileImmediate compiles CoreLX statements, or one expression, typed into
+// an immediate console. The input becomes the body of a __Boot() compiled
+// with CompileOptions.Immediate; a value expression becomes `return <expr>`.
+// Snippets are self-contained: they can use built-ins, constants such as
+// button names, and their own locals, but nothing the loaded program
+// defines.
+func CompileImmediate(input string) (*ImmediateProgram, error) {
+	source, hasValue, err := immediateSource(input)
+	if err != nil {
+		return nil, err
+	}
+	out := &ImmediateProgram{HasValue: hasValue}
+	res, compileErr := CompileSource(source, "", &CompileOptions{Immediate: true, EmitROMBytes: true})
+	if res != nil {
+		for _, d := range res.Diagnostics {
+			// Line 1 is the generated function header.
+			if d.Line > 1 {
+				d.Line--
+			}
+			if d.EndLine > 1 {
+				d.EndLine--
+			}
+			out.Diagnostics = append(out.Diagnostics, d)
+		}
+	}
+	if compileErr != nil {
+		for _, d := range out.Diagnostics {
+			if d.Severity == SeverityError {
+				return out, d
+			}
+		}
+		return out, compileErr
+	}
+	out.ROMBytes = res.ROMBytes
+	return out, nil
+}
+
+// immediateSource wraps console input in the __Boot() that
+// CompileImmediate compiles. Common indentation is removed first.
+func immediateSource(input string) (source string, hasValue bool, err error) {
+	lines := strings.Split(strings.ReplaceAll(input, "\r\n", "\n"), "\n")
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	if len(lines) == 0 {
+		return "", false, fmt.Errorf("nothing to run")
+	}
+	if len(lines) == 1 && isImmediateValueExpr(strings.TrimSpace(lines[0])) {
+		return "function __Boot() -> int\n    return " + strings.TrimSpace(lines[0]) + "\n", true, nil
+	}
+
+	indent := -1
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		n := len(line) - len(strings.TrimLeft(line, " \t"))
+		if indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	var body strings.Builder
+	body.WriteString("function __Boot()\n")
+	for _, line := range lines {
+		if len(line) >= indent {
+			line = line[indent:]
+		}
+		body.WriteString("    " + strings.TrimRight(line, " \t") + "\n")
+	}
+	return body.String(), false, nil
+}
+
+// isImmediateValueExpr reports whether line parses as a lone expression
+// statement that produces a value.
+func isImmediateValueExpr(line string) bool {
+	tokens, err := NewLexer("function __Boot()\n    " + line + "\n").Tokenize()
+	if err != nil {
+		return false
+	}
+	program, err := NewParser(tokens).Parse()
+	if err != nil || len(program.Functions) != 1 || len(program.Functions[0].Body) != 1 {
+		return false
+	}
+	stmt, ok := program.Functions[0].Body[0].(*ExprStmt)
+	if !ok {
+		return false
+	}
+	call, ok := stmt.Expr.(*CallExpr)
+	if !ok {
+		return true
+	}
+	var name string
+	switch fn := call.Func.(type) {
+	case *IdentExpr:
+		name = fn.Name
+	case *MemberExpr:
+		if obj, ok := fn.Object.(*IdentExpr); ok {
+			name = obj.Name + "." + fn.Member
+		}
+	}
+	return immediateValueBuiltins[name] || strings.HasPrefix(name, "SPR_")
+}
@@ -0,0 +1,49 @@
+package corelx
+
+import "testing"
+
+func TestImmediateSourceWrapping(t *testing.T) {
+	cases := []struct {
+		input    string
+		source   string
+		hasValue bool
+	}{
+		{"mem.read(0x2100)", "function __Boot() -> int\n    return mem.read(0x2100)\n", true},
+		{"  2 * 3 + 1  ", "function __Boot() -> int\n    return 2 * 3 + 1\n", true},
+		{"SPR_SIZE_16()", "function __Boot() -> int\n    return SPR_SIZE_16()\n", true},
+		{"oam.write_sprite_data(0, 50, 50, 1, 0x10, 1)", "function __Boot()\n    oam.write_sprite_data(0, 50, 50, 1, 0x10, 1)\n", false},
+		{"x := 5", "function __Boot()\n    x := 5\n", false},
+		{"\n    if true\n        oam.flush()\n\n", "function __Boot()\n    if true\n        oam.flush()\n", false},
+	}
+	for _, tc := range cases {
+		source, hasValue, err := immediateSource(tc.input)
+		if err != nil {
+			t.Fatalf("%q: %v", tc.input, err)
+		}
+		if source != tc.source || hasValue != tc.hasValue {
+			t.Fatalf("%q:\ngot  %q (value %v)\nwant %q (value %v)", tc.input, source, hasValue, tc.source, tc.hasValue)
+		}
+	}
+	if _, _, err := immediateSource(" \n\t\n"); err == nil {
+		t.Fatalf("expected blank input to be rejected")
+	}
+}
+
+func TestCompileImmediateSkipsBootSequence(t *testing.T) {
+	prog, err := CompileImmediate("mem.write(0x7000, 1)")
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	normal, err := CompileSource("function __Boot()\n    mem.write(0x7000, 1)\n", "", &CompileOptions{ForceBootSplash: true})
+	if err != nil {
+		t.Fatalf("compile normal: %v", err)
+	}
+	if len(prog.ROMBytes) == 0 || len(prog.ROMBytes) >= len(normal.ROMBytes) {
+		t.Fatalf("immediate ROM (%d bytes) should be smaller than one carrying the boot sequence (%d bytes)", len(prog.ROMBytes), len(normal.ROMBytes))
+	}
+
+	prog, err = CompileImmediate("oam.flush()\nx := (1 +")
+	if err == nil || prog == nil || len(prog.Diagnostics) == 0 || prog.Diagnostics[0].Line != 2 {
+		t.Fatalf("expected a diagnostic on input line 2, got %v %+v", err, prog)
+	}
+}
@@ -0,0 +1,288 @@
+package corelx
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The IR is a typed three-address view of a function: straight-line
+// instructions on virtual registers, grouped into basic blocks that end in
+// a jump, a two-way branch or a return. It is a dump for inspection only,
+// built when CompileOptions.EmitIR or IROutputPath asks for it. Code
+// generation (codegen.go) works from the AST and never reads the IR, so
+// the dump shows the control flow and values of the source, not the
+// instructions in the ROM.
+//
+// Lowering (ir_lower.go) covers the whole statement and expression
+// language.
+
+// IRType is the type of an IR value: a storage type ("int", "u8", "i8",
+// "u16", "i16", "fixed"), "bool", "string", a struct pointer ("*Vec2"), or
+// "?" when it cannot be inferred.
+type IRType string
+
+// IRTypeUnknown is the type of a value whose type cannot be inferred.
+const IRTypeUnknown IRType = "?"
+
+// VReg is a virtual register, numbered from 1. Each is assigned once; 0
+// means no register.
+type VReg int
+
+func (r VReg) String() string { return fmt.Sprintf("v%d", int(r)) }
+
+// IROp is an IR instruction's operation.
+type IROp uint8
+
+const (
+	IRConst      IROp = iota // Dst = Imm
+	IRString                 // Dst = address of the string literal Name
+	IRSymbol                 // Dst = the asset or function Name
+	IRLoad                   // Dst = variable Name (local, parameter or global)
+	IRStore                  // variable Name = Args[0]
+	IRLoadIndex              // Dst = Name[Args[0]]
+	IRStoreIndex             // Name[Args[0]] = Args[1]
+	IRLoadField              // Dst = Args[0].Name
+	IRStoreField             // Args[0].Name = Args[1]
+	IRUnary                  // Dst = Tok Args[0]
+	IRBinary                 // Dst = Args[0] Tok Args[1]
+	IRCall                   // Dst = Name(Args...); Dst is 0 for a call whose value is unused
+)
+
+// IRInst is one IR instruction.
+type IRInst struct {
+	Op   IROp
+	Dst  VReg
+	Type IRType // type of Dst, or of the stored value
+	Args []VReg
+	Tok  TokenType // operator of IRUnary and IRBinary
+	Name string
+	Imm  int64
+	// ArgNames are the names of named call arguments, "" for positional
+	// ones; nil when no argument is named.
+	ArgNames []string
+	Pos      Position
+}
+
+// IRTermKind is how a basic block ends.
+type IRTermKind uint8
+
+const (
+	IRJump   IRTermKind = iota // to Then
+	IRBranch                   // to Then when Cond is non-zero, else to Else
+	IRReturn                   // with Value, or nothing when it is 0
+)
+
+// IRTerm is a basic block's terminator.
+type IRTerm struct {
+	Kind       IRTermKind
+	Cond       VReg
+	Then, Else int // block IDs
+	Value      VReg
+}
+
+// IRBlock is a basic block: instructions run in order, then the
+// terminator transfers control.
+type IRBlock struct {
+	ID    int
+	Insts []IRInst
+	Term  IRTerm
+}
+
+// IRParam is a function parameter as seen by the IR.
+type IRParam struct {
+	Name string
+	Type IRType
+}
+
+// IRFunction is one function lowered to IR. Blocks[0] is the entry block;
+// block IDs are indexes into Blocks.
+type IRFunction struct {
+	Name     string
+	Params   []IRParam
+	Return   IRType // "" for a function without a result
+	Blocks   []*IRBlock
+	NumVRegs int
+}
+
+// Successors returns the IDs of the blocks b can transfer control to.
+func (b *IRBlock) Successors() []int {
+	switch b.Term.Kind {
+	case IRJump:
+		return []int{b.Term.Then}
+	case IRBranch:
+		return []int{b.Term.Then, b.Term.Else}
+	}
+	return nil
+}
+
+// Verify checks f's structural invariants: block IDs match their index,
+// terminators target existing blocks, every virtual register is assigned
+// exactly once, and every use refers to an assigned register.
+func (f *IRFunction) Verify() error {
+	defined := make(map[VReg]bool)
+	for i, b := range f.Blocks {
+		if b.ID != i {
+			return fmt.Errorf("%s: block %d has ID %d", f.Name, i, b.ID)
+		}
+		for _, in := range b.Insts {
+			if in.Dst == 0 {
+				continue
+			}
+			if defined[in.Dst] {
+				return fmt.Errorf("%s: %s assigned twice", f.Name, in.Dst)
+			}
+			if int(in.Dst) > f.NumVRegs {
+				return fmt.Errorf("%s: %s beyond NumVRegs %d", f.Name, in.Dst, f.NumVRegs)
+			}
+			defined[in.Dst] = true
+		}
+	}
+	use := func(b *IRBlock, r VReg) error {
+		if r != 0 && !defined[r] {
+			return fmt.Errorf("%s: block %d uses unassigned %s", f.Name, b.ID, r)
+		}
+		return nil
+	}
+	for _, b := range f.Blocks {
+		for _, in := range b.Insts {
+			for _, a := range in.Args {
+				if err := use(b, a); err != nil {
+					return err
+				}
+			}
+		}
+		if err := use(b, b.Term.Cond); err != nil {
+			return err
+		}
+		if err := use(b, b.Term.Value); err != nil {
+			return err
+		}
+		for _, s := range b.Successors() {
+			if s < 0 || s >= len(f.Blocks) {
+				return fmt.Errorf("%s: block %d jumps to missing block %d", f.Name, b.ID, s)
+			}
+		}
+	}
+	return nil
+}
+
+// String renders f in a readable text form, e.g.
+//
+//	function Add(a: int, b: int): int
+//	b0:
+//	    v1: int = load a
+//	    v2: int = load b
+//	    v3: int = v1 + v2
+//	    return v3
+func (f *IRFunction) String() string {
+	var sb strings.Builder
+	params := make([]string, len(f.Params))
+	for i, p := range f.Params {
+		params[i] = fmt.Sprintf("%s: %s", p.Name, p.Type)
+	}
+	fmt.Fprintf(&sb, "function %s(%s)", f.Name, strings.Join(params, ", "))
+	if f.Return != "" {
+		fmt.Fprintf(&sb, ": %s", f.Return)
+	}
+	sb.WriteString("\n")
+	for _, b := range f.Blocks {
+		fmt.Fprintf(&sb, "b%d:\n", b.ID)
+		for _, in := range b.Insts {
+			sb.WriteString("    " + in.String() + "\n")
+		}
+		sb.WriteString("    " + b.Term.String() + "\n")
+	}
+	return sb.String()
+}
+
+// formatIR renders functions one after another, as written by
+// CompileOptions.IROutputPath.
+func formatIR(funcs []*IRFunction) []byte {
+	var sb strings.Builder
+	for i, f := range funcs {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(f.String())
+	}
+	return []byte(sb.String())
+}
+
+func (in IRInst) String() string {
+	var rhs string
+	switch in.Op {
+	case IRConst:
+		rhs = fmt.Sprintf("%d", in.Imm)
+	case IRString:
+		rhs = fmt.Sprintf("string %q", in.Name)
+	case IRSymbol:
+		rhs = "symbol " + in.Name
+	case IRLoad:
+		rhs = "load " + in.Name
+	case IRStore:
+		return fmt.Sprintf("store %s = %s", in.Name, in.Args[0])
+	case IRLoadIndex:
+		rhs = fmt.Sprintf("load %s[%s]", in.Name, in.Args[0])
+	case IRStoreIndex:
+		return fmt.Sprintf("store %s[%s] = %s", in.Name, in.Args[0], in.Args[1])
+	case IRLoadField:
+		rhs = fmt.Sprintf("load %s.%s", in.Args[0], in.Name)
+	case IRStoreField:
+		return fmt.Sprintf("store %s.%s = %s", in.Args[0], in.Name, in.Args[1])
+	case IRUnary:
+		rhs = irOpName(in.Tok) + " " + in.Args[0].String()
+	case IRBinary:
+		rhs = fmt.Sprintf("%s %s %s", in.Args[0], irOpName(in.Tok), in.Args[1])
+	case IRCall:
+		args := make([]string, len(in.Args))
+		for i, a := range in.Args {
+			args[i] = a.String()
+			if i < len(in.ArgNames) && in.ArgNames[i] != "" {
+				args[i] = in.ArgNames[i] + "=" + args[i]
+			}
+		}
+		rhs = fmt.Sprintf("call %s(%s)", in.Name, strings.Join(args, ", "))
+		if in.Dst == 0 {
+			return rhs
+		}
+	}
+	return fmt.Sprintf("%s: %s = %s", in.Dst, in.Type, rhs)
+}
+
+func (t IRTerm) String() string {
+	switch t.Kind {
+	case IRJump:
+		return fmt.Sprintf("jump b%d", t.Then)
+	case IRBranch:
+		return fmt.Sprintf("branch %s, b%d, b%d", t.Cond, t.Then, t.Else)
+	}
+	if t.Value != 0 {
+		return "return " + t.Value.String()
+	}
+	return "return"
+}
+
+// irOpName renders an operator token in IR text.
+func irOpName(op TokenType) string {
+	switch op {
+	case TOKEN_AMPERSAND:
+		return "&"
+	case TOKEN_PIPE:
+		return "|"
+	case TOKEN_CARET:
+		return "^"
+	case TOKEN_LSHIFT:
+		return "<<"
+	case TOKEN_RSHIFT:
+		return ">>"
+	case TOKEN_TILDE:
+		return "~"
+	case TOKEN_AND:
+		return "and"
+	case TOKEN_OR:
+		return "or"
+	case TOKEN_NOT:
+		return "not"
+	}
+	return tokenOpName(op)
+}
@@ -0,0 +1,489 @@
+package corelx
+
+import "fmt"
+
+// irLowerer lowers one function's AST to IR. It borrows the code
+// generator for type information: cg.variables is filled in as locals are
+// declared, exactly as generateFunction does, so typeOf and inferInt see
+// the same scope.
+type irLowerer struct {
+	cg     *CodeGenerator
+	fn     *IRFunction
+	cur    *IRBlock
+	sealed []bool // per block ID: terminator set
+	loops  []irLoop
+}
+
+// irLoop is an enclosing loop's break and continue targets.
+type irLoop struct {
+	brk, cont int
+}
+
+// LowerIR lowers every function with a body to IR, in program order. It
+// runs after Generate, which folds the constants and lays out the globals
+// the lowering refers to.
+func (cg *CodeGenerator) LowerIR() ([]*IRFunction, error) {
+	var out []*IRFunction
+	for _, fn := range cg.program.Functions {
+		if fn.Extern {
+			continue
+		}
+		f, err := cg.lowerFunction(fn)
+		if err != nil {
+			return nil, fmt.Errorf("function %s: %w", fn.Name, err)
+		}
+		out = append(out, f)
+	}
+	return out, nil
+}
+
+func (cg *CodeGenerator) lowerFunction(fn *FunctionDecl) (*IRFunction, error) {
+	cg.variables = make(map[string]*VariableInfo)
+	cg.currentFunc = fn
+	l := &irLowerer{cg: cg, fn: &IRFunction{Name: fn.Name}}
+	if fn.ReturnType != nil {
+		l.fn.Return = cg.irTypeOfTypeExpr(fn.ReturnType)
+	}
+	for _, p := range fn.Params {
+		info := &VariableInfo{
+			Name:       p.Name,
+			VarType:    intTypeName(p.Type),
+			StructType: cg.structTypeNameFromTypeExpr(p.Type),
+		}
+		cg.variables[p.Name] = info
+		l.fn.Params = append(l.fn.Params, IRParam{Name: p.Name, Type: irVarType(info)})
+	}
+	l.cur = l.newBlock()
+	if err := l.stmts(fn.Body); err != nil {
+		return nil, err
+	}
+	l.terminate(IRTerm{Kind: IRReturn})
+	l.tidy()
+	return l.fn, l.fn.Verify()
+}
+
+func (l *irLowerer) newBlock() *IRBlock {
+	b := &IRBlock{ID: len(l.fn.Blocks)}
+	l.fn.Blocks = append(l.fn.Blocks, b)
+	l.sealed = append(l.sealed, false)
+	return b
+}
+
+// terminate ends the current block with t. Code after a return, break or
+// continue goes into a fresh block that nothing jumps to; tidy drops it.
+func (l *irLowerer) terminate(t IRTerm) {
+	if l.sealed[l.cur.ID] {
+		return
+	}
+	l.cur.Term = t
+	l.sealed[l.cur.ID] = true
+}
+
+func (l *irLowerer) jump(to *IRBlock) { l.terminate(IRTerm{Kind: IRJump, Then: to.ID}) }
+
+// emit appends in to the current block, giving it a fresh destination
+// register when it produces a value, and returns that register.
+func (l *irLowerer) emit(in IRInst, value bool) VReg {
+	if value {
+		l.fn.NumVRegs++
+		in.Dst = VReg(l.fn.NumVRegs)
+	}
+	l.cur.Insts = append(l.cur.Insts, in)
+	return in.Dst
+}
+
+// tidy threads jumps through empty blocks, drops the blocks the entry can
+// no longer reach, and numbers the rest in depth-first order from the
+// entry, so each block's code reads roughly as the source does.
+func (l *irLowerer) tidy() {
+	blocks := l.fn.Blocks
+	forward := func(id int) int {
+		for range blocks {
+			b := blocks[id]
+			if len(b.Insts) > 0 || b.Term.Kind != IRJump || b.Term.Then == id {
+				break
+			}
+			id = b.Term.Then
+		}
+		return id
+	}
+	for _, b := range blocks {
+		switch b.Term.Kind {
+		case IRBranch:
+			b.Term.Else = forward(b.Term.Else)
+			fallthrough
+		case IRJump:
+			b.Term.Then = forward(b.Term.Then)
+		}
+	}
+
+	ids := make([]int, len(blocks))
+	for i := range ids {
+		ids[i] = -1
+	}
+	var kept []*IRBlock
+	work := []int{0}
+	for len(work) > 0 {
+		id := work[len(work)-1]
+		work = work[:len(work)-1]
+		if ids[id] >= 0 {
+			continue
+		}
+		ids[id] = len(kept)
+		kept = append(kept, blocks[id])
+		succ := blocks[id].Successors()
+		for i := len(succ) - 1; i >= 0; i-- {
+			if ids[succ[i]] < 0 {
+				work = append(work, succ[i])
+			}
+		}
+	}
+	for _, b := range kept {
+		b.ID = ids[b.ID]
+		switch b.Term.Kind {
+		case IRBranch:
+			b.Term.Else = ids[b.Term.Else]
+			fallthrough
+		case IRJump:
+			b.Term.Then = ids[b.Term.Then]
+		}
+	}
+	l.fn.Blocks = kept
+}
+
+func (l *irLowerer) stmts(list []Stmt) error {
+	for _, s := range list {
+		if err := l.stmt(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *irLowerer) stmt(stmt Stmt) error {
+	cg := l.cg
+	switch s := stmt.(type) {
+	case *VarDeclStmt:
+		v, err := l.expr(s.Value)
+		if err != nil {
+			return err
+		}
+		info := &VariableInfo{Name: s.Name, StructType: cg.structTypeNameFromTypeExpr(s.Type)}
+		if call, ok := s.Value.(*CallExpr); ok && info.StructType == "" {
+			if ident, ok := call.Func.(*IdentExpr); ok {
+				if _, isStruct := cg.structLayoutFor(ident.Name); isStruct {
+					info.StructType = ident.Name
+				}
+			}
+		}
+		if info.StructType == "" {
+			// Same inference as generateVarDecl.
+			info.VarType = intTypeName(s.Type)
+			if info.VarType == "" {
+				info.VarType = localIntType(inferInt(cg, s.Value))
+			}
+			if info.VarType == "" {
+				info.VarType = cg.typeOf(s.Value)
+			}
+		}
+		cg.variables[s.Name] = info
+		l.emit(IRInst{Op: IRStore, Name: s.Name, Type: irVarType(info), Args: []VReg{v}, Pos: s.Position}, false)
+
+	case *AssignStmt:
+		v, err := l.expr(s.Value)
+		if err != nil {
+			return err
+		}
+		typ := cg.irTypeOf(s.Target)
+		switch t := s.Target.(type) {
+		case *IdentExpr:
+			l.emit(IRInst{Op: IRStore, Name: t.Name, Type: typ, Args: []VReg{v}, Pos: s.Position}, false)
+		case *IndexExpr:
+			name, ok := t.Array.(*IdentExpr)
+			if !ok {
+				return fmt.Errorf("line %d: cannot lower assignment to an index of %T", s.Position.Line, t.Array)
+			}
+			idx, err := l.expr(t.Index)
+			if err != nil {
+				return err
+			}
+			l.emit(IRInst{Op: IRStoreIndex, Name: name.Name, Type: typ, Args: []VReg{idx, v}, Pos: s.Position}, false)
+		case *MemberExpr:
+			obj, err := l.expr(t.Object)
+			if err != nil {
+				return err
+			}
+			l.emit(IRInst{Op: IRStoreField, Name: t.Member, Type: typ, Args: []VReg{obj, v}, Pos: s.Position}, false)
+		default:
+			return fmt.Errorf("line %d: cannot lower assignment to %T", s.Position.Line, s.Target)
+		}
+
+	case *IfStmt:
+		end := l.newBlock()
+		clauses := append([]*ElseIfClause{{Position: s.Position, Condition: s.Condition, Body: s.Then}}, s.ElseIf...)
+		for _, c := range clauses {
+			cond, err := l.expr(c.Condition)
+			if err != nil {
+				return err
+			}
+			then, next := l.newBlock(), l.newBlock()
+			l.terminate(IRTerm{Kind: IRBranch, Cond: cond, Then: then.ID, Else: next.ID})
+			l.cur = then
+			if err := l.stmts(c.Body); err != nil {
+				return err
+			}
+			l.jump(end)
+			l.cur = next
+		}
+		if err := l.stmts(s.Else); err != nil {
+			return err
+		}
+		l.jump(end)
+		l.cur = end
+
+	case *WhileStmt:
+		head, body, exit := l.newBlock(), l.newBlock(), l.newBlock()
+		l.jump(head)
+		l.cur = head
+		cond, err := l.expr(s.Condition)
+		if err != nil {
+			return err
+		}
+		l.terminate(IRTerm{Kind: IRBranch, Cond: cond, Then: body.ID, Else: exit.ID})
+		l.cur = body
+		l.loops = append(l.loops, irLoop{brk: exit.ID, cont: head.ID})
+		if err := l.stmts(s.Body); err != nil {
+			return err
+		}
+		l.loops = l.loops[:len(l.loops)-1]
+		l.jump(head)
+		l.cur = exit
+
+	case *ForStmt:
+		// for i = start to end [step n]: the limit is re-evaluated each
+		// iteration and the constant step's sign picks the exit test, as in
+		// generateFor.
+		step := int64(1)
+		if s.Step != nil {
+			v, err := evalConstExpr(s.Step, cg.consts)
+			if err != nil {
+				return fmt.Errorf("for loop 'step' must be a constant: %w", err)
+			}
+			step = v
+		}
+		cg.variables[s.VarName] = &VariableInfo{Name: s.VarName, VarType: typeInt}
+		start, err := l.expr(s.Start)
+		if err != nil {
+			return err
+		}
+		l.emit(IRInst{Op: IRStore, Name: s.VarName, Type: typeInt, Args: []VReg{start}, Pos: s.Position}, false)
+		head, body, next, exit := l.newBlock(), l.newBlock(), l.newBlock(), l.newBlock()
+		l.jump(head)
+		l.cur = head
+		limit, err := l.expr(s.End)
+		if err != nil {
+			return err
+		}
+		i := l.emit(IRInst{Op: IRLoad, Name: s.VarName, Type: typeInt, Pos: s.Position}, true)
+		past := TOKEN_GREATER
+		if step < 0 {
+			past = TOKEN_LESS
+		}
+		done := l.emit(IRInst{Op: IRBinary, Tok: past, Type: typeBool, Args: []VReg{i, limit}, Pos: s.Position}, true)
+		l.terminate(IRTerm{Kind: IRBranch, Cond: done, Then: exit.ID, Else: body.ID})
+		l.cur = body
+		l.loops = append(l.loops, irLoop{brk: exit.ID, cont: next.ID})
+		if err := l.stmts(s.Body); err != nil {
+			return err
+		}
+		l.loops = l.loops[:len(l.loops)-1]
+		l.jump(next)
+		l.cur = next
+		i = l.emit(IRInst{Op: IRLoad, Name: s.VarName, Type: typeInt, Pos: s.Position}, true)
+		n := l.emit(IRInst{Op: IRConst, Imm: step, Type: typeInt, Pos: s.Position}, true)
+		sum := l.emit(IRInst{Op: IRBinary, Tok: TOKEN_PLUS, Type: typeInt, Args: []VReg{i, n}, Pos: s.Position}, true)
+		l.emit(IRInst{Op: IRStore, Name: s.VarName, Type: typeInt, Args: []VReg{sum}, Pos: s.Position}, false)
+		l.jump(head)
+		l.cur = exit
+
+	case *BreakStmt, *ContinueStmt:
+		if len(l.loops) == 0 {
+			return fmt.Errorf("line %d: break or continue outside of a loop", stmt.Pos().Line)
+		}
+		loop := l.loops[len(l.loops)-1]
+		to := loop.brk
+		if _, ok := s.(*ContinueStmt); ok {
+			to = loop.cont
+		}
+		l.terminate(IRTerm{Kind: IRJump, Then: to})
+		l.cur = l.newBlock()
+
+	case *ReturnStmt:
+		var v VReg
+		if s.Value != nil {
+			var err error
+			if v, err = l.expr(s.Value); err != nil {
+				return err
+			}
+		}
+		l.terminate(IRTerm{Kind: IRReturn, Value: v})
+		l.cur = l.newBlock()
+
+	case *ExprStmt:
+		if call, ok := s.Expr.(*CallExpr); ok {
+			_, err := l.call(call, false)
+			return err
+		}
+		_, err := l.expr(s.Expr)
+		return err
+
+	default:
+		return fmt.Errorf("cannot lower statement %T", stmt)
+	}
+	return nil
+}
+
+func (l *irLowerer) expr(expr Expr) (VReg, error) {
+	cg := l.cg
+	pos := expr.Pos()
+	switch e := expr.(type) {
+	case *NumberExpr:
+		typ := IRType(typeInt)
+		if e.IsFixed {
+			typ = typeFixed
+		}
+		return l.emit(IRInst{Op: IRConst, Imm: int64(e.Value), Type: typ, Pos: pos}, true), nil
+
+	case *BoolExpr:
+		var v int64
+		if e.Value {
+			v = 1
+		}
+		return l.emit(IRInst{Op: IRConst, Imm: v, Type: typeBool, Pos: pos}, true), nil
+
+	case *StringExpr:
+		return l.emit(IRInst{Op: IRString, Name: e.Value, Type: typeString, Pos: pos}, true), nil
+
+	case *IdentExpr:
+		if v, ok := cg.variables[e.Name]; ok {
+			return l.emit(IRInst{Op: IRLoad, Name: e.Name, Type: irVarType(v), Pos: pos}, true), nil
+		}
+		if c, ok := cg.consts[e.Name]; ok {
+			return l.emit(IRInst{Op: IRConst, Imm: c, Type: cg.irTypeOf(e), Pos: pos}, true), nil
+		}
+		if g, ok := cg.globals[e.Name]; ok {
+			return l.emit(IRInst{Op: IRLoad, Name: e.Name, Type: irVarType(g), Pos: pos}, true), nil
+		}
+		return l.emit(IRInst{Op: IRSymbol, Name: e.Name, Type: IRTypeUnknown, Pos: pos}, true), nil
+
+	case *UnaryExpr:
+		v, err := l.expr(e.Operand)
+		if err != nil {
+			return 0, err
+		}
+		return l.emit(IRInst{Op: IRUnary, Tok: e.Op, Type: cg.irTypeOf(e), Args: []VReg{v}, Pos: pos}, true), nil
+
+	case *BinaryExpr:
+		// Both sides are always evaluated: and/or do not short-circuit.
+		a, err := l.expr(e.Left)
+		if err != nil {
+			return 0, err
+		}
+		b, err := l.expr(e.Right)
+		if err != nil {
+			return 0, err
+		}
+		return l.emit(IRInst{Op: IRBinary, Tok: e.Op, Type: cg.irTypeOf(e), Args: []VReg{a, b}, Pos: pos}, true), nil
+
+	case *CallExpr:
+		return l.call(e, true)
+
+	case *MemberExpr:
+		if ident, ok := e.Object.(*IdentExpr); ok {
+			if _, isVar := cg.variables[ident.Name]; !isVar {
+				// A namespaced name such as an asset field or module
+				// constant, not a struct field.
+				return l.emit(IRInst{Op: IRSymbol, Name: ident.Name + "." + e.Member, Type: cg.irTypeOf(e), Pos: pos}, true), nil
+			}
+		}
+		obj, err := l.expr(e.Object)
+		if err != nil {
+			return 0, err
+		}
+		return l.emit(IRInst{Op: IRLoadField, Name: e.Member, Type: cg.irTypeOf(e), Args: []VReg{obj}, Pos: pos}, true), nil
+
+	case *IndexExpr:
+		name, ok := e.Array.(*IdentExpr)
+		if !ok {
+			return 0, fmt.Errorf("line %d: cannot lower an index of %T", pos.Line, e.Array)
+		}
+		idx, err := l.expr(e.Index)
+		if err != nil {
+			return 0, err
+		}
+		return l.emit(IRInst{Op: IRLoadIndex, Name: name.Name, Type: cg.irTypeOf(e), Args: []VReg{idx}, Pos: pos}, true), nil
+	}
+	return 0, fmt.Errorf("line %d: cannot lower expression %T", pos.Line, expr)
+}
+
+// call lowers a call to a user function, builtin or struct constructor;
+// value reports whether its result is used.
+func (l *irLowerer) call(call *CallExpr, value bool) (VReg, error) {
+	cg := l.cg
+	name := callFuncName(call)
+	if name == "" {
+		return 0, fmt.Errorf("line %d: cannot lower a call through %T", call.Position.Line, call.Func)
+	}
+	args := make([]VReg, len(call.Args))
+	for i, a := range call.Args {
+		v, err := l.expr(a)
+		if err != nil {
+			return 0, err
+		}
+		args[i] = v
+	}
+	typ := cg.irTypeOf(call)
+	if _, isStruct := cg.structLayoutFor(name); isStruct {
+		typ = IRType("*" + name)
+	} else if fn := cg.findFunction(name); fn != nil {
+		typ = ""
+		if fn.ReturnType != nil {
+			typ = cg.irTypeOfTypeExpr(fn.ReturnType)
+		}
+	}
+	return l.emit(IRInst{Op: IRCall, Name: name, Type: typ, Args: args, ArgNames: call.ArgNames, Pos: call.Position}, value), nil
+}
+
+// irTypeOf is the IR type of an expression's value, from typeOf.
+func (cg *CodeGenerator) irTypeOf(expr Expr) IRType {
+	if t := cg.typeOf(expr); t != typeUnknown {
+		return IRType(t)
+	}
+	return IRTypeUnknown
+}
+
+// irTypeOfTypeExpr is the IR type of a declared type.
+func (cg *CodeGenerator) irTypeOfTypeExpr(t TypeExpr) IRType {
+	if s := cg.structTypeNameFromTypeExpr(t); s != "" {
+		return IRType("*" + s)
+	}
+	if n := intTypeName(t); n != "" {
+		return IRType(n)
+	}
+	if named, ok := t.(*NamedType); ok {
+		return IRType(named.Name)
+	}
+	return IRTypeUnknown
+}
+
+// irVarType is the IR type of a variable: its struct pointer type or its
+// storage type.
+func irVarType(v *VariableInfo) IRType {
+	switch {
+	case v.StructType != "":
+		return IRType("*" + v.StructType)
+	case v.VarType != "" && v.VarType != typeUnknown:
+		return IRType(v.VarType)
+	}
+	return IRTypeUnknown
+}
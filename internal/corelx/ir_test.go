@@ -0,0 +1,61 @@
+package corelx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLowerIRBuildsBasicBlocks(t *testing.T) {
+	src := "function Add(a: int, b: int) -> int\n    return a + b\n\nfunction Start()\n    x := 0\n    while true\n        if x == 3\n            break\n        x = Add(x, 1)\n        wait_vblank()\n"
+	res, err := CompileSource(src, "main.corelx", &CompileOptions{EmitIR: true})
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	funcs := make(map[string]*IRFunction)
+	for _, f := range res.IR {
+		if err := f.Verify(); err != nil {
+			t.Errorf("verify: %v", err)
+		}
+		funcs[f.Name] = f
+	}
+
+	add := funcs["Add"]
+	if add == nil {
+		t.Fatalf("no IR for Add")
+	}
+	text := add.String()
+	for _, want := range []string{"function Add(a: int, b: int): int", "v3: int = v1 + v2", "return v3"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Add IR missing %q:\n%s", want, text)
+		}
+	}
+
+	start := funcs["Start"]
+	if start == nil {
+		t.Fatalf("no IR for Start")
+	}
+	// The loop needs a back edge: some block jumps to a block at or before
+	// itself.
+	backEdge := false
+	for _, b := range start.Blocks {
+		for _, s := range b.Successors() {
+			if s <= b.ID {
+				backEdge = true
+			}
+		}
+	}
+	if !backEdge {
+		t.Errorf("Start IR has no loop back edge:\n%s", start)
+	}
+	if !strings.Contains(start.String(), "call Add(") {
+		t.Errorf("Start IR does not call Add:\n%s", start)
+	}
+
+	res, err = CompileSource(src, "main.corelx", nil)
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	if res.IR != nil {
+		t.Error("IR produced without EmitIR")
+	}
+}
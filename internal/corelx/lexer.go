@@ -46,6 +46,7 @@ const (
 	TOKEN_AT
 	TOKEN_TO
 	TOKEN_STEP
+	TOKEN_EXTERN
 
 	// Operators
 	TOKEN_ASSIGN      // :=
@@ -78,6 +79,7 @@ const (
 	TOKEN_COLON    // :
 	TOKEN_ARROW    // ->
 	TOKEN_DOT      // .
+	TOKEN_HASH     // # (function attributes: #[bank(N)])
 
 	// Comments
 	TOKEN_COMMENT
@@ -271,6 +273,14 @@ func (l *Lexer) scanToken() error {
 		l.emitToken(TOKEN_TILDE, "~", line, column)
 		return nil
 
+	case '#':
+		if l.conditionalLineAt(l.position - 1) {
+			l.skipLine()
+			return nil
+		}
+		l.emitToken(TOKEN_HASH, "#", line, column)
+		return nil
+
 	case '"':
 		return l.scanString(line, column)
 
@@ -289,6 +299,30 @@ func (l *Lexer) scanToken() error {
 	}
 }
 
+// conditionalLineAt reports whether pos is the '#' that starts a
+// conditional compilation directive line (see conditional.go).
+func (l *Lexer) conditionalLineAt(pos int) bool {
+	start := strings.LastIndexByte(l.source[:pos], '\n') + 1
+	if strings.TrimLeft(l.source[start:pos], " \t") != "" {
+		return false
+	}
+	end := strings.IndexByte(l.source[pos:], '\n')
+	if end < 0 {
+		end = len(l.source)
+	} else {
+		end += pos
+	}
+	_, _, ok := conditionalDirective(l.source[start:end])
+	return ok
+}
+
+// skipLine advances to the end of the current line, leaving the newline.
+func (l *Lexer) skipLine() {
+	for !l.isAtEnd() && l.peek() != '\n' {
+		l.advance()
+	}
+}
+
 func (l *Lexer) scanComment(line, column int) error {
 	// Skip until end of line
 	for !l.isAtEnd() && l.peek() != '\n' {
@@ -347,6 +381,12 @@ func (l *Lexer) scanNumber(line, column int) error {
 				l.advance()
 			}
 		}
+		// Optional `fx` suffix marks the literal as 8.8 fixed even without
+		// a fractional part (`2fx`, `1.5fx`).
+		if l.peek() == 'f' && l.peekNext() == 'x' && !l.isIdentCharAt(l.position+2) {
+			l.advance()
+			l.advance()
+		}
 	}
 
 	literal := l.source[start:l.position]
@@ -390,6 +430,7 @@ func (l *Lexer) identifierType(literal string) TokenType {
 		"at":       TOKEN_AT,
 		"to":       TOKEN_TO,
 		"step":     TOKEN_STEP,
+		"extern":   TOKEN_EXTERN,
 	}
 	if tokenType, ok := keywords[literal]; ok {
 		return tokenType
@@ -415,6 +456,11 @@ func (l *Lexer) handleIndentation() {
 			indent++
 			hasTabs = true
 			l.advance()
+		} else if r == '#' && l.conditionalLineAt(l.position) {
+			// #if/#else/#end lines (see conditional.go) never affect
+			// indentation: skip them like empty lines.
+			l.skipLine()
+			indent = 0
 		} else if r == '\n' {
 			// Empty line, reset and continue
 			indent = 0
@@ -539,6 +585,15 @@ func (l *Lexer) error(line, column int, message string) error {
 }
 
 // peekNext returns the rune after the current position without advancing.
+// isIdentCharAt reports whether the byte at i continues an identifier.
+func (l *Lexer) isIdentCharAt(i int) bool {
+	if i >= len(l.source) {
+		return false
+	}
+	c := rune(l.source[i])
+	return unicode.IsLetter(c) || unicode.IsDigit(c) || c == '_'
+}
+
 func (l *Lexer) peekNext() rune {
 	if l.isAtEnd() {
 		return 0
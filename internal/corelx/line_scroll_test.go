@@ -0,0 +1,49 @@
+package corelx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBGSetLineScroll(t *testing.T) {
+	source := `var wave: int[200]
+var i: int = 0
+
+function Start()
+    i = 0
+    while i < 200
+        wave[i] = i & 7
+        i = i + 1
+    bg.set_line_scroll(wave)
+    while true
+        wait_vblank()
+`
+	emu, result := compileAndBoot(t, source, 20000)
+	var table uint16
+	for _, e := range result.MemoryMap {
+		if e.Name == "wave" {
+			table = e.Address
+		}
+	}
+	if got := emu.PPU.LineScrollTable; got == 0 || got != table {
+		t.Fatalf("BG0_LINE_SCROLL = 0x%04X, want wave at 0x%04X", got, table)
+	}
+	if got := read16(emu, table+2*13); got != 5 {
+		t.Errorf("wave[13] = %d, want 5", got)
+	}
+
+	emu, _ = compileAndBoot(t, "function Start()\n    bg.clear_line_scroll()\n    while true\n        wait_vblank()\n", 200)
+	if emu.PPU.LineScrollTable != 0 {
+		t.Errorf("bg.clear_line_scroll left BG0_LINE_SCROLL = 0x%04X", emu.PPU.LineScrollTable)
+	}
+}
+
+func TestBGSetLineScrollRejectsBadTables(t *testing.T) {
+	for _, decl := range []string{"var wave: int[100]", "var wave: u8[200]", "var wave: int = 0"} {
+		source := decl + "\n\nfunction Start()\n    bg.set_line_scroll(wave)\n"
+		_, err := CompileSource(source, "line_scroll.corelx", nil)
+		if err == nil || !strings.Contains(err.Error(), "bg.set_line_scroll") {
+			t.Errorf("%s: err = %v, want a bg.set_line_scroll error", decl, err)
+		}
+	}
+}
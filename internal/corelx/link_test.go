@@ -0,0 +1,116 @@
+package corelx
+
+import (
+	"strings"
+	"testing"
+
+	ncasm "nitro-core-dx/internal/asm"
+	"nitro-core-dx/internal/emulator"
+	"nitro-core-dx/internal/link"
+)
+
+const externMainSource = `var result: int = 0
+extern function triple(x: int) -> int
+
+function Start()
+    result = triple(14)
+    while true
+        wait_vblank()
+`
+
+const externAsmSource = `
+.global triple
+triple:
+    MOV R1, R0
+    ADD R0, R1
+    ADD R0, R1
+    RET
+`
+
+// TestExternFunctionLinksAgainstAssembly compiles a CoreLX unit that calls
+// an assembly routine, links the two objects, and runs the result.
+func TestExternFunctionLinksAgainstAssembly(t *testing.T) {
+	res, err := CompileSource(externMainSource, "main.corelx", &CompileOptions{EmitObject: true})
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	if res.Object == nil {
+		t.Fatalf("expected an object in EmitObject mode")
+	}
+	if res.ROMBytes != nil {
+		t.Fatalf("object mode should not produce ROM bytes")
+	}
+	if res.Object.FixedBank != 1 {
+		t.Fatalf("CoreLX object FixedBank = %d, want 1", res.Object.FixedBank)
+	}
+	var resultAddr uint16
+	for _, e := range res.MemoryMap {
+		if e.Name == "result" {
+			resultAddr = e.Address
+		}
+	}
+	if resultAddr == 0 {
+		t.Fatalf("global 'result' missing from memory map")
+	}
+
+	asmObj, err := ncasm.AssembleObject(externAsmSource, "triple.asm")
+	if err != nil {
+		t.Fatalf("assemble: %v", err)
+	}
+	linked, err := link.Link([]*link.Object{res.Object, asmObj}, nil)
+	if err != nil {
+		t.Fatalf("link: %v", err)
+	}
+	if _, ok := findMapSymbol(linked.Map, "Start"); !ok {
+		t.Fatalf("link map missing CoreLX function Start")
+	}
+
+	emu := emulator.NewEmulator()
+	if err := emu.LoadROM(linked.ROMBytes); err != nil {
+		t.Fatalf("load ROM: %v", err)
+	}
+	for i := 0; i < 800; i++ {
+		if err := emu.CPU.ExecuteInstruction(); err != nil {
+			t.Fatalf("CPU step %d: %v", i, err)
+		}
+	}
+	if got := read16(emu, resultAddr); got != 42 {
+		t.Fatalf("triple(14) via linked asm = %d, want 42", got)
+	}
+}
+
+func TestExternFunctionWithoutLinkingIsAnError(t *testing.T) {
+	_, err := CompileSource(externMainSource, "main.corelx", nil)
+	if err == nil {
+		t.Fatalf("expected an error calling an extern function in a plain ROM build")
+	}
+	if !strings.Contains(err.Error(), "extern function triple") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestExternStartIsRejected(t *testing.T) {
+	_, err := CompileSource("extern function Start()\n", "main.corelx", &CompileOptions{EmitObject: true})
+	de, ok := err.(*DiagnosticsError)
+	if !ok {
+		t.Fatalf("expected diagnostics error, got %v", err)
+	}
+	found := false
+	for _, d := range de.Diagnostics {
+		if d.Code == "E_EXTERN_ENTRY" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected E_EXTERN_ENTRY, got %+v", de.Diagnostics)
+	}
+}
+
+func findMapSymbol(m *link.Map, name string) (link.MapSymbol, bool) {
+	for _, s := range m.Symbols {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return link.MapSymbol{}, false
+}
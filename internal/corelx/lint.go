@@ -0,0 +1,300 @@
+package corelx
+
+import (
+	"fmt"
+	"strings"
+
+	"nitro-core-dx/internal/hwdef"
+	"nitro-core-dx/internal/rom"
+)
+
+// Beginner diagnostics: well-known hardware mistakes that compile cleanly
+// but misbehave on the machine. They are warnings or info, never errors,
+// and each carries a note pointing at the manual section that explains it.
+const lintDocNote = "See docs/CORELX.md, \"Beginner Diagnostics\"."
+
+// lintProgram runs the beginner checks over every function.
+func (a *SemanticAnalyzer) lintProgram() {
+	freqSet := make(map[int64]bool)
+	for _, fn := range a.program.Functions {
+		for _, call := range stmtCalls(fn.Body) {
+			if callFuncName(call) == "apu.set_channel_freq" && len(call.Args) > 0 {
+				if ch, ok := a.lintConst(call.Args[0]); ok {
+					freqSet[ch] = true
+				}
+			}
+		}
+	}
+	for _, fn := range a.program.Functions {
+		a.lintFrameLoops(fn.Body, make(map[*CallExpr]bool))
+		a.lintCGRAMWrites(fn.Body, false)
+		a.lintNoteOn(fn, freqSet)
+	}
+}
+
+func (a *SemanticAnalyzer) addLint(pos Position, severity DiagnosticSeverity, code, message string) {
+	a.diagnostics = append(a.diagnostics, Diagnostic{
+		Category: CategoryValidationError,
+		Code:     code,
+		Message:  message,
+		Line:     pos.Line,
+		Column:   pos.Column,
+		Severity: severity,
+		Stage:    StageSemantic,
+		Notes:    []string{lintDocNote},
+	})
+}
+
+// lintFrameLoops warns about an oam.* call in a frame loop (a loop that
+// calls wait_vblank()) that runs before the loop's first wait_vblank():
+// it lands while the PPU is drawing the previous frame's sprites. It also
+// warns about a gfx.load_tiles_compressed in a frame loop whose decoder
+// takes longer than VBlank.
+func (a *SemanticAnalyzer) lintFrameLoops(stmts []Stmt, reported map[*CallExpr]bool) {
+	for _, stmt := range stmts {
+		var body []Stmt
+		switch s := stmt.(type) {
+		case *WhileStmt:
+			body = s.Body
+		case *ForStmt:
+			body = s.Body
+		case *IfStmt:
+			a.lintFrameLoops(s.Then, reported)
+			for _, ei := range s.ElseIf {
+				a.lintFrameLoops(ei.Body, reported)
+			}
+			a.lintFrameLoops(s.Else, reported)
+			continue
+		default:
+			continue
+		}
+		calls := stmtCalls(body)
+		if callsWaitVBlank(calls) {
+			for _, call := range calls {
+				if callFuncName(call) == "gfx.load_tiles_compressed" && !reported[call] {
+					reported[call] = true
+					a.lintTilesLZBudget(call)
+				}
+			}
+			for _, call := range calls {
+				name := callFuncName(call)
+				if name == "wait_vblank" {
+					break
+				}
+				if strings.HasPrefix(name, "oam.") && !reported[call] {
+					reported[call] = true
+					a.addLint(call.Position, SeverityWarning, "W_OAM_OUTSIDE_VBLANK", fmt.Sprintf("%s() runs before this loop's wait_vblank(): sprite writes outside VBlank change sprites mid-frame; move it after wait_vblank()", name))
+					break
+				}
+			}
+		}
+		a.lintFrameLoops(body, reported)
+	}
+}
+
+// lintTilesLZBudget warns when the lz asset a gfx.load_tiles_compressed
+// call expands takes the decoder more cycles than VBlank lasts.
+func (a *SemanticAnalyzer) lintTilesLZBudget(call *CallExpr) {
+	if len(call.Args) == 0 {
+		return
+	}
+	ident, ok := call.Args[0].(*IdentExpr)
+	if !ok {
+		return
+	}
+	for _, decl := range a.program.Assets {
+		if "ASSET_"+decl.Name != ident.Name || decl.Compression != "lz" {
+			continue
+		}
+		ir, diag := normalizeAssetDecl(decl, "")
+		if diag != nil {
+			return
+		}
+		packed, err := packTilesLZ(decl.Type, ir.Data)
+		if err != nil {
+			return
+		}
+		budget := vblankCycles
+		if a.program.Timing == rom.TimingFlag50Hz {
+			budget = vblankCycles50Hz
+		}
+		if cycles := tilesLZCycles(packed); cycles > budget {
+			a.addLint(call.Position, SeverityWarning, "W_TILES_LZ_OVER_VBLANK", fmt.Sprintf("gfx.load_tiles_compressed(%s) takes about %d CPU cycles, more than VBlank's %d: in a frame loop the load runs into the visible frame; load it once before the loop, or split the asset", ident.Name, cycles, budget))
+		}
+		return
+	}
+}
+
+func callsWaitVBlank(calls []*CallExpr) bool {
+	for _, call := range calls {
+		if callFuncName(call) == "wait_vblank" {
+			return true
+		}
+	}
+	return false
+}
+
+// lintCGRAMWrites warns about an odd run of consecutive CGRAM_DATA writes:
+// a color is two writes (low byte, then high byte), so the last one leaves
+// the port half-way through a color. Loop bodies are skipped, since a
+// loop that writes one byte per iteration is the usual palette copy.
+func (a *SemanticAnalyzer) lintCGRAMWrites(stmts []Stmt, inLoop bool) {
+	run := 0
+	var last Position
+	flush := func() {
+		if run%2 == 1 && !inLoop {
+			a.addLint(last, SeverityWarning, "W_CGRAM_SINGLE_BYTE", "CGRAM_DATA is written an odd number of times: each color needs two writes (low byte, then high byte), or the next write completes this color")
+		}
+		run = 0
+	}
+	for _, stmt := range stmts {
+		if pos, ok := a.cgramWrite(stmt); ok {
+			run++
+			last = pos
+			continue
+		}
+		flush()
+		switch s := stmt.(type) {
+		case *WhileStmt:
+			a.lintCGRAMWrites(s.Body, true)
+		case *ForStmt:
+			a.lintCGRAMWrites(s.Body, true)
+		case *IfStmt:
+			a.lintCGRAMWrites(s.Then, inLoop)
+			for _, ei := range s.ElseIf {
+				a.lintCGRAMWrites(ei.Body, inLoop)
+			}
+			a.lintCGRAMWrites(s.Else, inLoop)
+		}
+	}
+	flush()
+}
+
+// cgramWrite reports whether stmt is `mem.write(CGRAM_DATA, v)` (or the
+// port's address), a one-byte CGRAM write.
+func (a *SemanticAnalyzer) cgramWrite(stmt Stmt) (Position, bool) {
+	s, ok := stmt.(*ExprStmt)
+	if !ok {
+		return Position{}, false
+	}
+	call, ok := s.Expr.(*CallExpr)
+	if !ok || callFuncName(call) != "mem.write" || len(call.Args) == 0 {
+		return Position{}, false
+	}
+	if id, ok := call.Args[0].(*IdentExpr); ok && id.Name == "CGRAM_DATA" && !a.programDeclares("CGRAM_DATA") {
+		return call.Position, true
+	}
+	if addr, ok := a.lintConst(call.Args[0]); ok && addr == hwdef.CGRAM_DATA {
+		return call.Position, true
+	}
+	return Position{}, false
+}
+
+func (a *SemanticAnalyzer) programDeclares(name string) bool {
+	for _, g := range a.program.Globals {
+		if g.Name == name {
+			return true
+		}
+	}
+	for _, c := range a.program.Consts {
+		if c.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// lintNoteOn flags apu.note_on(ch) for a channel whose frequency is set
+// later in the same function (the note starts at the old frequency, an
+// audible blip) or nowhere in the program.
+func (a *SemanticAnalyzer) lintNoteOn(fn *FunctionDecl, freqSet map[int64]bool) {
+	calls := stmtCalls(fn.Body)
+	for i, call := range calls {
+		if callFuncName(call) != "apu.note_on" || len(call.Args) == 0 {
+			continue
+		}
+		ch, ok := a.lintConst(call.Args[0])
+		if !ok {
+			continue
+		}
+		if !freqSet[ch] {
+			a.addLint(call.Position, SeverityWarning, "W_APU_NO_FREQ", fmt.Sprintf("apu.note_on(%d) enables channel %d, but nothing calls apu.set_channel_freq(%d, ...): it plays at whatever frequency the channel was left at", ch, ch, ch))
+			continue
+		}
+		for _, later := range calls[i+1:] {
+			if callFuncName(later) != "apu.set_channel_freq" || len(later.Args) == 0 {
+				continue
+			}
+			if lch, ok := a.lintConst(later.Args[0]); ok && lch == ch {
+				a.addLint(call.Position, SeverityInfo, "I_APU_NOTE_BEFORE_FREQ", fmt.Sprintf("apu.note_on(%d) comes before apu.set_channel_freq(%d, ...): the note starts at the old frequency for a moment; set the frequency first", ch, ch))
+				break
+			}
+		}
+	}
+}
+
+// lintConst evaluates a constant expression, e.g. a channel number.
+func (a *SemanticAnalyzer) lintConst(expr Expr) (int64, bool) {
+	v, err := evalConstExpr(expr, a.constVals)
+	return v, err == nil
+}
+
+// stmtCalls lists the calls in stmts in source order, descending into
+// nested blocks and call arguments.
+func stmtCalls(stmts []Stmt) []*CallExpr {
+	var calls []*CallExpr
+	var expr func(Expr)
+	expr = func(e Expr) {
+		switch e := e.(type) {
+		case *CallExpr:
+			for _, arg := range e.Args {
+				expr(arg)
+			}
+			calls = append(calls, e)
+		case *BinaryExpr:
+			expr(e.Left)
+			expr(e.Right)
+		case *UnaryExpr:
+			expr(e.Operand)
+		case *MemberExpr:
+			expr(e.Object)
+		case *IndexExpr:
+			expr(e.Array)
+			expr(e.Index)
+		}
+	}
+	var walk func([]Stmt)
+	walk = func(stmts []Stmt) {
+		for _, stmt := range stmts {
+			switch s := stmt.(type) {
+			case *VarDeclStmt:
+				expr(s.Value)
+			case *AssignStmt:
+				expr(s.Target)
+				expr(s.Value)
+			case *ExprStmt:
+				expr(s.Expr)
+			case *ReturnStmt:
+				expr(s.Value)
+			case *IfStmt:
+				expr(s.Condition)
+				walk(s.Then)
+				for _, ei := range s.ElseIf {
+					expr(ei.Condition)
+					walk(ei.Body)
+				}
+				walk(s.Else)
+			case *WhileStmt:
+				expr(s.Condition)
+				walk(s.Body)
+			case *ForStmt:
+				expr(s.Start)
+				expr(s.End)
+				expr(s.Step)
+				walk(s.Body)
+			}
+		}
+	}
+	walk(stmts)
+	return calls
+}
@@ -0,0 +1,122 @@
+package corelx
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// lintCodes compiles source and returns "CODE@line" for each beginner
+// diagnostic, in order.
+func lintCodes(t *testing.T, source string) []string {
+	t.Helper()
+	result, _ := CompileSource(source, "lint.corelx", nil)
+	var got []string
+	for _, d := range result.Diagnostics {
+		switch d.Code {
+		case "W_OAM_OUTSIDE_VBLANK", "W_CGRAM_SINGLE_BYTE", "W_APU_NO_FREQ", "I_APU_NOTE_BEFORE_FREQ", "W_TILES_LZ_OVER_VBLANK":
+			if len(d.Notes) == 0 || !strings.Contains(d.Notes[0], "Beginner Diagnostics") {
+				t.Errorf("%s has no doc note: %v", d.Code, d.Notes)
+			}
+			got = append(got, fmt.Sprintf("%s@%d", d.Code, d.Line))
+		}
+	}
+	return got
+}
+
+func TestLintOAMOutsideVBlank(t *testing.T) {
+	bad := `function Start()
+    box := Sprite()
+    while true
+        oam.write(0, box)
+        oam.flush()
+        wait_vblank()
+`
+	if got := strings.Join(lintCodes(t, bad), ","); got != "W_OAM_OUTSIDE_VBLANK@4" {
+		t.Errorf("OAM before wait_vblank: got %q", got)
+	}
+
+	good := `function Start()
+    box := Sprite()
+    for i = 0 to 3
+        oam.clear_sprite(i)
+    while true
+        wait_vblank()
+        oam.write(0, box)
+        oam.flush()
+`
+	if got := lintCodes(t, good); len(got) != 0 {
+		t.Errorf("OAM after wait_vblank and in a setup loop: got %v", got)
+	}
+}
+
+func TestLintCGRAMSingleByte(t *testing.T) {
+	source := `function Start()
+    mem.write(CGRAM_ADDR, 2)
+    mem.write(CGRAM_DATA, 0x1F)
+    mem.write(CGRAM_DATA, 0x00)
+    mem.write(0x8013, 0xE0)
+    mem.write(CGRAM_ADDR, 8)
+    mem.write(CGRAM_DATA, 0xFF)
+    mem.write(0x8013, 0x7F)
+    for i = 0 to 7
+        mem.write(CGRAM_DATA, 0)
+    while true
+        wait_vblank()
+`
+	if got := strings.Join(lintCodes(t, source), ","); got != "W_CGRAM_SINGLE_BYTE@5" {
+		t.Errorf("got %q, want the third write of the first run only", got)
+	}
+}
+
+func TestLintNoteOnBeforeFrequency(t *testing.T) {
+	source := `const LEAD = 1
+
+function Start()
+    apu.enable()
+    apu.set_channel_freq(0, 440)
+    apu.note_on(0)
+    apu.note_on(LEAD)
+    apu.set_channel_freq(LEAD, 220)
+    apu.note_on(2)
+    while true
+        wait_vblank()
+`
+	if got := strings.Join(lintCodes(t, source), ","); got != "I_APU_NOTE_BEFORE_FREQ@7,W_APU_NO_FREQ@9" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestLintTilesLZOverVBlank(t *testing.T) {
+	// Nine tiles of noise pack to literals only, which the decoder cannot
+	// expand within VBlank; one flat tile is mostly a single copy.
+	noise := make([]byte, 9*32)
+	seed := uint32(3)
+	for i := range noise {
+		seed = seed*1103515245 + 12345
+		noise[i] = byte(seed >> 16)
+	}
+	assets := tileAssetSource("Big", "tiles8", "lz ", noise) + "\n" +
+		tileAssetSource("Small", "tiles8", "lz ", bytes.Repeat([]byte{0x55}, 32)) + "\n\n"
+
+	bad := assets + `function Start()
+    while true
+        wait_vblank()
+        gfx.load_tiles_compressed(ASSET_Big, 0)
+`
+	line := strings.Count(assets, "\n") + 4
+	if got := strings.Join(lintCodes(t, bad), ","); got != fmt.Sprintf("W_TILES_LZ_OVER_VBLANK@%d", line) {
+		t.Errorf("large lz load in a frame loop: got %q, want line %d", got, line)
+	}
+
+	good := assets + `function Start()
+    gfx.load_tiles_compressed(ASSET_Big, 0)
+    while true
+        wait_vblank()
+        gfx.load_tiles_compressed(ASSET_Small, 9)
+`
+	if got := lintCodes(t, good); len(got) != 0 {
+		t.Errorf("large lz load before the loop, small one in it: got %v", got)
+	}
+}
@@ -0,0 +1,125 @@
+package corelx
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"nitro-core-dx/internal/rom"
+)
+
+// SourceLine is where the code generated for one line of CoreLX source
+// starts: it runs from Bank:Offset to the next SourceLine or function.
+type SourceLine struct {
+	Function string
+	Line     int
+	Bank     uint8
+	Offset   uint16
+}
+
+// markSourceLine records that the code emitted next comes from line of the
+// function being generated. Synthesized statements (line 0) are skipped.
+func (cg *CodeGenerator) markSourceLine(line int) {
+	if line <= 0 || cg.currentFunc == nil {
+		return
+	}
+	cg.sourceLines = append(cg.sourceLines, SourceLine{
+		Function: cg.currentFunc.Name,
+		Line:     line,
+		Bank:     cg.currentBank,
+		Offset:   uint16(rom.ROMBankOffsetBase + cg.builder.GetCodeLength()*2),
+	})
+}
+
+// SourceLines lists the start of every statement's code in emission order.
+func (cg *CodeGenerator) SourceLines() []SourceLine { return cg.sourceLines }
+
+// codeEnd is the offset just past the last code word emitted into bank.
+func (cg *CodeGenerator) codeEnd(bank uint8) uint16 {
+	if cg.bankedBuilder != nil {
+		return uint16(rom.ROMBankOffsetBase + cg.bankedBuilder.GetCodeLength(bank)*2)
+	}
+	return uint16(rom.ROMBankOffsetBase + cg.builder.GetCodeLength()*2)
+}
+
+// formatListing renders the interleaved source listing emitted with
+// --emit-listing: every function in address order, each line of source
+// followed by the instructions generated for it. Only functions declared
+// in source (named in own) get source lines; the boot sequence, module
+// functions and compiler helpers are listed as bare code.
+func formatListing(sourcePath, source string, romBytes []byte, cg *CodeGenerator, own map[string]bool) ([]byte, error) {
+	banks, err := rom.DisassembleROM(romBytes)
+	if err != nil {
+		return nil, err
+	}
+	srcLines := strings.Split(source, "\n")
+	funcs := append([]FunctionSymbol(nil), cg.FunctionSymbols()...)
+	sort.SliceStable(funcs, func(i, j int) bool {
+		if funcs[i].Bank != funcs[j].Bank {
+			return funcs[i].Bank < funcs[j].Bank
+		}
+		return funcs[i].Offset < funcs[j].Offset
+	})
+	marks := make(map[string][]SourceLine)
+	for _, sl := range cg.SourceLines() {
+		if own[sl.Function] {
+			marks[sl.Function] = append(marks[sl.Function], sl)
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "; CoreLX listing of %s\n", filepath.Base(sourcePath))
+	b.WriteString("; Each source line is followed by the code generated for it.\n")
+	for i, fn := range funcs {
+		end := cg.codeEnd(fn.Bank)
+		if i+1 < len(funcs) && funcs[i+1].Bank == fn.Bank {
+			end = funcs[i+1].Offset
+		}
+		fmt.Fprintf(&b, "\n%s:  ; %02X:%04X", fn.Name, fn.Bank, fn.Offset)
+		if !own[fn.Name] {
+			b.WriteString("  (no source: compiler-generated or from a module)")
+		}
+		b.WriteString("\n")
+
+		pending := marks[fn.Name]
+		for _, inst := range banks[fn.Bank] {
+			if inst.Offset < fn.Offset {
+				continue
+			}
+			if inst.Offset >= end {
+				break
+			}
+			for len(pending) > 0 && pending[0].Offset <= inst.Offset {
+				b.WriteString(listingSourceLine(srcLines, pending[0].Line))
+				pending = pending[1:]
+			}
+			b.WriteString(listingInstruction(inst))
+		}
+		// Trailing statements that generated no code still appear.
+		for _, sl := range pending {
+			b.WriteString(listingSourceLine(srcLines, sl.Line))
+		}
+	}
+	return []byte(b.String()), nil
+}
+
+func listingSourceLine(srcLines []string, line int) string {
+	text := ""
+	if line-1 < len(srcLines) {
+		text = strings.TrimRight(srcLines[line-1], " \t\r")
+	}
+	return fmt.Sprintf("%5d | %s\n", line, text)
+}
+
+func listingInstruction(inst rom.Instruction) string {
+	words := make([]string, len(inst.Words))
+	for i, w := range inst.Words {
+		words[i] = fmt.Sprintf("%04X", w)
+	}
+	line := fmt.Sprintf("        %02X:%04X  %-10s %s", inst.Bank, inst.Offset, strings.Join(words, " "), inst.Text)
+	if inst.HasTarget {
+		line += fmt.Sprintf("  ; -> %04X", inst.Target)
+	}
+	return line + "\n"
+}
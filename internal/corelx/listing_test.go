@@ -0,0 +1,43 @@
+package corelx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEmitListingInterleavesSource(t *testing.T) {
+	src := "function Start()\n    x := 1\n    while true\n        x = x + 1\n        wait_vblank()\n"
+	res, err := CompileSource(src, "main.corelx", &CompileOptions{EmitListing: true})
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	listing := string(res.Listing)
+	lines := strings.Split(listing, "\n")
+
+	// Every source line of Start() appears once, in order, each followed by
+	// an instruction line.
+	at := 0
+	for n, want := range strings.Split(strings.TrimRight(src, "\n"), "\n") {
+		row := listingSourceLine(strings.Split(src, "\n"), n+1)
+		for at < len(lines) && lines[at]+"\n" != row {
+			at++
+		}
+		if at == len(lines) {
+			t.Fatalf("source line %d %q missing or out of order in listing:\n%s", n+1, want, listing)
+		}
+		if n > 0 && (at+1 >= len(lines) || !strings.HasPrefix(lines[at+1], "        01:")) {
+			t.Errorf("source line %d is not followed by its code: %q", n+1, lines[at+1])
+		}
+	}
+	if !strings.Contains(listing, "__Boot:  ; 01:8000  (no source") {
+		t.Errorf("listing does not mark the generated __Boot:\n%s", listing)
+	}
+
+	res, err = CompileSource(src, "main.corelx", nil)
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	if res.Listing != nil {
+		t.Error("listing produced without EmitListing")
+	}
+}
@@ -0,0 +1,180 @@
+package corelx
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Live asset memories (LivePiece.Space).
+const (
+	LiveVRAM  = "vram"
+	LiveCGRAM = "cgram"
+)
+
+// LiveAsset is a file-backed asset as a running ROM holds it once uploaded:
+// the bytes each piece puts into VRAM or CGRAM. Tools use it to patch an
+// edited asset into a running emulator without rebuilding the ROM (the Dev
+// Kit's asset hot-reload).
+type LiveAsset struct {
+	Name string
+	// File is the asset's own file: the manifest record's path or the
+	// image's .cxasset.
+	File   string
+	Pieces []LivePiece
+}
+
+// LivePiece is one upload of an asset. Addr is its fixed destination, or -1
+// when the ROM's code chooses it (gfx.load_tiles and friends); such a piece
+// is found by looking for its previous bytes.
+type LivePiece struct {
+	Space string
+	Addr  int
+	Data  []byte
+}
+
+// LiveAssets rebuilds the file-backed assets of the project at sourcePath:
+// tile, tilemap and palette records in its corelx.assets.json and the 8bpp
+// background images its source declares. With files, only the assets built
+// from those files are rebuilt. Inline assets, and kinds that do not land
+// in VRAM or CGRAM, are left out.
+func LiveAssets(source, sourcePath string, opts *CompileOptions, files ...string) ([]LiveAsset, error) {
+	cfg := defaultCompileOptions()
+	if opts != nil {
+		mergeCompileOptions(&cfg, *opts)
+	}
+	wanted := func(path string) bool {
+		if len(files) == 0 {
+			return true
+		}
+		for _, f := range files {
+			if filepath.Clean(f) == filepath.Clean(path) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var out []LiveAsset
+	decls, _, diags := loadProjectAssets(sourcePath, cfg)
+	if HasErrors(diags) {
+		return nil, &DiagnosticsError{Diagnostics: diags}
+	}
+	manifestPath := projectAssetManifestPath(sourcePath, cfg)
+	records, err := projectAssetRecordFiles(manifestPath)
+	if err != nil {
+		return nil, err
+	}
+	for _, decl := range decls {
+		file := records[decl.Name]
+		if file == "" || !wanted(file) {
+			continue
+		}
+		space := LiveVRAM
+		switch sectionForAssetType(decl.Type) {
+		case "gfx_tiles", "tilemaps":
+		case "palettes":
+			space = LiveCGRAM
+		default:
+			continue
+		}
+		ir, diag := normalizeAssetDecl(decl, manifestPath)
+		if diag != nil {
+			return nil, &DiagnosticsError{Diagnostics: []Diagnostic{*diag}}
+		}
+		out = append(out, LiveAsset{Name: decl.Name, File: file, Pieces: []LivePiece{{Space: space, Addr: -1, Data: ir.Data}}})
+	}
+
+	program, err := parseLiveSource(source, sourcePath, cfg)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range program.Assets {
+		if a.Type != "image" || a.FilePath == "" || a.FilePath == bootLogoSentinelPath {
+			continue
+		}
+		path := a.FilePath
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(sourcePath), path)
+		}
+		if !wanted(path) {
+			continue
+		}
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("image asset %s: %w", a.Name, err)
+		}
+		img, err := parseCxAsset(a.Name, string(raw))
+		if err != nil {
+			return nil, fmt.Errorf("image asset %s (%s): %w", a.Name, a.FilePath, err)
+		}
+		if !img.isBG8() {
+			continue
+		}
+		// The layout emitLoadBG8Image uploads.
+		tileBytes := img.Tiles * 64
+		live := LiveAsset{Name: a.Name, File: path, Pieces: []LivePiece{
+			{Space: LiveVRAM, Addr: 0, Data: img.Bitmap[:tileBytes]},
+			{Space: LiveVRAM, Addr: int(img.TilemapBase), Data: img.Bitmap[tileBytes:]},
+		}}
+		if img.Kind == "bg8_indexed" {
+			pal := make([]byte, 0, len(img.Palette)*2)
+			for _, c := range img.Palette {
+				pal = append(pal, byte(c), byte(c>>8))
+			}
+			live.Pieces = append(live.Pieces, LivePiece{Space: LiveCGRAM, Addr: 0, Data: pal})
+		}
+		out = append(out, live)
+	}
+	return out, nil
+}
+
+// projectAssetRecordFiles maps each manifest record with a path to its
+// absolute file path.
+func projectAssetRecordFiles(manifestPath string) (map[string]string, error) {
+	out := map[string]string{}
+	if manifestPath == "" {
+		return out, nil
+	}
+	data, err := os.ReadFile(manifestPath)
+	if os.IsNotExist(err) {
+		return out, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var m ProjectAssetManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	for _, rec := range m.Assets {
+		p := strings.TrimSpace(rec.Path)
+		if p == "" {
+			continue
+		}
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(filepath.Dir(manifestPath), p)
+		}
+		out[strings.TrimSpace(rec.Name)] = p
+	}
+	return out, nil
+}
+
+// parseLiveSource parses source far enough to read its asset declarations.
+func parseLiveSource(source, sourcePath string, cfg CompileOptions) (*Program, error) {
+	active, diags := applyConditionals(source, sourcePath, cfg.Defines)
+	if len(diags) > 0 {
+		return nil, &DiagnosticsError{Diagnostics: diags}
+	}
+	tokens, err := NewLexer(active).Tokenize()
+	if err != nil {
+		return nil, err
+	}
+	program, err := NewParser(tokens).Parse()
+	if err != nil {
+		return nil, err
+	}
+	return program, nil
+}
@@ -0,0 +1,56 @@
+package corelx
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"nitro-core-dx/internal/emulator"
+	"nitro-core-dx/internal/ppu"
+)
+
+func TestLiveAssetsBG8ImageMatchesItsUpload(t *testing.T) {
+	pic := image.NewRGBA(image.Rect(0, 0, 64, 16))
+	for x := 0; x < 64; x++ {
+		for y := 0; y < 16; y++ {
+			pic.SetRGBA(x, y, color.RGBA{R: uint8(x * 4), G: uint8(y * 16), A: 255})
+		}
+	}
+	img, err := emulator.BuildBG8ImageFromImage(pic, ppu.BGFormatIndexed8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	imgPath := filepath.Join(dir, "title.cxasset")
+	if err := os.WriteFile(imgPath, []byte(img.CxAsset("Title", "")), 0644); err != nil {
+		t.Fatal(err)
+	}
+	src := "asset Title: image \"title.cxasset\"\n\nfunction Start()\n    bg.load_image(Title, 1)\n"
+	srcPath := filepath.Join(dir, "main.corelx")
+
+	live, err := LiveAssets(src, srcPath, nil, imgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(live) != 1 || live[0].Name != "Title" || live[0].File != imgPath || len(live[0].Pieces) != 3 {
+		t.Fatalf("LiveAssets = %+v, want Title's tiles, tilemap and palette", live)
+	}
+	tiles, tilemap, pal := live[0].Pieces[0], live[0].Pieces[1], live[0].Pieces[2]
+	if tiles.Space != LiveVRAM || tiles.Addr != 0 || !bytes.Equal(tiles.Data, img.Tiles) {
+		t.Errorf("tiles piece at %s 0x%04X, %d bytes; want VRAM 0x0000, %d bytes", tiles.Space, tiles.Addr, len(tiles.Data), len(img.Tiles))
+	}
+	if tilemap.Space != LiveVRAM || tilemap.Addr != int(img.TilemapBase) || !bytes.Equal(tilemap.Data, img.Tilemap) {
+		t.Errorf("tilemap piece at %s 0x%04X; want VRAM 0x%04X", tilemap.Space, tilemap.Addr, img.TilemapBase)
+	}
+	if pal.Space != LiveCGRAM || pal.Addr != 0 || len(pal.Data) != len(img.Palette)*2 {
+		t.Errorf("palette piece at %s 0x%04X, %d bytes; want CGRAM 0x0000, %d bytes", pal.Space, pal.Addr, len(pal.Data), len(img.Palette)*2)
+	}
+
+	other, err := LiveAssets(src, srcPath, nil, filepath.Join(dir, "other.cxasset"))
+	if err != nil || len(other) != 0 {
+		t.Errorf("LiveAssets for an unrelated file = %+v, %v; want none", other, err)
+	}
+}
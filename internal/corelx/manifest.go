@@ -10,6 +10,30 @@ type BuildManifest struct {
 	PlannedROMSizeBytes uint32             `json:"planned_rom_size_bytes"` // manifest/planned layout size incl. accounted sections
 	Sections            []ManifestSection  `json:"sections"`
 	Assets              []ManifestAssetRef `json:"assets"`
+	Peephole            *ManifestPeephole  `json:"peephole,omitempty"` // nil when the pass did not run
+	Budgets             []ManifestBudget   `json:"budgets,omitempty"`  // the project's budgets, see budgets.go
+	Target              string             `json:"target,omitempty"`   // the build's Target when not TargetDev
+}
+
+// ManifestBudget is one project budget against what the build used.
+type ManifestBudget struct {
+	Name        string `json:"name"`
+	UsedBytes   uint32 `json:"used_bytes"`
+	BudgetBytes uint32 `json:"budget_bytes"`
+}
+
+// Over reports whether the build exceeds the budget.
+func (b ManifestBudget) Over() bool { return b.UsedBytes > b.BudgetBytes }
+
+// ManifestPeephole reports what the peephole pass did to the code section.
+// The code sizes exclude the IRQ stub appended after the pass.
+type ManifestPeephole struct {
+	CodeBytesBefore uint32 `json:"code_bytes_before"`
+	CodeBytesAfter  uint32 `json:"code_bytes_after"`
+	DeadMoves       int    `json:"dead_moves"`
+	RoundTrips      int    `json:"round_trips"`
+	BranchChains    int    `json:"branch_chains"`
+	JumpsToNext     int    `json:"jumps_to_next"`
 }
 
 type ManifestSection struct {
@@ -31,8 +55,15 @@ type ManifestAssetRef struct {
 	Column     int    `json:"column,omitempty"`
 }
 
-func buildManifestFromCompileState(sourcePath string, entryBank uint8, entryOffset uint16, codeBytes, romBytes uint32, program *Program, assets []AssetIR) *BuildManifest {
-	sectionOrder := []string{"gfx_tiles", "tilemaps", "palettes", "audio_seq", "audio_patch", "gamedata"}
+// manifestAssetSections are the asset sections that follow the code, in
+// ROM order.
+var manifestAssetSections = []string{"gfx_tiles", "tilemaps", "palettes", "audio_seq", "audio_patch", "gamedata"}
+
+// buildManifestFromCompileState lays out the manifest sections: the header,
+// the code, the asset sections, and rom_data -- the image, music, sample and
+// packed asset bytes (dataBytes) at the end of the ROM.
+func buildManifestFromCompileState(sourcePath string, entryBank uint8, entryOffset uint16, codeBytes, romBytes, dataBytes uint32, program *Program, assets []AssetIR) *BuildManifest {
+	sectionOrder := manifestAssetSections
 	sectionSizes := make(map[string]uint32, len(sectionOrder))
 	for _, a := range assets {
 		sectionSizes[a.Section] += uint32(len(a.Data))
@@ -109,6 +140,15 @@ func buildManifestFromCompileState(sourcePath string, entryBank uint8, entryOffs
 		}
 	}
 
+	if dataBytes > 0 && dataBytes <= romBytes {
+		manifest.Sections = append(manifest.Sections, ManifestSection{
+			Name:      "rom_data",
+			Offset:    romBytes - dataBytes,
+			SizeBytes: dataBytes,
+			UsedBytes: dataBytes,
+		})
+	}
+
 	plannedSize := uint32(0)
 	for _, s := range manifest.Sections {
 		end := s.Offset + s.SizeBytes
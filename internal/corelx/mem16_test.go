@@ -31,3 +31,31 @@ function Start()
 		t.Errorf("mem.read16 round-trip: want 0xBEEF, got 0x%04X", got)
 	}
 }
+
+// TestHardwareRegisterNames verifies hwdef register names evaluate to their
+// I/O address, and that a program's own global of the same name wins.
+func TestHardwareRegisterNames(t *testing.T) {
+	source := `var MASTER_VOLUME: int = 0
+function Start()
+    mem.write(BG1_CONTROL, 0x03)
+    MASTER_VOLUME = mem.read(BG1_CONTROL)
+    while true
+        wait_vblank()
+`
+	emu, result := compileAndBoot(t, source, 600)
+	if got := emu.CPU.Mem.Read8(0, 0x8009); got != 0x03 {
+		t.Errorf("BG1_CONTROL = 0x%02X, want 0x03", got)
+	}
+	var addr uint16
+	for _, e := range result.MemoryMap {
+		if e.Name == "MASTER_VOLUME" {
+			addr = e.Address
+		}
+	}
+	if addr == 0 {
+		t.Fatalf("global MASTER_VOLUME was not allocated")
+	}
+	if got := read16(emu, addr); got != 0x03 {
+		t.Errorf("MASTER_VOLUME global = %d, want 3", got)
+	}
+}
@@ -49,7 +49,12 @@ func loadModules(program *Program, sourcePath string, cfg CompileOptions) []Diag
 			continue
 		}
 
-		lexer := NewLexer(string(source))
+		active, condDiags := applyConditionals(string(source), modPath, cfg.Defines)
+		if len(condDiags) > 0 {
+			diags = append(diags, condDiags...)
+			continue
+		}
+		lexer := NewLexer(active)
 		tokens, lexErr := lexer.Tokenize()
 		if lexErr != nil {
 			diags = append(diags, Diagnostic{
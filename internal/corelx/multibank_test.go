@@ -95,3 +95,144 @@ func TestMultiBankCrossBankCallSmoke(t *testing.T) {
 		t.Fatalf("sentinel: want 12345, got %d -- far call across ROM banks did not execute correctly", got)
 	}
 }
+
+// TestBankOverlayPinsFunction checks a #[bank(N)] function lands in its
+// bank (forcing a multi-bank build even for a tiny program) and that calls
+// in and back out of it work.
+func TestBankOverlayPinsFunction(t *testing.T) {
+	source := `var sentinel: int = 0
+
+function Start()
+    sentinel = overlay_fn(20)
+    while true
+        wait_vblank()
+
+#[bank(3)]
+function overlay_fn(x: int) -> int
+    return add_one(x) + 1
+
+function add_one(x: int) -> int
+    return x + 1
+`
+	result, err := CompileSource(source, "overlay.corelx", nil)
+	if err != nil {
+		t.Fatalf("compile: %v (diagnostics: %+v)", err, result.Diagnostics)
+	}
+	if len(result.ROMBytes) <= 32+2*32768 {
+		t.Fatalf("expected a ROM reaching bank 3, got %d bytes", len(result.ROMBytes))
+	}
+
+	emu := emulator.NewEmulator()
+	emu.SetFrameLimit(false)
+	if err := emu.LoadROM(result.ROMBytes); err != nil {
+		t.Fatalf("LoadROM failed: %v", err)
+	}
+	emu.Start()
+	var sentinelAddr uint16
+	for _, e := range result.MemoryMap {
+		if e.Name == "sentinel" {
+			sentinelAddr = e.Address
+		}
+	}
+	for frame := 0; frame < 10; frame++ {
+		if err := emu.RunFrame(); err != nil {
+			t.Fatalf("RunFrame failed at frame %d: %v", frame, err)
+		}
+	}
+	if got := read16(emu, sentinelAddr); got != 22 {
+		t.Fatalf("sentinel: want 22, got %d -- call into bank 3 overlay failed", got)
+	}
+}
+
+func TestBankOverlayOverflowDiagnostic(t *testing.T) {
+	var src strings.Builder
+	src.WriteString("var padscratch: int = 0\n\n")
+	src.WriteString("function Start()\n")
+	src.WriteString("    while true\n")
+	src.WriteString("        wait_vblank()\n\n")
+	// Each padding function is ~13KB, so three of them overflow bank 2.
+	for f := 0; f < 3; f++ {
+		src.WriteString("#[bank(2)]\n")
+		fmt.Fprintf(&src, "function pad%d()\n", f)
+		for i := 0; i < 600; i++ {
+			fmt.Fprintf(&src, "    padscratch = padscratch + %d\n", i%97+1)
+		}
+		src.WriteString("\n")
+	}
+	result, err := CompileSource(src.String(), "overflow.corelx", nil)
+	if err == nil {
+		t.Fatalf("expected bank overflow error")
+	}
+	found := false
+	for _, d := range result.Diagnostics {
+		if d.Code == "E_BANK_OVERFLOW" {
+			found = true
+			if !strings.Contains(d.Message, "bank 2 overlay overflows: pad0, pad1, pad2") || d.Line == 0 {
+				t.Fatalf("unexpected overflow diagnostic: %+v", d)
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("expected E_BANK_OVERFLOW, got %+v", result.Diagnostics)
+	}
+}
+
+func TestBankOverlayAttributeErrors(t *testing.T) {
+	cases := map[string]string{
+		"entry": "#[bank(2)]\nfunction Start()\n    wait_vblank()\n",
+		"range": "#[bank(200)]\nfunction f()\n    wait_vblank()\nfunction Start()\n    f()\n",
+		"name":  "#[inline]\nfunction f()\n    wait_vblank()\nfunction Start()\n    f()\n",
+	}
+	for name, source := range cases {
+		if _, err := CompileSource(source, name+".corelx", nil); err == nil {
+			t.Fatalf("%s: expected compile error", name)
+		}
+	}
+}
+
+// TestFunctionSymbolsMatchROMLayout checks CompileResult.Functions against
+// where the CPU really goes: the first instruction executed in the bank 3
+// overlay must be overlay_fn's listed entry point.
+func TestFunctionSymbolsMatchROMLayout(t *testing.T) {
+	source := `var sentinel: int = 0
+
+function Start()
+    sentinel = overlay_fn(20)
+    while true
+        wait_vblank()
+
+#[bank(3)]
+function overlay_fn(x: int) -> int
+    return x + 1
+`
+	result, err := CompileSource(source, "symbols.corelx", nil)
+	if err != nil {
+		t.Fatalf("compile: %v (diagnostics: %+v)", err, result.Diagnostics)
+	}
+	syms := map[string]FunctionSymbol{}
+	for _, f := range result.Functions {
+		syms[f.Name] = f
+	}
+	start, ok := syms["Start"]
+	if !ok || start.Bank != 1 || start.Offset < 0x8000 {
+		t.Fatalf("Start symbol = %+v (found %v), want bank 1 at 0x8000+", start, ok)
+	}
+	overlay, ok := syms["overlay_fn"]
+	if !ok || overlay.Bank != 3 {
+		t.Fatalf("overlay_fn symbol = %+v (found %v), want bank 3", overlay, ok)
+	}
+
+	emu := emulator.NewEmulator()
+	if err := emu.LoadROM(result.ROMBytes); err != nil {
+		t.Fatalf("LoadROM failed: %v", err)
+	}
+	for i := 0; i < 10000 && emu.CPU.State.PCBank != 3; i++ {
+		if err := emu.CPU.ExecuteInstruction(); err != nil {
+			t.Fatalf("instruction %d: %v", i, err)
+		}
+	}
+	if emu.CPU.State.PCBank != 3 || emu.CPU.State.PCOffset != overlay.Offset {
+		t.Fatalf("first bank 3 PC = %02X:%04X, want overlay_fn at 03:%04X",
+			emu.CPU.State.PCBank, emu.CPU.State.PCOffset, overlay.Offset)
+	}
+}
@@ -0,0 +1,182 @@
+package corelx
+
+import (
+	"nitro-core-dx/internal/hwdef"
+	"nitro-core-dx/internal/rom"
+)
+
+// Shadow OAM: a program that calls oam.flush() gets a WRAM copy of all 128
+// OAM entries at the top of the global region. oam.write,
+// oam.write_sprite_data and oam.clear_sprite change the copy, and
+// oam.flush() DMAs it into OAM in one go during VBlank (waiting for VBlank
+// if called outside it), so a frame never shows half its sprite updates
+// however late the game loop runs. anim.update writes the tile byte to both
+// copies, so it works with or without a flush.
+//
+// The shadow is cleared by the first of those calls after reset, not by the
+// entry function, so a program's setup runs as soon after reset as it would
+// without a shadow (raster and scroll effects timed from reset stay put).
+//
+// A program that never calls oam.flush() keeps writing OAM directly, as do
+// immediate-mode snippets, which must not replace a running game's OAM with
+// a shadow they never filled.
+const (
+	oamShadowSize = 128 * 6
+	oamShadowBase = globalsLimit + 1 - oamShadowSize
+	// oamShadowReady is the word below the shadow: 0 from the entry
+	// function until __oamshadow has cleared the shadow.
+	oamShadowReady = oamShadowBase - 2
+
+	// DMA_CONTROL: start (bit 0), copy mode, destination type 2 (OAM).
+	oamDMAControl = 0x01 | 2<<2
+)
+
+// programFlushesOAM reports whether any function calls oam.flush().
+func programFlushesOAM(program *Program) bool {
+	for _, fn := range program.Functions {
+		for _, call := range stmtCalls(fn.Body) {
+			if callFuncName(call) == "oam.flush" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// emitOAMShadowEntry turns the sprite id in reg into the address of its
+// shadow entry, base + (id & 0x7F) * 6. Clobbers tmp.
+func (cg *CodeGenerator) emitOAMShadowEntry(reg, tmp uint8) {
+	cg.hAndImm(reg, 0x7F)
+	cg.builder.AddInstruction(rom.EncodeMOV(0, tmp, reg))
+	cg.hShlImm(tmp, 1)
+	cg.hShlImm(reg, 2)
+	cg.builder.AddInstruction(rom.EncodeADD(0, reg, tmp))
+	cg.builder.AddInstruction(rom.EncodeADD(1, reg, 0))
+	cg.builder.AddImmediate(oamShadowBase)
+}
+
+// emitOAMShadowStore stores the low byte of src at [addrReg] and advances
+// addrReg.
+func (cg *CodeGenerator) emitOAMShadowStore(addrReg, src uint8) {
+	cg.builder.AddInstruction(rom.EncodeMOV(7, addrReg, src))
+	cg.builder.AddInstruction(rom.EncodeADD(1, addrReg, 0))
+	cg.builder.AddImmediate(1)
+}
+
+// emitOAMShadowReadyCall makes sure the shadow has been cleared since
+// reset before it is written or flushed. Clobbers R6 and R7.
+func (cg *CodeGenerator) emitOAMShadowReadyCall() {
+	cg.needOAMShadowReady = true
+	cg.emitHelperCall("__oamshadow")
+}
+
+// generateOAMShadowWrite is oam.write(id, s) with a shadow: R0 = id, R1 =
+// sprite pointer. The six struct bytes are copied as they are.
+func (cg *CodeGenerator) generateOAMShadowWrite() {
+	cg.emitOAMShadowReadyCall()
+	cg.emitOAMShadowEntry(0, 6)
+	for i := 0; i < 6; i++ {
+		cg.builder.AddInstruction(rom.EncodeMOV(6, 5, 1)) // R5 = [R1] (8-bit load)
+		cg.emitOAMShadowStore(0, 5)
+		cg.builder.AddInstruction(rom.EncodeADD(1, 1, 0))
+		cg.builder.AddImmediate(1)
+	}
+}
+
+// generateOAMShadowWriteSpriteData is oam.write_sprite_data with a shadow:
+// R0 = id, R1 = x, R2 = y, R3 = tile, R4 = attr, R5 = ctrl. x_hi is built
+// the same way as the direct write (sign bit plus size code).
+func (cg *CodeGenerator) generateOAMShadowWriteSpriteData() {
+	cg.emitOAMShadowReadyCall()
+	cg.emitOAMShadowEntry(0, 6)
+	cg.builder.AddInstruction(rom.EncodeMOV(0, 6, 2)) // R6 = y
+	cg.emitOAMShadowStore(0, 1)                       // x_lo (low byte of x)
+	cg.hShrImm(1, 8)
+	cg.hAndImm(1, 1)
+	cg.emitSizeCodeBits(1, 5, 2)
+	cg.emitOAMShadowStore(0, 1) // x_hi
+	cg.emitOAMShadowStore(0, 6)
+	cg.emitOAMShadowStore(0, 3)
+	cg.emitOAMShadowStore(0, 4)
+	cg.emitOAMShadowStore(0, 5)
+}
+
+// generateOAMShadowClearSprite is oam.clear_sprite(id) with a shadow: all
+// six bytes of the entry become 0.
+func (cg *CodeGenerator) generateOAMShadowClearSprite() {
+	cg.emitOAMShadowReadyCall()
+	cg.emitOAMShadowEntry(0, 6)
+	cg.hMovImm(5, 0)
+	for i := 0; i < 6; i++ {
+		cg.emitOAMShadowStore(0, 5)
+	}
+}
+
+// emitOAMShadowUnready marks the shadow as not yet cleared, at program
+// entry. Only one word is stored, so setup is not held up.
+func (cg *CodeGenerator) emitOAMShadowUnready() {
+	cg.hMovImm(6, 0)
+	cg.hStore16(oamShadowReady, 6)
+}
+
+// emitOAMShadowReadyHelper emits __oamshadow: unless oamShadowReady is set,
+// zero the shadow and set it, so the first flush disables every sprite the
+// program has not written (as OAM is at reset) rather than copying whatever
+// WRAM held. Clobbers only R6 and R7, which the oam.* arguments never use.
+func (cg *CodeGenerator) emitOAMShadowReadyHelper() {
+	cg.recordFuncAddr("__oamshadow")
+	cg.hMovImm(7, oamShadowReady)
+	cg.builder.AddInstruction(rom.EncodeMOV(2, 6, 7)) // R6 = [ready]
+	cg.hCmpImm(6, 0)
+	done := cg.hBranch(rom.EncodeBNE())
+	cg.hMovImm(7, oamShadowBase)
+	cg.hMovImm(6, 0)
+	loop := cg.builder.GetCodeLength()
+	cg.builder.AddInstruction(rom.EncodeMOV(3, 7, 6)) // 16-bit store
+	cg.builder.AddInstruction(rom.EncodeADD(1, 7, 0))
+	cg.builder.AddImmediate(2)
+	cg.hCmpImm(7, oamShadowBase+oamShadowSize)
+	cg.builder.AddInstruction(rom.EncodeBNE())
+	cg.builder.AddImmediate(uint16(rom.CalculateBranchOffset(uint16(cg.builder.GetCodeLength()*2), uint16(loop*2))))
+	cg.hMovImm(6, 1)
+	cg.hStore16(oamShadowReady, 6)
+	cg.hPatchToHere(done)
+	cg.builder.AddInstruction(rom.EncodeRET())
+}
+
+// emitOAMFlushHelper emits __oamflush: wait until VBlank, DMA the shadow
+// into OAM (768 bytes, one per PPU cycle, well inside VBlank) and wait for
+// the DMA to finish, so writes to the shadow right after a flush belong to
+// the next one. Clobbers R4 to R7.
+func (cg *CodeGenerator) emitOAMFlushHelper() {
+	cg.recordFuncAddr("__oamflush")
+	cg.emitOAMShadowReadyCall()
+
+	waitStatus := func(addr uint16, branch uint16) {
+		top := cg.builder.GetCodeLength()
+		cg.hMovImm(4, addr)
+		cg.builder.AddInstruction(rom.EncodeMOV(2, 5, 4))
+		cg.hAndImm(5, 0x01)
+		cg.hCmpImm(5, 0)
+		pos := cg.hBranch(branch)
+		cg.builder.SetImmediateAt(pos, uint16(rom.CalculateBranchOffset(uint16(pos*2), uint16(top*2))))
+	}
+
+	waitStatus(hwdef.VBLANK_FLAG, rom.EncodeBEQ())
+	cg.hMovImm(5, 0)
+	cg.storeIOByte(hwdef.DMA_SOURCE_BANK, 5)
+	cg.storeIOByte(hwdef.DMA_DEST_ADDR_L, 5)
+	cg.storeIOByte(hwdef.DMA_DEST_ADDR_H, 5)
+	cg.hMovImm(5, oamShadowBase&0xFF)
+	cg.storeIOByte(hwdef.DMA_SOURCE_OFFSET_L, 5)
+	cg.hMovImm(5, oamShadowBase>>8)
+	cg.storeIOByte(hwdef.DMA_SOURCE_OFFSET_H, 5)
+	cg.hMovImm(5, oamShadowSize&0xFF)
+	cg.storeIOByte(hwdef.DMA_LENGTH_L, 5)
+	cg.hMovImm(5, oamShadowSize>>8)
+	cg.storeIOByte(hwdef.DMA_LENGTH_H, 5)
+	cg.hMovImm(5, oamDMAControl)
+	cg.storeIOByte(hwdef.DMA_CONTROL, 5)
+	waitStatus(hwdef.DMA_STATUS, rom.EncodeBNE())
+	cg.builder.AddInstruction(rom.EncodeRET())
+}
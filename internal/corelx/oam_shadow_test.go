@@ -0,0 +1,105 @@
+package corelx
+
+import (
+	"bytes"
+	"testing"
+)
+
+// oamFlushSource writes sprite 3 once at start-up and only flushes once the
+// test sets 0x0200, so OAM shows whether writes waited for the flush.
+const oamFlushSource = `function Start()
+    box := Sprite()
+    box.x_lo = 0x2C
+    box.x_hi = 1
+    box.y = 40
+    box.tile = 7
+    box.ctrl = SPR_ENABLE()
+    oam.write(3, box)
+    oam.write_sprite_data(5, 12, 34, 9, 0, SPR_ENABLE())
+    while true
+        wait_vblank()
+        if mem.read(0x0200) == 1
+            oam.flush()
+`
+
+func TestOAMWritesWaitForFlush(t *testing.T) {
+	emu, result := compileLoadForTest(t, oamFlushSource)
+	emu.SetFrameLimit(false)
+	emu.Start()
+	run := func(frames int) {
+		t.Helper()
+		for i := 0; i < frames; i++ {
+			if err := emu.RunFrame(); err != nil {
+				t.Fatalf("RunFrame: %v", err)
+			}
+		}
+	}
+
+	run(2)
+	if got := emu.PPU.OAM[:64]; !bytes.Equal(got, make([]byte, 64)) {
+		t.Fatalf("OAM changed before oam.flush(): % X", got[18:24])
+	}
+
+	emu.Bus.Write8(0, 0x0200, 1)
+	run(2)
+	if got, want := emu.PPU.OAM[18:24], []byte{0x2C, 0x01, 40, 7, 0, 0x01}; !bytes.Equal(got, want) {
+		t.Errorf("sprite 3 after flush = % X, want % X", got, want)
+	}
+	if got := emu.PPU.OAM[30:36]; got[0] != 12 || got[2] != 34 || got[3] != 9 || got[5] != 0x01 {
+		t.Errorf("sprite 5 after flush = % X", got)
+	}
+
+	found := false
+	for _, e := range result.MemoryMap {
+		if e.Name == "__oam_shadow" {
+			found = e.Address == oamShadowReady && e.Size == oamShadowSize+2
+		}
+	}
+	if !found {
+		t.Error("memory map does not list __oam_shadow")
+	}
+}
+
+func TestOAMWithoutFlushWritesDirectly(t *testing.T) {
+	emu, result := compileLoadForTest(t, `function Start()
+    oam.write_sprite_data(2, 12, 34, 9, 0, SPR_ENABLE())
+    while true
+        wait_vblank()
+`)
+	emu.SetFrameLimit(false)
+	emu.Start()
+	if err := emu.RunFrame(); err != nil {
+		t.Fatalf("RunFrame: %v", err)
+	}
+	if got := emu.PPU.OAM[12:18]; got[0] != 12 || got[3] != 9 {
+		t.Errorf("sprite 2 = % X, want a direct write", got)
+	}
+	for _, e := range result.MemoryMap {
+		if e.Name == "__oam_shadow" {
+			t.Error("program without oam.flush() reserved a shadow OAM")
+		}
+	}
+}
+
+// The shadow is cleared before its first use, not at entry, so sprites the
+// program never wrote flush as disabled whatever WRAM held at reset.
+func TestOAMShadowClearedBeforeFirstUse(t *testing.T) {
+	emu, _ := compileLoadForTest(t, oamFlushSource)
+	emu.SetFrameLimit(false)
+	for i := oamShadowReady; i <= globalsLimit; i++ {
+		emu.Bus.WRAM[i] = 0xFF
+	}
+	emu.Bus.WRAM[0x0200] = 1
+	emu.Start()
+	for i := 0; i < 2; i++ {
+		if err := emu.RunFrame(); err != nil {
+			t.Fatalf("RunFrame: %v", err)
+		}
+	}
+	if got := emu.PPU.OAM[:18]; !bytes.Equal(got, make([]byte, 18)) {
+		t.Errorf("sprites 0-2 after flush = % X, want cleared", got)
+	}
+	if got := emu.PPU.OAM[18:24]; got[0] != 0x2C || got[3] != 7 {
+		t.Errorf("sprite 3 after flush = % X, want the write before the clear kept", got)
+	}
+}
@@ -0,0 +1,114 @@
+package corelx
+
+import (
+	"fmt"
+
+	"nitro-core-dx/internal/hwdef"
+	"nitro-core-dx/internal/rom"
+)
+
+// PackedAsset is a compressed asset placed in the ROM data region and
+// expanded at run time by a generated decoder routine: an RLE `tilemap`
+// (tilemap_rle.go) or LZ tile graphics (tiles_lz.go).
+type PackedAsset struct {
+	Name        string
+	Compression string // "rle" or "lz"
+	Size        int    // unpacked bytes
+	Packed      int    // packed stream bytes, including the end marker
+	Bank        uint8  // ROM bank of the first byte
+	Offset      uint16 // ROM offset (0x8000-based) of the first byte
+}
+
+// packAsset compresses an asset's normalized bytes with its codec.
+func packAsset(a *AssetDecl, raw []byte) ([]byte, error) {
+	switch a.Compression {
+	case "rle":
+		return packTilemapRLE(raw)
+	case "lz":
+		return packTilesLZ(a.Type, raw)
+	default:
+		return nil, fmt.Errorf("unknown compression %q", a.Compression)
+	}
+}
+
+// loadPackedAssets compresses every asset declared with a compression and
+// lays the streams out in the shared ROM data region starting at
+// startBank, continuing from baseCursor (the bytes already used by image,
+// music and sample assets). The decoders follow a stream across banks.
+// Returns the assets (with bank/offset filled in) and the bytes to append
+// to the data region.
+func loadPackedAssets(program *Program, assets []AssetIR, startBank uint8, baseCursor int) (map[string]*PackedAsset, []byte, error) {
+	data := make(map[string][]byte, len(assets))
+	for _, a := range assets {
+		data[a.Name] = a.Data
+	}
+	out := make(map[string]*PackedAsset)
+	var region []byte
+	cursor := baseCursor
+
+	for _, a := range program.Assets {
+		if a.Compression == "" {
+			continue
+		}
+		raw, ok := data[a.Name]
+		if !ok {
+			// Normalization already reported why there is no data.
+			continue
+		}
+		packed, err := packAsset(a, raw)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s asset %s: %w", a.Type, a.Name, err)
+		}
+
+		bank, off := dataAddr(startBank, cursor)
+		region = append(region, packed...)
+		cursor += len(packed)
+		out[a.Name] = &PackedAsset{Name: a.Name, Compression: a.Compression, Size: len(raw), Packed: len(packed), Bank: bank, Offset: off}
+	}
+	return out, region, nil
+}
+
+// SetPackedAssets injects compressed assets (with ROM bank/offset).
+// Tilemaps not listed here load through inline writes.
+func (cg *CodeGenerator) SetPackedAssets(a map[string]*PackedAsset) { cg.packedAssets = a }
+
+// emitPackedReadByte reads the next byte of the stream at R1:R0
+// (bank:offset) into dst and advances R1:R0, wrapping offset 0xFFFF to
+// 0x8000 of the next bank. DBR is the stream's bank only for the read
+// itself and 0 again afterwards, so I/O ports can be written between
+// reads. Clobbers R7.
+func (cg *CodeGenerator) emitPackedReadByte(dst uint8) {
+	cg.builder.AddInstruction(rom.EncodeMOV(8, 1, 0))   // DBR = stream bank
+	cg.builder.AddInstruction(rom.EncodeMOV(6, dst, 0)) // dst = [R0]
+	cg.hMovImm(7, 0)
+	cg.builder.AddInstruction(rom.EncodeMOV(8, 7, 0)) // DBR = 0
+	cg.builder.AddInstruction(rom.EncodeADD(1, 0, 0))
+	cg.builder.AddImmediate(1)
+	cg.hCmpImm(0, 0)
+	sameBank := cg.hBranch(rom.EncodeBNE())
+	cg.hMovImm(0, rom.ROMBankOffsetBase)
+	cg.builder.AddInstruction(rom.EncodeADD(1, 1, 0))
+	cg.builder.AddImmediate(1)
+	cg.hPatchToHere(sameBank)
+}
+
+// emitSetVRAMAddr points VRAM_ADDR at the address in reg, using tmp for
+// the high byte. Clobbers R7.
+func (cg *CodeGenerator) emitSetVRAMAddr(reg, tmp uint8) {
+	cg.hMovImm(7, hwdef.VRAM_ADDR_L)
+	cg.builder.AddInstruction(rom.EncodeMOV(3, 7, reg))
+	cg.builder.AddInstruction(rom.EncodeMOV(0, tmp, reg))
+	cg.hShrImm(tmp, 8)
+	cg.hMovImm(7, hwdef.VRAM_ADDR_H)
+	cg.builder.AddInstruction(rom.EncodeMOV(3, 7, tmp))
+}
+
+// emitCountdownLoop decrements reg and branches back to the code word
+// index top while it is not zero.
+func (cg *CodeGenerator) emitCountdownLoop(reg uint8, top int) {
+	cg.builder.AddInstruction(rom.EncodeSUB(1, reg, 0))
+	cg.builder.AddImmediate(1)
+	cg.hCmpImm(reg, 0)
+	pos := cg.hBranch(rom.EncodeBNE())
+	cg.builder.SetImmediateAt(pos, uint16(rom.CalculateBranchOffset(uint16(pos*2), uint16(top*2))))
+}
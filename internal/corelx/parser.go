@@ -5,6 +5,8 @@ import (
 	"math"
 	"strconv"
 	"strings"
+
+	"nitro-core-dx/internal/rom"
 )
 
 // Parser parses CoreLX source code into an AST
@@ -76,6 +78,18 @@ func (p *Parser) Parse() (prog *Program, err error) {
 				return nil, err
 			}
 			prog.Functions = append(prog.Functions, fn)
+		} else if p.check(TOKEN_HASH) {
+			fn, err := p.parseAttributedFunction()
+			if err != nil {
+				return nil, err
+			}
+			prog.Functions = append(prog.Functions, fn)
+		} else if p.check(TOKEN_EXTERN) {
+			fn, err := p.parseExternFunction()
+			if err != nil {
+				return nil, err
+			}
+			prog.Functions = append(prog.Functions, fn)
 		} else if p.check(TOKEN_CONST) {
 			c, err := p.parseConstDecl()
 			if err != nil {
@@ -93,7 +107,7 @@ func (p *Parser) Parse() (prog *Program, err error) {
 		} else if p.check(TOKEN_DIRECTIVE) {
 			return nil, p.error(p.peek(), "directives ('--!') are only legal at the top of the file, before any code")
 		} else {
-			return nil, p.error(p.peek(), fmt.Sprintf("Expected asset, type, struct, const, var, or function declaration, got %v", p.peek().Type))
+			return nil, p.error(p.peek(), fmt.Sprintf("Expected asset, type, struct, const, var, extern, or function declaration, got %v", p.peek().Type))
 		}
 	}
 
@@ -150,6 +164,17 @@ func (p *Parser) applyDirective(prog *Program, tok Token) error {
 		}
 		return nil
 
+	case strings.HasPrefix(text, "timing:"):
+		switch rest := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(text, "timing:"))); rest {
+		case "60hz":
+			prog.Timing = rom.TimingFlag60Hz
+		case "50hz":
+			prog.Timing = rom.TimingFlag50Hz
+		default:
+			return p.error(tok, fmt.Sprintf("unknown timing %q in 'timing:' directive (want 60hz or 50hz)", rest))
+		}
+		return nil
+
 	default:
 		return p.error(tok, fmt.Sprintf("unknown directive: --! %s", text))
 	}
@@ -242,10 +267,11 @@ func (p *Parser) parseAsset() (*AssetDecl, error) {
 		return nil, p.error(p.previous(), fmt.Sprintf("Invalid asset type: %s", assetType))
 	}
 
-	// image and music assets reference an external file by path (hard split:
-	// large binary data lives in a side file, not inline). image -> .cxasset
-	// bitmap; music -> .ncdxmusic YM2608 stream.
-	if assetType == "image" || assetType == "music" {
+	// image, music and sample assets reference an external file by path
+	// (hard split: large binary data lives in a side file, not inline).
+	// image -> .cxasset bitmap; music -> .ncdxmusic YM2608 stream; sample ->
+	// .wav recording.
+	if assetType == "image" || assetType == "music" || assetType == "sample" {
 		pathTok := p.consume(TOKEN_STRING, "Expected a quoted file path after '"+assetType+"'")
 		path := strings.Trim(pathTok.Literal, "\"")
 		// consume trailing newline if present
@@ -255,6 +281,16 @@ func (p *Parser) parseAsset() (*AssetDecl, error) {
 		return &AssetDecl{Position: pos, Name: name, Type: assetType, FilePath: path}, nil
 	}
 
+	// Tilemaps and tiles may be packed compressed: `asset Level: tilemap
+	// rle hex`, `asset Sheet: tiles16 lz hex`.
+	var compression string
+	if p.check(TOKEN_IDENTIFIER) && isValidAssetCompression(p.peek().Literal) {
+		compression = p.advance().Literal
+		if err := checkAssetCompression(assetType, compression); err != nil {
+			return nil, p.error(p.previous(), err.Error())
+		}
+	}
+
 	// Encoding can be on same line or next line
 	var encoding string
 	if p.check(TOKEN_NEWLINE) {
@@ -314,17 +350,18 @@ func (p *Parser) parseAsset() (*AssetDecl, error) {
 	}
 
 	return &AssetDecl{
-		Position: pos,
-		Name:     name,
-		Type:     assetType,
-		Encoding: encoding,
-		Data:     strings.TrimSpace(data.String()),
+		Position:    pos,
+		Name:        name,
+		Type:        assetType,
+		Encoding:    encoding,
+		Data:        strings.TrimSpace(data.String()),
+		Compression: compression,
 	}, nil
 }
 
 func isValidAssetType(t string) bool {
 	switch t {
-	case "tiles8", "tiles16", "sprite", "tileset", "tilemap", "palette", "music", "sfx", "ambience", "gamedata", "blob", "image":
+	case "tiles8", "tiles16", "sprite", "tileset", "tilemap", "palette", "music", "sfx", "ambience", "gamedata", "blob", "image", "anim", "sample":
 		return true
 	default:
 		return false
@@ -340,6 +377,28 @@ func isValidAssetEncoding(enc string) bool {
 	}
 }
 
+func isValidAssetCompression(c string) bool {
+	return c == "rle" || c == "lz"
+}
+
+// checkAssetCompression reports whether an asset of type t may be packed
+// with compression c: rle for tilemaps, lz for tile graphics.
+func checkAssetCompression(t, c string) error {
+	if c == "" {
+		return nil
+	}
+	switch {
+	case c == "rle" && t == "tilemap", c == "lz" && (t == "tiles8" || t == "tiles16"):
+		return nil
+	case c == "rle":
+		return fmt.Errorf("rle compression is only supported for tilemap assets, not %s", t)
+	case c == "lz":
+		return fmt.Errorf("lz compression is only supported for tiles8 and tiles16 assets, not %s", t)
+	default:
+		return fmt.Errorf("Invalid compression: %s (expected rle or lz)", c)
+	}
+}
+
 func (p *Parser) parseTypeDecl() (*TypeDecl, error) {
 	pos := p.position()
 	p.consume(TOKEN_TYPE, "Expected 'type'")
@@ -430,35 +489,79 @@ func (p *Parser) parseField() (*FieldDecl, error) {
 	}, nil
 }
 
-func (p *Parser) parseFunction() (*FunctionDecl, error) {
-	pos := p.position()
-	p.consume(TOKEN_FUNCTION, "Expected 'function'")
-
-	name := p.consume(TOKEN_IDENTIFIER, "Expected function name").Literal
-
-	// Parse parameters
-	p.consume(TOKEN_LPAREN, "Expected '('")
-	params := make([]*ParamDecl, 0)
-	if !p.check(TOKEN_RPAREN) {
-		for {
-			param, err := p.parseParam()
-			if err != nil {
-				return nil, err
-			}
-			params = append(params, param)
-			if !p.check(TOKEN_COMMA) {
-				break
-			}
+// parseAttributedFunction parses one or more `#[name(args)]` attribute
+// lines followed by the function they apply to. The only attribute so far
+// is `#[bank(N)]`, which pins the function to ROM bank N.
+func (p *Parser) parseAttributedFunction() (*FunctionDecl, error) {
+	var bank uint8
+	for p.check(TOKEN_HASH) {
+		p.advance()
+		p.consume(TOKEN_LBRACKET, "Expected '[' after '#'")
+		nameTok := p.consume(TOKEN_IDENTIFIER, "Expected attribute name")
+		if nameTok.Literal != "bank" {
+			return nil, p.error(nameTok, fmt.Sprintf("unknown attribute %q (supported: bank)", nameTok.Literal))
+		}
+		p.consume(TOKEN_LPAREN, "Expected '(' after 'bank'")
+		numTok := p.consume(TOKEN_NUMBER, "Expected bank number")
+		n, err := parseNumberLiteral(numTok.Literal)
+		if err != nil || n < rom.ROMMinProgramBank || n > rom.ROMMaxProgramBank {
+			return nil, p.error(numTok, fmt.Sprintf("bank must be %d-%d, got %s", rom.ROMMinProgramBank, rom.ROMMaxProgramBank, numTok.Literal))
+		}
+		p.consume(TOKEN_RPAREN, "Expected ')'")
+		p.consume(TOKEN_RBRACKET, "Expected ']'")
+		for p.check(TOKEN_NEWLINE) {
 			p.advance()
 		}
+		bank = uint8(n)
 	}
-	p.consume(TOKEN_RPAREN, "Expected ')'")
+	if !p.check(TOKEN_FUNCTION) {
+		return nil, p.error(p.peek(), "Expected 'function' after attribute")
+	}
+	fn, err := p.parseFunction()
+	if err != nil {
+		return nil, err
+	}
+	fn.Bank = bank
+	return fn, nil
+}
 
-	// Parse return type (optional)
-	var returnType TypeExpr
-	if p.check(TOKEN_ARROW) {
+// parseExternFunction parses `extern function Name(params) -> type`, a
+// body-less declaration of a function another unit defines.
+func (p *Parser) parseExternFunction() (*FunctionDecl, error) {
+	pos := p.position()
+	p.consume(TOKEN_EXTERN, "Expected 'extern'")
+	if !p.check(TOKEN_FUNCTION) {
+		return nil, p.error(p.peek(), "Expected 'function' after 'extern'")
+	}
+	p.advance()
+	name := p.consume(TOKEN_IDENTIFIER, "Expected function name").Literal
+	params, returnType, err := p.parseSignature()
+	if err != nil {
+		return nil, err
+	}
+	if p.check(TOKEN_NEWLINE) {
 		p.advance()
-		returnType = p.parseTypeExpr()
+	}
+	if p.check(TOKEN_INDENT) {
+		return nil, p.error(p.peek(), fmt.Sprintf("extern function %s cannot have a body", name))
+	}
+	return &FunctionDecl{
+		Position:   pos,
+		Name:       name,
+		Params:     params,
+		ReturnType: returnType,
+		Extern:     true,
+	}, nil
+}
+
+func (p *Parser) parseFunction() (*FunctionDecl, error) {
+	pos := p.position()
+	p.consume(TOKEN_FUNCTION, "Expected 'function'")
+
+	name := p.consume(TOKEN_IDENTIFIER, "Expected function name").Literal
+	params, returnType, err := p.parseSignature()
+	if err != nil {
+		return nil, err
 	}
 
 	// Parse body
@@ -495,6 +598,35 @@ func (p *Parser) parseFunction() (*FunctionDecl, error) {
 	}, nil
 }
 
+// parseSignature parses a function's parameter list and optional return
+// type: `(name: type, ...) -> type`.
+func (p *Parser) parseSignature() ([]*ParamDecl, TypeExpr, error) {
+	p.consume(TOKEN_LPAREN, "Expected '('")
+	params := make([]*ParamDecl, 0)
+	if !p.check(TOKEN_RPAREN) {
+		for {
+			param, err := p.parseParam()
+			if err != nil {
+				return nil, nil, err
+			}
+			params = append(params, param)
+			if !p.check(TOKEN_COMMA) {
+				break
+			}
+			p.advance()
+		}
+	}
+	p.consume(TOKEN_RPAREN, "Expected ')'")
+
+	// Parse return type (optional)
+	var returnType TypeExpr
+	if p.check(TOKEN_ARROW) {
+		p.advance()
+		returnType = p.parseTypeExpr()
+	}
+	return params, returnType, nil
+}
+
 func (p *Parser) parseParam() (*ParamDecl, error) {
 	pos := p.position()
 	name := p.consume(TOKEN_IDENTIFIER, "Expected parameter name").Literal
@@ -983,8 +1115,20 @@ func (p *Parser) parseCall() Expr {
 			pos := p.position()
 			p.advance()
 			args := make([]Expr, 0)
+			var argNames []string
 			if !p.check(TOKEN_RPAREN) {
 				for {
+					name := ""
+					if p.check(TOKEN_IDENTIFIER) && p.peekNext().Type == TOKEN_EQUAL {
+						name = p.advance().Literal
+						p.advance() // '='
+					}
+					if name != "" && argNames == nil {
+						argNames = make([]string, len(args), len(args)+1)
+					}
+					if argNames != nil {
+						argNames = append(argNames, name)
+					}
 					arg := p.parseExpr()
 					if arg == nil {
 						// Try to recover - maybe we hit a newline or dedent
@@ -1008,10 +1152,14 @@ func (p *Parser) parseCall() Expr {
 			} else {
 				p.advance()
 			}
+			if len(argNames) > len(args) {
+				argNames = argNames[:len(args)]
+			}
 			expr = &CallExpr{
 				Position: pos,
 				Func:     expr,
 				Args:     args,
+				ArgNames: argNames,
 			}
 		} else if p.check(TOKEN_DOT) {
 			pos := p.position()
@@ -1054,9 +1202,10 @@ func (p *Parser) parsePrimary() Expr {
 		return &BoolExpr{Position: pos, Value: false}
 	case p.check(TOKEN_NUMBER):
 		tok := p.advance()
-		if strings.Contains(tok.Literal, ".") {
-			// Decimal literal => 8.8 fixed-point bits (charter D4).
-			f, ferr := strconv.ParseFloat(tok.Literal, 64)
+		if strings.Contains(tok.Literal, ".") || strings.HasSuffix(tok.Literal, "fx") {
+			// Decimal or `fx`-suffixed literal => 8.8 fixed-point bits
+			// (charter D4).
+			f, ferr := strconv.ParseFloat(strings.TrimSuffix(tok.Literal, "fx"), 64)
 			if ferr != nil {
 				panic(p.error(tok, "Invalid decimal literal"))
 			}
@@ -1115,6 +1264,14 @@ func (p *Parser) peek() Token {
 	return p.tokens[p.current]
 }
 
+// peekNext returns the token after the current one.
+func (p *Parser) peekNext() Token {
+	if p.current+1 >= len(p.tokens) {
+		return Token{Type: TOKEN_EOF}
+	}
+	return p.tokens[p.current+1]
+}
+
 func (p *Parser) previous() Token {
 	if p.current == 0 {
 		return Token{Type: TOKEN_EOF}
@@ -1155,6 +1312,12 @@ func (p *Parser) position() Position {
 		return Position{Line: 1, Column: 1}
 	}
 	tok := p.previous()
+	// At the start of a statement the previous token is the line break or
+	// indent before it; the statement starts at its own first token.
+	switch tok.Type {
+	case TOKEN_NEWLINE, TOKEN_INDENT, TOKEN_DEDENT:
+		tok = p.peek()
+	}
 	return Position{Line: tok.Line, Column: tok.Column}
 }
 
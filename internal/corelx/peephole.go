@@ -0,0 +1,67 @@
+package corelx
+
+import "nitro-core-dx/internal/rom"
+
+// SetPeephole enables the peephole pass (rom.Peephole) over the generated
+// code. It runs only on compact single-bank builds; multi-bank builds are
+// emitted as generated.
+func (cg *CodeGenerator) SetPeephole(enabled bool) {
+	cg.peephole = enabled
+}
+
+// PeepholeStats reports what the peephole pass changed, or nil when it did
+// not run.
+func (cg *CodeGenerator) PeepholeStats() *rom.PeepholeStats { return cg.peepholeStats }
+
+// runPeephole rewrites the code emitted so far and moves every recorded
+// code position to match: function addresses, pending CALL and IRQ vector
+// patches, the __fxsin table and its address, and source line marks. It
+// runs after the last helper and before the IRQ stub, whose alignment
+// depends on the final code length.
+func (cg *CodeGenerator) runPeephole() error {
+	b, ok := cg.builder.(*rom.ROMBuilder)
+	if !cg.peephole || !ok || cg.wideCallMode {
+		return nil
+	}
+	in := rom.PeepholeInput{Code: b.Words(), Fixed: make(map[int]bool)}
+	for _, p := range cg.callPatches {
+		in.Fixed[p.offsetPos] = true
+	}
+	for _, p := range cg.irqVectorPatchPositions {
+		in.Fixed[p.bankPos] = true
+		in.Fixed[p.offsetPos] = true
+	}
+	if cg.fxSinTable.End > cg.fxSinTable.Start {
+		in.Data = append(in.Data, cg.fxSinTable)
+		in.Fixed[cg.fxSinTableAddrPos] = true
+	}
+	res, err := rom.Peephole(in)
+	if err != nil {
+		return err
+	}
+	b.ReplaceCode(res.Code)
+
+	for name, addr := range cg.functionAddrs {
+		addr.index = res.Index(addr.index)
+		cg.functionAddrs[name] = addr
+	}
+	for i := range cg.callPatches {
+		cg.callPatches[i].offsetPos = res.Index(cg.callPatches[i].offsetPos)
+	}
+	for i := range cg.irqVectorPatchPositions {
+		p := &cg.irqVectorPatchPositions[i]
+		p.bankPos = res.Index(p.bankPos)
+		p.offsetPos = res.Index(p.offsetPos)
+	}
+	if cg.fxSinTable.End > cg.fxSinTable.Start {
+		cg.fxSinTable = rom.WordRange{Start: res.Index(cg.fxSinTable.Start), End: res.Index(cg.fxSinTable.End)}
+		cg.fxSinTableAddrPos = res.Index(cg.fxSinTableAddrPos)
+		b.SetImmediateAt(cg.fxSinTableAddrPos, uint16(rom.ROMBankOffsetBase+cg.fxSinTable.Start*2))
+	}
+	for i := range cg.sourceLines {
+		sl := &cg.sourceLines[i]
+		sl.Offset = uint16(rom.ROMBankOffsetBase + res.Index(int(sl.Offset-rom.ROMBankOffsetBase)/2)*2)
+	}
+	cg.peepholeStats = &res.Stats
+	return nil
+}
@@ -0,0 +1,60 @@
+package corelx_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"nitro-core-dx/internal/corelx"
+	"nitro-core-dx/internal/emulator"
+)
+
+// TestPeepholeKeepsPongBehavior compiles tutorial_pong with and without
+// the peephole pass and checks the smaller ROM plays the same: identical
+// OAM every frame under the same scripted input.
+func TestPeepholeKeepsPongBehavior(t *testing.T) {
+	sourcePath := filepath.Join("..", "..", "test", "roms", "tutorial_pong.corelx")
+	plain, err := corelx.CompileProject(sourcePath, &corelx.CompileOptions{NoPeephole: true})
+	if err != nil {
+		t.Fatalf("compile without peephole: %v", err)
+	}
+	opt, err := corelx.CompileProject(sourcePath, nil)
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	if plain.Manifest.Peephole != nil {
+		t.Errorf("NoPeephole build reports peephole stats: %+v", plain.Manifest.Peephole)
+	}
+	p := opt.Manifest.Peephole
+	if p == nil {
+		t.Fatal("manifest has no peephole stats")
+	}
+	if p.CodeBytesAfter >= p.CodeBytesBefore {
+		t.Errorf("peephole did not shrink the code: %d -> %d bytes", p.CodeBytesBefore, p.CodeBytesAfter)
+	}
+
+	run := func(romBytes []byte) *emulator.Emulator {
+		emu := emulator.NewEmulator()
+		emu.SetFrameLimit(false)
+		if err := emu.LoadROM(romBytes); err != nil {
+			t.Fatalf("LoadROM: %v", err)
+		}
+		emu.Start()
+		return emu
+	}
+	a, b := run(plain.ROMBytes), run(opt.ROMBytes)
+	for frame := 0; frame < 120; frame++ {
+		var buttons uint16
+		if frame >= 20 && frame < 50 {
+			buttons = 0x01 // Up
+		}
+		for _, emu := range []*emulator.Emulator{a, b} {
+			emu.SetInputButtons(buttons)
+			if err := emu.RunFrame(); err != nil {
+				t.Fatalf("RunFrame at frame %d: %v", frame, err)
+			}
+		}
+		if a.PPU.OAM != b.PPU.OAM {
+			t.Fatalf("OAM differs at frame %d", frame)
+		}
+	}
+}
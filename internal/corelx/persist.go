@@ -0,0 +1,88 @@
+package corelx
+
+import (
+	"fmt"
+
+	"nitro-core-dx/internal/hwdef"
+	"nitro-core-dx/internal/rom"
+)
+
+// persistMaxKey matches the emulator's PersistMaxKey: the mailbox drops key
+// bytes past it, so longer keys would silently collide.
+const persistMaxKey = 16
+
+// checkPersistCall validates persist.set(key, value) and persist.get(key):
+// the key is a string literal streamed to PERSIST_KEY, like text.draw's
+// text, so it must be known at compile time.
+func (a *SemanticAnalyzer) checkPersistCall(name string, call *CallExpr) {
+	want := 1
+	if name == "persist.set" {
+		want = 2
+	}
+	if len(call.Args) != want {
+		usage := "persist.get(key)"
+		if want == 2 {
+			usage = "persist.set(key, value)"
+		}
+		a.addDiagnostic(call.Position, CategoryValidationError, "E_PERSIST_ARGS", fmt.Sprintf("%s takes %d argument(s): %s", name, want, usage), "")
+		return
+	}
+	key, ok := call.Args[0].(*StringExpr)
+	if !ok {
+		a.addDiagnostic(call.Args[0].Pos(), CategoryValidationError, "E_PERSIST_KEY", fmt.Sprintf("%s: the key must be a string literal, e.g. \"hiscore\"", name), "")
+		return
+	}
+	if len(key.Value) == 0 || len(key.Value) > persistMaxKey {
+		a.addDiagnostic(key.Position, CategoryValidationError, "E_PERSIST_KEY", fmt.Sprintf("%s: key %q must be 1-%d characters", name, key.Value, persistMaxKey), "")
+		return
+	}
+	for _, ch := range key.Value {
+		if ch < 0x20 || ch > 0x7E {
+			a.addDiagnostic(key.Position, CategoryValidationError, "E_PERSIST_KEY", fmt.Sprintf("%s: key %q must be printable ASCII", name, key.Value), "")
+			return
+		}
+	}
+}
+
+// generatePersistCall emits persist.set and persist.get over the PERSIST_*
+// mailbox. set writes the value, streams the key and issues STORE; get
+// streams the key, issues LOAD and reads the 16-bit result into destReg.
+// With persistence disabled the mailbox reads 0, so get returns 0.
+// Clobbers R6 and R7, and R0 for set.
+func (cg *CodeGenerator) generatePersistCall(name string, call *CallExpr, destReg uint8) error {
+	key, ok := call.Args[0].(*StringExpr)
+	if !ok {
+		return fmt.Errorf("%s: the key must be a string literal", name)
+	}
+	command := uint16(0x01) // LOAD
+	if name == "persist.set" {
+		if len(call.Args) != 2 {
+			return fmt.Errorf("persist.set requires 2 arguments (key, value)")
+		}
+		if err := cg.generateExpr(call.Args[1], 0); err != nil {
+			return err
+		}
+		cg.storeIOByte(hwdef.PERSIST_VALUE_L, 0)
+		cg.builder.AddInstruction(rom.EncodeMOV(0, 6, 0)) // R6 = value
+		cg.hShrImm(6, 8)
+		cg.storeIOByte(hwdef.PERSIST_VALUE_H, 6)
+		command = 0x02 // STORE
+	}
+	cg.hMovImm(7, hwdef.PERSIST_KEY)
+	for _, ch := range key.Value {
+		cg.hMovImm(6, uint16(ch))
+		cg.builder.AddInstruction(rom.EncodeMOV(7, 7, 6)) // MOV [R7], R6 (8-bit store)
+	}
+	cg.hMovImm(6, command)
+	cg.storeIOByte(hwdef.PERSIST_CONTROL, 6)
+	if name == "persist.get" {
+		cg.hMovImm(7, hwdef.PERSIST_VALUE_H)
+		cg.builder.AddInstruction(rom.EncodeMOV(6, 6, 7)) // MOV R6, [R7] (8-bit read)
+		cg.hShlImm(6, 8)
+		cg.hMovImm(7, hwdef.PERSIST_VALUE_L)
+		cg.builder.AddInstruction(rom.EncodeMOV(6, 7, 7))       // MOV R7, [R7] (8-bit read)
+		cg.builder.AddInstruction(rom.EncodeOR(0, 6, 7))        // R6 |= R7
+		cg.builder.AddInstruction(rom.EncodeMOV(0, destReg, 6)) // MOV R{dest}, R6
+	}
+	return nil
+}
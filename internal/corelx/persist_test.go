@@ -0,0 +1,91 @@
+package corelx
+
+import (
+	"strings"
+	"testing"
+
+	"nitro-core-dx/internal/emulator"
+)
+
+func TestPersistDiagnostics(t *testing.T) {
+	cases := []struct {
+		name string
+		stmt string
+		want string
+	}{
+		{"set", `persist.set("hiscore", 100)`, ""},
+		{"get", `n = persist.get("hiscore")`, ""},
+		{"key not literal", `persist.set(n, 1)`, "E_PERSIST_KEY"},
+		{"key too long", `persist.set("a_very_long_key_name", 1)`, "E_PERSIST_KEY"},
+		{"empty key", `n = persist.get("")`, "E_PERSIST_KEY"},
+		{"set without value", `persist.set("hiscore")`, "E_PERSIST_ARGS"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			source := "function Start()\n    n := 0\n    " + tc.stmt + "\n"
+			result, _ := CompileSource(source, "persist.corelx", nil)
+			var codes []string
+			for _, d := range result.Diagnostics {
+				if d.Severity == SeverityError {
+					codes = append(codes, d.Code)
+				}
+			}
+			if got := strings.Join(codes, ","); got != tc.want {
+				t.Fatalf("diagnostics = %q, want %q (%+v)", got, tc.want, result.Diagnostics)
+			}
+		})
+	}
+}
+
+func TestPersistRoundTrip(t *testing.T) {
+	source := `var before: u16
+var after: u16
+
+function Start()
+    before = persist.get("hiscore")
+    persist.set("hiscore", before + 1234)
+    after = persist.get("hiscore")
+    while true
+        before = before
+`
+	result, err := CompileSource(source, "persist.corelx", &CompileOptions{EmitROMBytes: true})
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	addrs := map[string]uint16{}
+	for _, e := range result.MemoryMap {
+		addrs[e.Name] = e.Address
+	}
+	dir := t.TempDir()
+	boot := func(persist bool) *emulator.Emulator {
+		emu := emulator.NewEmulator()
+		if persist {
+			if err := emu.EnablePersistence(dir); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := emu.LoadROM(result.ROMBytes); err != nil {
+			t.Fatalf("load ROM: %v", err)
+		}
+		for i := 0; i < 2000; i++ {
+			if err := emu.CPU.ExecuteInstruction(); err != nil {
+				t.Fatalf("CPU step %d: %v", i, err)
+			}
+		}
+		return emu
+	}
+
+	// The second run starts from the first run's saved value.
+	for run, want := range []uint16{1234, 2468} {
+		emu := boot(true)
+		if got := read16(emu, addrs["after"]); got != want {
+			t.Fatalf("run %d: after = %d, want %d", run+1, got, want)
+		}
+	}
+
+	// Disabled, the mailbox is unmapped and get returns 0.
+	emu := boot(false)
+	if got := read16(emu, addrs["after"]); got != 0 {
+		t.Errorf("disabled: after = %d, want 0", got)
+	}
+}
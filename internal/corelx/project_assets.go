@@ -13,6 +13,11 @@ const defaultProjectAssetManifest = "corelx.assets.json"
 type ProjectAssetManifest struct {
 	FormatVersion int                  `json:"format_version,omitempty"`
 	Assets        []ProjectAssetRecord `json:"assets"`
+
+	// Budgets caps ROM sections (see budgets.go): "rom", "code", "assets",
+	// "rom_data" or an asset section name, mapped to a size such as
+	// "24 KB".
+	Budgets map[string]ByteSize `json:"budgets,omitempty"`
 }
 
 type ProjectAssetRecord struct {
@@ -21,15 +26,28 @@ type ProjectAssetRecord struct {
 	Encoding string `json:"encoding"`
 	Path     string `json:"path,omitempty"`
 	Data     string `json:"data,omitempty"`
+
+	// Compression is "rle" (tilemaps) or "lz" (tiles8/tiles16) to pack
+	// the asset compressed, or empty.
+	Compression string `json:"compression,omitempty"`
+}
+
+// projectAssetManifestPath is the project manifest consulted for
+// sourcePath, or "" when there is none to look for.
+func projectAssetManifestPath(sourcePath string, cfg CompileOptions) string {
+	if p := strings.TrimSpace(cfg.AssetManifestPath); p != "" {
+		return p
+	}
+	if sourcePath == "" {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(sourcePath), defaultProjectAssetManifest)
 }
 
 func loadProjectAssets(sourcePath string, cfg CompileOptions) ([]*AssetDecl, []string, []Diagnostic) {
-	manifestPath := strings.TrimSpace(cfg.AssetManifestPath)
+	manifestPath := projectAssetManifestPath(sourcePath, cfg)
 	if manifestPath == "" {
-		if sourcePath == "" {
-			return nil, nil, nil
-		}
-		manifestPath = filepath.Join(filepath.Dir(sourcePath), defaultProjectAssetManifest)
+		return nil, nil, nil
 	}
 
 	data, err := os.ReadFile(manifestPath)
@@ -101,6 +119,19 @@ func loadProjectAssets(sourcePath string, cfg CompileOptions) ([]*AssetDecl, []s
 				Stage:    StageAsset,
 			}}
 		}
+		compression := strings.TrimSpace(rec.Compression)
+		if err := checkAssetCompression(kind, compression); err != nil {
+			return nil, sourceFiles, []Diagnostic{{
+				Category: CategoryAssetFormatError,
+				Code:     "E_ASSET_MANIFEST_COMPRESSION",
+				Message:  fmt.Sprintf("asset record %d: %v", i, err),
+				File:     manifestPath,
+				Line:     i + 1,
+				Column:   1,
+				Severity: SeverityError,
+				Stage:    StageAsset,
+			}}
+		}
 
 		payload := rec.Data
 		if strings.TrimSpace(rec.Path) != "" {
@@ -124,11 +155,12 @@ func loadProjectAssets(sourcePath string, cfg CompileOptions) ([]*AssetDecl, []s
 		}
 
 		assets = append(assets, &AssetDecl{
-			Position: Position{Line: i + 1, Column: 1},
-			Name:     name,
-			Type:     kind,
-			Encoding: enc,
-			Data:     payload,
+			Position:    Position{Line: i + 1, Column: 1},
+			Name:        name,
+			Type:        kind,
+			Encoding:    enc,
+			Data:        payload,
+			Compression: compression,
 		})
 	}
 	return assets, uniqueStrings(sourceFiles), nil
@@ -0,0 +1,100 @@
+package corelx
+
+import (
+	"encoding/binary"
+	"math"
+	"strings"
+	"testing"
+)
+
+// testWAV builds a 16-bit PCM WAV with the given interleaved samples.
+func testWAV(rate, channels int, samples []int16) []byte {
+	data := make([]byte, len(samples)*2)
+	for i, s := range samples {
+		binary.LittleEndian.PutUint16(data[i*2:], uint16(s))
+	}
+	b := []byte("RIFF\x00\x00\x00\x00WAVEfmt ")
+	b = binary.LittleEndian.AppendUint32(b, 16)
+	b = binary.LittleEndian.AppendUint16(b, 1)
+	b = binary.LittleEndian.AppendUint16(b, uint16(channels))
+	b = binary.LittleEndian.AppendUint32(b, uint32(rate))
+	b = binary.LittleEndian.AppendUint32(b, uint32(rate*channels*2))
+	b = binary.LittleEndian.AppendUint16(b, uint16(channels*2))
+	b = binary.LittleEndian.AppendUint16(b, 16)
+	b = append(b, "data"...)
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(data)))
+	b = append(b, data...)
+	binary.LittleEndian.PutUint32(b[4:], uint32(len(b)-8))
+	return b
+}
+
+func TestPackSampleDownmixesAndDownsamples(t *testing.T) {
+	// Stereo at 22050 Hz: each output sample averages two frames of two
+	// channels.
+	wav := testWAV(22050, 2, []int16{
+		16384, 16384, 16384, 16384, // 0.5
+		-32768, 0, -32768, 0, // -0.5
+		32767, 32767, -32768, -32768, // ~0
+	})
+	mono, rate, err := decodeWAV(wav)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pcm, outRate := packSample(mono, rate)
+	if outRate != sampleMaxRate {
+		t.Fatalf("rate = %d, want %d", outRate, sampleMaxRate)
+	}
+	want := []int8{64, -64, 0}
+	if len(pcm) != len(want) {
+		t.Fatalf("got %d samples, want %d", len(pcm), len(want))
+	}
+	for i, w := range want {
+		if int8(pcm[i]) != w {
+			t.Errorf("sample %d = %d, want %d", i, int8(pcm[i]), w)
+		}
+	}
+
+	if _, _, err := decodeWAV([]byte("RIFF\x04\x00\x00\x00WAVE")); err == nil || !strings.Contains(err.Error(), "fmt") {
+		t.Errorf("decodeWAV without fmt chunk: err = %v", err)
+	}
+}
+
+func TestSfxPlayStartsSampleChannel(t *testing.T) {
+	samples := make([]int16, 400)
+	for i := range samples {
+		samples[i] = int16((i%50 - 25) * 1000)
+	}
+	main := `asset Jump: sample "jump.wav"
+
+function Start()
+    sfx.play(ASSET_Jump, 200)
+    while true
+        wait_vblank()
+`
+	emu := compileAndRunFramesWithMusic(t, "jump.wav", testWAV(8000, 1, samples), main, 2)
+	s := emu.APU.Sample
+	if s.Rate != 8000 || s.Length != 400 || s.Volume != 200 {
+		t.Fatalf("sample channel = %+v, want rate 8000, length 400, volume 200", s)
+	}
+	if !s.Playing {
+		t.Fatalf("sample stopped after two frames; 400 samples at 8 kHz last 50 ms")
+	}
+	for i := 0; i < 50; i++ {
+		want := int8(math.Round(float64(samples[i]) / 32768 * 127))
+		got := int8(emu.Bus.Read8(s.Bank, s.Addr+uint16(i)))
+		if got != want {
+			t.Fatalf("ROM byte %d = %d, want %d", i, got, want)
+		}
+	}
+}
+
+func TestSfxPlayNeedsSampleAsset(t *testing.T) {
+	source := "function Start()\n    sfx.play(2)\n"
+	result, err := CompileSource(source, "sfx.corelx", nil)
+	if err == nil {
+		t.Fatal("expected sfx.play(2) without the sfx module to fail")
+	}
+	if len(result.Diagnostics) == 0 || result.Diagnostics[0].Code != "E_SFX_ASSET" {
+		t.Fatalf("diagnostics = %+v, want E_SFX_ASSET", result.Diagnostics)
+	}
+}
@@ -0,0 +1,243 @@
+package corelx
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"nitro-core-dx/internal/hwdef"
+)
+
+// SampleAsset is a `sample` asset: a WAV file converted at pack time to
+// 8-bit signed mono PCM for the APU sample channel (apu.SampleChannel),
+// placed in ROM.
+type SampleAsset struct {
+	Name   string
+	Rate   int    // playback rate in Hz
+	Length int    // sample bytes
+	Bank   uint8  // ROM bank of the first byte
+	Offset uint16 // ROM offset (0x8000-based) of the first byte
+}
+
+// sampleMaxRate is the highest rate a sample is stored at; WAVs recorded
+// above it are downsampled when packed.
+const sampleMaxRate = 11025
+
+// sampleMaxLength is the longest sample the channel's 16-bit length
+// register can play.
+const sampleMaxLength = 0xFFFF
+
+// loadSampleAssets reads every `sample` asset's WAV file, converts it to
+// 8-bit PCM and lays the bytes out in the shared ROM data region starting
+// at startBank, continuing from baseCursor (the bytes already used by image
+// and music assets). The sample channel follows its data across banks, so
+// a sample need not fit in one. Returns the assets (with bank/offset filled
+// in) and the bytes to append to the data region.
+func loadSampleAssets(program *Program, sourcePath string, startBank uint8, baseCursor int) (map[string]*SampleAsset, []byte, error) {
+	srcDir := filepath.Dir(sourcePath)
+	assets := make(map[string]*SampleAsset)
+	var region []byte
+	cursor := baseCursor
+
+	for _, a := range program.Assets {
+		if a.Type != "sample" {
+			continue
+		}
+		path := a.FilePath
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(srcDir, path)
+		}
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("sample asset %s: %w", a.Name, err)
+		}
+		mono, rate, err := decodeWAV(raw)
+		if err != nil {
+			return nil, nil, fmt.Errorf("sample asset %s (%s): %w", a.Name, path, err)
+		}
+		pcm, rate := packSample(mono, rate)
+		if len(pcm) == 0 {
+			return nil, nil, fmt.Errorf("sample asset %s (%s): no audio data", a.Name, path)
+		}
+		if len(pcm) > sampleMaxLength {
+			return nil, nil, fmt.Errorf("sample asset %s (%s): %d samples at %d Hz is too long (at most %d)", a.Name, path, len(pcm), rate, sampleMaxLength)
+		}
+
+		bank, off := dataAddr(startBank, cursor)
+		region = append(region, pcm...)
+		cursor += len(pcm)
+		assets[a.Name] = &SampleAsset{Name: a.Name, Rate: rate, Length: len(pcm), Bank: bank, Offset: off}
+	}
+	return assets, region, nil
+}
+
+// decodeWAV reads an uncompressed 8- or 16-bit PCM WAV file and returns it
+// downmixed to mono in [-1, 1], with its sample rate.
+func decodeWAV(b []byte) ([]float64, int, error) {
+	if len(b) < 12 || string(b[0:4]) != "RIFF" || string(b[8:12]) != "WAVE" {
+		return nil, 0, errors.New("not a RIFF/WAVE file")
+	}
+	var (
+		format, channels, bits uint16
+		rate                   uint32
+		data                   []byte
+		haveFmt                bool
+	)
+	for p := 12; p+8 <= len(b); {
+		id := string(b[p : p+4])
+		size := int(binary.LittleEndian.Uint32(b[p+4 : p+8]))
+		p += 8
+		if size > len(b)-p {
+			return nil, 0, fmt.Errorf("WAV chunk %q is truncated", id)
+		}
+		switch id {
+		case "fmt ":
+			if size < 16 {
+				return nil, 0, errors.New("invalid WAV fmt chunk")
+			}
+			format = binary.LittleEndian.Uint16(b[p:])
+			channels = binary.LittleEndian.Uint16(b[p+2:])
+			rate = binary.LittleEndian.Uint32(b[p+4:])
+			bits = binary.LittleEndian.Uint16(b[p+14:])
+			// WAVE_FORMAT_EXTENSIBLE carries the real format in its
+			// sub-format GUID, whose first two bytes are the format tag.
+			if format == 0xFFFE && size >= 26 {
+				format = binary.LittleEndian.Uint16(b[p+24:])
+			}
+			haveFmt = true
+		case "data":
+			data = b[p : p+size]
+		}
+		p += size + size&1
+	}
+	switch {
+	case !haveFmt:
+		return nil, 0, errors.New("WAV has no fmt chunk")
+	case format != 1:
+		return nil, 0, fmt.Errorf("WAV format %d is not supported (uncompressed PCM only)", format)
+	case bits != 8 && bits != 16:
+		return nil, 0, fmt.Errorf("%d-bit WAV is not supported (8 or 16 bits only)", bits)
+	case channels == 0 || rate == 0:
+		return nil, 0, errors.New("WAV has no channels or a zero sample rate")
+	}
+
+	width := int(bits) / 8
+	frame := width * int(channels)
+	mono := make([]float64, len(data)/frame)
+	for i := range mono {
+		sum := 0.0
+		for c := 0; c < int(channels); c++ {
+			at := i*frame + c*width
+			if width == 1 {
+				sum += (float64(data[at]) - 128) / 128 // 8-bit WAV is unsigned
+			} else {
+				sum += float64(int16(binary.LittleEndian.Uint16(data[at:]))) / 32768
+			}
+		}
+		mono[i] = sum / float64(channels)
+	}
+	return mono, int(rate), nil
+}
+
+// packSample converts mono audio to 8-bit signed PCM, downsampling to
+// sampleMaxRate when recorded above it. Each output sample averages the
+// input samples it covers, which keeps most of the aliasing out.
+func packSample(mono []float64, rate int) ([]byte, int) {
+	outRate := rate
+	if outRate > sampleMaxRate {
+		outRate = sampleMaxRate
+	}
+	n := int(int64(len(mono)) * int64(outRate) / int64(rate))
+	out := make([]byte, n)
+	for i := range out {
+		lo := int(int64(i) * int64(rate) / int64(outRate))
+		hi := int(int64(i+1) * int64(rate) / int64(outRate))
+		if hi <= lo {
+			hi = lo + 1
+		}
+		if hi > len(mono) {
+			hi = len(mono)
+		}
+		sum := 0.0
+		for _, v := range mono[lo:hi] {
+			sum += v
+		}
+		v := math.Round(sum / float64(hi-lo) * 127)
+		out[i] = byte(int8(math.Max(-128, math.Min(127, v))))
+	}
+	return out, outRate
+}
+
+// sampleAssetArg returns the sample asset named by a call's first
+// argument (`ASSET_Jump` or `Jump`), if it names one.
+func sampleAssetArg(program *Program, call *CallExpr) (string, bool) {
+	if len(call.Args) == 0 {
+		return "", false
+	}
+	ident, ok := call.Args[0].(*IdentExpr)
+	if !ok {
+		return "", false
+	}
+	name := strings.TrimPrefix(ident.Name, "ASSET_")
+	for _, a := range program.Assets {
+		if a.Name == name && a.Type == "sample" {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// checkSfxPlay validates sfx.play. With a `sample` asset it is the sample
+// channel builtin; otherwise it must resolve to the sfx module's
+// sfx.play(channel).
+func (a *SemanticAnalyzer) checkSfxPlay(call *CallExpr) {
+	if _, ok := sampleAssetArg(a.program, call); ok {
+		if len(call.Args) > 2 {
+			a.addDiagnostic(call.Position, CategoryValidationError, "E_SFX_ARGS", "sfx.play takes a sample asset and an optional volume (0-255)", "")
+		}
+		return
+	}
+	if !a.isRequestedModule("sfx") {
+		a.addDiagnostic(call.Position, CategoryAssetReferenceError, "E_SFX_ASSET", "sfx.play needs a `sample` asset, e.g. sfx.play(ASSET_Jump)", "")
+	}
+}
+
+// generateSfxPlay starts a sample asset on the sample channel: it latches
+// the asset's ROM address, length and rate, sets the volume (255 unless
+// given) and writes SAMPLE_CONTROL's PLAY bit, restarting the channel if it
+// was already playing. Clobbers R0, R6 and R7.
+func (cg *CodeGenerator) generateSfxPlay(name string, call *CallExpr) error {
+	asset, ok := cg.sampleAssets[name]
+	if !ok {
+		return fmt.Errorf("sfx.play: sample asset %s was not loaded", name)
+	}
+	if len(call.Args) == 2 {
+		if err := cg.generateExpr(call.Args[1], 0); err != nil {
+			return err
+		}
+	} else {
+		cg.hMovImm(0, 0xFF)
+	}
+	cg.storeIOByte(hwdef.SAMPLE_VOLUME, 0)
+	for _, w := range []struct {
+		addr  uint16
+		value uint16
+	}{
+		{hwdef.SAMPLE_BANK, uint16(asset.Bank)},
+		{hwdef.SAMPLE_ADDR_L, asset.Offset & 0xFF},
+		{hwdef.SAMPLE_ADDR_H, asset.Offset >> 8},
+		{hwdef.SAMPLE_LENGTH_L, uint16(asset.Length) & 0xFF},
+		{hwdef.SAMPLE_LENGTH_H, uint16(asset.Length) >> 8},
+		{hwdef.SAMPLE_RATE_L, uint16(asset.Rate) & 0xFF},
+		{hwdef.SAMPLE_RATE_H, uint16(asset.Rate) >> 8},
+		{hwdef.SAMPLE_CONTROL, 0x01},
+	} {
+		cg.hMovImm(6, w.value)
+		cg.storeIOByte(w.addr, 6)
+	}
+	return nil
+}
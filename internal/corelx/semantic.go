@@ -2,6 +2,8 @@ package corelx
 
 import (
 	"fmt"
+
+	"nitro-core-dx/internal/hwdef"
 )
 
 // SemanticAnalyzer performs semantic analysis
@@ -10,6 +12,11 @@ type SemanticAnalyzer struct {
 	symbols     map[string]*Symbol
 	diagnostics []Diagnostic
 	currentFunc *FunctionDecl
+
+	// Integer and fixed-point constant values, for the integer type checks
+	// in typecheck.go.
+	constVals  map[string]int64
+	constFixed map[string]bool
 }
 
 // Symbol represents a symbol in the symbol table
@@ -36,6 +43,8 @@ func AnalyzeWithDiagnostics(program *Program) []Diagnostic {
 		program:     program,
 		symbols:     make(map[string]*Symbol),
 		diagnostics: make([]Diagnostic, 0),
+		constVals:   make(map[string]int64),
+		constFixed:  make(map[string]bool),
 	}
 
 	// Register built-in types
@@ -61,7 +70,7 @@ func AnalyzeWithDiagnostics(program *Program) []Diagnostic {
 
 	// Register top-level constants and globals so identifier references and
 	// constant expressions validate. Errors surface as diagnostics.
-	constVals := make(map[string]int64)
+	constVals := analyzer.constVals
 	for _, c := range program.Consts {
 		if _, dup := analyzer.symbols[c.Name]; dup {
 			analyzer.addDiagnostic(c.Position, CategorySymbolError, "E_CONST_DUPLICATE", fmt.Sprintf("duplicate declaration of %s", c.Name), "")
@@ -70,8 +79,9 @@ func AnalyzeWithDiagnostics(program *Program) []Diagnostic {
 		if _, err := evalConstExpr(c.Value, constVals); err != nil {
 			analyzer.addDiagnostic(c.Position, CategoryValidationError, "E_CONST_NOT_CONSTANT", fmt.Sprintf("const %s: %v", c.Name, err), "")
 		} else {
-			v, _ := evalConstExpr(c.Value, constVals)
+			v, isFixed, _ := evalConstExprTyped(c.Value, constVals, analyzer.constFixed)
 			constVals[c.Name] = v
+			analyzer.constFixed[c.Name] = isFixed
 		}
 		analyzer.symbols[c.Name] = &Symbol{Name: c.Name, Position: c.Position}
 	}
@@ -83,12 +93,24 @@ func AnalyzeWithDiagnostics(program *Program) []Diagnostic {
 		if _, err := globalTypeSize(g.TypeName); err != nil {
 			analyzer.addDiagnostic(g.Position, CategoryTypeError, "E_GLOBAL_TYPE", fmt.Sprintf("global %s: %v", g.Name, err), "")
 		}
-		analyzer.symbols[g.Name] = &Symbol{Name: g.Name, Position: g.Position}
+		analyzer.symbols[g.Name] = &Symbol{Name: g.Name, Type: &NamedType{Name: g.TypeName}, Position: g.Position}
+		analyzer.checkIntStore(g.Position, "global "+g.Name, g.TypeName, g.Init)
+		for _, el := range g.InitList {
+			analyzer.checkIntStore(g.Position, "global "+g.Name, g.TypeName, el)
+		}
 	}
 
 	// Register/analyze function declarations (name collisions first)
 	analyzer.registerFunctionDecls()
 
+	// Hardware register names resolve to their I/O address unless the
+	// program declares the name itself.
+	for _, reg := range hwdef.Registers() {
+		if _, exists := analyzer.symbols[reg.Name]; !exists {
+			analyzer.symbols[reg.Name] = &Symbol{Name: reg.Name}
+		}
+	}
+
 	// Analyze functions
 	for _, fn := range program.Functions {
 		analyzer.analyzeFunction(fn)
@@ -106,6 +128,8 @@ func AnalyzeWithDiagnostics(program *Program) []Diagnostic {
 		analyzer.addDiagnostic(Position{}, CategoryValidationError, "E_MISSING_ENTRYPOINT", "missing required function: Start() or __Boot()", "")
 	}
 
+	analyzer.lintProgram()
+
 	return analyzer.diagnostics
 }
 
@@ -124,43 +148,65 @@ func (a *SemanticAnalyzer) registerBuiltinTypes() {
 	}
 }
 
+// builtinFunctions lists every built-in the compiler accepts. Built-in
+// functions are handled by the code generator; this list is just for
+// semantic checking.
+var builtinFunctions = []string{
+	"Start", "__Boot", // Entry points
+	"int", "fixed", // charter D4 numeric conversions
+	"text.draw", "text.draw_int", "text.print_number", // HUD text via the text port
+	"dec.digits", // binary to decimal digits (see decimal.go)
+	"wait_vblank", "frame_counter", "reset_cause",
+	"sprite.set_pos", "sprite.set_size", "sprite.attr", "sprite.ctrl", "oam.write", "oam.write_sprite_data", "oam.clear_sprite", "oam.flush",
+	// LEGACY (scaffolding): apu.* drives the legacy 4-channel synth and is
+	// transitional only. The final audio subsystem is YM2608/OPNA; these
+	// builtins are pending replacement by the future music.* YM2608 API.
+	"apu.enable", "apu.set_channel_wave", "apu.set_channel_freq",
+	"apu.set_channel_volume", "apu.note_on", "apu.note_off",
+	// YM2608 audio subsystem: low-level register escape hatch (port 0 / port 1).
+	"ym.write", "ym.write_port1",
+	// YM2608 audio subsystem: high-level song playback over an external
+	// .ncdxmusic asset (registered here only as each builtin's codegen
+	// case lands, to avoid a registered-but-uncodegen'd gap).
+	"music.play", "music.play_loop", "music.stop", "music.set_volume", "music.fade_to", "music.play_jingle",
+	// Sprite animation over `anim` assets (compiler-emitted runtime, see anim.go).
+	"anim.play", "anim.stop", "anim.update",
+	// Sampled sound effects over `sample` assets (see sample_assets.go).
+	"sfx.play",
+	// Master low-pass filter and echo (apu.MasterEffects, 0x9031-0x9034).
+	"audio.set_lowpass", "audio.set_echo",
+	// Host-side save data over the PERSIST_* mailbox (see persist.go).
+	"persist.set", "persist.get",
+	// printf-style debugging over the DEBUG_* console (see debugprint.go).
+	"debug.print",
+	"ppu.enable_display", "ppu.set_border_color", "gfx.load_tiles", "gfx.load_tiles_compressed", "gfx.set_palette", "gfx.set_palette_color", "gfx.init_default_palettes",
+	"boot.show_default",
+	"input.read", "input.poll", "input.held", "input.pressed", "input.released",
+	"SPR_PAL", "SPR_HFLIP", "SPR_VFLIP", "SPR_PRI",
+	"SPR_ENABLE", "SPR_SIZE_8", "SPR_SIZE_16",
+	"SPR_SIZE_32X16", "SPR_SIZE_32X32", "SPR_SIZE_64X32", "SPR_SIZE_64X64", "SPR_SIZE_128X64", "SPR_SIZE_128X128",
+	"SPR_BLEND", "SPR_ALPHA",
+	"mem.write", "mem.read", "mem.write16", "mem.read16",
+	"bg.set_scroll", "bg.enable", "bg.disable", "bg.set_priority", "bg.set_tilemap_base", "bg.load_tilemap", "bg.set_source_mode", "bg.load_image", "bg.set_line_scroll", "bg.clear_line_scroll", "bg.bind_transform", "bg.set_tile_size",
+	"bg.set_tile", "bg.fill_span", "bg.clear", "bg.tile_at",
+	"phys.aabb",
+	"fx.mul", "fx.div", "fx.sin", "fx.cos",
+	"matrix_plane.enable", "matrix_plane.disable", "matrix_plane.load_bitmap", "matrix_plane.set_projection", "matrix_plane.set_depth", "matrix_plane.set_camera", "matrix_plane.set_surface", "matrix_plane.set_flags", "matrix_plane.load_tiles", "matrix_plane.load_tilemap", "matrix_plane.set_tile", "matrix_plane.fill_rect", "matrix_plane.clear",
+	"raster.enable", "raster.disable",
+	"raster.set_scanline_scroll", "raster.set_scanline_matrix", "raster.set_scanline_center", "raster.set_scanline_tilemap_base",
+	"raster.set_scanline_rebind", "raster.set_scanline_priority", "raster.set_scanline_source_mode",
+	"matrix.enable", "matrix.disable", "matrix.bind", "matrix.set_matrix", "matrix.set_center", "matrix.identity", "matrix.set_flags",
+}
+
+// BuiltinFunctions returns the names of every CoreLX built-in, in
+// registration order, so tools can keep their references in step with the
+// compiler.
+func BuiltinFunctions() []string {
+	return append([]string(nil), builtinFunctions...)
+}
+
 func (a *SemanticAnalyzer) registerBuiltinFunctions() {
-	// Built-in functions will be handled by code generator
-	// This is just for semantic checking
-	builtins := []string{
-		"Start", "__Boot", // Entry points
-		"int", "fixed", // charter D4 numeric conversions
-		"text.draw", "text.draw_int", // HUD text via the text port
-		"wait_vblank", "frame_counter",
-		"sprite.set_pos", "sprite.set_size", "oam.write", "oam.write_sprite_data", "oam.clear_sprite", "oam.flush",
-		// LEGACY (scaffolding): apu.* drives the legacy 4-channel synth and is
-		// transitional only. The final audio subsystem is YM2608/OPNA; these
-		// builtins are pending replacement by the future music.* YM2608 API.
-		"apu.enable", "apu.set_channel_wave", "apu.set_channel_freq",
-		"apu.set_channel_volume", "apu.note_on", "apu.note_off",
-		// YM2608 audio subsystem: low-level register escape hatch (port 0 / port 1).
-		"ym.write", "ym.write_port1",
-		// YM2608 audio subsystem: high-level song playback over an external
-		// .ncdxmusic asset (registered here only as each builtin's codegen
-		// case lands, to avoid a registered-but-uncodegen'd gap).
-		"music.play", "music.play_loop", "music.stop", "music.set_volume", "music.fade_to", "music.play_jingle",
-		"ppu.enable_display", "gfx.load_tiles", "gfx.set_palette", "gfx.set_palette_color", "gfx.init_default_palettes",
-		"boot.show_default",
-		"input.read", "input.poll", "input.held", "input.pressed", "input.released",
-		"SPR_PAL", "SPR_HFLIP", "SPR_VFLIP", "SPR_PRI",
-		"SPR_ENABLE", "SPR_SIZE_8", "SPR_SIZE_16",
-		"SPR_SIZE_32X16", "SPR_SIZE_32X32", "SPR_SIZE_64X32", "SPR_SIZE_64X64", "SPR_SIZE_128X64", "SPR_SIZE_128X128",
-		"SPR_BLEND", "SPR_ALPHA",
-		"mem.write", "mem.read", "mem.write16", "mem.read16",
-		"bg.set_scroll", "bg.enable", "bg.disable", "bg.set_priority", "bg.set_tilemap_base", "bg.load_tilemap", "bg.set_source_mode", "bg.bind_transform", "bg.set_tile_size",
-		"bg.set_tile", "bg.fill_span", "bg.clear",
-		"matrix_plane.enable", "matrix_plane.disable", "matrix_plane.load_bitmap", "matrix_plane.set_projection", "matrix_plane.set_depth", "matrix_plane.set_camera", "matrix_plane.set_surface", "matrix_plane.set_flags", "matrix_plane.load_tiles", "matrix_plane.load_tilemap", "matrix_plane.set_tile", "matrix_plane.fill_rect", "matrix_plane.clear",
-		"raster.enable", "raster.disable",
-		"raster.set_scanline_scroll", "raster.set_scanline_matrix", "raster.set_scanline_center", "raster.set_scanline_tilemap_base",
-		"raster.set_scanline_rebind", "raster.set_scanline_priority", "raster.set_scanline_source_mode",
-		"matrix.enable", "matrix.disable", "matrix.bind", "matrix.set_matrix", "matrix.set_center", "matrix.identity", "matrix.set_flags",
-	}
-	for _, name := range builtins {
+	for _, name := range builtinFunctions {
 		a.symbols[name] = &Symbol{
 			Name:      name,
 			IsFunc:    true,
@@ -240,10 +286,17 @@ func (a *SemanticAnalyzer) registerFunctionDecls() {
 
 func (a *SemanticAnalyzer) analyzeFunction(fn *FunctionDecl) {
 	if fn.Name == "Start" || fn.Name == "__Boot" {
+		if fn.Extern {
+			a.addDiagnostic(fn.Position, CategoryValidationError, "E_EXTERN_ENTRY", fmt.Sprintf("entry function %s() cannot be extern", fn.Name), "")
+		}
+		if fn.Bank > 1 {
+			a.addDiagnostic(fn.Position, CategoryValidationError, "E_BANK_ENTRY", fmt.Sprintf("entry function %s() must stay in bank 1 (the reset vector), not #[bank(%d)]", fn.Name, fn.Bank), "")
+		}
 		if len(fn.Params) > 0 {
 			a.addDiagnostic(fn.Position, CategoryValidationError, "E_ENTRY_PARAMS", fmt.Sprintf("function %s() must have no parameters", fn.Name), "")
 		}
 	}
+	a.checkGameLoopHook(fn)
 
 	oldFunc := a.currentFunc
 	a.currentFunc = fn
@@ -258,7 +311,12 @@ func (a *SemanticAnalyzer) analyzeFunction(fn *FunctionDecl) {
 	}()
 
 	// Register parameters as local variables.
+	if fn.Extern && len(fn.Params) > 6 {
+		a.addDiagnostic(fn.Position, CategoryValidationError, "E_EXTERN_PARAMS", fmt.Sprintf("extern function %s: too many parameters (max 6, passed in R0-R5)", fn.Name), "")
+	}
+	a.checkDeclaredType(fn.Position, fmt.Sprintf("function %s result", fn.Name), fn.ReturnType)
 	for _, param := range fn.Params {
+		a.checkDeclaredType(param.Position, "parameter "+param.Name, param.Type)
 		a.symbols[param.Name] = &Symbol{
 			Name:     param.Name,
 			Type:     param.Type,
@@ -282,6 +340,8 @@ func (a *SemanticAnalyzer) analyzeStmt(stmt Stmt) {
 			var varType TypeExpr
 			if s.Type != nil {
 				varType = s.Type
+				a.checkDeclaredType(s.Position, "variable "+s.Name, s.Type)
+				a.checkIntStore(s.Position, s.Name, intTypeName(s.Type), s.Value)
 			} else {
 				// Infer type from value
 				varType = a.inferType(s.Value)
@@ -297,6 +357,9 @@ func (a *SemanticAnalyzer) analyzeStmt(stmt Stmt) {
 	case *AssignStmt:
 		a.analyzeExpr(s.Target)
 		a.analyzeExpr(s.Value)
+		if target := inferInt(a, s.Target); target.isInt() {
+			a.checkIntStore(s.Position, assignTargetName(s.Target), target.typ, s.Value)
+		}
 
 	case *IfStmt:
 		a.analyzeExpr(s.Condition)
@@ -321,7 +384,7 @@ func (a *SemanticAnalyzer) analyzeStmt(stmt Stmt) {
 
 	case *ForStmt:
 		// BASIC counting loop: the loop variable is a fresh name in scope.
-		a.symbols[s.VarName] = &Symbol{Name: s.VarName, Position: s.Position}
+		a.symbols[s.VarName] = &Symbol{Name: s.VarName, Type: &NamedType{Name: "int"}, Position: s.Position}
 		a.analyzeExpr(s.Start)
 		a.analyzeExpr(s.End)
 		if s.Step != nil {
@@ -334,6 +397,9 @@ func (a *SemanticAnalyzer) analyzeStmt(stmt Stmt) {
 	case *ReturnStmt:
 		if s.Value != nil {
 			a.analyzeExpr(s.Value)
+			if a.currentFunc != nil {
+				a.checkIntStore(s.Position, a.currentFunc.Name+" result", intTypeName(a.currentFunc.ReturnType), s.Value)
+			}
 		}
 
 	case *ExprStmt:
@@ -346,6 +412,7 @@ func (a *SemanticAnalyzer) analyzeExpr(expr Expr) {
 	case *BinaryExpr:
 		a.analyzeExpr(e.Left)
 		a.analyzeExpr(e.Right)
+		a.checkCompare(e)
 
 	case *UnaryExpr:
 		a.analyzeExpr(e.Operand)
@@ -355,6 +422,20 @@ func (a *SemanticAnalyzer) analyzeExpr(expr Expr) {
 		for _, arg := range e.Args {
 			a.analyzeExpr(arg)
 		}
+		if name := callFuncName(e); isSpriteFieldsCall(name) {
+			a.checkSpriteFields(name, e)
+		} else if name == "sfx.play" {
+			a.checkSfxPlay(e)
+		} else if name == "persist.set" || name == "persist.get" {
+			a.checkPersistCall(name, e)
+		} else if name == "debug.print" {
+			a.checkDebugPrint(e)
+		} else if e.ArgNames != nil {
+			a.addDiagnostic(e.Position, CategoryValidationError, "E_NAMED_ARG", fmt.Sprintf("%s does not take named arguments", name), "")
+		}
+		a.checkBuiltinCall(e)
+		a.checkCallArgs(e)
+		a.checkGameLoopWait(e)
 
 	case *MemberExpr:
 		a.analyzeExpr(e.Object)
@@ -368,11 +449,6 @@ func (a *SemanticAnalyzer) analyzeExpr(expr Expr) {
 
 	case *IdentExpr:
 		// Check if it's a built-in namespace (ppu, sprite, oam, apu, gfx)
-		builtinNamespaces := map[string]bool{
-			"ppu": true, "sprite": true, "oam": true, "apu": true, "gfx": true, "input": true,
-			"mem": true, "bg": true, "matrix": true, "matrix_plane": true, "raster": true,
-			"text": true, "ym": true, "music": true, "boot": true,
-		}
 		if builtinNamespaces[e.Name] {
 			// Built-in namespace, valid
 			return
@@ -390,6 +466,14 @@ func (a *SemanticAnalyzer) analyzeExpr(expr Expr) {
 	}
 }
 
+// builtinNamespaces are the prefixes of the dotted built-ins (ppu.enable_display).
+var builtinNamespaces = map[string]bool{
+	"ppu": true, "sprite": true, "oam": true, "apu": true, "gfx": true, "input": true,
+	"mem": true, "bg": true, "matrix": true, "matrix_plane": true, "raster": true,
+	"text": true, "ym": true, "music": true, "boot": true, "anim": true, "phys": true, "fx": true,
+	"sfx": true, "persist": true, "debug": true, "audio": true, "dec": true,
+}
+
 // isRequestedModule reports whether name is one of the program's `--!
 // modules:` namespaces (charter D1).
 func (a *SemanticAnalyzer) isRequestedModule(name string) bool {
@@ -414,6 +498,18 @@ func (a *SemanticAnalyzer) addDiagnostic(pos Position, category DiagnosticCatego
 	})
 }
 
+func (a *SemanticAnalyzer) addWarning(pos Position, category DiagnosticCategory, code, message string) {
+	a.diagnostics = append(a.diagnostics, Diagnostic{
+		Category: category,
+		Code:     code,
+		Message:  message,
+		Line:     pos.Line,
+		Column:   pos.Column,
+		Severity: SeverityWarning,
+		Stage:    StageSemantic,
+	})
+}
+
 func (a *SemanticAnalyzer) addDuplicateDiagnostic(pos Position, category DiagnosticCategory, code, message, file string, previous Position, previousMsg string) {
 	d := Diagnostic{
 		Category: category,
@@ -436,26 +532,16 @@ func (a *SemanticAnalyzer) addDuplicateDiagnostic(pos Position, category Diagnos
 	a.diagnostics = append(a.diagnostics, d)
 }
 
+// inferType returns the type of a `:=` local: its initializer's integer
+// type (see localIntType), fixed, bool or a struct name, or nil when unknown.
 func (a *SemanticAnalyzer) inferType(expr Expr) TypeExpr {
-	switch e := expr.(type) {
-	case *NumberExpr:
-		// Default to i16 for integers
-		return &NamedType{Name: "i16"}
-	case *BoolExpr:
-		return &NamedType{Name: "bool"}
-	case *StringExpr:
-		// Strings are not directly supported, but we can use them for asset names
-		return &NamedType{Name: "u16"}
-	case *CallExpr:
-		// Try to infer from function return type
-		if ident, ok := e.Func.(*IdentExpr); ok {
-			if sym, exists := a.symbols[ident.Name]; exists && sym.IsFunc {
-				// For now, default to u16 for function calls
-				return &NamedType{Name: "u16"}
-			}
-		}
-		return &NamedType{Name: "u16"}
-	default:
-		return &NamedType{Name: "u16"}
+	x := inferInt(a, expr)
+	name := localIntType(x)
+	if name == "" && !x.konst {
+		name = x.typ
+	}
+	if name == "" {
+		return nil
 	}
+	return &NamedType{Name: name}
 }
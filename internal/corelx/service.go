@@ -19,6 +19,16 @@ func (s *Service) CompileBundleFile(sourcePath string, opts *CompileOptions) (Co
 	return BuildCompileBundle(res), res, err
 }
 
+// CompileBundle is Compile returning the compile bundle as well.
+func (s *Service) CompileBundle(source string, opts Options) (CompileBundle, *CompileResult, error) {
+	cfg, err := opts.CompileOptions()
+	if err != nil {
+		return CompileBundle{}, nil, err
+	}
+	res, err := CompileSource(source, opts.SourcePath, &cfg)
+	return BuildCompileBundle(res), res, err
+}
+
 func (s *Service) CompileBundleSource(source, sourcePath string, opts *CompileOptions) (CompileBundle, *CompileResult, error) {
 	res, err := CompileSource(source, sourcePath, opts)
 	return BuildCompileBundle(res), res, err
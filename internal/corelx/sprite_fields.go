@@ -0,0 +1,232 @@
+package corelx
+
+import (
+	"fmt"
+
+	"nitro-core-dx/internal/rom"
+)
+
+// sprite.attr(...) and sprite.ctrl(...) build an OAM attribute (byte 4) or
+// control (byte 5) value from named fields, e.g.
+//
+//	attr := sprite.attr(palette=1, hflip=true, priority=2)
+//
+// Constant fields are range-checked and folded at compile time, so a call
+// whose fields are all constants is just a constant. Other fields are
+// masked to their width and OR'd in at run time.
+
+// spriteField is one named field of an attr or ctrl value.
+type spriteField struct {
+	name  string
+	shift uint16
+	max   int64 // largest value; 1 for a flag
+}
+
+var spriteFieldSets = map[string][]spriteField{
+	"sprite.attr": {
+		{name: "palette", shift: 0, max: 15},
+		{name: "hflip", shift: 4, max: 1},
+		{name: "vflip", shift: 5, max: 1},
+		{name: "priority", shift: 6, max: 3},
+	},
+	"sprite.ctrl": {
+		{name: "enable", shift: 0, max: 1},
+		{name: "blend", shift: 2, max: 3},
+		{name: "alpha", shift: 4, max: 15},
+		// width/height select a size code; see spriteSizeCodes.
+		{name: "width"},
+		{name: "height"},
+	},
+}
+
+// spriteSizeCodes mirrors spriteSizeTable (internal/ppu/scanline.go): the
+// index is the sprite's 3-bit size code.
+var spriteSizeCodes = [8][2]int64{{8, 8}, {16, 16}, {32, 16}, {32, 32}, {64, 32}, {64, 64}, {128, 64}, {128, 128}}
+
+func isSpriteFieldsCall(name string) bool {
+	_, ok := spriteFieldSets[name]
+	return ok
+}
+
+// spriteSizeBits returns the ctrl-shaped bits for a size code, laid out like
+// the SPR_SIZE_* helpers: code bit 0 in bit 1, code bits 1/2 in bits 8/9
+// (see emitSizeCodeBits).
+func spriteSizeBits(code int) int64 {
+	return int64(code&1)<<1 | int64(code>>1&1)<<8 | int64(code>>2&1)<<9
+}
+
+// spriteFieldArg is a field whose value is only known at run time.
+type spriteFieldArg struct {
+	field spriteField
+	expr  Expr
+}
+
+// spriteFieldError is a problem with a sprite.attr/sprite.ctrl call.
+type spriteFieldError struct {
+	pos      Position
+	overflow bool // a constant out of its field's range
+	msg      string
+}
+
+// spriteFieldsValue is a resolved sprite.attr/sprite.ctrl call.
+type spriteFieldsValue struct {
+	konst   int64 // every constant field, shifted into place
+	runtime []spriteFieldArg
+}
+
+// resolveSpriteFields matches a call's named arguments to fields and folds
+// the constant ones. The value is usable even when errors are returned.
+func resolveSpriteFields(env intEnv, name string, call *CallExpr) (spriteFieldsValue, []spriteFieldError) {
+	var out spriteFieldsValue
+	var errs []spriteFieldError
+	fields := spriteFieldSets[name]
+	seen := map[string]bool{}
+	var width, height *int64
+	for i, arg := range call.Args {
+		argName := ""
+		if i < len(call.ArgNames) {
+			argName = call.ArgNames[i]
+		}
+		if argName == "" {
+			errs = append(errs, spriteFieldError{pos: arg.Pos(), msg: fmt.Sprintf("%s takes named fields only, e.g. %s=...", name, fields[0].name)})
+			continue
+		}
+		var field *spriteField
+		for j := range fields {
+			if fields[j].name == argName {
+				field = &fields[j]
+			}
+		}
+		if field == nil {
+			errs = append(errs, spriteFieldError{pos: arg.Pos(), msg: fmt.Sprintf("%s has no field %q (fields: %s)", name, argName, spriteFieldNames(fields))})
+			continue
+		}
+		if seen[argName] {
+			errs = append(errs, spriteFieldError{pos: arg.Pos(), msg: fmt.Sprintf("field %s given more than once", argName)})
+			continue
+		}
+		seen[argName] = true
+
+		value, konst := spriteFieldConst(env, arg)
+		if field.max == 0 {
+			// width/height
+			if !konst {
+				errs = append(errs, spriteFieldError{pos: arg.Pos(), msg: fmt.Sprintf("sprite %s must be a constant", argName)})
+				continue
+			}
+			v := value
+			if argName == "width" {
+				width = &v
+			} else {
+				height = &v
+			}
+			continue
+		}
+		if !konst {
+			out.runtime = append(out.runtime, spriteFieldArg{field: *field, expr: arg})
+			continue
+		}
+		if value < 0 || value > field.max {
+			errs = append(errs, spriteFieldError{pos: arg.Pos(), overflow: true, msg: fmt.Sprintf("%s=%d is out of range for %s (0 to %d)", argName, value, name, field.max)})
+			continue
+		}
+		out.konst |= value << field.shift
+	}
+
+	if (width == nil) != (height == nil) {
+		errs = append(errs, spriteFieldError{pos: call.Position, msg: "sprite size needs both width and height"})
+	} else if width != nil {
+		code := -1
+		for c, dims := range spriteSizeCodes {
+			if dims[0] == *width && dims[1] == *height {
+				code = c
+			}
+		}
+		if code < 0 {
+			errs = append(errs, spriteFieldError{pos: call.Position, msg: fmt.Sprintf("no %dx%d sprite size (sizes: 8x8, 16x16, 32x16, 32x32, 64x32, 64x64, 128x64, 128x128)", *width, *height)})
+		} else {
+			out.konst |= spriteSizeBits(code)
+		}
+	}
+	return out, errs
+}
+
+// spriteFieldConst returns the value of a constant field argument. true and
+// false count as 1 and 0.
+func spriteFieldConst(env intEnv, arg Expr) (int64, bool) {
+	if b, ok := arg.(*BoolExpr); ok {
+		if b.Value {
+			return 1, true
+		}
+		return 0, true
+	}
+	x := inferInt(env, arg)
+	return x.value, x.konst
+}
+
+func spriteFieldNames(fields []spriteField) string {
+	names := ""
+	for i, f := range fields {
+		if i > 0 {
+			names += ", "
+		}
+		names += f.name
+	}
+	return names
+}
+
+// spriteFieldsType is the inferred type of a sprite.attr/sprite.ctrl call:
+// a constant when every field is, else u8, or u16 when a size above 16x16
+// sets the ctrl value's high bits.
+func spriteFieldsType(env intEnv, name string, call *CallExpr) intExpr {
+	v, _ := resolveSpriteFields(env, name, call)
+	if len(v.runtime) == 0 {
+		return intExpr{konst: true, value: v.konst}
+	}
+	if v.konst > 0xFF {
+		return intExpr{typ: "u16"}
+	}
+	return intExpr{typ: "u8"}
+}
+
+// checkSpriteFields reports bad fields in a sprite.attr/sprite.ctrl call.
+func (a *SemanticAnalyzer) checkSpriteFields(name string, call *CallExpr) {
+	_, errs := resolveSpriteFields(a, name, call)
+	for _, err := range errs {
+		if err.overflow {
+			a.addDiagnostic(err.pos, CategoryOverflowError, "E_INT_OVERFLOW", err.msg, "")
+		} else {
+			a.addDiagnostic(err.pos, CategoryValidationError, "E_SPRITE_FIELD", err.msg, "")
+		}
+	}
+}
+
+// generateSpriteFieldsCall emits sprite.attr/sprite.ctrl. Runtime fields
+// are evaluated one at a time into R0 while the partial value waits on the
+// CPU stack, so a field may itself call functions. Clobbers R0 and R1.
+func (cg *CodeGenerator) generateSpriteFieldsCall(name string, call *CallExpr, destReg uint8) error {
+	v, errs := resolveSpriteFields(cg, name, call)
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", errs[0].msg)
+	}
+	if len(v.runtime) == 0 {
+		cg.hMovImm(destReg, uint16(v.konst))
+		return nil
+	}
+	cg.hMovImm(0, uint16(v.konst))
+	cg.builder.AddInstruction(rom.EncodeMOV(4, 0, 0)) // PUSH R0
+	for _, f := range v.runtime {
+		if err := cg.generateExpr(f.expr, 0); err != nil {
+			return err
+		}
+		cg.hAndImm(0, uint16(f.field.max))
+		if f.field.shift > 0 {
+			cg.hShlImm(0, f.field.shift)
+		}
+		cg.builder.AddInstruction(rom.EncodeMOV(5, 1, 0)) // POP R1
+		cg.builder.AddInstruction(rom.EncodeOR(0, 0, 1))
+		cg.builder.AddInstruction(rom.EncodeMOV(4, 0, 0)) // PUSH R0
+	}
+	cg.builder.AddInstruction(rom.EncodeMOV(5, destReg, 0)) // POP result
+	return nil
+}
@@ -0,0 +1,82 @@
+package corelx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSpriteFieldDiagnostics(t *testing.T) {
+	cases := []struct {
+		name string
+		expr string
+		want string
+	}{
+		{"attr", "sprite.attr(palette=15, hflip=true, priority=3)", ""},
+		{"ctrl with size", "sprite.ctrl(enable=true, width=128, height=64)", ""},
+		{"palette too big", "sprite.attr(palette=16)", "E_INT_OVERFLOW"},
+		{"flag as number", "sprite.attr(vflip=2)", "E_INT_OVERFLOW"},
+		{"unknown field", "sprite.attr(pallete=1)", "E_SPRITE_FIELD"},
+		{"repeated field", "sprite.ctrl(alpha=1, alpha=2)", "E_SPRITE_FIELD"},
+		{"positional", "sprite.attr(1)", "E_SPRITE_FIELD"},
+		{"bad size", "sprite.ctrl(width=16, height=8)", "E_SPRITE_FIELD"},
+		{"width alone", "sprite.ctrl(width=16)", "E_SPRITE_FIELD"},
+		{"runtime size", "sprite.ctrl(width=n, height=n)", "E_SPRITE_FIELD,E_SPRITE_FIELD"},
+		{"named arg elsewhere", "mem.read(addr=1)", "E_NAMED_ARG"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			source := "function Start()\n    n := 16\n    v := " + tc.expr + "\n"
+			result, _ := CompileSource(source, "fields.corelx", nil)
+			var codes []string
+			for _, d := range result.Diagnostics {
+				if d.Severity == SeverityError {
+					codes = append(codes, d.Code)
+				}
+			}
+			if got := strings.Join(codes, ","); got != tc.want {
+				t.Fatalf("diagnostics = %q, want %q (%+v)", got, tc.want, result.Diagnostics)
+			}
+		})
+	}
+}
+
+func TestSpriteFieldBuilders(t *testing.T) {
+	source := `var attr_const: int
+var attr_runtime: int
+var ctrl_const: int
+var ctrl_runtime: int
+
+function pal() -> int
+    return 0x13
+
+function Start()
+    flip := true
+    attr_const = sprite.attr(palette=1, hflip=true, priority=2)
+    attr_runtime = sprite.attr(palette=pal(), vflip=flip, priority=1)
+    ctrl_const = sprite.ctrl(enable=true, blend=1, alpha=8, width=32, height=32)
+    a := 3
+    ctrl_runtime = sprite.ctrl(enable=flip, alpha=a, width=16, height=16)
+    while true
+        a = a
+`
+	emu, result := compileAndBoot(t, source, 2000)
+	addrs := map[string]uint16{}
+	for _, e := range result.MemoryMap {
+		addrs[e.Name] = e.Address
+	}
+	for _, tc := range []struct {
+		name string
+		want uint16
+	}{
+		{"attr_const", 0x01 | 0x10 | 2<<6},
+		// pal() returns 0x13; only its low four bits are the palette.
+		{"attr_runtime", 0x03 | 0x20 | 1<<6},
+		// 32x32 is size code 3: bit 1 plus bit 8, as SPR_SIZE_32X32().
+		{"ctrl_const", 0x01 | 1<<2 | 8<<4 | 0x0102},
+		{"ctrl_runtime", 0x01 | 3<<4 | 0x02},
+	} {
+		if got := read16(emu, addrs[tc.name]); got != tc.want {
+			t.Errorf("%s = 0x%04X, want 0x%04X", tc.name, got, tc.want)
+		}
+	}
+}
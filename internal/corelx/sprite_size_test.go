@@ -81,3 +81,56 @@ function Start()
 		t.Fatalf("expected a visible sprite pixel at (63,63), got black/transparent")
 	}
 }
+
+// TestNegativeSpriteXEndToEnd checks that negative X reaches OAM as a
+// 9-bit signed value (low byte plus the X-high sign bit) whether it is a
+// literal argument, a variable, or set through sprite.set_pos. A negative
+// literal in the x argument used to arrive as 0: unary minus negated in R1,
+// the register that argument is evaluated into.
+func TestNegativeSpriteXEndToEnd(t *testing.T) {
+	source := `function Start()
+    ctrl := SPR_ENABLE() | SPR_SIZE_16()
+    pal := SPR_PAL(0)
+    wait_vblank()
+    oam.write_sprite_data(0, -10, 60, 0, pal, ctrl)
+    x := -3
+    oam.write_sprite_data(1, x, 60, 0, pal, ctrl)
+    s := Sprite()
+    sprite.set_pos(s, -7, 20)
+    s.ctrl = ctrl
+    oam.write(2, s)
+    while true
+        wait_vblank()
+`
+	dir := t.TempDir()
+	srcPath := filepath.Join(dir, "negative_x.corelx")
+	if err := os.WriteFile(srcPath, []byte(source), 0644); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	result, err := CompileProject(srcPath, nil)
+	if err != nil {
+		t.Fatalf("compile: %v (diagnostics: %+v)", err, result.Diagnostics)
+	}
+	emu := emulator.NewEmulator()
+	emu.SetFrameLimit(false)
+	if err := emu.LoadROM(result.ROMBytes); err != nil {
+		t.Fatalf("LoadROM: %v", err)
+	}
+	emu.Start()
+	for i := 0; i < 300; i++ {
+		if err := emu.RunFrame(); err != nil {
+			t.Fatalf("RunFrame: %v", err)
+		}
+	}
+
+	for i, want := range []int{-10, -3, -7} {
+		if got := emu.PPU.SpriteX(i); got != want {
+			t.Errorf("sprite %d: X = %d (OAM % X), want %d", i, got, emu.PPU.OAM[i*6:i*6+2], want)
+		}
+	}
+	// oam.write_sprite_data also folds the size code into X-high, next to
+	// the sign bit: size code 1 (16x16) -> 0x02 | sign.
+	if xHigh := emu.PPU.OAM[1]; xHigh != 0x03 {
+		t.Errorf("sprite 0 X-high = 0x%02X, want 0x03 (sign + size code 1)", xHigh)
+	}
+}
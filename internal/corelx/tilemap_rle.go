@@ -0,0 +1,128 @@
+package corelx
+
+import (
+	"fmt"
+
+	"nitro-core-dx/internal/rom"
+)
+
+// RLE tilemaps: `asset Level: tilemap rle hex` packs the map run-length
+// encoded into the ROM data region (see packed_assets.go) instead of
+// compiling it into inline VRAM writes (three code words per byte), and
+// bg.load_tilemap / matrix_plane.load_tilemap call the shared __tilemaprle
+// routine to expand it into the destination data port.
+//
+// The stream works on 2-byte tilemap entries, so a run of the same tile
+// and attribute packs down however it is laid out:
+//
+//	0x00      end of stream
+//	0x01-0x7F n literal entries follow (2n bytes)
+//	0x80-0xFF one entry follows, repeated (n & 0x7F) + 2 times
+const (
+	tilemapRLEMaxLiteral = 0x7F
+	tilemapRLEMaxRun     = 0x7F + 2
+)
+
+// packTilemapRLE encodes a tilemap (2 bytes per entry) as an RLE stream.
+func packTilemapRLE(data []byte) ([]byte, error) {
+	if len(data)%2 != 0 {
+		return nil, fmt.Errorf("%d bytes is not a whole number of 2-byte tilemap entries", len(data))
+	}
+	entries := len(data) / 2
+	same := func(a, b int) bool {
+		return data[2*a] == data[2*b] && data[2*a+1] == data[2*b+1]
+	}
+
+	var out []byte
+	litStart, litLen := 0, 0
+	flush := func() {
+		if litLen > 0 {
+			out = append(out, byte(litLen))
+			out = append(out, data[2*litStart:2*(litStart+litLen)]...)
+			litLen = 0
+		}
+	}
+	for i := 0; i < entries; {
+		run := 1
+		for i+run < entries && run < tilemapRLEMaxRun && same(i, i+run) {
+			run++
+		}
+		if run >= 2 {
+			flush()
+			out = append(out, 0x80|byte(run-2), data[2*i], data[2*i+1])
+			i += run
+			continue
+		}
+		if litLen == 0 {
+			litStart = i
+		}
+		litLen++
+		i++
+		if litLen == tilemapRLEMaxLiteral {
+			flush()
+		}
+	}
+	flush()
+	return append(out, 0x00), nil
+}
+
+// emitTilemapRLELoad expands the packed asset into port (VRAM_DATA or
+// MATRIX_PLANE_DATA), whose address the caller has already programmed.
+// keepReg survives the call; R0-R4, R6 and R7 do not.
+func (cg *CodeGenerator) emitTilemapRLELoad(asset *PackedAsset, port uint16, keepReg uint8) {
+	cg.builder.AddInstruction(rom.EncodeMOV(4, keepReg, 0)) // PUSH keepReg
+	cg.hMovImm(0, asset.Offset)
+	cg.hMovImm(1, uint16(asset.Bank))
+	cg.hMovImm(2, port)
+	cg.needTilemapRLE = true
+	cg.emitHelperCall("__tilemaprle")
+	cg.builder.AddInstruction(rom.EncodeMOV(5, keepReg, 0)) // POP keepReg
+}
+
+// emitTilemapRLEHelper emits __tilemaprle: decode the RLE stream at R1:R0
+// (bank:offset) into the I/O data port in R2. R2 is copied to R6 before
+// anything else, since a wide CALL has already clobbered R6/R7 on the way
+// in. Clobbers R0-R4, R6 and R7.
+func (cg *CodeGenerator) emitTilemapRLEHelper() {
+	cg.recordFuncAddr("__tilemaprle")
+
+	writePort := func(src uint8) {
+		cg.builder.AddInstruction(rom.EncodeMOV(3, 6, src))
+	}
+
+	cg.builder.AddInstruction(rom.EncodeMOV(0, 6, 2)) // R6 = port
+
+	next := cg.builder.GetCodeLength()
+	cg.emitPackedReadByte(2) // R2 = control byte
+	cg.hCmpImm(2, 0)
+	done := cg.hBranch(rom.EncodeBEQ())
+	cg.builder.AddInstruction(rom.EncodeMOV(0, 3, 2))
+	cg.hAndImm(3, 0x80)
+	cg.hCmpImm(3, 0)
+	isRun := cg.hBranch(rom.EncodeBNE())
+
+	// Literal: R2 entries copied straight through.
+	literal := cg.builder.GetCodeLength()
+	cg.emitPackedReadByte(3)
+	writePort(3)
+	cg.emitPackedReadByte(3)
+	writePort(3)
+	cg.emitCountdownLoop(2, literal)
+	cg.hJumpBack(next)
+
+	// Run: one entry written (R2 & 0x7F) + 2 times.
+	cg.hPatchToHere(isRun)
+	cg.hAndImm(2, 0x7F)
+	cg.builder.AddInstruction(rom.EncodeADD(1, 2, 0))
+	cg.builder.AddImmediate(2)
+	cg.emitPackedReadByte(3)
+	cg.emitPackedReadByte(4)
+	run := cg.builder.GetCodeLength()
+	writePort(3)
+	writePort(4)
+	cg.emitCountdownLoop(2, run)
+	cg.hJumpBack(next)
+
+	cg.hPatchToHere(done)
+	cg.builder.AddInstruction(rom.EncodeRET())
+}
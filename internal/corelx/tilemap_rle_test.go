@@ -0,0 +1,152 @@
+package corelx
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// unpackTilemapRLE is the Go twin of __tilemaprle.
+func unpackTilemapRLE(t *testing.T, packed []byte) []byte {
+	t.Helper()
+	var out []byte
+	for i := 0; ; {
+		c := packed[i]
+		i++
+		switch {
+		case c == 0:
+			if i != len(packed) {
+				t.Fatalf("end marker at %d of %d bytes", i-1, len(packed))
+			}
+			return out
+		case c&0x80 == 0:
+			out = append(out, packed[i:i+2*int(c)]...)
+			i += 2 * int(c)
+		default:
+			for n := int(c&0x7F) + 2; n > 0; n-- {
+				out = append(out, packed[i], packed[i+1])
+			}
+			i += 2
+		}
+	}
+}
+
+// testTilemap is a 32x32 map of tile 1 (attribute 0x10) with a border row
+// of distinct tiles and a few lone tiles, and its asset source lines.
+func testTilemap() ([]byte, string) {
+	raw := make([]byte, 32*32*2)
+	for i := 0; i < 32*32; i++ {
+		raw[2*i], raw[2*i+1] = 0x01, 0x10
+		if i < 32 {
+			raw[2*i] = byte(0x40 + i)
+		}
+	}
+	raw[2*300], raw[2*700+1] = 0x09, 0x23
+	// Quoted lines, since the lexer splits bare bytes like 4A.
+	var hex strings.Builder
+	for i := 0; i < len(raw); i += 32 {
+		fmt.Fprintf(&hex, "\n    \"% X \"", raw[i:i+32])
+	}
+	return raw, hex.String()
+}
+
+func TestPackTilemapRLERoundTrips(t *testing.T) {
+	raw, _ := testTilemap()
+	cases := map[string][]byte{
+		"map":       raw,
+		"empty":     nil,
+		"one entry": {0x05, 0x06},
+		"long run":  bytes.Repeat([]byte{0x02, 0x00}, 1000),
+		"literals": func() []byte {
+			b := make([]byte, 600)
+			for i := range b {
+				b[i] = byte(i)
+			}
+			return b
+		}(),
+	}
+	for name, data := range cases {
+		packed, err := packTilemapRLE(data)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if got := unpackTilemapRLE(t, packed); !bytes.Equal(got, data) {
+			t.Errorf("%s: round trip gave %d bytes, want %d", name, len(got), len(data))
+		}
+	}
+
+	packed, _ := packTilemapRLE(raw)
+	if len(packed) > 200 {
+		t.Errorf("32x32 map packed to %d bytes, want it well under its 2048", len(packed))
+	}
+	if _, err := packTilemapRLE([]byte{1, 2, 3}); err == nil {
+		t.Error("odd-length tilemap packed without error")
+	}
+}
+
+func TestRLETilemapLoadsIntoVRAMAndMatrixPlane(t *testing.T) {
+	raw, hex := testTilemap()
+	source := func(compression string) string {
+		return `asset Level: tilemap ` + compression + `hex` + hex + `
+
+function Start()
+    bg.set_tilemap_base(1, 0x4800)
+    base := bg.load_tilemap(ASSET_Level, 1)
+    if base == 0x4800
+        matrix_plane.enable(2, 32)
+        matrix_plane.load_tilemap(ASSET_Level, 2)
+    while true
+        wait_vblank()
+`
+	}
+
+	emu, packed := compileLoadForTest(t, source("rle "))
+	emu.SetFrameLimit(false)
+	emu.Start()
+	for i := 0; i < 3; i++ {
+		if err := emu.RunFrame(); err != nil {
+			t.Fatalf("RunFrame: %v", err)
+		}
+	}
+	if got := emu.PPU.VRAM[0x4800 : 0x4800+len(raw)]; !bytes.Equal(got, raw) {
+		t.Fatalf("VRAM tilemap differs from the asset (first bytes % X)", got[:8])
+	}
+	plane := emu.PPU.MatrixPlanes[2]
+	if !plane.Enabled {
+		t.Fatal("bg.load_tilemap did not return the layer's tilemap base")
+	}
+	if got := plane.Tilemap[:len(raw)]; !bytes.Equal(got, raw) {
+		t.Fatalf("matrix plane tilemap differs from the asset (first bytes % X)", got[:8])
+	}
+	if emu.CPU.State.DBR != 0 {
+		t.Errorf("DBR = %d after the load, want 0", emu.CPU.State.DBR)
+	}
+
+	// Both loads of the inline map cost three code words per byte; the
+	// packed one costs its stream plus the decoder.
+	_, inline := compileLoadForTest(t, source(""))
+	stream, _ := packTilemapRLE(raw)
+	rleBytes := codeSectionBytes(t, packed) + uint32(len(stream))
+	if inlineBytes := codeSectionBytes(t, inline); rleBytes*4 > inlineBytes {
+		t.Errorf("RLE build uses %d bytes of ROM, inline build %d; want the RLE one far smaller", rleBytes, inlineBytes)
+	}
+}
+
+func codeSectionBytes(t *testing.T, result *CompileResult) uint32 {
+	t.Helper()
+	for _, s := range result.Manifest.Sections {
+		if s.Name == "code" {
+			return s.UsedBytes
+		}
+	}
+	t.Fatal("manifest has no code section")
+	return 0
+}
+
+func TestRLECompressionOnlyForTilemaps(t *testing.T) {
+	_, err := CompileSource("asset Tiles: tiles8 rle hex\n    00 00\n\nfunction Start()\n    wait_vblank()\n", "rle.corelx", nil)
+	if err == nil || !strings.Contains(err.Error(), "only supported for tilemap") {
+		t.Fatalf("rle tiles8 asset: err = %v", err)
+	}
+}
@@ -0,0 +1,218 @@
+package corelx
+
+import (
+	"fmt"
+	"strings"
+
+	"nitro-core-dx/internal/hwdef"
+	"nitro-core-dx/internal/rom"
+)
+
+// LZ tiles: `asset Sheet: tiles16 lz hex` packs a sheet of tiles LZ
+// compressed into the ROM data region (see packed_assets.go), and
+// gfx.load_tiles_compressed(asset, base) calls the shared __tileslz routine
+// to expand all of it into VRAM from tile index base. Unlike gfx.load_tiles,
+// which loads one tile, a compressed asset may hold any whole number of
+// tiles (32 bytes each for tiles8, 128 for tiles16).
+//
+// The stream is byte-oriented. A copy re-reads bytes the decoder already
+// wrote, through VRAM_DATA, so the window costs no WRAM:
+//
+//	0x00      end of stream
+//	0x01-0x7F n literal bytes follow
+//	0x80-0xFF copy (n & 0x7F) + 4 bytes starting d bytes back, where d
+//	          (1..tilesLZWindow) is the next two bytes, little-endian
+const (
+	tilesLZMinMatch   = 4
+	tilesLZMaxMatch   = 0x7F + tilesLZMinMatch
+	tilesLZMaxLiteral = 0x7F
+	tilesLZWindow     = 4096
+)
+
+// Decoder cost in CPU cycles, measured on __tileslz (tiles_lz_test.go
+// checks the estimate against the emulator): a fixed cost per call and per
+// stream token, plus a cost per byte written. A copied byte costs more
+// than a literal one because it moves the VRAM address twice. A stream
+// crossing a ROM bank costs a few cycles more than this.
+const (
+	tilesLZCallCycles        = 33
+	tilesLZLiteralCycles     = 63
+	tilesLZLiteralByteCycles = 44
+	tilesLZCopyCycles        = 110
+	tilesLZCopyByteCycles    = 68
+	vblankCycles             = 20 * 581 // VBlank scanlines x CPU cycles per scanline
+	vblankCycles50Hz         = 64 * 581 // the same with `--! timing: 50hz`
+)
+
+// tileAssetBytes is the size of one tile of a tiles8 or tiles16 asset.
+func tileAssetBytes(kind string) int {
+	if kind == "tiles16" {
+		return 128
+	}
+	return 32
+}
+
+// packTilesLZ encodes a tiles8/tiles16 payload of whole tiles as an LZ
+// stream, greedily taking the longest match in the window at each byte.
+func packTilesLZ(kind string, data []byte) ([]byte, error) {
+	tile := tileAssetBytes(kind)
+	if len(data) == 0 || len(data)%tile != 0 {
+		return nil, fmt.Errorf("%d bytes is not a whole number of %d-byte tiles", len(data), tile)
+	}
+
+	var out []byte
+	litStart, litLen := 0, 0
+	flush := func() {
+		if litLen > 0 {
+			out = append(out, byte(litLen))
+			out = append(out, data[litStart:litStart+litLen]...)
+			litLen = 0
+		}
+	}
+	for i := 0; i < len(data); {
+		bestLen, bestDist := 0, 0
+		for j := i - 1; j >= 0 && i-j <= tilesLZWindow; j-- {
+			n := 0
+			for n < tilesLZMaxMatch && i+n < len(data) && data[j+n] == data[i+n] {
+				n++
+			}
+			if n > bestLen {
+				bestLen, bestDist = n, i-j
+				if n == tilesLZMaxMatch {
+					break
+				}
+			}
+		}
+		if bestLen >= tilesLZMinMatch {
+			flush()
+			out = append(out, 0x80|byte(bestLen-tilesLZMinMatch), byte(bestDist), byte(bestDist>>8))
+			i += bestLen
+			continue
+		}
+		if litLen == 0 {
+			litStart = i
+		}
+		litLen++
+		i++
+		if litLen == tilesLZMaxLiteral {
+			flush()
+		}
+	}
+	flush()
+	return append(out, 0x00), nil
+}
+
+// tilesLZCycles estimates how many CPU cycles __tileslz takes to expand a
+// packed stream, from the per-token and per-byte costs above.
+func tilesLZCycles(packed []byte) int {
+	cycles := tilesLZCallCycles
+	for i := 0; i < len(packed) && packed[i] != 0; {
+		c := int(packed[i])
+		if c&0x80 == 0 {
+			cycles += tilesLZLiteralCycles + c*tilesLZLiteralByteCycles
+			i += 1 + c
+		} else {
+			cycles += tilesLZCopyCycles + (c&0x7F+tilesLZMinMatch)*tilesLZCopyByteCycles
+			i += 3
+		}
+	}
+	return cycles
+}
+
+// generateLoadTilesCompressed handles gfx.load_tiles_compressed(asset,
+// base): the asset must be an ASSET_* literal naming an lz tile asset. The
+// VRAM address is base times the asset's tile size, as for gfx.load_tiles;
+// returns base.
+func (cg *CodeGenerator) generateLoadTilesCompressed(args []Expr, destReg uint8) error {
+	if len(args) != 2 {
+		return fmt.Errorf("gfx.load_tiles_compressed requires 2 arguments (asset, base)")
+	}
+	ident, ok := args[0].(*IdentExpr)
+	if !ok {
+		return fmt.Errorf("gfx.load_tiles_compressed needs an ASSET_* constant naming an lz tile asset")
+	}
+	name := strings.TrimPrefix(ident.Name, "ASSET_")
+	asset, ok := cg.assets[name]
+	if !ok {
+		return fmt.Errorf("gfx.load_tiles_compressed: unknown asset %s", ident.Name)
+	}
+	packed, ok := cg.packedAssets[name]
+	if !ok || packed.Compression != "lz" {
+		return fmt.Errorf("gfx.load_tiles_compressed: asset %s is not lz compressed (declare it `asset %s: %s lz ...`, or load it with gfx.load_tiles)", name, name, asset.Type)
+	}
+
+	if err := cg.generateExpr(args[1], 1); err != nil {
+		return err
+	}
+	shift := uint16(5)
+	if asset.Type == "tiles16" {
+		shift = 7
+	}
+	cg.builder.AddInstruction(rom.EncodeMOV(4, 1, 0)) // PUSH base
+	cg.builder.AddInstruction(rom.EncodeMOV(0, 2, 1))
+	cg.hShlImm(2, shift) // R2 = VRAM address
+	cg.hMovImm(0, packed.Offset)
+	cg.hMovImm(1, uint16(packed.Bank))
+	cg.needTilesLZ = true
+	cg.emitHelperCall("__tileslz")
+	cg.builder.AddInstruction(rom.EncodeMOV(5, destReg, 0)) // POP base
+	return nil
+}
+
+// emitTilesLZHelper emits __tileslz: decode the LZ stream at R1:R0
+// (bank:offset) into VRAM from the address in R2. R2 tracks the next byte
+// to write; a copy walks a source address in R6 d bytes behind it, moving
+// the VRAM address to read each byte back and again to write it. Clobbers
+// R0-R7.
+func (cg *CodeGenerator) emitTilesLZHelper() {
+	cg.recordFuncAddr("__tileslz")
+
+	next := cg.builder.GetCodeLength()
+	cg.emitPackedReadByte(3) // R3 = control byte
+	cg.hCmpImm(3, 0)
+	done := cg.hBranch(rom.EncodeBEQ())
+	cg.builder.AddInstruction(rom.EncodeMOV(0, 4, 3))
+	cg.hAndImm(4, 0x80)
+	cg.hCmpImm(4, 0)
+	isCopy := cg.hBranch(rom.EncodeBNE())
+
+	// Literal: R3 bytes streamed to VRAM from R2.
+	cg.emitSetVRAMAddr(2, 4)
+	literal := cg.builder.GetCodeLength()
+	cg.emitPackedReadByte(4)
+	cg.hMovImm(7, hwdef.VRAM_DATA)
+	cg.builder.AddInstruction(rom.EncodeMOV(3, 7, 4))
+	cg.builder.AddInstruction(rom.EncodeADD(1, 2, 0))
+	cg.builder.AddImmediate(1)
+	cg.emitCountdownLoop(3, literal)
+	cg.hJumpBack(next)
+
+	// Copy: (R3 & 0x7F) + 4 bytes from R6 = R2 - d.
+	cg.hPatchToHere(isCopy)
+	cg.hAndImm(3, 0x7F)
+	cg.builder.AddInstruction(rom.EncodeADD(1, 3, 0))
+	cg.builder.AddImmediate(tilesLZMinMatch)
+	cg.emitPackedReadByte(6)
+	cg.emitPackedReadByte(4)
+	cg.hShlImm(4, 8)
+	cg.builder.AddInstruction(rom.EncodeOR(0, 6, 4)) // R6 = d
+	cg.builder.AddInstruction(rom.EncodeMOV(0, 4, 2))
+	cg.builder.AddInstruction(rom.EncodeSUB(0, 4, 6))
+	cg.builder.AddInstruction(rom.EncodeMOV(0, 6, 4)) // R6 = R2 - d
+	copyLoop := cg.builder.GetCodeLength()
+	cg.emitSetVRAMAddr(6, 5)
+	cg.hMovImm(7, hwdef.VRAM_DATA)
+	cg.builder.AddInstruction(rom.EncodeMOV(2, 4, 7)) // R4 = VRAM[R6]
+	cg.emitSetVRAMAddr(2, 5)
+	cg.hMovImm(7, hwdef.VRAM_DATA)
+	cg.builder.AddInstruction(rom.EncodeMOV(3, 7, 4)) // VRAM[R2] = R4
+	cg.builder.AddInstruction(rom.EncodeADD(1, 6, 0))
+	cg.builder.AddImmediate(1)
+	cg.builder.AddInstruction(rom.EncodeADD(1, 2, 0))
+	cg.builder.AddImmediate(1)
+	cg.emitCountdownLoop(3, copyLoop)
+	cg.hJumpBack(next)
+
+	cg.hPatchToHere(done)
+	cg.builder.AddInstruction(rom.EncodeRET())
+}
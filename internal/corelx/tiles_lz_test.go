@@ -0,0 +1,165 @@
+package corelx
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"nitro-core-dx/internal/cpu"
+)
+
+// unpackTilesLZ is the Go twin of __tileslz.
+func unpackTilesLZ(t *testing.T, packed []byte) []byte {
+	t.Helper()
+	var out []byte
+	for i := 0; ; {
+		c := packed[i]
+		i++
+		switch {
+		case c == 0:
+			if i != len(packed) {
+				t.Fatalf("end marker at %d of %d bytes", i-1, len(packed))
+			}
+			return out
+		case c&0x80 == 0:
+			out = append(out, packed[i:i+int(c)]...)
+			i += int(c)
+		default:
+			d := int(packed[i]) | int(packed[i+1])<<8
+			i += 2
+			if d < 1 || d > len(out) || d > tilesLZWindow {
+				t.Fatalf("copy distance %d with %d bytes out", d, len(out))
+			}
+			for n := int(c&0x7F) + tilesLZMinMatch; n > 0; n-- {
+				out = append(out, out[len(out)-d])
+			}
+		}
+	}
+}
+
+// testTileSheet is four 16x16 tiles: a checkerboard, the same shifted
+// by a pixel, a flat colour and a noisy one. noise bytes come from a
+// fixed LCG so the sheet has both repeats and incompressible stretches.
+func testTileSheet() []byte {
+	sheet := make([]byte, 4*128)
+	for i := 0; i < 128; i++ {
+		row := i / 8
+		sheet[i] = 0x12
+		if row%2 == 1 {
+			sheet[i] = 0x21
+		}
+		sheet[128+i] = sheet[i]<<4 | sheet[i]>>4
+		sheet[256+i] = 0x33
+	}
+	seed := uint32(7)
+	for i := 0; i < 128; i++ {
+		seed = seed*1103515245 + 12345
+		sheet[384+i] = byte(seed >> 16)
+	}
+	return sheet
+}
+
+// tileAssetSource renders data as a tile asset declaration.
+func tileAssetSource(name, kind, compression string, data []byte) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "asset %s: %s %shex", name, kind, compression)
+	for i := 0; i < len(data); i += 32 {
+		fmt.Fprintf(&b, "\n    \"% X \"", data[i:min(i+32, len(data))])
+	}
+	return b.String()
+}
+
+func TestPackTilesLZRoundTrips(t *testing.T) {
+	sheet := testTileSheet()
+	cases := map[string][]byte{
+		"sheet":      sheet,
+		"flat tile":  bytes.Repeat([]byte{0x44}, 32),
+		"long sheet": bytes.Repeat(sheet, 12),
+	}
+	for name, data := range cases {
+		packed, err := packTilesLZ("tiles8", data)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if got := unpackTilesLZ(t, packed); !bytes.Equal(got, data) {
+			t.Errorf("%s: round trip differs", name)
+		}
+	}
+
+	packed, _ := packTilesLZ("tiles16", sheet)
+	if len(packed) > len(sheet)/2 {
+		t.Errorf("sheet packed to %d of %d bytes", len(packed), len(sheet))
+	}
+	if _, err := packTilesLZ("tiles16", sheet[:100]); err == nil {
+		t.Error("partial 16x16 tile packed without error")
+	}
+}
+
+func TestLoadTilesCompressedExpandsIntoVRAM(t *testing.T) {
+	sheet := testTileSheet()
+	source := tileAssetSource("Sheet", "tiles16", "lz ", sheet) + `
+
+function Start()
+    base := gfx.load_tiles_compressed(ASSET_Sheet, 3)
+    if base == 3
+        mem.write(0x0200, 1)
+    while true
+        wait_vblank()
+`
+	emu, result := compileLoadForTest(t, source)
+	var lzAddr uint16
+	for _, fn := range result.Functions {
+		if fn.Name == "__tileslz" {
+			lzAddr = fn.Offset
+		}
+	}
+	var start, cycles uint32
+	var callSP uint16
+	emu.CPU.CallHook = func(ev cpu.CallEvent) {
+		switch {
+		case ev.Kind == cpu.CallEventCall && ev.Offset == lzAddr:
+			start, callSP = emu.CPU.State.Cycles, ev.SP
+		case ev.Kind == cpu.CallEventReturn && start != 0 && cycles == 0 && ev.SP > callSP:
+			cycles = emu.CPU.State.Cycles - start
+		}
+	}
+	emu.SetFrameLimit(false)
+	emu.Start()
+	for i := 0; i < 3; i++ {
+		if err := emu.RunFrame(); err != nil {
+			t.Fatalf("RunFrame: %v", err)
+		}
+	}
+
+	if got := emu.PPU.VRAM[3*128 : 3*128+len(sheet)]; !bytes.Equal(got, sheet) {
+		t.Fatalf("VRAM differs from the sheet (first bytes % X)", got[:8])
+	}
+	if emu.PPU.VRAM[3*128-1] != 0 || emu.PPU.VRAM[3*128+len(sheet)] != 0 {
+		t.Error("load wrote outside the sheet's tiles")
+	}
+	if emu.Bus.Read8(0, 0x0200) != 1 {
+		t.Error("gfx.load_tiles_compressed did not return base")
+	}
+
+	packed, _ := packTilesLZ("tiles16", sheet)
+	estimate := tilesLZCycles(packed)
+	t.Logf("__tileslz: %d cycles measured, %d estimated", cycles, estimate)
+	if cycles == 0 || uint32(estimate) < cycles || uint32(estimate) > cycles*11/10 {
+		t.Errorf("__tileslz took %d cycles, estimate %d (want the estimate at or up to 10%% above)", cycles, estimate)
+	}
+}
+
+func TestLoadTilesNeedsTheMatchingLoader(t *testing.T) {
+	tile := bytes.Repeat([]byte{0x11}, 32)
+	for _, tc := range []struct{ compression, call, want string }{
+		{"lz ", "gfx.load_tiles", "load it with gfx.load_tiles_compressed"},
+		{"", "gfx.load_tiles_compressed", "is not lz compressed"},
+	} {
+		source := tileAssetSource("T", "tiles8", tc.compression, tile) + "\n\nfunction Start()\n    " + tc.call + "(ASSET_T, 1)\n"
+		_, err := CompileSource(source, "tiles.corelx", nil)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s on %q asset: err = %v, want %q", tc.call, tc.compression, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,625 @@
+package corelx
+
+import (
+	"fmt"
+
+	"nitro-core-dx/internal/rom"
+)
+
+// Integer storage types. Every value lives in a 16-bit register; the
+// declared type decides how it is stored (u8/i8 keep one byte), how it
+// widens back into a register (zero- or sign-extended) and whether ordered
+// comparisons are signed.
+type intType struct {
+	bits   int
+	signed bool
+}
+
+var intTypes = map[string]intType{
+	"u8":  {8, false},
+	"i8":  {8, true},
+	"u16": {16, false},
+	"i16": {16, true},
+	"int": {16, true},
+}
+
+// unsupportedIntTypes are integer type names CoreLX recognizes but cannot
+// store: the CPU has no 32-bit registers.
+var unsupportedIntTypes = map[string]bool{"i32": true, "u32": true}
+
+// builtinIntResults lists the integer result type of builtins whose value
+// is worth typing (byte reads are u8, counters and bitmasks are u16). The
+// large SPR_SIZE_* values carry size bits above bit 7, so they are u16.
+var builtinIntResults = map[string]string{
+	"int":              "int",
+	"frame_counter":    "u16",
+	"reset_cause":      "u8",
+	"input.read":       "u16",
+	"mem.read":         "u8",
+	"mem.read16":       "u16",
+	"bg.tile_at":       "u8",
+	"dec.digits":       "u8",
+	"persist.get":      "u16",
+	"SPR_PAL":          "u8",
+	"SPR_PRI":          "u8",
+	"SPR_HFLIP":        "u8",
+	"SPR_VFLIP":        "u8",
+	"SPR_ENABLE":       "u8",
+	"SPR_SIZE_8":       "u8",
+	"SPR_SIZE_16":      "u8",
+	"SPR_SIZE_32X16":   "u16",
+	"SPR_SIZE_32X32":   "u16",
+	"SPR_SIZE_64X32":   "u16",
+	"SPR_SIZE_64X64":   "u16",
+	"SPR_SIZE_128X64":  "u16",
+	"SPR_SIZE_128X128": "u16",
+	"SPR_BLEND":        "u8",
+	"SPR_ALPHA":        "u8",
+}
+
+// intRange is the set of values a type holds. 16-bit types accept any
+// constant in -32768..65535 on assignment, since hex literals and negative
+// numbers both spell the same register bits.
+func intRange(name string) (lo, hi int64) {
+	t := intTypes[name]
+	switch {
+	case t.bits == 8 && t.signed:
+		return -128, 127
+	case t.bits == 8:
+		return 0, 255
+	case t.signed:
+		return -32768, 32767
+	default:
+		return 0, 65535
+	}
+}
+
+// constFits reports whether constant v may be stored in type name.
+func constFits(name string, v int64) bool {
+	if intTypes[name].bits == 16 {
+		return v >= -32768 && v <= 65535
+	}
+	lo, hi := intRange(name)
+	return v >= lo && v <= hi
+}
+
+// widenInt returns the 16-bit type with the same signedness, used for
+// results that can outgrow one byte.
+func widenInt(name string) string {
+	switch name {
+	case "u8":
+		return "u16"
+	case "i8":
+		return "i16"
+	}
+	return name
+}
+
+// intExpr is the inferred integer type of an expression. Constant
+// expressions stay untyped (konst) until they meet a typed operand or a
+// typed destination. typ is an intTypes key, "fixed", "bool", a struct
+// name, or "" when unknown (checks are then skipped).
+type intExpr struct {
+	typ   string
+	konst bool
+	value int64
+}
+
+func (x intExpr) isInt() bool {
+	_, ok := intTypes[x.typ]
+	return ok && !x.konst
+}
+
+// intEnv resolves names for inferInt. The semantic analyzer and the code
+// generator both implement it, so diagnostics and codegen agree on types.
+type intEnv interface {
+	// varIntType returns the declared type of a variable, parameter or
+	// global (ok reports whether name is one; typ may still be "").
+	varIntType(name string) (typ string, ok bool)
+	// intConst returns the value of an integer constant.
+	intConst(name string) (int64, bool)
+	// fieldIntType returns the type of object.member, or "".
+	fieldIntType(object, member string) string
+	// callIntType returns the result type of a user function or builtin.
+	callIntType(name string) string
+}
+
+// inferInt infers the integer type of expr.
+func inferInt(env intEnv, expr Expr) intExpr {
+	switch e := expr.(type) {
+	case *NumberExpr:
+		if e.IsFixed {
+			return intExpr{typ: typeFixed}
+		}
+		return intExpr{konst: true, value: int64(e.Value)}
+	case *BoolExpr:
+		return intExpr{typ: typeBool}
+	case *IdentExpr:
+		if t, ok := env.varIntType(e.Name); ok {
+			return intExpr{typ: t}
+		}
+		if v, ok := env.intConst(e.Name); ok {
+			return intExpr{konst: true, value: v}
+		}
+	case *MemberExpr:
+		if obj, ok := e.Object.(*IdentExpr); ok {
+			return intExpr{typ: env.fieldIntType(obj.Name, e.Member)}
+		}
+	case *IndexExpr:
+		if arr, ok := e.Array.(*IdentExpr); ok {
+			if t, ok := env.varIntType(arr.Name); ok {
+				return intExpr{typ: t}
+			}
+		}
+	case *CallExpr:
+		name := callFuncName(e)
+		if isSpriteFieldsCall(name) {
+			return spriteFieldsType(env, name, e)
+		}
+		return intExpr{typ: env.callIntType(name)}
+	case *UnaryExpr:
+		x := inferInt(env, e.Operand)
+		switch e.Op {
+		case TOKEN_NOT:
+			return intExpr{typ: typeBool}
+		case TOKEN_MINUS:
+			if x.konst {
+				return intExpr{konst: true, value: -x.value}
+			}
+			if x.isInt() && !intTypes[x.typ].signed {
+				return intExpr{typ: "int"}
+			}
+			return intExpr{typ: widenInt(x.typ)}
+		case TOKEN_TILDE:
+			if x.konst {
+				return intExpr{konst: true, value: ^x.value}
+			}
+			return intExpr{typ: x.typ}
+		}
+	case *BinaryExpr:
+		return combineInt(e.Op, inferInt(env, e.Left), inferInt(env, e.Right))
+	}
+	return intExpr{}
+}
+
+// combineInt types a binary operation. Arithmetic keeps the operands' type
+// (u8 + u8 is u8 and wraps when stored), an untyped constant adopts the
+// other operand's type, and mixed types widen to the larger one; at equal
+// width a signed/unsigned mix is unsigned, as in C.
+func combineInt(op TokenType, l, r intExpr) intExpr {
+	switch op {
+	case TOKEN_EQUAL_EQUAL, TOKEN_BANG_EQUAL, TOKEN_LESS, TOKEN_LESS_EQUAL,
+		TOKEN_GREATER, TOKEN_GREATER_EQUAL, TOKEN_AND, TOKEN_OR:
+		return intExpr{typ: typeBool}
+	}
+	if l.konst && r.konst {
+		if v, err := applyConstBinary(op, l.value, r.value); err == nil {
+			return intExpr{konst: true, value: v}
+		}
+		return intExpr{}
+	}
+	if l.typ == typeFixed || r.typ == typeFixed {
+		return intExpr{typ: typeFixed}
+	}
+	switch op {
+	case TOKEN_AMPERSAND:
+		// Masking with a byte constant yields a byte.
+		if (r.konst && r.value >= 0 && r.value <= 0xFF) || (l.konst && l.value >= 0 && l.value <= 0xFF) {
+			return intExpr{typ: "u8"}
+		}
+	case TOKEN_RSHIFT:
+		if r.konst && l.typ == "u16" && r.value >= 8 {
+			return intExpr{typ: "u8"}
+		}
+		if !l.konst {
+			return intExpr{typ: l.typ}
+		}
+		return intExpr{typ: "int"}
+	case TOKEN_LSHIFT:
+		if !l.konst {
+			return intExpr{typ: widenInt(l.typ)}
+		}
+		return intExpr{typ: "int"}
+	}
+	switch {
+	case l.konst:
+		return intExpr{typ: r.typ}
+	case r.konst:
+		return intExpr{typ: l.typ}
+	}
+	return intExpr{typ: joinInt(l.typ, r.typ)}
+}
+
+// joinInt is the common type of two typed integer operands, or "" when
+// either is not an integer.
+func joinInt(a, b string) string {
+	ta, okA := intTypes[a]
+	tb, okB := intTypes[b]
+	switch {
+	case !okA || !okB:
+		return ""
+	case a == b:
+		return a
+	case ta == tb:
+		return "int" // int and i16 are the same type
+	case ta.bits != tb.bits:
+		if ta.bits > tb.bits {
+			return a
+		}
+		return b
+	case ta.bits == 8:
+		return "i16" // u8 and i8 both fit
+	default:
+		return "u16"
+	}
+}
+
+// localIntType is the type a `:=` local takes from its initializer: the
+// initializer's type, or int for a constant.
+func localIntType(x intExpr) string {
+	if x.konst {
+		return "int"
+	}
+	if x.isInt() {
+		return x.typ
+	}
+	return ""
+}
+
+// unsignedCompare reports whether an ordered comparison between l and r
+// must be unsigned: one side is a u16 value and neither side is untyped
+// or fixed. u8 values are never negative, so signed compares suit them.
+func unsignedCompare(l, r intExpr) bool {
+	if l.konst && r.konst {
+		return false
+	}
+	if !(l.konst || l.isInt()) || !(r.konst || r.isInt()) {
+		return false
+	}
+	return (l.isInt() && l.typ == "u16") || (r.isInt() && r.typ == "u16")
+}
+
+// isOrderedCompare reports whether op is <, <=, > or >=.
+func isOrderedCompare(op TokenType) bool {
+	switch op {
+	case TOKEN_LESS, TOKEN_LESS_EQUAL, TOKEN_GREATER, TOKEN_GREATER_EQUAL:
+		return true
+	}
+	return false
+}
+
+// intTypeName returns the name of a declared type, or "".
+func intTypeName(t TypeExpr) string {
+	if named, ok := t.(*NamedType); ok {
+		return named.Name
+	}
+	return ""
+}
+
+// structFieldType returns the declared type name of a struct field. The
+// builtin Sprite struct is all bytes.
+func structFieldType(prog *Program, structName, field string) string {
+	if structName == "Sprite" {
+		switch field {
+		case "x_lo", "x_hi", "y", "tile", "attr", "ctrl":
+			return "u8"
+		}
+		return ""
+	}
+	for _, td := range prog.Types {
+		if td.Name != structName {
+			continue
+		}
+		if st, ok := td.Type.(*StructType); ok {
+			for _, f := range st.Fields {
+				if f.Name == field {
+					return intTypeName(f.Type)
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// isStructName reports whether name is the builtin Sprite struct or a
+// struct declared by the program.
+func isStructName(prog *Program, name string) bool {
+	if name == "Sprite" {
+		return true
+	}
+	for _, td := range prog.Types {
+		if td.Name == name {
+			_, ok := td.Type.(*StructType)
+			return ok
+		}
+	}
+	return false
+}
+
+// --- semantic checks -------------------------------------------------------
+
+func (a *SemanticAnalyzer) varIntType(name string) (string, bool) {
+	sym, ok := a.symbols[name]
+	if !ok || sym.IsFunc || sym.IsBuiltin {
+		return "", false
+	}
+	if _, isConst := a.constVals[name]; isConst {
+		return "", false
+	}
+	if a.constFixed[name] {
+		return typeFixed, true
+	}
+	return intTypeName(sym.Type), true
+}
+
+func (a *SemanticAnalyzer) intConst(name string) (int64, bool) {
+	if a.constFixed[name] {
+		return 0, false
+	}
+	v, ok := a.constVals[name]
+	return v, ok
+}
+
+func (a *SemanticAnalyzer) fieldIntType(object, member string) string {
+	if sym, ok := a.symbols[object]; ok {
+		return structFieldType(a.program, intTypeName(sym.Type), member)
+	}
+	return ""
+}
+
+func (a *SemanticAnalyzer) callIntType(name string) string {
+	if t, ok := builtinIntResults[name]; ok {
+		return t
+	}
+	if isStructName(a.program, name) {
+		return name
+	}
+	for _, fn := range a.program.Functions {
+		if fn.Name == name {
+			return intTypeName(fn.ReturnType)
+		}
+	}
+	return ""
+}
+
+// checkDeclaredType rejects integer types CoreLX cannot store.
+func (a *SemanticAnalyzer) checkDeclaredType(pos Position, what string, t TypeExpr) {
+	if name := intTypeName(t); unsupportedIntTypes[name] {
+		a.addDiagnostic(pos, CategoryTypeError, "E_TYPE_UNSUPPORTED", fmt.Sprintf("%s: %s is not supported; CoreLX integers are 16-bit (use i16, u16 or int)", what, name), "")
+	}
+}
+
+// checkIntStore diagnoses storing value into a destination of type target:
+// a constant that does not fit is an error, a wider runtime value that is
+// silently cut to 8 bits is a warning.
+func (a *SemanticAnalyzer) checkIntStore(pos Position, dest, target string, value Expr) {
+	if _, ok := intTypes[target]; !ok || value == nil {
+		return
+	}
+	x := inferInt(a, value)
+	if x.konst {
+		if !constFits(target, x.value) {
+			lo, hi := intRange(target)
+			if intTypes[target].bits == 16 {
+				lo, hi = -32768, 65535
+			}
+			a.addDiagnostic(pos, CategoryOverflowError, "E_INT_OVERFLOW", fmt.Sprintf("constant %d does not fit in %s %s (%d to %d)", x.value, target, dest, lo, hi), "")
+		}
+		return
+	}
+	// int is the default integer type, so only explicitly sized 16-bit
+	// values are flagged.
+	if x.isInt() && x.typ != "int" && intTypes[x.typ].bits > intTypes[target].bits {
+		a.addWarning(pos, CategoryTypeError, "W_INT_TRUNCATION", fmt.Sprintf("%s value stored in %s %s is truncated to 8 bits; mask it with & 0xFF or widen %s", x.typ, target, dest, dest))
+	}
+}
+
+// checkCompare diagnoses comparisons whose result is fixed by the operand
+// types, and ordered comparisons between signed and unsigned values.
+func (a *SemanticAnalyzer) checkCompare(e *BinaryExpr) {
+	if e.Op != TOKEN_EQUAL_EQUAL && e.Op != TOKEN_BANG_EQUAL && !isOrderedCompare(e.Op) {
+		return
+	}
+	l, r := inferInt(a, e.Left), inferInt(a, e.Right)
+	if l.isInt() && r.isInt() && isOrderedCompare(e.Op) && intTypes[l.typ].signed != intTypes[r.typ].signed {
+		signed, unsigned := l.typ, r.typ
+		if intTypes[r.typ].signed {
+			signed, unsigned = r.typ, l.typ
+		}
+		if unsigned == "u16" {
+			a.addWarning(e.Position, CategoryTypeError, "W_SIGN_COMPARE", fmt.Sprintf("comparison between %s and %s is unsigned: negative %s values compare greater than every positive value", signed, unsigned, signed))
+		}
+	}
+	op, operand := e.Op, e.Left
+	if l.konst && r.isInt() {
+		// Mirror "c < x" into "x > c" so one check covers both sides.
+		l, r = r, l
+		op, operand = mirrorCompare(op), e.Right
+	}
+	if !l.isInt() || !r.konst || !isStoredValue(operand) {
+		return
+	}
+	lo, hi := intRange(l.typ)
+	if intTypes[l.typ].bits == 16 && intTypes[l.typ].signed {
+		return // 16-bit constants above 32767 are bit patterns, not out of range
+	}
+	var always bool
+	switch {
+	case r.value > hi:
+		always = op == TOKEN_LESS || op == TOKEN_LESS_EQUAL || op == TOKEN_BANG_EQUAL
+	case r.value < lo:
+		always = op == TOKEN_GREATER || op == TOKEN_GREATER_EQUAL || op == TOKEN_BANG_EQUAL
+	default:
+		return
+	}
+	a.addWarning(e.Position, CategoryTypeError, "W_CONST_COMPARE", fmt.Sprintf("comparison of %s value with %d is always %v", l.typ, r.value, always))
+}
+
+// isStoredValue reports whether e is a variable, field, element or call
+// result, which always holds a value of its type. Arithmetic results keep
+// all 16 register bits, so `a + b > 255` is a real test even for u8 a and b.
+func isStoredValue(e Expr) bool {
+	switch e.(type) {
+	case *BinaryExpr, *UnaryExpr:
+		return false
+	}
+	return true
+}
+
+func mirrorCompare(op TokenType) TokenType {
+	switch op {
+	case TOKEN_LESS:
+		return TOKEN_GREATER
+	case TOKEN_LESS_EQUAL:
+		return TOKEN_GREATER_EQUAL
+	case TOKEN_GREATER:
+		return TOKEN_LESS
+	case TOKEN_GREATER_EQUAL:
+		return TOKEN_LESS_EQUAL
+	}
+	return op
+}
+
+// checkCallArgs checks arguments passed to typed parameters of a user
+// function.
+func (a *SemanticAnalyzer) checkCallArgs(call *CallExpr) {
+	name := callFuncName(call)
+	for _, fn := range a.program.Functions {
+		if fn.Name != name {
+			continue
+		}
+		if len(call.Args) != len(fn.Params) {
+			noun := "arguments"
+			if len(fn.Params) == 1 {
+				noun = "argument"
+			}
+			a.addDiagnostic(call.Position, CategoryValidationError, "E_CALL_ARITY",
+				fmt.Sprintf("%s expects %d %s, got %d", name, len(fn.Params), noun, len(call.Args)), "")
+		}
+		for i, p := range fn.Params {
+			if i < len(call.Args) {
+				a.checkIntStore(call.Args[i].Pos(), "parameter "+p.Name, intTypeName(p.Type), call.Args[i])
+			}
+		}
+		return
+	}
+}
+
+// checkBuiltinCall reports a call into a built-in namespace, such as
+// ppu.enable_displai(), that names no built-in. Without it the mistake only
+// surfaces in code generation, with no source position.
+func (a *SemanticAnalyzer) checkBuiltinCall(call *CallExpr) {
+	member, ok := call.Func.(*MemberExpr)
+	if !ok {
+		return
+	}
+	obj, ok := member.Object.(*IdentExpr)
+	if !ok || !builtinNamespaces[obj.Name] || a.isRequestedModule(obj.Name) {
+		return
+	}
+	if sym, ok := a.symbols[obj.Name]; ok && !sym.IsBuiltin {
+		return // a variable that shadows the namespace
+	}
+	name := obj.Name + "." + member.Member
+	if sym, ok := a.symbols[name]; ok && sym.IsBuiltin {
+		return
+	}
+	a.addDiagnostic(call.Position, CategorySymbolError, "E_BUILTIN_UNKNOWN", "unknown function: "+name, "")
+}
+
+// assignTargetName renders an assignment target for diagnostics.
+func assignTargetName(target Expr) string {
+	switch t := target.(type) {
+	case *IdentExpr:
+		return t.Name
+	case *MemberExpr:
+		if obj, ok := t.Object.(*IdentExpr); ok {
+			return obj.Name + "." + t.Member
+		}
+	case *IndexExpr:
+		if arr, ok := t.Array.(*IdentExpr); ok {
+			return arr.Name + "[...]"
+		}
+	}
+	return "target"
+}
+
+// --- code generation -------------------------------------------------------
+
+func (cg *CodeGenerator) varIntType(name string) (string, bool) {
+	if v, ok := cg.variables[name]; ok {
+		if v.StructType != "" {
+			return v.StructType, true
+		}
+		return v.VarType, true
+	}
+	if cg.constFixed[name] {
+		return typeFixed, true
+	}
+	if _, ok := cg.consts[name]; ok {
+		return "", false
+	}
+	if g, ok := cg.globals[name]; ok {
+		return g.VarType, true
+	}
+	return "", false
+}
+
+func (cg *CodeGenerator) intConst(name string) (int64, bool) {
+	if _, ok := cg.variables[name]; ok || cg.constFixed[name] {
+		return 0, false
+	}
+	v, ok := cg.consts[name]
+	return v, ok
+}
+
+func (cg *CodeGenerator) fieldIntType(object, member string) string {
+	if v, ok := cg.variables[object]; ok && v.StructType != "" {
+		return structFieldType(cg.program, v.StructType, member)
+	}
+	return ""
+}
+
+func (cg *CodeGenerator) callIntType(name string) string {
+	if t, ok := builtinIntResults[name]; ok {
+		return t
+	}
+	if isStructName(cg.program, name) {
+		return name
+	}
+	if fn := cg.findFunction(name); fn != nil {
+		return intTypeName(fn.ReturnType)
+	}
+	return ""
+}
+
+// emitIntNarrow brings R{reg} into the range of typ before it is stored:
+// u8 keeps the low byte, i8 sign-extends it. Other types are left alone.
+func (cg *CodeGenerator) emitIntNarrow(reg uint8, typ string) {
+	switch typ {
+	case "u8":
+		cg.builder.AddInstruction(rom.EncodeAND(1, reg, 0)) // AND R{reg}, #0x00FF
+		cg.builder.AddImmediate(0x00FF)
+	case "i8":
+		// (x & 0xFF) ^ 0x80 - 0x80 copies bit 7 into the high byte.
+		cg.builder.AddInstruction(rom.EncodeAND(1, reg, 0))
+		cg.builder.AddImmediate(0x00FF)
+		cg.builder.AddInstruction(rom.EncodeXOR(1, reg, 0))
+		cg.builder.AddImmediate(0x0080)
+		cg.builder.AddInstruction(rom.EncodeSUB(1, reg, 0))
+		cg.builder.AddImmediate(0x0080)
+	}
+}
+
+// emitOrderedCompare compares R1 with R2 for <, <=, > or >=. The CPU's
+// ordered branches are signed, so an unsigned comparison first flips both
+// sign bits, which maps 0..0xFFFF onto -0x8000..0x7FFF in order.
+func (cg *CodeGenerator) emitOrderedCompare(e *BinaryExpr) {
+	if unsignedCompare(inferInt(cg, e.Left), inferInt(cg, e.Right)) {
+		cg.builder.AddInstruction(rom.EncodeXOR(1, 1, 0)) // XOR R1, #0x8000
+		cg.builder.AddImmediate(0x8000)
+		cg.builder.AddInstruction(rom.EncodeXOR(1, 2, 0)) // XOR R2, #0x8000
+		cg.builder.AddImmediate(0x8000)
+	}
+	cg.builder.AddInstruction(rom.EncodeCMP(0, 1, 2)) // CMP R1, R2
+}
@@ -0,0 +1,106 @@
+package corelx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIntTypeDiagnostics(t *testing.T) {
+	cases := []struct {
+		name string
+		body string
+		want string // diagnostic code, "" for none
+	}{
+		{"u8 overflow", "x: u8 = 300", "E_INT_OVERFLOW"},
+		{"i8 underflow", "x: i8 = -129", "E_INT_OVERFLOW"},
+		{"u16 bit pattern", "x: u16 = 0xFFFF\n    y: int = -1", ""},
+		{"i32 unsupported", "x: i32 = 1", "E_TYPE_UNSUPPORTED"},
+		{"u16 into u8", "b := input.read(0)\n    x: u8 = b", "W_INT_TRUNCATION"},
+		{"masked into u8", "x: u8 = input.read(0) & 0xFF", ""},
+		{"high byte into u8", "x: u8 = input.read(0) >> 8", ""},
+		{"int into u8", "n := 5\n    x: u8 = n", ""},
+		{"argument overflow", "take(300)", "E_INT_OVERFLOW"},
+		{"signed vs unsigned", "a: i16 = -1\n    b := frame_counter()\n    if a < b\n        a = 0", "W_SIGN_COMPARE"},
+		{"signed vs unsigned equality", "a: i16 = -1\n    b := frame_counter()\n    if a == b\n        a = 0", ""},
+		{"u8 out of range", "v := mem.read(0x0100)\n    if v > 300\n        v = 0", "W_CONST_COMPARE"},
+		{"u8 sum in range", "v := mem.read(0x0100)\n    if v + v > 255\n        v = 0", ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			source := "function take(v: u8)\n    mem.write(0x0100, v)\n\nfunction Start()\n    " + tc.body + "\n"
+			result, _ := CompileSource(source, "types.corelx", nil)
+			var codes []string
+			for _, d := range result.Diagnostics {
+				if strings.HasPrefix(d.Code, "E_INT") || strings.HasPrefix(d.Code, "E_TYPE") || strings.HasPrefix(d.Code, "W_") {
+					codes = append(codes, d.Code)
+				}
+			}
+			got := strings.Join(codes, ",")
+			if got != tc.want {
+				t.Fatalf("diagnostics = %q, want %q (%+v)", got, tc.want, result.Diagnostics)
+			}
+		})
+	}
+}
+
+func TestWarningsDoNotFailTheBuild(t *testing.T) {
+	source := "function Start()\n    b := input.read(0)\n    x: u8 = b\n"
+	result, err := CompileSource(source, "warn.corelx", nil)
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	if len(result.Diagnostics) != 1 || result.Diagnostics[0].Severity != SeverityWarning {
+		t.Fatalf("diagnostics = %+v, want one warning", result.Diagnostics)
+	}
+}
+
+func TestIntStoresWrapToDeclaredWidth(t *testing.T) {
+	source := `var g: u8 = 250
+var after: u8 = 7
+var wrapped: int
+var signed: int
+var unsigned_gt: int
+var signed_lt: int
+
+function Start()
+    x: u8 = 250
+    x = x + 10
+    wrapped = x
+    g = g + 10
+    s: i8 = 127
+    s = s + 1
+    signed = s
+    big: u16 = 0xF000
+    small: u16 = 0x0010
+    if big > small
+        unsigned_gt = 1
+    neg: int = -5
+    if neg < 3
+        signed_lt = 1
+    while true
+        wrapped = wrapped
+`
+	emu, result := compileAndBoot(t, source, 2000)
+	addrs := map[string]uint16{}
+	for _, e := range result.MemoryMap {
+		addrs[e.Name] = e.Address
+	}
+	if got := read16(emu, addrs["wrapped"]); got != 4 {
+		t.Errorf("u8 local 250+10 = %d, want 4", got)
+	}
+	if got := emu.CPU.Mem.Read8(0, addrs["g"]); got != 4 {
+		t.Errorf("u8 global 250+10 = %d, want 4", got)
+	}
+	if got := emu.CPU.Mem.Read8(0, addrs["after"]); got != 7 {
+		t.Errorf("byte after the u8 global = %d, want 7 (the store must be one byte)", got)
+	}
+	if got := read16(emu, addrs["signed"]); got != 0xFF80 {
+		t.Errorf("i8 127+1 = 0x%04X, want 0xFF80 (-128)", got)
+	}
+	if got := read16(emu, addrs["unsigned_gt"]); got != 1 {
+		t.Errorf("u16 0xF000 > 0x0010 was false; ordered u16 compares must be unsigned")
+	}
+	if got := read16(emu, addrs["signed_lt"]); got != 1 {
+		t.Errorf("int -5 < 3 was false; int compares must stay signed")
+	}
+}
@@ -1,7 +1,7 @@
 package corelx
 
 // Expression type names used by the charter-D4 numeric model.
-// "int" covers int/u8/u16 storage (all integer arithmetic);
+// "int" covers every integer storage type (see intTypes);
 // "fixed" is 8.8 fixed point; "unknown" suppresses checks.
 const (
 	typeInt     = "int"
@@ -14,7 +14,7 @@ const (
 // arithTypeForName maps a declared storage type to its arithmetic type.
 func arithTypeForName(name string) string {
 	switch name {
-	case "int", "i16", "u8", "u16":
+	case "int", "i16", "u8", "i8", "u16":
 		return typeInt
 	case "fixed":
 		return typeFixed
@@ -91,8 +91,10 @@ func (cg *CodeGenerator) typeOf(expr Expr) string {
 		switch name {
 		case "int":
 			return typeInt
-		case "fixed":
+		case "fixed", "fx.mul", "fx.div", "fx.sin", "fx.cos":
 			return typeFixed
+		case "phys.aabb":
+			return typeBool
 		}
 		if fn := cg.findFunction(name); fn != nil {
 			if named, ok := fn.ReturnType.(*NamedType); ok {
@@ -100,6 +102,9 @@ func (cg *CodeGenerator) typeOf(expr Expr) string {
 			}
 			return typeUnknown
 		}
+		if t, ok := builtinIntResults[name]; ok {
+			return arithTypeForName(t)
+		}
 		return typeUnknown
 	case *MemberExpr:
 		// Struct field access: resolve the field's declared type.
@@ -129,21 +134,7 @@ func callFuncName(call *CallExpr) string {
 
 // structFieldTypeName returns the declared type name of a struct field, or "".
 func (cg *CodeGenerator) structFieldTypeName(structName, field string) string {
-	for _, td := range cg.program.Types {
-		if td.Name != structName {
-			continue
-		}
-		if st, ok := td.Type.(*StructType); ok {
-			for _, f := range st.Fields {
-				if f.Name == field {
-					if named, ok := f.Type.(*NamedType); ok {
-						return named.Name
-					}
-				}
-			}
-		}
-	}
-	return ""
+	return structFieldType(cg.program, structName, field)
 }
 
 // checkNumericMix returns an error when fixed and int are mixed in an
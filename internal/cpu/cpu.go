@@ -29,6 +29,10 @@ type CPUState struct {
 	// Interrupt state
 	InterruptMask    uint8
 	InterruptPending uint8
+
+	// Stall is the bus contention wait cycles taken from Mem that the CPU
+	// has yet to sit out.
+	Stall uint32
 }
 
 // Flag bits
@@ -61,6 +65,66 @@ type CPU struct {
 	State CPUState
 	Mem   MemoryInterface
 	Log   LoggerInterface
+
+	// history is a ring of the PCs (bank<<16 | offset) of the last
+	// HistorySize instructions started; historyPos is the next slot. It is a
+	// host-side debugging aid, not machine state.
+	history    [HistorySize]uint32
+	historyPos uint8
+	historyLen uint8
+
+	// CallHook, when set, is called after every CALL, interrupt entry and
+	// RET completes. Like history, it is a debugging aid for the host.
+	CallHook func(ev CallEvent)
+
+	// waits is Mem when it charges wait cycles (see State.Stall).
+	waits WaitStater
+}
+
+// CallEventKind says what a CallEvent reports.
+type CallEventKind uint8
+
+const (
+	CallEventCall CallEventKind = iota
+	CallEventInterrupt
+	CallEventReturn
+)
+
+// CallEvent is one control transfer reported through CPU.CallHook.
+type CallEvent struct {
+	Kind CallEventKind
+	// Bank:Offset is the called function or interrupt handler, or for a
+	// return the address execution resumes at.
+	Bank   uint8
+	Offset uint16
+	// ReturnBank:ReturnOffset is the return address a call or interrupt
+	// pushed (unused for a return).
+	ReturnBank   uint8
+	ReturnOffset uint16
+	// SP is the stack pointer after the push or pop.
+	SP uint16
+}
+
+func (c *CPU) reportCall(kind CallEventKind, retBank uint8, retOffset uint16) {
+	if c.CallHook != nil {
+		c.CallHook(CallEvent{Kind: kind, Bank: c.State.PCBank, Offset: c.State.PCOffset,
+			ReturnBank: retBank, ReturnOffset: retOffset, SP: c.State.SP})
+	}
+}
+
+// HistorySize is how many recent instruction addresses RecentPCs keeps.
+const HistorySize = 32
+
+// RecentPCs returns the addresses (bank<<16 | offset) of the last
+// instructions started, oldest first. The newest is the one executing or
+// the one that failed, including one rejected for an invalid address.
+func (c *CPU) RecentPCs() []uint32 {
+	out := make([]uint32, 0, c.historyLen)
+	start := int(c.historyPos) - int(c.historyLen)
+	for i := 0; i < int(c.historyLen); i++ {
+		out = append(out, c.history[(start+i+HistorySize)%HistorySize])
+	}
+	return out
 }
 
 // MemoryInterface defines the interface for memory access
@@ -71,6 +135,14 @@ type MemoryInterface interface {
 	Write16(bank uint8, offset uint16, value uint16)
 }
 
+// WaitStater is implemented by a MemoryInterface whose accesses can cost
+// extra cycles (bus contention, or another device halting the CPU). The
+// CPU takes the cycles run up after each instruction and stalls for them,
+// running no instructions, before it continues.
+type WaitStater interface {
+	TakeWaitCycles() uint32
+}
+
 // LoggerInterface defines the interface for logging
 type LoggerInterface interface {
 	LogCPU(instruction uint16, state CPUState, cycles uint32)
@@ -82,6 +154,7 @@ func NewCPU(mem MemoryInterface, log LoggerInterface) *CPU {
 		Mem: mem,
 		Log: log,
 	}
+	cpu.waits, _ = mem.(WaitStater)
 	cpu.Reset()
 	return cpu
 }
@@ -109,6 +182,11 @@ func (c *CPU) Reset() {
 	c.State.Cycles = 0
 	c.State.InterruptMask = 0
 	c.State.InterruptPending = 0
+	c.State.Stall = 0
+	c.historyPos, c.historyLen = 0, 0
+	if c.waits != nil {
+		c.waits.TakeWaitCycles() // drop waits from before the reset
+	}
 }
 
 // SetEntryPoint sets the CPU entry point
@@ -327,6 +405,12 @@ func (c *CPU) FetchImmediate() uint16 {
 
 // ExecuteInstruction executes a single instruction
 func (c *CPU) ExecuteInstruction() error {
+	c.history[c.historyPos] = uint32(c.State.PCBank)<<16 | uint32(c.State.PCOffset&^1)
+	c.historyPos = (c.historyPos + 1) % HistorySize
+	if c.historyLen < HistorySize {
+		c.historyLen++
+	}
+
 	// Safety check: If PCBank is 0 and we're in I/O space, this is a critical error
 	// This should never happen - ROM code should be in bank 1+
 	if c.State.PCBank == 0 && c.State.PCOffset >= 0x8000 {
@@ -403,6 +487,20 @@ func (c *CPU) ExecuteInstruction() error {
 // ExecuteCycles executes CPU cycles until target cycles are reached
 func (c *CPU) ExecuteCycles(targetCycles uint32) error {
 	for c.State.Cycles < targetCycles {
+		if c.waits != nil {
+			c.State.Stall += c.waits.TakeWaitCycles()
+		}
+		if c.State.Stall > 0 {
+			// Stalled: the cycles pass without an instruction, and a
+			// stall longer than this step carries into the next.
+			n := targetCycles - c.State.Cycles
+			if c.State.Stall < n {
+				n = c.State.Stall
+			}
+			c.State.Stall -= n
+			c.State.Cycles += n
+			continue
+		}
 		if err := c.ExecuteInstruction(); err != nil {
 			return err
 		}
@@ -443,6 +541,7 @@ func (c *CPU) handleInterrupt(interruptType uint8) error {
 	}
 
 	// Save current PC to stack (PBR first, then PC)
+	retBank, retOffset := c.State.PBR, c.State.PCOffset
 	c.Push16(uint16(c.State.PBR))
 	c.Push16(c.State.PCOffset)
 
@@ -476,6 +575,7 @@ func (c *CPU) handleInterrupt(interruptType uint8) error {
 
 	// Clear interrupt pending flag
 	c.State.InterruptPending = INT_NONE
+	c.reportCall(CallEventInterrupt, retBank, retOffset)
 
 	// Interrupt handling takes cycles
 	c.State.Cycles += 7 // Interrupt overhead (save state + jump)
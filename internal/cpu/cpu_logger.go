@@ -3,6 +3,7 @@ package cpu
 import (
 	"fmt"
 	"nitro-core-dx/internal/debug"
+	"nitro-core-dx/internal/hwdef"
 )
 
 // CPULogLevel represents granular logging levels for CPU
@@ -79,16 +80,24 @@ func (a *CPULoggerAdapter) LogCPU(instruction uint16, state CPUState, cycles uin
 
 	case CPULogMemory:
 		// Log memory access and branches
-		if opcode == 0x1 && (mode == 2 || mode == 3) { // MOV with memory
+		if opcode == 0x1 && (mode == 2 || mode == 3 || mode == 6 || mode == 7) { // MOV with memory
 			logLevel = debug.LogLevelInfo
 			message = a.formatInstruction(instruction, opcode, mode, reg1, reg2)
 			data = a.getStateData(state, cycles)
-			if mode == 2 {
-				data["memory_op"] = "read"
-				data["address"] = fmt.Sprintf("%02X:%04X", state.DBR, state.R0) // Approximate
-			} else {
+			// Loads address through R2, stores through R1.
+			addr := stateRegister(state, reg2)
+			data["memory_op"] = "read"
+			if mode == 3 || mode == 7 {
+				addr = stateRegister(state, reg1)
 				data["memory_op"] = "write"
-				data["address"] = fmt.Sprintf("%02X:%04X", state.DBR, state.R0) // Approximate
+			}
+			data["address"] = fmt.Sprintf("%02X:%04X", state.DBR, addr)
+			// Bank 0 high addresses are I/O; name the register.
+			if state.DBR == 0 && addr >= hwdef.IOBase {
+				if reg, ok := hwdef.RegisterAt(addr); ok {
+					data["register"] = reg.Name
+					message += " ; " + reg.Name
+				}
 			}
 		} else if opcode == 0xC || opcode == 0xD || opcode == 0xE || opcode == 0xF {
 			logLevel = debug.LogLevelInfo
@@ -174,6 +183,8 @@ func (a *CPULoggerAdapter) formatOperands(opcode, mode, reg1, reg2 uint8) string
 			3: fmt.Sprintf("[R%d], R%d", reg1, reg2),
 			4: fmt.Sprintf("PUSH R%d", reg1),
 			5: fmt.Sprintf("POP R%d", reg1),
+			6: fmt.Sprintf("R%d, [R%d] (byte)", reg1, reg2),
+			7: fmt.Sprintf("[R%d], R%d (byte)", reg1, reg2),
 		}
 		if name, ok := modeNames[mode]; ok {
 			return name
@@ -223,6 +234,15 @@ func (a *CPULoggerAdapter) getStateData(state CPUState, cycles uint32) map[strin
 	}
 }
 
+// stateRegister returns general-purpose register r from a state snapshot.
+func stateRegister(state CPUState, r uint8) uint16 {
+	regs := [8]uint16{state.R0, state.R1, state.R2, state.R3, state.R4, state.R5, state.R6, state.R7}
+	if r < 8 {
+		return regs[r]
+	}
+	return 0
+}
+
 // detectRegisterChange detects if any register changed
 func (a *CPULoggerAdapter) detectRegisterChange(state CPUState) bool {
 	return state.R0 != a.lastState.R0 ||
@@ -86,3 +86,54 @@ type mockLogger struct{}
 
 func (m *mockLogger) LogCPU(instruction uint16, state CPUState, cycles uint32) {
 }
+
+// waitingMemory is a mockMemory that charges pending wait cycles, as a bus
+// modelling contention does.
+type waitingMemory struct {
+	mockMemory
+	pending uint32
+}
+
+func (m *waitingMemory) TakeWaitCycles() uint32 {
+	n := m.pending
+	m.pending = 0
+	return n
+}
+
+func TestStepCPUStallsForWaitCycles(t *testing.T) {
+	mem := &waitingMemory{}
+	cpu := NewCPU(mem, &mockLogger{})
+	cpu.SetEntryPoint(1, 0x8000)
+
+	// A halt longer than a step stalls the whole step, runs no
+	// instructions and carries into the next step.
+	mem.pending = 250
+	for step := 0; step < 2; step++ {
+		if err := cpu.StepCPU(100); err != nil {
+			t.Fatal(err)
+		}
+		if cpu.State.PCOffset != 0x8000 {
+			t.Fatalf("step %d ran instructions during a stall (PC %04X)", step, cpu.State.PCOffset)
+		}
+	}
+	if cpu.State.Cycles != 200 {
+		t.Fatalf("cycles = %d after two stalled steps, want 200", cpu.State.Cycles)
+	}
+
+	// The last 50 stall cycles leave 50 of the next step for instructions,
+	// the same as a CPU that stepped 50 cycles without waits.
+	if err := cpu.StepCPU(100); err != nil {
+		t.Fatal(err)
+	}
+	free := NewCPU(&mockMemory{}, &mockLogger{})
+	free.SetEntryPoint(1, 0x8000)
+	if err := free.StepCPU(50); err != nil {
+		t.Fatal(err)
+	}
+	if cpu.State.PCOffset != free.State.PCOffset {
+		t.Errorf("PC = %04X after the stall ended, want %04X", cpu.State.PCOffset, free.State.PCOffset)
+	}
+	if cpu.State.Cycles < 300 {
+		t.Errorf("cycles = %d, want the stall counted (at least 300)", cpu.State.Cycles)
+	}
+}
@@ -727,6 +727,7 @@ func (c *CPU) executeCALL(mode, reg1, reg2 uint8) error {
 		c.Push16(c.State.PCOffset)       // PCOffset (popped second by RET) -- correct return address
 		c.Push16(uint16(c.State.Flags))  // Flags (popped first by RET)
 
+		retOffset := c.State.PCOffset
 		if newOffset > 0xFFFF {
 			c.State.PCOffset = 0xFFFF
 		} else {
@@ -735,6 +736,7 @@ func (c *CPU) executeCALL(mode, reg1, reg2 uint8) error {
 		// Ensure PC stays aligned (instructions are 16-bit)
 		c.State.PCOffset &^= 1
 		c.State.Cycles += 3
+		c.reportCall(CallEventCall, c.State.PCBank, retOffset)
 		return nil
 
 	case 1: // CALL [bankReg:offsetReg]
@@ -744,6 +746,7 @@ func (c *CPU) executeCALL(mode, reg1, reg2 uint8) error {
 		}
 		// This mode has no immediate operand, so PCOffset already points
 		// right after the instruction — safe to push as-is.
+		retBank, retOffset := c.State.PCBank, c.State.PCOffset
 		c.Push16(uint16(c.State.PCBank))
 		c.Push16(c.State.PCOffset)
 		c.Push16(uint16(c.State.Flags))
@@ -752,6 +755,7 @@ func (c *CPU) executeCALL(mode, reg1, reg2 uint8) error {
 		c.State.PCBank = targetBank
 		c.State.PCOffset = targetOffset
 		c.State.Cycles += 3
+		c.reportCall(CallEventCall, retBank, retOffset)
 		return nil
 
 	default:
@@ -835,6 +839,7 @@ func (c *CPU) executeRET() error {
 	c.State.PCBank = c.State.PBR
 
 	c.State.Cycles += 2
+	c.reportCall(CallEventReturn, 0, 0)
 	return nil
 }
 
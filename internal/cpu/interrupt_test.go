@@ -115,3 +115,35 @@ func TestIRQMasked(t *testing.T) {
 		t.Errorf("IRQ should be pending (masked by I flag), got 0")
 	}
 }
+
+// TestCallHookReportsInterruptAndReturn checks the events a debugger's call
+// stack is built from.
+func TestCallHookReportsInterruptAndReturn(t *testing.T) {
+	mem := &mockMemory{}
+	cpu := NewCPU(mem, &mockLogger{})
+	mem.Write8(0, VectorIRQ, 0x01)
+	mem.Write8(0, VectorIRQ+1, 0x80)
+	mem.Write16(1, 0x8000, 0xF000) // handler: RET
+	mem.Write16(1, 0x8100, 0x0000) // NOP
+	cpu.SetEntryPoint(1, 0x8100)
+	sp := cpu.State.SP
+
+	var events []CallEvent
+	cpu.CallHook = func(ev CallEvent) { events = append(events, ev) }
+	cpu.TriggerInterrupt(INT_VBLANK)
+	cpu.ExecuteCycles(cpu.State.Cycles + 1) // NOP, then the interrupt
+	cpu.ExecuteInstruction()                // RET
+
+	want := []CallEvent{
+		{Kind: CallEventInterrupt, Bank: 1, Offset: 0x8000, ReturnBank: 1, ReturnOffset: 0x8102, SP: sp - 6},
+		{Kind: CallEventReturn, Bank: 1, Offset: 0x8102, SP: sp},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("events = %+v, want %+v", events, want)
+	}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Errorf("event %d = %+v, want %+v", i, events[i], want[i])
+		}
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 	"sync"
+
+	"nitro-core-dx/internal/hwdef"
 )
 
 // OAMReader interface for reading OAM data (to avoid import cycles)
@@ -131,11 +133,10 @@ func (c *CycleLogger) LogCycle(cpuState *CPUStateSnapshot) {
 	oamSprite0 := [6]uint8{0, 0, 0, 0, 0, 0}
 
 	if c.bus != nil {
-		// VBlank flag at 0x803E (PPU register 0x3E)
-		vblankFlag = c.bus.Read8(0, 0x803E)
+		vblankFlag = c.bus.Read8(0, hwdef.VBLANK_FLAG)
 
 		// OAM registers
-		oamAddr = c.bus.Read8(0, 0x8014) // OAM_ADDR
+		oamAddr = c.bus.Read8(0, hwdef.OAM_ADDR)
 		// Don't read OAM_DATA through bus.Read8 as it increments the byte index
 		// Instead, read it directly from OAM array if we have OAM access
 		if c.oam != nil && c.ppu != nil {
@@ -50,8 +50,24 @@ type CallFrame struct {
 	Bank   uint8
 	Offset uint16
 	FunctionName string
+
+	// ReturnBank:ReturnOffset is where the frame returns to, ReturnName
+	// its symbol when known.
+	ReturnBank   uint8
+	ReturnOffset uint16
+	ReturnName   string
+	// SP is the stack pointer just after the return address was pushed.
+	// A RET that leaves SP above it has returned from this frame.
+	SP uint16
+	// Interrupt marks a frame entered by an interrupt, not a CALL.
+	Interrupt bool
 }
 
+// MaxCallDepth bounds the call stack. Code that leaves functions without
+// RET (resetting SP, jumping out) would otherwise grow it forever; the
+// oldest frames are dropped first.
+const MaxCallDepth = 256
+
 // VariableInfo represents a variable's current state
 type VariableInfo struct {
 	Name     string
@@ -279,6 +295,38 @@ func (d *Debugger) PopCallFrame() *CallFrame {
 	return &frame
 }
 
+// EnterFrame pushes a frame for a CALL or interrupt entry.
+func (d *Debugger) EnterFrame(frame CallFrame) {
+	d.stackMu.Lock()
+	defer d.stackMu.Unlock()
+
+	if len(d.callStack) == MaxCallDepth {
+		d.callStack = append(d.callStack[:0], d.callStack[1:]...)
+	}
+	d.callStack = append(d.callStack, frame)
+}
+
+// ReturnTo pops every frame a RET leaving the stack pointer at sp has
+// returned from: normally just the innermost, more when frames were left
+// without a RET.
+func (d *Debugger) ReturnTo(sp uint16) {
+	d.stackMu.Lock()
+	defer d.stackMu.Unlock()
+
+	n := len(d.callStack)
+	for n > 0 && d.callStack[n-1].SP < sp {
+		n--
+	}
+	d.callStack = d.callStack[:n]
+}
+
+// ClearCallStack empties the call stack, e.g. after a reset.
+func (d *Debugger) ClearCallStack() {
+	d.stackMu.Lock()
+	defer d.stackMu.Unlock()
+	d.callStack = d.callStack[:0]
+}
+
 // GetCallStack returns the current call stack
 func (d *Debugger) GetCallStack() []CallFrame {
 	d.stackMu.RLock()
@@ -2,6 +2,7 @@ package debug
 
 import (
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -50,6 +51,36 @@ const (
 	ComponentSystem Component = "System"
 )
 
+// Components lists every component in display order.
+var Components = []Component{
+	ComponentCPU, ComponentPPU, ComponentAPU, ComponentMemory,
+	ComponentInput, ComponentUI, ComponentSystem,
+}
+
+// ParseComponent parses a component name, ignoring case ("cpu", "PPU").
+func ParseComponent(s string) (Component, error) {
+	for _, c := range Components {
+		if strings.EqualFold(s, string(c)) {
+			return c, nil
+		}
+	}
+	return "", fmt.Errorf("log component %q: want cpu, ppu, apu, memory, input, ui or system", s)
+}
+
+// ParseLogLevel parses a level name, ignoring case ("debug", "WARNING";
+// "warn" is accepted too).
+func ParseLogLevel(s string) (LogLevel, error) {
+	if strings.EqualFold(s, "warn") {
+		return LogLevelWarning, nil
+	}
+	for l := LogLevelNone; l <= LogLevelTrace; l++ {
+		if strings.EqualFold(s, l.String()) {
+			return l, nil
+		}
+	}
+	return LogLevelNone, fmt.Errorf("log level %q: want none, error, warning, info, debug or trace", s)
+}
+
 // LogEntry represents a single log entry
 type LogEntry struct {
 	Timestamp time.Time
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"sync"
 	"time"
+
+	"nitro-core-dx/internal/hwdef"
 )
 
 // Logger represents the centralized logging system
@@ -15,11 +17,14 @@ type Logger struct {
 	writeIndex int
 	entryCount int
 
-	// Component enable/disable flags
+	// Component enable/disable flags, and per-component levels that
+	// override minLevel (see SetComponentLevel)
 	componentEnabled map[Component]bool
+	componentLevel   map[Component]LogLevel
 	componentMu      sync.RWMutex
 
-	// Minimum log level (entries below this level are filtered)
+	// Most verbose level kept: an entry is filtered when it is less severe
+	// (a higher LogLevel) than this
 	minLevel LogLevel
 	levelMu  sync.RWMutex
 
@@ -43,6 +48,7 @@ func NewLogger(maxEntries int) *Logger {
 		writeIndex:       0,
 		entryCount:       0,
 		componentEnabled: make(map[Component]bool),
+		componentLevel:   make(map[Component]LogLevel),
 		minLevel:         LogLevelInfo, // Default to Info level
 		logChan:          make(chan LogEntry, 1000), // Buffered channel
 		shutdown:         make(chan struct{}),
@@ -103,21 +109,7 @@ func (l *Logger) addEntry(entry LogEntry) {
 
 // Log logs a message with the specified component and level
 func (l *Logger) Log(component Component, level LogLevel, message string, data map[string]interface{}) {
-	// Check if component is enabled
-	l.componentMu.RLock()
-	enabled := l.componentEnabled[component]
-	l.componentMu.RUnlock()
-
-	if !enabled {
-		return
-	}
-
-	// Check if level is high enough
-	l.levelMu.RLock()
-	minLevel := l.minLevel
-	l.levelMu.RUnlock()
-
-	if level < minLevel {
+	if !l.Enabled(component, level) {
 		return
 	}
 
@@ -246,6 +238,29 @@ func (l *Logger) Clear() {
 	l.writeIndex = 0
 }
 
+// Enabled reports whether an entry for component at level would be kept, so
+// hot paths can skip formatting messages nobody will see.
+func (l *Logger) Enabled(component Component, level LogLevel) bool {
+	if !l.IsComponentEnabled(component) {
+		return false
+	}
+	return level <= l.ComponentLevel(component)
+}
+
+// LogIOWrite records a bank 0 I/O write under the Memory component, naming
+// the register from the shared hwdef table (e.g. "I/O write OAM_ADDR
+// (0x8014) = 0x05").
+func (l *Logger) LogIOWrite(offset uint16, value uint8) {
+	if !l.Enabled(ComponentMemory, LogLevelDebug) {
+		return
+	}
+	data := map[string]interface{}{"address": offset, "value": value}
+	if reg, ok := hwdef.RegisterAt(offset); ok {
+		data["register"] = reg.Name
+	}
+	l.Log(ComponentMemory, LogLevelDebug, fmt.Sprintf("I/O write %s = 0x%02X", hwdef.Symbolize(offset), value), data)
+}
+
 // SetComponentEnabled enables or disables logging for a component
 func (l *Logger) SetComponentEnabled(component Component, enabled bool) {
 	l.componentMu.Lock()
@@ -260,6 +275,33 @@ func (l *Logger) IsComponentEnabled(component Component) bool {
 	return l.componentEnabled[component]
 }
 
+// SetComponentLevel sets the most verbose level kept for one component,
+// overriding SetMinLevel for it. It can be changed while the emulator runs.
+func (l *Logger) SetComponentLevel(component Component, level LogLevel) {
+	l.componentMu.Lock()
+	defer l.componentMu.Unlock()
+	l.componentLevel[component] = level
+}
+
+// ClearComponentLevel makes a component follow SetMinLevel again.
+func (l *Logger) ClearComponentLevel(component Component) {
+	l.componentMu.Lock()
+	defer l.componentMu.Unlock()
+	delete(l.componentLevel, component)
+}
+
+// ComponentLevel returns the most verbose level kept for a component: its
+// own level if one was set, otherwise the logger's.
+func (l *Logger) ComponentLevel(component Component) LogLevel {
+	l.componentMu.RLock()
+	level, ok := l.componentLevel[component]
+	l.componentMu.RUnlock()
+	if ok {
+		return level
+	}
+	return l.GetMinLevel()
+}
+
 // SetMinLevel sets the minimum log level
 func (l *Logger) SetMinLevel(level LogLevel) {
 	l.levelMu.Lock()
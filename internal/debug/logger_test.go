@@ -0,0 +1,52 @@
+package debug
+
+import "testing"
+
+func TestLoggerComponentLevels(t *testing.T) {
+	l := NewLogger(100)
+	defer l.Shutdown()
+	l.SetComponentEnabled(ComponentCPU, true)
+	l.SetComponentEnabled(ComponentPPU, true)
+
+	// The default level keeps Info and anything more severe.
+	if !l.Enabled(ComponentCPU, LogLevelError) || !l.Enabled(ComponentCPU, LogLevelInfo) || l.Enabled(ComponentCPU, LogLevelDebug) {
+		t.Fatal("default level should keep Error..Info and drop Debug")
+	}
+
+	l.SetComponentLevel(ComponentCPU, LogLevelTrace)
+	if !l.Enabled(ComponentCPU, LogLevelTrace) {
+		t.Error("CPU at trace drops Trace")
+	}
+	if l.Enabled(ComponentPPU, LogLevelDebug) {
+		t.Error("PPU follows CPU's level")
+	}
+	l.SetComponentLevel(ComponentPPU, LogLevelWarning)
+	if l.Enabled(ComponentPPU, LogLevelInfo) || !l.Enabled(ComponentPPU, LogLevelWarning) {
+		t.Error("PPU at warning should keep Warning and drop Info")
+	}
+	if l.Enabled(ComponentAPU, LogLevelError) {
+		t.Error("disabled APU logs")
+	}
+
+	l.ClearComponentLevel(ComponentCPU)
+	if l.ComponentLevel(ComponentCPU) != LogLevelInfo {
+		t.Errorf("cleared CPU level = %v, want Info", l.ComponentLevel(ComponentCPU))
+	}
+}
+
+func TestParseLogLevelAndComponent(t *testing.T) {
+	for in, want := range map[string]LogLevel{"none": LogLevelNone, "Error": LogLevelError, "warn": LogLevelWarning, "WARNING": LogLevelWarning, "debug": LogLevelDebug, "trace": LogLevelTrace} {
+		if got, err := ParseLogLevel(in); err != nil || got != want {
+			t.Errorf("ParseLogLevel(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	if _, err := ParseLogLevel("loud"); err == nil {
+		t.Error("ParseLogLevel(loud) succeeded")
+	}
+	if c, err := ParseComponent("memory"); err != nil || c != ComponentMemory {
+		t.Errorf("ParseComponent(memory) = %v, %v", c, err)
+	}
+	if _, err := ParseComponent("gpu"); err == nil {
+		t.Error("ParseComponent(gpu) succeeded")
+	}
+}
@@ -0,0 +1,193 @@
+package debug
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// Trace tracks. Each becomes one named row ("thread") in Perfetto or
+// chrome://tracing, in this order.
+const (
+	TraceTrackFrames = "Frames"
+	TraceTrackCPU    = "CPU"
+	TraceTrackDMA    = "DMA"
+	TraceTrackAudio  = "Audio"
+	TraceTrackUI     = "UI"
+)
+
+var traceTrackOrder = []string{TraceTrackFrames, TraceTrackCPU, TraceTrackDMA, TraceTrackAudio, TraceTrackUI}
+
+// DefaultTraceEvents is a TraceRecorder capacity of a few minutes of a
+// running ROM.
+const DefaultTraceEvents = 1 << 20
+
+// TraceRecorder collects host-time performance events (spans, instants and
+// counters) and writes them in the Chrome trace-event JSON format, which
+// Perfetto (ui.perfetto.dev) and chrome://tracing open as a timeline.
+//
+// Times are wall-clock, measured from the recorder's creation. Once
+// maxEvents are held further events are counted in Dropped and discarded,
+// so a long session keeps its beginning. It is safe for concurrent use.
+type TraceRecorder struct {
+	mu        sync.Mutex
+	start     time.Time
+	events    []traceEvent
+	maxEvents int
+	dropped   int
+}
+
+type traceEvent struct {
+	track string
+	name  string
+	phase byte // 'X' span, 'i' instant, 'C' counter
+	ts    time.Duration
+	dur   time.Duration
+	args  map[string]interface{}
+}
+
+// NewTraceRecorder creates a recorder holding up to maxEvents events
+// (DefaultTraceEvents if maxEvents <= 0).
+func NewTraceRecorder(maxEvents int) *TraceRecorder {
+	if maxEvents <= 0 {
+		maxEvents = DefaultTraceEvents
+	}
+	return &TraceRecorder{start: time.Now(), maxEvents: maxEvents}
+}
+
+// Span records name on track as running from start until end.
+func (r *TraceRecorder) Span(track, name string, start, end time.Time, args map[string]interface{}) {
+	r.add(traceEvent{track: track, name: name, phase: 'X', ts: start.Sub(r.start), dur: end.Sub(start), args: args})
+}
+
+// Instant records a point event on track.
+func (r *TraceRecorder) Instant(track, name string, at time.Time, args map[string]interface{}) {
+	r.add(traceEvent{track: track, name: name, phase: 'i', ts: at.Sub(r.start), args: args})
+}
+
+// Counter records the values of the counter series name at a point in
+// time, e.g. the audio queue depth; Perfetto draws each as a graph.
+func (r *TraceRecorder) Counter(name string, at time.Time, values map[string]float64) {
+	args := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		args[k] = v
+	}
+	r.add(traceEvent{name: name, phase: 'C', ts: at.Sub(r.start), args: args})
+}
+
+func (r *TraceRecorder) add(ev traceEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.events) >= r.maxEvents {
+		r.dropped++
+		return
+	}
+	r.events = append(r.events, ev)
+}
+
+// Len is the number of events held.
+func (r *TraceRecorder) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.events)
+}
+
+// Dropped is the number of events discarded because the recorder was full.
+func (r *TraceRecorder) Dropped() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.dropped
+}
+
+// chromeTraceEvent is one entry of the trace-event format's traceEvents
+// array. Times are microseconds.
+type chromeTraceEvent struct {
+	Name  string                 `json:"name"`
+	Cat   string                 `json:"cat,omitempty"`
+	Phase string                 `json:"ph"`
+	TS    float64                `json:"ts"`
+	Dur   *float64               `json:"dur,omitempty"`
+	PID   int                    `json:"pid"`
+	TID   int                    `json:"tid"`
+	Scope string                 `json:"s,omitempty"`
+	Args  map[string]interface{} `json:"args,omitempty"`
+}
+
+const tracePID = 1
+
+// WriteChromeTrace writes the recorded events as a Chrome trace-event JSON
+// object. Tracks are named threads of one "Nitro-Core-DX" process; tracks
+// other than the TraceTrack* constants follow them in first-use order.
+func (r *TraceRecorder) WriteChromeTrace(w io.Writer) error {
+	r.mu.Lock()
+	events := append([]traceEvent(nil), r.events...)
+	dropped := r.dropped
+	r.mu.Unlock()
+
+	tids := make(map[string]int)
+	var tracks []string
+	addTrack := func(track string) {
+		if _, ok := tids[track]; !ok {
+			tids[track] = len(tracks) + 1
+			tracks = append(tracks, track)
+		}
+	}
+	for _, t := range traceTrackOrder {
+		addTrack(t)
+	}
+	for _, ev := range events {
+		if ev.phase != 'C' {
+			addTrack(ev.track)
+		}
+	}
+
+	out := make([]chromeTraceEvent, 0, len(events)+2*len(tracks)+1)
+	out = append(out, chromeTraceEvent{Name: "process_name", Phase: "M", PID: tracePID, Args: map[string]interface{}{"name": "Nitro-Core-DX"}})
+	for i, t := range tracks {
+		out = append(out,
+			chromeTraceEvent{Name: "thread_name", Phase: "M", PID: tracePID, TID: tids[t], Args: map[string]interface{}{"name": t}},
+			chromeTraceEvent{Name: "thread_sort_index", Phase: "M", PID: tracePID, TID: tids[t], Args: map[string]interface{}{"sort_index": i}},
+		)
+	}
+	for _, ev := range events {
+		ce := chromeTraceEvent{
+			Name:  ev.name,
+			Phase: string(ev.phase),
+			TS:    traceMicros(ev.ts),
+			PID:   tracePID,
+			TID:   tids[ev.track],
+			Args:  ev.args,
+		}
+		if ev.track != "" {
+			ce.Cat = ev.track
+		}
+		switch ev.phase {
+		case 'X':
+			dur := traceMicros(ev.dur)
+			ce.Dur = &dur
+		case 'i':
+			ce.Scope = "t"
+		}
+		out = append(out, ce)
+	}
+
+	bw := bufio.NewWriter(w)
+	if err := json.NewEncoder(bw).Encode(struct {
+		TraceEvents     []chromeTraceEvent     `json:"traceEvents"`
+		DisplayTimeUnit string                 `json:"displayTimeUnit"`
+		OtherData       map[string]interface{} `json:"otherData"`
+	}{
+		TraceEvents:     out,
+		DisplayTimeUnit: "ms",
+		OtherData:       map[string]interface{}{"dropped_events": dropped},
+	}); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+func traceMicros(d time.Duration) float64 {
+	return float64(d) / float64(time.Microsecond)
+}
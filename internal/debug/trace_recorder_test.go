@@ -0,0 +1,72 @@
+package debug
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestTraceRecorderWritesChromeTrace(t *testing.T) {
+	r := NewTraceRecorder(3)
+	t0 := r.start
+	r.Span(TraceTrackFrames, "Frame 1", t0.Add(time.Millisecond), t0.Add(3*time.Millisecond), map[string]interface{}{"cpu_cycles": 100})
+	r.Instant("Custom", "marker", t0.Add(2*time.Millisecond), nil)
+	r.Counter("audio queue", t0.Add(4*time.Millisecond), map[string]float64{"samples": 735})
+	r.Span(TraceTrackUI, "tick", t0, t0, nil) // over capacity
+	if r.Len() != 3 || r.Dropped() != 1 {
+		t.Fatalf("Len %d Dropped %d, want 3 and 1", r.Len(), r.Dropped())
+	}
+
+	var buf bytes.Buffer
+	if err := r.WriteChromeTrace(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		TraceEvents []struct {
+			Name string                 `json:"name"`
+			Ph   string                 `json:"ph"`
+			TS   float64                `json:"ts"`
+			Dur  float64                `json:"dur"`
+			TID  int                    `json:"tid"`
+			Args map[string]interface{} `json:"args"`
+		} `json:"traceEvents"`
+		OtherData map[string]interface{} `json:"otherData"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("not JSON: %v\n%s", err, buf.String())
+	}
+	if got.OtherData["dropped_events"] != float64(1) {
+		t.Errorf("otherData = %v", got.OtherData)
+	}
+
+	threads := map[int]string{}
+	for _, ev := range got.TraceEvents {
+		if ev.Ph == "M" && ev.Name == "thread_name" {
+			threads[ev.TID] = ev.Args["name"].(string)
+		}
+	}
+	seen := 0
+	for _, ev := range got.TraceEvents {
+		switch ev.Name {
+		case "Frame 1":
+			seen++
+			if ev.Ph != "X" || ev.TS != 1000 || ev.Dur != 2000 || threads[ev.TID] != TraceTrackFrames {
+				t.Errorf("frame span = %+v on %q", ev, threads[ev.TID])
+			}
+		case "marker":
+			seen++
+			if ev.Ph != "i" || threads[ev.TID] != "Custom" {
+				t.Errorf("instant = %+v on %q", ev, threads[ev.TID])
+			}
+		case "audio queue":
+			seen++
+			if ev.Ph != "C" || ev.Args["samples"] != float64(735) {
+				t.Errorf("counter = %+v", ev)
+			}
+		}
+	}
+	if seen != 3 {
+		t.Errorf("found %d of 3 events in %s", seen, buf.String())
+	}
+}
@@ -0,0 +1,44 @@
+package devkit
+
+import "nitro-core-dx/internal/harness"
+
+// AudioHealth summarizes the clicks (see harness.ClickDetector) found in the
+// session's audio output since the ROM was loaded, reset or a state
+// restored.
+type AudioHealth struct {
+	Clicks int
+	// LastFrame (emulator.Emulator.FrameCount) and LastJump describe the
+	// most recent click.
+	LastFrame uint64
+	LastJump  int
+}
+
+// OK reports whether no click has been found.
+func (h AudioHealth) OK() bool { return h.Clicks == 0 }
+
+// feedAudioHealthLocked runs the frame just emulated through the click
+// detector.
+func (s *Service) feedAudioHealthLocked() {
+	if s.audioClicks == nil {
+		s.audioClicks = harness.NewClickDetector(harness.DefaultClickOptions())
+	}
+	clicks := s.audioClicks.Feed(s.emu.AudioSampleBuffer, s.emu.AudioNoteSamples)
+	if len(clicks) == 0 {
+		return
+	}
+	s.audioHealth.Clicks += len(clicks)
+	s.audioHealth.LastFrame = s.emu.FrameCount - 1
+	s.audioHealth.LastJump = clicks[len(clicks)-1].Jump
+}
+
+// resetAudioHealthLocked starts the detector over. The output before and
+// after a state load or reset does not join up, so it must not see the
+// jump; clear also forgets the clicks counted so far.
+func (s *Service) resetAudioHealthLocked(clear bool) {
+	if s.audioClicks != nil {
+		s.audioClicks.Reset()
+	}
+	if clear {
+		s.audioHealth = AudioHealth{}
+	}
+}
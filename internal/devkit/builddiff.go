@@ -0,0 +1,107 @@
+package devkit
+
+import (
+	"fmt"
+
+	"nitro-core-dx/internal/emulator"
+	"nitro-core-dx/internal/rom"
+)
+
+// BuildDiff is the generated code of the last two successful builds,
+// compared instruction by instruction and laid out side by side: the
+// previous build on the left, the latest on the right.
+type BuildDiff struct {
+	Header []rom.HeaderChange
+	// OnlyPrevious and OnlyLatest list banks present in just one build.
+	OnlyPrevious, OnlyLatest []uint8
+	Rows                     []BuildDiffRow
+}
+
+// Equal reports whether the two builds produced the same header and code.
+func (d *BuildDiff) Equal() bool {
+	return len(d.Header) == 0 && len(d.OnlyPrevious) == 0 && len(d.OnlyLatest) == 0 && len(d.Rows) == 0
+}
+
+// BuildDiffRow is one row of a BuildDiff. Kind is '@' for the start of a
+// hunk (only Bank is set), ' ' for unchanged code, '-' and '+' for code
+// only in the previous or latest build, and '~' for a previous instruction
+// replaced by the latest one beside it. Old or New is nil where that side
+// of the row is empty.
+type BuildDiffRow struct {
+	Kind     byte
+	Bank     uint8
+	Old, New *emulator.CodeLine
+}
+
+// BuildDiff compares the last two successful builds' disassembly, keeping
+// context unchanged instructions around each change. Lines carry each
+// build's own function names.
+func (s *Service) BuildDiff(context int) (*BuildDiff, error) {
+	s.mu.RLock()
+	prev, prevSyms := s.prevBuiltROM, s.prevBuiltSymbols
+	latest, latestSyms := s.builtROM, s.builtSymbols
+	s.mu.RUnlock()
+	if prev == nil {
+		return nil, fmt.Errorf("need two successful builds to compare")
+	}
+	d, err := rom.DiffROMs(prev, latest, context)
+	if err != nil {
+		return nil, err
+	}
+	out := &BuildDiff{Header: d.Header, OnlyPrevious: d.OnlyA, OnlyLatest: d.OnlyB}
+	for _, h := range d.Hunks {
+		out.Rows = append(out.Rows, BuildDiffRow{Kind: '@', Bank: h.Bank})
+		ops := h.Ops
+		for len(ops) > 0 {
+			if ops[0].Kind == ' ' {
+				out.Rows = append(out.Rows, BuildDiffRow{
+					Kind: ' ',
+					Bank: h.Bank,
+					Old:  diffLine(ops[0].Inst, prevSyms),
+					New:  diffLine(ops[0].Inst, latestSyms),
+				})
+				ops = ops[1:]
+				continue
+			}
+			// Pair a run of removals with the additions that follow it, so
+			// replaced code lines up with its replacement.
+			var removed, added []rom.Instruction
+			for len(ops) > 0 && ops[0].Kind == '-' {
+				removed = append(removed, ops[0].Inst)
+				ops = ops[1:]
+			}
+			for len(ops) > 0 && ops[0].Kind == '+' {
+				added = append(added, ops[0].Inst)
+				ops = ops[1:]
+			}
+			for i := 0; i < max(len(removed), len(added)); i++ {
+				row := BuildDiffRow{Bank: h.Bank}
+				if i < len(removed) {
+					row.Old = diffLine(removed[i], prevSyms)
+				}
+				if i < len(added) {
+					row.New = diffLine(added[i], latestSyms)
+				}
+				switch {
+				case row.Old != nil && row.New != nil:
+					row.Kind = '~'
+				case row.Old != nil:
+					row.Kind = '-'
+				default:
+					row.Kind = '+'
+				}
+				out.Rows = append(out.Rows, row)
+			}
+		}
+	}
+	return out, nil
+}
+
+func diffLine(inst rom.Instruction, syms emulator.SymbolTable) *emulator.CodeLine {
+	return &emulator.CodeLine{
+		Bank:   inst.Bank,
+		Offset: inst.Offset,
+		Text:   inst.Text,
+		Symbol: syms.Lookup(inst.Bank, inst.Offset),
+	}
+}
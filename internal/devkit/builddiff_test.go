@@ -0,0 +1,57 @@
+package devkit
+
+import "testing"
+
+func TestServiceBuildDiff(t *testing.T) {
+	svc := NewService(t.TempDir())
+	defer svc.Shutdown()
+
+	if _, err := svc.BuildDiff(3); err == nil {
+		t.Fatal("BuildDiff with no builds succeeded")
+	}
+
+	before := "function Start()\n    x := 1\n    while true\n        wait_vblank()\n"
+	after := "function Start()\n    x := 2\n    while true\n        wait_vblank()\n"
+	for _, src := range []string{before, after} {
+		if _, err := svc.BuildSource(src, "main.corelx"); err != nil {
+			t.Fatalf("build: %v", err)
+		}
+	}
+	d, err := svc.BuildDiff(3)
+	if err != nil {
+		t.Fatalf("BuildDiff: %v", err)
+	}
+	if d.Equal() {
+		t.Fatal("builds of different sources compare equal")
+	}
+	if d.Rows[0].Kind != '@' {
+		t.Fatalf("first row kind %q, want a hunk start", d.Rows[0].Kind)
+	}
+	changed := 0
+	for _, row := range d.Rows {
+		switch row.Kind {
+		case '~':
+			changed++
+			if row.Old.Text == row.New.Text {
+				t.Errorf("changed row shows the same instruction on both sides: %s", row.Old.Text)
+			}
+		case ' ':
+			if row.Old == nil || row.New == nil || row.Old.Text != row.New.Text {
+				t.Errorf("context row differs: %+v", row)
+			}
+		}
+		if row.Old != nil && row.Old.Symbol == "" {
+			t.Errorf("previous build line %s has no symbol", row.Old)
+		}
+	}
+	if changed == 0 {
+		t.Fatalf("no changed rows in %+v", d.Rows)
+	}
+
+	if _, err := svc.BuildSource(after, "main.corelx"); err != nil {
+		t.Fatalf("rebuild: %v", err)
+	}
+	if d, err := svc.BuildDiff(3); err != nil || !d.Equal() {
+		t.Fatalf("rebuilding the same source: diff %+v, %v; want equal", d, err)
+	}
+}
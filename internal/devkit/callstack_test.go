@@ -0,0 +1,58 @@
+package devkit
+
+import (
+	"strings"
+	"testing"
+
+	"nitro-core-dx/internal/emulator"
+)
+
+func TestServiceCallStackNamesFrames(t *testing.T) {
+	svc := NewService(t.TempDir())
+	defer svc.Shutdown()
+
+	src := `
+function Inner()
+    while true
+        wait_vblank()
+
+function Outer()
+    Inner()
+
+function Start()
+    Outer()
+`
+	build, err := svc.BuildSource(src, "callstack.corelx")
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if err := svc.LoadROMBytes(build.Result.ROMBytes); err != nil {
+		t.Fatalf("load rom: %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		if err := svc.RunFrame(); err != nil {
+			t.Fatalf("frame %d: %v", i, err)
+		}
+	}
+
+	stack := svc.CallStack()
+	var names []string
+	for _, f := range stack {
+		names = append(names, f.FunctionName)
+	}
+	got := strings.Join(names, " > ")
+	if !strings.HasSuffix(got, "Outer > Inner") {
+		t.Fatalf("call stack = %q, want it to end Outer > Inner", got)
+	}
+	inner, outer := stack[len(stack)-1], stack[len(stack)-2]
+	if inner.ReturnBank != outer.Bank || inner.ReturnOffset <= outer.Offset || !strings.HasPrefix(inner.ReturnName, "Outer+") {
+		t.Errorf("Inner returns to %s, want a point inside Outer", emulator.FormatCallFrame(inner))
+	}
+
+	if err := svc.ResetEmulator(); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(svc.CallStack()); n != 0 {
+		t.Errorf("call stack has %d frames after a reset", n)
+	}
+}
@@ -0,0 +1,69 @@
+package devkit
+
+import (
+	"bytes"
+	"fmt"
+
+	"nitro-core-dx/internal/corelx"
+	"nitro-core-dx/internal/debug"
+	"nitro-core-dx/internal/emulator"
+)
+
+// symbolTableFor converts a build's function list for fault reports and
+// the disassembly view.
+func symbolTableFor(res *corelx.CompileResult) emulator.SymbolTable {
+	if res == nil || len(res.Functions) == 0 {
+		return nil
+	}
+	syms := make([]emulator.Symbol, len(res.Functions))
+	for i, f := range res.Functions {
+		syms[i] = emulator.Symbol{Name: f.Name, Bank: f.Bank, Offset: f.Offset}
+	}
+	return emulator.NewSymbolTable(syms)
+}
+
+// rememberBuild keeps a successful build's ROM and symbols so that loading
+// that ROM gets symbolized fault reports. The build it replaces is kept
+// for BuildDiff.
+func (s *Service) rememberBuild(res *corelx.CompileResult) {
+	if res == nil || len(res.ROMBytes) == 0 {
+		return
+	}
+	s.mu.Lock()
+	s.prevBuiltROM, s.prevBuiltSymbols = s.builtROM, s.builtSymbols
+	s.builtROM = res.ROMBytes
+	s.builtSymbols = symbolTableFor(res)
+	s.mu.Unlock()
+}
+
+// symbolsForLocked returns the last build's symbols when romBytes is that
+// build's ROM, and nil for any other ROM.
+func (s *Service) symbolsForLocked(romBytes []byte) emulator.SymbolTable {
+	if bytes.Equal(romBytes, s.builtROM) {
+		return s.builtSymbols
+	}
+	return nil
+}
+
+// Disassemble decodes the code around bank:offset (see
+// emulator.Emulator.DisassembleAround), symbolized when the loaded ROM came
+// from the last build.
+func (s *Service) Disassemble(bank uint8, offset uint16, before, after int) ([]emulator.CodeLine, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.emu == nil {
+		return nil, fmt.Errorf("no ROM loaded")
+	}
+	return s.emu.DisassembleAround(bank, offset, before, after, s.symbols), nil
+}
+
+// CallStack returns the loaded ROM's call stack, outermost frame first,
+// with function names when the ROM came from the last build.
+func (s *Service) CallStack() []debug.CallFrame {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.emu == nil {
+		return nil
+	}
+	return s.emu.CallStack()
+}
@@ -0,0 +1,301 @@
+package devkit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"nitro-core-dx/internal/corelx"
+	"nitro-core-dx/internal/emulator"
+)
+
+const (
+	// GalleryManifestName is the manifest file BuildGallery writes into its
+	// output directory.
+	GalleryManifestName = "gallery.json"
+	// GalleryIndexName is the Markdown page that shows the screenshots in
+	// the project docs.
+	GalleryIndexName = "README.md"
+	// DefaultGalleryFrames is how long each project runs before capture:
+	// five seconds, past the ~190-frame default boot splash.
+	DefaultGalleryFrames = 300
+
+	GalleryKindTemplate = "template"
+	GalleryKindExample  = "example"
+
+	galleryWidth  = 320
+	galleryHeight = 200
+)
+
+// GalleryOptions configures BuildGallery.
+type GalleryOptions struct {
+	OutDir string
+	// Frames to run each project before the screenshot; 0 means
+	// DefaultGalleryFrames.
+	Frames int
+	// Examples are CoreLX sources, project directories or .ncdx containers
+	// built alongside the Templates.
+	Examples []string
+}
+
+// GalleryEntry is one captured project. Error is set, and Image empty, when
+// the project failed to build or run.
+type GalleryEntry struct {
+	Name   string `json:"name"`
+	Kind   string `json:"kind"`
+	Source string `json:"source,omitempty"`
+	Image  string `json:"image,omitempty"`
+	Hash   string `json:"fb_hash,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// GalleryManifest indexes the screenshots in a gallery directory. Image
+// paths are relative to that directory.
+type GalleryManifest struct {
+	Frames  int            `json:"frames"`
+	Width   int            `json:"width"`
+	Height  int            `json:"height"`
+	Entries []GalleryEntry `json:"entries"`
+}
+
+// Entry looks up a captured project by kind and name.
+func (m *GalleryManifest) Entry(kind, name string) (GalleryEntry, bool) {
+	if m == nil {
+		return GalleryEntry{}, false
+	}
+	for _, e := range m.Entries {
+		if e.Kind == kind && e.Name == name {
+			return e, true
+		}
+	}
+	return GalleryEntry{}, false
+}
+
+// Failed returns the entries that have no screenshot.
+func (m *GalleryManifest) Failed() []GalleryEntry {
+	var out []GalleryEntry
+	for _, e := range m.Entries {
+		if e.Error != "" {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// BuildGallery compiles every template and example, runs each headlessly for
+// opts.Frames frames, and writes a PNG per project plus gallery.json into
+// opts.OutDir. A project that fails is recorded in the manifest rather than
+// aborting the run; the returned error covers output I/O only.
+func BuildGallery(opts GalleryOptions) (*GalleryManifest, error) {
+	if opts.OutDir == "" {
+		return nil, fmt.Errorf("gallery output directory is required")
+	}
+	frames := opts.Frames
+	if frames <= 0 {
+		frames = DefaultGalleryFrames
+	}
+	if err := os.MkdirAll(opts.OutDir, 0o755); err != nil {
+		return nil, fmt.Errorf("create gallery dir: %w", err)
+	}
+
+	manifest := &GalleryManifest{Frames: frames, Width: galleryWidth, Height: galleryHeight}
+	capture := func(entry GalleryEntry, compile func() (*corelx.CompileResult, error)) error {
+		fb, err := runGalleryProject(compile, frames)
+		if err != nil {
+			entry.Error = err.Error()
+			manifest.Entries = append(manifest.Entries, entry)
+			return nil
+		}
+		entry.Image = entry.Kind + "_" + gallerySlug(entry.Name) + ".png"
+		entry.Hash = framebufferHash(fb)
+		if err := writeFramebufferPNG(filepath.Join(opts.OutDir, entry.Image), fb); err != nil {
+			return fmt.Errorf("write %s: %w", entry.Image, err)
+		}
+		manifest.Entries = append(manifest.Entries, entry)
+		return nil
+	}
+
+	for _, tpl := range Templates {
+		tpl := tpl
+		err := capture(GalleryEntry{Name: tpl.Name, Kind: GalleryKindTemplate}, func() (*corelx.CompileResult, error) {
+			return corelx.CompileSource(tpl.Content, gallerySlug(tpl.Name)+".corelx", &corelx.CompileOptions{EmitROMBytes: true})
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	examples := append([]string(nil), opts.Examples...)
+	sort.Strings(examples)
+	for _, path := range examples {
+		path := path
+		err := capture(GalleryEntry{Name: exampleName(path), Kind: GalleryKindExample, Source: filepath.ToSlash(path)}, func() (*corelx.CompileResult, error) {
+			return corelx.CompileProject(path, &corelx.CompileOptions{EmitROMBytes: true})
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal gallery manifest: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(opts.OutDir, GalleryManifestName), append(data, '\n'), 0o644); err != nil {
+		return nil, fmt.Errorf("write gallery manifest: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(opts.OutDir, GalleryIndexName), galleryIndex(manifest), 0o644); err != nil {
+		return nil, fmt.Errorf("write gallery index: %w", err)
+	}
+	return manifest, nil
+}
+
+// LoadGalleryManifest reads gallery.json from a gallery directory.
+func LoadGalleryManifest(dir string) (*GalleryManifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, GalleryManifestName))
+	if err != nil {
+		return nil, err
+	}
+	var m GalleryManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", GalleryManifestName, err)
+	}
+	return &m, nil
+}
+
+func galleryIndex(m *GalleryManifest) []byte {
+	var sb strings.Builder
+	sb.WriteString("# Example Gallery\n\n")
+	sb.WriteString("Generated by `go run ./cmd/gallery_builder`; do not edit by hand.\n")
+	fmt.Fprintf(&sb, "Each project ran headlessly for %d frames before capture.\n", m.Frames)
+	for _, section := range []struct{ kind, title string }{
+		{GalleryKindTemplate, "Project Templates"},
+		{GalleryKindExample, "Examples"},
+	} {
+		wrote := false
+		for _, e := range m.Entries {
+			if e.Kind != section.kind {
+				continue
+			}
+			if !wrote {
+				fmt.Fprintf(&sb, "\n## %s\n", section.title)
+				wrote = true
+			}
+			fmt.Fprintf(&sb, "\n### %s\n\n", e.Name)
+			if e.Source != "" {
+				fmt.Fprintf(&sb, "Source: `%s`\n\n", e.Source)
+			}
+			if e.Error != "" {
+				fmt.Fprintf(&sb, "No preview: %s\n", strings.SplitN(e.Error, "\n", 2)[0])
+				continue
+			}
+			fmt.Fprintf(&sb, "![%s](%s)\n", e.Name, e.Image)
+		}
+	}
+	return []byte(sb.String())
+}
+
+func runGalleryProject(compile func() (*corelx.CompileResult, error), frames int) ([]uint32, error) {
+	res, err := compile()
+	if err != nil {
+		return nil, fmt.Errorf("compile: %w", err)
+	}
+	if res == nil || len(res.ROMBytes) == 0 {
+		return nil, fmt.Errorf("compile produced no ROM")
+	}
+	return runHeadless(res.ROMBytes, frames)
+}
+
+// runHeadless runs romBytes deterministically for frames frames and returns
+// the last frame's framebuffer.
+func runHeadless(romBytes []byte, frames int) ([]uint32, error) {
+	emu := emulator.NewEmulator()
+	defer func() {
+		if emu.Logger != nil {
+			emu.Logger.Shutdown()
+		}
+	}()
+	emu.SetFrameLimit(false)
+	emu.SetDeterministic(true)
+	if err := emu.LoadROM(romBytes); err != nil {
+		return nil, fmt.Errorf("load ROM: %w", err)
+	}
+	emu.Start()
+	for i := 0; i < frames; i++ {
+		if err := emu.RunFrame(); err != nil {
+			return nil, fmt.Errorf("frame %d: %w", i, err)
+		}
+	}
+	return append([]uint32(nil), emu.PPU.OutputBuffer[:]...), nil
+}
+
+// exampleName names a project after its directory when the source is the
+// conventional main.corelx, otherwise after the file.
+func exampleName(path string) string {
+	base := filepath.Base(path)
+	if strings.EqualFold(base, "main.corelx") {
+		return filepath.Base(filepath.Dir(path))
+	}
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+func gallerySlug(name string) string {
+	var sb strings.Builder
+	lastUnderscore := false
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			sb.WriteRune(r)
+			lastUnderscore = false
+		} else if !lastUnderscore && sb.Len() > 0 {
+			sb.WriteByte('_')
+			lastUnderscore = true
+		}
+	}
+	return strings.TrimSuffix(sb.String(), "_")
+}
+
+func framebufferHash(fb []uint32) string {
+	raw := make([]byte, len(fb)*4)
+	for i, px := range fb {
+		raw[i*4+0] = byte(px)
+		raw[i*4+1] = byte(px >> 8)
+		raw[i*4+2] = byte(px >> 16)
+		raw[i*4+3] = byte(px >> 24)
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:])
+}
+
+func writeFramebufferPNG(path string, fb []uint32) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := encodeFramebufferPNG(f, fb); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func encodeFramebufferPNG(w io.Writer, fb []uint32) error {
+	if len(fb) < galleryWidth*galleryHeight {
+		return fmt.Errorf("framebuffer too small: %d", len(fb))
+	}
+	img := image.NewNRGBA(image.Rect(0, 0, galleryWidth, galleryHeight))
+	for y := 0; y < galleryHeight; y++ {
+		for x := 0; x < galleryWidth; x++ {
+			c := fb[y*galleryWidth+x]
+			img.SetNRGBA(x, y, color.NRGBA{R: uint8(c >> 16), G: uint8(c >> 8), B: uint8(c), A: 0xFF})
+		}
+	}
+	return png.Encode(w, img)
+}
@@ -0,0 +1,62 @@
+package devkit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuildGalleryCapturesEveryProject(t *testing.T) {
+	outDir := t.TempDir()
+	broken := filepath.Join(t.TempDir(), "broken.corelx")
+	if err := os.WriteFile(broken, []byte("function Start()\n    missing_call()\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	example := filepath.Join("..", "..", "Games", "SpriteProbe", "main.corelx")
+
+	manifest, err := BuildGallery(GalleryOptions{OutDir: outDir, Frames: 2, Examples: []string{example, broken}})
+	if err != nil {
+		t.Fatalf("build gallery: %v", err)
+	}
+	if got, want := len(manifest.Entries), len(Templates)+2; got != want {
+		t.Fatalf("entries = %d, want %d", got, want)
+	}
+
+	// Every template must build: a preview that fails here is a template
+	// the New Project dialog would hand out broken.
+	for _, tpl := range Templates {
+		e, ok := manifest.Entry(GalleryKindTemplate, tpl.Name)
+		if !ok || e.Error != "" {
+			t.Errorf("template %q: %+v", tpl.Name, e)
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(outDir, e.Image)); err != nil {
+			t.Errorf("template %q screenshot: %v", tpl.Name, err)
+		}
+	}
+	if e, ok := manifest.Entry(GalleryKindExample, "SpriteProbe"); !ok || e.Image != "example_spriteprobe.png" || len(e.Hash) != 64 {
+		t.Errorf("SpriteProbe entry = %+v", e)
+	}
+	failed := manifest.Failed()
+	if len(failed) != 1 || failed[0].Name != "broken" || failed[0].Image != "" {
+		t.Fatalf("failed = %+v, want only the broken example", failed)
+	}
+
+	loaded, err := LoadGalleryManifest(outDir)
+	if err != nil {
+		t.Fatalf("load manifest: %v", err)
+	}
+	if loaded.Frames != 2 || len(loaded.Entries) != len(manifest.Entries) {
+		t.Errorf("loaded manifest = %+v", loaded)
+	}
+	index, err := os.ReadFile(filepath.Join(outDir, GalleryIndexName))
+	if err != nil {
+		t.Fatalf("read index: %v", err)
+	}
+	for _, want := range []string{"![Blank Game](template_blank_game.png)", "### broken", "No preview: compile:"} {
+		if !strings.Contains(string(index), want) {
+			t.Errorf("index missing %q:\n%s", want, index)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package devkit
+
+import (
+	"bytes"
+	"fmt"
+
+	"nitro-core-dx/internal/corelx"
+)
+
+// PatchLiveAsset replaces an asset the running ROM has uploaded with its
+// rebuilt bytes, without restarting the ROM: old is the asset as last
+// built, next as rebuilt (see corelx.LiveAsset). Pieces with a fixed
+// destination are rewritten there; the others are found by searching VRAM
+// or CGRAM for old's bytes, on 2-byte boundaries, and every copy is
+// replaced. The bytes go in through the PPU's DMA write path. It returns the
+// number of pieces written, 0 when the ROM has not uploaded the asset.
+//
+// A piece that changed size cannot be patched in place, and a piece whose
+// old bytes are all the same value (a blank tile) cannot be found; both are
+// errors that call for a rebuild.
+func (s *Service) PatchLiveAsset(old, next corelx.LiveAsset) (int, error) {
+	if len(old.Pieces) != len(next.Pieces) {
+		return 0, fmt.Errorf("asset %s changed shape; rebuild to apply it", next.Name)
+	}
+	for i, p := range next.Pieces {
+		o := old.Pieces[i]
+		if len(o.Data) != len(p.Data) || o.Space != p.Space || o.Addr != p.Addr {
+			return 0, fmt.Errorf("asset %s changed size (%d -> %d bytes); rebuild to apply it", next.Name, len(o.Data), len(p.Data))
+		}
+		if p.Addr < 0 && uniformBytes(o.Data) {
+			return 0, fmt.Errorf("asset %s is blank, so it cannot be found in %s; rebuild to apply it", next.Name, p.Space)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.emu == nil {
+		return 0, fmt.Errorf("no ROM loaded")
+	}
+	if err := s.movieBusyErrorLocked(); err != nil {
+		return 0, err
+	}
+	s.breakStepHistoryLocked()
+	p := s.emu.PPU
+	patched := 0
+	for i, piece := range next.Pieces {
+		destType, mem := uint8(0), p.VRAM[:]
+		if piece.Space == corelx.LiveCGRAM {
+			destType, mem = 1, p.CGRAM[:]
+		}
+		addrs := []int{piece.Addr}
+		if piece.Addr < 0 {
+			addrs = findAligned(mem, old.Pieces[i].Data, 2)
+		} else if piece.Addr+len(piece.Data) > len(mem) {
+			return patched, fmt.Errorf("asset %s: %s 0x%04X+%d is out of range", next.Name, piece.Space, piece.Addr, len(piece.Data))
+		}
+		for _, addr := range addrs {
+			if err := p.InjectDMA(destType, uint16(addr), piece.Data); err != nil {
+				return patched, err
+			}
+			patched++
+		}
+	}
+	return patched, nil
+}
+
+// findAligned returns the offsets of every copy of pattern in mem that
+// starts on a multiple of align.
+func findAligned(mem, pattern []byte, align int) []int {
+	var out []int
+	if len(pattern) == 0 {
+		return out
+	}
+	for i := 0; i+len(pattern) <= len(mem); i += align {
+		if bytes.Equal(mem[i:i+len(pattern)], pattern) {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+func uniformBytes(b []byte) bool {
+	for _, v := range b {
+		if v != b[0] {
+			return false
+		}
+	}
+	return true
+}
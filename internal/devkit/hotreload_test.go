@@ -0,0 +1,107 @@
+package devkit
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"nitro-core-dx/internal/corelx"
+	"nitro-core-dx/internal/emulator"
+)
+
+// tileHex is one packed 4bpp 8x8 tile as a hex asset file. Its bytes count
+// up from first, so the tile is not blank and can be found in VRAM.
+func tileHex(first byte) string {
+	var sb strings.Builder
+	for i := 0; i < 32; i++ {
+		fmt.Fprintf(&sb, "%02X ", first+byte(i))
+	}
+	return sb.String()
+}
+
+func TestServicePatchLiveAssetReplacesUploadedTiles(t *testing.T) {
+	dir := t.TempDir()
+	tilePath := filepath.Join(dir, "hero.hex")
+	if err := os.WriteFile(tilePath, []byte(tileHex(0x10)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	manifest := `{"assets": [{"name": "Hero", "type": "tiles8", "encoding": "hex", "path": "hero.hex"}]}`
+	if err := os.WriteFile(filepath.Join(dir, "corelx.assets.json"), []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	src := `
+function Start()
+    base := gfx.load_tiles(ASSET_Hero, 0)
+    while true
+        wait_vblank()
+`
+	srcPath := filepath.Join(dir, "main.corelx")
+
+	svc := NewService(t.TempDir())
+	defer svc.Shutdown()
+	build, err := svc.BuildSource(src, srcPath)
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if err := svc.LoadROMBytes(build.Result.ROMBytes); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.RunFrames(3); err != nil {
+		t.Fatal(err)
+	}
+	before, err := corelx.LiveAssets(src, srcPath, nil, tilePath)
+	if err != nil || len(before) != 1 {
+		t.Fatalf("LiveAssets = %v, %v; want the Hero asset", before, err)
+	}
+	vram, _ := svc.ReadMemory(emulator.MemoryVRAM, 0, 0x10000)
+	if findAligned(vram, before[0].Pieces[0].Data, 2) == nil {
+		t.Fatal("the ROM did not upload the tile")
+	}
+
+	if err := os.WriteFile(tilePath, []byte(tileHex(0x50)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	after, err := corelx.LiveAssets(src, srcPath, nil, tilePath)
+	if err != nil || len(after) != 1 {
+		t.Fatalf("LiveAssets after edit = %v, %v", after, err)
+	}
+	n, err := svc.PatchLiveAsset(before[0], after[0])
+	if err != nil || n != 1 {
+		t.Fatalf("PatchLiveAsset = %d, %v; want 1 piece", n, err)
+	}
+	vram, _ = svc.ReadMemory(emulator.MemoryVRAM, 0, 0x10000)
+	if findAligned(vram, before[0].Pieces[0].Data, 2) != nil {
+		t.Error("old tile bytes are still in VRAM")
+	}
+	if !bytes.Contains(vram, after[0].Pieces[0].Data) {
+		t.Error("new tile bytes are not in VRAM")
+	}
+	if svc.Snapshot().FrameCount < 3 {
+		t.Error("patching restarted the ROM")
+	}
+}
+
+func TestServicePatchLiveAssetRejectsResize(t *testing.T) {
+	svc := NewService(t.TempDir())
+	defer svc.Shutdown()
+	old := corelx.LiveAsset{Name: "A", Pieces: []corelx.LivePiece{{Space: corelx.LiveVRAM, Addr: -1, Data: []byte{1, 2}}}}
+	next := corelx.LiveAsset{Name: "A", Pieces: []corelx.LivePiece{{Space: corelx.LiveVRAM, Addr: -1, Data: []byte{1, 2, 3, 4}}}}
+	if _, err := svc.PatchLiveAsset(old, next); err == nil || !strings.Contains(err.Error(), "rebuild") {
+		t.Errorf("resized asset err = %v, want a rebuild hint", err)
+	}
+	blank := corelx.LiveAsset{Name: "A", Pieces: []corelx.LivePiece{{Space: corelx.LiveVRAM, Addr: -1, Data: []byte{0, 0}}}}
+	if _, err := svc.PatchLiveAsset(blank, old); err == nil {
+		t.Error("patching a blank asset succeeded; it cannot be found")
+	}
+}
+
+func TestFindAligned(t *testing.T) {
+	mem := []byte{9, 1, 2, 0, 1, 2, 1, 2}
+	got := findAligned(mem, []byte{1, 2}, 2)
+	if len(got) != 2 || got[0] != 4 || got[1] != 6 {
+		t.Errorf("findAligned = %v, want [4 6] (offset 1 is unaligned)", got)
+	}
+}
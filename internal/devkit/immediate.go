@@ -0,0 +1,60 @@
+package devkit
+
+import (
+	"fmt"
+
+	"nitro-core-dx/internal/corelx"
+)
+
+// immediateMaxInstructions bounds one immediate-console snippet, so an
+// endless loop fails instead of hanging the Dev Kit.
+const immediateMaxInstructions = 2_000_000
+
+// ImmediateResult reports one immediate-console evaluation.
+type ImmediateResult struct {
+	// HasValue and Value report a value expression's result as a 16-bit word
+	// (see corelx.ImmediateProgram).
+	HasValue     bool
+	Value        uint16
+	Instructions int
+	Cycles       uint32
+	// Diagnostics are compile diagnostics, with lines counted from the
+	// first line of the input.
+	Diagnostics []corelx.Diagnostic
+}
+
+// RunImmediate compiles CoreLX statements (or one expression) and runs them
+// on the paused emulator. See corelx.CompileImmediate for what a snippet
+// can reference and emulator.RunImmediate for what its writes leave behind.
+func (s *Service) RunImmediate(input string) (ImmediateResult, error) {
+	var out ImmediateResult
+	prog, err := corelx.CompileImmediate(input)
+	if prog != nil {
+		out.Diagnostics = prog.Diagnostics
+	}
+	if err != nil {
+		return out, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.emu == nil {
+		return out, fmt.Errorf("no ROM loaded")
+	}
+	if !s.emu.Paused {
+		return out, fmt.Errorf("pause the emulator first")
+	}
+	if err := s.movieBusyErrorLocked(); err != nil {
+		return out, err
+	}
+	s.breakStepHistoryLocked()
+	run, err := s.emu.RunImmediate(prog.ROMBytes, immediateMaxInstructions)
+	out.Instructions = run.Instructions
+	out.Cycles = run.Cycles
+	if err != nil {
+		return out, err
+	}
+	out.HasValue = prog.HasValue
+	out.Value = run.R0
+	return out, nil
+}
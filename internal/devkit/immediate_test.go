@@ -0,0 +1,147 @@
+package devkit
+
+import (
+	"strings"
+	"testing"
+)
+
+func loadPausedImmediateSession(t *testing.T) *Service {
+	t.Helper()
+	svc := NewService(t.TempDir())
+	t.Cleanup(svc.Shutdown)
+
+	src := `
+var score: int = 7
+
+function Start()
+    count := 0
+    while true
+        count = count + 1
+        score = score + 0
+        wait_vblank()
+`
+	build, err := svc.BuildSource(src, "immediate.corelx")
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if err := svc.LoadROMBytes(build.Result.ROMBytes); err != nil {
+		t.Fatalf("load rom bytes: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := svc.RunFrame(); err != nil {
+			t.Fatalf("run frame: %v", err)
+		}
+	}
+	if paused, err := svc.TogglePause(); err != nil || !paused {
+		t.Fatalf("pause: paused=%v err=%v", paused, err)
+	}
+	return svc
+}
+
+func TestRunImmediateWritesHardwareAndPreservesProgram(t *testing.T) {
+	svc := loadPausedImmediateSession(t)
+	emu := svc.emu
+	cpuBefore := emu.CPU.State
+	vectorsBefore := emu.Bus.SystemVectors
+	var stackBefore [0x2000]uint8
+	copy(stackBefore[:], emu.Bus.WRAM[:0x2000])
+
+	res, err := svc.RunImmediate("oam.write_sprite_data(0, 50, 50, 1, 0x10, 1)")
+	if err != nil {
+		t.Fatalf("run immediate: %v (%+v)", err, res.Diagnostics)
+	}
+	if res.HasValue || res.Instructions == 0 {
+		t.Fatalf("expected a statement run, got %+v", res)
+	}
+	if got := emu.PPU.OAM[0:6]; got[0] != 50 || got[2] != 50 || got[3] != 1 || got[4] != 0x10 || got[5] != 1 {
+		t.Fatalf("OAM slot 0 = % X", got)
+	}
+
+	if emu.CPU.State != cpuBefore {
+		t.Fatalf("CPU state changed:\n%+v\n%+v", emu.CPU.State, cpuBefore)
+	}
+	if emu.Bus.SystemVectors != vectorsBefore {
+		t.Fatalf("system vectors changed")
+	}
+	if string(emu.Bus.WRAM[:0x2000]) != string(stackBefore[:]) {
+		t.Fatalf("stack/locals region changed")
+	}
+	if !emu.Paused {
+		t.Fatalf("expected the session to stay paused")
+	}
+
+	// The game keeps running from where it was paused.
+	if _, err := svc.TogglePause(); err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := svc.RunFrame(); err != nil {
+			t.Fatalf("run frame after immediate: %v", err)
+		}
+	}
+	if emu.Bus.Read16(0, 0x2100) != 7 {
+		t.Fatalf("game global score = %d, want 7", emu.Bus.Read16(0, 0x2100))
+	}
+}
+
+func TestRunImmediateValuesAndLocals(t *testing.T) {
+	svc := loadPausedImmediateSession(t)
+
+	cases := map[string]uint16{
+		"mem.read16(0x2100)": 7,
+		"2 * 3 + 1":          7,
+		"0x8000 >> 4":        0x0800,
+		"SPR_PAL(3)":         3,
+	}
+	for input, want := range cases {
+		res, err := svc.RunImmediate(input)
+		if err != nil {
+			t.Fatalf("%s: %v", input, err)
+		}
+		if !res.HasValue || res.Value != want {
+			t.Fatalf("%s = %+v, want value %d", input, res, want)
+		}
+	}
+
+	res, err := svc.RunImmediate("    x := 5\n    mem.write(0x7000, x + 1)\n")
+	if err != nil {
+		t.Fatalf("multi-line: %v (%+v)", err, res.Diagnostics)
+	}
+	if res.HasValue {
+		t.Fatalf("statements should not report a value")
+	}
+	if got := svc.emu.Bus.WRAM[0x7000]; got != 6 {
+		t.Fatalf("WRAM[0x7000] = %d, want 6", got)
+	}
+}
+
+func TestRunImmediateErrors(t *testing.T) {
+	svc := loadPausedImmediateSession(t)
+
+	res, err := svc.RunImmediate("oam.flush()\nx := (1 +")
+	if err == nil || len(res.Diagnostics) == 0 {
+		t.Fatalf("expected a compile error, got %v", err)
+	}
+	if res.Diagnostics[0].Line != 2 {
+		t.Fatalf("diagnostic line = %d, want 2 (%v)", res.Diagnostics[0].Line, res.Diagnostics[0])
+	}
+
+	if _, err := svc.RunImmediate("   \n"); err == nil {
+		t.Fatalf("expected empty input to be rejected")
+	}
+
+	pcBefore := svc.emu.CPU.State
+	if _, err := svc.RunImmediate("while true\n    mem.write(0x7000, 1)"); err == nil || !strings.Contains(err.Error(), "did not return") {
+		t.Fatalf("expected an endless loop to hit the instruction limit, got %v", err)
+	}
+	if svc.emu.CPU.State != pcBefore {
+		t.Fatalf("CPU state not restored after a failed run")
+	}
+
+	if _, err := svc.TogglePause(); err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	if _, err := svc.RunImmediate("mem.write(0x7000, 1)"); err == nil || !strings.Contains(err.Error(), "pause") {
+		t.Fatalf("expected running session to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package devkit
+
+import (
+	"fmt"
+
+	"nitro-core-dx/internal/debug"
+)
+
+// LogSetting is one component's logging on the live emulator logger.
+type LogSetting struct {
+	Component debug.Component
+	Enabled   bool
+	// Level is the most verbose level kept (Debug keeps Error, Warning,
+	// Info and Debug).
+	Level debug.LogLevel
+}
+
+// LogSettings returns every component's logging, in debug.Components
+// order, or nil when no ROM is loaded.
+func (s *Service) LogSettings() []LogSetting {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.emu == nil || s.emu.Logger == nil {
+		return nil
+	}
+	settings := make([]LogSetting, len(debug.Components))
+	for i, c := range debug.Components {
+		settings[i] = LogSetting{
+			Component: c,
+			Enabled:   s.emu.Logger.IsComponentEnabled(c),
+			Level:     s.emu.Logger.ComponentLevel(c),
+		}
+	}
+	return settings
+}
+
+// SetLogSetting changes one component's logging while the ROM runs. The
+// level also survives ROM reloads; whether the component is enabled is
+// reset by the next run configuration (see ApplyRunConfig).
+func (s *Service) SetLogSetting(setting LogSetting) error {
+	if _, err := debug.ParseComponent(string(setting.Component)); err != nil {
+		return err
+	}
+	if setting.Level < debug.LogLevelNone || setting.Level > debug.LogLevelTrace {
+		return fmt.Errorf("log level %d out of range", setting.Level)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.emu == nil || s.emu.Logger == nil {
+		return fmt.Errorf("no ROM loaded")
+	}
+	if s.logLevels == nil {
+		s.logLevels = make(map[debug.Component]debug.LogLevel)
+	}
+	s.logLevels[setting.Component] = setting.Level
+	s.emu.Logger.SetComponentLevel(setting.Component, setting.Level)
+	s.emu.Logger.SetComponentEnabled(setting.Component, setting.Enabled)
+	return nil
+}
@@ -0,0 +1,153 @@
+package devkit
+
+import (
+	"fmt"
+
+	"nitro-core-dx/internal/harness"
+)
+
+// MovieStatus describes the movie being recorded or played, if any.
+type MovieStatus struct {
+	Recording bool
+	Playing   bool
+	Frame     int // frames recorded or played so far
+	Frames    int // length of the movie being played
+	// Desync is the first checkpoint playback did not reproduce; playback
+	// carries on after it.
+	Desync string
+}
+
+// StartMovieRecording records a movie (see harness.Movie) of the running
+// ROM from the next frame: from a power cycle with powerOn, otherwise from
+// the current state. Stepping, immediate snippets and Dev Kit edits are
+// refused while a movie is active, since a movie only holds per-frame
+// input.
+func (s *Service) StartMovieRecording(powerOn bool, author, comment string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.emu == nil {
+		return fmt.Errorf("no ROM loaded")
+	}
+	if s.movieBusyLocked() {
+		return fmt.Errorf("a movie is already active")
+	}
+	paused := s.emu.Paused
+	rec, err := harness.StartMovie(s.emu, powerOn, author, comment)
+	if err != nil {
+		return err
+	}
+	s.emu.Paused = paused
+	s.clearStepHistoryLocked()
+	s.movieRec = rec
+	s.emu.Notify("Movie recording started")
+	return nil
+}
+
+// StopMovieRecording ends the recording and returns the movie.
+func (s *Service) StopMovieRecording() (*harness.Movie, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.movieRec == nil {
+		return nil, fmt.Errorf("no movie is recording")
+	}
+	m := s.movieRec.Finish()
+	s.movieRec = nil
+	s.emu.Notify(fmt.Sprintf("Movie recorded: %d frames", len(m.Input)))
+	return m, nil
+}
+
+// PlayMovie plays m on the loaded ROM, which must be the one it was
+// recorded with. Held buttons are ignored until it ends.
+func (s *Service) PlayMovie(m *harness.Movie) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.emu == nil {
+		return fmt.Errorf("no ROM loaded")
+	}
+	if s.movieBusyLocked() {
+		return fmt.Errorf("a movie is already active")
+	}
+	p, err := harness.PlayMovie(s.emu, m)
+	if err != nil {
+		return err
+	}
+	s.tickAccumulator = 0
+	s.clearStepHistoryLocked()
+	s.moviePlay = p
+	s.movieLen = len(m.Input)
+	s.movieDesync = nil
+	s.emu.Notify("Movie playing")
+	return nil
+}
+
+// StopMovie ends playback, or abandons a recording.
+func (s *Service) StopMovie() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clearMovieLocked()
+}
+
+// MovieStatus reports the active movie.
+func (s *Service) MovieStatus() MovieStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var st MovieStatus
+	switch {
+	case s.movieRec != nil:
+		st.Recording = true
+		st.Frame = s.movieRec.Frames()
+	case s.moviePlay != nil:
+		st.Playing = true
+		st.Frame = s.moviePlay.Frame()
+		st.Frames = s.movieLen
+	}
+	if s.movieDesync != nil {
+		st.Desync = s.movieDesync.Error()
+	}
+	return st
+}
+
+func (s *Service) movieBusyLocked() bool {
+	return s.movieRec != nil || s.moviePlay != nil
+}
+
+// movieBusyErrorLocked refuses a change a movie could not reproduce.
+func (s *Service) movieBusyErrorLocked() error {
+	if s.movieBusyLocked() {
+		return fmt.Errorf("stop the movie first")
+	}
+	return nil
+}
+
+func (s *Service) clearMovieLocked() {
+	s.movieRec = nil
+	s.moviePlay = nil
+}
+
+// movieInputLocked returns the buttons for the frame about to run: the
+// movie's while one plays (ending playback after its last frame), the
+// host's otherwise, recorded while a movie records.
+func (s *Service) movieInputLocked() uint16 {
+	buttons := s.host.Frame(s.held)
+	if s.moviePlay != nil {
+		if input, ok := s.moviePlay.Next(); ok {
+			buttons = input
+		} else {
+			s.moviePlay = nil
+		}
+	}
+	if s.movieRec != nil {
+		s.movieRec.Frame(buttons)
+	}
+	return buttons
+}
+
+// checkMovieLocked compares the frame just run with the playing movie.
+func (s *Service) checkMovieLocked() {
+	if s.moviePlay == nil || s.movieDesync != nil {
+		return
+	}
+	if d := s.moviePlay.Check(s.emu); d != nil {
+		s.movieDesync = d
+	}
+}
@@ -0,0 +1,75 @@
+package devkit
+
+import (
+	"testing"
+
+	"nitro-core-dx/internal/harness"
+)
+
+func TestServiceMovieRecordAndPlay(t *testing.T) {
+	svc := NewService(t.TempDir())
+	defer svc.Shutdown()
+
+	src := `
+function Start()
+    n := 0
+    while true
+        n = n + 1
+        wait_vblank()
+`
+	build, err := svc.BuildSource(src, "movie.corelx")
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	rom := build.Result.ROMBytes
+	if err := svc.LoadROMBytes(rom); err != nil {
+		t.Fatalf("load rom: %v", err)
+	}
+	if err := svc.StepFrame(3); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := svc.StartMovieRecording(true, "dev", ""); err != nil {
+		t.Fatalf("start recording: %v", err)
+	}
+	for i, buttons := range []uint16{0, 0x10, 0x10, 0, 0x01} {
+		svc.SetInputButtons(buttons)
+		if err := svc.StepFrame(1); err != nil {
+			t.Fatalf("frame %d: %v", i, err)
+		}
+	}
+	if err := svc.StepCPU(1); err == nil {
+		t.Error("StepCPU allowed while recording")
+	}
+	if st := svc.MovieStatus(); !st.Recording || st.Frame != 5 {
+		t.Fatalf("status while recording = %+v", st)
+	}
+	m, err := svc.StopMovieRecording()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Input) != 5 || m.Input[1] != 0x10 || !m.Header.PowerOn {
+		t.Fatalf("movie input %v, power-on %v", m.Input, m.Header.PowerOn)
+	}
+	if d, err := harness.VerifyMovie(rom, m); err != nil || d != nil {
+		t.Fatalf("headless verify: %v, %v", d, err)
+	}
+
+	// Playback ignores held buttons and ends with the movie.
+	svc.SetInputButtons(0x80)
+	if err := svc.PlayMovie(m); err != nil {
+		t.Fatalf("play: %v", err)
+	}
+	if err := svc.StepFrame(5); err != nil {
+		t.Fatal(err)
+	}
+	if st := svc.MovieStatus(); !st.Playing || st.Frame != 5 || st.Desync != "" {
+		t.Fatalf("status after playing = %+v", st)
+	}
+	if err := svc.StepFrame(1); err != nil {
+		t.Fatal(err)
+	}
+	if st := svc.MovieStatus(); st.Playing {
+		t.Fatalf("still playing after the last frame: %+v", st)
+	}
+}
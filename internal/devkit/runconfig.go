@@ -0,0 +1,270 @@
+package devkit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"nitro-core-dx/internal/corelx"
+	"nitro-core-dx/internal/debug"
+)
+
+// RunConfigFileName is the project file holding a project's run
+// configurations. It lives next to the main source file, like
+// corelx.assets.json.
+const RunConfigFileName = "corelx.run.json"
+
+// Build profiles for RunConfig.Profile.
+const (
+	// ProfileDebug skips the default boot splash so Start() runs on the
+	// first frame.
+	ProfileDebug = "debug"
+	// ProfileRelease builds exactly what the corelx CLI ships, splash
+	// included.
+	ProfileRelease = "release"
+)
+
+// RunConfig is one named Build + Run setup.
+type RunConfig struct {
+	Name    string `json:"name"`
+	Profile string `json:"profile"` // ProfileDebug or ProfileRelease
+	// Speed is the pacing multiplier (see Service.SetSpeed); 0 keeps the
+	// current speed.
+	Speed       float64 `json:"speed,omitempty"`
+	StartPaused bool    `json:"start_paused,omitempty"`
+	// LogComponents turns on emulator logging for these components (CPU,
+	// PPU, APU, Memory, Input, UI, System); the rest stay off.
+	LogComponents []string `json:"log_components,omitempty"`
+	// OnLoad is immediate-console CoreLX (see Service.RunImmediate) run
+	// once OnLoadFrame frames after the ROM loads, e.g. to poke a level
+	// select into work RAM.
+	OnLoad      string `json:"on_load,omitempty"`
+	OnLoadFrame int    `json:"on_load_frame,omitempty"`
+	// Defines are the flags set for the source's `#if NAME` sections, e.g.
+	// DEBUG for debug prints and cheats left out of release builds.
+	Defines []string `json:"defines,omitempty"`
+	// Target is the corelx target profile the build is tuned for ("dev",
+	// the default, or "hardware"; see corelx.Target).
+	Target string `json:"target,omitempty"`
+	// ValidateHardware runs each build briefly under strict hardware rules
+	// and reports what it relies on the emulator's leniency for as
+	// warnings (see corelx.CompileOptions.ValidateHardware).
+	ValidateHardware bool `json:"validate_hardware,omitempty"`
+}
+
+// logComponents maps RunConfig.LogComponents names to logger components.
+var logComponents = map[string]debug.Component{
+	"cpu": debug.ComponentCPU, "ppu": debug.ComponentPPU, "apu": debug.ComponentAPU,
+	"memory": debug.ComponentMemory, "input": debug.ComponentInput,
+	"ui": debug.ComponentUI, "system": debug.ComponentSystem,
+}
+
+// LogComponentNames lists the names RunConfig.LogComponents accepts, in
+// display order.
+var LogComponentNames = []string{"CPU", "PPU", "APU", "Memory", "Input", "UI", "System"}
+
+// Validate reports the first problem with c.
+func (c RunConfig) Validate() error {
+	if strings.TrimSpace(c.Name) == "" {
+		return fmt.Errorf("run configuration needs a name")
+	}
+	if c.Profile != ProfileDebug && c.Profile != ProfileRelease {
+		return fmt.Errorf("run configuration %q: profile must be %q or %q", c.Name, ProfileDebug, ProfileRelease)
+	}
+	if c.Speed != 0 && (c.Speed < MinSpeed || c.Speed > MaxSpeed) {
+		return fmt.Errorf("run configuration %q: speed must be 0 or %.2g-%.2g", c.Name, MinSpeed, MaxSpeed)
+	}
+	for _, name := range c.LogComponents {
+		if _, ok := logComponents[strings.ToLower(name)]; !ok {
+			return fmt.Errorf("run configuration %q: unknown log component %q", c.Name, name)
+		}
+	}
+	if c.OnLoadFrame < 0 {
+		return fmt.Errorf("run configuration %q: on_load_frame must be >= 0", c.Name)
+	}
+	for _, name := range c.Defines {
+		if !corelx.ValidDefineName(name) {
+			return fmt.Errorf("run configuration %q: %q is not a flag name", c.Name, name)
+		}
+	}
+	if _, err := corelx.ParseTarget(c.Target); err != nil {
+		return fmt.Errorf("run configuration %q: %w", c.Name, err)
+	}
+	return nil
+}
+
+// RunConfigs is the contents of a project's RunConfigFileName.
+type RunConfigs struct {
+	FormatVersion int         `json:"format_version,omitempty"`
+	Active        string      `json:"active,omitempty"` // name of the selected config
+	Configs       []RunConfig `json:"configs"`
+}
+
+// DefaultRunConfigs is what a project without a run configuration file
+// gets: "Release" (the previous Build + Run behavior) and "Debug", which
+// also sets the DEBUG flag.
+func DefaultRunConfigs() RunConfigs {
+	return RunConfigs{
+		FormatVersion: 1,
+		Active:        "Release",
+		Configs: []RunConfig{
+			{Name: "Release", Profile: ProfileRelease},
+			{Name: "Debug", Profile: ProfileDebug, Defines: []string{"DEBUG"}},
+		},
+	}
+}
+
+// Lookup returns the config called name.
+func (r RunConfigs) Lookup(name string) (RunConfig, bool) {
+	for _, c := range r.Configs {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return RunConfig{}, false
+}
+
+// Selected returns the active config, or the first one if Active names
+// none.
+func (r RunConfigs) Selected() RunConfig {
+	if c, ok := r.Lookup(r.Active); ok {
+		return c
+	}
+	if len(r.Configs) > 0 {
+		return r.Configs[0]
+	}
+	return DefaultRunConfigs().Configs[0]
+}
+
+// Validate checks every config and that names are unique.
+func (r RunConfigs) Validate() error {
+	if len(r.Configs) == 0 {
+		return fmt.Errorf("no run configurations")
+	}
+	seen := map[string]bool{}
+	for _, c := range r.Configs {
+		if err := c.Validate(); err != nil {
+			return err
+		}
+		if seen[c.Name] {
+			return fmt.Errorf("duplicate run configuration %q", c.Name)
+		}
+		seen[c.Name] = true
+	}
+	return nil
+}
+
+// LoadRunConfigs reads dir's RunConfigFileName. A missing file yields
+// DefaultRunConfigs.
+func LoadRunConfigs(dir string) (RunConfigs, error) {
+	data, err := os.ReadFile(filepath.Join(dir, RunConfigFileName))
+	if os.IsNotExist(err) {
+		return DefaultRunConfigs(), nil
+	}
+	if err != nil {
+		return RunConfigs{}, err
+	}
+	var r RunConfigs
+	if err := json.Unmarshal(data, &r); err != nil {
+		return RunConfigs{}, fmt.Errorf("%s: %w", RunConfigFileName, err)
+	}
+	if err := r.Validate(); err != nil {
+		return RunConfigs{}, fmt.Errorf("%s: %w", RunConfigFileName, err)
+	}
+	return r, nil
+}
+
+// SaveRunConfigs validates r and writes it to dir's RunConfigFileName.
+func SaveRunConfigs(dir string, r RunConfigs) error {
+	if err := r.Validate(); err != nil {
+		return err
+	}
+	if r.FormatVersion == 0 {
+		r.FormatVersion = 1
+	}
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, RunConfigFileName), append(data, '\n'), 0644)
+}
+
+// BuildSourceWith is BuildSource using cfg's build profile, flags, target
+// and hardware check.
+func (s *Service) BuildSourceWith(source, sourcePath string, cfg RunConfig) (*BuildResult, error) {
+	return s.buildSource(source, sourcePath, cfg)
+}
+
+// ApplyRunConfig applies cfg's run settings to the freshly loaded ROM:
+// speed, log components, the OnLoad hook and the start-paused flag. The
+// hook's result is returned when it ran.
+func (s *Service) ApplyRunConfig(cfg RunConfig) (*ImmediateResult, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg.Speed != 0 {
+		s.SetSpeed(cfg.Speed)
+	}
+
+	s.mu.Lock()
+	if s.emu == nil {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("no ROM loaded")
+	}
+	if logger := s.emu.Logger; logger != nil {
+		for _, c := range logComponents {
+			logger.SetComponentEnabled(c, false)
+		}
+		for _, name := range cfg.LogComponents {
+			logger.SetComponentEnabled(logComponents[strings.ToLower(name)], true)
+		}
+	}
+	s.emu.Pause()
+	s.mu.Unlock()
+
+	var hook *ImmediateResult
+	if strings.TrimSpace(cfg.OnLoad) != "" {
+		if cfg.OnLoadFrame > 0 {
+			if err := s.StepFrame(cfg.OnLoadFrame); err != nil {
+				return nil, fmt.Errorf("on-load hook: %w", err)
+			}
+		}
+		res, err := s.RunImmediate(cfg.OnLoad)
+		if err != nil {
+			return &res, fmt.Errorf("on-load hook: %w", err)
+		}
+		hook = &res
+	}
+
+	if !cfg.StartPaused {
+		s.mu.Lock()
+		if s.emu != nil {
+			s.emu.Resume()
+		}
+		s.mu.Unlock()
+	}
+	return hook, nil
+}
+
+// DrainLogs returns up to max of the emulator's newest log entries,
+// formatted, and clears its log. dropped counts older entries not
+// returned.
+func (s *Service) DrainLogs(max int) (lines []string, dropped int) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.emu == nil || s.emu.Logger == nil {
+		return nil, 0
+	}
+	entries := s.emu.Logger.GetEntries()
+	s.emu.Logger.Clear()
+	if len(entries) > max {
+		dropped = len(entries) - max
+		entries = entries[dropped:]
+	}
+	for i := range entries {
+		lines = append(lines, entries[i].Format())
+	}
+	return lines, dropped
+}
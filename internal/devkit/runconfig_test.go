@@ -0,0 +1,111 @@
+package devkit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunConfigsLoadSaveRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	got, err := LoadRunConfigs(dir)
+	if err != nil {
+		t.Fatalf("load defaults: %v", err)
+	}
+	if got.Selected().Name != "Release" || got.Selected().Profile != ProfileRelease {
+		t.Fatalf("default selection = %+v", got.Selected())
+	}
+
+	want := RunConfigs{
+		Active: "Trace",
+		Configs: []RunConfig{
+			{Name: "Release", Profile: ProfileRelease},
+			{Name: "Trace", Profile: ProfileDebug, Speed: 0.5, StartPaused: true, LogComponents: []string{"PPU", "memory"}, OnLoad: "mem.write(0x0200, 3)", OnLoadFrame: 2, Defines: []string{"DEBUG", "CHEATS"}, Target: "hardware", ValidateHardware: true},
+		},
+	}
+	if err := SaveRunConfigs(dir, want); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	got, err = LoadRunConfigs(dir)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	trace := got.Selected()
+	if trace.Name != "Trace" || trace.Speed != 0.5 || !trace.StartPaused || len(trace.LogComponents) != 2 || trace.OnLoadFrame != 2 || len(trace.Defines) != 2 || trace.Target != "hardware" || !trace.ValidateHardware {
+		t.Fatalf("round trip selected = %+v", trace)
+	}
+
+	for _, bad := range []RunConfigs{
+		{Configs: nil},
+		{Configs: []RunConfig{{Name: "A", Profile: "fast"}}},
+		{Configs: []RunConfig{{Name: "A", Profile: ProfileDebug, LogComponents: []string{"GPU"}}}},
+		{Configs: []RunConfig{{Name: "A", Profile: ProfileDebug}, {Name: "A", Profile: ProfileRelease}}},
+		{Configs: []RunConfig{{Name: "A", Profile: ProfileDebug, Speed: 9}}},
+		{Configs: []RunConfig{{Name: "A", Profile: ProfileDebug, Defines: []string{"NO FLAG"}}}},
+		{Configs: []RunConfig{{Name: "A", Profile: ProfileDebug, Target: "arcade"}}},
+	} {
+		if err := SaveRunConfigs(dir, bad); err == nil {
+			t.Errorf("save %+v should fail", bad)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, RunConfigFileName), []byte(`{"configs":[{"name":"x","profile":"nope"}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadRunConfigs(dir); err == nil || !strings.Contains(err.Error(), RunConfigFileName) {
+		t.Fatalf("invalid file should fail naming %s, got %v", RunConfigFileName, err)
+	}
+}
+
+func TestServiceApplyRunConfig(t *testing.T) {
+	svc := NewService(t.TempDir())
+	defer svc.Shutdown()
+
+	src := `
+function Start()
+    while true
+        wait_vblank()
+`
+	cfg := RunConfig{
+		Name:          "Debug",
+		Profile:       ProfileDebug,
+		Speed:         2,
+		StartPaused:   true,
+		LogComponents: []string{"Memory"},
+		OnLoad:        "mem.write(0x2100, 0x5A)",
+		OnLoadFrame:   2,
+	}
+	build, err := svc.BuildSourceWith(src, "runconfig.corelx", cfg)
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if err := svc.LoadROMBytes(build.Result.ROMBytes); err != nil {
+		t.Fatalf("load rom: %v", err)
+	}
+	hook, err := svc.ApplyRunConfig(cfg)
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if hook == nil || hook.Instructions == 0 {
+		t.Fatalf("on-load hook did not run: %+v", hook)
+	}
+	snap := svc.Snapshot()
+	if !snap.Paused || snap.FrameCount != 2 {
+		t.Fatalf("want paused at frame 2, got paused=%v frame=%d", snap.Paused, snap.FrameCount)
+	}
+	if got := svc.emu.Bus.WRAM[0x2100]; got != 0x5A {
+		t.Fatalf("hook write: WRAM[0x2100] = 0x%02X, want 0x5A", got)
+	}
+	if svc.Speed() != 2 {
+		t.Fatalf("speed = %v, want 2", svc.Speed())
+	}
+	if !svc.emu.Logger.IsComponentEnabled("Memory") || svc.emu.Logger.IsComponentEnabled("CPU") {
+		t.Fatalf("log components not applied")
+	}
+
+	cfg.StartPaused = false
+	cfg.OnLoad = "mem.write(0x2100, 0x01 +"
+	if _, err := svc.ApplyRunConfig(cfg); err == nil || !strings.Contains(err.Error(), "on-load hook") {
+		t.Fatalf("bad hook should fail, got %v", err)
+	}
+}
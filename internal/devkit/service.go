@@ -1,14 +1,21 @@
 package devkit
 
 import (
+	"context"
 	"fmt"
+	"image"
 	"path/filepath"
 	"strings"
 	"sync"
 	"time"
 
+	"nitro-core-dx/internal/apu"
 	"nitro-core-dx/internal/corelx"
+	"nitro-core-dx/internal/debug"
 	"nitro-core-dx/internal/emulator"
+	"nitro-core-dx/internal/harness"
+	"nitro-core-dx/internal/hwdef"
+	"nitro-core-dx/internal/input"
 )
 
 type BuildArtifacts struct {
@@ -16,6 +23,8 @@ type BuildArtifacts struct {
 	ManifestPath    string `json:"manifest_path"`
 	DiagnosticsPath string `json:"diagnostics_path"`
 	BundlePath      string `json:"bundle_path"`
+	// ListingPath is the interleaved source/instruction listing.
+	ListingPath string `json:"listing_path"`
 }
 
 type BuildResult struct {
@@ -30,9 +39,14 @@ type EmulatorSnapshot struct {
 	Loaded            bool
 	Running           bool
 	Paused            bool
+	Deterministic     bool
 	FPS               float64
 	CPUCyclesPerFrame uint32
 	FrameCount        uint64
+	Timing            emulator.FrameStats
+	// InputButtons is the controller 1 button mask the emulator currently
+	// sees (bit 0 = UP ... bit 11 = Z, see internal/input).
+	InputButtons uint16
 }
 
 type TickResult struct {
@@ -41,6 +55,12 @@ type TickResult struct {
 	PresentFrame  bool             `json:"present_frame"`
 	Framebuffer   []uint32         `json:"-"`
 	AudioFrames   [][]int16        `json:"-"`
+	// TestOutput is the text the ROM wrote to the test port since the
+	// previous tick, including failed assertions.
+	TestOutput string `json:"test_output,omitempty"`
+	// DebugLines are the lines the ROM finished on the debug console
+	// (debug.print) since the previous tick.
+	DebugLines []string `json:"debug_lines,omitempty"`
 }
 
 type CPURegistersSnapshot struct {
@@ -66,13 +86,54 @@ type PCStateSnapshot struct {
 	Cycles   uint32
 }
 
+// AudioChannelSnapshot is the metering view of one APU mixer channel.
+// Peak and RMS are normalized to 0..1 and cover the window since the previous
+// AudioMixerSnapshot call. Scope holds the most recent samples (oldest first),
+// tapped before mute/solo so a muted channel remains visible.
+type AudioChannelSnapshot struct {
+	Channel apu.MixerChannel
+	Name    string
+	Muted   bool
+	Solo    bool
+	Audible bool
+	Peak    float64
+	RMS     float64
+	Scope   []int16
+}
+
+type AudioMixerSnapshot struct {
+	Loaded   bool
+	Channels []AudioChannelSnapshot
+	Health   AudioHealth
+}
+
+// PaletteSnapshot is a copy of CGRAM as RGB555 words, indexed palette*16+color.
+type PaletteSnapshot struct {
+	Loaded bool
+	Colors [256]uint16
+}
+
+// IORegisterSnapshot is one named I/O register and its current value (see
+// emulator.PeekIORegister). Known is false for a write-only register nothing
+// has written since power-on.
+type IORegisterSnapshot struct {
+	emulator.IORegister
+	Value uint8
+	Known bool
+}
+
 // Backend defines the UI-agnostic Dev Kit contract intended for frontend wrappers.
 // Frontends may be rewritten freely as long as they target this contract (or a compatible superset)
 // and preserve emulator input/output semantics.
 type Backend interface {
 	TempDir() string
 	BuildSource(source, sourcePath string) (*BuildResult, error)
+	BuildSourceWith(source, sourcePath string, cfg RunConfig) (*BuildResult, error)
 	LoadROMBytes(romBytes []byte) error
+	ApplyRunConfig(cfg RunConfig) (*ImmediateResult, error)
+	DrainLogs(max int) (lines []string, dropped int)
+	LogSettings() []LogSetting
+	SetLogSetting(setting LogSetting) error
 	InstallRasterProgram(program emulator.RasterProgram) error
 	InstallMatrixPlaneProgram(program emulator.MatrixPlaneProgram) error
 	ClearRasterProgram() error
@@ -80,16 +141,55 @@ type Backend interface {
 	Shutdown()
 	Snapshot() EmulatorSnapshot
 	ResetEmulator() error
+	SoftResetEmulator() error
 	TogglePause() (bool, error)
+	SaveState() ([]byte, error)
+	LoadState(data []byte) error
 	SetInputButtons(buttons uint16)
+	SetTurbo(mask uint16, hz int)
+	ToggleMacroRecording() (recording bool, frames int)
+	PlayMacro() error
 	RunFrame() error
 	StepFrame(frames int) error
 	StepCPU(steps int) error
+	StepBack(steps int) (int, error)
+	StepBackAvailable() int
+	StartMovieRecording(powerOn bool, author, comment string) error
+	StopMovieRecording() (*harness.Movie, error)
+	PlayMovie(m *harness.Movie) error
+	StopMovie()
+	MovieStatus() MovieStatus
+	RunImmediate(input string) (ImmediateResult, error)
 	Tick(delta time.Duration) (TickResult, error)
+	RunFrames(n int) (TickResult, error)
+	SetDeterministic(enabled bool)
+	Deterministic() bool
+	SetPriorityView(enabled bool)
+	PriorityView() bool
+	SetInputDisplay(enabled bool)
+	InputDisplay() bool
+	Notify(text string)
+	SetSpeed(multiplier float64)
+	Speed() float64
+	ReportAudioQueue(samples int)
 	FramebufferCopy() []uint32
 	AudioSamplesFixedCopy() []int16
 	GetRegisters() CPURegistersSnapshot
 	GetPCState() PCStateSnapshot
+	CallStack() []debug.CallFrame
+	SetAudioChannelMuted(ch apu.MixerChannel, muted bool)
+	SetAudioChannelSolo(ch apu.MixerChannel, solo bool)
+	AudioMixerSnapshot(scopeSamples int) AudioMixerSnapshot
+	PaletteSnapshot() PaletteSnapshot
+	SetPaletteColor(palette, index int, rgb555 uint16) error
+	IORegisters() []IORegisterSnapshot
+	WriteIORegister(address uint16, value uint8) error
+	ReadMemory(space emulator.MemorySpace, addr uint32, n int) ([]uint8, error)
+	LastWrites(space emulator.MemorySpace, addr uint32) ([]emulator.WriteRecord, error)
+	Disassemble(bank uint8, offset uint16, before, after int) ([]emulator.CodeLine, error)
+	BuildDiff(context int) (*BuildDiff, error)
+	ExportTileSheet(opts emulator.TileSheetOptions) (*image.NRGBA, error)
+	ExportPaletteStrip(swatch int) (*image.NRGBA, error)
 }
 
 // Service is the UI-agnostic Dev Kit backend wrapper.
@@ -103,6 +203,52 @@ type Service struct {
 	mu              sync.RWMutex
 	emu             *emulator.Emulator
 	tickAccumulator time.Duration
+	deterministic   bool
+	speed           float64
+
+	// Mixer mute/solo survive ROM reloads, so they live here and are
+	// re-applied to each new emulator session.
+	audioMuted [apu.MixerChannelCount]bool
+	audioSolo  [apu.MixerChannelCount]bool
+	// logLevels are the per-component log levels set with SetLogSetting;
+	// they survive ROM reloads too.
+	logLevels map[debug.Component]debug.LogLevel
+	// priorityView is the PPU priority debug render mode (see
+	// SetPriorityView); it survives ROM reloads too.
+	priorityView bool
+	// inputDisplay is the on-screen controller overlay (see
+	// SetInputDisplay); it survives ROM reloads too.
+	inputDisplay bool
+
+	// held is what the frontend reports; host turns it into what the
+	// controller sees each frame (turbo, macro). Both survive ROM reloads.
+	held uint16
+	host *input.HostInput
+
+	// symbols symbolizes faults in the loaded ROM. builtROM and
+	// builtSymbols are the last build's; a ROM other than that build's
+	// loads without symbols. prevBuiltROM and prevBuiltSymbols are the
+	// build before that (see BuildDiff).
+	symbols          emulator.SymbolTable
+	builtROM         []byte
+	builtSymbols     emulator.SymbolTable
+	prevBuiltROM     []byte
+	prevBuiltSymbols emulator.SymbolTable
+
+	// Step Back history (see stepback.go).
+	stepHistory []stepCheckpoint
+	stepPos     uint64
+	stepDirty   bool
+
+	// The movie being recorded or played (see movie.go).
+	movieRec    *harness.MovieRecorder
+	moviePlay   *harness.MoviePlayer
+	movieLen    int
+	movieDesync *harness.MovieMismatch
+
+	// Click detection over the audio output (see audiohealth.go).
+	audioClicks *harness.ClickDetector
+	audioHealth AudioHealth
 }
 
 var _ Backend = (*Service)(nil)
@@ -111,6 +257,8 @@ func NewService(tempDir string) *Service {
 	return &Service{
 		tempDir:  tempDir,
 		compiler: corelx.NewService(),
+		speed:    1,
+		host:     input.NewHostInput(),
 	}
 }
 
@@ -118,7 +266,16 @@ func (s *Service) TempDir() string {
 	return s.tempDir
 }
 
+// BuildSource compiles source with the release profile (see
+// BuildSourceWith).
 func (s *Service) BuildSource(source, sourcePath string) (*BuildResult, error) {
+	return s.buildSource(source, sourcePath, RunConfig{Profile: ProfileRelease})
+}
+
+// buildSource compiles through corelx.Compile's Options, with the optimizer
+// on and cfg's boot splash (skipped for ProfileDebug), flags, target and
+// hardware check.
+func (s *Service) buildSource(source, sourcePath string, cfg RunConfig) (*BuildResult, error) {
 	if sourcePath == "" {
 		sourcePath = "untitled.corelx"
 	}
@@ -132,20 +289,33 @@ func (s *Service) BuildSource(source, sourcePath string) (*BuildResult, error) {
 		ManifestPath:    filepath.Join(s.tempDir, artifactBase+".manifest.json"),
 		DiagnosticsPath: filepath.Join(s.tempDir, artifactBase+".diagnostics.json"),
 		BundlePath:      filepath.Join(s.tempDir, artifactBase+".bundle.json"),
+		ListingPath:     filepath.Join(s.tempDir, artifactBase+".lst"),
 	}
 
 	start := time.Now()
-	opts := &corelx.CompileOptions{
+	advanced := &corelx.CompileOptions{
 		OutputPath:            artifacts.ROMPath,
 		ManifestOutputPath:    artifacts.ManifestPath,
 		DiagnosticsOutputPath: artifacts.DiagnosticsPath,
 		BundleOutputPath:      artifacts.BundlePath,
+		ListingOutputPath:     artifacts.ListingPath,
 		EmitROMBytes:          true,
 		EmitManifestJSON:      true,
 		EmitDiagnosticsJSON:   true,
 		EmitBundleJSON:        true,
+		ValidateHardware:      cfg.ValidateHardware,
+	}
+	bundle, res, err := s.compiler.CompileBundle(source, corelx.Options{
+		SourcePath:     sourcePath,
+		Target:         corelx.Target(cfg.Target),
+		Optimize:       true,
+		SkipBootSplash: cfg.Profile == ProfileDebug,
+		Defines:        cfg.Defines,
+		Advanced:       advanced,
+	})
+	if err == nil {
+		s.rememberBuild(res)
 	}
-	bundle, res, err := s.compiler.CompileBundleSource(source, sourcePath, opts)
 	return &BuildResult{
 		Bundle:     bundle,
 		Result:     res,
@@ -155,6 +325,42 @@ func (s *Service) BuildSource(source, sourcePath string) (*BuildResult, error) {
 	}, err
 }
 
+// CheckSource compiles source with cfg as BuildSourceWith does, but in
+// memory: no artifacts are written and the build is not remembered for
+// fault symbols. It is for checking a buffer while it is being edited. A
+// compile cannot be interrupted, so when ctx is canceled before it finishes
+// its result is dropped and CheckSource returns ctx.Err().
+func (s *Service) CheckSource(ctx context.Context, source, sourcePath string, cfg RunConfig) (*BuildResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if sourcePath == "" {
+		sourcePath = "untitled.corelx"
+	}
+	start := time.Now()
+	bundle, res, err := s.compiler.CompileBundle(source, corelx.Options{
+		SourcePath:     sourcePath,
+		Target:         corelx.Target(cfg.Target),
+		Optimize:       true,
+		SkipBootSplash: cfg.Profile == ProfileDebug,
+		Defines:        cfg.Defines,
+		Advanced: &corelx.CompileOptions{
+			EmitROMBytes:     true,
+			EmitManifestJSON: true,
+			ValidateHardware: cfg.ValidateHardware,
+		},
+	})
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
+	return &BuildResult{
+		Bundle:     bundle,
+		Result:     res,
+		Elapsed:    time.Since(start),
+		SourcePath: sourcePath,
+	}, err
+}
+
 func (s *Service) LoadROMBytes(romBytes []byte) error {
 	if len(romBytes) == 0 {
 		return fmt.Errorf("empty ROM bytes")
@@ -170,11 +376,30 @@ func (s *Service) LoadROMBytes(romBytes []byte) error {
 	}
 	emu.Start()
 	emu.SetInputButtons(0)
+	emu.EnableWriteTracking(true)
+	emu.EnableTestMode()
 
 	s.mu.Lock()
 	old := s.emu
 	s.emu = emu
+	s.symbols = s.symbolsForLocked(romBytes)
+	emu.EnableCallStack(s.symbols)
 	s.tickAccumulator = 0
+	s.clearStepHistoryLocked()
+	s.clearMovieLocked()
+	s.resetAudioHealthLocked(true)
+	emu.SetDeterministic(s.deterministic)
+	for ch := apu.MixerChannel(0); ch < apu.MixerChannelCount; ch++ {
+		emu.APU.SetChannelMuted(ch, s.audioMuted[ch])
+		emu.APU.SetChannelSolo(ch, s.audioSolo[ch])
+	}
+	for c, level := range s.logLevels {
+		emu.Logger.SetComponentLevel(c, level)
+	}
+	emu.PPU.PriorityView = s.priorityView
+	if s.inputDisplay {
+		emu.AddFramePlugin(inputDisplayPlugin, emulator.InputDisplayPlugin)
+	}
 	s.mu.Unlock()
 
 	if old != nil {
@@ -192,6 +417,8 @@ func (s *Service) Shutdown() {
 	emu := s.emu
 	s.emu = nil
 	s.tickAccumulator = 0
+	s.clearStepHistoryLocked()
+	s.clearMovieLocked()
 	s.mu.Unlock()
 	if emu != nil {
 		emu.Stop()
@@ -232,18 +459,142 @@ func (s *Service) Snapshot() EmulatorSnapshot {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	if s.emu == nil {
-		return EmulatorSnapshot{}
+		return EmulatorSnapshot{Deterministic: s.deterministic}
 	}
+	return s.snapshotLocked()
+}
+
+func (s *Service) snapshotLocked() EmulatorSnapshot {
 	return EmulatorSnapshot{
 		Loaded:            true,
 		Running:           s.emu.Running,
 		Paused:            s.emu.Paused,
+		Deterministic:     s.emu.Deterministic,
 		FPS:               s.emu.GetFPS(),
 		CPUCyclesPerFrame: s.emu.GetCPUCyclesPerFrame(),
 		FrameCount:        s.emu.FrameCount,
+		Timing:            s.emu.FrameStats(),
+		InputButtons:      s.emu.Input.Controller1Buttons,
+	}
+}
+
+// SetDeterministic switches the session between wall-clock pacing and
+// fixed-timestep deterministic mode. In deterministic mode every Tick call
+// advances exactly one frame regardless of delta, so a run is a pure function
+// of the ROM and the per-tick input sequence. The setting survives ROM loads.
+func (s *Service) SetDeterministic(enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deterministic = enabled
+	s.tickAccumulator = 0
+	if s.emu != nil {
+		s.emu.SetDeterministic(enabled)
+	}
+}
+
+func (s *Service) Deterministic() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.deterministic
+}
+
+// SetPriorityView turns the PPU's priority debug render mode on or off:
+// each pixel of the frame is tinted by the layer that won priority
+// resolution (see ppu.PriorityViewTints), which makes layering bugs
+// visible. It only changes the picture, not emulated state, but movie
+// checkpoints hash the tinted frame.
+func (s *Service) SetPriorityView(enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.priorityView = enabled
+	if s.emu != nil {
+		s.emu.PPU.PriorityView = enabled
+	}
+}
+
+func (s *Service) PriorityView() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.priorityView
+}
+
+// inputDisplayPlugin is the frame plugin name SetInputDisplay installs
+// under.
+const inputDisplayPlugin = "input-display"
+
+// SetInputDisplay shows or hides the on-screen controller drawn over the
+// bottom left corner of the frame, lit up with the buttons each frame saw
+// (emulator.InputDisplayPlugin). Like the priority view it only changes the
+// picture, and movie checkpoints hash it.
+func (s *Service) SetInputDisplay(enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inputDisplay = enabled
+	if s.emu == nil {
+		return
+	}
+	if enabled {
+		s.emu.AddFramePlugin(inputDisplayPlugin, emulator.InputDisplayPlugin)
+	} else {
+		s.emu.RemoveFramePlugin(inputDisplayPlugin)
+	}
+}
+
+// Notify shows text as an on-screen notification over the presented frame
+// (see emulator.Notifications). It is dropped when no ROM is loaded.
+func (s *Service) Notify(text string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.emu != nil {
+		s.emu.Notify(text)
+	}
+}
+
+func (s *Service) InputDisplay() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.inputDisplay
+}
+
+// Emulator speed limits accepted by SetSpeed.
+const (
+	MinSpeed = 0.25
+	MaxSpeed = 4.0
+)
+
+// SetSpeed scales wall-clock pacing: 2 runs the emulator at twice real time,
+// 0.5 at half. It is clamped to [MinSpeed, MaxSpeed] and has no effect in
+// deterministic mode, where every Tick advances exactly one frame.
+func (s *Service) SetSpeed(multiplier float64) {
+	if multiplier < MinSpeed {
+		multiplier = MinSpeed
 	}
+	if multiplier > MaxSpeed {
+		multiplier = MaxSpeed
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.speed = multiplier
+	s.tickAccumulator = 0
+}
+
+func (s *Service) Speed() float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.speed
 }
 
+// ReportAudioQueue forwards the host audio queue depth (in output samples)
+// to the emulator's frame statistics.
+func (s *Service) ReportAudioQueue(samples int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.emu != nil {
+		s.emu.ReportAudioQueue(samples)
+	}
+}
+
+// ResetEmulator power-cycles the loaded session (emulator.Reset).
 func (s *Service) ResetEmulator() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -251,6 +602,24 @@ func (s *Service) ResetEmulator() error {
 		return fmt.Errorf("no ROM loaded")
 	}
 	s.emu.Reset()
+	s.clearStepHistoryLocked()
+	s.clearMovieLocked()
+	s.resetAudioHealthLocked(true)
+	return nil
+}
+
+// SoftResetEmulator presses the reset button on the loaded session
+// (emulator.SoftReset): the ROM restarts with RAM and VRAM intact.
+func (s *Service) SoftResetEmulator() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.emu == nil {
+		return fmt.Errorf("no ROM loaded")
+	}
+	s.emu.SoftReset()
+	s.clearStepHistoryLocked()
+	s.clearMovieLocked()
+	s.resetAudioHealthLocked(true)
 	return nil
 }
 
@@ -268,13 +637,77 @@ func (s *Service) TogglePause() (bool, error) {
 	return true, nil
 }
 
+// SaveState serializes the loaded session (CPU, PPU, APU, memory, input and
+// run/pause flags). ROM contents are not included; LoadState expects the same
+// ROM to be loaded.
+func (s *Service) SaveState() ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.emu == nil {
+		return nil, fmt.Errorf("no ROM loaded")
+	}
+	return s.emu.SaveState()
+}
+
+// LoadState restores a snapshot produced by SaveState into the loaded
+// session.
+func (s *Service) LoadState(data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.emu == nil {
+		return fmt.Errorf("no ROM loaded")
+	}
+	if err := s.emu.LoadState(data); err != nil {
+		return err
+	}
+	s.tickAccumulator = 0
+	s.clearStepHistoryLocked()
+	s.clearMovieLocked()
+	s.resetAudioHealthLocked(true)
+	return nil
+}
+
 func (s *Service) SetInputButtons(buttons uint16) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	if s.emu == nil {
 		return
 	}
-	s.emu.SetInputButtons(buttons)
+	if buttons != s.held {
+		s.breakStepHistoryLocked()
+	}
+	s.held = buttons
+	if s.moviePlay == nil {
+		s.emu.SetInputButtons(s.host.Apply(buttons))
+	}
+}
+
+// SetTurbo makes the buttons in mask auto-fire at hz presses per second
+// while held (see input.HostInput).
+func (s *Service) SetTurbo(mask uint16, hz int) {
+	s.host.SetTurbo(mask, hz)
+}
+
+// ToggleMacroRecording starts recording an input macro from the held
+// buttons, or stops the current recording. It returns whether a recording
+// is now in progress and, when one just ended, its length in frames.
+func (s *Service) ToggleMacroRecording() (recording bool, frames int) {
+	if !s.host.Recording() {
+		s.host.StartRecording()
+		s.Notify("Macro recording started")
+		return true, 0
+	}
+	frames = s.host.StopRecording()
+	s.Notify(fmt.Sprintf("Macro recorded: %d frames", frames))
+	return false, frames
+}
+
+// PlayMacro replays the recorded input macro over the held buttons.
+func (s *Service) PlayMacro() error {
+	if !s.host.Play() {
+		return fmt.Errorf("no macro recorded")
+	}
+	return nil
 }
 
 func (s *Service) RunFrame() error {
@@ -283,7 +716,24 @@ func (s *Service) RunFrame() error {
 	if s.emu == nil {
 		return nil
 	}
-	return s.emu.RunFrame()
+	s.clearStepHistoryLocked()
+	return s.runFrameLocked()
+}
+
+// runFrameLocked advances the host input layer one frame and runs one
+// emulated frame with its output. A failed frame returns an
+// *emulator.Fault and pauses the emulator, so a Tick loop reports the
+// fault once instead of on every tick.
+func (s *Service) runFrameLocked() error {
+	s.breakStepHistoryLocked()
+	s.emu.SetInputButtons(s.movieInputLocked())
+	if err := s.emu.RunFrameGuarded(s.symbols); err != nil {
+		s.emu.Pause()
+		return err
+	}
+	s.checkMovieLocked()
+	s.feedAudioHealthLocked()
+	return nil
 }
 
 func (s *Service) StepFrame(frames int) error {
@@ -306,9 +756,13 @@ func (s *Service) StepFrame(frames int) error {
 	}
 
 	for i := 0; i < frames; i++ {
-		if err := s.emu.RunFrame(); err != nil {
+		if err := s.checkpointLocked(true); err != nil {
+			return err
+		}
+		if err := s.runFrameLocked(); err != nil {
 			return err
 		}
+		s.stepPos++
 	}
 	return nil
 }
@@ -323,22 +777,28 @@ func (s *Service) StepCPU(steps int) error {
 	if s.emu == nil {
 		return fmt.Errorf("no ROM loaded")
 	}
+	if err := s.movieBusyErrorLocked(); err != nil {
+		return err
+	}
 
 	for i := 0; i < steps; i++ {
+		if err := s.checkpointLocked(false); err != nil {
+			return err
+		}
 		if err := s.emu.CPU.ExecuteInstruction(); err != nil {
 			return err
 		}
+		s.stepPos++
 	}
 	return nil
 }
 
+// maxCatchUpFrames caps the frames one Tick or RunFrames call runs, so a
+// long host stall skips time instead of fast-forwarding through it.
+const maxCatchUpFrames = 4
+
 func (s *Service) Tick(delta time.Duration) (TickResult, error) {
-	const (
-		emuHz            = 60
-		maxCatchUpFrames = 4
-		maxDelta         = 250 * time.Millisecond
-	)
-	frameStep := time.Second / emuHz
+	const maxDelta = 250 * time.Millisecond
 
 	if delta < 0 {
 		delta = 0
@@ -354,54 +814,85 @@ func (s *Service) Tick(delta time.Duration) (TickResult, error) {
 	if s.emu == nil {
 		return out, nil
 	}
+	// Frames stepped since the last tick (by this one's predecessor or by
+	// stepping while paused) may have printed.
+	out.TestOutput = s.emu.TestPort.TakeOutput()
+	out.DebugLines = s.emu.DebugConsole.TakeLines()
 
 	if s.emu.Paused {
 		s.tickAccumulator = 0
-		out.Snapshot = EmulatorSnapshot{
-			Loaded:            true,
-			Running:           s.emu.Running,
-			Paused:            s.emu.Paused,
-			FPS:               s.emu.GetFPS(),
-			CPUCyclesPerFrame: s.emu.GetCPUCyclesPerFrame(),
-			FrameCount:        s.emu.FrameCount,
-		}
-		if s.emu.FrameCount%8 == 0 {
+		out.Snapshot = s.snapshotLocked()
+		// Keep presenting while notifications fade over the still frame.
+		if s.emu.FrameCount%8 == 0 || len(s.emu.OSD.Active()) > 0 {
 			out.PresentFrame = true
 			out.Framebuffer = copyFramebufferLocked(s.emu)
 		}
 		return out, nil
 	}
 
-	s.tickAccumulator += delta
-	maxAccum := frameStep * maxCatchUpFrames
-	if s.tickAccumulator > maxAccum {
-		s.tickAccumulator = maxAccum
+	s.clearStepHistoryLocked()
+	// One frame is 1/60 s, or 1/50 s for a ROM with 50 Hz timing.
+	frameStep := s.emu.FrameTime
+	frames := 1 // deterministic: fixed timestep, delta ignored entirely
+	if !s.deterministic {
+		s.tickAccumulator += time.Duration(float64(delta) * s.speed)
+		maxAccum := frameStep * maxCatchUpFrames
+		if s.tickAccumulator > maxAccum {
+			s.tickAccumulator = maxAccum
+		}
+		frames = int(s.tickAccumulator / frameStep)
+		s.tickAccumulator -= time.Duration(frames) * frameStep
 	}
+	err := s.runFramesLocked(frames, &out)
+	return out, err
+}
 
-	audioFrames := make([][]int16, 0, maxCatchUpFrames)
-	for s.tickAccumulator >= frameStep && out.FramesStepped < maxCatchUpFrames {
-		if err := s.emu.RunFrame(); err != nil {
-			return out, err
-		}
-		audioFrames = append(audioFrames, copyAudioLocked(s.emu))
-		s.tickAccumulator -= frameStep
-		out.FramesStepped++
+// RunFrames runs n frames (at most maxCatchUpFrames) of the running ROM
+// for a caller that paces emulation itself rather than by wall-clock time:
+// the Dev Kit's audio thread runs frames as the audio device drains, so UI
+// stalls do not starve it. The result is Tick's; nothing runs while
+// paused. Only the number of frames and their input reach the emulator,
+// so deterministic runs reproduce whoever paces them.
+func (s *Service) RunFrames(n int) (TickResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out TickResult
+	if s.emu == nil {
+		return out, nil
+	}
+	out.TestOutput = s.emu.TestPort.TakeOutput()
+	out.DebugLines = s.emu.DebugConsole.TakeLines()
+	if s.emu.Paused || n <= 0 {
+		out.Snapshot = s.snapshotLocked()
+		return out, nil
 	}
+	s.clearStepHistoryLocked()
+	s.tickAccumulator = 0
+	err := s.runFramesLocked(n, &out)
+	return out, err
+}
 
-	out.Snapshot = EmulatorSnapshot{
-		Loaded:            true,
-		Running:           s.emu.Running,
-		Paused:            s.emu.Paused,
-		FPS:               s.emu.GetFPS(),
-		CPUCyclesPerFrame: s.emu.GetCPUCyclesPerFrame(),
-		FrameCount:        s.emu.FrameCount,
+// runFramesLocked runs up to n frames into out, with each frame's audio
+// and the last framebuffer.
+func (s *Service) runFramesLocked(n int, out *TickResult) error {
+	if n > maxCatchUpFrames {
+		n = maxCatchUpFrames
+	}
+	out.AudioFrames = make([][]int16, 0, n)
+	var err error
+	for out.FramesStepped < n {
+		if err = s.runFrameLocked(); err != nil {
+			break
+		}
+		out.AudioFrames = append(out.AudioFrames, copyAudioLocked(s.emu))
+		out.FramesStepped++
 	}
-	out.AudioFrames = audioFrames
+	out.Snapshot = s.snapshotLocked()
 	if out.FramesStepped > 0 {
 		out.PresentFrame = true
 		out.Framebuffer = copyFramebufferLocked(s.emu)
 	}
-	return out, nil
+	return err
 }
 
 func (s *Service) FramebufferCopy() []uint32 {
@@ -461,6 +952,205 @@ func (s *Service) GetPCState() PCStateSnapshot {
 	}
 }
 
+func (s *Service) SetAudioChannelMuted(ch apu.MixerChannel, muted bool) {
+	if ch < 0 || ch >= apu.MixerChannelCount {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.audioMuted[ch] = muted
+	if s.emu != nil {
+		s.emu.APU.SetChannelMuted(ch, muted)
+	}
+}
+
+func (s *Service) SetAudioChannelSolo(ch apu.MixerChannel, solo bool) {
+	if ch < 0 || ch >= apu.MixerChannelCount {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.audioSolo[ch] = solo
+	if s.emu != nil {
+		s.emu.APU.SetChannelSolo(ch, solo)
+	}
+}
+
+// AudioMixerSnapshot returns per-channel levels and up to scopeSamples of
+// scope history. Reading it resets the level window, so a UI polling once per
+// presented frame gets per-frame VU readings.
+func (s *Service) AudioMixerSnapshot(scopeSamples int) AudioMixerSnapshot {
+	if scopeSamples < 0 {
+		scopeSamples = 0
+	}
+	if scopeSamples > apu.MixerScopeSize {
+		scopeSamples = apu.MixerScopeSize
+	}
+
+	// TakeMixerLevels mutates the meters, so this needs the write lock.
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := AudioMixerSnapshot{Channels: make([]AudioChannelSnapshot, 0, apu.MixerChannelCount)}
+	if s.emu == nil {
+		for ch := apu.MixerChannel(0); ch < apu.MixerChannelCount; ch++ {
+			out.Channels = append(out.Channels, AudioChannelSnapshot{
+				Channel: ch,
+				Name:    ch.String(),
+				Muted:   s.audioMuted[ch],
+				Solo:    s.audioSolo[ch],
+			})
+		}
+		return out
+	}
+	out.Loaded = true
+	out.Health = s.audioHealth
+	levels := s.emu.APU.TakeMixerLevels()
+	for _, lvl := range levels {
+		ch := AudioChannelSnapshot{
+			Channel: lvl.Channel,
+			Name:    lvl.Channel.String(),
+			Muted:   lvl.Muted,
+			Solo:    lvl.Solo,
+			Audible: lvl.Audible,
+			Peak:    float64(lvl.Peak) / 32768.0,
+			RMS:     float64(lvl.RMS) / 32768.0,
+		}
+		if scopeSamples > 0 {
+			ch.Scope = make([]int16, scopeSamples)
+			s.emu.APU.CopyChannelScope(lvl.Channel, ch.Scope)
+		}
+		out.Channels = append(out.Channels, ch)
+	}
+	return out
+}
+
+// PaletteSnapshot returns the running session's CGRAM.
+func (s *Service) PaletteSnapshot() PaletteSnapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var out PaletteSnapshot
+	if s.emu == nil {
+		return out
+	}
+	out.Loaded = true
+	for i := range out.Colors {
+		out.Colors[i] = uint16(s.emu.PPU.CGRAM[i*2]) | uint16(s.emu.PPU.CGRAM[i*2+1])<<8
+	}
+	return out
+}
+
+// SetPaletteColor writes one CGRAM entry through the CGRAM_ADDR/CGRAM_DATA
+// ports so the PPU color cache stays in sync. The ROM's own CGRAM port state
+// is restored afterwards, so a half-finished write sequence is not disturbed.
+func (s *Service) SetPaletteColor(palette, index int, rgb555 uint16) error {
+	if palette < 0 || palette > 15 || index < 0 || index > 15 {
+		return fmt.Errorf("palette color %d:%d out of range", palette, index)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.emu == nil {
+		return fmt.Errorf("no ROM loaded")
+	}
+	if err := s.movieBusyErrorLocked(); err != nil {
+		return err
+	}
+	s.breakStepHistoryLocked()
+	p := s.emu.PPU
+	addr, latch, pending := p.CGRAMAddr, p.CGRAMWriteLatch, p.CGRAMWriteValue
+	rgb555 &= 0x7FFF
+	p.Write8(0x12, uint8(palette*16+index))
+	p.Write8(0x13, uint8(rgb555))
+	p.Write8(0x13, uint8(rgb555>>8))
+	p.CGRAMAddr, p.CGRAMWriteLatch, p.CGRAMWriteValue = addr, latch, pending
+	return nil
+}
+
+// IORegisters reads every named I/O register without side effects. It
+// returns nil when no ROM is loaded.
+func (s *Service) IORegisters() []IORegisterSnapshot {
+	// A full lock: live reads go through the bus, and the FM status read
+	// refreshes the chip's cached status byte.
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.emu == nil {
+		return nil
+	}
+	regs := emulator.IORegisters()
+	out := make([]IORegisterSnapshot, len(regs))
+	for i, reg := range regs {
+		value, known := s.emu.PeekIORegister(reg)
+		out[i] = IORegisterSnapshot{IORegister: reg, Value: value, Known: known}
+	}
+	return out
+}
+
+// WriteIORegister writes one byte to a bank 0 I/O address through the bus,
+// exactly as a CPU store would, whether the emulator is running or paused.
+func (s *Service) WriteIORegister(address uint16, value uint8) error {
+	if address < hwdef.IOBase || address >= hwdef.IOEnd {
+		return fmt.Errorf("address 0x%04X is not an I/O register (0x8000-0xAFFF)", address)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.emu == nil {
+		return fmt.Errorf("no ROM loaded")
+	}
+	if err := s.movieBusyErrorLocked(); err != nil {
+		return err
+	}
+	s.breakStepHistoryLocked()
+	s.emu.Bus.Write8(0, address, value)
+	return nil
+}
+
+// ReadMemory copies n bytes of space starting at addr without bus side
+// effects. Bytes past the end of the space read as 0.
+func (s *Service) ReadMemory(space emulator.MemorySpace, addr uint32, n int) ([]uint8, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.emu == nil {
+		return nil, fmt.Errorf("no ROM loaded")
+	}
+	out := make([]uint8, n)
+	for i := range out {
+		out[i], _ = s.emu.PeekMemory(space, addr+uint32(i))
+	}
+	return out, nil
+}
+
+// LastWrites answers "who wrote this byte": the last few writes to it with
+// the PC and frame of each, newest first (see emulator.LastWrites). Write
+// tracking is on for every session the Dev Kit loads.
+func (s *Service) LastWrites(space emulator.MemorySpace, addr uint32) ([]emulator.WriteRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.emu == nil {
+		return nil, fmt.Errorf("no ROM loaded")
+	}
+	return s.emu.LastWrites(space, addr)
+}
+
+// ExportTileSheet decodes the current VRAM with one CGRAM palette (see
+// emulator.TileSheet).
+func (s *Service) ExportTileSheet(opts emulator.TileSheetOptions) (*image.NRGBA, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.emu == nil {
+		return nil, fmt.Errorf("no ROM loaded")
+	}
+	return s.emu.ExportTileSheet(opts)
+}
+
+// ExportPaletteStrip draws the current CGRAM as a 16x16 swatch grid.
+func (s *Service) ExportPaletteStrip(swatch int) (*image.NRGBA, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.emu == nil {
+		return nil, fmt.Errorf("no ROM loaded")
+	}
+	return s.emu.ExportPaletteStrip(swatch), nil
+}
+
 func baseNameOr(path, fallback string) string {
 	if path == "" {
 		return fallback
@@ -472,10 +1162,13 @@ func baseNameOr(path, fallback string) string {
 	return b
 }
 
+// copyFramebufferLocked copies the frame to present, with any on-screen
+// notifications drawn over the copy.
 func copyFramebufferLocked(emu *emulator.Emulator) []uint32 {
 	src := emu.GetOutputBuffer()
 	dst := make([]uint32, len(src))
 	copy(dst, src)
+	emu.OSD.Draw(dst, 320, 200)
 	return dst
 }
 
@@ -1,12 +1,18 @@
 package devkit
 
 import (
+	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
 	"time"
 
+	"nitro-core-dx/internal/apu"
+	"nitro-core-dx/internal/corelx"
 	"nitro-core-dx/internal/emulator"
+	"nitro-core-dx/internal/hwdef"
+	"nitro-core-dx/internal/rom"
 )
 
 func TestServiceBuildSourceSuccessArtifacts(t *testing.T) {
@@ -72,6 +78,29 @@ func TestServiceBuildSourceErrorDiagnostics(t *testing.T) {
 	}
 }
 
+func TestServiceCheckSourceWritesNothing(t *testing.T) {
+	tmpDir := t.TempDir()
+	svc := NewService(tmpDir)
+
+	check, err := svc.CheckSource(context.Background(), "function Nope()\n    wait_vblank()\n", "bad.corelx", RunConfig{})
+	if err == nil || check == nil || check.Bundle.Summary.ErrorCount == 0 {
+		t.Fatalf("CheckSource(bad) = %+v, %v; want diagnostics and an error", check, err)
+	}
+	check, err = svc.CheckSource(context.Background(), "function Start()\n    wait_vblank()\n", "main.corelx", RunConfig{})
+	if err != nil || check.Result == nil || check.Result.Manifest == nil {
+		t.Fatalf("CheckSource(good) = %+v, %v; want a manifest", check, err)
+	}
+	if entries, _ := os.ReadDir(tmpDir); len(entries) != 0 {
+		t.Fatalf("CheckSource wrote %d files to the temp dir", len(entries))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := svc.CheckSource(ctx, "function Start()\n    wait_vblank()\n", "main.corelx", RunConfig{}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("canceled CheckSource error = %v, want context.Canceled", err)
+	}
+}
+
 func TestServiceEmulatorSessionSmoke(t *testing.T) {
 	tmpDir := t.TempDir()
 	svc := NewService(tmpDir)
@@ -112,6 +141,9 @@ function Start()
 	if snap.FrameCount == 0 {
 		t.Fatalf("expected frame count > 0 after RunFrame")
 	}
+	if snap.InputButtons != 0x123 {
+		t.Fatalf("expected input mask 0x123 in snapshot, got 0x%03X", snap.InputButtons)
+	}
 
 	fb := svc.FramebufferCopy()
 	if len(fb) != 320*200 {
@@ -139,6 +171,9 @@ function Start()
 	if err := svc.ResetEmulator(); err != nil {
 		t.Fatalf("reset emulator: %v", err)
 	}
+	if err := svc.SoftResetEmulator(); err != nil {
+		t.Fatalf("soft reset emulator: %v", err)
+	}
 
 	svc.Shutdown()
 	snap = svc.Snapshot()
@@ -192,6 +227,47 @@ function Start()
 	}
 }
 
+func TestServiceRunFramesRunsCountNotTime(t *testing.T) {
+	svc := NewService(t.TempDir())
+	defer svc.Shutdown()
+
+	src := `
+function Start()
+    while true
+        wait_vblank()
+`
+	build, err := svc.BuildSource(src, "run_frames.corelx")
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if err := svc.LoadROMBytes(build.Result.ROMBytes); err != nil {
+		t.Fatalf("load rom: %v", err)
+	}
+
+	for _, tt := range []struct{ n, want int }{{0, 0}, {-1, 0}, {3, 3}, {50, maxCatchUpFrames}} {
+		before := svc.Snapshot().FrameCount
+		tick, err := svc.RunFrames(tt.n)
+		if err != nil {
+			t.Fatalf("RunFrames(%d): %v", tt.n, err)
+		}
+		if tick.FramesStepped != tt.want || len(tick.AudioFrames) != tt.want || tick.PresentFrame != (tt.want > 0) {
+			t.Fatalf("RunFrames(%d): %d frames, %d audio, present %v; want %d",
+				tt.n, tick.FramesStepped, len(tick.AudioFrames), tick.PresentFrame, tt.want)
+		}
+		if got := tick.Snapshot.FrameCount - before; got != uint64(tt.want) {
+			t.Fatalf("RunFrames(%d) advanced %d frames, want %d", tt.n, got, tt.want)
+		}
+	}
+
+	if _, err := svc.TogglePause(); err != nil {
+		t.Fatalf("pause: %v", err)
+	}
+	tick, err := svc.RunFrames(2)
+	if err != nil || tick.FramesStepped != 0 || !tick.Snapshot.Paused {
+		t.Fatalf("RunFrames while paused: %d frames, paused %v, %v", tick.FramesStepped, tick.Snapshot.Paused, err)
+	}
+}
+
 func TestServiceTickPausedPresentsWithoutStepping(t *testing.T) {
 	tmpDir := t.TempDir()
 	svc := NewService(tmpDir)
@@ -578,3 +654,756 @@ function Start()
 		t.Fatalf("expected PC to change after CPU step (%02X:%04X)", afterPC.PCBank, afterPC.PCOffset)
 	}
 }
+
+func TestServiceAudioMixerMuteSurvivesROMReload(t *testing.T) {
+	tmpDir := t.TempDir()
+	svc := NewService(tmpDir)
+	defer svc.Shutdown()
+
+	svc.SetAudioChannelMuted(apu.MixerChannelFM, true)
+	svc.SetAudioChannelSolo(apu.MixerChannelLegacy1, true)
+
+	idle := svc.AudioMixerSnapshot(16)
+	if idle.Loaded {
+		t.Fatalf("expected unloaded mixer snapshot before ROM load")
+	}
+	if len(idle.Channels) != int(apu.MixerChannelCount) || !idle.Channels[apu.MixerChannelFM].Muted {
+		t.Fatalf("expected pending mute in unloaded snapshot, got %+v", idle.Channels)
+	}
+
+	src := `
+function Start()
+    while true
+        wait_vblank()
+`
+	build, err := svc.BuildSource(src, "mixer.corelx")
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if err := svc.LoadROMBytes(build.Result.ROMBytes); err != nil {
+		t.Fatalf("load rom: %v", err)
+	}
+	if _, err := svc.Tick(time.Second / 60); err != nil {
+		t.Fatalf("tick: %v", err)
+	}
+
+	snap := svc.AudioMixerSnapshot(32)
+	if !snap.Loaded {
+		t.Fatalf("expected loaded mixer snapshot")
+	}
+	fm := snap.Channels[apu.MixerChannelFM]
+	if !fm.Muted || fm.Audible {
+		t.Fatalf("expected FM muted after reload, got %+v", fm)
+	}
+	ch0 := snap.Channels[apu.MixerChannelLegacy0]
+	if ch0.Audible {
+		t.Fatalf("expected CH0 inaudible while CH1 soloed, got %+v", ch0)
+	}
+	if !snap.Channels[apu.MixerChannelLegacy1].Audible {
+		t.Fatalf("expected soloed CH1 audible")
+	}
+	if len(ch0.Scope) != 32 {
+		t.Fatalf("expected 32 scope samples, got %d", len(ch0.Scope))
+	}
+}
+
+func TestServicePaletteSnapshotAndSetColor(t *testing.T) {
+	tmpDir := t.TempDir()
+	svc := NewService(tmpDir)
+	defer svc.Shutdown()
+
+	if snap := svc.PaletteSnapshot(); snap.Loaded {
+		t.Fatalf("expected unloaded palette snapshot before ROM load")
+	}
+	if err := svc.SetPaletteColor(0, 1, 0x7FFF); err == nil {
+		t.Fatalf("expected error writing palette without a ROM")
+	}
+
+	src := `
+function Start()
+    gfx.set_palette(2, 3, 0x1234)
+    while true
+        wait_vblank()
+`
+	build, err := svc.BuildSource(src, "palette.corelx")
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if err := svc.LoadROMBytes(build.Result.ROMBytes); err != nil {
+		t.Fatalf("load rom: %v", err)
+	}
+	if _, err := svc.Tick(time.Second / 60); err != nil {
+		t.Fatalf("tick: %v", err)
+	}
+
+	snap := svc.PaletteSnapshot()
+	if !snap.Loaded || snap.Colors[2*16+3] != 0x1234 {
+		t.Fatalf("expected ROM palette write visible, got loaded=%v color=0x%04X", snap.Loaded, snap.Colors[2*16+3])
+	}
+	if err := svc.SetPaletteColor(15, 15, 0x7C1F); err != nil {
+		t.Fatalf("set palette color: %v", err)
+	}
+	if got := svc.PaletteSnapshot().Colors[255]; got != 0x7C1F {
+		t.Fatalf("expected live write 0x7C1F, got 0x%04X", got)
+	}
+	if err := svc.SetPaletteColor(16, 0, 0); err == nil {
+		t.Fatalf("expected out-of-range palette error")
+	}
+}
+
+func TestServiceIORegistersPeekAndPoke(t *testing.T) {
+	tmpDir := t.TempDir()
+	svc := NewService(tmpDir)
+	defer svc.Shutdown()
+
+	if regs := svc.IORegisters(); regs != nil {
+		t.Fatalf("expected no registers before ROM load")
+	}
+	if err := svc.WriteIORegister(0x8008, 1); err == nil {
+		t.Fatalf("expected error writing a register without a ROM")
+	}
+
+	src := `
+function Start()
+    while true
+        wait_vblank()
+`
+	build, err := svc.BuildSource(src, "io.corelx")
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if err := svc.LoadROMBytes(build.Result.ROMBytes); err != nil {
+		t.Fatalf("load rom: %v", err)
+	}
+
+	// Writes land while running and survive the next frame.
+	for _, w := range []struct {
+		addr  uint16
+		value uint8
+	}{{0x8001, 0x01}, {0x8008, 0x11}, {0x9000, 0x40}} {
+		if err := svc.WriteIORegister(w.addr, w.value); err != nil {
+			t.Fatalf("write 0x%04X: %v", w.addr, err)
+		}
+	}
+	if _, err := svc.Tick(time.Second / 60); err != nil {
+		t.Fatalf("tick: %v", err)
+	}
+	values := map[string]IORegisterSnapshot{}
+	for _, reg := range svc.IORegisters() {
+		values[reg.Name] = reg
+	}
+	for name, want := range map[string]uint8{"BG0_SCROLLX_H": 0x01, "BG0_CONTROL": 0x11, "CH0_FREQ_LOW": 0x40} {
+		if got := values[name]; !got.Known || got.Value != want {
+			t.Fatalf("%s = %+v, want 0x%02X", name, got, want)
+		}
+	}
+	if got := svc.emu.PPU.BG0.ScrollX; got != 0x100 {
+		t.Fatalf("BG0 scroll X = 0x%X, want 0x100", got)
+	}
+
+	if err := svc.WriteIORegister(0x7000, 1); err == nil {
+		t.Fatalf("expected WRAM address to be rejected")
+	}
+}
+
+func TestServiceExportTileSheetAndPalette(t *testing.T) {
+	tmpDir := t.TempDir()
+	svc := NewService(tmpDir)
+	defer svc.Shutdown()
+
+	if _, err := svc.ExportTileSheet(emulator.TileSheetOptions{}); err == nil {
+		t.Fatalf("expected tile export to fail without a ROM")
+	}
+	if _, err := svc.ExportPaletteStrip(8); err == nil {
+		t.Fatalf("expected palette export to fail without a ROM")
+	}
+
+	src := `
+function Start()
+    gfx.set_palette(1, 1, 0x7C00)
+    while true
+        wait_vblank()
+`
+	build, err := svc.BuildSource(src, "rip.corelx")
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if err := svc.LoadROMBytes(build.Result.ROMBytes); err != nil {
+		t.Fatalf("load rom: %v", err)
+	}
+	if _, err := svc.Tick(time.Second / 60); err != nil {
+		t.Fatalf("tick: %v", err)
+	}
+	svc.emu.PPU.VRAM[0] = 0x11
+
+	sheet, err := svc.ExportTileSheet(emulator.TileSheetOptions{Palette: 1})
+	if err != nil {
+		t.Fatalf("export tiles: %v", err)
+	}
+	if got := sheet.NRGBAAt(0, 0); got.R != 255 || got.G != 0 || got.B != 0 {
+		t.Fatalf("tile pixel = %v, want palette 1 color 1 (red)", got)
+	}
+	strip, err := svc.ExportPaletteStrip(8)
+	if err != nil {
+		t.Fatalf("export palette: %v", err)
+	}
+	if got := strip.NRGBAAt(8+4, 8+4); got.R != 255 {
+		t.Fatalf("palette swatch = %v, want red", got)
+	}
+}
+
+func TestServiceDeterministicTickIgnoresDelta(t *testing.T) {
+	tmpDir := t.TempDir()
+	svc := NewService(tmpDir)
+	defer svc.Shutdown()
+
+	svc.SetDeterministic(true)
+
+	src := `
+function Start()
+    while true
+        wait_vblank()
+`
+	build, err := svc.BuildSource(src, "deterministic.corelx")
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if err := svc.LoadROMBytes(build.Result.ROMBytes); err != nil {
+		t.Fatalf("load rom: %v", err)
+	}
+
+	for i, delta := range []time.Duration{0, time.Millisecond, time.Second / 60, 200 * time.Millisecond} {
+		tick, err := svc.Tick(delta)
+		if err != nil {
+			t.Fatalf("tick %d: %v", i, err)
+		}
+		if tick.FramesStepped != 1 || len(tick.AudioFrames) != 1 {
+			t.Fatalf("tick %d (delta %v): expected exactly one frame, got %d frames / %d audio",
+				i, delta, tick.FramesStepped, len(tick.AudioFrames))
+		}
+		if !tick.Snapshot.Deterministic {
+			t.Fatalf("tick %d: expected deterministic snapshot", i)
+		}
+		if tick.Snapshot.FPS != 60 {
+			t.Fatalf("tick %d: expected nominal FPS 60, got %v", i, tick.Snapshot.FPS)
+		}
+	}
+	if got := svc.Snapshot().FrameCount; got != 4 {
+		t.Fatalf("expected 4 frames after 4 ticks, got %d", got)
+	}
+
+	svc.SetDeterministic(false)
+	tick, err := svc.Tick(0)
+	if err != nil {
+		t.Fatalf("tick: %v", err)
+	}
+	if tick.FramesStepped != 0 {
+		t.Fatalf("expected wall-clock mode to step no frames for zero delta, got %d", tick.FramesStepped)
+	}
+}
+
+func TestServicePriorityViewSurvivesReload(t *testing.T) {
+	tmpDir := t.TempDir()
+	svc := NewService(tmpDir)
+	defer svc.Shutdown()
+
+	svc.SetPriorityView(true)
+	build, err := svc.BuildSource(`
+function Start()
+    while true
+        wait_vblank()
+`, "priority.corelx")
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := svc.LoadROMBytes(build.Result.ROMBytes); err != nil {
+			t.Fatalf("load rom: %v", err)
+		}
+		if _, err := svc.RunFrames(2); err != nil {
+			t.Fatalf("run frames: %v", err)
+		}
+		if fb := svc.FramebufferCopy(); fb[0] == 0 {
+			t.Fatalf("load %d: frame is not tinted (pixel 0 = 0x%06X)", i, fb[0])
+		}
+	}
+
+	svc.SetPriorityView(false)
+	if _, err := svc.RunFrames(1); err != nil {
+		t.Fatalf("run frames: %v", err)
+	}
+	if fb := svc.FramebufferCopy(); fb[0] != 0 || svc.PriorityView() {
+		t.Fatalf("priority view still on after SetPriorityView(false): pixel 0 = 0x%06X", fb[0])
+	}
+}
+
+func TestServiceInputDisplaySurvivesReload(t *testing.T) {
+	tmpDir := t.TempDir()
+	svc := NewService(tmpDir)
+	defer svc.Shutdown()
+
+	svc.SetInputDisplay(true)
+	build, err := svc.BuildSource(`
+function Start()
+    while true
+        wait_vblank()
+`, "input_display.corelx")
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	// Top left corner of the overlay's outline.
+	const corner = 172*320 + 4
+	for i := 0; i < 2; i++ {
+		if err := svc.LoadROMBytes(build.Result.ROMBytes); err != nil {
+			t.Fatalf("load rom: %v", err)
+		}
+		if _, err := svc.RunFrames(2); err != nil {
+			t.Fatalf("run frames: %v", err)
+		}
+		if fb := svc.FramebufferCopy(); fb[corner] == 0 {
+			t.Fatalf("load %d: no input display drawn", i)
+		}
+	}
+
+	svc.SetInputDisplay(false)
+	if _, err := svc.RunFrames(1); err != nil {
+		t.Fatalf("run frames: %v", err)
+	}
+	if fb := svc.FramebufferCopy(); fb[corner] != 0 || svc.InputDisplay() {
+		t.Fatalf("input display still on after SetInputDisplay(false): pixel = 0x%06X", fb[corner])
+	}
+}
+
+func TestServiceNotifyDrawsOverPresentedFrame(t *testing.T) {
+	svc := NewService(t.TempDir())
+	defer svc.Shutdown()
+
+	build, err := svc.BuildSource(`
+function Start()
+    while true
+        wait_vblank()
+`, "notify.corelx")
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if err := svc.LoadROMBytes(build.Result.ROMBytes); err != nil {
+		t.Fatalf("load rom: %v", err)
+	}
+	if _, err := svc.RunFrames(2); err != nil {
+		t.Fatalf("run frames: %v", err)
+	}
+	svc.ToggleMacroRecording()
+	tick, err := svc.RunFrames(1)
+	if err != nil {
+		t.Fatalf("run frames: %v", err)
+	}
+	// The box's right edge sits 4 pixels in from the top right corner.
+	const boxPixel = 5*320 + 320 - 5
+	if tick.Framebuffer[boxPixel] == svc.emu.GetOutputBuffer()[boxPixel] {
+		t.Fatalf("no notification drawn: pixel = 0x%06X", tick.Framebuffer[boxPixel])
+	}
+	svc.ToggleMacroRecording()
+}
+
+func TestServiceSaveAndLoadState(t *testing.T) {
+	tmpDir := t.TempDir()
+	svc := NewService(tmpDir)
+	defer svc.Shutdown()
+
+	if _, err := svc.SaveState(); err == nil {
+		t.Fatalf("expected error saving state without a ROM")
+	}
+	if err := svc.LoadState(nil); err == nil {
+		t.Fatalf("expected error loading state without a ROM")
+	}
+
+	src := `
+function Start()
+    while true
+        wait_vblank()
+`
+	build, err := svc.BuildSource(src, "state.corelx")
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if err := svc.LoadROMBytes(build.Result.ROMBytes); err != nil {
+		t.Fatalf("load rom: %v", err)
+	}
+	if err := svc.StepFrame(2); err != nil {
+		t.Fatalf("step: %v", err)
+	}
+	if err := svc.SetPaletteColor(1, 1, 0x0421); err != nil {
+		t.Fatalf("set palette color: %v", err)
+	}
+	if _, err := svc.TogglePause(); err != nil {
+		t.Fatalf("pause: %v", err)
+	}
+
+	state, err := svc.SaveState()
+	if err != nil {
+		t.Fatalf("save state: %v", err)
+	}
+	if err := svc.SetPaletteColor(1, 1, 0x7FFF); err != nil {
+		t.Fatalf("set palette color: %v", err)
+	}
+	if _, err := svc.TogglePause(); err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+
+	if err := svc.LoadState(state); err != nil {
+		t.Fatalf("load state: %v", err)
+	}
+	if got := svc.PaletteSnapshot().Colors[17]; got != 0x0421 {
+		t.Fatalf("expected restored palette 0x0421, got 0x%04X", got)
+	}
+	if !svc.Snapshot().Paused {
+		t.Fatalf("expected paused flag restored from state")
+	}
+	if err := svc.LoadState([]byte("not a state")); err == nil {
+		t.Fatalf("expected error for corrupt state")
+	}
+}
+
+func TestServiceSpeedScalesWallClockTicks(t *testing.T) {
+	tmpDir := t.TempDir()
+	svc := NewService(tmpDir)
+	defer svc.Shutdown()
+
+	if got := svc.Speed(); got != 1 {
+		t.Fatalf("expected default speed 1, got %v", got)
+	}
+	svc.SetSpeed(100)
+	if got := svc.Speed(); got != MaxSpeed {
+		t.Fatalf("expected speed clamped to %v, got %v", MaxSpeed, got)
+	}
+
+	src := `
+function Start()
+    while true
+        wait_vblank()
+`
+	build, err := svc.BuildSource(src, "speed.corelx")
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if err := svc.LoadROMBytes(build.Result.ROMBytes); err != nil {
+		t.Fatalf("load rom: %v", err)
+	}
+
+	svc.SetSpeed(2)
+	tick, err := svc.Tick(time.Second / 60)
+	if err != nil {
+		t.Fatalf("tick: %v", err)
+	}
+	if tick.FramesStepped != 2 {
+		t.Fatalf("expected 2 frames at 2x speed, got %d", tick.FramesStepped)
+	}
+
+	svc.SetSpeed(0.5)
+	stepped := 0
+	for i := 0; i < 4; i++ {
+		tick, err := svc.Tick(time.Second / 60)
+		if err != nil {
+			t.Fatalf("tick: %v", err)
+		}
+		stepped += tick.FramesStepped
+	}
+	if stepped != 2 {
+		t.Fatalf("expected 2 frames over 4 ticks at half speed, got %d", stepped)
+	}
+}
+
+func TestServiceTurboAndMacroDriveInput(t *testing.T) {
+	tmpDir := t.TempDir()
+	svc := NewService(tmpDir)
+	defer svc.Shutdown()
+
+	src := `
+function Start()
+    while true
+        wait_vblank()
+`
+	build, err := svc.BuildSource(src, "turbo.corelx")
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if err := svc.LoadROMBytes(build.Result.ROMBytes); err != nil {
+		t.Fatalf("load rom: %v", err)
+	}
+
+	const buttonA = 0x10
+	svc.SetTurbo(buttonA, 30) // 2-frame period: down, up
+	svc.SetInputButtons(buttonA)
+	var seen []uint16
+	for i := 0; i < 4; i++ {
+		if err := svc.RunFrame(); err != nil {
+			t.Fatalf("run frame: %v", err)
+		}
+		seen = append(seen, svc.Snapshot().InputButtons&buttonA)
+	}
+	if seen[0] == seen[1] || seen[0] != seen[2] || seen[1] != seen[3] {
+		t.Fatalf("expected A to alternate under turbo, got %v", seen)
+	}
+	svc.SetTurbo(0, 30)
+
+	if err := svc.PlayMacro(); err == nil {
+		t.Fatalf("expected PlayMacro to fail with no macro recorded")
+	}
+	if recording, _ := svc.ToggleMacroRecording(); !recording {
+		t.Fatalf("expected recording to start")
+	}
+	for i := 0; i < 3; i++ {
+		if err := svc.RunFrame(); err != nil {
+			t.Fatalf("run frame: %v", err)
+		}
+	}
+	if recording, frames := svc.ToggleMacroRecording(); recording || frames != 3 {
+		t.Fatalf("expected a 3-frame macro, got recording=%v frames=%d", recording, frames)
+	}
+
+	svc.SetInputButtons(0)
+	if err := svc.PlayMacro(); err != nil {
+		t.Fatalf("play macro: %v", err)
+	}
+	if err := svc.RunFrame(); err != nil {
+		t.Fatalf("run frame: %v", err)
+	}
+	if got := svc.Snapshot().InputButtons; got != buttonA {
+		t.Fatalf("expected macro to press A, got %#04x", got)
+	}
+}
+
+func TestServiceReadMemoryAndLastWrites(t *testing.T) {
+	tmpDir := t.TempDir()
+	svc := NewService(tmpDir)
+	defer svc.Shutdown()
+
+	if _, err := svc.LastWrites(emulator.MemoryVRAM, 0); err == nil {
+		t.Fatalf("expected error without a ROM")
+	}
+
+	src := `
+function Start()
+    while true
+        wait_vblank()
+`
+	build, err := svc.BuildSource(src, "lastwrite.corelx")
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if err := svc.LoadROMBytes(build.Result.ROMBytes); err != nil {
+		t.Fatalf("load rom: %v", err)
+	}
+
+	for _, w := range []struct {
+		addr  uint16
+		value uint8
+	}{{hwdef.VRAM_ADDR_L, 0x20}, {hwdef.VRAM_ADDR_H, 0x40}, {hwdef.VRAM_DATA, 0x5A}} {
+		if err := svc.WriteIORegister(w.addr, w.value); err != nil {
+			t.Fatalf("write 0x%04X: %v", w.addr, err)
+		}
+	}
+	mem, err := svc.ReadMemory(emulator.MemoryVRAM, 0x4020, 2)
+	if err != nil {
+		t.Fatalf("read memory: %v", err)
+	}
+	if mem[0] != 0x5A {
+		t.Fatalf("VRAM 0x4020 = 0x%02X, want 0x5A", mem[0])
+	}
+	writes, err := svc.LastWrites(emulator.MemoryVRAM, 0x4020)
+	if err != nil {
+		t.Fatalf("last writes: %v", err)
+	}
+	if len(writes) != 1 || writes[0].Value != 0x5A {
+		t.Fatalf("expected one tracked write of 0x5A, got %v", writes)
+	}
+}
+
+func TestServiceFaultPausesAndDisassemblesWithSymbols(t *testing.T) {
+	tmpDir := t.TempDir()
+	svc := NewService(tmpDir)
+	defer svc.Shutdown()
+
+	src := `
+function Start()
+    while true
+        wait_vblank()
+`
+	build, err := svc.BuildSource(src, "fault.corelx")
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if err := svc.LoadROMBytes(build.Result.ROMBytes); err != nil {
+		t.Fatalf("load rom: %v", err)
+	}
+	var start corelx.FunctionSymbol
+	for _, f := range build.Result.Functions {
+		if f.Name == "Start" {
+			start = f
+		}
+	}
+	lines, err := svc.Disassemble(start.Bank, start.Offset, 2, 2)
+	if err != nil {
+		t.Fatalf("disassemble: %v", err)
+	}
+	found := false
+	for _, l := range lines {
+		found = found || l.Offset == start.Offset && l.Symbol == "Start"
+	}
+	if !found {
+		t.Fatalf("disassembly around Start has no Start line: %v", lines)
+	}
+
+	// A hand-built ROM whose first instruction is invalid faults on the
+	// first frame; the fault pauses the session and carries no symbols.
+	b := rom.NewROMBuilder()
+	b.AddInstruction(rom.EncodeADD(9, 0, 0))
+	bad, err := b.BuildROMBytes(1, 0x8000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.LoadROMBytes(bad); err != nil {
+		t.Fatalf("load rom: %v", err)
+	}
+	_, err = svc.Tick(100 * time.Millisecond)
+	var fault *emulator.Fault
+	if !errors.As(err, &fault) {
+		t.Fatalf("Tick = %v, want an *emulator.Fault", err)
+	}
+	if fault.Component != "CPU" || fault.PCOffset != 0x8000 || fault.Symbol != "" {
+		t.Fatalf("fault = %v", fault)
+	}
+	if !svc.Snapshot().Paused {
+		t.Fatalf("expected the session to pause after a fault")
+	}
+	if _, err := svc.Tick(100 * time.Millisecond); err != nil {
+		t.Fatalf("paused Tick after fault = %v, want nil", err)
+	}
+}
+
+func TestServiceTickReturnsTestPortOutput(t *testing.T) {
+	svc := NewService(t.TempDir())
+	defer svc.Shutdown()
+
+	src := `
+function Start()
+    mem.write(0xA030, 72)
+    mem.write(0xA034, 2)
+    mem.write(0xA030, 105)
+    mem.write(0xA034, 2)
+    while true
+        wait_vblank()
+`
+	build, err := svc.BuildSource(src, "testport.corelx")
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if err := svc.LoadROMBytes(build.Result.ROMBytes); err != nil {
+		t.Fatalf("load rom: %v", err)
+	}
+
+	var output string
+	for i := 0; i < 3; i++ {
+		tick, err := svc.Tick(time.Second / 60)
+		if err != nil {
+			t.Fatalf("tick: %v", err)
+		}
+		output += tick.TestOutput
+	}
+	if output != "Hi" {
+		t.Errorf("test port output = %q, want %q", output, "Hi")
+	}
+}
+
+func TestServiceTickReturnsDebugLines(t *testing.T) {
+	svc := NewService(t.TempDir())
+	defer svc.Shutdown()
+
+	src := `
+function Start()
+    lives := 3
+    debug.print("lives=", lives)
+    while true
+        wait_vblank()
+`
+	build, err := svc.BuildSource(src, "debugprint.corelx")
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if err := svc.LoadROMBytes(build.Result.ROMBytes); err != nil {
+		t.Fatalf("load rom: %v", err)
+	}
+
+	var lines []string
+	for i := 0; i < 3; i++ {
+		tick, err := svc.Tick(time.Second / 60)
+		if err != nil {
+			t.Fatalf("tick: %v", err)
+		}
+		lines = append(lines, tick.DebugLines...)
+	}
+	if len(lines) != 1 || lines[0] != "lives=3" {
+		t.Errorf("debug lines = %q, want [lives=3]", lines)
+	}
+}
+
+func TestServiceAudioHealthOnSteadyTone(t *testing.T) {
+	svc := NewService(t.TempDir())
+	defer svc.Shutdown()
+
+	src := `
+function Start()
+    apu.enable()
+    apu.set_channel_wave(0, 0)
+    apu.set_channel_freq(0, 440)
+    apu.set_channel_volume(0, 128)
+    apu.note_on(0)
+    apu.set_channel_wave(1, 1)
+    apu.set_channel_freq(1, 220)
+    apu.set_channel_volume(1, 96)
+    apu.note_on(1)
+    while true
+        wait_vblank()
+`
+	build, err := svc.BuildSource(src, "tone.corelx")
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if err := svc.LoadROMBytes(build.Result.ROMBytes); err != nil {
+		t.Fatalf("load rom: %v", err)
+	}
+	if err := svc.StepFrame(120); err != nil {
+		t.Fatalf("step: %v", err)
+	}
+	snap := svc.AudioMixerSnapshot(0)
+	if !snap.Health.OK() {
+		t.Fatalf("steady tone reported clicks: %+v", snap.Health)
+	}
+	peak := 0.0
+	for _, ch := range snap.Channels {
+		peak = max(peak, ch.Peak)
+	}
+	if peak == 0 {
+		t.Fatal("tone ROM produced no audio")
+	}
+
+	// A click: the output falls silent, then jumps with no note event.
+	svc.mu.Lock()
+	for _, level := range []int16{0, 0, 30000} {
+		for i := range svc.emu.AudioSampleBuffer {
+			svc.emu.AudioSampleBuffer[i] = level
+		}
+		svc.emu.AudioNoteSamples = nil
+		svc.feedAudioHealthLocked()
+	}
+	svc.mu.Unlock()
+	if h := svc.AudioMixerSnapshot(0).Health; h.Clicks != 1 || h.LastFrame != 119 {
+		t.Fatalf("injected jump: %+v", h)
+	}
+	if err := svc.ResetEmulator(); err != nil {
+		t.Fatal(err)
+	}
+	if h := svc.AudioMixerSnapshot(0).Health; !h.OK() {
+		t.Fatalf("health not cleared by reset: %+v", h)
+	}
+}
@@ -0,0 +1,260 @@
+package devkit
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"nitro-core-dx/internal/rom"
+)
+
+// ShareFileName is the project file describing a game for Package for
+// Sharing. It lives next to the main source file, like corelx.run.json.
+const ShareFileName = "corelx.share.json"
+
+// Files in a sharing package besides the ROM.
+const (
+	ShareScreenshotName = "screenshot.png"
+	ShareReadmeName     = "README.md"
+	ShareChecksumsName  = "SHA256SUMS"
+)
+
+// ShareInfo is what a shared game's README says about it. Every field is
+// optional.
+type ShareInfo struct {
+	FormatVersion int    `json:"format_version,omitempty"`
+	Title         string `json:"title,omitempty"` // default: the project's name
+	Author        string `json:"author,omitempty"`
+	Version       string `json:"version,omitempty"`
+	Description   string `json:"description,omitempty"`
+	// Controls says what each controller button does, by button name
+	// (up, down, left, right, a, b, x, y, l, r, start, z), e.g.
+	// {"a": "Jump", "start": "Pause"}.
+	Controls map[string]string `json:"controls,omitempty"`
+	// ScreenshotFrame is the frame the screenshot shows; 0 means
+	// DefaultGalleryFrames, past the default boot splash.
+	ScreenshotFrame int `json:"screenshot_frame,omitempty"`
+}
+
+// shareButtons lists the controller buttons in README order, with the
+// emulator window's default keys for them.
+var shareButtons = []struct {
+	name, label, keys string
+}{
+	{"up", "Up", "W / Up Arrow"},
+	{"down", "Down", "S / Down Arrow"},
+	{"left", "Left", "A / Left Arrow"},
+	{"right", "Right", "D / Right Arrow"},
+	{"a", "A", "Z"},
+	{"b", "B", "X"},
+	{"x", "X", "V"},
+	{"y", "Y", "C"},
+	{"l", "L", "Q"},
+	{"r", "R", "E"},
+	{"start", "Start", "Enter"},
+	{"z", "Z", "Backspace"},
+}
+
+// Validate checks the control button names and the screenshot frame.
+func (i ShareInfo) Validate() error {
+	for name := range i.Controls {
+		known := false
+		for _, b := range shareButtons {
+			known = known || b.name == strings.ToLower(name)
+		}
+		if !known {
+			return fmt.Errorf("controls: unknown button %q (want up, down, left, right, a, b, x, y, l, r, start, z)", name)
+		}
+	}
+	if i.ScreenshotFrame < 0 {
+		return fmt.Errorf("screenshot_frame must not be negative")
+	}
+	return nil
+}
+
+// LoadShareInfo reads dir's ShareFileName. A missing file yields an empty
+// ShareInfo.
+func LoadShareInfo(dir string) (ShareInfo, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ShareFileName))
+	if os.IsNotExist(err) {
+		return ShareInfo{}, nil
+	}
+	if err != nil {
+		return ShareInfo{}, err
+	}
+	var info ShareInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		return ShareInfo{}, fmt.Errorf("%s: %w", ShareFileName, err)
+	}
+	if err := info.Validate(); err != nil {
+		return ShareInfo{}, fmt.Errorf("%s: %w", ShareFileName, err)
+	}
+	return info, nil
+}
+
+// SharePackage describes a zip written by PackageForSharing.
+type SharePackage struct {
+	FileName  string // suggested name for the zip
+	Title     string
+	ROMName   string
+	ROMSHA256 string
+	Files     []string // in archive order
+}
+
+// PackageForSharing builds source with the release profile, runs the ROM
+// headlessly to info's screenshot frame, and writes a zip to w holding the
+// ROM, the screenshot, a README generated from info and a SHA256SUMS file.
+// The ROM gets its header checksum set and info's title and version as
+// metadata. The zip opens directly in the emulator.
+func (s *Service) PackageForSharing(w io.Writer, source, sourcePath string, info ShareInfo) (*SharePackage, error) {
+	if err := info.Validate(); err != nil {
+		return nil, err
+	}
+	// CheckSource builds in memory, so packaging leaves the last Build +
+	// Run's artifacts and fault symbols alone.
+	build, err := s.CheckSource(context.Background(), source, sourcePath, RunConfig{Profile: ProfileRelease})
+	if err != nil {
+		return nil, fmt.Errorf("release build: %w", err)
+	}
+	if build.Result == nil || len(build.Result.ROMBytes) == 0 {
+		return nil, fmt.Errorf("release build produced no ROM")
+	}
+	if info.Title == "" {
+		info.Title = exampleName(build.SourcePath)
+	}
+	frames := info.ScreenshotFrame
+	if frames == 0 {
+		frames = DefaultGalleryFrames
+	}
+
+	romBytes, err := stampSharedROM(build.Result.ROMBytes, info)
+	if err != nil {
+		return nil, err
+	}
+	fb, err := runHeadless(romBytes, frames)
+	if err != nil {
+		return nil, fmt.Errorf("screenshot: %w", err)
+	}
+	var shot bytes.Buffer
+	if err := encodeFramebufferPNG(&shot, fb); err != nil {
+		return nil, fmt.Errorf("screenshot: %w", err)
+	}
+
+	slug := gallerySlug(info.Title)
+	if slug == "" {
+		slug = "game"
+	}
+	pkg := &SharePackage{FileName: slug + ".zip", Title: info.Title, ROMName: slug + ".rom"}
+	files := []shareFile{
+		{pkg.ROMName, romBytes},
+		{ShareScreenshotName, shot.Bytes()},
+		{ShareReadmeName, shareReadme(info, pkg.ROMName)},
+	}
+	var sums strings.Builder
+	for _, f := range files {
+		sum := sha256.Sum256(f.data)
+		fmt.Fprintf(&sums, "%s  %s\n", hex.EncodeToString(sum[:]), f.name)
+	}
+	pkg.ROMSHA256 = strings.Fields(sums.String())[0]
+	files = append(files, shareFile{ShareChecksumsName, []byte(sums.String())})
+
+	zw := zip.NewWriter(w)
+	for _, f := range files {
+		fw, err := zw.Create(f.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := fw.Write(f.data); err != nil {
+			return nil, err
+		}
+		pkg.Files = append(pkg.Files, f.name)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return pkg, nil
+}
+
+type shareFile struct {
+	name string
+	data []byte
+}
+
+// stampSharedROM returns romBytes with info's title and version in its
+// metadata and the header checksum set.
+func stampSharedROM(romBytes []byte, info ShareInfo) ([]byte, error) {
+	ri, err := rom.Inspect(romBytes)
+	if err != nil {
+		return nil, err
+	}
+	md := rom.Metadata{}
+	for k, v := range ri.Metadata {
+		md[k] = v
+	}
+	md[rom.MetaTitle] = info.Title
+	if info.Version != "" {
+		md[rom.MetaVersion] = info.Version
+	}
+	out, err := rom.SetMetadata(romBytes, md)
+	if err != nil {
+		return nil, err
+	}
+	if err := rom.SetChecksum(out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// shareReadme renders a sharing package's README.
+func shareReadme(info ShareInfo, romName string) []byte {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s\n\n", info.Title)
+	if info.Description != "" {
+		sb.WriteString(strings.TrimSpace(info.Description) + "\n\n")
+	}
+	var byline []string
+	if info.Author != "" {
+		byline = append(byline, "By "+info.Author)
+	}
+	if info.Version != "" {
+		byline = append(byline, "Version "+info.Version)
+	}
+	if len(byline) > 0 {
+		sb.WriteString(strings.Join(byline, " · ") + "\n\n")
+	}
+	fmt.Fprintf(&sb, "![%s](%s)\n\n", info.Title, ShareScreenshotName)
+
+	sb.WriteString("## How to Play\n\n")
+	sb.WriteString("This is a game for the Nitro-Core-DX fantasy console. Drop this zip or\n")
+	fmt.Fprintf(&sb, "`%s` on the emulator window, or run:\n\n", romName)
+	fmt.Fprintf(&sb, "    nitro-core-dx -rom %s\n\n", romName)
+
+	sb.WriteString("## Controls\n\n")
+	if len(info.Controls) == 0 {
+		sb.WriteString("The controls are not listed.\n\n")
+	} else {
+		sb.WriteString("| Button | Keyboard | Action |\n|---|---|---|\n")
+		for _, b := range shareButtons {
+			for name, action := range info.Controls {
+				if strings.ToLower(name) == b.name {
+					fmt.Fprintf(&sb, "| %s | %s | %s |\n", b.label, b.keys, action)
+				}
+			}
+		}
+		sb.WriteString("\nThe keyboard column is the emulator's default mapping.\n\n")
+	}
+
+	sb.WriteString("## Checksums\n\n")
+	fmt.Fprintf(&sb, "`%s` lists the SHA-256 of the other files; check them with\n", ShareChecksumsName)
+	fmt.Fprintf(&sb, "`sha256sum -c %s`. The ROM's header checksum is set too, so\n", ShareChecksumsName)
+	sb.WriteString("`nitrotool info` reports it as ok.\n")
+	return []byte(sb.String())
+}
@@ -0,0 +1,98 @@
+package devkit
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"nitro-core-dx/internal/fileassoc"
+	"nitro-core-dx/internal/rom"
+)
+
+func TestPackageForSharing(t *testing.T) {
+	dir := t.TempDir()
+	svc := NewService(t.TempDir())
+	src := `
+function Start()
+    while true
+        wait_vblank()
+`
+	info := ShareInfo{
+		Title:           "Box Run!",
+		Author:          "Sam",
+		Version:         "1.2",
+		Controls:        map[string]string{"start": "Pause", "A": "Jump", "left": "Walk left"},
+		ScreenshotFrame: 10,
+	}
+	var buf bytes.Buffer
+	pkg, err := svc.PackageForSharing(&buf, src, filepath.Join(dir, "main.corelx"), info)
+	if err != nil {
+		t.Fatalf("PackageForSharing: %v", err)
+	}
+	if pkg.FileName != "box_run.zip" {
+		t.Errorf("FileName = %q, want box_run.zip", pkg.FileName)
+	}
+	want := []string{"box_run.rom", ShareScreenshotName, ShareReadmeName, ShareChecksumsName}
+	if strings.Join(pkg.Files, ",") != strings.Join(want, ",") {
+		t.Fatalf("files = %v, want %v", pkg.Files, want)
+	}
+
+	data := buf.Bytes()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(b)
+	}
+
+	ri, err := rom.Inspect([]byte(files["box_run.rom"]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ri.ChecksumStatus() != "ok" || ri.Metadata[rom.MetaTitle] != "Box Run!" || ri.Metadata[rom.MetaVersion] != "1.2" {
+		t.Errorf("ROM checksum %s, metadata %v; want ok, title and version", ri.ChecksumStatus(), ri.Metadata)
+	}
+	if !strings.HasPrefix(files[ShareScreenshotName], "\x89PNG") {
+		t.Errorf("screenshot is not a PNG")
+	}
+	readme := files[ShareReadmeName]
+	for _, s := range []string{"# Box Run!", "By Sam · Version 1.2", "| Left | A / Left Arrow | Walk left |", "| A | Z | Jump |"} {
+		if !strings.Contains(readme, s) {
+			t.Errorf("README missing %q:\n%s", s, readme)
+		}
+	}
+	if strings.Index(readme, "Walk left") > strings.Index(readme, "Pause") {
+		t.Errorf("README controls are not in button order:\n%s", readme)
+	}
+	if !strings.Contains(files[ShareChecksumsName], pkg.ROMSHA256+"  box_run.rom\n") {
+		t.Errorf("SHA256SUMS = %q, want the ROM's hash %s", files[ShareChecksumsName], pkg.ROMSHA256)
+	}
+
+	b, err := fileassoc.OpenBundle(data)
+	if err != nil || b.ROMName != "box_run.rom" {
+		t.Fatalf("the package does not open as a zipped game: %v", err)
+	}
+}
+
+func TestLoadShareInfo(t *testing.T) {
+	dir := t.TempDir()
+	if info, err := LoadShareInfo(dir); err != nil || info.Title != "" {
+		t.Fatalf("LoadShareInfo with no file = %+v, %v", info, err)
+	}
+	os.WriteFile(filepath.Join(dir, ShareFileName), []byte(`{"title": "T", "controls": {"jump": "A"}}`), 0644)
+	if _, err := LoadShareInfo(dir); err == nil || !strings.Contains(err.Error(), `unknown button "jump"`) {
+		t.Fatalf("LoadShareInfo accepted an unknown button: %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+package devkit
+
+import "fmt"
+
+// Step Back keeps a ring of savestates taken while the debugger steps a
+// paused ROM, each tagged with the step position it was taken at. A step
+// is one StepCPU instruction or one StepFrame frame. StepBack(n) restores
+// the newest checkpoint at or before position-n and re-executes the
+// instructions from there, so only a checkpoint every
+// stepCheckpointInterval instructions is needed.
+//
+// Between two checkpoints there is never anything but StepCPU
+// instructions: a frame, an immediate snippet or an edit made through the
+// Dev Kit forces a fresh checkpoint before the next instruction. Running
+// the ROM freely, resetting it or loading a state clears the history.
+const (
+	stepHistoryDepth       = 64
+	stepCheckpointInterval = 256
+)
+
+type stepCheckpoint struct {
+	state []byte
+	pos   uint64
+}
+
+// checkpointLocked records a checkpoint at the current step position when
+// the newest one is stale (see the comment above) or force is set.
+func (s *Service) checkpointLocked(force bool) error {
+	n := len(s.stepHistory)
+	if !force && !s.stepDirty && n > 0 && s.stepPos-s.stepHistory[n-1].pos < stepCheckpointInterval {
+		return nil
+	}
+	state, err := s.emu.SaveState()
+	if err != nil {
+		return fmt.Errorf("step back checkpoint: %w", err)
+	}
+	if n > 0 && s.stepHistory[n-1].pos == s.stepPos {
+		s.stepHistory[n-1].state = state
+	} else {
+		if n == stepHistoryDepth {
+			s.stepHistory = append(s.stepHistory[:0], s.stepHistory[1:]...)
+		}
+		s.stepHistory = append(s.stepHistory, stepCheckpoint{state: state, pos: s.stepPos})
+	}
+	s.stepDirty = false
+	return nil
+}
+
+// breakStepHistoryLocked notes that the emulator changed other than by
+// StepCPU, so the next instruction step starts a new checkpoint.
+func (s *Service) breakStepHistoryLocked() {
+	s.stepDirty = true
+}
+
+// clearStepHistoryLocked forgets every checkpoint.
+func (s *Service) clearStepHistoryLocked() {
+	s.stepHistory = nil
+	s.stepPos = 0
+	s.stepDirty = false
+}
+
+// StepBack rewinds the paused session by steps debugger steps (instructions
+// from StepCPU, frames from StepFrame), as far back as the history reaches.
+// It returns how many steps it actually went back.
+func (s *Service) StepBack(steps int) (int, error) {
+	if steps <= 0 {
+		return 0, fmt.Errorf("steps must be > 0")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.emu == nil {
+		return 0, fmt.Errorf("no ROM loaded")
+	}
+	if err := s.movieBusyErrorLocked(); err != nil {
+		return 0, err
+	}
+	if len(s.stepHistory) == 0 || s.stepPos == s.stepHistory[0].pos {
+		return 0, fmt.Errorf("no step history (step the paused ROM first)")
+	}
+
+	target := s.stepHistory[0].pos
+	if uint64(steps) < s.stepPos-target {
+		target = s.stepPos - uint64(steps)
+	}
+	i := len(s.stepHistory) - 1
+	for s.stepHistory[i].pos > target {
+		i--
+	}
+	cp := s.stepHistory[i]
+
+	paused := s.emu.Paused
+	if err := s.emu.LoadState(cp.state); err != nil {
+		return 0, err
+	}
+	s.emu.Paused = paused
+	s.tickAccumulator = 0
+	s.resetAudioHealthLocked(false)
+	s.stepHistory = s.stepHistory[:i+1]
+	s.stepDirty = false
+
+	from := s.stepPos
+	s.stepPos = cp.pos
+	for s.stepPos < target {
+		if err := s.emu.CPU.ExecuteInstruction(); err != nil {
+			return int(from - s.stepPos), err
+		}
+		s.stepPos++
+	}
+	return int(from - s.stepPos), nil
+}
+
+// StepBackAvailable is how many steps StepBack can currently undo.
+func (s *Service) StepBackAvailable() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.stepHistory) == 0 {
+		return 0
+	}
+	return int(s.stepPos - s.stepHistory[0].pos)
+}
@@ -0,0 +1,109 @@
+package devkit
+
+import "testing"
+
+type stepPoint struct {
+	pc   PCStateSnapshot
+	regs CPURegistersSnapshot
+}
+
+func captureStepPoint(svc *Service) stepPoint {
+	return stepPoint{pc: svc.GetPCState(), regs: svc.GetRegisters()}
+}
+
+func TestServiceStepBack(t *testing.T) {
+	svc := NewService(t.TempDir())
+	defer svc.Shutdown()
+
+	src := `
+function Start()
+    n := 0
+    while true
+        n = n + 1
+        wait_vblank()
+`
+	build, err := svc.BuildSource(src, "step_back.corelx")
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if err := svc.LoadROMBytes(build.Result.ROMBytes); err != nil {
+		t.Fatalf("load rom: %v", err)
+	}
+	if _, err := svc.TogglePause(); err != nil {
+		t.Fatalf("pause: %v", err)
+	}
+	if _, err := svc.StepBack(1); err == nil {
+		t.Fatal("StepBack with no history succeeded")
+	}
+
+	// Record the state after every instruction, across several checkpoint
+	// intervals.
+	const steps = 3*stepCheckpointInterval + 17
+	points := []stepPoint{captureStepPoint(svc)}
+	for i := 0; i < steps; i++ {
+		if err := svc.StepCPU(1); err != nil {
+			t.Fatalf("step %d: %v", i, err)
+		}
+		points = append(points, captureStepPoint(svc))
+	}
+
+	for _, back := range []int{1, 40, 300} {
+		before := svc.StepBackAvailable()
+		got, err := svc.StepBack(back)
+		if err != nil || got != back {
+			t.Fatalf("StepBack(%d) = %d, %v", back, got, err)
+		}
+		if svc.StepBackAvailable() != before-back {
+			t.Errorf("StepBackAvailable = %d after StepBack(%d), want %d", svc.StepBackAvailable(), back, before-back)
+		}
+		pos := svc.StepBackAvailable()
+		if p := captureStepPoint(svc); p != points[pos] {
+			t.Fatalf("after StepBack(%d): state %+v, want %+v", back, p, points[pos])
+		}
+	}
+
+	// Stepping on from a rewound point replays the same instructions.
+	pos := svc.StepBackAvailable()
+	if err := svc.StepCPU(5); err != nil {
+		t.Fatal(err)
+	}
+	if p := captureStepPoint(svc); p != points[pos+5] {
+		t.Fatalf("after stepping on: state %+v, want %+v", p, points[pos+5])
+	}
+
+	// A frame is one step; stepping back over it restores the state
+	// before it.
+	beforeFrame := captureStepPoint(svc)
+	if err := svc.StepFrame(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.StepCPU(10); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.StepBack(11); err != nil {
+		t.Fatal(err)
+	}
+	if p := captureStepPoint(svc); p != beforeFrame {
+		t.Fatalf("after stepping back over a frame: state %+v, want %+v", p, beforeFrame)
+	}
+	if !svc.Snapshot().Paused {
+		t.Error("StepBack unpaused the session")
+	}
+
+	// Asking for more than the history holds goes back to its start.
+	got, err := svc.StepBack(1 << 20)
+	if err != nil || got != pos+5 || captureStepPoint(svc) != points[0] {
+		t.Fatalf("StepBack past the start = %d, %v; state %+v", got, err, captureStepPoint(svc))
+	}
+
+	// Running freely forgets the history.
+	if err := svc.StepCPU(3); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.RunFrame(); err != nil {
+		t.Fatal(err)
+	}
+	if svc.StepBackAvailable() != 0 {
+		t.Errorf("StepBackAvailable = %d after RunFrame, want 0", svc.StepBackAvailable())
+	}
+}
@@ -0,0 +1,382 @@
+package emulator
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+
+	"nitro-core-dx/internal/ppu"
+)
+
+// BG8Image is a picture converted for an 8bpp background layer (see
+// ppu.BGFormatIndexed8 and ppu.BGFormatDirect8): deduplicated 8×8 tiles
+// loaded at VRAM 0x0000, a tilemap loaded at TilemapBase at the top of VRAM
+// and, for the indexed format, a palette loaded at CGRAM entry 0. A tile that
+// repeats another flipped is stored once and drawn through the tilemap
+// entry's flip bits.
+type BG8Image struct {
+	Format uint8 // ppu.BGFormatIndexed8 or ppu.BGFormatDirect8
+	// Width and Height are the picture's size in tiles.
+	Width, Height int
+	TilemapSize   uint8 // ppu.TilemapSize*
+	TilemapBase   uint16
+	Tiles         []byte   // 64 bytes per tile
+	Tilemap       []byte   // two bytes per entry, row-major
+	Palette       []uint16 // RGB555, indexed format only
+	Stats         BG8PackStats
+}
+
+// BG8PackStats reports what packing saved when BuildBG8ImageFromImage
+// converted a picture.
+type BG8PackStats struct {
+	Cells      int // tiles the picture covers
+	Duplicates int // cells that reuse an identical tile
+	Flipped    int // cells that reuse a tile flipped in X, Y or both
+	// SourceColors is how many colors the indexed conversion produced
+	// before unused and repeated ones were dropped from the palette.
+	SourceColors int
+}
+
+// SavedTiles returns how many tiles deduplication kept out of VRAM.
+func (s BG8PackStats) SavedTiles() int { return s.Duplicates + s.Flipped }
+
+// PackSummary describes b's tiles and palette and what packing saved, for
+// import reports.
+func (b *BG8Image) PackSummary() string {
+	st := b.Stats
+	out := fmt.Sprintf("%d cells -> %d unique tiles (%d repeated, %d flipped; %d bytes of VRAM saved)",
+		st.Cells, b.TileCount(), st.Duplicates, st.Flipped, st.SavedTiles()*64)
+	if b.Format == ppu.BGFormatIndexed8 {
+		out += fmt.Sprintf(", %d palette colors", len(b.Palette))
+		if st.SourceColors > len(b.Palette) {
+			out += fmt.Sprintf(" (reduced from %d)", st.SourceColors)
+		}
+	}
+	return out
+}
+
+// TileCount returns the number of unique tiles.
+func (b *BG8Image) TileCount() int { return len(b.Tiles) / 64 }
+
+// SourceMode returns the BGn_SOURCE_MODE value that selects the image's
+// format.
+func (b *BG8Image) SourceMode() uint8 { return b.Format << 1 }
+
+// BuildBG8ImageFromImage converts img to an 8bpp background in format. The
+// picture is drawn over black (backgrounds have no transparency) and padded
+// with black to whole tiles; tilemap cells outside it show a black tile.
+// The tilemap is the smallest size that covers the picture. It fails when the
+// tiles do not fit in VRAM below the tilemap.
+func BuildBG8ImageFromImage(img image.Image, format uint8) (*BG8Image, error) {
+	if img == nil {
+		return nil, fmt.Errorf("image is required")
+	}
+	if format != ppu.BGFormatIndexed8 && format != ppu.BGFormatDirect8 {
+		return nil, fmt.Errorf("format %d is not an 8bpp background format", format)
+	}
+	bounds := img.Bounds()
+	out := &BG8Image{
+		Format: format,
+		Width:  (bounds.Dx() + 7) / 8,
+		Height: (bounds.Dy() + 7) / 8,
+	}
+	side := max(out.Width, out.Height)
+	switch {
+	case side <= 32:
+		out.TilemapSize, side = ppu.TilemapSize32x32, 32
+	case side <= 64:
+		out.TilemapSize, side = ppu.TilemapSize64x64, 64
+	case side <= 128:
+		out.TilemapSize, side = ppu.TilemapSize128x128, 128
+	default:
+		return nil, fmt.Errorf("image is %dx%d pixels; an 8bpp background is at most 1024x1024", bounds.Dx(), bounds.Dy())
+	}
+	out.TilemapBase = uint16(0x10000 - side*side*2)
+
+	// Flatten onto black at the padded size.
+	flat := image.NewRGBA(image.Rect(0, 0, out.Width*8, out.Height*8))
+	draw.Draw(flat, flat.Bounds(), image.NewUniform(color.Black), image.Point{}, draw.Src)
+	draw.Draw(flat, bounds.Sub(bounds.Min), img, bounds.Min, draw.Over)
+
+	// pixels returns tile (tx, ty) as 64 pixel bytes plus its attribute bits.
+	var pixels func(tx, ty int) ([]byte, uint8)
+	var black []byte
+	if format == ppu.BGFormatIndexed8 {
+		palette, indexed := bg8Palette(flat)
+		out.Stats.SourceColors = len(palette)
+		palette, indexed = compactPalette(palette, indexed)
+		out.Palette = make([]uint16, len(palette))
+		for i, c := range palette {
+			out.Palette[i] = cgramOrder(c)
+		}
+		w := flat.Bounds().Dx()
+		pixels = func(tx, ty int) ([]byte, uint8) {
+			tile := make([]byte, 64)
+			for y := 0; y < 8; y++ {
+				copy(tile[y*8:y*8+8], indexed[(ty*8+y)*w+tx*8:])
+			}
+			return tile, 0
+		}
+		black = bytes.Repeat([]byte{nearestPaletteColor(palette, 0, 0, 0)}, 64)
+	} else {
+		pixels = func(tx, ty int) ([]byte, uint8) {
+			return directTile(flat, tx*8, ty*8)
+		}
+		black = make([]byte, 64)
+	}
+
+	// tileFor returns the index of tile, adding it unless it or a flipped
+	// copy is already stored, and the flip bits that draw it from there.
+	index := make(map[string]int)
+	tileFor := func(tile []byte, attr uint8) (int, uint8, error) {
+		key := string(tile) + string(rune(attr))
+		if n, ok := index[key]; ok {
+			return n, 0, nil
+		}
+		for _, flip := range []uint8{bg8FlipX, bg8FlipY, bg8FlipX | bg8FlipY} {
+			if n, ok := index[string(flipTile8(tile, flip))+string(rune(attr))]; ok {
+				return n, flip, nil
+			}
+		}
+		n := len(out.Tiles) / 64
+		if n >= 1024 {
+			return 0, 0, fmt.Errorf("%dx%d image needs more than 1024 unique tiles, even with repeated and flipped tiles shared; scale it toward the 320x200 screen or simplify it", bounds.Dx(), bounds.Dy())
+		}
+		index[key] = n
+		out.Tiles = append(out.Tiles, tile...)
+		return n, 0, nil
+	}
+	entry := func(n int, attr uint8) [2]byte {
+		return [2]byte{uint8(n), uint8(n>>8)&0x03 | attr}
+	}
+
+	out.Tilemap = make([]byte, side*side*2)
+	var blackEntry *[2]byte
+	for ty := 0; ty < side; ty++ {
+		for tx := 0; tx < side; tx++ {
+			var e [2]byte
+			if tx < out.Width && ty < out.Height {
+				tile, attr := pixels(tx, ty)
+				tiles := len(out.Tiles)
+				n, flip, err := tileFor(tile, attr)
+				if err != nil {
+					return nil, err
+				}
+				out.Stats.Cells++
+				switch {
+				case flip != 0:
+					out.Stats.Flipped++
+				case len(out.Tiles) == tiles:
+					out.Stats.Duplicates++
+				}
+				e = entry(n, attr|flip)
+			} else {
+				if blackEntry == nil {
+					n, flip, err := tileFor(black, 0)
+					if err != nil {
+						return nil, err
+					}
+					be := entry(n, flip)
+					blackEntry = &be
+				}
+				e = *blackEntry
+			}
+			copy(out.Tilemap[(ty*side+tx)*2:], e[:])
+		}
+	}
+
+	if len(out.Tiles) > int(out.TilemapBase) {
+		return nil, fmt.Errorf("%d unique tiles need %d bytes of VRAM; only %d fit below the %dx%d tilemap at 0x%04X",
+			out.TileCount(), len(out.Tiles), out.TilemapBase, side, side, out.TilemapBase)
+	}
+	return out, nil
+}
+
+// bg8Palette returns up to 256 RGB555 colors for img and each pixel's index:
+// the exact colors when there are few enough, otherwise a k-means reduction.
+func bg8Palette(img *image.RGBA) ([]uint16, []uint8) {
+	b := img.Bounds()
+	indexed := make([]uint8, b.Dx()*b.Dy())
+	lookup := make(map[uint16]uint8)
+	var palette []uint16
+	exact := true
+	for i := range indexed {
+		o := i * 4
+		c := rgbToRGB555(img.Pix[o], img.Pix[o+1], img.Pix[o+2])
+		n, ok := lookup[c]
+		if !ok {
+			if len(palette) == 256 {
+				exact = false
+				break
+			}
+			n = uint8(len(palette))
+			lookup[c] = n
+			palette = append(palette, c)
+		}
+		indexed[i] = n
+	}
+	if exact {
+		return palette, indexed
+	}
+	palette, indexed, _ = quantizeImage(img, 256, 4096)
+	return palette, indexed
+}
+
+// Tilemap entry attribute bits that flip a tile (see ppu.BGFormatIndexed8).
+const (
+	bg8FlipX uint8 = 0x10
+	bg8FlipY uint8 = 0x20
+)
+
+// flipTile8 returns the 64-byte 8×8 tile flipped by flip (bg8FlipX and/or
+// bg8FlipY).
+func flipTile8(tile []byte, flip uint8) []byte {
+	out := make([]byte, 64)
+	for y := 0; y < 8; y++ {
+		sy := y
+		if flip&bg8FlipY != 0 {
+			sy = 7 - y
+		}
+		for x := 0; x < 8; x++ {
+			sx := x
+			if flip&bg8FlipX != 0 {
+				sx = 7 - x
+			}
+			out[y*8+x] = tile[sy*8+sx]
+		}
+	}
+	return out
+}
+
+// compactPalette drops the colors no pixel uses and merges repeated ones
+// (a reduction can produce both), renumbering indexed to match. Colors keep
+// their order.
+func compactPalette(palette []uint16, indexed []uint8) ([]uint16, []uint8) {
+	used := make([]bool, len(palette))
+	for _, n := range indexed {
+		used[n] = true
+	}
+	remap := make([]uint8, len(palette))
+	first := make(map[uint16]uint8)
+	var out []uint16
+	for i, c := range palette {
+		if !used[i] {
+			continue
+		}
+		n, ok := first[c]
+		if !ok {
+			n = uint8(len(out))
+			first[c] = n
+			out = append(out, c)
+		}
+		remap[i] = n
+	}
+	if len(out) == len(palette) {
+		return palette, indexed
+	}
+	next := make([]uint8, len(indexed))
+	for i, n := range indexed {
+		next[i] = remap[n]
+	}
+	return out, next
+}
+
+// cgramOrder moves a color from rgbToRGB555's order (red in bits 4:0) to
+// CGRAM's (red in bits 14:10).
+func cgramOrder(c uint16) uint16 {
+	return (c&0x1F)<<10 | c&0x03E0 | (c>>10)&0x1F
+}
+
+// nearestPaletteColor returns the index of the palette color (in
+// rgbToRGB555's order) closest to (r, g, b).
+func nearestPaletteColor(palette []uint16, r, g, b uint8) uint8 {
+	want := imageSample{r: float64(r), g: float64(g), b: float64(b)}
+	best, bestDist := 0, -1.0
+	for i, c := range palette {
+		n := RGB555ToNRGBA(c)
+		d := want.distanceSquared(imageSample{r: float64(n.R), g: float64(n.G), b: float64(n.B)})
+		if bestDist < 0 || d < bestDist {
+			best, bestDist = i, d
+		}
+	}
+	return uint8(best)
+}
+
+// directTile converts the 8×8 tile at (x0, y0) to direct-color pixel bytes
+// (BBGGGRRR). The tile's blue bit 0, stored in the tilemap entry, follows
+// the majority of its pixels; the rest round to the nearest blue level with
+// that bit.
+func directTile(img *image.RGBA, x0, y0 int) ([]byte, uint8) {
+	level := func(v uint8) int { return (int(v)*7 + 127) / 255 }
+	odd := 0
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			odd += level(img.RGBAAt(x0+x, y0+y).B) & 1
+		}
+	}
+	blueLSB := 0
+	if odd*2 > 64 {
+		blueLSB = 1
+	}
+	tile := make([]byte, 64)
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			c := img.RGBAAt(x0+x, y0+y)
+			b := level(c.B)
+			if b&1 != blueLSB {
+				// Step to the neighbouring level that has the tile's bit.
+				lo, hi := b-1, b+1
+				if lo < 0 || (hi <= 7 && int(c.B)*7 > b*255) {
+					b = hi
+				} else {
+					b = lo
+				}
+			}
+			tile[y*8+x] = uint8(b>>1)<<6 | uint8(level(c.G))<<3 | uint8(level(c.R))
+		}
+	}
+	return tile, uint8(blueLSB) << 2
+}
+
+// CxAsset returns the image as a CoreLX external image asset (.cxasset)
+// named name: kind bg8_indexed or bg8_direct, the tiles followed by the
+// tilemap in one data block. comment, when set, becomes the first line.
+func (b *BG8Image) CxAsset(name, comment string) string {
+	var s strings.Builder
+	if comment != "" {
+		fmt.Fprintf(&s, "-- %s\n", comment)
+	}
+	kind := "bg8_indexed"
+	if b.Format == ppu.BGFormatDirect8 {
+		kind = "bg8_direct"
+	}
+	fmt.Fprintf(&s, "image %s:\n", name)
+	fmt.Fprintf(&s, "    kind: %s\n", kind)
+	side, _ := matrixPlaneWidth(b.TilemapSize)
+	fmt.Fprintf(&s, "    tilemap_size: %d\n", side)
+	fmt.Fprintf(&s, "    tilemap_base: 0x%04X\n", b.TilemapBase)
+	fmt.Fprintf(&s, "    tiles: %d\n", b.TileCount())
+	if len(b.Palette) > 0 {
+		s.WriteString("    palette: hex")
+		for _, c := range b.Palette {
+			fmt.Fprintf(&s, " %04x", c)
+		}
+		s.WriteString("\n")
+	}
+	s.WriteString("    data: hex\n")
+	data := append(append([]byte(nil), b.Tiles...), b.Tilemap...)
+	for i := 0; i < len(data); i += 32 {
+		s.WriteString("        ")
+		for j, v := range data[i:min(i+32, len(data))] {
+			if j > 0 {
+				s.WriteByte(' ')
+			}
+			fmt.Fprintf(&s, "%02x", v)
+		}
+		s.WriteString("\n")
+	}
+	return s.String()
+}
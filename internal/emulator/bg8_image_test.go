@@ -0,0 +1,189 @@
+package emulator
+
+import (
+	"image"
+	"image/color"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"nitro-core-dx/internal/hwdef"
+	ppucore "nitro-core-dx/internal/ppu"
+)
+
+// bg8TestPicture is a 320x200 title-screen-like picture: a 32-step color
+// ramp across the top rows and flat black below, so most tiles repeat.
+func bg8TestPicture() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 320, 200))
+	for y := 0; y < 200; y++ {
+		for x := 0; x < 320; x++ {
+			c := color.RGBA{A: 255}
+			if y < 16 {
+				c.R, c.G, c.B = uint8(x/10*8), uint8(255-x/10*8), 0x80
+			}
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+// renderBG8Image loads img onto BG0 the way bg.load_image does and renders a
+// frame.
+func renderBG8Image(t *testing.T, img *BG8Image) []uint32 {
+	t.Helper()
+	emu := NewEmulator()
+	if err := emu.LoadROM(spinROM(t)); err != nil {
+		t.Fatal(err)
+	}
+	copy(emu.PPU.VRAM[:], img.Tiles)
+	copy(emu.PPU.VRAM[img.TilemapBase:], img.Tilemap)
+	for i, c := range img.Palette {
+		emu.PPU.CGRAM[i*2] = uint8(c)
+		emu.PPU.CGRAM[i*2+1] = uint8(c >> 8)
+	}
+	emu.Bus.Write8(0, hwdef.BG0_TILEMAP_BASE_L, uint8(img.TilemapBase))
+	emu.Bus.Write8(0, hwdef.BG0_TILEMAP_BASE_H, uint8(img.TilemapBase>>8))
+	emu.Bus.Write8(0, hwdef.BG0_SOURCE_MODE, img.SourceMode())
+	emu.Bus.Write8(0, hwdef.BG0_CONTROL, 0x01|img.TilemapSize<<4)
+	emu.SetFrameLimit(false)
+	emu.Start()
+	if err := emu.RunFrame(); err != nil {
+		t.Fatal(err)
+	}
+	return emu.GetOutputBuffer()
+}
+
+func TestBuildBG8ImageIndexed(t *testing.T) {
+	img, err := BuildBG8ImageFromImage(bg8TestPicture(), ppucore.BGFormatIndexed8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Width != 40 || img.Height != 25 || img.TilemapSize != ppucore.TilemapSize64x64 || img.TilemapBase != 0xE000 {
+		t.Fatalf("layout = %dx%d tiles, size %d, base %04X", img.Width, img.Height, img.TilemapSize, img.TilemapBase)
+	}
+	// The ramp's two tile rows are the same 40 tiles; everything else,
+	// including the tilemap outside the picture, is one black tile.
+	if n := img.TileCount(); n != 41 {
+		t.Errorf("tile count = %d, want 41", n)
+	}
+	if len(img.Palette) != 33 {
+		t.Errorf("palette has %d colors, want the 33 exact ones", len(img.Palette))
+	}
+
+	out := renderBG8Image(t, img)
+	src := bg8TestPicture()
+	for _, p := range []image.Point{{0, 0}, {155, 7}, {319, 15}, {200, 100}} {
+		r, g, b, _ := src.At(p.X, p.Y).RGBA()
+		want := RGB555ToNRGBA(rgbToRGB555(uint8(r>>8), uint8(g>>8), uint8(b>>8)))
+		wantRGB := uint32(want.R)<<16 | uint32(want.G)<<8 | uint32(want.B)
+		if got := out[p.Y*320+p.X]; got != wantRGB {
+			t.Errorf("pixel %v = %06X, want %06X", p, got, wantRGB)
+		}
+	}
+}
+
+func TestBuildBG8ImageDirect(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 12, 8))
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 12; x++ {
+			src.SetRGBA(x, y, color.RGBA{R: 255, B: 255, A: 255})
+		}
+	}
+	img, err := BuildBG8ImageFromImage(src, ppucore.BGFormatDirect8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Palette != nil {
+		t.Error("direct format built a palette")
+	}
+	// A magenta tile; a tile that is half magenta, half black padding, whose
+	// blue bit 0 is clear because odd blue levels are not a majority; and the
+	// black tile for the rest of the map.
+	if n := img.TileCount(); n != 3 {
+		t.Fatalf("tile count = %d, want 3", n)
+	}
+	if img.Tiles[0] != 0xC7 || img.Tilemap[1]&0x04 == 0 {
+		t.Errorf("magenta pixel = %02X attr %02X, want C7 with blue bit 0", img.Tiles[0], img.Tilemap[1])
+	}
+	out := renderBG8Image(t, img)
+	if out[0] != 0xFF00FF || out[11] != 0xFF00DA || out[12] != 0 {
+		t.Errorf("rendered %06X %06X %06X, want FF00FF FF00DA 000000", out[0], out[11], out[12])
+	}
+}
+
+func TestBuildBG8ImageTooManyTiles(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 320, 200))
+	rng := rand.New(rand.NewSource(1))
+	rng.Read(src.Pix)
+	if _, err := BuildBG8ImageFromImage(src, ppucore.BGFormatDirect8); err == nil || !strings.Contains(err.Error(), "unique tiles") {
+		t.Fatalf("err = %v, want a VRAM budget error", err)
+	}
+}
+
+func TestBG8ImageCxAsset(t *testing.T) {
+	img, err := BuildBG8ImageFromImage(bg8TestPicture(), ppucore.BGFormatIndexed8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := img.CxAsset("Title", "generated")
+	for _, want := range []string{"-- generated\n", "image Title:\n", "kind: bg8_indexed\n", "tilemap_size: 64\n", "tilemap_base: 0xE000\n", "tiles: 41\n", "palette: hex "} {
+		if !strings.Contains(text, want) {
+			t.Errorf("asset text missing %q", want)
+		}
+	}
+}
+
+func TestBuildBG8ImageSharesFlippedTiles(t *testing.T) {
+	// A diagonal gradient tile followed by its X, Y and XY mirrors.
+	src := image.NewRGBA(image.Rect(0, 0, 32, 8))
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			c := color.RGBA{R: uint8(x * 32), G: uint8(y * 32), B: 0x40, A: 255}
+			src.SetRGBA(x, y, c)
+			src.SetRGBA(15-x, y, c)
+			src.SetRGBA(16+x, 7-y, c)
+			src.SetRGBA(31-x, 7-y, c)
+		}
+	}
+	for _, format := range []uint8{ppucore.BGFormatIndexed8, ppucore.BGFormatDirect8} {
+		img, err := BuildBG8ImageFromImage(src, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The gradient tile and the black tile for the rest of the map.
+		if n := img.TileCount(); n != 2 {
+			t.Errorf("format %d: tile count = %d, want 2", format, n)
+		}
+		if img.Stats.Cells != 4 || img.Stats.Flipped != 3 || img.Stats.SavedTiles() != 3 {
+			t.Errorf("format %d: stats = %+v, want 4 cells, 3 flipped", format, img.Stats)
+		}
+		for i, want := range []uint8{0, 0x10, 0x20, 0x30} {
+			if got := img.Tilemap[i*2+1] & 0x30; got != want {
+				t.Errorf("format %d: cell %d flip bits = %02X, want %02X", format, i, got, want)
+			}
+		}
+		out := renderBG8Image(t, img)
+		for _, p := range []image.Point{{1, 2}, {14, 2}, {17, 5}, {30, 5}} {
+			if out[p.Y*320+p.X] != out[2*320+1] {
+				t.Errorf("format %d: pixel %v = %06X, want the mirrored %06X", format, p, out[p.Y*320+p.X], out[2*320+1])
+			}
+		}
+		if !strings.Contains(img.PackSummary(), "4 cells -> 2 unique tiles (0 repeated, 3 flipped; 192 bytes of VRAM saved)") {
+			t.Errorf("format %d: summary = %q", format, img.PackSummary())
+		}
+	}
+}
+
+func TestCompactPalette(t *testing.T) {
+	palette := []uint16{0x1111, 0x2222, 0x1111, 0x3333}
+	indexed := []uint8{3, 2, 0, 3}
+	got, idx := compactPalette(palette, indexed)
+	if len(got) != 2 || got[0] != 0x1111 || got[1] != 0x3333 {
+		t.Fatalf("palette = %04X, want [1111 3333]", got)
+	}
+	for i, n := range idx {
+		if got[n] != palette[indexed[i]] {
+			t.Errorf("pixel %d = color %04X, want %04X", i, got[n], palette[indexed[i]])
+		}
+	}
+}
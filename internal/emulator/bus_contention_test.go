@@ -0,0 +1,136 @@
+package emulator
+
+import (
+	"testing"
+
+	"nitro-core-dx/internal/hwdef"
+	"nitro-core-dx/internal/rom"
+)
+
+// countingROM starts a DMA of dmaLength bytes (none if 0), then counts loop
+// iterations in R6 forever, reading the byte at bank 0 offset read in each.
+func countingROM(t *testing.T, read, dmaLength uint16) []byte {
+	t.Helper()
+	b := rom.NewROMBuilder()
+	pc := func() uint16 { return uint16(0x8000 + 2*b.GetCodeLength()) }
+	store8 := func(addr uint16, value uint16) {
+		b.AddInstruction(rom.EncodeMOV(1, 4, 0)) // MOV R4, #addr
+		b.AddImmediate(addr)
+		b.AddInstruction(rom.EncodeMOV(1, 5, 0)) // MOV R5, #value
+		b.AddImmediate(value)
+		b.AddInstruction(rom.EncodeMOV(7, 4, 5)) // MOV.B [R4], R5
+	}
+	if dmaLength > 0 {
+		store8(hwdef.DMA_LENGTH_L, dmaLength&0xFF)
+		store8(hwdef.DMA_LENGTH_H, dmaLength>>8)
+		store8(hwdef.DMA_CONTROL, 0x01)
+	}
+	b.AddInstruction(rom.EncodeMOV(1, 4, 0)) // MOV R4, #read
+	b.AddImmediate(read)
+	loop := pc()
+	b.AddInstruction(rom.EncodeMOV(6, 5, 4)) // loop: MOV.B R5, [R4]
+	b.AddInstruction(rom.EncodeADD(1, 6, 0)) // ADD R6, #1
+	b.AddImmediate(1)
+	b.AddInstruction(rom.EncodeJMP())
+	b.AddImmediate(uint16(rom.CalculateBranchOffset(pc(), loop)))
+	data, err := b.BuildROMBytes(1, 0x8000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// loopsInFirstFrame runs data for a frame and returns the loop count and
+// the CPU cycles the frame took.
+func loopsInFirstFrame(t *testing.T, data []byte, contention bool) (uint16, uint32) {
+	t.Helper()
+	emu := NewEmulator()
+	defer emu.Logger.Shutdown()
+	if err := emu.LoadROM(data); err != nil {
+		t.Fatal(err)
+	}
+	emu.SetFrameLimit(false)
+	emu.Bus.Contention = contention
+	emu.Start()
+	if err := emu.RunFrame(); err != nil {
+		t.Fatal(err)
+	}
+	return emu.CPU.State.R6, emu.GetCPUCyclesPerFrame()
+}
+
+func TestBusContentionSlowsTheCPU(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		read      uint16
+		dmaLength uint16
+	}{
+		{"PPU register reads", hwdef.FRAME_COUNTER_L, 0},
+		{"DMA", 0x0100, 0x4000},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			data := countingROM(t, tc.read, tc.dmaLength)
+			free, freeCycles := loopsInFirstFrame(t, data, false)
+			slow, slowCycles := loopsInFirstFrame(t, data, true)
+			t.Logf("%d loops free, %d with contention", free, slow)
+			if slow >= free {
+				t.Errorf("%d loops with contention, want fewer than the %d without", slow, free)
+			}
+			// The stalls are counted as CPU cycles, so the frame is as
+			// long either way, give or take where instructions straddle
+			// the clock's steps.
+			if diff := int64(slowCycles) - int64(freeCycles); diff < -256 || diff > 256 {
+				t.Errorf("CPU cycles per frame = %d with contention, %d without", slowCycles, freeCycles)
+			}
+		})
+	}
+}
+
+// A state saved while the CPU is halted for the bus resumes the halt.
+func TestSaveStateKeepsBusContentionHalt(t *testing.T) {
+	data := countingROM(t, 0x0100, 0)
+	start := func() *Emulator {
+		emu := NewEmulator()
+		if err := emu.LoadROM(data); err != nil {
+			t.Fatal(err)
+		}
+		emu.SetFrameLimit(false)
+		emu.Bus.Contention = true
+		emu.Start()
+		return emu
+	}
+	run := func(emu *Emulator, frames int) {
+		for i := 0; i < frames; i++ {
+			if err := emu.RunFrame(); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	emu := start()
+	defer emu.Logger.Shutdown()
+	run(emu, 1)
+	// A halt longer than a frame leaves the CPU stalled at the frame's end,
+	// and a second one waits on the bus for the CPU to take it.
+	emu.Bus.Halt(uint32(2 * emu.CyclesPerFrame))
+	run(emu, 1)
+	emu.Bus.Halt(5000)
+	if emu.CPU.State.Stall == 0 || emu.Bus.WaitCycles == 0 {
+		t.Fatalf("stall = %d, bus wait cycles = %d; want both pending", emu.CPU.State.Stall, emu.Bus.WaitCycles)
+	}
+	state, err := emu.SaveState()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restored := start()
+	defer restored.Logger.Shutdown()
+	if err := restored.LoadState(state); err != nil {
+		t.Fatal(err)
+	}
+	run(emu, 2)
+	run(restored, 2)
+	if restored.CPU.State.Cycles != emu.CPU.State.Cycles || restored.CPU.State.R6 != emu.CPU.State.R6 {
+		t.Errorf("after restoring: %d cycles, %d loops; want %d cycles, %d loops",
+			restored.CPU.State.Cycles, restored.CPU.State.R6, emu.CPU.State.Cycles, emu.CPU.State.R6)
+	}
+}
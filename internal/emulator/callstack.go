@@ -0,0 +1,67 @@
+package emulator
+
+import (
+	"fmt"
+
+	"nitro-core-dx/internal/cpu"
+	"nitro-core-dx/internal/debug"
+)
+
+// EnableCallStack makes the CPU maintain e.Debugger's call stack (creating
+// the Debugger if there is none) from every CALL, interrupt entry and RET.
+// Frames are named with syms, which may be nil. A power cycle, soft reset
+// or LoadState empties the stack, since the frames it held are gone.
+func (e *Emulator) EnableCallStack(syms SymbolTable) {
+	if e.Debugger == nil {
+		e.Debugger = debug.NewDebugger()
+	}
+	dbg := e.Debugger
+	e.CPU.CallHook = func(ev cpu.CallEvent) {
+		if ev.Kind == cpu.CallEventReturn {
+			dbg.ReturnTo(ev.SP)
+			return
+		}
+		dbg.EnterFrame(debug.CallFrame{
+			Bank:         ev.Bank,
+			Offset:       ev.Offset,
+			FunctionName: syms.Lookup(ev.Bank, ev.Offset),
+			ReturnBank:   ev.ReturnBank,
+			ReturnOffset: ev.ReturnOffset,
+			ReturnName:   syms.Lookup(ev.ReturnBank, ev.ReturnOffset),
+			SP:           ev.SP,
+			Interrupt:    ev.Kind == cpu.CallEventInterrupt,
+		})
+	}
+}
+
+// CallStack returns the call stack, outermost frame first, or nil when
+// EnableCallStack has not been called.
+func (e *Emulator) CallStack() []debug.CallFrame {
+	if e.CPU.CallHook == nil || e.Debugger == nil {
+		return nil
+	}
+	return e.Debugger.GetCallStack()
+}
+
+func (e *Emulator) clearCallStack() {
+	if e.Debugger != nil {
+		e.Debugger.ClearCallStack()
+	}
+}
+
+// FormatCallFrame renders a frame as "Update (01:8100), returns to
+// Start+0x26 (01:8040)", leaving out names that are not known.
+func FormatCallFrame(f debug.CallFrame) string {
+	s := formatCodeAddress(f.FunctionName, f.Bank, f.Offset)
+	if f.Interrupt {
+		s = "interrupt " + s
+	}
+	return s + ", returns to " + formatCodeAddress(f.ReturnName, f.ReturnBank, f.ReturnOffset)
+}
+
+func formatCodeAddress(name string, bank uint8, offset uint16) string {
+	if name == "" {
+		return fmt.Sprintf("%02X:%04X", bank, offset)
+	}
+	return fmt.Sprintf("%s (%02X:%04X)", name, bank, offset)
+}
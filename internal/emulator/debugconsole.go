@@ -0,0 +1,135 @@
+package emulator
+
+import (
+	"fmt"
+	"strings"
+
+	"nitro-core-dx/internal/hwdef"
+)
+
+const (
+	// DebugConsoleMaxLine is the longest line the debug console builds; a
+	// ROM that never writes a newline gets its text broken at this length.
+	DebugConsoleMaxLine = 256
+	// DebugConsoleMaxLines is how many finished lines are kept for the
+	// host; older ones are dropped when nobody takes them.
+	DebugConsoleMaxLines = 1024
+)
+
+// DebugConsole collects printf-style debug text a ROM writes through the
+// DEBUG_* registers (0xA040-0xA043) for the host to show:
+//
+//	DEBUG_CHAR       - append one character; '\n' ends the line
+//	DEBUG_VALUE_L/H  - a 16-bit value to print
+//	DEBUG_PRINT      - append DEBUG_VALUE in a hwdef.DebugPrint* format
+//
+// The registers are write-only and read 0. Like the persistence mailbox
+// they are not console hardware, but they are always mapped: on a real
+// console the writes go nowhere, so a ROM can leave its debug prints in.
+// The console is not reset by power cycles and is not part of save states.
+type DebugConsole struct {
+	value   uint16
+	line    []byte
+	lines   []string
+	dropped int
+}
+
+// NewDebugConsole returns an empty debug console.
+func NewDebugConsole() *DebugConsole {
+	return &DebugConsole{}
+}
+
+// TakeLines returns the lines finished since the previous call, oldest
+// first. If more than DebugConsoleMaxLines were finished, the oldest are
+// gone and a note saying how many leads the result.
+func (c *DebugConsole) TakeLines() []string {
+	lines := c.lines
+	if c.dropped > 0 {
+		lines = append([]string{fmt.Sprintf("(%d debug lines dropped)", c.dropped)}, lines...)
+	}
+	c.lines, c.dropped = nil, 0
+	return lines
+}
+
+// Pending returns the text of the line still being built.
+func (c *DebugConsole) Pending() string {
+	return string(c.line)
+}
+
+// Read8 reads a debug register; they are write-only and read 0.
+func (c *DebugConsole) Read8(offset uint16) uint8 {
+	return 0
+}
+
+// Write8 writes a debug register; offset is relative to hwdef.InputBase.
+func (c *DebugConsole) Write8(offset uint16, value uint8) {
+	switch offset + hwdef.InputBase {
+	case hwdef.DEBUG_CHAR:
+		c.writeChar(value)
+	case hwdef.DEBUG_VALUE_L:
+		c.value = (c.value & 0xFF00) | uint16(value)
+	case hwdef.DEBUG_VALUE_H:
+		c.value = (c.value & 0x00FF) | uint16(value)<<8
+	case hwdef.DEBUG_PRINT:
+		for _, ch := range []byte(FormatDebugValue(c.value, value)) {
+			c.writeChar(ch)
+		}
+	}
+}
+
+func (c *DebugConsole) writeChar(ch uint8) {
+	switch {
+	case ch == '\n':
+		c.endLine()
+	case ch == '\r':
+	default:
+		c.line = append(c.line, ch)
+		if len(c.line) >= DebugConsoleMaxLine {
+			c.endLine()
+		}
+	}
+}
+
+func (c *DebugConsole) endLine() {
+	c.lines = append(c.lines, string(c.line))
+	c.line = c.line[:0]
+	if over := len(c.lines) - DebugConsoleMaxLines; over > 0 {
+		c.lines = append(c.lines[:0], c.lines[over:]...)
+		c.dropped += over
+	}
+}
+
+// Read16 reads two consecutive debug registers.
+func (c *DebugConsole) Read16(offset uint16) uint16 {
+	return 0
+}
+
+// Write16 writes two consecutive debug registers.
+func (c *DebugConsole) Write16(offset uint16, value uint16) {
+	c.Write8(offset, uint8(value))
+	c.Write8(offset+1, uint8(value>>8))
+}
+
+// FormatDebugValue formats value the way DEBUG_PRINT does for the given
+// hwdef.DebugPrint* format. Unknown formats print as unsigned decimal.
+func FormatDebugValue(value uint16, format uint8) string {
+	switch format {
+	case hwdef.DebugPrintSigned:
+		return fmt.Sprint(int16(value))
+	case hwdef.DebugPrintHex:
+		return fmt.Sprintf("0x%04X", value)
+	case hwdef.DebugPrintFixed:
+		// Three places tell 8.8 steps (1/256) apart; 1.500 prints as 1.5.
+		s := strings.TrimRight(fmt.Sprintf("%.3f", float64(int16(value))/256), "0")
+		if strings.HasSuffix(s, ".") {
+			s += "0"
+		}
+		return s
+	case hwdef.DebugPrintBool:
+		if value != 0 {
+			return "true"
+		}
+		return "false"
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,68 @@
+package emulator
+
+import (
+	"strings"
+	"testing"
+
+	"nitro-core-dx/internal/hwdef"
+)
+
+func TestDebugConsoleLines(t *testing.T) {
+	emu := NewEmulator()
+	print := func(s string) {
+		for i := 0; i < len(s); i++ {
+			emu.Bus.Write8(0, hwdef.DEBUG_CHAR, s[i])
+		}
+	}
+	value := func(v uint16, format uint8) {
+		emu.Bus.Write16(0, hwdef.DEBUG_VALUE_L, v)
+		emu.Bus.Write8(0, hwdef.DEBUG_PRINT, format)
+	}
+
+	print("a=")
+	value(0xFFFE, hwdef.DebugPrintSigned)
+	print(" b=")
+	value(0xFFFE, hwdef.DebugPrintUnsigned)
+	print("\nc=")
+	value(0x0180, hwdef.DebugPrintFixed)
+	print(" ")
+	value(0xBEEF, hwdef.DebugPrintHex)
+	print("\npartial")
+
+	want := []string{"a=-2 b=65534", "c=1.5 0xBEEF"}
+	if got := emu.DebugConsole.TakeLines(); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("lines = %q, want %q", got, want)
+	}
+	if got := emu.DebugConsole.Pending(); got != "partial" {
+		t.Errorf("pending = %q, want %q", got, "partial")
+	}
+	if emu.Bus.Read8(0, hwdef.DEBUG_CHAR) != 0 {
+		t.Error("DEBUG_CHAR reads back a value")
+	}
+}
+
+func TestDebugConsoleLimits(t *testing.T) {
+	c := NewDebugConsole()
+	for i := 0; i < DebugConsoleMaxLine; i++ {
+		c.Write8(hwdef.DEBUG_CHAR-hwdef.InputBase, 'x')
+	}
+	if lines := c.TakeLines(); len(lines) != 1 || len(lines[0]) != DebugConsoleMaxLine {
+		t.Fatalf("long line not broken at %d: %d lines", DebugConsoleMaxLine, len(lines))
+	}
+
+	for i := 0; i < DebugConsoleMaxLines+3; i++ {
+		c.Write8(hwdef.DEBUG_CHAR-hwdef.InputBase, '\n')
+	}
+	lines := c.TakeLines()
+	if len(lines) != DebugConsoleMaxLines+1 || lines[0] != "(3 debug lines dropped)" {
+		t.Errorf("got %d lines starting %q, want the newest %d after a drop note", len(lines), lines[0], DebugConsoleMaxLines)
+	}
+}
+
+func TestFormatDebugValueFixed(t *testing.T) {
+	for value, want := range map[uint16]string{0x0100: "1.0", 0xFF80: "-0.5", 0x0040: "0.25", 0x0001: "0.004", 0: "0.0"} {
+		if got := FormatDebugValue(value, hwdef.DebugPrintFixed); got != want {
+			t.Errorf("FormatDebugValue(%#04x, fixed) = %q, want %q", value, got, want)
+		}
+	}
+}
@@ -84,6 +84,7 @@ func NewDeterminismHarness(useDebugMode bool) (*DeterminismHarness, error) {
 	logger := debug.NewLogger(10000)
 
 	emu := NewEmulatorWithLogger(logger)
+	emu.SetDeterministic(true)
 	h := &DeterminismHarness{
 		Emulator:      emu,
 		FrameStates:   make([]FrameState, 0),
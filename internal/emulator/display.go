@@ -0,0 +1,85 @@
+package emulator
+
+import "fmt"
+
+// Limits on DisplayOptions.
+const (
+	MaxDisplayBorder = 64
+	MaxDisplayCrop   = 32
+)
+
+// DisplayOptions shape the picture a host presents. The 320x200 active
+// area (GetOutputBuffer) is unchanged; hosts that honor the options draw
+// DisplayFrame instead.
+type DisplayOptions struct {
+	// Crop trims this many pixels from every edge of the active area, as a
+	// TV's overscan would hide them.
+	Crop int
+	// Border surrounds what is left with this many pixels of the ROM's
+	// BORDER_COLOR on every side, like the frame outside a classic
+	// console's picture.
+	Border int
+}
+
+// Validate reports an option outside its range.
+func (o DisplayOptions) Validate() error {
+	if o.Crop < 0 || o.Crop > MaxDisplayCrop {
+		return fmt.Errorf("crop %d out of range 0-%d", o.Crop, MaxDisplayCrop)
+	}
+	if o.Border < 0 || o.Border > MaxDisplayBorder {
+		return fmt.Errorf("border %d out of range 0-%d", o.Border, MaxDisplayBorder)
+	}
+	return nil
+}
+
+// Size is the width and height of the frames DisplayFrame produces.
+func (o DisplayOptions) Size() (w, h int) {
+	return 320 - 2*o.Crop + 2*o.Border, 200 - 2*o.Crop + 2*o.Border
+}
+
+// SetDisplayOptions changes how DisplayFrame frames the picture.
+func (e *Emulator) SetDisplayOptions(o DisplayOptions) error {
+	if err := o.Validate(); err != nil {
+		return err
+	}
+	e.display = o
+	return nil
+}
+
+// DisplayOptions returns the options set with SetDisplayOptions.
+func (e *Emulator) DisplayOptions() DisplayOptions {
+	return e.display
+}
+
+// DisplayFrame renders the last finished frame with the display options
+// applied into dst (grown as needed) and returns it with its size. The
+// border takes the BORDER_COLOR latched with that frame. Active
+// notifications (Notify) are drawn over the result.
+func (e *Emulator) DisplayFrame(dst []uint32) ([]uint32, int, int) {
+	o := e.display
+	w, h := o.Size()
+	if cap(dst) < w*h {
+		dst = make([]uint32, w*h)
+	}
+	dst = dst[:w*h]
+	src := e.PPU.DisplayBuffer[:]
+	border := e.PPU.DisplayBorder
+	inner := 320 - 2*o.Crop
+	for y := 0; y < h; y++ {
+		row := dst[y*w : (y+1)*w]
+		sy := y - o.Border + o.Crop
+		if sy < o.Crop || sy >= 200-o.Crop {
+			for x := range row {
+				row[x] = border
+			}
+			continue
+		}
+		for x := 0; x < o.Border; x++ {
+			row[x] = border
+			row[w-1-x] = border
+		}
+		copy(row[o.Border:o.Border+inner], src[sy*320+o.Crop:])
+	}
+	e.OSD.Draw(dst, w, h)
+	return dst, w, h
+}
@@ -0,0 +1,51 @@
+package emulator
+
+import (
+	"testing"
+
+	"nitro-core-dx/internal/hwdef"
+)
+
+// TestDisplayFrameCropsAndBorders sets BORDER_COLOR through the bus, runs a
+// frame to latch it, then checks a cropped, bordered frame's size, border
+// and where the active area lands.
+func TestDisplayFrameCropsAndBorders(t *testing.T) {
+	emu := NewEmulator()
+	if err := emu.LoadROM(spinROM(t)); err != nil {
+		t.Fatal(err)
+	}
+	emu.Deterministic = true
+	emu.Start()
+	emu.PPU.CGRAM[2*0x13], emu.PPU.CGRAM[2*0x13+1] = 0x1F, 0x00 // palette 1 color 3 = blue
+	emu.Bus.Write8(0, hwdef.BORDER_COLOR, 0x13)
+	if got := emu.Bus.Read8(0, hwdef.BORDER_COLOR); got != 0x13 {
+		t.Fatalf("BORDER_COLOR reads 0x%02X", got)
+	}
+	if err := emu.RunFrame(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := emu.SetDisplayOptions(DisplayOptions{Crop: 100}); err == nil {
+		t.Error("crop 100 accepted")
+	}
+	if err := emu.SetDisplayOptions(DisplayOptions{Crop: 8, Border: 16}); err != nil {
+		t.Fatal(err)
+	}
+	emu.PPU.DisplayBuffer[8*320+8] = 0xABCDEF // first pixel left after cropping
+	frame, w, h := emu.DisplayFrame(nil)
+	if w != 336 || h != 216 || len(frame) != w*h {
+		t.Fatalf("frame is %dx%d (%d pixels), want 336x216", w, h, len(frame))
+	}
+	blue := emu.PPU.DisplayBorder
+	if blue == 0 {
+		t.Fatal("border color not latched")
+	}
+	for _, i := range []int{0, 15*w + 100, 16*w + 15, 16*w + w - 1, (h-1)*w + w - 1} {
+		if frame[i] != blue {
+			t.Errorf("pixel %d = 0x%06X, want the border 0x%06X", i, frame[i], blue)
+		}
+	}
+	if got := frame[16*w+16]; got != 0xABCDEF {
+		t.Errorf("active area starts with 0x%06X, want 0xABCDEF", got)
+	}
+}
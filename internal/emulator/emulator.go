@@ -8,6 +8,7 @@ import (
 	"nitro-core-dx/internal/clock"
 	"nitro-core-dx/internal/cpu"
 	"nitro-core-dx/internal/debug"
+	"nitro-core-dx/internal/hwdef"
 	"nitro-core-dx/internal/input"
 	"nitro-core-dx/internal/memory"
 	"nitro-core-dx/internal/ppu"
@@ -30,10 +31,19 @@ type Emulator struct {
 
 	// Frame timing (for compatibility with host systems)
 	FrameLimitEnabled bool
+	FramePacing       FramePacing // how the frame limiter waits
 	TargetFPS         float64
 	FrameTime         time.Duration
 	LastFrameTime     time.Time
 
+	// Deterministic disables every wall-clock interaction in RunFrame: no
+	// frame-limit sleeping and no measured FPS (FPS reports TargetFPS).
+	// Emulated state already advances by exactly CyclesPerFrame per frame;
+	// this mode guarantees the host loop cannot perturb it either. Used by
+	// tests, record/replay, and lockstep (netplay-style) drivers. Host
+	// timing statistics (FrameStats) are still observed; they never feed back.
+	Deterministic bool
+
 	// Performance tracking
 	FPS               float64
 	FrameCount        uint64 // Total emulated frames since start/reset.
@@ -41,21 +51,81 @@ type Emulator struct {
 	FPSUpdateTime     time.Time
 	CPUCyclesPerFrame uint32
 	LastCPUCycles     uint32
-	CyclesPerFrame    uint64 // 79,200 cycles per frame (220 scanlines × 360 dots)
+	CyclesPerFrame    uint64 // 127,820 cycles at 60 Hz, 153,384 at 50 Hz (see SetTiming)
+	frameStats        frameStats
 
 	// State
 	Running bool
 	Paused  bool
 
-	// Audio samples buffer (for host adapter)
+	// Audio samples buffer (for host adapter): one frame, 735 samples at
+	// 60 Hz and 882 at 50 Hz
 	AudioSampleBuffer []int16
 	AudioSampleIndex  int
 
+	// AudioFloatBuffer holds the last frame mixed natively in float32 when
+	// float mixing is enabled (see SetFloatAudioMixing); nil otherwise.
+	// AudioSampleBuffer is always filled and is the canonical output.
+	AudioFloatBuffer []float32
+
+	// AudioNoteSamples lists, in order, the indexes into AudioSampleBuffer
+	// of the samples generated first after an APU note event (see
+	// apu.APU.NoteEvents) this frame.
+	AudioNoteSamples []int
+	audioNoteMark    uint64
+
 	// Cycle logger (for debugging)
 	CycleLogger *debug.CycleLogger
 
 	// Debugger (for interactive debugging)
 	Debugger *debug.Debugger
+
+	// Trace records host-time performance events, or is nil (see
+	// EnableTrace).
+	Trace *debug.TraceRecorder
+
+	// Persist is the host-side key/value store behind the PERSIST_*
+	// mailbox, or nil when persistence is disabled (see EnablePersistence).
+	Persist *PersistStore
+
+	// TestPort is the TEST_* port test ROMs report through, or nil outside
+	// test mode (see EnableTestMode).
+	TestPort *TestPort
+
+	// DebugConsole collects the text the ROM prints through the DEBUG_*
+	// registers (debug.print in CoreLX). Frontends take it with TakeLines.
+	DebugConsole *DebugConsole
+
+	// OSD holds the on-screen notifications DisplayFrame draws over the
+	// presented picture (see Notify).
+	OSD Notifications
+
+	// romImage is the ROM file last loaded, which keys the Persist store.
+	romImage []uint8
+
+	// timingOverride, when set, replaces the ROM header's timing mode (see
+	// SetTimingOverride).
+	timingOverride *ppu.Timing
+
+	// Uninitialized-read check state (see EnableUninitReadCheck).
+	uninitReads []UninitRead
+	uninitSeen  map[uint32]bool
+
+	// Startup-state fuzz mode (see EnableStartupFuzz).
+	fuzz startupFuzz
+
+	// VBlank watchdog state (see SetVBlankWatchdog).
+	vblankWatch vblankWatch
+	vblankStall *VBlankStall
+
+	// Last-write history (see EnableWriteTracking), nil when off.
+	writes *writeTracker
+
+	// Frame post-processing (see AddFramePlugin).
+	framePlugins []framePlugin
+
+	// Overscan crop and border for hosts (see SetDisplayOptions).
+	display DisplayOptions
 }
 
 // NewEmulator creates a new clock-driven emulator instance
@@ -106,6 +176,14 @@ func NewEmulatorWithLogger(logger *debug.Logger) *Emulator {
 		return bus.Read8(bank, offset)
 	}
 
+	// DMA halts the CPU for its transfer when the bus models contention.
+	ppu.HaltCPU = bus.Halt
+
+	// The sample channel fetches its PCM bytes over the bus.
+	apu.MemoryReader = func(bank uint8, offset uint16) uint8 {
+		return bus.Read8(bank, offset)
+	}
+
 	// Initialize interrupt/reset vectors in memory (bank 0, 0xFFE0-0xFFE3).
 	// Default vectors point to the ROM entry point and may be overridden by a ROM.
 	bus.InitSystemVectors()
@@ -152,7 +230,10 @@ func NewEmulatorWithLogger(logger *debug.Logger) *Emulator {
 		Paused:            false,
 		AudioSampleBuffer: make([]int16, 735), // 735 samples per frame
 		AudioSampleIndex:  0,
+		DebugConsole:      NewDebugConsole(),
 	}
+	bus.DebugHandler = emu.DebugConsole
+	emu.SetVBlankWatchdog(true)
 
 	return emu
 }
@@ -193,6 +274,21 @@ func (e *Emulator) LoadROM(data []uint8) error {
 		return fmt.Errorf("failed to set entry point: PCBank is %d, expected %d", e.CPU.State.PCBank, bank)
 	}
 
+	// A bad save file must not stop the game from booting; the store
+	// keeps the error for the frontend (PersistStore.Err).
+	e.romImage = data
+	if e.timingOverride != nil {
+		e.SetTiming(*e.timingOverride)
+	} else {
+		e.SetTiming(headerTiming(data))
+	}
+	if e.Persist != nil {
+		_ = e.Persist.Open(data)
+	}
+	if e.TestPort != nil {
+		e.TestPort.Reset()
+	}
+
 	return nil
 }
 
@@ -202,6 +298,7 @@ func (e *Emulator) RunFrame() error {
 	if !e.Running || e.Paused {
 		return nil
 	}
+	frameStart := time.Now()
 
 	// LEGACY (scaffolding): frame-level updates for the 4-channel synth's
 	// duration countdown / completion flags, once per emulated frame. Separate
@@ -218,11 +315,14 @@ func (e *Emulator) RunFrame() error {
 	// PPU renders dot-by-dot, scanline-by-scanline, matching hardware timing exactly
 
 	// Generate audio samples during frame execution
-	// At 44,100 Hz sample rate and 60 FPS, we need 735 samples per frame
+	// At 44,100 Hz sample rate we need 735 samples per frame at 60 FPS and
+	// 882 at 50 FPS: one per len(AudioSampleBuffer)
 	// Use fractional accumulator for accurate timing (matches scheduler)
 	// Exact cycles per sample: 7670000 / 44100 ≈ 173.923 cycles per sample
 	exactCyclesPerSampleFixed := (uint64(7670000) << 32) / uint64(44100) // Fixed-point: 32-bit fractional part
 	samplesGenerated := 0
+	frameSamples := len(e.AudioSampleBuffer)
+	e.AudioNoteSamples = e.AudioNoteSamples[:0]
 
 	// Use scheduler-driven execution for both debug and optimized modes
 	// This ensures CPU, PPU, and APU always advance on the same cycle timeline
@@ -238,6 +338,9 @@ func (e *Emulator) RunFrame() error {
 			if err != nil {
 				return fmt.Errorf("clock step error: %w", err)
 			}
+			if err := e.takeVBlankStall(); err != nil {
+				return err
+			}
 
 			// Log cycle state
 			snapshot := &debug.CPUStateSnapshot{
@@ -262,13 +365,10 @@ func (e *Emulator) RunFrame() error {
 			// Generate audio sample when it's time (using fractional accumulator)
 			// Calculate expected sample count using fractional arithmetic
 			expectedSamplesFixed := (cyclesStepped * exactCyclesPerSampleFixed) >> 32
-			if expectedSamplesFixed > uint64(samplesGenerated) && samplesGenerated < 735 {
+			if expectedSamplesFixed > uint64(samplesGenerated) && samplesGenerated < frameSamples {
 				// Generate samples up to expected count
-				for samplesGenerated < int(expectedSamplesFixed) && samplesGenerated < 735 {
-					sampleFixed := e.APU.GenerateSampleFixed()
-					if samplesGenerated < len(e.AudioSampleBuffer) {
-						e.AudioSampleBuffer[samplesGenerated] = sampleFixed
-					}
+				for samplesGenerated < int(expectedSamplesFixed) && samplesGenerated < frameSamples {
+					e.generateAudioSample(samplesGenerated)
 					samplesGenerated++
 				}
 			}
@@ -287,11 +387,23 @@ func (e *Emulator) RunFrame() error {
 				chunk = cyclesRemaining
 			}
 
+			var sliceStart time.Time
+			sliceBank, sliceOffset := e.CPU.State.PCBank, e.CPU.State.PCOffset
+			if e.Trace != nil {
+				sliceStart = time.Now()
+			}
+
 			// Use scheduler to step all components together
 			// The scheduler handles APU stepping internally based on sample rate
 			if err := e.Clock.StepCycles(chunk); err != nil {
 				return fmt.Errorf("clock step cycles error: %w", err)
 			}
+			if err := e.takeVBlankStall(); err != nil {
+				return err
+			}
+			if e.Trace != nil {
+				e.traceCPUSlice(sliceStart, chunk, sliceBank, sliceOffset)
+			}
 
 			cyclesRemaining -= chunk
 
@@ -303,26 +415,20 @@ func (e *Emulator) RunFrame() error {
 			// Calculate expected sample count using fractional arithmetic
 			// This matches the scheduler's fractional accumulator approach
 			expectedSamplesFixed := (cyclesElapsed * exactCyclesPerSampleFixed) >> 32
-			if expectedSamplesFixed > 735 {
-				expectedSamplesFixed = 735
+			if expectedSamplesFixed > uint64(frameSamples) {
+				expectedSamplesFixed = uint64(frameSamples)
 			}
 
 			// Generate any missing samples
-			for samplesGenerated < int(expectedSamplesFixed) && samplesGenerated < 735 {
-				sampleFixed := e.APU.GenerateSampleFixed()
-				if samplesGenerated < len(e.AudioSampleBuffer) {
-					e.AudioSampleBuffer[samplesGenerated] = sampleFixed
-				}
+			for samplesGenerated < int(expectedSamplesFixed) && samplesGenerated < frameSamples {
+				e.generateAudioSample(samplesGenerated)
 				samplesGenerated++
 			}
 		}
 
-		// Generate any remaining audio samples (should be exactly 735 total)
-		for samplesGenerated < 735 {
-			sampleFixed := e.APU.GenerateSampleFixed()
-			if samplesGenerated < len(e.AudioSampleBuffer) {
-				e.AudioSampleBuffer[samplesGenerated] = sampleFixed
-			}
+		// Generate any remaining audio samples (should be exactly frameSamples total)
+		for samplesGenerated < frameSamples {
+			e.generateAudioSample(samplesGenerated)
 			samplesGenerated++
 		}
 	}
@@ -336,6 +442,18 @@ func (e *Emulator) RunFrame() error {
 	// Update FPS counter
 	e.FrameCount++
 	e.fpsFrameCount++
+	if len(e.framePlugins) > 0 {
+		e.runFramePlugins()
+	}
+	e.frameStats.record(frameStart, time.Since(frameStart))
+	if e.Trace != nil {
+		e.traceFrame(frameStart)
+	}
+	if e.Deterministic {
+		e.FPS = e.TargetFPS
+		e.fpsFrameCount = 0
+		return nil
+	}
 	now := time.Now()
 	if now.Sub(e.FPSUpdateTime) >= time.Second {
 		e.FPS = float64(e.fpsFrameCount) / now.Sub(e.FPSUpdateTime).Seconds()
@@ -345,11 +463,7 @@ func (e *Emulator) RunFrame() error {
 
 	// Frame limiting
 	if e.FrameLimitEnabled {
-		elapsed := now.Sub(e.LastFrameTime)
-		if elapsed < e.FrameTime {
-			time.Sleep(e.FrameTime - elapsed)
-		}
-		e.LastFrameTime = time.Now()
+		e.LastFrameTime = realClock.waitUntil(e.FramePacing, e.LastFrameTime.Add(e.FrameTime))
 	} else {
 		e.LastFrameTime = time.Now()
 	}
@@ -357,6 +471,48 @@ func (e *Emulator) RunFrame() error {
 	return nil
 }
 
+// generateAudioSample produces output sample idx of the current frame into
+// AudioSampleBuffer and, when float mixing is enabled, AudioFloatBuffer.
+func (e *Emulator) generateAudioSample(idx int) {
+	if n := e.APU.NoteEvents(); n != e.audioNoteMark {
+		e.audioNoteMark = n
+		e.AudioNoteSamples = append(e.AudioNoteSamples, idx)
+	}
+	if e.AudioFloatBuffer != nil {
+		fixed, f := e.APU.GenerateSampleFixedFloat()
+		if idx < len(e.AudioSampleBuffer) {
+			e.AudioSampleBuffer[idx] = fixed
+		}
+		if idx < len(e.AudioFloatBuffer) {
+			e.AudioFloatBuffer[idx] = f
+		}
+		return
+	}
+	sampleFixed := e.APU.GenerateSampleFixed()
+	if idx < len(e.AudioSampleBuffer) {
+		e.AudioSampleBuffer[idx] = sampleFixed
+	}
+}
+
+// SetFloatAudioMixing enables or disables the native float mixing path. When
+// enabled, each frame is additionally mixed straight to AudioFloatBuffer so
+// float hosts need no per-sample conversion. Emulated state and
+// AudioSampleBuffer are identical either way.
+func (e *Emulator) SetFloatAudioMixing(enabled bool) {
+	if !enabled {
+		e.AudioFloatBuffer = nil
+		return
+	}
+	if len(e.AudioFloatBuffer) != len(e.AudioSampleBuffer) {
+		e.AudioFloatBuffer = apu.ConvertFrame(e.AudioSampleBuffer)
+	}
+}
+
+// FloatAudioMixing reports whether the native float mixing path is enabled.
+func (e *Emulator) FloatAudioMixing() bool {
+	return e.AudioFloatBuffer != nil
+}
+
 // Start starts (powers on) the emulator. It does not clear state, so it can
 // resume a machine that was only paused as well as boot a freshly reset one.
 func (e *Emulator) Start() {
@@ -392,6 +548,12 @@ func (e *Emulator) Resume() {
 // machine can be powered back on. Callers set Running/Paused as appropriate.
 func (e *Emulator) powerOff() {
 	e.Bus.ClearRAM()
+	e.Bus.ResetCause = hwdef.ResetCausePowerOn
+	// PPU registers, video memory and the controller latches return to
+	// their power-on values, exactly as a newly created machine's, so
+	// runs from a power cycle are reproducible (see harness movies).
+	e.loadPPUState(ppuStateOf(ppu.NewPPU(nil)))
+	e.loadInputState(InputState{})
 	e.PPU.ClearScreen()
 	e.CPU.Reset()
 	e.Clock.Reset()
@@ -401,44 +563,77 @@ func (e *Emulator) powerOff() {
 	e.FPS = 0
 	e.FPSUpdateTime = time.Now()
 	e.AudioSampleIndex = 0
+	e.frameStats.reset()
 }
 
-// Reset restarts the machine: a full power-off immediately followed by a
-// power-on. All volatile state is cleared (as in Stop), the boot vectors and
-// ROM entry point are re-established, and execution resumes running.
+// Reset power-cycles the machine (a hard reset): a full power-off immediately
+// followed by a power-on. All volatile state is cleared (as in Stop), work RAM
+// and VRAM come up holding the RAM pattern (see SetRAMPattern) or the startup
+// fuzz mode's random state (see EnableStartupFuzz), the boot vectors and ROM
+// entry point are re-established, and execution resumes running.
+// RESET_CAUSE reads ResetCauseHard.
 func (e *Emulator) Reset() {
 	e.powerOff()
+	e.Bus.FillRAMPattern()
+	e.Bus.RAMPattern.Fill(e.PPU.VRAM[:])
+	e.nextStartupFuzz()
+	e.clearWriteHistory()
+	e.clearCallStack()
+	e.Bus.ResetCause = hwdef.ResetCauseHard
 	e.Bus.InitSystemVectors()
 	e.Running = true
 	e.Paused = false
-	if e.Cartridge.HasROM() {
-		bank, offset, err := e.Cartridge.GetROMEntryPoint()
-		if err != nil {
-			if e.Logger != nil {
-				e.Logger.LogSystem(debug.LogLevelError, fmt.Sprintf("Failed to get ROM entry point during reset: %v", err), nil)
-			}
-			return
+	e.restartAtEntryPoint()
+}
+
+// SoftReset presses the reset button: the CPU registers are cleared and
+// execution restarts at the ROM entry point, but work RAM, VRAM and the rest
+// of the PPU state, the system vectors and the frame counter all survive.
+// Audio is silenced. RESET_CAUSE reads ResetCauseSoft so a ROM can keep its
+// progress in RAM across the reset.
+func (e *Emulator) SoftReset() {
+	e.CPU.Reset()
+	e.clearCallStack()
+	e.APU.Silence()
+	e.Bus.ResetCause = hwdef.ResetCauseSoft
+	e.Running = true
+	e.Paused = false
+	e.restartAtEntryPoint()
+}
+
+// restartAtEntryPoint points the CPU at the cartridge's ROM entry point,
+// logging (rather than returning) a bad entry point since resets have no
+// caller to report to.
+func (e *Emulator) restartAtEntryPoint() {
+	if !e.Cartridge.HasROM() {
+		return
+	}
+	bank, offset, err := e.Cartridge.GetROMEntryPoint()
+	if err != nil {
+		if e.Logger != nil {
+			e.Logger.LogSystem(debug.LogLevelError, fmt.Sprintf("Failed to get ROM entry point during reset: %v", err), nil)
 		}
-		if bank == 0 {
-			if e.Logger != nil {
-				e.Logger.LogSystem(debug.LogLevelError, "Invalid ROM entry point: bank is 0 (expected bank 1-125, got 0). ROM code must be located in bank 1 or higher.", nil)
-			}
-			return
+		return
+	}
+	if bank == 0 {
+		if e.Logger != nil {
+			e.Logger.LogSystem(debug.LogLevelError, "Invalid ROM entry point: bank is 0 (expected bank 1-125, got 0). ROM code must be located in bank 1 or higher.", nil)
 		}
-		if bank > 125 {
-			if e.Logger != nil {
-				e.Logger.LogSystem(debug.LogLevelError, fmt.Sprintf("Invalid ROM entry point: bank %d (expected bank 1-125, got %d). ROM banks are limited to 1-125.", bank, bank), nil)
-			}
-			return
+		return
+	}
+	if bank > 125 {
+		if e.Logger != nil {
+			e.Logger.LogSystem(debug.LogLevelError, fmt.Sprintf("Invalid ROM entry point: bank %d (expected bank 1-125, got %d). ROM banks are limited to 1-125.", bank, bank), nil)
 		}
-		if offset < 0x8000 {
-			if e.Logger != nil {
-				e.Logger.LogSystem(debug.LogLevelError, fmt.Sprintf("Invalid ROM entry point: offset 0x%04X (expected offset 0x8000-0xFFFF, got 0x%04X). ROM code must start at offset 0x8000 or higher.", offset, offset), nil)
-			}
-			return
+		return
+	}
+	if offset < 0x8000 {
+		if e.Logger != nil {
+			e.Logger.LogSystem(debug.LogLevelError, fmt.Sprintf("Invalid ROM entry point: offset 0x%04X (expected offset 0x8000-0xFFFF, got 0x%04X). ROM code must start at offset 0x8000 or higher.", offset, offset), nil)
 		}
-		e.CPU.SetEntryPoint(bank, offset)
+		return
 	}
+	e.CPU.SetEntryPoint(bank, offset)
 }
 
 // SetFrameLimit sets the frame limit mode
@@ -446,6 +641,24 @@ func (e *Emulator) SetFrameLimit(enabled bool) {
 	e.FrameLimitEnabled = enabled
 }
 
+// SetFramePacing sets how the frame limiter waits between frames.
+// PacingVSync has no display to follow here and waits as PacingHybrid.
+func (e *Emulator) SetFramePacing(p FramePacing) {
+	e.FramePacing = p
+}
+
+// SetDeterministic switches fixed-timestep deterministic mode on or off at
+// runtime. It only changes host-side timing behavior, never emulated state,
+// so it can be toggled between any two frames.
+func (e *Emulator) SetDeterministic(enabled bool) {
+	e.Deterministic = enabled
+	if !enabled {
+		e.fpsFrameCount = 0
+		e.FPSUpdateTime = time.Now()
+		e.LastFrameTime = time.Now()
+	}
+}
+
 // GetFPS returns the current FPS
 func (e *Emulator) GetFPS() float64 {
 	return e.FPS
@@ -456,6 +669,12 @@ func (e *Emulator) GetCPUCyclesPerFrame() uint32 {
 	return e.CPUCyclesPerFrame
 }
 
+// ROMImage returns the ROM file last loaded with LoadROM, or nil. Callers
+// must not modify it.
+func (e *Emulator) ROMImage() []byte {
+	return e.romImage
+}
+
 // GetOutputBuffer returns the PPU display buffer (front buffer with text overlay)
 func (e *Emulator) GetOutputBuffer() []uint32 {
 	return e.PPU.DisplayBuffer[:]
@@ -466,12 +685,25 @@ func (e *Emulator) SetInputButtons(buttons uint16) {
 	e.Input.Controller1Buttons = buttons
 }
 
-// GetAudioSamples returns the audio samples from the last frame
+// GetAudioSamples returns the audio samples from the last frame as a new
+// float32 slice. Hosts that run every frame should use AudioSamplesFloatInto
+// to avoid the allocation.
 func (e *Emulator) GetAudioSamples() []float32 {
-	// Convert buffered fixed-point samples to float32
-	samples := make([]float32, len(e.AudioSampleBuffer))
-	for i, sampleFixed := range e.AudioSampleBuffer {
-		samples[i] = apu.ConvertFixedToFloat(sampleFixed)
+	return e.AudioSamplesFloatInto(nil)
+}
+
+// AudioSamplesFloatInto writes the last frame's samples as float32 into dst
+// (reused when large enough) and returns it. With float mixing enabled this
+// is a copy of AudioFloatBuffer; otherwise the fixed-point frame is batch
+// converted.
+func (e *Emulator) AudioSamplesFloatInto(dst []float32) []float32 {
+	if e.AudioFloatBuffer != nil {
+		if cap(dst) < len(e.AudioFloatBuffer) {
+			dst = make([]float32, len(e.AudioFloatBuffer))
+		}
+		dst = dst[:len(e.AudioFloatBuffer)]
+		copy(dst, e.AudioFloatBuffer)
+		return dst
 	}
-	return samples
+	return apu.ConvertFrameInto(dst, e.AudioSampleBuffer)
 }
@@ -10,6 +10,8 @@ import (
 	"time"
 
 	"nitro-core-dx/internal/apu"
+	"nitro-core-dx/internal/hwdef"
+	"nitro-core-dx/internal/memory"
 )
 
 // TestResetReloadsEntryPoint tests that emulator.Reset() reloads entry point correctly
@@ -67,6 +69,49 @@ func TestResetReloadsEntryPoint(t *testing.T) {
 	}
 }
 
+func TestSoftResetKeepsRAMAndPowerCycleDoesNot(t *testing.T) {
+	emu := NewEmulator()
+	emu.SetRAMPattern(memory.RAMPattern{Kind: memory.RAMPatternAlternating})
+	if err := emu.LoadROM(persistTestROM(1)); err != nil {
+		t.Fatal(err)
+	}
+	if got := emu.Bus.Read8(0, hwdef.RESET_CAUSE); got != hwdef.ResetCausePowerOn {
+		t.Fatalf("RESET_CAUSE after load = %d, want power-on", got)
+	}
+	emu.Bus.Write8(0, 0x0100, 0x42)
+	emu.PPU.VRAM[0x10] = 0x99
+	emu.CPU.State.R3 = 7
+	emu.CPU.State.PCOffset = 0x9000
+
+	emu.SoftReset()
+	if got := emu.Bus.Read8(0, 0x0100); got != 0x42 {
+		t.Errorf("WRAM after soft reset = 0x%02X, want 0x42", got)
+	}
+	if emu.PPU.VRAM[0x10] != 0x99 {
+		t.Errorf("VRAM cleared by soft reset")
+	}
+	if emu.CPU.State.R3 != 0 || emu.CPU.State.PCOffset != 0x8000 {
+		t.Errorf("CPU after soft reset: R3=%d PC=0x%04X, want 0 and 0x8000", emu.CPU.State.R3, emu.CPU.State.PCOffset)
+	}
+	if got := emu.Bus.Read8(0, hwdef.RESET_CAUSE); got != hwdef.ResetCauseSoft {
+		t.Errorf("RESET_CAUSE after soft reset = %d, want %d", got, hwdef.ResetCauseSoft)
+	}
+
+	emu.Reset()
+	if got := emu.Bus.Read16(0, 0x0100); got != 0x5AA5 {
+		t.Errorf("WRAM after power cycle = 0x%04X, want pattern 0x5AA5", got)
+	}
+	if got := emu.Bus.Read8(0, hwdef.RESET_CAUSE); got != hwdef.ResetCauseHard {
+		t.Errorf("RESET_CAUSE after power cycle = %d, want %d", got, hwdef.ResetCauseHard)
+	}
+
+	emu.Stop()
+	emu.Start()
+	if got := emu.Bus.Read8(0, hwdef.RESET_CAUSE); got != hwdef.ResetCausePowerOn {
+		t.Errorf("RESET_CAUSE after stop/start = %d, want power-on", got)
+	}
+}
+
 func TestFMExtensionProgrammingThroughEmulatorSmoke(t *testing.T) {
 	t.Setenv("NCDX_YM_BACKEND", "ymfm")
 	emu := NewEmulator()
@@ -140,6 +185,65 @@ func TestRunFrameUsesFixedPointAPUPath(t *testing.T) {
 	}
 }
 
+func TestFloatAudioMixingMatchesFixedFrame(t *testing.T) {
+	newEmu := func() *Emulator {
+		emu := NewEmulator()
+		romData := make([]uint8, 64)
+		copy(romData, []uint8{0x52, 0x4D, 0x43, 0x46, 0x01, 0x00, 0x20})
+		romData[10] = 0x01
+		romData[13] = 0x80
+		romData[35] = 0xD0 // NOP; JMP back to it
+		romData[36] = 0xFA
+		romData[37] = 0xFF
+		if err := emu.LoadROM(romData); err != nil {
+			t.Fatalf("Failed to load ROM: %v", err)
+		}
+		emu.Start()
+		emu.SetFrameLimit(false)
+		emu.APU.Write8(0x00, 0xB8)
+		emu.APU.Write8(0x01, 0x01)
+		emu.APU.Write8(0x02, 0xC0)
+		emu.APU.Write8(0x03, 0x03)
+		emu.APU.MasterVolume = 0x90
+		return emu
+	}
+
+	fixed := newEmu()
+	float := newEmu()
+	float.SetFloatAudioMixing(true)
+	if !float.FloatAudioMixing() {
+		t.Fatal("expected float mixing enabled")
+	}
+
+	var out []float32
+	for frame := 0; frame < 3; frame++ {
+		if err := fixed.RunFrame(); err != nil {
+			t.Fatalf("fixed RunFrame: %v", err)
+		}
+		if err := float.RunFrame(); err != nil {
+			t.Fatalf("float RunFrame: %v", err)
+		}
+		for i := range fixed.AudioSampleBuffer {
+			if fixed.AudioSampleBuffer[i] != float.AudioSampleBuffer[i] {
+				t.Fatalf("frame %d sample %d: float mixing changed fixed output (%d vs %d)",
+					frame, i, fixed.AudioSampleBuffer[i], float.AudioSampleBuffer[i])
+			}
+		}
+		out = float.AudioSamplesFloatInto(out)
+		for i, f := range out {
+			want := apu.ConvertFixedToFloat(fixed.AudioSampleBuffer[i])
+			if math.Abs(float64(f-want)) > 1.0/32768.0 {
+				t.Fatalf("frame %d sample %d: float mix %v, fixed %v", frame, i, f, want)
+			}
+		}
+	}
+
+	float.SetFloatAudioMixing(false)
+	if float.AudioFloatBuffer != nil {
+		t.Fatal("expected float buffer released when float mixing disabled")
+	}
+}
+
 func TestFrameCountRemainsMonotonicAcrossFPSUpdates(t *testing.T) {
 	emu := NewEmulator()
 
@@ -182,6 +286,44 @@ func TestFrameCountRemainsMonotonicAcrossFPSUpdates(t *testing.T) {
 	}
 }
 
+func TestDeterministicModeIgnoresWallClock(t *testing.T) {
+	emu := NewEmulator()
+
+	romData := make([]uint8, 64)
+	copy(romData, []uint8{0x52, 0x4D, 0x43, 0x46, 0x01, 0x00, 0x20})
+	romData[10] = 0x01
+	romData[13] = 0x80
+	if err := emu.LoadROM(romData); err != nil {
+		t.Fatalf("Failed to load ROM: %v", err)
+	}
+	emu.Start()
+	emu.Clock.CPUStep = func(cycles uint64) error {
+		emu.CPU.State.Cycles += uint32(cycles)
+		return nil
+	}
+
+	// Frame limiting stays enabled: deterministic mode must still never sleep.
+	emu.SetDeterministic(true)
+	emu.LastFrameTime = time.Now().Add(time.Hour)
+	emu.FPSUpdateTime = time.Now().Add(-2 * time.Second)
+
+	start := time.Now()
+	for i := 0; i < 30; i++ {
+		if err := emu.RunFrame(); err != nil {
+			t.Fatalf("RunFrame %d failed: %v", i, err)
+		}
+		if emu.GetFPS() != emu.TargetFPS {
+			t.Fatalf("frame %d: FPS = %v, want nominal %v", i, emu.GetFPS(), emu.TargetFPS)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("deterministic frames appear to be wall-clock paced: %v for 30 frames", elapsed)
+	}
+	if emu.FrameCount != 30 {
+		t.Fatalf("FrameCount = %d, want 30", emu.FrameCount)
+	}
+}
+
 // framebufferChecksum returns a SHA256 hex of the display buffer (used to detect when frame content changes).
 func framebufferChecksum(buf []uint32) string {
 	raw := make([]byte, len(buf)*4)
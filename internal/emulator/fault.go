@@ -0,0 +1,308 @@
+package emulator
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"runtime/debug"
+	"sort"
+	"strings"
+
+	"nitro-core-dx/internal/clock"
+	"nitro-core-dx/internal/cpu"
+	"nitro-core-dx/internal/rom"
+)
+
+// Symbol names a code address, e.g. a CoreLX function's entry point.
+type Symbol struct {
+	Name   string
+	Bank   uint8
+	Offset uint16
+}
+
+// SymbolTable resolves code addresses to the nearest preceding symbol.
+type SymbolTable []Symbol
+
+// NewSymbolTable returns syms sorted by address.
+func NewSymbolTable(syms []Symbol) SymbolTable {
+	t := append(SymbolTable(nil), syms...)
+	sort.Slice(t, func(i, j int) bool {
+		if t[i].Bank != t[j].Bank {
+			return t[i].Bank < t[j].Bank
+		}
+		return t[i].Offset < t[j].Offset
+	})
+	return t
+}
+
+// Lookup renders bank:offset as "name" or "name+0x12" using the closest
+// symbol at or before it in the same bank, or "" when there is none.
+func (t SymbolTable) Lookup(bank uint8, offset uint16) string {
+	sym, ok := t.containing(bank, offset)
+	if !ok {
+		return ""
+	}
+	if d := offset - sym.Offset; d != 0 {
+		return fmt.Sprintf("%s+0x%X", sym.Name, d)
+	}
+	return sym.Name
+}
+
+// containing returns the closest symbol at or before bank:offset in the
+// same bank.
+func (t SymbolTable) containing(bank uint8, offset uint16) (Symbol, bool) {
+	i := sort.Search(len(t), func(i int) bool {
+		return t[i].Bank > bank || t[i].Bank == bank && t[i].Offset > offset
+	}) - 1
+	if i < 0 || t[i].Bank != bank {
+		return Symbol{}, false
+	}
+	return t[i], true
+}
+
+// CodeLine is one disassembled instruction.
+type CodeLine struct {
+	Bank   uint8
+	Offset uint16
+	Text   string // disassembly, "??" when the address cannot be read
+	Symbol string
+}
+
+func (l CodeLine) String() string {
+	s := fmt.Sprintf("%02X:%04X  %-22s", l.Bank, l.Offset, l.Text)
+	if l.Symbol != "" {
+		s += "  ; " + l.Symbol
+	}
+	return strings.TrimRight(s, " ")
+}
+
+// Fault is an emulation failure (an error from RunFrame or a recovered
+// panic) with the machine state at the time. *Fault is an error.
+type Fault struct {
+	// Component is the part that failed: CPU, PPU, APU, Memory, Input, or
+	// Emulator when it cannot be narrowed down.
+	Component string
+	Message   string
+	Panic     bool   // a Go panic rather than an emulated error
+	GoStack   string // the panicking goroutine's stack, for panics
+	Frame     uint64
+	// PCBank:PCOffset is the instruction that was executing: the start of
+	// the faulting instruction rather than CPU.PC, which may have moved past
+	// its operands.
+	PCBank   uint8
+	PCOffset uint16
+	CPU      cpu.CPUState
+	Symbol   string // symbolized PC, when a symbol table was given
+	// Detail is extra diagnosis shown in the report, such as the
+	// disassembly of a stuck VBlank wait loop.
+	Detail string
+	// Trace is the last instructions started, oldest first; the last one
+	// is the faulting instruction.
+	Trace []CodeLine
+}
+
+func (f *Fault) Error() string {
+	where := fmt.Sprintf("%02X:%04X", f.PCBank, f.PCOffset)
+	if f.Symbol != "" {
+		where += " (" + f.Symbol + ")"
+	}
+	kind := "fault"
+	if f.Panic {
+		kind = "panic"
+	}
+	return fmt.Sprintf("%s %s at PC %s, frame %d: %s", f.Component, kind, where, f.Frame, f.Message)
+}
+
+// Report is the full multi-line description: summary, registers, trace and
+// (for panics) the Go stack, suitable for pasting into a bug report.
+func (f *Fault) Report() string {
+	var sb strings.Builder
+	sb.WriteString(f.Error())
+	if f.Detail != "" {
+		sb.WriteString("\n\n" + strings.TrimRight(f.Detail, "\n"))
+	}
+	sb.WriteString("\n\nRegisters:\n")
+	c := f.CPU
+	fmt.Fprintf(&sb, "  R0:%04X  R1:%04X  R2:%04X  R3:%04X\n", c.R0, c.R1, c.R2, c.R3)
+	fmt.Fprintf(&sb, "  R4:%04X  R5:%04X  R6:%04X  R7:%04X\n", c.R4, c.R5, c.R6, c.R7)
+	fmt.Fprintf(&sb, "  PC:%02X:%04X  PBR:%02X  DBR:%02X  SP:%04X  Flags:%02X  Cycles:%d\n",
+		c.PCBank, c.PCOffset, c.PBR, c.DBR, c.SP, c.Flags, c.Cycles)
+	if len(f.Trace) > 0 {
+		sb.WriteString("\nLast instructions (oldest first):\n")
+		for _, l := range f.Trace {
+			sb.WriteString("  " + l.String() + "\n")
+		}
+	}
+	if f.GoStack != "" {
+		sb.WriteString("\nGo stack:\n")
+		sb.WriteString(f.GoStack)
+	}
+	return sb.String()
+}
+
+// RunFrameGuarded is RunFrame for hosts that must survive a broken ROM or
+// an emulator bug: an error or a panic comes back as a *Fault describing
+// the machine state, symbolized with syms (which may be nil).
+func (e *Emulator) RunFrameGuarded(syms SymbolTable) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			stack := string(debug.Stack())
+			f := e.newFault(fmt.Sprint(r), panicComponent(stack), syms)
+			f.Panic = true
+			f.GoStack = stack
+			err = f
+		}
+	}()
+	if err := e.RunFrame(); err != nil {
+		component := "Emulator"
+		var stepErr *clock.StepError
+		if errors.As(err, &stepErr) {
+			component = stepErr.Component
+			err = stepErr.Err
+		}
+		var stall *VBlankStall
+		if errors.As(err, &stall) {
+			f := e.newFault(err.Error(), "CPU", syms)
+			f.Detail = "VBlank wait loop:\n" + stall.Listing()
+			return f
+		}
+		return e.newFault(err.Error(), component, syms)
+	}
+	return nil
+}
+
+func (e *Emulator) newFault(msg, component string, syms SymbolTable) *Fault {
+	f := &Fault{
+		Component: component,
+		Message:   msg,
+		Frame:     e.FrameCount,
+		PCBank:    e.CPU.State.PCBank,
+		PCOffset:  e.CPU.State.PCOffset,
+		CPU:       e.CPU.State,
+	}
+	pcs := e.CPU.RecentPCs()
+	if len(pcs) > 0 {
+		last := pcs[len(pcs)-1]
+		f.PCBank, f.PCOffset = uint8(last>>16), uint16(last)
+	}
+	f.Symbol = syms.Lookup(f.PCBank, f.PCOffset)
+	for _, pc := range pcs {
+		inst, _ := e.DecodeAt(uint8(pc>>16), uint16(pc))
+		f.Trace = append(f.Trace, codeLine(inst, syms))
+	}
+	return f
+}
+
+func codeLine(inst rom.Instruction, syms SymbolTable) CodeLine {
+	return CodeLine{
+		Bank:   inst.Bank,
+		Offset: inst.Offset,
+		Text:   inst.Text,
+		Symbol: syms.Lookup(inst.Bank, inst.Offset),
+	}
+}
+
+// disassemblyAnchorWindow is how far back DisassembleAround looks for the
+// containing symbol before falling back to guessing an instruction
+// boundary.
+const disassemblyAnchorWindow = 0x400
+
+// DisassembleAround decodes up to before instructions ahead of bank:offset,
+// the instruction there, and up to after instructions following it. The
+// lines before are decoded forward from the containing symbol when it is
+// near, so they are exact; otherwise from the furthest start that lands on
+// offset, which is a best guess since immediates can look like opcodes.
+func (e *Emulator) DisassembleAround(bank uint8, offset uint16, before, after int, syms SymbolTable) []CodeLine {
+	var lines []CodeLine
+	if sym, ok := syms.containing(bank, offset); ok && offset-sym.Offset <= disassemblyAnchorWindow {
+		lines = e.decodeRun(bank, sym.Offset, offset, syms)
+	}
+	lowest := rom.ROMBankOffsetBase // ROM code starts at 0x8000 in its bank
+	if bank == 0 || bank >= 126 {
+		lowest = 0
+	}
+	for back := before * 4; lines == nil && back > 0; back -= 2 {
+		if int(offset)-back >= lowest {
+			lines = e.decodeRun(bank, offset-uint16(back), offset, syms)
+		}
+	}
+	if len(lines) > before {
+		lines = lines[len(lines)-before:]
+	}
+	pc := offset
+	for i := 0; i <= after; i++ {
+		inst, ok := e.DecodeAt(bank, pc)
+		lines = append(lines, codeLine(inst, syms))
+		if !ok {
+			break
+		}
+		pc += uint16(2 * len(inst.Words))
+	}
+	return lines
+}
+
+// decodeRun decodes from start up to (not including) end, or returns nil
+// when decoding does not land exactly on end.
+func (e *Emulator) decodeRun(bank uint8, start, end uint16, syms SymbolTable) []CodeLine {
+	lines := []CodeLine{}
+	for pc := start; pc != end; {
+		if pc > end {
+			return nil
+		}
+		inst, ok := e.DecodeAt(bank, pc)
+		if !ok {
+			return nil
+		}
+		lines = append(lines, codeLine(inst, syms))
+		pc += uint16(2 * len(inst.Words))
+	}
+	return lines
+}
+
+// DecodeAt decodes the instruction at bank:offset without bus side effects
+// (no open-bus or uninitialized-read updates, no I/O reads). ok is false,
+// and Text is "??", when the address is not ROM or work RAM.
+func (e *Emulator) DecodeAt(bank uint8, offset uint16) (inst rom.Instruction, ok bool) {
+	var b [4]uint8
+	n := 0
+	for ; n < len(b); n++ {
+		v, ok := e.peekCode(bank, offset+uint16(n))
+		if !ok {
+			break
+		}
+		b[n] = v
+	}
+	if n < 2 {
+		return rom.Instruction{Bank: bank, Offset: offset, Text: "??"}, false
+	}
+	word := uint16(b[0]) | uint16(b[1])<<8
+	imm := uint16(b[2]) | uint16(b[3])<<8
+	return rom.DecodeInstruction(bank, offset, word, imm, n == 4), true
+}
+
+func (e *Emulator) peekCode(bank uint8, offset uint16) (uint8, bool) {
+	if e.Cartridge != nil && e.Cartridge.Maps(bank, offset) {
+		return e.Cartridge.Read8(bank, offset), true
+	}
+	return e.PeekMemory(MemoryWRAM, uint32(bank)<<16|uint32(offset))
+}
+
+// panicFrame matches the first emulator-package frame of a Go stack.
+var panicFrame = regexp.MustCompile(`nitro-core-dx/internal/(cpu|ppu|apu|memory|input)\.`)
+
+// panicComponent names the component whose code panicked, judged by the
+// first emulator-package frame below the panic in stack.
+func panicComponent(stack string) string {
+	if i := strings.Index(stack, "panic("); i >= 0 {
+		stack = stack[i:]
+	}
+	m := panicFrame.FindStringSubmatch(stack)
+	if m == nil {
+		return "Emulator"
+	}
+	switch m[1] {
+	case "memory", "input":
+		return strings.ToUpper(m[1][:1]) + m[1][1:]
+	}
+	return strings.ToUpper(m[1])
+}
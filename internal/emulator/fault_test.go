@@ -0,0 +1,149 @@
+package emulator
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"nitro-core-dx/internal/rom"
+)
+
+func TestSymbolTableLookup(t *testing.T) {
+	syms := NewSymbolTable([]Symbol{
+		{Name: "update", Bank: 1, Offset: 0x8040},
+		{Name: "Start", Bank: 1, Offset: 0x8000},
+		{Name: "far", Bank: 2, Offset: 0x8000},
+	})
+	cases := []struct {
+		bank   uint8
+		offset uint16
+		want   string
+	}{
+		{1, 0x8000, "Start"},
+		{1, 0x8012, "Start+0x12"},
+		{1, 0x8040, "update"},
+		{1, 0x9000, "update+0xFC0"},
+		{2, 0x8002, "far+0x2"},
+		{3, 0x8000, ""},
+		{0, 0x8000, ""},
+	}
+	for _, c := range cases {
+		if got := syms.Lookup(c.bank, c.offset); got != c.want {
+			t.Errorf("Lookup(%02X:%04X) = %q, want %q", c.bank, c.offset, got, c.want)
+		}
+	}
+	if got := SymbolTable(nil).Lookup(1, 0x8000); got != "" {
+		t.Errorf("nil table Lookup = %q, want empty", got)
+	}
+}
+
+func TestPanicComponent(t *testing.T) {
+	stack := `goroutine 1 [running]:
+runtime/debug.Stack()
+nitro-core-dx/internal/emulator.(*Emulator).RunFrameGuarded.func1()
+panic({0x5a2f80?, 0x7c1e30?})
+nitro-core-dx/internal/ppu.(*PPU).renderDot(...)
+nitro-core-dx/internal/clock.(*Scheduler).Step(...)
+nitro-core-dx/internal/cpu.(*CPU).ExecuteInstruction(...)
+`
+	if got := panicComponent(stack); got != "PPU" {
+		t.Errorf("panicComponent = %q, want PPU", got)
+	}
+	if got := panicComponent("panic({})\nmain.main()\n"); got != "Emulator" {
+		t.Errorf("panicComponent with no component frame = %q, want Emulator", got)
+	}
+}
+
+// faultROM runs two MOVs and then an ADD with an unassigned mode.
+func faultROM(t *testing.T) []byte {
+	t.Helper()
+	b := rom.NewROMBuilder()
+	b.AddInstruction(rom.EncodeMOV(1, 1, 0)) // 8000: MOV R1, #0x1234
+	b.AddImmediate(0x1234)
+	b.AddInstruction(rom.EncodeMOV(1, 2, 0)) // 8004: MOV R2, #0x5678
+	b.AddImmediate(0x5678)
+	b.AddInstruction(rom.EncodeADD(9, 0, 0)) // 8008: unassigned mode
+	data, err := b.BuildROMBytes(1, 0x8000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestRunFrameGuardedReportsCPUFault(t *testing.T) {
+	emu := NewEmulator()
+	if err := emu.LoadROM(faultROM(t)); err != nil {
+		t.Fatal(err)
+	}
+	emu.Start()
+	syms := NewSymbolTable([]Symbol{{Name: "Start", Bank: 1, Offset: 0x8000}})
+
+	err := emu.RunFrameGuarded(syms)
+	var f *Fault
+	if !errors.As(err, &f) {
+		t.Fatalf("RunFrameGuarded = %v, want a *Fault", err)
+	}
+	if f.Component != "CPU" || f.Panic {
+		t.Errorf("Component = %q, Panic = %v; want CPU, false", f.Component, f.Panic)
+	}
+	if f.PCBank != 1 || f.PCOffset != 0x8008 || f.Symbol != "Start+0x8" {
+		t.Errorf("fault PC = %02X:%04X (%s), want 01:8008 (Start+0x8)", f.PCBank, f.PCOffset, f.Symbol)
+	}
+	if f.CPU.R1 != 0x1234 || f.CPU.R2 != 0x5678 {
+		t.Errorf("registers R1=%04X R2=%04X, want 1234 5678", f.CPU.R1, f.CPU.R2)
+	}
+	if len(f.Trace) != 3 {
+		t.Fatalf("trace has %d lines, want 3: %v", len(f.Trace), f.Trace)
+	}
+	if first := f.Trace[0]; first.Offset != 0x8000 || first.Symbol != "Start" || !strings.HasPrefix(first.Text, "MOV") {
+		t.Errorf("first trace line = %+v", first)
+	}
+	report := f.Report()
+	for _, want := range []string{"CPU fault at PC 01:8008 (Start+0x8)", "R1:1234", "01:8004", "unknown ADD mode"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report missing %q:\n%s", want, report)
+		}
+	}
+}
+
+func TestRunFrameGuardedRecoversPanic(t *testing.T) {
+	emu := NewEmulator()
+	if err := emu.LoadROM(faultROM(t)); err != nil {
+		t.Fatal(err)
+	}
+	emu.Start()
+	emu.CPU.Mem = nil // the next fetch dereferences a nil bus
+
+	var f *Fault
+	if err := emu.RunFrameGuarded(nil); !errors.As(err, &f) {
+		t.Fatalf("RunFrameGuarded = %v, want a *Fault", err)
+	}
+	if !f.Panic || f.Component != "CPU" || f.GoStack == "" {
+		t.Errorf("Panic = %v, Component = %q, stack %d bytes; want a CPU panic with a stack", f.Panic, f.Component, len(f.GoStack))
+	}
+}
+
+func TestDisassembleAroundAnchorsOnSymbol(t *testing.T) {
+	emu := NewEmulator()
+	if err := emu.LoadROM(faultROM(t)); err != nil {
+		t.Fatal(err)
+	}
+	syms := NewSymbolTable([]Symbol{{Name: "Start", Bank: 1, Offset: 0x8000}})
+
+	lines := emu.DisassembleAround(1, 0x8008, 1, 1, syms)
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3: %v", len(lines), lines)
+	}
+	if lines[0].Offset != 0x8004 || lines[1].Offset != 0x8008 || lines[2].Offset != 0x800A {
+		t.Errorf("offsets = %04X %04X %04X, want 8004 8008 800A", lines[0].Offset, lines[1].Offset, lines[2].Offset)
+	}
+	if lines[1].Symbol != "Start+0x8" {
+		t.Errorf("PC line symbol = %q, want Start+0x8", lines[1].Symbol)
+	}
+
+	// Without a symbol the start is guessed, but must still land on the PC.
+	lines = emu.DisassembleAround(1, 0x8008, 2, 0, nil)
+	if n := len(lines); n == 0 || lines[n-1].Offset != 0x8008 {
+		t.Fatalf("unanchored lines = %v, want to end at 01:8008", lines)
+	}
+}
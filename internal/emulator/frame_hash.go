@@ -0,0 +1,35 @@
+package emulator
+
+import "fmt"
+
+// FNV-1a, 32-bit.
+const (
+	fnvOffset32 = 2166136261
+	fnvPrime32  = 16777619
+)
+
+// HashFrame is the frame hash: 32-bit FNV-1a over the pixels in row order,
+// each as its red, green and blue bytes. It depends only on the picture,
+// so it is stable across runs, hosts and releases and can be kept as an
+// expected value in a regression test.
+func HashFrame(pixels []uint32) uint32 {
+	h := uint32(fnvOffset32)
+	for _, p := range pixels {
+		h = (h ^ p>>16&0xFF) * fnvPrime32
+		h = (h ^ p>>8&0xFF) * fnvPrime32
+		h = (h ^ p&0xFF) * fnvPrime32
+	}
+	return h
+}
+
+// FrameHash hashes the frame just presented, GetOutputBuffer (frame
+// plugins included), with HashFrame.
+func (e *Emulator) FrameHash() uint32 {
+	return HashFrame(e.GetOutputBuffer())
+}
+
+// FormatFrameHash spells a frame hash the way manifests and reports do:
+// eight lowercase hex digits.
+func FormatFrameHash(h uint32) string {
+	return fmt.Sprintf("%08x", h)
+}
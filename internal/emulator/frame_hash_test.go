@@ -0,0 +1,28 @@
+package emulator
+
+import (
+	"hash/fnv"
+	"testing"
+)
+
+func TestHashFrameIsFNV1aOverRGB(t *testing.T) {
+	pixels := []uint32{0x123456, 0xFFFFFF, 0x000000, 0xA0B0C0}
+	ref := fnv.New32a()
+	for _, p := range pixels {
+		ref.Write([]byte{byte(p >> 16), byte(p >> 8), byte(p)})
+	}
+	if got, want := HashFrame(pixels), ref.Sum32(); got != want {
+		t.Fatalf("HashFrame = %08x, want %08x", got, want)
+	}
+	if got := FormatFrameHash(0xBEEF); got != "0000beef" {
+		t.Errorf("FormatFrameHash = %q", got)
+	}
+
+	emu := NewEmulator()
+	defer emu.Logger.Shutdown()
+	before := emu.FrameHash()
+	emu.GetOutputBuffer()[100] = 0x010203
+	if emu.FrameHash() == before {
+		t.Error("FrameHash did not change with the picture")
+	}
+}
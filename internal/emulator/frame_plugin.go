@@ -0,0 +1,173 @@
+package emulator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"nitro-core-dx/internal/ppu"
+)
+
+// FramePlugin post-processes a finished frame before the host presents it:
+// overlays such as sprite hitboxes or memory-write heatmaps drawn without
+// touching the PPU. pixels is the frame GetOutputBuffer returns, 320x200
+// 0xRRGGBB values, which the plugin may read and draw into. It may read e
+// and post notifications with e.Notify but must not change emulated state.
+//
+// Plugins only change the picture the host sees, which includes framebuffer
+// hashes taken from GetOutputBuffer (movies, recordings); the next frame
+// is rendered from scratch either way.
+type FramePlugin func(e *Emulator, pixels []uint32)
+
+type framePlugin struct {
+	name string
+	fn   FramePlugin
+}
+
+// AddFramePlugin installs fn under name, replacing any plugin of that name.
+// Plugins run in installation order at the end of every RunFrame.
+func (e *Emulator) AddFramePlugin(name string, fn FramePlugin) {
+	for i := range e.framePlugins {
+		if e.framePlugins[i].name == name {
+			e.framePlugins[i].fn = fn
+			return
+		}
+	}
+	e.framePlugins = append(e.framePlugins, framePlugin{name: name, fn: fn})
+}
+
+// RemoveFramePlugin uninstalls the plugin called name, reporting whether
+// there was one.
+func (e *Emulator) RemoveFramePlugin(name string) bool {
+	for i := range e.framePlugins {
+		if e.framePlugins[i].name == name {
+			e.framePlugins = append(e.framePlugins[:i], e.framePlugins[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// FramePlugins lists the installed plugins' names in the order they run.
+func (e *Emulator) FramePlugins() []string {
+	names := make([]string, len(e.framePlugins))
+	for i, p := range e.framePlugins {
+		names[i] = p.name
+	}
+	return names
+}
+
+func (e *Emulator) runFramePlugins() {
+	pixels := e.GetOutputBuffer()
+	for _, p := range e.framePlugins {
+		p.fn(e, pixels)
+	}
+}
+
+// builtinFramePlugins are the plugins InstallFramePlugin knows by name. Each
+// entry prepares e (e.g. turning on the tracking it reads) and returns the
+// plugin.
+var builtinFramePlugins = map[string]func(e *Emulator) FramePlugin{
+	"input-display": func(*Emulator) FramePlugin { return InputDisplayPlugin },
+	"sprite-boxes":  func(*Emulator) FramePlugin { return SpriteBoxesPlugin },
+	"wram-heatmap": func(e *Emulator) FramePlugin {
+		e.EnableWriteTracking(true)
+		return WRAMHeatmapPlugin
+	},
+}
+
+// BuiltinFramePlugins lists the names InstallFramePlugin accepts.
+func BuiltinFramePlugins() []string {
+	names := make([]string, 0, len(builtinFramePlugins))
+	for name := range builtinFramePlugins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// InstallFramePlugin installs the built-in plugin called name.
+func (e *Emulator) InstallFramePlugin(name string) error {
+	mk, ok := builtinFramePlugins[name]
+	if !ok {
+		return fmt.Errorf("unknown frame plugin %q (have %s)", name, strings.Join(BuiltinFramePlugins(), ", "))
+	}
+	e.AddFramePlugin(name, mk(e))
+	return nil
+}
+
+// spriteBoxColors outline sprites by priority, 0 (lowest) to 3.
+var spriteBoxColors = [4]uint32{0x00FF00, 0x00FFFF, 0xFFFF00, 0xFF00FF}
+
+// SpriteBoxesPlugin outlines every enabled sprite's bounding box, coloured
+// by its priority, as a quick view of hitboxes and of sprites that are
+// invisible (transparent tiles, wrong palette).
+func SpriteBoxesPlugin(e *Emulator, pixels []uint32) {
+	for i := 0; i < 128; i++ {
+		x, y, w, h, enabled := e.PPU.SpriteBounds(i)
+		if !enabled {
+			continue
+		}
+		c := spriteBoxColors[(e.PPU.OAM[i*6+4]>>6)&0x03]
+		plot := func(dx, dy int) {
+			if sx, sy, ok := ppu.SpriteScreenPos(x, y, dx, dy); ok {
+				pixels[sy*320+sx] = c
+			}
+		}
+		for dx := 0; dx < w; dx++ {
+			plot(dx, 0)
+			plot(dx, h-1)
+		}
+		for dy := 1; dy < h-1; dy++ {
+			plot(0, dy)
+			plot(w-1, dy)
+		}
+	}
+}
+
+// The WRAM heatmap is a heatmapCols x heatmapRows grid of
+// heatmapBytesPerCell-byte cells covering bank 0 work RAM, each drawn as a
+// heatmapCellSize square in the bottom right corner of the screen.
+const (
+	heatmapCols         = 64
+	heatmapRows         = 32
+	heatmapBytesPerCell = 0x8000 / (heatmapCols * heatmapRows)
+	heatmapCellSize     = 2
+	heatmapFadeFrames   = 60
+)
+
+// WRAMHeatmapPlugin draws a map of bank 0 work RAM in the bottom right
+// corner, each cell red for a write this frame and fading over a second
+// after it; cells not written lately stay dim. It reads write tracking
+// (EnableWriteTracking), which installing it by name turns on.
+func WRAMHeatmapPlugin(e *Emulator, pixels []uint32) {
+	if e.writes == nil {
+		return
+	}
+	// Writes during the frame just run carry its number; FrameCount has
+	// since moved on by one.
+	frame := uint32(e.FrameCount) - 1
+	left := 320 - heatmapCols*heatmapCellSize
+	top := 200 - heatmapRows*heatmapCellSize
+	for cell := 0; cell < heatmapCols*heatmapRows; cell++ {
+		age := heatmapFadeFrames
+		for _, h := range e.writes.wram[cell*heatmapBytesPerCell : (cell+1)*heatmapBytesPerCell] {
+			if h.count == 0 {
+				continue
+			}
+			newest := h.slots[(int(h.next)+writeHistoryDepth-1)%writeHistoryDepth]
+			if a := int(frame - newest.frame); a < age {
+				age = a
+			}
+		}
+		heat := uint32(255 * (heatmapFadeFrames - age) / heatmapFadeFrames)
+		c := heat<<16 | (255-heat)/8<<8 | 0x20
+		x0 := left + (cell%heatmapCols)*heatmapCellSize
+		y0 := top + (cell/heatmapCols)*heatmapCellSize
+		for dy := 0; dy < heatmapCellSize; dy++ {
+			for dx := 0; dx < heatmapCellSize; dx++ {
+				pixels[(y0+dy)*320+x0+dx] = c
+			}
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package emulator
+
+import (
+	"reflect"
+	"testing"
+
+	"nitro-core-dx/internal/input"
+	"nitro-core-dx/internal/rom"
+)
+
+// spinROM jumps to itself forever.
+func spinROM(t *testing.T) []byte {
+	t.Helper()
+	b := rom.NewROMBuilder()
+	b.AddInstruction(rom.EncodeJMP()) // 8000: JMP 8000
+	b.AddImmediate(uint16(rom.CalculateBranchOffset(0x8002, 0x8000)))
+	data, err := b.BuildROMBytes(1, 0x8000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestFramePluginsRunInOrderAfterEachFrame(t *testing.T) {
+	emu := NewEmulator()
+	if err := emu.LoadROM(spinROM(t)); err != nil {
+		t.Fatal(err)
+	}
+	emu.SetFrameLimit(false)
+	emu.Start()
+
+	var order []string
+	var frames []uint64
+	emu.AddFramePlugin("mark", func(e *Emulator, pixels []uint32) {
+		order = append(order, "mark")
+		frames = append(frames, e.FrameCount)
+		pixels[0] = 0xFF0000
+	})
+	emu.AddFramePlugin("second", func(e *Emulator, pixels []uint32) {
+		order = append(order, "second")
+		if pixels[0] != 0xFF0000 {
+			t.Error("second plugin did not see the first one's drawing")
+		}
+	})
+	for i := 0; i < 2; i++ {
+		if err := emu.RunFrame(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if want := []string{"mark", "second", "mark", "second"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("plugin calls %v, want %v", order, want)
+	}
+	if !reflect.DeepEqual(frames, []uint64{1, 2}) {
+		t.Errorf("plugins saw frames %v", frames)
+	}
+	if emu.GetOutputBuffer()[0] != 0xFF0000 {
+		t.Error("plugin drawing is not in the presented frame")
+	}
+	if emu.PPU.OutputBuffer[0] == 0xFF0000 {
+		t.Error("plugin drew into the PPU's back buffer")
+	}
+
+	emu.AddFramePlugin("mark", func(*Emulator, []uint32) { order = append(order, "replaced") })
+	if !emu.RemoveFramePlugin("second") || emu.RemoveFramePlugin("second") {
+		t.Error("RemoveFramePlugin did not remove exactly once")
+	}
+	order = nil
+	if err := emu.RunFrame(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(order, []string{"replaced"}) || !reflect.DeepEqual(emu.FramePlugins(), []string{"mark"}) {
+		t.Errorf("after replace/remove: calls %v, plugins %v", order, emu.FramePlugins())
+	}
+
+	if err := emu.InstallFramePlugin("no-such-plugin"); err == nil {
+		t.Error("InstallFramePlugin accepted an unknown name")
+	}
+}
+
+func TestSpriteBoxesPlugin(t *testing.T) {
+	emu := NewEmulator()
+	oam := emu.PPU.OAM[:]
+	oam[0], oam[1], oam[2], oam[5] = 10, 0, 20, 0x01 // 8x8 at (10, 20)
+	oam[6], oam[8], oam[11] = 100, 100, 0x00         // sprite 1 disabled
+
+	pixels := make([]uint32, 320*200)
+	SpriteBoxesPlugin(emu, pixels)
+	at := func(x, y int) uint32 { return pixels[y*320+x] }
+	c := spriteBoxColors[0]
+	for _, p := range [][2]int{{10, 20}, {17, 20}, {10, 27}, {17, 27}, {13, 20}, {10, 24}} {
+		if at(p[0], p[1]) != c {
+			t.Errorf("outline pixel (%d,%d) = 0x%06X", p[0], p[1], at(p[0], p[1]))
+		}
+	}
+	if at(13, 24) != 0 || at(100, 100) != 0 {
+		t.Error("plugin drew inside a box or around a disabled sprite")
+	}
+}
+
+func TestWRAMHeatmapPlugin(t *testing.T) {
+	emu := NewEmulator()
+	if err := emu.InstallFramePlugin("wram-heatmap"); err != nil {
+		t.Fatal(err)
+	}
+	if !emu.WriteTracking() {
+		t.Fatal("installing wram-heatmap did not turn on write tracking")
+	}
+	emu.FrameCount = 5
+	emu.Bus.Write8(0, 0x0000, 1) // first cell, written this frame
+	emu.FrameCount = 6
+
+	pixels := make([]uint32, 320*200)
+	WRAMHeatmapPlugin(emu, pixels)
+	left, top := 320-heatmapCols*heatmapCellSize, 200-heatmapRows*heatmapCellSize
+	hot, cold := pixels[top*320+left], pixels[top*320+left+heatmapCellSize]
+	if hot>>16 != 0xFF || cold>>16 != 0 {
+		t.Errorf("written cell 0x%06X, idle cell 0x%06X", hot, cold)
+	}
+}
+
+func TestInputDisplayPlugin(t *testing.T) {
+	emu := NewEmulator()
+	emu.SetInputButtons(1<<input.ButtonA | 1<<input.ButtonLEFT)
+
+	pixels := make([]uint32, 320*200)
+	InputDisplayPlugin(emu, pixels)
+	top := 200 - inputDisplayMargin - inputDisplayH
+	at := func(x, y int) uint32 { return pixels[(top+y)*320+inputDisplayMargin+x] }
+	for _, c := range []struct {
+		name string
+		x, y int
+		want uint32
+	}{
+		{"outline", 0, 0, inputDisplayOutline},
+		{"body", 30, 8, inputDisplayBody},
+		{"A", 54, 12, inputDisplayPressed},
+		{"left", 8, 12, inputDisplayPressed},
+		{"B", 49, 17, inputDisplayIdle},
+		{"start", 30, 15, inputDisplayIdle},
+	} {
+		if got := at(c.x, c.y); got != c.want {
+			t.Errorf("%s pixel = 0x%06X, want 0x%06X", c.name, got, c.want)
+		}
+	}
+	if pixels[0] != 0 || pixels[199*320+319] != 0 {
+		t.Error("input display drew outside its corner")
+	}
+}
@@ -0,0 +1,147 @@
+package emulator
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Rolling host-side frame timing statistics.
+//
+// These measure how the emulator performs on the host; they never feed back
+// into emulated state, so they are collected in every mode (including
+// Deterministic).
+
+// FrameStatsWindow is the number of most recent frames summarized by
+// FrameStats (two seconds at 60 FPS).
+const FrameStatsWindow = 120
+
+// frameStatsMaxGap is the largest wall-clock gap between two frames that
+// still counts toward emulation speed. Longer gaps (pause, breakpoint,
+// window drag) are treated as a discontinuity rather than a slowdown.
+const frameStatsMaxGap = 250 * time.Millisecond
+
+// audioSampleRate is the APU output rate (see NewEmulatorWithLogger).
+const audioSampleRate = 44100
+
+// FrameStats is a snapshot of rolling frame timing statistics.
+type FrameStats struct {
+	// Frames is the number of frames in the window (0..FrameStatsWindow).
+	Frames int
+	// FrameTimeP50 and FrameTimeP95 are percentiles of host time spent
+	// inside RunFrame, excluding any frame-limit sleep.
+	FrameTimeP50 time.Duration
+	FrameTimeP95 time.Duration
+	// SpeedPercent is emulated time divided by wall time over the window,
+	// as a percentage (100 = real time). Zero until two frames have run.
+	SpeedPercent float64
+	// AudioQueueSamples is the host audio queue depth last reported via
+	// ReportAudioQueue, in output samples; AudioQueueTime is the same depth
+	// as playback time.
+	AudioQueueSamples int
+	AudioQueueTime    time.Duration
+}
+
+type frameStats struct {
+	work      [FrameStatsWindow]time.Duration
+	interval  [FrameStatsWindow]time.Duration
+	workPos   int
+	workCount int
+	intPos    int
+	intCount  int
+	lastStart time.Time
+
+	audioQueue int
+}
+
+func (s *frameStats) reset() {
+	*s = frameStats{audioQueue: s.audioQueue}
+}
+
+// record adds one frame that started at start and took work host time.
+func (s *frameStats) record(start time.Time, work time.Duration) {
+	s.work[s.workPos] = work
+	s.workPos = (s.workPos + 1) % FrameStatsWindow
+	if s.workCount < FrameStatsWindow {
+		s.workCount++
+	}
+
+	if !s.lastStart.IsZero() {
+		gap := start.Sub(s.lastStart)
+		if gap >= 0 && gap <= frameStatsMaxGap {
+			s.interval[s.intPos] = gap
+			s.intPos = (s.intPos + 1) % FrameStatsWindow
+			if s.intCount < FrameStatsWindow {
+				s.intCount++
+			}
+		}
+	}
+	s.lastStart = start
+}
+
+// ReportAudioQueue records the host audio queue depth in output samples
+// (mono sample frames, e.g. bytes queued / 8 for stereo float32). Hosts call
+// this after queuing each frame; it only feeds FrameStats.
+func (e *Emulator) ReportAudioQueue(samples int) {
+	if samples < 0 {
+		samples = 0
+	}
+	e.frameStats.audioQueue = samples
+}
+
+// FrameStats returns rolling frame timing statistics over the last
+// FrameStatsWindow frames.
+func (e *Emulator) FrameStats() FrameStats {
+	s := &e.frameStats
+	out := FrameStats{
+		Frames:            s.workCount,
+		AudioQueueSamples: s.audioQueue,
+		AudioQueueTime:    time.Duration(s.audioQueue) * time.Second / time.Duration(audioSampleRate),
+	}
+	if s.workCount > 0 {
+		var sorted [FrameStatsWindow]time.Duration
+		work := sorted[:s.workCount]
+		copy(work, s.work[:s.workCount])
+		sort.Slice(work, func(i, j int) bool { return work[i] < work[j] })
+		out.FrameTimeP50 = percentile(work, 50)
+		out.FrameTimeP95 = percentile(work, 95)
+	}
+	if s.intCount > 0 && e.FrameTime > 0 {
+		var wall time.Duration
+		for _, d := range s.interval[:s.intCount] {
+			wall += d
+		}
+		if wall > 0 {
+			emulated := time.Duration(s.intCount) * e.FrameTime
+			out.SpeedPercent = float64(emulated) / float64(wall) * 100
+		}
+	}
+	return out
+}
+
+// String formats the stats compactly for status bars and overlays, e.g.
+// "Speed 100% | p50 1.20ms p95 2.05ms | Audio 33ms".
+func (s FrameStats) String() string {
+	if s.Frames == 0 {
+		return "Speed --"
+	}
+	return fmt.Sprintf("Speed %.0f%% | p50 %s p95 %s | Audio %dms",
+		s.SpeedPercent,
+		formatFrameMillis(s.FrameTimeP50),
+		formatFrameMillis(s.FrameTimeP95),
+		s.AudioQueueTime.Milliseconds(),
+	)
+}
+
+func formatFrameMillis(d time.Duration) string {
+	return fmt.Sprintf("%.2fms", float64(d)/float64(time.Millisecond))
+}
+
+// percentile returns the p-th percentile (nearest rank) of sorted values.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	idx := (len(sorted)*p + 99) / 100
+	if idx < 1 {
+		idx = 1
+	}
+	return sorted[idx-1]
+}
@@ -0,0 +1,83 @@
+package emulator
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFrameStatsPercentilesAndSpeed(t *testing.T) {
+	e := &Emulator{FrameTime: time.Second / 60}
+	start := time.Unix(1000, 0)
+	// 100 frames: work 1..100ms, started every 1/30 s (half speed).
+	for i := 1; i <= 100; i++ {
+		e.frameStats.record(start, time.Duration(i)*time.Millisecond)
+		start = start.Add(time.Second / 30)
+	}
+	stats := e.FrameStats()
+	if stats.Frames != 100 {
+		t.Fatalf("Frames = %d, want 100", stats.Frames)
+	}
+	if stats.FrameTimeP50 != 50*time.Millisecond {
+		t.Fatalf("p50 = %v, want 50ms", stats.FrameTimeP50)
+	}
+	if stats.FrameTimeP95 != 95*time.Millisecond {
+		t.Fatalf("p95 = %v, want 95ms", stats.FrameTimeP95)
+	}
+	if stats.SpeedPercent < 49.9 || stats.SpeedPercent > 50.1 {
+		t.Fatalf("SpeedPercent = %.2f, want ~50", stats.SpeedPercent)
+	}
+}
+
+func TestFrameStatsIgnoresLongGapsAndWraps(t *testing.T) {
+	e := &Emulator{FrameTime: time.Second / 60}
+	start := time.Unix(1000, 0)
+	for i := 0; i < FrameStatsWindow+30; i++ {
+		if i == 60 {
+			// Pause/breakpoint: must not read as a slowdown.
+			start = start.Add(10 * time.Second)
+		}
+		e.frameStats.record(start, time.Millisecond)
+		start = start.Add(e.FrameTime)
+	}
+	stats := e.FrameStats()
+	if stats.Frames != FrameStatsWindow {
+		t.Fatalf("Frames = %d, want %d", stats.Frames, FrameStatsWindow)
+	}
+	if stats.SpeedPercent < 99.9 || stats.SpeedPercent > 100.1 {
+		t.Fatalf("SpeedPercent = %.2f, want ~100 with pause gap excluded", stats.SpeedPercent)
+	}
+
+	e.ReportAudioQueue(1470)
+	if got := e.FrameStats(); got.AudioQueueSamples != 1470 || got.AudioQueueTime != time.Second/30 {
+		t.Fatalf("audio queue = %d samples / %v, want 1470 / %v", got.AudioQueueSamples, got.AudioQueueTime, time.Second/30)
+	}
+}
+
+func TestFrameStatsResetOnPowerOff(t *testing.T) {
+	emu := NewEmulator()
+	romData := make([]uint8, 64)
+	copy(romData, []uint8{0x52, 0x4D, 0x43, 0x46, 0x01, 0x00, 0x20})
+	romData[10] = 0x01
+	romData[13] = 0x80
+	if err := emu.LoadROM(romData); err != nil {
+		t.Fatalf("Failed to load ROM: %v", err)
+	}
+	emu.Start()
+	emu.SetFrameLimit(false)
+	emu.Clock.CPUStep = func(cycles uint64) error {
+		emu.CPU.State.Cycles += uint32(cycles)
+		return nil
+	}
+	for i := 0; i < 3; i++ {
+		if err := emu.RunFrame(); err != nil {
+			t.Fatalf("RunFrame failed: %v", err)
+		}
+	}
+	if got := emu.FrameStats().Frames; got != 3 {
+		t.Fatalf("Frames = %d, want 3", got)
+	}
+	emu.Stop()
+	if got := emu.FrameStats().Frames; got != 0 {
+		t.Fatalf("Frames after Stop = %d, want 0", got)
+	}
+}
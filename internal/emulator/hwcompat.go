@@ -0,0 +1,133 @@
+package emulator
+
+import (
+	"fmt"
+
+	"nitro-core-dx/internal/hwdef"
+	"nitro-core-dx/internal/ppu"
+)
+
+// Kinds of HardwareIssue.
+const (
+	// HardwareLateWrite is a VRAM, CGRAM or OAM write made while the PPU
+	// draws the visible scanlines. Hardware drops it; Register names the
+	// memory.
+	HardwareLateWrite = "late_write"
+	// HardwareWriteOnlyRead is a read of a write-only register, which
+	// reads as 0 on hardware whatever was written; Register names it.
+	HardwareWriteOnlyRead = "write_only_read"
+)
+
+// HardwareIssue is one behavior of a ROM that works in the emulator only
+// because it is lenient, found by CheckHardwareCompat. Repeats of the same
+// kind and register are folded into one issue; the PC and frame are those
+// of the first.
+type HardwareIssue struct {
+	Kind     string
+	Register string
+	DMA      bool // a late write made by DMA
+	Count    int
+	PCBank   uint8 // instruction that made the first occurrence
+	PCOffset uint16
+	Frame    uint64
+}
+
+func (i HardwareIssue) String() string {
+	var what string
+	switch i.Kind {
+	case HardwareLateWrite:
+		via := "written"
+		if i.DMA {
+			via = "written by DMA"
+		}
+		what = fmt.Sprintf("%d %s byte(s) %s during active display, which hardware drops", i.Count, i.Register, via)
+	case HardwareWriteOnlyRead:
+		what = fmt.Sprintf("%d read(s) of write-only register %s, which reads 0 on hardware", i.Count, i.Register)
+	default:
+		what = fmt.Sprintf("%d %s %s", i.Count, i.Kind, i.Register)
+	}
+	return fmt.Sprintf("%s (first at PC %02X:%04X, frame %d)", what, i.PCBank, i.PCOffset, i.Frame)
+}
+
+// CheckHardwareCompat runs rom for frames frames in a fresh deterministic
+// emulator under strict hardware rules (ppu.VRAMAccessStrict, and hardware
+// timing with memory.Bus.Contention) and reports what it relies on the
+// emulator's leniency for: VRAM, CGRAM and OAM writes during active
+// display, and reads of write-only registers (see hwdef.WriteOnly). Issues
+// are in order of first occurrence. An error means the ROM could not be
+// loaded or faulted.
+func CheckHardwareCompat(rom []byte, frames int) ([]HardwareIssue, error) {
+	emu := NewEmulator()
+	if emu.Logger != nil {
+		defer emu.Logger.Shutdown()
+	}
+	if err := emu.LoadROM(rom); err != nil {
+		return nil, err
+	}
+	emu.SetFrameLimit(false)
+	emu.SetDeterministic(true)
+	emu.PPU.VRAMAccess = ppu.VRAMAccessStrict
+	emu.Bus.Contention = true
+
+	var issues []HardwareIssue
+	index := map[string]int{}
+	record := func(kind, register string, dma bool) {
+		key := fmt.Sprintf("%s/%s/%t", kind, register, dma)
+		if i, ok := index[key]; ok {
+			issues[i].Count++
+			return
+		}
+		index[key] = len(issues)
+		// The PC has already moved past the instruction doing the access;
+		// the newest recent PC is where it started.
+		pc := uint32(emu.CPU.State.PCBank)<<16 | uint32(emu.CPU.State.PCOffset)
+		if recent := emu.CPU.RecentPCs(); len(recent) > 0 {
+			pc = recent[len(recent)-1]
+		}
+		issues = append(issues, HardwareIssue{
+			Kind:     kind,
+			Register: register,
+			DMA:      dma,
+			Count:    1,
+			PCBank:   uint8(pc >> 16),
+			PCOffset: uint16(pc),
+			Frame:    emu.FrameCount,
+		})
+	}
+	emu.PPU.LateWrite = func(target string, dma bool) {
+		record(HardwareLateWrite, target, dma)
+	}
+	writeOnly := writeOnlyAddresses()
+	emu.Bus.IORead = func(offset uint16) {
+		if name, ok := writeOnly[offset]; ok {
+			record(HardwareWriteOnlyRead, name, false)
+		}
+	}
+
+	emu.Start()
+	for i := 0; i < frames; i++ {
+		if err := emu.RunFrame(); err != nil {
+			return issues, fmt.Errorf("frame %d: %w", i, err)
+		}
+	}
+	return issues, nil
+}
+
+// writeOnlyAddresses maps each I/O address that only write-only registers
+// occupy to the register's name. An address a readable register shares,
+// such as DMA_CONTROL's with DMA_STATUS, is left out.
+func writeOnlyAddresses() map[uint16]string {
+	out := map[uint16]string{}
+	readable := map[uint16]bool{}
+	for _, reg := range hwdef.Registers() {
+		if reg.Access == hwdef.WriteOnly {
+			out[reg.Address] = reg.Name
+		} else {
+			readable[reg.Address] = true
+		}
+	}
+	for addr := range readable {
+		delete(out, addr)
+	}
+	return out
+}
@@ -0,0 +1,13 @@
+package emulator
+
+import "testing"
+
+func TestWriteOnlyAddressesSkipSharedReadable(t *testing.T) {
+	addrs := writeOnlyAddresses()
+	if addrs[0x8000] != "BG0_SCROLLX_L" {
+		t.Errorf("0x8000 = %q, want BG0_SCROLLX_L", addrs[0x8000])
+	}
+	if name, ok := addrs[0x8060]; ok {
+		t.Errorf("0x8060 listed as write-only %s; DMA_STATUS reads there", name)
+	}
+}
@@ -0,0 +1,102 @@
+package emulator
+
+import (
+	"fmt"
+
+	"nitro-core-dx/internal/cpu"
+	"nitro-core-dx/internal/memory"
+)
+
+// Immediate execution runs a small ROM -- a CoreLX snippet compiled with
+// corelx.CompileOptions.Immediate -- on the CPU of a paused machine, the way
+// a debugger's "evaluate" runs code in the inferior. Only the CPU steps: the
+// PPU, APU and clock do not advance, so the snippet sees and changes the
+// machine exactly as it was paused.
+//
+// Everything the snippet writes through the bus (I/O registers, OAM, VRAM,
+// CGRAM, the YM2608, globals and the runtime block) stays written. What
+// belongs to the interrupted program is put back afterwards: the CPU state,
+// the cartridge, the system vectors, and the locals/call-stack region of
+// WRAM (0x0000-0x1FFF) that the snippet's own frames share with it.
+//
+// DMA a snippet starts runs on the next PPU step, after the game's cartridge
+// is back in place, so it must not source data from the snippet ROM.
+
+const (
+	// immediateStackTop bounds the WRAM region saved and restored around a
+	// snippet: CPU stack plus compiler-managed function frames.
+	immediateStackTop = 0x2000
+
+	// The snippet's entry function returns to this address. It lies in the
+	// last ROM bank, far past any snippet, and reaching it ends the run.
+	immediateReturnBank   = 125
+	immediateReturnOffset = 0xFFFE
+)
+
+// ImmediateRun reports a completed snippet.
+type ImmediateRun struct {
+	// R0 holds the entry function's return value, if it has one.
+	R0           uint16
+	Instructions int
+	Cycles       uint32
+}
+
+// RunImmediate executes romBytes from its entry point until the entry
+// function returns, or fails after maxInstructions. Because the PPU does not
+// advance, wait_vblank() only returns if the machine was paused inside
+// VBlank (as it is between frames); paused mid-frame, it spins until the
+// instruction limit.
+func (e *Emulator) RunImmediate(romBytes []byte, maxInstructions int) (ImmediateRun, error) {
+	var run ImmediateRun
+	if maxInstructions <= 0 {
+		return run, fmt.Errorf("maxInstructions must be > 0")
+	}
+	cart := memory.NewCartridge()
+	if err := cart.LoadROM(romBytes); err != nil {
+		return run, fmt.Errorf("load immediate ROM: %w", err)
+	}
+	entryBank, entryOffset, err := cart.GetROMEntryPoint()
+	if err != nil {
+		return run, fmt.Errorf("load immediate ROM: %w", err)
+	}
+
+	savedState := e.CPU.State
+	savedCart := e.Bus.Cartridge
+	savedVectors := e.Bus.SystemVectors
+	// The snippet runs on its own stack; its calls are not the ROM's.
+	savedCallHook := e.CPU.CallHook
+	var savedStack [immediateStackTop]uint8
+	copy(savedStack[:], e.Bus.WRAM[:immediateStackTop])
+	defer func() {
+		e.CPU.State = savedState
+		e.Bus.Cartridge = savedCart
+		e.Bus.SystemVectors = savedVectors
+		e.CPU.CallHook = savedCallHook
+		copy(e.Bus.WRAM[:immediateStackTop], savedStack[:])
+	}()
+	e.CPU.CallHook = nil
+
+	e.Bus.Cartridge = cart
+	e.CPU.State = cpu.CPUState{SP: 0x1FFF}
+	e.CPU.SetFlag(cpu.FlagI, true)
+	// The same three words a CALL pushes, so the entry function's RET lands
+	// on the return address.
+	e.CPU.Push16(immediateReturnBank)
+	e.CPU.Push16(immediateReturnOffset)
+	e.CPU.Push16(uint16(e.CPU.State.Flags))
+	e.CPU.SetEntryPoint(entryBank, entryOffset)
+
+	for run.Instructions < maxInstructions {
+		st := &e.CPU.State
+		if st.PCBank == immediateReturnBank && st.PCOffset == immediateReturnOffset {
+			run.R0 = st.R0
+			run.Cycles = st.Cycles
+			return run, nil
+		}
+		if err := e.CPU.ExecuteInstruction(); err != nil {
+			return run, fmt.Errorf("at %s after %d instructions: %w", e.CPU.GetPC(), run.Instructions, err)
+		}
+		run.Instructions++
+	}
+	return run, fmt.Errorf("did not return within %d instructions (loops and VBlank waits cannot finish while the PPU is paused)", maxInstructions)
+}
@@ -0,0 +1,67 @@
+package emulator
+
+import "nitro-core-dx/internal/input"
+
+// The input display is a small controller drawn in the bottom left corner
+// (the WRAM heatmap takes the bottom right), inputDisplayW x inputDisplayH
+// pixels, inputDisplayMargin from the screen edges.
+const (
+	inputDisplayW      = 64
+	inputDisplayH      = 24
+	inputDisplayMargin = 4
+
+	inputDisplayBody    = 0x202020
+	inputDisplayOutline = 0x909090
+	inputDisplayIdle    = 0x505050
+	inputDisplayPressed = 0xFFD040
+)
+
+// inputDisplayButtons places each button of the controller graphic, in
+// pixels from its top left corner: shoulders along the top edge, the d-pad
+// on the left, Start in the middle and the face buttons in a diamond on the
+// right (X top, Y left, A right, B bottom). Bit is the button's bit in the
+// controller word; -1 is the d-pad's centre, which never lights.
+var inputDisplayButtons = [...]struct {
+	bit        int
+	x, y, w, h int
+}{
+	{input.ButtonL, 3, 1, 14, 3},
+	{input.ButtonZ, 28, 1, 8, 3},
+	{input.ButtonR, 47, 1, 14, 3},
+	{input.ButtonUP, 12, 6, 4, 5},
+	{input.ButtonDOWN, 12, 15, 4, 5},
+	{input.ButtonLEFT, 7, 11, 5, 4},
+	{input.ButtonRIGHT, 16, 11, 5, 4},
+	{-1, 12, 11, 4, 4},
+	{input.ButtonSTART, 28, 14, 8, 3},
+	{input.ButtonX, 48, 6, 4, 4},
+	{input.ButtonY, 43, 11, 4, 4},
+	{input.ButtonA, 53, 11, 4, 4},
+	{input.ButtonB, 48, 16, 4, 4},
+}
+
+// InputDisplayPlugin draws controller 1 in the bottom left corner with the
+// buttons the frame just run saw pressed lit up, for tutorial recordings
+// and for checking what input code is actually given.
+func InputDisplayPlugin(e *Emulator, pixels []uint32) {
+	buttons := e.Input.Controller1Buttons
+	left := inputDisplayMargin
+	top := 200 - inputDisplayMargin - inputDisplayH
+	fill := func(x, y, w, h int, c uint32) {
+		for dy := 0; dy < h; dy++ {
+			row := (top+y+dy)*320 + left + x
+			for dx := 0; dx < w; dx++ {
+				pixels[row+dx] = c
+			}
+		}
+	}
+	fill(0, 0, inputDisplayW, inputDisplayH, inputDisplayOutline)
+	fill(1, 1, inputDisplayW-2, inputDisplayH-2, inputDisplayBody)
+	for _, b := range inputDisplayButtons {
+		c := uint32(inputDisplayIdle)
+		if b.bit >= 0 && buttons&(1<<b.bit) != 0 {
+			c = inputDisplayPressed
+		}
+		fill(b.x, b.y, b.w, b.h, c)
+	}
+}
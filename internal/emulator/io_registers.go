@@ -0,0 +1,41 @@
+package emulator
+
+import "nitro-core-dx/internal/hwdef"
+
+// IORegisterAccess says how a register can be inspected without disturbing
+// the running program (see hwdef.Access).
+type IORegisterAccess = hwdef.Access
+
+const (
+	// IOReadWrite registers read back their live value with no side effects.
+	IOReadWrite = hwdef.ReadWrite
+	// IOWriteOnly registers do not read back what was written;
+	// PeekIORegister reports the last value written instead.
+	IOWriteOnly = hwdef.WriteOnly
+	// IOPort registers change state when read or act on every write.
+	// PeekIORegister reports the last value written and never reads them.
+	IOPort = hwdef.Port
+	// IOReadOnly registers report status.
+	IOReadOnly = hwdef.ReadOnly
+)
+
+// IORegister names one byte of bank 0 I/O.
+type IORegister = hwdef.Register
+
+// IORegisters returns the named PPU, APU and input registers from the
+// shared hwdef table.
+func IORegisters() []IORegister {
+	return hwdef.Registers()
+}
+
+// PeekIORegister returns reg's current value without side effects: a live
+// read for readable registers, otherwise the last value written through the
+// bus (known reports whether there was one since power-on).
+func (e *Emulator) PeekIORegister(reg IORegister) (value uint8, known bool) {
+	switch reg.Access {
+	case IOReadWrite, IOReadOnly:
+		return e.Bus.Read8(0, reg.Address), true
+	default:
+		return e.Bus.LastIOWrite(reg.Address)
+	}
+}
@@ -0,0 +1,49 @@
+package emulator
+
+import "testing"
+
+func TestPeekIORegisterHasNoSideEffects(t *testing.T) {
+	emu := NewEmulator()
+	find := func(name string) IORegister {
+		for _, reg := range IORegisters() {
+			if reg.Name == name {
+				return reg
+			}
+		}
+		t.Fatalf("no register %s", name)
+		return IORegister{}
+	}
+
+	// Write-only: the last bus write is reported.
+	scroll := find("BG0_SCROLLX_L")
+	if _, known := emu.PeekIORegister(scroll); known {
+		t.Fatalf("unwritten write-only register should be unknown")
+	}
+	emu.Bus.Write8(0, 0x8000, 0x42)
+	if v, known := emu.PeekIORegister(scroll); !known || v != 0x42 {
+		t.Fatalf("BG0_SCROLLX_L = 0x%02X known=%v", v, known)
+	}
+
+	// Readable: the live value, including changes made behind the bus.
+	emu.Bus.Write8(0, 0x8008, 0x01)
+	if v, _ := emu.PeekIORegister(find("BG0_CONTROL")); v != 0x01 {
+		t.Fatalf("BG0_CONTROL = 0x%02X", v)
+	}
+	emu.Bus.Write8(0, 0x9002, 0x80)
+	if v, _ := emu.PeekIORegister(find("CH0_VOLUME")); v != 0x80 {
+		t.Fatalf("CH0_VOLUME = 0x%02X", v)
+	}
+
+	// Ports are never read: peeking VRAM_DATA must not advance VRAM_ADDR.
+	emu.Bus.Write8(0, 0x800E, 0x10)
+	emu.Bus.Write8(0, 0x800F, 0x00)
+	emu.PeekIORegister(find("VRAM_DATA"))
+	if emu.PPU.VRAMAddr != 0x0010 {
+		t.Fatalf("peek advanced VRAM_ADDR to 0x%04X", emu.PPU.VRAMAddr)
+	}
+
+	emu.Bus.ClearRAM()
+	if _, known := emu.PeekIORegister(scroll); known {
+		t.Fatalf("power-off should forget I/O writes")
+	}
+}
@@ -99,22 +99,29 @@ func resizeImageNearest(src image.Image, width, height int) *image.RGBA {
 }
 
 func quantizeImageTo4bpp(img image.Image, maxColors int) ([]uint16, []uint8, bool) {
+	if maxColors > 16 {
+		maxColors = 16
+	}
+	return quantizeImage(img, maxColors, 16384)
+}
+
+// quantizeImage reduces img to at most maxColors RGB555 colors (k-means over
+// at most about maxSamples pixels) and returns the palette and each pixel's
+// index. When img has transparent pixels, index 0 is reserved for them.
+func quantizeImage(img image.Image, maxColors, maxSamples int) ([]uint16, []uint8, bool) {
 	bounds := img.Bounds()
 	width, height := bounds.Dx(), bounds.Dy()
 	totalPixels := width * height
 	if maxColors < 1 {
 		maxColors = 1
 	}
-	if maxColors > 16 {
-		maxColors = 16
-	}
 
 	transparent := make([]bool, totalPixels)
 	hasTransparency := false
-	samples := make([]imageSample, 0, 16384)
+	samples := make([]imageSample, 0, maxSamples)
 	step := 1
-	if totalPixels > 16384 {
-		for step*step < totalPixels/16384 {
+	if totalPixels > maxSamples {
+		for step*step < totalPixels/maxSamples {
 			step++
 		}
 	}
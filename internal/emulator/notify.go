@@ -0,0 +1,151 @@
+package emulator
+
+import (
+	"sync"
+	"time"
+
+	"nitro-core-dx/internal/ppu"
+)
+
+// On-screen notifications stack down from the top right corner, newest
+// first, each a line of 8x8 font text on a dark box. They stay for
+// NotificationDuration and fade out over its last notificationFade.
+const (
+	NotificationDuration = 2 * time.Second
+	notificationFade     = 500 * time.Millisecond
+	maxNotifications     = 4
+
+	notificationMargin  = 4
+	notificationPadding = 2
+	notificationLineH   = 8 + 2*notificationPadding + 1
+
+	notificationText = 0xFFFFFF
+	notificationBox  = 0x101820
+	// notificationBoxAlpha is the box's opacity, out of 256, before fading.
+	notificationBoxAlpha = 192
+)
+
+// Notifications is a short queue of on-screen messages ("State saved",
+// "Recording started") that hosts draw over the presented picture. They are
+// timed on the wall clock so they fade while the emulator is paused, and
+// they never touch the emulated frame, so framebuffer hashes, movies and
+// screenshots of GetOutputBuffer are unaffected. The zero value is ready to
+// use and safe for concurrent use.
+type Notifications struct {
+	mu    sync.Mutex
+	items []notification
+	// now replaces time.Now in tests.
+	now func() time.Time
+}
+
+type notification struct {
+	text  string
+	shown time.Time
+}
+
+func (n *Notifications) clock() time.Time {
+	if n.now != nil {
+		return n.now()
+	}
+	return time.Now()
+}
+
+// Push shows text as a new notification, dropping the oldest when the
+// queue is full.
+func (n *Notifications) Push(text string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.items = append(n.items, notification{text: text, shown: n.clock()})
+	if len(n.items) > maxNotifications {
+		n.items = n.items[len(n.items)-maxNotifications:]
+	}
+}
+
+// Active returns the text of the notifications still on screen, newest
+// first.
+func (n *Notifications) Active() []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.expireLocked(n.clock())
+	texts := make([]string, len(n.items))
+	for i, item := range n.items {
+		texts[len(n.items)-1-i] = item.text
+	}
+	return texts
+}
+
+func (n *Notifications) expireLocked(now time.Time) {
+	keep := n.items[:0]
+	for _, item := range n.items {
+		if now.Sub(item.shown) < NotificationDuration {
+			keep = append(keep, item)
+		}
+	}
+	n.items = keep
+}
+
+// Draw draws the active notifications into pixels, a w x h frame of
+// 0xRRGGBB values, in its top right corner. Text that does not fit the
+// frame's width is cut off.
+func (n *Notifications) Draw(pixels []uint32, w, h int) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	now := n.clock()
+	n.expireLocked(now)
+	for i := range n.items {
+		item := n.items[len(n.items)-1-i]
+		alpha := 256
+		if left := NotificationDuration - now.Sub(item.shown); left < notificationFade {
+			alpha = int(256 * left / notificationFade)
+		}
+		top := notificationMargin + i*notificationLineH
+		if top+notificationLineH > h {
+			break
+		}
+		chars := min(len([]rune(item.text)), (w-2*notificationMargin-2*notificationPadding)/8)
+		if chars <= 0 {
+			break
+		}
+		boxW := chars*8 + 2*notificationPadding
+		left := w - notificationMargin - boxW
+		boxAlpha := notificationBoxAlpha * alpha / 256
+		for y := top; y < top+notificationLineH-1; y++ {
+			for x := left; x < left+boxW; x++ {
+				pixels[y*w+x] = blendRGB(pixels[y*w+x], notificationBox, boxAlpha)
+			}
+		}
+		x := left + notificationPadding
+		for _, r := range []rune(item.text)[:chars] {
+			if glyph, ok := ppu.Glyph(r); ok {
+				for row := 0; row < 8; row++ {
+					for col := 0; col < 8; col++ {
+						if glyph[row]>>col&1 != 0 {
+							o := (top+notificationPadding+row)*w + x + col
+							pixels[o] = blendRGB(pixels[o], notificationText, alpha)
+						}
+					}
+				}
+			}
+			x += 8
+		}
+	}
+}
+
+// blendRGB mixes src over dst with alpha out of 256.
+func blendRGB(dst, src uint32, alpha int) uint32 {
+	a := uint32(alpha)
+	var out uint32
+	for shift := 0; shift <= 16; shift += 8 {
+		d := dst >> shift & 0xFF
+		s := src >> shift & 0xFF
+		out |= (d*(256-a) + s*a) >> 8 << shift
+	}
+	return out
+}
+
+// Notify shows text as an on-screen notification in the frames DisplayFrame
+// produces. Hosts, Dev Kit backends and frame plugins use it to confirm
+// actions such as saving state or starting a recording.
+func (e *Emulator) Notify(text string) {
+	e.OSD.Push(text)
+}
@@ -0,0 +1,60 @@
+package emulator
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestNotificationsDrawAndFade(t *testing.T) {
+	emu := NewEmulator()
+	if err := emu.LoadROM(spinROM(t)); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1000, 0)
+	emu.OSD.now = func() time.Time { return now }
+	emu.Notify("State saved")
+
+	frame, w, _ := emu.DisplayFrame(nil)
+	// "S" is the first glyph: its top row lights columns 1-4 of the cell.
+	textX := w - notificationMargin - (11*8 + notificationPadding)
+	textY := notificationMargin + notificationPadding
+	if got := frame[textY*w+textX+1]; got != notificationText {
+		t.Errorf("glyph pixel = %06X, want %06X", got, notificationText)
+	}
+	if got := frame[textY*w+textX]; got == notificationText || got == 0 {
+		t.Errorf("box pixel = %06X, want the dimmed box color", got)
+	}
+	for _, px := range emu.GetOutputBuffer() {
+		if px == notificationText {
+			t.Fatal("notification drawn into the emulated frame")
+		}
+	}
+
+	now = now.Add(NotificationDuration - notificationFade/2)
+	frame, _, _ = emu.DisplayFrame(frame)
+	if got := frame[textY*w+textX+1]; got == notificationText || got == 0 {
+		t.Errorf("fading glyph pixel = %06X, want partly faded", got)
+	}
+
+	now = now.Add(notificationFade)
+	frame, _, _ = emu.DisplayFrame(frame)
+	if got := frame[textY*w+textX+1]; got != 0 {
+		t.Errorf("expired notification still drawn: %06X", got)
+	}
+	if active := emu.OSD.Active(); len(active) != 0 {
+		t.Errorf("active = %q after expiry", active)
+	}
+}
+
+func TestNotificationsKeepNewest(t *testing.T) {
+	var n Notifications
+	for i := 1; i <= maxNotifications+2; i++ {
+		n.Push(fmt.Sprintf("slot %d", i))
+	}
+	want := []string{"slot 6", "slot 5", "slot 4", "slot 3"}
+	if got := n.Active(); !slices.Equal(got, want) {
+		t.Errorf("active = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,175 @@
+package emulator
+
+import (
+	"fmt"
+	"runtime"
+	"time"
+)
+
+// FramePacing selects how a host loop waits for its next frame: the frame
+// limiter in RunFrame, and the emulator window's update loop (FramePacer).
+type FramePacing uint8
+
+const (
+	// PacingSleep sleeps until the next frame is due. It uses the least
+	// CPU, but the OS may wake it a millisecond or more late, which shows
+	// as judder on machines with a coarse timer.
+	PacingSleep FramePacing = iota
+	// PacingVSync ticks at the display's refresh rate, as the host reports
+	// it (the emulator window asks SDL), so presented frames line up with
+	// the monitor's refreshes instead of beating against them. Waits are
+	// hybrid. Without a known refresh rate it ticks at the frame rate.
+	PacingVSync
+	// PacingHybrid sleeps until hybridSpinWindow before the frame is due
+	// and then spins, for accurate frame times at the cost of some CPU.
+	PacingHybrid
+)
+
+// hybridSpinWindow is how long before a deadline hybrid pacing stops
+// sleeping and spins: longer than the timer slack of common desktop OSes.
+const hybridSpinWindow = 2 * time.Millisecond
+
+func (p FramePacing) String() string {
+	switch p {
+	case PacingVSync:
+		return "vsync"
+	case PacingHybrid:
+		return "hybrid"
+	}
+	return "sleep"
+}
+
+// ParseFramePacing parses "sleep", "vsync" or "hybrid".
+func ParseFramePacing(s string) (FramePacing, error) {
+	switch s {
+	case "sleep":
+		return PacingSleep, nil
+	case "vsync":
+		return PacingVSync, nil
+	case "hybrid":
+		return PacingHybrid, nil
+	}
+	return PacingSleep, fmt.Errorf("frame pacing %q: want sleep, vsync or hybrid", s)
+}
+
+// hostClock is the wall clock pacing waits on; tests replace it.
+type hostClock struct {
+	now   func() time.Time
+	sleep func(time.Duration)
+}
+
+var realClock = hostClock{now: time.Now, sleep: time.Sleep}
+
+// waitUntil blocks until deadline with pacing p's strategy and returns
+// the time it woke.
+func (c hostClock) waitUntil(p FramePacing, deadline time.Time) time.Time {
+	now := c.now()
+	if p == PacingSleep {
+		if d := deadline.Sub(now); d > 0 {
+			c.sleep(d)
+		}
+		return c.now()
+	}
+	if d := deadline.Sub(now) - hybridSpinWindow; d > 0 {
+		c.sleep(d)
+		now = c.now()
+	}
+	for now.Before(deadline) {
+		runtime.Gosched()
+		now = c.now()
+	}
+	return now
+}
+
+// FramePacer produces evenly spaced ticks for a host loop. Each tick is
+// due one Interval after the previous one was due, not after it was
+// served, so a late wake-up does not push every later frame back. A loop
+// that falls more than an Interval behind drops the missed ticks instead
+// of running them back to back.
+type FramePacer struct {
+	Pacing   FramePacing
+	Interval time.Duration
+	next     time.Time
+	clock    hostClock
+}
+
+// NewFramePacer returns a pacer ticking every interval with pacing.
+func NewFramePacer(pacing FramePacing, interval time.Duration) *FramePacer {
+	return &FramePacer{Pacing: pacing, Interval: interval, clock: realClock}
+}
+
+// Wait blocks until the next tick is due and returns the time it woke.
+func (p *FramePacer) Wait() time.Time {
+	if p.next.IsZero() {
+		p.next = p.clock.now()
+	}
+	p.next = p.next.Add(p.Interval)
+	now := p.clock.waitUntil(p.Pacing, p.next)
+	if now.Sub(p.next) > p.Interval {
+		p.next = now
+	}
+	return now
+}
+
+// FrameSkipper is automatic frame-skip for a host loop. While the loop
+// keeps falling behind the emulated clock, it presents only one frame of
+// every Level()+1 emulated, so the time drawing would take goes to
+// emulation instead; once the loop keeps up again the level steps back
+// down. Skipping never changes what is emulated, only what is drawn.
+type FrameSkipper struct {
+	// Max is the most frames skipped per frame presented; 0 disables
+	// frame-skip.
+	Max int
+
+	level      int
+	lateTicks  int
+	onTime     int
+	sinceShown int
+}
+
+// A FrameSkipper raises its level after skipRaiseTicks late ticks in a row
+// and lowers it after skipLowerTicks on-time ticks in a row, so one slow
+// tick does not start skipping and recovery is not mistaken for headroom.
+const (
+	skipRaiseTicks = 2
+	skipLowerTicks = 60
+)
+
+// Level returns how many frames are skipped per frame presented.
+func (f *FrameSkipper) Level() int {
+	return f.level
+}
+
+// Present records frames emulated frames and reports whether the loop
+// should draw the latest one.
+func (f *FrameSkipper) Present(frames int) bool {
+	if frames <= 0 {
+		return false
+	}
+	f.sinceShown += frames
+	if f.sinceShown <= f.level {
+		return false
+	}
+	f.sinceShown = 0
+	return true
+}
+
+// EndTick records whether the loop ended a tick still owing emulated
+// frames, and adjusts the level.
+func (f *FrameSkipper) EndTick(behind bool) {
+	if behind {
+		f.onTime = 0
+		f.lateTicks++
+		if f.lateTicks >= skipRaiseTicks && f.level < f.Max {
+			f.level++
+			f.lateTicks = 0
+		}
+		return
+	}
+	f.lateTicks = 0
+	f.onTime++
+	if f.onTime >= skipLowerTicks && f.level > 0 {
+		f.level--
+		f.onTime = 0
+	}
+}
@@ -0,0 +1,119 @@
+package emulator
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeClock is a hostClock whose sleeps oversleep by slack and whose every
+// reading advances by tick, so spinning makes progress.
+type fakeClock struct {
+	t     time.Time
+	slack time.Duration
+	tick  time.Duration
+	slept []time.Duration
+}
+
+func (c *fakeClock) clock() hostClock {
+	return hostClock{
+		now: func() time.Time {
+			c.t = c.t.Add(c.tick)
+			return c.t
+		},
+		sleep: func(d time.Duration) {
+			c.slept = append(c.slept, d)
+			c.t = c.t.Add(d + c.slack)
+		},
+	}
+}
+
+func TestParseFramePacing(t *testing.T) {
+	for _, p := range []FramePacing{PacingSleep, PacingVSync, PacingHybrid} {
+		got, err := ParseFramePacing(p.String())
+		if err != nil || got != p {
+			t.Errorf("ParseFramePacing(%q) = %v, %v", p.String(), got, err)
+		}
+	}
+	if _, err := ParseFramePacing("fast"); err == nil {
+		t.Error("ParseFramePacing(fast) succeeded")
+	}
+}
+
+func TestFramePacerHybridIsOnTime(t *testing.T) {
+	const interval = time.Second / 60
+	for _, tc := range []struct {
+		pacing  FramePacing
+		maxLate time.Duration
+	}{
+		// An OS that wakes sleepers 1.5 ms late makes sleep pacing late
+		// by that much and hybrid pacing late by at most one clock read.
+		{PacingSleep, 1500 * time.Microsecond},
+		{PacingHybrid, 10 * time.Microsecond},
+	} {
+		c := &fakeClock{t: time.Unix(0, 0), slack: 1500 * time.Microsecond, tick: 10 * time.Microsecond}
+		p := &FramePacer{Pacing: tc.pacing, Interval: interval, clock: c.clock()}
+		start := c.clock().now()
+		for i := 1; i <= 120; i++ {
+			woke := p.Wait()
+			due := start.Add(time.Duration(i) * interval)
+			if late := woke.Sub(due); late < 0 || late > tc.maxLate+2*c.tick {
+				t.Fatalf("%s tick %d woke %v after it was due, want 0-%v", tc.pacing, i, late, tc.maxLate)
+			}
+		}
+	}
+}
+
+func TestFramePacerDropsMissedTicks(t *testing.T) {
+	c := &fakeClock{t: time.Unix(0, 0)}
+	p := &FramePacer{Pacing: PacingSleep, Interval: 10 * time.Millisecond, clock: c.clock()}
+	p.Wait()
+	c.t = c.t.Add(100 * time.Millisecond) // the loop stalls for ten ticks
+	p.Wait()
+	c.slept = nil
+	p.Wait()
+	if len(c.slept) != 1 || c.slept[0] != 10*time.Millisecond {
+		t.Fatalf("after a stall the pacer slept %v, want one full interval", c.slept)
+	}
+}
+
+func TestFrameSkipperRaisesAndLowersLevel(t *testing.T) {
+	f := &FrameSkipper{Max: 2}
+	if !f.Present(1) || f.Level() != 0 {
+		t.Fatalf("a new skipper should present every frame")
+	}
+	f.EndTick(true)
+	if f.Level() != 0 {
+		t.Fatalf("one late tick raised the level to %d", f.Level())
+	}
+	for i := 0; i < 10; i++ {
+		f.EndTick(true)
+	}
+	if f.Level() != 2 {
+		t.Fatalf("level = %d after many late ticks, want Max (2)", f.Level())
+	}
+
+	presented := 0
+	for i := 0; i < 9; i++ {
+		if f.Present(1) {
+			presented++
+		}
+	}
+	if presented != 3 {
+		t.Errorf("presented %d of 9 frames at level 2, want 3", presented)
+	}
+
+	for i := 0; i < skipLowerTicks; i++ {
+		f.EndTick(false)
+	}
+	if f.Level() != 1 {
+		t.Fatalf("level = %d after %d on-time ticks, want 1", f.Level(), skipLowerTicks)
+	}
+
+	off := &FrameSkipper{}
+	for i := 0; i < 10; i++ {
+		off.EndTick(true)
+	}
+	if off.Level() != 0 || !off.Present(1) {
+		t.Fatalf("a skipper with Max 0 skipped frames")
+	}
+}
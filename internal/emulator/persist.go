@@ -0,0 +1,191 @@
+package emulator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"nitro-core-dx/internal/hwdef"
+)
+
+// PersistMaxKey is the longest key the mailbox accepts; further key bytes
+// are dropped.
+const PersistMaxKey = 16
+
+// PersistStore is a host-side key/value store for small game data (high
+// scores, settings) that lets a ROM save without a cartridge SRAM mapper.
+// Values are 16-bit and stored per ROM in Dir/<sha256 of the ROM>.json.
+//
+// The ROM talks to it through the PERSIST_* mailbox (0xA010-0xA013):
+//
+//	PERSIST_KEY        - write key bytes, one per write
+//	PERSIST_VALUE_L/H  - value to store, or the value a LOAD returned
+//	PERSIST_CONTROL    - write bit 0 LOAD, bit 1 STORE; read bit 0 FOUND,
+//	                     bit 7 AVAILABLE
+//
+// Each command consumes the key written since the previous command. A LOAD
+// of a key that was never stored returns 0 and clears FOUND. The store is
+// not emulated hardware: it is not reset by power cycles and is not part of
+// save states.
+type PersistStore struct {
+	// Dir holds one JSON file per ROM.
+	Dir string
+
+	romHash string
+	values  map[string]uint16
+	key     []byte
+	value   uint16
+	found   bool
+	err     error
+}
+
+// NewPersistStore returns a store that keeps its files in dir.
+func NewPersistStore(dir string) *PersistStore {
+	return &PersistStore{Dir: dir, values: make(map[string]uint16)}
+}
+
+// Open switches the store to the ROM with the given image, loading any
+// values saved for it. A missing file is an empty store; an unreadable one
+// is reported and also kept in Err.
+func (p *PersistStore) Open(romImage []byte) error {
+	sum := sha256.Sum256(romImage)
+	p.romHash = hex.EncodeToString(sum[:])
+	p.values = make(map[string]uint16)
+	p.key = p.key[:0]
+	p.value = 0
+	p.found = false
+	p.err = p.load()
+	return p.err
+}
+
+func (p *PersistStore) load() error {
+	raw, err := os.ReadFile(p.path())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("persist: %w", err)
+	}
+	if err := json.Unmarshal(raw, &p.values); err != nil {
+		return fmt.Errorf("persist: %s: %w", p.path(), err)
+	}
+	return nil
+}
+
+// Values returns a copy of the current ROM's saved values.
+func (p *PersistStore) Values() map[string]uint16 {
+	values := make(map[string]uint16, len(p.values))
+	for k, v := range p.values {
+		values[k] = v
+	}
+	return values
+}
+
+// Err returns the last error loading or saving the file, if any. The ROM
+// cannot see host I/O errors, so the frontend reports them.
+func (p *PersistStore) Err() error {
+	return p.err
+}
+
+func (p *PersistStore) path() string {
+	return filepath.Join(p.Dir, p.romHash+".json")
+}
+
+// save writes the current ROM's values, replacing the file atomically so a
+// crash never leaves it half-written.
+func (p *PersistStore) save() error {
+	if p.romHash == "" {
+		return errors.New("persist: no ROM loaded")
+	}
+	raw, err := json.MarshalIndent(p.values, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(p.Dir, 0o755); err != nil {
+		return fmt.Errorf("persist: %w", err)
+	}
+	tmp := p.path() + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o644); err != nil {
+		return fmt.Errorf("persist: %w", err)
+	}
+	if err := os.Rename(tmp, p.path()); err != nil {
+		return fmt.Errorf("persist: %w", err)
+	}
+	return nil
+}
+
+// Read8 reads a mailbox register; offset is relative to hwdef.InputBase.
+func (p *PersistStore) Read8(offset uint16) uint8 {
+	switch offset + hwdef.InputBase {
+	case hwdef.PERSIST_VALUE_L:
+		return uint8(p.value)
+	case hwdef.PERSIST_VALUE_H:
+		return uint8(p.value >> 8)
+	case hwdef.PERSIST_CONTROL:
+		status := uint8(0x80)
+		if p.found {
+			status |= 0x01
+		}
+		return status
+	}
+	return 0
+}
+
+// Write8 writes a mailbox register; offset is relative to hwdef.InputBase.
+func (p *PersistStore) Write8(offset uint16, value uint8) {
+	switch offset + hwdef.InputBase {
+	case hwdef.PERSIST_KEY:
+		if len(p.key) < PersistMaxKey {
+			p.key = append(p.key, value)
+		}
+	case hwdef.PERSIST_VALUE_L:
+		p.value = (p.value & 0xFF00) | uint16(value)
+	case hwdef.PERSIST_VALUE_H:
+		p.value = (p.value & 0x00FF) | uint16(value)<<8
+	case hwdef.PERSIST_CONTROL:
+		key := string(p.key)
+		p.key = p.key[:0]
+		switch {
+		case value&0x02 != 0:
+			p.values[key] = p.value
+			p.found = true
+			p.err = p.save()
+		case value&0x01 != 0:
+			p.value, p.found = p.values[key]
+		}
+	}
+}
+
+// Read16 reads two consecutive mailbox registers.
+func (p *PersistStore) Read16(offset uint16) uint16 {
+	return uint16(p.Read8(offset)) | uint16(p.Read8(offset+1))<<8
+}
+
+// Write16 writes two consecutive mailbox registers.
+func (p *PersistStore) Write16(offset uint16, value uint16) {
+	p.Write8(offset, uint8(value))
+	p.Write8(offset+1, uint8(value>>8))
+}
+
+// EnablePersistence attaches a PersistStore keeping its files in dir. ROMs
+// loaded afterwards (and the current one, if any) see the PERSIST_*
+// mailbox.
+func (e *Emulator) EnablePersistence(dir string) error {
+	e.Persist = NewPersistStore(dir)
+	e.Bus.PersistHandler = e.Persist
+	if e.romImage != nil {
+		return e.Persist.Open(e.romImage)
+	}
+	return nil
+}
+
+// DisablePersistence detaches the store, leaving the mailbox unmapped like
+// real hardware: reads return 0, so AVAILABLE is clear.
+func (e *Emulator) DisablePersistence() {
+	e.Persist = nil
+	e.Bus.PersistHandler = nil
+}
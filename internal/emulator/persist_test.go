@@ -0,0 +1,112 @@
+package emulator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"nitro-core-dx/internal/hwdef"
+)
+
+// persistTestROM is a minimal ROM whose last byte varies, so tests can load
+// ROMs with different hashes.
+func persistTestROM(id uint8) []uint8 {
+	romData := make([]uint8, 64)
+	copy(romData, "RMCF")
+	romData[4] = 0x01  // Version 1
+	romData[6] = 0x20  // ROM size 32
+	romData[10] = 0x01 // Entry bank 1
+	romData[13] = 0x80 // Entry offset 0x8000
+	romData[63] = id
+	return romData
+}
+
+func persistCommand(emu *Emulator, key string, command uint8) {
+	for i := 0; i < len(key); i++ {
+		emu.Bus.Write8(0, hwdef.PERSIST_KEY, key[i])
+	}
+	emu.Bus.Write8(0, hwdef.PERSIST_CONTROL, command)
+}
+
+func persistValue(emu *Emulator) uint16 {
+	return uint16(emu.Bus.Read8(0, hwdef.PERSIST_VALUE_L)) | uint16(emu.Bus.Read8(0, hwdef.PERSIST_VALUE_H))<<8
+}
+
+func TestPersistStoreSurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+	emu := NewEmulator()
+	if err := emu.EnablePersistence(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := emu.LoadROM(persistTestROM(1)); err != nil {
+		t.Fatal(err)
+	}
+	if emu.Bus.Read8(0, hwdef.PERSIST_CONTROL)&0x80 == 0 {
+		t.Fatal("AVAILABLE clear with persistence enabled")
+	}
+	emu.Bus.Write8(0, hwdef.PERSIST_VALUE_L, 0x39)
+	emu.Bus.Write8(0, hwdef.PERSIST_VALUE_H, 0x30)
+	persistCommand(emu, "hiscore", 0x02)
+	if err := emu.Persist.Err(); err != nil {
+		t.Fatalf("store: %v", err)
+	}
+
+	// A fresh emulator loading the same ROM sees the value; another ROM
+	// does not.
+	emu = NewEmulator()
+	if err := emu.LoadROM(persistTestROM(1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := emu.EnablePersistence(dir); err != nil {
+		t.Fatal(err)
+	}
+	persistCommand(emu, "hiscore", 0x01)
+	if got := persistValue(emu); got != 0x3039 {
+		t.Errorf("hiscore = 0x%04X, want 0x3039", got)
+	}
+	if emu.Bus.Read8(0, hwdef.PERSIST_CONTROL)&0x01 == 0 {
+		t.Error("FOUND clear after loading a saved key")
+	}
+	persistCommand(emu, "missing", 0x01)
+	if got := persistValue(emu); got != 0 || emu.Bus.Read8(0, hwdef.PERSIST_CONTROL)&0x01 != 0 {
+		t.Errorf("missing key: value %d, status 0x%02X", got, emu.Bus.Read8(0, hwdef.PERSIST_CONTROL))
+	}
+
+	if err := emu.LoadROM(persistTestROM(2)); err != nil {
+		t.Fatal(err)
+	}
+	persistCommand(emu, "hiscore", 0x01)
+	if got := persistValue(emu); got != 0 {
+		t.Errorf("other ROM hiscore = %d, want 0", got)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 1 {
+		t.Errorf("save files = %v, want one", files)
+	}
+}
+
+func TestPersistenceDisabledIsUnmapped(t *testing.T) {
+	dir := t.TempDir()
+	emu := NewEmulator()
+	if err := emu.LoadROM(persistTestROM(1)); err != nil {
+		t.Fatal(err)
+	}
+	emu.Bus.Write8(0, hwdef.PERSIST_VALUE_L, 7)
+	persistCommand(emu, "hiscore", 0x02)
+	if status := emu.Bus.Read8(0, hwdef.PERSIST_CONTROL); status != 0 {
+		t.Errorf("PERSIST_CONTROL = 0x%02X with persistence disabled, want 0", status)
+	}
+	if got := persistValue(emu); got != 0 {
+		t.Errorf("value = %d with persistence disabled, want 0", got)
+	}
+
+	if err := emu.EnablePersistence(dir); err != nil {
+		t.Fatal(err)
+	}
+	emu.DisablePersistence()
+	persistCommand(emu, "hiscore", 0x02)
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("disabled store wrote %d files", len(entries))
+	}
+}
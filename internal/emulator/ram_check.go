@@ -0,0 +1,83 @@
+package emulator
+
+import (
+	"fmt"
+
+	"nitro-core-dx/internal/debug"
+	"nitro-core-dx/internal/memory"
+)
+
+// maxUninitReads bounds the uninitialized-read report; a program that
+// reads a whole uninitialized buffer would otherwise flood it.
+const maxUninitReads = 256
+
+// UninitRead records the first read of one never-written work RAM byte.
+type UninitRead struct {
+	Bank     uint8
+	Offset   uint16
+	PCBank   uint8 // CPU program counter at the time of the read
+	PCOffset uint16
+	Frame    uint64
+}
+
+func (r UninitRead) String() string {
+	return fmt.Sprintf("%02X:%04X read before written (PC %02X:%04X, frame %d)", r.Bank, r.Offset, r.PCBank, r.PCOffset, r.Frame)
+}
+
+// SetRAMPattern chooses what work RAM, extended work RAM and VRAM hold
+// after a power cycle (Reset), and fills them with it now, so setting it
+// before LoadROM covers the first boot as well.
+func (e *Emulator) SetRAMPattern(p memory.RAMPattern) {
+	e.Bus.RAMPattern = p
+	p.Fill(e.Bus.WRAM[:])
+	p.Fill(e.Bus.WRAMExtended[:])
+	p.Fill(e.PPU.VRAM[:])
+}
+
+// EnableUninitReadCheck switches the uninitialized-read debug mode on or
+// off. While on, every read of a work RAM byte that has not been written
+// since power-on is recorded once per address (see UninitReads) and logged
+// as a memory warning with the PC responsible. Bytes written before the
+// check was enabled still count as written.
+func (e *Emulator) EnableUninitReadCheck(enabled bool) {
+	if !enabled {
+		e.Bus.UninitRead = nil
+		return
+	}
+	if e.uninitSeen == nil {
+		e.uninitSeen = make(map[uint32]bool)
+	}
+	e.Bus.UninitRead = e.recordUninitRead
+}
+
+// UninitReads returns the uninitialized reads recorded so far, oldest
+// first, up to the first 256 addresses.
+func (e *Emulator) UninitReads() []UninitRead {
+	return append([]UninitRead(nil), e.uninitReads...)
+}
+
+// ClearUninitReads forgets the recorded reads, so each address reports
+// again on its next uninitialized read.
+func (e *Emulator) ClearUninitReads() {
+	e.uninitReads = nil
+	e.uninitSeen = make(map[uint32]bool)
+}
+
+func (e *Emulator) recordUninitRead(bank uint8, offset uint16) {
+	addr := uint32(bank)<<16 | uint32(offset)
+	if e.uninitSeen[addr] || len(e.uninitReads) >= maxUninitReads {
+		return
+	}
+	e.uninitSeen[addr] = true
+	r := UninitRead{
+		Bank:     bank,
+		Offset:   offset,
+		PCBank:   e.CPU.State.PCBank,
+		PCOffset: e.CPU.State.PCOffset,
+		Frame:    e.FrameCount,
+	}
+	e.uninitReads = append(e.uninitReads, r)
+	if e.Logger != nil {
+		e.Logger.LogMemory(debug.LogLevelWarning, r.String(), nil)
+	}
+}
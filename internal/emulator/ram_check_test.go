@@ -0,0 +1,67 @@
+package emulator
+
+import (
+	"testing"
+
+	"nitro-core-dx/internal/memory"
+)
+
+func TestSetRAMPatternFillsRAMAndVRAM(t *testing.T) {
+	emu := NewEmulator()
+	emu.SetRAMPattern(memory.RAMPattern{Kind: memory.RAMPatternOnes})
+	if emu.Bus.WRAM[0x1234] != 0xFF || emu.Bus.WRAMExtended[0x10000] != 0xFF || emu.PPU.VRAM[0x4000] != 0xFF {
+		t.Fatal("SetRAMPattern did not fill WRAM, extended WRAM and VRAM")
+	}
+	emu.PPU.VRAM[0x4000] = 0
+	emu.Reset()
+	if emu.Bus.WRAM[0x1234] != 0xFF || emu.PPU.VRAM[0x4000] != 0xFF {
+		t.Fatal("power cycle did not reapply the RAM pattern")
+	}
+}
+
+// Without a RAM pattern set, a power cycle leaves RAM as the first boot did.
+func TestDefaultPowerCycleMatchesFirstBoot(t *testing.T) {
+	emu := NewEmulator()
+	if err := emu.LoadROM(persistTestROM(1)); err != nil {
+		t.Fatal(err)
+	}
+	wram, wramExt, vram := emu.Bus.WRAM, emu.Bus.WRAMExtended, emu.PPU.VRAM
+	emu.Reset()
+	if emu.Bus.WRAM != wram || emu.Bus.WRAMExtended != wramExt || emu.PPU.VRAM != vram {
+		t.Error("WRAM or VRAM after Reset differs from the first boot")
+	}
+}
+
+func TestUninitReadCheckRecordsPC(t *testing.T) {
+	emu := NewEmulator()
+	if err := emu.LoadROM(persistTestROM(1)); err != nil {
+		t.Fatal(err)
+	}
+	emu.EnableUninitReadCheck(true)
+	emu.Bus.Write8(0, 0x0200, 1)
+	emu.Bus.Read8(0, 0x0200)
+	emu.Bus.Read8(0, 0x0300)
+	emu.Bus.Read8(0, 0x0300)
+
+	reads := emu.UninitReads()
+	if len(reads) != 1 {
+		t.Fatalf("got %d reads, want 1 (one per address): %v", len(reads), reads)
+	}
+	if r := reads[0]; r.Offset != 0x0300 || r.PCBank != 1 || r.PCOffset != 0x8000 {
+		t.Errorf("read = %+v, want 00:0300 at PC 01:8000", r)
+	}
+
+	// A save state replaces RAM wholesale; none of it counts as uninitialized.
+	data, err := emu.SaveState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	emu.ClearUninitReads()
+	if err := emu.LoadState(data); err != nil {
+		t.Fatal(err)
+	}
+	emu.Bus.Read8(0, 0x0400)
+	if len(emu.UninitReads()) != 0 {
+		t.Errorf("read after LoadState reported as uninitialized")
+	}
+}
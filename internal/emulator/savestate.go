@@ -1,10 +1,10 @@
 package emulator
 
 import (
-	"bytes"
 	"encoding/gob"
 	"fmt"
 	"os"
+	"slices"
 
 	"nitro-core-dx/internal/apu"
 	"nitro-core-dx/internal/cpu"
@@ -27,14 +27,18 @@ func init() {
 	gob.Register(apu.AudioChannel{})
 }
 
+// Save state format versions. Versions 1 and 2 were a single gob-encoded
+// SaveState (2 added the matrix plane and DMA registers); version 3 is the
+// chunked format in savestate_format.go, with a version per component.
 const (
 	saveStateVersion1 uint16 = 1
 	saveStateVersion2 uint16 = 2
+	saveStateVersion3 uint16 = 3
 )
 
 // SaveState represents a complete emulator state snapshot
 type SaveState struct {
-	// Version for compatibility checking
+	// Version is the save state format version
 	Version uint16
 
 	// CPU state
@@ -98,6 +102,8 @@ type PPUState struct {
 	DMAFillValue           uint8
 	OAMAddr                uint8
 	OAMByteIndex           uint8
+	BorderColor            uint8
+	LineScrollTable        uint16
 }
 
 // APUState represents APU state for save/load
@@ -105,12 +111,17 @@ type APUState struct {
 	Channels                [4]apu.AudioChannel
 	MasterVolume            uint8
 	ChannelCompletionStatus uint8
+	Sample                  apu.SampleChannel
+	Effects                 apu.MasterEffects
 }
 
 // MemoryState represents Memory state for save/load
 type MemoryState struct {
 	WRAM         [32768]uint8
 	WRAMExtended [131072]uint8
+	ResetCause   uint8
+	WaitCycles   uint32 // bus contention cycles the CPU has yet to take
+	OpenBus      uint8  // what an unmapped read returns
 }
 
 // InputState represents Input state for save/load
@@ -123,11 +134,11 @@ type InputState struct {
 	Controller2LatchState bool
 }
 
-// SaveState saves the current emulator state to a byte slice
-func (e *Emulator) SaveState() ([]byte, error) {
-	// Create save state structure
-	state := SaveState{
-		Version:     saveStateVersion2,
+// CaptureState returns the current emulator state as SaveState would
+// serialize it, without encoding it.
+func (e *Emulator) CaptureState() SaveState {
+	return SaveState{
+		Version:     saveStateVersion3,
 		CPUState:    e.CPU.State,
 		PPUState:    e.savePPUState(),
 		APUState:    e.saveAPUState(),
@@ -136,39 +147,33 @@ func (e *Emulator) SaveState() ([]byte, error) {
 		Running:     e.Running,
 		Paused:      e.Paused,
 	}
+}
 
-	// Serialize using gob
-	var buf bytes.Buffer
-	encoder := gob.NewEncoder(&buf)
-	if err := encoder.Encode(state); err != nil {
+// SaveState saves the current emulator state to a byte slice
+func (e *Emulator) SaveState() ([]byte, error) {
+	data, err := encodeSaveState(e.CaptureState())
+	if err != nil {
 		return nil, fmt.Errorf("failed to encode save state: %w", err)
 	}
-
-	return buf.Bytes(), nil
+	return data, nil
 }
 
-// LoadState loads an emulator state from a byte slice
+// LoadState loads an emulator state from a byte slice. States saved by
+// earlier emulator versions are migrated to the current layout first.
 func (e *Emulator) LoadState(data []byte) error {
-	// Deserialize using gob
-	buf := bytes.NewBuffer(data)
-	decoder := gob.NewDecoder(buf)
-
-	var state SaveState
-	if err := decoder.Decode(&state); err != nil {
-		return fmt.Errorf("failed to decode save state: %w", err)
-	}
-
-	// Check version compatibility
-	if state.Version != saveStateVersion1 && state.Version != saveStateVersion2 {
-		return fmt.Errorf("unsupported save state version: %d (expected %d or %d)", state.Version, saveStateVersion1, saveStateVersion2)
+	state, err := decodeSaveState(data)
+	if err != nil {
+		return err
 	}
 
 	// Restore state
 	e.CPU.State = state.CPUState
-	e.loadPPUState(state.PPUState, state.Version)
+	e.loadPPUState(state.PPUState)
 	e.loadAPUState(state.APUState)
 	e.loadMemoryState(state.MemoryState)
 	e.loadInputState(state.InputState)
+	e.clearWriteHistory()
+	e.clearCallStack()
 	e.Running = state.Running
 	e.Paused = state.Paused
 
@@ -177,59 +182,66 @@ func (e *Emulator) LoadState(data []byte) error {
 
 // savePPUState extracts PPU state for saving
 func (e *Emulator) savePPUState() PPUState {
+	return ppuStateOf(e.PPU)
+}
+
+// ppuStateOf extracts p's saved state.
+func ppuStateOf(p *ppu.PPU) PPUState {
 	var transformChannels [4]ppu.TransformChannel
 	for i := 0; i < ppu.NumTransformChannels && i < 4; i++ {
-		transformChannels[i] = e.PPU.TransformChannels[i]
+		transformChannels[i] = p.TransformChannels[i]
 	}
 	return PPUState{
-		VRAM:                   e.PPU.VRAM,
-		CGRAM:                  e.PPU.CGRAM,
-		OAM:                    e.PPU.OAM,
-		BG0:                    e.PPU.BG0,
-		BG1:                    e.PPU.BG1,
-		BG2:                    e.PPU.BG2,
-		BG3:                    e.PPU.BG3,
+		VRAM:                   p.VRAM,
+		CGRAM:                  p.CGRAM,
+		OAM:                    p.OAM,
+		BG0:                    p.BG0,
+		BG1:                    p.BG1,
+		BG2:                    p.BG2,
+		BG3:                    p.BG3,
 		TransformChannels:      transformChannels,
-		MatrixPlanes:           e.PPU.MatrixPlanes,
-		Window0:                e.PPU.Window0,
-		Window1:                e.PPU.Window1,
-		WindowControl:          e.PPU.WindowControl,
-		WindowMainEnable:       e.PPU.WindowMainEnable,
-		WindowSubEnable:        e.PPU.WindowSubEnable,
-		HDMAEnabled:            e.PPU.HDMAEnabled,
-		HDMATableBase:          e.PPU.HDMATableBase,
-		HDMAControl:            e.PPU.HDMAControl,
-		HDMAExtControl:         e.PPU.HDMAExtControl,
-		FrameCounter:           e.PPU.FrameCounter,
-		VBlankFlag:             e.PPU.VBlankFlag,
-		VRAMAddr:               e.PPU.VRAMAddr,
-		CGRAMAddr:              e.PPU.CGRAMAddr,
-		CGRAMWriteLatch:        e.PPU.CGRAMWriteLatch,
-		CGRAMWriteValue:        e.PPU.CGRAMWriteValue,
-		MatrixPlaneSelect:      e.PPU.MatrixPlaneSelect,
-		MatrixPlaneAddr:        e.PPU.MatrixPlaneAddr,
-		MatrixPlanePatternAddr: e.PPU.MatrixPlanePatternAddr,
-		MatrixPlaneBitmapAddr:  e.PPU.MatrixPlaneBitmapAddr,
-		MatrixPlaneRowAddr:     e.PPU.MatrixPlaneRowAddr,
-		DMAEnabled:             e.PPU.DMAEnabled,
-		DMASourceBank:          e.PPU.DMASourceBank,
-		DMASourceOffset:        e.PPU.DMASourceOffset,
-		DMADestType:            e.PPU.DMADestType,
-		DMADestAddr:            e.PPU.DMADestAddr,
-		DMALength:              e.PPU.DMALength,
-		DMAMode:                e.PPU.DMAMode,
-		DMACycles:              e.PPU.DMACycles,
-		DMAProgress:            e.PPU.DMAProgress,
-		DMACurrentSrc:          e.PPU.DMACurrentSrc,
-		DMACurrentDest:         e.PPU.DMACurrentDest,
-		DMAFillValue:           e.PPU.DMAFillValue,
-		OAMAddr:                e.PPU.OAMAddr,
-		OAMByteIndex:           e.PPU.OAMByteIndex,
+		MatrixPlanes:           p.MatrixPlanes,
+		Window0:                p.Window0,
+		Window1:                p.Window1,
+		WindowControl:          p.WindowControl,
+		WindowMainEnable:       p.WindowMainEnable,
+		WindowSubEnable:        p.WindowSubEnable,
+		HDMAEnabled:            p.HDMAEnabled,
+		HDMATableBase:          p.HDMATableBase,
+		HDMAControl:            p.HDMAControl,
+		HDMAExtControl:         p.HDMAExtControl,
+		FrameCounter:           p.FrameCounter,
+		VBlankFlag:             p.VBlankFlag,
+		VRAMAddr:               p.VRAMAddr,
+		CGRAMAddr:              p.CGRAMAddr,
+		CGRAMWriteLatch:        p.CGRAMWriteLatch,
+		CGRAMWriteValue:        p.CGRAMWriteValue,
+		MatrixPlaneSelect:      p.MatrixPlaneSelect,
+		MatrixPlaneAddr:        p.MatrixPlaneAddr,
+		MatrixPlanePatternAddr: p.MatrixPlanePatternAddr,
+		MatrixPlaneBitmapAddr:  p.MatrixPlaneBitmapAddr,
+		MatrixPlaneRowAddr:     p.MatrixPlaneRowAddr,
+		DMAEnabled:             p.DMAEnabled,
+		DMASourceBank:          p.DMASourceBank,
+		DMASourceOffset:        p.DMASourceOffset,
+		DMADestType:            p.DMADestType,
+		DMADestAddr:            p.DMADestAddr,
+		DMALength:              p.DMALength,
+		DMAMode:                p.DMAMode,
+		DMACycles:              p.DMACycles,
+		DMAProgress:            p.DMAProgress,
+		DMACurrentSrc:          p.DMACurrentSrc,
+		DMACurrentDest:         p.DMACurrentDest,
+		DMAFillValue:           p.DMAFillValue,
+		OAMAddr:                p.OAMAddr,
+		OAMByteIndex:           p.OAMByteIndex,
+		BorderColor:            p.BorderColor,
+		LineScrollTable:        p.LineScrollTable,
 	}
 }
 
 // loadPPUState restores PPU state from saved state
-func (e *Emulator) loadPPUState(state PPUState, version uint16) {
+func (e *Emulator) loadPPUState(state PPUState) {
 	e.PPU.VRAM = state.VRAM
 	e.PPU.CGRAM = state.CGRAM
 	e.PPU.OAM = state.OAM
@@ -240,6 +252,7 @@ func (e *Emulator) loadPPUState(state PPUState, version uint16) {
 	for i := 0; i < ppu.NumTransformChannels && i < 4; i++ {
 		e.PPU.TransformChannels[i] = state.TransformChannels[i]
 	}
+	e.PPU.MatrixPlanes = state.MatrixPlanes
 	e.PPU.Window0 = state.Window0
 	e.PPU.Window1 = state.Window1
 	e.PPU.WindowControl = state.WindowControl
@@ -247,60 +260,25 @@ func (e *Emulator) loadPPUState(state PPUState, version uint16) {
 	e.PPU.WindowSubEnable = state.WindowSubEnable
 	e.PPU.HDMAEnabled = state.HDMAEnabled
 	e.PPU.HDMATableBase = state.HDMATableBase
-	if version >= saveStateVersion2 {
-		e.PPU.MatrixPlanes = state.MatrixPlanes
-		e.PPU.HDMAControl = state.HDMAControl
-		e.PPU.HDMAExtControl = state.HDMAExtControl
-		e.PPU.MatrixPlaneSelect = state.MatrixPlaneSelect
-		e.PPU.MatrixPlaneAddr = state.MatrixPlaneAddr
-		e.PPU.MatrixPlanePatternAddr = state.MatrixPlanePatternAddr
-		e.PPU.MatrixPlaneBitmapAddr = state.MatrixPlaneBitmapAddr
-		e.PPU.MatrixPlaneRowAddr = state.MatrixPlaneRowAddr
-		e.PPU.DMAEnabled = state.DMAEnabled
-		e.PPU.DMASourceBank = state.DMASourceBank
-		e.PPU.DMASourceOffset = state.DMASourceOffset
-		e.PPU.DMADestType = state.DMADestType
-		e.PPU.DMADestAddr = state.DMADestAddr
-		e.PPU.DMALength = state.DMALength
-		e.PPU.DMAMode = state.DMAMode
-		e.PPU.DMACycles = state.DMACycles
-		e.PPU.DMAProgress = state.DMAProgress
-		e.PPU.DMACurrentSrc = state.DMACurrentSrc
-		e.PPU.DMACurrentDest = state.DMACurrentDest
-		e.PPU.DMAFillValue = state.DMAFillValue
-	} else {
-		// Version 1 save states predate matrix-plane serialization. Reset the
-		// newer plane/MMIO state so we don't leak stale runtime data across loads.
-		e.PPU.MatrixPlanes = [ppu.NumTransformChannels]ppu.MatrixPlane{}
-		for i := range e.PPU.MatrixPlanes {
-			e.PPU.MatrixPlanes[i].Size = ppu.TilemapSize32x32
-			e.PPU.MatrixPlanes[i].BaseDistance = 0x0100
-			e.PPU.MatrixPlanes[i].FocalLength = 0x3000
-			e.PPU.MatrixPlanes[i].WidthScale = 0x0100
-			e.PPU.MatrixPlanes[i].HeightScale = 0x0200
-			e.PPU.MatrixPlanes[i].HeadingY = -0x0100
-			e.PPU.MatrixPlanes[i].FacingY = -0x0100
-		}
-		e.PPU.HDMAControl = 0
-		e.PPU.HDMAExtControl = 0
-		e.PPU.MatrixPlaneSelect = 0
-		e.PPU.MatrixPlaneAddr = 0
-		e.PPU.MatrixPlanePatternAddr = 0
-		e.PPU.MatrixPlaneBitmapAddr = 0
-		e.PPU.MatrixPlaneRowAddr = 0
-		e.PPU.DMAEnabled = false
-		e.PPU.DMASourceBank = 0
-		e.PPU.DMASourceOffset = 0
-		e.PPU.DMADestType = 0
-		e.PPU.DMADestAddr = 0
-		e.PPU.DMALength = 0
-		e.PPU.DMAMode = 0
-		e.PPU.DMACycles = 0
-		e.PPU.DMAProgress = 0
-		e.PPU.DMACurrentSrc = 0
-		e.PPU.DMACurrentDest = 0
-		e.PPU.DMAFillValue = 0
-	}
+	e.PPU.HDMAControl = state.HDMAControl
+	e.PPU.HDMAExtControl = state.HDMAExtControl
+	e.PPU.MatrixPlaneSelect = state.MatrixPlaneSelect
+	e.PPU.MatrixPlaneAddr = state.MatrixPlaneAddr
+	e.PPU.MatrixPlanePatternAddr = state.MatrixPlanePatternAddr
+	e.PPU.MatrixPlaneBitmapAddr = state.MatrixPlaneBitmapAddr
+	e.PPU.MatrixPlaneRowAddr = state.MatrixPlaneRowAddr
+	e.PPU.DMAEnabled = state.DMAEnabled
+	e.PPU.DMASourceBank = state.DMASourceBank
+	e.PPU.DMASourceOffset = state.DMASourceOffset
+	e.PPU.DMADestType = state.DMADestType
+	e.PPU.DMADestAddr = state.DMADestAddr
+	e.PPU.DMALength = state.DMALength
+	e.PPU.DMAMode = state.DMAMode
+	e.PPU.DMACycles = state.DMACycles
+	e.PPU.DMAProgress = state.DMAProgress
+	e.PPU.DMACurrentSrc = state.DMACurrentSrc
+	e.PPU.DMACurrentDest = state.DMACurrentDest
+	e.PPU.DMAFillValue = state.DMAFillValue
 	e.PPU.FrameCounter = state.FrameCounter
 	e.PPU.VBlankFlag = state.VBlankFlag
 	e.PPU.VRAMAddr = state.VRAMAddr
@@ -309,16 +287,22 @@ func (e *Emulator) loadPPUState(state PPUState, version uint16) {
 	e.PPU.CGRAMWriteValue = state.CGRAMWriteValue
 	e.PPU.OAMAddr = state.OAMAddr
 	e.PPU.OAMByteIndex = state.OAMByteIndex
+	e.PPU.BorderColor = state.BorderColor
+	e.PPU.LineScrollTable = state.LineScrollTable
 	e.PPU.ResetDerivedRuntimeCachesForStateLoad()
 	e.PPU.SyncTransformBindingsForStateLoad()
 }
 
 // saveAPUState extracts APU state for saving
 func (e *Emulator) saveAPUState() APUState {
+	effects := e.APU.Effects
+	effects.Echo = slices.Clone(effects.Echo)
 	return APUState{
 		Channels:                e.APU.Channels,
 		MasterVolume:            e.APU.MasterVolume,
 		ChannelCompletionStatus: e.APU.ChannelCompletionStatus,
+		Sample:                  e.APU.Sample,
+		Effects:                 effects,
 	}
 }
 
@@ -327,6 +311,8 @@ func (e *Emulator) loadAPUState(state APUState) {
 	e.APU.Channels = state.Channels
 	e.APU.MasterVolume = state.MasterVolume
 	e.APU.ChannelCompletionStatus = state.ChannelCompletionStatus
+	e.APU.Sample = state.Sample
+	e.APU.Effects = state.Effects
 }
 
 // saveMemoryState extracts Memory state for saving
@@ -334,6 +320,9 @@ func (e *Emulator) saveMemoryState() MemoryState {
 	return MemoryState{
 		WRAM:         e.Bus.WRAM,
 		WRAMExtended: e.Bus.WRAMExtended,
+		ResetCause:   e.Bus.ResetCause,
+		WaitCycles:   e.Bus.WaitCycles,
+		OpenBus:      e.Bus.OpenBus,
 	}
 }
 
@@ -341,6 +330,10 @@ func (e *Emulator) saveMemoryState() MemoryState {
 func (e *Emulator) loadMemoryState(state MemoryState) {
 	e.Bus.WRAM = state.WRAM
 	e.Bus.WRAMExtended = state.WRAMExtended
+	e.Bus.ResetCause = state.ResetCause
+	e.Bus.WaitCycles = state.WaitCycles
+	e.Bus.OpenBus = state.OpenBus
+	e.Bus.MarkRAMWritten()
 }
 
 // saveInputState extracts Input state for saving
@@ -0,0 +1,245 @@
+package emulator
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"fmt"
+
+	"nitro-core-dx/internal/cpu"
+	"nitro-core-dx/internal/ppu"
+)
+
+// A version 3 save state is the magic, the format version and a section
+// count, then one section per component:
+//
+//	id [4]byte | version uint16 | length uint32 | gob-encoded component
+//
+// all little-endian. Each component has its own version, raised when its
+// layout or meaning changes; loading runs saveStateMigrations to bring
+// older sections up to date. Sections with unknown ids are skipped, so a
+// component can be added without breaking older readers of the format.
+const saveStateMagic = "NCDXSTAT"
+
+// Save state section ids.
+const (
+	saveSectionCPU    = "CPU "
+	saveSectionPPU    = "PPU "
+	saveSectionAPU    = "APU "
+	saveSectionMemory = "MEM "
+	saveSectionInput  = "INPT"
+	saveSectionHost   = "HOST" // Running and Paused
+)
+
+// saveStateSection is one component of a save state: its id, the version
+// this emulator writes, and how to encode and decode it.
+type saveStateSection struct {
+	id      string
+	version uint16
+	save    func(*SaveState) any
+	load    func(*SaveState, *gob.Decoder) error
+}
+
+// saveStateHost is the host section: the emulator's run flags.
+type saveStateHost struct {
+	Running bool
+	Paused  bool
+}
+
+func gobSection[T any](id string, version uint16, part func(*SaveState) *T) saveStateSection {
+	return saveStateSection{
+		id:      id,
+		version: version,
+		save:    func(s *SaveState) any { return part(s) },
+		load:    func(s *SaveState, dec *gob.Decoder) error { return dec.Decode(part(s)) },
+	}
+}
+
+// saveStateSections lists the sections SaveState writes, in order. Raise a
+// section's version when its component's layout or meaning changes, and
+// add a migration from the previous version to saveStateMigrations.
+var saveStateSections = []saveStateSection{
+	gobSection(saveSectionCPU, 2, func(s *SaveState) *cpu.CPUState { return &s.CPUState }),
+	gobSection(saveSectionPPU, 2, func(s *SaveState) *PPUState { return &s.PPUState }),
+	gobSection(saveSectionAPU, 1, func(s *SaveState) *APUState { return &s.APUState }),
+	gobSection(saveSectionMemory, 3, func(s *SaveState) *MemoryState { return &s.MemoryState }),
+	gobSection(saveSectionInput, 1, func(s *SaveState) *InputState { return &s.InputState }),
+	{
+		id:      saveSectionHost,
+		version: 1,
+		save:    func(s *SaveState) any { return saveStateHost{Running: s.Running, Paused: s.Paused} },
+		load: func(s *SaveState, dec *gob.Decoder) error {
+			var h saveStateHost
+			if err := dec.Decode(&h); err != nil {
+				return err
+			}
+			s.Running, s.Paused = h.Running, h.Paused
+			return nil
+		},
+	},
+}
+
+// saveStateMigrations[id][v] upgrades section id of a decoded state from
+// version v to v+1. Version 0 is a section the state does not have, so a
+// migration from 0 lets states saved before a component existed load.
+var saveStateMigrations = map[string]map[uint16]func(*SaveState){
+	saveSectionCPU:    {1: migrateCPUStateV1},
+	saveSectionPPU:    {1: migratePPUStateV1},
+	saveSectionMemory: {1: migrateMemoryStateV1, 2: migrateMemoryStateV2},
+}
+
+// migrateCPUStateV1 upgrades CPU state saved before the CPU's bus
+// contention stall was: the CPU was not stalled.
+func migrateCPUStateV1(s *SaveState) {
+	s.CPUState.Stall = 0
+}
+
+// migrateMemoryStateV1 upgrades memory state saved before the bus's
+// pending wait cycles were: there were none.
+func migrateMemoryStateV1(s *SaveState) {
+	s.MemoryState.WaitCycles = 0
+}
+
+// migrateMemoryStateV2 upgrades memory state saved before the open bus
+// byte was: it reads as 0, as after a power-on.
+func migrateMemoryStateV2(s *SaveState) {
+	s.MemoryState.OpenBus = 0
+}
+
+// migratePPUStateV1 upgrades PPU state saved before the matrix plane and
+// DMA registers were: the planes get their power-on parameters and the
+// registers their reset values.
+func migratePPUStateV1(s *SaveState) {
+	p := &s.PPUState
+	p.MatrixPlanes = [ppu.NumTransformChannels]ppu.MatrixPlane{}
+	for i := range p.MatrixPlanes {
+		p.MatrixPlanes[i].Size = ppu.TilemapSize32x32
+		p.MatrixPlanes[i].BaseDistance = 0x0100
+		p.MatrixPlanes[i].FocalLength = 0x3000
+		p.MatrixPlanes[i].WidthScale = 0x0100
+		p.MatrixPlanes[i].HeightScale = 0x0200
+		p.MatrixPlanes[i].HeadingY = -0x0100
+		p.MatrixPlanes[i].FacingY = -0x0100
+	}
+	p.HDMAControl = 0
+	p.HDMAExtControl = 0
+	p.MatrixPlaneSelect = 0
+	p.MatrixPlaneAddr = 0
+	p.MatrixPlanePatternAddr = 0
+	p.MatrixPlaneBitmapAddr = 0
+	p.MatrixPlaneRowAddr = 0
+	p.DMAEnabled = false
+	p.DMASourceBank = 0
+	p.DMASourceOffset = 0
+	p.DMADestType = 0
+	p.DMADestAddr = 0
+	p.DMALength = 0
+	p.DMAMode = 0
+	p.DMACycles = 0
+	p.DMAProgress = 0
+	p.DMACurrentSrc = 0
+	p.DMACurrentDest = 0
+	p.DMAFillValue = 0
+}
+
+// encodeSaveState writes state in the current (version 3) format.
+func encodeSaveState(state SaveState) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(saveStateMagic)
+	le := binary.LittleEndian
+	buf.Write(le.AppendUint16(nil, saveStateVersion3))
+	buf.Write(le.AppendUint16(nil, uint16(len(saveStateSections))))
+	var payload bytes.Buffer
+	for _, sec := range saveStateSections {
+		payload.Reset()
+		if err := gob.NewEncoder(&payload).Encode(sec.save(&state)); err != nil {
+			return nil, fmt.Errorf("%s section: %w", sec.id, err)
+		}
+		buf.WriteString(sec.id)
+		buf.Write(le.AppendUint16(nil, sec.version))
+		buf.Write(le.AppendUint32(nil, uint32(payload.Len())))
+		buf.Write(payload.Bytes())
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeSaveState reads a save state in any format this emulator has
+// written and migrates it to the current one.
+func decodeSaveState(data []byte) (SaveState, error) {
+	var state SaveState
+	versions := map[string]uint16{}
+	if !bytes.HasPrefix(data, []byte(saveStateMagic)) {
+		// Versions 1 and 2: one gob-encoded SaveState. Version 1 is the
+		// PPU section's version 1; nothing else has changed since.
+		if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&state); err != nil {
+			return SaveState{}, fmt.Errorf("failed to decode save state: %w", err)
+		}
+		if state.Version != saveStateVersion1 && state.Version != saveStateVersion2 {
+			return SaveState{}, fmt.Errorf("unsupported save state version: %d (expected %d to %d)", state.Version, saveStateVersion1, saveStateVersion3)
+		}
+		for _, sec := range saveStateSections {
+			versions[sec.id] = 1
+		}
+		versions[saveSectionPPU] = state.Version
+	} else if err := decodeSaveStateSections(data, &state, versions); err != nil {
+		return SaveState{}, err
+	}
+
+	for _, sec := range saveStateSections {
+		for v := versions[sec.id]; v < sec.version; v++ {
+			migrate := saveStateMigrations[sec.id][v]
+			if migrate == nil {
+				if v == 0 {
+					return SaveState{}, fmt.Errorf("save state has no %s section", sec.id)
+				}
+				return SaveState{}, fmt.Errorf("no migration for %s section version %d", sec.id, v)
+			}
+			migrate(&state)
+		}
+	}
+	state.Version = saveStateVersion3
+	return state, nil
+}
+
+// decodeSaveStateSections decodes a version 3 state's sections into state,
+// recording each one's version in versions.
+func decodeSaveStateSections(data []byte, state *SaveState, versions map[string]uint16) error {
+	le := binary.LittleEndian
+	rest := data[len(saveStateMagic):]
+	if len(rest) < 4 {
+		return fmt.Errorf("failed to decode save state: truncated header")
+	}
+	format, count := le.Uint16(rest), le.Uint16(rest[2:])
+	if format != saveStateVersion3 {
+		return fmt.Errorf("unsupported save state version: %d (expected %d to %d)", format, saveStateVersion1, saveStateVersion3)
+	}
+	rest = rest[4:]
+	known := map[string]saveStateSection{}
+	for _, sec := range saveStateSections {
+		known[sec.id] = sec
+	}
+	for i := 0; i < int(count); i++ {
+		if len(rest) < 10 {
+			return fmt.Errorf("failed to decode save state: section %d is truncated", i)
+		}
+		id, version, size := string(rest[:4]), le.Uint16(rest[4:]), le.Uint32(rest[6:])
+		rest = rest[10:]
+		if uint64(size) > uint64(len(rest)) {
+			return fmt.Errorf("failed to decode save state: %s section is truncated", id)
+		}
+		payload := rest[:size]
+		rest = rest[size:]
+		sec, ok := known[id]
+		if !ok {
+			continue // from a newer emulator; this one has no such component
+		}
+		if version > sec.version {
+			return fmt.Errorf("save state %s section is version %d, newer than this emulator reads (%d)", id, version, sec.version)
+		}
+		if err := sec.load(state, gob.NewDecoder(bytes.NewReader(payload))); err != nil {
+			return fmt.Errorf("failed to decode save state %s section: %w", id, err)
+		}
+		versions[id] = version
+	}
+	return nil
+}
@@ -2,9 +2,14 @@ package emulator
 
 import (
 	"bytes"
+	"compress/gzip"
+	"encoding/binary"
 	"encoding/gob"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"nitro-core-dx/internal/ppu"
@@ -218,7 +223,7 @@ func TestLoadPPUStateRestoresTransformChannels(t *testing.T) {
 		},
 	}
 
-	emu.loadPPUState(state, saveStateVersion2)
+	emu.loadPPUState(state)
 
 	if emu.PPU.TransformChannels[0] != state.TransformChannels[0] {
 		t.Fatal("transform channel 0 should match loaded state")
@@ -429,3 +434,162 @@ func encodeSaveStateForTest(state SaveState) ([]byte, error) {
 	}
 	return buf.Bytes(), nil
 }
+
+// saveStateFixtureEmulator returns the machine the files in
+// testdata/savestates were saved from.
+func saveStateFixtureEmulator() *Emulator {
+	emu := NewEmulator()
+	emu.CPU.State.R0 = 0x1234
+	emu.CPU.State.PCBank = 1
+	emu.CPU.State.PCOffset = 0x8123
+	emu.Bus.WRAM[0x1000] = 0xAB
+	emu.Bus.WRAMExtended[0x10000] = 0x5A
+	emu.PPU.VRAM[0x2000] = 0xEF
+	emu.PPU.CGRAM[2] = 0x1F
+	emu.PPU.OAM[6] = 0x40
+	emu.PPU.FrameCounter = 42
+	emu.PPU.BG0.Enabled = true
+	emu.APU.MasterVolume = 128
+	emu.APU.Channels[0].Frequency = 440
+	emu.Input.Controller1Buttons = 0x0012
+	emu.PPU.MatrixPlanes[1].Enabled = true
+	emu.PPU.MatrixPlanes[1].BaseDistance = 0x0200
+	emu.PPU.DMASourceBank = 2
+	emu.Running = true
+	return emu
+}
+
+// TestLoadStateFixtures loads a state saved by each earlier save state
+// format. Set NCDX_WRITE_SAVESTATE_FIXTURE=1 to (re)write the current
+// format's fixture after adding a format version; keep the old ones.
+func TestLoadStateFixtures(t *testing.T) {
+	current := filepath.Join("testdata", "savestates", fmt.Sprintf("v%d.state.gz", saveStateVersion3))
+	if os.Getenv("NCDX_WRITE_SAVESTATE_FIXTURE") != "" {
+		data, err := saveStateFixtureEmulator().SaveState()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var gz bytes.Buffer
+		w := gzip.NewWriter(&gz)
+		w.Write(data)
+		w.Close()
+		if err := os.WriteFile(current, gz.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for version := saveStateVersion1; version <= saveStateVersion3; version++ {
+		path := filepath.Join("testdata", "savestates", fmt.Sprintf("v%d.state.gz", version))
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatalf("version %d fixture: %v", version, err)
+		}
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		data, err := io.ReadAll(zr)
+		f.Close()
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+
+		emu := NewEmulator()
+		if err := emu.LoadState(data); err != nil {
+			t.Fatalf("version %d: LoadState: %v", version, err)
+		}
+		if emu.CPU.State.R0 != 0x1234 || emu.CPU.State.PCBank != 1 || emu.CPU.State.PCOffset != 0x8123 {
+			t.Errorf("version %d: CPU state not restored", version)
+		}
+		if emu.Bus.WRAM[0x1000] != 0xAB || emu.Bus.WRAMExtended[0x10000] != 0x5A {
+			t.Errorf("version %d: memory not restored", version)
+		}
+		if emu.PPU.VRAM[0x2000] != 0xEF || emu.PPU.CGRAM[2] != 0x1F || emu.PPU.OAM[6] != 0x40 || emu.PPU.FrameCounter != 42 || !emu.PPU.BG0.Enabled {
+			t.Errorf("version %d: PPU state not restored", version)
+		}
+		if emu.APU.MasterVolume != 128 || emu.APU.Channels[0].Frequency != 440 || emu.Input.Controller1Buttons != 0x0012 || !emu.Running {
+			t.Errorf("version %d: APU, input or run state not restored", version)
+		}
+		plane := emu.PPU.MatrixPlanes[1]
+		if version == saveStateVersion1 {
+			if plane.Enabled || plane.BaseDistance != 0x0100 || emu.PPU.DMASourceBank != 0 {
+				t.Errorf("version 1: matrix plane and DMA state not migrated to reset values")
+			}
+		} else if !plane.Enabled || plane.BaseDistance != 0x0200 || emu.PPU.DMASourceBank != 2 {
+			t.Errorf("version %d: matrix plane and DMA state not restored", version)
+		}
+	}
+}
+
+func TestSaveStateSections(t *testing.T) {
+	data, err := saveStateFixtureEmulator().SaveState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(data, []byte(saveStateMagic)) {
+		t.Fatal("SaveState did not write the sectioned format")
+	}
+
+	// A section from a newer emulator is skipped.
+	extra := append([]byte(nil), data...)
+	binary.LittleEndian.PutUint16(extra[len(saveStateMagic)+2:], uint16(len(saveStateSections)+1))
+	extra = append(extra, "FUTR"...)
+	extra = binary.LittleEndian.AppendUint16(extra, 1)
+	extra = binary.LittleEndian.AppendUint32(extra, 3)
+	extra = append(extra, 1, 2, 3)
+	emu := NewEmulator()
+	if err := emu.LoadState(extra); err != nil {
+		t.Fatalf("state with an unknown section: %v", err)
+	}
+	if emu.Bus.WRAM[0x1000] != 0xAB {
+		t.Error("state with an unknown section not restored")
+	}
+
+	// A known section newer than this emulator reads is refused.
+	newer := append([]byte(nil), data...)
+	ppuAt := bytes.Index(newer, []byte(saveSectionPPU))
+	binary.LittleEndian.PutUint16(newer[ppuAt+4:], 99)
+	if err := NewEmulator().LoadState(newer); err == nil || !strings.Contains(err.Error(), "newer than this emulator") {
+		t.Errorf("newer PPU section: err = %v", err)
+	}
+
+	// So is a state missing a section with no migration from nothing.
+	missing := append([]byte(nil), data...)
+	binary.LittleEndian.PutUint16(missing[len(saveStateMagic)+2:], uint16(len(saveStateSections)-1))
+	if err := NewEmulator().LoadState(missing); err == nil || !strings.Contains(err.Error(), "no HOST section") {
+		t.Errorf("state without its last section: err = %v", err)
+	}
+}
+
+// The open bus byte is machine state: an unmapped read after loading a
+// state returns what it returned when the state was saved.
+func TestSaveLoadStateKeepsOpenBus(t *testing.T) {
+	emu := NewEmulator()
+	if err := emu.LoadROM(persistTestROM(1)); err != nil {
+		t.Fatal(err)
+	}
+	emu.Bus.Write8(0, 0x0040, 0x3C)
+	state, err := emu.SaveState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	emu.Bus.Write8(0, 0x0041, 0x99)
+
+	fresh := NewEmulator()
+	if err := fresh.LoadROM(persistTestROM(1)); err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range []*Emulator{emu, fresh} {
+		if err := e.LoadState(state); err != nil {
+			t.Fatal(err)
+		}
+		if got := e.Bus.Read8(200, 0x1234); got != 0x3C {
+			t.Errorf("unmapped read after LoadState = 0x%02X, want 0x3C", got)
+		}
+	}
+
+	emu.Reset()
+	if got := emu.Bus.Read8(200, 0x1234); got != 0 {
+		t.Errorf("unmapped read after power cycle = 0x%02X, want 0", got)
+	}
+}
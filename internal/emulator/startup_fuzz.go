@@ -0,0 +1,104 @@
+package emulator
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"nitro-core-dx/internal/cpu"
+	"nitro-core-dx/internal/debug"
+	"nitro-core-dx/internal/ppu"
+)
+
+// startupFuzz is the startup-state fuzz mode's state (see
+// EnableStartupFuzz).
+type startupFuzz struct {
+	enabled bool
+	seed    int64 // seed of the current run
+}
+
+// EnableStartupFuzz switches the startup-state fuzz mode on: instead of
+// the documented zeros, every power-on comes up with pseudo-random values
+// where the hardware leaves the state undefined, so a ROM that relies on
+// zero-initialized memory fails in the emulator rather than on hardware.
+// Randomized are
+//
+//   - work RAM, extended work RAM and VRAM (replacing the RAM pattern);
+//   - CGRAM and OAM;
+//   - the BG scroll offsets and tilemap bases (layers stay disabled);
+//   - CPU registers R0-R7 and the Z, N, C and V flags.
+//
+// The program counter, stack pointer, bank registers, the interrupt mask
+// and the system vectors keep their defined reset values. The state is
+// applied now, for the first boot, from seed; each later power cycle
+// (Reset) uses the next seed, so runs differ but a failing run reproduces
+// by enabling the mode with the seed StartupFuzzSeed reported for it.
+func (e *Emulator) EnableStartupFuzz(seed int64) {
+	e.fuzz = startupFuzz{enabled: true, seed: seed}
+	e.applyStartupFuzz()
+}
+
+// DisableStartupFuzz turns the fuzz mode off. The current state is kept;
+// the next power cycle comes up as documented again.
+func (e *Emulator) DisableStartupFuzz() {
+	e.fuzz = startupFuzz{}
+}
+
+// StartupFuzzSeed returns the seed the current run's startup state was
+// drawn from, and whether the fuzz mode is on.
+func (e *Emulator) StartupFuzzSeed() (int64, bool) {
+	return e.fuzz.seed, e.fuzz.enabled
+}
+
+// ParseStartupFuzzSeed parses the -fuzz-startup spelling of a seed: a
+// number, or "random" for one taken from the clock.
+func ParseStartupFuzzSeed(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if strings.EqualFold(s, "random") {
+		return time.Now().UnixNano(), nil
+	}
+	seed, err := strconv.ParseInt(s, 0, 64)
+	if err != nil {
+		return 0, fmt.Errorf("startup fuzz seed %q: want a number or random", s)
+	}
+	return seed, nil
+}
+
+// nextStartupFuzz advances to the next run's seed and applies it. Reset
+// calls it after the power cycle; it does nothing while the mode is off.
+func (e *Emulator) nextStartupFuzz() {
+	if !e.fuzz.enabled {
+		return
+	}
+	e.fuzz.seed++
+	e.applyStartupFuzz()
+}
+
+func (e *Emulator) applyStartupFuzz() {
+	r := rand.New(rand.NewSource(e.fuzz.seed))
+	r.Read(e.Bus.WRAM[:])
+	r.Read(e.Bus.WRAMExtended[:])
+	r.Read(e.PPU.VRAM[:])
+	r.Read(e.PPU.CGRAM[:])
+	r.Read(e.PPU.OAM[:])
+	for _, bg := range []*ppu.BackgroundLayer{&e.PPU.BG0, &e.PPU.BG1, &e.PPU.BG2, &e.PPU.BG3} {
+		bg.ScrollX = int16(r.Uint32())
+		bg.ScrollY = int16(r.Uint32())
+		bg.TilemapBase = uint16(r.Uint32())
+	}
+	// CGRAM changed behind the data port: drop the cached colors.
+	e.PPU.ResetDerivedRuntimeCachesForStateLoad()
+
+	s := &e.CPU.State
+	for _, reg := range []*uint16{&s.R0, &s.R1, &s.R2, &s.R3, &s.R4, &s.R5, &s.R6, &s.R7} {
+		*reg = uint16(r.Uint32())
+	}
+	const arithmeticFlags = 1<<cpu.FlagZ | 1<<cpu.FlagN | 1<<cpu.FlagC | 1<<cpu.FlagV
+	s.Flags = s.Flags&^arithmeticFlags | uint8(r.Uint32())&arithmeticFlags
+
+	if e.Logger != nil {
+		e.Logger.LogSystem(debug.LogLevelInfo, fmt.Sprintf("Startup fuzz: power-on state from seed %d", e.fuzz.seed), nil)
+	}
+}
@@ -0,0 +1,71 @@
+package emulator
+
+import (
+	"bytes"
+	"testing"
+
+	"nitro-core-dx/internal/cpu"
+)
+
+func TestStartupFuzzIsSeededAndKeepsDefinedState(t *testing.T) {
+	a, b := NewEmulator(), NewEmulator()
+	a.EnableStartupFuzz(42)
+	b.EnableStartupFuzz(42)
+	if a.Bus.WRAM != b.Bus.WRAM || a.PPU.OAM != b.PPU.OAM || a.CPU.State != b.CPU.State {
+		t.Fatal("same seed produced different startup state")
+	}
+	if bytes.Count(a.Bus.WRAM[:], []byte{0}) > len(a.Bus.WRAM)/64 {
+		t.Fatal("work RAM still mostly zero")
+	}
+	if a.PPU.CGRAM == [512]uint8{} || a.CPU.State.R0|a.CPU.State.R7 == 0 {
+		t.Fatal("CGRAM or CPU registers not randomized")
+	}
+	s := a.CPU.State
+	if s.SP != 0x1FFF || s.DBR != 0 || a.CPU.GetFlag(cpu.FlagI) || a.CPU.GetFlag(cpu.FlagD) {
+		t.Errorf("defined reset state changed: SP %04X DBR %d flags %08b", s.SP, s.DBR, s.Flags)
+	}
+	if a.PPU.BG0.Enabled || a.PPU.BG3.Enabled {
+		t.Error("fuzzing enabled a background layer")
+	}
+
+	c := NewEmulator()
+	c.EnableStartupFuzz(43)
+	if c.Bus.WRAM == a.Bus.WRAM {
+		t.Error("different seeds produced the same work RAM")
+	}
+}
+
+func TestStartupFuzzAdvancesSeedOnPowerCycle(t *testing.T) {
+	emu := NewEmulator()
+	if err := emu.LoadROM(persistTestROM(1)); err != nil {
+		t.Fatal(err)
+	}
+	emu.EnableStartupFuzz(7)
+	emu.Reset()
+	if seed, on := emu.StartupFuzzSeed(); seed != 8 || !on {
+		t.Fatalf("after Reset seed = %d, %v; want 8, true", seed, on)
+	}
+	again := NewEmulator()
+	again.EnableStartupFuzz(8)
+	if emu.Bus.WRAM != again.Bus.WRAM || emu.PPU.VRAM != again.PPU.VRAM {
+		t.Error("power cycle state does not reproduce from its seed")
+	}
+
+	emu.DisableStartupFuzz()
+	emu.Reset()
+	if _, on := emu.StartupFuzzSeed(); on || emu.CPU.State.R0 != 0 {
+		t.Error("power cycle after DisableStartupFuzz still fuzzed")
+	}
+}
+
+func TestParseStartupFuzzSeed(t *testing.T) {
+	if seed, err := ParseStartupFuzzSeed("0x10"); err != nil || seed != 16 {
+		t.Errorf("0x10 = %d, %v", seed, err)
+	}
+	if _, err := ParseStartupFuzzSeed("random"); err != nil {
+		t.Error(err)
+	}
+	if _, err := ParseStartupFuzzSeed("often"); err == nil {
+		t.Error("bad seed accepted")
+	}
+}
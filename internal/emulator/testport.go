@@ -0,0 +1,194 @@
+package emulator
+
+import (
+	"fmt"
+	"strings"
+
+	"nitro-core-dx/internal/hwdef"
+)
+
+// TestFailure is one failed assertion (hwdef.TestCmdAssertEqual).
+type TestFailure struct {
+	Assertion int // 1-based count of assertions made so far
+	Got       uint16
+	Want      uint16
+	Frame     uint64
+	PCBank    uint8
+	PCOffset  uint16
+}
+
+func (f TestFailure) String() string {
+	return fmt.Sprintf("assertion %d failed at %02X:%04X (frame %d): got %d (0x%04X), want %d (0x%04X)",
+		f.Assertion, f.PCBank, f.PCOffset, f.Frame, f.Got, f.Got, f.Want, f.Want)
+}
+
+// TestPort lets test ROMs report to the host: assertions, debug text and an
+// exit status. Like the persistence mailbox it is not console hardware; the
+// emulator maps it only in test mode (EnableTestMode).
+//
+// The ROM talks to it through the TEST_* registers (0xA030-0xA034):
+//
+//	TEST_VALUE_L/H   - the value to check, the character to print, or the
+//	                   exit status
+//	TEST_EXPECT_L/H  - the expected value for an assertion
+//	TEST_COMMAND     - write a hwdef.TestCmd* code (assert equal, print
+//	                   char, exit);
+//	                   read bit 0 FAILED (an assertion failed), bit 1
+//	                   EXITED, bit 7 AVAILABLE
+//
+// A failed assertion is recorded and also printed, so it shows in the debug
+// text next to whatever the ROM printed before it. The port is cleared
+// whenever a ROM is loaded.
+type TestPort struct {
+	value, expect uint16
+
+	assertions int
+	failures   []TestFailure
+	exited     bool
+	status     uint16
+
+	output strings.Builder
+	taken  int // bytes of output already returned by TakeOutput
+
+	// locate reports the current frame and executing instruction for
+	// failure records.
+	locate func() (frame uint64, bank uint8, offset uint16)
+}
+
+// NewTestPort returns an empty test port.
+func NewTestPort() *TestPort {
+	return &TestPort{}
+}
+
+// Reset clears every result and the debug text.
+func (p *TestPort) Reset() {
+	locate := p.locate
+	*p = TestPort{locate: locate}
+}
+
+// Assertions returns how many assertions the ROM has made.
+func (p *TestPort) Assertions() int { return p.assertions }
+
+// Failures returns the failed assertions, oldest first.
+func (p *TestPort) Failures() []TestFailure {
+	return append([]TestFailure(nil), p.failures...)
+}
+
+// Exited reports whether the ROM has exited, and with what status.
+func (p *TestPort) Exited() (status uint16, ok bool) {
+	return p.status, p.exited
+}
+
+// Output returns all debug text printed since the port was reset.
+func (p *TestPort) Output() string {
+	return p.output.String()
+}
+
+// TakeOutput returns the debug text printed since the previous call. It is
+// safe on a nil port, which has printed nothing.
+func (p *TestPort) TakeOutput() string {
+	if p == nil {
+		return ""
+	}
+	s := p.output.String()[p.taken:]
+	p.taken = p.output.Len()
+	return s
+}
+
+// Read8 reads a test register; offset is relative to hwdef.InputBase.
+func (p *TestPort) Read8(offset uint16) uint8 {
+	switch offset + hwdef.InputBase {
+	case hwdef.TEST_VALUE_L:
+		return uint8(p.value)
+	case hwdef.TEST_VALUE_H:
+		return uint8(p.value >> 8)
+	case hwdef.TEST_EXPECT_L:
+		return uint8(p.expect)
+	case hwdef.TEST_EXPECT_H:
+		return uint8(p.expect >> 8)
+	case hwdef.TEST_COMMAND:
+		status := uint8(0x80)
+		if len(p.failures) > 0 {
+			status |= 0x01
+		}
+		if p.exited {
+			status |= 0x02
+		}
+		return status
+	}
+	return 0
+}
+
+// Write8 writes a test register; offset is relative to hwdef.InputBase.
+func (p *TestPort) Write8(offset uint16, value uint8) {
+	switch offset + hwdef.InputBase {
+	case hwdef.TEST_VALUE_L:
+		p.value = (p.value & 0xFF00) | uint16(value)
+	case hwdef.TEST_VALUE_H:
+		p.value = (p.value & 0x00FF) | uint16(value)<<8
+	case hwdef.TEST_EXPECT_L:
+		p.expect = (p.expect & 0xFF00) | uint16(value)
+	case hwdef.TEST_EXPECT_H:
+		p.expect = (p.expect & 0x00FF) | uint16(value)<<8
+	case hwdef.TEST_COMMAND:
+		p.command(value)
+	}
+}
+
+func (p *TestPort) command(cmd uint8) {
+	switch cmd {
+	case hwdef.TestCmdAssertEqual:
+		p.assertions++
+		if p.value == p.expect {
+			return
+		}
+		f := TestFailure{Assertion: p.assertions, Got: p.value, Want: p.expect}
+		if p.locate != nil {
+			f.Frame, f.PCBank, f.PCOffset = p.locate()
+		}
+		p.failures = append(p.failures, f)
+		if s := p.output.String(); s != "" && !strings.HasSuffix(s, "\n") {
+			p.output.WriteByte('\n')
+		}
+		p.output.WriteString(f.String() + "\n")
+	case hwdef.TestCmdPrintChar:
+		p.output.WriteByte(uint8(p.value))
+	case hwdef.TestCmdExit:
+		if !p.exited {
+			p.exited, p.status = true, p.value
+		}
+	}
+}
+
+// Read16 reads two consecutive test registers.
+func (p *TestPort) Read16(offset uint16) uint16 {
+	return uint16(p.Read8(offset)) | uint16(p.Read8(offset+1))<<8
+}
+
+// Write16 writes two consecutive test registers.
+func (p *TestPort) Write16(offset uint16, value uint16) {
+	p.Write8(offset, uint8(value))
+	p.Write8(offset+1, uint8(value>>8))
+}
+
+// EnableTestMode maps a fresh TestPort, so ROMs can report assertions,
+// debug text and an exit status to the host.
+func (e *Emulator) EnableTestMode() {
+	e.TestPort = NewTestPort()
+	e.TestPort.locate = func() (uint64, uint8, uint16) {
+		bank, offset := e.CPU.State.PCBank, e.CPU.State.PCOffset
+		if pcs := e.CPU.RecentPCs(); len(pcs) > 0 {
+			last := pcs[len(pcs)-1]
+			bank, offset = uint8(last>>16), uint16(last)
+		}
+		return e.FrameCount, bank, offset
+	}
+	e.Bus.TestHandler = e.TestPort
+}
+
+// DisableTestMode unmaps the test port; its registers then read 0, so
+// AVAILABLE is clear.
+func (e *Emulator) DisableTestMode() {
+	e.TestPort = nil
+	e.Bus.TestHandler = nil
+}
@@ -0,0 +1,66 @@
+package emulator
+
+import (
+	"strings"
+	"testing"
+
+	"nitro-core-dx/internal/hwdef"
+)
+
+func testPortCommand(emu *Emulator, value, expect uint16, command uint8) {
+	emu.Bus.Write16(0, hwdef.TEST_VALUE_L, value)
+	emu.Bus.Write16(0, hwdef.TEST_EXPECT_L, expect)
+	emu.Bus.Write8(0, hwdef.TEST_COMMAND, command)
+}
+
+func TestTestPortReportsToHost(t *testing.T) {
+	emu := NewEmulator()
+	if emu.Bus.Read8(0, hwdef.TEST_COMMAND) != 0 {
+		t.Fatal("test port mapped outside test mode")
+	}
+	emu.EnableTestMode()
+	if err := emu.LoadROM(persistTestROM(1)); err != nil {
+		t.Fatal(err)
+	}
+	if emu.Bus.Read8(0, hwdef.TEST_COMMAND) != 0x80 {
+		t.Fatalf("status = %#02x, want AVAILABLE only", emu.Bus.Read8(0, hwdef.TEST_COMMAND))
+	}
+
+	for _, c := range "ok" {
+		testPortCommand(emu, uint16(c), 0, hwdef.TestCmdPrintChar)
+	}
+	testPortCommand(emu, 5, 5, hwdef.TestCmdAssertEqual)
+	testPortCommand(emu, 0x1234, 7, hwdef.TestCmdAssertEqual)
+	if got := emu.TestPort.TakeOutput(); !strings.HasPrefix(got, "ok\nassertion 2 failed") {
+		t.Errorf("output = %q, want the printed text then the failure", got)
+	}
+	if got := emu.TestPort.TakeOutput(); got != "" {
+		t.Errorf("second TakeOutput = %q, want nothing new", got)
+	}
+	failures := emu.TestPort.Failures()
+	if len(failures) != 1 || failures[0].Got != 0x1234 || failures[0].Want != 7 {
+		t.Errorf("failures = %+v", failures)
+	}
+
+	testPortCommand(emu, 3, 0, hwdef.TestCmdExit)
+	testPortCommand(emu, 0, 0, hwdef.TestCmdExit) // only the first exit counts
+	if status, ok := emu.TestPort.Exited(); !ok || status != 3 {
+		t.Errorf("Exited = %d, %v; want 3, true", status, ok)
+	}
+	if got := emu.Bus.Read8(0, hwdef.TEST_COMMAND); got != 0x83 {
+		t.Errorf("status = %#02x, want AVAILABLE|EXITED|FAILED", got)
+	}
+
+	// Loading a ROM starts a new test.
+	if err := emu.LoadROM(persistTestROM(2)); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := emu.TestPort.Exited(); ok || len(emu.TestPort.Failures()) != 0 || emu.TestPort.Output() != "" {
+		t.Error("test port not reset by LoadROM")
+	}
+
+	emu.DisableTestMode()
+	if emu.Bus.Read8(0, hwdef.TEST_COMMAND) != 0 {
+		t.Error("test port still mapped after DisableTestMode")
+	}
+}
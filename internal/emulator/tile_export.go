@@ -0,0 +1,126 @@
+package emulator
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+)
+
+// TileSheetOptions controls how VRAM is decoded into a tile sheet.
+type TileSheetOptions struct {
+	// TileSize is 8 or 16. Tiles are 4bpp, packed high nibble first, and
+	// stored back to back from VRAM address 0 (32 or 128 bytes each).
+	TileSize int
+	// Palette (0-15) selects the CGRAM bank every tile is drawn with.
+	Palette int
+	// Columns is the sheet width in tiles (default 16).
+	Columns int
+	// TransparentZero draws color index 0 as transparent, as sprites do.
+	TransparentZero bool
+	// KeepBlank keeps the all-zero tiles after the last used one; by
+	// default the sheet ends at the last tile with any set pixel.
+	KeepBlank bool
+}
+
+// TileSheet decodes vram as tiles drawn with one CGRAM palette. cgram holds
+// 256 little-endian RGB555 colors.
+func TileSheet(vram, cgram []byte, opts TileSheetOptions) (*image.NRGBA, error) {
+	if opts.TileSize == 0 {
+		opts.TileSize = 8
+	}
+	if opts.TileSize != 8 && opts.TileSize != 16 {
+		return nil, fmt.Errorf("tile size %d, want 8 or 16", opts.TileSize)
+	}
+	if opts.Palette < 0 || opts.Palette > 15 {
+		return nil, fmt.Errorf("palette %d out of range 0-15", opts.Palette)
+	}
+	if opts.Columns <= 0 {
+		opts.Columns = 16
+	}
+	size := opts.TileSize
+	tileBytes := size * size / 2
+	count := len(vram) / tileBytes
+	if !opts.KeepBlank {
+		for count > 0 && isBlankTile(vram[(count-1)*tileBytes:count*tileBytes]) {
+			count--
+		}
+		if count == 0 {
+			count = 1
+		}
+	}
+	rows := (count + opts.Columns - 1) / opts.Columns
+	img := image.NewNRGBA(image.Rect(0, 0, opts.Columns*size, rows*size))
+
+	var colors [16]color.NRGBA
+	for i := range colors {
+		colors[i] = cgramColor(cgram, opts.Palette*16+i)
+	}
+	if opts.TransparentZero {
+		colors[0] = color.NRGBA{}
+	}
+	for t := 0; t < count; t++ {
+		tile := vram[t*tileBytes : (t+1)*tileBytes]
+		ox, oy := (t%opts.Columns)*size, (t/opts.Columns)*size
+		for p := 0; p < size*size; p++ {
+			index := tile[p/2] >> 4
+			if p%2 == 1 {
+				index = tile[p/2] & 0x0F
+			}
+			img.SetNRGBA(ox+p%size, oy+p/size, colors[index])
+		}
+	}
+	return img, nil
+}
+
+// PaletteStrip draws CGRAM as 16 rows (one per palette) of 16 swatches,
+// each swatch pixels square.
+func PaletteStrip(cgram []byte, swatch int) *image.NRGBA {
+	if swatch <= 0 {
+		swatch = 16
+	}
+	img := image.NewNRGBA(image.Rect(0, 0, 16*swatch, 16*swatch))
+	for i := 0; i < 256; i++ {
+		c := cgramColor(cgram, i)
+		ox, oy := (i%16)*swatch, (i/16)*swatch
+		for y := 0; y < swatch; y++ {
+			for x := 0; x < swatch; x++ {
+				img.SetNRGBA(ox+x, oy+y, c)
+			}
+		}
+	}
+	return img
+}
+
+// ExportTileSheet decodes the live VRAM with the live CGRAM.
+func (e *Emulator) ExportTileSheet(opts TileSheetOptions) (*image.NRGBA, error) {
+	return TileSheet(e.PPU.VRAM[:], e.PPU.CGRAM[:], opts)
+}
+
+// ExportPaletteStrip draws the live CGRAM (see PaletteStrip).
+func (e *Emulator) ExportPaletteStrip(swatch int) *image.NRGBA {
+	return PaletteStrip(e.PPU.CGRAM[:], swatch)
+}
+
+// cgramColor decodes CGRAM entry i the way the PPU does: red in bits 14-10,
+// green in 9-5, blue in 4-0.
+func cgramColor(cgram []byte, i int) color.NRGBA {
+	if i*2+1 >= len(cgram) {
+		return color.NRGBA{A: 0xFF}
+	}
+	v := uint32(cgram[i*2]) | uint32(cgram[i*2+1])<<8
+	return color.NRGBA{
+		R: uint8(((v >> 10) & 0x1F) * 255 / 31),
+		G: uint8(((v >> 5) & 0x1F) * 255 / 31),
+		B: uint8((v & 0x1F) * 255 / 31),
+		A: 0xFF,
+	}
+}
+
+func isBlankTile(tile []byte) bool {
+	for _, b := range tile {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
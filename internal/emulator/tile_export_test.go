@@ -0,0 +1,87 @@
+package emulator
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestTileSheetDecodesTilesWithPalette(t *testing.T) {
+	vram := make([]byte, 0x10000)
+	cgram := make([]byte, 512)
+	// Palette 2: color 1 red, color 2 blue.
+	cgram[(2*16+1)*2+1] = 0x7C
+	cgram[(2*16+2)*2] = 0x1F
+	// Tile 3, top row: pixels 0-1 are colors 1 and 2.
+	vram[3*32] = 0x12
+
+	img, err := TileSheet(vram, cgram, TileSheetOptions{Palette: 2, Columns: 2})
+	if err != nil {
+		t.Fatalf("tile sheet: %v", err)
+	}
+	// Tiles 0-3 survive trimming: two rows of two.
+	if b := img.Bounds(); b.Dx() != 16 || b.Dy() != 16 {
+		t.Fatalf("sheet size = %v, want 16x16", b)
+	}
+	red := color.NRGBA{R: 255, A: 255}
+	blue := color.NRGBA{B: 255, A: 255}
+	if got := img.NRGBAAt(8, 8); got != red {
+		t.Fatalf("tile 3 pixel 0 = %v, want red", got)
+	}
+	if got := img.NRGBAAt(9, 8); got != blue {
+		t.Fatalf("tile 3 pixel 1 = %v, want blue", got)
+	}
+	if got := img.NRGBAAt(0, 0); got != (color.NRGBA{A: 255}) {
+		t.Fatalf("index 0 should be opaque black, got %v", got)
+	}
+
+	img, _ = TileSheet(vram, cgram, TileSheetOptions{Palette: 2, TransparentZero: true, KeepBlank: true})
+	if b := img.Bounds(); b.Dx() != 128 || b.Dy() != 2048/16*8 {
+		t.Fatalf("untrimmed 8x8 sheet = %v", b)
+	}
+	if got := img.NRGBAAt(0, 0); got.A != 0 {
+		t.Fatalf("index 0 should be transparent, got %v", got)
+	}
+
+	img, _ = TileSheet(vram, cgram, TileSheetOptions{TileSize: 16, Palette: 2})
+	if b := img.Bounds(); b.Dx() != 256 || b.Dy() != 16 {
+		t.Fatalf("16x16 sheet = %v", b)
+	}
+	// Byte 96 (8x8 tile 3) is row 12 of 16x16 tile 0.
+	if got := img.NRGBAAt(0, 12); got != red {
+		t.Fatalf("16x16 decode = %v, want red", got)
+	}
+
+	for _, bad := range []TileSheetOptions{{TileSize: 4}, {Palette: 16}, {Palette: -1}} {
+		if _, err := TileSheet(vram, cgram, bad); err == nil {
+			t.Fatalf("expected %+v to be rejected", bad)
+		}
+	}
+}
+
+func TestPaletteStripAndLiveExport(t *testing.T) {
+	emu := NewEmulator()
+	// Color 17 (palette 1, color 1) = green, written through the CGRAM ports.
+	emu.Bus.Write8(0, 0x8012, 17)
+	emu.Bus.Write8(0, 0x8013, 0xE0)
+	emu.Bus.Write8(0, 0x8013, 0x03)
+
+	strip := emu.ExportPaletteStrip(4)
+	if b := strip.Bounds(); b.Dx() != 64 || b.Dy() != 64 {
+		t.Fatalf("strip size = %v", b)
+	}
+	if got := strip.NRGBAAt(1*4+2, 1*4+2); got != (color.NRGBA{G: 255, A: 255}) {
+		t.Fatalf("swatch 17 = %v, want green", got)
+	}
+
+	emu.PPU.VRAM[0] = 0x10
+	sheet, err := emu.ExportTileSheet(TileSheetOptions{Palette: 1})
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if b := sheet.Bounds(); b.Dx() != 128 || b.Dy() != 8 {
+		t.Fatalf("trimmed sheet = %v", b)
+	}
+	if got := sheet.NRGBAAt(0, 0); got != (color.NRGBA{G: 255, A: 255}) {
+		t.Fatalf("live tile pixel = %v, want green", got)
+	}
+}
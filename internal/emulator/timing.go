@@ -0,0 +1,54 @@
+package emulator
+
+import (
+	"time"
+
+	"nitro-core-dx/internal/ppu"
+	"nitro-core-dx/internal/rom"
+)
+
+// SetTiming switches the video timing mode (see ppu.Timing). The frame gets
+// longer or shorter in cycles, the frame limiter retargets to 50 or 60 FPS
+// and the audio frame is resized so 44,100 samples still make a second
+// (882 per frame at 50 Hz, 735 at 60 Hz). The APU's per-frame updates
+// (legacy note durations) run once per frame either way. Call it between
+// frames; LoadROM calls it with the ROM's header timing.
+func (e *Emulator) SetTiming(t ppu.Timing) {
+	fps := t.FramesPerSecond()
+	e.PPU.Timing = t
+	e.CyclesPerFrame = t.CyclesPerFrame()
+	e.TargetFPS = float64(fps)
+	e.FrameTime = time.Second / time.Duration(fps)
+	if n := 44100 / fps; len(e.AudioSampleBuffer) != n {
+		e.AudioSampleBuffer = make([]int16, n)
+		if e.AudioFloatBuffer != nil {
+			e.AudioFloatBuffer = make([]float32, n)
+		}
+	}
+}
+
+// Timing is the current video timing mode.
+func (e *Emulator) Timing() ppu.Timing {
+	return e.PPU.Timing
+}
+
+// SetTimingOverride makes every ROM loaded from now on run at t whatever
+// its header says; nil goes back to following the header. The running ROM
+// switches immediately.
+func (e *Emulator) SetTimingOverride(t *ppu.Timing) {
+	e.timingOverride = t
+	if t != nil {
+		e.SetTiming(*t)
+	} else if e.romImage != nil {
+		e.SetTiming(headerTiming(e.romImage))
+	}
+}
+
+// headerTiming is the timing a ROM's header asks for; a missing or unknown
+// flag means 60 Hz.
+func headerTiming(data []uint8) ppu.Timing {
+	if h, err := rom.ParseHeader(data); err == nil && h.Timing == rom.TimingFlag50Hz {
+		return ppu.Timing50Hz
+	}
+	return ppu.Timing60Hz
+}
@@ -0,0 +1,57 @@
+package emulator
+
+import (
+	"testing"
+
+	"nitro-core-dx/internal/ppu"
+	"nitro-core-dx/internal/rom"
+)
+
+// TestTimingFollowsHeaderUnlessOverridden loads a ROM flagged 50 Hz: it runs
+// 153,384-cycle frames with 882 audio samples and one PPU frame per
+// RunFrame, until an override forces it back to 60 Hz.
+func TestTimingFollowsHeaderUnlessOverridden(t *testing.T) {
+	data := spinROM(t)
+	if err := rom.SetTiming(data, rom.TimingFlag50Hz); err != nil {
+		t.Fatal(err)
+	}
+	emu := NewEmulator()
+	emu.SetFloatAudioMixing(true)
+	if err := emu.LoadROM(data); err != nil {
+		t.Fatal(err)
+	}
+	emu.Deterministic = true
+	emu.Start()
+
+	if emu.Timing() != ppu.Timing50Hz || emu.CyclesPerFrame != 153384 || emu.TargetFPS != 50 {
+		t.Fatalf("50 Hz ROM: timing %v, %d cycles per frame, %v FPS", emu.Timing(), emu.CyclesPerFrame, emu.TargetFPS)
+	}
+	if len(emu.AudioSampleBuffer) != 882 || len(emu.AudioFloatBuffer) != 882 {
+		t.Fatalf("audio frame = %d/%d samples, want 882", len(emu.AudioSampleBuffer), len(emu.AudioFloatBuffer))
+	}
+	start := emu.PPU.FrameCounter
+	for i := 0; i < 3; i++ {
+		if err := emu.RunFrame(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := emu.PPU.FrameCounter - start; got != 3 {
+		t.Errorf("3 frames at 50 Hz advanced the PPU frame counter by %d", got)
+	}
+
+	t60 := ppu.Timing60Hz
+	emu.SetTimingOverride(&t60)
+	if emu.CyclesPerFrame != 127820 || len(emu.AudioSampleBuffer) != 735 {
+		t.Errorf("override: %d cycles, %d samples per frame", emu.CyclesPerFrame, len(emu.AudioSampleBuffer))
+	}
+	if err := emu.LoadROM(data); err != nil {
+		t.Fatal(err)
+	}
+	if emu.Timing() != ppu.Timing60Hz {
+		t.Errorf("reloading with an override: timing %v", emu.Timing())
+	}
+	emu.SetTimingOverride(nil)
+	if emu.Timing() != ppu.Timing50Hz {
+		t.Errorf("clearing the override: timing %v", emu.Timing())
+	}
+}
@@ -0,0 +1,70 @@
+package emulator
+
+import (
+	"fmt"
+	"time"
+
+	"nitro-core-dx/internal/debug"
+)
+
+// dmaDestNames names PPU DMA destination types in trace events.
+var dmaDestNames = [...]string{"VRAM", "CGRAM", "OAM", "matrix tilemap", "matrix pattern", "matrix bitmap", "matrix row params", "dest 7"}
+
+// EnableTrace records the emulator's performance events into rec: a span
+// per frame (debug.TraceTrackFrames), per CPU slice the clock runs between
+// audio catch-ups (debug.TraceTrackCPU) and per DMA transfer
+// (debug.TraceTrackDMA). Hosts add their own audio and UI events to
+// e.Trace. A nil rec stops recording.
+//
+// All times are host wall-clock; tracing never changes emulated state.
+// CPU slices are only recorded when cycle logging is off, since the cycle
+// logger steps one cycle at a time.
+func (e *Emulator) EnableTrace(rec *debug.TraceRecorder) {
+	e.Trace = rec
+	if rec == nil {
+		e.PPU.DMAHook = nil
+		return
+	}
+	var dmaStart time.Time
+	var dmaArgs map[string]interface{}
+	var dmaName string
+	e.PPU.DMAHook = func(active bool) {
+		p := e.PPU
+		if active {
+			dmaStart = time.Now()
+			mode := "copy"
+			if p.DMAMode == 1 {
+				mode = "fill"
+			}
+			dmaName = fmt.Sprintf("DMA %s %s", mode, dmaDestNames[p.DMADestType&7])
+			dmaArgs = map[string]interface{}{
+				"length":   p.DMALength,
+				"source":   fmt.Sprintf("%02X:%04X", p.DMASourceBank, p.DMASourceOffset),
+				"dest":     fmt.Sprintf("0x%04X", p.DMADestAddr),
+				"frame":    e.FrameCount,
+				"scanline": p.GetScanline(),
+			}
+			return
+		}
+		if !dmaStart.IsZero() {
+			e.Trace.Span(debug.TraceTrackDMA, dmaName, dmaStart, time.Now(), dmaArgs)
+			dmaStart = time.Time{}
+		}
+	}
+}
+
+// traceCPUSlice records a clock run of cycles cycles that started at start
+// with the CPU at bank:offset.
+func (e *Emulator) traceCPUSlice(start time.Time, cycles uint64, bank uint8, offset uint16) {
+	e.Trace.Span(debug.TraceTrackCPU, "CPU slice", start, time.Now(), map[string]interface{}{
+		"cycles": cycles,
+		"pc":     fmt.Sprintf("%02X:%04X", bank, offset),
+	})
+}
+
+// traceFrame records the frame that just ran, from start.
+func (e *Emulator) traceFrame(start time.Time) {
+	e.Trace.Span(debug.TraceTrackFrames, fmt.Sprintf("Frame %d", e.FrameCount), start, time.Now(), map[string]interface{}{
+		"cpu_cycles": e.CPUCyclesPerFrame,
+	})
+}
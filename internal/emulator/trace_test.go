@@ -0,0 +1,72 @@
+package emulator
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"nitro-core-dx/internal/debug"
+)
+
+func TestEnableTraceRecordsFramesCPUAndDMA(t *testing.T) {
+	emu := NewEmulator()
+	romData := make([]uint8, 64)
+	copy(romData, []uint8{0x52, 0x4D, 0x43, 0x46, 0x01, 0x00, 0x20})
+	romData[10] = 0x01
+	romData[13] = 0x80
+	if err := emu.LoadROM(romData); err != nil {
+		t.Fatalf("Failed to load ROM: %v", err)
+	}
+	emu.Start()
+	emu.SetFrameLimit(false)
+	emu.Clock.CPUStep = func(cycles uint64) error {
+		emu.CPU.State.Cycles += uint32(cycles)
+		return nil
+	}
+	rec := debug.NewTraceRecorder(0)
+	emu.EnableTrace(rec)
+
+	// A 32-byte VRAM fill, started between frames.
+	p := emu.PPU
+	p.Write8(0x66, 32) // DMA_LENGTH_L
+	p.Write8(0x67, 0)  // DMA_LENGTH_H
+	p.Write8(0x60, 0x03)
+	for i := 0; i < 2; i++ {
+		if err := emu.RunFrame(); err != nil {
+			t.Fatalf("RunFrame failed: %v", err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := rec.WriteChromeTrace(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var trace struct {
+		TraceEvents []struct {
+			Name string
+			Cat  string
+			Ph   string
+		}
+	}
+	if err := json.Unmarshal(buf.Bytes(), &trace); err != nil {
+		t.Fatalf("trace is not JSON: %v", err)
+	}
+	count := map[string]int{}
+	for _, ev := range trace.TraceEvents {
+		if ev.Ph == "X" {
+			count[ev.Cat]++
+		}
+	}
+	if count[debug.TraceTrackFrames] != 2 || count[debug.TraceTrackCPU] == 0 || count[debug.TraceTrackDMA] != 1 {
+		t.Fatalf("span counts by track = %v, want 2 frames, CPU slices and 1 DMA", count)
+	}
+
+	emu.EnableTrace(nil)
+	n := rec.Len()
+	if err := emu.RunFrame(); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Len() != n {
+		t.Errorf("events recorded after EnableTrace(nil): %d -> %d", n, rec.Len())
+	}
+}
@@ -0,0 +1,129 @@
+package emulator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"nitro-core-dx/internal/debug"
+)
+
+// The VBlank watchdog catches a ROM stuck polling VBLANK_FLAG. VBlank comes
+// every frame and the flag reads as set all through it, so a working wait
+// loop sees it within a frame. Reads from one tiny loop (PCs within
+// vblankWatchSpan bytes, each read within vblankWatchGap cycles of the
+// last) that find the flag clear for longer than a frame mean the loop is
+// reading it wrong or something else clears it first; rather than let the
+// machine spin silently, RunFrame stops with a *VBlankStall.
+const (
+	vblankWatchSpan = 32
+	vblankWatchGap  = 256
+)
+
+// vblankWatch is the current run of polling reads that found the flag
+// clear.
+type vblankWatch struct {
+	polling     bool
+	start, last uint32 // CPU cycles of the first and latest read
+	lo, hi      uint32 // lowest and highest PC (bank<<16 | offset) seen
+}
+
+// VBlankStall is the error RunFrame returns when the VBlank watchdog trips.
+type VBlankStall struct {
+	PCBank uint8
+	// Lo..Hi are the first and last instructions of the loop.
+	Lo, Hi uint16
+	Cycles uint32 // how long the loop polled without seeing VBlank
+	Frame  uint64
+	// Loop is the loop's instructions in address order, from the CPU's
+	// recent instructions.
+	Loop []CodeLine
+}
+
+func (s *VBlankStall) Error() string {
+	return fmt.Sprintf("stuck waiting for VBlank: loop at %02X:%04X..%04X read VBLANK_FLAG clear for %d cycles (more than a frame)",
+		s.PCBank, s.Lo, s.Hi, s.Cycles)
+}
+
+// Listing is the loop's disassembly, one instruction per line.
+func (s *VBlankStall) Listing() string {
+	var sb strings.Builder
+	for _, l := range s.Loop {
+		sb.WriteString("  " + l.String() + "\n")
+	}
+	return sb.String()
+}
+
+// SetVBlankWatchdog turns the VBlank watchdog on or off. It is on in a new
+// emulator.
+func (e *Emulator) SetVBlankWatchdog(enabled bool) {
+	e.vblankWatch = vblankWatch{}
+	e.vblankStall = nil
+	if enabled {
+		e.PPU.VBlankFlagRead = e.watchVBlankRead
+	} else {
+		e.PPU.VBlankFlagRead = nil
+	}
+}
+
+func (e *Emulator) watchVBlankRead(set bool) {
+	w := &e.vblankWatch
+	if set {
+		w.polling = false
+		return
+	}
+	pc := uint32(e.CPU.State.PCBank)<<16 | uint32(e.CPU.State.PCOffset)
+	now := e.CPU.State.Cycles
+	lo, hi := min(w.lo, pc), max(w.hi, pc)
+	if !w.polling || now-w.last > vblankWatchGap || lo>>16 != hi>>16 || hi-lo > vblankWatchSpan {
+		*w = vblankWatch{polling: true, start: now, last: now, lo: pc, hi: pc}
+		return
+	}
+	w.last, w.lo, w.hi = now, lo, hi
+	if e.vblankStall == nil && uint64(now-w.start) > e.CyclesPerFrame {
+		e.vblankStall = e.newVBlankStall()
+		if e.Logger != nil {
+			e.Logger.LogCPU(debug.LogLevelError, e.vblankStall.Error()+"\n"+e.vblankStall.Listing(), nil)
+		}
+	}
+}
+
+func (e *Emulator) newVBlankStall() *VBlankStall {
+	w := e.vblankWatch
+	s := &VBlankStall{
+		PCBank: uint8(w.lo >> 16),
+		Lo:     uint16(w.lo),
+		Hi:     uint16(w.hi),
+		Cycles: w.last - w.start,
+		Frame:  e.FrameCount,
+	}
+	seen := make(map[uint32]bool)
+	var pcs []uint32
+	for _, pc := range e.CPU.RecentPCs() {
+		if !seen[pc] && uint8(pc>>16) == s.PCBank {
+			seen[pc] = true
+			pcs = append(pcs, pc)
+		}
+	}
+	sort.Slice(pcs, func(i, j int) bool { return pcs[i] < pcs[j] })
+	if len(pcs) > 0 {
+		s.Lo, s.Hi = uint16(pcs[0]), uint16(pcs[len(pcs)-1])
+	}
+	for _, pc := range pcs {
+		inst, _ := e.DecodeAt(uint8(pc>>16), uint16(pc))
+		s.Loop = append(s.Loop, codeLine(inst, nil))
+	}
+	return s
+}
+
+// takeVBlankStall returns the stall the watchdog found, if any, and starts
+// watching afresh.
+func (e *Emulator) takeVBlankStall() error {
+	s := e.vblankStall
+	if s == nil {
+		return nil
+	}
+	e.vblankStall = nil
+	e.vblankWatch = vblankWatch{}
+	return s
+}
@@ -0,0 +1,64 @@
+package emulator
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"nitro-core-dx/internal/rom"
+)
+
+// vblankPollROM spins reading VBLANK_FLAG forever, never leaving the loop.
+func vblankPollROM(t *testing.T) []byte {
+	t.Helper()
+	b := rom.NewROMBuilder()
+	b.AddInstruction(rom.EncodeMOV(1, 4, 0)) // MOV R4, #VBLANK_FLAG
+	b.AddImmediate(0x803E)
+	b.AddInstruction(rom.EncodeMOV(2, 5, 4)) // loop: MOV R5, [R4]
+	b.AddInstruction(rom.EncodeJMP())
+	b.AddImmediate(uint16(rom.CalculateBranchOffset(0x8008, 0x8004)))
+	data, err := b.BuildROMBytes(1, 0x8000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestVBlankWatchdogReportsLoopThatNeverSeesVBlank(t *testing.T) {
+	emu := NewEmulator()
+	defer emu.Logger.Shutdown()
+	if err := emu.LoadROM(vblankPollROM(t)); err != nil {
+		t.Fatal(err)
+	}
+	emu.SetFrameLimit(false)
+	emu.Start()
+
+	// Polling the real flag sees every VBlank, so the loop is left alone.
+	for i := 0; i < 3; i++ {
+		if err := emu.RunFrame(); err != nil {
+			t.Fatalf("frame %d with a working flag: %v", i, err)
+		}
+	}
+
+	// A flag that never reads set trips the watchdog within two frames.
+	watch := emu.PPU.VBlankFlagRead
+	emu.PPU.VBlankFlagRead = func(bool) { watch(false) }
+	var err error
+	for i := 0; i < 2 && err == nil; i++ {
+		err = emu.RunFrameGuarded(nil)
+	}
+	var f *Fault
+	if !errors.As(err, &f) || f.Component != "CPU" || !strings.Contains(f.Message, "loop at 01:8004..8006") {
+		t.Fatalf("got %v, want a CPU fault for the stuck wait", err)
+	}
+	if !strings.Contains(f.Detail, "01:8004") || !strings.Contains(f.Detail, "01:8006") || !strings.Contains(f.Report(), "VBlank wait loop:") {
+		t.Errorf("report lacks the loop disassembly:\n%s", f.Report())
+	}
+
+	emu.SetVBlankWatchdog(false)
+	for i := 0; i < 3; i++ {
+		if err := emu.RunFrame(); err != nil {
+			t.Fatalf("watchdog off: %v", err)
+		}
+	}
+}
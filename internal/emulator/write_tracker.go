@@ -0,0 +1,266 @@
+package emulator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// writeHistoryDepth is how many writes write tracking keeps per byte; older
+// ones are overwritten ring-buffer style.
+const writeHistoryDepth = 4
+
+// MemorySpace names a memory the debugger tools can address.
+type MemorySpace uint8
+
+const (
+	// MemoryWRAM is work RAM as the CPU sees it: bank 0 0x0000-0x7FFF and
+	// extended work RAM in banks 126-127. Addresses are bank<<16 | offset.
+	MemoryWRAM MemorySpace = iota
+	// MemoryVRAM is the PPU's 64 KB of VRAM.
+	MemoryVRAM
+	// MemoryOAM is the 768 bytes of sprite attribute memory.
+	MemoryOAM
+)
+
+func (s MemorySpace) String() string {
+	switch s {
+	case MemoryVRAM:
+		return "vram"
+	case MemoryOAM:
+		return "oam"
+	default:
+		return "wram"
+	}
+}
+
+// FormatAddress renders addr for display: "00:1F00", "vram:4000" or
+// "oam:0012".
+func (s MemorySpace) FormatAddress(addr uint32) string {
+	if s == MemoryWRAM {
+		return fmt.Sprintf("%02X:%04X", addr>>16, addr&0xFFFF)
+	}
+	return fmt.Sprintf("%s:%04X", s, addr)
+}
+
+// ParseMemoryAddress parses "bank:offset" (work RAM on the CPU bus, e.g.
+// "0:0x1F00" or "126:0x0010"), "vram:offset" or "oam:offset". Numbers take
+// Go syntax (0x for hex); a bare "$" prefix is hex too.
+func ParseMemoryAddress(s string) (MemorySpace, uint32, error) {
+	prefix, rest, ok := strings.Cut(strings.TrimSpace(s), ":")
+	if !ok {
+		return 0, 0, fmt.Errorf("address %q: want bank:offset, vram:offset or oam:offset", s)
+	}
+	offset, err := parseMemoryNumber(rest, 16)
+	if err != nil {
+		return 0, 0, fmt.Errorf("address %q: bad offset: %w", s, err)
+	}
+	switch strings.ToLower(prefix) {
+	case "vram":
+		return MemoryVRAM, uint32(offset), nil
+	case "oam":
+		if offset >= 768 {
+			return 0, 0, fmt.Errorf("address %q: OAM is 768 bytes", s)
+		}
+		return MemoryOAM, uint32(offset), nil
+	}
+	bank, err := parseMemoryNumber(prefix, 8)
+	if err != nil {
+		return 0, 0, fmt.Errorf("address %q: bad bank: %w", s, err)
+	}
+	addr := uint32(bank)<<16 | uint32(offset)
+	if _, ok := wramIndex(addr); !ok {
+		return 0, 0, fmt.Errorf("address %q is not work RAM (0:0x0000-0x7FFF or 126-127:0x0000-0xFFFF)", s)
+	}
+	return MemoryWRAM, addr, nil
+}
+
+func parseMemoryNumber(s string, bits int) (uint64, error) {
+	s = strings.TrimSpace(s)
+	if rest, ok := strings.CutPrefix(s, "$"); ok {
+		return strconv.ParseUint(rest, 16, bits)
+	}
+	return strconv.ParseUint(s, 0, bits)
+}
+
+// wramIndex maps a bank<<16|offset work RAM address to an index into the
+// combined WRAM + extended WRAM range.
+func wramIndex(addr uint32) (int, bool) {
+	bank, offset := addr>>16, addr&0xFFFF
+	switch {
+	case bank == 0 && offset < 0x8000:
+		return int(offset), true
+	case bank == 126 || bank == 127:
+		return 0x8000 + int(bank-126)<<16 + int(offset), true
+	}
+	return 0, false
+}
+
+// PeekMemory reads one byte of space without bus side effects. ok is false
+// for an address outside it.
+func (e *Emulator) PeekMemory(space MemorySpace, addr uint32) (value uint8, ok bool) {
+	switch space {
+	case MemoryVRAM:
+		if addr < uint32(len(e.PPU.VRAM)) {
+			return e.PPU.VRAM[addr], true
+		}
+	case MemoryOAM:
+		if addr < uint32(len(e.PPU.OAM)) {
+			return e.PPU.OAM[addr], true
+		}
+	default:
+		if i, ok := wramIndex(addr); ok {
+			if i < 0x8000 {
+				return e.Bus.WRAM[i], true
+			}
+			return e.Bus.WRAMExtended[i-0x8000], true
+		}
+	}
+	return 0, false
+}
+
+// WriteRecord is one write seen by write tracking.
+type WriteRecord struct {
+	Value    uint8
+	PCBank   uint8 // CPU program counter at the time of the write
+	PCOffset uint16
+	Frame    uint64
+	DMA      bool // written by a DMA transfer rather than a CPU store
+}
+
+func (r WriteRecord) String() string {
+	s := fmt.Sprintf("0x%02X by PC %02X:%04X, frame %d", r.Value, r.PCBank, r.PCOffset, r.Frame)
+	if r.DMA {
+		s += " (DMA)"
+	}
+	return s
+}
+
+// writeSlot is a WriteRecord packed for the per-byte history arrays.
+type writeSlot struct {
+	frame    uint32
+	pcOffset uint16
+	pcBank   uint8
+	value    uint8
+	dma      bool
+}
+
+// writeHistory is the last writeHistoryDepth writes to one byte.
+type writeHistory struct {
+	slots [writeHistoryDepth]writeSlot
+	next  uint8 // slot the next write goes to
+	count uint8 // valid slots, up to writeHistoryDepth
+}
+
+func (h *writeHistory) add(s writeSlot) {
+	h.slots[h.next] = s
+	h.next = (h.next + 1) % writeHistoryDepth
+	if h.count < writeHistoryDepth {
+		h.count++
+	}
+}
+
+// writeTracker holds a history for every tracked byte.
+type writeTracker struct {
+	wram []writeHistory // WRAM then extended WRAM, see wramIndex
+	vram []writeHistory
+	oam  []writeHistory
+}
+
+// EnableWriteTracking switches last-write tracking on or off. While on,
+// every write to work RAM, VRAM and OAM records the value, the PC and the
+// frame, keeping the last four per byte (see LastWrites). Turning it off
+// drops the history. A power cycle or LoadState clears it.
+func (e *Emulator) EnableWriteTracking(enabled bool) {
+	if !enabled {
+		e.writes = nil
+		e.Bus.RAMWrite = nil
+		e.PPU.VRAMWrite = nil
+		e.PPU.OAMWrite = nil
+		return
+	}
+	if e.writes == nil {
+		e.writes = &writeTracker{
+			wram: make([]writeHistory, 0x8000+len(e.Bus.WRAMExtended)),
+			vram: make([]writeHistory, len(e.PPU.VRAM)),
+			oam:  make([]writeHistory, len(e.PPU.OAM)),
+		}
+	}
+	e.Bus.RAMWrite = func(bank uint8, offset uint16, value uint8) {
+		if i, ok := wramIndex(uint32(bank)<<16 | uint32(offset)); ok {
+			e.writes.wram[i].add(e.writeSlot(value, false))
+		}
+	}
+	e.PPU.VRAMWrite = func(addr uint16, value uint8, dma bool) {
+		e.writes.vram[addr].add(e.writeSlot(value, dma))
+	}
+	e.PPU.OAMWrite = func(addr uint16, value uint8, dma bool) {
+		if int(addr) < len(e.writes.oam) {
+			e.writes.oam[addr].add(e.writeSlot(value, dma))
+		}
+	}
+}
+
+// WriteTracking reports whether last-write tracking is on.
+func (e *Emulator) WriteTracking() bool {
+	return e.writes != nil
+}
+
+func (e *Emulator) writeSlot(value uint8, dma bool) writeSlot {
+	return writeSlot{
+		frame:    uint32(e.FrameCount),
+		pcOffset: e.CPU.State.PCOffset,
+		pcBank:   e.CPU.State.PCBank,
+		value:    value,
+		dma:      dma,
+	}
+}
+
+// clearWriteHistory forgets every tracked write, keeping tracking on.
+func (e *Emulator) clearWriteHistory() {
+	if e.writes == nil {
+		return
+	}
+	clear(e.writes.wram)
+	clear(e.writes.vram)
+	clear(e.writes.oam)
+}
+
+// LastWrites returns the tracked writes to one byte, newest first. It is
+// empty when nothing has written the byte since tracking started, and an
+// error when tracking is off or addr is outside space.
+func (e *Emulator) LastWrites(space MemorySpace, addr uint32) ([]WriteRecord, error) {
+	if e.writes == nil {
+		return nil, fmt.Errorf("write tracking is off")
+	}
+	var h *writeHistory
+	switch space {
+	case MemoryVRAM:
+		if addr < uint32(len(e.writes.vram)) {
+			h = &e.writes.vram[addr]
+		}
+	case MemoryOAM:
+		if addr < uint32(len(e.writes.oam)) {
+			h = &e.writes.oam[addr]
+		}
+	default:
+		if i, ok := wramIndex(addr); ok {
+			h = &e.writes.wram[i]
+		}
+	}
+	if h == nil {
+		return nil, fmt.Errorf("%s is outside %s", space.FormatAddress(addr), space)
+	}
+	out := make([]WriteRecord, 0, h.count)
+	for n := 1; n <= int(h.count); n++ {
+		s := h.slots[(int(h.next)-n+writeHistoryDepth)%writeHistoryDepth]
+		out = append(out, WriteRecord{
+			Value:    s.value,
+			PCBank:   s.pcBank,
+			PCOffset: s.pcOffset,
+			Frame:    uint64(s.frame),
+			DMA:      s.dma,
+		})
+	}
+	return out, nil
+}
@@ -0,0 +1,78 @@
+package emulator
+
+import (
+	"testing"
+
+	"nitro-core-dx/internal/hwdef"
+)
+
+func TestParseMemoryAddress(t *testing.T) {
+	cases := []struct {
+		in    string
+		space MemorySpace
+		addr  uint32
+	}{
+		{"0:0x1F00", MemoryWRAM, 0x1F00},
+		{"126:$10", MemoryWRAM, 126<<16 | 0x10},
+		{"vram:0x4000", MemoryVRAM, 0x4000},
+		{"OAM:12", MemoryOAM, 12},
+	}
+	for _, c := range cases {
+		space, addr, err := ParseMemoryAddress(c.in)
+		if err != nil || space != c.space || addr != c.addr {
+			t.Errorf("ParseMemoryAddress(%q) = %v, %#x, %v; want %v, %#x", c.in, space, addr, err, c.space, c.addr)
+		}
+	}
+	for _, bad := range []string{"0x1F00", "0:0x9000", "1:0x8000", "oam:768", "vram:x"} {
+		if _, _, err := ParseMemoryAddress(bad); err == nil {
+			t.Errorf("ParseMemoryAddress(%q) succeeded, want an error", bad)
+		}
+	}
+}
+
+func TestLastWritesRecordsPCAndFrame(t *testing.T) {
+	emu := NewEmulator()
+	if err := emu.LoadROM(persistTestROM(1)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := emu.LastWrites(MemoryWRAM, 0x1F00); err == nil {
+		t.Fatal("LastWrites succeeded with tracking off")
+	}
+	emu.EnableWriteTracking(true)
+
+	emu.CPU.State.PCBank, emu.CPU.State.PCOffset = 1, 0x8010
+	emu.Bus.Write8(0, 0x1F00, 0x11)
+	emu.FrameCount = 7
+	emu.CPU.State.PCOffset = 0x8020
+	for v := uint8(0x12); v <= 0x16; v++ {
+		emu.Bus.Write8(0, 0x1F00, v)
+	}
+	writes, err := emu.LastWrites(MemoryWRAM, 0x1F00)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(writes) != writeHistoryDepth {
+		t.Fatalf("got %d writes, want the last %d", len(writes), writeHistoryDepth)
+	}
+	if w := writes[0]; w.Value != 0x16 || w.PCBank != 1 || w.PCOffset != 0x8020 || w.Frame != 7 || w.DMA {
+		t.Errorf("newest write = %+v", w)
+	}
+	if writes[3].Value != 0x13 {
+		t.Errorf("oldest kept write = 0x%02X, want 0x13", writes[3].Value)
+	}
+
+	emu.Bus.Write8(0, hwdef.VRAM_ADDR_L, 0x34)
+	emu.Bus.Write8(0, hwdef.VRAM_ADDR_H, 0x12)
+	emu.Bus.Write8(0, hwdef.VRAM_DATA, 0xAB)
+	if writes, _ := emu.LastWrites(MemoryVRAM, 0x1234); len(writes) != 1 || writes[0].Value != 0xAB {
+		t.Errorf("VRAM writes = %v, want one 0xAB", writes)
+	}
+	if writes, _ := emu.LastWrites(MemoryWRAM, 0x1F01); len(writes) != 0 {
+		t.Errorf("untouched byte has writes: %v", writes)
+	}
+
+	emu.Reset()
+	if writes, _ := emu.LastWrites(MemoryWRAM, 0x1F00); len(writes) != 0 {
+		t.Errorf("power cycle kept write history: %v", writes)
+	}
+}
@@ -0,0 +1,151 @@
+package fileassoc
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"nitro-core-dx/internal/emulator"
+	"nitro-core-dx/internal/link"
+)
+
+// maxBundleEntry caps how much of one zip entry is extracted, well above the
+// largest ROM the cartridge maps, so a hostile archive cannot exhaust memory.
+const maxBundleEntry = 16 << 20
+
+// Bundle is a zipped game: exactly one .rom, plus optionally the link map it
+// was built with (for symbols) and anything else, such as sources or notes,
+// which is ignored.
+type Bundle struct {
+	ROMName string // the ROM's path inside the archive
+	ROM     []byte
+	Symbols emulator.SymbolTable // from the link map; nil without one
+}
+
+// ReadBundle reads the zip at path and extracts its ROM in memory.
+func ReadBundle(path string) (*Bundle, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	b, err := OpenBundle(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	return b, nil
+}
+
+// OpenBundle extracts the ROM and symbols from zip archive data. The link
+// map used is the one named after the ROM (game.rom, game.map), or else the
+// archive's only .map.
+func OpenBundle(data []byte) (*Bundle, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("read zip: %w", err)
+	}
+	var roms, maps []*zip.File
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || isArchiveJunk(f.Name) {
+			continue
+		}
+		switch strings.ToLower(path.Ext(f.Name)) {
+		case ".rom":
+			roms = append(roms, f)
+		case ".map":
+			maps = append(maps, f)
+		}
+	}
+	switch len(roms) {
+	case 0:
+		return nil, fmt.Errorf("zip holds no .rom file")
+	case 1:
+	default:
+		names := make([]string, len(roms))
+		for i, f := range roms {
+			names[i] = f.Name
+		}
+		return nil, fmt.Errorf("zip holds %d .rom files (%s); want one", len(roms), strings.Join(names, ", "))
+	}
+
+	b := &Bundle{ROMName: roms[0].Name}
+	if b.ROM, err = readZipEntry(roms[0]); err != nil {
+		return nil, err
+	}
+	if len(b.ROM) == 0 {
+		return nil, fmt.Errorf("%s is empty", b.ROMName)
+	}
+	if mf := bundleMap(b.ROMName, maps); mf != nil {
+		text, err := readZipEntry(mf)
+		if err != nil {
+			return nil, err
+		}
+		m, err := link.ParseMap(text)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", mf.Name, err)
+		}
+		syms := make([]emulator.Symbol, len(m.Symbols))
+		for i, s := range m.Symbols {
+			syms[i] = emulator.Symbol{Name: s.Name, Bank: s.Bank, Offset: s.Address}
+		}
+		b.Symbols = emulator.NewSymbolTable(syms)
+	}
+	return b, nil
+}
+
+// UnpackROM returns the ROM in data, a file called name: a zip has its ROM
+// extracted and anything else is returned as-is. It is for callers, such as
+// file dialogs, that hold a file's bytes rather than its path.
+func UnpackROM(name string, data []byte) ([]byte, error) {
+	if Classify(name) != KindBundle {
+		return data, nil
+	}
+	b, err := OpenBundle(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return b.ROM, nil
+}
+
+// bundleMap picks the link map for the ROM called romName.
+func bundleMap(romName string, maps []*zip.File) *zip.File {
+	want := strings.TrimSuffix(romName, path.Ext(romName)) + ".map"
+	for _, f := range maps {
+		if strings.EqualFold(f.Name, want) {
+			return f
+		}
+	}
+	if len(maps) == 1 {
+		return maps[0]
+	}
+	return nil
+}
+
+// isArchiveJunk reports whether name is metadata an archiver added, such
+// as macOS resource forks, rather than a file the author packed.
+func isArchiveJunk(name string) bool {
+	return strings.HasPrefix(name, "__MACOSX/") || strings.HasPrefix(path.Base(name), "._")
+}
+
+func readZipEntry(f *zip.File) ([]byte, error) {
+	if f.UncompressedSize64 > maxBundleEntry {
+		return nil, fmt.Errorf("%s is too large (%d bytes)", f.Name, f.UncompressedSize64)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", f.Name, err)
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(io.LimitReader(rc, maxBundleEntry+1))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", f.Name, err)
+	}
+	if len(data) > maxBundleEntry {
+		return nil, fmt.Errorf("%s is too large", f.Name)
+	}
+	return data, nil
+}
@@ -0,0 +1,93 @@
+package fileassoc
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// zipOf returns a zip archive holding files, in name order.
+func zipOf(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(files[name]))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestOpenBundleExtractsROMAndSymbols(t *testing.T) {
+	data := zipOf(t, map[string]string{
+		"game/game.rom":         "ROMDATA",
+		"game/game.map":         "Entry: 01:8000\n\nSymbols:\n  01:8000  global  Start                    main\n  01:8040  local   Update                   main\n",
+		"game/src/main.corelx":  "function Start()\n",
+		"game/README.txt":       "have fun",
+		"__MACOSX/game/._a.rom": "resource fork",
+	})
+	b, err := OpenBundle(data)
+	if err != nil {
+		t.Fatalf("OpenBundle: %v", err)
+	}
+	if b.ROMName != "game/game.rom" || string(b.ROM) != "ROMDATA" {
+		t.Fatalf("bundle ROM = %s %q", b.ROMName, b.ROM)
+	}
+	if got := b.Symbols.Lookup(1, 0x8044); got != "Update+0x4" {
+		t.Fatalf("Lookup(01:8044) = %q, want Update+0x4", got)
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "game.zip")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rom, err := ReadROM(path)
+	if err != nil || string(rom) != "ROMDATA" {
+		t.Fatalf("ReadROM(zip) = %q, %v", rom, err)
+	}
+	if rom, err := UnpackROM("game.zip", data); err != nil || string(rom) != "ROMDATA" {
+		t.Fatalf("UnpackROM(zip) = %q, %v", rom, err)
+	}
+	if rom, err := UnpackROM("game.rom", []byte("RAW")); err != nil || string(rom) != "RAW" {
+		t.Fatalf("UnpackROM(rom) = %q, %v", rom, err)
+	}
+}
+
+func TestOpenBundleRejectsAmbiguousArchives(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{"noROM", map[string]string{"README.txt": "?"}, "no .rom"},
+		{"twoROMs", map[string]string{"a.rom": "A", "b.rom": "B"}, "2 .rom files"},
+		{"emptyROM", map[string]string{"a.rom": ""}, "empty"},
+		{"badMap", map[string]string{"a.rom": "A", "a.map": "Entry: here\n"}, "a.map"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := OpenBundle(zipOf(t, tc.files))
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("OpenBundle = %v, want an error mentioning %q", err, tc.want)
+			}
+		})
+	}
+	if _, err := OpenBundle([]byte("not a zip")); err == nil {
+		t.Fatal("OpenBundle accepted data that is not a zip")
+	}
+}
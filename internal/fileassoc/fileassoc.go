@@ -0,0 +1,220 @@
+// Package fileassoc resolves files handed to the emulator and Dev Kit by the
+// desktop (drag-and-drop, --open, file-manager double-click) and writes the
+// freedesktop metadata that associates .rom and .corelx files with them.
+package fileassoc
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"nitro-core-dx/internal/corelx"
+)
+
+// MIME types registered for Nitro-Core-DX files.
+const (
+	ROMMimeType     = "application/x-nitro-core-dx-rom"
+	SourceMimeType  = "text/x-corelx"
+	ProjectMimeType = "application/x-nitro-core-dx-project"
+)
+
+// Kind classifies an openable file.
+type Kind int
+
+const (
+	KindUnknown Kind = iota
+	KindROM          // .rom: load directly
+	KindSource       // .corelx/.clx source or .ncdx project: build first
+	KindBundle       // .zip holding one .rom: extract in memory
+)
+
+// Classify returns the Kind for path based on its extension.
+func Classify(path string) Kind {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".rom":
+		return KindROM
+	case ".corelx", ".clx", ".ncdx":
+		return KindSource
+	case ".zip":
+		return KindBundle
+	}
+	return KindUnknown
+}
+
+// ParseOpenTarget turns an --open argument into a local path. Plain paths
+// are returned as-is; file:// URLs (what desktop launchers pass for %u) are
+// decoded. Other URL schemes are rejected.
+func ParseOpenTarget(arg string) (string, error) {
+	arg = strings.TrimSpace(arg)
+	if arg == "" {
+		return "", fmt.Errorf("empty open target")
+	}
+	if !strings.Contains(arg, "://") {
+		return arg, nil
+	}
+	u, err := url.Parse(arg)
+	if err != nil {
+		return "", fmt.Errorf("invalid open target %q: %w", arg, err)
+	}
+	if u.Scheme != "file" {
+		return "", fmt.Errorf("unsupported open target scheme %q (only file:// URLs)", u.Scheme)
+	}
+	if u.Host != "" && u.Host != "localhost" {
+		return "", fmt.Errorf("remote file URL %q is not supported", arg)
+	}
+	path := u.Path
+	// file:///C:/dir/x.rom parses to /C:/dir/x.rom.
+	if runtime.GOOS == "windows" && len(path) > 2 && path[0] == '/' && path[2] == ':' {
+		path = path[1:]
+	}
+	return filepath.FromSlash(path), nil
+}
+
+// ReadROM returns ROM bytes for path: a .rom file is read as-is, a zipped
+// game has its ROM extracted, and a CoreLX source or project is compiled. A
+// build failure returns the compiler error, which carries the diagnostics.
+func ReadROM(path string) ([]byte, error) {
+	switch Classify(path) {
+	case KindROM:
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if len(data) == 0 {
+			return nil, fmt.Errorf("ROM file is empty")
+		}
+		return data, nil
+	case KindBundle:
+		b, err := ReadBundle(path)
+		if err != nil {
+			return nil, err
+		}
+		return b.ROM, nil
+	case KindSource:
+		res, err := corelx.CompileProject(path, nil)
+		if err != nil {
+			return nil, fmt.Errorf("build %s: %w", filepath.Base(path), err)
+		}
+		if res == nil || len(res.ROMBytes) == 0 {
+			return nil, fmt.Errorf("build %s produced no ROM", filepath.Base(path))
+		}
+		return res.ROMBytes, nil
+	}
+	return nil, fmt.Errorf("unsupported file type %q (expected .rom, .zip or .corelx)", filepath.Ext(path))
+}
+
+// App describes one application to register as a handler.
+type App struct {
+	ID        string // desktop file basename, e.g. "nitro-core-dx-emulator"
+	Name      string
+	Comment   string
+	Exec      string // absolute path to the binary; empty = os.Executable()
+	MimeTypes []string
+}
+
+// DesktopEntry renders a freedesktop .desktop entry that launches the app
+// with --open %u, so the file manager hands over the opened file's URL.
+func DesktopEntry(app App) string {
+	var sb strings.Builder
+	sb.WriteString("[Desktop Entry]\n")
+	sb.WriteString("Type=Application\n")
+	fmt.Fprintf(&sb, "Name=%s\n", app.Name)
+	if app.Comment != "" {
+		fmt.Fprintf(&sb, "Comment=%s\n", app.Comment)
+	}
+	fmt.Fprintf(&sb, "Exec=%s --open %%u\n", quoteExec(app.Exec))
+	sb.WriteString("Terminal=false\n")
+	sb.WriteString("Categories=Development;Game;Emulator;\n")
+	fmt.Fprintf(&sb, "MimeType=%s;\n", strings.Join(app.MimeTypes, ";"))
+	return sb.String()
+}
+
+// MimeInfo renders the shared-mime-info package that maps .rom, .corelx/.clx
+// and .ncdx to the Nitro-Core-DX MIME types.
+func MimeInfo() string {
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<mime-info xmlns="http://www.freedesktop.org/standards/shared-mime-info">
+  <mime-type type="` + ROMMimeType + `">
+    <comment>Nitro-Core-DX ROM</comment>
+    <glob pattern="*.rom"/>
+  </mime-type>
+  <mime-type type="` + SourceMimeType + `">
+    <comment>CoreLX source</comment>
+    <sub-class-of type="text/plain"/>
+    <glob pattern="*.corelx"/>
+    <glob pattern="*.clx"/>
+  </mime-type>
+  <mime-type type="` + ProjectMimeType + `">
+    <comment>Nitro-Core-DX project</comment>
+    <sub-class-of type="application/zip"/>
+    <glob pattern="*.ncdx"/>
+  </mime-type>
+</mime-info>
+`
+}
+
+// Register writes the MIME package and a desktop entry for app under
+// dataHome (empty = $XDG_DATA_HOME or ~/.local/share) and returns the files
+// written. Only freedesktop (Linux/BSD) desktops are supported; refresh the
+// caches afterwards with update-mime-database and update-desktop-database.
+func Register(dataHome string, app App) ([]string, error) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		return nil, fmt.Errorf("file association registration is not supported on %s", runtime.GOOS)
+	}
+	if dataHome == "" {
+		var err error
+		if dataHome, err = DefaultDataHome(); err != nil {
+			return nil, err
+		}
+	}
+	if app.Exec == "" {
+		exe, err := os.Executable()
+		if err != nil {
+			return nil, err
+		}
+		app.Exec = exe
+	}
+	files := []struct {
+		path, data string
+	}{
+		{filepath.Join(dataHome, "mime", "packages", "nitro-core-dx.xml"), MimeInfo()},
+		{filepath.Join(dataHome, "applications", app.ID+".desktop"), DesktopEntry(app)},
+	}
+	written := make([]string, 0, len(files))
+	for _, f := range files {
+		if err := os.MkdirAll(filepath.Dir(f.path), 0o755); err != nil {
+			return written, err
+		}
+		if err := os.WriteFile(f.path, []byte(f.data), 0o644); err != nil {
+			return written, err
+		}
+		written = append(written, f.path)
+	}
+	return written, nil
+}
+
+// DefaultDataHome returns $XDG_DATA_HOME, falling back to ~/.local/share.
+func DefaultDataHome() (string, error) {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return dir, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share"), nil
+}
+
+// quoteExec quotes an Exec path per the desktop entry spec when it contains
+// characters the launcher would otherwise split or expand.
+func quoteExec(path string) string {
+	path = strings.ReplaceAll(path, "%", "%%")
+	if !strings.ContainsAny(path, " \t\"'\\$`") {
+		return path
+	}
+	r := strings.NewReplacer(`\`, `\\\\`, `"`, `\\"`, "$", `\\$`, "`", "\\\\`")
+	return `"` + r.Replace(path) + `"`
+}
@@ -0,0 +1,111 @@
+package fileassoc
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	for path, want := range map[string]Kind{
+		"game.rom":         KindROM,
+		"/x/Game.ROM":      KindROM,
+		"main.corelx":      KindSource,
+		"demo.clx":         KindSource,
+		"pack.ncdx":        KindSource,
+		"Game.ZIP":         KindBundle,
+		"notes.txt":        KindUnknown,
+		"no_extension_rom": KindUnknown,
+	} {
+		if got := Classify(path); got != want {
+			t.Errorf("Classify(%q) = %d, want %d", path, got, want)
+		}
+	}
+}
+
+func TestParseOpenTarget(t *testing.T) {
+	for _, tc := range []struct{ in, want string }{
+		{"/home/me/game.rom", "/home/me/game.rom"},
+		{"relative/demo.corelx", "relative/demo.corelx"},
+		{"file:///home/me/My%20Games/game.rom", filepath.FromSlash("/home/me/My Games/game.rom")},
+		{"file://localhost/tmp/a.rom", filepath.FromSlash("/tmp/a.rom")},
+	} {
+		got, err := ParseOpenTarget(tc.in)
+		if err != nil || got != tc.want {
+			t.Errorf("ParseOpenTarget(%q) = %q, %v; want %q", tc.in, got, err, tc.want)
+		}
+	}
+	for _, bad := range []string{"", "https://example.com/a.rom", "file://otherhost/a.rom"} {
+		if _, err := ParseOpenTarget(bad); err == nil {
+			t.Errorf("ParseOpenTarget(%q): expected error", bad)
+		}
+	}
+}
+
+func TestReadROMBuildsCoreLXSource(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "main.corelx")
+	if err := os.WriteFile(src, []byte("function Start()\n    while true\n        wait_vblank()\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	built, err := ReadROM(src)
+	if err != nil || len(built) == 0 {
+		t.Fatalf("ReadROM(source) = %d bytes, %v", len(built), err)
+	}
+
+	romPath := filepath.Join(dir, "main.rom")
+	if err := os.WriteFile(romPath, built, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ReadROM(romPath)
+	if err != nil || len(data) != len(built) {
+		t.Fatalf("ReadROM(rom) = %d bytes, %v; want %d", len(data), err, len(built))
+	}
+
+	if _, err := ReadROM(filepath.Join(dir, "notes.txt")); err == nil {
+		t.Fatalf("expected unsupported file type error")
+	}
+	bad := filepath.Join(dir, "bad.corelx")
+	if err := os.WriteFile(bad, []byte("function Start(\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadROM(bad); err == nil || !strings.Contains(err.Error(), "build bad.corelx") {
+		t.Fatalf("expected build error, got %v", err)
+	}
+}
+
+func TestRegisterWritesDesktopEntryAndMimeInfo(t *testing.T) {
+	dataHome := t.TempDir()
+	files, err := Register(dataHome, App{
+		ID:        "nitro-core-dx-emulator",
+		Name:      "Nitro-Core-DX Emulator",
+		Exec:      "/opt/ncdx/My Tools/nitro-core-dx",
+		MimeTypes: []string{ROMMimeType, SourceMimeType},
+	})
+	if err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("expected 2 files, got %v", files)
+	}
+	entry, err := os.ReadFile(filepath.Join(dataHome, "applications", "nitro-core-dx-emulator.desktop"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`Exec="/opt/ncdx/My Tools/nitro-core-dx" --open %u`,
+		"MimeType=" + ROMMimeType + ";" + SourceMimeType + ";",
+	} {
+		if !strings.Contains(string(entry), want) {
+			t.Errorf("desktop entry missing %q:\n%s", want, entry)
+		}
+	}
+	mime, err := os.ReadFile(filepath.Join(dataHome, "mime", "packages", "nitro-core-dx.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(mime), `<glob pattern="*.rom"/>`) {
+		t.Errorf("mime info missing *.rom glob:\n%s", mime)
+	}
+}
@@ -0,0 +1,51 @@
+package fileassoc
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Playlist is a directory's ROMs and CoreLX sources in name order, for
+// cycling through them (the emulator's -playlist jukebox mode). Moving past
+// either end wraps around.
+type Playlist struct {
+	Paths []string
+	index int
+}
+
+// LoadPlaylist lists the openable files directly in dir.
+func LoadPlaylist(dir string) (*Playlist, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, e := range entries {
+		if !e.IsDir() && Classify(e.Name()) != KindUnknown {
+			paths = append(paths, filepath.Join(dir, e.Name()))
+		}
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("%s: no .rom or .corelx files", dir)
+	}
+	sort.Strings(paths)
+	return &Playlist{Paths: paths}, nil
+}
+
+// Len is the number of entries.
+func (p *Playlist) Len() int { return len(p.Paths) }
+
+// Index is the current entry's position, from 0.
+func (p *Playlist) Index() int { return p.index }
+
+// Current is the current entry's path.
+func (p *Playlist) Current() string { return p.Paths[p.index] }
+
+// Step moves n entries forward (back when n is negative) and returns the
+// new current path.
+func (p *Playlist) Step(n int) string {
+	p.index = ((p.index+n)%len(p.Paths) + len(p.Paths)) % len(p.Paths)
+	return p.Current()
+}
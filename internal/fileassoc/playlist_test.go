@@ -0,0 +1,47 @@
+package fileassoc
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPlaylist(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"b.rom", "a.rom", "c.corelx", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "sub.rom"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := LoadPlaylist(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, path := range p.Paths {
+		names = append(names, filepath.Base(path))
+	}
+	if got := fmt.Sprint(names); got != "[a.rom b.rom c.corelx]" {
+		t.Fatalf("Paths = %s", got)
+	}
+	if filepath.Base(p.Current()) != "a.rom" {
+		t.Errorf("Current = %s, want a.rom", p.Current())
+	}
+	for _, tt := range []struct {
+		step int
+		want string
+	}{{1, "b.rom"}, {1, "c.corelx"}, {1, "a.rom"}, {-1, "c.corelx"}, {-4, "b.rom"}} {
+		if got := filepath.Base(p.Step(tt.step)); got != tt.want {
+			t.Errorf("Step(%d) = %s, want %s", tt.step, got, tt.want)
+		}
+	}
+
+	if _, err := LoadPlaylist(t.TempDir()); err == nil {
+		t.Error("LoadPlaylist of an empty directory succeeded")
+	}
+}
@@ -0,0 +1,145 @@
+package harness
+
+import (
+	"fmt"
+	"math"
+
+	"nitro-core-dx/internal/emulator"
+)
+
+// ClickOptions configure a ClickDetector.
+type ClickOptions struct {
+	// MinJump is the smallest sample-to-sample change, in int16 steps, that
+	// can count as a click.
+	MinJump int
+	// Ratio is how many times larger than the recent signal's own steps a
+	// jump must be. Square and pulse waves step by their full amplitude on
+	// every edge; the ratio keeps those from counting while a sine or a
+	// held level that suddenly jumps still does.
+	Ratio float64
+	// HalfLife is how many samples it takes the memory of a large step to
+	// halve.
+	HalfLife int
+	// EventWindow is how many samples after an APU note event (see
+	// emulator.Emulator.AudioNoteSamples) a jump is taken as intended: a
+	// note starting, stopping or changing.
+	EventWindow int
+	// EdgeWindow is how many samples after a note event the first jump
+	// that would count is still taken as intended: a new note's first
+	// waveform edge, with no earlier edges to compare it against, comes up
+	// to a period after the note starts.
+	EdgeWindow int
+}
+
+// DefaultClickOptions flag a jump of at least a quarter of full scale that
+// is four times any step of the last ~10 ms and not within 32 samples of a
+// note event; a note's first edge is allowed for ~45 ms (tones down to
+// about 22 Hz).
+func DefaultClickOptions() ClickOptions {
+	return ClickOptions{MinJump: 8192, Ratio: 4, HalfLife: 512, EventWindow: 32, EdgeWindow: 2048}
+}
+
+// AudioClick is one discontinuity in the output.
+type AudioClick struct {
+	// Frame and Sample locate the jump: Sample indexes the frame's
+	// AudioSampleBuffer and is the sample after the jump.
+	Frame  int
+	Sample int
+	// Jump is the signed change from the previous sample.
+	Jump int
+}
+
+func (c AudioClick) String() string {
+	return fmt.Sprintf("frame %d sample %d: jump %+d", c.Frame, c.Sample, c.Jump)
+}
+
+// ClickDetector flags discontinuities ("clicks" or "pops") in captured APU
+// output: sample-to-sample jumps that are large, far larger than the
+// signal's recent steps and not explained by a note event. These are the
+// marks of a phase reset on a running channel, a mixer overflow or a
+// dropped buffer. Feed it one frame at a time; it carries the last sample
+// and the step history across frames.
+type ClickDetector struct {
+	opts  ClickOptions
+	decay float64
+
+	frame   int
+	started bool
+	last    int
+	env     float64 // decaying maximum of recent |steps|
+	quiet   int     // samples left in the current note event window
+	edge    int     // samples left in which a first edge is allowed
+}
+
+// NewClickDetector returns a detector with opts.
+func NewClickDetector(opts ClickOptions) *ClickDetector {
+	d := &ClickDetector{opts: opts, decay: 1}
+	if opts.HalfLife > 0 {
+		d.decay = math.Pow(0.5, 1/float64(opts.HalfLife))
+	}
+	return d
+}
+
+// Reset forgets the previous sample and step history, e.g. after a power
+// cycle.
+func (d *ClickDetector) Reset() {
+	*d = ClickDetector{opts: d.opts, decay: d.decay}
+}
+
+// Feed analyzes one frame of samples. noteSamples are the indexes into
+// samples at which a note event took effect, ascending, as
+// emulator.Emulator.AudioNoteSamples lists them. It returns the frame's
+// clicks; the first frame fed is frame 0.
+func (d *ClickDetector) Feed(samples []int16, noteSamples []int) []AudioClick {
+	var clicks []AudioClick
+	for i, s := range samples {
+		for len(noteSamples) > 0 && noteSamples[0] <= i {
+			noteSamples = noteSamples[1:]
+			d.quiet = d.opts.EventWindow + 1
+			d.edge = d.opts.EdgeWindow + 1
+		}
+		v := int(s)
+		if !d.started {
+			d.started, d.last = true, v
+			continue
+		}
+		jump := v - d.last
+		d.last = v
+		step := float64(abs(jump))
+		click := abs(jump) >= d.opts.MinJump && step > d.opts.Ratio*d.env
+		switch {
+		case d.quiet > 0:
+		case click && d.edge > 0:
+			d.edge = 0
+		case click:
+			clicks = append(clicks, AudioClick{Frame: d.frame, Sample: i, Jump: jump})
+		}
+		d.quiet = max(d.quiet-1, 0)
+		d.edge = max(d.edge-1, 0)
+		d.env = max(step, d.env*d.decay)
+	}
+	d.frame++
+	return clicks
+}
+
+// CheckAudio runs emu for frames frames and returns the clicks in its
+// output. A test can load a ROM, start the emulator and require that
+// CheckAudio finds none.
+func CheckAudio(emu *emulator.Emulator, frames int, opts ClickOptions) ([]AudioClick, error) {
+	d := NewClickDetector(opts)
+	var clicks []AudioClick
+	for f := 0; f < frames; f++ {
+		if err := emu.RunFrame(); err != nil {
+			return clicks, fmt.Errorf("frame %d: %w", f, err)
+		}
+		clicks = append(clicks, d.Feed(emu.AudioSampleBuffer, emu.AudioNoteSamples)...)
+	}
+	return clicks, nil
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,95 @@
+package harness
+
+import (
+	"math"
+	"testing"
+
+	"nitro-core-dx/internal/emulator"
+)
+
+// sineFrames returns frames of a 441 Hz sine at amplitude amp; when
+// resetAt >= 0 the phase jumps by half a cycle at that sample of frame 1.
+func sineFrames(amp float64, resetAt int) [][]int16 {
+	frames := make([][]int16, 3)
+	phase := 0.0
+	for f := range frames {
+		frames[f] = make([]int16, 735)
+		for i := range frames[f] {
+			if f == 1 && i == resetAt {
+				phase += math.Pi
+			}
+			frames[f][i] = int16(amp * math.Sin(phase))
+			phase += 2 * math.Pi * 441 / 44100
+		}
+	}
+	return frames
+}
+
+func TestClickDetectorFlagsPhaseReset(t *testing.T) {
+	clean := NewClickDetector(DefaultClickOptions())
+	for _, fr := range sineFrames(20000, -1) {
+		if c := clean.Feed(fr, nil); len(c) != 0 {
+			t.Fatalf("clean sine flagged: %v", c)
+		}
+	}
+
+	d := NewClickDetector(DefaultClickOptions())
+	var clicks []AudioClick
+	for _, fr := range sineFrames(20000, 300) {
+		clicks = append(clicks, d.Feed(fr, nil)...)
+	}
+	if len(clicks) != 1 || clicks[0].Frame != 1 || clicks[0].Sample != 300 {
+		t.Fatalf("clicks = %v, want one at frame 1 sample 300", clicks)
+	}
+
+	// The same jump right after a note event is intended.
+	d = NewClickDetector(DefaultClickOptions())
+	for f, fr := range sineFrames(20000, 300) {
+		var notes []int
+		if f == 1 {
+			notes = []int{298}
+		}
+		if c := d.Feed(fr, notes); len(c) != 0 {
+			t.Errorf("jump after note event flagged: %v", c)
+		}
+	}
+}
+
+func TestClickDetectorIgnoresSquareWave(t *testing.T) {
+	d := NewClickDetector(DefaultClickOptions())
+	samples := make([]int16, 735)
+	level := int16(12000)
+	for f := 0; f < 4; f++ {
+		for i := range samples {
+			if (f*len(samples)+i)%50 == 0 {
+				level = -level
+			}
+			samples[i] = level
+		}
+		// The note starts on the first sample; its first edge has no
+		// earlier edges to compare against.
+		var notes []int
+		if f == 0 {
+			notes = []int{0}
+		}
+		if clicks := d.Feed(samples, notes); len(clicks) != 0 {
+			t.Fatalf("frame %d: square wave flagged: %v", f, clicks)
+		}
+	}
+}
+
+func TestCheckAudioSilentROM(t *testing.T) {
+	emu := emulator.NewEmulator()
+	if emu.Logger != nil {
+		defer emu.Logger.Shutdown()
+	}
+	if err := emu.LoadROM(counterROM(t)); err != nil {
+		t.Fatal(err)
+	}
+	emu.SetFrameLimit(false)
+	emu.Start()
+	clicks, err := CheckAudio(emu, 30, DefaultClickOptions())
+	if err != nil || len(clicks) != 0 {
+		t.Fatalf("clicks = %v, err = %v", clicks, err)
+	}
+}
@@ -0,0 +1,232 @@
+package harness
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"nitro-core-dx/internal/emulator"
+)
+
+// FrameHashFileName is the CoreLX project file declaring expected frame
+// hashes. It lives next to main.corelx, like corelx.assets.json and
+// corelx.run.json:
+//
+//	{
+//	  "format_version": 1,
+//	  "input": [{"frame": 30, "buttons": 16}, {"frame": 40, "buttons": 0}],
+//	  "expect": [{"frame": 0, "hash": "1c9d02a7"}, {"frame": 59, "hash": "8e01f3b2"}]
+//	}
+//
+// Frames are numbered from 0, the first frame after power-on; a hash is
+// checked once its frame has run. Each input entry is held from its frame
+// until the next one (no buttons before the first). The hash is
+// emulator.HashFrame of the presented frame.
+const FrameHashFileName = "corelx.hashes.json"
+
+// FrameHashManifest is the contents of a project's FrameHashFileName.
+type FrameHashManifest struct {
+	FormatVersion int               `json:"format_version,omitempty"`
+	Input         []FrameHashInput  `json:"input,omitempty"`
+	Expect        []FrameHashExpect `json:"expect"`
+}
+
+// FrameHashInput sets the controller from Frame on.
+type FrameHashInput struct {
+	Frame   int    `json:"frame"`
+	Buttons uint16 `json:"buttons"`
+}
+
+// FrameHashExpect is the hash Frame must produce, as emulator.FormatFrameHash
+// spells it.
+type FrameHashExpect struct {
+	Frame int    `json:"frame"`
+	Hash  string `json:"hash"`
+}
+
+// Validate reports the first problem with m.
+func (m *FrameHashManifest) Validate() error {
+	if len(m.Expect) == 0 {
+		return fmt.Errorf("no expected hashes")
+	}
+	for _, in := range m.Input {
+		if in.Frame < 0 {
+			return fmt.Errorf("input at frame %d: frame must be >= 0", in.Frame)
+		}
+	}
+	for _, ex := range m.Expect {
+		if ex.Frame < 0 {
+			return fmt.Errorf("hash at frame %d: frame must be >= 0", ex.Frame)
+		}
+	}
+	return nil
+}
+
+// frames is how many frames the manifest needs run: through the last
+// expected hash.
+func (m *FrameHashManifest) frames() int {
+	n := 0
+	for _, ex := range m.Expect {
+		n = max(n, ex.Frame+1)
+	}
+	return n
+}
+
+// inputAt returns the buttons held on frame.
+func (m *FrameHashManifest) inputAt(frame int) uint16 {
+	var buttons uint16
+	best := -1
+	for _, in := range m.Input {
+		if in.Frame <= frame && in.Frame >= best {
+			buttons, best = in.Buttons, in.Frame
+		}
+	}
+	return buttons
+}
+
+// FrameHashManifestPath is where the project at path (a project directory,
+// a .corelx file or a .ncdx container) keeps its FrameHashFileName. For a
+// .ncdx it is the container itself.
+func FrameHashManifestPath(path string) string {
+	if strings.EqualFold(filepath.Ext(path), ".ncdx") {
+		return path
+	}
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return filepath.Join(path, FrameHashFileName)
+	}
+	return filepath.Join(filepath.Dir(path), FrameHashFileName)
+}
+
+// LoadFrameHashManifest reads the expected frame hashes of the project at
+// path (see FrameHashManifestPath). A project without the file gets an
+// error wrapping os.ErrNotExist.
+func LoadFrameHashManifest(path string) (*FrameHashManifest, error) {
+	var data []byte
+	var err error
+	if strings.EqualFold(filepath.Ext(path), ".ncdx") {
+		data, err = readZipEntry(path, FrameHashFileName)
+	} else {
+		data, err = os.ReadFile(FrameHashManifestPath(path))
+	}
+	if err != nil {
+		return nil, err
+	}
+	var m FrameHashManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("%s: %w", FrameHashFileName, err)
+	}
+	if err := m.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", FrameHashFileName, err)
+	}
+	return &m, nil
+}
+
+// SaveFrameHashManifest writes m as the project's FrameHashFileName. A
+// .ncdx container is not rewritten.
+func SaveFrameHashManifest(path string, m *FrameHashManifest) error {
+	if strings.EqualFold(filepath.Ext(path), ".ncdx") {
+		return fmt.Errorf("%s: cannot update hashes inside a .ncdx container", filepath.Base(path))
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(FrameHashManifestPath(path), append(data, '\n'), 0644)
+}
+
+func readZipEntry(archivePath, name string) ([]byte, error) {
+	r, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	for _, f := range r.File {
+		if f.Name != name {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		return io.ReadAll(rc)
+	}
+	return nil, fmt.Errorf("%s: no %s: %w", filepath.Base(archivePath), name, os.ErrNotExist)
+}
+
+// FrameHashes runs rom on a fresh deterministic emulator with m's input
+// and returns the hash of every frame m expects one for, in m.Expect
+// order.
+func FrameHashes(rom []byte, m *FrameHashManifest) ([]string, error) {
+	emu := emulator.NewEmulator()
+	if emu.Logger != nil {
+		defer emu.Logger.Shutdown()
+	}
+	if err := emu.LoadROM(rom); err != nil {
+		return nil, fmt.Errorf("load ROM: %w", err)
+	}
+	emu.SetFrameLimit(false)
+	emu.SetDeterministic(true)
+	emu.Start()
+
+	want := make(map[int]bool, len(m.Expect))
+	for _, ex := range m.Expect {
+		want[ex.Frame] = true
+	}
+	got := make(map[int]string, len(want))
+	for frame := 0; frame < m.frames(); frame++ {
+		emu.SetInputButtons(m.inputAt(frame))
+		if err := emu.RunFrameGuarded(nil); err != nil {
+			var f *emulator.Fault
+			if errors.As(err, &f) {
+				return nil, errors.New(f.Report())
+			}
+			return nil, err
+		}
+		if want[frame] {
+			got[frame] = emulator.FormatFrameHash(emu.FrameHash())
+		}
+	}
+	hashes := make([]string, len(m.Expect))
+	for i, ex := range m.Expect {
+		hashes[i] = got[ex.Frame]
+	}
+	return hashes, nil
+}
+
+// RunFrameHashTest runs rom, built from the project at path, against the
+// project's expected frame hashes. It passes when every hash matches and
+// fails listing each frame that does not.
+func RunFrameHashTest(path string, rom []byte, m *FrameHashManifest) (res ROMTestResult) {
+	start := time.Now()
+	res = ROMTestResult{
+		Name: strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+		Path: path,
+	}
+	defer func() { res.Duration = time.Since(start) }()
+
+	hashes, err := FrameHashes(rom, m)
+	if err != nil {
+		res.Outcome, res.Message = OutcomeError, err.Error()
+		return res
+	}
+	res.Frames = m.frames()
+	var mismatches []string
+	for i, ex := range m.Expect {
+		if want := strings.ToLower(ex.Hash); hashes[i] != want {
+			mismatches = append(mismatches, fmt.Sprintf("frame %d: hash %s, want %s", ex.Frame, hashes[i], want))
+		}
+	}
+	res.Outcome = OutcomePass
+	if len(mismatches) > 0 {
+		res.Outcome = OutcomeFail
+		res.Message = fmt.Sprintf("%d of %d frame hashes differ\n%s", len(mismatches), len(m.Expect), strings.Join(mismatches, "\n"))
+	}
+	return res
+}
@@ -0,0 +1,76 @@
+package harness
+
+import (
+	"archive/zip"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFrameHashManifestInputAt(t *testing.T) {
+	m := &FrameHashManifest{Input: []FrameHashInput{{Frame: 10, Buttons: 1}, {Frame: 5, Buttons: 2}, {Frame: 20, Buttons: 0}}}
+	for frame, want := range map[int]uint16{0: 0, 5: 2, 9: 2, 10: 1, 19: 1, 20: 0, 99: 0} {
+		if got := m.inputAt(frame); got != want {
+			t.Errorf("inputAt(%d) = %d, want %d", frame, got, want)
+		}
+	}
+}
+
+func TestRunFrameHashTestComparesHashes(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := LoadFrameHashManifest(dir); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("project without %s: %v", FrameHashFileName, err)
+	}
+
+	rom := counterROM(t)
+	m := &FrameHashManifest{FormatVersion: 1, Expect: []FrameHashExpect{{Frame: 0}, {Frame: 9}}}
+	hashes, err := FrameHashes(rom, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range m.Expect {
+		if len(hashes[i]) != 8 {
+			t.Fatalf("hash %q is not 8 hex digits", hashes[i])
+		}
+		m.Expect[i].Hash = strings.ToUpper(hashes[i]) // case does not matter
+	}
+	if err := SaveFrameHashManifest(dir, m); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadFrameHashManifest(filepath.Join(dir, "main.corelx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res := RunFrameHashTest(dir, rom, loaded); !res.Passed() || res.Frames != 10 {
+		t.Fatalf("matching hashes: %s after %d frames: %s", res.Outcome, res.Frames, res.Message)
+	}
+
+	loaded.Expect[1].Hash = "00000000"
+	res := RunFrameHashTest(dir, rom, loaded)
+	if res.Outcome != OutcomeFail || !strings.Contains(res.Message, "frame 9: hash "+hashes[1]+", want 00000000") || strings.Contains(res.Message, "frame 0:") {
+		t.Fatalf("one wrong hash: %s: %s", res.Outcome, res.Message)
+	}
+}
+
+func TestLoadFrameHashManifestFromNCDX(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "game.ncdx")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	w, _ := zw.Create(FrameHashFileName)
+	w.Write([]byte(`{"expect": [{"frame": 3, "hash": "0123abcd"}]}`))
+	zw.Close()
+	f.Close()
+
+	m, err := LoadFrameHashManifest(path)
+	if err != nil || len(m.Expect) != 1 || m.Expect[0].Hash != "0123abcd" {
+		t.Fatalf("got %+v, %v", m, err)
+	}
+	if err := SaveFrameHashManifest(path, m); err == nil {
+		t.Error("SaveFrameHashManifest rewrote a .ncdx")
+	}
+}
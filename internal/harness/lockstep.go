@@ -0,0 +1,190 @@
+package harness
+
+import (
+	"fmt"
+	"reflect"
+
+	"nitro-core-dx/internal/emulator"
+)
+
+// Divergence is the first point where two lockstep emulators disagreed.
+type Divergence struct {
+	Frame int // 0-based frame that produced the difference
+	// Component is the first differing part of the machine in comparison
+	// order: cpu, memory, ppu, apu, input, framebuffer, audio (or error when
+	// only one side failed to run the frame).
+	Component string
+	// Detail names the first differing field and both values, e.g.
+	// "MemoryState.WRAM[0x0200]: 0x01 != 0x02".
+	Detail string
+	// Components lists every component that differed on Frame.
+	Components []string
+}
+
+func (d *Divergence) String() string {
+	return fmt.Sprintf("frame %d: %s: %s", d.Frame, d.Component, d.Detail)
+}
+
+// Lockstep runs two emulators from the same ROM with the same input and
+// compares their full machine state after every frame. Any difference means
+// emulation depends on something other than ROM and input (host timing, map
+// iteration order, shared globals, uninitialized host memory), which would
+// break replays and netplay.
+type Lockstep struct {
+	A, B  *emulator.Emulator
+	Frame int // frames run so far
+}
+
+// NewLockstep loads rom into two fresh emulators in deterministic mode.
+func NewLockstep(rom []byte) (*Lockstep, error) {
+	l := &Lockstep{}
+	for _, emu := range []**emulator.Emulator{&l.A, &l.B} {
+		e := emulator.NewEmulator()
+		if err := e.LoadROM(rom); err != nil {
+			l.Close()
+			return nil, fmt.Errorf("load ROM: %w", err)
+		}
+		e.SetFrameLimit(false)
+		e.SetDeterministic(true)
+		e.Start()
+		*emu = e
+	}
+	return l, nil
+}
+
+// Close releases both emulators' loggers.
+func (l *Lockstep) Close() {
+	for _, e := range []*emulator.Emulator{l.A, l.B} {
+		if e != nil && e.Logger != nil {
+			e.Logger.Shutdown()
+		}
+	}
+}
+
+// Step runs one frame on both emulators with input and compares them. It
+// returns nil when they still agree.
+func (l *Lockstep) Step(input uint16) (*Divergence, error) {
+	frame := l.Frame
+	l.Frame++
+	l.A.SetInputButtons(input)
+	l.B.SetInputButtons(input)
+	errA, errB := l.A.RunFrame(), l.B.RunFrame()
+	if errA != nil || errB != nil {
+		if fmt.Sprint(errA) == fmt.Sprint(errB) {
+			return nil, fmt.Errorf("frame %d: %w", frame, errA)
+		}
+		return &Divergence{
+			Frame:      frame,
+			Component:  "error",
+			Detail:     fmt.Sprintf("%v != %v", errA, errB),
+			Components: []string{"error"},
+		}, nil
+	}
+	return compareMachines(frame, l.A, l.B), nil
+}
+
+// Run steps frames frames, applying inputs[i] on frame i (0 once inputs run
+// out), and stops at the first divergence.
+func (l *Lockstep) Run(inputs []uint16, frames int) (*Divergence, error) {
+	for i := 0; i < frames; i++ {
+		var input uint16
+		if i < len(inputs) {
+			input = inputs[i]
+		}
+		d, err := l.Step(input)
+		if d != nil || err != nil {
+			return d, err
+		}
+	}
+	return nil, nil
+}
+
+// compareMachines compares the save-state view of a and b plus their frame
+// outputs.
+func compareMachines(frame int, a, b *emulator.Emulator) *Divergence {
+	sa, sb := a.CaptureState(), b.CaptureState()
+	components := []struct {
+		name string
+		a, b any
+	}{
+		{"cpu", sa.CPUState, sb.CPUState},
+		{"memory", sa.MemoryState, sb.MemoryState},
+		{"ppu", sa.PPUState, sb.PPUState},
+		{"apu", sa.APUState, sb.APUState},
+		{"input", sa.InputState, sb.InputState},
+		{"framebuffer", a.GetOutputBuffer(), b.GetOutputBuffer()},
+		{"audio", a.AudioSampleBuffer, b.AudioSampleBuffer},
+	}
+	var d *Divergence
+	for _, c := range components {
+		va, vb := reflect.ValueOf(c.a), reflect.ValueOf(c.b)
+		root := va.Type().Name()
+		if root == "" {
+			root = c.name
+		}
+		detail, differs := firstDiff(root, va, vb)
+		if !differs {
+			continue
+		}
+		if d == nil {
+			d = &Divergence{Frame: frame, Component: c.name, Detail: detail}
+		}
+		d.Components = append(d.Components, c.name)
+	}
+	return d
+}
+
+// firstDiff walks a and b (same type) and describes the first exported
+// field or element that differs.
+func firstDiff(path string, a, b reflect.Value) (string, bool) {
+	if equalValues(a, b) {
+		return "", false
+	}
+	switch a.Kind() {
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			f := a.Type().Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			if s, ok := firstDiff(path+"."+f.Name, a.Field(i), b.Field(i)); ok {
+				return s, true
+			}
+		}
+		return "", false
+	case reflect.Array, reflect.Slice:
+		if a.Len() != b.Len() {
+			return fmt.Sprintf("%s: length %d != %d", path, a.Len(), b.Len()), true
+		}
+		for i := 0; i < a.Len(); i++ {
+			if s, ok := firstDiff(fmt.Sprintf("%s[0x%04X]", path, i), a.Index(i), b.Index(i)); ok {
+				return s, true
+			}
+		}
+		return "", false
+	case reflect.Pointer, reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() != b.IsNil() {
+				return fmt.Sprintf("%s: nil mismatch", path), true
+			}
+			return "", false
+		}
+		return firstDiff(path, a.Elem(), b.Elem())
+	case reflect.Map, reflect.Func, reflect.Chan:
+		return "", false
+	}
+	switch a.Kind() {
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return fmt.Sprintf("%s: 0x%X != 0x%X", path, a.Uint(), b.Uint()), true
+	}
+	return fmt.Sprintf("%s: %v != %v", path, a.Interface(), b.Interface()), true
+}
+
+// equalValues compares with == where the type allows it (a memory compare
+// for the large RAM arrays) and falls back to reflect.DeepEqual.
+func equalValues(a, b reflect.Value) bool {
+	if a.Kind() != reflect.Interface && a.Type().Comparable() {
+		return a.Interface() == b.Interface()
+	}
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
@@ -0,0 +1,73 @@
+package harness
+
+import (
+	"strings"
+	"testing"
+
+	"nitro-core-dx/internal/rom"
+)
+
+// counterROM increments WRAM 0x0200 in a tight loop.
+func counterROM(t *testing.T) []byte {
+	t.Helper()
+	b := rom.NewROMBuilder()
+	b.AddInstruction(rom.EncodeMOV(1, 2, 0)) // MOV R2, #0x0200
+	b.AddImmediate(0x0200)
+	b.AddInstruction(rom.EncodeMOV(1, 1, 0)) // MOV R1, #0
+	b.AddImmediate(0)
+	b.AddInstruction(rom.EncodeADD(1, 1, 0)) // loop: ADD R1, #1
+	b.AddImmediate(1)
+	b.AddInstruction(rom.EncodeMOV(3, 2, 1)) // MOV [R2], R1
+	b.AddInstruction(rom.EncodeJMP())
+	b.AddImmediate(uint16(rom.CalculateBranchOffset(0x8010, 0x8008)))
+	data, err := b.BuildROMBytes(1, 0x8000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestLockstepAgreesOnIdenticalRuns(t *testing.T) {
+	l, err := NewLockstep(counterROM(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	d, err := l.Run([]uint16{0x10, 0, 0x01}, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d != nil {
+		t.Fatalf("identical runs diverged: %v", d)
+	}
+	if l.Frame != 5 {
+		t.Errorf("Frame = %d, want 5", l.Frame)
+	}
+}
+
+func TestLockstepReportsFirstDivergentComponent(t *testing.T) {
+	l, err := NewLockstep(counterROM(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	if d, err := l.Run(nil, 2); d != nil || err != nil {
+		t.Fatalf("diverged early: %v, %v", d, err)
+	}
+
+	// Something outside the ROM changes one machine's RAM.
+	l.B.Bus.WRAM[0x1000] ^= 0xFF
+	d, err := l.Step(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d == nil {
+		t.Fatal("expected a divergence")
+	}
+	if d.Frame != 2 || d.Component != "memory" {
+		t.Errorf("divergence = %v, want frame 2 memory", d)
+	}
+	if !strings.Contains(d.Detail, "WRAM[0x1000]") {
+		t.Errorf("detail %q does not name WRAM[0x1000]", d.Detail)
+	}
+}
@@ -0,0 +1,190 @@
+package harness
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/rand"
+
+	"nitro-core-dx/internal/emulator"
+)
+
+// Outcomes of a monkey run.
+const (
+	MonkeyOK     = "ok"
+	MonkeyFault  = "fault"  // the emulator returned an error or panicked
+	MonkeyHang   = "hang"   // the CPU sat in one short loop with work RAM unchanged
+	MonkeyFrozen = "frozen" // the framebuffer stopped changing
+)
+
+// MonkeyAllButtons is every controller button (bits 0-11).
+const MonkeyAllButtons = 0x0FFF
+
+// monkeyLoopSpan is the widest address range, in bytes, the CPU's recent
+// instructions may cover and still count as one short loop (a VBlank wait,
+// a spin on a flag) for hang detection.
+const monkeyLoopSpan = 0x40
+
+// MonkeyOptions configure RunMonkey.
+type MonkeyOptions struct {
+	Seed   int64
+	Frames int
+	// Buttons are the buttons random input may press, e.g. without Start to
+	// keep a game from pausing itself.
+	Buttons uint16
+	// MaxHold is the most frames one random input is held; each is held for
+	// 1..MaxHold frames, so both taps and held directions get exercised.
+	MaxHold int
+	// HangFrames and FreezeFrames are how long a hang or a frozen
+	// framebuffer must last before the run stops on it; 0 turns the check
+	// off.
+	HangFrames   int
+	FreezeFrames int
+}
+
+// DefaultMonkeyOptions are 3600 frames (a minute) of all buttons held for
+// up to 8 frames each, stopping on a 5 second hang or a 10 second freeze.
+func DefaultMonkeyOptions(seed int64) MonkeyOptions {
+	return MonkeyOptions{
+		Seed:         seed,
+		Frames:       3600,
+		Buttons:      MonkeyAllButtons,
+		MaxHold:      8,
+		HangFrames:   300,
+		FreezeFrames: 600,
+	}
+}
+
+// MonkeyResult is the outcome of one monkey run.
+type MonkeyResult struct {
+	Seed    int64
+	Outcome string
+	// Frames is the number of frames run, including the one the problem
+	// was found on.
+	Frames  int
+	Message string
+	// Input is the controller word fed on each frame. The same seed and
+	// options feed the same input, so rerunning with the seed reproduces
+	// the run.
+	Input []uint16
+}
+
+// Failed reports whether the run found a problem.
+func (r MonkeyResult) Failed() bool { return r.Outcome != MonkeyOK }
+
+func (r MonkeyResult) String() string {
+	if !r.Failed() {
+		return fmt.Sprintf("seed %d: ok after %d frames", r.Seed, r.Frames)
+	}
+	return fmt.Sprintf("seed %d: %s at frame %d: %s", r.Seed, r.Outcome, r.Frames-1, r.Message)
+}
+
+// RunMonkey loads rom into a fresh deterministic emulator and feeds it
+// pseudo-random input from opts.Seed for opts.Frames frames, stopping at the
+// first fault, hang or frozen framebuffer. It is a cheap smoke test: a
+// failure comes with the seed that reproduces it. The error is for a ROM
+// that cannot be loaded.
+func RunMonkey(rom []byte, opts MonkeyOptions) (MonkeyResult, error) {
+	emu := emulator.NewEmulator()
+	if emu.Logger != nil {
+		defer emu.Logger.Shutdown()
+	}
+	if err := emu.LoadROM(rom); err != nil {
+		return MonkeyResult{}, fmt.Errorf("load ROM: %w", err)
+	}
+	emu.SetFrameLimit(false)
+	emu.SetDeterministic(true)
+	emu.Start()
+
+	res := MonkeyResult{Seed: opts.Seed, Outcome: MonkeyOK}
+	rng := rand.New(rand.NewSource(opts.Seed))
+	maxHold := max(opts.MaxHold, 1)
+	var (
+		input uint16
+		hold  int
+
+		loopLo, loopHi uint32
+		wram           []byte
+		hangFor        int
+
+		fb        []uint32
+		frozenFor int
+	)
+	for res.Frames < opts.Frames {
+		if hold == 0 {
+			input = uint16(rng.Intn(1<<16)) & opts.Buttons
+			hold = 1 + rng.Intn(maxHold)
+		}
+		hold--
+		res.Input = append(res.Input, input)
+		res.Frames++
+		emu.SetInputButtons(input)
+		if err := emu.RunFrameGuarded(nil); err != nil {
+			res.Outcome, res.Message = MonkeyFault, err.Error()
+			var f *emulator.Fault
+			if errors.As(err, &f) {
+				res.Message = f.Report()
+			}
+			return res, nil
+		}
+
+		if opts.HangFrames > 0 {
+			lo, hi, tight := recentLoop(emu)
+			if tight && lo == loopLo && hi == loopHi && bytes.Equal(wram, emu.Bus.WRAM[:]) {
+				hangFor++
+			} else {
+				hangFor = 0
+			}
+			loopLo, loopHi = lo, hi
+			wram = append(wram[:0], emu.Bus.WRAM[:]...)
+			if hangFor >= opts.HangFrames {
+				res.Outcome = MonkeyHang
+				res.Message = fmt.Sprintf("CPU looping in %02X:%04X..%04X with work RAM unchanged for %d frames", lo>>16, uint16(lo), uint16(hi), hangFor)
+				return res, nil
+			}
+		}
+
+		if opts.FreezeFrames > 0 {
+			out := emu.GetOutputBuffer()
+			if fb != nil && equalPixels(fb, out) {
+				frozenFor++
+			} else {
+				frozenFor = 0
+			}
+			fb = append(fb[:0], out...)
+			if frozenFor >= opts.FreezeFrames {
+				res.Outcome = MonkeyFrozen
+				res.Message = fmt.Sprintf("framebuffer unchanged for %d frames", frozenFor)
+				return res, nil
+			}
+		}
+	}
+	return res, nil
+}
+
+// recentLoop returns the lowest and highest of the CPU's recent instruction
+// addresses and whether they make one short loop: one bank, at most
+// monkeyLoopSpan bytes apart.
+func recentLoop(emu *emulator.Emulator) (lo, hi uint32, tight bool) {
+	pcs := emu.CPU.RecentPCs()
+	if len(pcs) == 0 {
+		return 0, 0, false
+	}
+	lo, hi = pcs[0], pcs[0]
+	for _, pc := range pcs[1:] {
+		lo, hi = min(lo, pc), max(hi, pc)
+	}
+	return lo, hi, lo>>16 == hi>>16 && hi-lo <= monkeyLoopSpan
+}
+
+func equalPixels(a, b []uint32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,71 @@
+package harness
+
+import (
+	"slices"
+	"testing"
+
+	"nitro-core-dx/internal/rom"
+)
+
+func TestRunMonkeyFindsProblems(t *testing.T) {
+	faulty := rom.NewROMBuilder()
+	faulty.AddInstruction(rom.EncodeADD(9, 0, 0)) // unassigned ADD mode
+	faultyROM, err := faulty.BuildROMBytes(1, 0x8000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := DefaultMonkeyOptions(7)
+	opts.Frames, opts.HangFrames, opts.FreezeFrames = 200, 30, 60
+	for _, tc := range []struct {
+		name    string
+		rom     []byte
+		hang    int
+		outcome string
+		frames  int
+	}{
+		{"fault", faultyROM, 30, MonkeyFault, 1},
+		{"spin", storeROM(t), 30, MonkeyHang, 31},
+		// The counter keeps writing work RAM, so it never hangs, but it
+		// draws nothing.
+		{"counter", counterROM(t), 30, MonkeyFrozen, 61},
+		{"counter without checks", counterROM(t), 0, MonkeyOK, 200},
+	} {
+		o := opts
+		o.HangFrames = tc.hang
+		if tc.outcome == MonkeyOK {
+			o.FreezeFrames = 0
+		}
+		res, err := RunMonkey(tc.rom, o)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if res.Outcome != tc.outcome || res.Frames != tc.frames || len(res.Input) != res.Frames {
+			t.Errorf("%s: %s after %d frames (%d inputs), want %s after %d", tc.name, res.Outcome, res.Frames, len(res.Input), tc.outcome, tc.frames)
+		}
+	}
+}
+
+func TestRunMonkeyInputFollowsSeed(t *testing.T) {
+	opts := DefaultMonkeyOptions(42)
+	opts.Frames, opts.Buttons, opts.HangFrames, opts.FreezeFrames = 120, 0x00F0, 0, 0
+	a, err := RunMonkey(counterROM(t), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := RunMonkey(counterROM(t), opts)
+	if !slices.Equal(a.Input, b.Input) {
+		t.Fatal("same seed fed different input")
+	}
+	pressed := uint16(0)
+	for _, in := range a.Input {
+		pressed |= in
+	}
+	if pressed&^opts.Buttons != 0 || pressed == 0 {
+		t.Errorf("input pressed 0x%04X, want buttons within 0x%04X", pressed, opts.Buttons)
+	}
+	opts.Seed++
+	if c, _ := RunMonkey(counterROM(t), opts); slices.Equal(a.Input, c.Input) {
+		t.Error("different seeds fed the same input")
+	}
+}
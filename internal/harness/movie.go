@@ -0,0 +1,418 @@
+package harness
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"nitro-core-dx/internal/emulator"
+	"nitro-core-dx/internal/ppu"
+)
+
+// A movie (.ncm) is a shareable, reproducible run: where it starts (power-on
+// or a savestate), the controller input for every frame from there, the ROM
+// it was made with and who made it. Playing it back on the same ROM
+// reproduces the run exactly, which makes it a bug report or a speedrun
+// anyone can verify. Checkpoints (framebuffer and machine-state hashes
+// every MovieCheckpointInterval frames and at the end) let playback notice
+// the first frame where it no longer matches.
+//
+// File layout, little-endian:
+//
+//	"NCMV"            magic
+//	uint16            format version (MovieVersion; version 1 still reads)
+//	uint32 + bytes    header, JSON (MovieHeader)
+//	uint32 + bytes    start savestate (Emulator.SaveState); empty for power-on
+//	uint32 + n*uint16 controller 1 buttons, one per frame
+const (
+	MovieExt                = ".ncm"
+	MovieVersion            = 2
+	MovieCheckpointInterval = 60
+
+	movieMagic = "NCMV"
+	// movieMaxSection bounds each section when reading, so a corrupt
+	// length cannot allocate gigabytes.
+	movieMaxSection = 64 << 20
+)
+
+// MovieHeader is a movie's metadata.
+type MovieHeader struct {
+	// ROMHash is the SHA-256 of the ROM file (hex), as MovieROMHash.
+	ROMHash string    `json:"rom_hash"`
+	Author  string    `json:"author,omitempty"`
+	Comment string    `json:"comment,omitempty"`
+	Created time.Time `json:"created"`
+	// PowerOn movies start from a power cycle; the others from Movie.State.
+	PowerOn     bool              `json:"power_on"`
+	Checkpoints []MovieCheckpoint `json:"checkpoints,omitempty"`
+}
+
+// MovieCheckpoint is what the machine looked like after frame Frame
+// (0-based) of the movie.
+type MovieCheckpoint struct {
+	Frame           int    `json:"frame"`
+	FramebufferHash string `json:"fb_hash"`
+	StateHash       string `json:"state_hash"`
+}
+
+// Movie is a decoded .ncm file.
+type Movie struct {
+	Header MovieHeader
+	State  []byte   // start savestate; nil when Header.PowerOn
+	Input  []uint16 // buttons applied before each frame
+}
+
+// MovieROMHash returns the ROM hash movies are keyed by.
+func MovieROMHash(rom []byte) string {
+	h := sha256.Sum256(rom)
+	return hex.EncodeToString(h[:])
+}
+
+// stateHash hashes the machine state: the memories, then a fixed list of
+// CPU, PPU, APU and input registers, in a fixed order and little-endian.
+// It is written out by hand, not through a savestate, so that adding a
+// field to SaveState does not change the hash of every recorded movie.
+// Changing what is hashed here breaks existing checkpoints: raise
+// MovieVersion and drop older movies' state hashes in ReadMovie.
+func stateHash(emu *emulator.Emulator) string {
+	st := emu.CaptureState()
+	h := sha256.New()
+	put := func(vs ...any) {
+		for _, v := range vs {
+			binary.Write(h, binary.LittleEndian, v)
+		}
+	}
+
+	h.Write(st.MemoryState.WRAM[:])
+	h.Write(st.MemoryState.WRAMExtended[:])
+	h.Write(st.PPUState.VRAM[:])
+	h.Write(st.PPUState.CGRAM[:])
+	h.Write(st.PPUState.OAM[:])
+
+	c := st.CPUState
+	put(c.R0, c.R1, c.R2, c.R3, c.R4, c.R5, c.R6, c.R7)
+	put(c.PCBank, c.PCOffset, c.PBR, c.DBR, c.SP, c.Flags, c.Cycles, c.InterruptMask, c.InterruptPending)
+
+	p := st.PPUState
+	for _, bg := range []ppu.BackgroundLayer{p.BG0, p.BG1, p.BG2, p.BG3} {
+		put(bg.ScrollX, bg.ScrollY, bg.Enabled, bg.Priority, bg.TileSize, bg.TilemapSize,
+			bg.SourceMode, bg.TilemapBase, bg.TransformChannel, bg.MosaicEnabled, bg.MosaicSize)
+	}
+	put(p.FrameCounter, p.VBlankFlag, p.VRAMAddr, p.CGRAMAddr, p.OAMAddr, p.OAMByteIndex)
+	put(p.HDMAEnabled, p.HDMATableBase, p.DMAEnabled, p.DMAProgress, p.DMALength)
+
+	a := st.APUState
+	for _, ch := range a.Channels {
+		put(ch.Frequency, ch.Volume, ch.Enabled, ch.Waveform, ch.Duration, ch.DurationMode,
+			ch.PhaseFixed, ch.PhaseIncrementFixed, ch.NoiseLFSR)
+	}
+	put(a.MasterVolume, a.ChannelCompletionStatus)
+
+	in := st.InputState
+	put(in.Controller1Latched, in.Controller2Latched, in.Controller1LatchState, in.Controller2LatchState)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func captureCheckpoint(emu *emulator.Emulator, frame int) MovieCheckpoint {
+	return MovieCheckpoint{
+		Frame:           frame,
+		FramebufferHash: fbHash(emu.GetOutputBuffer()),
+		StateHash:       stateHash(emu),
+	}
+}
+
+// WriteMovie encodes m in the .ncm format.
+func WriteMovie(w io.Writer, m *Movie) error {
+	if m.Header.PowerOn != (len(m.State) == 0) {
+		return fmt.Errorf("movie: a movie starts from power-on or a savestate, not both")
+	}
+	header, err := json.Marshal(m.Header)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	buf.WriteString(movieMagic)
+	binary.Write(&buf, binary.LittleEndian, uint16(MovieVersion))
+	binary.Write(&buf, binary.LittleEndian, uint32(len(header)))
+	buf.Write(header)
+	binary.Write(&buf, binary.LittleEndian, uint32(len(m.State)))
+	buf.Write(m.State)
+	binary.Write(&buf, binary.LittleEndian, uint32(len(m.Input)))
+	binary.Write(&buf, binary.LittleEndian, m.Input)
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// ReadMovie decodes a .ncm movie.
+func ReadMovie(r io.Reader) (*Movie, error) {
+	var magic [4]byte
+	if _, err := io.ReadFull(r, magic[:]); err != nil || string(magic[:]) != movieMagic {
+		return nil, fmt.Errorf("movie: not a %s file", MovieExt)
+	}
+	var version uint16
+	if err := binary.Read(r, binary.LittleEndian, &version); err != nil {
+		return nil, fmt.Errorf("movie: %w", err)
+	}
+	if version < 1 || version > MovieVersion {
+		return nil, fmt.Errorf("movie: format version %d not supported (want 1 to %d)", version, MovieVersion)
+	}
+	header, err := readMovieSection(r, "header")
+	if err != nil {
+		return nil, err
+	}
+	m := &Movie{}
+	if err := json.Unmarshal(header, &m.Header); err != nil {
+		return nil, fmt.Errorf("movie: header: %w", err)
+	}
+	if version == 1 {
+		// Version 1 hashed the gob encoding of a savestate, which changes
+		// whenever SaveState gains a field; only its framebuffer hashes can
+		// still be checked.
+		for i := range m.Header.Checkpoints {
+			m.Header.Checkpoints[i].StateHash = ""
+		}
+	}
+	if m.State, err = readMovieSection(r, "start state"); err != nil {
+		return nil, err
+	}
+	if len(m.State) == 0 {
+		m.State = nil
+	}
+	if m.Header.PowerOn != (m.State == nil) {
+		return nil, fmt.Errorf("movie: header and start state disagree about power-on")
+	}
+	var frames uint32
+	if err := binary.Read(r, binary.LittleEndian, &frames); err != nil {
+		return nil, fmt.Errorf("movie: input: %w", err)
+	}
+	if frames > movieMaxSection/2 {
+		return nil, fmt.Errorf("movie: input: %d frames is too long", frames)
+	}
+	m.Input = make([]uint16, frames)
+	if err := binary.Read(r, binary.LittleEndian, m.Input); err != nil {
+		return nil, fmt.Errorf("movie: input: %w", err)
+	}
+	return m, nil
+}
+
+func readMovieSection(r io.Reader, name string) ([]byte, error) {
+	var n uint32
+	if err := binary.Read(r, binary.LittleEndian, &n); err != nil {
+		return nil, fmt.Errorf("movie: %s: %w", name, err)
+	}
+	if n > movieMaxSection {
+		return nil, fmt.Errorf("movie: %s: %d bytes is too large", name, n)
+	}
+	data := make([]byte, n)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, fmt.Errorf("movie: %s: %w", name, err)
+	}
+	return data, nil
+}
+
+// SaveMovie writes m to path.
+func SaveMovie(path string, m *Movie) error {
+	var buf bytes.Buffer
+	if err := WriteMovie(&buf, m); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// LoadMovie reads a movie from path.
+func LoadMovie(path string) (*Movie, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadMovie(f)
+}
+
+// MovieRecorder records a movie from a running emulator. Frontends call
+// Frame once per frame, right before RunFrame, with exactly the buttons
+// they pass to SetInputButtons, and Finish after the last RunFrame (from
+// the same goroutine, so the last frame's checkpoint is the one that ran).
+type MovieRecorder struct {
+	mu    sync.Mutex
+	emu   *emulator.Emulator
+	movie *Movie
+}
+
+// StartMovie starts recording on emu, which must have a ROM loaded. With
+// powerOn the machine is power-cycled first; otherwise the movie starts
+// from a savestate of the current moment.
+func StartMovie(emu *emulator.Emulator, powerOn bool, author, comment string) (*MovieRecorder, error) {
+	rom := emu.ROMImage()
+	if len(rom) == 0 {
+		return nil, fmt.Errorf("movie: no ROM loaded")
+	}
+	m := &Movie{Header: MovieHeader{
+		ROMHash: MovieROMHash(rom),
+		Author:  author,
+		Comment: comment,
+		Created: time.Now().UTC().Truncate(time.Second),
+		PowerOn: powerOn,
+	}}
+	if powerOn {
+		emu.Reset()
+	} else {
+		state, err := emu.SaveState()
+		if err != nil {
+			return nil, fmt.Errorf("movie: %w", err)
+		}
+		m.State = state
+	}
+	return &MovieRecorder{emu: emu, movie: m}, nil
+}
+
+// Frame records input as the buttons of the frame about to run.
+func (r *MovieRecorder) Frame(input uint16) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if n := len(r.movie.Input); n > 0 && n%MovieCheckpointInterval == 0 {
+		r.movie.Header.Checkpoints = append(r.movie.Header.Checkpoints, captureCheckpoint(r.emu, n-1))
+	}
+	r.movie.Input = append(r.movie.Input, input)
+}
+
+// Frames is the number of frames recorded so far.
+func (r *MovieRecorder) Frames() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.movie.Input)
+}
+
+// Finish ends the recording, adding the final checkpoint, and returns the
+// movie.
+func (r *MovieRecorder) Finish() *Movie {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m := r.movie
+	if n := len(m.Input); n > 0 {
+		cps := m.Header.Checkpoints
+		if len(cps) == 0 || cps[len(cps)-1].Frame != n-1 {
+			m.Header.Checkpoints = append(cps, captureCheckpoint(r.emu, n-1))
+		}
+	}
+	return m
+}
+
+// MovieMismatch is the first checkpoint playback did not reproduce.
+type MovieMismatch struct {
+	Frame     int
+	Component string // "framebuffer" or "state"
+	Expected  string
+	Actual    string
+}
+
+func (d *MovieMismatch) Error() string {
+	return fmt.Sprintf("movie desynced at frame %d: %s hash %.12s, want %.12s", d.Frame, d.Component, d.Actual, d.Expected)
+}
+
+// MoviePlayer plays a movie back. Frontends call Next before each frame,
+// apply the input, run the frame, then call Check.
+type MoviePlayer struct {
+	movie *Movie
+	frame int
+	cp    int // next checkpoint
+}
+
+// PlayMovie prepares emu to play m: it checks that the loaded ROM is the
+// movie's, then power-cycles or loads the start state and resumes.
+func PlayMovie(emu *emulator.Emulator, m *Movie) (*MoviePlayer, error) {
+	if rom := emu.ROMImage(); MovieROMHash(rom) != m.Header.ROMHash {
+		return nil, fmt.Errorf("movie: it was recorded with a different ROM")
+	}
+	if m.Header.PowerOn {
+		emu.Reset()
+	} else {
+		if err := emu.LoadState(m.State); err != nil {
+			return nil, fmt.Errorf("movie: start state: %w", err)
+		}
+		emu.Running = true
+		emu.Resume()
+	}
+	return &MoviePlayer{movie: m}, nil
+}
+
+// Next returns the buttons for the next frame; ok is false once the movie
+// has ended.
+func (p *MoviePlayer) Next() (input uint16, ok bool) {
+	if p.frame >= len(p.movie.Input) {
+		return 0, false
+	}
+	input = p.movie.Input[p.frame]
+	p.frame++
+	return input, true
+}
+
+// Frame is the number of frames played so far.
+func (p *MoviePlayer) Frame() int {
+	return p.frame
+}
+
+// Done reports whether every frame has been played.
+func (p *MoviePlayer) Done() bool {
+	return p.frame >= len(p.movie.Input)
+}
+
+// Check compares the frame just run with the movie's checkpoint for it,
+// if it has one. It returns nil when they match or there is none.
+func (p *MoviePlayer) Check(emu *emulator.Emulator) *MovieMismatch {
+	cps := p.movie.Header.Checkpoints
+	for p.cp < len(cps) && cps[p.cp].Frame < p.frame-1 {
+		p.cp++
+	}
+	if p.cp >= len(cps) || cps[p.cp].Frame != p.frame-1 {
+		return nil
+	}
+	want := cps[p.cp]
+	p.cp++
+	got := captureCheckpoint(emu, want.Frame)
+	if got.FramebufferHash != want.FramebufferHash {
+		return &MovieMismatch{Frame: want.Frame, Component: "framebuffer", Expected: want.FramebufferHash, Actual: got.FramebufferHash}
+	}
+	if want.StateHash != "" && got.StateHash != want.StateHash {
+		return &MovieMismatch{Frame: want.Frame, Component: "state", Expected: want.StateHash, Actual: got.StateHash}
+	}
+	return nil
+}
+
+// VerifyMovie plays m on a fresh emulator with rom, headless and in
+// deterministic mode, and returns the first checkpoint it does not
+// reproduce, or nil when the whole movie matches.
+func VerifyMovie(rom []byte, m *Movie) (*MovieMismatch, error) {
+	emu := emulator.NewEmulator()
+	defer emu.Logger.Shutdown()
+	if err := emu.LoadROM(rom); err != nil {
+		return nil, fmt.Errorf("load ROM: %w", err)
+	}
+	emu.SetFrameLimit(false)
+	emu.SetDeterministic(true)
+	emu.Start()
+	p, err := PlayMovie(emu, m)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		input, ok := p.Next()
+		if !ok {
+			return nil, nil
+		}
+		emu.SetInputButtons(input)
+		if err := emu.RunFrame(); err != nil {
+			return nil, fmt.Errorf("frame %d: %w", p.Frame()-1, err)
+		}
+		if d := p.Check(emu); d != nil {
+			return d, nil
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package harness
+
+import (
+	"bytes"
+	"testing"
+
+	"nitro-core-dx/internal/emulator"
+)
+
+// recordMovie runs counterROM for warmup frames, then records a movie of
+// frames frames with changing input.
+func recordMovie(t *testing.T, rom []byte, powerOn bool, warmup, frames int) *Movie {
+	t.Helper()
+	emu := emulator.NewEmulator()
+	defer emu.Logger.Shutdown()
+	if err := emu.LoadROM(rom); err != nil {
+		t.Fatal(err)
+	}
+	emu.SetFrameLimit(false)
+	emu.Start()
+	for i := 0; i < warmup; i++ {
+		emu.SetInputButtons(0x40)
+		if err := emu.RunFrame(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rec, err := StartMovie(emu, powerOn, "tester", "counter run")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < frames; i++ {
+		input := uint16(i % 7)
+		rec.Frame(input)
+		emu.SetInputButtons(input)
+		if err := emu.RunFrame(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return rec.Finish()
+}
+
+func TestMovieRoundTripAndVerify(t *testing.T) {
+	rom := counterROM(t)
+	for _, powerOn := range []bool{true, false} {
+		m := recordMovie(t, rom, powerOn, 5, 2*MovieCheckpointInterval+10)
+		if got := len(m.Header.Checkpoints); got != 3 {
+			t.Fatalf("powerOn=%v: %d checkpoints, want 3", powerOn, got)
+		}
+
+		var buf bytes.Buffer
+		if err := WriteMovie(&buf, m); err != nil {
+			t.Fatal(err)
+		}
+		back, err := ReadMovie(&buf)
+		if err != nil {
+			t.Fatalf("powerOn=%v: read: %v", powerOn, err)
+		}
+		if back.Header.Author != "tester" || back.Header.PowerOn != powerOn || len(back.Input) != len(m.Input) || !bytes.Equal(back.State, m.State) {
+			t.Fatalf("powerOn=%v: round trip header %+v, %d frames", powerOn, back.Header, len(back.Input))
+		}
+
+		if d, err := VerifyMovie(rom, back); err != nil || d != nil {
+			t.Fatalf("powerOn=%v: verify: %v, %v", powerOn, d, err)
+		}
+	}
+}
+
+func TestVerifyMovieReportsDesyncAndWrongROM(t *testing.T) {
+	rom := counterROM(t)
+	m := recordMovie(t, rom, false, 3, MovieCheckpointInterval+1)
+
+	// Any change to the start state shows at the first checkpoint.
+	emu := emulator.NewEmulator()
+	defer emu.Logger.Shutdown()
+	if err := emu.LoadROM(rom); err != nil {
+		t.Fatal(err)
+	}
+	if err := emu.LoadState(m.State); err != nil {
+		t.Fatal(err)
+	}
+	emu.Bus.WRAM[0x1000] ^= 0xFF
+	state, err := emu.SaveState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.State = state
+	d, err := VerifyMovie(rom, m)
+	if err != nil || d == nil || d.Frame != MovieCheckpointInterval-1 || d.Component != "state" {
+		t.Fatalf("VerifyMovie = %v, %v; want a state desync at frame %d", d, err, MovieCheckpointInterval-1)
+	}
+
+	other := append([]byte(nil), rom...)
+	other[len(other)-1] ^= 1
+	if _, err := VerifyMovie(other, m); err == nil {
+		t.Error("VerifyMovie accepted a different ROM")
+	}
+	if _, err := ReadMovie(bytes.NewReader([]byte("NOPE"))); err == nil {
+		t.Error("ReadMovie accepted a bad magic")
+	}
+}
+
+// The state hash is part of the .ncm format: a movie recorded by one
+// emulator must check on the next. A change here breaks every recorded
+// movie (see stateHash).
+func TestMovieStateHashIsStable(t *testing.T) {
+	const want = "73924f70125d66899c869a07ead3d723c6fdbf763e03bbc3a14d4b2e824ea78d"
+	m := recordMovie(t, counterROM(t), true, 0, MovieCheckpointInterval)
+	if got := m.Header.Checkpoints[0].StateHash; got != want {
+		t.Errorf("state hash at frame %d = %s, want %s", m.Header.Checkpoints[0].Frame, got, want)
+	}
+}
+
+// Version 1 movies still play, checking only their framebuffer hashes.
+func TestReadMovieVersion1DropsStateHashes(t *testing.T) {
+	rom := counterROM(t)
+	m := recordMovie(t, rom, true, 0, MovieCheckpointInterval)
+	m.Header.Checkpoints[0].StateHash = "gob-era hash"
+	var buf bytes.Buffer
+	if err := WriteMovie(&buf, m); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	data[len(movieMagic)] = 1 // format version, little-endian
+	back, err := ReadMovie(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cp := back.Header.Checkpoints[0]; cp.StateHash != "" || cp.FramebufferHash == "" {
+		t.Fatalf("checkpoint = %+v, want only the framebuffer hash", cp)
+	}
+	if d, err := VerifyMovie(rom, back); err != nil || d != nil {
+		t.Fatalf("verify: %v, %v", d, err)
+	}
+}
@@ -89,6 +89,12 @@ func Record(emu *emulator.Emulator, inputScript []uint16, includeAudio bool) (*R
 		rec.ROMHash = hex.EncodeToString(h[:])
 	}
 
+	// Record in fixed-timestep mode so the run matches a later replay
+	// regardless of host load; restore the caller's setting afterwards.
+	wasDeterministic := emu.Deterministic
+	emu.SetDeterministic(true)
+	defer emu.SetDeterministic(wasDeterministic)
+
 	for i := 0; i < n; i++ {
 		emu.SetInputButtons(inputScript[i])
 		if err := emu.RunFrame(); err != nil {
@@ -148,7 +154,7 @@ func ReplayAndCompare(rom []byte, rec *Recording, outDir string) (*DiffResult, e
 		return nil, fmt.Errorf("load ROM: %w", err)
 	}
 	emu.Start()
-	emu.SetFrameLimit(false)
+	emu.SetDeterministic(true)
 
 	// Optional: verify ROM hash
 	if rec.ROMHash != "" && len(rom) > 0 {
@@ -228,7 +234,7 @@ func ExportReplayToFrames(rom []byte, rec *Recording, outDir string) error {
 		return err
 	}
 	emu.Start()
-	emu.SetFrameLimit(false)
+	emu.SetDeterministic(true)
 
 	type frameMeta struct {
 		Frame int    `json:"frame"`
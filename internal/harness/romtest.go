@@ -0,0 +1,295 @@
+package harness
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"nitro-core-dx/internal/emulator"
+	"nitro-core-dx/internal/fileassoc"
+)
+
+// Test ROM result convention. A test ROM reports its outcome by writing
+// ResultStatusAddr in work RAM bank 0 (inside the CoreLX user scratch
+// region, so mem.write reaches it and the compiler never allocates it):
+//
+//	mem.write16(0x7FF2, 7)   -- optional: which check failed
+//	mem.write(0x7FF0, 2)     -- ResultFail
+//
+// Work RAM powers on zeroed, so a ROM that never writes the status is still
+// running and times out.
+//
+// ROMs run in the emulator's test mode, so they may report through the
+// TEST_* port (see emulator.TestPort) instead: exiting with status 0 passes
+// and any other status fails with that status as the code. A failed
+// port assertion fails the ROM however it ends, and the text it prints is
+// kept in ROMTestResult.Output.
+const (
+	ResultStatusAddr = 0x7FF0 // status byte: ResultRunning, ResultPass or ResultFail
+	ResultCodeAddr   = 0x7FF2 // 16-bit little-endian detail code, read on failure
+
+	ResultRunning = 0
+	ResultPass    = 1
+	ResultFail    = 2
+)
+
+// Outcomes of one test ROM run.
+const (
+	OutcomePass    = "pass"
+	OutcomeFail    = "fail"    // the ROM reported ResultFail
+	OutcomeTimeout = "timeout" // no result within the frame limit
+	OutcomeError   = "error"   // the ROM could not be loaded or faulted
+)
+
+// ROMTestResult is the outcome of one test ROM.
+type ROMTestResult struct {
+	Name     string        `json:"name"` // file name without extension
+	Path     string        `json:"path"`
+	Outcome  string        `json:"outcome"`
+	Code     uint16        `json:"code,omitempty"` // ResultCodeAddr, for OutcomeFail
+	Frames   int           `json:"frames"`         // frames run
+	Duration time.Duration `json:"duration_ns"`
+	Message  string        `json:"message,omitempty"`
+	Output   string        `json:"output,omitempty"` // text printed to the test port
+}
+
+// Passed reports whether the ROM passed.
+func (r ROMTestResult) Passed() bool { return r.Outcome == OutcomePass }
+
+// FindTestROMs lists the *.rom files directly in dir, sorted by name.
+func FindTestROMs(dir string) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.rom"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// readTestROM reads the ROM at path, extracting it and its symbols from a
+// zipped game. Any other file is read as-is, so a bad ROM fails in LoadROM
+// and reports like any other broken ROM.
+func readTestROM(path string) ([]byte, emulator.SymbolTable, error) {
+	if fileassoc.Classify(path) != fileassoc.KindBundle {
+		data, err := os.ReadFile(path)
+		return data, nil, err
+	}
+	b, err := fileassoc.ReadBundle(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return b.ROM, b.Symbols, nil
+}
+
+// RunROMTest loads the ROM at path into a fresh deterministic emulator and
+// runs it until it writes a result or maxFrames have passed. path may be a
+// zipped game, whose link map, if it has one, names functions in faults.
+func RunROMTest(path string, maxFrames int) (res ROMTestResult) {
+	start := time.Now()
+	res = ROMTestResult{
+		Name: strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+		Path: path,
+	}
+	defer func() { res.Duration = time.Since(start) }()
+
+	data, syms, err := readTestROM(path)
+	if err != nil {
+		res.Outcome, res.Message = OutcomeError, err.Error()
+		return res
+	}
+	emu := emulator.NewEmulator()
+	if emu.Logger != nil {
+		defer emu.Logger.Shutdown()
+	}
+	if err := emu.LoadROM(data); err != nil {
+		res.Outcome, res.Message = OutcomeError, "load ROM: "+err.Error()
+		return res
+	}
+	emu.SetFrameLimit(false)
+	emu.SetDeterministic(true)
+	emu.EnableTestMode()
+	emu.Start()
+	port := emu.TestPort
+	defer func() {
+		res.Output = port.Output()
+		if failures := port.Failures(); len(failures) > 0 && res.Outcome != OutcomeError {
+			if res.Outcome == OutcomePass {
+				res.Outcome, res.Message = OutcomeFail, ""
+			}
+			lines := []string{res.Message}
+			if res.Message == "" {
+				lines = nil
+			}
+			for _, f := range failures {
+				lines = append(lines, f.String())
+			}
+			res.Message = strings.Join(lines, "\n")
+		}
+	}()
+
+	for res.Frames < maxFrames {
+		if err := emu.RunFrameGuarded(syms); err != nil {
+			res.Outcome, res.Message = OutcomeError, err.Error()
+			var f *emulator.Fault
+			if errors.As(err, &f) {
+				res.Message = f.Report()
+			}
+			return res
+		}
+		res.Frames++
+		if status, exited := port.Exited(); exited {
+			res.Outcome = OutcomePass
+			if status != 0 {
+				res.Outcome, res.Code = OutcomeFail, status
+				res.Message = fmt.Sprintf("exited with status %d", status)
+			}
+			return res
+		}
+		switch emu.Bus.WRAM[ResultStatusAddr] {
+		case ResultPass:
+			res.Outcome = OutcomePass
+			return res
+		case ResultFail:
+			res.Outcome = OutcomeFail
+			res.Code = uint16(emu.Bus.WRAM[ResultCodeAddr]) | uint16(emu.Bus.WRAM[ResultCodeAddr+1])<<8
+			res.Message = fmt.Sprintf("reported failure code %d", res.Code)
+			return res
+		}
+	}
+	res.Outcome = OutcomeTimeout
+	res.Message = fmt.Sprintf("no result after %d frames", maxFrames)
+	return res
+}
+
+// RunROMTests runs every ROM in paths with up to workers at a time (at least
+// one). Results are in the order of paths.
+func RunROMTests(paths []string, maxFrames, workers int) []ROMTestResult {
+	if workers < 1 {
+		workers = 1
+	}
+	results := make([]ROMTestResult, len(paths))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				results[i] = RunROMTest(paths[i], maxFrames)
+			}
+		}()
+	}
+	for i := range paths {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return results
+}
+
+// ROMTestSummary counts results by outcome.
+type ROMTestSummary struct {
+	Total, Passed, Failed, TimedOut, Errors int
+}
+
+// Summarize counts results by outcome.
+func Summarize(results []ROMTestResult) ROMTestSummary {
+	s := ROMTestSummary{Total: len(results)}
+	for _, r := range results {
+		switch r.Outcome {
+		case OutcomePass:
+			s.Passed++
+		case OutcomeFail:
+			s.Failed++
+		case OutcomeTimeout:
+			s.TimedOut++
+		default:
+			s.Errors++
+		}
+	}
+	return s
+}
+
+// WriteROMTestJSON writes results and their summary as indented JSON.
+func WriteROMTestJSON(w io.Writer, results []ROMTestResult) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		Summary ROMTestSummary  `json:"summary"`
+		Results []ROMTestResult `json:"results"`
+	}{Summarize(results), results})
+}
+
+type junitSuite struct {
+	XMLName  xml.Name    `xml:"testsuite"`
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Errors   int         `xml:"errors,attr"`
+	Time     float64     `xml:"time,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      float64       `xml:"time,attr"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// WriteROMTestJUnit writes results as a JUnit XML test suite named suite,
+// the format most CI systems render. Failures and timeouts are <failure>s;
+// load errors and faults are <error>s.
+func WriteROMTestJUnit(w io.Writer, suite string, results []ROMTestResult) error {
+	sum := Summarize(results)
+	out := junitSuite{
+		Name:     suite,
+		Tests:    sum.Total,
+		Failures: sum.Failed + sum.TimedOut,
+		Errors:   sum.Errors,
+	}
+	for _, r := range results {
+		c := junitCase{Name: r.Name, Classname: suite, Time: r.Duration.Seconds(), SystemOut: r.Output}
+		out.Time += c.Time
+		problem := &junitProblem{Message: firstLine(r.Message), Type: r.Outcome, Body: r.Message}
+		switch r.Outcome {
+		case OutcomePass:
+		case OutcomeFail, OutcomeTimeout:
+			c.Failure = problem
+		default:
+			c.Error = problem
+		}
+		out.Cases = append(out.Cases, c)
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(out); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
@@ -0,0 +1,246 @@
+package harness
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"nitro-core-dx/internal/hwdef"
+	"nitro-core-dx/internal/rom"
+)
+
+// resultROM stores the given words at ResultStatusAddr onward, last word
+// first so the status lands after the code, and then spins; with no words
+// it only spins.
+func resultROM(t *testing.T, words ...uint16) []byte {
+	t.Helper()
+	var stores [][2]uint16
+	for i := len(words) - 1; i >= 0; i-- {
+		stores = append(stores, [2]uint16{uint16(ResultStatusAddr + 2*i), words[i]})
+	}
+	return storeROM(t, stores...)
+}
+
+// storeROM stores each {address, word} pair in bank 0, in order, and then
+// spins.
+func storeROM(t *testing.T, stores ...[2]uint16) []byte {
+	t.Helper()
+	b := rom.NewROMBuilder()
+	for _, st := range stores {
+		b.AddInstruction(rom.EncodeMOV(1, 2, 0)) // MOV R2, #addr
+		b.AddImmediate(st[0])
+		b.AddInstruction(rom.EncodeMOV(1, 1, 0)) // MOV R1, #word
+		b.AddImmediate(st[1])
+		b.AddInstruction(rom.EncodeMOV(3, 2, 1)) // MOV [R2], R1
+	}
+	spin := uint16(0x8000 + 2*b.GetCodeLength())
+	b.AddInstruction(rom.EncodeJMP())
+	b.AddImmediate(uint16(rom.CalculateBranchOffset(spin+2, spin)))
+	data, err := b.BuildROMBytes(1, 0x8000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func writeTestROMs(t *testing.T) string {
+	t.Helper()
+	faulty := rom.NewROMBuilder()
+	faulty.AddInstruction(rom.EncodeADD(9, 0, 0)) // unassigned ADD mode
+	faultyROM, err := faulty.BuildROMBytes(1, 0x8000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	for name, data := range map[string][]byte{
+		"a_pass.rom":    resultROM(t, ResultPass),
+		"b_fail.rom":    resultROM(t, ResultFail, 7), // code 7 at ResultCodeAddr
+		"c_timeout.rom": resultROM(t),
+		"d_fault.rom":   faultyROM,
+		"e_garbage.rom": []byte("not a ROM"),
+		"notes.txt":     []byte("ignored"),
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestRunROMTestsOutcomes(t *testing.T) {
+	paths, err := FindTestROMs(writeTestROMs(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 5 {
+		t.Fatalf("FindTestROMs found %d ROMs, want 5: %v", len(paths), paths)
+	}
+	results := RunROMTests(paths, 5, 3)
+	want := []struct {
+		name, outcome string
+	}{
+		{"a_pass", OutcomePass},
+		{"b_fail", OutcomeFail},
+		{"c_timeout", OutcomeTimeout},
+		{"d_fault", OutcomeError},
+		{"e_garbage", OutcomeError},
+	}
+	for i, w := range want {
+		if r := results[i]; r.Name != w.name || r.Outcome != w.outcome {
+			t.Errorf("result %d = %s %s (%s), want %s %s", i, r.Name, r.Outcome, r.Message, w.name, w.outcome)
+		}
+	}
+	if results[1].Code != 7 {
+		t.Errorf("fail code = %d, want 7", results[1].Code)
+	}
+	if results[0].Duration <= 0 {
+		t.Errorf("pass result has no duration")
+	}
+	if results[2].Frames != 5 {
+		t.Errorf("timeout ran %d frames, want 5", results[2].Frames)
+	}
+	if !strings.Contains(results[3].Message, "CPU fault") {
+		t.Errorf("fault message = %q, want the fault report", results[3].Message)
+	}
+	sum := Summarize(results)
+	if sum != (ROMTestSummary{Total: 5, Passed: 1, Failed: 1, TimedOut: 1, Errors: 2}) {
+		t.Errorf("Summarize = %+v", sum)
+	}
+}
+
+// TestRunROMTestTestPort checks ROMs reporting through the TEST_* port.
+func TestRunROMTestTestPort(t *testing.T) {
+	print := func(c byte) [][2]uint16 {
+		return [][2]uint16{{hwdef.TEST_VALUE_L, uint16(c)}, {hwdef.TEST_COMMAND, hwdef.TestCmdPrintChar}}
+	}
+	assert := func(got, want uint16) [][2]uint16 {
+		return [][2]uint16{{hwdef.TEST_VALUE_L, got}, {hwdef.TEST_EXPECT_L, want}, {hwdef.TEST_COMMAND, hwdef.TestCmdAssertEqual}}
+	}
+	exit := func(status uint16) [][2]uint16 {
+		return [][2]uint16{{hwdef.TEST_VALUE_L, status}, {hwdef.TEST_COMMAND, hwdef.TestCmdExit}}
+	}
+	join := func(parts ...[][2]uint16) [][2]uint16 {
+		var out [][2]uint16
+		for _, p := range parts {
+			out = append(out, p...)
+		}
+		return out
+	}
+	cases := []struct {
+		name    string
+		stores  [][2]uint16
+		outcome string
+		code    uint16
+		output  string
+		message string
+	}{
+		{"pass", join(print('h'), print('i'), assert(4, 4), exit(0)), OutcomePass, 0, "hi", ""},
+		{"status", exit(3), OutcomeFail, 3, "", "exited with status 3"},
+		{"assert", join(assert(1, 2), exit(0)), OutcomeFail, 0, "assertion 1 failed", "assertion 1 failed"},
+		{"assert-timeout", assert(1, 2), OutcomeTimeout, 0, "assertion 1 failed", "assertion 1 failed"},
+	}
+	dir := t.TempDir()
+	for _, c := range cases {
+		path := filepath.Join(dir, c.name+".rom")
+		if err := os.WriteFile(path, storeROM(t, c.stores...), 0644); err != nil {
+			t.Fatal(err)
+		}
+		r := RunROMTest(path, 5)
+		if r.Outcome != c.outcome || r.Code != c.code {
+			t.Errorf("%s: %s code %d (%s), want %s code %d", c.name, r.Outcome, r.Code, r.Message, c.outcome, c.code)
+		}
+		if !strings.HasPrefix(r.Output, c.output) {
+			t.Errorf("%s: output = %q, want prefix %q", c.name, r.Output, c.output)
+		}
+		if !strings.Contains(r.Message, c.message) {
+			t.Errorf("%s: message = %q, want %q", c.name, r.Message, c.message)
+		}
+	}
+}
+
+// TestRunROMTestZippedGame checks a zipped ROM runs and its link map
+// names the faulting function.
+func TestRunROMTestZippedGame(t *testing.T) {
+	faulty := rom.NewROMBuilder()
+	faulty.AddInstruction(rom.EncodeNOP())
+	faulty.AddInstruction(rom.EncodeADD(9, 0, 0)) // unassigned ADD mode
+	faultyROM, err := faulty.BuildROMBytes(1, 0x8000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	for name, files := range map[string]map[string][]byte{
+		"pass.zip": {"pass.rom": resultROM(t, ResultPass)},
+		"fault.zip": {
+			"fault.rom": faultyROM,
+			"fault.map": []byte("Entry: 01:8000\n\nSymbols:\n  01:8000  global  Broken                   main\n"),
+		},
+	} {
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		for file, data := range files {
+			w, err := zw.Create(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			w.Write(data)
+		}
+		if err := zw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), buf.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if r := RunROMTest(filepath.Join(dir, "pass.zip"), 5); r.Name != "pass" || r.Outcome != OutcomePass {
+		t.Errorf("pass.zip: %s %s (%s), want pass pass", r.Name, r.Outcome, r.Message)
+	}
+	r := RunROMTest(filepath.Join(dir, "fault.zip"), 5)
+	if r.Outcome != OutcomeError || !strings.Contains(r.Message, "(Broken+0x2)") {
+		t.Errorf("fault.zip: %s %q, want an error naming Broken+0x2", r.Outcome, r.Message)
+	}
+}
+
+func TestWriteROMTestReports(t *testing.T) {
+	results := []ROMTestResult{
+		{Name: "ok", Outcome: OutcomePass, Frames: 3, Output: "all good"},
+		{Name: "bad", Outcome: OutcomeFail, Code: 4, Message: "reported failure code 4"},
+		{Name: "crash", Outcome: OutcomeError, Message: "CPU fault at PC 01:8000\nregisters"},
+	}
+
+	var js bytes.Buffer
+	if err := WriteROMTestJSON(&js, results); err != nil {
+		t.Fatal(err)
+	}
+	var decoded struct {
+		Summary ROMTestSummary
+		Results []ROMTestResult
+	}
+	if err := json.Unmarshal(js.Bytes(), &decoded); err != nil {
+		t.Fatalf("JSON does not round-trip: %v\n%s", err, js.String())
+	}
+	if decoded.Summary.Failed != 1 || len(decoded.Results) != 3 || decoded.Results[1].Code != 4 {
+		t.Errorf("decoded JSON = %+v", decoded)
+	}
+
+	var xmlOut bytes.Buffer
+	if err := WriteROMTestJUnit(&xmlOut, "roms", results); err != nil {
+		t.Fatal(err)
+	}
+	got := xmlOut.String()
+	for _, want := range []string{
+		`<testsuite name="roms" tests="3" failures="1" errors="1"`,
+		`<testcase name="ok" classname="roms"`,
+		`<system-out>all good</system-out>`,
+		`<failure message="reported failure code 4" type="fail">`,
+		`<error message="CPU fault at PC 01:8000" type="error">`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("JUnit output missing %q:\n%s", want, got)
+		}
+	}
+}
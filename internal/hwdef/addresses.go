@@ -0,0 +1,313 @@
+package hwdef
+
+// I/O regions in bank 0. Each chip's handler sees addresses relative to its
+// base.
+const (
+	IOBase    = 0x8000
+	PPUBase   = 0x8000
+	APUBase   = 0x9000
+	FMBase    = 0x9100
+	InputBase = 0xA000
+	IOEnd     = 0xB000
+)
+
+// Register addresses, spelled as in the hardware documentation so code,
+// CoreLX sources and assembler listings can be grepped for the same name.
+// Each constant matches its Registers entry; registers_test.go checks the
+// two stay in step.
+const (
+	// BG0
+	BG0_SCROLLX_L      = 0x8000
+	BG0_SCROLLX_H      = 0x8001
+	BG0_SCROLLY_L      = 0x8002
+	BG0_SCROLLY_H      = 0x8003
+	BG0_CONTROL        = 0x8008
+	BG0_SOURCE_MODE    = 0x8068
+	BG0_TRANSFORM_BIND = 0x806C
+	BG0_TILEMAP_BASE_L = 0x8077
+	BG0_TILEMAP_BASE_H = 0x8078
+
+	// BG1
+	BG1_SCROLLX_L      = 0x8004
+	BG1_SCROLLX_H      = 0x8005
+	BG1_SCROLLY_L      = 0x8006
+	BG1_SCROLLY_H      = 0x8007
+	BG1_CONTROL        = 0x8009
+	BG1_SOURCE_MODE    = 0x8069
+	BG1_TRANSFORM_BIND = 0x806D
+	BG1_TILEMAP_BASE_L = 0x8079
+	BG1_TILEMAP_BASE_H = 0x807A
+
+	// BG2
+	BG2_SCROLLX_L      = 0x800A
+	BG2_SCROLLX_H      = 0x800B
+	BG2_SCROLLY_L      = 0x800C
+	BG2_SCROLLY_H      = 0x800D
+	BG2_CONTROL        = 0x8021
+	BG2_SOURCE_MODE    = 0x806A
+	BG2_TRANSFORM_BIND = 0x806E
+	BG2_TILEMAP_BASE_L = 0x807B
+	BG2_TILEMAP_BASE_H = 0x807C
+
+	// BG3
+	BG3_SCROLLX_L      = 0x8022
+	BG3_SCROLLX_H      = 0x8023
+	BG3_SCROLLY_L      = 0x8024
+	BG3_SCROLLY_H      = 0x8025
+	BG3_CONTROL        = 0x8026
+	BG3_SOURCE_MODE    = 0x806B
+	BG3_TRANSFORM_BIND = 0x806F
+	BG3_TILEMAP_BASE_L = 0x807D
+	BG3_TILEMAP_BASE_H = 0x807E
+
+	// Video ports
+	VRAM_ADDR_L = 0x800E
+	VRAM_ADDR_H = 0x800F
+	VRAM_DATA   = 0x8010
+	CGRAM_ADDR  = 0x8012
+	CGRAM_DATA  = 0x8013
+	OAM_ADDR    = 0x8014
+	OAM_DATA    = 0x8015
+
+	// BG0 matrix
+	MATRIX_CONTROL    = 0x8018
+	MATRIX_A_L        = 0x8019
+	MATRIX_A_H        = 0x801A
+	MATRIX_B_L        = 0x801B
+	MATRIX_B_H        = 0x801C
+	MATRIX_C_L        = 0x801D
+	MATRIX_C_H        = 0x801E
+	MATRIX_D_L        = 0x801F
+	MATRIX_D_H        = 0x8020
+	MATRIX_CENTER_X_L = 0x8027
+	MATRIX_CENTER_X_H = 0x8028
+	MATRIX_CENTER_Y_L = 0x8029
+	MATRIX_CENTER_Y_H = 0x802A
+
+	// BG1 matrix
+	BG1_MATRIX_CONTROL    = 0x802B
+	BG1_MATRIX_A_L        = 0x802C
+	BG1_MATRIX_A_H        = 0x802D
+	BG1_MATRIX_B_L        = 0x802E
+	BG1_MATRIX_B_H        = 0x802F
+	BG1_MATRIX_C_L        = 0x8030
+	BG1_MATRIX_C_H        = 0x8031
+	BG1_MATRIX_D_L        = 0x8032
+	BG1_MATRIX_D_H        = 0x8033
+	BG1_MATRIX_CENTER_X_L = 0x8034
+	BG1_MATRIX_CENTER_X_H = 0x8035
+	BG1_MATRIX_CENTER_Y_L = 0x8036
+	BG1_MATRIX_CENTER_Y_H = 0x8037
+
+	// BG2 matrix
+	BG2_MATRIX_CONTROL    = 0x8038
+	BG2_MATRIX_A_L        = 0x8039
+	BG2_MATRIX_A_H        = 0x803A
+	BG2_MATRIX_B_L        = 0x803B
+	BG2_MATRIX_B_H        = 0x803C
+	BG2_MATRIX_C_L        = 0x803D
+	BG2_MATRIX_C_H        = 0x803E
+	BG2_MATRIX_D_L        = 0x803F
+	BG2_MATRIX_D_H        = 0x8040
+	BG2_MATRIX_CENTER_X_L = 0x8041
+	BG2_MATRIX_CENTER_X_H = 0x8042
+	BG2_MATRIX_CENTER_Y_L = 0x8043
+	BG2_MATRIX_CENTER_Y_H = 0x8044
+
+	// BG3 matrix
+	BG3_MATRIX_CONTROL    = 0x8045
+	BG3_MATRIX_A_L        = 0x8046
+	BG3_MATRIX_A_H        = 0x8047
+	BG3_MATRIX_B_L        = 0x8048
+	BG3_MATRIX_B_H        = 0x8049
+	BG3_MATRIX_C_L        = 0x804A
+	BG3_MATRIX_C_H        = 0x804B
+	BG3_MATRIX_D_L        = 0x804C
+	BG3_MATRIX_D_H        = 0x804D
+	BG3_MATRIX_CENTER_X_L = 0x804E
+	BG3_MATRIX_CENTER_X_H = 0x804F
+	BG3_MATRIX_CENTER_Y_L = 0x8050
+	BG3_MATRIX_CENTER_Y_H = 0x8051
+
+	// Windows
+	WINDOW0_LEFT       = 0x8052
+	WINDOW0_RIGHT      = 0x8053
+	WINDOW0_TOP        = 0x8054
+	WINDOW0_BOTTOM     = 0x8055
+	WINDOW1_LEFT       = 0x8056
+	WINDOW1_RIGHT      = 0x8057
+	WINDOW1_TOP        = 0x8058
+	WINDOW1_BOTTOM     = 0x8059
+	WINDOW_CONTROL     = 0x805A
+	WINDOW_MAIN_ENABLE = 0x805B
+	WINDOW_SUB_ENABLE  = 0x805C
+
+	// DMA
+	HDMA_CONTROL           = 0x805D
+	HDMA_TABLE_BASE_L      = 0x805E
+	HDMA_TABLE_BASE_H      = 0x805F
+	HDMA_EXTENSION_CONTROL = 0x807F
+	DMA_CONTROL            = 0x8060
+	DMA_STATUS             = 0x8060
+	DMA_SOURCE_BANK        = 0x8061
+	DMA_SOURCE_OFFSET_L    = 0x8062
+	DMA_SOURCE_OFFSET_H    = 0x8063
+	DMA_DEST_ADDR_L        = 0x8064
+	DMA_DEST_ADDR_H        = 0x8065
+	DMA_LENGTH_L           = 0x8066
+	DMA_LENGTH_H           = 0x8067
+
+	// Text
+	TEXT_X_L     = 0x8070
+	TEXT_X_H     = 0x8071
+	TEXT_Y       = 0x8072
+	TEXT_COLOR_R = 0x8073
+	TEXT_COLOR_G = 0x8074
+	TEXT_COLOR_B = 0x8075
+	TEXT_CHAR    = 0x8076
+
+	// Matrix planes
+	MATRIX_PLANE_SELECT             = 0x8080
+	MATRIX_PLANE_CONTROL            = 0x8081
+	MATRIX_PLANE_ADDR_L             = 0x8082
+	MATRIX_PLANE_ADDR_H             = 0x8083
+	MATRIX_PLANE_DATA               = 0x8084
+	MATRIX_PLANE_PATTERN_ADDR_L     = 0x8085
+	MATRIX_PLANE_PATTERN_ADDR_H     = 0x8086
+	MATRIX_PLANE_PATTERN_DATA       = 0x8087
+	MATRIX_PLANE_BITMAP_ADDR_L      = 0x8088
+	MATRIX_PLANE_BITMAP_ADDR_M      = 0x8089
+	MATRIX_PLANE_BITMAP_ADDR_H      = 0x808A
+	MATRIX_PLANE_BITMAP_DATA        = 0x808B
+	MATRIX_PLANE_FLAGS              = 0x808C
+	MATRIX_PLANE_ROW_CONTROL        = 0x808D
+	MATRIX_PLANE_ROW_ADDR_L         = 0x808E
+	MATRIX_PLANE_ROW_ADDR_H         = 0x808F
+	MATRIX_PLANE_ROW_DATA           = 0x8090
+	MATRIX_PLANE_PROJECTION_CONTROL = 0x8091
+	MATRIX_PLANE_HORIZON            = 0x8092
+	MATRIX_PLANE_CAMERA_X_L         = 0x8093
+	MATRIX_PLANE_CAMERA_X_H         = 0x8094
+	MATRIX_PLANE_CAMERA_Y_L         = 0x8095
+	MATRIX_PLANE_CAMERA_Y_H         = 0x8096
+	MATRIX_PLANE_HEADING_X_L        = 0x8097
+	MATRIX_PLANE_HEADING_X_H        = 0x8098
+	MATRIX_PLANE_HEADING_Y_L        = 0x8099
+	MATRIX_PLANE_HEADING_Y_H        = 0x809A
+	MATRIX_PLANE_BASE_DISTANCE_L    = 0x809B
+	MATRIX_PLANE_BASE_DISTANCE_H    = 0x809C
+	MATRIX_PLANE_FOCAL_LENGTH_L     = 0x809D
+	MATRIX_PLANE_FOCAL_LENGTH_H     = 0x809E
+	MATRIX_PLANE_WIDTH_SCALE_L      = 0x809F
+	MATRIX_PLANE_WIDTH_SCALE_H      = 0x80A0
+	MATRIX_PLANE_ORIGIN_X_L         = 0x80A1
+	MATRIX_PLANE_ORIGIN_X_H         = 0x80A2
+	MATRIX_PLANE_ORIGIN_Y_L         = 0x80A3
+	MATRIX_PLANE_ORIGIN_Y_H         = 0x80A4
+	MATRIX_PLANE_FACING_X_L         = 0x80A5
+	MATRIX_PLANE_FACING_X_H         = 0x80A6
+	MATRIX_PLANE_FACING_Y_L         = 0x80A7
+	MATRIX_PLANE_FACING_Y_H         = 0x80A8
+	MATRIX_PLANE_HEIGHT_SCALE_L     = 0x80A9
+	MATRIX_PLANE_HEIGHT_SCALE_H     = 0x80AA
+
+	// Display
+	BORDER_COLOR = 0x80AB
+
+	// BG0 line scroll
+	BG0_LINE_SCROLL_L = 0x80AC
+	BG0_LINE_SCROLL_H = 0x80AD
+
+	// System
+	VBLANK_FLAG     = 0x803E
+	FRAME_COUNTER_L = 0x803F
+	FRAME_COUNTER_H = 0x8040
+
+	// APU channels
+	CH0_FREQ_LOW      = 0x9000
+	CH0_FREQ_HIGH     = 0x9001
+	CH0_VOLUME        = 0x9002
+	CH0_CONTROL       = 0x9003
+	CH0_DURATION_LOW  = 0x9004
+	CH0_DURATION_HIGH = 0x9005
+	CH0_DURATION_MODE = 0x9006
+	CH1_FREQ_LOW      = 0x9008
+	CH1_FREQ_HIGH     = 0x9009
+	CH1_VOLUME        = 0x900A
+	CH1_CONTROL       = 0x900B
+	CH1_DURATION_LOW  = 0x900C
+	CH1_DURATION_HIGH = 0x900D
+	CH1_DURATION_MODE = 0x900E
+	CH2_FREQ_LOW      = 0x9010
+	CH2_FREQ_HIGH     = 0x9011
+	CH2_VOLUME        = 0x9012
+	CH2_CONTROL       = 0x9013
+	CH2_DURATION_LOW  = 0x9014
+	CH2_DURATION_HIGH = 0x9015
+	CH2_DURATION_MODE = 0x9016
+	CH3_FREQ_LOW      = 0x9018
+	CH3_FREQ_HIGH     = 0x9019
+	CH3_VOLUME        = 0x901A
+	CH3_CONTROL       = 0x901B
+	CH3_DURATION_LOW  = 0x901C
+	CH3_DURATION_HIGH = 0x901D
+	CH3_DURATION_MODE = 0x901E
+	MASTER_VOLUME     = 0x9020
+	COMPLETION_STATUS = 0x9021
+	SAMPLE_BANK       = 0x9028
+	SAMPLE_ADDR_L     = 0x9029
+	SAMPLE_ADDR_H     = 0x902A
+	SAMPLE_LENGTH_L   = 0x902B
+	SAMPLE_LENGTH_H   = 0x902C
+	SAMPLE_RATE_L     = 0x902D
+	SAMPLE_RATE_H     = 0x902E
+	SAMPLE_VOLUME     = 0x902F
+	SAMPLE_CONTROL    = 0x9030
+	LOWPASS           = 0x9031
+	ECHO_DELAY        = 0x9032
+	ECHO_FEEDBACK     = 0x9033
+	ECHO_MIX          = 0x9034
+
+	// FM
+	FM_ADDR           = 0x9100
+	FM_DATA           = 0x9101
+	FM_STATUS         = 0x9102
+	FM_CONTROL        = 0x9103
+	FM_PORT1_ADDR     = 0x9104
+	FM_PORT1_DATA     = 0x9105
+	FM_VOLUME         = 0x9106
+	YM_BURST_COUNT_L  = 0x9110
+	YM_BURST_COUNT_H  = 0x9111
+	YM_BURST_BANK     = 0x9112
+	YM_BURST_OFFSET_L = 0x9113
+	YM_BURST_OFFSET_H = 0x9114
+	YM_BURST_TRIGGER  = 0x9115
+
+	// Input
+	CONTROLLER1_L = 0xA000
+	CONTROLLER1_H = 0xA001
+	CONTROLLER2_L = 0xA002
+	CONTROLLER2_H = 0xA003
+
+	// Persistent storage mailbox (host-side, see emulator.PersistStore)
+	PERSIST_KEY     = 0xA010
+	PERSIST_VALUE_L = 0xA011
+	PERSIST_VALUE_H = 0xA012
+	PERSIST_CONTROL = 0xA013
+
+	// Reset status (served by the bus)
+	RESET_CAUSE = 0xA020
+
+	// Test port (emulator test mode only, see emulator.TestPort)
+	TEST_VALUE_L  = 0xA030
+	TEST_VALUE_H  = 0xA031
+	TEST_EXPECT_L = 0xA032
+	TEST_EXPECT_H = 0xA033
+	TEST_COMMAND  = 0xA034
+
+	// Debug console (host-side, see emulator.DebugConsole)
+	DEBUG_CHAR    = 0xA040
+	DEBUG_VALUE_L = 0xA041
+	DEBUG_VALUE_H = 0xA042
+	DEBUG_PRINT   = 0xA043
+)
@@ -0,0 +1,20 @@
+package hwdef
+
+// Bus contention, in CPU cycles. A CPU byte access to a chip's registers
+// waits for that chip's side of the bus, so it costs the wait cycles below
+// on top of the instruction's own; a 16-bit access is two byte accesses.
+// Transfers that take the bus halt the CPU until they finish. The emulator
+// charges these when memory.Bus.Contention is set.
+const (
+	PPUWaitCycles = 2 // PPU registers, 0x8000-0x8FFF
+	APUWaitCycles = 1 // APU registers, 0x9000-0x90FF
+	FMWaitCycles  = 4 // FM registers and the YM burst streamer, 0x9100-0x9FFF
+
+	// DMAHaltCyclesPerByte is how long a PPU DMA transfer halts the CPU
+	// for each byte it moves.
+	DMAHaltCyclesPerByte = 1
+
+	// YMBurstHaltCyclesPerEntry is how long the YM burst streamer halts the
+	// CPU for each entry: three ROM reads and two FM register writes.
+	YMBurstHaltCyclesPerEntry = 3 + 2*FMWaitCycles
+)
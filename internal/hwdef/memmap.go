@@ -0,0 +1,132 @@
+package hwdef
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Region is a range of the CPU's 24-bit address space: offsets Start-End
+// (inclusive) in each bank from FirstBank to LastBank.
+type Region struct {
+	Name        string `json:"name"`
+	FirstBank   uint8  `json:"first_bank"`
+	LastBank    uint8  `json:"last_bank"`
+	Start       uint16 `json:"start"`
+	End         uint16 `json:"end"`
+	Description string `json:"description"`
+}
+
+// PortMemory is a memory the CPU reaches only through I/O ports or DMA.
+type PortMemory struct {
+	Name        string `json:"name"`
+	Size        int    `json:"size"`
+	Ports       string `json:"ports"`
+	Description string `json:"description"`
+}
+
+// MemoryMap is the console's address space and I/O registers, the data the
+// generated memory map document and JSON are made from (see cmd/hwdoc).
+type MemoryMap struct {
+	Regions      []Region     `json:"regions"`
+	PortMemories []PortMemory `json:"port_memories"`
+	Registers    []Register   `json:"registers"`
+}
+
+// Map returns the memory map.
+func Map() MemoryMap {
+	return MemoryMap{
+		Regions: []Region{
+			{"WRAM", 0, 0, 0x0000, 0x7FFF, "32 KB work RAM."},
+			{"PPU registers", 0, 0, PPUBase, APUBase - 1, "Video registers and ports."},
+			{"APU registers", 0, 0, APUBase, FMBase - 1, "Tone channels, sample channel and master effects."},
+			{"FM registers", 0, 0, FMBase, InputBase - 1, "FM chip ports and the bus-side YM burst streamer."},
+			{"Input and system registers", 0, 0, InputBase, IOEnd - 1, "Controllers, persistence mailbox, reset cause, test port and debug console."},
+			{"Unmapped", 0, 0, IOEnd, 0xFFDF, "No device; reads return open bus."},
+			{"System vectors", 0, 0, 0xFFE0, 0xFFFF, "IRQ and NMI vectors (bank, offset high byte), set by the ROM."},
+			{"Cartridge ROM", 1, 125, 0x8000, 0xFFFF, "32 KB of ROM per bank; offsets below 0x8000 and past the ROM's end are unmapped."},
+			{"Extended WRAM", 126, 127, 0x0000, 0xFFFF, "128 KB of work RAM."},
+			{"Unmapped banks", 128, 255, 0x0000, 0xFFFF, "Not decoded; reads return open bus."},
+		},
+		PortMemories: []PortMemory{
+			{"VRAM", 65536, "VRAM_ADDR, VRAM_DATA", "Tiles and tilemaps."},
+			{"CGRAM", 512, "CGRAM_ADDR, CGRAM_DATA", "256 RGB555 colors: 16 palettes of 16."},
+			{"OAM", 768, "OAM_ADDR, OAM_DATA", "128 sprites of 6 bytes."},
+			{"Matrix plane tilemap", 128 * 128 * 2, "MATRIX_PLANE_ADDR, MATRIX_PLANE_DATA", "Per matrix plane (MATRIX_PLANE_SELECT); up to 128x128 entries."},
+			{"Matrix plane patterns", 32 * 1024, "MATRIX_PLANE_PATTERN_ADDR, MATRIX_PLANE_PATTERN_DATA", "Per matrix plane."},
+			{"Matrix plane bitmap", 1024 * 1024 / 2, "MATRIX_PLANE_BITMAP_ADDR, MATRIX_PLANE_BITMAP_DATA", "Per matrix plane; a 4bpp bitmap up to 1024x1024."},
+		},
+		Registers: Registers(),
+	}
+}
+
+// JSON returns m as indented JSON, the machine-readable memory map.
+func (m MemoryMap) JSON() ([]byte, error) {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// Markdown returns m as the memory map document.
+func (m MemoryMap) Markdown() string {
+	var b strings.Builder
+	b.WriteString("# Nitro-Core-DX Memory Map\n\n")
+	b.WriteString("> Generated from `internal/hwdef` by `go run ./cmd/hwdoc`; do not edit by hand.\n")
+	b.WriteString("> The same data is in [memory_map.json](memory_map.json).\n\n")
+
+	b.WriteString("## CPU address space\n\n")
+	b.WriteString("Addresses are `bank:offset`.\n\n")
+	b.WriteString("| Banks | Offsets | Region | Notes |\n|---|---|---|---|\n")
+	for _, r := range m.Regions {
+		banks := fmt.Sprintf("%02X", r.FirstBank)
+		if r.LastBank != r.FirstBank {
+			banks = fmt.Sprintf("%02X-%02X", r.FirstBank, r.LastBank)
+		}
+		fmt.Fprintf(&b, "| %s | `%04X-%04X` | %s | %s |\n", banks, r.Start, r.End, r.Name, r.Description)
+	}
+
+	b.WriteString("\n## Port-accessed memories\n\n")
+	b.WriteString("| Memory | Size | Ports | Notes |\n|---|---|---|---|\n")
+	for _, pm := range m.PortMemories {
+		fmt.Fprintf(&b, "| %s | %d bytes | %s | %s |\n", pm.Name, pm.Size, pm.Ports, pm.Description)
+	}
+
+	b.WriteString("\n## I/O registers\n\n")
+	b.WriteString("Access: **R/W** reads back its value, **W** is write-only (reads 0), ")
+	b.WriteString("**port** acts on every access, **R** is read-only status. ")
+	b.WriteString("Fields are listed low bit first; a range after a field is the values the hardware defines.\n")
+	var groups []string
+	byGroup := map[string][]Register{}
+	for _, reg := range m.Registers {
+		if _, ok := byGroup[reg.Group]; !ok {
+			groups = append(groups, reg.Group)
+		}
+		byGroup[reg.Group] = append(byGroup[reg.Group], reg)
+	}
+	for _, group := range groups {
+		fmt.Fprintf(&b, "\n### %s\n\n", group)
+		b.WriteString("| Address | Register | Access | Fields | Notes |\n|---|---|---|---|---|\n")
+		for _, reg := range byGroup[group] {
+			b.WriteString(registerRow(reg))
+		}
+	}
+	return b.String()
+}
+
+// registerRow is reg's line in the register tables.
+func registerRow(reg Register) string {
+	fields := make([]string, len(reg.Fields))
+	for i, f := range reg.Fields {
+		bits := fmt.Sprintf("bit %d", f.Shift)
+		if f.Width > 1 {
+			bits = fmt.Sprintf("bits %d:%d", f.Shift+f.Width-1, f.Shift)
+		}
+		if f.Max != 0 {
+			bits += fmt.Sprintf(", 0-%d", f.Max)
+		}
+		fields[i] = fmt.Sprintf("%s (%s)", f.Name, bits)
+	}
+	return fmt.Sprintf("| `%04X` | `%s` | %s | %s | %s |\n", reg.Address, reg.Name, reg.Access, strings.Join(fields, ", "), reg.Note)
+}
@@ -0,0 +1,73 @@
+package hwdef
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+// The checked-in memory map docs must match the register table; regenerate
+// them with go run ./cmd/hwdoc after changing it.
+func TestMemoryMapDocsUpToDate(t *testing.T) {
+	m := Map()
+	data, err := m.JSON()
+	if err != nil {
+		t.Fatalf("JSON: %v", err)
+	}
+	for path, want := range map[string]string{
+		"../../docs/MEMORY_MAP.md":    m.Markdown(),
+		"../../docs/memory_map.json": string(data),
+	} {
+		got, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("read %s: %v", path, err)
+		}
+		if string(got) != want {
+			t.Errorf("%s is out of date; run go run ./cmd/hwdoc", path)
+		}
+	}
+}
+
+func TestMemoryMapJSONRoundTrip(t *testing.T) {
+	data, err := Map().JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back struct {
+		Regions   []Region `json:"regions"`
+		Registers []struct {
+			Name    string `json:"name"`
+			Address uint16 `json:"address"`
+			Access  string `json:"access"`
+			Fields  []Field
+		} `json:"registers"`
+	}
+	if err := json.Unmarshal(data, &back); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(back.Registers) != len(Registers()) {
+		t.Fatalf("%d registers in JSON, want %d", len(back.Registers), len(Registers()))
+	}
+	for _, reg := range back.Registers {
+		if reg.Name == "BG0_CONTROL" {
+			if reg.Address != 0x8008 || reg.Access != "R/W" || len(reg.Fields) == 0 {
+				t.Errorf("BG0_CONTROL in JSON = %+v", reg)
+			}
+		}
+	}
+
+	// Bank 0 regions tile 0x0000-0xFFFF without gaps or overlap.
+	next := 0
+	for _, r := range back.Regions {
+		if r.FirstBank != 0 {
+			continue
+		}
+		if int(r.Start) != next || r.End < r.Start {
+			t.Errorf("bank 0 region %s starts at 0x%04X, want 0x%04X", r.Name, r.Start, next)
+		}
+		next = int(r.End) + 1
+	}
+	if next != 0x10000 {
+		t.Errorf("bank 0 regions end at 0x%04X", next)
+	}
+}
@@ -0,0 +1,440 @@
+// Package hwdef defines the console's memory-mapped hardware registers:
+// their names, addresses, bit fields and valid ranges. The PPU and APU
+// register decoders, the bus, the emulator's register views, the debug
+// logger and CPU tracer, the assembler and the CoreLX compiler all read it,
+// so a register is added or moved here and nowhere else.
+package hwdef
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Access says how a register can be inspected without disturbing the
+// running program.
+type Access int
+
+const (
+	// ReadWrite registers read back their live value with no side effects.
+	ReadWrite Access = iota
+	// WriteOnly registers do not read back what was written (scroll, window
+	// and matrix registers read as 0).
+	WriteOnly
+	// Port registers change state when read (auto-incrementing data ports)
+	// or act on every write.
+	Port
+	// ReadOnly registers report status; writes are ignored or land on a
+	// different register that shares the address.
+	ReadOnly
+)
+
+func (a Access) String() string {
+	switch a {
+	case ReadWrite:
+		return "R/W"
+	case WriteOnly:
+		return "W"
+	case Port:
+		return "port"
+	case ReadOnly:
+		return "R"
+	default:
+		return fmt.Sprintf("Access(%d)", int(a))
+	}
+}
+
+// MarshalText encodes an Access as its String form, for the memory map
+// JSON.
+func (a Access) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// Register names one byte of bank 0 I/O.
+type Register struct {
+	Name    string `json:"name"`
+	Address uint16 `json:"address"`
+	Group   string `json:"group"`
+	Access  Access `json:"access"`
+	// Fields lists the register's bit fields, low bit first. Registers that
+	// hold a plain byte (or one half of a 16-bit value) have none.
+	Fields []Field `json:"fields,omitempty"`
+	// Note describes a side effect or meaning the name and fields do not
+	// show, for the memory map.
+	Note string `json:"note,omitempty"`
+}
+
+// Field is a run of bits within a register.
+type Field struct {
+	Name  string `json:"name"`
+	Shift uint8  `json:"shift"`
+	Width uint8  `json:"width"`
+	// Max is the largest value the hardware gives a meaning to, when that is
+	// below the field's full range (e.g. DMA_CONTROL's 3-bit destination has
+	// six targets). Zero means every value fits.
+	Max uint8 `json:"max,omitempty"`
+}
+
+// Mask returns the field's bits in position.
+func (f Field) Mask() uint8 {
+	return uint8((1<<f.Width)-1) << f.Shift
+}
+
+// Get extracts the field from a register value.
+func (f Field) Get(value uint8) uint8 {
+	return (value & f.Mask()) >> f.Shift
+}
+
+// Field returns the named bit field of the register.
+func (r Register) Field(name string) (Field, bool) {
+	for _, f := range r.Fields {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return Field{}, false
+}
+
+// Validate reports the first field of value that holds a setting the
+// hardware does not define.
+func (r Register) Validate(value uint8) error {
+	for _, f := range r.Fields {
+		if f.Max != 0 && f.Get(value) > f.Max {
+			return fmt.Errorf("%s: %s = %d, valid range is 0-%d", r.Name, f.Name, f.Get(value), f.Max)
+		}
+	}
+	return nil
+}
+
+var registers = buildRegisterTable()
+
+// Registers returns the named PPU, APU and input registers in address
+// order within each group. Addresses 0x803E-0x8040 appear twice (writes
+// reach BG2's matrix, reads return the VBlank flag and frame counter), as
+// does 0x8060 (DMA_CONTROL on write, DMA_STATUS on read).
+func Registers() []Register {
+	return append([]Register(nil), registers...)
+}
+
+// RegisterAt returns the register at a bank 0 I/O address. Where two share
+// an address, the one a write reaches is returned.
+func RegisterAt(addr uint16) (Register, bool) {
+	i, ok := byAddress[addr]
+	if !ok {
+		return Register{}, false
+	}
+	return registers[i], true
+}
+
+// RegisterByName looks a register up by its name (e.g. "OAM_ADDR"), ignoring
+// case.
+func RegisterByName(name string) (Register, bool) {
+	i, ok := byName[strings.ToUpper(name)]
+	if !ok {
+		return Register{}, false
+	}
+	return registers[i], true
+}
+
+// Symbolize names an I/O address for traces: "OAM_ADDR (0x8014)" for a
+// named register, otherwise the bare address.
+func Symbolize(addr uint16) string {
+	if reg, ok := RegisterAt(addr); ok {
+		return fmt.Sprintf("%s (0x%04X)", reg.Name, addr)
+	}
+	return fmt.Sprintf("0x%04X", addr)
+}
+
+func buildRegisterTable() []Register {
+	var regs []Register
+	add := func(group string, access Access, addr uint16, names ...string) {
+		for i, name := range names {
+			regs = append(regs, Register{Name: name, Address: addr + uint16(i), Group: group, Access: access})
+		}
+	}
+
+	scroll := [4]uint16{0x8000, 0x8004, 0x800A, 0x8022}
+	control := [4]uint16{0x8008, 0x8009, 0x8021, 0x8026}
+	for n := 0; n < 4; n++ {
+		bg := fmt.Sprintf("BG%d", n)
+		add(bg, WriteOnly, scroll[n], bg+"_SCROLLX_L", bg+"_SCROLLX_H", bg+"_SCROLLY_L", bg+"_SCROLLY_H")
+		add(bg, ReadWrite, control[n], bg+"_CONTROL")
+		add(bg, ReadWrite, 0x8068+uint16(n), bg+"_SOURCE_MODE")
+		add(bg, ReadWrite, 0x806C+uint16(n), bg+"_TRANSFORM_BIND")
+		add(bg, ReadWrite, 0x8077+uint16(n)*2, bg+"_TILEMAP_BASE_L", bg+"_TILEMAP_BASE_H")
+	}
+
+	add("Video ports", WriteOnly, 0x800E, "VRAM_ADDR_L", "VRAM_ADDR_H")
+	add("Video ports", Port, 0x8010, "VRAM_DATA")
+	add("Video ports", WriteOnly, 0x8012, "CGRAM_ADDR")
+	add("Video ports", Port, 0x8013, "CGRAM_DATA")
+	add("Video ports", WriteOnly, 0x8014, "OAM_ADDR")
+	add("Video ports", Port, 0x8015, "OAM_DATA")
+
+	matrix := [4]uint16{0x8018, 0x802B, 0x8038, 0x8045}
+	center := [4]uint16{0x8027, 0x8034, 0x8041, 0x804E}
+	for n := 0; n < 4; n++ {
+		prefix := "MATRIX_"
+		if n > 0 {
+			prefix = fmt.Sprintf("BG%d_MATRIX_", n)
+		}
+		group := fmt.Sprintf("BG%d matrix", n)
+		add(group, ReadWrite, matrix[n], prefix+"CONTROL")
+		add(group, WriteOnly, matrix[n]+1,
+			prefix+"A_L", prefix+"A_H", prefix+"B_L", prefix+"B_H",
+			prefix+"C_L", prefix+"C_H", prefix+"D_L", prefix+"D_H")
+		add(group, WriteOnly, center[n], prefix+"CENTER_X_L", prefix+"CENTER_X_H", prefix+"CENTER_Y_L", prefix+"CENTER_Y_H")
+	}
+
+	add("Windows", WriteOnly, 0x8052,
+		"WINDOW0_LEFT", "WINDOW0_RIGHT", "WINDOW0_TOP", "WINDOW0_BOTTOM",
+		"WINDOW1_LEFT", "WINDOW1_RIGHT", "WINDOW1_TOP", "WINDOW1_BOTTOM",
+		"WINDOW_CONTROL", "WINDOW_MAIN_ENABLE", "WINDOW_SUB_ENABLE")
+
+	add("DMA", ReadWrite, 0x805D, "HDMA_CONTROL", "HDMA_TABLE_BASE_L", "HDMA_TABLE_BASE_H")
+	add("DMA", ReadWrite, 0x807F, "HDMA_EXTENSION_CONTROL")
+	// Reads of 0x8060 return DMA_STATUS, not the control byte.
+	add("DMA", WriteOnly, 0x8060, "DMA_CONTROL")
+	add("DMA", ReadOnly, 0x8060, "DMA_STATUS")
+	add("DMA", ReadWrite, 0x8061,
+		"DMA_SOURCE_BANK", "DMA_SOURCE_OFFSET_L", "DMA_SOURCE_OFFSET_H",
+		"DMA_DEST_ADDR_L", "DMA_DEST_ADDR_H", "DMA_LENGTH_L", "DMA_LENGTH_H")
+
+	add("Text", WriteOnly, 0x8070, "TEXT_X_L", "TEXT_X_H", "TEXT_Y", "TEXT_COLOR_R", "TEXT_COLOR_G", "TEXT_COLOR_B")
+	add("Text", Port, 0x8076, "TEXT_CHAR")
+
+	add("Matrix planes", ReadWrite, 0x8080,
+		"MATRIX_PLANE_SELECT", "MATRIX_PLANE_CONTROL", "MATRIX_PLANE_ADDR_L", "MATRIX_PLANE_ADDR_H")
+	add("Matrix planes", Port, 0x8084, "MATRIX_PLANE_DATA")
+	add("Matrix planes", ReadWrite, 0x8085, "MATRIX_PLANE_PATTERN_ADDR_L", "MATRIX_PLANE_PATTERN_ADDR_H")
+	add("Matrix planes", Port, 0x8087, "MATRIX_PLANE_PATTERN_DATA")
+	add("Matrix planes", ReadWrite, 0x8088,
+		"MATRIX_PLANE_BITMAP_ADDR_L", "MATRIX_PLANE_BITMAP_ADDR_M", "MATRIX_PLANE_BITMAP_ADDR_H")
+	add("Matrix planes", Port, 0x808B, "MATRIX_PLANE_BITMAP_DATA")
+	add("Matrix planes", ReadWrite, 0x808C,
+		"MATRIX_PLANE_FLAGS", "MATRIX_PLANE_ROW_CONTROL", "MATRIX_PLANE_ROW_ADDR_L", "MATRIX_PLANE_ROW_ADDR_H")
+	add("Matrix planes", Port, 0x8090, "MATRIX_PLANE_ROW_DATA")
+	add("Matrix planes", ReadWrite, 0x8091,
+		"MATRIX_PLANE_PROJECTION_CONTROL", "MATRIX_PLANE_HORIZON",
+		"MATRIX_PLANE_CAMERA_X_L", "MATRIX_PLANE_CAMERA_X_H", "MATRIX_PLANE_CAMERA_Y_L", "MATRIX_PLANE_CAMERA_Y_H",
+		"MATRIX_PLANE_HEADING_X_L", "MATRIX_PLANE_HEADING_X_H", "MATRIX_PLANE_HEADING_Y_L", "MATRIX_PLANE_HEADING_Y_H",
+		"MATRIX_PLANE_BASE_DISTANCE_L", "MATRIX_PLANE_BASE_DISTANCE_H",
+		"MATRIX_PLANE_FOCAL_LENGTH_L", "MATRIX_PLANE_FOCAL_LENGTH_H",
+		"MATRIX_PLANE_WIDTH_SCALE_L", "MATRIX_PLANE_WIDTH_SCALE_H",
+		"MATRIX_PLANE_ORIGIN_X_L", "MATRIX_PLANE_ORIGIN_X_H", "MATRIX_PLANE_ORIGIN_Y_L", "MATRIX_PLANE_ORIGIN_Y_H",
+		"MATRIX_PLANE_FACING_X_L", "MATRIX_PLANE_FACING_X_H", "MATRIX_PLANE_FACING_Y_L", "MATRIX_PLANE_FACING_Y_H",
+		"MATRIX_PLANE_HEIGHT_SCALE_L", "MATRIX_PLANE_HEIGHT_SCALE_H")
+
+	// BORDER_COLOR is the CGRAM index (palette*16 + color) of the border
+	// hosts may draw outside the 320x200 active area.
+	add("Display", ReadWrite, 0x80AB, "BORDER_COLOR")
+	// BG0_LINE_SCROLL is the WRAM address of a 200-entry table of signed
+	// 16-bit offsets added to BG0's horizontal scroll, one per scanline;
+	// 0x0000 turns it off.
+	add("BG0", ReadWrite, 0x80AC, "BG0_LINE_SCROLL_L", "BG0_LINE_SCROLL_H")
+
+	// Reading VBLANK_FLAG clears it.
+	add("System", Port, 0x803E, "VBLANK_FLAG")
+	add("System", ReadOnly, 0x803F, "FRAME_COUNTER_L", "FRAME_COUNTER_H")
+
+	for n := 0; n < 4; n++ {
+		ch := fmt.Sprintf("CH%d", n)
+		add("APU channels", ReadWrite, 0x9000+uint16(n)*8,
+			ch+"_FREQ_LOW", ch+"_FREQ_HIGH", ch+"_VOLUME", ch+"_CONTROL",
+			ch+"_DURATION_LOW", ch+"_DURATION_HIGH", ch+"_DURATION_MODE")
+	}
+	add("APU channels", ReadWrite, 0x9020, "MASTER_VOLUME")
+	// Reading COMPLETION_STATUS clears it.
+	add("APU channels", Port, 0x9021, "COMPLETION_STATUS")
+	// The sample channel reads 8-bit PCM straight from memory; see
+	// apu.SampleChannel.
+	add("Sample channel", ReadWrite, 0x9028,
+		"SAMPLE_BANK", "SAMPLE_ADDR_L", "SAMPLE_ADDR_H", "SAMPLE_LENGTH_L", "SAMPLE_LENGTH_H",
+		"SAMPLE_RATE_L", "SAMPLE_RATE_H", "SAMPLE_VOLUME")
+	// Writing SAMPLE_CONTROL starts or stops playback.
+	add("Sample channel", Port, 0x9030, "SAMPLE_CONTROL")
+	// The master low-pass filter and echo; see apu.MasterEffects.
+	add("Master effects", ReadWrite, 0x9031, "LOWPASS", "ECHO_DELAY", "ECHO_FEEDBACK", "ECHO_MIX")
+
+	add("FM", ReadWrite, 0x9100, "FM_ADDR")
+	add("FM", Port, 0x9101, "FM_DATA")
+	add("FM", ReadOnly, 0x9102, "FM_STATUS")
+	add("FM", ReadWrite, 0x9103, "FM_CONTROL", "FM_PORT1_ADDR")
+	add("FM", Port, 0x9105, "FM_PORT1_DATA")
+	add("FM", ReadWrite, 0x9106, "FM_VOLUME")
+	// The burst streamer lives on the bus, not the APU: it copies
+	// (port, addr, data) triplets from ROM into the FM ports.
+	add("FM", WriteOnly, 0x9110,
+		"YM_BURST_COUNT_L", "YM_BURST_COUNT_H", "YM_BURST_BANK", "YM_BURST_OFFSET_L", "YM_BURST_OFFSET_H")
+	add("FM", Port, 0x9115, "YM_BURST_TRIGGER")
+
+	add("Input", ReadOnly, 0xA000, "CONTROLLER1_L")
+	add("Input", ReadWrite, 0xA001, "CONTROLLER1_H")
+	add("Input", ReadOnly, 0xA002, "CONTROLLER2_L")
+	add("Input", ReadWrite, 0xA003, "CONTROLLER2_H")
+	// The persistence mailbox is a host-side key/value store on the bus,
+	// not cartridge SRAM. Key bytes are appended one per write.
+	add("Persist", Port, 0xA010, "PERSIST_KEY")
+	add("Persist", ReadWrite, 0xA011, "PERSIST_VALUE_L", "PERSIST_VALUE_H")
+	add("Persist", Port, 0xA013, "PERSIST_CONTROL")
+	add("System", ReadOnly, 0xA020, "RESET_CAUSE")
+	// The test port exists only when the emulator runs in test mode; test
+	// ROMs use it to report assertions, debug text and an exit status.
+	add("Test", ReadWrite, 0xA030, "TEST_VALUE_L", "TEST_VALUE_H", "TEST_EXPECT_L", "TEST_EXPECT_H")
+	add("Test", Port, 0xA034, "TEST_COMMAND")
+	// The debug console collects printf-style output for the host. It is
+	// write-only: writes to DEBUG_CHAR and DEBUG_PRINT append text.
+	add("Debug", Port, 0xA040, "DEBUG_CHAR")
+	add("Debug", WriteOnly, 0xA041, "DEBUG_VALUE_L", "DEBUG_VALUE_H")
+	add("Debug", Port, 0xA043, "DEBUG_PRINT")
+
+	setFields := func(name string, fields ...Field) {
+		for i := range regs {
+			if regs[i].Name == name {
+				regs[i].Fields = fields
+				return
+			}
+		}
+		panic("hwdef: no register " + name)
+	}
+	for n := 0; n < 4; n++ {
+		bg := fmt.Sprintf("BG%d", n)
+		setFields(bg+"_CONTROL",
+			Field{Name: "ENABLE", Shift: 0, Width: 1},
+			Field{Name: "TILE_16X16", Shift: 1, Width: 1},
+			Field{Name: "PRIORITY", Shift: 2, Width: 2},
+			Field{Name: "TILEMAP_SIZE", Shift: 4, Width: 2, Max: 2})
+		setFields(bg+"_SOURCE_MODE",
+			Field{Name: "BITMAP", Shift: 0, Width: 1},
+			Field{Name: "FORMAT", Shift: 1, Width: 2, Max: 2})
+		setFields(bg+"_TRANSFORM_BIND", Field{Name: "CHANNEL", Shift: 0, Width: 2})
+
+		prefix := "MATRIX_"
+		if n > 0 {
+			prefix = bg + "_MATRIX_"
+		}
+		setFields(prefix+"CONTROL",
+			Field{Name: "ENABLE", Shift: 0, Width: 1},
+			Field{Name: "MIRROR_H", Shift: 1, Width: 1},
+			Field{Name: "MIRROR_V", Shift: 2, Width: 1},
+			Field{Name: "OUTSIDE_MODE", Shift: 3, Width: 2},
+			Field{Name: "DIRECT_COLOR", Shift: 5, Width: 1})
+	}
+	setFields("HDMA_CONTROL",
+		Field{Name: "ENABLE", Shift: 0, Width: 1},
+		Field{Name: "LAYERS", Shift: 1, Width: 4},
+		Field{Name: "REBIND_TABLE", Shift: 5, Width: 1},
+		Field{Name: "PRIORITY_TABLE", Shift: 6, Width: 1},
+		Field{Name: "TILEMAP_BASE_TABLE", Shift: 7, Width: 1})
+	// Destinations: VRAM, CGRAM, OAM, then the matrix plane's tilemap,
+	// pattern and bitmap memories.
+	setFields("DMA_CONTROL",
+		Field{Name: "START", Shift: 0, Width: 1},
+		Field{Name: "FILL", Shift: 1, Width: 1},
+		Field{Name: "DEST", Shift: 2, Width: 3, Max: 5})
+	setFields("DMA_STATUS", Field{Name: "ACTIVE", Shift: 0, Width: 1})
+	setFields("VBLANK_FLAG", Field{Name: "VBLANK", Shift: 0, Width: 1})
+	setFields("MATRIX_PLANE_SELECT", Field{Name: "PLANE", Shift: 0, Width: 2})
+	setFields("MATRIX_PLANE_CONTROL",
+		Field{Name: "ENABLE", Shift: 0, Width: 1},
+		Field{Name: "SIZE", Shift: 1, Width: 2, Max: 2},
+		Field{Name: "BITMAP", Shift: 3, Width: 1},
+		Field{Name: "PALETTE", Shift: 4, Width: 4})
+	for n := 0; n < 4; n++ {
+		ch := fmt.Sprintf("CH%d", n)
+		// Channels 0-2 pick one of four waveforms; channel 3 is square or noise.
+		wave := Field{Name: "WAVEFORM", Shift: 1, Width: 2}
+		if n == 3 {
+			wave = Field{Name: "NOISE", Shift: 1, Width: 1}
+		}
+		setFields(ch+"_CONTROL",
+			Field{Name: "ENABLE", Shift: 0, Width: 1},
+			wave,
+			Field{Name: "PCM", Shift: 4, Width: 1})
+		setFields(ch+"_DURATION_MODE", Field{Name: "LOOP", Shift: 0, Width: 1})
+	}
+	setFields("COMPLETION_STATUS", Field{Name: "CHANNELS", Shift: 0, Width: 4})
+	setFields("SAMPLE_CONTROL",
+		Field{Name: "PLAY", Shift: 0, Width: 1},
+		Field{Name: "LOOP", Shift: 1, Width: 1})
+	setFields("YM_BURST_TRIGGER", Field{Name: "START", Shift: 0, Width: 1})
+	// These are the write commands. Reads return bit 0 FOUND (the last
+	// LOAD hit a saved key) and bit 7 AVAILABLE (the store is enabled).
+	setFields("PERSIST_CONTROL",
+		Field{Name: "LOAD", Shift: 0, Width: 1},
+		Field{Name: "STORE", Shift: 1, Width: 1})
+	// Writes are TestCmd* codes, not bits. Reads return bit 0 FAILED, bit
+	// 1 EXITED and bit 7 AVAILABLE (test mode is on).
+	setFields("TEST_COMMAND",
+		Field{Name: "FAILED", Shift: 0, Width: 1},
+		Field{Name: "EXITED", Shift: 1, Width: 1},
+		Field{Name: "AVAILABLE", Shift: 7, Width: 1})
+
+	setNote := func(name, note string) {
+		for i := range regs {
+			if regs[i].Name == name {
+				regs[i].Note = note
+				return
+			}
+		}
+		panic("hwdef: no register " + name)
+	}
+	setNote("VRAM_DATA", "Each read or write moves VRAM_ADDR on by one.")
+	setNote("CGRAM_ADDR", "Color index (0-255); writing it restarts the CGRAM_DATA byte pair.")
+	setNote("CGRAM_DATA", "Write the RGB555 color low byte then high byte; the second write stores it and moves CGRAM_ADDR on by one. Reads return 0.")
+	setNote("OAM_ADDR", "Sprite index (0-127); writing it restarts at the sprite's first byte. Ignored during active display.")
+	setNote("OAM_DATA", "Each access moves through the sprite's 6 bytes, then to the next sprite. Writes are ignored during active display.")
+	setNote("DMA_CONTROL", "Writes only; reads of 0x8060 return DMA_STATUS. DEST: 0 VRAM, 1 CGRAM, 2 OAM, 3 matrix plane tilemap, 4 pattern, 5 bitmap.")
+	setNote("DMA_STATUS", "Reads only; writes to 0x8060 reach DMA_CONTROL.")
+	setNote("TEXT_CHAR", "Writing draws the character at TEXT_X, TEXT_Y in the text color and moves TEXT_X on by 8.")
+	setNote("BORDER_COLOR", "CGRAM index (palette*16 + color) of the border hosts may draw outside the 320x200 active area.")
+	setNote("BG0_LINE_SCROLL_L", "With BG0_LINE_SCROLL_H, the WRAM address of a 200-entry table of signed 16-bit horizontal scroll offsets, one per scanline; 0x0000 turns it off.")
+	setNote("VBLANK_FLAG", "Reading it clears it. Writes to 0x803E reach BG2's matrix.")
+	setNote("FRAME_COUNTER_L", "Reads only; writes to 0x803F-0x8040 reach BG2's matrix.")
+	setNote("COMPLETION_STATUS", "One bit per channel whose duration ran out; reading it clears it.")
+	setNote("SAMPLE_CONTROL", "Writing it starts or stops playback of the PCM at SAMPLE_BANK:SAMPLE_ADDR.")
+	setNote("YM_BURST_TRIGGER", "Copies YM_BURST_COUNT (port, addr, data) triplets from ROM at YM_BURST_BANK:YM_BURST_OFFSET into the FM ports.")
+	setNote("PERSIST_KEY", "Key bytes are appended one per write. The mailbox is a host-side key/value store, not cartridge SRAM.")
+	setNote("PERSIST_CONTROL", "Reads return bit 0 FOUND (the last LOAD hit a saved key) and bit 7 AVAILABLE (the store is enabled).")
+	setNote("RESET_CAUSE", "0 power-on, 1 soft reset (RAM kept), 2 hard reset (RAM filled with the RAM pattern).")
+	setNote("TEST_COMMAND", "Test mode only. Writes are commands: 1 assert TEST_VALUE == TEST_EXPECT, 2 print TEST_VALUE_L, 3 exit with status TEST_VALUE.")
+	setNote("DEBUG_CHAR", "Writing appends the character to the debug console.")
+	setNote("DEBUG_PRINT", "Writing appends DEBUG_VALUE: 0 unsigned, 1 signed, 2 hex, 3 8.8 fixed point, 4 bool.")
+	return regs
+}
+
+var byAddress, byName = indexRegisters()
+
+func indexRegisters() (map[uint16]int, map[string]int) {
+	addrs := make(map[uint16]int, len(registers))
+	names := make(map[string]int, len(registers))
+	for i, reg := range registers {
+		if _, dup := addrs[reg.Address]; !dup {
+			addrs[reg.Address] = i
+		}
+		names[reg.Name] = i
+	}
+	return addrs, names
+}
+
+// RESET_CAUSE values: how the CPU last came to start at the ROM entry point.
+const (
+	ResetCausePowerOn = 0 // cold boot: RAM zeroed unless a RAM pattern is set
+	ResetCauseSoft    = 1 // reset button: RAM, VRAM and the cartridge kept
+	ResetCauseHard    = 2 // power cycle: RAM and VRAM filled with the RAM pattern
+)
+
+// TEST_COMMAND codes.
+const (
+	TestCmdAssertEqual = 1 // record a failure unless TEST_VALUE == TEST_EXPECT
+	TestCmdPrintChar   = 2 // print the character in TEST_VALUE_L
+	TestCmdExit        = 3 // end the test with status TEST_VALUE (0 = pass)
+)
+
+// DEBUG_PRINT formats: how DEBUG_VALUE is appended to the debug console.
+const (
+	DebugPrintUnsigned = 0 // decimal, 0..65535
+	DebugPrintSigned   = 1 // decimal, -32768..32767
+	DebugPrintHex      = 2 // 0x%04X
+	DebugPrintFixed    = 3 // signed 8.8 fixed point
+	DebugPrintBool     = 4 // false for 0, true otherwise
+)
@@ -0,0 +1,125 @@
+package hwdef
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"testing"
+)
+
+func TestRegisterTable(t *testing.T) {
+	names := map[string]bool{}
+	byAddr := map[uint16]int{}
+	for _, reg := range Registers() {
+		if names[reg.Name] {
+			t.Errorf("duplicate register name %s", reg.Name)
+		}
+		names[reg.Name] = true
+		if reg.Address < 0x8000 || reg.Address >= 0xB000 {
+			t.Errorf("%s at 0x%04X is outside I/O space", reg.Name, reg.Address)
+		}
+		byAddr[reg.Address]++
+	}
+	for addr, n := range byAddr {
+		if n > 1 && (addr < 0x803E || addr > 0x8040) && addr != 0x8060 {
+			t.Errorf("0x%04X is listed %d times", addr, n)
+		}
+	}
+	for _, name := range []string{"BG0_CONTROL", "CGRAM_ADDR", "CH0_FREQ_LOW", "OAM_DATA", "FM_VOLUME", "BG3_MATRIX_CENTER_Y_H"} {
+		if !names[name] {
+			t.Errorf("missing %s", name)
+		}
+	}
+}
+
+func TestRegisterLookup(t *testing.T) {
+	if reg, ok := RegisterAt(0x8014); !ok || reg.Name != "OAM_ADDR" {
+		t.Fatalf("RegisterAt(0x8014) = %+v, %v", reg, ok)
+	}
+	// The shared address reports the register writes reach.
+	if reg, _ := RegisterAt(0x803F); reg.Name != "BG2_MATRIX_D_L" {
+		t.Errorf("RegisterAt(0x803F) = %s", reg.Name)
+	}
+	if reg, ok := RegisterByName("ch0_control"); !ok || reg.Address != 0x9003 {
+		t.Errorf("RegisterByName(ch0_control) = %+v, %v", reg, ok)
+	}
+	if got := Symbolize(0x9003); got != "CH0_CONTROL (0x9003)" {
+		t.Errorf("Symbolize(0x9003) = %q", got)
+	}
+	if got := Symbolize(0x8FFF); got != "0x8FFF" {
+		t.Errorf("Symbolize(0x8FFF) = %q", got)
+	}
+}
+
+// TestAddressConstants keeps addresses.go and the register table in step:
+// every register has a constant of the same name and address, and every
+// register constant names a register.
+func TestAddressConstants(t *testing.T) {
+	file, err := parser.ParseFile(token.NewFileSet(), "addresses.go", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	consts := map[string]uint64{}
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			vs := spec.(*ast.ValueSpec)
+			lit, ok := vs.Values[0].(*ast.BasicLit)
+			if !ok {
+				t.Fatalf("%s: want a literal address", vs.Names[0].Name)
+			}
+			v, err := strconv.ParseUint(lit.Value, 0, 16)
+			if err != nil {
+				t.Fatalf("%s: %v", vs.Names[0].Name, err)
+			}
+			consts[vs.Names[0].Name] = v
+		}
+	}
+
+	for _, reg := range Registers() {
+		v, ok := consts[reg.Name]
+		if !ok {
+			t.Errorf("no constant for %s", reg.Name)
+		} else if v != uint64(reg.Address) {
+			t.Errorf("%s = 0x%04X, table has 0x%04X", reg.Name, v, reg.Address)
+		}
+		delete(consts, reg.Name)
+	}
+	for _, region := range []string{"IOBase", "PPUBase", "APUBase", "FMBase", "InputBase", "IOEnd"} {
+		delete(consts, region)
+	}
+	for name := range consts {
+		t.Errorf("constant %s names no register", name)
+	}
+}
+
+func TestRegisterFields(t *testing.T) {
+	reg, _ := RegisterByName("BG1_CONTROL")
+	prio, ok := reg.Field("PRIORITY")
+	if !ok || prio.Mask() != 0x0C || prio.Get(0x2D) != 3 {
+		t.Errorf("BG1_CONTROL PRIORITY = %+v", prio)
+	}
+	if err := reg.Validate(0x21); err != nil {
+		t.Errorf("Validate(0x21) = %v", err)
+	}
+	if err := reg.Validate(0x31); err == nil {
+		t.Error("tilemap size 3 should be out of range")
+	}
+
+	for _, r := range Registers() {
+		var used uint8
+		for _, f := range r.Fields {
+			if f.Width == 0 || int(f.Shift)+int(f.Width) > 8 {
+				t.Errorf("%s.%s does not fit in a byte", r.Name, f.Name)
+			}
+			if used&f.Mask() != 0 {
+				t.Errorf("%s.%s overlaps another field", r.Name, f.Name)
+			}
+			used |= f.Mask()
+		}
+	}
+}
@@ -0,0 +1,150 @@
+// Package i18n is the message catalog for the Dev Kit and emulator UI.
+//
+// Messages are keyed by their English text, gettext style: code writes
+// i18n.T("Open ROM...") and gets the English string back until a locale with
+// a translation is selected. Translations live in locales/<tag>.json, one
+// file per language, embedded in the binary. A message missing from the
+// selected catalog falls back to English, so an incomplete translation
+// never blanks a label.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// English is the source locale every message is written in.
+const English = "en"
+
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+// catalog is one locales/<tag>.json file.
+type catalog struct {
+	// Name is the language's own name, shown in locale pickers.
+	Name     string            `json:"name"`
+	Messages map[string]string `json:"messages"`
+}
+
+var (
+	catalogs = loadCatalogs()
+
+	mu      sync.RWMutex
+	current = English
+)
+
+func loadCatalogs() map[string]catalog {
+	all := map[string]catalog{English: {Name: "English"}}
+	files, err := localeFiles.ReadDir("locales")
+	if err != nil {
+		panic(err)
+	}
+	for _, f := range files {
+		data, err := localeFiles.ReadFile("locales/" + f.Name())
+		if err != nil {
+			panic(err)
+		}
+		var c catalog
+		if err := json.Unmarshal(data, &c); err != nil {
+			panic(fmt.Sprintf("i18n: %s: %v", f.Name(), err))
+		}
+		all[strings.TrimSuffix(f.Name(), path.Ext(f.Name()))] = c
+	}
+	return all
+}
+
+// T returns msg in the current locale. With args, the translated text is a
+// fmt format and args fill its verbs.
+func T(msg string, args ...any) string {
+	mu.RLock()
+	tag := current
+	mu.RUnlock()
+	if translated, ok := catalogs[tag].Messages[msg]; ok && translated != "" {
+		msg = translated
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(msg, args...)
+	}
+	return msg
+}
+
+// SetLocale selects the catalog T reads. tag may be a bare language ("es")
+// or a POSIX locale ("es_MX.UTF-8"); only the language part is used. An
+// empty tag selects English.
+func SetLocale(tag string) error {
+	lang := Normalize(tag)
+	if _, ok := catalogs[lang]; !ok {
+		return fmt.Errorf("unknown locale %q (available: %s)", tag, strings.Join(Locales(), ", "))
+	}
+	mu.Lock()
+	current = lang
+	mu.Unlock()
+	return nil
+}
+
+// Locale returns the selected locale's tag.
+func Locale() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// Locales returns the tags of the available catalogs, English first.
+func Locales() []string {
+	tags := make([]string, 0, len(catalogs))
+	for tag := range catalogs {
+		if tag != English {
+			tags = append(tags, tag)
+		}
+	}
+	sort.Strings(tags)
+	return append([]string{English}, tags...)
+}
+
+// Name returns the language's own name for tag ("Español" for "es"), or
+// tag itself when there is no such catalog.
+func Name(tag string) string {
+	if c, ok := catalogs[tag]; ok && c.Name != "" {
+		return c.Name
+	}
+	return tag
+}
+
+// Normalize reduces a locale string to its lowercase language tag:
+// "es_MX.UTF-8" and "es-MX" become "es". "C" and "POSIX" mean English.
+func Normalize(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, ".@"); i >= 0 {
+		tag = tag[:i]
+	}
+	if i := strings.IndexAny(tag, "_-"); i >= 0 {
+		tag = tag[:i]
+	}
+	if tag == "" || tag == "c" || tag == "posix" {
+		return English
+	}
+	return tag
+}
+
+// Detect returns the language tag the environment asks for: NCDX_LANG, then
+// the POSIX LC_ALL, LC_MESSAGES and LANG variables. It returns English when
+// none names an available catalog.
+func Detect() string {
+	for _, name := range []string{"NCDX_LANG", "LC_ALL", "LC_MESSAGES", "LANG"} {
+		v := os.Getenv(name)
+		if v == "" {
+			continue
+		}
+		if lang := Normalize(v); catalogs[lang].Name != "" {
+			return lang
+		}
+		return English
+	}
+	return English
+}
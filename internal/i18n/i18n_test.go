@@ -0,0 +1,145 @@
+package i18n
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// uiSourceDirs are the packages whose i18n.T messages every catalog must
+// translate.
+var uiSourceDirs = []string{"../../cmd/corelx_devkit", "../../cmd/emulator", "../ui"}
+
+func TestTranslateAndFallBack(t *testing.T) {
+	defer SetLocale(English)
+	if got := T("Open ROM..."); got != "Open ROM..." {
+		t.Errorf("English T = %q", got)
+	}
+	if err := SetLocale("es_MX.UTF-8"); err != nil {
+		t.Fatal(err)
+	}
+	if Locale() != "es" {
+		t.Fatalf("Locale() = %q, want es", Locale())
+	}
+	if got := T("Open ROM..."); got != "Abrir ROM..." {
+		t.Errorf("T(Open ROM...) = %q", got)
+	}
+	if got := T("Loaded ROM: %s", "demo.rom"); got != "ROM cargada: demo.rom" {
+		t.Errorf("T with args = %q", got)
+	}
+	if got := T("Not in any catalog %d", 3); got != "Not in any catalog 3" {
+		t.Errorf("missing message = %q, want the English fallback", got)
+	}
+}
+
+func TestSetLocaleRejectsUnknown(t *testing.T) {
+	defer SetLocale(English)
+	if err := SetLocale("xx"); err == nil || !strings.Contains(err.Error(), "available: en, es") {
+		t.Errorf("SetLocale(xx) err = %v", err)
+	}
+	if Locale() != English {
+		t.Errorf("failed SetLocale changed the locale to %q", Locale())
+	}
+	if err := SetLocale(""); err != nil || Locale() != English {
+		t.Errorf("SetLocale(\"\") = %v, locale %q; want English", err, Locale())
+	}
+}
+
+func TestDetect(t *testing.T) {
+	for _, tc := range []struct{ ncdx, lcAll, lang, want string }{
+		{"", "", "es_ES.UTF-8", "es"},
+		{"", "C", "es_ES.UTF-8", English},
+		{"es", "en_US.UTF-8", "", "es"},
+		{"", "", "fr_FR.UTF-8", English},
+		{"", "", "", English},
+	} {
+		t.Setenv("NCDX_LANG", tc.ncdx)
+		t.Setenv("LC_ALL", tc.lcAll)
+		t.Setenv("LC_MESSAGES", "")
+		t.Setenv("LANG", tc.lang)
+		if got := Detect(); got != tc.want {
+			t.Errorf("NCDX_LANG=%q LC_ALL=%q LANG=%q: Detect() = %q, want %q", tc.ncdx, tc.lcAll, tc.lang, got, tc.want)
+		}
+	}
+}
+
+var printfVerb = regexp.MustCompile(`%[-+# 0]*[0-9]*(?:\.[0-9]+)?[a-zA-Z%]`)
+
+func TestCatalogVerbsMatch(t *testing.T) {
+	for tag, c := range catalogs {
+		for msg, translated := range c.Messages {
+			want := printfVerb.FindAllString(msg, -1)
+			if got := printfVerb.FindAllString(translated, -1); !slices.Equal(got, want) {
+				t.Errorf("%s: %q has verbs %q, want %q", tag, translated, got, want)
+			}
+		}
+	}
+}
+
+// TestCatalogsCoverUIStrings keeps every catalog in step with the source:
+// each i18n.T message in the UI packages must be translated, and each
+// translation must still be used.
+func TestCatalogsCoverUIStrings(t *testing.T) {
+	used := map[string]bool{}
+	for _, dir := range uiSourceDirs {
+		files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, path := range files {
+			if strings.HasSuffix(path, "_test.go") {
+				continue
+			}
+			f, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ast.Inspect(f, func(n ast.Node) bool {
+				call, ok := n.(*ast.CallExpr)
+				if !ok || len(call.Args) == 0 {
+					return true
+				}
+				sel, ok := call.Fun.(*ast.SelectorExpr)
+				if !ok || sel.Sel.Name != "T" {
+					return true
+				}
+				if pkg, ok := sel.X.(*ast.Ident); !ok || pkg.Name != "i18n" {
+					return true
+				}
+				lit, ok := call.Args[0].(*ast.BasicLit)
+				if !ok || lit.Kind != token.STRING {
+					t.Errorf("%s: i18n.T needs a string literal message", path)
+					return true
+				}
+				msg, _ := strconv.Unquote(lit.Value)
+				used[msg] = true
+				return true
+			})
+		}
+	}
+	if len(used) == 0 {
+		t.Fatal("no i18n.T messages found in the UI sources")
+	}
+	for _, tag := range Locales() {
+		if tag == English {
+			continue
+		}
+		messages := catalogs[tag].Messages
+		for msg := range used {
+			if messages[msg] == "" {
+				t.Errorf("%s: no translation for %q", tag, msg)
+			}
+		}
+		for msg := range messages {
+			if !used[msg] {
+				t.Errorf("%s: %q is no longer used", tag, msg)
+			}
+		}
+	}
+}
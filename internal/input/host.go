@@ -0,0 +1,177 @@
+package input
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// maxMacroFrames bounds a recorded macro (one minute at 60 FPS).
+const maxMacroFrames = 3600
+
+// buttonNames maps the names used by -turbo and the Dev Kit settings to
+// controller 1 button bits.
+var buttonNames = map[string]uint16{
+	"up": 1 << ButtonUP, "down": 1 << ButtonDOWN, "left": 1 << ButtonLEFT, "right": 1 << ButtonRIGHT,
+	"a": 1 << ButtonA, "b": 1 << ButtonB, "x": 1 << ButtonX, "y": 1 << ButtonY,
+	"l": 1 << ButtonL, "r": 1 << ButtonR, "start": 1 << ButtonSTART, "z": 1 << ButtonZ,
+}
+
+// ParseButtons parses a comma-separated list of button names ("a,b,start")
+// into a button mask. An empty list is 0.
+func ParseButtons(list string) (uint16, error) {
+	var mask uint16
+	for _, name := range strings.Split(list, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		bit, ok := buttonNames[name]
+		if !ok {
+			return 0, fmt.Errorf("unknown button %q (want up, down, left, right, a, b, x, y, l, r, start, z)", name)
+		}
+		mask |= bit
+	}
+	return mask, nil
+}
+
+// HostInput is the host-side input layer between the keyboard (or virtual
+// pad) and the emulated controller: per-button turbo and a recordable
+// button macro. Frontends call Frame once per emulated frame with the
+// buttons held and feed the result to the emulator. It never touches
+// emulated state, so it is not part of save states. It is safe for use
+// from a UI goroutine and the emulation goroutine at once.
+type HostInput struct {
+	mu sync.Mutex
+
+	turboMask uint16
+	turboHz   int
+
+	frame     uint64
+	replay    uint16 // this frame's macro buttons
+	recording bool
+	macro     []uint16
+	playing   []uint16
+}
+
+// NewHostInput returns a HostInput with turbo off and a 15 Hz turbo rate.
+func NewHostInput() *HostInput {
+	return &HostInput{turboHz: 15}
+}
+
+// SetTurbo makes the buttons in mask auto-fire at hz presses per second
+// (clamped to 1-30) while held. A zero mask turns turbo off.
+func (h *HostInput) SetTurbo(mask uint16, hz int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.turboMask = mask
+	h.turboHz = min(max(hz, 1), 30)
+}
+
+// Turbo returns the turbo buttons and rate.
+func (h *HostInput) Turbo() (mask uint16, hz int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.turboMask, h.turboHz
+}
+
+// Frame advances one emulated frame. held is what the player is pressing;
+// the result is what the controller reports: turbo buttons pulse, a
+// playing macro is ORed in, and a recording macro captures held.
+func (h *HostInput) Frame(held uint16) uint16 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.frame++
+	if h.recording && len(h.macro) < maxMacroFrames {
+		h.macro = append(h.macro, held)
+	}
+	h.replay = 0
+	if len(h.playing) > 0 {
+		h.replay = h.playing[0]
+		h.playing = h.playing[1:]
+	}
+	return h.applyLocked(held)
+}
+
+// Apply filters held through the current frame's turbo phase and macro
+// step without advancing, for hosts that re-sample input mid-frame.
+func (h *HostInput) Apply(held uint16) uint16 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.applyLocked(held)
+}
+
+func (h *HostInput) applyLocked(held uint16) uint16 {
+	if h.turboMask != 0 && !h.turboOn() {
+		held &^= h.turboMask
+	}
+	return held | h.replay
+}
+
+// turboOn reports whether turbo buttons are down this frame: each period
+// of 60/turboHz frames starts with them down for half of it.
+func (h *HostInput) turboOn() bool {
+	period := uint64(60 / h.turboHz)
+	return h.frame%period < (period+1)/2
+}
+
+// StartRecording discards the current macro and records a new one from the
+// next frame.
+func (h *HostInput) StartRecording() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.recording = true
+	h.macro = nil
+}
+
+// StopRecording ends recording and returns the macro length in frames.
+// Trailing frames with nothing held are dropped.
+func (h *HostInput) StopRecording() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.recording = false
+	for len(h.macro) > 0 && h.macro[len(h.macro)-1] == 0 {
+		h.macro = h.macro[:len(h.macro)-1]
+	}
+	return len(h.macro)
+}
+
+// Recording reports whether a macro is being recorded.
+func (h *HostInput) Recording() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.recording
+}
+
+// Play starts replaying the recorded macro from the next frame, on top of
+// whatever is held. It reports false when there is no macro.
+func (h *HostInput) Play() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.recording || len(h.macro) == 0 {
+		return false
+	}
+	h.playing = append([]uint16(nil), h.macro...)
+	return true
+}
+
+// Playing reports whether a macro is still being replayed.
+func (h *HostInput) Playing() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.playing) > 0
+}
+
+// Macro returns a copy of the recorded macro, one button mask per frame.
+func (h *HostInput) Macro() []uint16 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]uint16(nil), h.macro...)
+}
+
+// SetMacro replaces the recorded macro.
+func (h *HostInput) SetMacro(frames []uint16) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.macro = append([]uint16(nil), frames...)
+}
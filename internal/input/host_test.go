@@ -0,0 +1,80 @@
+package input
+
+import "testing"
+
+func TestParseButtons(t *testing.T) {
+	mask, err := ParseButtons("A, b,START")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := uint16(1<<ButtonA | 1<<ButtonB | 1<<ButtonSTART); mask != want {
+		t.Errorf("mask = 0x%04X, want 0x%04X", mask, want)
+	}
+	if mask, err := ParseButtons(""); err != nil || mask != 0 {
+		t.Errorf("empty list = 0x%04X, %v", mask, err)
+	}
+	if _, err := ParseButtons("a,jump"); err == nil {
+		t.Error("expected an error for an unknown button")
+	}
+}
+
+func TestTurboPulsesHeldButtons(t *testing.T) {
+	h := NewHostInput()
+	h.SetTurbo(1<<ButtonA, 15) // 4-frame period: 2 down, 2 up
+
+	held := uint16(1<<ButtonA | 1<<ButtonLEFT)
+	var pattern []bool
+	for i := 0; i < 8; i++ {
+		out := h.Frame(held)
+		if out&(1<<ButtonLEFT) == 0 {
+			t.Fatalf("frame %d: non-turbo button dropped", i)
+		}
+		pattern = append(pattern, out&(1<<ButtonA) != 0)
+	}
+	downs := 0
+	for i, down := range pattern {
+		if down {
+			downs++
+		}
+		if i >= 4 && down != pattern[i-4] {
+			t.Errorf("turbo not periodic: %v", pattern)
+			break
+		}
+	}
+	if downs != 4 {
+		t.Errorf("A down %d of 8 frames at 15 Hz, want 4: %v", downs, pattern)
+	}
+	if out := h.Frame(0); out != 0 {
+		t.Errorf("turbo fired with nothing held: 0x%04X", out)
+	}
+}
+
+func TestMacroRecordAndPlay(t *testing.T) {
+	h := NewHostInput()
+	if h.Play() {
+		t.Fatal("Play with no macro reported true")
+	}
+	h.StartRecording()
+	h.Frame(1 << ButtonRIGHT)
+	h.Frame(1 << ButtonA)
+	h.Frame(0)
+	if n := h.StopRecording(); n != 2 {
+		t.Fatalf("macro length = %d, want 2 (trailing idle frame dropped)", n)
+	}
+
+	if !h.Play() {
+		t.Fatal("Play reported false")
+	}
+	if got := h.Frame(1 << ButtonUP); got != 1<<ButtonUP|1<<ButtonRIGHT {
+		t.Errorf("first replay frame = 0x%04X, want UP|RIGHT", got)
+	}
+	if got := h.Frame(0); got != 1<<ButtonA {
+		t.Errorf("second replay frame = 0x%04X, want A", got)
+	}
+	if h.Playing() {
+		t.Error("still playing after the last frame")
+	}
+	if got := h.Frame(0); got != 0 {
+		t.Errorf("frame after replay = 0x%04X, want 0", got)
+	}
+}
@@ -1,5 +1,7 @@
 package input
 
+import "fmt"
+
 // InputSystem represents the input system
 // It implements the memory.IOHandler interface
 //
@@ -8,7 +10,7 @@ package input
 // - Controller captures current button states into shift register
 // - FPGA reads serial data and stores in latched register
 // - Reading data register returns latched state (not current state)
-// - Latch is edge-triggered: capture happens on write of 1, not persistent
+// - Latch is edge-triggered: capture happens when bit 0 goes 0->1, not persistent
 type InputSystem struct {
 	// Current button states (updated by UI/emulator)
 	Controller1Buttons uint16
@@ -22,8 +24,31 @@ type InputSystem struct {
 	// Latch state (for tracking edge detection)
 	Controller1LatchState bool
 	Controller2LatchState bool
+
+	// Mode selects what the data registers return (see LatchMode). It is a
+	// host setting, not machine state.
+	Mode LatchMode
+
+	// Poll, when set, is called for fresh controller 1 buttons whenever
+	// the ROM latches controller 1 (and on every controller 1 data read in
+	// LatchImmediate mode), so input is sampled when the program asks for
+	// it rather than once per host UI tick.
+	Poll func() uint16
 }
 
+// LatchMode is the input pipeline between host buttons and the CPU.
+type LatchMode uint8
+
+const (
+	// LatchOnStrobe returns the buttons captured at the last latch strobe,
+	// as the hardware does. This is the default.
+	LatchOnStrobe LatchMode = iota
+	// LatchImmediate returns the live button state on every read; latch
+	// writes are accepted but not needed. Lowest latency, but a ROM that
+	// reads the two data bytes can see them from different moments.
+	LatchImmediate
+)
+
 // NewInputSystem creates a new input system
 func NewInputSystem() *InputSystem {
 	return &InputSystem{
@@ -40,6 +65,9 @@ func NewInputSystem() *InputSystem {
 // Returns the LATCHED button state (not current state)
 // This matches FPGA behavior: after latching, reads return the captured state
 func (i *InputSystem) Read8(offset uint16) uint8 {
+	if i.Mode == LatchImmediate {
+		return i.readImmediate(offset)
+	}
 	switch offset {
 	case 0x00: // CONTROLLER1 (low byte) - returns latched state
 		value := uint8(i.Controller1Latched & 0xFF)
@@ -59,40 +87,63 @@ func (i *InputSystem) Read8(offset uint16) uint8 {
 	}
 }
 
+// readImmediate serves Read8 in LatchImmediate mode.
+func (i *InputSystem) readImmediate(offset uint16) uint8 {
+	switch offset {
+	case 0x00, 0x01:
+		if i.Poll != nil {
+			i.Controller1Buttons = i.Poll()
+		}
+		return uint8(i.Controller1Buttons >> (8 * offset))
+	case 0x02, 0x03:
+		return uint8(i.Controller2Buttons >> (8 * (offset - 2)))
+	default:
+		return 0
+	}
+}
+
 // Write8 writes an 8-bit value to input registers
-// Latch control: Writing 1 to latch register captures current button state
+// Latch control: bit 0 of the latch register drives the LATCH line; setting
+// it captures the current button state. The other bits are not decoded.
 // This matches FPGA behavior: pulse LATCH signal to controller, capture button states
 func (i *InputSystem) Write8(offset uint16, value uint8) {
+	latch := value&1 != 0
 	switch offset {
 	case 0x01: // CONTROLLER1_LATCH
 		// Edge-triggered: capture on rising edge (0->1 transition)
 		// In FPGA: this pulses the LATCH signal to the controller
 		// The controller captures current button states into its shift register
 		// The FPGA then reads the serial data and stores it
-		if value == 1 {
-			if !i.Controller1LatchState {
-				// Rising edge: capture current button state into latched register
-				// This simulates the FPGA reading serial data from controller
-				i.Controller1Latched = i.Controller1Buttons
+		if latch && !i.Controller1LatchState {
+			if i.Poll != nil {
+				i.Controller1Buttons = i.Poll()
 			}
-			// If already latched (value == 1 and state == true), don't re-capture
-			// This ensures edge-triggered behavior
-		} else if value == 0 {
-			// Writing 0 releases the latch (falling edge)
-			// This allows the next write of 1 to be a rising edge again
+			// Rising edge: capture current button state into latched register
+			// This simulates the FPGA reading serial data from controller
+			i.Controller1Latched = i.Controller1Buttons
 		}
-		// Update latch state
-		i.Controller1LatchState = (value == 1)
+		// While the line stays high nothing is re-captured; clearing bit 0
+		// releases it so the next set is a rising edge again.
+		i.Controller1LatchState = latch
 	case 0x03: // CONTROLLER2_LATCH
 		// Same behavior for controller 2
-		if value == 1 {
-			if !i.Controller2LatchState {
-				// Rising edge: capture current button state into latched register
-				i.Controller2Latched = i.Controller2Buttons
-			}
+		if latch && !i.Controller2LatchState {
+			// Rising edge: capture current button state into latched register
+			i.Controller2Latched = i.Controller2Buttons
 		}
-		i.Controller2LatchState = (value == 1)
+		i.Controller2LatchState = latch
+	}
+}
+
+// ParseLatchMode parses "strobe" (LatchOnStrobe) or "immediate".
+func ParseLatchMode(s string) (LatchMode, error) {
+	switch s {
+	case "strobe", "":
+		return LatchOnStrobe, nil
+	case "immediate":
+		return LatchImmediate, nil
 	}
+	return LatchOnStrobe, fmt.Errorf("input latch mode %q: want strobe or immediate", s)
 }
 
 // Read16 reads a 16-bit value from input registers
@@ -160,3 +160,86 @@ func TestRead16(t *testing.T) {
 		t.Errorf("Expected 16-bit value 0x%04X, got 0x%04X", expected, value)
 	}
 }
+
+// TestLatchDecodesBitZeroOnly checks that only bit 0 of the latch register
+// drives the LATCH line: 0x03 strobes like 1, and 0x02 releases like 0.
+func TestLatchDecodesBitZeroOnly(t *testing.T) {
+	input := NewInputSystem()
+	input.SetButton(ButtonA, true)
+	input.Write8(0x01, 0x03)
+	if input.Controller1Latched != 1<<ButtonA {
+		t.Fatalf("0x03 did not latch: 0x%04X", input.Controller1Latched)
+	}
+
+	input.SetButton(ButtonA, false)
+	input.SetButton(ButtonB, true)
+	input.Write8(0x01, 0x81) // bit 0 still high: no new edge
+	if input.Controller1Latched != 1<<ButtonA {
+		t.Errorf("re-captured while the line stayed high: 0x%04X", input.Controller1Latched)
+	}
+	input.Write8(0x01, 0x02) // bit 0 low: release
+	input.Write8(0x01, 0x01)
+	if input.Controller1Latched != 1<<ButtonB {
+		t.Errorf("0x02 did not release the latch: 0x%04X", input.Controller1Latched)
+	}
+}
+
+// TestLatchIsPerController checks that strobing one controller leaves the
+// other's latched state alone.
+func TestLatchIsPerController(t *testing.T) {
+	input := NewInputSystem()
+	input.SetButton2(ButtonX, true)
+	input.Write8(0x03, 1)
+	input.SetButton2(ButtonX, false)
+	input.Write8(0x01, 0)
+	input.Write8(0x01, 1)
+	if got := input.Read8(0x02); got != 1<<ButtonX {
+		t.Errorf("controller 2 changed by a controller 1 strobe: 0x%02X", got)
+	}
+}
+
+// TestImmediateModeReadsLiveState checks LatchImmediate: reads follow the
+// current buttons without a strobe.
+func TestImmediateModeReadsLiveState(t *testing.T) {
+	input := NewInputSystem()
+	input.Mode = LatchImmediate
+	input.SetButton(ButtonSTART, true)
+	if got := input.Read16(0x00); got != 1<<ButtonSTART {
+		t.Fatalf("immediate read = 0x%04X, want START", got)
+	}
+	input.SetButton(ButtonSTART, false)
+	if got := input.Read16(0x00); got != 0 {
+		t.Errorf("immediate read after release = 0x%04X, want 0", got)
+	}
+}
+
+// TestPollSamplesOnStrobe checks that Poll is consulted at the strobe, so
+// the latched state is the host's input at that moment.
+func TestPollSamplesOnStrobe(t *testing.T) {
+	input := NewInputSystem()
+	host := uint16(1 << ButtonUP)
+	polls := 0
+	input.Poll = func() uint16 { polls++; return host }
+
+	input.Write8(0x01, 1)
+	host = 1 << ButtonDOWN
+	if got := input.Read8(0x00); got != 1<<ButtonUP {
+		t.Errorf("latched read = 0x%02X, want UP from the strobe-time poll", got)
+	}
+	input.Write8(0x01, 1) // no edge: no poll
+	if polls != 1 {
+		t.Errorf("polls = %d, want 1", polls)
+	}
+}
+
+func TestParseLatchMode(t *testing.T) {
+	if m, err := ParseLatchMode("immediate"); err != nil || m != LatchImmediate {
+		t.Errorf("immediate = %v, %v", m, err)
+	}
+	if m, err := ParseLatchMode("strobe"); err != nil || m != LatchOnStrobe {
+		t.Errorf("strobe = %v, %v", m, err)
+	}
+	if _, err := ParseLatchMode("sometimes"); err == nil {
+		t.Error("expected an error")
+	}
+}
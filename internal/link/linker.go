@@ -0,0 +1,357 @@
+package link
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"nitro-core-dx/internal/rom"
+)
+
+// Options configures a link.
+type Options struct {
+	// Entry names the global symbol execution starts at. Empty means the
+	// start of the first fixed unit (CoreLX boot code) or, with no fixed
+	// unit, the start of the first unit given.
+	Entry string
+}
+
+// Placement records where one unit landed.
+type Placement struct {
+	Unit    string
+	Bank    uint8
+	Address uint16 // bank-local start address (0x8000+)
+	Words   int
+}
+
+// MapSymbol is one resolved symbol in the link map.
+type MapSymbol struct {
+	Name    string
+	Unit    string
+	Bank    uint8
+	Address uint16
+	Global  bool
+}
+
+// Map is the final layout of a linked ROM.
+type Map struct {
+	EntryBank   uint8
+	EntryOffset uint16
+	Units       []Placement
+	Symbols     []MapSymbol // sorted by bank, then address
+	DataBank    uint8       // 0 when there is no data region
+	DataBytes   int
+}
+
+// Result is a linked ROM image plus its map.
+type Result struct {
+	ROMBytes []byte
+	Map      *Map
+}
+
+type placedObject struct {
+	obj  *Object
+	bank uint8
+	base int // word index within the bank
+}
+
+func (p *placedObject) address(offset int) uint16 {
+	return uint16(rom.ROMBankOffsetBase + (p.base+offset)*2)
+}
+
+type globalDef struct {
+	unit *placedObject
+	sym  Symbol
+}
+
+// Link lays out objects, resolves every relocation, and builds the ROM.
+//
+// Fixed units are placed first at their pinned bank. The remaining units
+// are placed in the order given, each into the lowest bank with room, below
+// any data region. A unit never straddles a bank, so PC-relative references
+// inside it always stay valid. All resolution errors are reported together.
+func Link(objects []*Object, opts *Options) (*Result, error) {
+	if len(objects) == 0 {
+		return nil, fmt.Errorf("nothing to link")
+	}
+	cfg := Options{}
+	if opts != nil {
+		cfg = *opts
+	}
+
+	var dataObj *Object
+	for _, o := range objects {
+		if err := o.Validate(); err != nil {
+			return nil, err
+		}
+		if len(o.Code) > rom.ROMBankSizeWords {
+			return nil, fmt.Errorf("%s: %d code words do not fit in one bank (%d words)", o.Name, len(o.Code), rom.ROMBankSizeWords)
+		}
+		if len(o.Data) > 0 {
+			if dataObj != nil {
+				return nil, fmt.Errorf("%s: only one unit may carry a data region (already provided by %s)", o.Name, dataObj.Name)
+			}
+			dataObj = o
+		}
+	}
+
+	codeLimit := uint8(rom.ROMMaxProgramBank)
+	if dataObj != nil {
+		codeLimit = dataObj.DataBank - 1
+	}
+
+	fill := make(map[uint8]int)
+	pinned := make(map[uint8]bool)
+	placed := make([]*placedObject, 0, len(objects))
+	for _, o := range objects {
+		if o.FixedBank == 0 {
+			continue
+		}
+		if o.FixedBank < rom.ROMMinProgramBank || o.FixedBank > codeLimit {
+			return nil, fmt.Errorf("%s: fixed bank %d outside code banks %d-%d", o.Name, o.FixedBank, rom.ROMMinProgramBank, codeLimit)
+		}
+		if pinned[o.FixedBank] {
+			return nil, fmt.Errorf("%s: bank %d is already pinned by another fixed unit", o.Name, o.FixedBank)
+		}
+		pinned[o.FixedBank] = true
+		placed = append(placed, &placedObject{obj: o, bank: o.FixedBank})
+		fill[o.FixedBank] = len(o.Code)
+	}
+	for _, o := range objects {
+		if o.FixedBank != 0 {
+			continue
+		}
+		bank := uint8(rom.ROMMinProgramBank)
+		for ; bank <= codeLimit; bank++ {
+			if fill[bank]+len(o.Code) <= rom.ROMBankSizeWords {
+				break
+			}
+		}
+		if bank > codeLimit {
+			return nil, fmt.Errorf("%s: no code bank below %d has room for %d words", o.Name, codeLimit+1, len(o.Code))
+		}
+		placed = append(placed, &placedObject{obj: o, bank: bank, base: fill[bank]})
+		fill[bank] += len(o.Code)
+	}
+
+	var errs []error
+	globals := make(map[string]globalDef)
+	for _, p := range placed {
+		for _, s := range p.obj.Symbols {
+			if !s.Global {
+				continue
+			}
+			if prev, ok := globals[s.Name]; ok {
+				errs = append(errs, fmt.Errorf("duplicate global symbol %q: defined in %s and %s", s.Name, prev.unit.obj.Name, p.obj.Name))
+				continue
+			}
+			globals[s.Name] = globalDef{unit: p, sym: s}
+		}
+	}
+
+	// Copy each unit into its bank image, then patch relocations in place.
+	banks := make(map[uint8][]uint16)
+	for _, p := range placed {
+		words := banks[p.bank]
+		if need := p.base + len(p.obj.Code); len(words) < need {
+			words = append(words, make([]uint16, need-len(words))...)
+		}
+		copy(words[p.base:], p.obj.Code)
+		banks[p.bank] = words
+	}
+	for _, p := range placed {
+		for _, r := range p.obj.Relocs {
+			target, targetUnit, ok := resolve(p, globals, r.Symbol)
+			if !ok {
+				errs = append(errs, fmt.Errorf("%s: undefined symbol %q", where(p.obj, r), r.Symbol))
+				continue
+			}
+			var value uint16
+			switch r.Kind {
+			case RelocRel16:
+				if targetUnit.bank != p.bank {
+					errs = append(errs, fmt.Errorf("%s: %q is in bank %d but the reference is in bank %d; PC-relative branches cannot cross banks (use a far call via bank()/addr)",
+						where(p.obj, r), r.Symbol, targetUnit.bank, p.bank))
+					continue
+				}
+				value = uint16(rom.CalculateBranchOffset(p.address(r.Offset), targetUnit.address(target)))
+			case RelocAbs16:
+				value = targetUnit.address(target)
+			case RelocBank:
+				value = uint16(targetUnit.bank)
+			}
+			banks[p.bank][p.base+r.Offset] = value
+		}
+	}
+
+	m := &Map{}
+	if dataObj != nil {
+		m.DataBank = dataObj.DataBank
+		m.DataBytes = len(dataObj.Data)
+	}
+	for _, p := range placed {
+		m.Units = append(m.Units, Placement{Unit: p.obj.Name, Bank: p.bank, Address: p.address(0), Words: len(p.obj.Code)})
+		for _, s := range p.obj.Symbols {
+			m.Symbols = append(m.Symbols, MapSymbol{Name: s.Name, Unit: p.obj.Name, Bank: p.bank, Address: p.address(s.Offset), Global: s.Global})
+		}
+	}
+	sort.SliceStable(m.Units, func(i, j int) bool {
+		if m.Units[i].Bank != m.Units[j].Bank {
+			return m.Units[i].Bank < m.Units[j].Bank
+		}
+		return m.Units[i].Address < m.Units[j].Address
+	})
+	sort.SliceStable(m.Symbols, func(i, j int) bool {
+		if m.Symbols[i].Bank != m.Symbols[j].Bank {
+			return m.Symbols[i].Bank < m.Symbols[j].Bank
+		}
+		return m.Symbols[i].Address < m.Symbols[j].Address
+	})
+
+	switch {
+	case cfg.Entry != "":
+		def, ok := globals[cfg.Entry]
+		if !ok {
+			errs = append(errs, fmt.Errorf("entry symbol %q is not a global symbol of any unit", cfg.Entry))
+			break
+		}
+		m.EntryBank, m.EntryOffset = def.unit.bank, def.unit.address(def.sym.Offset)
+	default:
+		first := placed[0] // fixed units were placed first
+		m.EntryBank, m.EntryOffset = first.bank, first.address(0)
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	b := rom.NewBankedROMBuilder()
+	bankNums := make([]int, 0, len(banks))
+	for bank := range banks {
+		bankNums = append(bankNums, int(bank))
+	}
+	sort.Ints(bankNums)
+	for _, bank := range bankNums {
+		for _, w := range banks[uint8(bank)] {
+			b.AddInstruction(uint8(bank), w)
+		}
+	}
+	if dataObj != nil {
+		b.SetDataRegion(dataObj.DataBank, dataObj.Data)
+	}
+	romBytes, err := b.BuildROMBytes(m.EntryBank, m.EntryOffset)
+	if err != nil {
+		return nil, err
+	}
+	return &Result{ROMBytes: romBytes, Map: m}, nil
+}
+
+// resolve looks a symbol up in the referencing unit first, then globally.
+func resolve(p *placedObject, globals map[string]globalDef, name string) (int, *placedObject, bool) {
+	if off, ok := p.obj.SymbolOffset(name); ok {
+		return off, p, true
+	}
+	if def, ok := globals[name]; ok {
+		return def.sym.Offset, def.unit, true
+	}
+	return 0, nil, false
+}
+
+func where(o *Object, r Reloc) string {
+	if r.Line > 0 {
+		return fmt.Sprintf("%s:%d", o.Name, r.Line)
+	}
+	return o.Name
+}
+
+// FormatMap renders a link map as a plain-text listing.
+func FormatMap(m *Map) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "Entry: %02X:%04X\n", m.EntryBank, m.EntryOffset)
+	if m.DataBytes > 0 {
+		fmt.Fprintf(&buf, "Data:  %02X:8000 (%d bytes)\n", m.DataBank, m.DataBytes)
+	}
+	buf.WriteString("\nUnits:\n")
+	for _, u := range m.Units {
+		end := u.Address
+		if u.Words > 0 {
+			end = u.Address + uint16(u.Words*2-1)
+		}
+		fmt.Fprintf(&buf, "  %02X:%04X-%04X  %6d words  %s\n", u.Bank, u.Address, end, u.Words, u.Unit)
+	}
+	buf.WriteString("\nSymbols:\n")
+	for _, s := range m.Symbols {
+		scope := "local "
+		if s.Global {
+			scope = "global"
+		}
+		fmt.Fprintf(&buf, "  %02X:%04X  %s  %-24s %s\n", s.Bank, s.Address, scope, s.Name, s.Unit)
+	}
+	return buf.Bytes()
+}
+
+// ParseMap reads a link map written by FormatMap, so tools that only have
+// the map (a zipped game's symbols, say) can resolve addresses.
+func ParseMap(data []byte) (*Map, error) {
+	m := &Map{}
+	section := ""
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		fields := strings.Fields(line)
+		var err error
+		switch {
+		case line == "":
+			continue
+		case line == "Units:" || line == "Symbols:":
+			section = line
+			continue
+		case fields[0] == "Entry:" && len(fields) == 2:
+			m.EntryBank, m.EntryOffset, err = parseMapAddr(fields[1])
+		case fields[0] == "Data:" && len(fields) == 4:
+			m.DataBank, _, err = parseMapAddr(fields[1])
+			if err == nil {
+				m.DataBytes, err = strconv.Atoi(strings.TrimPrefix(fields[2], "("))
+			}
+		case section == "Units:" && len(fields) >= 3 && fields[2] == "words":
+			var u Placement
+			u.Bank, u.Address, err = parseMapAddr(strings.SplitN(fields[0], "-", 2)[0])
+			if err == nil {
+				u.Words, err = strconv.Atoi(fields[1])
+			}
+			u.Unit = strings.Join(fields[3:], " ")
+			m.Units = append(m.Units, u)
+		case section == "Symbols:" && len(fields) >= 3 && (fields[1] == "global" || fields[1] == "local"):
+			s := MapSymbol{Name: fields[2], Unit: strings.Join(fields[3:], " "), Global: fields[1] == "global"}
+			s.Bank, s.Address, err = parseMapAddr(fields[0])
+			m.Symbols = append(m.Symbols, s)
+		default:
+			err = fmt.Errorf("unexpected %q", line)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("link map line %d: %w", n, err)
+		}
+	}
+	return m, sc.Err()
+}
+
+// parseMapAddr parses a map address, "BB:AAAA" in hex.
+func parseMapAddr(s string) (uint8, uint16, error) {
+	bank, addr, ok := strings.Cut(s, ":")
+	if !ok {
+		return 0, 0, fmt.Errorf("address %q: want BB:AAAA", s)
+	}
+	b, err := strconv.ParseUint(bank, 16, 8)
+	if err != nil {
+		return 0, 0, fmt.Errorf("address %q: %w", s, err)
+	}
+	a, err := strconv.ParseUint(addr, 16, 16)
+	if err != nil {
+		return 0, 0, fmt.Errorf("address %q: %w", s, err)
+	}
+	return uint8(b), uint16(a), nil
+}
@@ -0,0 +1,177 @@
+package link
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"nitro-core-dx/internal/rom"
+)
+
+// bankWord reads code word i of a bank from a built ROM image.
+func bankWord(t *testing.T, romBytes []byte, bank uint8, i int) uint16 {
+	t.Helper()
+	off := 32 + (int(bank)-1)*rom.ROMBankSizeBytes + i*2 // 32-byte header
+	if off+1 >= len(romBytes) {
+		t.Fatalf("bank %d word %d outside ROM (%d bytes)", bank, i, len(romBytes))
+	}
+	return uint16(romBytes[off]) | uint16(romBytes[off+1])<<8
+}
+
+func TestLinkResolvesAcrossUnits(t *testing.T) {
+	main := &Object{
+		Name: "main",
+		// 0: CALL opcode, 1: rel16 -> helper, 2: abs16 -> helper, 3: bank -> helper
+		Code:    []uint16{0xF100, 0, 0, 0},
+		Symbols: []Symbol{{Name: "main", Offset: 0, Global: true}},
+		Relocs: []Reloc{
+			{Offset: 1, Symbol: "helper", Kind: RelocRel16},
+			{Offset: 2, Symbol: "helper", Kind: RelocAbs16},
+			{Offset: 3, Symbol: "helper", Kind: RelocBank},
+		},
+	}
+	lib := &Object{
+		Name:    "lib",
+		Code:    []uint16{0xF200, 0xF200},
+		Symbols: []Symbol{{Name: "pad", Offset: 0}, {Name: "helper", Offset: 1, Global: true}},
+	}
+	res, err := Link([]*Object{main, lib}, nil)
+	if err != nil {
+		t.Fatalf("Link: %v", err)
+	}
+	helperAddr := uint16(rom.ROMBankOffsetBase + (4+1)*2)
+	if got := bankWord(t, res.ROMBytes, 1, 1); got != uint16(rom.CalculateBranchOffset(rom.ROMBankOffsetBase+2, helperAddr)) {
+		t.Fatalf("rel16 = 0x%04X", got)
+	}
+	if got := bankWord(t, res.ROMBytes, 1, 2); got != helperAddr {
+		t.Fatalf("abs16 = 0x%04X, want 0x%04X", got, helperAddr)
+	}
+	if got := bankWord(t, res.ROMBytes, 1, 3); got != 1 {
+		t.Fatalf("bank = %d, want 1", got)
+	}
+	if res.Map.EntryBank != 1 || res.Map.EntryOffset != rom.ROMBankOffsetBase {
+		t.Fatalf("entry = %02X:%04X, want 01:8000", res.Map.EntryBank, res.Map.EntryOffset)
+	}
+	text := FormatMap(res.Map)
+	if !bytes.Contains(text, []byte("01:800A  global  helper")) {
+		t.Fatalf("map missing helper:\n%s", text)
+	}
+}
+
+func TestParseMapRoundTrip(t *testing.T) {
+	want := &Map{
+		EntryBank:   1,
+		EntryOffset: 0x8000,
+		Units:       []Placement{{Unit: "main game", Bank: 1, Address: 0x8000, Words: 10}},
+		Symbols: []MapSymbol{
+			{Name: "start", Unit: "main game", Bank: 1, Address: 0x8000, Global: true},
+			{Name: "loop", Unit: "main game", Bank: 1, Address: 0x8008},
+		},
+		DataBank:  2,
+		DataBytes: 300,
+	}
+	got, err := ParseMap(FormatMap(want))
+	if err != nil {
+		t.Fatalf("ParseMap: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ParseMap(FormatMap(m)) = %+v, want %+v", got, want)
+	}
+	if _, err := ParseMap([]byte("Entry: nowhere\n")); err == nil {
+		t.Fatal("ParseMap accepted a bad entry address")
+	}
+}
+
+func TestLinkFixedUnitAndEntrySymbol(t *testing.T) {
+	asm := &Object{Name: "asm", Code: []uint16{0xF200}, Symbols: []Symbol{{Name: "go", Global: true}}}
+	fixed := &Object{Name: "corelx", Code: make([]uint16, 10), FixedBank: 1}
+	res, err := Link([]*Object{asm, fixed}, nil)
+	if err != nil {
+		t.Fatalf("Link: %v", err)
+	}
+	if res.Map.Units[0].Unit != "corelx" || res.Map.Units[0].Address != rom.ROMBankOffsetBase {
+		t.Fatalf("fixed unit not at 01:8000: %+v", res.Map.Units)
+	}
+	if res.Map.EntryOffset != rom.ROMBankOffsetBase {
+		t.Fatalf("default entry should be the fixed unit, got %04X", res.Map.EntryOffset)
+	}
+
+	res, err = Link([]*Object{asm, fixed}, &Options{Entry: "go"})
+	if err != nil {
+		t.Fatalf("Link with entry: %v", err)
+	}
+	if want := uint16(rom.ROMBankOffsetBase + 20); res.Map.EntryOffset != want {
+		t.Fatalf("entry = %04X, want %04X", res.Map.EntryOffset, want)
+	}
+}
+
+func TestLinkErrors(t *testing.T) {
+	full := make([]uint16, rom.ROMBankSizeWords)
+	cases := []struct {
+		name    string
+		objects []*Object
+		want    []string
+	}{
+		{
+			name: "undefined and duplicate",
+			objects: []*Object{
+				{Name: "a", Code: []uint16{0, 0}, Symbols: []Symbol{{Name: "dup", Global: true}}, Relocs: []Reloc{{Offset: 1, Symbol: "missing", Kind: RelocAbs16, Line: 7}}},
+				{Name: "b", Code: []uint16{0}, Symbols: []Symbol{{Name: "dup", Global: true}}},
+			},
+			want: []string{`a:7: undefined symbol "missing"`, `duplicate global symbol "dup"`},
+		},
+		{
+			name: "cross-bank branch",
+			objects: []*Object{
+				{Name: "big", Code: full, Relocs: []Reloc{{Offset: 1, Symbol: "far", Kind: RelocRel16}}},
+				{Name: "far", Code: []uint16{0}, Symbols: []Symbol{{Name: "far", Global: true}}},
+			},
+			want: []string{"PC-relative branches cannot cross banks"},
+		},
+		{
+			name: "local symbols stay local",
+			objects: []*Object{
+				{Name: "a", Code: []uint16{0, 0}, Relocs: []Reloc{{Offset: 1, Symbol: "hidden", Kind: RelocAbs16}}},
+				{Name: "b", Code: []uint16{0}, Symbols: []Symbol{{Name: "hidden"}}},
+			},
+			want: []string{`undefined symbol "hidden"`},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Link(tc.objects, nil)
+			if err == nil {
+				t.Fatalf("expected link error")
+			}
+			for _, want := range tc.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Fatalf("error %q missing %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestObjectRoundTrip(t *testing.T) {
+	obj := &Object{
+		Name:    "unit.asm",
+		Code:    []uint16{1, 2, 3},
+		Symbols: []Symbol{{Name: "start", Global: true}},
+		Relocs:  []Reloc{{Offset: 2, Symbol: "x", Kind: RelocBank}},
+	}
+	data, err := MarshalObject(obj)
+	if err != nil {
+		t.Fatalf("MarshalObject: %v", err)
+	}
+	got, err := UnmarshalObject(data)
+	if err != nil {
+		t.Fatalf("UnmarshalObject: %v", err)
+	}
+	if got.Version != ObjectFormatVersion || len(got.Code) != 3 || got.Relocs[0].Kind != RelocBank || !got.Symbols[0].Global {
+		t.Fatalf("round trip mismatch: %+v", got)
+	}
+	if _, err := UnmarshalObject([]byte(`{"version":99}`)); err == nil {
+		t.Fatalf("expected version error")
+	}
+}
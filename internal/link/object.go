@@ -0,0 +1,165 @@
+// Package link combines relocatable compilation units (assembled .asm files
+// and compiled CoreLX programs) into a single ROM image.
+//
+// Each unit is an Object: a stream of 16-bit code words, the symbols it
+// defines (word offsets into that stream), and relocations naming symbols
+// whose final addresses are only known after layout. The linker places every
+// unit into a LoROM bank, resolves relocations against local symbols first
+// and then against other units' global symbols, and reports the final
+// placement as a link map.
+package link
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// ObjectFormatVersion is written into serialized objects; ReadObject rejects
+// other versions.
+const ObjectFormatVersion = 1
+
+// ObjectExt is the conventional file extension for serialized objects.
+const ObjectExt = ".ncobj"
+
+// RelocKind selects how a relocation's target address is written into the
+// code word.
+type RelocKind string
+
+const (
+	// RelocRel16 patches a signed PC-relative branch/JMP/CALL offset. The
+	// word being patched is the offset word itself (same convention as
+	// rom.CalculateBranchOffset). Source and target must share a bank.
+	RelocRel16 RelocKind = "rel16"
+	// RelocAbs16 patches the target's bank-local address (0x8000+).
+	RelocAbs16 RelocKind = "abs16"
+	// RelocBank patches the target's ROM bank number, for far calls
+	// (MOV R6, #bank; MOV R7, #addr; CALL R6:R7).
+	RelocBank RelocKind = "bank"
+)
+
+// Symbol is a named position inside an object's code.
+type Symbol struct {
+	Name string `json:"name"`
+	// Offset is the code word index the symbol points at.
+	Offset int `json:"offset"`
+	// Global symbols are visible to other units; local symbols only satisfy
+	// relocations within their own object (and still appear in the map).
+	Global bool `json:"global,omitempty"`
+}
+
+// Reloc is a code word to patch with a symbol's final address.
+type Reloc struct {
+	// Offset is the code word index to patch.
+	Offset int       `json:"offset"`
+	Symbol string    `json:"symbol"`
+	Kind   RelocKind `json:"kind"`
+	// Line is the source line that produced the reference (0 if unknown),
+	// used only for error messages.
+	Line int `json:"line,omitempty"`
+}
+
+// Object is one relocatable compilation unit.
+type Object struct {
+	Version int `json:"version"`
+	// Name identifies the unit in diagnostics and the link map (usually the
+	// source path).
+	Name    string   `json:"name"`
+	Code    []uint16 `json:"code"`
+	Symbols []Symbol `json:"symbols"`
+	Relocs  []Reloc  `json:"relocs,omitempty"`
+
+	// FixedBank pins the unit at FixedBank:0x8000 instead of letting the
+	// linker place it. CoreLX units are always fixed at bank 1: their boot
+	// code must sit at the reset vector and their IRQ vector fix-up bakes
+	// in absolute addresses.
+	FixedBank uint8 `json:"fixed_bank,omitempty"`
+
+	// Data is an optional read-only region (CoreLX image/music assets) that
+	// the unit's code expects at DataBank:0x8000. The linker keeps code out
+	// of the banks it covers.
+	DataBank uint8  `json:"data_bank,omitempty"`
+	Data     []byte `json:"data,omitempty"`
+}
+
+// SymbolOffset returns the word offset of a symbol defined by the object.
+func (o *Object) SymbolOffset(name string) (int, bool) {
+	for _, s := range o.Symbols {
+		if s.Name == name {
+			return s.Offset, true
+		}
+	}
+	return 0, false
+}
+
+// Validate checks that symbol and relocation offsets fall inside the code.
+func (o *Object) Validate() error {
+	seen := make(map[string]bool, len(o.Symbols))
+	for _, s := range o.Symbols {
+		if s.Offset < 0 || s.Offset > len(o.Code) {
+			return fmt.Errorf("%s: symbol %q offset %d outside code (%d words)", o.Name, s.Name, s.Offset, len(o.Code))
+		}
+		if seen[s.Name] {
+			return fmt.Errorf("%s: symbol %q defined twice", o.Name, s.Name)
+		}
+		seen[s.Name] = true
+	}
+	for _, r := range o.Relocs {
+		if r.Offset < 0 || r.Offset >= len(o.Code) {
+			return fmt.Errorf("%s: relocation for %q at word %d outside code (%d words)", o.Name, r.Symbol, r.Offset, len(o.Code))
+		}
+		switch r.Kind {
+		case RelocRel16, RelocAbs16, RelocBank:
+		default:
+			return fmt.Errorf("%s: relocation for %q has unknown kind %q", o.Name, r.Symbol, r.Kind)
+		}
+	}
+	if len(o.Data) > 0 && o.DataBank == 0 {
+		return fmt.Errorf("%s: data region without a data bank", o.Name)
+	}
+	return nil
+}
+
+// MarshalObject serializes an object as indented JSON.
+func MarshalObject(o *Object) ([]byte, error) {
+	out := *o
+	out.Version = ObjectFormatVersion
+	return json.MarshalIndent(&out, "", "  ")
+}
+
+// UnmarshalObject parses a serialized object and validates it.
+func UnmarshalObject(data []byte) (*Object, error) {
+	var o Object
+	if err := json.Unmarshal(data, &o); err != nil {
+		return nil, err
+	}
+	if o.Version != ObjectFormatVersion {
+		return nil, fmt.Errorf("unsupported object format version %d (expected %d)", o.Version, ObjectFormatVersion)
+	}
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+// WriteObject writes an object file.
+func WriteObject(path string, o *Object) error {
+	data, err := MarshalObject(o)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// ReadObject reads an object file.
+func ReadObject(path string) (*Object, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	o, err := UnmarshalObject(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return o, nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"nitro-core-dx/internal/apu"
 	"nitro-core-dx/internal/debug"
+	"nitro-core-dx/internal/hwdef"
 )
 
 // Bus represents the memory bus that routes memory accesses
@@ -26,6 +27,58 @@ type Bus struct {
 	PPUHandler   IOHandler
 	APUHandler   IOHandler
 	InputHandler IOHandler
+	// PersistHandler serves the PERSIST_* mailbox (0xA010-0xA013) with
+	// offsets relative to InputBase. Nil leaves the mailbox unmapped, as on
+	// real hardware.
+	PersistHandler IOHandler
+	// TestHandler serves the TEST_* port (0xA030-0xA034) the same way; it
+	// is only set in the emulator's test mode.
+	TestHandler IOHandler
+	// DebugHandler receives writes to the DEBUG_* console (0xA040-0xA043)
+	// the same way.
+	DebugHandler IOHandler
+
+	// ResetCause is what RESET_CAUSE reads: one of the hwdef.ResetCause*
+	// values, set by the emulator whenever it (re)starts the CPU.
+	ResetCause uint8
+
+	// RAMPattern is what FillRAMPattern writes into work RAM on a power
+	// cycle.
+	RAMPattern RAMPattern
+
+	// UninitRead, when set, is called for every read of a work RAM or
+	// extended work RAM byte that has not been written since power-on
+	// (ClearRAM). The read itself is served as usual.
+	UninitRead func(bank uint8, offset uint16)
+
+	// RAMWrite, when set, is called after every write to work RAM or
+	// extended work RAM.
+	RAMWrite func(bank uint8, offset uint16, value uint8)
+
+	// IORead, when set, is called before every read of a bank 0 I/O
+	// address (0x8000-0xFFDF). The read itself is served as usual.
+	IORead func(offset uint16)
+
+	// Contention models the hardware's bus contention: every access to a
+	// PPU, APU or FM register costs the CPU hwdef's wait cycles for that
+	// chip, and DMA and the YM burst streamer halt it while they own the
+	// bus (Halt). The CPU stalls for them through TakeWaitCycles, so they
+	// count in its cycles per frame. Off, I/O accesses cost nothing extra.
+	Contention bool
+	// WaitCycles is the wait and halt cycles run up that the CPU has not
+	// yet taken. It is machine state, saved with WRAM.
+	WaitCycles uint32
+
+	// StrictUnmapped logs every access to an unmapped address as a memory
+	// warning. UnmappedAccesses counts them either way.
+	StrictUnmapped   bool
+	UnmappedAccesses uint64
+
+	// OpenBus is the last byte driven on the data bus by a read or write.
+	// Reads of unmapped addresses return it, as the bus lines keep their
+	// previous value when nothing answers. It is machine state, saved with
+	// WRAM.
+	OpenBus uint8
 
 	// Logger for debug logging
 	logger *debug.Logger
@@ -34,8 +87,21 @@ type Bus struct {
 	ymBurstCount uint16
 	ymBurstBank  uint8
 	ymBurstOff   uint16
+
+	// Last byte written to each I/O address (0x8000-0xAFFF), for tools that
+	// inspect write-only registers. Not part of save states.
+	ioLastWrite [ioShadowSize]uint8
+	ioWritten   [ioShadowSize]bool
+
+	// Which work RAM bytes have been written since power-on, for
+	// UninitRead.
+	wramWritten    [32768]bool
+	wramExtWritten [131072]bool
 }
 
+// ioShadowSize covers the PPU, APU and input I/O ranges.
+const ioShadowSize = hwdef.IOEnd - hwdef.IOBase
+
 // IOHandler defines the interface for I/O register handlers
 type IOHandler interface {
 	Read8(offset uint16) uint8
@@ -82,14 +148,61 @@ func (b *Bus) ClearRAM() {
 	for i := range b.SystemVectors {
 		b.SystemVectors[i] = 0
 	}
+	b.ioLastWrite = [ioShadowSize]uint8{}
+	b.ioWritten = [ioShadowSize]bool{}
+	b.wramWritten = [32768]bool{}
+	b.wramExtWritten = [131072]bool{}
+	b.OpenBus = 0
+}
+
+// FillRAMPattern is ClearRAM for a hard power cycle: work RAM and extended
+// work RAM come up holding RAMPattern, zeroes unless one is set. With the
+// alternating 0xA5/0x5A pattern a program that reads memory it never wrote
+// sees 0x5AA5 instead of a plausible 0.
+func (b *Bus) FillRAMPattern() {
+	b.ClearRAM()
+	b.RAMPattern.Fill(b.WRAM[:])
+	b.RAMPattern.Fill(b.WRAMExtended[:])
+}
+
+// MarkRAMWritten counts every work RAM byte as written, so UninitRead stays
+// quiet after RAM is replaced wholesale (e.g. by loading a save state).
+func (b *Bus) MarkRAMWritten() {
+	for i := range b.wramWritten {
+		b.wramWritten[i] = true
+	}
+	for i := range b.wramExtWritten {
+		b.wramExtWritten[i] = true
+	}
+}
+
+// LastIOWrite returns the last byte the bus wrote to I/O address offset in
+// bank 0, and whether it was written since power-on. Reading it has no side
+// effects, unlike reading data ports or one-shot flags.
+func (b *Bus) LastIOWrite(offset uint16) (uint8, bool) {
+	if offset < hwdef.IOBase || offset >= hwdef.IOEnd {
+		return 0, false
+	}
+	i := offset - hwdef.IOBase
+	return b.ioLastWrite[i], b.ioWritten[i]
 }
 
-// Read8 reads an 8-bit value from memory
+// Read8 reads an 8-bit value from memory. Unmapped addresses read open bus:
+// the last byte read or written.
 func (b *Bus) Read8(bank uint8, offset uint16) uint8 {
+	v := b.read8(bank, offset)
+	b.OpenBus = v
+	return v
+}
+
+func (b *Bus) read8(bank uint8, offset uint16) uint8 {
 	// Bank 0: WRAM (0x0000-0x7FFF) or I/O (0x8000+)
 	if bank == 0 {
 		if offset < 0x8000 {
 			// WRAM
+			if b.UninitRead != nil && !b.wramWritten[offset] {
+				b.UninitRead(bank, offset)
+			}
 			return b.WRAM[offset]
 		}
 		// System vectors live in the top 32 bytes of bank 0.
@@ -103,38 +216,56 @@ func (b *Bus) Read8(bank uint8, offset uint16) uint8 {
 
 	// Banks 1-125: ROM space (routed to cartridge)
 	if bank >= 1 && bank <= 125 {
-		if b.Cartridge != nil {
+		if b.Cartridge != nil && b.Cartridge.Maps(bank, offset) {
 			return b.Cartridge.Read8(bank, offset)
 		}
-		// Unmapped: return 0 (could implement open bus behavior in future if needed)
-		// Open bus would return the previous data bus value, but most ROMs don't rely on this
-		return 0
+		// Below 0x8000, past the end of the ROM, or no cartridge.
+		return b.unmappedRead(bank, offset)
 	}
 
 	// Banks 126-127: Extended WRAM
 	if bank == 126 || bank == 127 {
 		extOffset := (uint32(bank-126) * 65536) + uint32(offset)
-		if extOffset < 131072 {
-			return b.WRAMExtended[extOffset]
+		if b.UninitRead != nil && !b.wramExtWritten[extOffset] {
+			b.UninitRead(bank, offset)
 		}
-		// Unmapped: return 0 (could implement open bus behavior in future if needed)
-		return 0
+		return b.WRAMExtended[extOffset]
 	}
 
-	// Unmapped bank: return 0
-	// NOTE: Real hardware might have "open bus" behavior (returning previous data bus value),
-	// but most ROMs don't rely on this. If ROM compatibility issues arise, open bus can be
-	// implemented by tracking the last data bus value and returning it for unmapped addresses.
-	return 0
+	// Banks 128-255 are not decoded.
+	return b.unmappedRead(bank, offset)
+}
+
+// unmappedRead serves a read nothing answers: the open bus value.
+func (b *Bus) unmappedRead(bank uint8, offset uint16) uint8 {
+	b.UnmappedAccesses++
+	if b.StrictUnmapped && b.logger != nil {
+		b.logger.LogMemory(debug.LogLevelWarning, fmt.Sprintf("Unmapped read: %02X:%04X (open bus 0x%02X)", bank, offset, b.OpenBus), nil)
+	}
+	return b.OpenBus
+}
+
+// unmappedWrite drops a write nothing answers.
+func (b *Bus) unmappedWrite(bank uint8, offset uint16, value uint8) {
+	b.UnmappedAccesses++
+	if b.StrictUnmapped && b.logger != nil {
+		b.logger.LogMemory(debug.LogLevelWarning, fmt.Sprintf("Unmapped write: %02X:%04X = 0x%02X", bank, offset, value), nil)
+	}
 }
 
 // Write8 writes an 8-bit value to memory
 func (b *Bus) Write8(bank uint8, offset uint16, value uint8) {
+	b.OpenBus = value
+
 	// Bank 0: WRAM (0x0000-0x7FFF) or I/O (0x8000+)
 	if bank == 0 {
 		if offset < 0x8000 {
 			// WRAM
 			b.WRAM[offset] = value
+			b.wramWritten[offset] = true
+			if b.RAMWrite != nil {
+				b.RAMWrite(bank, offset, value)
+			}
 		} else if offset >= 0xFFE0 {
 			// System vectors
 			b.SystemVectors[offset-0xFFE0] = value
@@ -153,11 +284,15 @@ func (b *Bus) Write8(bank uint8, offset uint16, value uint8) {
 	// Banks 126-127: Extended WRAM
 	if bank == 126 || bank == 127 {
 		extOffset := (uint32(bank-126) * 65536) + uint32(offset)
-		if extOffset < 131072 {
-			b.WRAMExtended[extOffset] = value
+		b.WRAMExtended[extOffset] = value
+		b.wramExtWritten[extOffset] = true
+		if b.RAMWrite != nil {
+			b.RAMWrite(bank, offset, value)
 		}
 		return
 	}
+
+	b.unmappedWrite(bank, offset, value)
 }
 
 // Read16 reads a 16-bit value from memory (little-endian)
@@ -172,15 +307,15 @@ func (b *Bus) Read16(bank uint8, offset uint16) uint16 {
 // For I/O registers that need special handling (like CGRAM_DATA),
 // write both bytes to the same address
 func (b *Bus) Write16(bank uint8, offset uint16, value uint16) {
-	// Special case: CGRAM_DATA (0x8013) needs two 8-bit writes to the same address
-	if bank == 0 && offset == 0x8013 {
+	// Special case: CGRAM_DATA needs two 8-bit writes to the same address
+	if bank == 0 && offset == hwdef.CGRAM_DATA {
 		// Write low byte first, then high byte (both to same address)
 		b.Write8(bank, offset, uint8(value&0xFF))
 		b.Write8(bank, offset, uint8(value>>8))
 		return
 	}
-	// Special case: CGRAM_ADDR (0x8012) - only write low byte, don't write to CGRAM_DATA
-	if bank == 0 && offset == 0x8012 {
+	// Special case: CGRAM_ADDR - only write low byte, don't write to CGRAM_DATA
+	if bank == 0 && offset == hwdef.CGRAM_ADDR {
 		// Only write the low byte to CGRAM_ADDR, ignore high byte
 		b.Write8(bank, offset, uint8(value&0xFF))
 		return
@@ -192,26 +327,50 @@ func (b *Bus) Write16(bank uint8, offset uint16, value uint16) {
 
 // readIO8 reads from I/O registers
 func (b *Bus) readIO8(offset uint16) uint8 {
+	if b.IORead != nil {
+		b.IORead(offset)
+	}
+	b.chargeIOWait(offset)
 	// PPU registers: 0x8000-0x8FFF
-	if offset >= 0x8000 && offset < 0x9000 {
+	if offset >= hwdef.PPUBase && offset < hwdef.APUBase {
 		if b.PPUHandler != nil {
-			return b.PPUHandler.Read8(offset - 0x8000)
+			return b.PPUHandler.Read8(offset - hwdef.PPUBase)
 		}
-		return 0
+		return b.unmappedRead(0, offset)
 	}
 
 	// APU registers: 0x9000-0x9FFF
-	if offset >= 0x9000 && offset < 0xA000 {
+	if offset >= hwdef.APUBase && offset < hwdef.InputBase {
 		if b.APUHandler != nil {
-			return b.APUHandler.Read8(offset - 0x9000)
+			return b.APUHandler.Read8(offset - hwdef.APUBase)
+		}
+		return b.unmappedRead(0, offset)
+	}
+
+	if isPersistRegister(offset) {
+		if b.PersistHandler != nil {
+			return b.PersistHandler.Read8(offset - hwdef.InputBase)
 		}
+		// Not open bus: an absent mailbox must read AVAILABLE clear.
 		return 0
 	}
+	if isTestRegister(offset) {
+		if b.TestHandler != nil {
+			return b.TestHandler.Read8(offset - hwdef.InputBase)
+		}
+		return 0
+	}
+	if isDebugRegister(offset) {
+		return 0 // write-only
+	}
+	if offset == hwdef.RESET_CAUSE {
+		return b.ResetCause
+	}
 
 	// Input registers: 0xA000-0xAFFF
-	if offset >= 0xA000 && offset < 0xB000 {
+	if offset >= hwdef.InputBase && offset < hwdef.IOEnd {
 		if b.InputHandler != nil {
-			inputOffset := offset - 0xA000
+			inputOffset := offset - hwdef.InputBase
 			value := b.InputHandler.Read8(inputOffset)
 			// Debug logging for input reads (if logger is available and input logging is enabled)
 			if b.logger != nil && b.logger.IsComponentEnabled(debug.ComponentInput) {
@@ -219,43 +378,49 @@ func (b *Bus) readIO8(offset uint16) uint8 {
 			}
 			return value
 		}
-		return 0
+		return b.unmappedRead(0, offset)
 	}
 
-	return 0
+	// 0xB000-0xFFDF: no device.
+	return b.unmappedRead(0, offset)
 }
 
 // writeIO8 writes to I/O registers
 func (b *Bus) writeIO8(offset uint16, value uint8) {
+	if offset < hwdef.IOEnd {
+		b.ioLastWrite[offset-hwdef.IOBase] = value
+		b.ioWritten[offset-hwdef.IOBase] = true
+	}
+	if b.logger != nil {
+		b.logger.LogIOWrite(offset, value)
+	}
+	b.chargeIOWait(offset)
+
 	// PPU registers: 0x8000-0x8FFF
-	if offset >= 0x8000 && offset < 0x9000 {
+	if offset >= hwdef.PPUBase && offset < hwdef.APUBase {
 		if b.PPUHandler != nil {
-			ppuOffset := offset - 0x8000
-			// Log OAM writes for debugging
-			if ppuOffset == 0x14 || ppuOffset == 0x15 {
-				// OAM_ADDR or OAM_DATA - will be logged by PPU
-			}
-			b.PPUHandler.Write8(ppuOffset, value)
+			// OAM_ADDR and OAM_DATA writes are logged by the PPU.
+			b.PPUHandler.Write8(offset-hwdef.PPUBase, value)
 		}
 		return
 	}
 
 	// APU registers: 0x9000-0x9FFF
-	if offset >= 0x9000 && offset < 0xA000 {
+	if offset >= hwdef.APUBase && offset < hwdef.InputBase {
 		// 0x9110-0x9115: bus-side YM burst streamer.
-		if offset >= 0x9110 && offset <= 0x9115 {
+		if offset >= hwdef.YM_BURST_COUNT_L && offset <= hwdef.YM_BURST_TRIGGER {
 			switch offset {
-			case 0x9110:
+			case hwdef.YM_BURST_COUNT_L:
 				b.ymBurstCount = (b.ymBurstCount & 0xFF00) | uint16(value)
-			case 0x9111:
+			case hwdef.YM_BURST_COUNT_H:
 				b.ymBurstCount = (b.ymBurstCount & 0x00FF) | (uint16(value) << 8)
-			case 0x9112:
+			case hwdef.YM_BURST_BANK:
 				b.ymBurstBank = value
-			case 0x9113:
+			case hwdef.YM_BURST_OFFSET_L:
 				b.ymBurstOff = (b.ymBurstOff & 0xFF00) | uint16(value)
-			case 0x9114:
+			case hwdef.YM_BURST_OFFSET_H:
 				b.ymBurstOff = (b.ymBurstOff & 0x00FF) | (uint16(value) << 8)
-			case 0x9115:
+			case hwdef.YM_BURST_TRIGGER:
 				if value&0x01 != 0 {
 					b.executeYMBurst()
 				}
@@ -263,15 +428,37 @@ func (b *Bus) writeIO8(offset uint16, value uint8) {
 			return
 		}
 		if b.APUHandler != nil {
-			b.APUHandler.Write8(offset-0x9000, value)
+			b.APUHandler.Write8(offset-hwdef.APUBase, value)
+		}
+		return
+	}
+
+	if isPersistRegister(offset) {
+		if b.PersistHandler != nil {
+			b.PersistHandler.Write8(offset-hwdef.InputBase, value)
+		}
+		return
+	}
+	if isTestRegister(offset) {
+		if b.TestHandler != nil {
+			b.TestHandler.Write8(offset-hwdef.InputBase, value)
+		}
+		return
+	}
+	if isDebugRegister(offset) {
+		if b.DebugHandler != nil {
+			b.DebugHandler.Write8(offset-hwdef.InputBase, value)
 		}
 		return
 	}
+	if offset == hwdef.RESET_CAUSE {
+		return // read-only
+	}
 
 	// Input registers: 0xA000-0xAFFF
-	if offset >= 0xA000 && offset < 0xB000 {
+	if offset >= hwdef.InputBase && offset < hwdef.IOEnd {
 		if b.InputHandler != nil {
-			inputOffset := offset - 0xA000
+			inputOffset := offset - hwdef.InputBase
 			b.InputHandler.Write8(inputOffset, value)
 			// Debug logging for input writes (if logger is available and input logging is enabled)
 			if b.logger != nil && b.logger.IsComponentEnabled(debug.ComponentInput) {
@@ -280,6 +467,58 @@ func (b *Bus) writeIO8(offset uint16, value uint8) {
 		}
 		return
 	}
+
+	// 0xB000-0xFFDF: no device.
+	b.unmappedWrite(0, offset, value)
+}
+
+// isPersistRegister reports whether an I/O address is in the PERSIST_*
+// mailbox, which shares the input region but not its handler.
+func isPersistRegister(offset uint16) bool {
+	return offset >= hwdef.PERSIST_KEY && offset <= hwdef.PERSIST_CONTROL
+}
+
+// isTestRegister reports whether an I/O address is in the TEST_* port.
+func isTestRegister(offset uint16) bool {
+	return offset >= hwdef.TEST_VALUE_L && offset <= hwdef.TEST_COMMAND
+}
+
+// isDebugRegister reports whether an I/O address is in the DEBUG_* console.
+func isDebugRegister(offset uint16) bool {
+	return offset >= hwdef.DEBUG_CHAR && offset <= hwdef.DEBUG_PRINT
+}
+
+// TakeWaitCycles returns the wait and halt cycles run up since the last
+// call and clears them (cpu.WaitStater). They are always 0 without
+// Contention.
+func (b *Bus) TakeWaitCycles() uint32 {
+	n := b.WaitCycles
+	b.WaitCycles = 0
+	return n
+}
+
+// Halt halts the CPU for cycles cycles while another device owns the bus,
+// if Contention is set.
+func (b *Bus) Halt(cycles uint32) {
+	if b.Contention {
+		b.WaitCycles += cycles
+	}
+}
+
+// chargeIOWait charges the wait cycles of a CPU access to the I/O address
+// offset.
+func (b *Bus) chargeIOWait(offset uint16) {
+	if !b.Contention {
+		return
+	}
+	switch {
+	case offset >= hwdef.PPUBase && offset < hwdef.APUBase:
+		b.WaitCycles += hwdef.PPUWaitCycles
+	case offset >= hwdef.APUBase && offset < hwdef.FMBase:
+		b.WaitCycles += hwdef.APUWaitCycles
+	case offset >= hwdef.FMBase && offset < hwdef.InputBase:
+		b.WaitCycles += hwdef.FMWaitCycles
+	}
 }
 
 // executeYMBurst streams a block of (port, addr, data) triplets from ROM into
@@ -288,6 +527,7 @@ func (b *Bus) executeYMBurst() {
 	if b.APUHandler == nil || b.Cartridge == nil || b.ymBurstCount == 0 {
 		return
 	}
+	b.Halt(uint32(b.ymBurstCount) * hwdef.YMBurstHaltCyclesPerEntry)
 	bank := b.ymBurstBank
 	off := b.ymBurstOff
 	for i := uint16(0); i < b.ymBurstCount; i++ {
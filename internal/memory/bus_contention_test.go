@@ -0,0 +1,31 @@
+package memory
+
+import (
+	"testing"
+
+	"nitro-core-dx/internal/hwdef"
+)
+
+func TestBusContentionChargesIOWaits(t *testing.T) {
+	bus := NewBus(NewCartridge())
+	bus.Write8(0, hwdef.BG0_SCROLLX_L, 1)
+	bus.Halt(100)
+	if n := bus.TakeWaitCycles(); n != 0 {
+		t.Fatalf("wait cycles = %d without contention, want 0", n)
+	}
+
+	bus.Contention = true
+	bus.Write8(0, hwdef.BG0_SCROLLX_L, 1) // PPU
+	bus.Read16(0, hwdef.APUBase)          // APU, two bytes
+	bus.Write8(0, hwdef.FMBase, 0x20)     // FM
+	bus.Read8(0, 0x0100)                  // WRAM
+	bus.Read8(0, hwdef.InputBase)         // input
+	bus.Halt(100)
+	want := uint32(hwdef.PPUWaitCycles + 2*hwdef.APUWaitCycles + hwdef.FMWaitCycles + 100)
+	if n := bus.TakeWaitCycles(); n != want {
+		t.Fatalf("wait cycles = %d, want %d", n, want)
+	}
+	if n := bus.TakeWaitCycles(); n != 0 {
+		t.Fatalf("wait cycles = %d after taking them, want 0", n)
+	}
+}
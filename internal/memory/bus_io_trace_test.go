@@ -0,0 +1,40 @@
+package memory
+
+import (
+	"testing"
+
+	"nitro-core-dx/internal/debug"
+)
+
+func TestBusLogsIOWritesByRegisterName(t *testing.T) {
+	logger := debug.NewLogger(100)
+	bus := NewBus(NewCartridge())
+	bus.SetLogger(logger)
+
+	// Memory logging is opt-in: nothing is recorded until it is enabled.
+	bus.Write8(0, 0x8014, 0x01)
+	logger.SetComponentEnabled(debug.ComponentMemory, true)
+	logger.SetMinLevel(debug.LogLevelDebug)
+	bus.Write8(0, 0x8014, 0x05)
+	bus.Write8(0, 0x9003, 0x81)
+	bus.Write8(0, 0x8FFF, 0x02)
+	logger.Shutdown()
+
+	var got []string
+	for _, e := range logger.GetEntries() {
+		got = append(got, e.Message)
+	}
+	want := []string{
+		"I/O write OAM_ADDR (0x8014) = 0x05",
+		"I/O write CH0_CONTROL (0x9003) = 0x81",
+		"I/O write 0x8FFF = 0x02",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("entries = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("entry %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
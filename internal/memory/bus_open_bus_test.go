@@ -0,0 +1,47 @@
+package memory
+
+import "testing"
+
+func TestUnmappedReadsReturnOpenBus(t *testing.T) {
+	cart := NewCartridge()
+	rom := make([]uint8, 64)
+	copy(rom, "RMCF")
+	rom[4] = 0x01  // Version 1
+	rom[6] = 0x20  // ROM size 32
+	rom[10] = 0x01 // Entry bank 1
+	rom[13] = 0x80 // Entry offset 0x8000
+	if err := cart.LoadROM(rom); err != nil {
+		t.Fatal(err)
+	}
+	b := NewBus(cart)
+
+	b.Write8(0, 0x0040, 0x3C)
+	for _, addr := range []struct {
+		bank   uint8
+		offset uint16
+	}{
+		{200, 0x1234}, // undecoded bank
+		{1, 0x4000},   // below the ROM window
+		{1, 0x9000},   // past the end of the ROM image
+		{0, 0xC000},   // I/O gap above the input registers
+	} {
+		if got := b.Read8(addr.bank, addr.offset); got != 0x3C {
+			t.Errorf("%02X:%04X = 0x%02X, want open bus 0x3C", addr.bank, addr.offset, got)
+		}
+	}
+	if b.UnmappedAccesses != 4 {
+		t.Errorf("UnmappedAccesses = %d, want 4", b.UnmappedAccesses)
+	}
+
+	// A mapped read drives the bus too.
+	b.WRAM[0x0050] = 0x77
+	b.Read8(0, 0x0050)
+	if got := b.Read8(200, 0); got != 0x77 {
+		t.Errorf("open bus after WRAM read = 0x%02X, want 0x77", got)
+	}
+
+	// The absent persist mailbox still reads 0 so AVAILABLE is clear.
+	if got := b.Read8(0, 0xA013); got != 0 {
+		t.Errorf("unmapped PERSIST_CONTROL = 0x%02X, want 0", got)
+	}
+}
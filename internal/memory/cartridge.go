@@ -78,6 +78,15 @@ func (c *Cartridge) Read8(bank uint8, offset uint16) uint8 {
 	return 0
 }
 
+// Maps reports whether the cartridge answers a read of bank:offset, i.e.
+// the address falls inside the loaded ROM image.
+func (c *Cartridge) Maps(bank uint8, offset uint16) bool {
+	if bank < 1 || bank > 125 || offset < 0x8000 {
+		return false
+	}
+	return (uint32(bank-1)*32768)+uint32(offset-0x8000) < c.ROMSize
+}
+
 // Read16 reads a 16-bit value from ROM (little-endian)
 func (c *Cartridge) Read16(bank uint8, offset uint16) uint16 {
 	low := c.Read8(bank, offset)
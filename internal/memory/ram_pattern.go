@@ -0,0 +1,89 @@
+package memory
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// RAMPatternKind selects how RAMPattern fills memory.
+type RAMPatternKind uint8
+
+const (
+	RAMPatternZero RAMPatternKind = iota
+	// RAMPatternAlternating fills 0xA5, 0x5A, 0xA5, ... so a 16-bit read of
+	// never-written memory sees 0x5AA5 rather than a plausible 0.
+	RAMPatternAlternating
+	RAMPatternOnes
+	// RAMPatternRandom fills pseudo-random bytes from Seed, so a failure
+	// caused by uninitialized memory reproduces with the same seed.
+	RAMPatternRandom
+)
+
+// RAMPattern is what work RAM and VRAM hold after a power cycle. The zero
+// value is all zeroes, as at the first boot.
+type RAMPattern struct {
+	Kind RAMPatternKind
+	Seed int64 // RAMPatternRandom only
+}
+
+// ParseRAMPattern parses the -ram-init style spelling of a pattern: "a5"
+// (alternating 0xA5/0x5A), "00", "ff", "random" (seed 1) or "random:SEED".
+func ParseRAMPattern(s string) (RAMPattern, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "a5", "a55a":
+		return RAMPattern{Kind: RAMPatternAlternating}, nil
+	case "00", "0", "zero", "":
+		return RAMPattern{Kind: RAMPatternZero}, nil
+	case "ff", "ones":
+		return RAMPattern{Kind: RAMPatternOnes}, nil
+	case "random":
+		return RAMPattern{Kind: RAMPatternRandom, Seed: 1}, nil
+	}
+	if rest, ok := strings.CutPrefix(strings.ToLower(s), "random:"); ok {
+		seed, err := strconv.ParseInt(rest, 0, 64)
+		if err != nil {
+			return RAMPattern{}, fmt.Errorf("RAM pattern %q: bad seed: %w", s, err)
+		}
+		return RAMPattern{Kind: RAMPatternRandom, Seed: seed}, nil
+	}
+	return RAMPattern{}, fmt.Errorf("RAM pattern %q: want a5, 00, ff, random or random:SEED", s)
+}
+
+// String returns the spelling ParseRAMPattern accepts.
+func (p RAMPattern) String() string {
+	switch p.Kind {
+	case RAMPatternAlternating:
+		return "a5"
+	case RAMPatternOnes:
+		return "ff"
+	case RAMPatternRandom:
+		return fmt.Sprintf("random:%d", p.Seed)
+	default:
+		return "00"
+	}
+}
+
+// Fill writes the pattern over buf. Random fills restart from Seed on every
+// call, so two buffers of the same size get the same bytes.
+func (p RAMPattern) Fill(buf []uint8) {
+	switch p.Kind {
+	case RAMPatternAlternating:
+		for i := range buf {
+			if i&1 == 0 {
+				buf[i] = 0xA5
+			} else {
+				buf[i] = 0x5A
+			}
+		}
+	case RAMPatternOnes:
+		for i := range buf {
+			buf[i] = 0xFF
+		}
+	case RAMPatternRandom:
+		rand.New(rand.NewSource(p.Seed)).Read(buf)
+	default:
+		clear(buf)
+	}
+}
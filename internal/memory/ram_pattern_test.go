@@ -0,0 +1,70 @@
+package memory
+
+import "testing"
+
+func TestParseRAMPattern(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want RAMPattern
+	}{
+		{"a5", RAMPattern{Kind: RAMPatternAlternating}},
+		{"00", RAMPattern{Kind: RAMPatternZero}},
+		{"FF", RAMPattern{Kind: RAMPatternOnes}},
+		{"random", RAMPattern{Kind: RAMPatternRandom, Seed: 1}},
+		{"random:0x2A", RAMPattern{Kind: RAMPatternRandom, Seed: 42}},
+	} {
+		got, err := ParseRAMPattern(tc.in)
+		if err != nil {
+			t.Fatalf("%q: %v", tc.in, err)
+		}
+		if got != tc.want {
+			t.Errorf("%q = %+v, want %+v", tc.in, got, tc.want)
+		}
+		if back, _ := ParseRAMPattern(got.String()); back != got {
+			t.Errorf("%q does not round-trip through %q", tc.in, got.String())
+		}
+	}
+	for _, bad := range []string{"12", "random:x"} {
+		if _, err := ParseRAMPattern(bad); err == nil {
+			t.Errorf("%q: expected an error", bad)
+		}
+	}
+}
+
+func TestRandomRAMPatternIsSeeded(t *testing.T) {
+	a, b, c := make([]uint8, 64), make([]uint8, 64), make([]uint8, 64)
+	RAMPattern{Kind: RAMPatternRandom, Seed: 7}.Fill(a)
+	RAMPattern{Kind: RAMPatternRandom, Seed: 7}.Fill(b)
+	RAMPattern{Kind: RAMPatternRandom, Seed: 8}.Fill(c)
+	if string(a) != string(b) {
+		t.Error("same seed gave different bytes")
+	}
+	if string(a) == string(c) {
+		t.Error("different seeds gave the same bytes")
+	}
+}
+
+func TestUninitReadReportsNeverWrittenWRAM(t *testing.T) {
+	b := NewBus(NewCartridge())
+	var hits []uint16
+	b.UninitRead = func(bank uint8, offset uint16) { hits = append(hits, offset) }
+
+	b.Write8(0, 0x0010, 1)
+	b.Read8(0, 0x0010)
+	b.Read8(0, 0x0011)
+	b.Write8(126, 0x0000, 1)
+	b.Read8(126, 0x0000)
+	if len(hits) != 1 || hits[0] != 0x0011 {
+		t.Fatalf("uninit reads = %v, want [0x0011]", hits)
+	}
+
+	b.RAMPattern = RAMPattern{Kind: RAMPatternAlternating}
+	b.FillRAMPattern()
+	b.Read8(0, 0x0010)
+	if len(hits) != 2 {
+		t.Fatalf("write before power cycle still counted: %v", hits)
+	}
+	if got := b.Read16(0, 0x0100); got != 0x5AA5 {
+		t.Errorf("power-on pattern = 0x%04X, want 0x5AA5", got)
+	}
+}
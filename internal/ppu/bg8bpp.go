@@ -0,0 +1,53 @@
+package ppu
+
+// Background color formats, selected per layer by BGn_SOURCE_MODE bits 2:1.
+//
+// The 8bpp formats are for title screens and static art: each tile pixel is a
+// whole byte, row-major, so an 8×8 tile is 64 bytes at VRAM index*64 and a
+// 16×16 tile is 256 bytes at index*256. The tilemap entry keeps its two-byte
+// shape, but in 8bpp the attribute byte's palette bits change meaning:
+//
+//	bits 1:0 - tile index bits 9:8 (1024 tiles)
+//	bit 2    - direct format only: blue bit 0
+//	bit 4    - flip X
+//	bit 5    - flip Y
+const (
+	BGFormat4bpp      uint8 = 0 // 16-color tiles, palette from the tilemap entry
+	BGFormatIndexed8  uint8 = 1 // pixel byte is a CGRAM index (all 256 colors)
+	BGFormatDirect8   uint8 = 2 // pixel byte is BBGGGRRR, bypassing CGRAM
+	bgSourceModeMask  uint8 = 0x07
+	bgSourceFormatBit       = 1
+)
+
+// ColorFormat returns the layer's BGFormat* value.
+func (l *BackgroundLayer) ColorFormat() uint8 {
+	format := (l.SourceMode >> bgSourceFormatBit) & 0x03
+	if format > BGFormatDirect8 {
+		return BGFormat4bpp
+	}
+	return format
+}
+
+// bg8bppTileIndex returns the 10-bit tile index of an 8bpp tilemap entry.
+func bg8bppTileIndex(tileIndex, attributes uint8) uint32 {
+	return uint32(tileIndex) | uint32(attributes&0x03)<<8
+}
+
+// bg8bppPixel reads the pixel byte at (px, py), already flipped, of an 8bpp
+// tile. ok is false when the pixel lies past the end of VRAM.
+func (p *PPU) bg8bppPixel(tileIndex, attributes uint8, tileSize, px, py int) (value uint8, ok bool) {
+	addr := bg8bppTileIndex(tileIndex, attributes)*uint32(tileSize*tileSize) + uint32(py*tileSize+px)
+	if addr >= 65536 {
+		return 0, false
+	}
+	return p.VRAM[addr], true
+}
+
+// directColorRGB333 expands a direct-format pixel (BBGGGRRR plus the tilemap
+// entry's blue bit 0) to RGB888.
+func directColorRGB333(pixel, attributes uint8) uint32 {
+	r := uint32(pixel & 0x07)
+	g := uint32((pixel >> 3) & 0x07)
+	b := uint32(pixel>>6)<<1 | uint32((attributes>>2)&0x01)
+	return (r*255/7)<<16 | (g*255/7)<<8 | b*255/7
+}
@@ -0,0 +1,90 @@
+package ppu
+
+import (
+	"testing"
+
+	"nitro-core-dx/internal/debug"
+)
+
+// newBG8TestPPU returns a PPU with BG0 enabled in 8bpp format, tilemap at
+// 0xE000, and tilemap cell (0, 0) pointing at tile 0x105 with attr.
+func newBG8TestPPU(format, attr uint8) *PPU {
+	p := NewPPU(debug.NewLogger(1000))
+	p.Write8(0x08, 0x01)         // BG0_CONTROL: enable, 8×8, 32×32
+	p.Write8(0x68, format<<1)    // BG0_SOURCE_MODE
+	p.Write8(0x77, 0x00)         // BG0_TILEMAP_BASE_L
+	p.Write8(0x78, 0xE0)         // BG0_TILEMAP_BASE_H
+	p.VRAM[0xE000] = 0x05        // tile index bits 7:0
+	p.VRAM[0xE001] = 0x01 | attr // tile index bits 9:8
+	for i := 0; i < 64; i++ {
+		p.VRAM[0x105*64+i] = uint8(0x10 + i)
+	}
+	return p
+}
+
+func renderBG8TestFrame(p *PPU) {
+	p.activeScanlineY = -1
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			p.renderDot(y, x)
+		}
+	}
+}
+
+func TestBG8IndexedReadsFullCGRAM(t *testing.T) {
+	p := newBG8TestPPU(BGFormatIndexed8, 0x10) // flip X
+	for i := 0; i < 256; i++ {
+		setPaletteColor(p, uint8(i>>4), uint8(i&0x0F), uint16(i)*0x21+1)
+	}
+	if got := p.Read8(0x68); got != 0x02 {
+		t.Fatalf("BG0_SOURCE_MODE reads %02X, want 02", got)
+	}
+	renderBG8TestFrame(p)
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			idx := uint8(0x10 + y*8 + (7 - x))
+			want := p.getColorFromCGRAM(idx>>4, idx&0x0F)
+			if got := p.OutputBuffer[y*320+x]; got != want {
+				t.Fatalf("pixel (%d,%d) = %06X, want CGRAM %d = %06X", x, y, got, idx, want)
+			}
+		}
+	}
+
+	// The frame-at-once renderer reads the same pixels.
+	for i := range p.OutputBuffer {
+		p.OutputBuffer[i] = 0
+	}
+	p.renderBackgroundLayer(0)
+	if got, want := p.OutputBuffer[0], p.getColorFromCGRAM(0x1, 0x7); got != want {
+		t.Fatalf("frame renderer pixel (0,0) = %06X, want %06X", got, want)
+	}
+}
+
+func TestBG8DirectColorBypassesCGRAM(t *testing.T) {
+	p := newBG8TestPPU(BGFormatDirect8, 0x04) // blue bit 0 set
+	p.VRAM[0x105*64] = 0xFF                   // white with the tile's blue bit
+	p.VRAM[0x105*64+1] = 0x07                 // full red, blue bit 0 only
+	p.VRAM[0x105*64+2] = 0x38                 // full green
+	renderBG8TestFrame(p)
+	want := []uint32{0xFFFFFF, 0xFF0024, 0x00FF24}
+	for x, w := range want {
+		if got := p.OutputBuffer[x]; got != w {
+			t.Errorf("pixel %d = %06X, want %06X", x, got, w)
+		}
+	}
+}
+
+func TestBGSourceModeKeepsFormatBits(t *testing.T) {
+	p := NewPPU(debug.NewLogger(1000))
+	p.Write8(0x69, 0xFF)
+	if got := p.Read8(0x69); got != 0x07 {
+		t.Fatalf("BG1_SOURCE_MODE reads %02X, want 07", got)
+	}
+	if p.BG1.ColorFormat() != BGFormat4bpp {
+		t.Fatalf("format 2:1=3 is reserved and should render as 4bpp, got %d", p.BG1.ColorFormat())
+	}
+	p.Write8(0x69, 0x04)
+	if p.BG1.ColorFormat() != BGFormatDirect8 {
+		t.Fatalf("format = %d, want direct", p.BG1.ColorFormat())
+	}
+}
@@ -40,10 +40,7 @@ func TestDebugSpriteRendering(t *testing.T) {
 	fmt.Printf("  Control: 0x%02X\n", ppu.OAM[5])
 
 	// Calculate sprite X position
-	spriteX := int(ppu.OAM[0])
-	if (ppu.OAM[1] & 0x01) != 0 {
-		spriteX |= 0xFFFFFF00
-	}
+	spriteX := ppu.SpriteX(0)
 	spriteY := int(ppu.OAM[2])
 	fmt.Printf("Sprite position: (%d, %d)\n", spriteX, spriteY)
 
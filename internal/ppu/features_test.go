@@ -568,6 +568,29 @@ func TestDMATransferToDedicatedMatrixPlaneBitmap(t *testing.T) {
 	}
 }
 
+// TestDMATransferFillsWholeOAM copies all 128 sprite entries in one DMA:
+// OAM is 768 bytes, so bytes past 255 must not wrap onto the first
+// sprites.
+func TestDMATransferFillsWholeOAM(t *testing.T) {
+	ppu := NewPPU(nil)
+	ppu.MemoryReader = func(bank uint8, offset uint16) uint8 {
+		return uint8(offset/6) ^ uint8(offset%6)<<7
+	}
+	ppu.DMASourceBank = 0
+	ppu.DMASourceOffset = 0
+	ppu.DMADestType = 2
+	ppu.DMALength = uint16(len(ppu.OAM))
+	ppu.DMAEnabled = true
+
+	ppu.executeDMA()
+
+	for i, got := range ppu.OAM {
+		if want := uint8(i/6) ^ uint8(i%6)<<7; got != want {
+			t.Fatalf("OAM[%d] = 0x%02X after DMA, want 0x%02X", i, got, want)
+		}
+	}
+}
+
 func TestDMATransferToDedicatedMatrixPlaneRows(t *testing.T) {
 	logger := debug.NewLogger(1000)
 	ppu := NewPPU(logger)
@@ -0,0 +1,30 @@
+package ppu
+
+// Line scroll gives BG0 a per-scanline horizontal offset without building an
+// HDMA table in VRAM: BG0_LINE_SCROLL holds the WRAM address of 200 signed
+// 16-bit little-endian entries, one per visible scanline, and each line adds
+// its entry to BG0_SCROLLX. A table address of 0x0000 turns it off. The
+// offset is fetched at the start of the line, so the CPU can rewrite the
+// table (for a wave or parallax bands) while the frame is drawing and see the
+// change on the next line fetched. Matrix mode ignores it.
+
+// lineScrollWRAMMask keeps table reads inside bank 0 WRAM, so an entry never
+// reaches an I/O register with read side effects.
+const lineScrollWRAMMask = 0x7FFF
+
+// lineScrollEntry reads scanline's entry from the BG0 line scroll table, or 0
+// when line scroll is off.
+func (p *PPU) lineScrollEntry(scanline int) int16 {
+	if p.LineScrollTable == 0 || p.MemoryReader == nil || scanline < 0 || scanline >= VisibleScanlines {
+		return 0
+	}
+	addr := p.LineScrollTable + uint16(scanline)*2
+	lo := p.MemoryReader(0, addr&lineScrollWRAMMask)
+	hi := p.MemoryReader(0, (addr+1)&lineScrollWRAMMask)
+	return int16(uint16(lo) | uint16(hi)<<8)
+}
+
+// latchLineScroll fetches the BG0 offset renderDot uses for scanline.
+func (p *PPU) latchLineScroll(scanline int) {
+	p.lineScrollX = p.lineScrollEntry(scanline)
+}
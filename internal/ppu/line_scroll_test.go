@@ -0,0 +1,93 @@
+package ppu
+
+import (
+	"testing"
+
+	"nitro-core-dx/internal/debug"
+)
+
+// newLineScrollTestPPU returns a PPU whose BG0 repeats one 8×8 tile with
+// color index x+1 in column x, and whose MemoryReader serves wram.
+func newLineScrollTestPPU(wram []byte) *PPU {
+	p := NewPPU(debug.NewLogger(1000))
+	p.MemoryReader = func(bank uint8, offset uint16) uint8 {
+		if bank != 0 || int(offset) >= len(wram) {
+			return 0
+		}
+		return wram[offset]
+	}
+	p.Write8(0x08, 0x01) // BG0_CONTROL: enable, 8×8, 32×32
+	p.Write8(0x77, 0x00) // BG0_TILEMAP_BASE_L
+	p.Write8(0x78, 0x40) // BG0_TILEMAP_BASE_H: 0x4000, all tile 0
+	for row := 0; row < 8; row++ {
+		for i := 0; i < 4; i++ {
+			p.VRAM[row*4+i] = uint8(2*i+1)<<4 | uint8(2*i+2)
+		}
+	}
+	for c := 1; c <= 8; c++ {
+		setPaletteColor(p, 0, uint8(c), uint16(c)*0x0421)
+	}
+	return p
+}
+
+// lineScrollColumn returns which tile column (0-7) screen pixel (x, y) shows.
+func lineScrollColumn(t *testing.T, p *PPU, x, y int) int {
+	t.Helper()
+	got := p.OutputBuffer[y*ScreenWidth+x]
+	for c := 1; c <= 8; c++ {
+		if got == p.getColorFromCGRAM(0, uint8(c)) {
+			return c - 1
+		}
+	}
+	t.Fatalf("pixel (%d,%d) = %06X is not a test tile color", x, y, got)
+	return -1
+}
+
+func TestBG0LineScrollOffsetsEachScanline(t *testing.T) {
+	wram := make([]byte, 0x8000)
+	const table = 0x2100
+	for y := 0; y < VisibleScanlines; y++ {
+		v := uint16(int16(y%8 - 4)) // -4..3, negative entries included
+		wram[table+y*2] = uint8(v)
+		wram[table+y*2+1] = uint8(v >> 8)
+	}
+	p := newLineScrollTestPPU(wram)
+	p.Write8(0x00, 0x02) // BG0_SCROLLX = 2: the table adds to it
+	p.Write8(0xAC, uint8(table&0xFF))
+	p.Write8(0xAD, uint8(table>>8))
+	if got := uint16(p.Read8(0xAC)) | uint16(p.Read8(0xAD))<<8; got != table {
+		t.Fatalf("BG0_LINE_SCROLL reads %04X, want %04X", got, table)
+	}
+
+	if err := p.StepPPU(DotsPerScanline * VisibleScanlines); err != nil {
+		t.Fatal(err)
+	}
+	for _, y := range []int{0, 3, 4, 7, 100, 199} {
+		want := ((10 + 2 + y%8 - 4) % 8)
+		if got := lineScrollColumn(t, p, 10, y); got != want {
+			t.Errorf("line %d shows column %d at x=10, want %d", y, got, want)
+		}
+	}
+
+	// The frame-at-once renderer applies the same table.
+	p.renderBackgroundLayer(0)
+	if got := lineScrollColumn(t, p, 10, 5); got != (10+2+1)%8 {
+		t.Errorf("frame renderer line 5 shows column %d, want %d", got, (10+2+1)%8)
+	}
+}
+
+func TestBG0LineScrollOffByDefault(t *testing.T) {
+	wram := make([]byte, 0x8000)
+	for i := range wram {
+		wram[i] = 0x03 // a table at 0x0000 would shift every line
+	}
+	p := newLineScrollTestPPU(wram)
+	if err := p.StepPPU(DotsPerScanline * VisibleScanlines); err != nil {
+		t.Fatal(err)
+	}
+	for _, y := range []int{0, 50, 199} {
+		if got := lineScrollColumn(t, p, 5, y); got != 5 {
+			t.Errorf("line %d shows column %d at x=5, want 5 with line scroll off", y, got)
+		}
+	}
+}
@@ -1,7 +1,10 @@
 package ppu
 
 import (
+	"fmt"
+
 	"nitro-core-dx/internal/debug"
+	"nitro-core-dx/internal/hwdef"
 )
 
 // NumTransformChannels is the number of affine/matrix transform engines.
@@ -79,6 +82,49 @@ type PPU struct {
 	// Set by emulator to allow DMA transfers
 	MemoryReader func(bank uint8, offset uint16) uint8
 
+	// VRAMWrite and OAMWrite, when set, are called after every VRAM or OAM
+	// byte written through the data ports (dma false) or by DMA (dma true).
+	VRAMWrite func(addr uint16, value uint8, dma bool)
+	OAMWrite  func(addr uint16, value uint8, dma bool)
+
+	// DMAHook, when set, is called with true when a DMA transfer starts
+	// (its registers describe it) and with false when it completes or is
+	// aborted.
+	DMAHook func(active bool)
+
+	// HaltCPU, when set, is called as a DMA transfer starts with how many
+	// CPU cycles it owns the bus for (see memory.Bus.Halt).
+	HaltCPU func(cycles uint32)
+
+	// VBlankFlagRead, when set, is called on every read of VBLANK_FLAG
+	// with whether it read as set.
+	VBlankFlagRead func(set bool)
+
+	// VRAMAccess decides whether VRAM/CGRAM writes during active display
+	// land (see VRAMAccessMode); LateVRAMWrites counts them in warn and
+	// strict mode.
+	VRAMAccess         VRAMAccessMode
+	LateVRAMWrites     uint64
+	lateVRAMWriteFrame uint16
+	// LateOAMWrites counts OAM writes made during active display: port
+	// writes, which are always ignored, and DMA into OAM, which lands
+	// unless VRAMAccess is strict.
+	LateOAMWrites uint64
+	// LateWrite, when set, is called for every write counted in
+	// LateVRAMWrites or LateOAMWrites; target is "VRAM", "CGRAM" or "OAM".
+	LateWrite func(target string, dma bool)
+
+	// Timing is the video timing mode: 60 Hz (the zero value) or 50 Hz.
+	// Change it between frames; savestates do not record it.
+	Timing Timing
+
+	// PriorityView tints each finished frame by the layer that won each
+	// pixel (see priority_view.go). dotWinner is the layer that last wrote
+	// the dot being rendered.
+	PriorityView    bool
+	priorityWinners [320 * 200]PriorityLayer
+	dotWinner       PriorityLayer
+
 	// VRAM/CGRAM/OAM access registers
 	VRAMAddr               uint16
 	CGRAMAddr              uint8
@@ -107,6 +153,18 @@ type PPU struct {
 	OAMAddr         uint8
 	OAMByteIndex    uint8 // Current byte index within sprite (0-5)
 
+	// BorderColor is the BORDER_COLOR register: the CGRAM index of the
+	// overscan border. DisplayBorder is its RGB888 color latched with
+	// DisplayBuffer at the end of each frame.
+	BorderColor   uint8
+	DisplayBorder uint32
+
+	// LineScrollTable is the BG0_LINE_SCROLL register: the WRAM address of
+	// BG0's per-scanline horizontal offsets, 0 when off (see line_scroll.go).
+	// lineScrollX is the entry latched for the scanline being drawn.
+	LineScrollTable uint16
+	lineScrollX     int16
+
 	// Text rendering state (MMIO 0x8070-0x8076)
 	TextX     uint16 // cursor X (auto-advances by 8 after each char)
 	TextY     uint8  // cursor Y
@@ -192,7 +250,7 @@ type BackgroundLayer struct {
 	Priority    uint8 // 0-3, higher renders on top
 	TileSize    bool  // false = 8×8, true = 16×16
 	TilemapSize uint8 // 0 = 32x32 tiles, 1 = 64x64 tiles, 2 = 128x128 tiles
-	SourceMode  uint8 // 0=tilemap, 1=bitmap (reserved; tilemap is the only active runtime source today)
+	SourceMode  uint8 // bit 0: 0=tilemap, 1=bitmap (reserved); bits 2:1: color format (see ColorFormat)
 	TilemapBase uint16
 	// TransformChannel selects the runtime transform channel bound to this layer.
 	// Defaults to the layer index.
@@ -361,8 +419,9 @@ func (p *PPU) ResetDerivedRuntimeCachesForStateLoad() {
 }
 
 // ClearScreen blanks both the back (OutputBuffer) and front (DisplayBuffer)
-// framebuffers to black and drops any buffered text overlay. Used on
-// stop/reset so the presented image does not stay frozen on the last frame.
+// framebuffers and the border to black and drops any buffered text overlay.
+// Used on stop/reset so the presented image does not stay frozen on the
+// last frame.
 func (p *PPU) ClearScreen() {
 	for i := range p.OutputBuffer {
 		p.OutputBuffer[i] = 0x000000
@@ -370,6 +429,7 @@ func (p *PPU) ClearScreen() {
 	for i := range p.DisplayBuffer {
 		p.DisplayBuffer[i] = 0x000000
 	}
+	p.DisplayBorder = 0x000000
 	p.textCount = 0
 }
 
@@ -424,7 +484,7 @@ func (p *PPU) applyLayerSourceModeRegister(layerNum int, value uint8) {
 	if layer == nil {
 		return
 	}
-	layer.SourceMode = value & 0x01
+	layer.SourceMode = value & bgSourceModeMask
 }
 
 func (p *PPU) readLayerSourceModeRegister(layerNum int) uint8 {
@@ -432,7 +492,7 @@ func (p *PPU) readLayerSourceModeRegister(layerNum int) uint8 {
 	if layer == nil {
 		return 0
 	}
-	return layer.SourceMode & 0x01
+	return layer.SourceMode & bgSourceModeMask
 }
 
 func (p *PPU) applyLayerTransformBindRegister(layerNum int, value uint8) {
@@ -775,154 +835,160 @@ func (p *PPU) matrixTilemapEntry(layer *BackgroundLayer, tileX, tileY int) (tile
 
 // Read8 reads an 8-bit value from PPU registers
 func (p *PPU) Read8(offset uint16) uint8 {
-	switch offset {
-	case 0x08: // BG0_CONTROL
+	switch hwdef.PPUBase + offset {
+	case hwdef.BG0_CONTROL:
 		return p.readLayerControlRegister(0)
-	case 0x09: // BG1_CONTROL
+	case hwdef.BG1_CONTROL:
 		return p.readLayerControlRegister(1)
-	case 0x21: // BG2_CONTROL
+	case hwdef.BG2_CONTROL:
 		return p.readLayerControlRegister(2)
-	case 0x26: // BG3_CONTROL
+	case hwdef.BG3_CONTROL:
 		return p.readLayerControlRegister(3)
-	case 0x68: // BG0_SOURCE_MODE
+	case hwdef.BG0_SOURCE_MODE:
 		return p.readLayerSourceModeRegister(0)
-	case 0x69: // BG1_SOURCE_MODE
+	case hwdef.BG1_SOURCE_MODE:
 		return p.readLayerSourceModeRegister(1)
-	case 0x6A: // BG2_SOURCE_MODE
+	case hwdef.BG2_SOURCE_MODE:
 		return p.readLayerSourceModeRegister(2)
-	case 0x6B: // BG3_SOURCE_MODE
+	case hwdef.BG3_SOURCE_MODE:
 		return p.readLayerSourceModeRegister(3)
-	case 0x6C: // BG0_TRANSFORM_BIND
+	case hwdef.BG0_TRANSFORM_BIND:
 		return p.readLayerTransformBindRegister(0)
-	case 0x6D: // BG1_TRANSFORM_BIND
+	case hwdef.BG1_TRANSFORM_BIND:
 		return p.readLayerTransformBindRegister(1)
-	case 0x6E: // BG2_TRANSFORM_BIND
+	case hwdef.BG2_TRANSFORM_BIND:
 		return p.readLayerTransformBindRegister(2)
-	case 0x6F: // BG3_TRANSFORM_BIND
+	case hwdef.BG3_TRANSFORM_BIND:
 		return p.readLayerTransformBindRegister(3)
-	case 0x77: // BG0_TILEMAP_BASE_L
+	case hwdef.BG0_TILEMAP_BASE_L:
 		return p.readLayerTilemapBaseLow(0)
-	case 0x78: // BG0_TILEMAP_BASE_H
+	case hwdef.BG0_TILEMAP_BASE_H:
 		return p.readLayerTilemapBaseHigh(0)
-	case 0x79: // BG1_TILEMAP_BASE_L
+	case hwdef.BG1_TILEMAP_BASE_L:
 		return p.readLayerTilemapBaseLow(1)
-	case 0x7A: // BG1_TILEMAP_BASE_H
+	case hwdef.BG1_TILEMAP_BASE_H:
 		return p.readLayerTilemapBaseHigh(1)
-	case 0x7B: // BG2_TILEMAP_BASE_L
+	case hwdef.BG2_TILEMAP_BASE_L:
 		return p.readLayerTilemapBaseLow(2)
-	case 0x7C: // BG2_TILEMAP_BASE_H
+	case hwdef.BG2_TILEMAP_BASE_H:
 		return p.readLayerTilemapBaseHigh(2)
-	case 0x7D: // BG3_TILEMAP_BASE_L
+	case hwdef.BG3_TILEMAP_BASE_L:
 		return p.readLayerTilemapBaseLow(3)
-	case 0x7E: // BG3_TILEMAP_BASE_H
+	case hwdef.BG3_TILEMAP_BASE_H:
 		return p.readLayerTilemapBaseHigh(3)
-	case 0x5D: // HDMA_CONTROL
+	case hwdef.HDMA_CONTROL:
 		return p.HDMAControl
-	case 0x5E: // HDMA_TABLE_BASE_L
+	case hwdef.HDMA_TABLE_BASE_L:
 		return uint8(p.HDMATableBase & 0xFF)
-	case 0x5F: // HDMA_TABLE_BASE_H
+	case hwdef.HDMA_TABLE_BASE_H:
 		return uint8(p.HDMATableBase >> 8)
-	case 0x7F: // HDMA_EXTENSION_CONTROL
+	case hwdef.HDMA_EXTENSION_CONTROL:
 		return p.HDMAExtControl
-	case 0x80: // MATRIX_PLANE_SELECT
+	case hwdef.MATRIX_PLANE_SELECT:
 		return p.MatrixPlaneSelect & 0x03
-	case 0x81: // MATRIX_PLANE_CONTROL
+	case hwdef.MATRIX_PLANE_CONTROL:
 		return p.readSelectedMatrixPlaneControl()
-	case 0x82: // MATRIX_PLANE_ADDR_L
+	case hwdef.MATRIX_PLANE_ADDR_L:
 		return uint8(p.MatrixPlaneAddr & 0xFF)
-	case 0x83: // MATRIX_PLANE_ADDR_H
+	case hwdef.MATRIX_PLANE_ADDR_H:
 		return uint8((p.MatrixPlaneAddr >> 8) & 0x7F)
-	case 0x84: // MATRIX_PLANE_DATA
+	case hwdef.MATRIX_PLANE_DATA:
 		plane := p.getSelectedMatrixPlane()
 		addr := int(p.MatrixPlaneAddr) & (len(plane.Tilemap) - 1)
 		value := plane.Tilemap[addr]
 		p.MatrixPlaneAddr = uint16((addr + 1) & (len(plane.Tilemap) - 1))
 		return value
-	case 0x85: // MATRIX_PLANE_PATTERN_ADDR_L
+	case hwdef.MATRIX_PLANE_PATTERN_ADDR_L:
 		return p.readSelectedMatrixPlanePatternAddrLow()
-	case 0x86: // MATRIX_PLANE_PATTERN_ADDR_H
+	case hwdef.MATRIX_PLANE_PATTERN_ADDR_H:
 		return p.readSelectedMatrixPlanePatternAddrHigh()
-	case 0x87: // MATRIX_PLANE_PATTERN_DATA
+	case hwdef.MATRIX_PLANE_PATTERN_DATA:
 		plane := p.getSelectedMatrixPlane()
 		addr := int(p.MatrixPlanePatternAddr) & (len(plane.Pattern) - 1)
 		value := plane.Pattern[addr]
 		p.MatrixPlanePatternAddr = uint16((addr + 1) & (len(plane.Pattern) - 1))
 		return value
-	case 0x88: // MATRIX_PLANE_BITMAP_ADDR_L
+	case hwdef.MATRIX_PLANE_BITMAP_ADDR_L:
 		return p.readSelectedMatrixPlaneBitmapAddrLow()
-	case 0x89: // MATRIX_PLANE_BITMAP_ADDR_M
+	case hwdef.MATRIX_PLANE_BITMAP_ADDR_M:
 		return p.readSelectedMatrixPlaneBitmapAddrMid()
-	case 0x8A: // MATRIX_PLANE_BITMAP_ADDR_H
+	case hwdef.MATRIX_PLANE_BITMAP_ADDR_H:
 		return p.readSelectedMatrixPlaneBitmapAddrHigh()
-	case 0x8B: // MATRIX_PLANE_BITMAP_DATA
+	case hwdef.MATRIX_PLANE_BITMAP_DATA:
 		plane := p.getSelectedMatrixPlane()
 		addr := int(p.MatrixPlaneBitmapAddr) & (len(plane.Bitmap) - 1)
 		value := plane.Bitmap[addr]
 		p.MatrixPlaneBitmapAddr = uint32((addr + 1) & (len(plane.Bitmap) - 1))
 		return value
-	case 0x8C: // MATRIX_PLANE_FLAGS
+	case hwdef.MATRIX_PLANE_FLAGS:
 		return p.readSelectedMatrixPlaneFlags()
-	case 0x8D: // MATRIX_PLANE_ROW_CONTROL
+	case hwdef.MATRIX_PLANE_ROW_CONTROL:
 		return p.readSelectedMatrixPlaneRowControl()
-	case 0x8E: // MATRIX_PLANE_ROW_ADDR_L
+	case hwdef.MATRIX_PLANE_ROW_ADDR_L:
 		return p.readSelectedMatrixPlaneRowAddrLow()
-	case 0x8F: // MATRIX_PLANE_ROW_ADDR_H
+	case hwdef.MATRIX_PLANE_ROW_ADDR_H:
 		return p.readSelectedMatrixPlaneRowAddrHigh()
-	case 0x90: // MATRIX_PLANE_ROW_DATA
+	case hwdef.MATRIX_PLANE_ROW_DATA:
 		return p.readSelectedMatrixPlaneRowData()
-	case 0x91: // MATRIX_PLANE_PROJECTION_CONTROL
+	case hwdef.MATRIX_PLANE_PROJECTION_CONTROL:
 		return p.readSelectedMatrixPlaneProjectionControl()
-	case 0x92: // MATRIX_PLANE_HORIZON
+	case hwdef.MATRIX_PLANE_HORIZON:
 		return p.getSelectedMatrixPlane().Horizon
-	case 0x93: // MATRIX_PLANE_CAMERA_X_L
+	case hwdef.MATRIX_PLANE_CAMERA_X_L:
 		return uint8(p.getSelectedMatrixPlane().CameraX & 0xFF)
-	case 0x94: // MATRIX_PLANE_CAMERA_X_H
+	case hwdef.MATRIX_PLANE_CAMERA_X_H:
 		return uint8((uint16(p.getSelectedMatrixPlane().CameraX) >> 8) & 0xFF)
-	case 0x95: // MATRIX_PLANE_CAMERA_Y_L
+	case hwdef.MATRIX_PLANE_CAMERA_Y_L:
 		return uint8(p.getSelectedMatrixPlane().CameraY & 0xFF)
-	case 0x96: // MATRIX_PLANE_CAMERA_Y_H
+	case hwdef.MATRIX_PLANE_CAMERA_Y_H:
 		return uint8((uint16(p.getSelectedMatrixPlane().CameraY) >> 8) & 0xFF)
-	case 0x97: // MATRIX_PLANE_HEADING_X_L
+	case hwdef.MATRIX_PLANE_HEADING_X_L:
 		return uint8(p.getSelectedMatrixPlane().HeadingX & 0xFF)
-	case 0x98: // MATRIX_PLANE_HEADING_X_H
+	case hwdef.MATRIX_PLANE_HEADING_X_H:
 		return uint8((uint16(p.getSelectedMatrixPlane().HeadingX) >> 8) & 0xFF)
-	case 0x99: // MATRIX_PLANE_HEADING_Y_L
+	case hwdef.MATRIX_PLANE_HEADING_Y_L:
 		return uint8(p.getSelectedMatrixPlane().HeadingY & 0xFF)
-	case 0x9A: // MATRIX_PLANE_HEADING_Y_H
+	case hwdef.MATRIX_PLANE_HEADING_Y_H:
 		return uint8((uint16(p.getSelectedMatrixPlane().HeadingY) >> 8) & 0xFF)
-	case 0x9B: // MATRIX_PLANE_BASE_DISTANCE_L
+	case hwdef.MATRIX_PLANE_BASE_DISTANCE_L:
 		return uint8(p.getSelectedMatrixPlane().BaseDistance & 0xFF)
-	case 0x9C: // MATRIX_PLANE_BASE_DISTANCE_H
+	case hwdef.MATRIX_PLANE_BASE_DISTANCE_H:
 		return uint8((p.getSelectedMatrixPlane().BaseDistance >> 8) & 0xFF)
-	case 0x9D: // MATRIX_PLANE_FOCAL_LENGTH_L
+	case hwdef.MATRIX_PLANE_FOCAL_LENGTH_L:
 		return uint8(p.getSelectedMatrixPlane().FocalLength & 0xFF)
-	case 0x9E: // MATRIX_PLANE_FOCAL_LENGTH_H
+	case hwdef.MATRIX_PLANE_FOCAL_LENGTH_H:
 		return uint8((p.getSelectedMatrixPlane().FocalLength >> 8) & 0xFF)
-	case 0x9F: // MATRIX_PLANE_WIDTH_SCALE_L
+	case hwdef.MATRIX_PLANE_WIDTH_SCALE_L:
 		return uint8(p.getSelectedMatrixPlane().WidthScale & 0xFF)
-	case 0xA0: // MATRIX_PLANE_WIDTH_SCALE_H
+	case hwdef.MATRIX_PLANE_WIDTH_SCALE_H:
 		return uint8((p.getSelectedMatrixPlane().WidthScale >> 8) & 0xFF)
-	case 0xA1: // MATRIX_PLANE_ORIGIN_X_L
+	case hwdef.MATRIX_PLANE_ORIGIN_X_L:
 		return uint8(p.getSelectedMatrixPlane().OriginX & 0xFF)
-	case 0xA2: // MATRIX_PLANE_ORIGIN_X_H
+	case hwdef.MATRIX_PLANE_ORIGIN_X_H:
 		return uint8((uint16(p.getSelectedMatrixPlane().OriginX) >> 8) & 0xFF)
-	case 0xA3: // MATRIX_PLANE_ORIGIN_Y_L
+	case hwdef.MATRIX_PLANE_ORIGIN_Y_L:
 		return uint8(p.getSelectedMatrixPlane().OriginY & 0xFF)
-	case 0xA4: // MATRIX_PLANE_ORIGIN_Y_H
+	case hwdef.MATRIX_PLANE_ORIGIN_Y_H:
 		return uint8((uint16(p.getSelectedMatrixPlane().OriginY) >> 8) & 0xFF)
-	case 0xA5: // MATRIX_PLANE_FACING_X_L
+	case hwdef.MATRIX_PLANE_FACING_X_L:
 		return uint8(p.getSelectedMatrixPlane().FacingX & 0xFF)
-	case 0xA6: // MATRIX_PLANE_FACING_X_H
+	case hwdef.MATRIX_PLANE_FACING_X_H:
 		return uint8((uint16(p.getSelectedMatrixPlane().FacingX) >> 8) & 0xFF)
-	case 0xA7: // MATRIX_PLANE_FACING_Y_L
+	case hwdef.MATRIX_PLANE_FACING_Y_L:
 		return uint8(p.getSelectedMatrixPlane().FacingY & 0xFF)
-	case 0xA8: // MATRIX_PLANE_FACING_Y_H
+	case hwdef.MATRIX_PLANE_FACING_Y_H:
 		return uint8((uint16(p.getSelectedMatrixPlane().FacingY) >> 8) & 0xFF)
-	case 0xA9: // MATRIX_PLANE_HEIGHT_SCALE_L
+	case hwdef.MATRIX_PLANE_HEIGHT_SCALE_L:
 		return uint8(p.getSelectedMatrixPlane().HeightScale & 0xFF)
-	case 0xAA: // MATRIX_PLANE_HEIGHT_SCALE_H
+	case hwdef.MATRIX_PLANE_HEIGHT_SCALE_H:
 		return uint8((p.getSelectedMatrixPlane().HeightScale >> 8) & 0xFF)
-	case 0x18: // MATRIX_CONTROL (BG0)
+	case hwdef.BORDER_COLOR:
+		return p.BorderColor
+	case hwdef.BG0_LINE_SCROLL_L:
+		return uint8(p.LineScrollTable)
+	case hwdef.BG0_LINE_SCROLL_H:
+		return uint8(p.LineScrollTable >> 8)
+	case hwdef.MATRIX_CONTROL: // BG0
 		channel := p.getLayerBoundTransformChannel(0)
 		var value uint8
 		if channel.Enabled {
@@ -939,7 +1005,7 @@ func (p *PPU) Read8(offset uint16) uint8 {
 			value |= 0x20
 		}
 		return value
-	case 0x2B: // BG1_MATRIX_CONTROL
+	case hwdef.BG1_MATRIX_CONTROL:
 		channel := p.getLayerBoundTransformChannel(1)
 		var value uint8
 		if channel.Enabled {
@@ -956,7 +1022,7 @@ func (p *PPU) Read8(offset uint16) uint8 {
 			value |= 0x20
 		}
 		return value
-	case 0x38: // BG2_MATRIX_CONTROL
+	case hwdef.BG2_MATRIX_CONTROL:
 		channel := p.getLayerBoundTransformChannel(2)
 		var value uint8
 		if channel.Enabled {
@@ -973,7 +1039,7 @@ func (p *PPU) Read8(offset uint16) uint8 {
 			value |= 0x20
 		}
 		return value
-	case 0x45: // BG3_MATRIX_CONTROL
+	case hwdef.BG3_MATRIX_CONTROL:
 		channel := p.getLayerBoundTransformChannel(3)
 		var value uint8
 		if channel.Enabled {
@@ -990,17 +1056,17 @@ func (p *PPU) Read8(offset uint16) uint8 {
 			value |= 0x20
 		}
 		return value
-	case 0x10: // VRAM_DATA
+	case hwdef.VRAM_DATA:
 		value := p.VRAM[p.VRAMAddr]
 		p.VRAMAddr++
 		if p.VRAMAddr > 0xFFFF {
 			p.VRAMAddr = 0
 		}
 		return value
-	case 0x13: // CGRAM_DATA
+	case hwdef.CGRAM_DATA:
 		// CGRAM is write-only, return 0
 		return 0
-	case 0x15: // OAM_DATA
+	case hwdef.OAM_DATA:
 		if p.OAMAddr < 128 {
 			addr := uint16(p.OAMAddr)*6 + uint16(p.OAMByteIndex)
 			if addr < 768 {
@@ -1019,7 +1085,7 @@ func (p *PPU) Read8(offset uint16) uint8 {
 			}
 		}
 		return 0
-	case 0x3E: // VBLANK_FLAG (one-shot: cleared when read)
+	case hwdef.VBLANK_FLAG: // one-shot: cleared when read
 		// VBlank flag: hardware-accurate synchronization signal
 		// Set at start of VBlank period (scanline 200), cleared when read (one-shot)
 		// Bit 0 = VBlank active (1 = VBlank period, 0 = not VBlank)
@@ -1032,7 +1098,7 @@ func (p *PPU) Read8(offset uint16) uint8 {
 		//
 		// CRITICAL FIX: Check if we're in VBlank BEFORE reading the flag value.
 		// This ensures the flag is set correctly even if it was cleared by a previous read.
-		inVBlank := p.currentScanline >= VisibleScanlines && p.currentScanline < TotalScanlines
+		inVBlank := p.currentScanline >= VisibleScanlines && p.currentScanline < p.Timing.Scanlines()
 
 		flag := p.VBlankFlag
 
@@ -1054,33 +1120,36 @@ func (p *PPU) Read8(offset uint16) uint8 {
 				"VBlank flag read: scanline=%d, dot=%d, inVBlank=%v, flag=%v, returning=0x%02X",
 				p.currentScanline, p.currentDot, inVBlank, flag, map[bool]uint8{true: 0x01, false: 0x00}[flag])
 		}
+		if p.VBlankFlagRead != nil {
+			p.VBlankFlagRead(flag)
+		}
 		if flag {
 			return 0x01
 		}
 		return 0x00
-	case 0x3F: // FRAME_COUNTER_LOW
+	case hwdef.FRAME_COUNTER_L:
 		return uint8(p.FrameCounter & 0xFF)
-	case 0x40: // FRAME_COUNTER_HIGH
+	case hwdef.FRAME_COUNTER_H:
 		return uint8(p.FrameCounter >> 8)
-	case 0x60: // DMA_STATUS
+	case hwdef.DMA_STATUS:
 		// Bit 0: DMA active (1=transferring, 0=idle)
 		if p.DMAEnabled && p.DMAProgress < p.DMALength {
 			return 0x01
 		}
 		return 0x00
-	case 0x61: // DMA_SOURCE_BANK
+	case hwdef.DMA_SOURCE_BANK:
 		return p.DMASourceBank
-	case 0x62: // DMA_SOURCE_OFFSET_L
+	case hwdef.DMA_SOURCE_OFFSET_L:
 		return uint8(p.DMASourceOffset & 0xFF)
-	case 0x63: // DMA_SOURCE_OFFSET_H
+	case hwdef.DMA_SOURCE_OFFSET_H:
 		return uint8(p.DMASourceOffset >> 8)
-	case 0x64: // DMA_DEST_ADDR_L
+	case hwdef.DMA_DEST_ADDR_L:
 		return uint8(p.DMADestAddr & 0xFF)
-	case 0x65: // DMA_DEST_ADDR_H
+	case hwdef.DMA_DEST_ADDR_H:
 		return uint8(p.DMADestAddr >> 8)
-	case 0x66: // DMA_LENGTH_L
+	case hwdef.DMA_LENGTH_L:
 		return uint8(p.DMALength & 0xFF)
-	case 0x67: // DMA_LENGTH_H
+	case hwdef.DMA_LENGTH_H:
 		return uint8(p.DMALength >> 8)
 	default:
 		return 0
@@ -1089,61 +1158,66 @@ func (p *PPU) Read8(offset uint16) uint8 {
 
 // Write8 writes an 8-bit value to PPU registers
 func (p *PPU) Write8(offset uint16, value uint8) {
-	switch offset {
+	switch hwdef.PPUBase + offset {
 	// BG0 scroll
-	case 0x00: // BG0_SCROLLX_L
+	case hwdef.BG0_SCROLLX_L:
 		p.BG0.ScrollX = int16((uint16(p.BG0.ScrollX) & 0xFF00) | uint16(value))
-	case 0x01: // BG0_SCROLLX_H
+	case hwdef.BG0_SCROLLX_H:
 		p.BG0.ScrollX = int16((uint16(p.BG0.ScrollX) & 0x00FF) | (uint16(value) << 8))
-	case 0x02: // BG0_SCROLLY_L
+	case hwdef.BG0_SCROLLY_L:
 		p.BG0.ScrollY = int16((uint16(p.BG0.ScrollY) & 0xFF00) | uint16(value))
-	case 0x03: // BG0_SCROLLY_H
+	case hwdef.BG0_SCROLLY_H:
 		p.BG0.ScrollY = int16((uint16(p.BG0.ScrollY) & 0x00FF) | (uint16(value) << 8))
 
 	// BG1 scroll
-	case 0x04: // BG1_SCROLLX_L
+	case hwdef.BG1_SCROLLX_L:
 		p.BG1.ScrollX = int16((uint16(p.BG1.ScrollX) & 0xFF00) | uint16(value))
-	case 0x05: // BG1_SCROLLX_H
+	case hwdef.BG1_SCROLLX_H:
 		p.BG1.ScrollX = int16((uint16(p.BG1.ScrollX) & 0x00FF) | (uint16(value) << 8))
-	case 0x06: // BG1_SCROLLY_L
+	case hwdef.BG1_SCROLLY_L:
 		p.BG1.ScrollY = int16((uint16(p.BG1.ScrollY) & 0xFF00) | uint16(value))
-	case 0x07: // BG1_SCROLLY_H
+	case hwdef.BG1_SCROLLY_H:
 		p.BG1.ScrollY = int16((uint16(p.BG1.ScrollY) & 0x00FF) | (uint16(value) << 8))
 
 	// BG0/BG1 control
-	case 0x08: // BG0_CONTROL
+	case hwdef.BG0_CONTROL:
 		p.applyLayerControlRegister(0, value)
-	case 0x09: // BG1_CONTROL
+	case hwdef.BG1_CONTROL:
 		p.applyLayerControlRegister(1, value)
 
 	// BG2 scroll
-	case 0x0A: // BG2_SCROLLX_L
+	case hwdef.BG2_SCROLLX_L:
 		p.BG2.ScrollX = int16((uint16(p.BG2.ScrollX) & 0xFF00) | uint16(value))
-	case 0x0B: // BG2_SCROLLX_H
+	case hwdef.BG2_SCROLLX_H:
 		p.BG2.ScrollX = int16((uint16(p.BG2.ScrollX) & 0x00FF) | (uint16(value) << 8))
-	case 0x0C: // BG2_SCROLLY_L
+	case hwdef.BG2_SCROLLY_L:
 		p.BG2.ScrollY = int16((uint16(p.BG2.ScrollY) & 0xFF00) | uint16(value))
-	case 0x0D: // BG2_SCROLLY_H
+	case hwdef.BG2_SCROLLY_H:
 		p.BG2.ScrollY = int16((uint16(p.BG2.ScrollY) & 0x00FF) | (uint16(value) << 8))
 
 	// VRAM access
-	case 0x0E: // VRAM_ADDR_L
+	case hwdef.VRAM_ADDR_L:
 		p.VRAMAddr = (p.VRAMAddr & 0xFF00) | uint16(value)
-	case 0x0F: // VRAM_ADDR_H
+	case hwdef.VRAM_ADDR_H:
 		p.VRAMAddr = (p.VRAMAddr & 0x00FF) | (uint16(value) << 8)
-	case 0x10: // VRAM_DATA
+	case hwdef.VRAM_DATA:
 		// Only log VRAM writes during initialization (first frame) and only first 32 bytes
 		if p.Logger != nil && p.FrameCounter == 0 && p.VRAMAddr < 32 {
 			p.Logger.LogPPUf(debug.LogLevelDebug, "VRAM_DATA write: addr=0x%04X, value=0x%02X", p.VRAMAddr, value)
 		}
-		p.VRAM[p.VRAMAddr] = value
+		if p.allowVRAMWrite("VRAM", p.VRAMAddr, false) {
+			p.VRAM[p.VRAMAddr] = value
+			if p.VRAMWrite != nil {
+				p.VRAMWrite(p.VRAMAddr, value, false)
+			}
+		}
 		p.VRAMAddr++
 		if p.VRAMAddr > 0xFFFF {
 			p.VRAMAddr = 0
 		}
 
 	// CGRAM access
-	case 0x12: // CGRAM_ADDR
+	case hwdef.CGRAM_ADDR:
 		// Only log CGRAM_ADDR during initialization (first frame)
 		if p.Logger != nil && p.FrameCounter == 0 && value < 64 {
 			paletteIndex := value / 32
@@ -1152,7 +1226,7 @@ func (p *PPU) Write8(offset uint16, value uint8) {
 		}
 		p.CGRAMAddr = value
 		p.CGRAMWriteLatch = false
-	case 0x13: // CGRAM_DATA
+	case hwdef.CGRAM_DATA:
 		if !p.CGRAMWriteLatch {
 			// First write: low byte
 			p.CGRAMWriteValue = uint16(value)
@@ -1162,7 +1236,10 @@ func (p *PPU) Write8(offset uint16, value uint8) {
 			p.CGRAMWriteValue |= (uint16(value) << 8)
 			// Write to CGRAM
 			addr := uint16(p.CGRAMAddr) * 2
-			if addr < 512 {
+			if addr < 512 && !p.allowVRAMWrite("CGRAM", addr, false) {
+				// Dropped: the color is lost but the address still advances.
+				p.CGRAMAddr++
+			} else if addr < 512 {
 				cgramIndex := p.CGRAMAddr
 				// Only log CGRAM_DATA during initialization (first frame) and only first 20 colors
 				if p.Logger != nil && p.FrameCounter == 0 && addr < 40 {
@@ -1184,7 +1261,7 @@ func (p *PPU) Write8(offset uint16, value uint8) {
 		}
 
 	// OAM access
-	case 0x14: // OAM_ADDR
+	case hwdef.OAM_ADDR:
 		// Only log OAM_ADDR writes occasionally (every 60 frames) to reduce performance impact
 		if p.Logger != nil && p.FrameCounter%60 == 0 && value < 4 {
 			p.Logger.LogPPUf(debug.LogLevelDebug, "OAM_ADDR write: 0x%02X (sprite %d), byte index was %d, resetting to 0",
@@ -1194,7 +1271,8 @@ func (p *PPU) Write8(offset uint16, value uint8) {
 		// During visible rendering (scanlines 0-199), OAM is locked
 		// Allow writes if: VBlank period (scanline >= 200) OR frame hasn't started yet OR first frame (initialization)
 		// Note: ROM should wait for VBlank before updating sprites to avoid wavy artifacts
-		if p.currentScanline < 200 && p.frameStarted && p.FrameCounter > 1 {
+		if p.inActiveDisplay() {
+			p.noteLateOAMWrite(false)
 			if p.Logger != nil {
 				p.Logger.LogPPUf(debug.LogLevelWarning, "OAM_ADDR write ignored during visible rendering (scanline %d)", p.currentScanline)
 			}
@@ -1206,12 +1284,13 @@ func (p *PPU) Write8(offset uint16, value uint8) {
 		}
 		p.OAMByteIndex = 0 // Reset byte index when setting sprite address
 		// Removed frequent logging - only log occasionally above
-	case 0x15: // OAM_DATA
+	case hwdef.OAM_DATA:
 		// OAM writes are only allowed during VBlank period (hardware-accurate)
 		// During visible rendering (scanlines 0-199), OAM is locked
 		// Allow writes if: VBlank period (scanline >= 200) OR frame hasn't started yet OR first frame (initialization)
 		// Note: ROM should wait for VBlank before updating sprites to avoid wavy artifacts
-		if p.currentScanline < 200 && p.frameStarted && p.FrameCounter > 1 {
+		if p.inActiveDisplay() {
+			p.noteLateOAMWrite(false)
 			if p.Logger != nil {
 				p.Logger.LogPPUf(debug.LogLevelWarning, "OAM_DATA write ignored during visible rendering (scanline %d)", p.currentScanline)
 			}
@@ -1227,6 +1306,9 @@ func (p *PPU) Write8(offset uint16, value uint8) {
 		addr := uint16(p.OAMAddr)*6 + uint16(p.OAMByteIndex)
 		if addr < 768 {
 			p.OAM[addr] = value
+			if p.OAMWrite != nil {
+				p.OAMWrite(addr, value, false)
+			}
 			// Removed frequent logging - only log occasionally above
 			p.OAMByteIndex++
 			if p.OAMByteIndex >= 6 {
@@ -1245,227 +1327,227 @@ func (p *PPU) Write8(offset uint16, value uint8) {
 		}
 
 	// Matrix Mode (Legacy - maps to BG0 for backward compatibility)
-	case 0x18: // MATRIX_CONTROL (BG0)
+	case hwdef.MATRIX_CONTROL: // BG0
 		channel := p.getLayerBoundTransformChannel(0)
 		channel.Enabled = (value & 0x01) != 0
 		channel.MirrorH = (value & 0x02) != 0
 		channel.MirrorV = (value & 0x04) != 0
 		channel.OutsideMode = (value >> 3) & 0x3
 		channel.DirectColor = (value & 0x20) != 0
-	case 0x19: // MATRIX_A_L (BG0)
+	case hwdef.MATRIX_A_L: // BG0
 		channel := p.getLayerBoundTransformChannel(0)
 		channel.A = int16((uint16(channel.A) & 0xFF00) | uint16(value))
-	case 0x1A: // MATRIX_A_H (BG0)
+	case hwdef.MATRIX_A_H: // BG0
 		channel := p.getLayerBoundTransformChannel(0)
 		channel.A = int16((uint16(channel.A) & 0x00FF) | (uint16(value) << 8))
-	case 0x1B: // MATRIX_B_L (BG0)
+	case hwdef.MATRIX_B_L: // BG0
 		channel := p.getLayerBoundTransformChannel(0)
 		channel.B = int16((uint16(channel.B) & 0xFF00) | uint16(value))
-	case 0x1C: // MATRIX_B_H (BG0)
+	case hwdef.MATRIX_B_H: // BG0
 		channel := p.getLayerBoundTransformChannel(0)
 		channel.B = int16((uint16(channel.B) & 0x00FF) | (uint16(value) << 8))
-	case 0x1D: // MATRIX_C_L (BG0)
+	case hwdef.MATRIX_C_L: // BG0
 		channel := p.getLayerBoundTransformChannel(0)
 		channel.C = int16((uint16(channel.C) & 0xFF00) | uint16(value))
-	case 0x1E: // MATRIX_C_H (BG0)
+	case hwdef.MATRIX_C_H: // BG0
 		channel := p.getLayerBoundTransformChannel(0)
 		channel.C = int16((uint16(channel.C) & 0x00FF) | (uint16(value) << 8))
-	case 0x1F: // MATRIX_D_L (BG0)
+	case hwdef.MATRIX_D_L: // BG0
 		channel := p.getLayerBoundTransformChannel(0)
 		channel.D = int16((uint16(channel.D) & 0xFF00) | uint16(value))
-	case 0x20: // MATRIX_D_H (BG0)
+	case hwdef.MATRIX_D_H: // BG0
 		channel := p.getLayerBoundTransformChannel(0)
 		channel.D = int16((uint16(channel.D) & 0x00FF) | (uint16(value) << 8))
 
 	// BG2/BG3 control
-	case 0x21: // BG2_CONTROL
+	case hwdef.BG2_CONTROL:
 		p.applyLayerControlRegister(2, value)
-	case 0x22: // BG3_SCROLLX_L
+	case hwdef.BG3_SCROLLX_L:
 		p.BG3.ScrollX = int16((uint16(p.BG3.ScrollX) & 0xFF00) | uint16(value))
-	case 0x23: // BG3_SCROLLX_H
+	case hwdef.BG3_SCROLLX_H:
 		p.BG3.ScrollX = int16((uint16(p.BG3.ScrollX) & 0x00FF) | (uint16(value) << 8))
-	case 0x24: // BG3_SCROLLY_L
+	case hwdef.BG3_SCROLLY_L:
 		p.BG3.ScrollY = int16((uint16(p.BG3.ScrollY) & 0xFF00) | uint16(value))
-	case 0x25: // BG3_SCROLLY_H
+	case hwdef.BG3_SCROLLY_H:
 		p.BG3.ScrollY = int16((uint16(p.BG3.ScrollY) & 0x00FF) | (uint16(value) << 8))
-	case 0x26: // BG3_CONTROL
+	case hwdef.BG3_CONTROL:
 		p.applyLayerControlRegister(3, value)
 
 	// Matrix center (BG0)
-	case 0x27: // MATRIX_CENTER_X_L (BG0)
+	case hwdef.MATRIX_CENTER_X_L: // BG0
 		channel := p.getLayerBoundTransformChannel(0)
 		channel.CenterX = int16((uint16(channel.CenterX) & 0xFF00) | uint16(value))
-	case 0x28: // MATRIX_CENTER_X_H (BG0)
+	case hwdef.MATRIX_CENTER_X_H: // BG0
 		channel := p.getLayerBoundTransformChannel(0)
 		channel.CenterX = int16((uint16(channel.CenterX) & 0x00FF) | (uint16(value) << 8))
-	case 0x29: // MATRIX_CENTER_Y_L (BG0)
+	case hwdef.MATRIX_CENTER_Y_L: // BG0
 		channel := p.getLayerBoundTransformChannel(0)
 		channel.CenterY = int16((uint16(channel.CenterY) & 0xFF00) | uint16(value))
-	case 0x2A: // MATRIX_CENTER_Y_H (BG0)
+	case hwdef.MATRIX_CENTER_Y_H: // BG0
 		channel := p.getLayerBoundTransformChannel(0)
 		channel.CenterY = int16((uint16(channel.CenterY) & 0x00FF) | (uint16(value) << 8))
 
 	// BG1 Matrix Mode (per-layer transformation)
-	case 0x2B: // BG1_MATRIX_CONTROL
+	case hwdef.BG1_MATRIX_CONTROL:
 		channel := p.getLayerBoundTransformChannel(1)
 		channel.Enabled = (value & 0x01) != 0
 		channel.MirrorH = (value & 0x02) != 0
 		channel.MirrorV = (value & 0x04) != 0
 		channel.OutsideMode = (value >> 3) & 0x3
 		channel.DirectColor = (value & 0x20) != 0
-	case 0x2C: // BG1_MATRIX_A_L
+	case hwdef.BG1_MATRIX_A_L:
 		channel := p.getLayerBoundTransformChannel(1)
 		channel.A = int16((uint16(channel.A) & 0xFF00) | uint16(value))
-	case 0x2D: // BG1_MATRIX_A_H
+	case hwdef.BG1_MATRIX_A_H:
 		channel := p.getLayerBoundTransformChannel(1)
 		channel.A = int16((uint16(channel.A) & 0x00FF) | (uint16(value) << 8))
-	case 0x2E: // BG1_MATRIX_B_L
+	case hwdef.BG1_MATRIX_B_L:
 		channel := p.getLayerBoundTransformChannel(1)
 		channel.B = int16((uint16(channel.B) & 0xFF00) | uint16(value))
-	case 0x2F: // BG1_MATRIX_B_H
+	case hwdef.BG1_MATRIX_B_H:
 		channel := p.getLayerBoundTransformChannel(1)
 		channel.B = int16((uint16(channel.B) & 0x00FF) | (uint16(value) << 8))
-	case 0x30: // BG1_MATRIX_C_L
+	case hwdef.BG1_MATRIX_C_L:
 		channel := p.getLayerBoundTransformChannel(1)
 		channel.C = int16((uint16(channel.C) & 0xFF00) | uint16(value))
-	case 0x31: // BG1_MATRIX_C_H
+	case hwdef.BG1_MATRIX_C_H:
 		channel := p.getLayerBoundTransformChannel(1)
 		channel.C = int16((uint16(channel.C) & 0x00FF) | (uint16(value) << 8))
-	case 0x32: // BG1_MATRIX_D_L
+	case hwdef.BG1_MATRIX_D_L:
 		channel := p.getLayerBoundTransformChannel(1)
 		channel.D = int16((uint16(channel.D) & 0xFF00) | uint16(value))
-	case 0x33: // BG1_MATRIX_D_H
+	case hwdef.BG1_MATRIX_D_H:
 		channel := p.getLayerBoundTransformChannel(1)
 		channel.D = int16((uint16(channel.D) & 0x00FF) | (uint16(value) << 8))
-	case 0x34: // BG1_MATRIX_CENTER_X_L
+	case hwdef.BG1_MATRIX_CENTER_X_L:
 		channel := p.getLayerBoundTransformChannel(1)
 		channel.CenterX = int16((uint16(channel.CenterX) & 0xFF00) | uint16(value))
-	case 0x35: // BG1_MATRIX_CENTER_X_H
+	case hwdef.BG1_MATRIX_CENTER_X_H:
 		channel := p.getLayerBoundTransformChannel(1)
 		channel.CenterX = int16((uint16(channel.CenterX) & 0x00FF) | (uint16(value) << 8))
-	case 0x36: // BG1_MATRIX_CENTER_Y_L
+	case hwdef.BG1_MATRIX_CENTER_Y_L:
 		channel := p.getLayerBoundTransformChannel(1)
 		channel.CenterY = int16((uint16(channel.CenterY) & 0xFF00) | uint16(value))
-	case 0x37: // BG1_MATRIX_CENTER_Y_H
+	case hwdef.BG1_MATRIX_CENTER_Y_H:
 		channel := p.getLayerBoundTransformChannel(1)
 		channel.CenterY = int16((uint16(channel.CenterY) & 0x00FF) | (uint16(value) << 8))
 
 	// BG2 Matrix Mode
-	case 0x38: // BG2_MATRIX_CONTROL
+	case hwdef.BG2_MATRIX_CONTROL:
 		channel := p.getLayerBoundTransformChannel(2)
 		channel.Enabled = (value & 0x01) != 0
 		channel.MirrorH = (value & 0x02) != 0
 		channel.MirrorV = (value & 0x04) != 0
 		channel.OutsideMode = (value >> 3) & 0x3
 		channel.DirectColor = (value & 0x20) != 0
-	case 0x39: // BG2_MATRIX_A_L
+	case hwdef.BG2_MATRIX_A_L:
 		channel := p.getLayerBoundTransformChannel(2)
 		channel.A = int16((uint16(channel.A) & 0xFF00) | uint16(value))
-	case 0x3A: // BG2_MATRIX_A_H
+	case hwdef.BG2_MATRIX_A_H:
 		channel := p.getLayerBoundTransformChannel(2)
 		channel.A = int16((uint16(channel.A) & 0x00FF) | (uint16(value) << 8))
-	case 0x3B: // BG2_MATRIX_B_L
+	case hwdef.BG2_MATRIX_B_L:
 		channel := p.getLayerBoundTransformChannel(2)
 		channel.B = int16((uint16(channel.B) & 0xFF00) | uint16(value))
-	case 0x3C: // BG2_MATRIX_B_H
+	case hwdef.BG2_MATRIX_B_H:
 		channel := p.getLayerBoundTransformChannel(2)
 		channel.B = int16((uint16(channel.B) & 0x00FF) | (uint16(value) << 8))
-	case 0x3D: // BG2_MATRIX_C_L
+	case hwdef.BG2_MATRIX_C_L:
 		channel := p.getLayerBoundTransformChannel(2)
 		channel.C = int16((uint16(channel.C) & 0xFF00) | uint16(value))
-	case 0x3E: // BG2_MATRIX_C_H
+	case hwdef.BG2_MATRIX_C_H:
 		channel := p.getLayerBoundTransformChannel(2)
 		channel.C = int16((uint16(channel.C) & 0x00FF) | (uint16(value) << 8))
-	case 0x3F: // BG2_MATRIX_D_L
+	case hwdef.BG2_MATRIX_D_L:
 		channel := p.getLayerBoundTransformChannel(2)
 		channel.D = int16((uint16(channel.D) & 0xFF00) | uint16(value))
-	case 0x40: // BG2_MATRIX_D_H
+	case hwdef.BG2_MATRIX_D_H:
 		channel := p.getLayerBoundTransformChannel(2)
 		channel.D = int16((uint16(channel.D) & 0x00FF) | (uint16(value) << 8))
-	case 0x41: // BG2_MATRIX_CENTER_X_L
+	case hwdef.BG2_MATRIX_CENTER_X_L:
 		channel := p.getLayerBoundTransformChannel(2)
 		channel.CenterX = int16((uint16(channel.CenterX) & 0xFF00) | uint16(value))
-	case 0x42: // BG2_MATRIX_CENTER_X_H
+	case hwdef.BG2_MATRIX_CENTER_X_H:
 		channel := p.getLayerBoundTransformChannel(2)
 		channel.CenterX = int16((uint16(channel.CenterX) & 0x00FF) | (uint16(value) << 8))
-	case 0x43: // BG2_MATRIX_CENTER_Y_L
+	case hwdef.BG2_MATRIX_CENTER_Y_L:
 		channel := p.getLayerBoundTransformChannel(2)
 		channel.CenterY = int16((uint16(channel.CenterY) & 0xFF00) | uint16(value))
-	case 0x44: // BG2_MATRIX_CENTER_Y_H
+	case hwdef.BG2_MATRIX_CENTER_Y_H:
 		channel := p.getLayerBoundTransformChannel(2)
 		channel.CenterY = int16((uint16(channel.CenterY) & 0x00FF) | (uint16(value) << 8))
 
 	// BG3 Matrix Mode
-	case 0x45: // BG3_MATRIX_CONTROL
+	case hwdef.BG3_MATRIX_CONTROL:
 		channel := p.getLayerBoundTransformChannel(3)
 		channel.Enabled = (value & 0x01) != 0
 		channel.MirrorH = (value & 0x02) != 0
 		channel.MirrorV = (value & 0x04) != 0
 		channel.OutsideMode = (value >> 3) & 0x3
 		channel.DirectColor = (value & 0x20) != 0
-	case 0x46: // BG3_MATRIX_A_L
+	case hwdef.BG3_MATRIX_A_L:
 		channel := p.getLayerBoundTransformChannel(3)
 		channel.A = int16((uint16(channel.A) & 0xFF00) | uint16(value))
-	case 0x47: // BG3_MATRIX_A_H
+	case hwdef.BG3_MATRIX_A_H:
 		channel := p.getLayerBoundTransformChannel(3)
 		channel.A = int16((uint16(channel.A) & 0x00FF) | (uint16(value) << 8))
-	case 0x48: // BG3_MATRIX_B_L
+	case hwdef.BG3_MATRIX_B_L:
 		channel := p.getLayerBoundTransformChannel(3)
 		channel.B = int16((uint16(channel.B) & 0xFF00) | uint16(value))
-	case 0x49: // BG3_MATRIX_B_H
+	case hwdef.BG3_MATRIX_B_H:
 		channel := p.getLayerBoundTransformChannel(3)
 		channel.B = int16((uint16(channel.B) & 0x00FF) | (uint16(value) << 8))
-	case 0x4A: // BG3_MATRIX_C_L
+	case hwdef.BG3_MATRIX_C_L:
 		channel := p.getLayerBoundTransformChannel(3)
 		channel.C = int16((uint16(channel.C) & 0xFF00) | uint16(value))
-	case 0x4B: // BG3_MATRIX_C_H
+	case hwdef.BG3_MATRIX_C_H:
 		channel := p.getLayerBoundTransformChannel(3)
 		channel.C = int16((uint16(channel.C) & 0x00FF) | (uint16(value) << 8))
-	case 0x4C: // BG3_MATRIX_D_L
+	case hwdef.BG3_MATRIX_D_L:
 		channel := p.getLayerBoundTransformChannel(3)
 		channel.D = int16((uint16(channel.D) & 0xFF00) | uint16(value))
-	case 0x4D: // BG3_MATRIX_D_H
+	case hwdef.BG3_MATRIX_D_H:
 		channel := p.getLayerBoundTransformChannel(3)
 		channel.D = int16((uint16(channel.D) & 0x00FF) | (uint16(value) << 8))
-	case 0x4E: // BG3_MATRIX_CENTER_X_L
+	case hwdef.BG3_MATRIX_CENTER_X_L:
 		channel := p.getLayerBoundTransformChannel(3)
 		channel.CenterX = int16((uint16(channel.CenterX) & 0xFF00) | uint16(value))
-	case 0x4F: // BG3_MATRIX_CENTER_X_H
+	case hwdef.BG3_MATRIX_CENTER_X_H:
 		channel := p.getLayerBoundTransformChannel(3)
 		channel.CenterX = int16((uint16(channel.CenterX) & 0x00FF) | (uint16(value) << 8))
-	case 0x50: // BG3_MATRIX_CENTER_Y_L
+	case hwdef.BG3_MATRIX_CENTER_Y_L:
 		channel := p.getLayerBoundTransformChannel(3)
 		channel.CenterY = int16((uint16(channel.CenterY) & 0xFF00) | uint16(value))
-	case 0x51: // BG3_MATRIX_CENTER_Y_H
+	case hwdef.BG3_MATRIX_CENTER_Y_H:
 		channel := p.getLayerBoundTransformChannel(3)
 		channel.CenterY = int16((uint16(channel.CenterY) & 0x00FF) | (uint16(value) << 8))
 
-	// Windowing (0x52-0x5C)
-	case 0x52: // WINDOW0_LEFT
+	// Windowing (0x8052-0x805C)
+	case hwdef.WINDOW0_LEFT:
 		p.Window0.Left = value
-	case 0x53: // WINDOW0_RIGHT
+	case hwdef.WINDOW0_RIGHT:
 		p.Window0.Right = value
-	case 0x54: // WINDOW0_TOP
+	case hwdef.WINDOW0_TOP:
 		p.Window0.Top = value
-	case 0x55: // WINDOW0_BOTTOM
+	case hwdef.WINDOW0_BOTTOM:
 		p.Window0.Bottom = value
-	case 0x56: // WINDOW1_LEFT
+	case hwdef.WINDOW1_LEFT:
 		p.Window1.Left = value
-	case 0x57: // WINDOW1_RIGHT
+	case hwdef.WINDOW1_RIGHT:
 		p.Window1.Right = value
-	case 0x58: // WINDOW1_TOP
+	case hwdef.WINDOW1_TOP:
 		p.Window1.Top = value
-	case 0x59: // WINDOW1_BOTTOM
+	case hwdef.WINDOW1_BOTTOM:
 		p.Window1.Bottom = value
-	case 0x5A: // WINDOW_CONTROL
+	case hwdef.WINDOW_CONTROL:
 		p.WindowControl = value
-	case 0x5B: // WINDOW_MAIN_ENABLE
+	case hwdef.WINDOW_MAIN_ENABLE:
 		p.WindowMainEnable = value
-	case 0x5C: // WINDOW_SUB_ENABLE
+	case hwdef.WINDOW_SUB_ENABLE:
 		p.WindowSubEnable = value
 
-	// HDMA (0x5D-0x5F)
-	case 0x5D: // HDMA_CONTROL
+	// HDMA (0x805D-0x805F)
+	case hwdef.HDMA_CONTROL:
 		// Bit 0: HDMA enable
 		// Bits 1-4: Layer enable (BG0-BG3)
 		// Bit 5: Rebind table present
@@ -1473,13 +1555,13 @@ func (p *PPU) Write8(offset uint16, value uint8) {
 		// Bit 7: Tilemap-base table present
 		p.HDMAEnabled = (value & 0x01) != 0
 		p.HDMAControl = value
-	case 0x5E: // HDMA_TABLE_BASE_L
+	case hwdef.HDMA_TABLE_BASE_L:
 		p.HDMATableBase = (p.HDMATableBase & 0xFF00) | uint16(value)
-	case 0x5F: // HDMA_TABLE_BASE_H
+	case hwdef.HDMA_TABLE_BASE_H:
 		p.HDMATableBase = (p.HDMATableBase & 0x00FF) | (uint16(value) << 8)
 
-	// DMA registers (0x8060-0x8067, but offset is relative to 0x8000, so 0x60-0x67)
-	case 0x60: // DMA_CONTROL
+	// DMA registers (0x8060-0x8067)
+	case hwdef.DMA_CONTROL:
 		// Bit 0: Enable DMA (1=start transfer, 0=disable)
 		// Bit 1: Mode (0=copy, 1=fill)
 		// Bits [4:2]: Destination type
@@ -1497,224 +1579,236 @@ func (p *PPU) Write8(offset uint16, value uint8) {
 			if p.DMAMode == 1 && p.MemoryReader != nil {
 				p.DMAFillValue = p.MemoryReader(p.DMASourceBank, p.DMASourceOffset)
 			}
+			if p.DMAHook != nil {
+				p.DMAHook(true)
+			}
+			if p.HaltCPU != nil {
+				p.HaltCPU(uint32(p.DMALength) * hwdef.DMAHaltCyclesPerByte)
+			}
 			// Note: DMA will execute incrementally during StepPPU (cycle-accurate)
 		} else {
 			// Disable DMA (abort current transfer)
-			p.DMAEnabled = false
-			p.DMAProgress = 0
+			p.finishDMA()
 		}
-	case 0x61: // DMA_SOURCE_BANK
+	case hwdef.DMA_SOURCE_BANK:
 		p.DMASourceBank = value
-	case 0x62: // DMA_SOURCE_OFFSET_L
+	case hwdef.DMA_SOURCE_OFFSET_L:
 		p.DMASourceOffset = (p.DMASourceOffset & 0xFF00) | uint16(value)
-	case 0x63: // DMA_SOURCE_OFFSET_H
+	case hwdef.DMA_SOURCE_OFFSET_H:
 		p.DMASourceOffset = (p.DMASourceOffset & 0x00FF) | (uint16(value) << 8)
-	case 0x64: // DMA_DEST_ADDR_L
+	case hwdef.DMA_DEST_ADDR_L:
 		p.DMADestAddr = (p.DMADestAddr & 0xFF00) | uint16(value)
-	case 0x65: // DMA_DEST_ADDR_H
+	case hwdef.DMA_DEST_ADDR_H:
 		p.DMADestAddr = (p.DMADestAddr & 0x00FF) | (uint16(value) << 8)
-	case 0x66: // DMA_LENGTH_L
+	case hwdef.DMA_LENGTH_L:
 		p.DMALength = (p.DMALength & 0xFF00) | uint16(value)
-	case 0x67: // DMA_LENGTH_H
+	case hwdef.DMA_LENGTH_H:
 		p.DMALength = (p.DMALength & 0x00FF) | (uint16(value) << 8)
 
-	// Layer source-mode registers (0x68-0x6B)
-	case 0x68: // BG0_SOURCE_MODE
+	// Layer source-mode registers (0x8068-0x806B)
+	case hwdef.BG0_SOURCE_MODE:
 		p.applyLayerSourceModeRegister(0, value)
-	case 0x69: // BG1_SOURCE_MODE
+	case hwdef.BG1_SOURCE_MODE:
 		p.applyLayerSourceModeRegister(1, value)
-	case 0x6A: // BG2_SOURCE_MODE
+	case hwdef.BG2_SOURCE_MODE:
 		p.applyLayerSourceModeRegister(2, value)
-	case 0x6B: // BG3_SOURCE_MODE
+	case hwdef.BG3_SOURCE_MODE:
 		p.applyLayerSourceModeRegister(3, value)
-	case 0x6C: // BG0_TRANSFORM_BIND
+	case hwdef.BG0_TRANSFORM_BIND:
 		p.applyLayerTransformBindRegister(0, value)
-	case 0x6D: // BG1_TRANSFORM_BIND
+	case hwdef.BG1_TRANSFORM_BIND:
 		p.applyLayerTransformBindRegister(1, value)
-	case 0x6E: // BG2_TRANSFORM_BIND
+	case hwdef.BG2_TRANSFORM_BIND:
 		p.applyLayerTransformBindRegister(2, value)
-	case 0x6F: // BG3_TRANSFORM_BIND
+	case hwdef.BG3_TRANSFORM_BIND:
 		p.applyLayerTransformBindRegister(3, value)
-	case 0x77: // BG0_TILEMAP_BASE_L
+	case hwdef.BG0_TILEMAP_BASE_L:
 		p.writeLayerTilemapBaseLow(0, value)
-	case 0x78: // BG0_TILEMAP_BASE_H
+	case hwdef.BG0_TILEMAP_BASE_H:
 		p.writeLayerTilemapBaseHigh(0, value)
-	case 0x79: // BG1_TILEMAP_BASE_L
+	case hwdef.BG1_TILEMAP_BASE_L:
 		p.writeLayerTilemapBaseLow(1, value)
-	case 0x7A: // BG1_TILEMAP_BASE_H
+	case hwdef.BG1_TILEMAP_BASE_H:
 		p.writeLayerTilemapBaseHigh(1, value)
-	case 0x7B: // BG2_TILEMAP_BASE_L
+	case hwdef.BG2_TILEMAP_BASE_L:
 		p.writeLayerTilemapBaseLow(2, value)
-	case 0x7C: // BG2_TILEMAP_BASE_H
+	case hwdef.BG2_TILEMAP_BASE_H:
 		p.writeLayerTilemapBaseHigh(2, value)
-	case 0x7D: // BG3_TILEMAP_BASE_L
+	case hwdef.BG3_TILEMAP_BASE_L:
 		p.writeLayerTilemapBaseLow(3, value)
-	case 0x7E: // BG3_TILEMAP_BASE_H
+	case hwdef.BG3_TILEMAP_BASE_H:
 		p.writeLayerTilemapBaseHigh(3, value)
-	case 0x7F: // HDMA_EXTENSION_CONTROL
+	case hwdef.HDMA_EXTENSION_CONTROL:
 		// Bit 0: Source-mode table present
 		p.HDMAExtControl = value
-	case 0x80: // MATRIX_PLANE_SELECT
+	case hwdef.MATRIX_PLANE_SELECT:
 		p.MatrixPlaneSelect = value & 0x03
-	case 0x81: // MATRIX_PLANE_CONTROL
+	case hwdef.MATRIX_PLANE_CONTROL:
 		// Bit 0: enable, Bits [2:1]: size, Bit 3: source mode (0=tilemap, 1=bitmap), Bits [7:4]: bitmap palette
 		p.applySelectedMatrixPlaneControl(value)
-	case 0x82: // MATRIX_PLANE_ADDR_L
+	case hwdef.MATRIX_PLANE_ADDR_L:
 		p.MatrixPlaneAddr = (p.MatrixPlaneAddr & 0xFF00) | uint16(value)
-	case 0x83: // MATRIX_PLANE_ADDR_H
+	case hwdef.MATRIX_PLANE_ADDR_H:
 		p.MatrixPlaneAddr = (p.MatrixPlaneAddr & 0x00FF) | (uint16(value&0x7F) << 8)
-	case 0x84: // MATRIX_PLANE_DATA
+	case hwdef.MATRIX_PLANE_DATA:
 		plane := p.getSelectedMatrixPlane()
 		addr := int(p.MatrixPlaneAddr) & (len(plane.Tilemap) - 1)
 		plane.Tilemap[addr] = value
 		p.MatrixPlaneAddr = uint16((addr + 1) & (len(plane.Tilemap) - 1))
-	case 0x85: // MATRIX_PLANE_PATTERN_ADDR_L
+	case hwdef.MATRIX_PLANE_PATTERN_ADDR_L:
 		p.writeSelectedMatrixPlanePatternAddrLow(value)
-	case 0x86: // MATRIX_PLANE_PATTERN_ADDR_H
+	case hwdef.MATRIX_PLANE_PATTERN_ADDR_H:
 		p.writeSelectedMatrixPlanePatternAddrHigh(value)
-	case 0x87: // MATRIX_PLANE_PATTERN_DATA
+	case hwdef.MATRIX_PLANE_PATTERN_DATA:
 		plane := p.getSelectedMatrixPlane()
 		addr := int(p.MatrixPlanePatternAddr) & (len(plane.Pattern) - 1)
 		plane.Pattern[addr] = value
 		p.MatrixPlanePatternAddr = uint16((addr + 1) & (len(plane.Pattern) - 1))
-	case 0x88: // MATRIX_PLANE_BITMAP_ADDR_L
+	case hwdef.MATRIX_PLANE_BITMAP_ADDR_L:
 		p.writeSelectedMatrixPlaneBitmapAddrLow(value)
-	case 0x89: // MATRIX_PLANE_BITMAP_ADDR_M
+	case hwdef.MATRIX_PLANE_BITMAP_ADDR_M:
 		p.writeSelectedMatrixPlaneBitmapAddrMid(value)
-	case 0x8A: // MATRIX_PLANE_BITMAP_ADDR_H
+	case hwdef.MATRIX_PLANE_BITMAP_ADDR_H:
 		p.writeSelectedMatrixPlaneBitmapAddrHigh(value)
-	case 0x8B: // MATRIX_PLANE_BITMAP_DATA
+	case hwdef.MATRIX_PLANE_BITMAP_DATA:
 		plane := p.getSelectedMatrixPlane()
 		addr := int(p.MatrixPlaneBitmapAddr) & (len(plane.Bitmap) - 1)
 		plane.Bitmap[addr] = value
 		p.MatrixPlaneBitmapAddr = uint32((addr + 1) & (len(plane.Bitmap) - 1))
-	case 0x8C: // MATRIX_PLANE_FLAGS
+	case hwdef.MATRIX_PLANE_FLAGS:
 		// Bit 0: bitmap palette index 0 is transparent
 		// Bit 1: render both faces for vertical projected quads
 		p.applySelectedMatrixPlaneFlags(value)
-	case 0x8D: // MATRIX_PLANE_ROW_CONTROL
+	case hwdef.MATRIX_PLANE_ROW_CONTROL:
 		p.applySelectedMatrixPlaneRowControl(value)
-	case 0x8E: // MATRIX_PLANE_ROW_ADDR_L
+	case hwdef.MATRIX_PLANE_ROW_ADDR_L:
 		p.writeSelectedMatrixPlaneRowAddrLow(value)
-	case 0x8F: // MATRIX_PLANE_ROW_ADDR_H
+	case hwdef.MATRIX_PLANE_ROW_ADDR_H:
 		p.writeSelectedMatrixPlaneRowAddrHigh(value)
-	case 0x90: // MATRIX_PLANE_ROW_DATA
+	case hwdef.MATRIX_PLANE_ROW_DATA:
 		p.writeSelectedMatrixPlaneRowData(value)
-	case 0x91: // MATRIX_PLANE_PROJECTION_CONTROL
+	case hwdef.MATRIX_PLANE_PROJECTION_CONTROL:
 		p.applySelectedMatrixPlaneProjectionControl(value)
-	case 0x92: // MATRIX_PLANE_HORIZON
+	case hwdef.MATRIX_PLANE_HORIZON:
 		p.getSelectedMatrixPlane().Horizon = value
-	case 0x93: // MATRIX_PLANE_CAMERA_X_L
+	case hwdef.MATRIX_PLANE_CAMERA_X_L:
 		plane := p.getSelectedMatrixPlane()
 		plane.CameraX = int16((uint16(plane.CameraX) & 0xFF00) | uint16(value))
 		if p.Logger != nil {
 			p.Logger.LogPPUf(debug.LogLevelDebug, "PPU MMIO: plane %d CAMERA_X_L=0x%02X -> CameraX=%d", p.MatrixPlaneSelect&0x03, value, plane.CameraX)
 		}
-	case 0x94: // MATRIX_PLANE_CAMERA_X_H
+	case hwdef.MATRIX_PLANE_CAMERA_X_H:
 		plane := p.getSelectedMatrixPlane()
 		plane.CameraX = int16((uint16(plane.CameraX) & 0x00FF) | (uint16(value) << 8))
 		if p.Logger != nil {
 			p.Logger.LogPPUf(debug.LogLevelDebug, "PPU MMIO: plane %d CAMERA_X_H=0x%02X -> CameraX=%d", p.MatrixPlaneSelect&0x03, value, plane.CameraX)
 		}
-	case 0x95: // MATRIX_PLANE_CAMERA_Y_L
+	case hwdef.MATRIX_PLANE_CAMERA_Y_L:
 		plane := p.getSelectedMatrixPlane()
 		plane.CameraY = int16((uint16(plane.CameraY) & 0xFF00) | uint16(value))
 		if p.Logger != nil {
 			p.Logger.LogPPUf(debug.LogLevelDebug, "PPU MMIO: plane %d CAMERA_Y_L=0x%02X -> CameraY=%d", p.MatrixPlaneSelect&0x03, value, plane.CameraY)
 		}
-	case 0x96: // MATRIX_PLANE_CAMERA_Y_H
+	case hwdef.MATRIX_PLANE_CAMERA_Y_H:
 		plane := p.getSelectedMatrixPlane()
 		plane.CameraY = int16((uint16(plane.CameraY) & 0x00FF) | (uint16(value) << 8))
 		if p.Logger != nil {
 			p.Logger.LogPPUf(debug.LogLevelDebug, "PPU MMIO: plane %d CAMERA_Y_H=0x%02X -> CameraY=%d", p.MatrixPlaneSelect&0x03, value, plane.CameraY)
 		}
-	case 0x97: // MATRIX_PLANE_HEADING_X_L
+	case hwdef.MATRIX_PLANE_HEADING_X_L:
 		plane := p.getSelectedMatrixPlane()
 		plane.HeadingX = int16((uint16(plane.HeadingX) & 0xFF00) | uint16(value))
 		if p.Logger != nil {
 			p.Logger.LogPPUf(debug.LogLevelDebug, "PPU MMIO: plane %d HEADING_X_L=0x%02X -> HeadingX=%d", p.MatrixPlaneSelect&0x03, value, plane.HeadingX)
 		}
-	case 0x98: // MATRIX_PLANE_HEADING_X_H
+	case hwdef.MATRIX_PLANE_HEADING_X_H:
 		plane := p.getSelectedMatrixPlane()
 		plane.HeadingX = int16((uint16(plane.HeadingX) & 0x00FF) | (uint16(value) << 8))
 		if p.Logger != nil {
 			p.Logger.LogPPUf(debug.LogLevelDebug, "PPU MMIO: plane %d HEADING_X_H=0x%02X -> HeadingX=%d", p.MatrixPlaneSelect&0x03, value, plane.HeadingX)
 		}
-	case 0x99: // MATRIX_PLANE_HEADING_Y_L
+	case hwdef.MATRIX_PLANE_HEADING_Y_L:
 		plane := p.getSelectedMatrixPlane()
 		plane.HeadingY = int16((uint16(plane.HeadingY) & 0xFF00) | uint16(value))
 		if p.Logger != nil {
 			p.Logger.LogPPUf(debug.LogLevelDebug, "PPU MMIO: plane %d HEADING_Y_L=0x%02X -> HeadingY=%d", p.MatrixPlaneSelect&0x03, value, plane.HeadingY)
 		}
-	case 0x9A: // MATRIX_PLANE_HEADING_Y_H
+	case hwdef.MATRIX_PLANE_HEADING_Y_H:
 		plane := p.getSelectedMatrixPlane()
 		plane.HeadingY = int16((uint16(plane.HeadingY) & 0x00FF) | (uint16(value) << 8))
 		if p.Logger != nil {
 			p.Logger.LogPPUf(debug.LogLevelDebug, "PPU MMIO: plane %d HEADING_Y_H=0x%02X -> HeadingY=%d", p.MatrixPlaneSelect&0x03, value, plane.HeadingY)
 		}
-	case 0x9B: // MATRIX_PLANE_BASE_DISTANCE_L
+	case hwdef.MATRIX_PLANE_BASE_DISTANCE_L:
 		plane := p.getSelectedMatrixPlane()
 		plane.BaseDistance = (plane.BaseDistance & 0xFF00) | uint16(value)
-	case 0x9C: // MATRIX_PLANE_BASE_DISTANCE_H
+	case hwdef.MATRIX_PLANE_BASE_DISTANCE_H:
 		plane := p.getSelectedMatrixPlane()
 		plane.BaseDistance = (plane.BaseDistance & 0x00FF) | (uint16(value) << 8)
-	case 0x9D: // MATRIX_PLANE_FOCAL_LENGTH_L
+	case hwdef.MATRIX_PLANE_FOCAL_LENGTH_L:
 		plane := p.getSelectedMatrixPlane()
 		plane.FocalLength = (plane.FocalLength & 0xFF00) | uint16(value)
-	case 0x9E: // MATRIX_PLANE_FOCAL_LENGTH_H
+	case hwdef.MATRIX_PLANE_FOCAL_LENGTH_H:
 		plane := p.getSelectedMatrixPlane()
 		plane.FocalLength = (plane.FocalLength & 0x00FF) | (uint16(value) << 8)
-	case 0x9F: // MATRIX_PLANE_WIDTH_SCALE_L
+	case hwdef.MATRIX_PLANE_WIDTH_SCALE_L:
 		plane := p.getSelectedMatrixPlane()
 		plane.WidthScale = (plane.WidthScale & 0xFF00) | uint16(value)
-	case 0xA0: // MATRIX_PLANE_WIDTH_SCALE_H
+	case hwdef.MATRIX_PLANE_WIDTH_SCALE_H:
 		plane := p.getSelectedMatrixPlane()
 		plane.WidthScale = (plane.WidthScale & 0x00FF) | (uint16(value) << 8)
-	case 0xA1: // MATRIX_PLANE_ORIGIN_X_L
+	case hwdef.MATRIX_PLANE_ORIGIN_X_L:
 		plane := p.getSelectedMatrixPlane()
 		plane.OriginX = int16((uint16(plane.OriginX) & 0xFF00) | uint16(value))
-	case 0xA2: // MATRIX_PLANE_ORIGIN_X_H
+	case hwdef.MATRIX_PLANE_ORIGIN_X_H:
 		plane := p.getSelectedMatrixPlane()
 		plane.OriginX = int16((uint16(plane.OriginX) & 0x00FF) | (uint16(value) << 8))
-	case 0xA3: // MATRIX_PLANE_ORIGIN_Y_L
+	case hwdef.MATRIX_PLANE_ORIGIN_Y_L:
 		plane := p.getSelectedMatrixPlane()
 		plane.OriginY = int16((uint16(plane.OriginY) & 0xFF00) | uint16(value))
-	case 0xA4: // MATRIX_PLANE_ORIGIN_Y_H
+	case hwdef.MATRIX_PLANE_ORIGIN_Y_H:
 		plane := p.getSelectedMatrixPlane()
 		plane.OriginY = int16((uint16(plane.OriginY) & 0x00FF) | (uint16(value) << 8))
-	case 0xA5: // MATRIX_PLANE_FACING_X_L
+	case hwdef.MATRIX_PLANE_FACING_X_L:
 		plane := p.getSelectedMatrixPlane()
 		plane.FacingX = int16((uint16(plane.FacingX) & 0xFF00) | uint16(value))
-	case 0xA6: // MATRIX_PLANE_FACING_X_H
+	case hwdef.MATRIX_PLANE_FACING_X_H:
 		plane := p.getSelectedMatrixPlane()
 		plane.FacingX = int16((uint16(plane.FacingX) & 0x00FF) | (uint16(value) << 8))
-	case 0xA7: // MATRIX_PLANE_FACING_Y_L
+	case hwdef.MATRIX_PLANE_FACING_Y_L:
 		plane := p.getSelectedMatrixPlane()
 		plane.FacingY = int16((uint16(plane.FacingY) & 0xFF00) | uint16(value))
-	case 0xA8: // MATRIX_PLANE_FACING_Y_H
+	case hwdef.MATRIX_PLANE_FACING_Y_H:
 		plane := p.getSelectedMatrixPlane()
 		plane.FacingY = int16((uint16(plane.FacingY) & 0x00FF) | (uint16(value) << 8))
-	case 0xA9: // MATRIX_PLANE_HEIGHT_SCALE_L
+	case hwdef.MATRIX_PLANE_HEIGHT_SCALE_L:
 		plane := p.getSelectedMatrixPlane()
 		plane.HeightScale = (plane.HeightScale & 0xFF00) | uint16(value)
-	case 0xAA: // MATRIX_PLANE_HEIGHT_SCALE_H
+	case hwdef.MATRIX_PLANE_HEIGHT_SCALE_H:
 		plane := p.getSelectedMatrixPlane()
 		plane.HeightScale = (plane.HeightScale & 0x00FF) | (uint16(value) << 8)
 
-	// Text rendering registers (0x70-0x76)
-	case 0x70: // TEXT_X_L
+	case hwdef.BORDER_COLOR:
+		p.BorderColor = value
+	case hwdef.BG0_LINE_SCROLL_L:
+		p.LineScrollTable = (p.LineScrollTable & 0xFF00) | uint16(value)
+	case hwdef.BG0_LINE_SCROLL_H:
+		p.LineScrollTable = (p.LineScrollTable & 0x00FF) | (uint16(value) << 8)
+
+	// Text rendering registers (0x8070-0x8076)
+	case hwdef.TEXT_X_L:
 		p.TextX = (p.TextX & 0xFF00) | uint16(value)
-	case 0x71: // TEXT_X_H
+	case hwdef.TEXT_X_H:
 		p.TextX = (p.TextX & 0x00FF) | (uint16(value) << 8)
-	case 0x72: // TEXT_Y
+	case hwdef.TEXT_Y:
 		p.TextY = value
-	case 0x73: // TEXT_COLOR_R
+	case hwdef.TEXT_COLOR_R:
 		p.TextR = value
-	case 0x74: // TEXT_COLOR_G
+	case hwdef.TEXT_COLOR_G:
 		p.TextG = value
-	case 0x75: // TEXT_COLOR_B
+	case hwdef.TEXT_COLOR_B:
 		p.TextB = value
-	case 0x76: // TEXT_CHAR - buffers character for end-of-frame rendering, advances X by 8
+	case hwdef.TEXT_CHAR: // buffers character for end-of-frame rendering, advances X by 8
 		if p.textCount < len(p.textCmds) {
 			p.textCmds[p.textCount] = textCmd{
 				x:     int(p.TextX),
@@ -1739,16 +1833,14 @@ func (p *PPU) stepDMA() {
 		// DMA not active or already complete
 		if p.DMAProgress >= p.DMALength {
 			// DMA just completed
-			p.DMAEnabled = false
-			p.DMAProgress = 0
+			p.finishDMA()
 		}
 		return
 	}
 
 	if p.MemoryReader == nil {
 		// No memory reader, abort DMA
-		p.DMAEnabled = false
-		p.DMAProgress = 0
+		p.finishDMA()
 		return
 	}
 
@@ -1761,32 +1853,54 @@ func (p *PPU) stepDMA() {
 		// Copy mode: read from source
 		if p.MemoryReader == nil {
 			// No memory reader, abort DMA
-			p.DMAEnabled = false
-			p.DMAProgress = 0
+			p.finishDMA()
 			return
 		}
 		data = p.MemoryReader(p.DMASourceBank, p.DMACurrentSrc)
 		p.DMACurrentSrc++
 	}
 
-	// Write to destination
+	p.writeDMADest(data)
+
+	// Advance progress
+	p.DMAProgress++
+
+	// Check if DMA is complete
+	if p.DMAProgress >= p.DMALength {
+		p.finishDMA()
+	}
+}
+
+// writeDMADest stores one DMA byte at the current destination and advances
+// it.
+func (p *PPU) writeDMADest(data uint8) {
 	switch p.DMADestType {
 	case 0: // VRAM
 		destAddr := uint32(p.DMACurrentDest)
-		if destAddr < 65536 {
+		if destAddr < 65536 && p.allowVRAMWrite("VRAM", uint16(destAddr), true) {
 			p.VRAM[destAddr] = data
+			if p.VRAMWrite != nil {
+				p.VRAMWrite(uint16(destAddr), data, true)
+			}
 		}
 		p.DMACurrentDest++
 	case 1: // CGRAM
 		// CGRAM is 16-bit (RGB555), so we need to handle it specially
 		// For simplicity, write as 8-bit (low byte only)
 		addr := p.DMACurrentDest & 0x1FF // Wrap at 512 bytes
-		p.CGRAM[addr] = data
-		p.invalidateCGRAMCacheByByteAddr(addr)
+		if p.allowVRAMWrite("CGRAM", addr, true) {
+			p.CGRAM[addr] = data
+			p.invalidateCGRAMCacheByByteAddr(addr)
+		}
 		p.DMACurrentDest++
 	case 2: // OAM
-		addr := p.DMACurrentDest & 0x2FF // Wrap at 768 bytes
-		p.OAM[addr] = data
+		addr := p.DMACurrentDest % uint16(len(p.OAM)) // Wrap at 768 bytes (not a power of two)
+		if p.allowOAMDMA() {
+			p.OAM[addr] = data
+			if p.OAMWrite != nil {
+				p.OAMWrite(addr, data, true)
+			}
+		}
 		p.DMACurrentDest++
 	case 3: // Dedicated matrix-plane tilemap
 		plane := p.getSelectedMatrixPlane()
@@ -1806,14 +1920,32 @@ func (p *PPU) stepDMA() {
 	case 6: // Dedicated matrix-plane row params
 		p.writeSelectedMatrixPlaneRowData(data)
 	}
+}
 
-	// Advance progress
-	p.DMAProgress++
+// InjectDMA writes data to VRAM (destType 0) or CGRAM (destType 1) at dest
+// the way a DMA transfer would, for debugger tools patching a running ROM.
+// It happens at once, ignores VRAMAccess, and leaves the ROM's own DMA
+// registers and any transfer in progress as they were.
+func (p *PPU) InjectDMA(destType uint8, dest uint16, data []byte) error {
+	if destType > 1 {
+		return fmt.Errorf("InjectDMA: destination type %d is not VRAM or CGRAM", destType)
+	}
+	savedType, savedDest, savedAccess := p.DMADestType, p.DMACurrentDest, p.VRAMAccess
+	p.DMADestType, p.DMACurrentDest, p.VRAMAccess = destType, dest, VRAMAccessOpen
+	for _, b := range data {
+		p.writeDMADest(b)
+	}
+	p.DMADestType, p.DMACurrentDest, p.VRAMAccess = savedType, savedDest, savedAccess
+	return nil
+}
 
-	// Check if DMA is complete
-	if p.DMAProgress >= p.DMALength {
-		p.DMAEnabled = false
-		p.DMAProgress = 0
+// finishDMA ends the current DMA transfer, completed or aborted.
+func (p *PPU) finishDMA() {
+	active := p.DMAEnabled
+	p.DMAEnabled = false
+	p.DMAProgress = 0
+	if active && p.DMAHook != nil {
+		p.DMAHook(false)
 	}
 }
 
@@ -1972,10 +2104,7 @@ func (p *PPU) RenderFrame() {
 		p.Logger.LogPPUf(debug.LogLevelDebug,
 			"Sprite 0 OAM: OAM[0-5]=0x%02X 0x%02X 0x%02X 0x%02X 0x%02X 0x%02X",
 			p.OAM[0], p.OAM[1], p.OAM[2], p.OAM[3], p.OAM[4], p.OAM[5])
-		spriteX := int(p.OAM[0])
-		if (p.OAM[1] & 0x01) != 0 {
-			spriteX |= 0xFFFFFF00
-		}
+		spriteX := p.SpriteX(0)
 		spriteY := int(p.OAM[2])
 		tileIndex := uint8(p.OAM[3])
 		attributes := uint8(p.OAM[4])
@@ -2045,6 +2174,10 @@ func (p *PPU) renderBackgroundLayer(layerNum int) {
 
 	// Render each pixel
 	for y := 0; y < 200; y++ {
+		lineScroll := 0
+		if layerNum == 0 {
+			lineScroll = int(p.lineScrollEntry(y))
+		}
 		for x := 0; x < 320; x++ {
 			// Check windowing
 			if !p.isPixelInWindow(x, y, layerNum) {
@@ -2053,7 +2186,7 @@ func (p *PPU) renderBackgroundLayer(layerNum int) {
 
 			// Calculate tilemap coordinates with scroll
 			// Screen pixel (x, y) -> world pixel (worldX, worldY)
-			worldX := int(x) + int(layer.ScrollX)
+			worldX := int(x) + int(layer.ScrollX) + lineScroll
 			worldY := int(y) + int(layer.ScrollY)
 
 			// Wrap coordinates (tilemap repeats)
@@ -2106,6 +2239,20 @@ func (p *PPU) renderBackgroundLayer(layerNum int) {
 				pixelYInTile = tileSize - 1 - pixelYInTile
 			}
 
+			// 8bpp formats: one byte per pixel
+			if format := layer.ColorFormat(); format != BGFormat4bpp {
+				pixel, ok := p.bg8bppPixel(tileIndex, attributes, tileSize, pixelXInTile, pixelYInTile)
+				switch {
+				case !ok:
+					p.OutputBuffer[y*320+x] = 0x000000
+				case format == BGFormatDirect8:
+					p.OutputBuffer[y*320+x] = directColorRGB333(pixel, attributes)
+				default:
+					p.OutputBuffer[y*320+x] = p.getColorFromCGRAM(pixel>>4, pixel&0x0F)
+				}
+				continue
+			}
+
 			// Read tile data (4bpp = 2 pixels per byte)
 			// Tile data starts at VRAM offset = tileIndex * (tileSize * tileSize / 2)
 			tileDataOffset := uint16(tileIndex) * uint16(tileSize*tileSize/2)
@@ -2158,17 +2305,10 @@ func (p *PPU) renderSprites() {
 		oamAddr := spriteIndex * 6
 
 		// Read sprite data
-		// Byte 0: X position (low byte, unsigned)
-		xLow := uint8(p.OAM[oamAddr])
-		// Byte 1: X position (high byte, bit 0 only, sign extends)
+		// Bytes 0-1: 9-bit signed X (low byte, then sign in bit 0 of the
+		// X-high byte, which also carries the size code)
 		xHigh := uint8(p.OAM[oamAddr+1])
-		// Combine X position: 9-bit signed value
-		// Low 8 bits from byte 0, sign bit from bit 0 of byte 1
-		spriteX := int(xLow)
-		if (xHigh & 0x01) != 0 {
-			// Sign extend (negative value)
-			spriteX |= 0xFFFFFF00
-		}
+		spriteX := SpriteXFromOAM(p.OAM[oamAddr], xHigh)
 
 		// Byte 2: Y position (8-bit, 0-255)
 		spriteY := int(p.OAM[oamAddr+2])
@@ -2209,9 +2349,9 @@ func (p *PPU) renderSprites() {
 		// Render sprite pixels
 		for py := 0; py < spriteHeight; py++ {
 			for px := 0; px < spriteWidth; px++ {
-				// Calculate screen position
-				screenX := spriteX + px
-				screenY := spriteY + py
+				// Calculate screen position (wrapping, see SpriteXMin)
+				screenX := (spriteX + px) & (spriteXWrap - 1)
+				screenY := (spriteY + py) & (spriteYWrap - 1)
 
 				// Check bounds
 				if screenX < 0 || screenX >= 320 || screenY < 0 || screenY >= 200 {
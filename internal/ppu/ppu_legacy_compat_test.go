@@ -364,8 +364,8 @@ func TestLayerSourceModeRegisterRoundTrip(t *testing.T) {
 
 	ppu.Write8(0x68, 0x01)
 	ppu.Write8(0x69, 0x00)
-	ppu.Write8(0x6A, 0x03) // masked to bitmap mode
-	ppu.Write8(0x6B, 0x02) // masked to tilemap mode
+	ppu.Write8(0x6A, 0x09) // bit 3 is masked off: bitmap mode, 4bpp
+	ppu.Write8(0x6B, 0x08) // bit 3 is masked off: tilemap mode, 4bpp
 
 	if ppu.BG0.SourceMode != 1 {
 		t.Fatalf("BG0.SourceMode = %d, want 1", ppu.BG0.SourceMode)
@@ -0,0 +1,55 @@
+package ppu
+
+import "testing"
+
+// TestPriorityViewTintsByWinningLayer draws BG0 under BG1 (higher priority
+// value, so it renders on top) and a sprite above both, then checks which
+// layer each pixel reports and that the finished frame took the tints.
+func TestPriorityViewTintsByWinningLayer(t *testing.T) {
+	p := NewPPU(nil)
+	p.CGRAM[2], p.CGRAM[3] = 0xFF, 0x7F // palette 0 color 1 = white
+	for i := 0; i < 32; i++ {
+		p.VRAM[32+i] = 0x11 // tile 1: solid color 1
+	}
+	p.BG0.Enabled, p.BG0.Priority, p.BG0.TilemapBase = true, 0, 0x4000
+	p.BG1.Enabled, p.BG1.Priority, p.BG1.TilemapBase = true, 1, 0x5000
+	p.VRAM[0x5000] = 1 // BG1 tile (0,0) = tile 1, the rest tile 0 (black)
+	p.OAM[0], p.OAM[2], p.OAM[3], p.OAM[4], p.OAM[5] = 20, 4, 1, 0xC0, 0x01
+
+	p.PriorityView = true
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 32; x++ {
+			p.renderDot(y, x)
+		}
+	}
+	p.BG0.Enabled, p.BG1.Enabled = false, false
+	p.renderDot(12, 12)
+
+	for _, c := range []struct {
+		x, y int
+		want PriorityLayer
+	}{
+		{2, 2, PriorityBG1},
+		{12, 2, PriorityBG1},
+		{22, 6, PrioritySprite},
+		{12, 12, PriorityBackdrop},
+	} {
+		if got := p.PriorityLayerAt(c.x, c.y); got != c.want {
+			t.Errorf("PriorityLayerAt(%d, %d) = %v, want %v", c.x, c.y, got, c.want)
+		}
+	}
+
+	p.endFrame()
+	for _, c := range []struct {
+		x, y int
+		want uint32
+	}{
+		{2, 2, PriorityViewTints[PriorityBG1]}, // white keeps the full tint
+		{12, 2, 0x186018},                      // black dims it
+		{12, 12, 0x181818},
+	} {
+		if got := p.DisplayBuffer[c.y*320+c.x]; got != c.want {
+			t.Errorf("pixel (%d, %d) = 0x%06X, want 0x%06X", c.x, c.y, got, c.want)
+		}
+	}
+}
@@ -20,7 +20,8 @@ const (
 
 	// Frame timing
 	// Visible scanlines: 200
-	// VBlank scanlines: 20 (total 220 scanlines per frame)
+	// VBlank scanlines: 20 (total 220 scanlines per frame); the 50 Hz
+	// timing mode has 64 (see timing.go)
 	VisibleScanlines = 200
 	VBlankScanlines  = 20
 	TotalScanlines   = 220
@@ -234,7 +235,7 @@ func (p *PPU) decodeScanlineCommands(scanline int) scanlineCommandSet {
 				sourceMode := p.VRAM[uint16(sourceModeAddr)]
 				if sourceMode != hdmaSourceModeSentinelKeep {
 					command.ApplySourceMode = true
-					command.SourceMode = sourceMode & 0x01
+					command.SourceMode = sourceMode & bgSourceModeMask
 				}
 			}
 		}
@@ -265,7 +266,7 @@ func (p *PPU) applyScanlineCommands(scanline int, commands scanlineCommandSet) {
 			layer.TilemapBase = command.TilemapBase
 		}
 		if command.ApplySourceMode {
-			layer.SourceMode = command.SourceMode & 0x01
+			layer.SourceMode = command.SourceMode & bgSourceModeMask
 		}
 		layer, channel := p.resolveLayerTransformChannel(layerNum)
 		if layer == nil || channel == nil {
@@ -325,6 +326,7 @@ func (p *PPU) StepPPU(cycles uint64) error {
 		if p.currentDot == 0 && p.currentScanline < VisibleScanlines {
 			p.evaluateSpritesForScanline(p.currentScanline)
 			p.fillResolvedLayerChannelCache()
+			p.latchLineScroll(p.currentScanline)
 		}
 
 		// Calculate cycles until end of current scanline
@@ -390,7 +392,7 @@ func (p *PPU) StepPPU(cycles uint64) error {
 			p.currentScanline++
 
 			// Check if frame is complete
-			if p.currentScanline >= TotalScanlines {
+			if p.currentScanline >= p.Timing.Scanlines() {
 				p.endFrame()
 				p.currentScanline = 0
 				p.frameStarted = false
@@ -427,6 +429,10 @@ func (p *PPU) stepDot() error {
 		p.FrameComplete = false
 	}
 
+	if p.currentDot == 0 {
+		p.latchLineScroll(p.currentScanline)
+	}
+
 	// Render current dot if in visible area
 	if p.currentScanline < VisibleScanlines && p.currentDot < VisibleDots {
 		// Render this pixel
@@ -462,7 +468,7 @@ func (p *PPU) stepDot() error {
 
 		p.currentScanline++
 
-		if p.currentScanline >= TotalScanlines {
+		if p.currentScanline >= p.Timing.Scanlines() {
 			// End of frame
 			p.endFrame()
 			p.currentScanline = 0
@@ -500,6 +506,10 @@ func (p *PPU) endScanline() {
 
 // endFrame is called at the end of each frame
 func (p *PPU) endFrame() {
+	if p.PriorityView {
+		p.applyPriorityView()
+	}
+
 	// Render buffered text commands on top of the finished frame
 	for i := 0; i < p.textCount; i++ {
 		cmd := &p.textCmds[i]
@@ -509,6 +519,7 @@ func (p *PPU) endFrame() {
 
 	// Copy to display buffer (front buffer) so startFrame can safely clear the back buffer
 	copy(p.DisplayBuffer[:], p.OutputBuffer[:])
+	p.DisplayBorder = p.getColorFromCGRAM(p.BorderColor>>4, p.BorderColor&0x0F)
 
 	p.FrameComplete = true
 }
@@ -566,6 +577,7 @@ func (p *PPU) renderDot(scanline, dot int) {
 		}
 	}
 
+	p.dotWinner = PriorityBackdrop
 	for _, elem := range elements {
 		if elem.elementType == 0 {
 			layerNum := elem.layerNum
@@ -582,6 +594,9 @@ func (p *PPU) renderDot(scanline, dot int) {
 			p.renderDotSpritePixel(x, y, &p.spriteScratch[elem.spriteIndex])
 		}
 	}
+	if p.PriorityView {
+		p.priorityWinners[y*320+x] = p.dotWinner
+	}
 }
 
 // collectSpritesAtPixel collects all sprites that overlap the given pixel
@@ -599,7 +614,7 @@ func (p *PPU) collectSpritesAtPixel(x, y int, sprites []spriteInfo) int {
 		s := active[i]
 
 		// Scanline membership is already pre-evaluated. Only X bounds need per-pixel check.
-		if x < s.x || x >= s.x+s.width {
+		if spriteLocal(x, s.x, spriteXWrap) >= s.width {
 			continue
 		}
 
@@ -622,12 +637,8 @@ func (p *PPU) evaluateSpritesForScanline(y int) {
 	for spriteIndex := 0; spriteIndex < 128; spriteIndex++ {
 		oamAddr := spriteIndex * 6
 
-		xLow := uint8(p.OAM[oamAddr])
 		xHigh := uint8(p.OAM[oamAddr+1])
-		spriteX := int(xLow)
-		if (xHigh & 0x01) != 0 {
-			spriteX |= 0xFFFFFF00
-		}
+		spriteX := SpriteXFromOAM(p.OAM[oamAddr], xHigh)
 
 		spriteY := int(p.OAM[oamAddr+2])
 		tileIndex := uint8(p.OAM[oamAddr+3])
@@ -641,7 +652,7 @@ func (p *PPU) evaluateSpritesForScanline(y int) {
 		sizeCode := spriteSizeCodeFromOAM(xHigh, control)
 		dims := spriteSizeTable[sizeCode]
 		width, height := dims[0], dims[1]
-		if y < spriteY || y >= spriteY+height {
+		if spriteLocal(y, spriteY, spriteYWrap) >= height {
 			continue
 		}
 
@@ -773,8 +784,8 @@ func max(a, b int16) int16 {
 // renderDotSpritePixel renders a single sprite pixel with blending support
 func (p *PPU) renderDotSpritePixel(x, y int, sprite *spriteInfo) {
 	// Calculate tile coordinates
-	px := x - sprite.x
-	py := y - sprite.y
+	px := spriteLocal(x, sprite.x, spriteXWrap)
+	py := spriteLocal(y, sprite.y, spriteYWrap)
 
 	// Apply flip
 	flipX := (sprite.attributes & 0x10) != 0
@@ -829,6 +840,7 @@ func (p *PPU) renderDotSpritePixel(x, y int, sprite *spriteInfo) {
 		blendedColor := p.blendColor(spriteColor, backgroundColor, blendMode, alpha)
 		p.OutputBuffer[y*320+x] = blendedColor
 	}
+	p.dotWinner = PrioritySprite
 }
 
 // renderDotBackgroundLayer renders a single dot for a background layer
@@ -860,6 +872,9 @@ func (p *PPU) renderDotBackgroundLayer(layerNum, x, y int) {
 	// Calculate tilemap coordinates with scroll
 	worldX := int(x) + int(layer.ScrollX)
 	worldY := int(y) + int(layer.ScrollY)
+	if layerNum == 0 {
+		worldX += int(p.lineScrollX)
+	}
 
 	// Tile size and tilemap dimensions are powers of two, so wrapping/division can use
 	// masks/shifts (hardware-friendly and faster than modulo/division).
@@ -920,29 +935,41 @@ func (p *PPU) renderDotBackgroundLayer(layerNum, x, y int) {
 		pixelYInTile = tileSize - 1 - pixelYInTile
 	}
 
-	// Read tile data
-	tileDataOffset := uint16(tileIndex) << tileBytesShift
-	pixelOffsetInTile := (pixelYInTile << tileShift) | pixelXInTile
-	byteOffsetInTile := pixelOffsetInTile >> 1
-	pixelInByte := pixelOffsetInTile & 0x01
+	var colorIndex uint8
+	format := layer.ColorFormat()
+	if format == BGFormat4bpp {
+		// Read tile data
+		tileDataOffset := uint16(tileIndex) << tileBytesShift
+		pixelOffsetInTile := (pixelYInTile << tileShift) | pixelXInTile
+		byteOffsetInTile := pixelOffsetInTile >> 1
+		pixelInByte := pixelOffsetInTile & 0x01
 
-	if uint32(tileDataOffset)+uint32(byteOffsetInTile) >= 65536 {
-		return
-	}
-	tileDataAddr := tileDataOffset + uint16(byteOffsetInTile)
+		if uint32(tileDataOffset)+uint32(byteOffsetInTile) >= 65536 {
+			return
+		}
+		tileDataAddr := tileDataOffset + uint16(byteOffsetInTile)
 
-	// Read pixel color index
-	tileByte := p.VRAM[tileDataAddr]
-	var colorIndex uint8
-	if pixelInByte == 0 {
-		colorIndex = (tileByte >> 4) & 0x0F
+		// Read pixel color index
+		tileByte := p.VRAM[tileDataAddr]
+		if pixelInByte == 0 {
+			colorIndex = (tileByte >> 4) & 0x0F
+		} else {
+			colorIndex = tileByte & 0x0F
+		}
 	} else {
-		colorIndex = tileByte & 0x0F
+		// 8bpp: one byte per pixel, split into the CGRAM palette/color pair.
+		pixel, ok := p.bg8bppPixel(tileIndex, attributes, tileSize, pixelXInTile, pixelYInTile)
+		if !ok {
+			return
+		}
+		paletteIndex, colorIndex = pixel>>4, pixel&0x0F
 	}
 
 	// Look up color in CGRAM, or synthesize direct color if enabled.
 	color := p.getColorFromCGRAM(paletteIndex, colorIndex)
-	if _, channel := p.resolveLayerTransformChannel(layerNum); channel != nil && channel.DirectColor {
+	if format == BGFormatDirect8 {
+		color = directColorRGB333(paletteIndex<<4|colorIndex, attributes)
+	} else if _, channel := p.resolveLayerTransformChannel(layerNum); channel != nil && channel.DirectColor {
 		// Synthesize RGB from palette+pixel index to bypass CGRAM.
 		combined := (uint16(paletteIndex&0x0F) << 4) | uint16(colorIndex&0x0F)
 		r5 := uint32(combined&0x07) * 31 / 7
@@ -968,6 +995,7 @@ func (p *PPU) renderDotBackgroundLayer(layerNum, x, y int) {
 	}
 
 	p.OutputBuffer[y*320+x] = color
+	p.dotWinner = PriorityBG0 + PriorityLayer(layerNum)
 }
 
 func (p *PPU) matrixPlaneSourceDimensions(layer *BackgroundLayer, plane *MatrixPlane) (width, height, tileSize int) {
@@ -1335,6 +1363,7 @@ func (p *PPU) renderDotMatrixMode(layerNum, x, y int) {
 			return
 		}
 		p.OutputBuffer[y*320+x] = color
+		p.dotWinner = PriorityBG0 + PriorityLayer(layerNum)
 		return
 	}
 
@@ -1361,6 +1390,7 @@ func (p *PPU) renderDotMatrixMode(layerNum, x, y int) {
 			// Use backdrop color (CGRAM palette 0, color 0)
 			backdropColor := p.getColorFromCGRAM(0, 0)
 			p.OutputBuffer[y*320+x] = backdropColor
+			p.dotWinner = PriorityBG0 + PriorityLayer(layerNum)
 			return
 		case 2: // Character #0 mode - render tile 0
 			// Force tile index to 0
@@ -1412,6 +1442,7 @@ func (p *PPU) renderDotMatrixMode(layerNum, x, y int) {
 	}
 
 	p.OutputBuffer[y*320+x] = color
+	p.dotWinner = PriorityBG0 + PriorityLayer(layerNum)
 }
 
 // spriteInfo holds sprite data for priority sorting
@@ -133,7 +133,7 @@ func (p *PPU) WriteScanlineCommandProgram(scanline int, program ScanlineCommandP
 		for layerNum := 0; layerNum < 4; layerNum++ {
 			value := uint8(hdmaSourceModeSentinelKeep)
 			if program.Layers[layerNum].HasSourceMode {
-				value = program.Layers[layerNum].SourceMode & 0x01
+				value = program.Layers[layerNum].SourceMode & bgSourceModeMask
 			}
 			p.VRAM[uint16(sourceModeBase+uint32(layerNum))] = value
 		}
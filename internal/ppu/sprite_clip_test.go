@@ -0,0 +1,155 @@
+package ppu
+
+import (
+	"fmt"
+	"testing"
+
+	"nitro-core-dx/internal/debug"
+)
+
+// clipTestColor is the color index of sprite pixel (tx, ty) in the
+// clipping tests: never 0 (transparent) and different for neighboring
+// rows and columns, so an off-by-one or a wrong flip shows up as a wrong
+// color rather than a missing pixel.
+func clipTestColor(tx, ty int) uint8 {
+	return uint8(1 + (tx*3+ty*5)%15)
+}
+
+// paintClipTestSprite fills the VRAM a sprite of sizeCode at tileIndex
+// reads from, going through spriteTileByteOffset so it works for both the
+// legacy and the tile-grid layouts.
+func paintClipTestSprite(ppu *PPU, sizeCode, tileIndex uint8) {
+	w, h := spriteSizeTable[sizeCode][0], spriteSizeTable[sizeCode][1]
+	for ty := 0; ty < h; ty++ {
+		for tx := 0; tx < w; tx++ {
+			off, half := spriteTileByteOffset(sizeCode, tileIndex, w, h, tx, ty)
+			c := clipTestColor(tx, ty)
+			if half == 0 {
+				ppu.VRAM[off] = ppu.VRAM[off]&0x0F | c<<4
+			} else {
+				ppu.VRAM[off] = ppu.VRAM[off]&0xF0 | c
+			}
+		}
+	}
+}
+
+// wantClipFrame is the reference image: sprite pixel (px, py) lands on
+// ((x+px) mod 512, (y+py) mod 256) and is kept only if that is on screen.
+func wantClipFrame(ppu *PPU, x, y int, sizeCode uint8, flipX, flipY bool) []uint32 {
+	w, h := spriteSizeTable[sizeCode][0], spriteSizeTable[sizeCode][1]
+	want := make([]uint32, ScreenWidth*ScreenHeight)
+	for py := 0; py < h; py++ {
+		for px := 0; px < w; px++ {
+			sx := ((x+px)%512 + 512) % 512
+			sy := (y + py) % 256
+			if sx >= ScreenWidth || sy >= ScreenHeight {
+				continue
+			}
+			tx, ty := px, py
+			if flipX {
+				tx = w - 1 - px
+			}
+			if flipY {
+				ty = h - 1 - py
+			}
+			want[sy*ScreenWidth+sx] = ppu.getColorFromCGRAM(0, clipTestColor(tx, ty))
+		}
+	}
+	return want
+}
+
+// TestSpriteClippingAtScreenEdges draws sprites hanging off each edge and
+// corner of the screen, in several sizes and every flip, and checks the
+// whole frame pixel for pixel: the visible part must be exactly the right
+// rows and columns of the sprite, and nothing may leak anywhere else.
+func TestSpriteClippingAtScreenEdges(t *testing.T) {
+	positions := []struct {
+		name string
+		x, y int
+	}{
+		{"inside", 100, 80},
+		{"left", -3, 80},
+		{"right", ScreenWidth - 5, 80},
+		{"top", 100, 256 - 4},
+		{"bottom", 100, ScreenHeight - 5},
+		{"top-left", -6, 256 - 2},
+		{"top-right", ScreenWidth - 2, 256 - 7},
+		{"bottom-left", -1, ScreenHeight - 1},
+		{"bottom-right", ScreenWidth - 1, ScreenHeight - 3},
+		{"off-left", -16, 80},
+		{"off-right", ScreenWidth, 80},
+		{"off-bottom", 100, ScreenHeight},
+	}
+	sizes := []uint8{0, 1, 2} // 8x8, 16x16 (both legacy layout), 32x16 (tile grid)
+	flips := []struct {
+		name         string
+		flipX, flipY bool
+	}{
+		{"noflip", false, false},
+		{"flipX", true, false},
+		{"flipY", false, true},
+		{"flipXY", true, true},
+	}
+
+	ppu := NewPPU(debug.NewLogger(1000))
+	for c := uint8(1); c < 16; c++ {
+		setPaletteColor(ppu, 0, c, uint16(c)*0x0842)
+	}
+	const tileIndex = 2
+	for _, size := range sizes {
+		for i := range ppu.VRAM {
+			ppu.VRAM[i] = 0
+		}
+		paintClipTestSprite(ppu, size, tileIndex)
+		w, h := spriteSizeTable[size][0], spriteSizeTable[size][1]
+		for _, pos := range positions {
+			for _, fl := range flips {
+				t.Run(fmt.Sprintf("%dx%d/%s/%s", w, h, pos.name, fl.name), func(t *testing.T) {
+					setSpriteOAM(ppu, 0, pos.x, uint8(pos.y), tileIndex, 0, 1, size)
+					if fl.flipX {
+						ppu.OAM[4] |= 0x10
+					}
+					if fl.flipY {
+						ppu.OAM[4] |= 0x20
+					}
+					want := wantClipFrame(ppu, pos.x, pos.y, size, fl.flipX, fl.flipY)
+
+					for i := range ppu.OutputBuffer {
+						ppu.OutputBuffer[i] = 0
+					}
+					ppu.activeScanlineY = -1
+					for y := 0; y < ScreenHeight; y++ {
+						for x := 0; x < ScreenWidth; x++ {
+							ppu.renderDot(y, x)
+						}
+					}
+					checkClipFrame(t, "scanline renderer", ppu.OutputBuffer[:], want)
+
+					// The frame-at-once renderer must clip the same way.
+					for i := range ppu.OutputBuffer {
+						ppu.OutputBuffer[i] = 0
+					}
+					ppu.renderSprites()
+					checkClipFrame(t, "frame renderer", ppu.OutputBuffer[:], want)
+				})
+			}
+		}
+	}
+}
+
+func checkClipFrame(t *testing.T, renderer string, got, want []uint32) {
+	t.Helper()
+	bad := 0
+	for i := range want {
+		if got[i] == want[i] {
+			continue
+		}
+		if bad < 5 {
+			t.Errorf("%s: pixel (%d,%d) = 0x%06X, want 0x%06X", renderer, i%ScreenWidth, i/ScreenWidth, got[i], want[i])
+		}
+		bad++
+	}
+	if bad > 5 {
+		t.Errorf("%s: %d pixels wrong in total", renderer, bad)
+	}
+}
@@ -10,13 +10,9 @@ import (
 // into the X-high byte's bits [3:1] (see spriteSizeTable's doc comment).
 func setSpriteOAM(ppu *PPU, index int, x int, y, tile, palette, priority, sizeCode uint8) {
 	oamAddr := index * 6
-	ppu.OAM[oamAddr] = uint8(x & 0xFF)
-	xHigh := uint8(0)
-	if x < 0 {
-		xHigh |= 0x01
-	}
-	xHigh |= (sizeCode & 0x07) << 1
-	ppu.OAM[oamAddr+1] = xHigh
+	xLow, sign := EncodeSpriteX(x)
+	ppu.OAM[oamAddr] = xLow
+	ppu.OAM[oamAddr+1] = sign | (sizeCode&0x07)<<1
 	ppu.OAM[oamAddr+2] = y
 	ppu.OAM[oamAddr+3] = tile
 	ppu.OAM[oamAddr+4] = (palette & 0x0F) | (priority << 6)
@@ -0,0 +1,68 @@
+package ppu
+
+// Sprite X is a 9-bit two's-complement value split across the first two
+// OAM bytes: byte 0 holds the low 8 bits and bit 0 of byte 1 is the sign
+// (bit 8). Bits [3:1] of byte 1 are the size code (see
+// spriteSizeCodeFromOAM), so writers must merge the sign bit in rather
+// than overwrite the byte. A sprite at X = -5 has its leftmost 5 columns
+// off the left edge of the screen.
+//
+// Positions wrap: X modulo 512 and Y (one unsigned byte) modulo 256, so a
+// sprite hangs off the right or bottom edge by running past the screen and
+// off the left or top edge by starting just short of the wrap point. With a
+// 320-pixel screen, X 256-319 are on screen but encode like -256..-193
+// (EncodeSpriteX(300) == EncodeSpriteX(-212)), and a 16-row sprite at
+// Y = 250 shows its last 10 rows at the top of the screen.
+const (
+	SpriteXMin = -256
+	SpriteXMax = 255
+
+	spriteXWrap = 512
+	spriteYWrap = 256
+)
+
+// SpriteXFromOAM decodes a sprite X position from OAM bytes 0 and 1. Only
+// bit 0 of xHigh is consulted.
+func SpriteXFromOAM(xLow, xHigh uint8) int {
+	x := int(xLow)
+	if xHigh&0x01 != 0 {
+		x -= 0x100
+	}
+	return x
+}
+
+// EncodeSpriteX splits x into OAM byte 0 and the sign bit for byte 1.
+// Values outside SpriteXMin..SpriteXMax wrap as the 9-bit field does.
+func EncodeSpriteX(x int) (xLow, signBit uint8) {
+	return uint8(x), uint8(x>>8) & 0x01
+}
+
+// spriteLocal returns the column (or row) of a sprite at origin that lands
+// on screen position pos, given the axis' wrap; callers compare it against
+// the sprite's width (or height).
+func spriteLocal(pos, origin, wrap int) int {
+	return (pos - origin) & (wrap - 1)
+}
+
+// SpriteX returns the decoded X position of OAM entry index (0-127).
+func (p *PPU) SpriteX(index int) int {
+	return SpriteXFromOAM(p.OAM[index*6], p.OAM[index*6+1])
+}
+
+// SpriteBounds decodes OAM entry index (0-127) as the renderer does: its
+// position, its size in pixels and whether it is enabled.
+func (p *PPU) SpriteBounds(index int) (x, y, width, height int, enabled bool) {
+	base := index * 6
+	xHigh, control := p.OAM[base+1], p.OAM[base+5]
+	dims := spriteSizeTable[spriteSizeCodeFromOAM(xHigh, control)]
+	return SpriteXFromOAM(p.OAM[base], xHigh), int(p.OAM[base+2]), dims[0], dims[1], control&0x01 != 0
+}
+
+// SpriteScreenPos maps a pixel at (x, y) relative to a sprite's origin
+// (ox, oy) onto the screen, wrapping as sprite positions do. ok is false
+// when it lands off screen.
+func SpriteScreenPos(ox, oy, x, y int) (sx, sy int, ok bool) {
+	sx = (ox + x) & (spriteXWrap - 1)
+	sy = (oy + y) & (spriteYWrap - 1)
+	return sx, sy, sx < 320 && sy < 200
+}
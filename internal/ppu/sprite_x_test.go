@@ -0,0 +1,45 @@
+package ppu
+
+import (
+	"testing"
+
+	"nitro-core-dx/internal/debug"
+)
+
+func TestSpriteXRoundTrip(t *testing.T) {
+	for _, x := range []int{SpriteXMin, -129, -8, -1, 0, 1, 127, 200, SpriteXMax} {
+		xLow, sign := EncodeSpriteX(x)
+		// Size-code bits in the X-high byte must not affect X.
+		if got := SpriteXFromOAM(xLow, sign|0x0E); got != x {
+			t.Errorf("X %d: encoded %02X/%d, decoded %d", x, xLow, sign, got)
+		}
+	}
+	if xLow, sign := EncodeSpriteX(-5); xLow != 0xFB || sign != 1 {
+		t.Errorf("EncodeSpriteX(-5) = %02X, %d; want FB, 1", xLow, sign)
+	}
+}
+
+// TestSpriteNegativeXDrawsVisibleColumns checks that a sprite with a
+// negative X shows its right-hand columns at the left edge instead of
+// vanishing (the sign bit used to be widened to a large positive X).
+func TestSpriteNegativeXDrawsVisibleColumns(t *testing.T) {
+	ppu := NewPPU(debug.NewLogger(1000))
+	setPaletteColor(ppu, 0, 1, 0x001F)
+	fill8x8Tile(ppu, 4, 1)
+	setSpriteOAM(ppu, 0, -5, 40, 4, 0, 1, 0) // 8x8: columns 5-7 on screen
+
+	if got := ppu.SpriteX(0); got != -5 {
+		t.Fatalf("SpriteX(0) = %d, want -5", got)
+	}
+	want := ppu.getColorFromCGRAM(0, 1)
+	for x := 0; x < 6; x++ {
+		ppu.renderDot(40, x)
+		got := ppu.OutputBuffer[40*320+x]
+		if x < 3 && got != want {
+			t.Errorf("x=%d: got 0x%06X, want sprite color 0x%06X", x, got, want)
+		}
+		if x >= 3 && got != 0 {
+			t.Errorf("x=%d: got 0x%06X, want transparent past the sprite's right edge", x, got)
+		}
+	}
+}
@@ -56,13 +56,21 @@ var font8x8 = map[rune][8]uint8{
 	'#':  {0x36, 0x36, 0x7F, 0x36, 0x7F, 0x36, 0x36, 0x00},
 }
 
-// drawChar renders a single character at the specified pixel position directly
-// into the output buffer. Used by the text MMIO registers.
-func (p *PPU) drawChar(char rune, x, y int, color uint32) {
+// Glyph returns the font's 8x8 bitmap for char, one byte per row with bit 0
+// the leftmost pixel. Lowercase letters use the uppercase glyphs; ok is
+// false for characters the font does not have.
+func Glyph(char rune) (glyph [8]uint8, ok bool) {
 	if char >= 'a' && char <= 'z' {
 		char = char - 'a' + 'A'
 	}
-	glyph, ok := font8x8[char]
+	glyph, ok = font8x8[char]
+	return glyph, ok
+}
+
+// drawChar renders a single character at the specified pixel position directly
+// into the output buffer. Used by the text MMIO registers.
+func (p *PPU) drawChar(char rune, x, y int, color uint32) {
+	glyph, ok := Glyph(char)
 	if !ok {
 		return
 	}
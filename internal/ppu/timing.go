@@ -0,0 +1,64 @@
+package ppu
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Timing is the video timing mode. Both modes keep the dot clock and the
+// 200 visible scanlines; 50 Hz (PAL-style) lengthens VBlank so the same
+// ~7.67 MHz clock yields 50 frames a second instead of 60:
+//
+//	60 Hz: 220 scanlines (20 VBlank), 127,820 cycles per frame
+//	50 Hz: 264 scanlines (64 VBlank), 153,384 cycles per frame
+//
+// FrameCounter, the VBlank flag and the VBlank IRQ all follow the frame, so
+// a ROM that counts frames runs 5/6 as fast in wall-clock time at 50 Hz.
+type Timing uint8
+
+const (
+	Timing60Hz Timing = iota
+	Timing50Hz
+)
+
+// TotalScanlines50Hz is the frame length of Timing50Hz (TotalScanlines is
+// the 60 Hz one).
+const TotalScanlines50Hz = 264
+
+// Scanlines is the number of scanlines per frame, visible and VBlank.
+func (t Timing) Scanlines() int {
+	if t == Timing50Hz {
+		return TotalScanlines50Hz
+	}
+	return TotalScanlines
+}
+
+// VBlankScanlines is the number of scanlines after the visible ones.
+func (t Timing) VBlankScanlines() int { return t.Scanlines() - VisibleScanlines }
+
+// CyclesPerFrame is the frame length in master clock cycles.
+func (t Timing) CyclesPerFrame() uint64 { return uint64(t.Scanlines() * DotsPerScanline) }
+
+// FramesPerSecond is the nominal frame rate, 60 or 50.
+func (t Timing) FramesPerSecond() int {
+	if t == Timing50Hz {
+		return 50
+	}
+	return 60
+}
+
+func (t Timing) String() string {
+	return fmt.Sprintf("%d Hz", t.FramesPerSecond())
+}
+
+// ParseTiming reads a timing mode as given on the command line: "60",
+// "60hz" or "ntsc", and "50", "50hz" or "pal".
+func ParseTiming(s string) (Timing, error) {
+	switch strings.ToLower(strings.ReplaceAll(strings.TrimSpace(s), " ", "")) {
+	case "60", "60hz", "ntsc":
+		return Timing60Hz, nil
+	case "50", "50hz", "pal":
+		return Timing50Hz, nil
+	}
+	return Timing60Hz, fmt.Errorf("unknown timing mode %q (want 60hz or 50hz)", s)
+}
@@ -0,0 +1,56 @@
+package ppu
+
+import (
+	"testing"
+
+	"nitro-core-dx/internal/hwdef"
+)
+
+// TestTiming50HzLengthensVBlank runs both modes for exactly one frame from
+// the same point: each must end on its own frame boundary, with VBlank
+// reported for every scanline past the visible 200.
+func TestTiming50HzLengthensVBlank(t *testing.T) {
+	for _, tc := range []struct {
+		timing Timing
+		cycles uint64
+		vblank int
+	}{
+		{Timing60Hz, 127820, 20},
+		{Timing50Hz, 153384, 64},
+	} {
+		p := NewPPU(nil)
+		p.Timing = tc.timing
+		if got := tc.timing.CyclesPerFrame(); got != tc.cycles {
+			t.Fatalf("%v: CyclesPerFrame = %d, want %d", tc.timing, got, tc.cycles)
+		}
+		if got := tc.timing.VBlankScanlines(); got != tc.vblank {
+			t.Fatalf("%v: VBlankScanlines = %d, want %d", tc.timing, got, tc.vblank)
+		}
+
+		// Last scanline of the frame: still VBlank, same frame.
+		if err := p.StepPPU(tc.cycles - DotsPerScanline); err != nil {
+			t.Fatal(err)
+		}
+		if p.currentScanline != tc.timing.Scanlines()-1 || p.Read8(hwdef.VBLANK_FLAG-hwdef.PPUBase)&1 == 0 {
+			t.Errorf("%v: at scanline %d, want the last scanline and VBlank", tc.timing, p.currentScanline)
+		}
+		counter := p.FrameCounter
+		if err := p.StepPPU(DotsPerScanline + 1); err != nil {
+			t.Fatal(err)
+		}
+		if p.currentScanline != 0 || p.FrameCounter != counter+1 {
+			t.Errorf("%v: one frame later at scanline %d, frame counter %d -> %d", tc.timing, p.currentScanline, counter, p.FrameCounter)
+		}
+	}
+}
+
+func TestParseTiming(t *testing.T) {
+	for in, want := range map[string]Timing{"60": Timing60Hz, "60Hz": Timing60Hz, "ntsc": Timing60Hz, "50": Timing50Hz, "50 hz": Timing50Hz, "PAL": Timing50Hz} {
+		if got, err := ParseTiming(in); err != nil || got != want {
+			t.Errorf("ParseTiming(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	if _, err := ParseTiming("30hz"); err == nil {
+		t.Error("ParseTiming accepted 30hz")
+	}
+}
@@ -0,0 +1,109 @@
+package ppu
+
+import (
+	"fmt"
+
+	"nitro-core-dx/internal/debug"
+)
+
+// VRAMAccessMode selects what happens to VRAM and CGRAM writes made while
+// the PPU is drawing the visible scanlines (see inActiveDisplay), through
+// the data ports or by DMA.
+//
+// On hardware such writes are dropped, as OAM writes are: the PPU owns the
+// memory while it draws, the byte is lost and the address still advances,
+// so the rest of an upload lands one slot further on per dropped byte.
+// Nothing is delayed or queued. ROMs should upload during VBlank.
+type VRAMAccessMode uint8
+
+const (
+	// VRAMAccessOpen lets every write land without comment. This is the
+	// emulator's long-standing behavior and the default.
+	VRAMAccessOpen VRAMAccessMode = iota
+	// VRAMAccessWarn is the lenient developer mode: writes still land, but
+	// the first late write of each frame is logged as a PPU warning and
+	// every one is counted in LateVRAMWrites.
+	VRAMAccessWarn
+	// VRAMAccessStrict is the hardware mode: late writes are dropped,
+	// logged and counted, so a ROM that only works leniently shows it.
+	// Late DMA into OAM is dropped as well (see allowOAMDMA).
+	VRAMAccessStrict
+)
+
+func (m VRAMAccessMode) String() string {
+	switch m {
+	case VRAMAccessWarn:
+		return "warn"
+	case VRAMAccessStrict:
+		return "strict"
+	}
+	return "open"
+}
+
+// ParseVRAMAccessMode parses "open", "warn" or "strict".
+func ParseVRAMAccessMode(s string) (VRAMAccessMode, error) {
+	switch s {
+	case "open":
+		return VRAMAccessOpen, nil
+	case "warn":
+		return VRAMAccessWarn, nil
+	case "strict":
+		return VRAMAccessStrict, nil
+	}
+	return VRAMAccessOpen, fmt.Errorf("vram access mode %q: want open, warn or strict", s)
+}
+
+// inActiveDisplay reports whether the PPU is drawing the visible scanlines,
+// when OAM is locked and VRAM/CGRAM writes are late. The first frames are
+// exempt so ROM initialization before the first VBlank wait is not
+// affected.
+func (p *PPU) inActiveDisplay() bool {
+	return p.currentScanline < VisibleScanlines && p.frameStarted && p.FrameCounter > 1
+}
+
+// allowVRAMWrite applies VRAMAccess to a VRAM or CGRAM write (target names
+// it in the log) and reports whether the write should land.
+func (p *PPU) allowVRAMWrite(target string, addr uint16, dma bool) bool {
+	if p.VRAMAccess == VRAMAccessOpen || !p.inActiveDisplay() {
+		return true
+	}
+	p.LateVRAMWrites++
+	if p.LateWrite != nil {
+		p.LateWrite(target, dma)
+	}
+	if p.lateVRAMWriteFrame != p.FrameCounter {
+		p.lateVRAMWriteFrame = p.FrameCounter
+		if p.Logger != nil {
+			via := "port"
+			if dma {
+				via = "DMA"
+			}
+			action := "allowed"
+			if p.VRAMAccess == VRAMAccessStrict {
+				action = "dropped"
+			}
+			p.Logger.LogPPUf(debug.LogLevelWarning, "%s write during active display %s: %s addr=0x%04X, frame %d scanline %d (further late writes this frame not logged)",
+				target, action, via, addr, p.FrameCounter, p.currentScanline)
+		}
+	}
+	return p.VRAMAccess != VRAMAccessStrict
+}
+
+// allowOAMDMA reports whether a DMA byte into OAM should land. OAM port
+// writes are ignored during active display in every mode; DMA has always
+// been let through, so only strict mode drops it. Late DMA bytes are
+// counted in LateOAMWrites either way.
+func (p *PPU) allowOAMDMA() bool {
+	if !p.inActiveDisplay() {
+		return true
+	}
+	p.noteLateOAMWrite(true)
+	return p.VRAMAccess != VRAMAccessStrict
+}
+
+func (p *PPU) noteLateOAMWrite(dma bool) {
+	p.LateOAMWrites++
+	if p.LateWrite != nil {
+		p.LateWrite("OAM", dma)
+	}
+}
@@ -0,0 +1,118 @@
+package ppu
+
+import (
+	"testing"
+
+	"nitro-core-dx/internal/debug"
+)
+
+// lateWritePPU returns a PPU parked on a visible scanline of frame 5, where
+// VRAM and CGRAM writes count as late.
+func lateWritePPU(mode VRAMAccessMode) *PPU {
+	p := NewPPU(debug.NewLogger(100))
+	p.VRAMAccess = mode
+	p.frameStarted = true
+	p.FrameCounter = 5
+	p.currentScanline = 50
+	return p
+}
+
+func TestVRAMAccessModesDuringActiveDisplay(t *testing.T) {
+	tests := []struct {
+		mode      VRAMAccessMode
+		lands     bool
+		wantCount uint64
+	}{
+		{VRAMAccessOpen, true, 0},
+		{VRAMAccessWarn, true, 2},
+		{VRAMAccessStrict, false, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode.String(), func(t *testing.T) {
+			p := lateWritePPU(tt.mode)
+			p.Write8(0x0E, 0x34) // VRAM_ADDR_L
+			p.Write8(0x0F, 0x12) // VRAM_ADDR_H
+			p.Write8(0x10, 0xAB) // VRAM_DATA
+			p.Write8(0x12, 3)    // CGRAM_ADDR
+			p.Write8(0x13, 0xFF) // CGRAM_DATA low
+			p.Write8(0x13, 0x7F) // CGRAM_DATA high
+
+			if got := p.VRAM[0x1234] == 0xAB; got != tt.lands {
+				t.Errorf("VRAM write landed = %v, want %v", got, tt.lands)
+			}
+			if got := p.CGRAM[6] == 0xFF && p.CGRAM[7] == 0x7F; got != tt.lands {
+				t.Errorf("CGRAM write landed = %v, want %v", got, tt.lands)
+			}
+			// Dropped or not, both addresses advance.
+			if p.VRAMAddr != 0x1235 || p.CGRAMAddr != 4 || p.CGRAMWriteLatch {
+				t.Errorf("VRAMAddr 0x%04X, CGRAMAddr %d, latch %v after writes", p.VRAMAddr, p.CGRAMAddr, p.CGRAMWriteLatch)
+			}
+			if p.LateVRAMWrites != tt.wantCount {
+				t.Errorf("LateVRAMWrites = %d, want %d", p.LateVRAMWrites, tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestVRAMAccessStrictAllowsVBlankAndDropsDMA(t *testing.T) {
+	p := lateWritePPU(VRAMAccessStrict)
+	p.currentScanline = VisibleScanlines // VBlank
+	p.Write8(0x10, 0x11)
+	if p.VRAM[0] != 0x11 || p.LateVRAMWrites != 0 {
+		t.Fatalf("VBlank write: VRAM[0] = 0x%02X, late %d", p.VRAM[0], p.LateVRAMWrites)
+	}
+
+	// A DMA fill running into active display loses its bytes too.
+	p.currentScanline = 10
+	p.MemoryReader = func(bank uint8, offset uint16) uint8 { return 0 }
+	p.DMAEnabled = true
+	p.DMAMode = 1
+	p.DMAFillValue = 0x55
+	p.DMADestType = 0
+	p.DMACurrentDest = 0x100
+	p.DMALength = 4
+	for p.DMAEnabled {
+		p.stepDMA()
+	}
+	if p.VRAM[0x100] != 0 || p.LateVRAMWrites != 4 {
+		t.Errorf("DMA fill: VRAM[0x100] = 0x%02X, late %d; want dropped, 4", p.VRAM[0x100], p.LateVRAMWrites)
+	}
+}
+
+func TestLateOAMWritesCounted(t *testing.T) {
+	for _, mode := range []VRAMAccessMode{VRAMAccessOpen, VRAMAccessStrict} {
+		p := lateWritePPU(mode)
+		var targets []string
+		p.LateWrite = func(target string, dma bool) { targets = append(targets, target) }
+		p.Write8(0x14, 2)    // OAM_ADDR: ignored during active display
+		p.Write8(0x15, 0x40) // OAM_DATA: ignored too
+
+		p.MemoryReader = func(bank uint8, offset uint16) uint8 { return 0 }
+		p.DMAEnabled = true
+		p.DMAMode = 1
+		p.DMAFillValue = 0x66
+		p.DMADestType = 2
+		p.DMACurrentDest = 12
+		p.DMALength = 2
+		for p.DMAEnabled {
+			p.stepDMA()
+		}
+		if p.LateOAMWrites != 4 || len(targets) != 4 || targets[0] != "OAM" {
+			t.Errorf("%s: LateOAMWrites = %d, LateWrite targets %v; want 4 OAM", mode, p.LateOAMWrites, targets)
+		}
+		if landed := p.OAM[12] == 0x66; landed != (mode != VRAMAccessStrict) {
+			t.Errorf("%s: OAM DMA landed = %v", mode, landed)
+		}
+	}
+}
+
+func TestParseVRAMAccessMode(t *testing.T) {
+	for _, m := range []VRAMAccessMode{VRAMAccessOpen, VRAMAccessWarn, VRAMAccessStrict} {
+		if got, err := ParseVRAMAccessMode(m.String()); err != nil || got != m {
+			t.Errorf("ParseVRAMAccessMode(%q) = %v, %v", m.String(), got, err)
+		}
+	}
+	if _, err := ParseVRAMAccessMode("lenient"); err == nil {
+		t.Error("ParseVRAMAccessMode(lenient) succeeded")
+	}
+}
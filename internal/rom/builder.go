@@ -55,6 +55,17 @@ func (b *ROMBuilder) GetCodeLength() int {
 	return len(b.code)
 }
 
+// Words returns a copy of the code emitted so far.
+func (b *ROMBuilder) Words() []uint16 {
+	return append([]uint16(nil), b.code...)
+}
+
+// ReplaceCode replaces the code emitted so far, for passes that rewrite it
+// (see Peephole).
+func (b *ROMBuilder) ReplaceCode(words []uint16) {
+	b.code = append([]uint16(nil), words...)
+}
+
 // BuildROM builds the ROM file
 func (b *ROMBuilder) BuildROM(entryBank uint8, entryOffset uint16, outputPath string) error {
 	romData, err := b.BuildROMBytes(entryBank, entryOffset)
@@ -100,7 +111,7 @@ func (b *ROMBuilder) BuildROMBytes(entryBank uint8, entryOffset uint16) ([]byte,
 	binary.LittleEndian.PutUint16(romData[14:16], 0)
 	// Checksum: 0 (unused)
 	binary.LittleEndian.PutUint32(romData[16:20], 0)
-	// Reserved: 0
+	// Timing (byte 20): 60 Hz; reserved: 0
 	for i := 20; i < 32; i++ {
 		romData[i] = 0
 	}
@@ -0,0 +1,254 @@
+package rom
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// HeaderSize is the size of the RMCF header that precedes the ROM payload.
+const HeaderSize = 32
+
+// Header is the decoded RMCF header (see BuildROMBytes for the layout).
+type Header struct {
+	Magic       uint32
+	Version     uint16
+	Size        uint32
+	EntryBank   uint16
+	EntryOffset uint16
+	MapperFlags uint16
+	Checksum    uint32
+	// Timing is byte 20: TimingFlag60Hz or TimingFlag50Hz.
+	Timing uint8
+}
+
+// ParseHeader decodes the header of a ROM image and checks its magic and
+// that the file holds the payload the header declares.
+func ParseHeader(data []byte) (Header, error) {
+	if len(data) < HeaderSize {
+		return Header{}, fmt.Errorf("ROM too small: %d bytes", len(data))
+	}
+	h := Header{
+		Magic:       binary.LittleEndian.Uint32(data[0:4]),
+		Version:     binary.LittleEndian.Uint16(data[4:6]),
+		Size:        binary.LittleEndian.Uint32(data[6:10]),
+		EntryBank:   binary.LittleEndian.Uint16(data[10:12]),
+		EntryOffset: binary.LittleEndian.Uint16(data[12:14]),
+		MapperFlags: binary.LittleEndian.Uint16(data[14:16]),
+		Checksum:    binary.LittleEndian.Uint32(data[16:20]),
+		Timing:      data[20],
+	}
+	if h.Magic != 0x46434D52 {
+		return h, fmt.Errorf("invalid ROM magic: 0x%08X", h.Magic)
+	}
+	if uint64(len(data)) < HeaderSize+uint64(h.Size) {
+		return h, fmt.Errorf("ROM data too small: header declares %d bytes, file has %d", h.Size, len(data)-HeaderSize)
+	}
+	return h, nil
+}
+
+// Instruction is one decoded instruction at a LoROM address.
+type Instruction struct {
+	Bank   uint8
+	Offset uint16
+	// Words is the opcode word followed by its immediate, if any.
+	Words []uint16
+	// Text is the assembler-style mnemonic and operands. PC-relative
+	// operands are shown as signed offsets, so Text does not change when
+	// code around the instruction moves.
+	Text string
+	// Target is the resolved destination of a PC-relative JMP, CALL or
+	// branch, valid when HasTarget is set.
+	Target    uint16
+	HasTarget bool
+}
+
+// DisassembleBank decodes one bank's payload (up to ROMBankSizeBytes, mapped
+// at 0x8000) by linear sweep. Data mixed into the bank decodes as whatever
+// instructions its words happen to spell.
+func DisassembleBank(bank uint8, payload []byte) []Instruction {
+	var out []Instruction
+	words := len(payload) / 2
+	for i := 0; i < words; {
+		word := binary.LittleEndian.Uint16(payload[i*2:])
+		var imm uint16
+		if i+1 < words {
+			imm = binary.LittleEndian.Uint16(payload[i*2+2:])
+		}
+		inst := DecodeInstruction(bank, 0x8000+uint16(i*2), word, imm, i+1 < words)
+		out = append(out, inst)
+		i += len(inst.Words)
+	}
+	return out
+}
+
+// DecodeInstruction decodes the instruction whose opcode word is at
+// bank:offset. imm is the word after it, used when the opcode takes an
+// immediate; hasImm is false when there is no such word.
+func DecodeInstruction(bank uint8, offset uint16, word, imm uint16, hasImm bool) Instruction {
+	inst := Instruction{Bank: bank, Offset: offset, Words: []uint16{word}}
+	text, needsImm := decodeOp(word)
+	switch {
+	case needsImm && hasImm:
+		inst.Words = append(inst.Words, imm)
+		inst.Text, inst.Target, inst.HasTarget = withImmediate(word, text, imm, offset)
+	case needsImm:
+		inst.Text = text + " <truncated>"
+	default:
+		inst.Text = text
+	}
+	return inst
+}
+
+// DisassembleROM decodes every bank of a ROM image's payload.
+func DisassembleROM(data []byte) (map[uint8][]Instruction, error) {
+	h, err := ParseHeader(data)
+	if err != nil {
+		return nil, err
+	}
+	payload := data[HeaderSize : HeaderSize+int(h.Size)]
+	banks := make(map[uint8][]Instruction)
+	for start := 0; start < len(payload); start += ROMBankSizeBytes {
+		end := start + ROMBankSizeBytes
+		if end > len(payload) {
+			end = len(payload)
+		}
+		bank := uint8(1 + start/ROMBankSizeBytes)
+		banks[bank] = DisassembleBank(bank, payload[start:end])
+	}
+	return banks, nil
+}
+
+// decodeOp returns the mnemonic and register operands of an opcode word, and
+// whether an immediate word follows it, mirroring CPU.ExecuteInstruction.
+func decodeOp(word uint16) (string, bool) {
+	opcode := word >> 12
+	mode := (word >> 8) & 0xF
+	r1 := (word >> 4) & 0xF
+	r2 := word & 0xF
+	rr := fmt.Sprintf("R%d, R%d", r1, r2)
+	ri := fmt.Sprintf("R%d, #", r1)
+
+	switch opcode {
+	case 0x0:
+		if word == 0 {
+			return "NOP", false
+		}
+	case 0x1:
+		switch mode {
+		case 0:
+			return "MOV " + rr, false
+		case 1:
+			return "MOV " + ri, true
+		case 2:
+			return fmt.Sprintf("MOV R%d, [R%d]", r1, r2), false
+		case 3:
+			return fmt.Sprintf("MOV [R%d], R%d", r1, r2), false
+		case 4:
+			return fmt.Sprintf("PUSH R%d", r1), false
+		case 5:
+			return fmt.Sprintf("POP R%d", r1), false
+		case 6:
+			return fmt.Sprintf("MOV.B R%d, [R%d]", r1, r2), false
+		case 7:
+			return fmt.Sprintf("MOV.B [R%d], R%d", r1, r2), false
+		case 8:
+			return fmt.Sprintf("MOV DBR, R%d", r1), false
+		case 9:
+			return fmt.Sprintf("MOV R%d, [R%d", r1, r2), true
+		case 10:
+			return fmt.Sprintf("MOV [R%d", r1), true
+		case 11:
+			return fmt.Sprintf("MOV R%d, [R%d]+", r1, r2), false
+		case 12:
+			return fmt.Sprintf("MOV [R%d]-, R%d", r1, r2), false
+		case 13:
+			return fmt.Sprintf("MOV.B R%d, [R%d", r1, r2), true
+		case 14:
+			return fmt.Sprintf("MOV.B [R%d", r1), true
+		}
+	case 0x2, 0x3:
+		name := "ADD"
+		if opcode == 0x3 {
+			name = "SUB"
+		}
+		switch mode {
+		case 0:
+			return name + " " + rr, false
+		case 1:
+			return name + " " + ri, true
+		case 2:
+			return name + ".B " + rr, false
+		case 3:
+			return name + ".B " + ri, true
+		}
+	case 0x4, 0x5, 0x6, 0x7, 0x8, 0xA:
+		name := map[uint16]string{0x4: "MUL", 0x5: "DIV", 0x6: "AND", 0x7: "OR", 0x8: "XOR", 0xA: "SHL"}[opcode]
+		if mode == 0 {
+			return name + " " + rr, false
+		}
+		return name + " " + ri, true
+	case 0x9:
+		return fmt.Sprintf("NOT R%d", r1), false
+	case 0xB:
+		switch mode {
+		case 0:
+			return "SHR " + rr, false
+		case 1:
+			return "SHR " + ri, true
+		case 2:
+			return "SAR " + rr, false
+		case 3:
+			return "SAR " + ri, true
+		case 4:
+			return "ROL " + rr, false
+		case 5:
+			return "ROR " + rr, false
+		}
+	case 0xC:
+		switch mode {
+		case 0:
+			return "CMP " + rr, false
+		case 7:
+			return "CMP " + ri, true
+		default:
+			if mode <= 6 {
+				return [...]string{"", "BEQ", "BNE", "BGT", "BLT", "BGE", "BLE"}[mode], true
+			}
+		}
+	case 0xD, 0xE:
+		name := "JMP"
+		if opcode == 0xE {
+			name = "CALL"
+		}
+		switch mode {
+		case 0:
+			return name, true
+		case 1:
+			return fmt.Sprintf("%s [R%d:R%d]", name, r1, r2), false
+		}
+	case 0xF:
+		return "RET", false
+	}
+	return fmt.Sprintf(".word 0x%04X", word), false
+}
+
+// withImmediate completes text with the immediate operand. For PC-relative
+// ops it also resolves the target from the instruction's offset.
+func withImmediate(word uint16, text string, imm, offset uint16) (string, uint16, bool) {
+	opcode := word >> 12
+	mode := (word >> 8) & 0xF
+	pcRel := (opcode == 0xC && mode >= 1 && mode <= 6) || ((opcode == 0xD || opcode == 0xE) && mode == 0)
+	if pcRel {
+		rel := int16(imm)
+		// The offset is relative to the PC after the immediate word.
+		target := uint16(int32(offset) + 4 + int32(rel))
+		return fmt.Sprintf("%s %+d", text, rel), target, true
+	}
+	switch {
+	case opcode == 0x1 && (mode == 9 || mode == 13):
+		return fmt.Sprintf("%s%+d]", text, int16(imm)), 0, false
+	case opcode == 0x1 && (mode == 10 || mode == 14):
+		return fmt.Sprintf("%s%+d], R%d", text, int16(imm), word&0xF), 0, false
+	}
+	return fmt.Sprintf("%s0x%04X", text, imm), 0, false
+}
@@ -0,0 +1,254 @@
+package rom
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"sort"
+	"strings"
+)
+
+// MetadataMagic starts the optional metadata trailer that follows a ROM's
+// payload. Loaders read only the Size bytes the header declares, so the
+// trailer never reaches the cartridge and can be rewritten without
+// rebuilding the ROM.
+//
+// Layout: "NCMD", entry count (u16), then per entry a key length (u8), the
+// key, a value length (u16) and the value. All integers little-endian.
+const MetadataMagic = "NCMD"
+
+// Well-known metadata keys. Any other key is kept and shown as-is.
+const (
+	MetaTitle   = "title"
+	MetaVersion = "version"
+)
+
+// Metadata is the key/value trailer of a ROM image.
+type Metadata map[string]string
+
+// BankUsage is one bank of the payload and how much of it is not zero
+// padding.
+type BankUsage struct {
+	Bank uint8
+	// Size is the bank's length in the image (ROMBankSizeBytes except
+	// possibly for the last bank); Used runs to its last non-zero byte.
+	Size, Used int
+}
+
+// Info is what Inspect reports about a ROM image.
+type Info struct {
+	Header   Header
+	FileSize int
+	// PayloadCRC is the CRC-32 (IEEE) of the payload, the value the header
+	// checksum holds once set (see SetChecksum). Builders leave it 0.
+	PayloadCRC uint32
+	Banks      []BankUsage
+	Metadata   Metadata
+	// Trailing counts bytes after the payload that are not a metadata
+	// trailer.
+	Trailing int
+}
+
+// ChecksumStatus describes the header checksum: "not set", "ok" or
+// "MISMATCH".
+func (i *Info) ChecksumStatus() string {
+	switch i.Header.Checksum {
+	case 0:
+		return "not set"
+	case i.PayloadCRC:
+		return "ok"
+	}
+	return "MISMATCH"
+}
+
+// Inspect decodes a ROM image's header, payload layout and metadata.
+func Inspect(data []byte) (*Info, error) {
+	h, err := ParseHeader(data)
+	if err != nil {
+		return nil, err
+	}
+	end := HeaderSize + int(h.Size)
+	payload := data[HeaderSize:end]
+	info := &Info{Header: h, FileSize: len(data), PayloadCRC: crc32.ChecksumIEEE(payload)}
+	for start := 0; start < len(payload); start += ROMBankSizeBytes {
+		chunk := payload[start:min(start+ROMBankSizeBytes, len(payload))]
+		used := len(chunk)
+		for used > 0 && chunk[used-1] == 0 {
+			used--
+		}
+		info.Banks = append(info.Banks, BankUsage{Bank: uint8(1 + start/ROMBankSizeBytes), Size: len(chunk), Used: used})
+	}
+	if tail := data[end:]; len(tail) > 0 {
+		md, n, err := decodeMetadata(tail)
+		if err != nil {
+			return nil, err
+		}
+		info.Metadata = md
+		info.Trailing = len(tail) - n
+	}
+	return info, nil
+}
+
+// Summary is a human-readable listing of the header, checksum, bank usage
+// and metadata, as printed by nitrotool info and the Dev Kit.
+func (i *Info) Summary() string {
+	var sb strings.Builder
+	h := i.Header
+	fmt.Fprintf(&sb, "format:   RMCF v%d, %d bytes (%d payload)\n", h.Version, i.FileSize, h.Size)
+	fmt.Fprintf(&sb, "entry:    %02X:%04X\n", h.EntryBank, h.EntryOffset)
+	fmt.Fprintf(&sb, "mapper:   0x%04X\n", h.MapperFlags)
+	fmt.Fprintf(&sb, "timing:   %s\n", TimingName(h.Timing))
+	fmt.Fprintf(&sb, "checksum: 0x%08X (%s, payload CRC-32 0x%08X)\n", h.Checksum, i.ChecksumStatus(), i.PayloadCRC)
+	for _, b := range i.Banks {
+		fmt.Fprintf(&sb, "bank %02X:  %5d / %5d bytes used\n", b.Bank, b.Used, b.Size)
+	}
+	if len(i.Metadata) == 0 {
+		sb.WriteString("metadata: (none)\n")
+	} else {
+		sb.WriteString("metadata:\n")
+		for _, k := range i.Metadata.keys() {
+			fmt.Fprintf(&sb, "  %s: %s\n", k, i.Metadata[k])
+		}
+	}
+	if i.Trailing > 0 {
+		fmt.Fprintf(&sb, "trailing: %d unrecognized bytes after payload\n", i.Trailing)
+	}
+	return sb.String()
+}
+
+// SetMetadata returns a copy of data whose metadata trailer is replaced by
+// md (removed when md is empty). The header and payload are unchanged.
+func SetMetadata(data []byte, md Metadata) ([]byte, error) {
+	h, err := ParseHeader(data)
+	if err != nil {
+		return nil, err
+	}
+	end := HeaderSize + int(h.Size)
+	if tail := data[end:]; len(tail) > 0 {
+		if _, n, err := decodeMetadata(tail); err != nil {
+			return nil, err
+		} else if n != len(tail) {
+			return nil, fmt.Errorf("%d unrecognized bytes after payload", len(tail)-n)
+		}
+	}
+	trailer, err := encodeMetadata(md)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, 0, end+len(trailer))
+	out = append(out, data[:end]...)
+	return append(out, trailer...), nil
+}
+
+// SetChecksum writes the payload's CRC-32 into the header checksum field of
+// data in place.
+func SetChecksum(data []byte) error {
+	h, err := ParseHeader(data)
+	if err != nil {
+		return err
+	}
+	binary.LittleEndian.PutUint32(data[16:20], crc32.ChecksumIEEE(data[HeaderSize:HeaderSize+int(h.Size)]))
+	return nil
+}
+
+// Header timing flags (byte 20). The emulator runs a ROM at the rate its
+// header asks for unless the user overrides it.
+const (
+	TimingFlag60Hz = 0
+	TimingFlag50Hz = 1
+)
+
+// TimingName describes a header timing flag: "60 Hz", "50 Hz" or, for a
+// value no emulator knows, "unknown (0xNN, runs at 60 Hz)".
+func TimingName(flag uint8) string {
+	switch flag {
+	case TimingFlag60Hz:
+		return "60 Hz"
+	case TimingFlag50Hz:
+		return "50 Hz"
+	}
+	return fmt.Sprintf("unknown (0x%02X, runs at 60 Hz)", flag)
+}
+
+// SetTiming writes a timing flag into the header of data in place.
+func SetTiming(data []byte, flag uint8) error {
+	if _, err := ParseHeader(data); err != nil {
+		return err
+	}
+	if flag != TimingFlag60Hz && flag != TimingFlag50Hz {
+		return fmt.Errorf("invalid timing flag %d", flag)
+	}
+	data[20] = flag
+	return nil
+}
+
+func (md Metadata) keys() []string {
+	keys := make([]string, 0, len(md))
+	for k := range md {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func encodeMetadata(md Metadata) ([]byte, error) {
+	if len(md) == 0 {
+		return nil, nil
+	}
+	if len(md) > 0xFFFF {
+		return nil, fmt.Errorf("too many metadata entries: %d", len(md))
+	}
+	var buf bytes.Buffer
+	buf.WriteString(MetadataMagic)
+	binary.Write(&buf, binary.LittleEndian, uint16(len(md)))
+	for _, k := range md.keys() {
+		v := md[k]
+		if k == "" || len(k) > 0xFF {
+			return nil, fmt.Errorf("metadata key %q must be 1-255 bytes", k)
+		}
+		if len(v) > 0xFFFF {
+			return nil, fmt.Errorf("metadata %s: value too long (%d bytes)", k, len(v))
+		}
+		buf.WriteByte(byte(len(k)))
+		buf.WriteString(k)
+		binary.Write(&buf, binary.LittleEndian, uint16(len(v)))
+		buf.WriteString(v)
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeMetadata parses a trailer at the start of tail and returns it with
+// the number of bytes it spans. A tail that does not start with the magic
+// is not a trailer: it yields no metadata and 0 bytes.
+func decodeMetadata(tail []byte) (Metadata, int, error) {
+	if !bytes.HasPrefix(tail, []byte(MetadataMagic)) {
+		return nil, 0, nil
+	}
+	if len(tail) < 6 {
+		return nil, 0, fmt.Errorf("metadata trailer truncated")
+	}
+	count := int(binary.LittleEndian.Uint16(tail[4:6]))
+	md := make(Metadata, count)
+	p := 6
+	for n := 0; n < count; n++ {
+		if p+1 > len(tail) {
+			return nil, 0, fmt.Errorf("metadata trailer truncated in entry %d", n)
+		}
+		kl := int(tail[p])
+		p++
+		if p+kl+2 > len(tail) {
+			return nil, 0, fmt.Errorf("metadata trailer truncated in entry %d", n)
+		}
+		key := string(tail[p : p+kl])
+		p += kl
+		vl := int(binary.LittleEndian.Uint16(tail[p:]))
+		p += 2
+		if p+vl > len(tail) {
+			return nil, 0, fmt.Errorf("metadata %s truncated", key)
+		}
+		md[key] = string(tail[p : p+vl])
+		p += vl
+	}
+	return md, p, nil
+}
@@ -0,0 +1,78 @@
+package rom
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInspectReportsBanksAndChecksum(t *testing.T) {
+	b := NewROMBuilder()
+	b.AddInstruction(EncodeNOP())
+	b.AddInstruction(EncodeRET())
+	data, err := b.BuildROMBytes(1, 0x8000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := Inspect(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(info.Banks) != 1 || info.Banks[0].Size != 4 {
+		t.Fatalf("banks = %+v, want one 4-byte bank", info.Banks)
+	}
+	if info.ChecksumStatus() != "not set" || len(info.Metadata) != 0 {
+		t.Errorf("fresh ROM: checksum %s, metadata %v", info.ChecksumStatus(), info.Metadata)
+	}
+
+	if err := SetChecksum(data); err != nil {
+		t.Fatal(err)
+	}
+	if info, _ = Inspect(data); info.ChecksumStatus() != "ok" {
+		t.Errorf("after SetChecksum: %s", info.ChecksumStatus())
+	}
+	data[HeaderSize] ^= 0xFF
+	if info, _ = Inspect(data); info.ChecksumStatus() != "MISMATCH" {
+		t.Errorf("after corrupting payload: %s", info.ChecksumStatus())
+	}
+}
+
+func TestSetMetadataRoundTripsAndLeavesPayload(t *testing.T) {
+	b := NewROMBuilder()
+	b.AddInstruction(EncodeRET())
+	data, err := b.BuildROMBytes(1, 0x8000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	edited, err := SetMetadata(data, Metadata{MetaTitle: "Nitro Quest", MetaVersion: "1.2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d, err := DiffROMs(data, edited, 0); err != nil || !d.Equal() {
+		t.Fatalf("metadata changed header or code: %+v, %v", d, err)
+	}
+	// Replacing the trailer keeps one copy of it.
+	edited, err = SetMetadata(edited, Metadata{MetaTitle: "Nitro Quest II"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := Inspect(edited)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Metadata[MetaTitle] != "Nitro Quest II" || len(info.Metadata) != 1 || info.Trailing != 0 {
+		t.Errorf("metadata = %v, trailing %d", info.Metadata, info.Trailing)
+	}
+	if !strings.Contains(info.Summary(), "title: Nitro Quest II") {
+		t.Errorf("summary missing title:\n%s", info.Summary())
+	}
+
+	stripped, err := SetMetadata(edited, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stripped) != len(data) {
+		t.Errorf("stripped ROM is %d bytes, want %d", len(stripped), len(data))
+	}
+}
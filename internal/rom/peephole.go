@@ -0,0 +1,306 @@
+package rom
+
+import "fmt"
+
+// WordRange is a half-open range [Start, End) of word indexes.
+type WordRange struct {
+	Start, End int
+}
+
+// PeepholeInput describes one bank of emitted code for Peephole.
+type PeepholeInput struct {
+	Code []uint16
+	// Data lists word ranges that hold data rather than instructions
+	// (e.g. a lookup table emitted after a helper). They are copied
+	// unchanged and never decoded.
+	Data []WordRange
+	// Fixed lists word indexes of immediates that are patched later or
+	// hold an absolute address, such as the placeholder of an unresolved
+	// CALL. The instructions owning them are neither removed nor
+	// retargeted.
+	Fixed map[int]bool
+}
+
+// PeepholeStats counts what Peephole changed.
+type PeepholeStats struct {
+	BeforeWords int
+	AfterWords  int
+	// DeadMoves counts register loads removed because the next
+	// instruction overwrites the same register without reading it.
+	DeadMoves int
+	// RoundTrips counts MOV Rb, Ra removed right after MOV Ra, Rb.
+	RoundTrips int
+	// BranchChains counts branches and jumps retargeted past a JMP.
+	BranchChains int
+	// JumpsToNext counts JMPs removed because they targeted the next
+	// instruction.
+	JumpsToNext int
+}
+
+// PeepholeResult is Peephole's output.
+type PeepholeResult struct {
+	Code  []uint16
+	Stats PeepholeStats
+	// remap[i] is the new index of old word i, or of the next kept word
+	// when word i was removed; remap[len(old code)] is the new length.
+	remap []int
+}
+
+// Index returns the new word index of old word index i. A removed
+// instruction maps to the instruction that now follows its position, which
+// is where control reaching it ends up.
+func (r *PeepholeResult) Index(i int) int {
+	if i < 0 || i >= len(r.remap) {
+		return i
+	}
+	return r.remap[i]
+}
+
+type peepInst struct {
+	pos, size int
+	word, imm uint16
+	target    int // word index of a PC-relative target, -1 otherwise
+	fixed     bool
+	removed   bool
+}
+
+func (p *peepInst) opcode() uint16 { return p.word >> 12 }
+func (p *peepInst) mode() uint16   { return (p.word >> 8) & 0xF }
+func (p *peepInst) r1() uint16     { return (p.word >> 4) & 0xF }
+func (p *peepInst) r2() uint16     { return p.word & 0xF }
+
+// isJMP reports a same-bank PC-relative JMP.
+func (p *peepInst) isJMP() bool { return p.opcode() == 0xD && p.mode() == 0 }
+
+// isBranch reports a conditional branch or same-bank JMP.
+func (p *peepInst) isBranch() bool {
+	return p.isJMP() || (p.opcode() == 0xC && p.mode() >= 1 && p.mode() <= 6)
+}
+
+// loadDest returns the register a MOV fully overwrites without reading it
+// first (MOV Rx, Ry / MOV Rx, #imm / MOV Rx, [Ry] with y != x), or -1.
+// withMemory false leaves out the memory load, which can have side effects
+// (reading some I/O registers advances them) and so is never removable.
+func (p *peepInst) loadDest(withMemory bool) int {
+	if p.opcode() != 0x1 {
+		return -1
+	}
+	switch p.mode() {
+	case 1:
+		return int(p.r1())
+	case 0:
+		if p.r1() != p.r2() {
+			return int(p.r1())
+		}
+	case 2:
+		if withMemory && p.r1() != p.r2() {
+			return int(p.r1())
+		}
+	}
+	return -1
+}
+
+// Peephole rewrites one bank of emitted code, mapped at 0x8000, with a few
+// local patterns:
+//
+//   - MOV Rx, ... immediately followed by another load of Rx that does not
+//     read it drops the first load;
+//   - MOV Ra, Rb; MOV Rb, Ra drops the second MOV when nothing branches to
+//     it;
+//   - a branch or JMP to a JMP is retargeted to that JMP's destination;
+//   - a JMP to the instruction right after it is dropped.
+//
+// Removing code shifts what follows, so every PC-relative operand is
+// re-encoded against the new layout. Absolute code addresses are not
+// visible in the instruction stream: callers list them in Fixed and move
+// them with PeepholeResult.Index.
+func Peephole(in PeepholeInput) (*PeepholeResult, error) {
+	code := in.Code
+	insts, err := decodeForPeephole(in)
+	if err != nil {
+		return nil, err
+	}
+	at := make(map[int]*peepInst, len(insts))
+	targeted := make(map[int]bool)
+	for _, p := range insts {
+		at[p.pos] = p
+		if p.target >= 0 {
+			targeted[p.target] = true
+		}
+	}
+	res := &PeepholeResult{Stats: PeepholeStats{BeforeWords: len(code)}}
+
+	// Adjacent pairs. Both instructions must be code that runs one after
+	// the other, so a data range or the end of the bank ends a pair.
+	for i := 0; i+1 < len(insts); i++ {
+		a, b := insts[i], insts[i+1]
+		if a.pos+a.size != b.pos || a.fixed || b.fixed {
+			continue
+		}
+		if d := a.loadDest(false); d >= 0 && d == b.loadDest(true) {
+			a.removed = true
+			res.Stats.DeadMoves++
+			continue
+		}
+		if a.opcode() == 0x1 && a.mode() == 0 && b.word == EncodeMOV(0, uint8(a.r2()), uint8(a.r1())) &&
+			a.r1() != a.r2() && !targeted[b.pos] {
+			b.removed = true
+			res.Stats.RoundTrips++
+			i++
+		}
+	}
+
+	// JMPs to the next kept instruction. Removing one can make the JMP
+	// before it fall into the same case, so repeat until nothing changes.
+	dropJumpsToNext := func() {
+		for changed := true; changed; {
+			changed = false
+			for i, p := range insts {
+				if p.removed || p.fixed || !p.isJMP() {
+					continue
+				}
+				next := len(code)
+				for _, q := range insts[i+1:] {
+					if !q.removed {
+						next = q.pos
+						break
+					}
+				}
+				if dataBetween(in.Data, p.pos, next) {
+					continue
+				}
+				if resolveRemoved(at, p.target, next) == next {
+					p.removed = true
+					res.Stats.JumpsToNext++
+					changed = true
+				}
+			}
+		}
+	}
+	dropJumpsToNext()
+
+	// Branch chains, then JMPs that retargeting left pointing at the next
+	// instruction. The hop limit guards against JMP cycles.
+	for _, p := range insts {
+		if p.removed || p.fixed || !p.isBranch() {
+			continue
+		}
+		t := p.target
+		for hops := 0; hops < len(insts); hops++ {
+			next, ok := at[t]
+			if !ok || next.removed || next.fixed || !next.isJMP() || next.target == t {
+				break
+			}
+			t = next.target
+		}
+		if t != p.target {
+			p.target = t
+			res.Stats.BranchChains++
+		}
+	}
+	dropJumpsToNext()
+
+	// Lay out the kept words and re-encode PC-relative operands.
+	res.remap = make([]int, len(code)+1)
+	removedAt := make(map[int]int, len(insts))
+	for _, p := range insts {
+		if p.removed {
+			removedAt[p.pos] = p.size
+		}
+	}
+	out := make([]uint16, 0, len(code))
+	for i := 0; i < len(code); {
+		if n, ok := removedAt[i]; ok {
+			for k := 0; k < n; k++ {
+				res.remap[i+k] = len(out)
+			}
+			i += n
+			continue
+		}
+		res.remap[i] = len(out)
+		out = append(out, code[i])
+		i++
+	}
+	res.remap[len(code)] = len(out)
+	for _, p := range insts {
+		if p.removed || p.fixed || p.target < 0 {
+			continue
+		}
+		from := res.remap[p.pos]
+		rel := (res.remap[p.target] - (from + 2)) * 2
+		if rel < -32768 || rel > 32767 {
+			return nil, fmt.Errorf("peephole: branch at word %d out of range after rewrite", p.pos)
+		}
+		out[from+1] = uint16(int16(rel))
+	}
+	res.Code = out
+	res.Stats.AfterWords = len(out)
+	return res, nil
+}
+
+// decodeForPeephole decodes in.Code by linear sweep, skipping data ranges.
+func decodeForPeephole(in PeepholeInput) ([]*peepInst, error) {
+	code := in.Code
+	var insts []*peepInst
+	for i := 0; i < len(code); {
+		if end, ok := dataEnd(in.Data, i); ok {
+			i = end
+			continue
+		}
+		word := code[i]
+		_, needsImm := decodeOp(word)
+		p := &peepInst{pos: i, size: 1, word: word, target: -1}
+		if needsImm {
+			if i+1 >= len(code) {
+				return nil, fmt.Errorf("peephole: instruction at word %d is missing its immediate", i)
+			}
+			p.size = 2
+			p.imm = code[i+1]
+			p.fixed = in.Fixed[i+1]
+			op, mode := word>>12, (word>>8)&0xF
+			if (op == 0xC && mode >= 1 && mode <= 6) || ((op == 0xD || op == 0xE) && mode == 0) {
+				p.target = i + 2 + int(int16(p.imm))/2
+				if p.target < 0 || p.target > len(code) {
+					// Outside this bank's code: leave it alone.
+					p.fixed = true
+					p.target = -1
+				}
+			}
+		}
+		insts = append(insts, p)
+		i += p.size
+	}
+	return insts, nil
+}
+
+// dataEnd returns the end of the data range starting at i.
+func dataEnd(data []WordRange, i int) (int, bool) {
+	for _, r := range data {
+		if r.Start == i && r.End > i {
+			return r.End, true
+		}
+	}
+	return 0, false
+}
+
+// dataBetween reports whether a data range lies within [from, to).
+func dataBetween(data []WordRange, from, to int) bool {
+	for _, r := range data {
+		if r.Start < to && r.End > from {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveRemoved follows target past removed instructions up to limit.
+func resolveRemoved(at map[int]*peepInst, target, limit int) int {
+	for target < limit {
+		p, ok := at[target]
+		if !ok || !p.removed {
+			break
+		}
+		target += p.size
+	}
+	return target
+}
@@ -0,0 +1,78 @@
+package rom
+
+import (
+	"reflect"
+	"testing"
+)
+
+// rel is the PC-relative immediate for a branch whose opcode is at word
+// from and whose target is word to.
+func rel(from, to int) uint16 {
+	return uint16(CalculateBranchOffset(uint16((from+1)*2), uint16(to*2)))
+}
+
+func TestPeepholeRewritesAndRelocates(t *testing.T) {
+	code := []uint16{
+		EncodeMOV(1, 7, 0), 1, // 0: dead, overwritten next
+		EncodeMOV(1, 7, 0), 2, // 2
+		EncodeBEQ(), rel(4, 10), // 4: branch to a JMP
+		EncodeMOV(0, 1, 2),      // 6
+		EncodeMOV(0, 2, 1),      // 7: round trip
+		EncodeJMP(), rel(8, 10), // 8: jump to the next instruction
+		EncodeJMP(), rel(10, 13), // 10
+		EncodeNOP(),        // 12
+		EncodeMOV(2, 6, 7), // 13: memory reads are kept
+		EncodeMOV(2, 6, 7), // 14
+		EncodeCALL(), 0,    // 15: unresolved call
+		EncodeRET(),          // 17
+		0x1170, 1, 0x1170, 2, // 18: data that decodes as MOV R7, #imm
+	}
+	res, err := Peephole(PeepholeInput{
+		Code:  code,
+		Data:  []WordRange{{18, 22}},
+		Fixed: map[int]bool{16: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []uint16{
+		EncodeMOV(1, 7, 0), 2,
+		EncodeBEQ(), rel(2, 8),
+		EncodeMOV(0, 1, 2),
+		EncodeJMP(), rel(5, 8),
+		EncodeNOP(),
+		EncodeMOV(2, 6, 7),
+		EncodeMOV(2, 6, 7),
+		EncodeCALL(), 0,
+		EncodeRET(),
+		0x1170, 1, 0x1170, 2,
+	}
+	if !reflect.DeepEqual(res.Code, want) {
+		t.Fatalf("code =\n%04X\nwant\n%04X", res.Code, want)
+	}
+	wantStats := PeepholeStats{BeforeWords: 22, AfterWords: 17, DeadMoves: 1, RoundTrips: 1, BranchChains: 1, JumpsToNext: 1}
+	if res.Stats != wantStats {
+		t.Errorf("stats = %+v, want %+v", res.Stats, wantStats)
+	}
+	for old, idx := range map[int]int{0: 0, 8: 5, 13: 8, 16: 11, 18: 13, 22: 17} {
+		if got := res.Index(old); got != idx {
+			t.Errorf("Index(%d) = %d, want %d", old, got, idx)
+		}
+	}
+}
+
+func TestPeepholeKeepsTargetedRoundTrip(t *testing.T) {
+	code := []uint16{
+		EncodeMOV(0, 1, 2),
+		EncodeMOV(0, 2, 1), // 1: branched to below, so not redundant
+		EncodeBNE(), rel(2, 1),
+		EncodeRET(),
+	}
+	res, err := Peephole(PeepholeInput{Code: code})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(res.Code, code) {
+		t.Errorf("code changed: %04X", res.Code)
+	}
+}
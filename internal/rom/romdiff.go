@@ -0,0 +1,265 @@
+package rom
+
+import (
+	"fmt"
+	"sort"
+)
+
+// DiffMaxEdits bounds the instruction-level alignment of one bank. Banks
+// that differ by more edits than this are reported as a single replaced
+// range instead of being aligned instruction by instruction.
+const DiffMaxEdits = 1024
+
+// HeaderChange is one header field that differs between two ROMs.
+type HeaderChange struct {
+	Field string
+	A, B  uint32
+}
+
+// DiffOp is one line of an instruction diff: ' ' for context, '-' for an
+// instruction only in the first ROM, '+' for one only in the second.
+type DiffOp struct {
+	Kind byte
+	Inst Instruction
+}
+
+// DiffHunk is a run of changed instructions in one bank, with context.
+type DiffHunk struct {
+	Bank uint8
+	Ops  []DiffOp
+}
+
+// ROMDiff is the result of DiffROMs.
+type ROMDiff struct {
+	Header []HeaderChange
+	// OnlyA and OnlyB list banks present in just one ROM.
+	OnlyA, OnlyB []uint8
+	Hunks        []DiffHunk
+}
+
+// Equal reports whether the two ROMs decoded to the same header and code.
+func (d *ROMDiff) Equal() bool {
+	return len(d.Header) == 0 && len(d.OnlyA) == 0 && len(d.OnlyB) == 0 && len(d.Hunks) == 0
+}
+
+// DiffROMs compares two ROM images header field by header field and then
+// bank by bank as disassembled instructions, so inserted or removed code
+// shows up as a few changed instructions rather than every shifted byte.
+// context is the number of unchanged instructions kept around each hunk.
+func DiffROMs(a, b []byte, context int) (*ROMDiff, error) {
+	ha, err := ParseHeader(a)
+	if err != nil {
+		return nil, fmt.Errorf("first ROM: %w", err)
+	}
+	hb, err := ParseHeader(b)
+	if err != nil {
+		return nil, fmt.Errorf("second ROM: %w", err)
+	}
+	out := &ROMDiff{Header: diffHeaders(ha, hb)}
+
+	banksA, _ := DisassembleROM(a)
+	banksB, _ := DisassembleROM(b)
+	var banks []int
+	for bank := range banksA {
+		if _, ok := banksB[bank]; !ok {
+			out.OnlyA = append(out.OnlyA, bank)
+		}
+		banks = append(banks, int(bank))
+	}
+	for bank := range banksB {
+		if _, ok := banksA[bank]; !ok {
+			out.OnlyB = append(out.OnlyB, bank)
+		}
+	}
+	sort.Ints(banks)
+	sort.Slice(out.OnlyA, func(i, j int) bool { return out.OnlyA[i] < out.OnlyA[j] })
+	sort.Slice(out.OnlyB, func(i, j int) bool { return out.OnlyB[i] < out.OnlyB[j] })
+
+	for _, bank := range banks {
+		instsB, ok := banksB[uint8(bank)]
+		if !ok {
+			continue
+		}
+		script := diffInstructions(trimPadding(banksA[uint8(bank)]), trimPadding(instsB))
+		for _, ops := range groupHunks(script, context) {
+			out.Hunks = append(out.Hunks, DiffHunk{Bank: uint8(bank), Ops: ops})
+		}
+	}
+	return out, nil
+}
+
+func diffHeaders(a, b Header) []HeaderChange {
+	fields := []struct {
+		name string
+		a, b uint32
+	}{
+		{"version", uint32(a.Version), uint32(b.Version)},
+		{"size", a.Size, b.Size},
+		{"entry bank", uint32(a.EntryBank), uint32(b.EntryBank)},
+		{"entry offset", uint32(a.EntryOffset), uint32(b.EntryOffset)},
+		{"mapper flags", uint32(a.MapperFlags), uint32(b.MapperFlags)},
+		{"checksum", a.Checksum, b.Checksum},
+		{"timing", uint32(a.Timing), uint32(b.Timing)},
+	}
+	var out []HeaderChange
+	for _, f := range fields {
+		if f.a != f.b {
+			out = append(out, HeaderChange{Field: f.name, A: f.a, B: f.b})
+		}
+	}
+	return out
+}
+
+// trimPadding drops the trailing NOP (zero) words that pad a bank out to
+// its full size, so a bank that grew is not reported as rewritten padding.
+func trimPadding(insts []Instruction) []Instruction {
+	n := len(insts)
+	for n > 0 && len(insts[n-1].Words) == 1 && insts[n-1].Words[0] == 0 {
+		n--
+	}
+	return insts[:n]
+}
+
+// diffInstructions aligns a and b by instruction text (Myers' algorithm
+// after trimming the common prefix and suffix) and returns the edit script.
+func diffInstructions(a, b []Instruction) []DiffOp {
+	var ops []DiffOp
+	pre := 0
+	for pre < len(a) && pre < len(b) && a[pre].Text == b[pre].Text {
+		ops = append(ops, DiffOp{Kind: ' ', Inst: b[pre]})
+		pre++
+	}
+	suf := 0
+	for suf < len(a)-pre && suf < len(b)-pre && a[len(a)-1-suf].Text == b[len(b)-1-suf].Text {
+		suf++
+	}
+	ops = append(ops, myers(a[pre:len(a)-suf], b[pre:len(b)-suf])...)
+	for i := len(b) - suf; i < len(b); i++ {
+		ops = append(ops, DiffOp{Kind: ' ', Inst: b[i]})
+	}
+	return ops
+}
+
+func myers(a, b []Instruction) []DiffOp {
+	n, m := len(a), len(b)
+	replaceAll := func() []DiffOp {
+		ops := make([]DiffOp, 0, n+m)
+		for _, in := range a {
+			ops = append(ops, DiffOp{Kind: '-', Inst: in})
+		}
+		for _, in := range b {
+			ops = append(ops, DiffOp{Kind: '+', Inst: in})
+		}
+		return ops
+	}
+	if n == 0 || m == 0 {
+		return replaceAll()
+	}
+
+	limit := n + m
+	if limit > DiffMaxEdits {
+		limit = DiffMaxEdits
+	}
+	off := limit + 1
+	v := make([]int, 2*limit+3)
+	var trace [][]int
+	found := false
+	for d := 0; d <= limit && !found; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[off+k-1] < v[off+k+1]) {
+				x = v[off+k+1]
+			} else {
+				x = v[off+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x].Text == b[y].Text {
+				x++
+				y++
+			}
+			v[off+k] = x
+			if x >= n && y >= m {
+				found = true
+				break
+			}
+		}
+	}
+	if !found {
+		return replaceAll()
+	}
+
+	// Walk the trace backwards to recover the edit script.
+	var rev []DiffOp
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		vd := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && vd[off+k-1] < vd[off+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := vd[off+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			rev = append(rev, DiffOp{Kind: ' ', Inst: b[y]})
+		}
+		if d == 0 {
+			break
+		}
+		if x == prevX {
+			y--
+			rev = append(rev, DiffOp{Kind: '+', Inst: b[y]})
+		} else {
+			x--
+			rev = append(rev, DiffOp{Kind: '-', Inst: a[x]})
+		}
+	}
+	ops := make([]DiffOp, len(rev))
+	for i, op := range rev {
+		ops[len(rev)-1-i] = op
+	}
+	return ops
+}
+
+// groupHunks splits an edit script into hunks of changes separated by more
+// than 2*context unchanged instructions.
+func groupHunks(ops []DiffOp, context int) [][]DiffOp {
+	if context < 0 {
+		context = 0
+	}
+	var hunks [][]DiffOp
+	start, end := -1, -1
+	flush := func() {
+		if start < 0 {
+			return
+		}
+		lo, hi := start-context, end+context
+		if lo < 0 {
+			lo = 0
+		}
+		if hi >= len(ops) {
+			hi = len(ops) - 1
+		}
+		hunks = append(hunks, ops[lo:hi+1])
+		start = -1
+	}
+	for i, op := range ops {
+		if op.Kind == ' ' {
+			continue
+		}
+		if start >= 0 && i-end-1 > 2*context {
+			flush()
+		}
+		if start < 0 {
+			start = i
+		}
+		end = i
+	}
+	flush()
+	return hunks
+}
@@ -0,0 +1,97 @@
+package rom
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDisassembleBankDecodesImmediatesAndBranches(t *testing.T) {
+	b := NewROMBuilder()
+	b.AddInstruction(EncodeMOV(1, 2, 0))
+	b.AddImmediate(0x1234)
+	b.AddInstruction(EncodeCMP(7, 2, 0))
+	b.AddImmediate(5)
+	b.AddInstruction(EncodeBNE())
+	b.AddImmediate(uint16(CalculateBranchOffset(10, 0)))
+	b.AddInstruction(EncodeMOV(9, 1, 3))
+	b.AddImmediate(0xFFFE)
+	b.AddInstruction(EncodeRET())
+	data, err := b.BuildROMBytes(1, 0x8000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	banks, err := DisassembleROM(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	insts := banks[1]
+	want := []string{"MOV R2, #0x1234", "CMP R2, #0x0005", "BNE -12", "MOV R1, [R3-2]", "RET"}
+	if len(insts) != len(want) {
+		t.Fatalf("got %d instructions, want %d: %+v", len(insts), len(want), insts)
+	}
+	for i, w := range want {
+		if insts[i].Text != w {
+			t.Errorf("inst %d = %q, want %q", i, insts[i].Text, w)
+		}
+	}
+	if br := insts[2]; !br.HasTarget || br.Offset != 0x8008 || br.Target != 0x8000 {
+		t.Errorf("BNE at %04X target %04X (%v), want 8008 -> 8000", br.Offset, br.Target, br.HasTarget)
+	}
+}
+
+func TestDiffROMsAlignsInsertedInstructions(t *testing.T) {
+	build := func(extra bool) []byte {
+		b := NewROMBuilder()
+		for i := 0; i < 20; i++ {
+			b.AddInstruction(EncodeMOV(1, uint8(i%8), 0))
+			b.AddImmediate(uint16(i))
+			if extra && i == 10 {
+				b.AddInstruction(EncodeADD(0, 1, 2))
+			}
+		}
+		b.AddInstruction(EncodeRET())
+		data, err := b.BuildROMBytes(1, 0x8000)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+
+	same, err := DiffROMs(build(false), build(false), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !same.Equal() {
+		t.Fatalf("identical ROMs differ: %+v", same)
+	}
+
+	d, err := DiffROMs(build(false), build(true), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Header) != 1 || d.Header[0].Field != "size" {
+		t.Errorf("header changes = %+v, want size only", d.Header)
+	}
+	if len(d.Hunks) != 1 {
+		t.Fatalf("got %d hunks, want 1", len(d.Hunks))
+	}
+	var changed []string
+	for _, op := range d.Hunks[0].Ops {
+		if op.Kind != ' ' {
+			changed = append(changed, string(op.Kind)+op.Inst.Text)
+		}
+	}
+	if strings.Join(changed, "|") != "+ADD R1, R2" {
+		t.Errorf("changed = %q, want the inserted ADD only", changed)
+	}
+	if len(d.Hunks[0].Ops) != 5 {
+		t.Errorf("hunk has %d lines, want 1 change and 2+2 context", len(d.Hunks[0].Ops))
+	}
+}
+
+func TestParseHeaderRejectsBadMagic(t *testing.T) {
+	if _, err := ParseHeader(make([]byte, HeaderSize)); err == nil {
+		t.Fatal("expected an error for a zero header")
+	}
+}
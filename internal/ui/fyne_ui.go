@@ -1,17 +1,22 @@
 package ui
 
 import (
-	"encoding/binary"
 	"fmt"
 	"image"
+	"image/color"
 	"io"
-	"math"
+	"path/filepath"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"nitro-core-dx/internal/apu"
 	"nitro-core-dx/internal/debug"
 	"nitro-core-dx/internal/emulator"
+	"nitro-core-dx/internal/fileassoc"
+	"nitro-core-dx/internal/harness"
+	"nitro-core-dx/internal/i18n"
+	"nitro-core-dx/internal/input"
 	"nitro-core-dx/internal/ui/panels"
 
 	"fyne.io/fyne/v2"
@@ -20,6 +25,7 @@ import (
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/driver/desktop"
 	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/storage"
 	"fyne.io/fyne/v2/widget"
 	"github.com/veandco/go-sdl2/sdl"
@@ -41,7 +47,10 @@ type FyneUI struct {
 	// Fyne widgets
 	emulatorImage *canvas.Image
 	statusLabel   *widget.Label
+	perfOSD       *canvas.Text // frame timing overlay drawn over the screen
+	showPerfOSD   bool
 	frameImages   [2]*image.RGBA
+	displayFrame  []uint32 // DisplayFrame output, reused between frames
 	frameImageIdx int
 
 	// Debug panels
@@ -72,6 +81,38 @@ type FyneUI struct {
 	keyStates        map[fyne.KeyName]bool
 	typedKeyUntil    map[fyne.KeyName]time.Time // fallback "held" lease for typed-only platforms
 	desktopKeyEvents bool
+
+	// Turbo buttons and the input macro, applied on top of the keys held
+	hostInput *input.HostInput
+
+	// debugConsole receives the ROM's debug.print lines (see
+	// SetDebugConsole); nil discards them.
+	debugConsole io.Writer
+
+	// The movie being recorded or played (see movie.go). The update loop
+	// drives it; movieMu guards it against FinishMovie after Run.
+	movieMu     sync.Mutex
+	movieRec    *harness.MovieRecorder
+	moviePlay   *harness.MoviePlayer
+	movieLen    int
+	movieDesync *harness.MovieMismatch
+
+	// Jukebox mode (see playlist.go). The update loop owns everything but
+	// playlistStep, which the hotkeys add to.
+	playlist         *fileassoc.Playlist
+	playlistInterval time.Duration
+	playlistLoaded   time.Time
+	playlistErr      error
+	playlistStep     atomic.Int32
+
+	// inputDisplayToggle is set by View > Input Display and consumed by the
+	// update loop (see input_display.go).
+	inputDisplayToggle atomic.Bool
+
+	// Frame pacing: how the update loop waits between ticks, and
+	// automatic frame-skip (see SetFramePacing and SetMaxFrameSkip).
+	pacing    emulator.FramePacing
+	frameSkip emulator.FrameSkipper
 }
 
 // NewFyneUI creates a new Fyne-based UI
@@ -103,15 +144,26 @@ func NewFyneUI(emu *emulator.Emulator, scale int) (*FyneUI, error) {
 	window := fyneApp.NewWindow("Nitro-Core-DX Emulator")
 
 	// Create status label
-	statusLabel := widget.NewLabel("FPS: 0.0 | CPU: 0 cycles/frame | Frame: 0")
+	statusLabel := widget.NewLabel(i18n.T("FPS: 0.0 | CPU: 0 cycles/frame | Frame: 0"))
 
 	// Create reusable frame buffers for UI rendering (double-buffered to avoid UI thread races).
-	frame0 := image.NewRGBA(image.Rect(0, 0, 320*scale, 200*scale))
-	frame1 := image.NewRGBA(image.Rect(0, 0, 320*scale, 200*scale))
+	// The picture is 320×200 unless the display options crop it or add a border.
+	screenW, screenH := emu.DisplayOptions().Size()
+	frame0 := image.NewRGBA(image.Rect(0, 0, screenW*scale, screenH*scale))
+	frame1 := image.NewRGBA(image.Rect(0, 0, screenW*scale, screenH*scale))
 	// Create emulator image (will be updated with rendered frames)
 	emulatorImage := canvas.NewImageFromImage(frame0)
 	emulatorImage.FillMode = canvas.ImageFillContain
 
+	// Performance OSD (hidden until toggled from View > Performance OSD)
+	perfOSD := canvas.NewText("", color.NRGBA{R: 0xF0, G: 0xF0, B: 0x40, A: 0xFF})
+	perfOSD.TextStyle = fyne.TextStyle{Monospace: true}
+	perfOSD.Hide()
+	screen := container.NewStack(
+		emulatorImage,
+		container.NewVBox(container.NewHBox(perfOSD, layout.NewSpacer())),
+	)
+
 	// Create debug panels (initially hidden)
 	registersPanel, updateRegistersFunc := panels.RegisterViewer(emu, window)
 	registersPanel.Hide()
@@ -142,6 +194,7 @@ func NewFyneUI(emu *emulator.Emulator, scale int) (*FyneUI, error) {
 		audioFrame:      make([]byte, 735*2*4),
 		emulatorImage:   emulatorImage,
 		statusLabel:     statusLabel,
+		perfOSD:         perfOSD,
 		frameImages:     [2]*image.RGBA{frame0, frame1},
 		registersPanel:  registersPanel,
 		memoryPanel:     memoryPanel,
@@ -153,6 +206,7 @@ func NewFyneUI(emu *emulator.Emulator, scale int) (*FyneUI, error) {
 		updateLogs:      updateLogsFunc,
 		keyStates:       make(map[fyne.KeyName]bool),
 		typedKeyUntil:   make(map[fyne.KeyName]time.Time),
+		hostInput:       input.NewHostInput(),
 	}
 
 	// Create right-side panel container (vertical stack for multiple panels)
@@ -168,7 +222,7 @@ func NewFyneUI(emu *emulator.Emulator, scale int) (*FyneUI, error) {
 
 	// Create horizontal splitter for resizable panels
 	// Left side: emulator screen, Right side: debug panels
-	splitContent := container.NewHSplit(emulatorImage, rightPanels)
+	splitContent := container.NewHSplit(screen, rightPanels)
 	// Initially hide panels by setting offset to 1.0 (fully to the right, panels hidden)
 	splitContent.SetOffset(1.0)
 
@@ -190,7 +244,7 @@ func NewFyneUI(emu *emulator.Emulator, scale int) (*FyneUI, error) {
 	window.SetContent(mainContent)
 	// Set initial window size (emulator + panels + status bar)
 	// Window is resizable, so this is just the initial size
-	window.Resize(fyne.NewSize(float32(320*scale)+400, float32(200*scale)+50))
+	window.Resize(fyne.NewSize(float32(screenW*scale)+400, float32(screenH*scale)+50))
 	window.CenterOnScreen()
 
 	// Create menus (pass UI instance for panel toggling)
@@ -199,6 +253,11 @@ func NewFyneUI(emu *emulator.Emulator, scale int) (*FyneUI, error) {
 	// Set up keyboard input handling
 	setupKeyboardInput(window, ui)
 
+	// Dropping a .rom loads it; dropping CoreLX source builds it first.
+	window.SetOnDropped(func(_ fyne.Position, uris []fyne.URI) {
+		ui.openDropped(uris)
+	})
+
 	return ui, nil
 }
 
@@ -312,6 +371,14 @@ func (ui *FyneUI) applyFyneKeyStates(buttons uint16) uint16 {
 
 // updateInputFromKeys updates the emulator's input state based on current SDL keyboard state
 func (ui *FyneUI) updateInputFromKeys() {
+	// Always set input, even if 0 (this ensures input is cleared when no keys are pressed)
+	// This also ensures the latched state will be 0 when the ROM next latches
+	ui.emulator.SetInputButtons(ui.hostInput.Apply(ui.currentButtons()))
+}
+
+// currentButtons returns the controller 1 button mask for the keys held
+// right now.
+func (ui *FyneUI) currentButtons() uint16 {
 	// Always start with 0 - only set bits if keys are actually pressed
 	var buttons uint16 = 0
 
@@ -378,11 +445,86 @@ func (ui *FyneUI) updateInputFromKeys() {
 
 	// Merge Fyne key state tracking. This is the primary path for the Fyne window and
 	// also acts as a fallback when SDL keyboard state does not reflect Fyne focus/input.
-	buttons = ui.applyFyneKeyStates(buttons)
+	return ui.applyFyneKeyStates(buttons)
+}
 
-	// Always set input, even if 0 (this ensures input is cleared when no keys are pressed)
-	// This also ensures the latched state will be 0 when the ROM next latches
-	ui.emulator.SetInputButtons(buttons)
+// SetMidFrameInputPoll makes the emulator read the keyboard at the moment
+// the ROM latches the controller, instead of using the state sampled once
+// per UI tick before the frame.
+func (ui *FyneUI) SetMidFrameInputPoll(enabled bool) {
+	if enabled {
+		ui.emulator.Input.Poll = func() uint16 { return ui.hostInput.Apply(ui.currentButtons()) }
+	} else {
+		ui.emulator.Input.Poll = nil
+	}
+}
+
+// SetDebugConsole prints each line the ROM writes to the debug console
+// (DEBUG_* registers, debug.print in CoreLX) to w as frames complete.
+func (ui *FyneUI) SetDebugConsole(w io.Writer) {
+	ui.debugConsole = w
+}
+
+// SetTurbo makes the buttons in mask auto-fire at hz presses per second
+// while held.
+func (ui *FyneUI) SetTurbo(mask uint16, hz int) {
+	ui.hostInput.SetTurbo(mask, hz)
+}
+
+// SetFramePacing sets how the update loop waits for its next tick (see
+// emulator.FramePacing). Call it before Run.
+func (ui *FyneUI) SetFramePacing(p emulator.FramePacing) {
+	ui.pacing = p
+}
+
+// SetMaxFrameSkip sets the most frames automatic frame-skip leaves undrawn
+// per frame drawn when the host cannot keep up; 0 draws every frame. Call
+// it before Run.
+func (ui *FyneUI) SetMaxFrameSkip(n int) {
+	ui.frameSkip.Max = n
+}
+
+// tickInterval is the update loop's tick: one emulated frame, or one
+// refresh of the primary display, as SDL reports it, with vsync pacing.
+func (ui *FyneUI) tickInterval() time.Duration {
+	if ui.pacing == emulator.PacingVSync {
+		if mode, err := sdl.GetCurrentDisplayMode(0); err == nil && mode.RefreshRate > 0 {
+			return time.Second / time.Duration(mode.RefreshRate)
+		}
+	}
+	return ui.emulator.FrameTime
+}
+
+// frameSkipStatusText is the status bar note shown while frames are being
+// skipped.
+func (ui *FyneUI) frameSkipStatusText() string {
+	if level := ui.frameSkip.Level(); level > 0 {
+		return fmt.Sprintf(" | SKIP %d", level)
+	}
+	return ""
+}
+
+// toggleMacroRecording starts recording an input macro, or stops the one
+// being recorded.
+func (ui *FyneUI) toggleMacroRecording() {
+	if !ui.hostInput.Recording() {
+		ui.hostInput.StartRecording()
+		ui.statusLabel.SetText(i18n.T("Recording macro... (Ctrl+M to stop)"))
+		ui.emulator.Notify("Macro recording started")
+		return
+	}
+	n := ui.hostInput.StopRecording()
+	ui.statusLabel.SetText(i18n.T("Macro recorded: %d frames (Ctrl+Shift+M to play)", n))
+	ui.emulator.Notify(fmt.Sprintf("Macro recorded: %d frames", n))
+}
+
+// playMacro replays the recorded input macro.
+func (ui *FyneUI) playMacro() {
+	if !ui.hostInput.Play() {
+		ui.statusLabel.SetText(i18n.T("No macro recorded (Ctrl+M to record)"))
+		return
+	}
+	ui.emulator.Notify("Macro playing")
 }
 
 // updateLayout updates the main layout based on which panels are visible
@@ -418,11 +560,40 @@ func (ui *FyneUI) loadROMBytes(data []byte) error {
 	return nil
 }
 
+// OpenPath loads a .rom file or a zipped game, or builds a CoreLX source
+// (.corelx/.clx) or .ncdx project and loads the result.
+func (ui *FyneUI) OpenPath(path string) error {
+	data, err := fileassoc.ReadROM(path)
+	if err != nil {
+		return err
+	}
+	if err := ui.loadROMBytes(data); err != nil {
+		return fmt.Errorf("failed to load ROM: %w", err)
+	}
+	ui.statusLabel.SetText(i18n.T("Loaded ROM: %s", filepath.Base(path)))
+	ui.emulator.Notify("Loaded " + filepath.Base(path))
+	return nil
+}
+
+// openDropped opens the first dropped local file the emulator understands.
+func (ui *FyneUI) openDropped(uris []fyne.URI) {
+	for _, u := range uris {
+		if u == nil || u.Scheme() != "file" || fileassoc.Classify(u.Path()) == fileassoc.KindUnknown {
+			continue
+		}
+		if err := ui.OpenPath(u.Path()); err != nil {
+			dialog.ShowError(err, ui.window)
+		}
+		return
+	}
+	ui.statusLabel.SetText(i18n.T("Drop a .rom or .corelx file to load it"))
+}
+
 // createMenus creates the native Fyne menus
 func createMenus(window fyne.Window, emu *emulator.Emulator, ui *FyneUI) {
 	// File menu
-	fileMenu := fyne.NewMenu("File",
-		fyne.NewMenuItem("Open ROM...", func() {
+	fileMenu := fyne.NewMenu(i18n.T("File"),
+		fyne.NewMenuItem(i18n.T("Open ROM..."), func() {
 			openDialog := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
 				if err != nil {
 					dialog.ShowError(fmt.Errorf("failed to open ROM: %w", err), window)
@@ -434,6 +605,9 @@ func createMenus(window fyne.Window, emu *emulator.Emulator, ui *FyneUI) {
 				defer reader.Close()
 
 				data, readErr := io.ReadAll(reader)
+				if readErr == nil {
+					data, readErr = fileassoc.UnpackROM(reader.URI().Name(), data)
+				}
 				if readErr != nil {
 					dialog.ShowError(fmt.Errorf("failed to read ROM: %w", readErr), window)
 					return
@@ -445,44 +619,91 @@ func createMenus(window fyne.Window, emu *emulator.Emulator, ui *FyneUI) {
 				}
 
 				romName := reader.URI().Name()
-				ui.statusLabel.SetText(fmt.Sprintf("Loaded ROM: %s", romName))
+				ui.statusLabel.SetText(i18n.T("Loaded ROM: %s", romName))
+				ui.emulator.Notify("Loaded " + romName)
 			}, window)
-			openDialog.SetFilter(storage.NewExtensionFileFilter([]string{".rom"}))
+			openDialog.SetFilter(storage.NewExtensionFileFilter([]string{".rom", ".zip"}))
 			openDialog.Show()
 		}),
 		fyne.NewMenuItemSeparator(),
-		fyne.NewMenuItem("Exit", func() {
+		fyne.NewMenuItem(i18n.T("Exit"), func() {
 			window.Close()
 		}),
 	)
 
+	// Reset button (Ctrl+R) keeps RAM; power cycle (Ctrl+Shift+R) does not.
+	softResetKey := &desktop.CustomShortcut{KeyName: fyne.KeyR, Modifier: fyne.KeyModifierControl}
+	powerCycleKey := &desktop.CustomShortcut{KeyName: fyne.KeyR, Modifier: fyne.KeyModifierControl | fyne.KeyModifierShift}
+	softReset := fyne.NewMenuItem(i18n.T("Soft Reset"), func() {
+		emu.SoftReset()
+	})
+	softReset.Shortcut = softResetKey
+	powerCycle := fyne.NewMenuItem(i18n.T("Power Cycle"), func() {
+		emu.Reset()
+	})
+	powerCycle.Shortcut = powerCycleKey
+	window.Canvas().AddShortcut(softResetKey, func(fyne.Shortcut) { emu.SoftReset() })
+	window.Canvas().AddShortcut(powerCycleKey, func(fyne.Shortcut) { emu.Reset() })
+
+	// Input macro: Ctrl+M starts/stops recording, Ctrl+Shift+M plays it back.
+	recordMacroKey := &desktop.CustomShortcut{KeyName: fyne.KeyM, Modifier: fyne.KeyModifierControl}
+	playMacroKey := &desktop.CustomShortcut{KeyName: fyne.KeyM, Modifier: fyne.KeyModifierControl | fyne.KeyModifierShift}
+	recordMacro := fyne.NewMenuItem(i18n.T("Record Macro"), ui.toggleMacroRecording)
+	recordMacro.Shortcut = recordMacroKey
+	playMacro := fyne.NewMenuItem(i18n.T("Play Macro"), ui.playMacro)
+	playMacro.Shortcut = playMacroKey
+	window.Canvas().AddShortcut(recordMacroKey, func(fyne.Shortcut) { ui.toggleMacroRecording() })
+	window.Canvas().AddShortcut(playMacroKey, func(fyne.Shortcut) { ui.playMacro() })
+
+	// Playlist (-playlist): Ctrl+PageDown / Ctrl+PageUp load the next and
+	// previous ROM.
+	nextROMKey := &desktop.CustomShortcut{KeyName: fyne.KeyPageDown, Modifier: fyne.KeyModifierControl}
+	prevROMKey := &desktop.CustomShortcut{KeyName: fyne.KeyPageUp, Modifier: fyne.KeyModifierControl}
+	nextROM := fyne.NewMenuItem(i18n.T("Next ROM"), func() { ui.stepPlaylist(1) })
+	nextROM.Shortcut = nextROMKey
+	prevROM := fyne.NewMenuItem(i18n.T("Previous ROM"), func() { ui.stepPlaylist(-1) })
+	prevROM.Shortcut = prevROMKey
+	window.Canvas().AddShortcut(nextROMKey, func(fyne.Shortcut) { ui.stepPlaylist(1) })
+	window.Canvas().AddShortcut(prevROMKey, func(fyne.Shortcut) { ui.stepPlaylist(-1) })
+
 	// Emulation menu
-	emulationMenu := fyne.NewMenu("Emulation",
-		fyne.NewMenuItem("Start", func() {
+	emulationMenu := fyne.NewMenu(i18n.T("Emulation"),
+		fyne.NewMenuItem(i18n.T("Start"), func() {
 			emu.Start()
 		}),
-		fyne.NewMenuItem("Pause", func() {
+		fyne.NewMenuItem(i18n.T("Pause"), func() {
 			emu.Pause()
 		}),
-		fyne.NewMenuItem("Resume", func() {
+		fyne.NewMenuItem(i18n.T("Resume"), func() {
 			emu.Resume()
 		}),
-		fyne.NewMenuItem("Stop", func() {
+		fyne.NewMenuItem(i18n.T("Stop"), func() {
 			emu.Stop()
 		}),
-		fyne.NewMenuItem("Restart", func() {
-			emu.Reset()
-		}),
-		fyne.NewMenuItem("Step Frame", func() {
+		softReset,
+		powerCycle,
+		fyne.NewMenuItem(i18n.T("Step Frame"), func() {
 			if emu.Paused {
 				emu.RunFrame()
 			}
 		}),
+		fyne.NewMenuItemSeparator(),
+		recordMacro,
+		playMacro,
+		fyne.NewMenuItemSeparator(),
+		nextROM,
+		prevROM,
 	)
 
+	// Input display overlay: View > Input Display or Ctrl+I.
+	inputDisplayKey := &desktop.CustomShortcut{KeyName: fyne.KeyI, Modifier: fyne.KeyModifierControl}
+	inputDisplay := fyne.NewMenuItem(i18n.T("Input Display"), ui.toggleInputDisplay)
+	inputDisplay.Shortcut = inputDisplayKey
+	window.Canvas().AddShortcut(inputDisplayKey, func(fyne.Shortcut) { ui.toggleInputDisplay() })
+
 	// View menu
-	viewMenu := fyne.NewMenu("View",
-		fyne.NewMenuItem("Log Viewer", func() {
+	viewMenu := fyne.NewMenu(i18n.T("View"),
+		fyne.NewMenuItem(i18n.T("Log Viewer"), func() {
 			ui.showLogViewer = !ui.showLogViewer
 			if ui.logViewerPanel != nil {
 				if ui.showLogViewer {
@@ -494,7 +715,7 @@ func createMenus(window fyne.Window, emu *emulator.Emulator, ui *FyneUI) {
 			ui.updateLayout()
 		}),
 		fyne.NewMenuItemSeparator(),
-		fyne.NewMenuItem("Registers", func() {
+		fyne.NewMenuItem(i18n.T("Registers"), func() {
 			ui.showRegisters = !ui.showRegisters
 			if ui.registersPanel != nil {
 				if ui.showRegisters {
@@ -505,7 +726,7 @@ func createMenus(window fyne.Window, emu *emulator.Emulator, ui *FyneUI) {
 			}
 			ui.updateLayout()
 		}),
-		fyne.NewMenuItem("Memory Viewer", func() {
+		fyne.NewMenuItem(i18n.T("Memory Viewer"), func() {
 			ui.showMemory = !ui.showMemory
 			if ui.memoryPanel != nil {
 				if ui.showMemory {
@@ -516,7 +737,7 @@ func createMenus(window fyne.Window, emu *emulator.Emulator, ui *FyneUI) {
 			}
 			ui.updateLayout()
 		}),
-		fyne.NewMenuItem("Tile Viewer", func() {
+		fyne.NewMenuItem(i18n.T("Tile Viewer"), func() {
 			ui.showTiles = !ui.showTiles
 			if ui.tilesPanel != nil {
 				if ui.showTiles {
@@ -527,11 +748,16 @@ func createMenus(window fyne.Window, emu *emulator.Emulator, ui *FyneUI) {
 			}
 			ui.updateLayout()
 		}),
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem(i18n.T("Performance OSD"), func() {
+			ui.SetPerfOSD(!ui.showPerfOSD)
+		}),
+		inputDisplay,
 	)
 
 	// Debug menu
-	debugMenu := fyne.NewMenu("Debug",
-		fyne.NewMenuItem("Registers", func() {
+	debugMenu := fyne.NewMenu(i18n.T("Debug"),
+		fyne.NewMenuItem(i18n.T("Registers"), func() {
 			ui.showRegisters = !ui.showRegisters
 			if ui.registersPanel != nil {
 				if ui.showRegisters {
@@ -542,7 +768,7 @@ func createMenus(window fyne.Window, emu *emulator.Emulator, ui *FyneUI) {
 			}
 			ui.updateLayout()
 		}),
-		fyne.NewMenuItem("Memory Viewer", func() {
+		fyne.NewMenuItem(i18n.T("Memory Viewer"), func() {
 			ui.showMemory = !ui.showMemory
 			if ui.memoryPanel != nil {
 				if ui.showMemory {
@@ -553,7 +779,7 @@ func createMenus(window fyne.Window, emu *emulator.Emulator, ui *FyneUI) {
 			}
 			ui.updateLayout()
 		}),
-		fyne.NewMenuItem("Tile Viewer", func() {
+		fyne.NewMenuItem(i18n.T("Tile Viewer"), func() {
 			ui.showTiles = !ui.showTiles
 			if ui.tilesPanel != nil {
 				if ui.showTiles {
@@ -565,7 +791,7 @@ func createMenus(window fyne.Window, emu *emulator.Emulator, ui *FyneUI) {
 			ui.updateLayout()
 		}),
 		fyne.NewMenuItemSeparator(),
-		fyne.NewMenuItem("Toggle Cycle Logging", func() {
+		fyne.NewMenuItem(i18n.T("Toggle Cycle Logging"), func() {
 			if emu.CycleLogger != nil {
 				emu.CycleLogger.Toggle()
 				// Only show cycle logging status if logging is enabled
@@ -588,8 +814,8 @@ func createMenus(window fyne.Window, emu *emulator.Emulator, ui *FyneUI) {
 
 	// Create logging submenu
 	if emu.Logger != nil {
-		loggingSubmenu := fyne.NewMenu("Logging",
-			fyne.NewMenuItem("Enable All Logging", func() {
+		loggingSubmenu := fyne.NewMenu(i18n.T("Logging"),
+			fyne.NewMenuItem(i18n.T("Enable All Logging"), func() {
 				emu.Logger.SetComponentEnabled(debug.ComponentCPU, true)
 				emu.Logger.SetComponentEnabled(debug.ComponentPPU, true)
 				emu.Logger.SetComponentEnabled(debug.ComponentAPU, true)
@@ -598,7 +824,7 @@ func createMenus(window fyne.Window, emu *emulator.Emulator, ui *FyneUI) {
 				emu.Logger.SetComponentEnabled(debug.ComponentUI, true)
 				emu.Logger.SetComponentEnabled(debug.ComponentSystem, true)
 			}),
-			fyne.NewMenuItem("Disable All Logging", func() {
+			fyne.NewMenuItem(i18n.T("Disable All Logging"), func() {
 				emu.Logger.SetComponentEnabled(debug.ComponentCPU, false)
 				emu.Logger.SetComponentEnabled(debug.ComponentPPU, false)
 				emu.Logger.SetComponentEnabled(debug.ComponentAPU, false)
@@ -608,31 +834,31 @@ func createMenus(window fyne.Window, emu *emulator.Emulator, ui *FyneUI) {
 				emu.Logger.SetComponentEnabled(debug.ComponentSystem, false)
 			}),
 			fyne.NewMenuItemSeparator(),
-			fyne.NewMenuItem("Toggle CPU Logging", func() {
+			fyne.NewMenuItem(i18n.T("Toggle CPU Logging"), func() {
 				enabled := emu.Logger.IsComponentEnabled(debug.ComponentCPU)
 				emu.Logger.SetComponentEnabled(debug.ComponentCPU, !enabled)
 			}),
-			fyne.NewMenuItem("Toggle PPU Logging", func() {
+			fyne.NewMenuItem(i18n.T("Toggle PPU Logging"), func() {
 				enabled := emu.Logger.IsComponentEnabled(debug.ComponentPPU)
 				emu.Logger.SetComponentEnabled(debug.ComponentPPU, !enabled)
 			}),
-			fyne.NewMenuItem("Toggle APU Logging", func() {
+			fyne.NewMenuItem(i18n.T("Toggle APU Logging"), func() {
 				enabled := emu.Logger.IsComponentEnabled(debug.ComponentAPU)
 				emu.Logger.SetComponentEnabled(debug.ComponentAPU, !enabled)
 			}),
-			fyne.NewMenuItem("Toggle Memory Logging", func() {
+			fyne.NewMenuItem(i18n.T("Toggle Memory Logging"), func() {
 				enabled := emu.Logger.IsComponentEnabled(debug.ComponentMemory)
 				emu.Logger.SetComponentEnabled(debug.ComponentMemory, !enabled)
 			}),
-			fyne.NewMenuItem("Toggle Input Logging", func() {
+			fyne.NewMenuItem(i18n.T("Toggle Input Logging"), func() {
 				enabled := emu.Logger.IsComponentEnabled(debug.ComponentInput)
 				emu.Logger.SetComponentEnabled(debug.ComponentInput, !enabled)
 			}),
-			fyne.NewMenuItem("Toggle UI Logging", func() {
+			fyne.NewMenuItem(i18n.T("Toggle UI Logging"), func() {
 				enabled := emu.Logger.IsComponentEnabled(debug.ComponentUI)
 				emu.Logger.SetComponentEnabled(debug.ComponentUI, !enabled)
 			}),
-			fyne.NewMenuItem("Toggle System Logging", func() {
+			fyne.NewMenuItem(i18n.T("Toggle System Logging"), func() {
 				enabled := emu.Logger.IsComponentEnabled(debug.ComponentSystem)
 				emu.Logger.SetComponentEnabled(debug.ComponentSystem, !enabled)
 			}),
@@ -640,12 +866,12 @@ func createMenus(window fyne.Window, emu *emulator.Emulator, ui *FyneUI) {
 		// Insert the logging submenu before the separator and cycle logging
 		// Find the separator before "Toggle Cycle Logging"
 		for i, item := range debugMenu.Items {
-			if item.Label == "Toggle Cycle Logging" {
+			if item.Label == i18n.T("Toggle Cycle Logging") {
 				// Insert logging submenu before this item
 				newItems := make([]*fyne.MenuItem, 0, len(debugMenu.Items)+1)
 				newItems = append(newItems, debugMenu.Items[:i-1]...) // Items before separator
 				// Create menu item with submenu
-				loggingMenuItem := fyne.NewMenuItem("Logging", nil)
+				loggingMenuItem := fyne.NewMenuItem(i18n.T("Logging"), nil)
 				loggingMenuItem.ChildMenu = loggingSubmenu
 				newItems = append(newItems, loggingMenuItem)
 				newItems = append(newItems, debugMenu.Items[i-1:]...) // Separator and rest
@@ -656,11 +882,11 @@ func createMenus(window fyne.Window, emu *emulator.Emulator, ui *FyneUI) {
 	}
 
 	// Help menu
-	helpMenu := fyne.NewMenu("Help",
-		fyne.NewMenuItem("About", func() {
+	helpMenu := fyne.NewMenu(i18n.T("Help"),
+		fyne.NewMenuItem(i18n.T("About"), func() {
 			dialog.ShowInformation(
-				"About Nitro-Core-DX Emulator",
-				"Nitro-Core-DX Emulator is the standalone emulator UI for Nitro-Core-DX.\n\nUse File > Open ROM... to load a .rom file, then use Emulation controls to start/pause/reset.",
+				i18n.T("About Nitro-Core-DX Emulator"),
+				i18n.T("Nitro-Core-DX Emulator is the standalone emulator UI for Nitro-Core-DX.\n\nUse File > Open ROM... to load a .rom file, then use Emulation controls to start/pause/reset."),
 				window,
 			)
 		}),
@@ -679,16 +905,17 @@ func createMenus(window fyne.Window, emu *emulator.Emulator, ui *FyneUI) {
 
 // renderEmulatorScreen renders the emulator screen and converts to scaled Fyne image
 func (ui *FyneUI) renderEmulatorScreen() (image.Image, error) {
-	// Get output buffer from emulator. The UI update loop calls RunFrame() and then renders
-	// on the same goroutine, so reading the buffer here is safe.
-	buffer := ui.emulator.GetOutputBuffer()
-
-	if len(buffer) != 320*200 {
-		return nil, fmt.Errorf("buffer size mismatch: expected %d, got %d", 320*200, len(buffer))
-	}
+	// Get the framed picture (active area plus any crop/border) from the emulator. The UI
+	// update loop calls RunFrame() and then renders on the same goroutine, so reading the
+	// buffer here is safe.
+	buffer, width, height := ui.emulator.DisplayFrame(ui.displayFrame)
+	ui.displayFrame = buffer
 
 	// Reuse double-buffered RGBA images to avoid per-frame allocations.
 	img := ui.frameImages[ui.frameImageIdx]
+	if b := img.Bounds(); b.Dx() != width*ui.scale || b.Dy() != height*ui.scale {
+		return nil, fmt.Errorf("frame size mismatch: image is %dx%d, frame is %dx%d at scale %d", b.Dx(), b.Dy(), width, height, ui.scale)
+	}
 	ui.frameImageIdx ^= 1
 
 	// Scale pixels manually for perfect integer scaling (direct Pix writes are much
@@ -696,9 +923,9 @@ func (ui *FyneUI) renderEmulatorScreen() (image.Image, error) {
 	pix := img.Pix
 	stride := img.Stride
 	scale := ui.scale
-	for y := 0; y < 200; y++ {
-		for x := 0; x < 320; x++ {
-			idx := y*320 + x
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			idx := y*width + x
 			colorValue := buffer[idx]
 
 			// Convert RGB888 to RGBA
@@ -725,6 +952,17 @@ func (ui *FyneUI) renderEmulatorScreen() (image.Image, error) {
 	return img, nil
 }
 
+// SetPerfOSD shows or hides the frame timing overlay (speed %, frame time
+// percentiles, audio queue depth) drawn over the emulator screen.
+func (ui *FyneUI) SetPerfOSD(show bool) {
+	ui.showPerfOSD = show
+	if show {
+		ui.perfOSD.Show()
+	} else {
+		ui.perfOSD.Hide()
+	}
+}
+
 // Run runs the Fyne UI main loop
 func (ui *FyneUI) Run() error {
 	defer ui.Cleanup()
@@ -750,26 +988,34 @@ func (ui *FyneUI) Run() error {
 	return nil
 }
 
-// updateLoop updates the UI at 60 FPS
+// updateLoop updates the UI once per tick of the frame pacer and runs frames at the
+// emulator's rate
 func (ui *FyneUI) updateLoop() {
-	// Run a higher-rate UI tick and advance emulation using a fixed 60Hz timestep accumulator.
-	// This keeps gameplay speed stable even when UI rendering occasionally stutters.
-	const emuHz = 60
-	const uiTickHz = 120
+	// Tick once per emulator frame (or display refresh with vsync pacing) and advance
+	// emulation using a fixed timestep accumulator (one emulator FrameTime: 60Hz, or 50Hz
+	// for a ROM with 50 Hz timing). This keeps gameplay speed stable even when UI
+	// rendering occasionally stutters; frame-skip drops drawing, never emulation, when
+	// the host cannot keep up.
 	const maxCatchUpFrames = 4
-	frameStep := time.Second / emuHz
+	// A tick that wakes up to frameSlack early still runs its frame; the accumulator
+	// carries the difference, so jitter around the deadline does not alternate
+	// between zero and two frames per tick.
+	const frameSlack = time.Millisecond
 
-	ticker := time.NewTicker(time.Second / uiTickHz)
-	defer ticker.Stop()
+	pacer := emulator.NewFramePacer(ui.pacing, ui.tickInterval())
 	uiTickCount := 0
 	lastTick := time.Now()
 	accumulator := time.Duration(0)
 	wasRunning := ui.emulator.Running
 
 	for ui.running {
-		<-ticker.C
+		// Follow the ROM's frame rate, which loading another ROM can change.
+		if ui.pacing != emulator.PacingVSync {
+			pacer.Interval = ui.emulator.FrameTime
+		}
+		now := pacer.Wait()
 		uiTickCount++
-		now := time.Now()
+		tickStart := now
 		delta := now.Sub(lastTick)
 		lastTick = now
 		// Clamp long stalls (window drag/breakpoint/suspend) to avoid huge catch-up bursts.
@@ -777,6 +1023,9 @@ func (ui *FyneUI) updateLoop() {
 			delta = 250 * time.Millisecond
 		}
 
+		ui.advancePlaylist(now)
+		ui.applyInputDisplayToggle()
+
 		// Pump SDL events to update keyboard state
 		sdl.PumpEvents()
 
@@ -792,25 +1041,34 @@ func (ui *FyneUI) updateLoop() {
 			// nothing should run until Start/Resume.)
 			accumulator = 0
 		} else {
+			// Read each tick: loading another ROM can change the timing.
+			frameStep := ui.emulator.FrameTime
 			accumulator += delta
 			maxAccum := frameStep * maxCatchUpFrames
 			if accumulator > maxAccum {
 				accumulator = maxAccum
 			}
 
-			// Fixed-timestep emulation: advance 1 frame per 16.67ms of accumulated time.
-			for accumulator >= frameStep && framesStepped < maxCatchUpFrames {
+			// Fixed-timestep emulation: advance 1 frame per 16.67ms (20ms at 50Hz) of accumulated time.
+			for accumulator >= frameStep-frameSlack && framesStepped < maxCatchUpFrames {
+				ui.emulator.SetInputButtons(ui.movieInput(ui.hostInput.Frame(ui.currentButtons())))
 				if err := ui.emulator.RunFrame(); err != nil {
 					if ui.emulator.Logger != nil {
 						ui.emulator.Logger.LogUI(debug.LogLevelError, fmt.Sprintf("Emulation error: %v", err), nil)
 					}
 					break
 				}
+				ui.checkMovie()
 				ui.queueFrameAudio()
 				accumulator -= frameStep
 				framesStepped++
 			}
 		}
+		if ui.debugConsole != nil {
+			for _, line := range ui.emulator.DebugConsole.TakeLines() {
+				fmt.Fprintln(ui.debugConsole, line)
+			}
+		}
 
 		// Render emulator screen
 		// Note: RunFrame() completes a full PPU frame (127,820 cycles), so buffer is ready
@@ -822,22 +1080,28 @@ func (ui *FyneUI) updateLoop() {
 		// cleared (black) framebuffer is pushed to the display.
 		justStopped := wasRunning && !ui.emulator.Running
 		wasRunning = ui.emulator.Running
-		if framesStepped > 0 || (ui.emulator.Paused && uiTickCount%8 == 0) || justStopped {
+		if ui.frameSkip.Present(framesStepped) || (ui.emulator.Paused && uiTickCount%8 == 0) || justStopped {
 			img, imgErr = ui.renderEmulatorScreen()
 		}
 
 		// Update UI on main thread. Throttle status/panel refreshes slightly to reduce
 		// Fyne/UI work in the hot path while keeping the emulator display at full rate.
 		fps := ui.emulator.GetFPS()
+		stats := ui.emulator.FrameStats()
 		cycles := ui.emulator.GetCPUCyclesPerFrame()
 		frameCount := ui.emulator.FrameCount
 		refreshAuxPanels := (uiTickCount%4 == 0) // ~15 Hz at 60 FPS target
+		extraStatus := ui.movieStatusText() + ui.playlistStatusText() + ui.frameSkipStatusText()
 		fyne.Do(func() {
 			if img != nil && imgErr == nil {
 				ui.emulatorImage.Image = img
 				ui.emulatorImage.Refresh()
 			}
-			ui.statusLabel.SetText(fmt.Sprintf("FPS: %.1f | CPU: %d cycles/frame | Frame: %d", fps, cycles, frameCount))
+			ui.statusLabel.SetText(i18n.T("FPS: %.1f | Speed: %.0f%% | CPU: %d cycles/frame | Frame: %d%s", fps, stats.SpeedPercent, cycles, frameCount, extraStatus))
+			if ui.showPerfOSD && refreshAuxPanels {
+				ui.perfOSD.Text = fmt.Sprintf("FPS %.1f | %s", fps, stats)
+				ui.perfOSD.Refresh()
+			}
 			if refreshAuxPanels {
 				// Update register viewer if visible
 				if ui.showRegisters && ui.updateRegisters != nil {
@@ -857,6 +1121,17 @@ func (ui *FyneUI) updateLoop() {
 				}
 			}
 		})
+		tickEnd := time.Now()
+		if framesStepped > 0 {
+			// Behind when emulating and drawing took longer than the frames cover.
+			ui.frameSkip.EndTick(tickEnd.Sub(tickStart) > time.Duration(framesStepped)*ui.emulator.FrameTime)
+		}
+		if trace := ui.emulator.Trace; trace != nil {
+			trace.Span(debug.TraceTrackUI, "UI tick", tickStart, tickEnd, map[string]interface{}{
+				"frames": framesStepped,
+				"skip":   ui.frameSkip.Level(),
+			})
+		}
 	}
 }
 
@@ -875,18 +1150,20 @@ func (ui *FyneUI) queueFrameAudio() {
 		return
 	}
 
+	start := time.Now()
 	// Convert mono int16 fixed-point samples to interleaved stereo float32 (little-endian).
 	// Duplicating channels keeps behavior simple until a stereo mixer path is introduced.
-	j := 0
-	for _, s := range samples {
-		f := apu.ConvertFixedToFloat(s)
-		bits := math.Float32bits(f)
-		binary.LittleEndian.PutUint32(ui.audioFrame[j:j+4], bits)
-		binary.LittleEndian.PutUint32(ui.audioFrame[j+4:j+8], bits)
-		j += 8
-	}
+	ui.audioFrame = apu.ConvertFrameStereoBytes(ui.audioFrame, samples)
 
 	_ = sdl.QueueAudio(ui.audioDev, ui.audioFrame)
+	// Queue is interleaved stereo float32: 8 bytes per output sample.
+	queued := int(sdl.GetQueuedAudioSize(ui.audioDev) / 8)
+	ui.emulator.ReportAudioQueue(queued)
+	if trace := ui.emulator.Trace; trace != nil {
+		end := time.Now()
+		trace.Span(debug.TraceTrackAudio, "Queue audio", start, end, map[string]interface{}{"samples": len(samples)})
+		trace.Counter("Audio queue", end, map[string]float64{"samples": float64(queued)})
+	}
 }
 
 // Cleanup cleans up resources
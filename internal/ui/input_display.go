@@ -0,0 +1,40 @@
+package ui
+
+import (
+	"fmt"
+
+	"nitro-core-dx/internal/debug"
+	"nitro-core-dx/internal/i18n"
+)
+
+// inputDisplayPlugin is the built-in frame plugin behind View > Input Display.
+const inputDisplayPlugin = "input-display"
+
+// toggleInputDisplay asks the update loop to show or hide the on-screen
+// controller (emulator.InputDisplayPlugin). It refuses while a movie is
+// recording or playing: the overlay is drawn into the frames movies hash.
+func (ui *FyneUI) toggleInputDisplay() {
+	ui.movieMu.Lock()
+	busy := ui.movieRec != nil || ui.moviePlay != nil
+	ui.movieMu.Unlock()
+	if busy {
+		ui.statusLabel.SetText(i18n.T("Input display is unavailable while a movie is recording or playing"))
+		return
+	}
+	ui.inputDisplayToggle.Store(true)
+}
+
+// applyInputDisplayToggle installs or removes the input display plugin if
+// the menu asked for it. It runs on the update loop, between frames, since
+// RunFrame walks the plugin list.
+func (ui *FyneUI) applyInputDisplayToggle() {
+	if !ui.inputDisplayToggle.Swap(false) {
+		return
+	}
+	if ui.emulator.RemoveFramePlugin(inputDisplayPlugin) {
+		return
+	}
+	if err := ui.emulator.InstallFramePlugin(inputDisplayPlugin); err != nil && ui.emulator.Logger != nil {
+		ui.emulator.Logger.LogUI(debug.LogLevelError, fmt.Sprintf("Input display: %v", err), nil)
+	}
+}
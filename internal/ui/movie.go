@@ -0,0 +1,104 @@
+package ui
+
+import (
+	"fmt"
+
+	"nitro-core-dx/internal/harness"
+	"nitro-core-dx/internal/i18n"
+)
+
+// RecordMovie records a movie from a power cycle of the loaded ROM.
+// FinishMovie returns it once the window has closed.
+func (ui *FyneUI) RecordMovie(author, comment string) error {
+	rec, err := harness.StartMovie(ui.emulator, true, author, comment)
+	if err != nil {
+		return err
+	}
+	ui.movieMu.Lock()
+	ui.movieRec = rec
+	ui.movieMu.Unlock()
+	ui.SetMidFrameInputPoll(false)
+	ui.emulator.Notify("Movie recording started")
+	return nil
+}
+
+// PlayMovie plays m on the loaded ROM in place of the keyboard. The status
+// bar reports the first checkpoint that does not match, and MovieDesync
+// returns it.
+func (ui *FyneUI) PlayMovie(m *harness.Movie) error {
+	p, err := harness.PlayMovie(ui.emulator, m)
+	if err != nil {
+		return err
+	}
+	ui.movieMu.Lock()
+	ui.moviePlay = p
+	ui.movieLen = len(m.Input)
+	ui.movieMu.Unlock()
+	ui.SetMidFrameInputPoll(false)
+	ui.emulator.Notify("Movie playing")
+	return nil
+}
+
+// FinishMovie ends the recording started by RecordMovie and returns it, or
+// nil if there is none.
+func (ui *FyneUI) FinishMovie() *harness.Movie {
+	ui.movieMu.Lock()
+	defer ui.movieMu.Unlock()
+	if ui.movieRec == nil {
+		return nil
+	}
+	m := ui.movieRec.Finish()
+	ui.movieRec = nil
+	return m
+}
+
+// MovieDesync is the first checkpoint the playing movie did not reproduce,
+// or nil.
+func (ui *FyneUI) MovieDesync() *harness.MovieMismatch {
+	ui.movieMu.Lock()
+	defer ui.movieMu.Unlock()
+	return ui.movieDesync
+}
+
+// movieInput returns the buttons for the frame about to run: the playing
+// movie's, or buttons (recorded if a movie is recording).
+func (ui *FyneUI) movieInput(buttons uint16) uint16 {
+	ui.movieMu.Lock()
+	defer ui.movieMu.Unlock()
+	if ui.moviePlay != nil {
+		if input, ok := ui.moviePlay.Next(); ok {
+			buttons = input
+		}
+	}
+	if ui.movieRec != nil {
+		ui.movieRec.Frame(buttons)
+	}
+	return buttons
+}
+
+// checkMovie compares the frame just run with the playing movie.
+func (ui *FyneUI) checkMovie() {
+	ui.movieMu.Lock()
+	defer ui.movieMu.Unlock()
+	if ui.moviePlay == nil || ui.movieDesync != nil {
+		return
+	}
+	ui.movieDesync = ui.moviePlay.Check(ui.emulator)
+}
+
+// movieStatusText describes the active movie for the status bar.
+func (ui *FyneUI) movieStatusText() string {
+	ui.movieMu.Lock()
+	defer ui.movieMu.Unlock()
+	switch {
+	case ui.movieRec != nil:
+		return fmt.Sprintf(" | REC %d", ui.movieRec.Frames())
+	case ui.moviePlay == nil:
+		return ""
+	case ui.movieDesync != nil:
+		return " | " + ui.movieDesync.Error()
+	case ui.moviePlay.Done():
+		return " | " + i18n.T("Movie ended, in sync")
+	}
+	return fmt.Sprintf(" | PLAY %d/%d", ui.moviePlay.Frame(), ui.movieLen)
+}
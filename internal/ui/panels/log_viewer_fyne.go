@@ -154,7 +154,7 @@ func LogViewerFyne(logger *debug.Logger, window fyne.Window) (*fyne.Container, f
 			}
 
 			// Check level filter
-			if entry.Level < levelFilter {
+			if entry.Level > levelFilter {
 				continue
 			}
 
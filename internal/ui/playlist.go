@@ -0,0 +1,73 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"nitro-core-dx/internal/debug"
+	"nitro-core-dx/internal/fileassoc"
+	"nitro-core-dx/internal/i18n"
+)
+
+// SetPlaylist turns on jukebox mode over p, whose current entry should
+// already be loaded: Ctrl+PageDown / Ctrl+PageUp step through it, and with
+// interval > 0 the next entry loads after the current one has run that
+// long. Call before Run.
+func (ui *FyneUI) SetPlaylist(p *fileassoc.Playlist, interval time.Duration) {
+	ui.playlist = p
+	ui.playlistInterval = interval
+	ui.playlistLoaded = time.Now()
+}
+
+// stepPlaylist asks the update loop to move n playlist entries.
+func (ui *FyneUI) stepPlaylist(n int) {
+	if ui.playlist == nil {
+		ui.statusLabel.SetText(i18n.T("No playlist (start the emulator with -playlist <dir>)"))
+		return
+	}
+	ui.playlistStep.Add(int32(n))
+}
+
+// advancePlaylist loads the entry the hotkeys asked for, or the next one
+// once the auto-advance interval is up. It runs on the update loop, between
+// frames.
+func (ui *FyneUI) advancePlaylist(now time.Time) {
+	if ui.playlist == nil {
+		return
+	}
+	n := int(ui.playlistStep.Swap(0))
+	if n == 0 && ui.playlistInterval > 0 && now.Sub(ui.playlistLoaded) >= ui.playlistInterval {
+		n = 1
+	}
+	if n == 0 {
+		return
+	}
+	path := ui.playlist.Step(n)
+	ui.playlistLoaded = now
+	ui.playlistErr = nil
+	data, err := fileassoc.ReadROM(path)
+	if err == nil {
+		err = ui.loadROMBytes(data)
+	}
+	if err != nil {
+		ui.playlistErr = err
+		if ui.emulator.Logger != nil {
+			ui.emulator.Logger.LogUI(debug.LogLevelError, fmt.Sprintf("Playlist: %s: %v", path, err), nil)
+		}
+		return
+	}
+	ui.emulator.Notify(fmt.Sprintf("ROM %d/%d: %s", ui.playlist.Index()+1, ui.playlist.Len(), filepath.Base(path)))
+}
+
+// playlistStatusText describes the playlist position for the status bar.
+func (ui *FyneUI) playlistStatusText() string {
+	if ui.playlist == nil {
+		return ""
+	}
+	s := fmt.Sprintf(" | ROM %d/%d: %s", ui.playlist.Index()+1, ui.playlist.Len(), filepath.Base(ui.playlist.Current()))
+	if ui.playlistErr != nil {
+		s += " (failed to load: " + ui.playlistErr.Error() + ")"
+	}
+	return s
+}
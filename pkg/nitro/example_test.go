@@ -0,0 +1,83 @@
+package nitro_test
+
+import (
+	"fmt"
+	"image/png"
+	"log"
+	"os"
+
+	"nitro-core-dx/pkg/nitro"
+)
+
+// Run a ROM for two seconds holding RIGHT and save the last frame as a PNG.
+func Example() {
+	rom, err := os.ReadFile("game.rom")
+	if err != nil {
+		log.Fatal(err)
+	}
+	console, err := nitro.Load(rom)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := console.Input(1, nitro.ButtonRight); err != nil {
+		log.Fatal(err)
+	}
+	for i := 0; i < 2*nitro.FrameRate; i++ {
+		if err := console.RunFrame(); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	f, err := os.Create("frame.png")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+	if err := png.Encode(f, console.Image()); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// Collect a ROM's sound, e.g. to write it to a WAV file or an audio device.
+func ExampleConsole_AudioFrame() {
+	rom, err := os.ReadFile("game.rom")
+	if err != nil {
+		log.Fatal(err)
+	}
+	console, err := nitro.Load(rom)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var pcm []int16 // mono, nitro.SampleRate Hz
+	for i := 0; i < 10*nitro.FrameRate; i++ {
+		if err := console.RunFrame(); err != nil {
+			log.Fatal(err)
+		}
+		pcm = append(pcm, console.AudioFrame()...)
+	}
+	fmt.Printf("%d samples\n", len(pcm))
+}
+
+// Rewind: save the machine, try something, and go back.
+func ExampleConsole_SaveState() {
+	rom, err := os.ReadFile("game.rom")
+	if err != nil {
+		log.Fatal(err)
+	}
+	console, err := nitro.Load(rom)
+	if err != nil {
+		log.Fatal(err)
+	}
+	state, err := console.SaveState()
+	if err != nil {
+		log.Fatal(err)
+	}
+	buttons, _ := nitro.ParseButtons("a,start")
+	console.Input(1, buttons)
+	for i := 0; i < nitro.FrameRate; i++ {
+		console.RunFrame()
+	}
+	if err := console.LoadState(state); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,179 @@
+// Package nitro embeds the Nitro-Core-DX console in other Go programs
+// without importing the internal packages: load a ROM, feed it controller
+// input, run it a frame at a time, and read back the picture, the sound and
+// savestates.
+//
+// A Console is a plain deterministic machine. It never sleeps or reads the
+// wall clock, so a frame runs as fast as the host can emulate it and the
+// same ROM, input and frame count always produce the same output. Pacing
+// (60 frames per second for real-time play) is up to the host. A Console is
+// not safe for concurrent use.
+package nitro
+
+import (
+	"fmt"
+	"image"
+
+	"nitro-core-dx/internal/debug"
+	"nitro-core-dx/internal/emulator"
+	"nitro-core-dx/internal/input"
+)
+
+// Video and audio formats.
+const (
+	ScreenWidth  = 320
+	ScreenHeight = 200
+
+	// FrameRate is the console's frame rate in frames per second.
+	FrameRate = 60
+	// SampleRate is the audio sample rate in Hz; each frame produces
+	// SamplesPerFrame mono samples.
+	SampleRate      = 44100
+	SamplesPerFrame = SampleRate / FrameRate
+)
+
+// Buttons is a controller button mask.
+type Buttons uint16
+
+// Controller buttons.
+const (
+	ButtonUp    Buttons = 1 << input.ButtonUP
+	ButtonDown  Buttons = 1 << input.ButtonDOWN
+	ButtonLeft  Buttons = 1 << input.ButtonLEFT
+	ButtonRight Buttons = 1 << input.ButtonRIGHT
+	ButtonA     Buttons = 1 << input.ButtonA
+	ButtonB     Buttons = 1 << input.ButtonB
+	ButtonX     Buttons = 1 << input.ButtonX
+	ButtonY     Buttons = 1 << input.ButtonY
+	ButtonL     Buttons = 1 << input.ButtonL
+	ButtonR     Buttons = 1 << input.ButtonR
+	ButtonStart Buttons = 1 << input.ButtonSTART
+	ButtonZ     Buttons = 1 << input.ButtonZ
+)
+
+// ParseButtons parses a comma-separated list of button names ("a,start",
+// "up, right"), case-insensitively.
+func ParseButtons(list string) (Buttons, error) {
+	mask, err := input.ParseButtons(list)
+	return Buttons(mask), err
+}
+
+// Console is one Nitro-Core-DX machine.
+type Console struct {
+	emu *emulator.Emulator
+}
+
+// New returns a powered-off console with no ROM. Call Load before RunFrame.
+func New() *Console {
+	emu := emulator.NewEmulatorWithLogger(debug.NewLogger(100))
+	emu.SetFrameLimit(false)
+	emu.SetDeterministic(true)
+	return &Console{emu: emu}
+}
+
+// Load returns a console running rom (a .rom file image), as New then
+// Console.Load.
+func Load(rom []byte) (*Console, error) {
+	c := New()
+	if err := c.Load(rom); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Load inserts rom and power-cycles the console, so it boots the new ROM
+// from a clean machine on the next RunFrame.
+func (c *Console) Load(rom []byte) error {
+	if err := c.emu.LoadROM(rom); err != nil {
+		return err
+	}
+	c.emu.Reset()
+	return nil
+}
+
+// Loaded reports whether a ROM has been loaded.
+func (c *Console) Loaded() bool {
+	return c.emu.Cartridge.HasROM()
+}
+
+// RunFrame emulates one frame (1/60 s) with the current input.
+func (c *Console) RunFrame() error {
+	if !c.Loaded() {
+		return fmt.Errorf("no ROM loaded")
+	}
+	return c.emu.RunFrame()
+}
+
+// Frame is the number of frames run since the ROM was loaded or the
+// console last reset.
+func (c *Console) Frame() uint64 {
+	return c.emu.FrameCount
+}
+
+// Reset power-cycles the console (a hard reset).
+func (c *Console) Reset() {
+	c.emu.Reset()
+}
+
+// SoftReset presses the reset button: the ROM restarts but keeps RAM and
+// video memory.
+func (c *Console) SoftReset() {
+	c.emu.SoftReset()
+}
+
+// Framebuffer is the last frame's picture, ScreenWidth*ScreenHeight pixels
+// in rows from the top left, each 0x00RRGGBB. It is the console's own
+// buffer: it changes on the next RunFrame and must not be modified.
+func (c *Console) Framebuffer() []uint32 {
+	return c.emu.GetOutputBuffer()
+}
+
+// Image copies the last frame's picture into an image.
+func (c *Console) Image() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, ScreenWidth, ScreenHeight))
+	for i, px := range c.Framebuffer() {
+		img.Pix[i*4+0] = uint8(px >> 16)
+		img.Pix[i*4+1] = uint8(px >> 8)
+		img.Pix[i*4+2] = uint8(px)
+		img.Pix[i*4+3] = 0xFF
+	}
+	return img
+}
+
+// AudioFrame is the last frame's sound, SamplesPerFrame signed 16-bit mono
+// samples at SampleRate. Like Framebuffer it is the console's own buffer.
+func (c *Console) AudioFrame() []int16 {
+	return c.emu.AudioSampleBuffer
+}
+
+// WRAM is the console's 32 KB of work RAM (bank 0, 0x0000-0x7FFF), where
+// CoreLX globals live; a build's .memmap file lists their addresses. Like
+// Framebuffer it is the console's own buffer and must not be modified.
+func (c *Console) WRAM() []byte {
+	return c.emu.Bus.WRAM[:]
+}
+
+// Input sets the buttons held on controller port 1 or 2 for the frames
+// that follow.
+func (c *Console) Input(port int, buttons Buttons) error {
+	switch port {
+	case 1:
+		c.emu.Input.Controller1Buttons = uint16(buttons)
+	case 2:
+		c.emu.Input.Controller2Buttons = uint16(buttons)
+	default:
+		return fmt.Errorf("controller port %d out of range (1-2)", port)
+	}
+	return nil
+}
+
+// SaveState returns a snapshot of the whole machine, which LoadState
+// restores on a console running the same ROM.
+func (c *Console) SaveState() ([]byte, error) {
+	return c.emu.SaveState()
+}
+
+// LoadState restores a SaveState snapshot.
+func (c *Console) LoadState(state []byte) error {
+	return c.emu.LoadState(state)
+}
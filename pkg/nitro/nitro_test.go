@@ -0,0 +1,103 @@
+package nitro
+
+import (
+	"reflect"
+	"testing"
+
+	"nitro-core-dx/internal/corelx"
+)
+
+// spriteExample is a manual example that moves a sprite with the D-pad.
+const spriteExample = "../../docs/manual_examples/sprite.corelx"
+
+func loadTestConsole(t *testing.T) *Console {
+	t.Helper()
+	res, err := corelx.CompileProject(spriteExample, nil)
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	c, err := Load(res.ROMBytes)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	return c
+}
+
+func runFrames(t *testing.T, c *Console, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		if err := c.RunFrame(); err != nil {
+			t.Fatalf("frame %d: %v", c.Frame(), err)
+		}
+	}
+}
+
+func TestConsoleRunsInput(t *testing.T) {
+	if err := New().RunFrame(); err == nil {
+		t.Fatal("RunFrame with no ROM succeeded")
+	}
+
+	c := loadTestConsole(t)
+	runFrames(t, c, 3)
+	if c.Frame() != 3 {
+		t.Fatalf("Frame() = %d after 3 frames", c.Frame())
+	}
+	if len(c.Framebuffer()) != ScreenWidth*ScreenHeight || len(c.AudioFrame()) != SamplesPerFrame {
+		t.Fatalf("framebuffer %d pixels, audio %d samples", len(c.Framebuffer()), len(c.AudioFrame()))
+	}
+	still := append([]uint32(nil), c.Framebuffer()...)
+
+	if err := c.Input(1, ButtonRight); err != nil {
+		t.Fatal(err)
+	}
+	runFrames(t, c, 3)
+	if reflect.DeepEqual(c.Framebuffer(), still) {
+		t.Fatal("holding RIGHT did not move the sprite")
+	}
+	img := c.Image()
+	for i, px := range c.Framebuffer() {
+		got := img.RGBAAt(i%ScreenWidth, i/ScreenWidth)
+		if uint32(got.R)<<16|uint32(got.G)<<8|uint32(got.B) != px&0xFFFFFF {
+			t.Fatalf("Image pixel %d is %v, framebuffer 0x%06X", i, got, px)
+		}
+	}
+
+	if err := c.Input(3, 0); err == nil {
+		t.Error("Input on port 3 succeeded")
+	}
+	if b, err := ParseButtons("a, Start"); err != nil || b != ButtonA|ButtonStart {
+		t.Errorf("ParseButtons = %v, %v", b, err)
+	}
+}
+
+func TestConsoleSaveStateReplays(t *testing.T) {
+	c := loadTestConsole(t)
+	runFrames(t, c, 10)
+	state, err := c.SaveState()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	run := func() ([]uint32, []int16) {
+		for i, b := range []Buttons{0, ButtonRight, ButtonDown, 0, ButtonLeft | ButtonUp} {
+			if err := c.Input(1, b); err != nil {
+				t.Fatal(err)
+			}
+			runFrames(t, c, i+1)
+		}
+		return append([]uint32(nil), c.Framebuffer()...), append([]int16(nil), c.AudioFrame()...)
+	}
+	fb, audio := run()
+	if err := c.LoadState(state); err != nil {
+		t.Fatal(err)
+	}
+	fb2, audio2 := run()
+	if !reflect.DeepEqual(fb, fb2) || !reflect.DeepEqual(audio, audio2) {
+		t.Error("replaying from a savestate produced different output")
+	}
+
+	c.Reset()
+	if c.Frame() != 0 {
+		t.Errorf("Frame() = %d after Reset", c.Frame())
+	}
+}
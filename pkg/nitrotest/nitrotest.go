@@ -0,0 +1,223 @@
+// Package nitrotest runs Nitro-Core-DX games from Go tests: build or load
+// a ROM, play a controller script on it headlessly and assert on what the
+// game left in work RAM, so game logic can be developed test first.
+//
+//	func TestPressingACounts(t *testing.T) {
+//		rom := nitrotest.Build(t, "../game/main.corelx")
+//		nitrotest.PlayInputs(t, rom, nitrotest.Inputs{
+//			nitrotest.Wait(2),
+//			nitrotest.Press(nitro.ButtonA),
+//			nitrotest.Wait(2),
+//		}, func(t testing.TB, r *nitrotest.Run) {
+//			if got := r.Global("count"); got != 1 {
+//				t.Errorf("count = %d after one press, want 1", got)
+//			}
+//		})
+//	}
+//
+// Runs are deterministic (see nitro.Console): the same ROM and script give
+// the same result every time.
+package nitrotest
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"nitro-core-dx/internal/corelx"
+	"nitro-core-dx/pkg/nitro"
+)
+
+// ROM is a game to play: its image and the WRAM addresses of its globals.
+type ROM struct {
+	Image   []byte
+	Symbols map[string]Symbol
+}
+
+// Symbol is a CoreLX global's place in WRAM.
+type Symbol struct {
+	Address uint16
+	Size    uint16
+}
+
+// Build compiles the CoreLX project at path (a main .corelx file, a
+// project directory or a .ncdx container), failing t if it does not build.
+func Build(t testing.TB, path string) *ROM {
+	t.Helper()
+	res, err := corelx.CompileProject(path, nil)
+	if err != nil {
+		t.Fatalf("nitrotest: build %s: %v", path, err)
+	}
+	rom := &ROM{Image: res.ROMBytes, Symbols: map[string]Symbol{}}
+	for _, e := range res.MemoryMap {
+		rom.Symbols[e.Name] = Symbol{Address: e.Address, Size: e.Size}
+	}
+	return rom
+}
+
+// Load reads a built .rom file, and its globals from the .memmap listing
+// the compiler writes next to it (path + ".memmap") when there is one.
+func Load(t testing.TB, path string) *ROM {
+	t.Helper()
+	image, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("nitrotest: %v", err)
+	}
+	rom := &ROM{Image: image, Symbols: map[string]Symbol{}}
+	listing, err := os.ReadFile(path + ".memmap")
+	if os.IsNotExist(err) {
+		return rom
+	}
+	if err != nil {
+		t.Fatalf("nitrotest: %v", err)
+	}
+	if rom.Symbols, err = parseMemoryMap(listing); err != nil {
+		t.Fatalf("nitrotest: %s.memmap: %v", path, err)
+	}
+	return rom
+}
+
+// parseMemoryMap reads a .memmap listing: "name 0xADDR size kind" lines
+// under # comments.
+func parseMemoryMap(listing []byte) (map[string]Symbol, error) {
+	symbols := map[string]Symbol{}
+	sc := bufio.NewScanner(bytes.NewReader(listing))
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		f := strings.Fields(text)
+		if len(f) < 3 {
+			return nil, fmt.Errorf("line %d: want name, address and size", line)
+		}
+		addr, err := strconv.ParseUint(f[1], 0, 16)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: address %q: %w", line, f[1], err)
+		}
+		size, err := strconv.ParseUint(f[2], 0, 16)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: size %q: %w", line, f[2], err)
+		}
+		symbols[f[0]] = Symbol{Address: uint16(addr), Size: uint16(size)}
+	}
+	return symbols, sc.Err()
+}
+
+// Step is one entry of a controller script: hold Buttons on controller 1
+// for Frames frames, then run Check, if set, on the result.
+type Step struct {
+	Buttons nitro.Buttons
+	Frames  int
+	Check   func(t testing.TB, r *Run)
+}
+
+// Inputs is a controller script, played from power-on.
+type Inputs []Step
+
+// Hold holds buttons for frames frames.
+func Hold(buttons nitro.Buttons, frames int) Step {
+	return Step{Buttons: buttons, Frames: frames}
+}
+
+// Wait runs frames frames with no buttons held.
+func Wait(frames int) Step {
+	return Step{Frames: frames}
+}
+
+// Press holds buttons for one frame. Follow it with a Wait so the game
+// sees the release before the next press.
+func Press(buttons nitro.Buttons) Step {
+	return Hold(buttons, 1)
+}
+
+// Expect checks the game at this point of the script without running a
+// frame.
+func Expect(check func(t testing.TB, r *Run)) Step {
+	return Step{Check: check}
+}
+
+// Run is a game being played, as checks and PlayInputs' assert see it.
+type Run struct {
+	*nitro.Console
+	t   testing.TB
+	rom *ROM
+}
+
+// Byte returns the WRAM byte at addr.
+func (r *Run) Byte(addr uint16) uint8 {
+	r.t.Helper()
+	wram := r.WRAM()
+	if int(addr) >= len(wram) {
+		r.t.Fatalf("nitrotest: 0x%04X is outside WRAM (0x0000-0x%04X)", addr, len(wram)-1)
+	}
+	return wram[addr]
+}
+
+// Word returns the little-endian WRAM word at addr.
+func (r *Run) Word(addr uint16) uint16 {
+	r.t.Helper()
+	return uint16(r.Byte(addr)) | uint16(r.Byte(addr+1))<<8
+}
+
+// Addr returns the WRAM address of the global name, failing the test if
+// the ROM has no such global.
+func (r *Run) Addr(name string) uint16 {
+	r.t.Helper()
+	sym, ok := r.rom.Symbols[name]
+	if !ok {
+		r.t.Fatalf("nitrotest: no global %q in the ROM's memory map", name)
+	}
+	return sym.Address
+}
+
+// Global returns the value of the global name: its byte for a one-byte
+// global, else its first word (an int, or the first element of an array
+// or struct).
+func (r *Run) Global(name string) uint16 {
+	r.t.Helper()
+	addr := r.Addr(name)
+	if r.rom.Symbols[name].Size == 1 {
+		return uint16(r.Byte(addr))
+	}
+	return r.Word(addr)
+}
+
+// Signed returns the global name as a signed 16-bit int.
+func (r *Run) Signed(name string) int16 {
+	r.t.Helper()
+	return int16(r.Global(name))
+}
+
+// PlayInputs powers on a console with rom, plays inputs and then calls
+// assert, if not nil, with the result. A ROM that fails to load or faults
+// fails the test at the frame it happened.
+func PlayInputs(t testing.TB, rom *ROM, inputs Inputs, assert func(t testing.TB, r *Run)) *Run {
+	t.Helper()
+	console, err := nitro.Load(rom.Image)
+	if err != nil {
+		t.Fatalf("nitrotest: load ROM: %v", err)
+	}
+	r := &Run{Console: console, t: t, rom: rom}
+	for i, step := range inputs {
+		if err := console.Input(1, step.Buttons); err != nil {
+			t.Fatalf("nitrotest: step %d: %v", i, err)
+		}
+		for f := 0; f < step.Frames; f++ {
+			if err := console.RunFrame(); err != nil {
+				t.Fatalf("nitrotest: step %d, frame %d: %v", i, console.Frame(), err)
+			}
+		}
+		if step.Check != nil {
+			step.Check(t, r)
+		}
+	}
+	if assert != nil {
+		assert(t, r)
+	}
+	return r
+}
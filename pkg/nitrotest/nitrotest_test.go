@@ -0,0 +1,64 @@
+package nitrotest
+
+import (
+	"path/filepath"
+	"testing"
+
+	"nitro-core-dx/internal/corelx"
+	"nitro-core-dx/pkg/nitro"
+)
+
+// counterExample counts presses of A in the global count.
+const counterExample = "../../docs/manual_examples/counter.corelx"
+
+func TestPlayInputsCountsPresses(t *testing.T) {
+	rom := Build(t, counterExample)
+	checked := 0
+	PlayInputs(t, rom, Inputs{
+		Wait(3),
+		Expect(func(t testing.TB, r *Run) {
+			checked++
+			if got := r.Global("count"); got != 0 {
+				t.Errorf("count = %d before any press", got)
+			}
+		}),
+		Press(nitro.ButtonA),
+		Wait(2),
+		Hold(nitro.ButtonA, 30), // held: still one press
+		Wait(2),
+		Expect(func(t testing.TB, r *Run) {
+			checked++
+			if got := r.Global("count"); got != 2 {
+				t.Errorf("count = %d after two presses, want 2", got)
+			}
+		}),
+	}, func(t testing.TB, r *Run) {
+		checked++
+		if r.Frame() != 38 {
+			t.Errorf("ran %d frames, want 38", r.Frame())
+		}
+		if r.Word(r.Addr("count")) != 2 || r.Signed("count") != 2 {
+			t.Error("Word/Signed disagree with Global")
+		}
+	})
+	if checked != 3 {
+		t.Fatalf("%d checks ran, want 3", checked)
+	}
+}
+
+func TestLoadReadsMemoryMap(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "counter.rom")
+	if _, err := corelx.CompileProject(counterExample, &corelx.CompileOptions{OutputPath: out}); err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	built := Build(t, counterExample)
+	loaded := Load(t, out)
+	if loaded.Symbols["count"] != built.Symbols["count"] || loaded.Symbols["count"].Size != 2 {
+		t.Fatalf("count from .memmap = %+v, from the build %+v", loaded.Symbols["count"], built.Symbols["count"])
+	}
+	PlayInputs(t, loaded, Inputs{Wait(2), Press(nitro.ButtonA), Wait(2)}, func(t testing.TB, r *Run) {
+		if got := r.Global("count"); got != 1 {
+			t.Errorf("count = %d after one press, want 1", got)
+		}
+	})
+}
@@ -41,6 +41,8 @@ echo "Building ${APP_NAME} (${GOOS_TARGET}/${GOARCH_TARGET})..."
     -out roms/matrix_floor_only_kart.rom
   GOOS="${GOOS_TARGET}" GOARCH="${GOARCH_TARGET}" \
     go build -tags ymfm_cgo,no_sdl_ttf -o "${STAGE_DIR}/${APP_NAME}" ./cmd/corelx_devkit
+  # Template previews for the New Project dialog, captured from this build.
+  go run ./cmd/gallery_builder -out "${STAGE_DIR}/docs/gallery"
 )
 
 cp "${ROOT_DIR}/LICENSE" "${STAGE_DIR}/LICENSE"
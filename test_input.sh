@@ -16,11 +16,11 @@ fi
 
 # Build test ROM generator
 echo "📦 Building input test ROM generator..."
-go build -o testrom_input ./cmd/testrom/input
+go build -o testrom ./cmd/testrom
 
 # Generate test ROM
 echo "🔨 Generating input test ROM..."
-./testrom_input roms/input_test.rom
+./testrom input roms/input_test.rom
 
 if [ ! -f "roms/input_test.rom" ]; then
     echo "❌ Failed to generate test ROM"
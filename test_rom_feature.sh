@@ -4,7 +4,7 @@
 ROM_NAME=$1
 if [ -z "$ROM_NAME" ]; then
     echo "Usage: $0 <rom_name>"
-    echo "  rom_name: minimal, input, colors, audio, complete"
+    echo "  rom_name: a cmd/testrom scenario (go run ./cmd/testrom -list)"
     exit 1
 fi
 
@@ -19,15 +19,9 @@ if [ ! -f "$ROM_PATH" ]; then
     echo "❌ ROM not found: $ROM_PATH"
     echo "   Building it first..."
     
-    case $ROM_NAME in
-        minimal)
-            go run ./cmd/testrom/minimal "$ROM_PATH"
-            ;;
-        *)
-            echo "   Build script for $ROM_NAME not implemented yet"
-            exit 1
-            ;;
-    esac
+    if ! go run ./cmd/testrom "$ROM_NAME" "$ROM_PATH"; then
+        exit 1
+    fi
 fi
 
 echo "ROM found: $ROM_PATH"